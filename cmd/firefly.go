@@ -34,6 +34,7 @@ import (
 	"github.com/hyperledger/firefly/internal/apiserver"
 	"github.com/hyperledger/firefly/internal/coreconfig"
 	"github.com/hyperledger/firefly/internal/namespace"
+	"github.com/hyperledger/firefly/internal/tracing"
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 )
@@ -211,6 +212,13 @@ func startFirefly(ctx context.Context, cancelCtx context.CancelFunc, mgr namespa
 		close(ffDone)
 	}()
 
+	shutdownTracing, err := tracing.Init(ctx)
+	if err != nil {
+		errChan <- err
+		return
+	}
+	defer shutdownTracing()
+
 	if err = mgr.Init(ctx, cancelCtx, resetChan, reloadConfig); err != nil {
 		errChan <- err
 		return
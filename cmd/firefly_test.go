@@ -64,6 +64,16 @@ func TestExecEngineInitFail(t *testing.T) {
 	assert.Regexp(t, "splutter", err)
 }
 
+func TestExecTracingInitFail(t *testing.T) {
+	t.Setenv("FIREFLY_TRACING_ENABLED", "true")
+	t.Setenv("FIREFLY_TRACING_OTLP_PROTOCOL", "wrong")
+	_utManager = &namespacemocks.Manager{}
+	defer func() { _utManager = nil }()
+	os.Chdir(configDir)
+	err := Execute()
+	assert.Regexp(t, "FF10501", err)
+}
+
 func TestExecEngineStartFail(t *testing.T) {
 	o := &namespacemocks.Manager{}
 	o.On("Init", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
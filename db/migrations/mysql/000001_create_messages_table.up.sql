@@ -0,0 +1,31 @@
+CREATE TABLE messages (
+  seq             BIGINT          NOT NULL AUTO_INCREMENT PRIMARY KEY,
+  id              CHAR(36)        NOT NULL,
+  cid             CHAR(36),
+  mtype           VARCHAR(64)     NOT NULL,
+  author          VARCHAR(1024)   NOT NULL,
+  "key"           VARCHAR(1024),
+  created         BIGINT          NOT NULL,
+  namespace       VARCHAR(64)     NOT NULL,
+  namespace_local VARCHAR(64),
+  topics          VARCHAR(1024)   NOT NULL,
+  tag             VARCHAR(64)     NOT NULL,
+  group_hash      CHAR(64),
+  datahash        CHAR(64)        NOT NULL,
+  hash            CHAR(64)        NOT NULL,
+  pins            VARCHAR(1024)   NOT NULL,
+  state           VARCHAR(64),
+  confirmed       BIGINT,
+  tx_type         VARCHAR(64)     NOT NULL,
+  tx_id           CHAR(36),
+  tx_parent_type  VARCHAR(64)     NOT NULL DEFAULT '',
+  tx_parent_id    CHAR(36),
+  batch_id        CHAR(36),
+  idempotency_key VARCHAR(256),
+  reject_reason   LONGTEXT        DEFAULT (''),
+  trace_context   LONGTEXT,
+  UNIQUE INDEX messages_id (namespace_local, id),
+  UNIQUE INDEX messages_idempotency_keys (namespace, idempotency_key),
+  INDEX messages_sortorder (confirmed, created),
+  INDEX messages_topics_tag (namespace, topics(640), tag)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_bin;
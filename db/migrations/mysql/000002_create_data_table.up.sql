@@ -0,0 +1,25 @@
+CREATE TABLE data (
+  seq              BIGINT          NOT NULL AUTO_INCREMENT PRIMARY KEY,
+  id               CHAR(36)        NOT NULL,
+  validator        VARCHAR(64)     NOT NULL,
+  namespace        VARCHAR(64)     NOT NULL,
+  datatype_name    VARCHAR(64)     NOT NULL,
+  datatype_version VARCHAR(64)     NOT NULL,
+  hash             CHAR(64)        NOT NULL,
+  created          BIGINT          NOT NULL,
+  blob_hash        CHAR(64),
+  blob_public      VARCHAR(1024),
+  blob_name        VARCHAR(1024),
+  blob_path        VARCHAR(1024),
+  blob_size        BIGINT,
+  public           VARCHAR(1024),
+  value_size       BIGINT,
+  value            LONGTEXT,
+  UNIQUE INDEX data_id (namespace, id),
+  INDEX data_hash (namespace, hash),
+  INDEX data_created (namespace, created),
+  INDEX data_blobs (blob_hash),
+  INDEX data_blob_name (blob_name(768)),
+  INDEX data_blob_path (blob_path(768)),
+  INDEX data_blob_size (blob_size)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_bin;
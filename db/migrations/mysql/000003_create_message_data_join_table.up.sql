@@ -0,0 +1,10 @@
+CREATE TABLE messages_data (
+  seq         BIGINT          NOT NULL AUTO_INCREMENT PRIMARY KEY,
+  namespace   VARCHAR(64),
+  message_id  CHAR(36)        NOT NULL,
+  data_id     CHAR(36)        NOT NULL,
+  data_hash   CHAR(64)        NOT NULL,
+  data_idx    INT             NOT NULL,
+  INDEX messages_data_message (namespace, message_id),
+  INDEX messages_data_data (namespace, data_id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_bin;
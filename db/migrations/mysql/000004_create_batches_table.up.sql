@@ -0,0 +1,20 @@
+CREATE TABLE batches (
+  seq           BIGINT          NOT NULL AUTO_INCREMENT PRIMARY KEY,
+  id            CHAR(36)        NOT NULL,
+  btype         VARCHAR(64)     NOT NULL,
+  namespace     VARCHAR(64)     NOT NULL,
+  author        VARCHAR(1024)   NOT NULL,
+  "key"         VARCHAR(1024),
+  group_hash    CHAR(64),
+  hash          CHAR(64),
+  created       BIGINT          NOT NULL,
+  manifest      LONGTEXT        NOT NULL,
+  confirmed     BIGINT,
+  tx_type       VARCHAR(64)     NOT NULL,
+  tx_id         CHAR(36),
+  node_id       CHAR(36),
+  trace_context LONGTEXT,
+  UNIQUE INDEX batches_id (namespace, id),
+  INDEX batches_created (namespace, created),
+  INDEX batches_fortx (namespace, tx_id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_bin;
@@ -0,0 +1,13 @@
+CREATE TABLE transactions (
+  seq             BIGINT          NOT NULL AUTO_INCREMENT PRIMARY KEY,
+  id              CHAR(36)        NOT NULL,
+  ttype           VARCHAR(64)     NOT NULL,
+  namespace       VARCHAR(64)     NOT NULL,
+  created         BIGINT          NOT NULL,
+  blockchain_ids  VARCHAR(1024),
+  idempotency_key VARCHAR(256),
+  UNIQUE INDEX transactions_id (namespace, id),
+  UNIQUE INDEX transactions_idempotency_keys (namespace, idempotency_key),
+  INDEX transactions_created (created),
+  INDEX transactions_blockchain_ids (blockchain_ids(768))
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_bin;
@@ -0,0 +1,15 @@
+CREATE TABLE datatypes (
+  seq         BIGINT          NOT NULL AUTO_INCREMENT PRIMARY KEY,
+  id          CHAR(36)        NOT NULL,
+  message_id  CHAR(36)        NOT NULL,
+  validator   VARCHAR(64)     NOT NULL,
+  namespace   VARCHAR(64)     NOT NULL,
+  name        VARCHAR(64)     NOT NULL,
+  version     VARCHAR(64)     NOT NULL,
+  hash        CHAR(64)        NOT NULL,
+  created     BIGINT          NOT NULL,
+  value       LONGTEXT,
+  UNIQUE INDEX datatypes_id (namespace, id),
+  UNIQUE INDEX datatypes_unique (namespace, name, version),
+  INDEX datatypes_created (created)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_bin;
@@ -0,0 +1,7 @@
+CREATE TABLE offsets (
+  seq         BIGINT          NOT NULL AUTO_INCREMENT PRIMARY KEY,
+  otype       VARCHAR(64)     NOT NULL,
+  name        VARCHAR(64)     NOT NULL,
+  current     BIGINT          NOT NULL,
+  UNIQUE INDEX offsets_unique (otype, name)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_bin;
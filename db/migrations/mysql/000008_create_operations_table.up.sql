@@ -0,0 +1,20 @@
+CREATE TABLE operations (
+  seq           BIGINT          NOT NULL AUTO_INCREMENT PRIMARY KEY,
+  id            CHAR(36)        NOT NULL,
+  namespace     VARCHAR(64)     NOT NULL,
+  tx_id         CHAR(36)        NOT NULL,
+  optype        VARCHAR(64)     NOT NULL,
+  opstatus      VARCHAR(64)     NOT NULL,
+  plugin        VARCHAR(64)     NOT NULL,
+  created       BIGINT          NOT NULL,
+  updated       BIGINT,
+  error         LONGTEXT        NOT NULL,
+  input         LONGTEXT,
+  output        LONGTEXT,
+  retry_id      CHAR(36),
+  trace_context LONGTEXT,
+  UNIQUE INDEX operations_id (id),
+  INDEX operations_created (created),
+  INDEX operations_tx (tx_id),
+  INDEX operations_type_status (optype, opstatus)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_bin;
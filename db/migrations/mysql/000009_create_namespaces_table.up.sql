@@ -0,0 +1,9 @@
+CREATE TABLE namespaces (
+  seq               BIGINT          NOT NULL AUTO_INCREMENT PRIMARY KEY,
+  name              VARCHAR(64)     NOT NULL,
+  remote_name       VARCHAR(64),
+  description       VARCHAR(4096),
+  created           BIGINT          NOT NULL,
+  firefly_contracts LONGTEXT,
+  UNIQUE INDEX namespaces_name (name)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_bin;
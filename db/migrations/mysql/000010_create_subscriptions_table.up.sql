@@ -0,0 +1,13 @@
+CREATE TABLE subscriptions (
+  seq         BIGINT          NOT NULL AUTO_INCREMENT PRIMARY KEY,
+  id          CHAR(36)        NOT NULL,
+  namespace   VARCHAR(64)     NOT NULL,
+  name        VARCHAR(64)     NOT NULL,
+  transport   VARCHAR(64)     NOT NULL,
+  filters     LONGTEXT,
+  options     LONGTEXT        NOT NULL,
+  created     BIGINT          NOT NULL,
+  updated     BIGINT,
+  UNIQUE INDEX subscriptions_id (id),
+  UNIQUE INDEX subscriptions_name (namespace, name)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_bin;
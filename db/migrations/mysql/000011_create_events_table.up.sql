@@ -0,0 +1,14 @@
+CREATE TABLE events (
+  seq         BIGINT          NOT NULL AUTO_INCREMENT PRIMARY KEY,
+  id          CHAR(36)        NOT NULL,
+  etype       VARCHAR(64)     NOT NULL,
+  namespace   VARCHAR(64)     NOT NULL,
+  ref         CHAR(36),
+  cid         CHAR(36),
+  tx_id       CHAR(36),
+  topic       VARCHAR(64),
+  created     BIGINT          NOT NULL,
+  UNIQUE INDEX events_id (id),
+  INDEX events_created (created),
+  INDEX events_topic (topic)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_bin;
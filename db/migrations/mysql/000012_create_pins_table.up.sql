@@ -0,0 +1,15 @@
+CREATE TABLE pins (
+  seq         BIGINT          NOT NULL AUTO_INCREMENT PRIMARY KEY,
+  namespace   VARCHAR(64),
+  masked      BOOLEAN         NOT NULL,
+  hash        CHAR(64)        NOT NULL,
+  batch_id    CHAR(36)        NOT NULL,
+  batch_hash  VARCHAR(64),
+  idx         BIGINT          NOT NULL,
+  signer      LONGTEXT,
+  dispatched  BOOLEAN         NOT NULL,
+  created     BIGINT          NOT NULL,
+  UNIQUE INDEX pins_pin (namespace, hash, batch_id, idx),
+  INDEX pins_batch (batch_id),
+  INDEX pins_dispatched (dispatched)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_bin;
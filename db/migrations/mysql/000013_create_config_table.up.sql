@@ -0,0 +1,6 @@
+CREATE TABLE config (
+  seq           BIGINT          NOT NULL AUTO_INCREMENT PRIMARY KEY,
+  config_key    VARCHAR(512)    NOT NULL,
+  config_value  LONGTEXT        NOT NULL,
+  UNIQUE INDEX config_config_key (config_key)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_bin;
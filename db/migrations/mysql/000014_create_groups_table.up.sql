@@ -0,0 +1,10 @@
+CREATE TABLE "groups" (
+  seq             BIGINT          NOT NULL AUTO_INCREMENT PRIMARY KEY,
+  message_id      CHAR(36),
+  name            VARCHAR(64)     NOT NULL,
+  namespace       VARCHAR(64)     NOT NULL,
+  namespace_local VARCHAR(64),
+  hash            CHAR(64)        NOT NULL,
+  created         BIGINT          NOT NULL,
+  UNIQUE INDEX groups_hash (namespace_local, hash)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_bin;
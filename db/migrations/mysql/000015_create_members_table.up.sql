@@ -0,0 +1,8 @@
+CREATE TABLE members (
+  seq         BIGINT          NOT NULL AUTO_INCREMENT PRIMARY KEY,
+  group_hash  CHAR(64)        NOT NULL,
+  idx         INT             NOT NULL,
+  identity    VARCHAR(1024)   NOT NULL,
+  node_id     CHAR(36)        NOT NULL,
+  INDEX members_group (group_hash)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_bin;
@@ -0,0 +1,6 @@
+CREATE TABLE nonces (
+  seq         BIGINT          NOT NULL AUTO_INCREMENT PRIMARY KEY,
+  hash        CHAR(64)        NOT NULL,
+  nonce       BIGINT          NOT NULL,
+  INDEX nonces_hash (hash)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_bin;
@@ -0,0 +1,9 @@
+CREATE TABLE nextpins (
+  seq         BIGINT          NOT NULL AUTO_INCREMENT PRIMARY KEY,
+  namespace   VARCHAR(64),
+  context     CHAR(64)        NOT NULL,
+  identity    VARCHAR(1024)   NOT NULL,
+  hash        CHAR(64)        NOT NULL,
+  nonce       BIGINT          NOT NULL,
+  INDEX nextpins_context (namespace, context)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_bin;
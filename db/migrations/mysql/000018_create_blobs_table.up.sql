@@ -0,0 +1,12 @@
+CREATE TABLE blobs (
+  seq         BIGINT          NOT NULL AUTO_INCREMENT PRIMARY KEY,
+  namespace   VARCHAR(64)     NOT NULL,
+  hash        CHAR(64)        NOT NULL,
+  payload_ref VARCHAR(1024)   NOT NULL,
+  created     BIGINT          NOT NULL,
+  peer        VARCHAR(256)    NOT NULL,
+  size        BIGINT,
+  data_id     CHAR(36)        NOT NULL,
+  INDEX blobs_namespace_data_id (namespace, data_id),
+  INDEX blobs_payload_ref (payload_ref(768))
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_bin;
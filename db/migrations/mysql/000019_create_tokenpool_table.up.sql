@@ -0,0 +1,28 @@
+CREATE TABLE tokenpool (
+  seq              BIGINT          NOT NULL AUTO_INCREMENT PRIMARY KEY,
+  id               CHAR(36)        NOT NULL,
+  namespace        VARCHAR(64)     NOT NULL,
+  name             VARCHAR(64)     NOT NULL,
+  network_name     VARCHAR(64),
+  standard         VARCHAR(64),
+  locator          VARCHAR(1024)   NOT NULL,
+  type             VARCHAR(64)     NOT NULL,
+  decimals         INTEGER         DEFAULT 0,
+  connector        VARCHAR(64)     NOT NULL,
+  message_id       CHAR(36),
+  active           BOOLEAN,
+  created          BIGINT          NOT NULL,
+  symbol           VARCHAR(64),
+  info             LONGTEXT,
+  tx_type          VARCHAR(64)     NOT NULL,
+  tx_id            CHAR(36),
+  interface        CHAR(36),
+  interface_format VARCHAR(64)     DEFAULT '',
+  methods          LONGTEXT,
+  published        BOOLEAN         DEFAULT false,
+  plugin_data      LONGTEXT,
+  UNIQUE INDEX tokenpool_id (id),
+  UNIQUE INDEX tokenpool_name (namespace, name),
+  UNIQUE INDEX tokenpool_networkname (namespace, network_name),
+  INDEX tokenpool_fortx (namespace, tx_id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_bin;
@@ -0,0 +1,25 @@
+CREATE TABLE tokentransfer (
+  seq              BIGINT          NOT NULL AUTO_INCREMENT PRIMARY KEY,
+  type             VARCHAR(64)     NOT NULL,
+  local_id         CHAR(36)        NOT NULL,
+  pool_id          CHAR(36),
+  token_index      VARCHAR(1024),
+  uri              VARCHAR(1024),
+  connector        VARCHAR(64),
+  namespace        VARCHAR(64),
+  "key"            VARCHAR(1024),
+  from_key         VARCHAR(1024),
+  to_key           VARCHAR(1024),
+  amount           VARCHAR(65),
+  protocol_id      VARCHAR(1024)   NOT NULL,
+  message_id       CHAR(36),
+  message_hash     CHAR(64),
+  tx_type          VARCHAR(64),
+  tx_id            CHAR(36),
+  blockchain_event CHAR(36),
+  created          BIGINT          NOT NULL,
+  UNIQUE INDEX tokentransfer_id (local_id),
+  UNIQUE INDEX tokentransfer_protocolid (namespace, pool_id, protocol_id(668)),
+  INDEX tokentransfer_messageid (message_id),
+  INDEX tokentransfer_pool (pool_id, token_index(732))
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_bin;
@@ -0,0 +1,12 @@
+CREATE TABLE tokenbalance (
+  seq              BIGINT          NOT NULL AUTO_INCREMENT PRIMARY KEY,
+  pool_id          CHAR(36),
+  token_index      VARCHAR(1024),
+  uri              VARCHAR(1024),
+  connector        VARCHAR(64),
+  namespace        VARCHAR(64),
+  "key"            VARCHAR(1024)   NOT NULL,
+  balance          VARCHAR(65),
+  updated          BIGINT,
+  UNIQUE INDEX tokenbalance_pool (namespace, "key"(334), pool_id, token_index(334))
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_bin;
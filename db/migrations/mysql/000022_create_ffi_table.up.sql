@@ -0,0 +1,14 @@
+CREATE TABLE ffi (
+  seq               BIGINT          NOT NULL AUTO_INCREMENT PRIMARY KEY,
+  id                CHAR(36)        NOT NULL,
+  namespace         VARCHAR(64)     NOT NULL,
+  name              VARCHAR(1024)   NOT NULL,
+  network_name      VARCHAR(64),
+  version           VARCHAR(64)     NOT NULL,
+  description       LONGTEXT        NOT NULL,
+  message_id        CHAR(36),
+  published         BOOLEAN         DEFAULT false,
+  UNIQUE INDEX ffi_id (namespace, id),
+  UNIQUE INDEX ffi_name (namespace, name(640), version),
+  UNIQUE INDEX ffi_networkname (namespace, network_name, version)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_bin;
@@ -0,0 +1,12 @@
+CREATE TABLE ffievents (
+  seq               BIGINT          NOT NULL AUTO_INCREMENT PRIMARY KEY,
+  id                CHAR(36)        NOT NULL,
+  interface_id      CHAR(36)        NULL    ,
+  namespace         VARCHAR(64)     NOT NULL,
+  name              VARCHAR(1024)   NOT NULL,
+  pathname          VARCHAR(1024)   NOT NULL,
+  description       LONGTEXT        NOT NULL,
+  params            LONGTEXT        NOT NULL,
+  details           LONGTEXT,
+  UNIQUE INDEX ffievents_pathname (interface_id, pathname(732))
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_bin;
@@ -0,0 +1,14 @@
+CREATE TABLE contractapis (
+  seq               BIGINT          NOT NULL AUTO_INCREMENT PRIMARY KEY,
+  id                CHAR(36)        NOT NULL,
+  interface_id      CHAR(36)        NOT NULL,
+  location          LONGTEXT,
+  name              VARCHAR(64)     NOT NULL,
+  network_name      VARCHAR(64),
+  namespace         VARCHAR(64)     NOT NULL,
+  message_id        CHAR(36),
+  published         BOOLEAN         DEFAULT false,
+  UNIQUE INDEX contractapis_id (namespace, id),
+  UNIQUE INDEX contractapis_namespace_name (namespace, name),
+  UNIQUE INDEX contractapis_networkname (namespace, network_name)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_bin;
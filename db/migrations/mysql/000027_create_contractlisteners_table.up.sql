@@ -0,0 +1,18 @@
+CREATE TABLE contractlisteners (
+  seq              BIGINT          NOT NULL AUTO_INCREMENT PRIMARY KEY,
+  id               CHAR(36)        NOT NULL,
+  interface_id     CHAR(36)        NULL,
+  event            LONGTEXT        NOT NULL,
+  filters          LONGTEXT,
+  namespace        VARCHAR(64)     NOT NULL,
+  name             VARCHAR(64)     NULL,
+  backend_id       VARCHAR(1024)   NOT NULL,
+  location         LONGTEXT,
+  signature        VARCHAR(1024),
+  topic            VARCHAR(64),
+  options          LONGTEXT,
+  created          BIGINT          NOT NULL,
+  UNIQUE INDEX contractsubscriptions_name (namespace, name),
+  UNIQUE INDEX contractsubscriptions_protocolid (backend_id(768)),
+  INDEX contractlisteners_signature (signature(768))
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_bin;
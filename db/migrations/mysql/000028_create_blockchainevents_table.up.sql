@@ -0,0 +1,21 @@
+CREATE TABLE blockchainevents (
+  seq              BIGINT          NOT NULL AUTO_INCREMENT PRIMARY KEY,
+  id               CHAR(36)        NOT NULL,
+  source           VARCHAR(256)    NOT NULL,
+  namespace        VARCHAR(64)     NOT NULL,
+  name             VARCHAR(256)    NOT NULL,
+  protocol_id      VARCHAR(256)    NOT NULL,
+  listener_id      CHAR(36),
+  listener_key     CHAR(36)        AS (IFNULL(listener_id, '')) STORED,
+  output           LONGTEXT,
+  info             LONGTEXT,
+  timestamp        BIGINT          NOT NULL,
+  tx_type          VARCHAR(64),
+  tx_id            CHAR(36),
+  tx_blockchain_id VARCHAR(1024),
+  UNIQUE INDEX blockchainevents_id (id),
+  UNIQUE INDEX blockchainevents_listener_protocolid (namespace, listener_key, protocol_id),
+  INDEX blockchainevents_listener_id (listener_id),
+  INDEX blockchainevents_tx (tx_id),
+  INDEX blockchainevents_txblockchainid (tx_blockchain_id(768))
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_bin;
@@ -0,0 +1,24 @@
+CREATE TABLE tokenapproval (
+  seq              BIGINT          NOT NULL AUTO_INCREMENT PRIMARY KEY,
+  local_id         CHAR(36)        NOT NULL,
+  pool_id          CHAR(36),
+  connector        VARCHAR(64),
+  namespace        VARCHAR(64),
+  "key"            VARCHAR(1024)   NOT NULL,
+  operator_key     VARCHAR(1024)   NOT NULL,
+  approved         BOOLEAN         NOT NULL,
+  info             LONGTEXT,
+  protocol_id      VARCHAR(1024)   NOT NULL,
+  subject          VARCHAR(1024),
+  active           BOOLEAN,
+  message_id       CHAR(36),
+  message_hash     CHAR(64),
+  tx_type          VARCHAR(64),
+  tx_id            CHAR(36),
+  blockchain_event CHAR(36),
+  created          BIGINT          NOT NULL,
+  UNIQUE INDEX tokenapproval_id (local_id),
+  UNIQUE INDEX tokenapproval_protocolid (namespace, pool_id, protocol_id(668)),
+  INDEX tokenapproval_messageid (message_id),
+  INDEX tokenapproval_subject (pool_id, subject(732))
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_bin;
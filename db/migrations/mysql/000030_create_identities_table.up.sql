@@ -0,0 +1,19 @@
+CREATE TABLE identities (
+  seq                   BIGINT          NOT NULL AUTO_INCREMENT PRIMARY KEY,
+  id                    CHAR(36)        NOT NULL,
+  did                   VARCHAR(256)    NOT NULL,
+  parent                CHAR(36),
+  messages_claim        CHAR(36),
+  messages_verification CHAR(36),
+  messages_update       CHAR(36),
+  itype                 VARCHAR(64)     NOT NULL,
+  namespace             VARCHAR(64)     NOT NULL,
+  name                  VARCHAR(64)     NOT NULL,
+  description           VARCHAR(4096)   NOT NULL,
+  profile               LONGTEXT,
+  created               BIGINT          NOT NULL,
+  updated               BIGINT          NOT NULL,
+  UNIQUE INDEX identities_id (namespace, id),
+  UNIQUE INDEX identities_did (namespace, did),
+  UNIQUE INDEX identities_name (itype, namespace, name)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_bin;
@@ -0,0 +1,12 @@
+CREATE TABLE verifiers (
+  seq            BIGINT          NOT NULL AUTO_INCREMENT PRIMARY KEY,
+  hash           CHAR(64)        NOT NULL,
+  identity       CHAR(36)        NOT NULL,
+  vtype          VARCHAR(256)    NOT NULL,
+  namespace      VARCHAR(64)     NOT NULL,
+  value          LONGTEXT        NOT NULL,
+  created        BIGINT          NOT NULL,
+  UNIQUE INDEX verifiers_hash (namespace, hash),
+  UNIQUE INDEX verifiers_identity (namespace, identity),
+  UNIQUE INDEX verifiers_value (namespace, vtype, value(448))
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_bin;
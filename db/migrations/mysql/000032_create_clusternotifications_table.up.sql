@@ -0,0 +1,9 @@
+CREATE TABLE clusternotifications (
+  seq         BIGINT          NOT NULL AUTO_INCREMENT PRIMARY KEY,
+  namespace   VARCHAR(64)     NOT NULL,
+  topic       VARCHAR(64)     NOT NULL,
+  payload     LONGTEXT,
+  created     BIGINT          NOT NULL,
+  INDEX clusternotifications_topic (namespace, topic, seq),
+  INDEX clusternotifications_created (created)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_bin;
@@ -0,0 +1,16 @@
+CREATE TABLE deadletters (
+  seq               BIGINT          NOT NULL AUTO_INCREMENT PRIMARY KEY,
+  id                CHAR(36)        NOT NULL,
+  namespace         VARCHAR(64)     NOT NULL,
+  subscription_id   CHAR(36)        NOT NULL,
+  subscription_name VARCHAR(64)     NOT NULL,
+  event_id          CHAR(36)        NOT NULL,
+  event_sequence    BIGINT          NOT NULL,
+  event_type        VARCHAR(64)     NOT NULL,
+  attempts          INTEGER         NOT NULL,
+  last_error        LONGTEXT,
+  created           BIGINT          NOT NULL,
+  updated           BIGINT,
+  UNIQUE INDEX deadletters_id (namespace, id),
+  INDEX deadletters_subscription (namespace, subscription_id, event_sequence)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_bin;
@@ -0,0 +1,12 @@
+CREATE TABLE apikeys (
+  seq               BIGINT          NOT NULL AUTO_INCREMENT PRIMARY KEY,
+  id                CHAR(36)        NOT NULL,
+  namespace         VARCHAR(64)     NOT NULL,
+  name              VARCHAR(64)     NOT NULL,
+  roles             LONGTEXT,
+  key_hash          CHAR(64)        NOT NULL,
+  expires           BIGINT,
+  created           BIGINT          NOT NULL,
+  UNIQUE INDEX apikeys_id (namespace, id),
+  UNIQUE INDEX apikeys_hash (namespace, key_hash)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_bin;
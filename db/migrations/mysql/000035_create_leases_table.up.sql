@@ -0,0 +1,8 @@
+CREATE TABLE leases (
+  seq               BIGINT          NOT NULL AUTO_INCREMENT PRIMARY KEY,
+  namespace         VARCHAR(64)     NOT NULL,
+  name              VARCHAR(64)     NOT NULL,
+  holder            VARCHAR(256)    NOT NULL,
+  expires           BIGINT          NOT NULL,
+  UNIQUE INDEX leases_name (namespace, name)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_bin;
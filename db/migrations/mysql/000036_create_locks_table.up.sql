@@ -0,0 +1,3 @@
+CREATE TABLE locks (
+  name              VARCHAR(256)    NOT NULL PRIMARY KEY
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_bin;
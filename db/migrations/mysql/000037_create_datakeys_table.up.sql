@@ -0,0 +1,10 @@
+CREATE TABLE datakeys (
+  seq               BIGINT          NOT NULL AUTO_INCREMENT PRIMARY KEY,
+  id                CHAR(36)        NOT NULL,
+  key_id            VARCHAR(256)    NOT NULL,
+  wrapped           LONGTEXT        NOT NULL,
+  created           BIGINT          NOT NULL,
+  updated           BIGINT          NOT NULL,
+  UNIQUE INDEX datakeys_id (id),
+  INDEX datakeys_key_id (key_id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_bin;
@@ -0,0 +1 @@
+ALTER TABLE blockchainevents DROP COLUMN removed;
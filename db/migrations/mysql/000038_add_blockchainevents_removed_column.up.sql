@@ -0,0 +1 @@
+ALTER TABLE blockchainevents ADD COLUMN removed BOOLEAN DEFAULT false;
@@ -0,0 +1 @@
+ALTER TABLE pins ADD COLUMN invalidated BOOLEAN DEFAULT false;
@@ -0,0 +1 @@
+ALTER TABLE tokentransfer ADD COLUMN invalidated BOOLEAN DEFAULT false;
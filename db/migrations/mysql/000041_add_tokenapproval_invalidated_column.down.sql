@@ -0,0 +1 @@
+ALTER TABLE tokenapproval DROP COLUMN invalidated;
@@ -0,0 +1 @@
+ALTER TABLE tokenapproval ADD COLUMN invalidated BOOLEAN DEFAULT false;
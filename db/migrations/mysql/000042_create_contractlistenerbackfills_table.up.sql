@@ -0,0 +1,16 @@
+CREATE TABLE contractlistenerbackfills (
+  seq               BIGINT          NOT NULL AUTO_INCREMENT PRIMARY KEY,
+  id                CHAR(36)        NOT NULL,
+  namespace         VARCHAR(64)     NOT NULL,
+  listener_id       CHAR(36)        NOT NULL,
+  from_block        BIGINT          NOT NULL,
+  to_block          BIGINT          NOT NULL,
+  current_block     BIGINT,
+  status            VARCHAR(64)     NOT NULL,
+  error             LONGTEXT,
+  backend_id        VARCHAR(1024),
+  created           BIGINT          NOT NULL,
+  updated           BIGINT          NOT NULL,
+  UNIQUE INDEX contractlistenerbackfills_id (namespace, id),
+  INDEX contractlistenerbackfills_listener (namespace, listener_id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_bin;
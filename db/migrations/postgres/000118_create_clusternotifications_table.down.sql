@@ -0,0 +1,5 @@
+BEGIN;
+DROP TRIGGER IF EXISTS clusternotifications_insert ON clusternotifications;
+DROP FUNCTION IF EXISTS clusternotifications_notify;
+DROP TABLE IF EXISTS clusternotifications;
+COMMIT;
\ No newline at end of file
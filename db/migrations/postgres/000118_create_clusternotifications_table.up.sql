@@ -0,0 +1,22 @@
+BEGIN;
+CREATE TABLE clusternotifications (
+  seq         SERIAL          PRIMARY KEY,
+  namespace   VARCHAR(64)     NOT NULL,
+  topic       VARCHAR(64)     NOT NULL,
+  payload     TEXT,
+  created     BIGINT          NOT NULL
+);
+
+CREATE INDEX clusternotifications_topic ON clusternotifications(namespace,topic,seq);
+CREATE INDEX clusternotifications_created ON clusternotifications(created);
+
+CREATE OR REPLACE FUNCTION clusternotifications_notify() RETURNS TRIGGER AS $$
+BEGIN
+  PERFORM pg_notify('ff_clusternotifications', json_build_object('namespace', NEW.namespace, 'topic', NEW.topic, 'sequence', NEW.seq)::text);
+  RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER clusternotifications_insert AFTER INSERT ON clusternotifications
+  FOR EACH ROW EXECUTE PROCEDURE clusternotifications_notify();
+COMMIT;
\ No newline at end of file
@@ -0,0 +1,3 @@
+BEGIN;
+DROP TABLE IF EXISTS apikeys;
+COMMIT;
\ No newline at end of file
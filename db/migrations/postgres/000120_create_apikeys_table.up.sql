@@ -0,0 +1,15 @@
+BEGIN;
+CREATE TABLE apikeys (
+  seq               SERIAL          PRIMARY KEY,
+  id                UUID            NOT NULL,
+  namespace         VARCHAR(64)     NOT NULL,
+  name              VARCHAR(64)     NOT NULL,
+  roles             TEXT,
+  key_hash          CHAR(64)        NOT NULL,
+  expires           BIGINT,
+  created           BIGINT          NOT NULL
+);
+
+CREATE UNIQUE INDEX apikeys_id ON apikeys(namespace,id);
+CREATE UNIQUE INDEX apikeys_hash ON apikeys(namespace,key_hash);
+COMMIT;
\ No newline at end of file
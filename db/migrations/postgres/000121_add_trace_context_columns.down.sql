@@ -0,0 +1,5 @@
+BEGIN;
+ALTER TABLE messages DROP COLUMN trace_context;
+ALTER TABLE batches DROP COLUMN trace_context;
+ALTER TABLE operations DROP COLUMN trace_context;
+COMMIT;
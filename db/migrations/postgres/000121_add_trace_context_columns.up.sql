@@ -0,0 +1,5 @@
+BEGIN;
+ALTER TABLE messages ADD COLUMN trace_context TEXT;
+ALTER TABLE batches ADD COLUMN trace_context TEXT;
+ALTER TABLE operations ADD COLUMN trace_context TEXT;
+COMMIT;
@@ -0,0 +1,11 @@
+BEGIN;
+CREATE TABLE leases (
+  seq               SERIAL          PRIMARY KEY,
+  namespace         VARCHAR(64)     NOT NULL,
+  name              VARCHAR(64)     NOT NULL,
+  holder            VARCHAR(256)    NOT NULL,
+  expires           BIGINT          NOT NULL
+);
+
+CREATE UNIQUE INDEX leases_name ON leases(namespace,name);
+COMMIT;
\ No newline at end of file
@@ -0,0 +1,13 @@
+BEGIN;
+CREATE TABLE datakeys (
+  seq               SERIAL          PRIMARY KEY,
+  id                UUID            NOT NULL,
+  key_id            VARCHAR(256)    NOT NULL,
+  wrapped           TEXT            NOT NULL,
+  created           BIGINT          NOT NULL,
+  updated           BIGINT          NOT NULL
+);
+
+CREATE UNIQUE INDEX datakeys_id ON datakeys(id);
+CREATE INDEX datakeys_key_id ON datakeys(key_id);
+COMMIT;
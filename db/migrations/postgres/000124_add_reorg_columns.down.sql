@@ -0,0 +1,6 @@
+BEGIN;
+ALTER TABLE blockchainevents DROP COLUMN removed;
+ALTER TABLE pins DROP COLUMN invalidated;
+ALTER TABLE tokentransfer DROP COLUMN invalidated;
+ALTER TABLE tokenapproval DROP COLUMN invalidated;
+COMMIT;
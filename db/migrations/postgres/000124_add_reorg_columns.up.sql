@@ -0,0 +1,6 @@
+BEGIN;
+ALTER TABLE blockchainevents ADD COLUMN removed BOOLEAN DEFAULT false;
+ALTER TABLE pins ADD COLUMN invalidated BOOLEAN DEFAULT false;
+ALTER TABLE tokentransfer ADD COLUMN invalidated BOOLEAN DEFAULT false;
+ALTER TABLE tokenapproval ADD COLUMN invalidated BOOLEAN DEFAULT false;
+COMMIT;
@@ -0,0 +1,19 @@
+BEGIN;
+CREATE TABLE contractlistenerbackfills (
+  seq               SERIAL          PRIMARY KEY,
+  id                UUID            NOT NULL,
+  namespace         VARCHAR(64)     NOT NULL,
+  listener_id       UUID            NOT NULL,
+  from_block        BIGINT          NOT NULL,
+  to_block          BIGINT          NOT NULL,
+  current_block     BIGINT,
+  status            VARCHAR(64)     NOT NULL,
+  error             TEXT,
+  backend_id        VARCHAR(1024),
+  created           BIGINT          NOT NULL,
+  updated           BIGINT          NOT NULL
+);
+
+CREATE UNIQUE INDEX contractlistenerbackfills_id ON contractlistenerbackfills(namespace,id);
+CREATE INDEX contractlistenerbackfills_listener ON contractlistenerbackfills(namespace,listener_id);
+COMMIT;
@@ -0,0 +1 @@
+DROP INDEX CONCURRENTLY IF EXISTS data_value_gin;
@@ -0,0 +1,3 @@
+-- Optional index for JSON-path filters on data values. It is not applied by the migrations of the node,
+-- as building it on a large data table can take some time. CONCURRENTLY cannot run inside a transaction.
+CREATE INDEX CONCURRENTLY IF NOT EXISTS data_value_gin ON data USING GIN ((value::jsonb) jsonb_path_ops);
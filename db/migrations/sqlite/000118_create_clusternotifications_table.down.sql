@@ -0,0 +1 @@
+DROP TABLE IF EXISTS clusternotifications;
\ No newline at end of file
@@ -0,0 +1,10 @@
+CREATE TABLE clusternotifications (
+  seq         INTEGER         PRIMARY KEY AUTOINCREMENT,
+  namespace   VARCHAR(64)     NOT NULL,
+  topic       VARCHAR(64)     NOT NULL,
+  payload     TEXT,
+  created     BIGINT          NOT NULL
+);
+
+CREATE INDEX clusternotifications_topic ON clusternotifications(namespace,topic,seq);
+CREATE INDEX clusternotifications_created ON clusternotifications(created);
\ No newline at end of file
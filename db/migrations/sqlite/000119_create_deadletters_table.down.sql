@@ -0,0 +1 @@
+DROP TABLE IF EXISTS deadletters;
\ No newline at end of file
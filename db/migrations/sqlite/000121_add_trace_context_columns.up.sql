@@ -0,0 +1,3 @@
+ALTER TABLE messages ADD COLUMN trace_context TEXT;
+ALTER TABLE batches ADD COLUMN trace_context TEXT;
+ALTER TABLE operations ADD COLUMN trace_context TEXT;
@@ -0,0 +1,9 @@
+CREATE TABLE leases (
+  seq               INTEGER         PRIMARY KEY AUTOINCREMENT,
+  namespace         VARCHAR(64)     NOT NULL,
+  name              VARCHAR(64)     NOT NULL,
+  holder            VARCHAR(256)    NOT NULL,
+  expires           BIGINT          NOT NULL
+);
+
+CREATE UNIQUE INDEX leases_name ON leases(namespace,name);
\ No newline at end of file
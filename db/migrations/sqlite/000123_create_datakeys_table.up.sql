@@ -0,0 +1,11 @@
+CREATE TABLE datakeys (
+  seq               INTEGER         PRIMARY KEY AUTOINCREMENT,
+  id                UUID            NOT NULL,
+  key_id            VARCHAR(256)    NOT NULL,
+  wrapped           TEXT            NOT NULL,
+  created           BIGINT          NOT NULL,
+  updated           BIGINT          NOT NULL
+);
+
+CREATE UNIQUE INDEX datakeys_id ON datakeys(id);
+CREATE INDEX datakeys_key_id ON datakeys(key_id);
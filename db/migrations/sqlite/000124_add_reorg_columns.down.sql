@@ -0,0 +1,4 @@
+ALTER TABLE blockchainevents DROP COLUMN removed;
+ALTER TABLE pins DROP COLUMN invalidated;
+ALTER TABLE tokentransfer DROP COLUMN invalidated;
+ALTER TABLE tokenapproval DROP COLUMN invalidated;
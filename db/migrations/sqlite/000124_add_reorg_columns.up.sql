@@ -0,0 +1,4 @@
+ALTER TABLE blockchainevents ADD COLUMN removed BOOLEAN DEFAULT false;
+ALTER TABLE pins ADD COLUMN invalidated BOOLEAN DEFAULT false;
+ALTER TABLE tokentransfer ADD COLUMN invalidated BOOLEAN DEFAULT false;
+ALTER TABLE tokenapproval ADD COLUMN invalidated BOOLEAN DEFAULT false;
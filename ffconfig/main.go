@@ -18,9 +18,11 @@ package main
 
 import (
 	"fmt"
+	"io"
 	"os"
 
 	"github.com/hyperledger/firefly/ffconfig/migrate"
+	"github.com/hyperledger/firefly/ffconfig/nsarchive"
 	"github.com/spf13/cobra"
 )
 
@@ -53,10 +55,56 @@ var migrateCommand = &cobra.Command{
 	},
 }
 
+var exportCommand = &cobra.Command{
+	Use:   "export",
+	Short: "Export the definitions in a namespace to an archive, using the SPI of a FireFly node",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if archiveFile == "" {
+			return archiveClient().Export(cmd.Context(), cmd.OutOrStdout())
+		}
+		f, err := os.OpenFile(archiveFile, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0600)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		return archiveClient().Export(cmd.Context(), f)
+	},
+}
+
+var importCommand = &cobra.Command{
+	Use:   "import",
+	Short: "Import an archive into a namespace, using the SPI of a FireFly node",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		var r io.Reader = cmd.InOrStdin()
+		if archiveFile != "" {
+			f, err := os.Open(archiveFile)
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+			r = f
+		}
+		result, err := archiveClient().Import(cmd.Context(), r, dryRun)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(cmd.OutOrStdout(), string(result))
+		return nil
+	},
+}
+
+func archiveClient() *nsarchive.Client {
+	return &nsarchive.Client{URL: spiURL, Namespace: namespace}
+}
+
 var cfgFile string
 var outFile string
 var fromVersion string
 var toVersion string
+var spiURL string
+var namespace string
+var archiveFile string
+var dryRun bool
 
 func init() {
 	rootCmd.PersistentFlags().StringVarP(&cfgFile, "config", "f", "firefly.core.yml", "config file")
@@ -64,6 +112,14 @@ func init() {
 	migrateCommand.PersistentFlags().StringVar(&fromVersion, "from", "", "from version (optional, such as 1.0.0)")
 	migrateCommand.PersistentFlags().StringVar(&toVersion, "to", "", "to version (optional, such as 1.1.0)")
 	rootCmd.AddCommand(migrateCommand)
+	for _, cmd := range []*cobra.Command{exportCommand, importCommand} {
+		cmd.PersistentFlags().StringVarP(&spiURL, "url", "u", "http://127.0.0.1:5001", "URL of the SPI of the FireFly node")
+		cmd.PersistentFlags().StringVarP(&namespace, "namespace", "n", "default", "namespace")
+		rootCmd.AddCommand(cmd)
+	}
+	exportCommand.PersistentFlags().StringVarP(&archiveFile, "out", "o", "", "archive file to write (if unspecified, write to stdout)")
+	importCommand.PersistentFlags().StringVarP(&archiveFile, "in", "i", "", "archive file to read (if unspecified, read from stdin)")
+	importCommand.PersistentFlags().BoolVar(&dryRun, "dry-run", false, "verify the archive and report what would be imported, without writing anything")
 }
 
 func main() {
@@ -17,9 +17,14 @@
 package main
 
 import (
+	"bytes"
 	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"os/exec"
+	"path/filepath"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -104,3 +109,86 @@ func TestConfigMigrateCmdBadVersion(t *testing.T) {
 	assert.Error(t, err)
 	assert.Regexp(t, "bad 'from' version", err)
 }
+
+func newTestSPI(t *testing.T) string {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/spi/v1/namespaces/ns1/export":
+			w.Write([]byte("archive"))
+		case "/spi/v1/namespaces/ns1/import":
+			f, _, err := r.FormFile("file")
+			assert.NoError(t, err)
+			b, _ := io.ReadAll(f)
+			assert.Equal(t, "archive", string(b))
+			fmt.Fprintf(w, `{"source":"ns1","dryRun":%s}`, r.FormValue("dryrun"))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(server.Close)
+	t.Cleanup(func() {
+		archiveFile = ""
+		dryRun = false
+		rootCmd.SetArgs([]string{})
+		rootCmd.SetIn(nil)
+		rootCmd.SetOut(nil)
+	})
+	return server.URL
+}
+
+func TestNamespaceExportImportFile(t *testing.T) {
+	url := newTestSPI(t)
+	archive := filepath.Join(t.TempDir(), "ns1.ffns.gz")
+
+	rootCmd.SetArgs([]string{"export", "-u", url, "-n", "ns1", "-o", archive})
+	err := rootCmd.Execute()
+	assert.NoError(t, err)
+
+	var out bytes.Buffer
+	rootCmd.SetOut(&out)
+	rootCmd.SetArgs([]string{"import", "-u", url, "-n", "ns1", "-i", archive, "--dry-run"})
+	err = rootCmd.Execute()
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"source":"ns1","dryRun":true}`, out.String())
+}
+
+func TestNamespaceExportImportStdio(t *testing.T) {
+	url := newTestSPI(t)
+
+	var archive bytes.Buffer
+	rootCmd.SetOut(&archive)
+	rootCmd.SetArgs([]string{"export", "-u", url, "-n", "ns1"})
+	err := rootCmd.Execute()
+	assert.NoError(t, err)
+	assert.Equal(t, "archive", archive.String())
+
+	var out bytes.Buffer
+	rootCmd.SetIn(&archive)
+	rootCmd.SetOut(&out)
+	rootCmd.SetArgs([]string{"import", "-u", url, "-n", "ns1"})
+	err = rootCmd.Execute()
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"source":"ns1","dryRun":false}`, out.String())
+}
+
+func TestNamespaceExportBadFile(t *testing.T) {
+	url := newTestSPI(t)
+	rootCmd.SetArgs([]string{"export", "-u", url, "-n", "ns1", "-o", t.TempDir()})
+	err := rootCmd.Execute()
+	assert.Regexp(t, "is a directory", err)
+}
+
+func TestNamespaceImportMissingFile(t *testing.T) {
+	url := newTestSPI(t)
+	rootCmd.SetArgs([]string{"import", "-u", url, "-n", "ns1", "-i", filepath.Join(t.TempDir(), "missing")})
+	err := rootCmd.Execute()
+	assert.Regexp(t, "no such file or directory", err)
+}
+
+func TestNamespaceImportFail(t *testing.T) {
+	url := newTestSPI(t)
+	rootCmd.SetIn(bytes.NewReader([]byte("archive")))
+	rootCmd.SetArgs([]string{"import", "-u", url, "-n", "ns2"})
+	err := rootCmd.Execute()
+	assert.Regexp(t, "failed with status 404", err)
+}
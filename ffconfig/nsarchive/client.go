@@ -0,0 +1,95 @@
+// Copyright © 2024 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nsarchive
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// Client calls the namespace export and import routes of the FireFly SPI
+type Client struct {
+	URL        string
+	Namespace  string
+	HTTPClient *http.Client
+}
+
+func (c *Client) namespaceURL(path string) string {
+	return fmt.Sprintf("%s/spi/v1/namespaces/%s/%s", strings.TrimSuffix(c.URL, "/"), url.PathEscape(c.Namespace), path)
+}
+
+func (c *Client) do(req *http.Request) (*http.Response, error) {
+	httpClient := c.HTTPClient
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	res, err := httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if res.StatusCode != http.StatusOK {
+		defer res.Body.Close()
+		body, _ := io.ReadAll(res.Body)
+		return nil, fmt.Errorf("%s %s failed with status %d: %s", req.Method, req.URL, res.StatusCode, body)
+	}
+	return res, nil
+}
+
+// Export streams the archive of the namespace to w
+func (c *Client) Export(ctx context.Context, w io.Writer) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.namespaceURL("export"), nil)
+	if err != nil {
+		return err
+	}
+	res, err := c.do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	_, err = io.Copy(w, res.Body)
+	return err
+}
+
+// Import uploads an archive into the namespace, and returns the JSON result
+func (c *Client) Import(ctx context.Context, r io.Reader, dryRun bool) ([]byte, error) {
+	var body bytes.Buffer
+	// Writes to the buffer cannot fail, other than copying from r
+	mw := multipart.NewWriter(&body)
+	_ = mw.WriteField("dryrun", fmt.Sprintf("%t", dryRun))
+	fw, _ := mw.CreateFormFile("file", "archive.ffns.gz")
+	if _, err := io.Copy(fw, r); err != nil {
+		return nil, err
+	}
+	mw.Close()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.namespaceURL("import"), &body)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+	res, err := c.do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	return io.ReadAll(res.Body)
+}
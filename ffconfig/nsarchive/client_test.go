@@ -0,0 +1,111 @@
+// Copyright © 2024 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nsarchive
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestClient(t *testing.T, handler http.HandlerFunc) *Client {
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+	return &Client{URL: server.URL + "/", Namespace: "ns1"}
+}
+
+type failingReader struct{}
+
+func (failingReader) Read(p []byte) (int, error) {
+	return 0, fmt.Errorf("pop")
+}
+
+func TestExport(t *testing.T) {
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodGet, r.Method)
+		assert.Equal(t, "/spi/v1/namespaces/ns1/export", r.URL.Path)
+		w.Write([]byte("archive"))
+	})
+	var b bytes.Buffer
+	err := c.Export(context.Background(), &b)
+	assert.NoError(t, err)
+	assert.Equal(t, "archive", b.String())
+}
+
+func TestExportStatusFail(t *testing.T) {
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`{"error":"FF10109"}`))
+	})
+	err := c.Export(context.Background(), io.Discard)
+	assert.Regexp(t, "failed with status 404.*FF10109", err)
+}
+
+func TestExportRequestFail(t *testing.T) {
+	c := &Client{URL: "://bad", Namespace: "ns1"}
+	err := c.Export(context.Background(), io.Discard)
+	assert.Error(t, err)
+}
+
+func TestExportConnectFail(t *testing.T) {
+	c := newTestClient(t, nil)
+	c.URL = "http://localhost:0"
+	err := c.Export(context.Background(), io.Discard)
+	assert.Error(t, err)
+}
+
+func TestImport(t *testing.T) {
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "/spi/v1/namespaces/ns1/import", r.URL.Path)
+		assert.Equal(t, "true", r.FormValue("dryrun"))
+		f, _, err := r.FormFile("file")
+		assert.NoError(t, err)
+		b, _ := io.ReadAll(f)
+		assert.Equal(t, "archive", string(b))
+		w.Write([]byte(`{"source":"ns2"}`))
+	})
+	result, err := c.Import(context.Background(), bytes.NewReader([]byte("archive")), true)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"source":"ns2"}`, string(result))
+}
+
+func TestImportStatusFail(t *testing.T) {
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+	})
+	_, err := c.Import(context.Background(), bytes.NewReader([]byte("archive")), false)
+	assert.Regexp(t, "failed with status 400", err)
+}
+
+func TestImportReadFail(t *testing.T) {
+	c := &Client{URL: "http://localhost:0", Namespace: "ns1"}
+	_, err := c.Import(context.Background(), failingReader{}, false)
+	assert.Regexp(t, "pop", err)
+}
+
+func TestImportRequestFail(t *testing.T) {
+	c := &Client{URL: "://bad", Namespace: "ns1"}
+	_, err := c.Import(context.Background(), bytes.NewReader([]byte("archive")), false)
+	assert.Error(t, err)
+}
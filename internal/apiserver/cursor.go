@@ -0,0 +1,187 @@
+// Copyright © 2024 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package apiserver
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"github.com/hyperledger/firefly-common/pkg/ffapi"
+	"github.com/hyperledger/firefly-common/pkg/i18n"
+	"github.com/hyperledger/firefly/internal/coremsgs"
+	"github.com/hyperledger/firefly/pkg/core"
+)
+
+const (
+	cursorParam       = "cursor"
+	ndjsonContentType = "application/x-ndjson"
+)
+
+// pageCursor is the position after the last record of a page, which is passed to the client as an opaque token
+type pageCursor struct {
+	Sequence int64 `json:"sequence"`
+}
+
+type cursorResults struct {
+	Count int64       `json:"count"`
+	Items interface{} `json:"items"`
+	Next  string      `json:"next,omitempty"`
+}
+
+func (c *pageCursor) String() string {
+	b, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func parseCursor(ctx context.Context, token string) (*pageCursor, error) {
+	var c pageCursor
+	b, err := base64.RawURLEncoding.DecodeString(token)
+	if err == nil {
+		err = json.Unmarshal(b, &c)
+	}
+	if err != nil {
+		return nil, i18n.NewError(ctx, coremsgs.MsgInvalidCursor, token)
+	}
+	return &c, nil
+}
+
+func acceptsNDJSON(req *http.Request) bool {
+	return strings.Contains(req.Header.Get("Accept"), ndjsonContentType)
+}
+
+// cursorQuery pages through a locally sequenced collection with a condition on the sequence, rather than with
+// a skip. Each page is then read from the index on the sequence however deep it is, and records that arrive
+// while paging do not move records between pages.
+type cursorQuery struct {
+	r          *ffapi.APIRequest
+	cr         *coreRequest
+	handler    func(r *ffapi.APIRequest, cr *coreRequest) (output interface{}, err error)
+	descending bool
+	limit      uint64
+}
+
+func cursorHandler(r *ffapi.APIRequest, cr *coreRequest, handler func(r *ffapi.APIRequest, cr *coreRequest) (output interface{}, err error)) (output interface{}, err error) {
+	token, paging := r.QP[cursorParam]
+	streaming := acceptsNDJSON(r.Req)
+	if !paging && !streaming {
+		return handler(r, cr)
+	}
+
+	fi, err := r.Filter.Finalize()
+	if err != nil {
+		return nil, err
+	}
+	if fi.Skip > 0 {
+		return nil, i18n.NewError(cr.ctx, coremsgs.MsgCursorWithSkip)
+	}
+	q := &cursorQuery{
+		r:          r,
+		cr:         cr,
+		handler:    handler,
+		descending: true, // the default sort of every locally sequenced collection
+		limit:      fi.Limit,
+	}
+	switch {
+	case len(fi.Sort) == 0:
+	case len(fi.Sort) == 1 && fi.Sort[0].Field == "sequence":
+		q.descending = fi.Sort[0].Descending
+	default:
+		return nil, i18n.NewError(cr.ctx, coremsgs.MsgCursorSortUnsupported)
+	}
+
+	var after *pageCursor
+	if token != "" {
+		if after, err = parseCursor(cr.ctx, token); err != nil {
+			return nil, err
+		}
+	}
+	// The first page is read before responding, so that errors in the query are returned with their status
+	items, next, err := q.page(after)
+	if err != nil {
+		return nil, err
+	}
+	if streaming {
+		return q.stream(items, next), nil
+	}
+	results := &cursorResults{
+		Count: int64(items.Len()),
+		Items: items.Interface(),
+	}
+	if next != nil {
+		results.Next = next.String()
+	}
+	return results, nil
+}
+
+// page reads the page after a cursor, and returns the cursor for the following page if there might be one
+func (q *cursorQuery) page(after *pageCursor) (items reflect.Value, next *pageCursor, err error) {
+	// Each page has its own copy of the filter, as handlers can add conditions to it
+	r := *q.r
+	fb := q.r.Filter.Builder()
+	conditions := q.r.Filter.GetConditions()
+	if after != nil {
+		condition := fb.Gt("sequence", after.Sequence)
+		if q.descending {
+			condition = fb.Lt("sequence", after.Sequence)
+		}
+		conditions = append(conditions, condition)
+	}
+	r.Filter = fb.And(conditions...)
+	output, err := q.handler(&r, q.cr)
+	if err != nil {
+		return items, nil, err
+	}
+	if res, ok := output.(*ffapi.FilterResultsWithCount); ok {
+		output = res.Items
+	}
+	items = reflect.ValueOf(output)
+	if q.limit > 0 && uint64(items.Len()) >= q.limit {
+		last := items.Index(items.Len() - 1).Interface().(core.LocallySequenced)
+		next = &pageCursor{Sequence: last.LocalSequence()}
+	}
+	return items, next, nil
+}
+
+// stream writes every page to a pipe as newline delimited JSON, which is copied to the response as it is written.
+// If reading a later page fails, the response ends with the error. The request quota is held until the writer
+// closes, as the pages are read after the handler has returned.
+func (q *cursorQuery) stream(items reflect.Value, next *pageCursor) io.ReadCloser {
+	q.r.ResponseHeaders.Set("Content-Type", ndjsonContentType)
+	release := q.cr.quota.takeOver()
+	pr, pw := io.Pipe()
+	go func() {
+		defer release()
+		enc := json.NewEncoder(pw)
+		var err error
+		for {
+			for i := 0; err == nil && i < items.Len(); i++ {
+				err = enc.Encode(items.Index(i).Interface())
+			}
+			if err != nil || next == nil {
+				break
+			}
+			items, next, err = q.page(next)
+		}
+		pw.CloseWithError(err)
+	}()
+	return pr
+}
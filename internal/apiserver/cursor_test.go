@@ -0,0 +1,205 @@
+// Copyright © 2024 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package apiserver
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/firefly-common/pkg/ffapi"
+	"github.com/hyperledger/firefly-common/pkg/fftypes"
+	"github.com/hyperledger/firefly/mocks/assetmocks"
+	"github.com/hyperledger/firefly/pkg/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func testCursorEvents(sequences ...int64) []*core.Event {
+	events := make([]*core.Event, len(sequences))
+	for i, seq := range sequences {
+		events[i] = &core.Event{ID: fftypes.NewUUID(), Sequence: seq}
+	}
+	return events
+}
+
+func filterMatches(expected string) interface{} {
+	return mock.MatchedBy(func(filter ffapi.AndFilter) bool {
+		f, _ := filter.Finalize()
+		return strings.TrimSpace(f.String()) == expected
+	})
+}
+
+func TestGetEventsCursorPages(t *testing.T) {
+	o, r := newTestAPIServer()
+	o.On("Authorize", mock.Anything, mock.Anything).Return(nil)
+	o.On("GetEvents", mock.Anything, filterMatches("( type == 'message_confirmed' ) limit=2")).
+		Return(testCursorEvents(12, 11), nil, nil)
+	o.On("GetEvents", mock.Anything, filterMatches("( type == 'message_confirmed' ) && ( sequence << 11 ) limit=2")).
+		Return(testCursorEvents(9), nil, nil)
+
+	req := httptest.NewRequest("GET", "/api/v1/namespaces/mynamespace/events?type=message_confirmed&limit=2&cursor=", nil)
+	res := httptest.NewRecorder()
+	r.ServeHTTP(res, req)
+	assert.Equal(t, 200, res.Result().StatusCode)
+	var page struct {
+		Count int64         `json:"count"`
+		Items []*core.Event `json:"items"`
+		Next  string        `json:"next"`
+	}
+	err := json.NewDecoder(res.Body).Decode(&page)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), page.Count)
+	assert.Len(t, page.Items, 2)
+	assert.NotEmpty(t, page.Next)
+
+	req = httptest.NewRequest("GET", "/api/v1/namespaces/mynamespace/events?type=message_confirmed&limit=2&cursor="+page.Next, nil)
+	res = httptest.NewRecorder()
+	r.ServeHTTP(res, req)
+	assert.Equal(t, 200, res.Result().StatusCode)
+	page.Next = ""
+	err = json.NewDecoder(res.Body).Decode(&page)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), page.Count)
+	assert.Empty(t, page.Next)
+
+	o.AssertExpectations(t)
+}
+
+func TestGetEventsCursorAscendingWithCount(t *testing.T) {
+	o, r := newTestAPIServer()
+	o.On("Authorize", mock.Anything, mock.Anything).Return(nil)
+	total := int64(100)
+	o.On("GetEventsWithReferences", mock.Anything, filterMatches("( sequence >> 5 ) sort=sequence limit=1 count=true")).
+		Return([]*core.EnrichedEvent{{Event: core.Event{Sequence: 6}}}, &ffapi.FilterResult{TotalCount: &total}, nil)
+
+	cursor := (&pageCursor{Sequence: 5}).String()
+	req := httptest.NewRequest("GET", "/api/v1/namespaces/mynamespace/events?fetchreferences&sort=sequence&limit=1&count&cursor="+cursor, nil)
+	res := httptest.NewRecorder()
+	r.ServeHTTP(res, req)
+	assert.Equal(t, 200, res.Result().StatusCode)
+	var page cursorResults
+	err := json.NewDecoder(res.Body).Decode(&page)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), page.Count)
+	next, err := parseCursor(context.Background(), page.Next)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(6), next.Sequence)
+}
+
+func TestGetTokenTransfersNDJSON(t *testing.T) {
+	o, r := newTestAPIServer()
+	o.On("Authorize", mock.Anything, mock.Anything).Return(nil)
+	mam := &assetmocks.Manager{}
+	o.On("Assets").Return(mam)
+	mam.On("GetTokenTransfers", mock.Anything, filterMatches("( ( from == '0x1' ) || ( to == '0x1' ) ) limit=2")).
+		Return([]*core.TokenTransfer{{Sequence: 4}, {Sequence: 3}}, nil, nil)
+	mam.On("GetTokenTransfers", mock.Anything, filterMatches("( sequence << 3 ) && ( ( from == '0x1' ) || ( to == '0x1' ) ) limit=2")).
+		Return([]*core.TokenTransfer{{Sequence: 2}, {Sequence: 1}}, nil, nil)
+	mam.On("GetTokenTransfers", mock.Anything, filterMatches("( sequence << 1 ) && ( ( from == '0x1' ) || ( to == '0x1' ) ) limit=2")).
+		Return([]*core.TokenTransfer{}, nil, nil)
+
+	req := httptest.NewRequest("GET", "/api/v1/namespaces/ns1/tokens/transfers?fromOrTo=0x1&limit=2", nil)
+	req.Header.Set("Accept", ndjsonContentType)
+	res := httptest.NewRecorder()
+	r.ServeHTTP(res, req)
+	assert.Equal(t, 200, res.Result().StatusCode)
+	assert.Equal(t, ndjsonContentType, res.Header().Get("Content-Type"))
+
+	lines := 0
+	scanner := bufio.NewScanner(res.Body)
+	for scanner.Scan() {
+		var transfer core.TokenTransfer
+		err := json.Unmarshal(scanner.Bytes(), &transfer)
+		assert.NoError(t, err)
+		lines++
+	}
+	assert.Equal(t, 4, lines)
+	mam.AssertExpectations(t)
+}
+
+func TestNDJSONHoldsQuotaUntilStreamEnds(t *testing.T) {
+	mgr, o, as := newTestServer()
+	o.ExpectedCalls = nil
+	released := make(chan struct{})
+	o.On("Authorize", mock.Anything, mock.Anything).Return(nil)
+	o.On("AcquireRequestQuota", mock.Anything, mock.Anything).Return(func() { close(released) }, time.Duration(0), nil)
+	o.On("GetBlockchainEvents", mock.Anything, filterMatches("limit=1")).
+		Return([]*core.BlockchainEvent{{Sequence: 2}}, nil, nil)
+	o.On("GetBlockchainEvents", mock.Anything, filterMatches("( sequence << 2 ) limit=1")).
+		Run(func(args mock.Arguments) {
+			// Later pages are read after the handler has returned, while the quota is still held
+			select {
+			case <-released:
+				assert.Fail(t, "quota released before the stream ended")
+			default:
+			}
+		}).
+		Return([]*core.BlockchainEvent{}, nil, nil)
+	r := as.createMuxRouter(context.Background(), mgr)
+
+	req := httptest.NewRequest("GET", "/api/v1/namespaces/ns1/blockchainevents?limit=1", nil)
+	req.Header.Set("Accept", ndjsonContentType)
+	res := httptest.NewRecorder()
+	r.ServeHTTP(res, req)
+	assert.Equal(t, 200, res.Result().StatusCode)
+	<-released
+
+	o.AssertExpectations(t)
+}
+
+func TestGetBlockchainEventsNDJSONFailLaterPage(t *testing.T) {
+	o, r := newTestAPIServer()
+	o.On("Authorize", mock.Anything, mock.Anything).Return(nil)
+	o.On("GetBlockchainEvents", mock.Anything, filterMatches("limit=1")).
+		Return([]*core.BlockchainEvent{{Sequence: 2}}, nil, nil)
+	o.On("GetBlockchainEvents", mock.Anything, filterMatches("( sequence << 2 ) limit=1")).
+		Return(nil, nil, fmt.Errorf("pop"))
+
+	req := httptest.NewRequest("GET", "/api/v1/namespaces/ns1/blockchainevents?limit=1", nil)
+	req.Header.Set("Accept", ndjsonContentType)
+	res := httptest.NewRecorder()
+	r.ServeHTTP(res, req)
+	assert.Regexp(t, "pop", res.Body.String())
+}
+
+func TestGetMsgsCursorErrors(t *testing.T) {
+	o, r := newTestAPIServer()
+	o.On("Authorize", mock.Anything, mock.Anything).Return(nil)
+	o.On("GetMessages", mock.Anything, mock.Anything).Return(nil, nil, fmt.Errorf("pop"))
+
+	for query, expected := range map[string]string{
+		"sort=created&cursor=":        "FF10516",
+		"sort=sequence,id&cursor=":    "FF10516",
+		"skip=10&cursor=":             "FF10517",
+		"cursor=!!!":                  "FF10515",
+		"cursor=bm90IGpzb24":          "FF10515",
+		"sequence=notanumber&cursor=": "FF00",
+		"cursor=":                     "pop",
+	} {
+		req := httptest.NewRequest("GET", "/api/v1/namespaces/ns1/messages?"+query, nil)
+		res := httptest.NewRecorder()
+		r.ServeHTTP(res, req)
+		assert.NotEqual(t, 200, res.Result().StatusCode, query)
+		assert.True(t, strings.Contains(res.Body.String(), expected), "%s: %s", query, res.Body.String())
+	}
+}
@@ -33,6 +33,7 @@ var getMsgs = &ffapi.Route{
 	PathParams: nil,
 	QueryParams: []*ffapi.QueryParam{
 		{Name: "fetchdata", IsBool: true, Description: coremsgs.APIFetchDataDesc},
+		{Name: cursorParam, Description: coremsgs.APIParamsCursor},
 	},
 	FilterFactory:   database.MessageQueryFactory,
 	Description:     coremsgs.APIEndpointsGetMsgs,
@@ -40,6 +41,7 @@ var getMsgs = &ffapi.Route{
 	JSONOutputValue: func() interface{} { return []*core.Message{} },
 	JSONOutputCodes: []int{http.StatusOK},
 	Extensions: &coreExtensions{
+		CursorPaging: true,
 		CoreJSONHandler: func(r *ffapi.APIRequest, cr *coreRequest) (output interface{}, err error) {
 			if strings.EqualFold(r.QP["fetchdata"], "true") {
 				return r.FilterResult(cr.or.GetMessagesWithData(cr.ctx, r.Filter))
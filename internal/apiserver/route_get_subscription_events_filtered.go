@@ -38,6 +38,7 @@ var getSubscriptionEventsFiltered = &ffapi.Route{
 	QueryParams: []*ffapi.QueryParam{
 		{Name: "startsequence", IsBool: false, Description: coremsgs.APISubscriptionStartSequenceID},
 		{Name: "endsequence", IsBool: false, Description: coremsgs.APISubscriptionEndSequenceID},
+		{Name: "expression", IsBool: false, Description: coremsgs.APISubscriptionExpression},
 	},
 	FilterFactory:   database.EventQueryFactory,
 	Description:     coremsgs.APIEndpointsGetSubscriptionEventsFiltered,
@@ -68,6 +69,13 @@ var getSubscriptionEventsFiltered = &ffapi.Route{
 				endSeq = -1
 			}
 
+			if expression := r.QP["expression"]; expression != "" && subscription != nil {
+				if subscription.Filter.Expression != "" {
+					expression = fmt.Sprintf("(%s) && (%s)", subscription.Filter.Expression, expression)
+				}
+				subscription.Filter.Expression = expression
+			}
+
 			return r.FilterResult(cr.or.GetSubscriptionEventsHistorical(cr.ctx, subscription, r.Filter, startSeq, endSeq))
 		},
 	},
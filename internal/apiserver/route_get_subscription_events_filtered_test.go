@@ -82,4 +82,41 @@ func TestGetSubscriptionEventsFilteredNoSequenceIDsProvided(t *testing.T) {
 
 	r.ServeHTTP(res, req)
 	assert.Equal(t, 200, res.Result().StatusCode)
-}
\ No newline at end of file
+}
+func TestGetSubscriptionEventsFilteredExpression(t *testing.T) {
+	o, r := newTestAPIServer()
+	o.On("Authorize", mock.Anything, mock.Anything).Return(nil)
+	req := httptest.NewRequest("GET", "/api/v1/namespaces/mynamespace/subscriptions/abcd12345/events?expression=event.topic+%3D%3D+%27topic1%27", nil)
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	res := httptest.NewRecorder()
+	o.On("GetSubscriptionByID", mock.Anything, "abcd12345").
+		Return(&core.Subscription{
+			Filter: core.SubscriptionFilter{
+				Expression: "event.type == 'message_confirmed'",
+			},
+		}, nil)
+	o.On("GetSubscriptionEventsHistorical", mock.Anything, mock.MatchedBy(func(sub *core.Subscription) bool {
+		return sub.Filter.Expression == "(event.type == 'message_confirmed') && (event.topic == 'topic1')"
+	}), mock.Anything, -1, -1).
+		Return([]*core.EnrichedEvent{}, nil, nil)
+
+	r.ServeHTTP(res, req)
+	assert.Equal(t, 200, res.Result().StatusCode)
+}
+
+func TestGetSubscriptionEventsFilteredExpressionNoSubscriptionExpression(t *testing.T) {
+	o, r := newTestAPIServer()
+	o.On("Authorize", mock.Anything, mock.Anything).Return(nil)
+	req := httptest.NewRequest("GET", "/api/v1/namespaces/mynamespace/subscriptions/abcd12345/events?expression=event.topic+%3D%3D+%27topic1%27", nil)
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	res := httptest.NewRecorder()
+	o.On("GetSubscriptionByID", mock.Anything, "abcd12345").
+		Return(&core.Subscription{}, nil)
+	o.On("GetSubscriptionEventsHistorical", mock.Anything, mock.MatchedBy(func(sub *core.Subscription) bool {
+		return sub.Filter.Expression == "event.topic == 'topic1'"
+	}), mock.Anything, -1, -1).
+		Return([]*core.EnrichedEvent{}, nil, nil)
+
+	r.ServeHTTP(res, req)
+	assert.Equal(t, 200, res.Result().StatusCode)
+}
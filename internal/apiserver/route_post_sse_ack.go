@@ -0,0 +1,47 @@
+// Copyright © 2024 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package apiserver
+
+import (
+	"net/http"
+
+	"github.com/hyperledger/firefly-common/pkg/ffapi"
+	"github.com/hyperledger/firefly/internal/coremsgs"
+	"github.com/hyperledger/firefly/internal/events/eifactory"
+	"github.com/hyperledger/firefly/internal/events/sse"
+	"github.com/hyperledger/firefly/pkg/core"
+)
+
+var postSSEAck = &ffapi.Route{
+	Name:   "postSSEAck",
+	Path:   "sse/{connid}/ack",
+	Method: http.MethodPost,
+	PathParams: []*ffapi.PathParam{
+		{Name: "connid", Description: coremsgs.APIParamsSSEConnectionID},
+	},
+	QueryParams:     nil,
+	Description:     coremsgs.APIEndpointsPostSSEAck,
+	JSONInputValue:  func() interface{} { return &core.SSEAck{} },
+	JSONOutputValue: nil,
+	JSONOutputCodes: []int{http.StatusNoContent},
+	Extensions: &coreExtensions{
+		CoreJSONHandler: func(r *ffapi.APIRequest, cr *coreRequest) (output interface{}, err error) {
+			ei, _ := eifactory.GetPlugin(cr.ctx, "sse")
+			return nil, ei.(*sse.SSE).Ack(cr.ctx, cr.or.GetNamespace(cr.ctx).Name, r.PP["connid"], r.Input.(*core.SSEAck))
+		},
+	},
+}
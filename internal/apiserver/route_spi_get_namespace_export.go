@@ -0,0 +1,44 @@
+// Copyright © 2024 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package apiserver
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/hyperledger/firefly-common/pkg/ffapi"
+	"github.com/hyperledger/firefly/internal/coremsgs"
+)
+
+var spiGetNamespaceExport = &ffapi.Route{
+	Name:            "spiGetNamespaceExport",
+	Path:            "export",
+	Method:          http.MethodGet,
+	PathParams:      nil,
+	QueryParams:     nil,
+	Description:     coremsgs.APIEndpointsSPIGetNamespaceExport,
+	JSONInputValue:  nil,
+	JSONOutputValue: func() interface{} { return []byte{} },
+	JSONOutputCodes: []int{http.StatusOK},
+	Extensions: &coreExtensions{
+		CoreJSONHandler: func(r *ffapi.APIRequest, cr *coreRequest) (output interface{}, err error) {
+			r.ResponseHeaders.Set("Content-Type", "application/gzip")
+			r.ResponseHeaders.Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s.ffns.gz"`, r.PP["ns"]))
+			return cr.or.ExportNamespace(cr.ctx), nil
+		},
+	},
+}
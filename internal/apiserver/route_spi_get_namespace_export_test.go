@@ -0,0 +1,43 @@
+// Copyright © 2024 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package apiserver
+
+import (
+	"bytes"
+	"io"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestSPIGetNamespaceExport(t *testing.T) {
+	or, r := newTestSPIServer()
+	or.On("Authorize", mock.Anything, mock.Anything).Return(nil)
+	req := httptest.NewRequest("GET", "/spi/v1/namespaces/ns1/export", nil)
+	res := httptest.NewRecorder()
+
+	or.On("ExportNamespace", mock.Anything).Return(io.NopCloser(bytes.NewReader([]byte("archive"))))
+	r.ServeHTTP(res, req)
+
+	assert.Equal(t, 200, res.Result().StatusCode)
+	assert.Equal(t, "application/gzip", res.Result().Header.Get("Content-Type"))
+	assert.Equal(t, `attachment; filename="ns1.ffns.gz"`, res.Result().Header.Get("Content-Disposition"))
+	b, _ := io.ReadAll(res.Body)
+	assert.Equal(t, "archive", string(b))
+}
@@ -0,0 +1,50 @@
+// Copyright © 2024 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package apiserver
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/hyperledger/firefly/pkg/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestSPIGetStatusLeader(t *testing.T) {
+	or, r := newTestSPIServer()
+	or.On("Authorize", mock.Anything, mock.Anything).Return(nil)
+	req := httptest.NewRequest("GET", "/spi/v1/namespaces/ns1/status/leader", nil)
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	res := httptest.NewRecorder()
+
+	or.On("GetLeaderStatus", mock.Anything).Return(&core.LeaderStatus{
+		Enabled: true,
+		Replica: "replica1",
+		Leader:  true,
+		Holder:  "replica1",
+	})
+	r.ServeHTTP(res, req)
+
+	assert.Equal(t, 200, res.Result().StatusCode)
+	var status core.LeaderStatus
+	err := json.NewDecoder(res.Body).Decode(&status)
+	assert.NoError(t, err)
+	assert.True(t, status.Leader)
+	assert.Equal(t, "replica1", status.Holder)
+}
@@ -0,0 +1,72 @@
+// Copyright © 2024 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package apiserver
+
+import (
+	"bytes"
+	"encoding/json"
+	"mime/multipart"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/hyperledger/firefly/pkg/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestSPIPostNamespaceImport(t *testing.T) {
+	or, r := newTestSPIServer()
+	or.On("Authorize", mock.Anything, mock.Anything).Return(nil)
+
+	var b bytes.Buffer
+	w := multipart.NewWriter(&b)
+	err := w.WriteField("dryrun", "true")
+	assert.NoError(t, err)
+	writer, err := w.CreateFormFile("file", "ns1.ffns.gz")
+	assert.NoError(t, err)
+	writer.Write([]byte(`archive`))
+	w.Close()
+	req := httptest.NewRequest("POST", "/spi/v1/namespaces/ns1/import", &b)
+	req.Header.Set("Content-Type", w.FormDataContentType())
+	res := httptest.NewRecorder()
+
+	or.On("ImportNamespace", mock.Anything, mock.Anything, true).Return(&core.NamespaceImportResult{
+		Source: "ns2",
+		DryRun: true,
+	}, nil)
+	r.ServeHTTP(res, req)
+
+	assert.Equal(t, 200, res.Result().StatusCode)
+	var result core.NamespaceImportResult
+	err = json.NewDecoder(res.Body).Decode(&result)
+	assert.NoError(t, err)
+	assert.Equal(t, "ns2", result.Source)
+	assert.True(t, result.DryRun)
+}
+
+func TestSPIPostNamespaceImportJSON(t *testing.T) {
+	or, r := newTestSPIServer()
+	or.On("Authorize", mock.Anything, mock.Anything).Return(nil)
+	req := httptest.NewRequest("POST", "/spi/v1/namespaces/ns1/import", bytes.NewReader([]byte(`{}`)))
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	res := httptest.NewRecorder()
+
+	r.ServeHTTP(res, req)
+
+	assert.Equal(t, 400, res.Result().StatusCode)
+	assert.Regexp(t, "FF10509", res.Body.String())
+}
@@ -31,12 +31,15 @@ type coreRequest struct {
 	or         orchestrator.Orchestrator
 	ctx        context.Context
 	apiBaseURL string
+	quota      *requestQuota
 }
 
 type coreExtensions struct {
 	EnabledIf             func(or orchestrator.Orchestrator) bool
 	CoreJSONHandler       func(r *ffapi.APIRequest, cr *coreRequest) (output interface{}, err error)
 	CoreFormUploadHandler func(r *ffapi.APIRequest, cr *coreRequest) (output interface{}, err error)
+	// CursorPaging enables the cursor query parameter, and NDJSON streaming, on a locally sequenced collection
+	CursorPaging bool
 }
 
 const (
@@ -53,12 +56,16 @@ var routes = append(
 		getWebSockets,
 	}),
 	namespacedRoutes([]*ffapi.Route{
+		deleteAPIKey,
 		deleteContractAPI,
 		deleteContractInterface,
 		deleteContractListener,
 		deleteData,
 		deleteSubscription,
+		deleteSubscriptionDeadLetter,
 		deleteTokenPool,
+		getAPIKeyByID,
+		getAPIKeys,
 		getBatchByID,
 		getBatches,
 		getBlockchainEventByID,
@@ -71,6 +78,7 @@ var routes = append(
 		getContractInterface,
 		getContractInterfaceNameVersion,
 		getContractInterfaces,
+		getContractListenerBackfills,
 		getContractListenerByNameOrID,
 		getContractListeners,
 		getData,
@@ -112,6 +120,8 @@ var routes = append(
 		getSubscriptionByID,
 		getSubscriptions,
 		getSubscriptionEventsFiltered,
+		getSubscriptionDeadLetterByID,
+		getSubscriptionDeadLetters,
 		getTokenAccountPools,
 		getTokenAccounts,
 		getTokenApprovals,
@@ -129,11 +139,14 @@ var routes = append(
 		getVerifierByID,
 		getVerifiers,
 		patchUpdateIdentity,
+		postAPIKey,
 		postBatchCancel,
+		postChartAggregate,
 		postContractAPIInvoke,
 		postContractAPIPublish,
 		postContractAPIQuery,
 		postContractAPIListeners,
+		postContractListenerBackfill,
 		postContractListenerSignature,
 		postContractInterfaceGenerate,
 		postContractInterfacePublish,
@@ -158,6 +171,9 @@ var routes = append(
 		postNodesSelf,
 		postOpRetry,
 		postPinsRewind,
+		postRetentionPreview,
+		postSSEAck,
+		postSubscriptionDeadLetterReplay,
 		postTokenApproval,
 		postTokenBurn,
 		postTokenMint,
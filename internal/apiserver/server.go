@@ -18,8 +18,12 @@ package apiserver
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"math"
+	"net"
 	"net/http"
+	"strconv"
 	"strings"
 	"time"
 
@@ -34,10 +38,14 @@ import (
 	"github.com/hyperledger/firefly/internal/coreconfig"
 	"github.com/hyperledger/firefly/internal/coremsgs"
 	"github.com/hyperledger/firefly/internal/events/eifactory"
+	"github.com/hyperledger/firefly/internal/events/sse"
 	"github.com/hyperledger/firefly/internal/events/websockets"
 	"github.com/hyperledger/firefly/internal/metrics"
 	"github.com/hyperledger/firefly/internal/namespace"
 	"github.com/hyperledger/firefly/internal/orchestrator"
+	"github.com/hyperledger/firefly/internal/tracing"
+	"github.com/hyperledger/firefly/pkg/core"
+	"github.com/hyperledger/firefly/pkg/database"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 )
 
@@ -198,6 +206,66 @@ func (as *apiServer) getBaseURL(req *http.Request) string {
 	return baseURL
 }
 
+// requestIdentity returns the caller that per-identity quotas are applied to, which is the identity
+// set by the auth plugin if there is one, or otherwise the address of the client
+func requestIdentity(req *http.Request, ac *core.AuthContext) string {
+	if ac.Identity != "" {
+		return ac.Identity
+	}
+	if host, _, err := net.SplitHostPort(req.RemoteAddr); err == nil {
+		return host
+	}
+	return req.RemoteAddr
+}
+
+// requestQuota is the quota held by a request, which is released when the handler returns - unless a
+// response that is written after that (such as a stream) has taken it over
+type requestQuota struct {
+	release   func()
+	takenOver bool
+}
+
+// acquireRequestQuota applies the rate limits and concurrency quotas of the namespace to a request,
+// telling the client when to retry if it is rejected
+func acquireRequestQuota(r *ffapi.APIRequest, or orchestrator.Orchestrator, ac *core.AuthContext) (*requestQuota, error) {
+	release, retryAfter, err := or.AcquireRequestQuota(r.Req.Context(), requestIdentity(r.Req, ac))
+	if err != nil {
+		r.ResponseHeaders.Set("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+		return nil, err
+	}
+	return &requestQuota{release: release}, nil
+}
+
+// takeOver returns the function that releases the quota, which the caller must then call itself
+func (q *requestQuota) takeOver() func() {
+	if q == nil {
+		return func() {}
+	}
+	q.takenOver = true
+	return q.release
+}
+
+func (q *requestQuota) handlerDone() {
+	if q != nil && !q.takenOver {
+		q.release()
+	}
+}
+
+// authorizeRequest authorizes a request for a route against the namespace, returning the auth context
+// with the identity the authorizer resolved for the caller
+func authorizeRequest(r *ffapi.APIRequest, or orchestrator.Orchestrator, route *ffapi.Route) (*core.AuthContext, error) {
+	authReq := &fftypes.AuthReq{
+		Method: r.Req.Method,
+		URL:    r.Req.URL,
+		Header: r.Req.Header,
+	}
+	ac := &core.AuthContext{Route: route.Name}
+	if err := or.Authorize(core.WithAuthContext(r.Req.Context(), ac), authReq); err != nil {
+		return nil, err
+	}
+	return ac, nil
+}
+
 func (as *apiServer) routeHandler(hf *ffapi.HandlerFactory, mgr namespace.Manager, fixedBaseURL string, route *ffapi.Route) http.HandlerFunc {
 	// We extend the base ffapi functionality, with standardized DB filter support for all core resources.
 	// We also pass the Orchestrator context through
@@ -208,16 +276,16 @@ func (as *apiServer) routeHandler(hf *ffapi.HandlerFactory, mgr namespace.Manage
 			return nil, err
 		}
 
-		// Authorize the request
-		authReq := &fftypes.AuthReq{
-			Method: r.Req.Method,
-			URL:    r.Req.URL,
-			Header: r.Req.Header,
-		}
+		var quota *requestQuota
 		if or != nil {
-			if err := or.Authorize(r.Req.Context(), authReq); err != nil {
+			ac, err := authorizeRequest(r, or, route)
+			if err != nil {
+				return nil, err
+			}
+			if quota, err = acquireRequestQuota(r, or, ac); err != nil {
 				return nil, err
 			}
+			defer quota.handlerDone()
 		}
 
 		if ce.EnabledIf != nil && !ce.EnabledIf(or) {
@@ -228,11 +296,20 @@ func (as *apiServer) routeHandler(hf *ffapi.HandlerFactory, mgr namespace.Manage
 		if apiBaseURL == "" {
 			apiBaseURL = as.getBaseURL(r.Req)
 		}
+		ctx := r.Req.Context()
+		if r.Req.Method == http.MethodGet {
+			// Queries tolerate results that are slightly behind, so can be served by a read replica
+			ctx = database.WithReplicaReads(ctx)
+		}
 		cr := &coreRequest{
 			mgr:        mgr,
 			or:         or,
-			ctx:        r.Req.Context(),
+			ctx:        ctx,
 			apiBaseURL: apiBaseURL,
+			quota:      quota,
+		}
+		if ce.CursorPaging {
+			return cursorHandler(r, cr, ce.CoreJSONHandler)
 		}
 		return ce.CoreJSONHandler(r, cr)
 	}
@@ -242,6 +319,18 @@ func (as *apiServer) routeHandler(hf *ffapi.HandlerFactory, mgr namespace.Manage
 			if err != nil {
 				return nil, err
 			}
+			if or != nil {
+				ac, err := authorizeRequest(r, or, route)
+				if err != nil {
+					return nil, err
+				}
+				// Quotas apply to the identity the authorizer resolved, as for JSON routes
+				quota, err := acquireRequestQuota(r, or, ac)
+				if err != nil {
+					return nil, err
+				}
+				defer quota.handlerDone()
+			}
 			if ce.EnabledIf != nil && !ce.EnabledIf(or) {
 				return nil, i18n.NewError(r.Req.Context(), coremsgs.MsgActionNotSupported)
 			}
@@ -259,7 +348,7 @@ func (as *apiServer) routeHandler(hf *ffapi.HandlerFactory, mgr namespace.Manage
 			return ce.CoreFormUploadHandler(r, cr)
 		}
 	}
-	return hf.RouteHandler(route)
+	return tracing.ServerHandler(route.Name, hf.RouteHandler(route))
 }
 
 func (as *apiServer) handlerFactory() *ffapi.HandlerFactory {
@@ -393,6 +482,15 @@ func (as *apiServer) createMuxRouter(ctx context.Context, mgr namespace.Manager)
 	// namespace scoped web sockets
 	r.HandleFunc("/api/v1/namespaces/{ns}/ws", hf.APIWrapper(getNamespacedWebSocketHandler(ws.(*websockets.WebSockets), mgr)))
 
+	// namespace scoped Server-Sent Events streams, when that transport is enabled
+	for _, transport := range config.GetStringSlice(coreconfig.EventTransportsEnabled) {
+		if transport == "sse" {
+			s, _ := eifactory.GetPlugin(ctx, "sse")
+			s.(*sse.SSE).SetAuthorizer(mgr)
+			r.HandleFunc("/api/v1/namespaces/{ns}/sse", getNamespacedSSEHandler(s.(*sse.SSE), mgr))
+		}
+	}
+
 	uiPath := config.GetString(coreconfig.UIPath)
 	if uiPath != "" && config.GetBool(coreconfig.UIEnabled) {
 		r.PathPrefix(`/ui`).Handler(newStaticHandler(uiPath, "index.html", `/ui`))
@@ -419,6 +517,24 @@ func getNamespacedWebSocketHandler(ws websockets.WebSocketsNamespaced, mgr names
 
 }
 
+func getNamespacedSSEHandler(s sse.SSENamespaced, mgr namespace.Manager) http.HandlerFunc {
+	// Not wrapped with the API handler, as the stream must outlive the API request timeout
+	return func(res http.ResponseWriter, req *http.Request) {
+		namespace := mux.Vars(req)["ns"]
+		or, err := mgr.Orchestrator(req.Context(), namespace, false)
+		if err != nil || or == nil {
+			res.Header().Add("Content-Type", "application/json")
+			res.WriteHeader(http.StatusNotFound)
+			_ = json.NewEncoder(res).Encode(&fftypes.RESTError{
+				Error: i18n.NewError(req.Context(), coremsgs.Msg404NotFound).Error(),
+			})
+			return
+		}
+
+		s.ServeHTTPNamespaced(namespace, res, req)
+	}
+}
+
 func (as *apiServer) notFoundHandler(res http.ResponseWriter, req *http.Request) (status int, err error) {
 	res.Header().Add("Content-Type", "application/json")
 	return 404, i18n.NewError(req.Context(), coremsgs.Msg404NotFound)
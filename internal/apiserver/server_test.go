@@ -27,6 +27,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/getkin/kin-openapi/openapi3"
 	"github.com/go-resty/resty/v2"
@@ -44,8 +45,10 @@ import (
 	"github.com/hyperledger/firefly/mocks/namespacemocks"
 	"github.com/hyperledger/firefly/mocks/orchestratormocks"
 	"github.com/hyperledger/firefly/mocks/spieventsmocks"
+	"github.com/hyperledger/firefly/mocks/ssemocks"
 	"github.com/hyperledger/firefly/mocks/websocketsmocks"
 	"github.com/hyperledger/firefly/pkg/core"
+	"github.com/hyperledger/firefly/pkg/database"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 )
@@ -60,6 +63,7 @@ func newTestServer() (*namespacemocks.Manager, *orchestratormocks.Orchestrator,
 	mgr.On("Orchestrator", mock.Anything, "default", false).Return(o, nil).Maybe()
 	mgr.On("Orchestrator", mock.Anything, "mynamespace", false).Return(o, nil).Maybe()
 	mgr.On("Orchestrator", mock.Anything, "ns1", false).Return(o, nil).Maybe()
+	o.On("AcquireRequestQuota", mock.Anything, mock.Anything).Return(func() {}, time.Duration(0), nil).Maybe()
 	config.Set(coreconfig.APIMaxFilterLimit, 100)
 	as := NewAPIServer().(*apiServer)
 	return mgr, o, as
@@ -224,6 +228,141 @@ func TestUnauthorized(t *testing.T) {
 	assert.Regexp(t, "FF00169", resJSON["error"])
 }
 
+func TestAuthorizeRouteName(t *testing.T) {
+	mgr, o, as := newTestServer()
+	o.On("Authorize", mock.MatchedBy(func(ctx context.Context) bool {
+		return core.GetAuthContext(ctx).Route == "getBatches"
+	}), mock.Anything).Return(i18n.NewError(context.Background(), i18n.MsgForbidden))
+	handler := as.routeHandler(as.handlerFactory(), mgr, "", getBatches)
+
+	req := httptest.NewRequest("GET", "http://localhost:12345/test", nil)
+	res := httptest.NewRecorder()
+	handler.ServeHTTP(res, req)
+	assert.Equal(t, 403, res.Result().StatusCode)
+}
+
+func TestGetRouteReplicaReads(t *testing.T) {
+	o, r := newTestAPIServer()
+	o.On("Authorize", mock.Anything, mock.Anything).Return(nil)
+	o.On("GetBatches", mock.MatchedBy(func(ctx context.Context) bool {
+		return database.ReplicaReadsAllowed(ctx)
+	}), mock.Anything).Return([]*core.BatchPersisted{}, nil, nil)
+
+	req := httptest.NewRequest("GET", "/api/v1/namespaces/ns1/batches", nil)
+	res := httptest.NewRecorder()
+	r.ServeHTTP(res, req)
+	assert.Equal(t, 200, res.Result().StatusCode)
+}
+
+func TestPostRoutePrimaryReads(t *testing.T) {
+	o, r := newTestAPIServer()
+	o.On("Authorize", mock.Anything, mock.Anything).Return(nil)
+	o.On("GetChartAggregates", mock.MatchedBy(func(ctx context.Context) bool {
+		return !database.ReplicaReadsAllowed(ctx)
+	}), database.CollectionName("events"), mock.Anything).Return([]*core.ChartAggregate{}, nil)
+
+	req := httptest.NewRequest("POST", "/api/v1/namespaces/ns1/charts/aggregate/events", bytes.NewReader([]byte(`{"buckets":1}`)))
+	req.Header.Set("Content-Type", "application/json")
+	res := httptest.NewRecorder()
+	r.ServeHTTP(res, req)
+	assert.Equal(t, 200, res.Result().StatusCode)
+}
+
+func TestRequestQuotaIdentity(t *testing.T) {
+	mgr, o, as := newTestServer()
+	o.ExpectedCalls = nil
+	released := false
+	o.On("Authorize", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		core.GetAuthContext(args[0].(context.Context)).Identity = "user1"
+	}).Return(nil)
+	o.On("AcquireRequestQuota", mock.Anything, "user1").Return(func() { released = true }, time.Duration(0), nil)
+	o.On("GetBatches", mock.Anything, mock.Anything).Return([]*core.BatchPersisted{}, nil, nil)
+	r := as.createMuxRouter(context.Background(), mgr)
+
+	req := httptest.NewRequest("GET", "/api/v1/namespaces/ns1/batches", nil)
+	res := httptest.NewRecorder()
+	r.ServeHTTP(res, req)
+	assert.Equal(t, 200, res.Result().StatusCode)
+	assert.True(t, released)
+}
+
+func TestRequestQuotaRejected(t *testing.T) {
+	mgr, o, as := newTestServer()
+	o.ExpectedCalls = nil
+	o.On("Authorize", mock.Anything, mock.Anything).Return(nil)
+	o.On("AcquireRequestQuota", mock.Anything, "192.0.2.1").Return(nil, 1500*time.Millisecond, i18n.NewError(context.Background(), coremsgs.MsgRequestRateLimited, "namespace", "ns1"))
+	r := as.createMuxRouter(context.Background(), mgr)
+
+	req := httptest.NewRequest("GET", "/api/v1/namespaces/ns1/batches", nil)
+	res := httptest.NewRecorder()
+	r.ServeHTTP(res, req)
+	assert.Equal(t, 429, res.Result().StatusCode)
+	assert.Equal(t, "2", res.Result().Header.Get("Retry-After"))
+}
+
+func newTestFormUpload(t *testing.T) *http.Request {
+	var b bytes.Buffer
+	w := multipart.NewWriter(&b)
+	writer, err := w.CreateFormFile("file", "filename.ext")
+	assert.NoError(t, err)
+	writer.Write([]byte(`some data`))
+	w.Close()
+	req := httptest.NewRequest("POST", "/api/v1/namespaces/ns1/data", &b)
+	req.Header.Set("Content-Type", w.FormDataContentType())
+	return req
+}
+
+func TestRequestQuotaRejectedFormData(t *testing.T) {
+	mgr, o, as := newTestServer()
+	o.ExpectedCalls = nil
+	o.On("Authorize", mock.Anything, mock.Anything).Return(nil)
+	o.On("AcquireRequestQuota", mock.Anything, "192.0.2.1").Return(nil, time.Second, i18n.NewError(context.Background(), coremsgs.MsgRequestConcurrencyLimited, "identity", "192.0.2.1"))
+	r := as.createMuxRouter(context.Background(), mgr)
+
+	res := httptest.NewRecorder()
+	r.ServeHTTP(res, newTestFormUpload(t))
+	assert.Equal(t, 429, res.Result().StatusCode)
+	assert.Equal(t, "1", res.Result().Header.Get("Retry-After"))
+}
+
+func TestRequestQuotaIdentityFormData(t *testing.T) {
+	mgr, o, as := newTestServer()
+	o.ExpectedCalls = nil
+	o.On("Authorize", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		core.GetAuthContext(args[0].(context.Context)).Identity = "user1"
+	}).Return(nil)
+	o.On("AcquireRequestQuota", mock.Anything, "user1").Return(nil, time.Second, i18n.NewError(context.Background(), coremsgs.MsgRequestConcurrencyLimited, "identity", "user1"))
+	r := as.createMuxRouter(context.Background(), mgr)
+
+	res := httptest.NewRecorder()
+	r.ServeHTTP(res, newTestFormUpload(t))
+	assert.Equal(t, 429, res.Result().StatusCode)
+	o.AssertExpectations(t)
+}
+
+func TestUnauthorizedFormData(t *testing.T) {
+	mgr, o, as := newTestServer()
+	o.ExpectedCalls = nil
+	o.On("Authorize", mock.MatchedBy(func(ctx context.Context) bool {
+		return core.GetAuthContext(ctx).Route == "postDataNamespace"
+	}), mock.Anything).Return(i18n.NewError(context.Background(), i18n.MsgForbidden))
+	r := as.createMuxRouter(context.Background(), mgr)
+
+	res := httptest.NewRecorder()
+	r.ServeHTTP(res, newTestFormUpload(t))
+	assert.Equal(t, 403, res.Result().StatusCode)
+	o.AssertNotCalled(t, "AcquireRequestQuota", mock.Anything, mock.Anything)
+}
+
+func TestRequestIdentityRemoteAddr(t *testing.T) {
+	req := httptest.NewRequest("GET", "/", nil)
+	req.RemoteAddr = "10.0.0.1:54321"
+	assert.Equal(t, "10.0.0.1", requestIdentity(req, &core.AuthContext{}))
+	req.RemoteAddr = "pipe"
+	assert.Equal(t, "pipe", requestIdentity(req, &core.AuthContext{}))
+	assert.Equal(t, "user1", requestIdentity(req, &core.AuthContext{Identity: "user1"}))
+}
+
 func TestSwaggerJSON(t *testing.T) {
 	o, r := newTestAPIServer()
 	o.On("Authorize", mock.Anything, mock.Anything).Return(nil)
@@ -550,6 +689,47 @@ func TestGetNamespacedWebSocketHandlerUnknownNamespace(t *testing.T) {
 	assert.Equal(t, 404, status)
 }
 
+func TestGetNamespacedSSEHandler(t *testing.T) {
+	mgr, _, _ := newTestServer()
+	msns := &ssemocks.SSENamespaced{}
+	msns.On("ServeHTTPNamespaced", "ns1", mock.Anything, mock.Anything).Return()
+
+	req := httptest.NewRequest("GET", "/api/v1/namespaces/ns1/sse?ephemeral", nil)
+	req = mux.SetURLVars(req, map[string]string{"ns": "ns1"})
+	res := httptest.NewRecorder()
+
+	handler := getNamespacedSSEHandler(msns, mgr)
+	handler(res, req)
+	msns.AssertExpectations(t)
+}
+
+func TestGetNamespacedSSEHandlerUnknownNamespace(t *testing.T) {
+	mgr, _, _ := newTestServer()
+	msns := &ssemocks.SSENamespaced{}
+
+	mgr.On("Orchestrator", mock.Anything, "unknown", false).Return(nil, errors.New("unknown namespace")).Maybe()
+	req := httptest.NewRequest("GET", "/api/v1/namespaces/unknown/sse?ephemeral", nil)
+	req = mux.SetURLVars(req, map[string]string{"ns": "unknown"})
+	res := httptest.NewRecorder()
+
+	handler := getNamespacedSSEHandler(msns, mgr)
+	handler(res, req)
+	assert.Equal(t, 404, res.Code)
+	assert.Regexp(t, "FF10109", res.Body.String())
+}
+
+func TestSSERouteEnabled(t *testing.T) {
+	mgr, _, as := newTestServer()
+	config.Set(coreconfig.EventTransportsEnabled, []string{"websockets", "sse"})
+	r := as.createMuxRouter(context.Background(), mgr)
+
+	var match mux.RouteMatch
+	req := httptest.NewRequest("GET", "/api/v1/namespaces/ns1/sse", nil)
+	assert.True(t, r.Match(req, &match))
+	assert.NotNil(t, match.Route)
+	assert.Nil(t, match.MatchErr)
+}
+
 func TestContractAPIDefaultNS(t *testing.T) {
 	mgr, o, as := newTestServer()
 	r := as.createMuxRouter(context.Background(), mgr)
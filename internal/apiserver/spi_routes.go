@@ -32,7 +32,10 @@ var spiRoutes = append(globalRoutes([]*ffapi.Route{
 	spiPostReset,
 }),
 	namespacedSPIRoutes([]*ffapi.Route{
+		spiGetNamespaceExport,
 		spiGetOps,
+		spiGetStatusLeader,
+		spiPostNamespaceImport,
 	})...,
 )
 
@@ -186,7 +186,7 @@ func (am *assetManager) GetTokenPoolByLocator(ctx context.Context, connector, po
 	}
 
 	fb := database.TokenPoolQueryFactory.NewFilter(ctx)
-	results, _, err := am.database.GetTokenPools(ctx, am.namespace, fb.And(
+	results, _, err := am.database.GetTokenPools(database.WithPrimaryReads(ctx), am.namespace, fb.And(
 		fb.Eq("connector", connector),
 		fb.Eq("locator", poolLocator),
 	))
@@ -213,10 +213,10 @@ func (am *assetManager) GetTokenPoolByNameOrID(ctx context.Context, poolNameOrID
 		if err := fftypes.ValidateFFNameField(ctx, poolNameOrID, "name"); err != nil {
 			return nil, err
 		}
-		if pool, err = am.database.GetTokenPool(ctx, am.namespace, poolNameOrID); err != nil {
+		if pool, err = am.database.GetTokenPool(database.WithPrimaryReads(ctx), am.namespace, poolNameOrID); err != nil {
 			return nil, err
 		}
-	} else if pool, err = am.GetTokenPoolByID(ctx, poolID); err != nil {
+	} else if pool, err = am.GetTokenPoolByID(database.WithPrimaryReads(ctx), poolID); err != nil {
 		return nil, err
 	}
 	if pool == nil {
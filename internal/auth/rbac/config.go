@@ -0,0 +1,81 @@
+// Copyright © 2024 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rbac
+
+import (
+	"github.com/hyperledger/firefly-common/pkg/config"
+)
+
+const (
+	defaultRolesClaim   = "roles"
+	defaultAPIKeyHeader = "X-API-Key"
+)
+
+const (
+	// RBACConfJWT is the sub-section for validating JWT bearer tokens
+	RBACConfJWT = "jwt"
+	// RBACConfJWTJWKSFile is a JSON Web Key Set file containing the keys tokens can be signed with
+	RBACConfJWTJWKSFile = "jwksFile"
+	// RBACConfJWTPublicKeys are PEM encoded public keys tokens can be signed with, in addition to any in the JWKS file
+	RBACConfJWTPublicKeys = "publicKeys"
+	// RBACConfJWTIssuer is the issuer tokens must have, if set
+	RBACConfJWTIssuer = "issuer"
+	// RBACConfJWTAudience is an audience tokens must have, if set
+	RBACConfJWTAudience = "audience"
+	// RBACConfJWTRolesClaim is the claim that holds the roles of the token, which can be a dotted path to a nested claim
+	RBACConfJWTRolesClaim = "rolesClaim"
+
+	// RBACConfAPIKeys is the sub-section for API keys
+	RBACConfAPIKeys = "apiKeys"
+	// RBACConfAPIKeysHeader is the request header API keys are passed in
+	RBACConfAPIKeysHeader = "header"
+
+	// RBACConfRoles is the array of roles, and the permissions each role grants
+	RBACConfRoles = "roles"
+	// RBACConfRoleName is the name of the role, as it appears in tokens and on API keys
+	RBACConfRoleName = "name"
+	// RBACConfRolePermissions is the array of permissions the role grants
+	RBACConfRolePermissions = "permissions"
+	// RBACConfPermissionNamespaces are the namespaces the permission applies to
+	RBACConfPermissionNamespaces = "namespaces"
+	// RBACConfPermissionRoutes are the API route names the permission applies to
+	RBACConfPermissionRoutes = "routes"
+	// RBACConfPermissionMethods are the HTTP methods the permission applies to
+	RBACConfPermissionMethods = "methods"
+	// RBACConfPermissionSubscriptions are the subscription names event streams can be started for
+	RBACConfPermissionSubscriptions = "subscriptions"
+)
+
+func (a *Auth) InitConfig(config config.Section) {
+	jwtConfig := config.SubSection(RBACConfJWT)
+	jwtConfig.AddKnownKey(RBACConfJWTJWKSFile)
+	jwtConfig.AddKnownKey(RBACConfJWTPublicKeys)
+	jwtConfig.AddKnownKey(RBACConfJWTIssuer)
+	jwtConfig.AddKnownKey(RBACConfJWTAudience)
+	jwtConfig.AddKnownKey(RBACConfJWTRolesClaim, defaultRolesClaim)
+
+	apiKeysConfig := config.SubSection(RBACConfAPIKeys)
+	apiKeysConfig.AddKnownKey(RBACConfAPIKeysHeader, defaultAPIKeyHeader)
+
+	rolesConfig := config.SubArray(RBACConfRoles)
+	rolesConfig.AddKnownKey(RBACConfRoleName)
+	permissionsConfig := rolesConfig.SubArray(RBACConfRolePermissions)
+	permissionsConfig.AddKnownKey(RBACConfPermissionNamespaces)
+	permissionsConfig.AddKnownKey(RBACConfPermissionRoutes)
+	permissionsConfig.AddKnownKey(RBACConfPermissionMethods)
+	permissionsConfig.AddKnownKey(RBACConfPermissionSubscriptions)
+}
@@ -0,0 +1,238 @@
+// Copyright © 2024 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rbac
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"os"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/hyperledger/firefly-common/pkg/config"
+	"github.com/hyperledger/firefly-common/pkg/i18n"
+	"github.com/hyperledger/firefly-common/pkg/log"
+	"github.com/hyperledger/firefly/internal/coremsgs"
+)
+
+// jwtValidator verifies bearer tokens against a fixed set of keys, and extracts their roles
+type jwtValidator struct {
+	keysByID   map[string]interface{}
+	allKeys    []jwt.VerificationKey
+	parser     *jwt.Parser
+	rolesClaim string
+}
+
+type jsonWebKey struct {
+	KeyType string `json:"kty"`
+	KeyID   string `json:"kid"`
+	Use     string `json:"use"`
+	Curve   string `json:"crv"`
+	N       string `json:"n"`
+	E       string `json:"e"`
+	X       string `json:"x"`
+	Y       string `json:"y"`
+	K       string `json:"k"`
+}
+
+type jsonWebKeySet struct {
+	Keys []*jsonWebKey `json:"keys"`
+}
+
+// newJWTValidator returns nil if there are no keys configured, as bearer tokens are not accepted
+func newJWTValidator(ctx context.Context, config config.Section) (*jwtValidator, error) {
+	v := &jwtValidator{
+		keysByID:   map[string]interface{}{},
+		rolesClaim: config.GetString(RBACConfJWTRolesClaim),
+	}
+
+	if jwksFile := config.GetString(RBACConfJWTJWKSFile); jwksFile != "" {
+		if err := v.loadJWKS(ctx, jwksFile); err != nil {
+			return nil, err
+		}
+	}
+	for i, keyPEM := range config.GetStringSlice(RBACConfJWTPublicKeys) {
+		key, err := parsePublicKeyPEM(keyPEM)
+		if err != nil {
+			return nil, i18n.NewError(ctx, coremsgs.MsgAuthPublicKeyInvalid, i, err)
+		}
+		v.allKeys = append(v.allKeys, key)
+	}
+	if len(v.allKeys) == 0 {
+		return nil, nil
+	}
+
+	options := []jwt.ParserOption{
+		jwt.WithValidMethods([]string{"RS256", "RS384", "RS512", "PS256", "PS384", "PS512", "ES256", "ES384", "ES512", "EdDSA", "HS256", "HS384", "HS512"}),
+		jwt.WithExpirationRequired(),
+	}
+	if issuer := config.GetString(RBACConfJWTIssuer); issuer != "" {
+		options = append(options, jwt.WithIssuer(issuer))
+	}
+	if audience := config.GetString(RBACConfJWTAudience); audience != "" {
+		options = append(options, jwt.WithAudience(audience))
+	}
+	v.parser = jwt.NewParser(options...)
+	log.L(ctx).Infof("JWT validation enabled with %d keys", len(v.allKeys))
+	return v, nil
+}
+
+func (v *jwtValidator) loadJWKS(ctx context.Context, jwksFile string) error {
+	b, err := os.ReadFile(jwksFile)
+	if err != nil {
+		return i18n.NewError(ctx, coremsgs.MsgAuthJWKSInvalid, jwksFile, err)
+	}
+	var jwks jsonWebKeySet
+	if err := json.Unmarshal(b, &jwks); err != nil {
+		return i18n.NewError(ctx, coremsgs.MsgAuthJWKSInvalid, jwksFile, err)
+	}
+	for _, jwk := range jwks.Keys {
+		if jwk.Use == "enc" {
+			continue
+		}
+		key, err := jwk.verificationKey()
+		if err != nil {
+			return i18n.NewError(ctx, coremsgs.MsgAuthJWKSInvalid, jwksFile, err)
+		}
+		if jwk.KeyID != "" {
+			v.keysByID[jwk.KeyID] = key
+		}
+		v.allKeys = append(v.allKeys, key)
+	}
+	return nil
+}
+
+func decodeBigInt(s string) (*big.Int, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil || len(b) == 0 {
+		return nil, fmt.Errorf("invalid key parameter '%s'", s)
+	}
+	return new(big.Int).SetBytes(b), nil
+}
+
+func (jwk *jsonWebKey) verificationKey() (interface{}, error) {
+	switch jwk.KeyType {
+	case "RSA":
+		n, err := decodeBigInt(jwk.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decodeBigInt(jwk.E)
+		if err != nil {
+			return nil, err
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch jwk.Curve {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("unsupported curve '%s'", jwk.Curve)
+		}
+		x, err := decodeBigInt(jwk.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decodeBigInt(jwk.Y)
+		if err != nil {
+			return nil, err
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+	case "OKP":
+		x, err := base64.RawURLEncoding.DecodeString(jwk.X)
+		if jwk.Curve != "Ed25519" || err != nil || len(x) != ed25519.PublicKeySize {
+			return nil, fmt.Errorf("unsupported or invalid OKP key with curve '%s'", jwk.Curve)
+		}
+		return ed25519.PublicKey(x), nil
+	case "oct":
+		k, err := base64.RawURLEncoding.DecodeString(jwk.K)
+		if err != nil || len(k) == 0 {
+			return nil, fmt.Errorf("invalid symmetric key")
+		}
+		return k, nil
+	default:
+		return nil, fmt.Errorf("unsupported key type '%s'", jwk.KeyType)
+	}
+}
+
+func parsePublicKeyPEM(keyPEM string) (interface{}, error) {
+	block, _ := pem.Decode([]byte(keyPEM))
+	if block == nil {
+		return nil, fmt.Errorf("no PEM data found")
+	}
+	return x509.ParsePKIXPublicKey(block.Bytes)
+}
+
+func (v *jwtValidator) keyFunc(token *jwt.Token) (interface{}, error) {
+	if kid, ok := token.Header["kid"].(string); ok {
+		if key, ok := v.keysByID[kid]; ok {
+			return key, nil
+		}
+	}
+	// Tokens without a known key ID are checked against every key
+	return jwt.VerificationKeySet{Keys: v.allKeys}, nil
+}
+
+// roles verifies the token, and returns the subject and roles from its claims
+func (v *jwtValidator) roles(ctx context.Context, tokenString string) (string, []string, error) {
+	claims := jwt.MapClaims{}
+	if _, err := v.parser.ParseWithClaims(tokenString, claims, v.keyFunc); err != nil {
+		log.L(ctx).Warnf("Bearer token rejected: %s", err)
+		return "", nil, i18n.NewError(ctx, i18n.MsgUnauthorized)
+	}
+	subject, _ := claims.GetSubject()
+	return subject, claimValues(claims, v.rolesClaim), nil
+}
+
+// claimValues reads a claim that is either an array of strings, or a single string of space or comma
+// separated values. The claim can be nested within other claims using a dotted path, such as "realm_access.roles".
+func claimValues(claims jwt.MapClaims, path string) []string {
+	var value interface{} = map[string]interface{}(claims)
+	for _, segment := range strings.Split(path, ".") {
+		m, ok := value.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		value = m[segment]
+	}
+	values := []string{}
+	switch v := value.(type) {
+	case string:
+		values = strings.FieldsFunc(v, func(r rune) bool { return r == ' ' || r == ',' })
+	case []interface{}:
+		for _, entry := range v {
+			if s, ok := entry.(string); ok {
+				values = append(values, s)
+			}
+		}
+	}
+	return values
+}
@@ -0,0 +1,283 @@
+// Copyright © 2024 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rbac
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+)
+
+type testKeys struct {
+	rsa      *rsa.PrivateKey
+	ec       *ecdsa.PrivateKey
+	ed25519  ed25519.PrivateKey
+	hmac     []byte
+	jwksFile string
+}
+
+func b64(b []byte) string {
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func newTestKeys(t *testing.T) *testKeys {
+	keys := &testKeys{hmac: []byte("a shared secret")}
+	var err error
+	keys.rsa, err = rsa.GenerateKey(rand.Reader, 2048)
+	assert.NoError(t, err)
+	keys.ec, err = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+	_, keys.ed25519, err = ed25519.GenerateKey(rand.Reader)
+	assert.NoError(t, err)
+
+	keys.jwksFile = writeJWKS(t, []*jsonWebKey{
+		{KeyType: "RSA", KeyID: "rsa1", N: b64(keys.rsa.N.Bytes()), E: b64(big.NewInt(int64(keys.rsa.E)).Bytes())},
+		{KeyType: "EC", KeyID: "ec1", Curve: "P-256", X: b64(keys.ec.X.Bytes()), Y: b64(keys.ec.Y.Bytes())},
+		{KeyType: "OKP", Curve: "Ed25519", X: b64(keys.ed25519.Public().(ed25519.PublicKey))},
+		{KeyType: "oct", KeyID: "hmac1", K: b64(keys.hmac)},
+		{KeyType: "RSA", Use: "enc"},
+	})
+	return keys
+}
+
+func writeJWKS(t *testing.T, keys []*jsonWebKey) string {
+	b, err := json.Marshal(&jsonWebKeySet{Keys: keys})
+	assert.NoError(t, err)
+	jwksFile := filepath.Join(t.TempDir(), "jwks.json")
+	err = os.WriteFile(jwksFile, b, 0600)
+	assert.NoError(t, err)
+	return jwksFile
+}
+
+func publicKeyPEM(t *testing.T, key interface{}) string {
+	b, err := x509.MarshalPKIXPublicKey(key)
+	assert.NoError(t, err)
+	return string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: b}))
+}
+
+func signToken(t *testing.T, method jwt.SigningMethod, kid string, key interface{}, claims jwt.MapClaims) string {
+	if _, ok := claims["exp"]; !ok {
+		claims["exp"] = time.Now().Add(1 * time.Minute).Unix()
+	}
+	token := jwt.NewWithClaims(method, claims)
+	if kid != "" {
+		token.Header["kid"] = kid
+	}
+	s, err := token.SignedString(key)
+	assert.NoError(t, err)
+	return s
+}
+
+func newTestValidator(t *testing.T, yaml string) *jwtValidator {
+	conf := newTestConfig(t, yaml)
+	v, err := newJWTValidator(context.Background(), conf.SubSection(RBACConfJWT))
+	assert.NoError(t, err)
+	return v
+}
+
+func TestJWTValidatorKeyTypes(t *testing.T) {
+	keys := newTestKeys(t)
+	v := newTestValidator(t, fmt.Sprintf(`
+plugins:
+  auth:
+  - name: rbac1
+    type: rbac
+    rbac:
+      jwt:
+        jwksFile: %s
+`, keys.jwksFile))
+	assert.Len(t, v.allKeys, 4)
+	assert.Len(t, v.keysByID, 3)
+
+	ctx := context.Background()
+	claims := func() jwt.MapClaims { return jwt.MapClaims{"sub": "user1", "roles": []string{"reader"}} }
+	for _, token := range []string{
+		signToken(t, jwt.SigningMethodRS256, "rsa1", keys.rsa, claims()),
+		signToken(t, jwt.SigningMethodPS256, "", keys.rsa, claims()),
+		signToken(t, jwt.SigningMethodES256, "ec1", keys.ec, claims()),
+		signToken(t, jwt.SigningMethodEdDSA, "unknown", keys.ed25519, claims()),
+		signToken(t, jwt.SigningMethodHS256, "hmac1", keys.hmac, claims()),
+	} {
+		subject, roles, err := v.roles(ctx, token)
+		assert.NoError(t, err)
+		assert.Equal(t, "user1", subject)
+		assert.Equal(t, []string{"reader"}, roles)
+	}
+}
+
+func TestJWTValidatorPublicKeysIssuerAudience(t *testing.T) {
+	keys := newTestKeys(t)
+	v := newTestValidator(t, fmt.Sprintf(`
+plugins:
+  auth:
+  - name: rbac1
+    type: rbac
+    rbac:
+      jwt:
+        publicKeys:
+        - |
+%s
+        issuer: https://issuer.example.com
+        audience: firefly
+        rolesClaim: realm_access.roles
+`, indent(publicKeyPEM(t, &keys.ec.PublicKey), "          ")))
+	assert.Len(t, v.allKeys, 1)
+
+	ctx := context.Background()
+	token := signToken(t, jwt.SigningMethodES256, "", keys.ec, jwt.MapClaims{
+		"iss":          "https://issuer.example.com",
+		"aud":          "firefly",
+		"realm_access": map[string]interface{}{"roles": "reader,writer admin"},
+	})
+	_, roles, err := v.roles(ctx, token)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"reader", "writer", "admin"}, roles)
+
+	token = signToken(t, jwt.SigningMethodES256, "", keys.ec, jwt.MapClaims{
+		"iss": "https://other.example.com",
+		"aud": "firefly",
+	})
+	_, _, err = v.roles(ctx, token)
+	assert.Regexp(t, "FF00169", err)
+}
+
+func indent(s, prefix string) string {
+	return prefix + strings.ReplaceAll(strings.TrimSpace(s), "\n", "\n"+prefix)
+}
+
+func TestJWTValidatorRejectsTokens(t *testing.T) {
+	keys := newTestKeys(t)
+	v := newTestValidator(t, fmt.Sprintf(`
+plugins:
+  auth:
+  - name: rbac1
+    type: rbac
+    rbac:
+      jwt:
+        jwksFile: %s
+`, keys.jwksFile))
+
+	ctx := context.Background()
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NoError(t, err)
+	for _, token := range []string{
+		"not a token",
+		signToken(t, jwt.SigningMethodRS256, "rsa1", otherKey, jwt.MapClaims{}),
+		signToken(t, jwt.SigningMethodRS256, "", otherKey, jwt.MapClaims{}),
+		signToken(t, jwt.SigningMethodRS256, "rsa1", keys.rsa, jwt.MapClaims{"exp": time.Now().Add(-1 * time.Minute).Unix()}),
+		signToken(t, jwt.SigningMethodNone, "", jwt.UnsafeAllowNoneSignatureType, jwt.MapClaims{}),
+	} {
+		_, _, err := v.roles(ctx, token)
+		assert.Regexp(t, "FF00169", err)
+	}
+
+	// Expiry is required
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.MapClaims{"sub": "user1"})
+	s, err := token.SignedString(keys.rsa)
+	assert.NoError(t, err)
+	_, _, err = v.roles(ctx, s)
+	assert.Regexp(t, "FF00169", err)
+}
+
+func TestJWTValidatorNoKeys(t *testing.T) {
+	v := newTestValidator(t, `
+plugins:
+  auth:
+  - name: rbac1
+    type: rbac
+`)
+	assert.Nil(t, v)
+}
+
+func TestJWTValidatorBadJWKS(t *testing.T) {
+	ctx := context.Background()
+	badFile := filepath.Join(t.TempDir(), "bad.json")
+	err := os.WriteFile(badFile, []byte("!json"), 0600)
+	assert.NoError(t, err)
+
+	for _, jwksFile := range []string{
+		filepath.Join(t.TempDir(), "missing.json"),
+		badFile,
+		writeJWKS(t, []*jsonWebKey{{KeyType: "unknown"}}),
+		writeJWKS(t, []*jsonWebKey{{KeyType: "RSA", N: "!b64", E: "AQAB"}}),
+		writeJWKS(t, []*jsonWebKey{{KeyType: "RSA", N: "AQAB", E: ""}}),
+		writeJWKS(t, []*jsonWebKey{{KeyType: "EC", Curve: "P-192"}}),
+		writeJWKS(t, []*jsonWebKey{{KeyType: "EC", Curve: "P-384", X: "", Y: "AQAB"}}),
+		writeJWKS(t, []*jsonWebKey{{KeyType: "EC", Curve: "P-521", X: "AQAB", Y: ""}}),
+		writeJWKS(t, []*jsonWebKey{{KeyType: "OKP", Curve: "X25519", X: "AQAB"}}),
+		writeJWKS(t, []*jsonWebKey{{KeyType: "oct", K: ""}}),
+	} {
+		conf := newTestConfig(t, fmt.Sprintf(`
+plugins:
+  auth:
+  - name: rbac1
+    type: rbac
+    rbac:
+      jwt:
+        jwksFile: %s
+`, jwksFile))
+		_, err := newJWTValidator(ctx, conf.SubSection(RBACConfJWT))
+		assert.Regexp(t, "FF10497", err)
+	}
+}
+
+func TestJWTValidatorBadPublicKey(t *testing.T) {
+	conf := newTestConfig(t, `
+plugins:
+  auth:
+  - name: rbac1
+    type: rbac
+    rbac:
+      jwt:
+        publicKeys:
+        - not a PEM key
+`)
+	_, err := newJWTValidator(context.Background(), conf.SubSection(RBACConfJWT))
+	assert.Regexp(t, "FF10498.*0", err)
+}
+
+func TestClaimValues(t *testing.T) {
+	claims := jwt.MapClaims{
+		"scope":  "a b",
+		"groups": []interface{}{"g1", 42, "g2"},
+		"nested": map[string]interface{}{"roles": []interface{}{"r1"}},
+		"number": 42,
+	}
+	assert.Equal(t, []string{"a", "b"}, claimValues(claims, "scope"))
+	assert.Equal(t, []string{"g1", "g2"}, claimValues(claims, "groups"))
+	assert.Equal(t, []string{"r1"}, claimValues(claims, "nested.roles"))
+	assert.Empty(t, claimValues(claims, "number"))
+	assert.Empty(t, claimValues(claims, "missing"))
+	assert.Nil(t, claimValues(claims, "scope.roles"))
+}
@@ -0,0 +1,100 @@
+// Copyright © 2024 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rbac
+
+import (
+	"context"
+	"strings"
+
+	"github.com/hyperledger/firefly-common/pkg/config"
+	"github.com/hyperledger/firefly-common/pkg/fftypes"
+	"github.com/hyperledger/firefly-common/pkg/i18n"
+	"github.com/hyperledger/firefly/internal/coremsgs"
+	"github.com/hyperledger/firefly/pkg/core"
+)
+
+// permission grants access to a set of requests. Each empty list matches anything, as does a "*" entry.
+type permission struct {
+	namespaces    []string
+	routes        []string
+	methods       []string
+	subscriptions []string
+}
+
+// policy maps each role to the permissions it grants
+type policy map[string][]*permission
+
+func loadPolicy(ctx context.Context, config config.Section) (policy, error) {
+	p := policy{}
+	rolesConfig := config.SubArray(RBACConfRoles)
+	for i := 0; i < rolesConfig.ArraySize(); i++ {
+		roleConfig := rolesConfig.ArrayEntry(i)
+		name := roleConfig.GetString(RBACConfRoleName)
+		if name == "" {
+			return nil, i18n.NewError(ctx, coremsgs.MsgAuthRoleNoName, i)
+		}
+		permissionsConfig := roleConfig.SubArray(RBACConfRolePermissions)
+		permissions := p[name]
+		for j := 0; j < permissionsConfig.ArraySize(); j++ {
+			permissionConfig := permissionsConfig.ArrayEntry(j)
+			permissions = append(permissions, &permission{
+				namespaces:    permissionConfig.GetStringSlice(RBACConfPermissionNamespaces),
+				routes:        permissionConfig.GetStringSlice(RBACConfPermissionRoutes),
+				methods:       permissionConfig.GetStringSlice(RBACConfPermissionMethods),
+				subscriptions: permissionConfig.GetStringSlice(RBACConfPermissionSubscriptions),
+			})
+		}
+		p[name] = permissions
+	}
+	return p, nil
+}
+
+func matches(list []string, value string) bool {
+	if len(list) == 0 {
+		return true
+	}
+	for _, entry := range list {
+		if entry == "*" || strings.EqualFold(entry, value) {
+			return true
+		}
+	}
+	return false
+}
+
+// allows checks the request against the permission. API requests are checked by route and method,
+// and event streams by the name of the subscription they are started for.
+func (perm *permission) allows(req *fftypes.AuthReq, ac *core.AuthContext) bool {
+	if !matches(perm.namespaces, req.Namespace) {
+		return false
+	}
+	if ac.EventStream {
+		return matches(perm.subscriptions, ac.Subscription)
+	}
+	return matches(perm.routes, ac.Route) && matches(perm.methods, req.Method)
+}
+
+// allows checks whether any of the roles grants a permission for the request
+func (p policy) allows(roles []string, req *fftypes.AuthReq, ac *core.AuthContext) bool {
+	for _, role := range roles {
+		for _, perm := range p[role] {
+			if perm.allows(req, ac) {
+				return true
+			}
+		}
+	}
+	return false
+}
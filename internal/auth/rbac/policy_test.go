@@ -0,0 +1,121 @@
+// Copyright © 2024 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rbac
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/hyperledger/firefly-common/pkg/config"
+	"github.com/hyperledger/firefly-common/pkg/fftypes"
+	"github.com/hyperledger/firefly/internal/coreconfig"
+	"github.com/hyperledger/firefly/pkg/core"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+)
+
+// newTestConfig loads the plugin config in the same way as the namespace manager, from an entry
+// in the plugins.auth array, so that the keys of the nested roles and permissions arrays are known
+func newTestConfig(t *testing.T, yaml string) config.Section {
+	coreconfig.Reset()
+	authConfig := config.RootArray("plugins.auth")
+	(&Auth{}).InitConfig(authConfig.SubSection(Name()))
+	viper.SetConfigType("yaml")
+	err := viper.ReadConfig(strings.NewReader(yaml))
+	assert.NoError(t, err)
+	return authConfig.ArrayEntry(0).SubSection(Name())
+}
+
+const testPolicyConfig = `
+plugins:
+  auth:
+  - name: rbac1
+    type: rbac
+    rbac:
+      roles:
+      - name: reader
+        permissions:
+        - namespaces: [ns1]
+          methods: [GET]
+      - name: admin
+        permissions:
+        - {}
+      - name: listener
+        permissions:
+        - namespaces: [ns1]
+          routes: [postNewSubscription]
+          subscriptions: [sub1, "sub2"]
+`
+
+func TestLoadPolicy(t *testing.T) {
+	conf := newTestConfig(t, testPolicyConfig)
+	p, err := loadPolicy(context.Background(), conf)
+	assert.NoError(t, err)
+	assert.Len(t, p, 3)
+	assert.Equal(t, []string{"ns1"}, p["reader"][0].namespaces)
+	assert.Equal(t, []string{"GET"}, p["reader"][0].methods)
+	assert.Empty(t, p["admin"][0].namespaces)
+	assert.Equal(t, []string{"sub1", "sub2"}, p["listener"][0].subscriptions)
+}
+
+func TestLoadPolicyRoleNoName(t *testing.T) {
+	conf := newTestConfig(t, `
+plugins:
+  auth:
+  - name: rbac1
+    type: rbac
+    rbac:
+      roles:
+      - permissions:
+        - {}
+`)
+	_, err := loadPolicy(context.Background(), conf)
+	assert.Regexp(t, "FF10496.*0", err)
+}
+
+func TestMatches(t *testing.T) {
+	assert.True(t, matches(nil, "anything"))
+	assert.True(t, matches([]string{"*"}, "anything"))
+	assert.True(t, matches([]string{"a", "GET"}, "get"))
+	assert.False(t, matches([]string{"a", "b"}, "c"))
+}
+
+func TestPolicyAllows(t *testing.T) {
+	conf := newTestConfig(t, testPolicyConfig)
+	p, err := loadPolicy(context.Background(), conf)
+	assert.NoError(t, err)
+
+	getNS1 := &fftypes.AuthReq{Method: "GET", Namespace: "ns1"}
+	postNS1 := &fftypes.AuthReq{Method: "POST", Namespace: "ns1"}
+	getNS2 := &fftypes.AuthReq{Method: "GET", Namespace: "ns2"}
+	getMessages := &core.AuthContext{Route: "getMessages"}
+	newSub := &core.AuthContext{Route: "postNewSubscription"}
+
+	assert.True(t, p.allows([]string{"reader"}, getNS1, getMessages))
+	assert.False(t, p.allows([]string{"reader"}, postNS1, newSub))
+	assert.False(t, p.allows([]string{"reader"}, getNS2, getMessages))
+	assert.True(t, p.allows([]string{"unknown", "admin"}, getNS2, getMessages))
+	assert.True(t, p.allows([]string{"listener"}, postNS1, newSub))
+	assert.False(t, p.allows([]string{"listener"}, getNS1, getMessages))
+	assert.False(t, p.allows(nil, getNS1, getMessages))
+
+	wsNS1 := &fftypes.AuthReq{Namespace: "ns1"}
+	assert.True(t, p.allows([]string{"listener"}, wsNS1, &core.AuthContext{EventStream: true, Subscription: "sub2"}))
+	assert.False(t, p.allows([]string{"listener"}, wsNS1, &core.AuthContext{EventStream: true, Subscription: "sub3"}))
+	assert.True(t, p.allows([]string{"reader"}, wsNS1, &core.AuthContext{EventStream: true, Subscription: "sub3"}))
+}
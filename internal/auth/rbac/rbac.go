@@ -0,0 +1,127 @@
+// Copyright © 2024 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rbac
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hyperledger/firefly-common/pkg/config"
+	"github.com/hyperledger/firefly-common/pkg/fftypes"
+	"github.com/hyperledger/firefly-common/pkg/i18n"
+	"github.com/hyperledger/firefly-common/pkg/log"
+	"github.com/hyperledger/firefly/pkg/core"
+)
+
+const (
+	authHeaderName         = "Authorization"
+	bearerAuthHeaderPrefix = "Bearer "
+	apiKeyIdentityPrefix   = "apikey:"
+)
+
+// Auth is a role-based access control plugin, that authenticates callers using JWT bearer tokens
+// or API keys, and authorizes each request against the permissions granted to the roles of the caller
+type Auth struct {
+	jwt          *jwtValidator
+	apiKeyHeader string
+	policy       policy
+	apiKeysMux   sync.Mutex
+	apiKeyStores map[string]core.APIKeyStore
+}
+
+func Name() string {
+	return "rbac"
+}
+
+func (a *Auth) Name() string {
+	return Name()
+}
+
+func (a *Auth) Init(ctx context.Context, name string, config config.Section) (err error) {
+	if a.jwt, err = newJWTValidator(ctx, config.SubSection(RBACConfJWT)); err != nil {
+		return err
+	}
+	if a.policy, err = loadPolicy(ctx, config); err != nil {
+		return err
+	}
+	a.apiKeyHeader = config.SubSection(RBACConfAPIKeys).GetString(RBACConfAPIKeysHeader)
+	a.apiKeyStores = map[string]core.APIKeyStore{}
+	log.L(ctx).Infof("rbac auth plugin enabled (name=%s roles=%d)", name, len(a.policy))
+	return nil
+}
+
+// SetAPIKeyStore is called for each namespace the plugin is used in, so API keys can be looked up
+func (a *Auth) SetAPIKeyStore(namespace string, store core.APIKeyStore) {
+	a.apiKeysMux.Lock()
+	defer a.apiKeysMux.Unlock()
+	a.apiKeyStores[namespace] = store
+}
+
+func (a *Auth) apiKeyRoles(ctx context.Context, namespace, key string) (string, []string, error) {
+	a.apiKeysMux.Lock()
+	store := a.apiKeyStores[namespace]
+	a.apiKeysMux.Unlock()
+	if store == nil {
+		log.L(ctx).Warnf("API key rejected: no API keys available for namespace '%s'", namespace)
+		return "", nil, i18n.NewError(ctx, i18n.MsgUnauthorized)
+	}
+	apiKey, err := store.GetAPIKeyByHash(ctx, namespace, core.HashAPIKey(key))
+	if err != nil {
+		return "", nil, err
+	}
+	if apiKey == nil {
+		log.L(ctx).Warnf("API key rejected: not found in namespace '%s'", namespace)
+		return "", nil, i18n.NewError(ctx, i18n.MsgUnauthorized)
+	}
+	if apiKey.Expires != nil && apiKey.Expires.Time().Before(time.Now()) {
+		log.L(ctx).Warnf("API key rejected: '%s' expired at %s", apiKey.Name, apiKey.Expires)
+		return "", nil, i18n.NewError(ctx, i18n.MsgUnauthorized)
+	}
+	return apiKeyIdentityPrefix + apiKey.Name, apiKey.Roles, nil
+}
+
+func (a *Auth) authenticate(ctx context.Context, req *fftypes.AuthReq) (string, []string, error) {
+	authHeader := req.Header.Get(authHeaderName)
+	if a.jwt != nil && strings.HasPrefix(authHeader, bearerAuthHeaderPrefix) {
+		subject, roles, err := a.jwt.roles(ctx, strings.TrimPrefix(authHeader, bearerAuthHeaderPrefix))
+		if err != nil {
+			return "", nil, err
+		}
+		log.L(ctx).Debugf("Authenticated token subject '%s' with roles %v", subject, roles)
+		return subject, roles, nil
+	}
+	if key := req.Header.Get(a.apiKeyHeader); key != "" && req.Namespace != "" {
+		return a.apiKeyRoles(ctx, req.Namespace, key)
+	}
+	return "", nil, i18n.NewError(ctx, i18n.MsgUnauthorized)
+}
+
+func (a *Auth) Authorize(ctx context.Context, req *fftypes.AuthReq) error {
+	identity, roles, err := a.authenticate(ctx, req)
+	if err != nil {
+		return err
+	}
+	ac := core.GetAuthContext(ctx)
+	ac.Identity = identity
+	if !a.policy.allows(roles, req, ac) {
+		log.L(ctx).Warnf("Request %s %s (route=%s subscription=%s) forbidden for roles %v", req.Method, req.Namespace, ac.Route, ac.Subscription, roles)
+		return i18n.NewError(ctx, i18n.MsgForbidden)
+	}
+	return nil
+}
@@ -0,0 +1,204 @@
+// Copyright © 2024 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rbac
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/hyperledger/firefly-common/pkg/fftypes"
+	"github.com/hyperledger/firefly/mocks/databasemocks"
+	"github.com/hyperledger/firefly/pkg/core"
+	"github.com/stretchr/testify/assert"
+)
+
+const testAuthConfig = `
+plugins:
+  auth:
+  - name: rbac1
+    type: rbac
+    rbac:
+      jwt:
+        jwksFile: %s
+      apiKeys:
+        header: X-Test-Key
+      roles:
+      - name: reader
+        permissions:
+        - namespaces: [ns1]
+          methods: [GET]
+      - name: listener
+        permissions:
+        - namespaces: [ns1]
+          subscriptions: [sub1]
+`
+
+func newTestAuth(t *testing.T) (*Auth, *testKeys) {
+	keys := newTestKeys(t)
+	conf := newTestConfig(t, fmt.Sprintf(testAuthConfig, keys.jwksFile))
+	a := &Auth{}
+	err := a.Init(context.Background(), "rbac1", conf)
+	assert.NoError(t, err)
+	assert.Equal(t, "rbac", a.Name())
+	return a, keys
+}
+
+func bearerRequest(t *testing.T, keys *testKeys, namespace, method string, roles []string) *fftypes.AuthReq {
+	token := signToken(t, jwt.SigningMethodRS256, "rsa1", keys.rsa, jwt.MapClaims{"sub": "user1", "roles": roles})
+	header := http.Header{}
+	header.Set("Authorization", "Bearer "+token)
+	return &fftypes.AuthReq{Method: method, Namespace: namespace, Header: header}
+}
+
+func apiKeyRequest(namespace, method, key string) *fftypes.AuthReq {
+	header := http.Header{}
+	header.Set("X-Test-Key", key)
+	return &fftypes.AuthReq{Method: method, Namespace: namespace, Header: header}
+}
+
+func TestInitBadJWT(t *testing.T) {
+	conf := newTestConfig(t, fmt.Sprintf(testAuthConfig, "missing.json"))
+	err := (&Auth{}).Init(context.Background(), "rbac1", conf)
+	assert.Regexp(t, "FF10497", err)
+}
+
+func TestInitBadPolicy(t *testing.T) {
+	conf := newTestConfig(t, `
+plugins:
+  auth:
+  - name: rbac1
+    type: rbac
+    rbac:
+      roles:
+      - permissions: []
+`)
+	err := (&Auth{}).Init(context.Background(), "rbac1", conf)
+	assert.Regexp(t, "FF10496", err)
+}
+
+func TestAuthorizeBearer(t *testing.T) {
+	a, keys := newTestAuth(t)
+	ac := &core.AuthContext{Route: "getMessages"}
+	ctx := core.WithAuthContext(context.Background(), ac)
+
+	err := a.Authorize(ctx, bearerRequest(t, keys, "ns1", http.MethodGet, []string{"reader"}))
+	assert.NoError(t, err)
+	assert.Equal(t, "user1", ac.Identity)
+
+	err = a.Authorize(ctx, bearerRequest(t, keys, "ns1", http.MethodPost, []string{"reader"}))
+	assert.Regexp(t, "FF00170", err)
+
+	err = a.Authorize(ctx, bearerRequest(t, keys, "ns2", http.MethodGet, []string{"reader"}))
+	assert.Regexp(t, "FF00170", err)
+
+	req := bearerRequest(t, keys, "ns1", http.MethodGet, []string{"reader"})
+	req.Header.Set("Authorization", "Bearer not.a.token")
+	err = a.Authorize(ctx, req)
+	assert.Regexp(t, "FF00169", err)
+}
+
+func TestAuthorizeEventStream(t *testing.T) {
+	a, keys := newTestAuth(t)
+	req := bearerRequest(t, keys, "ns1", "", []string{"listener"})
+
+	err := a.Authorize(core.WithAuthContext(context.Background(), &core.AuthContext{EventStream: true, Subscription: "sub1"}), req)
+	assert.NoError(t, err)
+
+	err = a.Authorize(core.WithAuthContext(context.Background(), &core.AuthContext{EventStream: true, Subscription: "sub2"}), req)
+	assert.Regexp(t, "FF00170", err)
+}
+
+func TestAuthorizeAPIKey(t *testing.T) {
+	a, _ := newTestAuth(t)
+	mdi := &databasemocks.Plugin{}
+	a.SetAPIKeyStore("ns1", mdi)
+	ac := &core.AuthContext{}
+	ctx := core.WithAuthContext(context.Background(), ac)
+
+	mdi.On("GetAPIKeyByHash", ctx, "ns1", core.HashAPIKey("key1")).Return(&core.APIKey{
+		Name:  "app1",
+		Roles: fftypes.FFStringArray{"reader"},
+	}, nil)
+	err := a.Authorize(ctx, apiKeyRequest("ns1", http.MethodGet, "key1"))
+	assert.NoError(t, err)
+	assert.Equal(t, "apikey:app1", ac.Identity)
+
+	err = a.Authorize(ctx, apiKeyRequest("ns1", http.MethodDelete, "key1"))
+	assert.Regexp(t, "FF00170", err)
+
+	mdi.AssertExpectations(t)
+}
+
+func TestAuthorizeAPIKeyExpired(t *testing.T) {
+	a, _ := newTestAuth(t)
+	mdi := &databasemocks.Plugin{}
+	a.SetAPIKeyStore("ns1", mdi)
+	ctx := context.Background()
+
+	mdi.On("GetAPIKeyByHash", ctx, "ns1", core.HashAPIKey("key1")).Return(&core.APIKey{
+		Name:    "app1",
+		Roles:   fftypes.FFStringArray{"reader"},
+		Expires: fftypes.UnixTime(time.Now().Add(-1 * time.Hour).Unix()),
+	}, nil)
+	err := a.Authorize(ctx, apiKeyRequest("ns1", http.MethodGet, "key1"))
+	assert.Regexp(t, "FF00169", err)
+
+	mdi.AssertExpectations(t)
+}
+
+func TestAuthorizeAPIKeyNotFound(t *testing.T) {
+	a, _ := newTestAuth(t)
+	mdi := &databasemocks.Plugin{}
+	a.SetAPIKeyStore("ns1", mdi)
+	ctx := context.Background()
+
+	mdi.On("GetAPIKeyByHash", ctx, "ns1", core.HashAPIKey("key1")).Return(nil, nil)
+	err := a.Authorize(ctx, apiKeyRequest("ns1", http.MethodGet, "key1"))
+	assert.Regexp(t, "FF00169", err)
+
+	mdi.AssertExpectations(t)
+}
+
+func TestAuthorizeAPIKeyLookupFail(t *testing.T) {
+	a, _ := newTestAuth(t)
+	mdi := &databasemocks.Plugin{}
+	a.SetAPIKeyStore("ns1", mdi)
+	ctx := context.Background()
+
+	mdi.On("GetAPIKeyByHash", ctx, "ns1", core.HashAPIKey("key1")).Return(nil, fmt.Errorf("pop"))
+	err := a.Authorize(ctx, apiKeyRequest("ns1", http.MethodGet, "key1"))
+	assert.EqualError(t, err, "pop")
+
+	mdi.AssertExpectations(t)
+}
+
+func TestAuthorizeAPIKeyNoStore(t *testing.T) {
+	a, _ := newTestAuth(t)
+	a.SetAPIKeyStore("ns1", nil)
+	err := a.Authorize(context.Background(), apiKeyRequest("ns1", http.MethodGet, "key1"))
+	assert.Regexp(t, "FF00169", err)
+}
+
+func TestAuthorizeNoCredentials(t *testing.T) {
+	a, _ := newTestAuth(t)
+	err := a.Authorize(context.Background(), &fftypes.AuthReq{Method: http.MethodGet, Namespace: "ns1", Header: http.Header{}})
+	assert.Regexp(t, "FF00169", err)
+}
@@ -80,6 +80,7 @@ type Manager interface {
 	CancelBatch(ctx context.Context, batchID string) error
 	NewMessages() chan<- int64
 	Start() error
+	StartSequencer()
 	Close()
 	WaitStop()
 	Status() *ManagerStatus
@@ -108,6 +109,7 @@ type batchManager struct {
 	allDispatchers             []*dispatcher
 	newMessages                chan int64
 	done                       chan struct{}
+	sequencing                 bool
 	retry                      *retry.Retry
 	readOffset                 int64
 	rewindOffsetMux            sync.Mutex
@@ -168,12 +170,17 @@ func (bm *batchManager) RegisterDispatcher(name string, pinned bool, msgTypes []
 }
 
 func (bm *batchManager) Start() error {
-	go bm.messageSequencer()
 	// We must be always ready to process DB events, or we block commits. So we have a dedicated worker for that
 	go bm.newMessageNotifier()
 	return nil
 }
 
+// StartSequencer starts assigning messages to batches. Only one replica sharing the database can run the sequencer
+func (bm *batchManager) StartSequencer() {
+	bm.sequencing = true
+	go bm.messageSequencer()
+}
+
 func (bm *batchManager) NewMessages() chan<- int64 {
 	return bm.newMessages
 }
@@ -475,7 +482,9 @@ func (bm *batchManager) Close() {
 }
 
 func (bm *batchManager) WaitStop() {
-	<-bm.done
+	if bm.sequencing {
+		<-bm.done
+	}
 	processors := bm.getProcessors()
 	for _, p := range processors {
 		<-p.done
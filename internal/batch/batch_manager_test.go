@@ -152,6 +152,7 @@ func TestE2EDispatchBroadcast(t *testing.T) {
 
 	err := bm.Start()
 	assert.NoError(t, err)
+	bm.StartSequencer()
 
 	bm.NewMessages() <- msg.Sequence
 
@@ -278,6 +279,7 @@ func TestE2EDispatchPrivateUnpinned(t *testing.T) {
 
 	err := bm.Start()
 	assert.NoError(t, err)
+	bm.StartSequencer()
 
 	bm.NewMessages() <- msg.Sequence
 
@@ -317,6 +319,7 @@ func TestDispatchUnknownType(t *testing.T) {
 
 	err := bm.Start()
 	assert.NoError(t, err)
+	bm.StartSequencer()
 
 	cancel()
 	bm.WaitStop()
@@ -1017,3 +1020,12 @@ func TestCancelBatch(t *testing.T) {
 	mdi.AssertExpectations(t)
 	mdm.AssertExpectations(t)
 }
+
+func TestStartWithoutSequencer(t *testing.T) {
+	bm, cancel := newTestBatchManager(t)
+	err := bm.Start()
+	assert.NoError(t, err)
+	cancel()
+	bm.WaitStop()
+	assert.False(t, bm.sequencing)
+}
@@ -30,6 +30,7 @@ import (
 	"github.com/hyperledger/firefly/internal/coremsgs"
 	"github.com/hyperledger/firefly/internal/data"
 	"github.com/hyperledger/firefly/internal/operations"
+	"github.com/hyperledger/firefly/internal/tracing"
 	"github.com/hyperledger/firefly/internal/txcommon"
 	"github.com/hyperledger/firefly/pkg/core"
 	"github.com/hyperledger/firefly/pkg/database"
@@ -398,14 +399,26 @@ func (bp *batchProcessor) assemblyLoop() {
 	}
 }
 
-func (bp *batchProcessor) flush(overflow bool) error {
+func (bp *batchProcessor) flush(overflow bool) (err error) {
 	id, flushWork, byteSize := bp.startFlush(overflow)
 
 	log.L(bp.ctx).Debugf("Flushing batch %s", id)
 	state := bp.initPayload(id, flushWork)
 
+	// The batch continues the traces of all the messages it contains
+	traceContexts := make([]fftypes.JSONObject, len(flushWork))
+	for i, w := range flushWork {
+		traceContexts[i] = w.msg.TraceContext
+	}
+	ctx, span := tracing.StartSpanFrom(bp.ctx, "batch.flush", traceContexts,
+		tracing.NamespaceKey.String(bp.bm.namespace),
+		tracing.BatchIDKey.String(id.String()),
+	)
+	defer func() { tracing.EndSpan(span, err) }()
+	state.Batch.TraceContext = tracing.TraceContext(ctx)
+
 	// Sealing phase: assigns persisted pins to messages, and finalizes the manifest
-	err := bp.sealBatch(state)
+	err = bp.sealBatch(ctx, state)
 	if err != nil {
 		return err
 	}
@@ -414,7 +427,7 @@ func (bp *batchProcessor) flush(overflow bool) error {
 	// Dispatch phase: the heavy lifting work - calling plugins to do the hard work of the batch.
 	//   The dispatcher can update the state, such as appending to the BlobsPublished array,
 	//   to affect DB updates as part of the finalization phase.
-	err = bp.dispatchBatch(state)
+	err = bp.dispatchBatch(ctx, state)
 	if err != nil {
 		return err
 	}
@@ -422,7 +435,7 @@ func (bp *batchProcessor) flush(overflow bool) error {
 
 	// Finalization phase: Writes back the changes to the DB, so that these messages
 	//   are all tagged as part of this batch, and won't be included in any future batches.
-	err = bp.markPayloadDispatched(state)
+	err = bp.markPayloadDispatched(ctx, state)
 	if err != nil {
 		return err
 	}
@@ -547,12 +560,12 @@ func (bp *batchProcessor) updateMessagePins(ctx context.Context, messages []*cor
 	return nil
 }
 
-func (bp *batchProcessor) sealBatch(payload *DispatchPayload) (err error) {
+func (bp *batchProcessor) sealBatch(ctx context.Context, payload *DispatchPayload) (err error) {
 	var state *dispatchState
 	txType := payload.Batch.TX.Type
 
-	err = bp.retry.Do(bp.ctx, "batch persist", func(attempt int) (retry bool, err error) {
-		return true, bp.database.RunAsGroup(bp.ctx, func(ctx context.Context) (err error) {
+	err = bp.retry.Do(ctx, "batch persist", func(attempt int) (retry bool, err error) {
+		return true, bp.database.RunAsGroup(ctx, func(ctx context.Context) (err error) {
 
 			// Clear state from any previous retry. We need to do fresh queries against the DB for nonces.
 			state = &dispatchState{
@@ -616,9 +629,9 @@ func (bp *batchProcessor) sealBatch(payload *DispatchPayload) (err error) {
 	return nil
 }
 
-func (bp *batchProcessor) dispatchBatch(payload *DispatchPayload) error {
+func (bp *batchProcessor) dispatchBatch(ctx context.Context, payload *DispatchPayload) error {
 	// Call the dispatcher to do the heavy lifting - will only exit if we're closed
-	return operations.RunWithOperationContext(bp.ctx, func(ctx context.Context) error {
+	return operations.RunWithOperationContext(ctx, func(ctx context.Context) error {
 		return bp.retry.Do(ctx, "batch dispatch", func(attempt int) (retry bool, err error) {
 			err = bp.conf.dispatch(ctx, payload)
 			if err != nil {
@@ -629,7 +642,7 @@ func (bp *batchProcessor) dispatchBatch(payload *DispatchPayload) error {
 						payload.addMessageUpdate(payload.Messages, core.MessageStateReady, core.MessageStateCancelled)
 						if gapFillPayload != nil {
 							payload.addMessageUpdate(gapFillPayload.Messages, core.MessageStateStaged, core.MessageStateSent)
-							err = bp.dispatchBatch(gapFillPayload)
+							err = bp.dispatchBatch(ctx, gapFillPayload)
 						}
 					}
 				}
@@ -694,7 +707,7 @@ func (bp *batchProcessor) prepareGapFill(ctx context.Context, payload *DispatchP
 	gapFillPayload.Batch.ID = fftypes.NewUUID()
 	log.L(ctx).Infof("Prepared gap fill batch %s", gapFillPayload.Batch.ID)
 
-	err := bp.sealBatch(gapFillPayload)
+	err := bp.sealBatch(ctx, gapFillPayload)
 	if err == nil {
 		log.L(ctx).Infof("Sealed gap fill batch %s", gapFillPayload.Batch.ID)
 	}
@@ -702,9 +715,9 @@ func (bp *batchProcessor) prepareGapFill(ctx context.Context, payload *DispatchP
 	return gapFillPayload, err
 }
 
-func (bp *batchProcessor) markPayloadDispatched(payload *DispatchPayload) error {
-	return bp.retry.Do(bp.ctx, "mark dispatched messages", func(attempt int) (retry bool, err error) {
-		return true, bp.database.RunAsGroup(bp.ctx, func(ctx context.Context) (err error) {
+func (bp *batchProcessor) markPayloadDispatched(ctx context.Context, payload *DispatchPayload) error {
+	return bp.retry.Do(ctx, "mark dispatched messages", func(attempt int) (retry bool, err error) {
+		return true, bp.database.RunAsGroup(ctx, func(ctx context.Context) (err error) {
 			confirmTime := fftypes.Now()
 			for _, state := range payload.MessageUpdates {
 				// Update the message state in the cache
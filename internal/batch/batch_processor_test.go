@@ -147,7 +147,7 @@ func TestHandleDispatchConflictError(t *testing.T) {
 		return &conflictErr
 	})
 	defer cancel()
-	bp.dispatchBatch(&DispatchPayload{})
+	bp.dispatchBatch(bp.ctx, &DispatchPayload{})
 	bp.cancelCtx()
 	<-bp.done
 }
@@ -216,7 +216,7 @@ func TestCloseToUnblockDispatch(t *testing.T) {
 	})
 	defer cancel()
 	bp.cancelCtx()
-	bp.dispatchBatch(&DispatchPayload{})
+	bp.dispatchBatch(bp.ctx, &DispatchPayload{})
 	<-bp.done
 }
 
@@ -282,7 +282,7 @@ func TestInsertNewNonceFail(t *testing.T) {
 	mdm.On("UpdateMessageIfCached", mock.Anything, mock.Anything).Return()
 
 	gid := fftypes.NewRandB32()
-	err := bp.sealBatch(&DispatchPayload{
+	err := bp.sealBatch(bp.ctx, &DispatchPayload{
 		Batch: core.BatchPersisted{
 			BatchHeader: core.BatchHeader{
 				Group: gid,
@@ -325,7 +325,7 @@ func TestUpdateExistingNonceFail(t *testing.T) {
 	mdm.On("UpdateMessageIfCached", mock.Anything, mock.Anything).Return()
 
 	gid := fftypes.NewRandB32()
-	err := bp.sealBatch(&DispatchPayload{
+	err := bp.sealBatch(bp.ctx, &DispatchPayload{
 		Batch: core.BatchPersisted{
 			BatchHeader: core.BatchHeader{
 				Group: gid,
@@ -363,7 +363,7 @@ func TestGetNonceFail(t *testing.T) {
 	mdm.On("UpdateMessageIfCached", mock.Anything, mock.Anything).Return()
 
 	gid := fftypes.NewRandB32()
-	err := bp.sealBatch(&DispatchPayload{
+	err := bp.sealBatch(bp.ctx, &DispatchPayload{
 		Batch: core.BatchPersisted{
 			BatchHeader: core.BatchHeader{
 				Group: gid,
@@ -402,7 +402,7 @@ func TestGetNonceMigrationFail(t *testing.T) {
 	mdm.On("UpdateMessageIfCached", mock.Anything, mock.Anything).Return()
 
 	gid := fftypes.NewRandB32()
-	err := bp.sealBatch(&DispatchPayload{
+	err := bp.sealBatch(bp.ctx, &DispatchPayload{
 		Batch: core.BatchPersisted{
 			BatchHeader: core.BatchHeader{
 				Group: gid,
@@ -662,12 +662,12 @@ func TestMaskContextsRetryAfterPinsAssigned(t *testing.T) {
 	mdi.On("UpdateMessage", mock.Anything, "ns1", msg2.Header.ID, mock.Anything).Return(nil).Once()
 
 	state := bp.initPayload(fftypes.NewUUID(), []*batchWork{{msg: msg1}, {msg: msg2}})
-	err := bp.sealBatch(state)
+	err := bp.sealBatch(bp.ctx, state)
 	assert.NoError(t, err)
 
 	// Second time there should be no additional calls, because now the messages
 	// have pins in there that have been written to the database.
-	err = bp.sealBatch(state)
+	err = bp.sealBatch(bp.ctx, state)
 	assert.NoError(t, err)
 
 	bp.cancelCtx()
@@ -709,7 +709,7 @@ func TestMaskContextsUpdateMessageFail(t *testing.T) {
 	}
 
 	state := bp.initPayload(fftypes.NewUUID(), []*batchWork{{msg: msg}})
-	err := bp.sealBatch(state)
+	err := bp.sealBatch(bp.ctx, state)
 	assert.Regexp(t, "FF00154", err)
 
 	bp.cancelCtx()
@@ -754,7 +754,7 @@ func TestSealBatchTXAlreadyAssigned(t *testing.T) {
 	}
 
 	state := bp.initPayload(fftypes.NewUUID(), []*batchWork{{msg: msg}})
-	err := bp.sealBatch(state)
+	err := bp.sealBatch(bp.ctx, state)
 	assert.NoError(t, err)
 	assert.Equal(t, core.TransactionTypeContractInvokePin, state.Batch.TX.Type)
 	assert.Equal(t, txID, state.Batch.TX.ID)
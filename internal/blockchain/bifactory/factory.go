@@ -31,6 +31,7 @@ import (
 
 var pluginsByType = map[string]func() blockchain.Plugin{
 	(*ethereum.Ethereum)(nil).Name(): func() blockchain.Plugin { return &ethereum.Ethereum{} },
+	(*ethereum.EthRPC)(nil).Name():   func() blockchain.Plugin { return &ethereum.EthRPC{} },
 	(*fabric.Fabric)(nil).Name():     func() blockchain.Plugin { return &fabric.Fabric{} },
 	(*tezos.Tezos)(nil).Name():       func() blockchain.Plugin { return &tezos.Tezos{} },
 }
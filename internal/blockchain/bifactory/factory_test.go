@@ -38,6 +38,13 @@ func TestGetPluginEthereum(t *testing.T) {
 	assert.NotNil(t, plugin)
 }
 
+func TestGetPluginEthRPC(t *testing.T) {
+	ctx := context.Background()
+	plugin, err := GetPlugin(ctx, "ethrpc")
+	assert.NoError(t, err)
+	assert.NotNil(t, plugin)
+}
+
 func TestGetPluginFabric(t *testing.T) {
 	ctx := context.Background()
 	plugin, err := GetPlugin(ctx, "fabric")
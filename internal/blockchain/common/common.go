@@ -22,6 +22,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"regexp"
 	"strings"
 	"sync"
 
@@ -44,6 +45,10 @@ type BlockchainCallbacks interface {
 	SetOperationalHandler(namespace string, handler core.OperationCallbacks)
 
 	OperationUpdate(ctx context.Context, plugin core.Named, nsOpID string, status core.OpStatus, blockchainTXID, errorMessage string, opOutput fftypes.JSONObject)
+	// Returns a page of the pending operations of a plugin in a namespace, if the operation handler of the namespace supports queries
+	GetPendingOperations(ctx context.Context, namespace string, plugin core.Named, opTypes []core.OpType, skip, limit uint64) ([]*core.Operation, error)
+	// Returns a backfill job and its listener, if the handler of the namespace supports looking them up
+	GetContractListenerBackfill(ctx context.Context, namespace string, id *fftypes.UUID) (*core.ContractListenerBackfill, *core.ContractListener, error)
 	// Common logic for parsing a BatchPinOrNetworkAction event, and if not discarded to add it to the by-namespace map
 	PrepareBatchPinOrNetworkAction(ctx context.Context, events EventsToDispatch, subInfo *SubscriptionInfo, location *fftypes.JSONAny, event *blockchain.Event, signingKey *core.VerifierRef, params *BatchPinParams)
 	// Common logic for parsing a BatchPinOrNetworkAction event, and if not discarded to add it to the by-namespace map
@@ -105,6 +110,16 @@ type BlockchainRESTError struct {
 	SubmissionRejected bool `json:"submissionRejected,omitempty"`
 }
 
+// ConnectorStatus is the response to GET /status on an FFTM based connector, such as evmconnect or tezosconnect.
+// Connectors that do not report the head of the chain leave ChainHead unset.
+type ConnectorStatus struct {
+	ChainHead *ConnectorChainHead `json:"chainHead,omitempty"`
+}
+
+type ConnectorChainHead struct {
+	BlockNumber fftypes.FFuint64 `json:"blockNumber"`
+}
+
 type conflictError struct {
 	err error
 }
@@ -166,6 +181,28 @@ func (cb *callbacks) OperationUpdate(ctx context.Context, plugin core.Named, nsO
 	log.L(ctx).Errorf("No handler found for blockchain operation '%s'", nsOpID)
 }
 
+func (cb *callbacks) GetPendingOperations(ctx context.Context, namespace string, plugin core.Named, opTypes []core.OpType, skip, limit uint64) ([]*core.Operation, error) {
+	cb.lock.RLock()
+	handler, ok := cb.opHandlers[namespace].(core.OperationQueryCallbacks)
+	cb.lock.RUnlock()
+	if !ok {
+		log.L(ctx).Debugf("No operation query handler found for namespace '%s'", namespace)
+		return nil, nil
+	}
+	return handler.GetPendingOperations(ctx, plugin.Name(), opTypes, skip, limit)
+}
+
+func (cb *callbacks) GetContractListenerBackfill(ctx context.Context, namespace string, id *fftypes.UUID) (*core.ContractListenerBackfill, *core.ContractListener, error) {
+	cb.lock.RLock()
+	handler, ok := cb.handlers[namespace].(blockchain.BackfillCallbacks)
+	cb.lock.RUnlock()
+	if !ok {
+		log.L(ctx).Debugf("No backfill handler found for namespace '%s'", namespace)
+		return nil, nil, nil
+	}
+	return handler.GetContractListenerBackfill(ctx, id)
+}
+
 func (cb *callbacks) PrepareBatchPinOrNetworkAction(ctx context.Context, events EventsToDispatch, subInfo *SubscriptionInfo, location *fftypes.JSONAny, event *blockchain.Event, signingKey *core.VerifierRef, params *BatchPinParams) {
 	// Check if this is actually an operator action
 	if len(params.Contexts) == 0 && strings.HasPrefix(params.NsOrAction, blockchain.FireFlyActionPrefix) {
@@ -354,6 +391,25 @@ func GetNamespaceFromSubName(subName string) string {
 	return ""
 }
 
+// GetBackfillFromSubName returns the namespace and backfill ID of a temporary backfill subscription,
+// which is named in the format `ff-backfill-<namespace>-<backfill ID>`. Returns nil for any other subscription.
+func GetBackfillFromSubName(subName string) (string, *fftypes.UUID) {
+	withoutPrefix := strings.TrimPrefix(subName, "ff-backfill-")
+	const UUIDLength = 36
+	if len(withoutPrefix) == len(subName) || len(withoutPrefix) <= UUIDLength+1 {
+		return "", nil
+	}
+	uuidSplit := len(withoutPrefix) - UUIDLength - 1
+	if withoutPrefix[uuidSplit] != '-' {
+		return "", nil
+	}
+	backfillID, err := fftypes.ParseUUID(context.Background(), withoutPrefix[uuidSplit+1:])
+	if err != nil {
+		return "", nil
+	}
+	return withoutPrefix[:uuidSplit], backfillID
+}
+
 func (s *subscriptions) AddSubscription(ctx context.Context, namespace *core.Namespace, version int, subID string, extra interface{}) {
 	if version == 1 {
 		// The V1 contract shares a single subscription per contract, and the remote namespace name is passed on chain.
@@ -443,3 +499,76 @@ func WrapRESTError(ctx context.Context, errRes *BlockchainRESTError, res *resty.
 	}
 	return ffresty.WrapRestErr(ctx, res, err, defMsgKey)
 }
+
+// identifierRegexp matches each identifier in an error message, which are compared to the names of the errors
+var identifierRegexp = regexp.MustCompile(`[\w$]+`)
+
+// DecodeNamedError matches an error message against the names of custom errors, for blockchains where contracts
+// raise errors as strings. If the name is followed by a JSON object, such as `InsufficientFunds: {"available": 10}`,
+// the object is returned as the parameters of the error.
+func DecodeNamedError(errors []*fftypes.FFIError, errorMessage string) *core.ContractError {
+	identifiers := identifierRegexp.FindAllStringIndex(errorMessage, -1)
+	for _, ffiError := range errors {
+		if ffiError.Name == "" {
+			continue
+		}
+		var loc []int
+		for _, l := range identifiers {
+			if errorMessage[l[0]:l[1]] == ffiError.Name {
+				loc = l
+				break
+			}
+		}
+		if loc == nil {
+			continue
+		}
+		contractError := &core.ContractError{Name: ffiError.Name}
+		rest := strings.TrimLeft(errorMessage[loc[1]:], ": ")
+		if strings.HasPrefix(rest, "{") {
+			var params fftypes.JSONObject
+			d := json.NewDecoder(strings.NewReader(rest))
+			d.UseNumber()
+			if err := d.Decode(&params); err == nil {
+				contractError.Params = params
+			}
+		}
+		return contractError
+	}
+	return nil
+}
+
+// GetConnectorChainHead queries the status of an FFTM based connector for the head of the chain.
+// Nil is returned if the connector does not report the head of the chain, or has no status API (such as ethconnect).
+func GetConnectorChainHead(ctx context.Context, client *resty.Client, defMsgKey i18n.ErrorMessageKey) (*uint64, error) {
+	var status ConnectorStatus
+	res, err := client.R().
+		SetContext(ctx).
+		SetResult(&status).
+		Get("/status")
+	if err != nil || !res.IsSuccess() {
+		if err == nil && res.StatusCode() == http.StatusNotFound {
+			return nil, nil
+		}
+		return nil, ffresty.WrapRestErr(ctx, res, err, defMsgKey)
+	}
+	if status.ChainHead == nil {
+		return nil, nil
+	}
+	head := uint64(status.ChainHead.BlockNumber)
+	return &head, nil
+}
+
+// SetListenerLag records the head of the chain on the health of a listener, and how many blocks the last block
+// the listener has checkpointed is behind the newest block with enough confirmations.
+// The lag is only known for listeners that have a checkpoint.
+func SetListenerLag(health *core.ContractListenerHealth, head uint64, confirmations int) {
+	health.ChainHead = &head
+	if health.CheckpointBlock == nil {
+		return
+	}
+	var lagBlocks uint64
+	if head >= uint64(confirmations) && head-uint64(confirmations) > *health.CheckpointBlock {
+		lagBlocks = head - uint64(confirmations) - *health.CheckpointBlock
+	}
+	health.LagBlocks = &lagBlocks
+}
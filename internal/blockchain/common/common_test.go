@@ -31,6 +31,7 @@ import (
 	"github.com/hyperledger/firefly/mocks/coremocks"
 	"github.com/hyperledger/firefly/pkg/blockchain"
 	"github.com/hyperledger/firefly/pkg/core"
+	"github.com/jarcoal/httpmock"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 )
@@ -59,6 +60,55 @@ func TestCallbackOperationUpdate(t *testing.T) {
 	mcb.AssertExpectations(t)
 }
 
+func TestCallbackGetPendingOperations(t *testing.T) {
+	mbi := &blockchainmocks.Plugin{}
+	mqcb := &coremocks.OperationQueryCallbacks{}
+	cb := NewBlockchainCallbacks()
+	cb.SetOperationalHandler("ns1", mqcb)
+	cb.SetOperationalHandler("ns2", &coremocks.OperationCallbacks{})
+
+	ops := []*core.Operation{{ID: fftypes.NewUUID()}}
+	opTypes := []core.OpType{core.OpTypeBlockchainInvoke}
+	mbi.On("Name").Return("utblockchain")
+	mqcb.On("GetPendingOperations", mock.Anything, "utblockchain", opTypes, uint64(50), uint64(25)).Return(ops, nil).Once()
+	result, err := cb.GetPendingOperations(context.Background(), "ns1", mbi, opTypes, 50, 25)
+	assert.NoError(t, err)
+	assert.Equal(t, ops, result)
+
+	// Namespaces without a handler that supports queries have nothing to return
+	result, err = cb.GetPendingOperations(context.Background(), "ns2", mbi, opTypes, 0, 25)
+	assert.NoError(t, err)
+	assert.Empty(t, result)
+	result, err = cb.GetPendingOperations(context.Background(), "ns3", mbi, opTypes, 0, 25)
+	assert.NoError(t, err)
+	assert.Empty(t, result)
+
+	mqcb.AssertExpectations(t)
+}
+
+func TestCallbackGetContractListenerBackfill(t *testing.T) {
+	mbcb := &blockchainmocks.BackfillCallbacks{}
+	cb := NewBlockchainCallbacks()
+	cb.SetHandler("ns1", mbcb)
+	cb.SetHandler("ns2", &blockchainmocks.Callbacks{})
+
+	backfill := &core.ContractListenerBackfill{ID: fftypes.NewUUID()}
+	listener := &core.ContractListener{ID: fftypes.NewUUID()}
+	mbcb.On("GetContractListenerBackfill", mock.Anything, backfill.ID).Return(backfill, listener, nil).Once()
+	bf, l, err := cb.GetContractListenerBackfill(context.Background(), "ns1", backfill.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, backfill, bf)
+	assert.Equal(t, listener, l)
+
+	// Namespaces without a handler that supports backfills have nothing to return
+	bf, l, err = cb.GetContractListenerBackfill(context.Background(), "ns2", backfill.ID)
+	assert.NoError(t, err)
+	assert.Nil(t, bf)
+	assert.Nil(t, l)
+
+	mbcb.AssertExpectations(t)
+}
+
 func matchBatchWithEvent(protocolID string) interface{} {
 	return mock.MatchedBy(func(batch []*blockchain.EventToDispatch) bool {
 		return len(batch) == 1 &&
@@ -306,6 +356,24 @@ func TestGetNamespaceFromSubName(t *testing.T) {
 	assert.Equal(t, "", ns)
 }
 
+func TestGetBackfillFromSubName(t *testing.T) {
+	ns, backfillID := GetBackfillFromSubName("ff-backfill-ns-1-03071072-079b-4047-b192-a07186fc9db8")
+	assert.Equal(t, "ns-1", ns)
+	assert.Equal(t, "03071072-079b-4047-b192-a07186fc9db8", backfillID.String())
+
+	_, backfillID = GetBackfillFromSubName("ff-sub-ns1-03071072-079b-4047-b192-a07186fc9db8")
+	assert.Nil(t, backfillID)
+
+	_, backfillID = GetBackfillFromSubName("ff-backfill-03071072-079b-4047-b192-a07186fc9db8")
+	assert.Nil(t, backfillID)
+
+	_, backfillID = GetBackfillFromSubName("ff-backfill-ns1_03071072-079b-4047-b192-a07186fc9db8")
+	assert.Nil(t, backfillID)
+
+	_, backfillID = GetBackfillFromSubName("ff-backfill-ns1-03071072-079b-4047-b192-a07186fc9dbX")
+	assert.Nil(t, backfillID)
+}
+
 func TestSubscriptionsAddRemoveSubscription(t *testing.T) {
 	ns := &core.Namespace{Name: "ns1", NetworkName: "ns1"}
 	subs := NewFireflySubscriptions()
@@ -455,3 +523,77 @@ func TestErrorWrappingNonConflict(t *testing.T) {
 	_, conforms := err.(operations.ConflictError)
 	assert.False(t, conforms)
 }
+
+func TestDecodeNamedError(t *testing.T) {
+	errors := []*fftypes.FFIError{
+		{FFIErrorDefinition: fftypes.FFIErrorDefinition{Name: ""}},
+		{FFIErrorDefinition: fftypes.FFIErrorDefinition{Name: "Funds"}},
+		{FFIErrorDefinition: fftypes.FFIErrorDefinition{Name: "InsufficientFunds"}},
+	}
+
+	contractError := DecodeNamedError(errors, `chaincode response 500, InsufficientFunds: {"available": 10}`)
+	assert.Equal(t, "InsufficientFunds", contractError.Name)
+	assert.Equal(t, `{"available":10}`, contractError.Params.String())
+
+	contractError = DecodeNamedError(errors, `failed with "InsufficientFunds"`)
+	assert.Equal(t, "InsufficientFunds", contractError.Name)
+	assert.Nil(t, contractError.Params)
+
+	contractError = DecodeNamedError(errors, `InsufficientFunds: {bad json`)
+	assert.Equal(t, "InsufficientFunds", contractError.Name)
+	assert.Nil(t, contractError.Params)
+
+	assert.Nil(t, DecodeNamedError(errors, "InsufficientFundsForGas"))
+	assert.Nil(t, DecodeNamedError(errors, "pop"))
+}
+
+func TestSetListenerLag(t *testing.T) {
+	health := &core.ContractListenerHealth{}
+	SetListenerLag(health, 100, 5)
+	assert.Equal(t, uint64(100), *health.ChainHead)
+	assert.Nil(t, health.LagBlocks)
+
+	checkpoint := uint64(90)
+	health = &core.ContractListenerHealth{CheckpointBlock: &checkpoint}
+	SetListenerLag(health, 100, 5)
+	assert.Equal(t, uint64(5), *health.LagBlocks)
+
+	// Caught up with the newest block with enough confirmations
+	SetListenerLag(health, 95, 5)
+	assert.Equal(t, uint64(0), *health.LagBlocks)
+
+	// Fewer blocks on the chain than the number of confirmations
+	SetListenerLag(health, 3, 5)
+	assert.Equal(t, uint64(0), *health.LagBlocks)
+}
+
+func TestGetConnectorChainHead(t *testing.T) {
+	client := resty.New().SetBaseURL("http://localhost:12345")
+	httpmock.ActivateNonDefault(client.GetClient())
+	defer httpmock.DeactivateAndReset()
+
+	httpmock.RegisterResponder("GET", "http://localhost:12345/status",
+		httpmock.NewJsonResponderOrPanic(200, fftypes.JSONObject{
+			"chainHead": fftypes.JSONObject{"blockNumber": "12345"},
+		}))
+	head, err := GetConnectorChainHead(context.Background(), client, coremsgs.MsgEthConnectorRESTErr)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(12345), *head)
+
+	httpmock.RegisterResponder("GET", "http://localhost:12345/status",
+		httpmock.NewJsonResponderOrPanic(200, fftypes.JSONObject{}))
+	head, err = GetConnectorChainHead(context.Background(), client, coremsgs.MsgEthConnectorRESTErr)
+	assert.NoError(t, err)
+	assert.Nil(t, head)
+
+	httpmock.RegisterResponder("GET", "http://localhost:12345/status",
+		httpmock.NewStringResponder(404, "not found"))
+	head, err = GetConnectorChainHead(context.Background(), client, coremsgs.MsgEthConnectorRESTErr)
+	assert.NoError(t, err)
+	assert.Nil(t, head)
+
+	httpmock.RegisterResponder("GET", "http://localhost:12345/status",
+		httpmock.NewStringResponder(500, "pop"))
+	_, err = GetConnectorChainHead(context.Background(), client, coremsgs.MsgEthConnectorRESTErr)
+	assert.Regexp(t, "FF10111.*pop", err)
+}
@@ -24,6 +24,7 @@ import (
 	"regexp"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/go-resty/resty/v2"
 	"github.com/hyperledger/firefly-common/pkg/config"
@@ -39,6 +40,7 @@ import (
 	"github.com/hyperledger/firefly/internal/coreconfig"
 	"github.com/hyperledger/firefly/internal/coremsgs"
 	"github.com/hyperledger/firefly/internal/metrics"
+	"github.com/hyperledger/firefly/internal/tracing"
 	"github.com/hyperledger/firefly/pkg/blockchain"
 	"github.com/hyperledger/firefly/pkg/core"
 	"github.com/sirupsen/logrus"
@@ -52,6 +54,10 @@ const (
 	ethTxStatusPending string = "Pending"
 )
 
+const (
+	defaultBackfillPollingInterval = 1 * time.Second
+)
+
 const (
 	ReceiptTransactionSuccess string = "TransactionSuccess"
 	ReceiptTransactionFailed  string = "TransactionFailed"
@@ -77,6 +83,7 @@ type Ethereum struct {
 	ethconnectConf       config.Section
 	subs                 common.FireflySubscriptions
 	cache                cache.CInterface
+	backfillInterval     time.Duration
 }
 
 type eventStreamWebsocket struct {
@@ -109,6 +116,12 @@ type ListenerStatus struct {
 	Catchup    bool               `json:"catchup"`
 }
 
+// backfillSubscription is the part of a backfill job needed to attribute the events of its temporary subscription
+type backfillSubscription struct {
+	listenerID string
+	toBlock    uint64
+}
+
 type EthconnectMessageRequest struct {
 	Headers EthconnectMessageHeaders `json:"headers,omitempty"`
 	To      string                   `json:"to"`
@@ -168,6 +181,7 @@ func (e *Ethereum) Init(ctx context.Context, cancelCtx context.CancelFunc, conf
 	if err != nil {
 		return err
 	}
+	tracing.InstrumentClient(e.client)
 
 	e.pluginTopic = ethconnectConf.GetString(EthconnectConfigTopic)
 	if e.pluginTopic == "" {
@@ -197,6 +211,7 @@ func (e *Ethereum) Init(ctx context.Context, cancelCtx context.CancelFunc, conf
 	e.closed = make(map[string]chan struct{})
 	e.wsconn = make(map[string]wsclient.WSClient)
 	e.streams = newStreamManager(e.client, e.cache, e.ethconnectConf.GetUint(EthconnectConfigBatchSize), uint(e.ethconnectConf.GetDuration(EthconnectConfigBatchTimeout).Milliseconds()))
+	e.backfillInterval = defaultBackfillPollingInterval
 
 	return nil
 }
@@ -319,6 +334,7 @@ func (e *Ethereum) parseBlockchainEvent(ctx context.Context, msgJSON fftypes.JSO
 	dataJSON := msgJSON.GetObject("data")
 	signature := msgJSON.GetString("signature")
 	name := strings.SplitN(signature, "(", 2)[0]
+	removed := msgJSON.GetBool("removed")
 	timestampStr := msgJSON.GetString("timestamp")
 	timestamp, err := fftypes.ParseTimeString(timestampStr)
 	if err != nil {
@@ -332,6 +348,7 @@ func (e *Ethereum) parseBlockchainEvent(ctx context.Context, msgJSON fftypes.JSO
 	}
 
 	delete(msgJSON, "data")
+	delete(msgJSON, "removed")
 	return &blockchain.Event{
 		BlockchainTXID: sTransactionHash,
 		Source:         e.Name(),
@@ -342,6 +359,7 @@ func (e *Ethereum) parseBlockchainEvent(ctx context.Context, msgJSON fftypes.JSO
 		Timestamp:      timestamp,
 		Location:       e.buildEventLocationString(msgJSON),
 		Signature:      signature,
+		Removed:        removed,
 	}
 }
 
@@ -386,13 +404,30 @@ func (e *Ethereum) processContractEvent(ctx context.Context, events common.Event
 	if err != nil {
 		return err // this is a problem - we should be able to find the listener that dispatched this to us
 	}
-
+	listenerID := subID
 	namespace := common.GetNamespaceFromSubName(subName)
+	if bfNamespace, backfillID := common.GetBackfillFromSubName(subName); backfillID != nil {
+		// Events from a backfill are delivered as if they came from the listener being backfilled
+		bf, err := e.getBackfill(ctx, bfNamespace, backfillID)
+		if err != nil {
+			return err
+		}
+		if bf == nil {
+			log.L(ctx).Warnf("Ignoring event from subscription '%s' of unknown backfill %s", subID, backfillID)
+			return nil
+		}
+		if msgJSON.GetInt64("blockNumber") > int64(bf.toBlock) {
+			return nil
+		}
+		namespace = bfNamespace
+		listenerID = bf.listenerID
+	}
+
 	event := e.parseBlockchainEvent(ctx, msgJSON)
 	if event != nil {
 		e.callbacks.PrepareBlockchainEvent(ctx, events, namespace, &blockchain.EventForListener{
 			Event:      event,
-			ListenerID: subID,
+			ListenerID: listenerID,
 		})
 	}
 	return nil
@@ -417,7 +452,11 @@ func (e *Ethereum) handleMessageBatch(ctx context.Context, batchID int64, messag
 		signature := msgJSON.GetString("signature")
 		sub := msgJSON.GetString("subId")
 		logger := log.L(ctx)
-		logger.Infof("[EVM:%d:%d/%d]: '%s' on '%s'", batchID, i+1, count, signature, sub)
+		if msgJSON.GetBool("removed") {
+			logger.Infof("[EVM:%d:%d/%d]: '%s' on '%s' removed by the connector", batchID, i+1, count, signature, sub)
+		} else {
+			logger.Infof("[EVM:%d:%d/%d]: '%s' on '%s'", batchID, i+1, count, signature, sub)
+		}
 		logger.Tracef("Message: %+v", msgJSON)
 
 		// Matches one of the active FireFly BatchPin subscriptions
@@ -894,37 +933,35 @@ func (e *Ethereum) encodeContractLocation(ctx context.Context, location *Locatio
 	return result, err
 }
 
-func (e *Ethereum) AddContractListener(ctx context.Context, listener *core.ContractListener, lastProtocolID string) (err error) {
-	namespace := listener.Namespace
-	filters := make([]*filter, 0)
+// buildListenerFilters converts the filters of a listener into the form used by the connector
+func (e *Ethereum) buildListenerFilters(ctx context.Context, listener *core.ContractListener) (firstEventABI *abi.Entry, location *Location, filters []*filter, err error) {
+	filters = make([]*filter, 0)
 
 	if len(listener.Filters) == 0 {
-		return i18n.NewError(ctx, coremsgs.MsgFiltersEmpty, listener.Name)
+		return nil, nil, nil, i18n.NewError(ctx, coremsgs.MsgFiltersEmpty, listener.Name)
 	}
 
 	// For ethconnect we need to use one event and one location as it does not support filters
 	// Note: the first filter event gets copied to the root of the listener for backwards
 	// compatibility so available here
 	// it will be ignored by evmconnect
-	var firstEventABI *abi.Entry
 	firstEventABI, err = ffi2abi.ConvertFFIEventDefinitionToABI(ctx, &listener.Filters[0].Event.FFIEventDefinition)
 	if err != nil {
-		return i18n.WrapError(ctx, err, coremsgs.MsgContractParamInvalid)
+		return nil, nil, nil, i18n.WrapError(ctx, err, coremsgs.MsgContractParamInvalid)
 	}
 
 	// First filter location is copied over to the root
-	var location *Location
 	if listener.Filters[0].Location != nil {
 		location, err = e.parseContractLocation(ctx, listener.Filters[0].Location)
 		if err != nil {
-			return err
+			return nil, nil, nil, err
 		}
 	}
 
 	for _, f := range listener.Filters {
 		abi, err := ffi2abi.ConvertFFIEventDefinitionToABI(ctx, &f.Event.FFIEventDefinition)
 		if err != nil {
-			return i18n.WrapError(ctx, err, coremsgs.MsgContractParamInvalid)
+			return nil, nil, nil, i18n.WrapError(ctx, err, coremsgs.MsgContractParamInvalid)
 		}
 		evmFilter := &filter{
 			Event: abi,
@@ -932,19 +969,30 @@ func (e *Ethereum) AddContractListener(ctx context.Context, listener *core.Contr
 		if f.Location != nil {
 			location, err := e.parseContractLocation(ctx, f.Location)
 			if err != nil {
-				return err
+				return nil, nil, nil, err
 			}
 			evmFilter.Address = location.Address
 		}
 		filters = append(filters, evmFilter)
 	}
+	return firstEventABI, location, filters, nil
+}
+
+func (e *Ethereum) AddContractListener(ctx context.Context, listener *core.ContractListener, lastProtocolID string) (err error) {
+	namespace := listener.Namespace
+	firstEventABI, location, filters, err := e.buildListenerFilters(ctx, listener)
+	if err != nil {
+		return err
+	}
 
 	subName := fmt.Sprintf("ff-sub-%s-%s", listener.Namespace, listener.ID)
 	firstEvent := string(core.SubOptsFirstEventNewest)
+	confirmations := 0
 	if listener.Options != nil {
 		firstEvent = listener.Options.FirstEvent
+		confirmations = listener.Options.Confirmations
 	}
-	result, err := e.streams.createSubscription(ctx, e.streamID[namespace], subName, firstEvent, location, firstEventABI, filters, lastProtocolID)
+	result, err := e.streams.createSubscription(ctx, e.streamID[namespace], subName, firstEvent, confirmations, location, firstEventABI, filters, lastProtocolID)
 	if err != nil {
 		return err
 	}
@@ -965,11 +1013,9 @@ func (e *Ethereum) GetContractListenerStatus(ctx context.Context, namespace, sub
 
 	checkpoint := &ListenerStatus{
 		Catchup: sub.Catchup,
-		Checkpoint: ListenerCheckpoint{
-			Block:            sub.Checkpoint.Block,
-			TransactionIndex: sub.Checkpoint.TransactionIndex,
-			LogIndex:         sub.Checkpoint.LogIndex,
-		},
+	}
+	if sub.Checkpoint != nil {
+		checkpoint.Checkpoint = *sub.Checkpoint
 	}
 
 	// reduce checkpoint data to a single enum
@@ -981,6 +1027,134 @@ func (e *Ethereum) GetContractListenerStatus(ctx context.Context, namespace, sub
 	return true, checkpoint, status, nil
 }
 
+func (e *Ethereum) GetContractListenerHealth(ctx context.Context, namespace, subID string) (*core.ContractListenerHealth, error) {
+	esID := e.streamID[namespace]
+	sub, err := e.streams.getSubscription(ctx, subID, false)
+	if err != nil {
+		return nil, err
+	}
+	health := &core.ContractListenerHealth{Status: core.ContractListenerStatusUnknown}
+	if sub.Stream != esID {
+		return health, nil
+	}
+	health.Status = core.ContractListenerStatusSynced
+	if sub.Catchup {
+		health.Status = core.ContractListenerStatusSyncing
+	}
+	// Ethconnect does not report a checkpoint, or the head of the chain, so the lag is only known with evmconnect
+	if sub.Checkpoint != nil && sub.Checkpoint.Block > 0 {
+		block := uint64(sub.Checkpoint.Block)
+		health.CheckpointBlock = &block
+	}
+	head, err := common.GetConnectorChainHead(ctx, e.client, coremsgs.MsgEthConnectorRESTErr)
+	if err != nil {
+		return nil, err
+	}
+	if head != nil {
+		common.SetListenerLag(health, *head, sub.Confirmations)
+	}
+	return health, nil
+}
+
+// BackfillContractListener creates a temporary subscription on the connector with the same filters as the listener,
+// starting at fromBlock, and polls its checkpoint until it has passed toBlock. The temporary subscription is named after
+// the backfill, so its events are delivered with the ID of the listener being backfilled, and any beyond toBlock are dropped.
+// The subscription is left in place if the context is cancelled, so that a backfill resumed after a restart can reuse it.
+// Requires a connector that reports subscription checkpoints (evmconnect) - otherwise progress could never be tracked.
+func (e *Ethereum) BackfillContractListener(ctx context.Context, listener *core.ContractListener, backfill *core.ContractListenerBackfill, started func(backendID string) error, progress func(block uint64)) error {
+	firstEventABI, location, filters, err := e.buildListenerFilters(ctx, listener)
+	if err != nil {
+		return err
+	}
+	existing, err := e.streams.getSubscription(ctx, listener.BackendID, false)
+	if err != nil {
+		return err
+	}
+	if existing.Checkpoint == nil {
+		return i18n.NewError(ctx, coremsgs.MsgNotSupportedByBlockchainPlugin)
+	}
+
+	var sub *subscription
+	if backfill.BackendID != "" {
+		if sub, err = e.streams.getSubscription(ctx, backfill.BackendID, true); err != nil {
+			return err
+		}
+	}
+	if sub == nil {
+		fromBlock := backfill.FromBlock
+		if backfill.CurrentBlock != nil {
+			fromBlock = *backfill.CurrentBlock + 1
+		}
+		confirmations := 0
+		if listener.Options != nil {
+			confirmations = listener.Options.Confirmations
+		}
+		subName := fmt.Sprintf("ff-backfill-%s-%s", listener.Namespace, backfill.ID)
+		sub, err = e.streams.createSubscription(ctx, e.streamID[listener.Namespace], subName, strconv.FormatUint(fromBlock, 10), confirmations, location, firstEventABI, filters, "")
+		if err != nil {
+			return err
+		}
+		if err := started(sub.ID); err != nil {
+			e.deleteBackfillSubscription(ctx, sub.ID)
+			return err
+		}
+	}
+	defer func() {
+		if ctx.Err() == nil {
+			e.deleteBackfillSubscription(ctx, sub.ID)
+		}
+	}()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return i18n.NewError(ctx, coremsgs.MsgContextCanceled)
+		case <-time.After(e.backfillInterval):
+		}
+		status, err := e.streams.getSubscription(ctx, sub.ID, false)
+		if err != nil {
+			return err
+		}
+		if status.Checkpoint == nil {
+			// No blocks have been processed by the subscription yet
+			continue
+		}
+		block := status.Checkpoint.Block
+		if block > int64(backfill.ToBlock) || (block == int64(backfill.ToBlock) && !status.Catchup) {
+			progress(backfill.ToBlock)
+			return nil
+		}
+		if block >= int64(backfill.FromBlock) {
+			progress(uint64(block))
+		}
+	}
+}
+
+func (e *Ethereum) deleteBackfillSubscription(ctx context.Context, subID string) {
+	if err := e.streams.deleteSubscription(e.ctx, subID, true); err != nil {
+		log.L(ctx).Errorf("Failed to delete backfill subscription %s: %s", subID, err)
+	}
+}
+
+// getBackfill looks up the listener and range of a backfill from FireFly core, as the events of its temporary
+// subscription can arrive after a restart
+func (e *Ethereum) getBackfill(ctx context.Context, namespace string, backfillID *fftypes.UUID) (*backfillSubscription, error) {
+	cacheKey := "backfill:" + backfillID.String()
+	if cached, ok := e.cache.Get(cacheKey).(*backfillSubscription); ok {
+		return cached, nil
+	}
+	backfill, listener, err := e.callbacks.GetContractListenerBackfill(ctx, namespace, backfillID)
+	if err != nil || backfill == nil {
+		return nil, err
+	}
+	bf := &backfillSubscription{
+		listenerID: listener.BackendID,
+		toBlock:    backfill.ToBlock,
+	}
+	e.cache.Set(cacheKey, bf)
+	return bf, nil
+}
+
 func (e *Ethereum) GetFFIParamValidator(ctx context.Context) (fftypes.FFIParamValidator, error) {
 	return &ffi2abi.ParamValidator{}, nil
 }
@@ -1045,6 +1219,92 @@ func (e *Ethereum) GenerateErrorSignature(ctx context.Context, errorDef *fftypes
 	return ffi2abi.ABIMethodToSignature(abi)
 }
 
+var revertDataRegexp = regexp.MustCompile(`0x[0-9a-fA-F]{8,}`)
+
+var errorSerializer = abi.NewSerializer().
+	SetFormattingMode(abi.FormatAsObjects).
+	SetIntSerializer(abi.Base10StringIntSerializer).
+	SetByteSerializer(abi.HexByteSerializer0xPrefix).
+	SetAddressSerializer(abi.HexAddrSerializer0xPrefix)
+
+// DecodeContractError looks for revert data in the error message, or for an error that the connector
+// has already decoded against the errors passed on the request, such as `MyError("0x1234","10")`
+func (e *Ethereum) DecodeContractError(ctx context.Context, errors []*fftypes.FFIError, errorMessage string) *core.ContractError {
+	for _, ffiError := range errors {
+		errorABI, err := ffi2abi.ConvertFFIErrorDefinitionToABI(ctx, &ffiError.FFIErrorDefinition)
+		if err != nil {
+			log.L(ctx).Warnf("Unable to convert error '%s' to ABI: %s", ffiError.Name, err)
+			continue
+		}
+		params := decodeRevertData(ctx, errorABI, errorMessage)
+		if params == nil {
+			params = parseErrorString(errorABI, errorMessage)
+		}
+		if params != nil {
+			return &core.ContractError{
+				Name:      ffiError.Name,
+				Signature: ffi2abi.ABIMethodToSignature(errorABI),
+				Params:    params,
+			}
+		}
+	}
+	return nil
+}
+
+func decodeRevertData(ctx context.Context, errorABI *abi.Entry, errorMessage string) fftypes.JSONObject {
+	for _, match := range revertDataRegexp.FindAllString(errorMessage, -1) {
+		revertData, err := hex.DecodeString(match[2:])
+		if err != nil {
+			continue
+		}
+		cv, err := errorABI.DecodeCallDataCtx(ctx, revertData)
+		if err != nil {
+			continue
+		}
+		if params, err := errorSerializer.SerializeInterfaceCtx(ctx, cv); err == nil {
+			if obj, ok := params.(map[string]interface{}); ok {
+				return obj
+			}
+		}
+	}
+	return nil
+}
+
+// errorCallRegexp matches an identifier followed by an opening bracket, which is compared to the name of the error
+var errorCallRegexp = regexp.MustCompile(`([\w$]+)\(`)
+
+// parseErrorString parses the format used by firefly-signer to stringify a decoded error, where each
+// parameter is JSON encoded. Each closing bracket is tried in turn, as parameters may contain brackets.
+func parseErrorString(errorABI *abi.Entry, errorMessage string) fftypes.JSONObject {
+	for _, loc := range errorCallRegexp.FindAllStringSubmatchIndex(errorMessage, -1) {
+		if errorMessage[loc[2]:loc[3]] != errorABI.Name {
+			continue
+		}
+		args := errorMessage[loc[1]:]
+		for end, c := range args {
+			if c != ')' {
+				continue
+			}
+			var values []interface{}
+			d := json.NewDecoder(strings.NewReader("[" + args[:end] + "]"))
+			d.UseNumber()
+			if err := d.Decode(&values); err != nil || len(values) != len(errorABI.Inputs) {
+				continue
+			}
+			params := fftypes.JSONObject{}
+			for i, input := range errorABI.Inputs {
+				name := input.Name
+				if name == "" {
+					name = strconv.Itoa(i)
+				}
+				params[name] = values[i]
+			}
+			return params
+		}
+	}
+	return nil
+}
+
 type parsedFFIMethod struct {
 	methodABI *abi.Entry
 	errorsABI []*abi.Entry
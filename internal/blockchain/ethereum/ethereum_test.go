@@ -33,6 +33,9 @@ import (
 	"github.com/hyperledger/firefly-common/pkg/fftypes"
 	"github.com/hyperledger/firefly-common/pkg/log"
 	"github.com/hyperledger/firefly-common/pkg/wsclient"
+	"github.com/hyperledger/firefly-signer/pkg/abi"
+	"github.com/hyperledger/firefly-signer/pkg/ethtypes"
+	"github.com/hyperledger/firefly-signer/pkg/ffi2abi"
 	"github.com/hyperledger/firefly/internal/blockchain/common"
 	"github.com/hyperledger/firefly/internal/cache"
 	"github.com/hyperledger/firefly/internal/coreconfig"
@@ -143,6 +146,7 @@ func newTestEthereum() (*Ethereum, func()) {
 		streams: &streamManager{
 			client: r,
 		},
+		backfillInterval: 1 * time.Millisecond,
 	}
 	return e, func() {
 		cancel()
@@ -2024,12 +2028,19 @@ func TestAddSubscription(t *testing.T) {
 			},
 		},
 		Options: &core.ContractListenerOptions{
-			FirstEvent: string(core.SubOptsFirstEventOldest),
+			FirstEvent:    string(core.SubOptsFirstEventOldest),
+			Confirmations: 5,
 		},
 	}
 
 	httpmock.RegisterResponder("POST", `http://localhost:12345/subscriptions`,
-		httpmock.NewJsonResponderOrPanic(200, &subscription{}))
+		func(req *http.Request) (*http.Response, error) {
+			var body subscription
+			err := json.NewDecoder(req.Body).Decode(&body)
+			assert.NoError(t, err)
+			assert.Equal(t, 5, body.Confirmations)
+			return httpmock.NewJsonResponderOrPanic(200, &subscription{})(req)
+		})
 
 	err := e.AddContractListener(context.Background(), sub, "")
 
@@ -2673,6 +2684,190 @@ func TestHandleMessageContractEventWithNamespace(t *testing.T) {
 	em.AssertExpectations(t)
 }
 
+func TestHandleMessageContractEventBackfill(t *testing.T) {
+	data := fftypes.JSONAnyPtr(`
+[
+  {
+		"address": "0x1C197604587F046FD40684A8f21f4609FB811A7b",
+		"blockNumber": "38011",
+		"transactionIndex": "0x0",
+		"transactionHash": "0xc26df2bf1a733e9249372d61eb11bd8662d26c8129df76890b1beb2f6fa72628",
+		"data": {
+			"from": "0x91D2B4381A4CD5C7C0F27565A7D4B829844C8635",
+			"value": "1"
+    },
+		"subId": "backfill1",
+		"signature": "Changed(address,uint256)",
+		"logIndex": "50",
+		"timestamp": "1640811383"
+  },
+	{
+		"address": "0x1C197604587F046FD40684A8f21f4609FB811A7b",
+		"blockNumber": "38012",
+		"transactionIndex": "0x0",
+		"transactionHash": "0xc26df2bf1a733e9249372d61eb11bd8662d26c8129df76890b1beb2f6fa72638",
+		"data": {
+			"from": "0x91D2B4381A4CD5C7C0F27565A7D4B829844C8635",
+			"value": "2"
+    },
+		"subId": "backfill1",
+		"signature": "Changed(address,uint256)",
+		"logIndex": "50",
+		"timestamp": "1640811384"
+  }
+]`)
+
+	em := &blockchainmocks.BackfillCallbacks{}
+	e, cancel := newTestEthereum()
+	defer cancel()
+
+	backfillID := fftypes.NewUUID()
+	httpmock.ActivateNonDefault(e.client.GetClient())
+	defer httpmock.DeactivateAndReset()
+	httpmock.RegisterResponder("GET", "http://localhost:12345/subscriptions/backfill1",
+		httpmock.NewJsonResponderOrPanic(200, subscription{
+			ID: "backfill1", Stream: "es12345", Name: "ff-backfill-ns1-" + backfillID.String(),
+		}))
+	e.streams = newTestStreamManager(e.client)
+
+	em.On("GetContractListenerBackfill", mock.Anything, backfillID).Return(&core.ContractListenerBackfill{
+		ID:      backfillID,
+		ToBlock: 38011,
+	}, &core.ContractListener{
+		BackendID: "sub2",
+	}, nil).Once()
+	e.callbacks = common.NewBlockchainCallbacks()
+	e.SetHandler("ns1", em)
+
+	em.On("BlockchainEventBatch", mock.MatchedBy(func(batch []*blockchain.EventToDispatch) bool {
+		return len(batch) == 1
+	})).Return(nil)
+
+	var events []interface{}
+	err := json.Unmarshal(data.Bytes(), &events)
+	assert.NoError(t, err)
+	err = e.handleMessageBatch(context.Background(), 0, events)
+	assert.NoError(t, err)
+
+	ev := em.Calls[1].Arguments[0].([]*blockchain.EventToDispatch)[0]
+	assert.Equal(t, "sub2", ev.ForListener.ListenerID)
+	assert.Equal(t, "1", ev.ForListener.Event.Output.GetString("value"))
+
+	// The backfill is cached for later events from the same subscription
+	err = e.handleMessageBatch(context.Background(), 0, events)
+	assert.NoError(t, err)
+
+	em.AssertExpectations(t)
+}
+
+func TestHandleMessageContractEventBackfillUnknown(t *testing.T) {
+	em := &blockchainmocks.BackfillCallbacks{}
+	e, cancel := newTestEthereum()
+	defer cancel()
+
+	backfillID := fftypes.NewUUID()
+	httpmock.ActivateNonDefault(e.client.GetClient())
+	defer httpmock.DeactivateAndReset()
+	httpmock.RegisterResponder("GET", "http://localhost:12345/subscriptions/backfill1",
+		httpmock.NewJsonResponderOrPanic(200, subscription{
+			ID: "backfill1", Stream: "es12345", Name: "ff-backfill-ns1-" + backfillID.String(),
+		}))
+	e.streams = newTestStreamManager(e.client)
+
+	em.On("GetContractListenerBackfill", mock.Anything, backfillID).Return(nil, nil, nil)
+	e.callbacks = common.NewBlockchainCallbacks()
+	e.SetHandler("ns1", em)
+
+	err := e.processContractEvent(context.Background(), common.EventsToDispatch{}, fftypes.JSONObject{
+		"subId":       "backfill1",
+		"blockNumber": "38011",
+	})
+	assert.NoError(t, err)
+
+	em.AssertExpectations(t)
+}
+
+func TestHandleMessageContractEventBackfillLookupFail(t *testing.T) {
+	em := &blockchainmocks.BackfillCallbacks{}
+	e, cancel := newTestEthereum()
+	defer cancel()
+
+	backfillID := fftypes.NewUUID()
+	httpmock.ActivateNonDefault(e.client.GetClient())
+	defer httpmock.DeactivateAndReset()
+	httpmock.RegisterResponder("GET", "http://localhost:12345/subscriptions/backfill1",
+		httpmock.NewJsonResponderOrPanic(200, subscription{
+			ID: "backfill1", Stream: "es12345", Name: "ff-backfill-ns1-" + backfillID.String(),
+		}))
+	e.streams = newTestStreamManager(e.client)
+
+	em.On("GetContractListenerBackfill", mock.Anything, backfillID).Return(nil, nil, fmt.Errorf("pop"))
+	e.callbacks = common.NewBlockchainCallbacks()
+	e.SetHandler("ns1", em)
+
+	err := e.processContractEvent(context.Background(), common.EventsToDispatch{}, fftypes.JSONObject{
+		"subId":       "backfill1",
+		"blockNumber": "38011",
+	})
+	assert.EqualError(t, err, "pop")
+
+	em.AssertExpectations(t)
+}
+
+func TestHandleMessageContractEventRemoved(t *testing.T) {
+	data := fftypes.JSONAnyPtr(`
+[
+  {
+		"address": "0x1C197604587F046FD40684A8f21f4609FB811A7b",
+		"blockNumber": "38011",
+		"transactionIndex": "0x0",
+		"transactionHash": "0xc26df2bf1a733e9249372d61eb11bd8662d26c8129df76890b1beb2f6fa72628",
+		"data": {
+			"from": "0x91D2B4381A4CD5C7C0F27565A7D4B829844C8635",
+			"value": "1"
+    },
+		"subId": "sub2",
+		"signature": "Changed(address,uint256)",
+		"logIndex": "50",
+		"timestamp": "1640811383",
+		"removed": true
+  }
+]`)
+
+	em := &blockchainmocks.Callbacks{}
+	e, cancel := newTestEthereum()
+	defer cancel()
+	httpmock.ActivateNonDefault(e.client.GetClient())
+	defer httpmock.DeactivateAndReset()
+
+	httpmock.RegisterResponder("GET", "http://localhost:12345/subscriptions/sub2",
+		httpmock.NewJsonResponderOrPanic(200, subscription{
+			ID: "sub2", Stream: "es12345", Name: "ff-sub-ns1-1132312312312",
+		}))
+
+	e.callbacks = common.NewBlockchainCallbacks()
+	e.SetHandler("ns1", em)
+	e.streams = newTestStreamManager(e.client)
+
+	em.On("BlockchainEventBatch", mock.MatchedBy(func(batch []*blockchain.EventToDispatch) bool {
+		return len(batch) == 1
+	})).Return(nil)
+
+	var events []interface{}
+	err := json.Unmarshal(data.Bytes(), &events)
+	assert.NoError(t, err)
+	err = e.handleMessageBatch(context.Background(), 0, events)
+	assert.NoError(t, err)
+
+	ev := em.Calls[0].Arguments[0].([]*blockchain.EventToDispatch)[0]
+	assert.Equal(t, "sub2", ev.ForListener.ListenerID)
+	assert.Equal(t, "000000038011/000000/000050", ev.ForListener.Event.ProtocolID)
+	assert.True(t, ev.ForListener.Event.Removed)
+	assert.NotContains(t, ev.ForListener.Event.Info, "removed")
+
+	em.AssertExpectations(t)
+}
+
 func TestHandleMessageContractEventNoNamespaceHandlers(t *testing.T) {
 	data := fftypes.JSONAnyPtr(`
 [
@@ -3842,6 +4037,74 @@ func TestGenerateErrorSignatureInvalid(t *testing.T) {
 	assert.Equal(t, "", signature)
 }
 
+func TestDecodeContractError(t *testing.T) {
+	e, _ := newTestEthereum()
+	errors := []*fftypes.FFIError{
+		{
+			FFIErrorDefinition: fftypes.FFIErrorDefinition{
+				Name: "BadError",
+				Params: []*fftypes.FFIParam{
+					{
+						Name:   "x",
+						Schema: fftypes.JSONAnyPtr(`{"!bad": "bad"`),
+					},
+				},
+			},
+		},
+		{
+			FFIErrorDefinition: fftypes.FFIErrorDefinition{
+				Name: "InsufficientBalance",
+				Params: []*fftypes.FFIParam{
+					{
+						Name:   "available",
+						Schema: fftypes.JSONAnyPtr(`{"type": "integer", "details": {"type": "uint256"}}`),
+					},
+					{
+						Name:   "owner",
+						Schema: fftypes.JSONAnyPtr(`{"type": "string", "details": {"type": "address"}}`),
+					},
+				},
+			},
+		},
+	}
+	errorABI, err := ffi2abi.ConvertFFIErrorDefinitionToABI(context.Background(), &errors[1].FFIErrorDefinition)
+	assert.NoError(t, err)
+	revertData, err := errorABI.EncodeCallDataJSON([]byte(`{"available":"10","owner":"0x1f9090aae28b8a3dceadf281b0f12828e676c326"}`))
+	assert.NoError(t, err)
+
+	// Revert data that has not been decoded by the connector
+	contractError := e.DecodeContractError(context.Background(), errors, fmt.Sprintf("FF23021: EVM reverted: 0x1234 %s", ethtypes.HexBytes0xPrefix(revertData)))
+	assert.Equal(t, "InsufficientBalance", contractError.Name)
+	assert.Equal(t, "InsufficientBalance(uint256,address)", contractError.Signature)
+	assert.Equal(t, `{"available":"10","owner":"0x1f9090aae28b8a3dceadf281b0f12828e676c326"}`, contractError.Params.String())
+
+	// An error already decoded by the connector
+	contractError = e.DecodeContractError(context.Background(), errors, `FF23021: EVM reverted: InsufficientBalance("10","0x1f9090aae28b8a3dceadf281b0f12828e676c326")`)
+	assert.Equal(t, "InsufficientBalance", contractError.Name)
+	assert.Equal(t, `{"available":"10","owner":"0x1f9090aae28b8a3dceadf281b0f12828e676c326"}`, contractError.Params.String())
+
+	// Closing brackets within parameters
+	contractError = e.DecodeContractError(context.Background(), errors, `reverted: InsufficientBalance(")","(")`)
+	assert.Equal(t, `{"available":")","owner":"("}`, contractError.Params.String())
+
+	assert.Nil(t, e.DecodeContractError(context.Background(), errors, `MyInsufficientBalance("10","0x1f9090aae28b8a3dceadf281b0f12828e676c326")`))
+	assert.Nil(t, e.DecodeContractError(context.Background(), errors, `InsufficientBalance("10")`))
+	assert.Nil(t, e.DecodeContractError(context.Background(), errors, "FF23021: EVM reverted: 0x08c379a0 0x123456789"))
+}
+
+func TestParseErrorStringUnnamedParams(t *testing.T) {
+	errorABI := &abi.Entry{
+		Type:   abi.Error,
+		Name:   "Failed",
+		Inputs: abi.ParameterArray{{Type: "uint256"}},
+	}
+	params := parseErrorString(errorABI, `Failed("10")`)
+	assert.Equal(t, `{"0":"10"}`, params.String())
+
+	params = parseErrorString(errorABI, `reverted: Wrapped(Failed("20"))`)
+	assert.Equal(t, `{"0":"20"}`, params.String())
+}
+
 func TestSubmitNetworkAction(t *testing.T) {
 	e, _ := newTestEthereum()
 	httpmock.ActivateNonDefault(e.client.GetClient())
@@ -4577,6 +4840,422 @@ func TestAddFireflySubscriptionGetVersionError(t *testing.T) {
 	assert.Regexp(t, "FF10111", err)
 }
 
+func testBackfillListener() *core.ContractListener {
+	return &core.ContractListener{
+		ID:        fftypes.NewUUID(),
+		Namespace: "ns1",
+		BackendID: "sub1",
+		Filters: []*core.ListenerFilter{
+			{
+				Event: &core.FFISerializedEvent{
+					FFIEventDefinition: fftypes.FFIEventDefinition{
+						Name: "Changed",
+						Params: fftypes.FFIParams{
+							{
+								Name:   "value",
+								Schema: fftypes.JSONAnyPtr(`{"type": "integer", "details": {"type": "uint256"}}`),
+							},
+						},
+					},
+				},
+				Location: fftypes.JSONAnyPtr(fftypes.JSONObject{
+					"address": "0x123",
+				}.String()),
+			},
+		},
+		Options: &core.ContractListenerOptions{
+			Confirmations: 5,
+		},
+	}
+}
+
+func mockBackfillListenerCheckpoint() {
+	httpmock.RegisterResponder("GET", `http://localhost:12345/subscriptions/sub1`,
+		httpmock.NewJsonResponderOrPanic(200, &subscription{
+			ID:                     "sub1",
+			subscriptionCheckpoint: subscriptionCheckpoint{Checkpoint: &ListenerCheckpoint{Block: 1000}},
+		}))
+}
+
+func testBackfill() *core.ContractListenerBackfill {
+	return &core.ContractListenerBackfill{
+		ID:        fftypes.MustParseUUID("4f0e1ab6-0d7b-4a3c-9ff5-0e3c0f8e2b11"),
+		FromBlock: 100,
+		ToBlock:   200,
+	}
+}
+
+func noStarted(backendID string) error {
+	return nil
+}
+
+func TestBackfillContractListener(t *testing.T) {
+	e, cancel := newTestEthereum()
+	defer cancel()
+	httpmock.ActivateNonDefault(e.client.GetClient())
+	defer httpmock.DeactivateAndReset()
+	e.streamID["ns1"] = "es-1"
+	mockBackfillListenerCheckpoint()
+
+	httpmock.RegisterResponder("POST", `http://localhost:12345/subscriptions`,
+		func(req *http.Request) (*http.Response, error) {
+			var body subscription
+			err := json.NewDecoder(req.Body).Decode(&body)
+			assert.NoError(t, err)
+			assert.Equal(t, "es-1", body.Stream)
+			assert.Equal(t, "100", body.FromBlock)
+			assert.Equal(t, 5, body.Confirmations)
+			assert.Equal(t, "ff-backfill-ns1-4f0e1ab6-0d7b-4a3c-9ff5-0e3c0f8e2b11", body.Name)
+			return httpmock.NewJsonResponderOrPanic(200, &subscription{ID: "backfill1"})(req)
+		})
+	checkpoints := []subscription{
+		{subscriptionCheckpoint: subscriptionCheckpoint{Catchup: true}},
+		{subscriptionCheckpoint: subscriptionCheckpoint{Checkpoint: &ListenerCheckpoint{Block: 0}, Catchup: true}},
+		{subscriptionCheckpoint: subscriptionCheckpoint{Checkpoint: &ListenerCheckpoint{Block: 150}, Catchup: true}},
+		{subscriptionCheckpoint: subscriptionCheckpoint{Checkpoint: &ListenerCheckpoint{Block: 201}, Catchup: true}},
+	}
+	polls := 0
+	httpmock.RegisterResponder("GET", `http://localhost:12345/subscriptions/backfill1`,
+		func(req *http.Request) (*http.Response, error) {
+			sub := checkpoints[polls]
+			polls++
+			return httpmock.NewJsonResponderOrPanic(200, &sub)(req)
+		})
+	httpmock.RegisterResponder("DELETE", `http://localhost:12345/subscriptions/backfill1`,
+		httpmock.NewStringResponder(204, ""))
+
+	var started string
+	var progress []uint64
+	err := e.BackfillContractListener(context.Background(), testBackfillListener(), testBackfill(), func(backendID string) error {
+		started = backendID
+		return nil
+	}, func(block uint64) {
+		progress = append(progress, block)
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "backfill1", started)
+	assert.Equal(t, []uint64{150, 200}, progress)
+	assert.Equal(t, 1, httpmock.GetCallCountInfo()["DELETE http://localhost:12345/subscriptions/backfill1"])
+}
+
+func TestBackfillContractListenerResume(t *testing.T) {
+	e, cancel := newTestEthereum()
+	defer cancel()
+	httpmock.ActivateNonDefault(e.client.GetClient())
+	defer httpmock.DeactivateAndReset()
+	e.streamID["ns1"] = "es-1"
+	mockBackfillListenerCheckpoint()
+
+	// The subscription of the interrupted backfill still exists, so is reused
+	httpmock.RegisterResponder("GET", `http://localhost:12345/subscriptions/backfill1`,
+		httpmock.NewJsonResponderOrPanic(200, &subscription{
+			ID:                     "backfill1",
+			subscriptionCheckpoint: subscriptionCheckpoint{Checkpoint: &ListenerCheckpoint{Block: 200}},
+		}))
+	httpmock.RegisterResponder("DELETE", `http://localhost:12345/subscriptions/backfill1`,
+		httpmock.NewStringResponder(204, ""))
+
+	backfill := testBackfill()
+	backfill.BackendID = "backfill1"
+	var progress []uint64
+	err := e.BackfillContractListener(context.Background(), testBackfillListener(), backfill, func(backendID string) error {
+		assert.Fail(t, "no subscription should be created")
+		return nil
+	}, func(block uint64) {
+		progress = append(progress, block)
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []uint64{200}, progress)
+	assert.Zero(t, httpmock.GetCallCountInfo()["POST http://localhost:12345/subscriptions"])
+}
+
+func TestBackfillContractListenerResumeRecreate(t *testing.T) {
+	e, cancel := newTestEthereum()
+	defer cancel()
+	httpmock.ActivateNonDefault(e.client.GetClient())
+	defer httpmock.DeactivateAndReset()
+	e.streamID["ns1"] = "es-1"
+	mockBackfillListenerCheckpoint()
+
+	// The subscription of the interrupted backfill has gone, so is created again after the last block delivered
+	httpmock.RegisterResponder("GET", `http://localhost:12345/subscriptions/backfill1`,
+		httpmock.NewStringResponder(404, ""))
+	httpmock.RegisterResponder("POST", `http://localhost:12345/subscriptions`,
+		func(req *http.Request) (*http.Response, error) {
+			var body subscription
+			err := json.NewDecoder(req.Body).Decode(&body)
+			assert.NoError(t, err)
+			assert.Equal(t, "151", body.FromBlock)
+			return httpmock.NewJsonResponderOrPanic(200, &subscription{ID: "backfill2"})(req)
+		})
+	httpmock.RegisterResponder("GET", `http://localhost:12345/subscriptions/backfill2`,
+		httpmock.NewJsonResponderOrPanic(200, &subscription{
+			subscriptionCheckpoint: subscriptionCheckpoint{Checkpoint: &ListenerCheckpoint{Block: 201}},
+		}))
+	httpmock.RegisterResponder("DELETE", `http://localhost:12345/subscriptions/backfill2`,
+		httpmock.NewStringResponder(204, ""))
+
+	backfill := testBackfill()
+	backfill.BackendID = "backfill1"
+	currentBlock := uint64(150)
+	backfill.CurrentBlock = &currentBlock
+	var started string
+	err := e.BackfillContractListener(context.Background(), testBackfillListener(), backfill, func(backendID string) error {
+		started = backendID
+		return nil
+	}, func(block uint64) {})
+	assert.NoError(t, err)
+	assert.Equal(t, "backfill2", started)
+}
+
+func TestBackfillContractListenerResumeGetFail(t *testing.T) {
+	e, cancel := newTestEthereum()
+	defer cancel()
+	httpmock.ActivateNonDefault(e.client.GetClient())
+	defer httpmock.DeactivateAndReset()
+	e.streamID["ns1"] = "es-1"
+	mockBackfillListenerCheckpoint()
+
+	httpmock.RegisterResponder("GET", `http://localhost:12345/subscriptions/backfill1`,
+		httpmock.NewStringResponder(500, "pop"))
+
+	backfill := testBackfill()
+	backfill.BackendID = "backfill1"
+	err := e.BackfillContractListener(context.Background(), testBackfillListener(), backfill, noStarted, func(block uint64) {})
+	assert.Regexp(t, "FF10111.*pop", err)
+}
+
+func TestBackfillContractListenerStartedFail(t *testing.T) {
+	e, cancel := newTestEthereum()
+	defer cancel()
+	httpmock.ActivateNonDefault(e.client.GetClient())
+	defer httpmock.DeactivateAndReset()
+	e.streamID["ns1"] = "es-1"
+	mockBackfillListenerCheckpoint()
+
+	httpmock.RegisterResponder("POST", `http://localhost:12345/subscriptions`,
+		httpmock.NewJsonResponderOrPanic(200, &subscription{ID: "backfill1"}))
+	httpmock.RegisterResponder("DELETE", `http://localhost:12345/subscriptions/backfill1`,
+		httpmock.NewStringResponder(204, ""))
+
+	err := e.BackfillContractListener(context.Background(), testBackfillListener(), testBackfill(), func(backendID string) error {
+		return fmt.Errorf("pop")
+	}, func(block uint64) {})
+	assert.EqualError(t, err, "pop")
+	assert.Equal(t, 1, httpmock.GetCallCountInfo()["DELETE http://localhost:12345/subscriptions/backfill1"])
+}
+
+func TestBackfillContractListenerAtHead(t *testing.T) {
+	e, cancel := newTestEthereum()
+	defer cancel()
+	httpmock.ActivateNonDefault(e.client.GetClient())
+	defer httpmock.DeactivateAndReset()
+	e.streamID["ns1"] = "es-1"
+	mockBackfillListenerCheckpoint()
+
+	httpmock.RegisterResponder("POST", `http://localhost:12345/subscriptions`,
+		httpmock.NewJsonResponderOrPanic(200, &subscription{ID: "backfill1"}))
+	httpmock.RegisterResponder("GET", `http://localhost:12345/subscriptions/backfill1`,
+		httpmock.NewJsonResponderOrPanic(200, &subscription{
+			subscriptionCheckpoint: subscriptionCheckpoint{Checkpoint: &ListenerCheckpoint{Block: 200}},
+		}))
+	httpmock.RegisterResponder("DELETE", `http://localhost:12345/subscriptions/backfill1`,
+		httpmock.NewStringResponder(500, "pop"))
+
+	var progress []uint64
+	err := e.BackfillContractListener(context.Background(), testBackfillListener(), testBackfill(), noStarted, func(block uint64) {
+		progress = append(progress, block)
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []uint64{200}, progress)
+}
+
+func TestBackfillContractListenerBadFilters(t *testing.T) {
+	e, cancel := newTestEthereum()
+	defer cancel()
+
+	listener := testBackfillListener()
+	listener.Filters = nil
+	err := e.BackfillContractListener(context.Background(), listener, testBackfill(), noStarted, func(block uint64) {})
+	assert.Regexp(t, "FF10475", err)
+}
+
+func TestBackfillContractListenerCreateFail(t *testing.T) {
+	e, cancel := newTestEthereum()
+	defer cancel()
+	httpmock.ActivateNonDefault(e.client.GetClient())
+	defer httpmock.DeactivateAndReset()
+	e.streamID["ns1"] = "es-1"
+	mockBackfillListenerCheckpoint()
+
+	httpmock.RegisterResponder("POST", `http://localhost:12345/subscriptions`,
+		httpmock.NewStringResponder(500, "pop"))
+
+	err := e.BackfillContractListener(context.Background(), testBackfillListener(), testBackfill(), noStarted, func(block uint64) {})
+	assert.Regexp(t, "FF10111.*pop", err)
+}
+
+func TestBackfillContractListenerStatusFail(t *testing.T) {
+	e, cancel := newTestEthereum()
+	defer cancel()
+	httpmock.ActivateNonDefault(e.client.GetClient())
+	defer httpmock.DeactivateAndReset()
+	e.streamID["ns1"] = "es-1"
+	mockBackfillListenerCheckpoint()
+
+	httpmock.RegisterResponder("POST", `http://localhost:12345/subscriptions`,
+		httpmock.NewJsonResponderOrPanic(200, &subscription{ID: "backfill1"}))
+	httpmock.RegisterResponder("GET", `http://localhost:12345/subscriptions/backfill1`,
+		httpmock.NewStringResponder(500, "pop"))
+	httpmock.RegisterResponder("DELETE", `http://localhost:12345/subscriptions/backfill1`,
+		httpmock.NewStringResponder(204, ""))
+
+	err := e.BackfillContractListener(context.Background(), testBackfillListener(), testBackfill(), noStarted, func(block uint64) {})
+	assert.Regexp(t, "FF10111.*pop", err)
+	assert.Equal(t, 1, httpmock.GetCallCountInfo()["DELETE http://localhost:12345/subscriptions/backfill1"])
+}
+
+func TestBackfillContractListenerCancelled(t *testing.T) {
+	e, cancel := newTestEthereum()
+	defer cancel()
+	httpmock.ActivateNonDefault(e.client.GetClient())
+	defer httpmock.DeactivateAndReset()
+	e.streamID["ns1"] = "es-1"
+	mockBackfillListenerCheckpoint()
+	e.backfillInterval = 1 * time.Minute
+
+	ctx, cancelCtx := context.WithCancel(context.Background())
+	httpmock.RegisterResponder("POST", `http://localhost:12345/subscriptions`,
+		func(req *http.Request) (*http.Response, error) {
+			cancelCtx()
+			return httpmock.NewJsonResponderOrPanic(200, &subscription{ID: "backfill1"})(req)
+		})
+	httpmock.RegisterResponder("DELETE", `http://localhost:12345/subscriptions/backfill1`,
+		httpmock.NewStringResponder(204, ""))
+
+	err := e.BackfillContractListener(ctx, testBackfillListener(), testBackfill(), noStarted, func(block uint64) {})
+	assert.Regexp(t, "FF00154", err)
+	// The subscription is kept, for the backfill to resume with
+	assert.Zero(t, httpmock.GetCallCountInfo()["DELETE http://localhost:12345/subscriptions/backfill1"])
+}
+
+func TestBackfillContractListenerNoCheckpoint(t *testing.T) {
+	e, cancel := newTestEthereum()
+	defer cancel()
+	httpmock.ActivateNonDefault(e.client.GetClient())
+	defer httpmock.DeactivateAndReset()
+	e.streamID["ns1"] = "es-1"
+
+	// Ethconnect does not report a checkpoint for subscriptions, so progress cannot be tracked
+	httpmock.RegisterResponder("GET", `http://localhost:12345/subscriptions/sub1`,
+		httpmock.NewJsonResponderOrPanic(200, &subscription{ID: "sub1"}))
+
+	err := e.BackfillContractListener(context.Background(), testBackfillListener(), testBackfill(), noStarted, func(block uint64) {})
+	assert.Regexp(t, "FF10429", err)
+	assert.Zero(t, httpmock.GetCallCountInfo()["POST http://localhost:12345/subscriptions"])
+}
+
+func TestBackfillContractListenerGetListenerFail(t *testing.T) {
+	e, cancel := newTestEthereum()
+	defer cancel()
+	httpmock.ActivateNonDefault(e.client.GetClient())
+	defer httpmock.DeactivateAndReset()
+	e.streamID["ns1"] = "es-1"
+
+	httpmock.RegisterResponder("GET", `http://localhost:12345/subscriptions/sub1`,
+		httpmock.NewStringResponder(500, "pop"))
+
+	err := e.BackfillContractListener(context.Background(), testBackfillListener(), testBackfill(), noStarted, func(block uint64) {})
+	assert.Regexp(t, "FF10111.*pop", err)
+}
+
+func TestGetContractListenerHealth(t *testing.T) {
+	e, cancel := newTestEthereum()
+	defer cancel()
+	httpmock.ActivateNonDefault(e.client.GetClient())
+	defer httpmock.DeactivateAndReset()
+	e.streamID["ns1"] = "es12345"
+
+	httpmock.RegisterResponder("GET", "http://localhost:12345/subscriptions/sub1",
+		httpmock.NewJsonResponderOrPanic(200, subscription{
+			ID: "sub1", Stream: "es12345", Confirmations: 5, subscriptionCheckpoint: subscriptionCheckpoint{
+				Catchup:    true,
+				Checkpoint: &ListenerCheckpoint{Block: 1000},
+			},
+		}))
+	httpmock.RegisterResponder("GET", "http://localhost:12345/subscriptions/sub2",
+		httpmock.NewJsonResponderOrPanic(200, subscription{ID: "sub2", Stream: "es12345"}))
+	httpmock.RegisterResponder("GET", "http://localhost:12345/subscriptions/sub3",
+		httpmock.NewStringResponder(500, "pop"))
+	httpmock.RegisterResponder("GET", "http://localhost:12345/subscriptions/sub4",
+		httpmock.NewJsonResponderOrPanic(200, subscription{ID: "sub4", Stream: "es67890"}))
+	httpmock.RegisterResponder("GET", "http://localhost:12345/status",
+		httpmock.NewJsonResponderOrPanic(200, fftypes.JSONObject{
+			"chainHead": fftypes.JSONObject{"blockNumber": "1100"},
+		}))
+
+	// 95 confirmed blocks after the checkpoint
+	health, err := e.GetContractListenerHealth(context.Background(), "ns1", "sub1")
+	assert.NoError(t, err)
+	assert.Equal(t, core.ContractListenerStatusSyncing, health.Status)
+	assert.Equal(t, uint64(1000), *health.CheckpointBlock)
+	assert.Equal(t, uint64(1100), *health.ChainHead)
+	assert.Equal(t, uint64(95), *health.LagBlocks)
+	assert.Nil(t, health.LagSeconds)
+
+	// Without a checkpoint, the lag is not known
+	health, err = e.GetContractListenerHealth(context.Background(), "ns1", "sub2")
+	assert.NoError(t, err)
+	assert.Equal(t, core.ContractListenerStatusSynced, health.Status)
+	assert.Nil(t, health.CheckpointBlock)
+	assert.Equal(t, uint64(1100), *health.ChainHead)
+	assert.Nil(t, health.LagBlocks)
+
+	_, err = e.GetContractListenerHealth(context.Background(), "ns1", "sub3")
+	assert.Regexp(t, "FF10111.*pop", err)
+
+	// A subscription on another stream is not part of this namespace
+	health, err = e.GetContractListenerHealth(context.Background(), "ns1", "sub4")
+	assert.NoError(t, err)
+	assert.Equal(t, core.ContractListenerStatusUnknown, health.Status)
+	assert.Nil(t, health.ChainHead)
+}
+
+func TestGetContractListenerHealthNoStatusAPI(t *testing.T) {
+	e, cancel := newTestEthereum()
+	defer cancel()
+	httpmock.ActivateNonDefault(e.client.GetClient())
+	defer httpmock.DeactivateAndReset()
+	e.streamID["ns1"] = "es12345"
+
+	httpmock.RegisterResponder("GET", "http://localhost:12345/subscriptions/sub1",
+		httpmock.NewJsonResponderOrPanic(200, subscription{ID: "sub1", Stream: "es12345"}))
+	httpmock.RegisterResponder("GET", "http://localhost:12345/status",
+		httpmock.NewStringResponder(404, "not found"))
+
+	// Connectors without a status API (such as ethconnect) do not report the head of the chain
+	health, err := e.GetContractListenerHealth(context.Background(), "ns1", "sub1")
+	assert.NoError(t, err)
+	assert.Nil(t, health.ChainHead)
+	assert.Nil(t, health.LagBlocks)
+}
+
+func TestGetContractListenerHealthStatusFail(t *testing.T) {
+	e, cancel := newTestEthereum()
+	defer cancel()
+	httpmock.ActivateNonDefault(e.client.GetClient())
+	defer httpmock.DeactivateAndReset()
+	e.streamID["ns1"] = "es12345"
+
+	httpmock.RegisterResponder("GET", "http://localhost:12345/subscriptions/sub1",
+		httpmock.NewJsonResponderOrPanic(200, subscription{ID: "sub1", Stream: "es12345"}))
+	httpmock.RegisterResponder("GET", "http://localhost:12345/status",
+		httpmock.NewStringResponder(500, "pop"))
+
+	_, err := e.GetContractListenerHealth(context.Background(), "ns1", "sub1")
+	assert.Regexp(t, "FF10111.*pop", err)
+}
+
 func TestGetContractListenerStatus(t *testing.T) {
 	e, cancel := newTestEthereum()
 	defer cancel()
@@ -4602,7 +5281,7 @@ func TestGetContractListenerStatus(t *testing.T) {
 		httpmock.NewJsonResponderOrPanic(200, subscription{
 			ID: "sub1", Stream: "es12345", Name: "ff-sub-1132312312312", subscriptionCheckpoint: subscriptionCheckpoint{
 				Catchup:    false,
-				Checkpoint: checkpoint,
+				Checkpoint: &checkpoint,
 			},
 		}))
 
@@ -4648,7 +5327,7 @@ func TestGetContractListenerStatusSyncing(t *testing.T) {
 		httpmock.NewJsonResponderOrPanic(200, subscription{
 			ID: "sub1", Stream: "es12345", Name: "ff-sub-1132312312312", subscriptionCheckpoint: subscriptionCheckpoint{
 				Catchup:    true,
-				Checkpoint: checkpoint,
+				Checkpoint: &checkpoint,
 			},
 		}))
 
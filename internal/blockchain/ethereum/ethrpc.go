@@ -0,0 +1,643 @@
+// Copyright © 2024 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ethereum
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hyperledger/firefly-common/pkg/config"
+	"github.com/hyperledger/firefly-common/pkg/ffresty"
+	"github.com/hyperledger/firefly-common/pkg/fftypes"
+	"github.com/hyperledger/firefly-common/pkg/i18n"
+	"github.com/hyperledger/firefly-common/pkg/log"
+	"github.com/hyperledger/firefly-signer/pkg/abi"
+	"github.com/hyperledger/firefly-signer/pkg/ethsigner"
+	"github.com/hyperledger/firefly-signer/pkg/ethtypes"
+	"github.com/hyperledger/firefly-signer/pkg/ffi2abi"
+	"github.com/hyperledger/firefly-signer/pkg/fswallet"
+	"github.com/hyperledger/firefly-signer/pkg/rpcbackend"
+	"github.com/hyperledger/firefly/internal/blockchain/common"
+	"github.com/hyperledger/firefly/internal/cache"
+	"github.com/hyperledger/firefly/internal/coreconfig"
+	"github.com/hyperledger/firefly/internal/coremsgs"
+	"github.com/hyperledger/firefly/internal/metrics"
+	"github.com/hyperledger/firefly/internal/tracing"
+	"github.com/hyperledger/firefly/pkg/blockchain"
+	"github.com/hyperledger/firefly/pkg/core"
+)
+
+// EthRPC is a blockchain plugin that talks directly to the JSON-RPC endpoint of an Ethereum node,
+// rather than to an ethconnect or evmconnect connector. Events are found by polling eth_getLogs,
+// queries are made with eth_call, and transactions are signed locally using keys from a directory
+// of keystore V3 files.
+//
+// The handling of FFIs, contract locations and signing keys is shared with the connector based
+// plugin, which is embedded.
+type EthRPC struct {
+	Ethereum
+	rpcConf             config.Section
+	rpc                 rpcbackend.Backend
+	wallet              ethsigner.Wallet
+	chainID             int64
+	pollingInterval     time.Duration
+	maxBlockRange       uint64
+	confirmations       int
+	gasEstimationFactor float64
+	mux                 sync.Mutex
+	namespaces          map[string]bool
+	subscriptions       map[string]*rpcSubscription
+	pendingTxns         map[string]*pendingTxn
+	txMux               sync.Mutex
+	nonces              map[string]uint64
+	pollerDone          chan struct{}
+}
+
+func (r *EthRPC) Name() string {
+	return "ethrpc"
+}
+
+func (r *EthRPC) Init(ctx context.Context, cancelCtx context.CancelFunc, conf config.Section, metrics metrics.Manager, cacheManager cache.Manager) (err error) {
+	r.InitConfig(conf)
+	keystoreConf := conf.SubSection(KeystoreConfigKey)
+
+	r.ctx = log.WithLogField(ctx, "proto", "ethrpc")
+	r.cancelCtx = cancelCtx
+	r.metrics = metrics
+	r.capabilities = &blockchain.Capabilities{}
+	r.callbacks = common.NewBlockchainCallbacks()
+	r.subs = common.NewFireflySubscriptions()
+
+	if r.rpcConf.GetString(ffresty.HTTPConfigURL) == "" {
+		return i18n.NewError(ctx, coremsgs.MsgMissingPluginConfig, "url", r.rpcConf)
+	}
+	client, err := ffresty.New(r.ctx, r.rpcConf)
+	if err != nil {
+		return err
+	}
+	tracing.InstrumentClient(client)
+	r.rpc = rpcbackend.NewRPCClient(client)
+
+	if keystoreConf.GetString(fswallet.ConfigPath) == "" {
+		return i18n.NewError(ctx, coremsgs.MsgMissingPluginConfig, "path", keystoreConf)
+	}
+	if r.wallet, err = fswallet.NewFilesystemWallet(r.ctx, fswallet.ReadConfig(keystoreConf)); err != nil {
+		return err
+	}
+	if err = r.wallet.Initialize(r.ctx); err != nil {
+		return err
+	}
+
+	r.chainID = conf.GetInt64(RPCConfigChainID)
+	r.pollingInterval = conf.GetDuration(RPCConfigPollingInterval)
+	r.maxBlockRange = conf.GetUint64(RPCConfigMaxBlockRange)
+	if r.maxBlockRange == 0 {
+		r.maxBlockRange = 1
+	}
+	r.confirmations = conf.GetInt(RPCConfigConfirmations)
+	r.gasEstimationFactor = conf.GetFloat64(RPCConfigGasEstimationFactor)
+
+	cache, err := cacheManager.GetCache(
+		cache.NewCacheConfig(
+			ctx,
+			coreconfig.CacheBlockchainLimit,
+			coreconfig.CacheBlockchainTTL,
+			"",
+		),
+	)
+	if err != nil {
+		return err
+	}
+	r.cache = cache
+
+	r.namespaces = make(map[string]bool)
+	r.subscriptions = make(map[string]*rpcSubscription)
+	r.pendingTxns = make(map[string]*pendingTxn)
+	r.nonces = make(map[string]uint64)
+	return nil
+}
+
+func (r *EthRPC) StartNamespace(ctx context.Context, namespace string) (err error) {
+	log.L(r.ctx).Debugf("Starting namespace: %s", namespace)
+	if err := r.recoverPendingOperations(ctx, namespace); err != nil {
+		return err
+	}
+	r.mux.Lock()
+	defer r.mux.Unlock()
+	r.namespaces[namespace] = true
+	if r.pollerDone == nil {
+		r.pollerDone = make(chan struct{})
+		go r.pollLoop()
+	}
+	return nil
+}
+
+func (r *EthRPC) StopNamespace(ctx context.Context, namespace string) (err error) {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+	delete(r.namespaces, namespace)
+	// Subscriptions only live in memory, so they are re-created from the last delivered event when the namespace restarts
+	for id, sub := range r.subscriptions {
+		if sub.Namespace == namespace {
+			delete(r.subscriptions, id)
+		}
+	}
+	return nil
+}
+
+func (r *EthRPC) AddFireflySubscription(ctx context.Context, namespace *core.Namespace, contract *blockchain.MultipartyContract, lastProtocolID string) (string, error) {
+	ethLocation, err := r.parseContractLocation(ctx, contract.Location)
+	if err != nil {
+		return "", err
+	}
+	address, err := formatEthAddress(ctx, ethLocation.Address)
+	if err != nil {
+		return "", err
+	}
+
+	version, err := r.GetNetworkVersion(ctx, contract.Location)
+	if err != nil {
+		return "", err
+	}
+
+	subName := fmt.Sprintf("%s_%s", namespace.Name, address)
+	filters := []*rpcFilter{{Address: address, Event: batchPinEventABI}}
+	sub, err := r.newSubscription(ctx, namespace.Name, subName, contract.FirstEvent, lastProtocolID, r.confirmations, filters)
+	if err != nil {
+		return "", err
+	}
+	sub.FireFlyContract = true
+	r.addSubscription(sub)
+
+	r.subs.AddSubscription(ctx, namespace, version, sub.ID, nil)
+	return sub.ID, nil
+}
+
+func (r *EthRPC) RemoveFireflySubscription(ctx context.Context, subID string) {
+	r.subs.RemoveSubscription(ctx, subID)
+	r.mux.Lock()
+	defer r.mux.Unlock()
+	delete(r.subscriptions, subID)
+}
+
+func (r *EthRPC) AddContractListener(ctx context.Context, listener *core.ContractListener, lastProtocolID string) (err error) {
+	if len(listener.Filters) == 0 {
+		return i18n.NewError(ctx, coremsgs.MsgFiltersEmpty, listener.Name)
+	}
+
+	filters := make([]*rpcFilter, 0, len(listener.Filters))
+	for _, f := range listener.Filters {
+		eventABI, err := ffi2abi.ConvertFFIEventDefinitionToABI(ctx, &f.Event.FFIEventDefinition)
+		if err != nil {
+			return i18n.WrapError(ctx, err, coremsgs.MsgContractParamInvalid)
+		}
+		rf := &rpcFilter{Event: eventABI}
+		if f.Location != nil {
+			location, err := r.parseContractLocation(ctx, f.Location)
+			if err != nil {
+				return err
+			}
+			if rf.Address, err = formatEthAddress(ctx, location.Address); err != nil {
+				return err
+			}
+		}
+		filters = append(filters, rf)
+	}
+
+	subName := fmt.Sprintf("ff-sub-%s-%s", listener.Namespace, listener.ID)
+	firstEvent := string(core.SubOptsFirstEventNewest)
+	confirmations := r.confirmations
+	if listener.Options != nil {
+		firstEvent = listener.Options.FirstEvent
+		if listener.Options.Confirmations > 0 {
+			confirmations = listener.Options.Confirmations
+		}
+	}
+	sub, err := r.newSubscription(ctx, listener.Namespace, subName, firstEvent, lastProtocolID, confirmations, filters)
+	if err != nil {
+		return err
+	}
+	r.addSubscription(sub)
+	listener.BackendID = sub.ID
+	return nil
+}
+
+func (r *EthRPC) DeleteContractListener(ctx context.Context, subscription *core.ContractListener, okNotFound bool) error {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+	if _, ok := r.subscriptions[subscription.BackendID]; !ok && !okNotFound {
+		return i18n.NewError(ctx, coremsgs.MsgEthRPCListenerNotFound, subscription.BackendID)
+	}
+	delete(r.subscriptions, subscription.BackendID)
+	return nil
+}
+
+func (r *EthRPC) GetContractListenerStatus(ctx context.Context, namespace, subID string, okNotFound bool) (found bool, detail interface{}, status core.ContractListenerStatus, err error) {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+	sub, ok := r.subscriptions[subID]
+	if !ok || sub.Namespace != namespace {
+		if !okNotFound {
+			err = i18n.NewError(ctx, coremsgs.MsgEthRPCListenerNotFound, subID)
+		}
+		return false, nil, core.ContractListenerStatusUnknown, err
+	}
+
+	// The checkpoint is the last block that has been fully processed
+	checkpoint := &ListenerStatus{
+		Catchup: sub.Catchup,
+		Checkpoint: ListenerCheckpoint{
+			Block:            int64(sub.Checkpoint) - 1,
+			TransactionIndex: -1,
+			LogIndex:         -1,
+		},
+	}
+
+	status = core.ContractListenerStatusSynced
+	if sub.Catchup {
+		status = core.ContractListenerStatusSyncing
+	}
+	return true, checkpoint, status, nil
+}
+
+func (r *EthRPC) GetContractListenerHealth(ctx context.Context, namespace, subID string) (*core.ContractListenerHealth, error) {
+	r.mux.Lock()
+	sub, ok := r.subscriptions[subID]
+	var snapshot rpcSubscription
+	if ok {
+		snapshot = *sub
+	}
+	r.mux.Unlock()
+	if !ok || snapshot.Namespace != namespace {
+		return nil, i18n.NewError(ctx, coremsgs.MsgEthRPCListenerNotFound, subID)
+	}
+
+	head, err := r.getBlockNumber(ctx)
+	if err != nil {
+		return nil, err
+	}
+	health := &core.ContractListenerHealth{
+		Status:    core.ContractListenerStatusSynced,
+		ChainHead: &head,
+		LastError: snapshot.LastError,
+	}
+	if snapshot.Catchup {
+		health.Status = core.ContractListenerStatusSyncing
+	}
+	if snapshot.Checkpoint > 0 {
+		block := snapshot.Checkpoint - 1
+		health.CheckpointBlock = &block
+	}
+
+	// Lag is measured from the oldest block not yet processed, to the newest block with enough confirmations
+	var lagBlocks uint64
+	var lagSeconds int64
+	if head >= uint64(snapshot.Confirmations) && head-uint64(snapshot.Confirmations) >= snapshot.Checkpoint {
+		confirmed := head - uint64(snapshot.Confirmations)
+		lagBlocks = confirmed - snapshot.Checkpoint + 1
+		timestamps := make(map[uint64]*fftypes.FFTime)
+		from, err := r.getBlockTimestamp(ctx, snapshot.Checkpoint, timestamps)
+		if err != nil {
+			return nil, err
+		}
+		to, err := r.getBlockTimestamp(ctx, confirmed, timestamps)
+		if err != nil {
+			return nil, err
+		}
+		lagSeconds = int64(time.Time(*to).Sub(time.Time(*from)).Seconds())
+	}
+	health.LagBlocks = &lagBlocks
+	health.LagSeconds = &lagSeconds
+	return health, nil
+}
+
+// BackfillContractListener queries the range directly, so has no temporary subscription to record
+func (r *EthRPC) BackfillContractListener(ctx context.Context, listener *core.ContractListener, backfill *core.ContractListenerBackfill, started func(backendID string) error, progress func(block uint64)) error {
+	r.mux.Lock()
+	sub, ok := r.subscriptions[listener.BackendID]
+	r.mux.Unlock()
+	if !ok {
+		return i18n.NewError(ctx, coremsgs.MsgEthRPCListenerNotFound, listener.BackendID)
+	}
+
+	head, err := r.getBlockNumber(ctx)
+	if err != nil {
+		return err
+	}
+	toBlock := backfill.ToBlock
+	if head < uint64(sub.Confirmations) || toBlock > head-uint64(sub.Confirmations) {
+		return i18n.NewError(ctx, coremsgs.MsgBackfillBeyondConfirmedBlock, int64(head)-int64(sub.Confirmations))
+	}
+
+	// A backfill that was interrupted resumes after the last block it delivered
+	fromBlock := backfill.FromBlock
+	if backfill.CurrentBlock != nil {
+		fromBlock = *backfill.CurrentBlock + 1
+	}
+
+	// The range is queried independently of the poller, so the checkpoint of the listener is unaffected
+	for from := fromBlock; from <= toBlock; {
+		to := from + r.maxBlockRange - 1
+		if to > toBlock {
+			to = toBlock
+		}
+		if err := r.deliverLogs(ctx, sub, from, to); err != nil {
+			return err
+		}
+		progress(to)
+		from = to + 1
+	}
+	return nil
+}
+
+func (r *EthRPC) SubmitBatchPin(ctx context.Context, nsOpID, networkNamespace, signingKey string, batch *blockchain.BatchPin, location *fftypes.JSONAny) error {
+	ethLocation, err := r.parseContractLocation(ctx, location)
+	if err != nil {
+		return err
+	}
+
+	version, err := r.GetNetworkVersion(ctx, location)
+	if err != nil {
+		return err
+	}
+
+	method, input := r.buildBatchPinInput(version, networkNamespace, batch)
+	_, err = r.invokeContractMethod(ctx, ethLocation.Address, signingKey, method, nsOpID, input, nil, nil)
+	return err
+}
+
+func (r *EthRPC) SubmitNetworkAction(ctx context.Context, nsOpID string, signingKey string, action core.NetworkActionType, location *fftypes.JSONAny) error {
+	ethLocation, err := r.parseContractLocation(ctx, location)
+	if err != nil {
+		return err
+	}
+
+	version, err := r.GetNetworkVersion(ctx, location)
+	if err != nil {
+		return err
+	}
+
+	var input []interface{}
+	var method *abi.Entry
+
+	if version == 1 {
+		method = batchPinMethodABIV1
+		input = []interface{}{
+			blockchain.FireFlyActionPrefix + action,
+			ethHexFormatB32(nil),
+			ethHexFormatB32(nil),
+			"",
+			[]string{},
+		}
+	} else {
+		method = networkActionMethodABI
+		input = []interface{}{
+			blockchain.FireFlyActionPrefix + action,
+			"",
+		}
+	}
+	_, err = r.invokeContractMethod(ctx, ethLocation.Address, signingKey, method, nsOpID, input, nil, nil)
+	return err
+}
+
+func (r *EthRPC) DeployContract(ctx context.Context, nsOpID, signingKey string, definition, contract *fftypes.JSONAny, input []interface{}, options map[string]interface{}) (submissionRejected bool, err error) {
+	if r.metrics.IsMetricsEnabled() {
+		r.metrics.BlockchainContractDeployment()
+	}
+
+	var contractABI abi.ABI
+	if err := json.Unmarshal(definition.Bytes(), &contractABI); err != nil {
+		return true, i18n.NewError(ctx, coremsgs.MsgEthRPCInvalidContract, err)
+	}
+	var bytecode ethtypes.HexBytes0xPrefix
+	if err := json.Unmarshal(contract.Bytes(), &bytecode); err != nil || len(bytecode) == 0 {
+		return true, i18n.NewError(ctx, coremsgs.MsgEthRPCInvalidContract, "contract must be a hex encoded string of the compiled bytecode")
+	}
+
+	data := bytecode
+	if constructor := contractABI.Constructor(); constructor != nil {
+		args, err := constructor.Inputs.EncodeABIDataValuesCtx(ctx, input)
+		if err != nil {
+			return true, err
+		}
+		data = append(append(ethtypes.HexBytes0xPrefix{}, bytecode...), args...)
+	} else if len(input) > 0 {
+		return true, i18n.NewError(ctx, coremsgs.MsgEthRPCInvalidContract, "the ABI has no constructor, but input was supplied")
+	}
+
+	return r.submitTransaction(ctx, &txRequest{
+		nsOpID:  nsOpID,
+		from:    signingKey,
+		data:    data,
+		errors:  contractABI,
+		options: options,
+	})
+}
+
+func (r *EthRPC) InvokeContract(ctx context.Context, nsOpID string, signingKey string, location *fftypes.JSONAny, parsedMethod interface{}, input map[string]interface{}, options map[string]interface{}, batch *blockchain.BatchPin) (bool, error) {
+	ethereumLocation, err := r.parseContractLocation(ctx, location)
+	if err != nil {
+		return true, err
+	}
+	methodInfo, orderedInput, err := r.prepareRequest(ctx, parsedMethod, input)
+	if err != nil {
+		return true, err
+	}
+	if batch != nil {
+		err := r.checkDataSupport(ctx, methodInfo.methodABI)
+		if err == nil {
+			method, batchPin := r.buildBatchPinInput(2, "", batch)
+			encoded, err := method.Inputs.EncodeABIDataValuesCtx(ctx, batchPin)
+			if err == nil {
+				orderedInput[len(orderedInput)-1] = hex.EncodeToString(encoded)
+			}
+		}
+		if err != nil {
+			return true, err
+		}
+	}
+	return r.invokeContractMethod(ctx, ethereumLocation.Address, signingKey, methodInfo.methodABI, nsOpID, orderedInput, methodInfo.errorsABI, options)
+}
+
+func (r *EthRPC) invokeContractMethod(ctx context.Context, address, signingKey string, method *abi.Entry, nsOpID string, input []interface{}, errors []*abi.Entry, options map[string]interface{}) (submissionRejected bool, err error) {
+	if r.metrics.IsMetricsEnabled() {
+		r.metrics.BlockchainTransaction(address, method.Name)
+	}
+	to, err := ethtypes.NewAddress(address)
+	if err != nil {
+		return true, i18n.NewError(ctx, coremsgs.MsgInvalidEthAddress)
+	}
+	data, err := method.EncodeCallDataValuesCtx(ctx, input)
+	if err != nil {
+		return true, err
+	}
+	return r.submitTransaction(ctx, &txRequest{
+		nsOpID:  nsOpID,
+		from:    signingKey,
+		to:      to,
+		data:    data,
+		errors:  errors,
+		options: options,
+	})
+}
+
+func (r *EthRPC) QueryContract(ctx context.Context, signingKey string, location *fftypes.JSONAny, parsedMethod interface{}, input map[string]interface{}, options map[string]interface{}) (interface{}, error) {
+	ethereumLocation, err := r.parseContractLocation(ctx, location)
+	if err != nil {
+		return nil, err
+	}
+	methodInfo, orderedInput, err := r.prepareRequest(ctx, parsedMethod, input)
+	if err != nil {
+		return nil, err
+	}
+	return r.queryContractMethod(ctx, ethereumLocation.Address, signingKey, methodInfo.methodABI, orderedInput, methodInfo.errorsABI, options)
+}
+
+func (r *EthRPC) queryContractMethod(ctx context.Context, address, signingKey string, method *abi.Entry, input []interface{}, errors []*abi.Entry, options map[string]interface{}) (interface{}, error) {
+	if r.metrics.IsMetricsEnabled() {
+		r.metrics.BlockchainQuery(address, method.Name)
+	}
+	to, err := ethtypes.NewAddress(address)
+	if err != nil {
+		return nil, i18n.NewError(ctx, coremsgs.MsgInvalidEthAddress)
+	}
+	data, err := method.EncodeCallDataValuesCtx(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+	tx := &ethsigner.Transaction{To: to, Data: data}
+	if err := applyTxOptions(ctx, tx, options); err != nil {
+		return nil, err
+	}
+	if signingKey != "" {
+		tx.From = json.RawMessage(fmt.Sprintf(`"%s"`, signingKey))
+	}
+
+	var result ethtypes.HexBytes0xPrefix
+	if rpcErr := r.rpc.CallRPC(ctx, &result, "eth_call", tx, "latest"); rpcErr != nil {
+		return nil, r.wrapCallError(ctx, rpcErr, errors)
+	}
+
+	outputs, err := method.Outputs.DecodeABIDataCtx(ctx, result, 0)
+	if err != nil {
+		return nil, err
+	}
+	return querySerializer.SerializeInterfaceCtx(ctx, outputs)
+}
+
+// querySerializer formats query outputs in the same way as evmconnect, so results are the same with either plugin
+var querySerializer = abi.NewSerializer().
+	SetFormattingMode(abi.FormatAsObjects).
+	SetIntSerializer(abi.Base10StringIntSerializer).
+	SetByteSerializer(abi.HexByteSerializer0xPrefix).
+	SetDefaultNameGenerator(func(idx int) string {
+		if idx == 0 {
+			return "output"
+		}
+		return fmt.Sprintf("output%d", idx)
+	})
+
+func (r *EthRPC) GetNetworkVersion(ctx context.Context, location *fftypes.JSONAny) (version int, err error) {
+	ethLocation, err := r.parseContractLocation(ctx, location)
+	if err != nil {
+		return 0, err
+	}
+
+	cacheKey := "version:" + ethLocation.Address
+	if cachedValue := r.cache.GetInt(cacheKey); cachedValue != 0 {
+		return cachedValue, nil
+	}
+
+	version, err = r.queryNetworkVersion(ctx, ethLocation.Address)
+	if err == nil {
+		r.cache.SetInt(cacheKey, version)
+	}
+	return version, err
+}
+
+func (r *EthRPC) queryNetworkVersion(ctx context.Context, address string) (version int, err error) {
+	output, err := r.queryContractMethod(ctx, address, "", networkVersionMethodABI, []interface{}{}, nil, nil)
+	if err != nil {
+		// A reverted call is interpreted as "method does not exist, default to version 1"
+		if strings.Contains(err.Error(), string(coremsgs.MsgEthRPCReverted)) {
+			return 1, nil
+		}
+		return 0, err
+	}
+
+	outputJSON, _ := output.(map[string]interface{})
+	switch result := outputJSON["output"].(type) {
+	case string:
+		version, err = strconv.Atoi(result)
+	default:
+		err = i18n.NewError(ctx, coremsgs.MsgBadNetworkVersion, outputJSON["output"])
+	}
+	return version, err
+}
+
+func (r *EthRPC) GetAndConvertDeprecatedContractConfig(ctx context.Context) (location *fftypes.JSONAny, fromBlock string, err error) {
+	// There is no deprecated config for this plugin - the contract must be configured on the namespace
+	return nil, "", i18n.NewError(ctx, coremsgs.MsgMissingPluginConfig, "location", "namespaces.predefined[].multiparty.contract[]")
+}
+
+func (r *EthRPC) GetTransactionStatus(ctx context.Context, operation *core.Operation) (interface{}, error) {
+	txHash := operation.Output.GetString("transactionHash")
+	if txHash == "" {
+		return nil, nil
+	}
+
+	var receipt *rpcReceipt
+	if rpcErr := r.rpc.CallRPC(ctx, &receipt, "eth_getTransactionReceipt", txHash); rpcErr != nil {
+		return nil, i18n.WrapError(ctx, rpcErr.Error(), coremsgs.MsgEthRPCErr, rpcErr.Message)
+	}
+	if receipt == nil {
+		return fftypes.JSONObject{
+			"transactionHash": txHash,
+			"status":          ethTxStatusPending,
+		}, nil
+	}
+
+	// If the operation has not caught up with the receipt, for example because it was submitted
+	// before a restart, then deliver the receipt now as if the tracker had found it
+	if operation.Status == core.OpStatusPending || operation.Status == core.OpStatusInitialized {
+		nsOpID := (&core.PreparedOperation{ID: operation.ID, Namespace: operation.Namespace}).NamespacedIDString()
+		r.mux.Lock()
+		txn, ok := r.pendingTxns[txHash]
+		r.mux.Unlock()
+		if !ok {
+			txn = &pendingTxn{nsOpID: nsOpID, hash: txHash}
+		}
+		if err := r.handleTxReceipt(ctx, txn, receipt); err != nil {
+			log.L(ctx).Warnf("Failed to handle receipt for transaction %s: %s", txHash, err)
+		} else {
+			r.mux.Lock()
+			delete(r.pendingTxns, txHash)
+			r.mux.Unlock()
+		}
+	}
+
+	var status fftypes.JSONObject
+	b, _ := json.Marshal(receipt)
+	_ = json.Unmarshal(b, &status)
+	return status, nil
+}
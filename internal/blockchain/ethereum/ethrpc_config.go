@@ -0,0 +1,67 @@
+// Copyright © 2024 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ethereum
+
+import (
+	"github.com/hyperledger/firefly-common/pkg/config"
+	"github.com/hyperledger/firefly-common/pkg/ffresty"
+	"github.com/hyperledger/firefly-signer/pkg/fswallet"
+)
+
+const (
+	defaultRPCChainID             = -1
+	defaultRPCPollingInterval     = "1s"
+	defaultRPCMaxBlockRange       = 1000
+	defaultRPCConfirmations       = 0
+	defaultRPCGasEstimationFactor = 1.5
+	defaultRPCKeystorePrimaryExt  = ".key.json"
+	defaultRPCKeystorePasswordExt = ".password"
+)
+
+const (
+	// RPCConfigKey is a sub-key in the config to contain the JSON-RPC endpoint of the Ethereum node
+	RPCConfigKey = "rpc"
+	// RPCConfigChainID is the chain ID to use when signing transactions - queried from the node when not set
+	RPCConfigChainID = "chainId"
+	// RPCConfigPollingInterval is how often the node is polled for new blocks, logs and transaction receipts
+	RPCConfigPollingInterval = "pollingInterval"
+	// RPCConfigMaxBlockRange is the maximum number of blocks to include in a single eth_getLogs query
+	RPCConfigMaxBlockRange = "maxBlockRange"
+	// RPCConfigConfirmations is the default number of blocks to wait before delivering an event
+	RPCConfigConfirmations = "confirmations"
+	// RPCConfigGasEstimationFactor is the factor applied to the result of eth_estimateGas to set the gas limit of a transaction
+	RPCConfigGasEstimationFactor = "gasEstimationFactor"
+
+	// KeystoreConfigKey is a sub-key in the config to contain the directory of keystore V3 files used for signing
+	KeystoreConfigKey = "keystore"
+)
+
+func (r *EthRPC) InitConfig(config config.Section) {
+	r.rpcConf = config.SubSection(RPCConfigKey)
+	ffresty.InitConfig(r.rpcConf)
+
+	config.AddKnownKey(RPCConfigChainID, defaultRPCChainID)
+	config.AddKnownKey(RPCConfigPollingInterval, defaultRPCPollingInterval)
+	config.AddKnownKey(RPCConfigMaxBlockRange, defaultRPCMaxBlockRange)
+	config.AddKnownKey(RPCConfigConfirmations, defaultRPCConfirmations)
+	config.AddKnownKey(RPCConfigGasEstimationFactor, defaultRPCGasEstimationFactor)
+
+	keystoreConf := config.SubSection(KeystoreConfigKey)
+	fswallet.InitConfig(keystoreConf)
+	keystoreConf.SetDefault(fswallet.ConfigFilenamesPrimaryExt, defaultRPCKeystorePrimaryExt)
+	keystoreConf.SetDefault(fswallet.ConfigFilenamesPasswordExt, defaultRPCKeystorePasswordExt)
+}
@@ -0,0 +1,384 @@
+// Copyright © 2024 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ethereum
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hyperledger/firefly-common/pkg/fftypes"
+	"github.com/hyperledger/firefly-common/pkg/i18n"
+	"github.com/hyperledger/firefly-common/pkg/log"
+	"github.com/hyperledger/firefly-signer/pkg/abi"
+	"github.com/hyperledger/firefly-signer/pkg/ethtypes"
+	"github.com/hyperledger/firefly/internal/blockchain/common"
+	"github.com/hyperledger/firefly/internal/coremsgs"
+	"github.com/hyperledger/firefly/pkg/blockchain"
+	"github.com/hyperledger/firefly/pkg/core"
+)
+
+type rpcSubscription struct {
+	ID              string
+	Name            string
+	Namespace       string
+	FireFlyContract bool
+	Filters         []*rpcFilter
+	Confirmations   int
+	Checkpoint      uint64 // the next block to query
+	Catchup         bool
+	LastError       string
+}
+
+type rpcFilter struct {
+	Address string // empty to match any contract
+	Event   *abi.Entry
+}
+
+type rpcLog struct {
+	Address          string                      `json:"address"`
+	Topics           []ethtypes.HexBytes0xPrefix `json:"topics"`
+	Data             ethtypes.HexBytes0xPrefix   `json:"data"`
+	BlockNumber      ethtypes.HexUint64          `json:"blockNumber"`
+	BlockHash        string                      `json:"blockHash"`
+	TransactionHash  string                      `json:"transactionHash"`
+	TransactionIndex ethtypes.HexUint64          `json:"transactionIndex"`
+	LogIndex         ethtypes.HexUint64          `json:"logIndex"`
+}
+
+type rpcLogQuery struct {
+	FromBlock ethtypes.HexUint64            `json:"fromBlock"`
+	ToBlock   ethtypes.HexUint64            `json:"toBlock"`
+	Address   []string                      `json:"address,omitempty"`
+	Topics    [][]ethtypes.HexBytes0xPrefix `json:"topics"`
+}
+
+type rpcBlock struct {
+	Number    ethtypes.HexUint64 `json:"number"`
+	Hash      string             `json:"hash"`
+	Timestamp ethtypes.HexUint64 `json:"timestamp"`
+}
+
+// eventSerializer formats event outputs in the same way as evmconnect, so events are the same with either plugin
+var eventSerializer = abi.NewSerializer().
+	SetFormattingMode(abi.FormatAsObjects).
+	SetIntSerializer(abi.Base10StringIntSerializer).
+	SetByteSerializer(abi.HexByteSerializer0xPrefix)
+
+func (r *EthRPC) newSubscription(ctx context.Context, namespace, name, firstEvent, lastProtocolID string, confirmations int, filters []*rpcFilter) (*rpcSubscription, error) {
+	fromBlock, err := resolveFromBlock(ctx, firstEvent, lastProtocolID)
+	if err != nil {
+		return nil, err
+	}
+	var checkpoint uint64
+	if fromBlock == "latest" {
+		head, err := r.getBlockNumber(ctx)
+		if err != nil {
+			return nil, err
+		}
+		checkpoint = head + 1
+	} else {
+		checkpoint, _ = strconv.ParseUint(fromBlock, 10, 64)
+	}
+	return &rpcSubscription{
+		ID:            fftypes.NewUUID().String(),
+		Name:          name,
+		Namespace:     namespace,
+		Filters:       filters,
+		Confirmations: confirmations,
+		Checkpoint:    checkpoint,
+		Catchup:       true,
+	}, nil
+}
+
+func (r *EthRPC) addSubscription(sub *rpcSubscription) {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+	r.subscriptions[sub.ID] = sub
+	log.L(r.ctx).Infof("Added subscription %s (%s) from block %d", sub.ID, sub.Name, sub.Checkpoint)
+}
+
+func (r *EthRPC) getBlockNumber(ctx context.Context) (uint64, error) {
+	var head ethtypes.HexUint64
+	if rpcErr := r.rpc.CallRPC(ctx, &head, "eth_blockNumber"); rpcErr != nil {
+		return 0, i18n.WrapError(ctx, rpcErr.Error(), coremsgs.MsgEthRPCErr, rpcErr.Message)
+	}
+	return head.Uint64(), nil
+}
+
+func (r *EthRPC) pollLoop() {
+	defer close(r.pollerDone)
+	for {
+		catchup, err := r.poll(r.ctx)
+		if err != nil {
+			log.L(r.ctx).Errorf("Polling the ethereum node failed: %s", err)
+		}
+		if catchup && err == nil {
+			// Go straight round again until all subscriptions are up to date
+			continue
+		}
+		select {
+		case <-r.ctx.Done():
+			log.L(r.ctx).Debugf("Polling loop exiting")
+			return
+		case <-time.After(r.pollingInterval):
+		}
+	}
+}
+
+// poll performs one cycle of log queries for all subscriptions in started namespaces, followed by a
+// check for the receipts of any pending transactions. It returns true if any subscription is still
+// behind the head of the chain.
+func (r *EthRPC) poll(ctx context.Context) (catchup bool, err error) {
+	head, err := r.getBlockNumber(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	r.mux.Lock()
+	subs := make([]*rpcSubscription, 0, len(r.subscriptions))
+	for _, sub := range r.subscriptions {
+		if r.namespaces[sub.Namespace] {
+			subs = append(subs, sub)
+		}
+	}
+	r.mux.Unlock()
+	sort.Slice(subs, func(i, j int) bool { return subs[i].ID < subs[j].ID })
+
+	for _, sub := range subs {
+		subCatchup, err := r.pollSubscription(ctx, sub, head)
+		if err != nil {
+			return false, err
+		}
+		catchup = catchup || subCatchup
+	}
+
+	r.checkReceipts(ctx)
+	return catchup, nil
+}
+
+func (r *EthRPC) pollSubscription(ctx context.Context, sub *rpcSubscription, head uint64) (catchup bool, err error) {
+	r.mux.Lock()
+	checkpoint := sub.Checkpoint
+	r.mux.Unlock()
+
+	// Only blocks with enough confirmations on top of them are queried
+	if head < uint64(sub.Confirmations) || head-uint64(sub.Confirmations) < checkpoint {
+		r.setCheckpoint(sub, checkpoint, false)
+		return false, nil
+	}
+	confirmed := head - uint64(sub.Confirmations)
+	toBlock := checkpoint + r.maxBlockRange - 1
+	if toBlock > confirmed {
+		toBlock = confirmed
+	}
+
+	if err := r.deliverLogs(ctx, sub, checkpoint, toBlock); err != nil {
+		r.mux.Lock()
+		sub.LastError = err.Error()
+		r.mux.Unlock()
+		return false, err
+	}
+
+	catchup = toBlock < confirmed
+	r.setCheckpoint(sub, toBlock+1, catchup)
+	return catchup, nil
+}
+
+// deliverLogs queries the logs matching a subscription in an inclusive range of blocks, and dispatches them as a single batch
+func (r *EthRPC) deliverLogs(ctx context.Context, sub *rpcSubscription, fromBlock, toBlock uint64) error {
+	query := &rpcLogQuery{
+		FromBlock: ethtypes.HexUint64(fromBlock),
+		ToBlock:   ethtypes.HexUint64(toBlock),
+		Topics:    [][]ethtypes.HexBytes0xPrefix{{}},
+	}
+	anyAddress := false
+	for _, f := range sub.Filters {
+		query.Topics[0] = append(query.Topics[0], f.Event.SignatureHashBytes())
+		if f.Address == "" {
+			anyAddress = true
+		} else {
+			query.Address = append(query.Address, f.Address)
+		}
+	}
+	if anyAddress {
+		query.Address = nil
+	}
+
+	var logs []*rpcLog
+	if rpcErr := r.rpc.CallRPC(ctx, &logs, "eth_getLogs", query); rpcErr != nil {
+		return i18n.WrapError(ctx, rpcErr.Error(), coremsgs.MsgEthRPCErr, rpcErr.Message)
+	}
+	sort.Slice(logs, func(i, j int) bool {
+		if logs[i].BlockNumber != logs[j].BlockNumber {
+			return logs[i].BlockNumber < logs[j].BlockNumber
+		}
+		if logs[i].TransactionIndex != logs[j].TransactionIndex {
+			return logs[i].TransactionIndex < logs[j].TransactionIndex
+		}
+		return logs[i].LogIndex < logs[j].LogIndex
+	})
+
+	events := make(common.EventsToDispatch)
+	timestamps := make(map[uint64]*fftypes.FFTime)
+	for _, l := range logs {
+		if err := r.processLog(ctx, events, sub, l, timestamps); err != nil {
+			return err
+		}
+	}
+	return r.callbacks.DispatchBlockchainEvents(ctx, events)
+}
+
+func (r *EthRPC) setCheckpoint(sub *rpcSubscription, checkpoint uint64, catchup bool) {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+	sub.Checkpoint = checkpoint
+	sub.Catchup = catchup
+	sub.LastError = ""
+}
+
+func (r *EthRPC) getBlockTimestamp(ctx context.Context, blockNumber uint64, timestamps map[uint64]*fftypes.FFTime) (*fftypes.FFTime, error) {
+	if ts, ok := timestamps[blockNumber]; ok {
+		return ts, nil
+	}
+	var block *rpcBlock
+	if rpcErr := r.rpc.CallRPC(ctx, &block, "eth_getBlockByNumber", ethtypes.HexUint64(blockNumber), false); rpcErr != nil {
+		return nil, i18n.WrapError(ctx, rpcErr.Error(), coremsgs.MsgEthRPCErr, rpcErr.Message)
+	}
+	if block == nil {
+		return nil, i18n.NewError(ctx, coremsgs.MsgEthRPCErr, fmt.Sprintf("block %d not found", blockNumber))
+	}
+	ts := fftypes.FFTime(time.Unix(int64(block.Timestamp.Uint64()), 0).UTC())
+	timestamps[blockNumber] = &ts
+	return &ts, nil
+}
+
+func (r *EthRPC) matchFilter(sub *rpcSubscription, l *rpcLog) *rpcFilter {
+	if len(l.Topics) == 0 {
+		return nil
+	}
+	for _, f := range sub.Filters {
+		if bytes.Equal(l.Topics[0], f.Event.SignatureHashBytes()) &&
+			(f.Address == "" || strings.EqualFold(f.Address, l.Address)) {
+			return f
+		}
+	}
+	return nil
+}
+
+func (r *EthRPC) processLog(ctx context.Context, events common.EventsToDispatch, sub *rpcSubscription, l *rpcLog, timestamps map[uint64]*fftypes.FFTime) error {
+	f := r.matchFilter(sub, l)
+	if f == nil {
+		return nil
+	}
+	signature, err := f.Event.SignatureCtx(ctx)
+	if err != nil {
+		return err
+	}
+	logger := log.L(ctx)
+	logger.Infof("[EVM:%d/%d/%d]: '%s' on '%s'", l.BlockNumber, l.TransactionIndex, l.LogIndex, signature, sub.ID)
+
+	decoded, err := f.Event.DecodeEventDataCtx(ctx, l.Topics, l.Data)
+	if err != nil {
+		// An event that does not match the ABI (such as one with different indexed fields) cannot be delivered
+		logger.Errorf("Ignoring event that cannot be decoded as '%s': %s", signature, err)
+		return nil
+	}
+	output, err := eventSerializer.SerializeInterfaceCtx(ctx, decoded)
+	if err != nil {
+		return err
+	}
+	outputJSON, _ := output.(map[string]interface{})
+
+	timestamp, err := r.getBlockTimestamp(ctx, l.BlockNumber.Uint64(), timestamps)
+	if err != nil {
+		return err
+	}
+
+	address := strings.ToLower(l.Address)
+	event := &blockchain.Event{
+		BlockchainTXID: l.TransactionHash,
+		Source:         r.Name(),
+		Name:           f.Event.Name,
+		ProtocolID:     fmt.Sprintf("%.12d/%.6d/%.6d", l.BlockNumber, l.TransactionIndex, l.LogIndex),
+		Output:         outputJSON,
+		Info: fftypes.JSONObject{
+			"address":          address,
+			"blockHash":        l.BlockHash,
+			"blockNumber":      strconv.FormatUint(l.BlockNumber.Uint64(), 10),
+			"logIndex":         strconv.FormatUint(l.LogIndex.Uint64(), 10),
+			"signature":        signature,
+			"subId":            sub.ID,
+			"timestamp":        timestamp.String(),
+			"transactionHash":  l.TransactionHash,
+			"transactionIndex": strconv.FormatUint(l.TransactionIndex.Uint64(), 10),
+		},
+		Timestamp: timestamp,
+		Location:  fmt.Sprintf("address=%s", address),
+		Signature: signature,
+	}
+
+	if sub.FireFlyContract {
+		return r.processRPCBatchPinEvent(ctx, events, sub, address, event)
+	}
+	r.callbacks.PrepareBlockchainEvent(ctx, events, sub.Namespace, &blockchain.EventForListener{
+		Event:      event,
+		ListenerID: sub.ID,
+	})
+	return nil
+}
+
+func (r *EthRPC) processRPCBatchPinEvent(ctx context.Context, events common.EventsToDispatch, sub *rpcSubscription, address string, event *blockchain.Event) error {
+	subInfo := r.subs.GetSubscription(sub.ID)
+	if subInfo == nil {
+		log.L(ctx).Infof("Ignoring BatchPin event for removed subscription %s", sub.ID)
+		return nil
+	}
+	location, err := r.encodeContractLocation(ctx, &Location{Address: address})
+	if err != nil {
+		return err
+	}
+
+	nsOrAction := event.Output.GetString("action")
+	if nsOrAction == "" {
+		nsOrAction = event.Output.GetString("namespace")
+	}
+	params := &common.BatchPinParams{
+		UUIDs:      event.Output.GetString("uuids"),
+		BatchHash:  event.Output.GetString("batchHash"),
+		PayloadRef: event.Output.GetString("payloadRef"),
+		Contexts:   event.Output.GetStringArray("contexts"),
+		NsOrAction: nsOrAction,
+	}
+
+	authorAddress, err := formatEthAddress(ctx, event.Output.GetString("author"))
+	if err != nil {
+		log.L(ctx).Errorf("BatchPin event is not valid - bad from address (%s): %+v", err, event.Output)
+		return nil // move on
+	}
+	verifier := &core.VerifierRef{
+		Type:  core.VerifierTypeEthAddress,
+		Value: authorAddress,
+	}
+
+	r.callbacks.PrepareBatchPinOrNetworkAction(ctx, events, subInfo, location, event, verifier, params)
+	return nil
+}
@@ -0,0 +1,500 @@
+// Copyright © 2024 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ethereum
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/hyperledger/firefly-common/pkg/fftypes"
+	"github.com/hyperledger/firefly-signer/pkg/abi"
+	"github.com/hyperledger/firefly-signer/pkg/ethsigner"
+	"github.com/hyperledger/firefly-signer/pkg/ethtypes"
+	"github.com/hyperledger/firefly-signer/pkg/rpcbackend"
+	"github.com/stretchr/testify/assert"
+)
+
+// simRevert is returned by a simulated contract to revert the call, with optional revert data
+type simRevert struct {
+	data []byte
+}
+
+type simLog struct {
+	topics []ethtypes.HexBytes0xPrefix
+	data   []byte
+}
+
+// simContract executes calls and transactions against a contract on the simulated chain.
+// State must only be changed when write is true.
+type simContract func(from string, data []byte, write bool) (result []byte, logs []*simLog, revert *simRevert)
+
+type simTx struct {
+	hash string
+	from string
+	tx   *ethsigner.Transaction
+}
+
+// simChain is an in-memory Ethereum node, serving just enough of the JSON-RPC API for the ethrpc plugin.
+// Each transaction is mined into its own block, unless holdTxs is set.
+type simChain struct {
+	t         *testing.T
+	mux       sync.Mutex
+	server    *httptest.Server
+	chainID   int64
+	head      uint64
+	logs      []*rpcLog
+	receipts  map[string]*rpcReceipt
+	txs       map[string]*simTx
+	pending   []*simTx
+	holdTxs   bool
+	nonces    map[string]uint64
+	contracts map[string]simContract
+	deploy    func(data []byte) simContract
+	txCount   int
+	errors    map[string]*rpcbackend.RPCError
+	calls     map[string]int
+}
+
+func newSimChain(t *testing.T) *simChain {
+	c := &simChain{
+		t:         t,
+		chainID:   2024,
+		receipts:  make(map[string]*rpcReceipt),
+		txs:       make(map[string]*simTx),
+		nonces:    make(map[string]uint64),
+		contracts: make(map[string]simContract),
+		errors:    make(map[string]*rpcbackend.RPCError),
+		calls:     make(map[string]int),
+	}
+	c.server = httptest.NewServer(http.HandlerFunc(c.serveHTTP))
+	t.Cleanup(c.server.Close)
+	return c
+}
+
+func (c *simChain) url() string {
+	return c.server.URL
+}
+
+func (c *simChain) setError(method string, rpcErr *rpcbackend.RPCError) {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	c.errors[method] = rpcErr
+}
+
+func (c *simChain) callCount(method string) int {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	return c.calls[method]
+}
+
+// mineBlocks adds empty blocks to the chain
+func (c *simChain) mineBlocks(count int) {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	c.head += uint64(count)
+}
+
+// minePending mines all held transactions into a single block
+func (c *simChain) minePending() {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	c.head++
+	for i, stx := range c.pending {
+		c.execTx(stx, uint64(i))
+	}
+	c.pending = nil
+}
+
+func (c *simChain) blockHash(blockNumber uint64) string {
+	return fmt.Sprintf("0x%064x", 0xb10c0000+blockNumber)
+}
+
+func (c *simChain) blockTimestamp(blockNumber uint64) uint64 {
+	return 1700000000 + blockNumber*2
+}
+
+func (c *simChain) serveHTTP(w http.ResponseWriter, req *http.Request) {
+	var rpcReq rpcbackend.RPCRequest
+	err := json.NewDecoder(req.Body).Decode(&rpcReq)
+	assert.NoError(c.t, err)
+
+	c.mux.Lock()
+	c.calls[rpcReq.Method]++
+	result, rpcErr := c.errors[rpcReq.Method], (*rpcbackend.RPCError)(nil)
+	var res interface{}
+	if result != nil {
+		rpcErr = result
+	} else {
+		res, rpcErr = c.handle(rpcReq.Method, rpcReq.Params)
+	}
+	c.mux.Unlock()
+
+	rpcRes := &rpcbackend.RPCResponse{
+		JSONRpc: "2.0",
+		ID:      rpcReq.ID,
+		Error:   rpcErr,
+	}
+	if rpcErr == nil {
+		b, _ := json.Marshal(res)
+		rpcRes.Result = fftypes.JSONAnyPtrBytes(b)
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(rpcRes)
+}
+
+func (c *simChain) param(params []*fftypes.JSONAny, idx int, v interface{}) {
+	assert.Greater(c.t, len(params), idx)
+	err := json.Unmarshal(params[idx].Bytes(), v)
+	assert.NoError(c.t, err)
+}
+
+func (c *simChain) revertError(revert *simRevert) *rpcbackend.RPCError {
+	rpcErr := &rpcbackend.RPCError{Code: 3, Message: "execution reverted"}
+	if len(revert.data) > 0 {
+		rpcErr.Data = *fftypes.JSONAnyPtr(fmt.Sprintf(`"%s"`, ethtypes.HexBytes0xPrefix(revert.data)))
+	}
+	return rpcErr
+}
+
+func (c *simChain) call(tx *ethsigner.Transaction) ([]byte, *rpcbackend.RPCError) {
+	if tx.To == nil {
+		return []byte{}, nil
+	}
+	contract := c.contracts[strings.ToLower(tx.To.String())]
+	if contract == nil {
+		return []byte{}, nil
+	}
+	var from string
+	_ = json.Unmarshal(tx.From, &from)
+	result, _, revert := contract(strings.ToLower(from), tx.Data, false)
+	if revert != nil {
+		return nil, c.revertError(revert)
+	}
+	return result, nil
+}
+
+func (c *simChain) handle(method string, params []*fftypes.JSONAny) (interface{}, *rpcbackend.RPCError) {
+	switch method {
+	case "eth_chainId":
+		return ethtypes.NewHexIntegerU64(uint64(c.chainID)), nil
+	case "eth_blockNumber":
+		return ethtypes.HexUint64(c.head), nil
+	case "eth_gasPrice":
+		return ethtypes.NewHexIntegerU64(1000000000), nil
+	case "eth_getTransactionCount":
+		var addr string
+		c.param(params, 0, &addr)
+		return ethtypes.HexUint64(c.nonces[strings.ToLower(addr)]), nil
+	case "eth_estimateGas":
+		var tx ethsigner.Transaction
+		c.param(params, 0, &tx)
+		if _, rpcErr := c.call(&tx); rpcErr != nil {
+			return nil, rpcErr
+		}
+		return ethtypes.NewHexIntegerU64(100000), nil
+	case "eth_call":
+		var tx ethsigner.Transaction
+		c.param(params, 0, &tx)
+		result, rpcErr := c.call(&tx)
+		if rpcErr != nil {
+			return nil, rpcErr
+		}
+		return ethtypes.HexBytes0xPrefix(result), nil
+	case "eth_sendRawTransaction":
+		var raw ethtypes.HexBytes0xPrefix
+		c.param(params, 0, &raw)
+		return c.sendRawTransaction(raw)
+	case "eth_getTransactionReceipt":
+		var hash string
+		c.param(params, 0, &hash)
+		if receipt, ok := c.receipts[hash]; ok {
+			return receipt, nil
+		}
+		return nil, nil
+	case "eth_getTransactionByHash":
+		var hash string
+		c.param(params, 0, &hash)
+		stx, ok := c.txs[hash]
+		if !ok {
+			return nil, nil
+		}
+		return &rpcTransaction{
+			Hash:  stx.hash,
+			From:  ethtypes.MustNewAddress(stx.from),
+			To:    stx.tx.To,
+			Nonce: ethtypes.HexUint64(stx.tx.Nonce.Uint64()),
+			Gas:   stx.tx.GasLimit,
+			Value: stx.tx.Value,
+			Input: stx.tx.Data,
+		}, nil
+	case "eth_getBlockByNumber":
+		var blockNumber ethtypes.HexUint64
+		c.param(params, 0, &blockNumber)
+		if blockNumber.Uint64() > c.head {
+			return nil, nil
+		}
+		return &rpcBlock{
+			Number:    blockNumber,
+			Hash:      c.blockHash(blockNumber.Uint64()),
+			Timestamp: ethtypes.HexUint64(c.blockTimestamp(blockNumber.Uint64())),
+		}, nil
+	case "eth_getLogs":
+		var query rpcLogQuery
+		c.param(params, 0, &query)
+		return c.getLogs(&query), nil
+	default:
+		return nil, &rpcbackend.RPCError{Code: -32601, Message: fmt.Sprintf("method %s not found", method)}
+	}
+}
+
+func (c *simChain) sendRawTransaction(raw ethtypes.HexBytes0xPrefix) (interface{}, *rpcbackend.RPCError) {
+	from, tx, err := ethsigner.RecoverRawTransaction(context.Background(), raw, c.chainID)
+	if err != nil {
+		return nil, &rpcbackend.RPCError{Code: -32000, Message: err.Error()}
+	}
+	sender := strings.ToLower(from.String())
+	if tx.Nonce.Uint64() != c.nonces[sender] {
+		return nil, &rpcbackend.RPCError{Code: -32000, Message: fmt.Sprintf("invalid nonce %d (expected %d)", tx.Nonce.Uint64(), c.nonces[sender])}
+	}
+	c.nonces[sender]++
+	c.txCount++
+	stx := &simTx{
+		hash: fmt.Sprintf("0x%064x", 0x7e0000+c.txCount),
+		from: sender,
+		tx:   tx.Transaction,
+	}
+	c.txs[stx.hash] = stx
+	if c.holdTxs {
+		c.pending = append(c.pending, stx)
+	} else {
+		c.head++
+		c.execTx(stx, 0)
+	}
+	return stx.hash, nil
+}
+
+func (c *simChain) execTx(stx *simTx, txIndex uint64) {
+	receipt := &rpcReceipt{
+		TransactionHash:  stx.hash,
+		BlockNumber:      ethtypes.HexUint64(c.head),
+		BlockHash:        c.blockHash(c.head),
+		TransactionIndex: ethtypes.HexUint64(txIndex),
+		From:             stx.from,
+		To:               stx.tx.To,
+		GasUsed:          ethtypes.NewHexIntegerU64(50000),
+		Status:           ethtypes.NewHexIntegerU64(1),
+	}
+	c.receipts[stx.hash] = receipt
+
+	var contract simContract
+	var to string
+	if stx.tx.To == nil {
+		contractAddress := ethtypes.MustNewAddress(fmt.Sprintf("0x%040x", 0xc0000+len(c.contracts)))
+		receipt.ContractAddress = contractAddress
+		to = strings.ToLower(contractAddress.String())
+		if c.deploy != nil {
+			c.contracts[to] = c.deploy(stx.tx.Data)
+		}
+		return
+	}
+	to = strings.ToLower(stx.tx.To.String())
+	contract = c.contracts[to]
+	if contract == nil {
+		return
+	}
+	_, logs, revert := contract(stx.from, stx.tx.Data, true)
+	if revert != nil {
+		receipt.Status = ethtypes.NewHexIntegerU64(0)
+		return
+	}
+	for _, l := range logs {
+		c.logs = append(c.logs, &rpcLog{
+			Address:          to,
+			Topics:           l.topics,
+			Data:             l.data,
+			BlockNumber:      ethtypes.HexUint64(c.head),
+			BlockHash:        c.blockHash(c.head),
+			TransactionHash:  stx.hash,
+			TransactionIndex: ethtypes.HexUint64(txIndex),
+			LogIndex:         ethtypes.HexUint64(len(c.logs)),
+		})
+	}
+}
+
+func (c *simChain) getLogs(query *rpcLogQuery) []*rpcLog {
+	logs := []*rpcLog{}
+	for _, l := range c.logs {
+		if l.BlockNumber < query.FromBlock || l.BlockNumber > query.ToBlock {
+			continue
+		}
+		if len(query.Address) > 0 {
+			match := false
+			for _, a := range query.Address {
+				match = match || strings.EqualFold(a, l.Address)
+			}
+			if !match {
+				continue
+			}
+		}
+		if len(query.Topics) > 0 && len(query.Topics[0]) > 0 {
+			match := false
+			for _, topic := range query.Topics[0] {
+				match = match || bytes.Equal(topic, l.Topics[0])
+			}
+			if !match {
+				continue
+			}
+		}
+		logs = append(logs, l)
+	}
+	return logs
+}
+
+func simSelector(method *abi.Entry) []byte {
+	return method.FunctionSelectorBytes()
+}
+
+func simDecodeInput(t *testing.T, method *abi.Entry, data []byte) map[string]interface{} {
+	values, err := method.Inputs.DecodeABIDataCtx(context.Background(), data[4:], 0)
+	assert.NoError(t, err)
+	input, err := eventSerializer.SerializeInterfaceCtx(context.Background(), values)
+	assert.NoError(t, err)
+	return input.(map[string]interface{})
+}
+
+// newSimFireFlyContract simulates the FireFly multiparty contract. Version 1 contracts do not
+// implement networkVersion, so the call reverts.
+func newSimFireFlyContract(t *testing.T, version int, timestamp int64) simContract {
+	ctx := context.Background()
+	emitBatchPin := func(values []interface{}) []*simLog {
+		data, err := batchPinEventABI.Inputs.EncodeABIDataValuesCtx(ctx, values)
+		assert.NoError(t, err)
+		return []*simLog{{
+			topics: []ethtypes.HexBytes0xPrefix{batchPinEventABI.SignatureHashBytes()},
+			data:   data,
+		}}
+	}
+	return func(from string, data []byte, write bool) ([]byte, []*simLog, *simRevert) {
+		switch {
+		case bytes.HasPrefix(data, simSelector(networkVersionMethodABI)):
+			if version == 1 {
+				return nil, nil, &simRevert{}
+			}
+			result, err := networkVersionMethodABI.Outputs.EncodeABIDataValuesCtx(ctx, []interface{}{version})
+			assert.NoError(t, err)
+			return result, nil, nil
+		case bytes.HasPrefix(data, simSelector(batchPinMethodABI)):
+			input := simDecodeInput(t, batchPinMethodABI, data)
+			return nil, emitBatchPin([]interface{}{
+				from, timestamp, "", input["uuids"], input["batchHash"], input["payloadRef"], input["contexts"],
+			}), nil
+		case bytes.HasPrefix(data, simSelector(networkActionMethodABI)):
+			input := simDecodeInput(t, networkActionMethodABI, data)
+			return nil, emitBatchPin([]interface{}{
+				from, timestamp, input["action"], ethHexFormatB32(nil), ethHexFormatB32(nil), "", []string{},
+			}), nil
+		default:
+			return nil, nil, &simRevert{}
+		}
+	}
+}
+
+const simStorageABI = `[
+	{
+		"type": "constructor",
+		"inputs": [{"name": "initial", "type": "uint256"}]
+	},
+	{
+		"name": "set",
+		"type": "function",
+		"inputs": [{"name": "x", "type": "uint256"}],
+		"outputs": []
+	},
+	{
+		"name": "get",
+		"type": "function",
+		"stateMutability": "view",
+		"inputs": [],
+		"outputs": [{"name": "x", "type": "uint256"}]
+	},
+	{
+		"name": "Changed",
+		"type": "event",
+		"inputs": [
+			{"name": "from", "type": "address", "indexed": true},
+			{"name": "value", "type": "uint256", "indexed": false}
+		]
+	},
+	{
+		"name": "BadValue",
+		"type": "error",
+		"inputs": [{"name": "value", "type": "uint256"}]
+	}
+]`
+
+func simStorageContractABI(t *testing.T) abi.ABI {
+	var a abi.ABI
+	err := json.Unmarshal([]byte(simStorageABI), &a)
+	assert.NoError(t, err)
+	return a
+}
+
+// newSimStorageContract simulates a contract that stores a single non-zero value, emitting an event when it changes
+func newSimStorageContract(t *testing.T, initial int64) simContract {
+	ctx := context.Background()
+	a := simStorageContractABI(t)
+	value := big.NewInt(initial)
+	return func(from string, data []byte, write bool) ([]byte, []*simLog, *simRevert) {
+		switch {
+		case bytes.HasPrefix(data, simSelector(a.Functions()["set"])):
+			input := simDecodeInput(t, a.Functions()["set"], data)
+			x, _ := new(big.Int).SetString(input["x"].(string), 10)
+			if x.Sign() == 0 {
+				revert, err := a.Errors()["BadValue"].EncodeCallDataValuesCtx(ctx, []interface{}{x})
+				assert.NoError(t, err)
+				return nil, nil, &simRevert{data: revert}
+			}
+			if !write {
+				return []byte{}, nil, nil
+			}
+			value = x
+			changed := a.Events()["Changed"]
+			eventData, err := abi.ParameterArray{changed.Inputs[1]}.EncodeABIDataValuesCtx(ctx, []interface{}{x})
+			assert.NoError(t, err)
+			fromTopic, err := abi.ParameterArray{changed.Inputs[0]}.EncodeABIDataValuesCtx(ctx, []interface{}{from})
+			assert.NoError(t, err)
+			return []byte{}, []*simLog{{
+				topics: []ethtypes.HexBytes0xPrefix{changed.SignatureHashBytes(), fromTopic},
+				data:   eventData,
+			}}, nil
+		case bytes.HasPrefix(data, simSelector(a.Functions()["get"])):
+			result, err := a.Functions()["get"].Outputs.EncodeABIDataValuesCtx(ctx, []interface{}{value})
+			assert.NoError(t, err)
+			return result, nil, nil
+		default:
+			return nil, nil, &simRevert{}
+		}
+	}
+}
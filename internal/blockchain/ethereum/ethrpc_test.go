@@ -0,0 +1,1278 @@
+// Copyright © 2024 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ethereum
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/firefly-common/pkg/config"
+	"github.com/hyperledger/firefly-common/pkg/ffresty"
+	"github.com/hyperledger/firefly-common/pkg/fftypes"
+	"github.com/hyperledger/firefly-signer/pkg/ethtypes"
+	"github.com/hyperledger/firefly-signer/pkg/ffi2abi"
+	"github.com/hyperledger/firefly-signer/pkg/fswallet"
+	"github.com/hyperledger/firefly-signer/pkg/keystorev3"
+	"github.com/hyperledger/firefly-signer/pkg/rpcbackend"
+	"github.com/hyperledger/firefly-signer/pkg/secp256k1"
+	"github.com/hyperledger/firefly/internal/blockchain/common"
+	"github.com/hyperledger/firefly/internal/cache"
+	"github.com/hyperledger/firefly/internal/coreconfig"
+	"github.com/hyperledger/firefly/mocks/blockchainmocks"
+	"github.com/hyperledger/firefly/mocks/cachemocks"
+	"github.com/hyperledger/firefly/mocks/coremocks"
+	"github.com/hyperledger/firefly/mocks/metricsmocks"
+	"github.com/hyperledger/firefly/pkg/blockchain"
+	"github.com/hyperledger/firefly/pkg/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+var utRPCConfig = config.RootSection("ethrpc_unit_tests")
+
+const (
+	testFireFlyAddress = "0x1c197604587f046fd40684a8f21f4609fb811a7b"
+	testStorageAddress = "0x5e1d0f6f0b3b6a5b1a1bbd6d2c5f4e3d2c1b0a99"
+)
+
+type testEthRPC struct {
+	*EthRPC
+	chain *simChain
+	key   string
+	em    *blockchainmocks.Callbacks
+	om    *coremocks.OperationQueryCallbacks
+	done  func()
+}
+
+func writeTestKeystore(t *testing.T) (dir, address string) {
+	dir = t.TempDir()
+	keypair, err := secp256k1.GenerateSecp256k1KeyPair()
+	assert.NoError(t, err)
+	address = keypair.Address.String()
+	wallet := keystorev3.NewWalletFileLight("pass", keypair)
+	err = os.WriteFile(filepath.Join(dir, address[2:]+defaultRPCKeystorePrimaryExt), wallet.JSON(), 0600)
+	assert.NoError(t, err)
+	err = os.WriteFile(filepath.Join(dir, address[2:]+defaultRPCKeystorePasswordExt), []byte("pass"), 0600)
+	assert.NoError(t, err)
+	return dir, address
+}
+
+func resetRPCConf(r *EthRPC, url, keystorePath string) {
+	coreconfig.Reset()
+	r.InitConfig(utRPCConfig)
+	utRPCConfig.SubSection(RPCConfigKey).Set(ffresty.HTTPConfigURL, url)
+	utRPCConfig.SubSection(KeystoreConfigKey).Set(fswallet.ConfigPath, keystorePath)
+	utRPCConfig.SubSection(KeystoreConfigKey).Set(fswallet.ConfigDisableListener, true)
+	utRPCConfig.Set(RPCConfigPollingInterval, "10ms")
+}
+
+func newTestMetrics() *metricsmocks.Manager {
+	mm := &metricsmocks.Manager{}
+	mm.On("IsMetricsEnabled").Return(true)
+	mm.On("BlockchainTransaction", mock.Anything, mock.Anything).Return(nil)
+	mm.On("BlockchainContractDeployment", mock.Anything, mock.Anything).Return(nil)
+	mm.On("BlockchainQuery", mock.Anything, mock.Anything).Return(nil)
+	return mm
+}
+
+func newTestCacheManager(ctx context.Context) *cachemocks.Manager {
+	cmi := &cachemocks.Manager{}
+	cmi.On("GetCache", mock.Anything).Return(cache.NewUmanagedCache(ctx, 100, 5*time.Minute), nil)
+	return cmi
+}
+
+func newTestEthRPC(t *testing.T, setConf ...func()) *testEthRPC {
+	chain := newSimChain(t)
+	chain.contracts[testFireFlyAddress] = newSimFireFlyContract(t, 2, 1700000000)
+	chain.contracts[testStorageAddress] = newSimStorageContract(t, 7)
+	keystorePath, key := writeTestKeystore(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	r := &EthRPC{}
+	resetRPCConf(r, chain.url(), keystorePath)
+	for _, fn := range setConf {
+		fn()
+	}
+	err := r.Init(ctx, cancel, utRPCConfig, newTestMetrics(), newTestCacheManager(ctx))
+	assert.NoError(t, err)
+
+	te := &testEthRPC{
+		EthRPC: r,
+		chain:  chain,
+		key:    key,
+		em:     &blockchainmocks.Callbacks{},
+		om:     &coremocks.OperationQueryCallbacks{},
+	}
+	r.SetHandler("ns1", te.em)
+	r.SetOperationHandler("ns1", te.om)
+	// Namespaces are marked active directly, so tests can drive the poller one cycle at a time
+	r.namespaces["ns1"] = true
+	te.done = func() {
+		cancel()
+		if r.pollerDone != nil {
+			<-r.pollerDone
+		}
+		te.em.AssertExpectations(t)
+		te.om.AssertExpectations(t)
+	}
+	return te
+}
+
+func storageFFI(t *testing.T) (ffi *fftypes.FFI, methods map[string]*fftypes.FFIMethod) {
+	a := simStorageContractABI(t)
+	ffi, err := ffi2abi.ConvertABIToFFI(context.Background(), "ns1", "storage", "1.0", "", &a)
+	assert.NoError(t, err)
+	methods = make(map[string]*fftypes.FFIMethod)
+	for _, m := range ffi.Methods {
+		methods[m.Name] = m
+	}
+	return ffi, methods
+}
+
+func (te *testEthRPC) parseStorageMethod(t *testing.T, name string) interface{} {
+	ffi, methods := storageFFI(t)
+	parsed, err := te.ParseInterface(context.Background(), methods[name], ffi.Errors)
+	assert.NoError(t, err)
+	return parsed
+}
+
+func (te *testEthRPC) expectOpUpdate(nsOpID string, status core.OpStatus) *mock.Call {
+	return te.om.On("OperationUpdate", mock.MatchedBy(func(update *core.OperationUpdate) bool {
+		return update.Plugin == "ethrpc" && update.NamespacedOpID == nsOpID && update.Status == status
+	})).Once()
+}
+
+func (te *testEthRPC) expectPendingOperations(skip uint64, ops []*core.Operation) *mock.Call {
+	return te.om.On("GetPendingOperations", mock.Anything, "ethrpc", transactionOpTypes, skip, uint64(pendingOperationsPageSize)).Return(ops, nil).Once()
+}
+
+func (te *testEthRPC) lastOpUpdate() *core.OperationUpdate {
+	return te.om.Calls[len(te.om.Calls)-1].Arguments[0].(*core.OperationUpdate)
+}
+
+func TestEthRPCInitMissingURL(t *testing.T) {
+	r := &EthRPC{}
+	resetRPCConf(r, "", t.TempDir())
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	err := r.Init(ctx, cancel, utRPCConfig, newTestMetrics(), newTestCacheManager(ctx))
+	assert.Regexp(t, "FF10138.*url", err)
+	assert.Equal(t, "ethrpc", r.Name())
+}
+
+func TestEthRPCInitMissingKeystore(t *testing.T) {
+	r := &EthRPC{}
+	resetRPCConf(r, "http://localhost:8545", "")
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	err := r.Init(ctx, cancel, utRPCConfig, newTestMetrics(), newTestCacheManager(ctx))
+	assert.Regexp(t, "FF10138.*path", err)
+}
+
+func TestEthRPCInitCacheFail(t *testing.T) {
+	r := &EthRPC{}
+	resetRPCConf(r, "http://localhost:8545", t.TempDir())
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	cmi := &cachemocks.Manager{}
+	cmi.On("GetCache", mock.Anything).Return(nil, fmt.Errorf("pop"))
+	err := r.Init(ctx, cancel, utRPCConfig, newTestMetrics(), cmi)
+	assert.Regexp(t, "pop", err)
+}
+
+func TestEthRPCGetAndConvertDeprecatedContractConfig(t *testing.T) {
+	te := newTestEthRPC(t)
+	defer te.done()
+	_, _, err := te.GetAndConvertDeprecatedContractConfig(context.Background())
+	assert.Regexp(t, "FF10138", err)
+}
+
+func TestEthRPCBatchPinEndToEnd(t *testing.T) {
+	te := newTestEthRPC(t)
+	defer te.done()
+	ctx := context.Background()
+
+	location := fftypes.JSONAnyPtr(fmt.Sprintf(`{"address":"%s"}`, testFireFlyAddress))
+	subID, err := te.AddFireflySubscription(ctx, &core.Namespace{Name: "ns1", NetworkName: "ns1"}, &blockchain.MultipartyContract{
+		Location:   location,
+		FirstEvent: string(core.SubOptsFirstEventOldest),
+	}, "")
+	assert.NoError(t, err)
+	assert.NotEmpty(t, subID)
+
+	batch := &blockchain.BatchPin{
+		TransactionID:   fftypes.NewUUID(),
+		BatchID:         fftypes.NewUUID(),
+		BatchHash:       fftypes.NewRandB32(),
+		BatchPayloadRef: "Qmf412jQZiuVUtdgnB36FXFX7xg5V6KEbSJ4dpQuhkLyfD",
+		Contexts:        []*fftypes.Bytes32{fftypes.NewRandB32(), fftypes.NewRandB32()},
+	}
+	nsOpID := "ns1:" + fftypes.NewUUID().String()
+	te.expectOpUpdate(nsOpID, core.OpStatusPending)
+	err = te.SubmitBatchPin(ctx, nsOpID, "ns1", te.key, batch, location)
+	assert.NoError(t, err)
+	pending := te.lastOpUpdate()
+	assert.NotEmpty(t, pending.BlockchainTXID)
+	assert.Equal(t, pending.BlockchainTXID, pending.Output.GetString("transactionHash"))
+
+	te.expectOpUpdate(nsOpID, core.OpStatusSucceeded)
+	te.em.On("BlockchainEventBatch", mock.MatchedBy(func(events []*blockchain.EventToDispatch) bool {
+		return len(events) == 1 &&
+			events[0].Type == blockchain.EventTypeBatchPinComplete &&
+			events[0].BatchPinComplete.Namespace == "ns1" &&
+			events[0].BatchPinComplete.SigningKey.Value == te.key
+	})).Return(nil).Once()
+
+	catchup, err := te.poll(ctx)
+	assert.NoError(t, err)
+	assert.False(t, catchup)
+
+	b := te.em.Calls[0].Arguments[0].([]*blockchain.EventToDispatch)[0].BatchPinComplete.Batch
+	assert.Equal(t, batch.TransactionID, b.TransactionID)
+	assert.Equal(t, batch.BatchID, b.BatchID)
+	assert.Equal(t, batch.BatchHash, b.BatchHash)
+	assert.Equal(t, batch.BatchPayloadRef, b.BatchPayloadRef)
+	assert.Equal(t, batch.Contexts, b.Contexts)
+	assert.Equal(t, "ethrpc", b.Event.Source)
+	assert.Equal(t, "BatchPin", b.Event.Name)
+	assert.Equal(t, "000000000001/000000/000000", b.Event.ProtocolID)
+	assert.Equal(t, pending.BlockchainTXID, b.Event.BlockchainTXID)
+	assert.Equal(t, "address="+testFireFlyAddress, b.Event.Location)
+	assert.Equal(t, subID, b.Event.Info["subId"])
+
+	succeeded := te.lastOpUpdate()
+	assert.Equal(t, "000000000001/000000", succeeded.Output.GetString("protocolId"))
+
+	// Nothing more to deliver on the next cycle
+	catchup, err = te.poll(ctx)
+	assert.NoError(t, err)
+	assert.False(t, catchup)
+
+	te.RemoveFireflySubscription(ctx, subID)
+	assert.Empty(t, te.subscriptions)
+}
+
+func TestEthRPCNetworkAction(t *testing.T) {
+	te := newTestEthRPC(t)
+	defer te.done()
+	ctx := context.Background()
+
+	location := fftypes.JSONAnyPtr(fmt.Sprintf(`{"address":"%s"}`, testFireFlyAddress))
+	_, err := te.AddFireflySubscription(ctx, &core.Namespace{Name: "ns1", NetworkName: "ns1"}, &blockchain.MultipartyContract{
+		Location:   location,
+		FirstEvent: string(core.SubOptsFirstEventNewest),
+	}, "")
+	assert.NoError(t, err)
+
+	nsOpID := "ns1:" + fftypes.NewUUID().String()
+	te.expectOpUpdate(nsOpID, core.OpStatusPending)
+	err = te.SubmitNetworkAction(ctx, nsOpID, te.key, core.NetworkActionTerminate, location)
+	assert.NoError(t, err)
+
+	te.expectOpUpdate(nsOpID, core.OpStatusSucceeded)
+	te.em.On("BlockchainEventBatch", mock.MatchedBy(func(events []*blockchain.EventToDispatch) bool {
+		return len(events) == 1 &&
+			events[0].Type == blockchain.EventTypeNetworkAction &&
+			events[0].NetworkAction.Action == "terminate" &&
+			events[0].NetworkAction.SigningKey.Value == te.key
+	})).Return(nil).Once()
+
+	_, err = te.poll(ctx)
+	assert.NoError(t, err)
+}
+
+func TestEthRPCNetworkVersion(t *testing.T) {
+	te := newTestEthRPC(t)
+	defer te.done()
+	ctx := context.Background()
+
+	te.chain.contracts[testStorageAddress] = newSimFireFlyContract(t, 1, 1700000000)
+	version, err := te.GetNetworkVersion(ctx, fftypes.JSONAnyPtr(fmt.Sprintf(`{"address":"%s"}`, testStorageAddress)))
+	assert.NoError(t, err)
+	assert.Equal(t, 1, version)
+
+	location := fftypes.JSONAnyPtr(fmt.Sprintf(`{"address":"%s"}`, testFireFlyAddress))
+	version, err = te.GetNetworkVersion(ctx, location)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, version)
+
+	// Cached
+	version, err = te.GetNetworkVersion(ctx, location)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, version)
+	assert.Equal(t, 2, te.chain.callCount("eth_call"))
+}
+
+func TestEthRPCNetworkVersionFail(t *testing.T) {
+	te := newTestEthRPC(t)
+	defer te.done()
+
+	te.chain.setError("eth_call", &rpcbackend.RPCError{Code: -32000, Message: "pop"})
+	_, err := te.GetNetworkVersion(context.Background(), fftypes.JSONAnyPtr(fmt.Sprintf(`{"address":"%s"}`, testFireFlyAddress)))
+	assert.Regexp(t, "FF10532.*pop", err)
+}
+
+func TestEthRPCContractListener(t *testing.T) {
+	te := newTestEthRPC(t)
+	defer te.done()
+	ctx := context.Background()
+
+	ffi, _ := storageFFI(t)
+	listener := &core.ContractListener{
+		ID:        fftypes.NewUUID(),
+		Namespace: "ns1",
+		Filters: core.ListenerFilters{{
+			Event:    &core.FFISerializedEvent{FFIEventDefinition: ffi.Events[0].FFIEventDefinition},
+			Location: fftypes.JSONAnyPtr(fmt.Sprintf(`{"address":"%s"}`, testStorageAddress)),
+		}},
+		Options: &core.ContractListenerOptions{
+			FirstEvent:    string(core.SubOptsFirstEventOldest),
+			Confirmations: 2,
+		},
+	}
+	err := te.AddContractListener(ctx, listener, "")
+	assert.NoError(t, err)
+	assert.NotEmpty(t, listener.BackendID)
+
+	nsOpID := "ns1:" + fftypes.NewUUID().String()
+	te.expectOpUpdate(nsOpID, core.OpStatusPending)
+	te.expectOpUpdate(nsOpID, core.OpStatusSucceeded)
+	_, err = te.InvokeContract(ctx, nsOpID, te.key, fftypes.JSONAnyPtr(fmt.Sprintf(`{"address":"%s"}`, testStorageAddress)),
+		te.parseStorageMethod(t, "set"), map[string]interface{}{"x": 42}, nil, nil)
+	assert.NoError(t, err)
+
+	// Not yet confirmed
+	_, err = te.poll(ctx)
+	assert.NoError(t, err)
+	assert.Empty(t, te.em.Calls)
+
+	te.chain.mineBlocks(2)
+	te.em.On("BlockchainEventBatch", mock.MatchedBy(func(events []*blockchain.EventToDispatch) bool {
+		return len(events) == 1 &&
+			events[0].Type == blockchain.EventTypeForListener &&
+			events[0].ForListener.ListenerID == listener.BackendID
+	})).Return(nil).Once()
+	_, err = te.poll(ctx)
+	assert.NoError(t, err)
+
+	event := te.em.Calls[0].Arguments[0].([]*blockchain.EventToDispatch)[0].ForListener.Event
+	assert.Equal(t, "Changed", event.Name)
+	assert.Equal(t, "Changed(address,uint256)", event.Signature)
+	assert.Equal(t, fftypes.JSONObject{"from": te.key, "value": "42"}, event.Output)
+	assert.Equal(t, "1", event.Info["blockNumber"])
+	assert.Equal(t, "2023-11-14T22:13:22Z", event.Info["timestamp"])
+
+	found, detail, status, err := te.GetContractListenerStatus(ctx, "ns1", listener.BackendID, false)
+	assert.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, core.ContractListenerStatusSynced, status)
+	assert.Equal(t, int64(1), detail.(*ListenerStatus).Checkpoint.Block)
+
+	err = te.DeleteContractListener(ctx, listener, false)
+	assert.NoError(t, err)
+	err = te.DeleteContractListener(ctx, listener, false)
+	assert.Regexp(t, "FF10536", err)
+	err = te.DeleteContractListener(ctx, listener, true)
+	assert.NoError(t, err)
+
+	found, _, status, err = te.GetContractListenerStatus(ctx, "ns1", listener.BackendID, true)
+	assert.NoError(t, err)
+	assert.False(t, found)
+	assert.Equal(t, core.ContractListenerStatusUnknown, status)
+	_, _, _, err = te.GetContractListenerStatus(ctx, "ns1", listener.BackendID, false)
+	assert.Regexp(t, "FF10536", err)
+}
+
+func TestEthRPCContractListenerResumeCatchup(t *testing.T) {
+	te := newTestEthRPC(t, func() {
+		utRPCConfig.Set(RPCConfigMaxBlockRange, 2)
+	})
+	defer te.done()
+	ctx := context.Background()
+
+	te.chain.mineBlocks(10)
+	ffi, _ := storageFFI(t)
+	listener := &core.ContractListener{
+		ID:        fftypes.NewUUID(),
+		Namespace: "ns1",
+		Filters: core.ListenerFilters{{
+			Event: &core.FFISerializedEvent{FFIEventDefinition: ffi.Events[0].FFIEventDefinition},
+		}},
+	}
+	err := te.AddContractListener(ctx, listener, "000000000004/000000/000000")
+	assert.NoError(t, err)
+	// Delivery restarts from the block before the last event
+	assert.Equal(t, uint64(3), te.subscriptions[listener.BackendID].Checkpoint)
+
+	catchup, err := te.poll(ctx)
+	assert.NoError(t, err)
+	assert.True(t, catchup)
+
+	_, detail, status, err := te.GetContractListenerStatus(ctx, "ns1", listener.BackendID, false)
+	assert.NoError(t, err)
+	assert.Equal(t, core.ContractListenerStatusSyncing, status)
+	assert.Equal(t, int64(4), detail.(*ListenerStatus).Checkpoint.Block)
+
+	for catchup {
+		catchup, err = te.poll(ctx)
+		assert.NoError(t, err)
+	}
+	assert.Equal(t, uint64(11), te.subscriptions[listener.BackendID].Checkpoint)
+}
+
+func TestEthRPCBackfillContractListener(t *testing.T) {
+	te := newTestEthRPC(t, func() {
+		utRPCConfig.Set(RPCConfigMaxBlockRange, 2)
+	})
+	defer te.done()
+	ctx := context.Background()
+
+	ffi, _ := storageFFI(t)
+	listener := &core.ContractListener{
+		ID:        fftypes.NewUUID(),
+		Namespace: "ns1",
+		Filters: core.ListenerFilters{{
+			Event:    &core.FFISerializedEvent{FFIEventDefinition: ffi.Events[0].FFIEventDefinition},
+			Location: fftypes.JSONAnyPtr(fmt.Sprintf(`{"address":"%s"}`, testStorageAddress)),
+		}},
+		Options: &core.ContractListenerOptions{Confirmations: 1},
+	}
+	err := te.AddContractListener(ctx, listener, "")
+	assert.NoError(t, err)
+
+	nsOpID := "ns1:" + fftypes.NewUUID().String()
+	te.expectOpUpdate(nsOpID, core.OpStatusPending)
+	_, err = te.InvokeContract(ctx, nsOpID, te.key, fftypes.JSONAnyPtr(fmt.Sprintf(`{"address":"%s"}`, testStorageAddress)),
+		te.parseStorageMethod(t, "set"), map[string]interface{}{"x": 42}, nil, nil)
+	assert.NoError(t, err)
+	te.chain.mineBlocks(3)
+	checkpoint := te.subscriptions[listener.BackendID].Checkpoint
+
+	err = te.BackfillContractListener(ctx, listener, &core.ContractListenerBackfill{FromBlock: 0, ToBlock: 4}, nil, func(block uint64) {})
+	assert.Regexp(t, "FF10539.*3", err)
+
+	te.em.On("BlockchainEventBatch", mock.MatchedBy(func(events []*blockchain.EventToDispatch) bool {
+		return len(events) == 1 &&
+			events[0].ForListener.ListenerID == listener.BackendID &&
+			events[0].ForListener.Event.Output.GetString("value") == "42"
+	})).Return(nil).Once()
+	var progress []uint64
+	err = te.BackfillContractListener(ctx, listener, &core.ContractListenerBackfill{FromBlock: 0, ToBlock: 3}, nil, func(block uint64) {
+		progress = append(progress, block)
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []uint64{1, 3}, progress)
+	// The listener itself is unaffected
+	assert.Equal(t, checkpoint, te.subscriptions[listener.BackendID].Checkpoint)
+
+	// A backfill that already delivered the whole range has nothing left to do when it is resumed
+	currentBlock := uint64(3)
+	progress = nil
+	err = te.BackfillContractListener(ctx, listener, &core.ContractListenerBackfill{FromBlock: 0, ToBlock: 3, CurrentBlock: &currentBlock}, nil, func(block uint64) {
+		progress = append(progress, block)
+	})
+	assert.NoError(t, err)
+	assert.Empty(t, progress)
+}
+
+func TestEthRPCBackfillContractListenerFail(t *testing.T) {
+	te := newTestEthRPC(t)
+	defer te.done()
+	ctx := context.Background()
+
+	err := te.BackfillContractListener(ctx, &core.ContractListener{BackendID: "unknown"}, &core.ContractListenerBackfill{}, nil, func(block uint64) {})
+	assert.Regexp(t, "FF10536", err)
+
+	ffi, _ := storageFFI(t)
+	listener := &core.ContractListener{
+		ID:        fftypes.NewUUID(),
+		Namespace: "ns1",
+		Filters: core.ListenerFilters{{
+			Event: &core.FFISerializedEvent{FFIEventDefinition: ffi.Events[0].FFIEventDefinition},
+		}},
+	}
+	err = te.AddContractListener(ctx, listener, "")
+	assert.NoError(t, err)
+
+	te.chain.setError("eth_getLogs", &rpcbackend.RPCError{Code: -32000, Message: "pop"})
+	err = te.BackfillContractListener(ctx, listener, &core.ContractListenerBackfill{}, nil, func(block uint64) {})
+	assert.Regexp(t, "FF10532.*pop", err)
+
+	te.chain.setError("eth_blockNumber", &rpcbackend.RPCError{Code: -32000, Message: "pop"})
+	err = te.BackfillContractListener(ctx, listener, &core.ContractListenerBackfill{}, nil, func(block uint64) {})
+	assert.Regexp(t, "FF10532.*pop", err)
+}
+
+func TestEthRPCContractListenerHealth(t *testing.T) {
+	te := newTestEthRPC(t, func() {
+		utRPCConfig.Set(RPCConfigMaxBlockRange, 2)
+	})
+	defer te.done()
+	ctx := context.Background()
+
+	te.chain.mineBlocks(10)
+	ffi, _ := storageFFI(t)
+	listener := &core.ContractListener{
+		ID:        fftypes.NewUUID(),
+		Namespace: "ns1",
+		Filters: core.ListenerFilters{{
+			Event: &core.FFISerializedEvent{FFIEventDefinition: ffi.Events[0].FFIEventDefinition},
+		}},
+		Options: &core.ContractListenerOptions{
+			FirstEvent:    string(core.SubOptsFirstEventOldest),
+			Confirmations: 2,
+		},
+	}
+	err := te.AddContractListener(ctx, listener, "")
+	assert.NoError(t, err)
+
+	// Blocks 0 to 8 have enough confirmations, and none have been processed
+	health, err := te.GetContractListenerHealth(ctx, "ns1", listener.BackendID)
+	assert.NoError(t, err)
+	assert.Equal(t, core.ContractListenerStatusSyncing, health.Status)
+	assert.Nil(t, health.CheckpointBlock)
+	assert.Equal(t, uint64(10), *health.ChainHead)
+	assert.Equal(t, uint64(9), *health.LagBlocks)
+	assert.Equal(t, int64(16), *health.LagSeconds)
+
+	te.chain.setError("eth_getLogs", &rpcbackend.RPCError{Code: -32000, Message: "pop"})
+	_, err = te.poll(ctx)
+	assert.Regexp(t, "pop", err)
+	health, err = te.GetContractListenerHealth(ctx, "ns1", listener.BackendID)
+	assert.NoError(t, err)
+	assert.Regexp(t, "pop", health.LastError)
+
+	te.chain.setError("eth_getLogs", nil)
+	catchup, err := te.poll(ctx)
+	assert.NoError(t, err)
+	assert.True(t, catchup)
+	health, err = te.GetContractListenerHealth(ctx, "ns1", listener.BackendID)
+	assert.NoError(t, err)
+	assert.Equal(t, core.ContractListenerStatusSyncing, health.Status)
+	assert.Equal(t, uint64(1), *health.CheckpointBlock)
+	assert.Equal(t, uint64(7), *health.LagBlocks)
+	assert.Empty(t, health.LastError)
+
+	for catchup {
+		catchup, err = te.poll(ctx)
+		assert.NoError(t, err)
+	}
+	health, err = te.GetContractListenerHealth(ctx, "ns1", listener.BackendID)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(8), *health.CheckpointBlock)
+	assert.Equal(t, uint64(0), *health.LagBlocks)
+	assert.Equal(t, int64(0), *health.LagSeconds)
+
+	_, err = te.GetContractListenerHealth(ctx, "ns2", listener.BackendID)
+	assert.Regexp(t, "FF10536", err)
+
+	te.chain.setError("eth_getBlockByNumber", &rpcbackend.RPCError{Code: -32000, Message: "pop"})
+	te.chain.mineBlocks(1)
+	_, err = te.GetContractListenerHealth(ctx, "ns1", listener.BackendID)
+	assert.Regexp(t, "FF10532.*pop", err)
+
+	te.chain.setError("eth_blockNumber", &rpcbackend.RPCError{Code: -32000, Message: "pop"})
+	_, err = te.GetContractListenerHealth(ctx, "ns1", listener.BackendID)
+	assert.Regexp(t, "FF10532.*pop", err)
+}
+
+func TestEthRPCContractListenerBadFilters(t *testing.T) {
+	te := newTestEthRPC(t)
+	defer te.done()
+	ctx := context.Background()
+
+	err := te.AddContractListener(ctx, &core.ContractListener{Namespace: "ns1"}, "")
+	assert.Regexp(t, "FF10475", err)
+
+	ffi, _ := storageFFI(t)
+	err = te.AddContractListener(ctx, &core.ContractListener{
+		Namespace: "ns1",
+		Filters: core.ListenerFilters{{
+			Event:    &core.FFISerializedEvent{FFIEventDefinition: ffi.Events[0].FFIEventDefinition},
+			Location: fftypes.JSONAnyPtr(`{"address":"bad"}`),
+		}},
+	}, "")
+	assert.Regexp(t, "FF10141", err)
+}
+
+func TestEthRPCPollGetLogsFail(t *testing.T) {
+	te := newTestEthRPC(t)
+	defer te.done()
+	ctx := context.Background()
+
+	ffi, _ := storageFFI(t)
+	listener := &core.ContractListener{
+		ID:        fftypes.NewUUID(),
+		Namespace: "ns1",
+		Filters: core.ListenerFilters{{
+			Event: &core.FFISerializedEvent{FFIEventDefinition: ffi.Events[0].FFIEventDefinition},
+		}},
+		Options: &core.ContractListenerOptions{FirstEvent: string(core.SubOptsFirstEventOldest)},
+	}
+	err := te.AddContractListener(ctx, listener, "")
+	assert.NoError(t, err)
+
+	te.chain.setError("eth_getLogs", &rpcbackend.RPCError{Code: -32000, Message: "pop"})
+	_, err = te.poll(ctx)
+	assert.Regexp(t, "FF10532.*pop", err)
+	assert.Equal(t, uint64(0), te.subscriptions[listener.BackendID].Checkpoint)
+
+	te.chain.setError("eth_blockNumber", &rpcbackend.RPCError{Code: -32000, Message: "pop"})
+	_, err = te.poll(ctx)
+	assert.Regexp(t, "FF10532.*pop", err)
+}
+
+func TestEthRPCPollDispatchFail(t *testing.T) {
+	te := newTestEthRPC(t)
+	defer te.done()
+	ctx := context.Background()
+
+	ffi, _ := storageFFI(t)
+	listener := &core.ContractListener{
+		ID:        fftypes.NewUUID(),
+		Namespace: "ns1",
+		Filters: core.ListenerFilters{{
+			Event: &core.FFISerializedEvent{FFIEventDefinition: ffi.Events[0].FFIEventDefinition},
+		}},
+		Options: &core.ContractListenerOptions{FirstEvent: string(core.SubOptsFirstEventOldest)},
+	}
+	err := te.AddContractListener(ctx, listener, "")
+	assert.NoError(t, err)
+
+	nsOpID := "ns1:" + fftypes.NewUUID().String()
+	te.expectOpUpdate(nsOpID, core.OpStatusPending)
+	_, err = te.InvokeContract(ctx, nsOpID, te.key, fftypes.JSONAnyPtr(fmt.Sprintf(`{"address":"%s"}`, testStorageAddress)),
+		te.parseStorageMethod(t, "set"), map[string]interface{}{"x": 1}, nil, nil)
+	assert.NoError(t, err)
+
+	// The event is delivered again on the next cycle, after a failure
+	te.em.On("BlockchainEventBatch", mock.Anything).Return(fmt.Errorf("pop")).Once()
+	_, err = te.poll(ctx)
+	assert.Regexp(t, "pop", err)
+	assert.Equal(t, uint64(0), te.subscriptions[listener.BackendID].Checkpoint)
+
+	te.expectOpUpdate(nsOpID, core.OpStatusSucceeded)
+	te.em.On("BlockchainEventBatch", mock.Anything).Return(nil).Once()
+	_, err = te.poll(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(2), te.subscriptions[listener.BackendID].Checkpoint)
+}
+
+func TestEthRPCPollLoop(t *testing.T) {
+	te := newTestEthRPC(t)
+	defer te.done()
+	ctx := context.Background()
+
+	ffi, _ := storageFFI(t)
+	listener := &core.ContractListener{
+		ID:        fftypes.NewUUID(),
+		Namespace: "ns1",
+		Filters: core.ListenerFilters{{
+			Event: &core.FFISerializedEvent{FFIEventDefinition: ffi.Events[0].FFIEventDefinition},
+		}},
+	}
+	err := te.AddContractListener(ctx, listener, "")
+	assert.NoError(t, err)
+
+	te.em.On("BlockchainEventBatch", mock.Anything).Return(nil).Once()
+
+	// The receipt is checked after events are delivered in each cycle
+	delivered := make(chan struct{})
+	nsOpID := "ns1:" + fftypes.NewUUID().String()
+	te.expectOpUpdate(nsOpID, core.OpStatusPending)
+	te.expectOpUpdate(nsOpID, core.OpStatusSucceeded).Run(func(args mock.Arguments) {
+		close(delivered)
+	})
+	_, err = te.InvokeContract(ctx, nsOpID, te.key, fftypes.JSONAnyPtr(fmt.Sprintf(`{"address":"%s"}`, testStorageAddress)),
+		te.parseStorageMethod(t, "set"), map[string]interface{}{"x": 3}, nil, nil)
+	assert.NoError(t, err)
+
+	te.expectPendingOperations(0, nil)
+	err = te.StartNamespace(ctx, "ns1")
+	assert.NoError(t, err)
+	<-delivered
+
+	err = te.StopNamespace(ctx, "ns1")
+	assert.NoError(t, err)
+	assert.Empty(t, te.subscriptions)
+	te.cancelCtx()
+	<-te.pollerDone
+}
+
+func TestEthRPCQueryContract(t *testing.T) {
+	te := newTestEthRPC(t)
+	defer te.done()
+
+	result, err := te.QueryContract(context.Background(), te.key, fftypes.JSONAnyPtr(fmt.Sprintf(`{"address":"%s"}`, testStorageAddress)),
+		te.parseStorageMethod(t, "get"), map[string]interface{}{}, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"x": "7"}, result)
+}
+
+func TestEthRPCQueryContractBadOption(t *testing.T) {
+	te := newTestEthRPC(t)
+	defer te.done()
+
+	_, err := te.QueryContract(context.Background(), te.key, fftypes.JSONAnyPtr(fmt.Sprintf(`{"address":"%s"}`, testStorageAddress)),
+		te.parseStorageMethod(t, "get"), map[string]interface{}{}, map[string]interface{}{"privateFor": []string{}})
+	assert.Regexp(t, "FF10534.*privateFor", err)
+}
+
+func TestEthRPCInvokeContractRevert(t *testing.T) {
+	te := newTestEthRPC(t)
+	defer te.done()
+
+	rejected, err := te.InvokeContract(context.Background(), "ns1:"+fftypes.NewUUID().String(), te.key,
+		fftypes.JSONAnyPtr(fmt.Sprintf(`{"address":"%s"}`, testStorageAddress)),
+		te.parseStorageMethod(t, "set"), map[string]interface{}{"x": 0}, nil, nil)
+	assert.True(t, rejected)
+	assert.Regexp(t, `FF10533.*BadValue\("0"\)`, err)
+	assert.Equal(t, 0, te.chain.callCount("eth_sendRawTransaction"))
+}
+
+func TestEthRPCInvokeContractUnsupportedOption(t *testing.T) {
+	te := newTestEthRPC(t)
+	defer te.done()
+
+	rejected, err := te.InvokeContract(context.Background(), "ns1:"+fftypes.NewUUID().String(), te.key,
+		fftypes.JSONAnyPtr(fmt.Sprintf(`{"address":"%s"}`, testStorageAddress)),
+		te.parseStorageMethod(t, "set"), map[string]interface{}{"x": 1}, map[string]interface{}{"nonce": 12}, nil)
+	assert.True(t, rejected)
+	assert.Regexp(t, "FF10534.*nonce", err)
+}
+
+func TestEthRPCInvokeContractUnknownKey(t *testing.T) {
+	te := newTestEthRPC(t)
+	defer te.done()
+
+	rejected, err := te.InvokeContract(context.Background(), "ns1:"+fftypes.NewUUID().String(), "0x1111111111111111111111111111111111111111",
+		fftypes.JSONAnyPtr(fmt.Sprintf(`{"address":"%s"}`, testStorageAddress)),
+		te.parseStorageMethod(t, "set"), map[string]interface{}{"x": 1}, nil, nil)
+	assert.True(t, rejected)
+	assert.Error(t, err)
+	assert.Equal(t, 0, te.chain.callCount("eth_sendRawTransaction"))
+}
+
+func TestEthRPCInvokeContractSendFail(t *testing.T) {
+	te := newTestEthRPC(t)
+	defer te.done()
+
+	te.chain.setError("eth_sendRawTransaction", &rpcbackend.RPCError{Code: -32000, Message: "pop"})
+	rejected, err := te.InvokeContract(context.Background(), "ns1:"+fftypes.NewUUID().String(), te.key,
+		fftypes.JSONAnyPtr(fmt.Sprintf(`{"address":"%s"}`, testStorageAddress)),
+		te.parseStorageMethod(t, "set"), map[string]interface{}{"x": 1}, nil, nil)
+	assert.True(t, rejected)
+	assert.Regexp(t, "FF10532.*pop", err)
+	assert.Empty(t, te.nonces)
+}
+
+func TestEthRPCInvokeContractNonces(t *testing.T) {
+	te := newTestEthRPC(t, func() {
+		utRPCConfig.Set(RPCConfigChainID, 2024)
+	})
+	defer te.done()
+	ctx := context.Background()
+
+	te.chain.holdTxs = true
+	te.om.On("OperationUpdate", mock.Anything).Return()
+	for i := 1; i <= 3; i++ {
+		_, err := te.InvokeContract(ctx, "ns1:"+fftypes.NewUUID().String(), te.key,
+			fftypes.JSONAnyPtr(fmt.Sprintf(`{"address":"%s"}`, testStorageAddress)),
+			te.parseStorageMethod(t, "set"), map[string]interface{}{"x": i}, map[string]interface{}{"gasPrice": "0x0"}, nil)
+		assert.NoError(t, err)
+	}
+	assert.Equal(t, uint64(3), te.nonces[te.key])
+	assert.Len(t, te.pendingTxns, 3)
+	assert.Equal(t, 0, te.chain.callCount("eth_chainId"))
+	assert.Equal(t, 0, te.chain.callCount("eth_gasPrice"))
+
+	te.chain.minePending()
+	_, err := te.poll(ctx)
+	assert.NoError(t, err)
+	assert.Empty(t, te.pendingTxns)
+}
+
+func TestEthRPCFailedTransactionRevertReason(t *testing.T) {
+	te := newTestEthRPC(t)
+	defer te.done()
+	ctx := context.Background()
+
+	nsOpID := "ns1:" + fftypes.NewUUID().String()
+	te.expectOpUpdate(nsOpID, core.OpStatusPending)
+	// Setting the gas limit skips estimation, so the transaction is mined and fails
+	rejected, err := te.InvokeContract(ctx, nsOpID, te.key, fftypes.JSONAnyPtr(fmt.Sprintf(`{"address":"%s"}`, testStorageAddress)),
+		te.parseStorageMethod(t, "set"), map[string]interface{}{"x": 0}, map[string]interface{}{"gas": 100000}, nil)
+	assert.False(t, rejected)
+	assert.NoError(t, err)
+
+	te.expectOpUpdate(nsOpID, core.OpStatusFailed)
+	_, err = te.poll(ctx)
+	assert.NoError(t, err)
+	assert.Regexp(t, `FF10533.*BadValue\("0"\)`, te.lastOpUpdate().ErrorMessage)
+}
+
+func TestEthRPCDeployContract(t *testing.T) {
+	te := newTestEthRPC(t)
+	defer te.done()
+	ctx := context.Background()
+
+	te.chain.deploy = func(data []byte) simContract {
+		assert.Equal(t, []byte{0x60, 0x80}, data[0:2])
+		return newSimStorageContract(t, 5)
+	}
+
+	nsOpID := "ns1:" + fftypes.NewUUID().String()
+	te.expectOpUpdate(nsOpID, core.OpStatusPending)
+	te.expectOpUpdate(nsOpID, core.OpStatusSucceeded)
+	rejected, err := te.DeployContract(ctx, nsOpID, te.key, fftypes.JSONAnyPtr(simStorageABI), fftypes.JSONAnyPtr(`"0x6080"`), []interface{}{5}, nil)
+	assert.False(t, rejected)
+	assert.NoError(t, err)
+
+	_, err = te.poll(ctx)
+	assert.NoError(t, err)
+	var location Location
+	b, _ := json.Marshal(te.lastOpUpdate().Output.GetObject("contractLocation"))
+	err = json.Unmarshal(b, &location)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, location.Address)
+
+	result, err := te.QueryContract(ctx, te.key, fftypes.JSONAnyPtr(fmt.Sprintf(`{"address":"%s"}`, location.Address)),
+		te.parseStorageMethod(t, "get"), map[string]interface{}{}, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"x": "5"}, result)
+}
+
+func TestEthRPCDeployContractBadInput(t *testing.T) {
+	te := newTestEthRPC(t)
+	defer te.done()
+	ctx := context.Background()
+
+	rejected, err := te.DeployContract(ctx, "ns1:"+fftypes.NewUUID().String(), te.key, fftypes.JSONAnyPtr(`{}`), fftypes.JSONAnyPtr(`"0x6080"`), nil, nil)
+	assert.True(t, rejected)
+	assert.Regexp(t, "FF10535", err)
+
+	rejected, err = te.DeployContract(ctx, "ns1:"+fftypes.NewUUID().String(), te.key, fftypes.JSONAnyPtr(`[]`), fftypes.JSONAnyPtr(`"not hex"`), nil, nil)
+	assert.True(t, rejected)
+	assert.Regexp(t, "FF10535", err)
+
+	rejected, err = te.DeployContract(ctx, "ns1:"+fftypes.NewUUID().String(), te.key, fftypes.JSONAnyPtr(`[]`), fftypes.JSONAnyPtr(`"0x6080"`), []interface{}{1}, nil)
+	assert.True(t, rejected)
+	assert.Regexp(t, "FF10535", err)
+
+	rejected, err = te.DeployContract(ctx, "ns1:"+fftypes.NewUUID().String(), te.key, fftypes.JSONAnyPtr(simStorageABI), fftypes.JSONAnyPtr(`"0x6080"`), []interface{}{"bad"}, nil)
+	assert.True(t, rejected)
+	assert.Error(t, err)
+}
+
+func TestEthRPCGetTransactionStatus(t *testing.T) {
+	te := newTestEthRPC(t)
+	defer te.done()
+	ctx := context.Background()
+
+	te.chain.holdTxs = true
+	opID := fftypes.NewUUID()
+	nsOpID := "ns1:" + opID.String()
+	te.expectOpUpdate(nsOpID, core.OpStatusPending)
+	_, err := te.InvokeContract(ctx, nsOpID, te.key, fftypes.JSONAnyPtr(fmt.Sprintf(`{"address":"%s"}`, testStorageAddress)),
+		te.parseStorageMethod(t, "set"), map[string]interface{}{"x": 9}, nil, nil)
+	assert.NoError(t, err)
+	op := &core.Operation{
+		ID:        opID,
+		Namespace: "ns1",
+		Status:    core.OpStatusPending,
+		Output:    te.lastOpUpdate().Output,
+	}
+
+	status, err := te.GetTransactionStatus(ctx, op)
+	assert.NoError(t, err)
+	assert.Equal(t, "Pending", status.(fftypes.JSONObject).GetString("status"))
+
+	// The receipt is delivered by the status check, even if the poller has not seen it
+	te.chain.minePending()
+	te.expectOpUpdate(nsOpID, core.OpStatusSucceeded)
+	status, err = te.GetTransactionStatus(ctx, op)
+	assert.NoError(t, err)
+	assert.Equal(t, "0x1", status.(fftypes.JSONObject).GetString("status"))
+	assert.Empty(t, te.pendingTxns)
+
+	op.Status = core.OpStatusSucceeded
+	_, err = te.GetTransactionStatus(ctx, op)
+	assert.NoError(t, err)
+
+	status, err = te.GetTransactionStatus(ctx, &core.Operation{})
+	assert.NoError(t, err)
+	assert.Nil(t, status)
+
+	te.chain.setError("eth_getTransactionReceipt", &rpcbackend.RPCError{Code: -32000, Message: "pop"})
+	_, err = te.GetTransactionStatus(ctx, op)
+	assert.Regexp(t, "FF10532.*pop", err)
+}
+
+func TestEthRPCInvokeContractWithBatch(t *testing.T) {
+	te := newTestEthRPC(t)
+	defer te.done()
+	ctx := context.Background()
+
+	te.chain.contracts[testStorageAddress] = func(from string, data []byte, write bool) ([]byte, []*simLog, *simRevert) {
+		return []byte{}, nil, nil
+	}
+	parsedMethod, err := te.ParseInterface(ctx, testFFIPinMethod(), testFFIErrors())
+	assert.NoError(t, err)
+	batch := &blockchain.BatchPin{
+		TransactionID:   fftypes.NewUUID(),
+		BatchID:         fftypes.NewUUID(),
+		BatchHash:       fftypes.NewRandB32(),
+		BatchPayloadRef: "test-payload",
+		Contexts:        []*fftypes.Bytes32{},
+	}
+	nsOpID := "ns1:" + fftypes.NewUUID().String()
+	te.expectOpUpdate(nsOpID, core.OpStatusPending)
+	_, err = te.InvokeContract(ctx, nsOpID, te.key, fftypes.JSONAnyPtr(fmt.Sprintf(`{"address":"%s"}`, testStorageAddress)),
+		parsedMethod, nil, nil, batch)
+	assert.NoError(t, err)
+
+	parsedMethod, err = te.ParseInterface(ctx, testFFIMethod(), testFFIErrors())
+	assert.NoError(t, err)
+	rejected, err := te.InvokeContract(ctx, nsOpID, te.key, fftypes.JSONAnyPtr(fmt.Sprintf(`{"address":"%s"}`, testStorageAddress)),
+		parsedMethod, map[string]interface{}{"x": 1, "y": 2}, nil, batch)
+	assert.True(t, rejected)
+	assert.Regexp(t, "FF10443", err)
+}
+
+func TestEthRPCInvokeContractBadRequest(t *testing.T) {
+	te := newTestEthRPC(t)
+	defer te.done()
+	ctx := context.Background()
+
+	rejected, err := te.InvokeContract(ctx, "ns1:"+fftypes.NewUUID().String(), te.key, fftypes.JSONAnyPtr(`{}`),
+		te.parseStorageMethod(t, "set"), map[string]interface{}{"x": 1}, nil, nil)
+	assert.True(t, rejected)
+	assert.Regexp(t, "FF10310", err)
+
+	rejected, err = te.InvokeContract(ctx, "ns1:"+fftypes.NewUUID().String(), te.key, fftypes.JSONAnyPtr(fmt.Sprintf(`{"address":"%s"}`, testStorageAddress)),
+		"wrong", map[string]interface{}{"x": 1}, nil, nil)
+	assert.True(t, rejected)
+	assert.Regexp(t, "FF10457", err)
+
+	rejected, err = te.InvokeContract(ctx, "ns1:"+fftypes.NewUUID().String(), te.key, fftypes.JSONAnyPtr(fmt.Sprintf(`{"address":"%s"}`, testStorageAddress)),
+		te.parseStorageMethod(t, "set"), map[string]interface{}{"x": "not a number"}, nil, nil)
+	assert.True(t, rejected)
+	assert.Error(t, err)
+
+	_, err = te.QueryContract(ctx, te.key, fftypes.JSONAnyPtr(`{}`), te.parseStorageMethod(t, "get"), map[string]interface{}{}, nil)
+	assert.Regexp(t, "FF10310", err)
+
+	_, err = te.QueryContract(ctx, te.key, fftypes.JSONAnyPtr(fmt.Sprintf(`{"address":"%s"}`, testStorageAddress)), "wrong", map[string]interface{}{}, nil)
+	assert.Regexp(t, "FF10457", err)
+}
+
+func TestEthRPCSubmitBatchPinBadLocation(t *testing.T) {
+	te := newTestEthRPC(t)
+	defer te.done()
+	ctx := context.Background()
+
+	err := te.SubmitBatchPin(ctx, "ns1:"+fftypes.NewUUID().String(), "ns1", te.key, &blockchain.BatchPin{}, fftypes.JSONAnyPtr(`{}`))
+	assert.Regexp(t, "FF10310", err)
+	err = te.SubmitNetworkAction(ctx, "ns1:"+fftypes.NewUUID().String(), te.key, core.NetworkActionTerminate, fftypes.JSONAnyPtr(`{}`))
+	assert.Regexp(t, "FF10310", err)
+	_, err = te.AddFireflySubscription(ctx, &core.Namespace{Name: "ns1"}, &blockchain.MultipartyContract{Location: fftypes.JSONAnyPtr(`{}`)}, "")
+	assert.Regexp(t, "FF10310", err)
+
+	te.chain.setError("eth_call", &rpcbackend.RPCError{Code: -32000, Message: "pop"})
+	location := fftypes.JSONAnyPtr(fmt.Sprintf(`{"address":"%s"}`, testFireFlyAddress))
+	err = te.SubmitBatchPin(ctx, "ns1:"+fftypes.NewUUID().String(), "ns1", te.key, &blockchain.BatchPin{}, location)
+	assert.Regexp(t, "FF10532", err)
+	err = te.SubmitNetworkAction(ctx, "ns1:"+fftypes.NewUUID().String(), te.key, core.NetworkActionTerminate, location)
+	assert.Regexp(t, "FF10532", err)
+	_, err = te.AddFireflySubscription(ctx, &core.Namespace{Name: "ns1"}, &blockchain.MultipartyContract{Location: location}, "")
+	assert.Regexp(t, "FF10532", err)
+}
+
+func TestEthRPCNetworkActionV1(t *testing.T) {
+	te := newTestEthRPC(t)
+	defer te.done()
+
+	v1 := newSimFireFlyContract(t, 1, 1700000000)
+	te.chain.contracts[testFireFlyAddress] = func(from string, data []byte, write bool) ([]byte, []*simLog, *simRevert) {
+		if bytes.HasPrefix(data, simSelector(batchPinMethodABIV1)) {
+			return []byte{}, nil, nil
+		}
+		return v1(from, data, write)
+	}
+	nsOpID := "ns1:" + fftypes.NewUUID().String()
+	te.expectOpUpdate(nsOpID, core.OpStatusPending)
+	err := te.SubmitNetworkAction(context.Background(), nsOpID, te.key, core.NetworkActionTerminate,
+		fftypes.JSONAnyPtr(fmt.Sprintf(`{"address":"%s"}`, testFireFlyAddress)))
+	assert.NoError(t, err)
+}
+
+func TestEthRPCSubmitRPCFailures(t *testing.T) {
+	for _, method := range []string{"eth_chainId", "eth_estimateGas", "eth_gasPrice", "eth_getTransactionCount"} {
+		t.Run(method, func(t *testing.T) {
+			te := newTestEthRPC(t)
+			defer te.done()
+			te.chain.setError(method, &rpcbackend.RPCError{Code: -32000, Message: "pop"})
+			rejected, err := te.InvokeContract(context.Background(), "ns1:"+fftypes.NewUUID().String(), te.key,
+				fftypes.JSONAnyPtr(fmt.Sprintf(`{"address":"%s"}`, testStorageAddress)),
+				te.parseStorageMethod(t, "set"), map[string]interface{}{"x": 1}, nil, nil)
+			assert.True(t, rejected)
+			assert.Regexp(t, "FF10532.*pop", err)
+		})
+	}
+}
+
+func TestEthRPCWrapCallError(t *testing.T) {
+	te := newTestEthRPC(t)
+	defer te.done()
+	ctx := context.Background()
+
+	err := te.wrapCallError(ctx, &rpcbackend.RPCError{Code: 3, Message: "execution reverted", Data: *fftypes.JSONAnyPtr(`"0x12345678"`)}, nil)
+	assert.Regexp(t, "FF10533.*0x12345678", err)
+	err = te.wrapCallError(ctx, &rpcbackend.RPCError{Code: -32000, Message: "VM Exception: revert"}, nil)
+	assert.Regexp(t, "FF10533.*VM Exception", err)
+	err = te.wrapCallError(ctx, &rpcbackend.RPCError{Code: -32000, Message: "pop"}, nil)
+	assert.Regexp(t, "FF10532.*pop", err)
+}
+
+func TestEthRPCRevertReasonUnavailable(t *testing.T) {
+	te := newTestEthRPC(t)
+	defer te.done()
+
+	err := te.getRevertReason(context.Background(), &pendingTxn{}, &rpcReceipt{})
+	assert.Regexp(t, "FF10533.*no revert reason", err)
+}
+
+func TestEthRPCCheckReceiptsFail(t *testing.T) {
+	te := newTestEthRPC(t)
+	defer te.done()
+	ctx := context.Background()
+
+	te.pendingTxns["0x1234"] = &pendingTxn{nsOpID: "ns1:" + fftypes.NewUUID().String(), hash: "0x1234"}
+	te.chain.setError("eth_getTransactionReceipt", &rpcbackend.RPCError{Code: -32000, Message: "pop"})
+	te.checkReceipts(ctx)
+	assert.Len(t, te.pendingTxns, 1)
+
+	// A receipt that cannot be handled stays tracked, so it is retried on the next poll
+	te.chain.setError("eth_getTransactionReceipt", nil)
+	te.chain.receipts["0x1234"] = &rpcReceipt{TransactionHash: "0x1234", Status: ethtypes.NewHexIntegerU64(1)}
+	te.pendingTxns["0x1234"].nsOpID = ""
+	te.checkReceipts(ctx)
+	assert.Len(t, te.pendingTxns, 1)
+	assert.Equal(t, 2, te.chain.callCount("eth_getTransactionReceipt"))
+
+	nsOpID := "ns1:" + fftypes.NewUUID().String()
+	te.pendingTxns["0x1234"].nsOpID = nsOpID
+	te.expectOpUpdate(nsOpID, core.OpStatusSucceeded)
+	te.checkReceipts(ctx)
+	assert.Empty(t, te.pendingTxns)
+}
+
+func TestEthRPCStartNamespaceTracksPendingOperations(t *testing.T) {
+	te := newTestEthRPC(t)
+	defer te.done()
+	ctx := context.Background()
+
+	te.chain.holdTxs = true
+	ops := make([]*core.Operation, 2)
+	for i := range ops {
+		ops[i] = &core.Operation{ID: fftypes.NewUUID(), Namespace: "ns1", Status: core.OpStatusPending}
+		nsOpID := "ns1:" + ops[i].ID.String()
+		te.expectOpUpdate(nsOpID, core.OpStatusPending).Run(func(args mock.Arguments) {
+			ops[i].Output = args[0].(*core.OperationUpdate).Output
+		})
+		_, err := te.InvokeContract(ctx, nsOpID, te.key, fftypes.JSONAnyPtr(fmt.Sprintf(`{"address":"%s"}`, testStorageAddress)),
+			te.parseStorageMethod(t, "set"), map[string]interface{}{"x": i + 1}, nil, nil)
+		assert.NoError(t, err)
+	}
+	// The operations are read a page at a time, and those without a known transaction are skipped
+	firstPage := append([]*core.Operation{}, ops...)
+	for len(firstPage) < pendingOperationsPageSize {
+		firstPage = append(firstPage, &core.Operation{ID: fftypes.NewUUID(), Namespace: "ns1"})
+	}
+	te.expectPendingOperations(0, firstPage)
+	te.expectPendingOperations(pendingOperationsPageSize, []*core.Operation{
+		{ID: fftypes.NewUUID(), Namespace: "ns1", Output: fftypes.JSONObject{"transactionHash": "0x1234"}},
+	})
+
+	// Nothing is held in memory across a restart
+	te.pendingTxns = make(map[string]*pendingTxn)
+	te.nonces = make(map[string]uint64)
+	// A poller that has already stopped is not restarted, so the test drives the poll cycle itself
+	te.pollerDone = make(chan struct{})
+	close(te.pollerDone)
+	err := te.StartNamespace(ctx, "ns1")
+	assert.NoError(t, err)
+	assert.Len(t, te.pendingTxns, 2)
+	assert.Equal(t, uint64(2), te.nonces[te.key])
+	txHash := ops[0].Output.GetString("transactionHash")
+	assert.Equal(t, "ns1:"+ops[0].ID.String(), te.pendingTxns[txHash].nsOpID)
+	assert.Equal(t, uint64(0), te.pendingTxns[txHash].tx.Nonce.Uint64())
+
+	// The receipts are delivered once the transactions are mined
+	te.chain.minePending()
+	te.expectOpUpdate("ns1:"+ops[0].ID.String(), core.OpStatusSucceeded)
+	te.expectOpUpdate("ns1:"+ops[1].ID.String(), core.OpStatusSucceeded)
+	_, err = te.poll(ctx)
+	assert.NoError(t, err)
+	assert.Empty(t, te.pendingTxns)
+}
+
+func TestEthRPCStartNamespaceTrackPendingOperationsFail(t *testing.T) {
+	te := newTestEthRPC(t)
+	defer te.done()
+
+	te.chain.setError("eth_getTransactionByHash", &rpcbackend.RPCError{Code: -32000, Message: "pop"})
+	te.expectPendingOperations(0, []*core.Operation{
+		{ID: fftypes.NewUUID(), Namespace: "ns1", Output: fftypes.JSONObject{"transactionHash": "0x1234"}},
+	})
+	err := te.StartNamespace(context.Background(), "ns1")
+	assert.Regexp(t, "FF10532.*pop", err)
+	assert.Nil(t, te.pollerDone)
+	assert.Empty(t, te.pendingTxns)
+}
+
+func TestEthRPCStartNamespaceGetPendingOperationsFail(t *testing.T) {
+	te := newTestEthRPC(t)
+	defer te.done()
+
+	te.om.On("GetPendingOperations", mock.Anything, "ethrpc", transactionOpTypes, uint64(0), uint64(pendingOperationsPageSize)).Return(nil, fmt.Errorf("pop"))
+	err := te.StartNamespace(context.Background(), "ns1")
+	assert.Regexp(t, "pop", err)
+	assert.Nil(t, te.pollerDone)
+}
+
+func TestEthRPCStartNamespaceNoOperationQueries(t *testing.T) {
+	te := newTestEthRPC(t)
+	defer te.done()
+
+	// A handler that cannot query operations leaves nothing to recover
+	te.SetOperationHandler("ns1", &coremocks.OperationCallbacks{})
+	te.pollerDone = make(chan struct{})
+	close(te.pollerDone)
+	err := te.StartNamespace(context.Background(), "ns1")
+	assert.NoError(t, err)
+	assert.Empty(t, te.pendingTxns)
+}
+
+func TestEthRPCPollBlockTimestampFail(t *testing.T) {
+	te := newTestEthRPC(t)
+	defer te.done()
+	ctx := context.Background()
+
+	ffi, _ := storageFFI(t)
+	err := te.AddContractListener(ctx, &core.ContractListener{
+		ID:        fftypes.NewUUID(),
+		Namespace: "ns1",
+		Filters: core.ListenerFilters{{
+			Event: &core.FFISerializedEvent{FFIEventDefinition: ffi.Events[0].FFIEventDefinition},
+		}},
+		Options: &core.ContractListenerOptions{FirstEvent: string(core.SubOptsFirstEventOldest)},
+	}, "")
+	assert.NoError(t, err)
+
+	nsOpID := "ns1:" + fftypes.NewUUID().String()
+	te.expectOpUpdate(nsOpID, core.OpStatusPending)
+	_, err = te.InvokeContract(ctx, nsOpID, te.key, fftypes.JSONAnyPtr(fmt.Sprintf(`{"address":"%s"}`, testStorageAddress)),
+		te.parseStorageMethod(t, "set"), map[string]interface{}{"x": 1}, nil, nil)
+	assert.NoError(t, err)
+
+	te.chain.setError("eth_getBlockByNumber", &rpcbackend.RPCError{Code: -32000, Message: "pop"})
+	_, err = te.poll(ctx)
+	assert.Regexp(t, "FF10532.*pop", err)
+
+	_, err = te.getBlockTimestamp(ctx, 100, map[uint64]*fftypes.FFTime{})
+	assert.Regexp(t, "FF10532", err)
+	te.chain.setError("eth_getBlockByNumber", nil)
+	_, err = te.getBlockTimestamp(ctx, 100, map[uint64]*fftypes.FFTime{})
+	assert.Regexp(t, "FF10532.*block 100 not found", err)
+}
+
+func TestEthRPCProcessLogMismatch(t *testing.T) {
+	te := newTestEthRPC(t)
+	defer te.done()
+	ctx := context.Background()
+
+	sub := &rpcSubscription{
+		ID:        fftypes.NewUUID().String(),
+		Namespace: "ns1",
+		Filters:   []*rpcFilter{{Address: testFireFlyAddress, Event: batchPinEventABI}},
+	}
+	events := make(common.EventsToDispatch)
+	timestamps := make(map[uint64]*fftypes.FFTime)
+
+	// No topics
+	err := te.processLog(ctx, events, sub, &rpcLog{Address: testFireFlyAddress}, timestamps)
+	assert.NoError(t, err)
+	// Other contract
+	err = te.processLog(ctx, events, sub, &rpcLog{Address: testStorageAddress, Topics: []ethtypes.HexBytes0xPrefix{batchPinEventABI.SignatureHashBytes()}}, timestamps)
+	assert.NoError(t, err)
+	// Undecodable
+	err = te.processLog(ctx, events, sub, &rpcLog{Address: testFireFlyAddress, Topics: []ethtypes.HexBytes0xPrefix{batchPinEventABI.SignatureHashBytes()}}, timestamps)
+	assert.NoError(t, err)
+	assert.Empty(t, events)
+
+	// A BatchPin for a subscription that has been removed
+	data, err := batchPinEventABI.Inputs.EncodeABIDataValuesCtx(ctx, []interface{}{
+		te.key, 0, "", ethHexFormatB32(nil), ethHexFormatB32(nil), "", []string{},
+	})
+	assert.NoError(t, err)
+	sub.FireFlyContract = true
+	timestamps[1] = fftypes.Now()
+	err = te.processLog(ctx, events, sub, &rpcLog{
+		Address:     testFireFlyAddress,
+		Topics:      []ethtypes.HexBytes0xPrefix{batchPinEventABI.SignatureHashBytes()},
+		Data:        data,
+		BlockNumber: 1,
+	}, timestamps)
+	assert.NoError(t, err)
+	assert.Empty(t, events)
+}
+
+func TestEthRPCAddContractListenerFail(t *testing.T) {
+	te := newTestEthRPC(t)
+	defer te.done()
+	ctx := context.Background()
+
+	ffi, _ := storageFFI(t)
+	listener := &core.ContractListener{
+		ID:        fftypes.NewUUID(),
+		Namespace: "ns1",
+		Filters: core.ListenerFilters{{
+			Event: &core.FFISerializedEvent{FFIEventDefinition: ffi.Events[0].FFIEventDefinition},
+		}},
+	}
+	err := te.AddContractListener(ctx, listener, "bad")
+	assert.Regexp(t, "FF10472", err)
+
+	te.chain.setError("eth_blockNumber", &rpcbackend.RPCError{Code: -32000, Message: "pop"})
+	err = te.AddContractListener(ctx, listener, "")
+	assert.Regexp(t, "FF10532.*pop", err)
+
+	listener.Filters[0].Location = fftypes.JSONAnyPtr(`{}`)
+	err = te.AddContractListener(ctx, listener, "")
+	assert.Regexp(t, "FF10310", err)
+
+	listener.Filters[0].Event.Params = fftypes.FFIParams{{Name: "bad", Schema: fftypes.JSONAnyPtr(`{"type":"wrong"}`)}}
+	err = te.AddContractListener(ctx, listener, "")
+	assert.Regexp(t, "FF10311", err)
+}
@@ -0,0 +1,360 @@
+// Copyright © 2024 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ethereum
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/hyperledger/firefly-common/pkg/fftypes"
+	"github.com/hyperledger/firefly-common/pkg/i18n"
+	"github.com/hyperledger/firefly-common/pkg/log"
+	"github.com/hyperledger/firefly-signer/pkg/abi"
+	"github.com/hyperledger/firefly-signer/pkg/ethsigner"
+	"github.com/hyperledger/firefly-signer/pkg/ethtypes"
+	"github.com/hyperledger/firefly-signer/pkg/rpcbackend"
+	"github.com/hyperledger/firefly/internal/blockchain/common"
+	"github.com/hyperledger/firefly/internal/coremsgs"
+	"github.com/hyperledger/firefly/pkg/core"
+)
+
+const (
+	// ReceiptTransactionUpdate reports that a transaction has been submitted, but is not yet mined
+	ReceiptTransactionUpdate string = "TransactionUpdate"
+	// pendingOperationsPageSize is the number of pending operations read at a time when resuming after a restart
+	pendingOperationsPageSize = 25
+)
+
+// The types of operation that submit a transaction through this plugin
+var transactionOpTypes = []core.OpType{
+	core.OpTypeBlockchainPinBatch,
+	core.OpTypeBlockchainNetworkAction,
+	core.OpTypeBlockchainContractDeploy,
+	core.OpTypeBlockchainInvoke,
+}
+
+type txRequest struct {
+	nsOpID  string
+	from    string
+	to      *ethtypes.Address0xHex // nil to deploy a contract
+	data    ethtypes.HexBytes0xPrefix
+	errors  []*abi.Entry
+	options map[string]interface{}
+}
+
+type pendingTxn struct {
+	nsOpID string
+	hash   string
+	tx     *ethsigner.Transaction
+	errors []*abi.Entry
+}
+
+type rpcReceipt struct {
+	TransactionHash   string                 `json:"transactionHash"`
+	BlockNumber       ethtypes.HexUint64     `json:"blockNumber"`
+	BlockHash         string                 `json:"blockHash"`
+	TransactionIndex  ethtypes.HexUint64     `json:"transactionIndex"`
+	From              string                 `json:"from"`
+	To                *ethtypes.Address0xHex `json:"to"`
+	ContractAddress   *ethtypes.Address0xHex `json:"contractAddress"`
+	GasUsed           *ethtypes.HexInteger   `json:"gasUsed"`
+	EffectiveGasPrice *ethtypes.HexInteger   `json:"effectiveGasPrice,omitempty"`
+	Status            *ethtypes.HexInteger   `json:"status"`
+}
+
+// rpcTransaction is the subset of the eth_getTransactionByHash result needed to resume tracking a transaction
+type rpcTransaction struct {
+	Hash  string                    `json:"hash"`
+	From  *ethtypes.Address0xHex    `json:"from"`
+	To    *ethtypes.Address0xHex    `json:"to"`
+	Nonce ethtypes.HexUint64        `json:"nonce"`
+	Gas   *ethtypes.HexInteger      `json:"gas"`
+	Value *ethtypes.HexInteger      `json:"value"`
+	Input ethtypes.HexBytes0xPrefix `json:"input"`
+}
+
+// The transaction fields that can be set through the options of an invoke or deploy request
+var supportedTxOptions = map[string]bool{
+	"gas":                  true,
+	"gasPrice":             true,
+	"maxFeePerGas":         true,
+	"maxPriorityFeePerGas": true,
+	"value":                true,
+}
+
+func applyTxOptions(ctx context.Context, tx *ethsigner.Transaction, options map[string]interface{}) error {
+	if len(options) == 0 {
+		return nil
+	}
+	for k := range options {
+		if !supportedTxOptions[k] {
+			return i18n.NewError(ctx, coremsgs.MsgEthRPCUnsupportedOption, k)
+		}
+	}
+	b, _ := json.Marshal(options)
+	if err := json.Unmarshal(b, tx); err != nil {
+		return i18n.WrapError(ctx, err, coremsgs.MsgEthRPCUnsupportedOption, string(b))
+	}
+	return nil
+}
+
+// submitTransaction signs a transaction with the key for the "from" address, and sends it to the node.
+// Nothing has been submitted if an error is returned, so failures always reject the submission.
+func (r *EthRPC) submitTransaction(ctx context.Context, req *txRequest) (submissionRejected bool, err error) {
+	from, err := ethtypes.NewAddress(req.from)
+	if err != nil {
+		return true, i18n.NewError(ctx, coremsgs.MsgInvalidEthAddress)
+	}
+	tx := &ethsigner.Transaction{
+		From: json.RawMessage(fmt.Sprintf(`"%s"`, from)),
+		To:   req.to,
+		Data: req.data,
+	}
+	if err := applyTxOptions(ctx, tx, req.options); err != nil {
+		return true, err
+	}
+
+	chainID, err := r.getChainID(ctx)
+	if err != nil {
+		return true, err
+	}
+	if tx.GasLimit == nil {
+		var gas ethtypes.HexInteger
+		if rpcErr := r.rpc.CallRPC(ctx, &gas, "eth_estimateGas", tx); rpcErr != nil {
+			return true, r.wrapCallError(ctx, rpcErr, req.errors)
+		}
+		limit, _ := new(big.Float).Mul(new(big.Float).SetInt(gas.BigInt()), big.NewFloat(r.gasEstimationFactor)).Int(nil)
+		tx.GasLimit = ethtypes.NewHexInteger(limit)
+	}
+	if tx.GasPrice == nil && tx.MaxFeePerGas == nil && tx.MaxPriorityFeePerGas == nil {
+		var gasPrice ethtypes.HexInteger
+		if rpcErr := r.rpc.CallRPC(ctx, &gasPrice, "eth_gasPrice"); rpcErr != nil {
+			return true, i18n.WrapError(ctx, rpcErr.Error(), coremsgs.MsgEthRPCErr, rpcErr.Message)
+		}
+		tx.GasPrice = &gasPrice
+	}
+
+	// Nonces are allocated and consumed one transaction at a time
+	r.txMux.Lock()
+	defer r.txMux.Unlock()
+	nonce, err := r.nextNonce(ctx, from.String())
+	if err != nil {
+		return true, err
+	}
+	tx.Nonce = ethtypes.NewHexIntegerU64(nonce)
+	signed, err := r.wallet.Sign(ctx, tx, chainID)
+	if err != nil {
+		return true, err
+	}
+	var txHash ethtypes.HexBytes0xPrefix
+	if rpcErr := r.rpc.CallRPC(ctx, &txHash, "eth_sendRawTransaction", ethtypes.HexBytes0xPrefix(signed)); rpcErr != nil {
+		return true, i18n.WrapError(ctx, rpcErr.Error(), coremsgs.MsgEthRPCErr, rpcErr.Message)
+	}
+	r.nonces[from.String()] = nonce + 1
+	log.L(ctx).Infof("Submitted transaction %s for operation %s (from=%s nonce=%d)", txHash, req.nsOpID, from, nonce)
+
+	txn := &pendingTxn{
+		nsOpID: req.nsOpID,
+		hash:   txHash.String(),
+		tx:     tx,
+		errors: req.errors,
+	}
+	r.mux.Lock()
+	r.pendingTxns[txn.hash] = txn
+	r.mux.Unlock()
+
+	// Record the transaction hash against the operation straight away, so the receipt can be found after a restart
+	err = common.HandleReceipt(ctx, "", r, &common.BlockchainReceiptNotification{
+		Headers: common.BlockchainReceiptHeaders{
+			ReceiptID: req.nsOpID,
+			ReplyType: ReceiptTransactionUpdate,
+		},
+		TxHash: txn.hash,
+	}, r.callbacks)
+	return false, err
+}
+
+func (r *EthRPC) getChainID(ctx context.Context) (int64, error) {
+	if r.chainID < 0 {
+		var chainID ethtypes.HexInteger
+		if rpcErr := r.rpc.CallRPC(ctx, &chainID, "eth_chainId"); rpcErr != nil {
+			return -1, i18n.WrapError(ctx, rpcErr.Error(), coremsgs.MsgEthRPCErr, rpcErr.Message)
+		}
+		r.chainID = chainID.Int64()
+	}
+	return r.chainID, nil
+}
+
+// nextNonce must be called while holding txMux. The node's pending transaction count is used, unless
+// transactions submitted by this plugin have not yet reached its pending pool.
+func (r *EthRPC) nextNonce(ctx context.Context, from string) (uint64, error) {
+	var count ethtypes.HexUint64
+	if rpcErr := r.rpc.CallRPC(ctx, &count, "eth_getTransactionCount", from, "pending"); rpcErr != nil {
+		return 0, i18n.WrapError(ctx, rpcErr.Error(), coremsgs.MsgEthRPCErr, rpcErr.Message)
+	}
+	nonce := count.Uint64()
+	if local, ok := r.nonces[from]; ok && local > nonce {
+		nonce = local
+	}
+	return nonce, nil
+}
+
+// wrapCallError returns the error for a failed eth_call or eth_estimateGas. When the node supplies
+// revert data, it is decoded against the errors in the ABI.
+func (r *EthRPC) wrapCallError(ctx context.Context, rpcErr *rpcbackend.RPCError, errors []*abi.Entry) error {
+	var revertData ethtypes.HexBytes0xPrefix
+	if err := json.Unmarshal(rpcErr.Data.Bytes(), &revertData); err == nil && len(revertData) > 0 {
+		if errString, ok := abi.ABI(errors).ErrorStringCtx(ctx, revertData); ok {
+			return i18n.NewError(ctx, coremsgs.MsgEthRPCReverted, errString)
+		}
+		return i18n.NewError(ctx, coremsgs.MsgEthRPCReverted, revertData)
+	}
+	if strings.Contains(strings.ToLower(rpcErr.Message), "revert") {
+		return i18n.NewError(ctx, coremsgs.MsgEthRPCReverted, rpcErr.Message)
+	}
+	return i18n.WrapError(ctx, rpcErr.Error(), coremsgs.MsgEthRPCErr, rpcErr.Message)
+}
+
+func (r *EthRPC) checkReceipts(ctx context.Context) {
+	r.mux.Lock()
+	txns := make([]*pendingTxn, 0, len(r.pendingTxns))
+	for _, txn := range r.pendingTxns {
+		txns = append(txns, txn)
+	}
+	r.mux.Unlock()
+
+	for _, txn := range txns {
+		var receipt *rpcReceipt
+		if rpcErr := r.rpc.CallRPC(ctx, &receipt, "eth_getTransactionReceipt", txn.hash); rpcErr != nil {
+			log.L(ctx).Warnf("Failed to get receipt for transaction %s: %s", txn.hash, rpcErr.Message)
+			continue
+		}
+		if receipt == nil {
+			continue
+		}
+		// The transaction remains tracked until its receipt has been handled, so it is retried on the next poll
+		if err := r.handleTxReceipt(ctx, txn, receipt); err != nil {
+			log.L(ctx).Warnf("Failed to handle receipt for transaction %s: %s", txn.hash, err)
+			continue
+		}
+		r.mux.Lock()
+		delete(r.pendingTxns, txn.hash)
+		r.mux.Unlock()
+	}
+}
+
+// recoverPendingOperations pages through the operations of a namespace that were submitted through this plugin
+// and are still pending, to resume tracking their transactions
+func (r *EthRPC) recoverPendingOperations(ctx context.Context, namespace string) error {
+	for skip := uint64(0); ; skip += pendingOperationsPageSize {
+		pendingOps, err := r.callbacks.GetPendingOperations(ctx, namespace, r, transactionOpTypes, skip, pendingOperationsPageSize)
+		if err != nil {
+			return err
+		}
+		if err := r.trackPendingOperations(ctx, pendingOps); err != nil {
+			return err
+		}
+		if len(pendingOps) < pendingOperationsPageSize {
+			return nil
+		}
+	}
+}
+
+// trackPendingOperations resumes tracking of the transactions that were submitted before a restart,
+// so their receipts are delivered and the nonces they consumed are not allocated again.
+func (r *EthRPC) trackPendingOperations(ctx context.Context, pendingOps []*core.Operation) error {
+	for _, op := range pendingOps {
+		txHash := op.Output.GetString("transactionHash")
+		if txHash == "" {
+			continue
+		}
+		var tx *rpcTransaction
+		if rpcErr := r.rpc.CallRPC(ctx, &tx, "eth_getTransactionByHash", txHash); rpcErr != nil {
+			return i18n.WrapError(ctx, rpcErr.Error(), coremsgs.MsgEthRPCErr, rpcErr.Message)
+		}
+		if tx == nil || tx.From == nil {
+			log.L(ctx).Warnf("Transaction %s for operation %s is not known to the node", txHash, op.ID)
+			continue
+		}
+
+		from := tx.From.String()
+		r.txMux.Lock()
+		if r.nonces[from] < tx.Nonce.Uint64()+1 {
+			r.nonces[from] = tx.Nonce.Uint64() + 1
+		}
+		r.txMux.Unlock()
+
+		r.mux.Lock()
+		if _, ok := r.pendingTxns[txHash]; !ok {
+			r.pendingTxns[txHash] = &pendingTxn{
+				nsOpID: (&core.PreparedOperation{ID: op.ID, Namespace: op.Namespace}).NamespacedIDString(),
+				hash:   txHash,
+				tx: &ethsigner.Transaction{
+					From:     json.RawMessage(fmt.Sprintf(`"%s"`, from)),
+					To:       tx.To,
+					Nonce:    ethtypes.NewHexIntegerU64(tx.Nonce.Uint64()),
+					GasLimit: tx.Gas,
+					Value:    tx.Value,
+					Data:     tx.Input,
+				},
+			}
+			log.L(ctx).Infof("Tracking transaction %s for pending operation %s (from=%s nonce=%d)", txHash, op.ID, from, tx.Nonce.Uint64())
+		}
+		r.mux.Unlock()
+	}
+	return nil
+}
+
+func (r *EthRPC) handleTxReceipt(ctx context.Context, txn *pendingTxn, receipt *rpcReceipt) error {
+	reply := &common.BlockchainReceiptNotification{
+		Headers: common.BlockchainReceiptHeaders{
+			ReceiptID: txn.nsOpID,
+			ReplyType: ReceiptTransactionSuccess,
+		},
+		TxHash:     txn.hash,
+		ProtocolID: fmt.Sprintf("%.12d/%.6d", receipt.BlockNumber, receipt.TransactionIndex),
+	}
+	if receipt.Status == nil || receipt.Status.BigInt().Sign() == 0 {
+		reply.Headers.ReplyType = ReceiptTransactionFailed
+		reply.Message = r.getRevertReason(ctx, txn, receipt).Error()
+	} else if receipt.ContractAddress != nil {
+		reply.ContractLocation = fftypes.JSONAnyPtr(fmt.Sprintf(`{"address":"%s"}`, receipt.ContractAddress))
+	}
+	return common.HandleReceipt(ctx, "", r, reply, r.callbacks)
+}
+
+// getRevertReason replays a failed transaction with eth_call against the block it was mined in,
+// to recover the revert data that is not included in the receipt.
+func (r *EthRPC) getRevertReason(ctx context.Context, txn *pendingTxn, receipt *rpcReceipt) error {
+	if txn.tx != nil {
+		call := &ethsigner.Transaction{
+			From:     txn.tx.From,
+			To:       txn.tx.To,
+			Data:     txn.tx.Data,
+			Value:    txn.tx.Value,
+			GasLimit: txn.tx.GasLimit,
+		}
+		var result ethtypes.HexBytes0xPrefix
+		if rpcErr := r.rpc.CallRPC(ctx, &result, "eth_call", call, receipt.BlockNumber); rpcErr != nil {
+			return r.wrapCallError(ctx, rpcErr, txn.errors)
+		}
+	}
+	return i18n.NewError(ctx, coremsgs.MsgEthRPCReverted, "no revert reason available")
+}
@@ -60,6 +60,7 @@ type subscription struct {
 	EthCompatAddress string     `json:"address,omitempty"`
 	EthCompatEvent   *abi.Entry `json:"event,omitempty"`
 	Filters          []*filter  `json:"filters"`
+	Confirmations    int        `json:"confirmations,omitempty"`
 	subscriptionCheckpoint
 }
 
@@ -68,9 +69,10 @@ type filter struct {
 	Address string     `json:"address,omitempty"`
 }
 
+// subscriptionCheckpoint is only reported by evmconnect - ethconnect does not return a checkpoint
 type subscriptionCheckpoint struct {
-	Checkpoint ListenerCheckpoint `json:"checkpoint,omitempty"`
-	Catchup    bool               `json:"catchup,omitempty"`
+	Checkpoint *ListenerCheckpoint `json:"checkpoint,omitempty"`
+	Catchup    bool                `json:"catchup,omitempty"`
 }
 
 func newStreamManager(client *resty.Client, cache cache.CInterface, batchSize, batchTimeout uint) *streamManager {
@@ -248,7 +250,7 @@ func resolveFromBlock(ctx context.Context, firstEvent, lastProtocolID string) (s
 	return strconv.FormatUint(blockNumber, 10), nil
 }
 
-func (s *streamManager) createSubscription(ctx context.Context, stream, subName, firstEvent string, location *Location, abi *abi.Entry, filters []*filter, lastProtocolID string) (*subscription, error) {
+func (s *streamManager) createSubscription(ctx context.Context, stream, subName, firstEvent string, confirmations int, location *Location, abi *abi.Entry, filters []*filter, lastProtocolID string) (*subscription, error) {
 	fromBlock, err := resolveFromBlock(ctx, firstEvent, lastProtocolID)
 	if err != nil {
 		return nil, err
@@ -260,6 +262,7 @@ func (s *streamManager) createSubscription(ctx context.Context, stream, subName,
 		FromBlock:      fromBlock,
 		EthCompatEvent: abi, // only used for ethconnect
 		Filters:        filters,
+		Confirmations:  confirmations, // the connector default applies when not set
 	}
 
 	if location != nil {
@@ -338,7 +341,7 @@ func (s *streamManager) ensureFireFlySubscription(ctx context.Context, namespace
 			Address: location.Address,
 		},
 	}
-	if sub, err = s.createSubscription(ctx, stream, name, firstEvent, location, abi, filters, lastProtocolID); err != nil {
+	if sub, err = s.createSubscription(ctx, stream, name, firstEvent, 0, location, abi, filters, lastProtocolID); err != nil {
 		return nil, err
 	}
 	log.L(ctx).Infof("%s subscription: %s", abi.Name, sub.ID)
@@ -58,6 +58,12 @@ type subscription struct {
 	Stream    string      `json:"stream"`
 	FromBlock string      `json:"fromBlock"`
 	Filter    eventFilter `json:"filter"`
+	// Checkpoint is only reported by versions of fabconnect that track the progress of each subscription
+	Checkpoint *subscriptionCheckpoint `json:"checkpoint,omitempty"`
+}
+
+type subscriptionCheckpoint struct {
+	Block uint64 `json:"block"`
 }
 
 type eventFilter struct {
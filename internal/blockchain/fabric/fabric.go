@@ -37,6 +37,7 @@ import (
 	"github.com/hyperledger/firefly/internal/coreconfig"
 	"github.com/hyperledger/firefly/internal/coremsgs"
 	"github.com/hyperledger/firefly/internal/metrics"
+	"github.com/hyperledger/firefly/internal/tracing"
 	"github.com/hyperledger/firefly/pkg/blockchain"
 	"github.com/hyperledger/firefly/pkg/core"
 )
@@ -111,6 +112,14 @@ type fabIdentity struct {
 	CACert string `json:"caCert"`
 }
 
+type chainInfoResponse struct {
+	Result chainInfo `json:"result"`
+}
+
+type chainInfo struct {
+	Height fftypes.FFuint64 `json:"height"`
+}
+
 type Location struct {
 	Channel   string `json:"channel"`
 	Chaincode string `json:"chaincode"`
@@ -211,6 +220,7 @@ func (f *Fabric) Init(ctx context.Context, cancelCtx context.CancelFunc, conf co
 	if err != nil {
 		return err
 	}
+	tracing.InstrumentClient(f.client)
 
 	f.defaultChannel = fabconnectConf.GetString(FabconnectConfigDefaultChannel)
 	// the org identity is guaranteed to be configured by the core
@@ -1007,6 +1017,49 @@ func (f *Fabric) GetContractListenerStatus(ctx context.Context, namespace, subID
 	return true, nil, core.ContractListenerStatusUnknown, err
 }
 
+func (f *Fabric) GetContractListenerHealth(ctx context.Context, namespace, subID string) (*core.ContractListenerHealth, error) {
+	sub, err := f.streams.getSubscription(ctx, subID, false)
+	if err != nil {
+		return nil, err
+	}
+	health := &core.ContractListenerHealth{Status: core.ContractListenerStatusUnknown}
+	if sub.Checkpoint != nil && sub.Checkpoint.Block > 0 {
+		block := sub.Checkpoint.Block
+		health.CheckpointBlock = &block
+	}
+	head, err := f.getChainHead(ctx, sub.Channel, sub.Signer)
+	if err != nil {
+		return nil, err
+	}
+	common.SetListenerLag(health, head, 0)
+	return health, nil
+}
+
+// getChainHead returns the number of the newest block on a channel, which is one less than the height of the chain
+func (f *Fabric) getChainHead(ctx context.Context, channel, signer string) (uint64, error) {
+	var resErr common.BlockchainRESTError
+	var info chainInfoResponse
+	res, err := f.client.R().
+		SetContext(ctx).
+		SetQueryParam("fly-channel", channel).
+		SetQueryParam("fly-signer", signer).
+		SetError(&resErr).
+		SetResult(&info).
+		Get("/chaininfo")
+	if err != nil || !res.IsSuccess() {
+		return 0, common.WrapRESTError(ctx, &resErr, res, err, coremsgs.MsgFabconnectRESTErr)
+	}
+	if info.Result.Height == 0 {
+		return 0, nil
+	}
+	return uint64(info.Result.Height) - 1, nil
+}
+
+func (f *Fabric) BackfillContractListener(ctx context.Context, listener *core.ContractListener, backfill *core.ContractListenerBackfill, started func(backendID string) error, progress func(block uint64)) error {
+	// Fabconnect subscriptions cannot be bounded to a range of blocks
+	return i18n.NewError(ctx, coremsgs.MsgNotSupportedByBlockchainPlugin)
+}
+
 func (f *Fabric) GetFFIParamValidator(ctx context.Context) (fftypes.FFIParamValidator, error) {
 	// Fabconnect does not require any additional validation beyond "JSON Schema correctness" at this time
 	return nil, nil
@@ -1034,6 +1087,11 @@ func (f *Fabric) GenerateErrorSignature(ctx context.Context, event *fftypes.FFIE
 	return ""
 }
 
+// Chaincode reports errors as strings, so custom errors are matched by name
+func (f *Fabric) DecodeContractError(ctx context.Context, errors []*fftypes.FFIError, errorMessage string) *core.ContractError {
+	return common.DecodeNamedError(errors, errorMessage)
+}
+
 func (f *Fabric) GetNetworkVersion(ctx context.Context, location *fftypes.JSONAny) (version int, err error) {
 	fabricOnChainLocation, err := parseContractLocation(ctx, location)
 	if err != nil {
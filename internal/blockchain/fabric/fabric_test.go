@@ -231,6 +231,20 @@ func TestGenerateErrorSignatureNoOp(t *testing.T) {
 	assert.Empty(t, e.GenerateErrorSignature(context.Background(), &fftypes.FFIErrorDefinition{}))
 }
 
+func TestDecodeContractError(t *testing.T) {
+	e, cancel := newTestFabric()
+	defer cancel()
+	resetConf(e)
+
+	errors := []*fftypes.FFIError{
+		{FFIErrorDefinition: fftypes.FFIErrorDefinition{Name: "AssetNotFound"}},
+	}
+	contractError := e.DecodeContractError(context.Background(), errors, `Failed to submit: chaincode response 500, AssetNotFound: {"id":"asset1"}`)
+	assert.Equal(t, "AssetNotFound", contractError.Name)
+	assert.Equal(t, "asset1", contractError.Params.GetString("id"))
+	assert.Nil(t, e.DecodeContractError(context.Background(), errors, "pop"))
+}
+
 func TestInitMissingTopic(t *testing.T) {
 	e, cancel := newTestFabric()
 	defer cancel()
@@ -3416,6 +3430,77 @@ func TestGetContractListenerStatusError(t *testing.T) {
 	assert.Error(t, err)
 }
 
+func TestGetContractListenerHealth(t *testing.T) {
+	e, cancel := newTestFabric()
+	defer cancel()
+	httpmock.ActivateNonDefault(e.client.GetClient())
+	defer httpmock.DeactivateAndReset()
+	e.streams = newTestStreamManager(e.client, "signer")
+
+	httpmock.RegisterResponder("GET", "http://localhost:12345/subscriptions/sub1",
+		httpmock.NewJsonResponderOrPanic(200, subscription{
+			ID: "sub1", Channel: "firefly", Signer: "signer001",
+			Checkpoint: &subscriptionCheckpoint{Block: 40},
+		}))
+	httpmock.RegisterResponder("GET", "http://localhost:12345/subscriptions/sub2",
+		httpmock.NewStringResponder(500, "pop"))
+	httpmock.RegisterResponder("GET", "http://localhost:12345/subscriptions/sub3",
+		httpmock.NewJsonResponderOrPanic(200, subscription{ID: "sub3", Channel: "empty", Signer: "signer001"}))
+	httpmock.RegisterResponder("GET", "http://localhost:12345/chaininfo",
+		func(req *http.Request) (*http.Response, error) {
+			assert.Equal(t, "signer001", req.URL.Query().Get("fly-signer"))
+			height := 50
+			if req.URL.Query().Get("fly-channel") == "empty" {
+				height = 0
+			}
+			return httpmock.NewJsonResponderOrPanic(200, fftypes.JSONObject{
+				"result": fftypes.JSONObject{"height": height},
+			})(req)
+		})
+
+	// The newest block is one less than the height
+	health, err := e.GetContractListenerHealth(context.Background(), "ns1", "sub1")
+	assert.NoError(t, err)
+	assert.Equal(t, core.ContractListenerStatusUnknown, health.Status)
+	assert.Equal(t, uint64(40), *health.CheckpointBlock)
+	assert.Equal(t, uint64(49), *health.ChainHead)
+	assert.Equal(t, uint64(9), *health.LagBlocks)
+
+	_, err = e.GetContractListenerHealth(context.Background(), "ns1", "sub2")
+	assert.Regexp(t, "FF10284.*pop", err)
+
+	// Without a checkpoint, the lag is not known
+	health, err = e.GetContractListenerHealth(context.Background(), "ns1", "sub3")
+	assert.NoError(t, err)
+	assert.Nil(t, health.CheckpointBlock)
+	assert.Equal(t, uint64(0), *health.ChainHead)
+	assert.Nil(t, health.LagBlocks)
+}
+
+func TestGetContractListenerHealthChainInfoFail(t *testing.T) {
+	e, cancel := newTestFabric()
+	defer cancel()
+	httpmock.ActivateNonDefault(e.client.GetClient())
+	defer httpmock.DeactivateAndReset()
+	e.streams = newTestStreamManager(e.client, "signer")
+
+	httpmock.RegisterResponder("GET", "http://localhost:12345/subscriptions/sub1",
+		httpmock.NewJsonResponderOrPanic(200, subscription{ID: "sub1"}))
+	httpmock.RegisterResponder("GET", "http://localhost:12345/chaininfo",
+		httpmock.NewJsonResponderOrPanic(500, fftypes.JSONObject{"error": "pop"}))
+
+	_, err := e.GetContractListenerHealth(context.Background(), "ns1", "sub1")
+	assert.Regexp(t, "FF10284.*pop", err)
+}
+
+func TestBackfillContractListenerNotSupported(t *testing.T) {
+	e, cancel := newTestFabric()
+	defer cancel()
+
+	err := e.BackfillContractListener(context.Background(), &core.ContractListener{}, &core.ContractListenerBackfill{FromBlock: 1, ToBlock: 10}, nil, func(block uint64) {})
+	assert.Regexp(t, "FF10429", err)
+}
+
 func TestGetTransactionStatus(t *testing.T) {
 	e, cancel := newTestFabric()
 	defer cancel()
@@ -36,6 +36,7 @@ import (
 	"github.com/hyperledger/firefly/internal/coreconfig"
 	"github.com/hyperledger/firefly/internal/coremsgs"
 	"github.com/hyperledger/firefly/internal/metrics"
+	"github.com/hyperledger/firefly/internal/tracing"
 	"github.com/hyperledger/firefly/pkg/blockchain"
 	"github.com/hyperledger/firefly/pkg/core"
 )
@@ -175,6 +176,7 @@ func (t *Tezos) Init(ctx context.Context, cancelCtx context.CancelFunc, conf con
 	if err != nil {
 		return err
 	}
+	tracing.InstrumentClient(t.client)
 
 	t.pluginTopic = tezosconnectConf.GetString(TezosconnectConfigTopic)
 	if t.pluginTopic == "" {
@@ -506,6 +508,31 @@ func (t *Tezos) GetContractListenerStatus(ctx context.Context, namespace, subID
 	return true, checkpoint, status, nil
 }
 
+func (t *Tezos) GetContractListenerHealth(ctx context.Context, namespace, subID string) (*core.ContractListenerHealth, error) {
+	found, detail, status, err := t.GetContractListenerStatus(ctx, namespace, subID, false)
+	if err != nil {
+		return nil, err
+	}
+	health := &core.ContractListenerHealth{Status: status}
+	if listenerStatus, ok := detail.(*ListenerStatus); found && ok && listenerStatus.Checkpoint.Block > 0 {
+		block := uint64(listenerStatus.Checkpoint.Block)
+		health.CheckpointBlock = &block
+	}
+	head, err := common.GetConnectorChainHead(ctx, t.client, coremsgs.MsgTezosconnectRESTErr)
+	if err != nil {
+		return nil, err
+	}
+	if head != nil {
+		common.SetListenerLag(health, *head, 0)
+	}
+	return health, nil
+}
+
+func (t *Tezos) BackfillContractListener(ctx context.Context, listener *core.ContractListener, backfill *core.ContractListenerBackfill, started func(backendID string) error, progress func(block uint64)) error {
+	// Tezosconnect listeners cannot be bounded to a range of blocks
+	return i18n.NewError(ctx, coremsgs.MsgNotSupportedByBlockchainPlugin)
+}
+
 func (t *Tezos) GetFFIParamValidator(ctx context.Context) (fftypes.FFIParamValidator, error) {
 	// Tezosconnect does not require any additional validation beyond "JSON Schema correctness" at this time
 	return nil, nil
@@ -536,6 +563,11 @@ func (t *Tezos) GenerateErrorSignature(ctx context.Context, event *fftypes.FFIEr
 	return ""
 }
 
+// Contracts report errors with FAILWITH, so custom errors are matched by the name of the failure
+func (t *Tezos) DecodeContractError(ctx context.Context, errors []*fftypes.FFIError, errorMessage string) *core.ContractError {
+	return common.DecodeNamedError(errors, errorMessage)
+}
+
 func (t *Tezos) GenerateFFI(ctx context.Context, generationRequest *fftypes.FFIGenerationRequest) (*fftypes.FFI, error) {
 	return nil, i18n.NewError(ctx, coremsgs.MsgFFIGenerationUnsupported)
 }
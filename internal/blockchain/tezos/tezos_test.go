@@ -1948,6 +1948,80 @@ func TestGetContractListenerStatusGetSubNotFound(t *testing.T) {
 	assert.False(t, found)
 }
 
+func TestGetContractListenerHealth(t *testing.T) {
+	tz, cancel := newTestTezos()
+	defer cancel()
+	httpmock.ActivateNonDefault(tz.client.GetClient())
+	defer httpmock.DeactivateAndReset()
+	tz.streams = newTestStreamManager(tz.client)
+
+	httpmock.RegisterResponder("GET", "http://localhost:12345/subscriptions/sub1",
+		httpmock.NewJsonResponderOrPanic(200, subscription{
+			ID: "sub1", subscriptionCheckpoint: subscriptionCheckpoint{
+				Catchup:    true,
+				Checkpoint: ListenerCheckpoint{Block: 1000},
+			},
+		}))
+	httpmock.RegisterResponder("GET", "http://localhost:12345/subscriptions/sub2",
+		httpmock.NewStringResponder(500, "pop"))
+	httpmock.RegisterResponder("GET", "http://localhost:12345/status",
+		httpmock.NewJsonResponderOrPanic(200, fftypes.JSONObject{
+			"chainHead": fftypes.JSONObject{"blockNumber": 1010},
+		}))
+
+	health, err := tz.GetContractListenerHealth(context.Background(), "ns1", "sub1")
+	assert.NoError(t, err)
+	assert.Equal(t, core.ContractListenerStatusSyncing, health.Status)
+	assert.Equal(t, uint64(1000), *health.CheckpointBlock)
+	assert.Equal(t, uint64(1010), *health.ChainHead)
+	assert.Equal(t, uint64(10), *health.LagBlocks)
+
+	_, err = tz.GetContractListenerHealth(context.Background(), "ns1", "sub2")
+	assert.Regexp(t, "pop", err)
+}
+
+func TestGetContractListenerHealthNoChainHead(t *testing.T) {
+	tz, cancel := newTestTezos()
+	defer cancel()
+	httpmock.ActivateNonDefault(tz.client.GetClient())
+	defer httpmock.DeactivateAndReset()
+	tz.streams = newTestStreamManager(tz.client)
+
+	httpmock.RegisterResponder("GET", "http://localhost:12345/subscriptions/sub1",
+		httpmock.NewJsonResponderOrPanic(200, subscription{ID: "sub1"}))
+	httpmock.RegisterResponder("GET", "http://localhost:12345/status",
+		httpmock.NewJsonResponderOrPanic(200, fftypes.JSONObject{}))
+
+	health, err := tz.GetContractListenerHealth(context.Background(), "ns1", "sub1")
+	assert.NoError(t, err)
+	assert.Nil(t, health.ChainHead)
+	assert.Nil(t, health.LagBlocks)
+}
+
+func TestGetContractListenerHealthStatusFail(t *testing.T) {
+	tz, cancel := newTestTezos()
+	defer cancel()
+	httpmock.ActivateNonDefault(tz.client.GetClient())
+	defer httpmock.DeactivateAndReset()
+	tz.streams = newTestStreamManager(tz.client)
+
+	httpmock.RegisterResponder("GET", "http://localhost:12345/subscriptions/sub1",
+		httpmock.NewJsonResponderOrPanic(200, subscription{ID: "sub1"}))
+	httpmock.RegisterResponder("GET", "http://localhost:12345/status",
+		httpmock.NewStringResponder(500, "pop"))
+
+	_, err := tz.GetContractListenerHealth(context.Background(), "ns1", "sub1")
+	assert.Regexp(t, "FF10283.*pop", err)
+}
+
+func TestBackfillContractListenerNotSupported(t *testing.T) {
+	tz, cancel := newTestTezos()
+	defer cancel()
+
+	err := tz.BackfillContractListener(context.Background(), &core.ContractListener{}, &core.ContractListenerBackfill{FromBlock: 1, ToBlock: 10}, nil, func(block uint64) {})
+	assert.Regexp(t, "FF10429", err)
+}
+
 func TestGetTransactionStatusSuccess(t *testing.T) {
 	tz, cancel := newTestTezos()
 	defer cancel()
@@ -2083,6 +2157,18 @@ func TestGenerateErrorSignature(t *testing.T) {
 	assert.Equal(t, res, "")
 }
 
+func TestDecodeContractError(t *testing.T) {
+	tz, cancel := newTestTezos()
+	defer cancel()
+
+	errors := []*fftypes.FFIError{
+		{FFIErrorDefinition: fftypes.FFIErrorDefinition{Name: "FA2_INSUFFICIENT_BALANCE"}},
+	}
+	contractError := tz.DecodeContractError(context.Background(), errors, `script_rejected: failed with {"string":"FA2_INSUFFICIENT_BALANCE"}`)
+	assert.Equal(t, "FA2_INSUFFICIENT_BALANCE", contractError.Name)
+	assert.Nil(t, tz.DecodeContractError(context.Background(), errors, "pop"))
+}
+
 func TestSubmitNetworkAction(t *testing.T) {
 	tz, cancel := newTestTezos()
 	defer cancel()
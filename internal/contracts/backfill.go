@@ -0,0 +1,200 @@
+// Copyright © 2024 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package contracts
+
+import (
+	"context"
+	"time"
+
+	"github.com/hyperledger/firefly-common/pkg/fftypes"
+	"github.com/hyperledger/firefly-common/pkg/i18n"
+	"github.com/hyperledger/firefly-common/pkg/log"
+	"github.com/hyperledger/firefly/internal/coremsgs"
+	"github.com/hyperledger/firefly/pkg/core"
+	"github.com/hyperledger/firefly/pkg/database"
+)
+
+// defaultBackfillRetention is how long a backfill that has finished is still reported for its listener
+const defaultBackfillRetention = 1 * time.Hour
+
+// BackfillContractListener records a backfill job in the database and starts it in the background. The job records
+// any temporary subscription it creates, and its progress, so that it can be resumed after a restart and so that
+// the events of the temporary subscription can be attributed to the listener.
+func (cm *contractManager) BackfillContractListener(ctx context.Context, nameOrID string, req *core.ContractListenerBackfillRequest) (*core.ContractListenerBackfill, error) {
+	if req.FromBlock > req.ToBlock {
+		return nil, i18n.NewError(ctx, coremsgs.MsgBackfillBlockRangeInvalid, req.FromBlock, req.ToBlock)
+	}
+	listener, err := cm.GetContractListenerByNameOrID(ctx, nameOrID)
+	if err != nil {
+		return nil, err
+	}
+
+	cm.backfillMux.Lock()
+	defer cm.backfillMux.Unlock()
+	fb := database.ContractListenerBackfillQueryFactory.NewFilter(ctx)
+	running, _, err := cm.database.GetContractListenerBackfills(ctx, cm.namespace, fb.And(
+		fb.Eq("listener", listener.ID),
+		fb.Eq("status", core.ContractListenerBackfillStatusRunning),
+	).Limit(1))
+	if err != nil {
+		return nil, err
+	}
+	if len(running) > 0 {
+		return nil, i18n.NewError(ctx, coremsgs.MsgBackfillInProgress, running[0].ID)
+	}
+
+	now := fftypes.Now()
+	backfill := &core.ContractListenerBackfill{
+		ID:        fftypes.NewUUID(),
+		Namespace: cm.namespace,
+		Listener:  listener.ID,
+		FromBlock: req.FromBlock,
+		ToBlock:   req.ToBlock,
+		Status:    core.ContractListenerBackfillStatusRunning,
+		Created:   now,
+		Updated:   now,
+	}
+	if err := cm.database.InsertContractListenerBackfill(ctx, backfill); err != nil {
+		return nil, err
+	}
+	result := *backfill
+	cm.startBackfill(listener, backfill)
+	return &result, nil
+}
+
+// startBackfill must be called with backfillMux held
+func (cm *contractManager) startBackfill(listener *core.ContractListener, backfill *core.ContractListenerBackfill) {
+	done := make(chan struct{})
+	cm.backfills[*backfill.ID] = done
+	go cm.runBackfill(listener, backfill, done)
+}
+
+func (cm *contractManager) runBackfill(listener *core.ContractListener, bf *core.ContractListenerBackfill, done chan struct{}) {
+	defer close(done)
+	ctx := log.WithLogField(cm.ctx, "backfill", bf.ID.String())
+	log.L(ctx).Infof("Backfilling listener %s from block %d to %d", listener.ID, bf.FromBlock, bf.ToBlock)
+
+	err := cm.blockchain.BackfillContractListener(ctx, listener, bf, func(backendID string) error {
+		update := database.ContractListenerBackfillQueryFactory.NewUpdate(ctx).
+			Set("backendid", backendID).
+			Set("updated", fftypes.Now())
+		if err := cm.database.UpdateContractListenerBackfill(ctx, cm.namespace, bf.ID, update); err != nil {
+			return err
+		}
+		bf.BackendID = backendID
+		return nil
+	}, func(block uint64) {
+		if bf.CurrentBlock != nil && *bf.CurrentBlock >= block {
+			return
+		}
+		update := database.ContractListenerBackfillQueryFactory.NewUpdate(ctx).
+			Set("currentblock", block).
+			Set("updated", fftypes.Now())
+		if err := cm.database.UpdateContractListenerBackfill(ctx, cm.namespace, bf.ID, update); err != nil {
+			// The backfill is simply resumed from an earlier block after a restart
+			log.L(ctx).Warnf("Failed to record progress of backfill to block %d: %s", block, err)
+			return
+		}
+		bf.CurrentBlock = &block
+	})
+
+	if ctx.Err() != nil {
+		// The namespace is stopping - the backfill is left running, and resumed when the namespace next starts
+		log.L(ctx).Infof("Backfill of listener %s interrupted", listener.ID)
+		return
+	}
+
+	update := database.ContractListenerBackfillQueryFactory.NewUpdate(ctx).S()
+	if err != nil {
+		log.L(ctx).Errorf("Backfill of listener %s failed: %s", listener.ID, err)
+		update.Set("status", core.ContractListenerBackfillStatusFailed).Set("error", err.Error())
+	} else {
+		log.L(ctx).Infof("Backfill of listener %s complete", listener.ID)
+		update.Set("status", core.ContractListenerBackfillStatusSucceeded)
+	}
+	if err := cm.database.UpdateContractListenerBackfill(ctx, cm.namespace, bf.ID, update.Set("updated", fftypes.Now())); err != nil {
+		log.L(ctx).Errorf("Failed to record the status of backfill: %s", err)
+		return
+	}
+	time.AfterFunc(cm.backfillRetention, func() { cm.removeBackfill(ctx, bf.ID) })
+}
+
+// resumeBackfills restarts the backfills that were running when the namespace last stopped,
+// and schedules the removal of those that have finished
+func (cm *contractManager) resumeBackfills() {
+	ctx := log.WithLogField(cm.ctx, "role", "backfill-resume")
+	fb := database.ContractListenerBackfillQueryFactory.NewFilter(ctx)
+	backfills, _, err := cm.database.GetContractListenerBackfills(ctx, cm.namespace, fb.And().Sort("created"))
+	if err != nil {
+		log.L(ctx).Errorf("Failed to query backfills to resume: %s", err)
+		return
+	}
+
+	cm.backfillMux.Lock()
+	defer cm.backfillMux.Unlock()
+	for _, bf := range backfills {
+		if _, active := cm.backfills[*bf.ID]; active {
+			continue
+		}
+		if bf.Status != core.ContractListenerBackfillStatusRunning {
+			remaining := time.Until(time.Time(*bf.Updated).Add(cm.backfillRetention))
+			id := bf.ID
+			time.AfterFunc(remaining, func() { cm.removeBackfill(ctx, id) })
+			continue
+		}
+		listener, err := cm.database.GetContractListenerByID(ctx, cm.namespace, bf.Listener)
+		if err != nil {
+			log.L(ctx).Errorf("Failed to query listener %s to resume backfill %s: %s", bf.Listener, bf.ID, err)
+			continue
+		}
+		if listener == nil {
+			log.L(ctx).Warnf("Removing backfill %s of deleted listener %s", bf.ID, bf.Listener)
+			if err := cm.database.DeleteContractListenerBackfill(ctx, cm.namespace, bf.ID); err != nil {
+				log.L(ctx).Errorf("Failed to remove backfill %s: %s", bf.ID, err)
+			}
+			continue
+		}
+		log.L(ctx).Infof("Resuming backfill %s of listener %s", bf.ID, listener.ID)
+		cm.startBackfill(listener, bf)
+	}
+}
+
+func (cm *contractManager) GetContractListenerBackfills(ctx context.Context, nameOrID string) ([]*core.ContractListenerBackfill, error) {
+	listener, err := cm.GetContractListenerByNameOrID(ctx, nameOrID)
+	if err != nil {
+		return nil, err
+	}
+
+	fb := database.ContractListenerBackfillQueryFactory.NewFilter(ctx)
+	backfills, _, err := cm.database.GetContractListenerBackfills(ctx, cm.namespace, fb.And(
+		fb.Eq("listener", listener.ID),
+	).Sort("created"))
+	return backfills, err
+}
+
+func (cm *contractManager) removeBackfill(ctx context.Context, id *fftypes.UUID) {
+	if cm.ctx.Err() != nil {
+		// The namespace has stopped - the backfill is removed when it next starts
+		return
+	}
+	if err := cm.database.DeleteContractListenerBackfill(ctx, cm.namespace, id); err != nil {
+		log.L(ctx).Errorf("Failed to remove backfill %s: %s", id, err)
+	}
+	cm.backfillMux.Lock()
+	defer cm.backfillMux.Unlock()
+	delete(cm.backfills, *id)
+}
@@ -0,0 +1,418 @@
+// Copyright © 2024 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package contracts
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/firefly-common/pkg/ffapi"
+	"github.com/hyperledger/firefly-common/pkg/fftypes"
+	"github.com/hyperledger/firefly/mocks/blockchainmocks"
+	"github.com/hyperledger/firefly/mocks/databasemocks"
+	"github.com/hyperledger/firefly/pkg/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func waitBackfill(cm *contractManager, id *fftypes.UUID) {
+	cm.backfillMux.Lock()
+	done := cm.backfills[*id]
+	cm.backfillMux.Unlock()
+	<-done
+}
+
+// backfillUpdate matches an update of a backfill by its fields other than the updated time
+func backfillUpdate(expected string) interface{} {
+	return mock.MatchedBy(func(update ffapi.Update) bool {
+		info, _ := update.Finalize()
+		return strings.HasPrefix(info.String(), expected+", updated=")
+	})
+}
+
+func TestBackfillContractListener(t *testing.T) {
+	cm := newTestContractManager()
+	mbi := cm.blockchain.(*blockchainmocks.Plugin)
+	mdi := cm.database.(*databasemocks.Plugin)
+
+	listener := &core.ContractListener{
+		ID:        fftypes.NewUUID(),
+		Namespace: "ns1",
+	}
+	mdi.On("GetContractListener", context.Background(), "ns1", "sub1").Return(listener, nil)
+	mdi.On("GetContractListenerBackfills", context.Background(), "ns1", mock.MatchedBy(func(filter ffapi.Filter) bool {
+		info, _ := filter.Finalize()
+		return info.String() == fmt.Sprintf("( listener == '%s' ) && ( status == 'running' ) limit=1", listener.ID)
+	})).Return([]*core.ContractListenerBackfill{}, nil, nil).Once()
+	var inserted *core.ContractListenerBackfill
+	mdi.On("InsertContractListenerBackfill", context.Background(), mock.MatchedBy(func(bf *core.ContractListenerBackfill) bool {
+		inserted = bf
+		return bf.FromBlock == 100 && bf.ToBlock == 200 && bf.Status == core.ContractListenerBackfillStatusRunning
+	})).Return(nil)
+	mdi.On("UpdateContractListenerBackfill", mock.Anything, "ns1", mock.Anything, backfillUpdate("backendid='backfill1'")).Return(nil).Once()
+	mdi.On("UpdateContractListenerBackfill", mock.Anything, "ns1", mock.Anything, backfillUpdate("currentblock=150")).Return(nil).Once()
+	mdi.On("UpdateContractListenerBackfill", mock.Anything, "ns1", mock.Anything, backfillUpdate("status='succeeded'")).Return(nil).Once()
+
+	release := make(chan struct{})
+	mbi.On("BackfillContractListener", mock.Anything, listener, mock.Anything, mock.Anything, mock.Anything).
+		Run(func(args mock.Arguments) {
+			err := args[3].(func(backendID string) error)("backfill1")
+			assert.NoError(t, err)
+			args[4].(func(block uint64))(150)
+			// Progress that does not advance is not recorded again
+			args[4].(func(block uint64))(150)
+			<-release
+		}).
+		Return(nil).Once()
+
+	bf, err := cm.BackfillContractListener(context.Background(), "sub1", &core.ContractListenerBackfillRequest{
+		FromBlock: 100,
+		ToBlock:   200,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "ns1", bf.Namespace)
+	assert.Equal(t, listener.ID, bf.Listener)
+	assert.Equal(t, inserted.ID, bf.ID)
+	assert.Equal(t, core.ContractListenerBackfillStatusRunning, bf.Status)
+
+	close(release)
+	waitBackfill(cm, bf.ID)
+	assert.Equal(t, "backfill1", inserted.BackendID)
+	assert.Equal(t, uint64(150), *inserted.CurrentBlock)
+
+	mbi.AssertExpectations(t)
+	mdi.AssertExpectations(t)
+}
+
+func TestBackfillContractListenerInProgress(t *testing.T) {
+	cm := newTestContractManager()
+	mdi := cm.database.(*databasemocks.Plugin)
+
+	listener := &core.ContractListener{
+		ID:        fftypes.NewUUID(),
+		Namespace: "ns1",
+	}
+	mdi.On("GetContractListener", context.Background(), "ns1", "sub1").Return(listener, nil)
+	mdi.On("GetContractListenerBackfills", context.Background(), "ns1", mock.Anything).Return([]*core.ContractListenerBackfill{
+		{ID: fftypes.NewUUID(), Status: core.ContractListenerBackfillStatusRunning},
+	}, nil, nil)
+
+	_, err := cm.BackfillContractListener(context.Background(), "sub1", &core.ContractListenerBackfillRequest{
+		FromBlock: 100,
+		ToBlock:   200,
+	})
+	assert.Regexp(t, "FF10538", err)
+
+	mdi.AssertExpectations(t)
+}
+
+func TestBackfillContractListenerQueryFail(t *testing.T) {
+	cm := newTestContractManager()
+	mdi := cm.database.(*databasemocks.Plugin)
+
+	listener := &core.ContractListener{
+		ID:        fftypes.NewUUID(),
+		Namespace: "ns1",
+	}
+	mdi.On("GetContractListener", context.Background(), "ns1", "sub1").Return(listener, nil)
+	mdi.On("GetContractListenerBackfills", context.Background(), "ns1", mock.Anything).Return(nil, nil, fmt.Errorf("pop"))
+
+	_, err := cm.BackfillContractListener(context.Background(), "sub1", &core.ContractListenerBackfillRequest{ToBlock: 10})
+	assert.EqualError(t, err, "pop")
+
+	mdi.AssertExpectations(t)
+}
+
+func TestBackfillContractListenerInsertFail(t *testing.T) {
+	cm := newTestContractManager()
+	mdi := cm.database.(*databasemocks.Plugin)
+
+	listener := &core.ContractListener{
+		ID:        fftypes.NewUUID(),
+		Namespace: "ns1",
+	}
+	mdi.On("GetContractListener", context.Background(), "ns1", "sub1").Return(listener, nil)
+	mdi.On("GetContractListenerBackfills", context.Background(), "ns1", mock.Anything).Return([]*core.ContractListenerBackfill{}, nil, nil)
+	mdi.On("InsertContractListenerBackfill", context.Background(), mock.Anything).Return(fmt.Errorf("pop"))
+
+	_, err := cm.BackfillContractListener(context.Background(), "sub1", &core.ContractListenerBackfillRequest{ToBlock: 10})
+	assert.EqualError(t, err, "pop")
+	assert.Empty(t, cm.backfills)
+
+	mdi.AssertExpectations(t)
+}
+
+func TestBackfillContractListenerFail(t *testing.T) {
+	cm := newTestContractManager()
+	mbi := cm.blockchain.(*blockchainmocks.Plugin)
+	mdi := cm.database.(*databasemocks.Plugin)
+
+	listener := &core.ContractListener{
+		ID:        fftypes.NewUUID(),
+		Namespace: "ns1",
+	}
+	mdi.On("GetContractListener", context.Background(), "ns1", "sub1").Return(listener, nil)
+	mdi.On("GetContractListenerBackfills", context.Background(), "ns1", mock.Anything).Return([]*core.ContractListenerBackfill{}, nil, nil)
+	mdi.On("InsertContractListenerBackfill", context.Background(), mock.Anything).Return(nil)
+	mdi.On("UpdateContractListenerBackfill", mock.Anything, "ns1", mock.Anything, backfillUpdate("backendid='backfill1'")).Return(fmt.Errorf("pop")).Once()
+	mdi.On("UpdateContractListenerBackfill", mock.Anything, "ns1", mock.Anything, backfillUpdate("currentblock=5")).Return(fmt.Errorf("pop")).Once()
+	mdi.On("UpdateContractListenerBackfill", mock.Anything, "ns1", mock.Anything, backfillUpdate("status='failed', error='pop'")).Return(nil).Once()
+	mbi.On("BackfillContractListener", mock.Anything, listener, mock.Anything, mock.Anything, mock.Anything).
+		Run(func(args mock.Arguments) {
+			err := args[3].(func(backendID string) error)("backfill1")
+			assert.EqualError(t, err, "pop")
+			args[4].(func(block uint64))(5)
+		}).
+		Return(fmt.Errorf("pop"))
+
+	bf, err := cm.BackfillContractListener(context.Background(), "sub1", &core.ContractListenerBackfillRequest{
+		ToBlock: 10,
+	})
+	assert.NoError(t, err)
+	waitBackfill(cm, bf.ID)
+
+	mbi.AssertExpectations(t)
+	mdi.AssertExpectations(t)
+}
+
+func TestBackfillContractListenerStatusUpdateFail(t *testing.T) {
+	cm := newTestContractManager()
+	cm.backfillRetention = 0
+	mbi := cm.blockchain.(*blockchainmocks.Plugin)
+	mdi := cm.database.(*databasemocks.Plugin)
+
+	listener := &core.ContractListener{
+		ID:        fftypes.NewUUID(),
+		Namespace: "ns1",
+	}
+	mdi.On("GetContractListener", context.Background(), "ns1", "sub1").Return(listener, nil)
+	mdi.On("GetContractListenerBackfills", context.Background(), "ns1", mock.Anything).Return([]*core.ContractListenerBackfill{}, nil, nil)
+	mdi.On("InsertContractListenerBackfill", context.Background(), mock.Anything).Return(nil)
+	mdi.On("UpdateContractListenerBackfill", mock.Anything, "ns1", mock.Anything, mock.Anything).Return(fmt.Errorf("pop"))
+	mbi.On("BackfillContractListener", mock.Anything, listener, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+	bf, err := cm.BackfillContractListener(context.Background(), "sub1", &core.ContractListenerBackfillRequest{
+		ToBlock: 10,
+	})
+	assert.NoError(t, err)
+	waitBackfill(cm, bf.ID)
+
+	// The backfill is left to be resumed on restart, rather than removed
+	mdi.AssertNotCalled(t, "DeleteContractListenerBackfill", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestBackfillContractListenerInterrupted(t *testing.T) {
+	cm := newTestContractManager()
+	mbi := cm.blockchain.(*blockchainmocks.Plugin)
+	mdi := cm.database.(*databasemocks.Plugin)
+	ctx, cancel := context.WithCancel(context.Background())
+	cm.ctx = ctx
+
+	listener := &core.ContractListener{
+		ID:        fftypes.NewUUID(),
+		Namespace: "ns1",
+	}
+	mdi.On("GetContractListener", context.Background(), "ns1", "sub1").Return(listener, nil)
+	mdi.On("GetContractListenerBackfills", context.Background(), "ns1", mock.Anything).Return([]*core.ContractListenerBackfill{}, nil, nil)
+	mdi.On("InsertContractListenerBackfill", context.Background(), mock.Anything).Return(nil)
+	mbi.On("BackfillContractListener", mock.Anything, listener, mock.Anything, mock.Anything, mock.Anything).
+		Run(func(args mock.Arguments) {
+			cancel()
+		}).
+		Return(fmt.Errorf("context cancelled"))
+
+	bf, err := cm.BackfillContractListener(context.Background(), "sub1", &core.ContractListenerBackfillRequest{
+		ToBlock: 10,
+	})
+	assert.NoError(t, err)
+	waitBackfill(cm, bf.ID)
+
+	// The status is left as running, so the backfill is resumed
+	mdi.AssertNotCalled(t, "UpdateContractListenerBackfill", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestBackfillContractListenerRemovedAfterRetention(t *testing.T) {
+	cm := newTestContractManager()
+	cm.backfillRetention = 0
+	mbi := cm.blockchain.(*blockchainmocks.Plugin)
+	mdi := cm.database.(*databasemocks.Plugin)
+
+	listener := &core.ContractListener{
+		ID:        fftypes.NewUUID(),
+		Namespace: "ns1",
+	}
+	mdi.On("GetContractListener", context.Background(), "ns1", "sub1").Return(listener, nil)
+	mdi.On("GetContractListenerBackfills", context.Background(), "ns1", mock.Anything).Return([]*core.ContractListenerBackfill{}, nil, nil)
+	mdi.On("InsertContractListenerBackfill", context.Background(), mock.Anything).Return(nil)
+	mdi.On("UpdateContractListenerBackfill", mock.Anything, "ns1", mock.Anything, mock.Anything).Return(nil)
+	mbi.On("BackfillContractListener", mock.Anything, listener, mock.Anything, mock.Anything, mock.Anything).Return(fmt.Errorf("pop"))
+	removed := make(chan struct{})
+	mdi.On("DeleteContractListenerBackfill", mock.Anything, "ns1", mock.Anything).
+		Run(func(args mock.Arguments) { close(removed) }).
+		Return(fmt.Errorf("pop"))
+
+	bf, err := cm.BackfillContractListener(context.Background(), "sub1", &core.ContractListenerBackfillRequest{
+		ToBlock: 10,
+	})
+	assert.NoError(t, err)
+	<-removed
+	assert.Eventually(t, func() bool {
+		cm.backfillMux.Lock()
+		defer cm.backfillMux.Unlock()
+		_, exists := cm.backfills[*bf.ID]
+		return !exists
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
+func TestRemoveBackfillStopped(t *testing.T) {
+	cm := newTestContractManager()
+	mdi := cm.database.(*databasemocks.Plugin)
+	ctx, cancel := context.WithCancel(context.Background())
+	cm.ctx = ctx
+	cancel()
+
+	id := fftypes.NewUUID()
+	cm.backfills[*id] = make(chan struct{})
+	cm.removeBackfill(ctx, id)
+
+	// The backfill is left to be removed when the namespace next starts
+	mdi.AssertNotCalled(t, "DeleteContractListenerBackfill", mock.Anything, mock.Anything, mock.Anything)
+	assert.Contains(t, cm.backfills, *id)
+}
+
+func TestResumeBackfills(t *testing.T) {
+	cm := newTestContractManager()
+	mbi := cm.blockchain.(*blockchainmocks.Plugin)
+	mdi := cm.database.(*databasemocks.Plugin)
+
+	listener := &core.ContractListener{
+		ID:        fftypes.NewUUID(),
+		Namespace: "ns1",
+	}
+	currentBlock := uint64(50)
+	running := &core.ContractListenerBackfill{
+		ID:           fftypes.NewUUID(),
+		Listener:     listener.ID,
+		FromBlock:    0,
+		ToBlock:      100,
+		CurrentBlock: &currentBlock,
+		BackendID:    "backfill1",
+		Status:       core.ContractListenerBackfillStatusRunning,
+	}
+	alreadyRunning := &core.ContractListenerBackfill{
+		ID:     fftypes.NewUUID(),
+		Status: core.ContractListenerBackfillStatusRunning,
+	}
+	cm.backfills[*alreadyRunning.ID] = make(chan struct{})
+	deletedListener := &core.ContractListenerBackfill{
+		ID:       fftypes.NewUUID(),
+		Listener: fftypes.NewUUID(),
+		Status:   core.ContractListenerBackfillStatusRunning,
+	}
+	listenerFail := &core.ContractListenerBackfill{
+		ID:       fftypes.NewUUID(),
+		Listener: fftypes.NewUUID(),
+		Status:   core.ContractListenerBackfillStatusRunning,
+	}
+	expiredTime := fftypes.FFTime(time.Now().Add(-2 * time.Hour))
+	expired := &core.ContractListenerBackfill{
+		ID:      fftypes.NewUUID(),
+		Status:  core.ContractListenerBackfillStatusSucceeded,
+		Updated: &expiredTime,
+	}
+
+	mdi.On("GetContractListenerBackfills", mock.Anything, "ns1", mock.Anything).Return([]*core.ContractListenerBackfill{
+		running, alreadyRunning, deletedListener, listenerFail, expired,
+	}, nil, nil)
+	mdi.On("GetContractListenerByID", mock.Anything, "ns1", listener.ID).Return(listener, nil)
+	mdi.On("GetContractListenerByID", mock.Anything, "ns1", deletedListener.Listener).Return(nil, nil)
+	mdi.On("GetContractListenerByID", mock.Anything, "ns1", listenerFail.Listener).Return(nil, fmt.Errorf("pop"))
+	mdi.On("DeleteContractListenerBackfill", mock.Anything, "ns1", deletedListener.ID).Return(fmt.Errorf("pop"))
+	removed := make(chan struct{})
+	mdi.On("DeleteContractListenerBackfill", mock.Anything, "ns1", expired.ID).
+		Run(func(args mock.Arguments) { close(removed) }).
+		Return(nil)
+	mdi.On("UpdateContractListenerBackfill", mock.Anything, "ns1", running.ID, backfillUpdate("status='succeeded'")).Return(nil)
+	mdi.On("DeleteContractListenerBackfill", mock.Anything, "ns1", running.ID).Return(nil).Maybe()
+	mbi.On("BackfillContractListener", mock.Anything, listener, running, mock.Anything, mock.Anything).Return(nil)
+
+	cm.resumeBackfills()
+	waitBackfill(cm, running.ID)
+	<-removed
+
+	mbi.AssertExpectations(t)
+}
+
+func TestResumeBackfillsQueryFail(t *testing.T) {
+	cm := newTestContractManager()
+	mdi := cm.database.(*databasemocks.Plugin)
+
+	mdi.On("GetContractListenerBackfills", mock.Anything, "ns1", mock.Anything).Return(nil, nil, fmt.Errorf("pop"))
+
+	cm.resumeBackfills()
+	assert.Empty(t, cm.backfills)
+
+	mdi.AssertExpectations(t)
+}
+
+func TestGetContractListenerBackfills(t *testing.T) {
+	cm := newTestContractManager()
+	mdi := cm.database.(*databasemocks.Plugin)
+
+	listener := &core.ContractListener{
+		ID:        fftypes.NewUUID(),
+		Namespace: "ns1",
+	}
+	backfills := []*core.ContractListenerBackfill{{ID: fftypes.NewUUID()}}
+	mdi.On("GetContractListener", context.Background(), "ns1", "sub1").Return(listener, nil)
+	mdi.On("GetContractListenerBackfills", context.Background(), "ns1", mock.MatchedBy(func(filter ffapi.Filter) bool {
+		info, _ := filter.Finalize()
+		return info.String() == fmt.Sprintf("( listener == '%s' ) sort=created", listener.ID)
+	})).Return(backfills, nil, nil)
+
+	result, err := cm.GetContractListenerBackfills(context.Background(), "sub1")
+	assert.NoError(t, err)
+	assert.Equal(t, backfills, result)
+
+	mdi.AssertExpectations(t)
+}
+
+func TestBackfillContractListenerBadRange(t *testing.T) {
+	cm := newTestContractManager()
+
+	_, err := cm.BackfillContractListener(context.Background(), "sub1", &core.ContractListenerBackfillRequest{
+		FromBlock: 200,
+		ToBlock:   100,
+	})
+	assert.Regexp(t, "FF10537", err)
+}
+
+func TestBackfillContractListenerNotFound(t *testing.T) {
+	cm := newTestContractManager()
+	mdi := cm.database.(*databasemocks.Plugin)
+
+	mdi.On("GetContractListener", context.Background(), "ns1", "sub1").Return(nil, nil)
+
+	_, err := cm.BackfillContractListener(context.Background(), "sub1", &core.ContractListenerBackfillRequest{})
+	assert.Regexp(t, "FF10109", err)
+
+	_, err = cm.GetContractListenerBackfills(context.Background(), "sub1")
+	assert.Regexp(t, "FF10109", err)
+}
@@ -0,0 +1,169 @@
+// Copyright © 2024 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package contracts
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/hyperledger/firefly-common/pkg/fftypes"
+	"github.com/hyperledger/firefly-common/pkg/log"
+	"github.com/hyperledger/firefly/pkg/core"
+	"github.com/hyperledger/firefly/pkg/database"
+)
+
+const listenerHealthPageSize = 100
+
+func (cm *contractManager) Start() {
+	cm.resumeBackfills()
+	if cm.healthInterval <= 0 || (!cm.metrics.IsMetricsEnabled() && cm.lagBlocksThreshold == 0 && cm.lagTimeThreshold == 0) {
+		return
+	}
+	cm.healthDone = make(chan struct{})
+	go cm.listenerHealthLoop()
+}
+
+func (cm *contractManager) WaitStop() {
+	if cm.healthDone != nil {
+		<-cm.healthDone
+	}
+}
+
+func (cm *contractManager) listenerHealthLoop() {
+	defer close(cm.healthDone)
+	ctx := log.WithLogField(cm.ctx, "role", "listener-health")
+	ticker := time.NewTicker(cm.healthInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			cm.checkListenerHealth(ctx)
+		case <-ctx.Done():
+			log.L(ctx).Debugf("Listener health monitor exiting")
+			return
+		}
+	}
+}
+
+// getListenerHealth queries the blockchain plugin for the health of a listener, and compares it to the configured thresholds.
+// A failure to query the plugin is reported in the health, rather than returned.
+func (cm *contractManager) getListenerHealth(ctx context.Context, listener *core.ContractListener) *core.ContractListenerHealth {
+	health, err := cm.blockchain.GetContractListenerHealth(ctx, listener.Namespace, listener.BackendID)
+	if err != nil {
+		health = &core.ContractListenerHealth{
+			Status:    core.ContractListenerStatusUnknown,
+			LastError: err.Error(),
+		}
+	} else {
+		cm.setLagSeconds(listener, health)
+	}
+	health.Behind = (cm.lagBlocksThreshold > 0 && health.LagBlocks != nil && *health.LagBlocks > cm.lagBlocksThreshold) ||
+		(cm.lagTimeThreshold > 0 && health.LagSeconds != nil && time.Duration(*health.LagSeconds)*time.Second > cm.lagTimeThreshold)
+	health.Updated = fftypes.Now()
+	return health
+}
+
+// setLagSeconds measures how long a listener has been behind, for plugins that report how many blocks a listener is
+// behind, but not the timestamps of those blocks. It is measured from the first check that found the listener behind,
+// so it is only as precise as the interval between checks.
+func (cm *contractManager) setLagSeconds(listener *core.ContractListener, health *core.ContractListenerHealth) {
+	if health.LagSeconds != nil || health.LagBlocks == nil {
+		return
+	}
+	cm.lagMux.Lock()
+	defer cm.lagMux.Unlock()
+	var lagSeconds int64
+	if *health.LagBlocks == 0 {
+		delete(cm.lagSince, *listener.ID)
+	} else {
+		now := fftypes.Now()
+		since := cm.lagSince[*listener.ID]
+		if since == nil {
+			since = now
+			cm.lagSince[*listener.ID] = since
+		}
+		lagSeconds = int64(time.Time(*now).Sub(time.Time(*since)).Seconds())
+	}
+	health.LagSeconds = &lagSeconds
+}
+
+// checkListenerHealth updates the metrics for every listener in the namespace, and emits an event for each listener
+// that has fallen behind since the last check
+func (cm *contractManager) checkListenerHealth(ctx context.Context) {
+	seen := make(map[fftypes.UUID]bool)
+	fb := database.ContractListenerQueryFactory.NewFilter(ctx)
+	for skip := uint64(0); ; skip += listenerHealthPageSize {
+		listeners, _, err := cm.database.GetContractListeners(ctx, cm.namespace, fb.And().Sort("created").Skip(skip).Limit(listenerHealthPageSize))
+		if err != nil {
+			// Listeners not reached are left as they are until the next check
+			log.L(ctx).Errorf("Failed to query contract listeners: %s", err)
+			return
+		}
+		for _, listener := range listeners {
+			seen[*listener.ID] = true
+			cm.updateListenerHealth(ctx, listener)
+		}
+		if len(listeners) < listenerHealthPageSize {
+			break
+		}
+	}
+
+	for id := range cm.listenersBehind {
+		if !seen[id] {
+			cm.metrics.ContractListenerRemoved(cm.namespace, id.String())
+			delete(cm.listenersBehind, id)
+		}
+	}
+	cm.lagMux.Lock()
+	for id := range cm.lagSince {
+		if !seen[id] {
+			delete(cm.lagSince, id)
+		}
+	}
+	cm.lagMux.Unlock()
+}
+
+func (cm *contractManager) updateListenerHealth(ctx context.Context, listener *core.ContractListener) {
+	health := cm.getListenerHealth(ctx, listener)
+	if cm.metrics.IsMetricsEnabled() {
+		cm.metrics.ContractListenerHealth(cm.namespace, listener.ID.String(), health)
+	}
+
+	wasBehind := cm.listenersBehind[*listener.ID]
+	switch {
+	case health.Behind && !wasBehind:
+		log.L(ctx).Warnf("Contract listener %s is behind: lagBlocks=%s lagSeconds=%s", listener.ID, formatLag(health.LagBlocks), formatLag(health.LagSeconds))
+		event := core.NewEvent(core.EventTypeContractListenerBehind, cm.namespace, listener.ID, nil, listener.Topic)
+		if err := cm.database.InsertEvent(ctx, event); err != nil {
+			// Not recorded as behind, so the event is emitted on the next check
+			log.L(ctx).Errorf("Failed to emit event for contract listener %s: %s", listener.ID, err)
+			cm.listenersBehind[*listener.ID] = false
+			return
+		}
+	case !health.Behind && wasBehind:
+		log.L(ctx).Infof("Contract listener %s has caught up", listener.ID)
+	}
+	cm.listenersBehind[*listener.ID] = health.Behind
+}
+
+func formatLag[T uint64 | int64](v *T) string {
+	if v == nil {
+		return "unknown"
+	}
+	return fmt.Sprint(*v)
+}
@@ -0,0 +1,242 @@
+// Copyright © 2024 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package contracts
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/firefly-common/pkg/fftypes"
+	"github.com/hyperledger/firefly/mocks/blockchainmocks"
+	"github.com/hyperledger/firefly/mocks/databasemocks"
+	"github.com/hyperledger/firefly/mocks/metricsmocks"
+	"github.com/hyperledger/firefly/pkg/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func lagHealth(blocks uint64, seconds int64) *core.ContractListenerHealth {
+	return &core.ContractListenerHealth{
+		Status:     core.ContractListenerStatusSyncing,
+		LagBlocks:  &blocks,
+		LagSeconds: &seconds,
+	}
+}
+
+func TestListenerHealthDisabled(t *testing.T) {
+	cm := newTestContractManager()
+	mdi := cm.database.(*databasemocks.Plugin)
+	mmi := cm.metrics.(*metricsmocks.Manager)
+	mmi.On("IsMetricsEnabled").Return(false)
+	mdi.On("GetContractListenerBackfills", mock.Anything, "ns1", mock.Anything).Return([]*core.ContractListenerBackfill{}, nil, nil)
+
+	cm.healthInterval = time.Minute
+	cm.Start()
+	assert.Nil(t, cm.healthDone)
+	cm.WaitStop()
+
+	cm.healthInterval = 0
+	cm.lagBlocksThreshold = 10
+	cm.Start()
+	assert.Nil(t, cm.healthDone)
+	cm.WaitStop()
+}
+
+func TestListenerHealthStartStop(t *testing.T) {
+	cm := newTestContractManager()
+	mdi := cm.database.(*databasemocks.Plugin)
+	mmi := cm.metrics.(*metricsmocks.Manager)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cm.ctx = ctx
+	cm.healthInterval = time.Millisecond
+
+	checked := make(chan struct{})
+	mmi.On("IsMetricsEnabled").Return(true)
+	mdi.On("GetContractListenerBackfills", mock.Anything, "ns1", mock.Anything).Return([]*core.ContractListenerBackfill{}, nil, nil)
+	mdi.On("GetContractListeners", mock.Anything, "ns1", mock.Anything).
+		Run(func(args mock.Arguments) {
+			close(checked)
+			cancel()
+		}).
+		Return([]*core.ContractListener{}, nil, nil).Once()
+	mdi.On("GetContractListeners", mock.Anything, "ns1", mock.Anything).Return([]*core.ContractListener{}, nil, nil).Maybe()
+
+	cm.Start()
+	<-checked
+	cm.WaitStop()
+}
+
+func TestCheckListenerHealth(t *testing.T) {
+	cm := newTestContractManager()
+	mdi := cm.database.(*databasemocks.Plugin)
+	mbi := cm.blockchain.(*blockchainmocks.Plugin)
+	mmi := cm.metrics.(*metricsmocks.Manager)
+	cm.lagBlocksThreshold = 10
+	cm.lagTimeThreshold = time.Minute
+
+	l1 := &core.ContractListener{ID: fftypes.NewUUID(), Namespace: "ns1", BackendID: "sub1", Topic: "topic1"}
+	l2 := &core.ContractListener{ID: fftypes.NewUUID(), Namespace: "ns1", BackendID: "sub2"}
+	mmi.On("IsMetricsEnabled").Return(true)
+	mmi.On("ContractListenerHealth", "ns1", mock.Anything, mock.Anything).Return()
+
+	// First check - l1 has fallen behind on blocks, and l2 has fallen behind on time
+	mdi.On("GetContractListeners", mock.Anything, "ns1", mock.Anything).Return([]*core.ContractListener{l1, l2}, nil, nil).Once()
+	mbi.On("GetContractListenerHealth", mock.Anything, "ns1", "sub1").Return(lagHealth(11, 5), nil).Once()
+	mbi.On("GetContractListenerHealth", mock.Anything, "ns1", "sub2").Return(lagHealth(1, 61), nil).Once()
+	mdi.On("InsertEvent", mock.Anything, mock.MatchedBy(func(e *core.Event) bool {
+		return e.Type == core.EventTypeContractListenerBehind && e.Reference.Equals(l1.ID) && e.Topic == "topic1"
+	})).Return(nil).Once()
+	mdi.On("InsertEvent", mock.Anything, mock.MatchedBy(func(e *core.Event) bool {
+		return e.Type == core.EventTypeContractListenerBehind && e.Reference.Equals(l2.ID)
+	})).Return(nil).Once()
+	cm.checkListenerHealth(context.Background())
+	assert.True(t, cm.listenersBehind[*l1.ID])
+	assert.True(t, cm.listenersBehind[*l2.ID])
+
+	// Second check - l1 is still behind so no new event, and l2 has caught up
+	mdi.On("GetContractListeners", mock.Anything, "ns1", mock.Anything).Return([]*core.ContractListener{l1, l2}, nil, nil).Once()
+	mbi.On("GetContractListenerHealth", mock.Anything, "ns1", "sub1").Return(lagHealth(20, 5), nil).Once()
+	mbi.On("GetContractListenerHealth", mock.Anything, "ns1", "sub2").Return(lagHealth(0, 0), nil).Once()
+	cm.checkListenerHealth(context.Background())
+	assert.True(t, cm.listenersBehind[*l1.ID])
+	assert.False(t, cm.listenersBehind[*l2.ID])
+
+	// Third check - l1 has been deleted
+	mdi.On("GetContractListeners", mock.Anything, "ns1", mock.Anything).Return([]*core.ContractListener{l2}, nil, nil).Once()
+	mbi.On("GetContractListenerHealth", mock.Anything, "ns1", "sub2").Return(lagHealth(0, 0), nil).Once()
+	mmi.On("ContractListenerRemoved", "ns1", l1.ID.String()).Return().Once()
+	cm.checkListenerHealth(context.Background())
+	assert.NotContains(t, cm.listenersBehind, *l1.ID)
+
+	mdi.AssertExpectations(t)
+	mbi.AssertExpectations(t)
+	mmi.AssertExpectations(t)
+}
+
+func TestCheckListenerHealthEventFail(t *testing.T) {
+	cm := newTestContractManager()
+	mdi := cm.database.(*databasemocks.Plugin)
+	mbi := cm.blockchain.(*blockchainmocks.Plugin)
+	mmi := cm.metrics.(*metricsmocks.Manager)
+	cm.lagBlocksThreshold = 10
+
+	l1 := &core.ContractListener{ID: fftypes.NewUUID(), Namespace: "ns1", BackendID: "sub1"}
+	mmi.On("IsMetricsEnabled").Return(false)
+	mdi.On("GetContractListeners", mock.Anything, "ns1", mock.Anything).Return([]*core.ContractListener{l1}, nil, nil)
+	lagBlocks := uint64(11)
+	mbi.On("GetContractListenerHealth", mock.Anything, "ns1", "sub1").Return(&core.ContractListenerHealth{LagBlocks: &lagBlocks}, nil)
+
+	mdi.On("InsertEvent", mock.Anything, mock.Anything).Return(fmt.Errorf("pop")).Once()
+	cm.checkListenerHealth(context.Background())
+	assert.False(t, cm.listenersBehind[*l1.ID])
+
+	// The event is retried on the next check
+	mdi.On("InsertEvent", mock.Anything, mock.Anything).Return(nil).Once()
+	cm.checkListenerHealth(context.Background())
+	assert.True(t, cm.listenersBehind[*l1.ID])
+
+	mdi.AssertExpectations(t)
+}
+
+func TestCheckListenerHealthPaging(t *testing.T) {
+	cm := newTestContractManager()
+	mdi := cm.database.(*databasemocks.Plugin)
+	mbi := cm.blockchain.(*blockchainmocks.Plugin)
+	mmi := cm.metrics.(*metricsmocks.Manager)
+
+	page := make([]*core.ContractListener, listenerHealthPageSize)
+	for i := range page {
+		page[i] = &core.ContractListener{ID: fftypes.NewUUID(), Namespace: "ns1", BackendID: "sub1"}
+	}
+	mmi.On("IsMetricsEnabled").Return(false)
+	mdi.On("GetContractListeners", mock.Anything, "ns1", mock.Anything).Return(page, nil, nil).Once()
+	mdi.On("GetContractListeners", mock.Anything, "ns1", mock.Anything).Return([]*core.ContractListener{}, nil, nil).Once()
+	mbi.On("GetContractListenerHealth", mock.Anything, "ns1", "sub1").Return(nil, fmt.Errorf("pop"))
+
+	cm.checkListenerHealth(context.Background())
+	assert.Len(t, cm.listenersBehind, listenerHealthPageSize)
+
+	mdi.AssertExpectations(t)
+}
+
+func TestCheckListenerHealthQueryFail(t *testing.T) {
+	cm := newTestContractManager()
+	mdi := cm.database.(*databasemocks.Plugin)
+
+	cm.listenersBehind[*fftypes.NewUUID()] = true
+	mdi.On("GetContractListeners", mock.Anything, "ns1", mock.Anything).Return(nil, nil, fmt.Errorf("pop"))
+
+	cm.checkListenerHealth(context.Background())
+	assert.Len(t, cm.listenersBehind, 1)
+}
+
+func TestCheckListenerHealthLagTimeFromBlocks(t *testing.T) {
+	cm := newTestContractManager()
+	mdi := cm.database.(*databasemocks.Plugin)
+	mbi := cm.blockchain.(*blockchainmocks.Plugin)
+	mmi := cm.metrics.(*metricsmocks.Manager)
+	cm.lagTimeThreshold = time.Minute
+
+	l1 := &core.ContractListener{ID: fftypes.NewUUID(), Namespace: "ns1", BackendID: "sub1"}
+	lagBlocks := func(blocks uint64) *core.ContractListenerHealth {
+		return &core.ContractListenerHealth{Status: core.ContractListenerStatusSyncing, LagBlocks: &blocks}
+	}
+	mmi.On("IsMetricsEnabled").Return(false)
+
+	// The plugin only reports the lag in blocks, so the lag in time is measured from the first check that was behind
+	mdi.On("GetContractListeners", mock.Anything, "ns1", mock.Anything).Return([]*core.ContractListener{l1}, nil, nil).Once()
+	mbi.On("GetContractListenerHealth", mock.Anything, "ns1", "sub1").Return(lagBlocks(5), nil).Once()
+	cm.checkListenerHealth(context.Background())
+	assert.False(t, cm.listenersBehind[*l1.ID])
+	assert.Contains(t, cm.lagSince, *l1.ID)
+
+	// Still behind two minutes later
+	since := fftypes.FFTime(time.Now().Add(-2 * time.Minute))
+	cm.lagSince[*l1.ID] = &since
+	mdi.On("GetContractListeners", mock.Anything, "ns1", mock.Anything).Return([]*core.ContractListener{l1}, nil, nil).Once()
+	mbi.On("GetContractListenerHealth", mock.Anything, "ns1", "sub1").Return(lagBlocks(3), nil).Once()
+	mdi.On("InsertEvent", mock.Anything, mock.MatchedBy(func(e *core.Event) bool {
+		return e.Type == core.EventTypeContractListenerBehind && e.Reference.Equals(l1.ID)
+	})).Return(nil).Once()
+	cm.checkListenerHealth(context.Background())
+	assert.True(t, cm.listenersBehind[*l1.ID])
+
+	// Caught up
+	mdi.On("GetContractListeners", mock.Anything, "ns1", mock.Anything).Return([]*core.ContractListener{l1}, nil, nil).Once()
+	mbi.On("GetContractListenerHealth", mock.Anything, "ns1", "sub1").Return(lagBlocks(0), nil).Once()
+	cm.checkListenerHealth(context.Background())
+	assert.False(t, cm.listenersBehind[*l1.ID])
+	assert.NotContains(t, cm.lagSince, *l1.ID)
+
+	// Behind again, then deleted
+	mdi.On("GetContractListeners", mock.Anything, "ns1", mock.Anything).Return([]*core.ContractListener{l1}, nil, nil).Once()
+	mbi.On("GetContractListenerHealth", mock.Anything, "ns1", "sub1").Return(lagBlocks(1), nil).Once()
+	cm.checkListenerHealth(context.Background())
+	assert.Contains(t, cm.lagSince, *l1.ID)
+	mdi.On("GetContractListeners", mock.Anything, "ns1", mock.Anything).Return([]*core.ContractListener{}, nil, nil).Once()
+	mmi.On("ContractListenerRemoved", "ns1", l1.ID.String()).Return().Once()
+	cm.checkListenerHealth(context.Background())
+	assert.NotContains(t, cm.lagSince, *l1.ID)
+	assert.Equal(t, "unknown", formatLag[uint64](nil))
+
+	mdi.AssertExpectations(t)
+	mbi.AssertExpectations(t)
+	mmi.AssertExpectations(t)
+}
@@ -25,7 +25,10 @@ import (
 	"hash"
 	"sort"
 	"strings"
+	"sync"
+	"time"
 
+	"github.com/hyperledger/firefly-common/pkg/config"
 	"github.com/hyperledger/firefly-common/pkg/ffapi"
 	"github.com/hyperledger/firefly-common/pkg/fftypes"
 	"github.com/hyperledger/firefly-common/pkg/i18n"
@@ -38,6 +41,7 @@ import (
 	"github.com/hyperledger/firefly/internal/data"
 	"github.com/hyperledger/firefly/internal/database/sqlcommon"
 	"github.com/hyperledger/firefly/internal/identity"
+	"github.com/hyperledger/firefly/internal/metrics"
 	"github.com/hyperledger/firefly/internal/operations"
 	"github.com/hyperledger/firefly/internal/privatemessaging"
 	"github.com/hyperledger/firefly/internal/syncasync"
@@ -52,6 +56,10 @@ import (
 type Manager interface {
 	core.Named
 
+	// Start resumes any backfills of contract listeners that were interrupted, and begins monitoring the health of contract listeners, if enabled
+	Start()
+	WaitStop()
+
 	GetFFI(ctx context.Context, name, version string) (*fftypes.FFI, error)
 	GetFFIWithChildren(ctx context.Context, name, version string) (*fftypes.FFI, error)
 	GetFFIByID(ctx context.Context, id *fftypes.UUID) (*fftypes.FFI, error)
@@ -80,6 +88,8 @@ type Manager interface {
 	GetContractListeners(ctx context.Context, filter ffapi.AndFilter) ([]*core.ContractListener, *ffapi.FilterResult, error)
 	GetContractAPIListeners(ctx context.Context, apiName, eventPath string, filter ffapi.AndFilter) ([]*core.ContractListener, *ffapi.FilterResult, error)
 	DeleteContractListenerByNameOrID(ctx context.Context, nameOrID string) error
+	BackfillContractListener(ctx context.Context, nameOrID string, req *core.ContractListenerBackfillRequest) (*core.ContractListenerBackfill, error)
+	GetContractListenerBackfills(ctx context.Context, nameOrID string) ([]*core.ContractListenerBackfill, error)
 	GenerateFFI(ctx context.Context, generationRequest *fftypes.FFIGenerationRequest) (*fftypes.FFI, error)
 
 	// From operations.OperationHandler
@@ -88,20 +98,32 @@ type Manager interface {
 }
 
 type contractManager struct {
-	namespace         string
-	database          database.Plugin
-	data              data.Manager
-	broadcast         broadcast.Manager        // optional
-	messaging         privatemessaging.Manager // optional
-	batch             batch.Manager            // optional
-	txHelper          txcommon.Helper
-	txWriter          txwriter.Writer
-	identity          identity.Manager
-	blockchain        blockchain.Plugin
-	ffiParamValidator fftypes.FFIParamValidator
-	operations        operations.Manager
-	syncasync         syncasync.Bridge
-	methodCache       cache.CInterface
+	ctx                context.Context
+	namespace          string
+	database           database.Plugin
+	data               data.Manager
+	broadcast          broadcast.Manager        // optional
+	messaging          privatemessaging.Manager // optional
+	batch              batch.Manager            // optional
+	txHelper           txcommon.Helper
+	txWriter           txwriter.Writer
+	identity           identity.Manager
+	blockchain         blockchain.Plugin
+	ffiParamValidator  fftypes.FFIParamValidator
+	operations         operations.Manager
+	syncasync          syncasync.Bridge
+	metrics            metrics.Manager
+	methodCache        cache.CInterface
+	backfillMux        sync.Mutex
+	backfills          map[fftypes.UUID]chan struct{}
+	backfillRetention  time.Duration
+	healthInterval     time.Duration
+	lagBlocksThreshold uint64
+	lagTimeThreshold   time.Duration
+	healthDone         chan struct{}
+	listenersBehind    map[fftypes.UUID]bool
+	lagMux             sync.Mutex
+	lagSince           map[fftypes.UUID]*fftypes.FFTime
 }
 
 type methodCacheEntry struct {
@@ -113,8 +135,8 @@ type schemaValidationEntry struct {
 	schema *jsonschema.Schema
 }
 
-func NewContractManager(ctx context.Context, ns string, di database.Plugin, bi blockchain.Plugin, dm data.Manager, bm broadcast.Manager, pm privatemessaging.Manager, bp batch.Manager, im identity.Manager, om operations.Manager, txHelper txcommon.Helper, txWriter txwriter.Writer, sa syncasync.Bridge, cacheManager cache.Manager) (Manager, error) {
-	if di == nil || im == nil || bi == nil || dm == nil || om == nil || txHelper == nil || txWriter == nil || sa == nil || cacheManager == nil {
+func NewContractManager(ctx context.Context, ns string, di database.Plugin, bi blockchain.Plugin, dm data.Manager, bm broadcast.Manager, pm privatemessaging.Manager, bp batch.Manager, im identity.Manager, om operations.Manager, txHelper txcommon.Helper, txWriter txwriter.Writer, sa syncasync.Bridge, mm metrics.Manager, cacheManager cache.Manager) (Manager, error) {
+	if di == nil || im == nil || bi == nil || dm == nil || om == nil || txHelper == nil || txWriter == nil || sa == nil || mm == nil || cacheManager == nil {
 		return nil, i18n.NewError(ctx, coremsgs.MsgInitializationNilDepError, "ContractManager")
 	}
 	v, err := bi.GetFFIParamValidator(ctx)
@@ -123,19 +145,28 @@ func NewContractManager(ctx context.Context, ns string, di database.Plugin, bi b
 	}
 
 	cm := &contractManager{
-		namespace:         ns,
-		database:          di,
-		data:              dm,
-		broadcast:         bm,
-		messaging:         pm,
-		batch:             bp,
-		txHelper:          txHelper,
-		txWriter:          txWriter,
-		identity:          im,
-		blockchain:        bi,
-		ffiParamValidator: v,
-		operations:        om,
-		syncasync:         sa,
+		ctx:                ctx,
+		namespace:          ns,
+		database:           di,
+		data:               dm,
+		broadcast:          bm,
+		messaging:          pm,
+		batch:              bp,
+		txHelper:           txHelper,
+		txWriter:           txWriter,
+		identity:           im,
+		blockchain:         bi,
+		ffiParamValidator:  v,
+		operations:         om,
+		syncasync:          sa,
+		metrics:            mm,
+		backfills:          make(map[fftypes.UUID]chan struct{}),
+		backfillRetention:  defaultBackfillRetention,
+		healthInterval:     config.GetDuration(coreconfig.ContractListenerHealthInterval),
+		lagBlocksThreshold: config.GetUint64(coreconfig.ContractListenerHealthLagBlocks),
+		lagTimeThreshold:   config.GetDuration(coreconfig.ContractListenerHealthLagTime),
+		listenersBehind:    make(map[fftypes.UUID]bool),
+		lagSince:           make(map[fftypes.UUID]*fftypes.FFTime),
 	}
 
 	cm.methodCache, err = cacheManager.GetCache(
@@ -516,12 +547,12 @@ func (cm *contractManager) resolveInvokeContractRequest(ctx context.Context, req
 			req.Errors = cMethodDetails.errors
 			return nil
 		}
-		req.Method, err = cm.database.GetFFIMethod(ctx, cm.namespace, req.Interface, req.MethodPath)
+		req.Method, err = cm.database.GetFFIMethod(database.WithPrimaryReads(ctx), cm.namespace, req.Interface, req.MethodPath)
 		if err != nil || req.Method == nil {
 			return i18n.NewError(ctx, coremsgs.MsgContractMethodResolveError, req.MethodPath, err)
 		}
 		fb := database.FFIErrorQueryFactory.NewFilter(ctx)
-		req.Errors, _, err = cm.database.GetFFIErrors(ctx, cm.namespace, fb.Eq("interface", req.Interface))
+		req.Errors, _, err = cm.database.GetFFIErrors(database.WithPrimaryReads(ctx), cm.namespace, fb.Eq("interface", req.Interface))
 		if err != nil {
 			return i18n.NewError(ctx, coremsgs.MsgContractErrorsResolveError, err)
 		}
@@ -1022,6 +1053,9 @@ func (cm *contractManager) verifyContractListener(ctx context.Context, listener
 	} else if listener.Options.FirstEvent == "" {
 		listener.Options.FirstEvent = cm.getDefaultContractListenerOptions().FirstEvent
 	}
+	if listener.Options.Confirmations < 0 {
+		return nil, i18n.NewError(ctx, coremsgs.MsgContractListenerConfirmationsInvalid, listener.Options.Confirmations)
+	}
 
 	_, err = cm.ConstructContractListenerSignature(ctx, listener)
 	if err != nil {
@@ -1193,6 +1227,7 @@ func (cm *contractManager) GetContractListenerByNameOrIDWithStatus(ctx context.C
 	enrichedListener = &core.ContractListenerWithStatus{
 		ContractListener: *listener,
 		Status:           status,
+		Health:           cm.getListenerHealth(ctx, listener),
 	}
 	return enrichedListener, nil
 }
@@ -1240,6 +1275,9 @@ func (cm *contractManager) DeleteContractListenerByNameOrID(ctx context.Context,
 		if err = cm.blockchain.DeleteContractListener(ctx, listener, true /* ok if not found */); err != nil {
 			return err
 		}
+		if err = cm.database.DeleteContractListenerBackfills(ctx, cm.namespace, listener.ID); err != nil {
+			return err
+		}
 		return cm.database.DeleteContractListenerByID(ctx, cm.namespace, listener.ID)
 	})
 }
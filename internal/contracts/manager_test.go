@@ -43,6 +43,7 @@ import (
 	"github.com/hyperledger/firefly/mocks/databasemocks"
 	"github.com/hyperledger/firefly/mocks/datamocks"
 	"github.com/hyperledger/firefly/mocks/identitymanagermocks"
+	"github.com/hyperledger/firefly/mocks/metricsmocks"
 	"github.com/hyperledger/firefly/mocks/operationmocks"
 	"github.com/hyperledger/firefly/mocks/privatemessagingmocks"
 	"github.com/hyperledger/firefly/mocks/syncasyncmocks"
@@ -83,13 +84,13 @@ func newTestContractManager() *contractManager {
 			a[1].(func(context.Context) error)(a[0].(context.Context)),
 		}
 	}
-	cm, _ := NewContractManager(context.Background(), "ns1", mdi, mbi, mdm, mbm, mpm, mbp, mim, mom, txHelper, txw, msa, cmi)
+	cm, _ := NewContractManager(context.Background(), "ns1", mdi, mbi, mdm, mbm, mpm, mbp, mim, mom, txHelper, txw, msa, &metricsmocks.Manager{}, cmi)
 	cm.(*contractManager).txHelper = &txcommonmocks.Helper{}
 	return cm.(*contractManager)
 }
 
 func TestNewContractManagerFail(t *testing.T) {
-	_, err := NewContractManager(context.Background(), "", nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil)
+	_, err := NewContractManager(context.Background(), "", nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil)
 	assert.Regexp(t, "FF10128", err)
 }
 
@@ -120,7 +121,7 @@ func TestNewContractManagerVerifyListenersFails(t *testing.T) {
 	mbi.On("Name").Return("mockblockchain").Maybe()
 	mdi.On("GetContractListeners", mock.Anything, "ns1", mock.Anything).Return(nil, nil, fmt.Errorf("KABOOM!")).Once()
 
-	cm, err := NewContractManager(context.Background(), "ns1", mdi, mbi, mdm, mbm, mpm, mbp, mim, mom, txHelper, txw, msa, cmi)
+	cm, err := NewContractManager(context.Background(), "ns1", mdi, mbi, mdm, mbm, mpm, mbp, mim, mom, txHelper, txw, msa, &metricsmocks.Manager{}, cmi)
 	assert.Nil(t, cm)
 	assert.NotNil(t, err)
 }
@@ -141,7 +142,7 @@ func TestNewContractManagerFFISchemaLoaderFail(t *testing.T) {
 	txHelper, _ := txcommon.NewTransactionHelper(ctx, "ns1", mdi, mdm, cmi)
 	msa := &syncasyncmocks.Bridge{}
 	mbi.On("GetFFIParamValidator", mock.Anything).Return(nil, fmt.Errorf("pop"))
-	_, err := NewContractManager(context.Background(), "ns1", mdi, mbi, mdm, mbm, mpm, mbp, mim, mom, txHelper, txw, msa, cmi)
+	_, err := NewContractManager(context.Background(), "ns1", mdi, mbi, mdm, mbm, mpm, mbp, mim, mom, txHelper, txw, msa, &metricsmocks.Manager{}, cmi)
 	assert.Regexp(t, "pop", err)
 }
 
@@ -160,7 +161,7 @@ func TestNewContractManagerCacheConfigFail(t *testing.T) {
 	txHelper := &txcommonmocks.Helper{}
 	msa := &syncasyncmocks.Bridge{}
 	mbi.On("GetFFIParamValidator", mock.Anything).Return(nil, nil)
-	_, err := NewContractManager(context.Background(), "ns1", mdi, mbi, mdm, mbm, mpm, mbp, mim, mom, txHelper, txw, msa, cmi)
+	_, err := NewContractManager(context.Background(), "ns1", mdi, mbi, mdm, mbm, mpm, mbp, mim, mom, txHelper, txw, msa, &metricsmocks.Manager{}, cmi)
 	assert.Regexp(t, "pop", err)
 }
 
@@ -182,7 +183,7 @@ func TestNewContractManagerFFISchemaLoader(t *testing.T) {
 	mdi.On("GetContractListeners", mock.Anything, "ns1", mock.Anything).Return(nil, nil, nil)
 	mbi.On("GetFFIParamValidator", mock.Anything).Return(&ffi2abi.ParamValidator{}, nil)
 	mom.On("RegisterHandler", mock.Anything, mock.Anything, mock.Anything)
-	_, err := NewContractManager(context.Background(), "ns1", mdi, mbi, mdm, mbm, mpm, mbp, mim, mom, txHelper, txw, msa, cmi)
+	_, err := NewContractManager(context.Background(), "ns1", mdi, mbi, mdm, mbm, mpm, mbp, mim, mom, txHelper, txw, msa, &metricsmocks.Manager{}, cmi)
 	assert.NoError(t, err)
 }
 
@@ -1436,6 +1437,21 @@ func TestAddContractListenerMissingTopic(t *testing.T) {
 	assert.Regexp(t, "FF00140.*'topic'", err)
 }
 
+func TestAddContractListenerBadConfirmations(t *testing.T) {
+	cm := newTestContractManager()
+	sub := &core.ContractListenerInput{
+		ContractListener: core.ContractListener{
+			Topic: "test-topic",
+			Options: &core.ContractListenerOptions{
+				Confirmations: -1,
+			},
+		},
+	}
+
+	_, err := cm.AddContractListener(context.Background(), sub)
+	assert.Regexp(t, "FF10531", err)
+}
+
 func TestAddContractListenerNoInterface(t *testing.T) {
 	cm := newTestContractManager()
 	mbi := cm.blockchain.(*blockchainmocks.Plugin)
@@ -3144,9 +3160,13 @@ func TestGetContractListenerByNameOrIDWithStatus(t *testing.T) {
 	backendID := "testID"
 	mdi.On("GetContractListenerByID", context.Background(), "ns1", id).Return(&core.ContractListener{Namespace: "ns1", BackendID: backendID}, nil)
 	mbi.On("GetContractListenerStatus", context.Background(), "ns1", backendID, false).Return(true, fftypes.JSONAnyPtr(fftypes.JSONObject{}.String()), core.ContractListenerStatusSynced, nil)
+	mbi.On("GetContractListenerHealth", context.Background(), "ns1", backendID).Return(&core.ContractListenerHealth{Status: core.ContractListenerStatusSynced}, nil)
 
-	_, err := cm.GetContractListenerByNameOrIDWithStatus(context.Background(), id.String())
+	listener, err := cm.GetContractListenerByNameOrIDWithStatus(context.Background(), id.String())
 	assert.NoError(t, err)
+	assert.Equal(t, core.ContractListenerStatusSynced, listener.Health.Status)
+	assert.False(t, listener.Health.Behind)
+	assert.NotNil(t, listener.Health.Updated)
 }
 
 func TestGetContractListenerByNameOrIDWithStatusListenerFail(t *testing.T) {
@@ -3169,6 +3189,7 @@ func TestGetContractListenerByNameOrIDWithStatusPluginFail(t *testing.T) {
 	backendID := "testID"
 	mdi.On("GetContractListenerByID", context.Background(), "ns1", id).Return(&core.ContractListener{Namespace: "ns1", BackendID: backendID}, nil)
 	mbi.On("GetContractListenerStatus", context.Background(), "ns1", backendID, false).Return(false, nil, core.ContractListenerStatusUnknown, fmt.Errorf("pop"))
+	mbi.On("GetContractListenerHealth", context.Background(), "ns1", backendID).Return(nil, fmt.Errorf("pop"))
 
 	listener, err := cm.GetContractListenerByNameOrIDWithStatus(context.Background(), id.String())
 
@@ -3177,6 +3198,8 @@ func TestGetContractListenerByNameOrIDWithStatusPluginFail(t *testing.T) {
 	}
 
 	assert.Equal(t, listener.Status, testError)
+	assert.Equal(t, core.ContractListenerStatusUnknown, listener.Health.Status)
+	assert.Equal(t, "pop", listener.Health.LastError)
 	assert.NoError(t, err)
 }
 
@@ -3419,12 +3442,31 @@ func TestDeleteContractListener(t *testing.T) {
 
 	mdi.On("GetContractListener", context.Background(), "ns1", "sub1").Return(sub, nil)
 	mbi.On("DeleteContractListener", context.Background(), sub, true).Return(nil)
+	mdi.On("DeleteContractListenerBackfills", context.Background(), "ns1", sub.ID).Return(nil)
 	mdi.On("DeleteContractListenerByID", context.Background(), "ns1", sub.ID).Return(nil)
 
 	err := cm.DeleteContractListenerByNameOrID(context.Background(), "sub1")
 	assert.NoError(t, err)
 }
 
+func TestDeleteContractListenerBackfillsFail(t *testing.T) {
+	cm := newTestContractManager()
+	mbi := cm.blockchain.(*blockchainmocks.Plugin)
+	mdi := cm.database.(*databasemocks.Plugin)
+
+	sub := &core.ContractListener{
+		ID: fftypes.NewUUID(),
+	}
+
+	mdi.On("GetContractListener", context.Background(), "ns1", "sub1").Return(sub, nil)
+	mbi.On("DeleteContractListener", context.Background(), sub, true).Return(nil)
+	mdi.On("DeleteContractListenerBackfills", context.Background(), "ns1", sub.ID).Return(fmt.Errorf("pop"))
+
+	err := cm.DeleteContractListenerByNameOrID(context.Background(), "sub1")
+	assert.EqualError(t, err, "pop")
+	mdi.AssertNotCalled(t, "DeleteContractListenerByID", mock.Anything, mock.Anything, mock.Anything)
+}
+
 func TestDeleteContractListenerBlockchainFail(t *testing.T) {
 	cm := newTestContractManager()
 	mbi := cm.blockchain.(*blockchainmocks.Plugin)
@@ -167,6 +167,7 @@ func (cm *contractManager) OnOperationUpdate(ctx context.Context, op *core.Opera
 			}
 		}
 		if update.Status == core.OpStatusFailed {
+			cm.decodeContractError(ctx, op, update)
 			event := core.NewEvent(core.EventTypeBlockchainInvokeOpFailed, op.Namespace, op.ID, op.Transaction, "")
 			if err := cm.database.InsertEvent(ctx, event); err != nil {
 				return err
@@ -189,6 +190,39 @@ func (cm *contractManager) OnOperationUpdate(ctx context.Context, op *core.Opera
 	return nil
 }
 
+// decodeContractError matches the error of a failed invocation against the custom errors of the method, and
+// records the decoded error in the output of the operation so applications do not need to parse the error message
+func (cm *contractManager) decodeContractError(ctx context.Context, op *core.Operation, update *core.OperationUpdate) {
+	if update.ErrorMessage == "" {
+		return
+	}
+	req, err := txcommon.RetrieveBlockchainInvokeInputs(ctx, op)
+	if err != nil {
+		log.L(ctx).Warnf("Unable to decode error for operation %s: %s", op.ID, err)
+		return
+	}
+	if len(req.Errors) == 0 {
+		return
+	}
+	contractError := cm.blockchain.DecodeContractError(ctx, req.Errors, update.ErrorMessage)
+	if contractError == nil {
+		return
+	}
+	var errorJSON fftypes.JSONObject
+	b, _ := json.Marshal(contractError)
+	_ = json.Unmarshal(b, &errorJSON)
+	// The output of the update replaces that of the operation, so the decoded error is added to a merge of the two
+	output := fftypes.JSONObject{}
+	for k, v := range op.Output {
+		output[k] = v
+	}
+	for k, v := range update.Output {
+		output[k] = v
+	}
+	output["contractError"] = errorJSON
+	update.Output = output
+}
+
 func opBlockchainContractDeploy(op *core.Operation, req *core.ContractDeployRequest) *core.PreparedOperation {
 	return &core.PreparedOperation{
 		ID:        op.ID,
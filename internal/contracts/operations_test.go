@@ -886,3 +886,89 @@ func TestOperationUpdateInvokeFail(t *testing.T) {
 
 	mdi.AssertExpectations(t)
 }
+
+func TestOperationUpdateInvokeFailContractError(t *testing.T) {
+	cm := newTestContractManager()
+
+	errors := []*fftypes.FFIError{
+		{FFIErrorDefinition: fftypes.FFIErrorDefinition{Name: "InsufficientBalance"}},
+	}
+	op := &core.Operation{
+		ID:     fftypes.NewUUID(),
+		Type:   core.OpTypeBlockchainInvoke,
+		Output: fftypes.JSONObject{"transactionHash": "0x123", "protocolId": "000000000010/000000"},
+	}
+	err := addBlockchainReqInputs(op, &core.ContractCallRequest{Errors: errors})
+	assert.NoError(t, err)
+	update := &core.OperationUpdate{
+		Status:       core.OpStatusFailed,
+		ErrorMessage: "reverted: InsufficientBalance(\"10\")",
+		Output:       fftypes.JSONObject{"transactionHash": "0x456"},
+	}
+
+	mbi := cm.blockchain.(*blockchainmocks.Plugin)
+	mbi.On("DecodeContractError", context.Background(), mock.MatchedBy(func(e []*fftypes.FFIError) bool {
+		return len(e) == 1 && e[0].Name == "InsufficientBalance"
+	}), update.ErrorMessage).Return(&core.ContractError{
+		Name:      "InsufficientBalance",
+		Signature: "InsufficientBalance(uint256)",
+		Params:    fftypes.JSONObject{"available": "10"},
+	})
+
+	mdi := cm.database.(*databasemocks.Plugin)
+	mdi.On("InsertEvent", context.Background(), mock.MatchedBy(func(event *core.Event) bool {
+		return event.Type == core.EventTypeBlockchainInvokeOpFailed && *event.Reference == *op.ID
+	})).Return(nil)
+
+	err = cm.OnOperationUpdate(context.Background(), op, update)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"name":"InsufficientBalance","params":{"available":"10"},"signature":"InsufficientBalance(uint256)"}`, update.Output.GetObject("contractError").String())
+	// The existing output of the operation is kept, and updated by the output of the update
+	assert.Equal(t, "0x456", update.Output.GetString("transactionHash"))
+	assert.Equal(t, "000000000010/000000", update.Output.GetString("protocolId"))
+	assert.Equal(t, "0x123", op.Output.GetString("transactionHash"))
+
+	mbi.AssertExpectations(t)
+	mdi.AssertExpectations(t)
+}
+
+func TestOperationUpdateInvokeFailNoContractError(t *testing.T) {
+	cm := newTestContractManager()
+
+	errors := []*fftypes.FFIError{
+		{FFIErrorDefinition: fftypes.FFIErrorDefinition{Name: "InsufficientBalance"}},
+	}
+	op := &core.Operation{
+		ID:   fftypes.NewUUID(),
+		Type: core.OpTypeBlockchainInvoke,
+	}
+	err := addBlockchainReqInputs(op, &core.ContractCallRequest{Errors: errors})
+	assert.NoError(t, err)
+	update := &core.OperationUpdate{
+		Status:       core.OpStatusFailed,
+		ErrorMessage: "pop",
+		Output:       fftypes.JSONObject{"transactionHash": "0x123"},
+	}
+
+	mbi := cm.blockchain.(*blockchainmocks.Plugin)
+	mbi.On("DecodeContractError", context.Background(), mock.Anything, "pop").Return(nil)
+
+	mdi := cm.database.(*databasemocks.Plugin)
+	mdi.On("InsertEvent", context.Background(), mock.Anything).Return(nil)
+
+	err = cm.OnOperationUpdate(context.Background(), op, update)
+	assert.NoError(t, err)
+	assert.Equal(t, fftypes.JSONObject{"transactionHash": "0x123"}, update.Output)
+
+	// No errors defined on the method
+	op.Input = fftypes.JSONObject{}
+	err = cm.OnOperationUpdate(context.Background(), op, update)
+	assert.NoError(t, err)
+
+	// Inputs that cannot be parsed
+	op.Input = fftypes.JSONObject{"errors": "bad"}
+	err = cm.OnOperationUpdate(context.Background(), op, update)
+	assert.NoError(t, err)
+
+	mbi.AssertNumberOfCalls(t, "DecodeContractError", 1)
+}
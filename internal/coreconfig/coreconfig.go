@@ -71,6 +71,32 @@ const (
 	NamespaceMultipartyContractLocation = "location"
 	// NamespaceMultipartyContractOptions is an object of additional blockchain-specific configuration
 	NamespaceMultipartyContractOptions = "options"
+	// NamespaceRateLimit overrides the API rate limits and concurrency quotas for a namespace
+	NamespaceRateLimit = "rateLimit"
+	// RateLimitNamespace is the limit shared by all requests to a namespace
+	RateLimitNamespace = "namespace"
+	// RateLimitIdentity is the limit applied to the requests of each identity within a namespace
+	RateLimitIdentity = "identity"
+	// RateLimitRequestsPerSecond is the sustained rate of requests allowed, with zero meaning unlimited
+	RateLimitRequestsPerSecond = "requestsPerSecond"
+	// RateLimitBurst is the number of requests that can be made above the sustained rate, after a quiet period
+	RateLimitBurst = "burst"
+	// RateLimitMaxConcurrent is the maximum number of requests that can be in flight at once, with zero meaning unlimited
+	RateLimitMaxConcurrent = "maxConcurrent"
+	// NamespaceRetention is the retention policy of a namespace, with the maximum age of each type of record
+	NamespaceRetention = "retention"
+	// RetentionEvents is the maximum age of delivered events
+	RetentionEvents = "events"
+	// RetentionMessages is the maximum age of confirmed and rejected messages
+	RetentionMessages = "messages"
+	// RetentionData is the maximum age of data
+	RetentionData = "data"
+	// RetentionOperations is the maximum age of succeeded and failed operations
+	RetentionOperations = "operations"
+	// RetentionBlockchainEvents is the maximum age of blockchain events
+	RetentionBlockchainEvents = "blockchainEvents"
+	// RetentionTokenTransfers is the maximum age of token transfers
+	RetentionTokenTransfers = "tokenTransfers"
 )
 
 // The following keys can be access from the root configuration.
@@ -92,6 +118,18 @@ var (
 	APIOASPanicOnMissingDescription = ffc("api.oas.panicOnMissingDescription")
 	// APIPassThroughHeaders is a list of HTTP request headers to pass through to requests made to dependency microservices
 	APIPassthroughHeaders = ffc("api.passthroughHeaders")
+	// APIRateLimitNamespaceRequestsPerSecond is the default sustained rate of requests allowed to each namespace
+	APIRateLimitNamespaceRequestsPerSecond = ffc("api.rateLimit.namespace.requestsPerSecond")
+	// APIRateLimitNamespaceBurst is the default burst of requests allowed to each namespace
+	APIRateLimitNamespaceBurst = ffc("api.rateLimit.namespace.burst")
+	// APIRateLimitNamespaceMaxConcurrent is the default maximum number of concurrent requests to each namespace
+	APIRateLimitNamespaceMaxConcurrent = ffc("api.rateLimit.namespace.maxConcurrent")
+	// APIRateLimitIdentityRequestsPerSecond is the default sustained rate of requests allowed for each identity in a namespace
+	APIRateLimitIdentityRequestsPerSecond = ffc("api.rateLimit.identity.requestsPerSecond")
+	// APIRateLimitIdentityBurst is the default burst of requests allowed for each identity in a namespace
+	APIRateLimitIdentityBurst = ffc("api.rateLimit.identity.burst")
+	// APIRateLimitIdentityMaxConcurrent is the default maximum number of concurrent requests for each identity in a namespace
+	APIRateLimitIdentityMaxConcurrent = ffc("api.rateLimit.identity.maxConcurrent")
 	// BatchManagerReadPageSize is the size of each page of messages read from the database into memory when assembling batches
 	BatchManagerReadPageSize = ffc("batch.manager.readPageSize")
 	// BatchManagerReadPollTimeout is how long without any notifications of new messages to wait, before doing a page query
@@ -249,6 +287,8 @@ var (
 	EventAggregatorRewindQueueLength = ffc("event.aggregator.rewindQueueLength")
 	// EventAggregatorRewindQueryLimit safety limit on the maximum number of records to search when performing queries to search for rewinds
 	EventAggregatorRewindQueryLimit = ffc("event.aggregator.rewindQueryLimit")
+	// EventAggregatorRewindRetention how long rewinds forwarded from other replicas to the leader are kept in the database
+	EventAggregatorRewindRetention = ffc("event.aggregator.rewindRetention")
 	// EventAggregatorRetryFactor the backoff factor to use for retry of database operations
 	EventAggregatorRetryFactor = ffc("event.aggregator.retry.factor")
 	// EventAggregatorRetryInitDelay the initial delay to use for retry of data base operations
@@ -341,6 +381,46 @@ var (
 	SubscriptionsRetryFactor = ffc("subscription.retry.factor")
 	// SubscriptionMaxHistoricalEventScanLength the maximum amount of historical events we scan for in the DB when indexing through old events against a subscription
 	SubscriptionMaxHistoricalEventScanLength = ffc("subscription.events.maxScanLength")
+	// SyncAsyncCoordinatorType selects how the sync/async bridge shares in-flight requests between replicas - "local" or "database"
+	SyncAsyncCoordinatorType = ffc("syncasync.coordinator.type")
+	// SyncAsyncCoordinatorPollInterval how often to check the database for notifications from other replicas, when they cannot be pushed
+	SyncAsyncCoordinatorPollInterval = ffc("syncasync.coordinator.pollInterval")
+	// SyncAsyncCoordinatorRetention how long notifications between replicas are retained in the database
+	SyncAsyncCoordinatorRetention = ffc("syncasync.coordinator.retention")
+	// RetentionInterval is how often the retention policy of each namespace is applied
+	RetentionInterval = ffc("retention.interval")
+	// RetentionBatchSize is the number of records archived and deleted in each database transaction
+	RetentionBatchSize = ffc("retention.batchSize")
+	// RetentionArchiveDirectory is the directory that records are archived to before they are deleted
+	RetentionArchiveDirectory = ffc("retention.archiveDirectory")
+	// ContractListenerHealthInterval is how often the health of each contract listener is checked
+	ContractListenerHealthInterval = ffc("contracts.listenerHealth.interval")
+	// ContractListenerHealthLagBlocks is the number of blocks a contract listener can be behind, before it is reported as behind
+	ContractListenerHealthLagBlocks = ffc("contracts.listenerHealth.lagBlocks")
+	// ContractListenerHealthLagTime is how far behind in block time a contract listener can be, before it is reported as behind
+	ContractListenerHealthLagTime = ffc("contracts.listenerHealth.lagTime")
+	// LeaderElectionEnabled elects one replica to run the singleton background workers of each namespace
+	LeaderElectionEnabled = ffc("leaderElection.enabled")
+	// LeaderElectionLeaseDuration is how long the leader lease is held for, before it must be renewed
+	LeaderElectionLeaseDuration = ffc("leaderElection.leaseDuration")
+	// LeaderElectionRenewInterval is how often the leader renews its lease, and the other replicas try to acquire it
+	LeaderElectionRenewInterval = ffc("leaderElection.renewInterval")
+	// LeaderElectionReplicaName is the identity of this replica in the lease
+	LeaderElectionReplicaName = ffc("leaderElection.replicaName")
+	// TracingEnabled determines whether OpenTelemetry spans are exported
+	TracingEnabled = ffc("tracing.enabled")
+	// TracingServiceName is the service name recorded on every span exported by this node
+	TracingServiceName = ffc("tracing.serviceName")
+	// TracingSampleRatio is the fraction of new traces that are sampled, between 0 and 1
+	TracingSampleRatio = ffc("tracing.sampleRatio")
+	// TracingOTLPProtocol is the OTLP transport used to export spans - "http" or "grpc"
+	TracingOTLPProtocol = ffc("tracing.otlp.protocol")
+	// TracingOTLPEndpoint is the URL of the OTLP collector
+	TracingOTLPEndpoint = ffc("tracing.otlp.endpoint")
+	// TracingOTLPHeaders are additional headers sent to the OTLP collector, such as for authentication
+	TracingOTLPHeaders = ffc("tracing.otlp.headers")
+	// TracingOTLPTimeout is the maximum time to wait for the OTLP collector to accept a batch of spans
+	TracingOTLPTimeout = ffc("tracing.otlp.timeout")
 	// TransactionWriterCount
 	TransactionWriterCount = ffc("transaction.writer.count")
 	// TransactionWriterBatchTimeout
@@ -365,6 +445,12 @@ func setDefaults() {
 	viper.SetDefault(string(APIMaxFilterSkip), 1000) // protects database (skip+limit pagination is not for bulk operations)
 	viper.SetDefault(string(APIRequestTimeout), "120s")
 	viper.SetDefault(string(APIPassthroughHeaders), []string{})
+	viper.SetDefault(string(APIRateLimitNamespaceRequestsPerSecond), 0)
+	viper.SetDefault(string(APIRateLimitNamespaceBurst), 0)
+	viper.SetDefault(string(APIRateLimitNamespaceMaxConcurrent), 0)
+	viper.SetDefault(string(APIRateLimitIdentityRequestsPerSecond), 0)
+	viper.SetDefault(string(APIRateLimitIdentityBurst), 0)
+	viper.SetDefault(string(APIRateLimitIdentityMaxConcurrent), 0)
 	viper.SetDefault(string(AssetManagerKeyNormalization), "blockchain_plugin")
 	viper.SetDefault(string(CacheBatchLimit), 100)
 	viper.SetDefault(string(CacheBatchTTL), "5m")
@@ -462,6 +548,23 @@ func setDefaults() {
 	viper.SetDefault(string(SubscriptionsRetryMaxDelay), "30s")
 	viper.SetDefault(string(SubscriptionsRetryFactor), 2.0)
 	viper.SetDefault(string(SubscriptionMaxHistoricalEventScanLength), 1000)
+	viper.SetDefault(string(SyncAsyncCoordinatorType), "local")
+	viper.SetDefault(string(SyncAsyncCoordinatorPollInterval), "1s")
+	viper.SetDefault(string(SyncAsyncCoordinatorRetention), "5m")
+	viper.SetDefault(string(TracingEnabled), false)
+	viper.SetDefault(string(RetentionInterval), "1h")
+	viper.SetDefault(string(RetentionBatchSize), 1000)
+	viper.SetDefault(string(LeaderElectionEnabled), false)
+	viper.SetDefault(string(ContractListenerHealthInterval), "1m")
+	viper.SetDefault(string(ContractListenerHealthLagBlocks), 0)
+	viper.SetDefault(string(ContractListenerHealthLagTime), "0")
+	viper.SetDefault(string(EventAggregatorRewindRetention), "5m")
+	viper.SetDefault(string(LeaderElectionLeaseDuration), "15s")
+	viper.SetDefault(string(LeaderElectionRenewInterval), "5s")
+	viper.SetDefault(string(TracingServiceName), "firefly")
+	viper.SetDefault(string(TracingSampleRatio), 1.0)
+	viper.SetDefault(string(TracingOTLPProtocol), "http")
+	viper.SetDefault(string(TracingOTLPTimeout), "10s")
 	viper.SetDefault(string(TransactionWriterBatchMaxTransactions), 100)
 	viper.SetDefault(string(TransactionWriterBatchTimeout), "10ms")
 	viper.SetDefault(string(TransactionWriterCount), 5)
@@ -58,14 +58,19 @@ var (
 	APIParamsTokenTransferID                = ffm("api.params.tokenTransferID", "The token transfer ID")
 	APIParamsTransactionID                  = ffm("api.params.transactionID", "The transaction ID")
 	APIParamsVerifierHash                   = ffm("api.params.verifierID", "The hash of the verifier")
+	APIParamsDeadLetterID                   = ffm("api.params.deadLetterID", "The dead-letter entry ID")
+	APIParamsSSEConnectionID                = ffm("api.params.sseConnectionID", "The connection ID sent in the first frame of the event stream")
 	APIParamsMethodPath                     = ffm("api.params.methodPath", "The name or uniquely generated path name of a method on a smart contract")
 	APIParamsEventPath                      = ffm("api.params.eventPath", "The name or uniquely generated path name of a event on a smart contract")
 	APIParamsInterfaceID                    = ffm("api.params.interfaceID", "The contract interface ID")
 	APIParamsValidator                      = ffm("api.params.validator", "The validator type for this data item. Options are: \"json\", \"none\", or \"definition\"")
 	APIParamsMetadata                       = ffm("api.params.metadata", "Metadata associated with this data item")
 	APIParamsAutometa                       = ffm("api.params.autometa", "When set, FireFly will automatically generate JSON metadata with the upload details")
+	APIParamsNamespaceImportDryRun          = ffm("api.params.namespaceImportDryRun", "When set to true, FireFly verifies the archive and reports what would be imported, without writing to the database")
 	APIParamsContractAPIID                  = ffm("api.params.contractAPIID", "The ID of the contract API")
+	APIParamsAPIKeyID                       = ffm("api.params.apiKeyID", "The API key ID")
 	APIParamsFetchStatus                    = ffm("api.params.fetchStatus", "When set, the API will return additional status information if available")
+	APIParamsCursor                         = ffm("api.params.cursor", "Pages through the results by sequence. Set to an empty value for the first page, then to the 'next' token of each page for the following page")
 
 	APIEndpointsAdminGetNamespaceByName = ffm("api.endpoints.adminGetNamespaceByName", "Gets a namespace by name")
 	APIEndpointsAdminGetNamespaces      = ffm("api.endpoints.adminGetNamespaces", "List namespaces")
@@ -76,124 +81,140 @@ var (
 	APIEndpointsAdminGetListenerByID    = ffm("api.endpoints.adminGetListenerByID", "Gets a contract listener by ID")
 	APIEndpointsAdminGetListeners       = ffm("api.endpoints.adminGetListeners", "Lists contract listeners")
 
-	APIEndpointsDeleteContractAPI               = ffm("api.endpoints.deleteContractAPI", "Delete a contract API")
-	APIEndpointsDeleteContractInterface         = ffm("api.endpoints.deleteContractInterface", "Delete a contract interface")
-	APIEndpointsDeleteContractListener          = ffm("api.endpoints.deleteContractListener", "Deletes a contract listener referenced by its name or its ID")
-	APIEndpointsDeleteSubscription              = ffm("api.endpoints.deleteSubscription", "Deletes a subscription")
-	APIEndpointsDeleteTokenPool                 = ffm("api.endpoints.deleteTokenPool", "Delete a token pool")
-	APIEndpointsGetBatchBbyID                   = ffm("api.endpoints.getBatchByID", "Gets a message batch")
-	APIEndpointsGetBatches                      = ffm("api.endpoints.getBatches", "Gets a list of message batches")
-	APIEndpointsGetBlockchainEventByID          = ffm("api.endpoints.getBlockchainEventByID", "Gets a blockchain event")
-	APIEndpointsListBlockchainEvents            = ffm("api.endpoints.getBlockchainEvents", "Gets a list of blockchain events")
-	APIEndpointsGetChartHistogram               = ffm("api.endpoints.getChartHistogram", "Gets a JSON object containing statistics data that can be used to build a graphical representation of recent activity in a given database collection")
-	APIEndpointsGetContractAPIByName            = ffm("api.endpoints.getContractAPIByName", "Gets information about a contract API, including the URLs for the OpenAPI Spec and Swagger UI for the API")
-	APIEndpointsGetContractAPIs                 = ffm("api.endpoints.getContractAPIs", "Gets a list of contract APIs that have been published")
-	APIEndpointsGetContractInterfaceNameVersion = ffm("api.endpoints.getContractInterfaceNameVersion", "Gets a contract interface by its name and version")
-	APIEndpointsGetContractInterface            = ffm("api.endpoints.getContractInterface", "Gets a contract interface by its ID")
-	APIEndpointsGetContractInterfaces           = ffm("api.endpoints.getContractInterfaces", "Gets a list of contract interfaces that have been published")
-	APIEndpointsGetContractListenerByNameOrID   = ffm("api.endpoints.getContractListenerByNameOrID", "Gets a contract listener by its name or ID")
-	APIEndpointsGetContractListeners            = ffm("api.endpoints.getContractListeners", "Gets a list of contract listeners")
-	APIEndpointsGetDataBlob                     = ffm("api.endpoints.getDataBlob", "Downloads the original file that was previously uploaded or received")
-	APIEndpointsGetDataValue                    = ffm("api.endpoints.getDataValue", "Downloads the JSON value of the data resource, without the associated metadata")
-	APIEndpointsGetDataByID                     = ffm("api.endpoints.getDataByID", "Gets a data item by its ID, including metadata about this item")
-	APIEndpointsDeleteData                      = ffm("api.endpoints.deleteData", "Deletes a data item by its ID, including metadata about this item")
-	APIEndpointsGetDataMsgs                     = ffm("api.endpoints.getDataMsgs", "Gets a list of the messages associated with a data item")
-	APIEndpointsGetData                         = ffm("api.endpoints.getData", "Gets a list of data items")
-	APIEndpointsGetDataSubPaths                 = ffm("api.endpoints.getDataSubPaths", "Gets a list of path names of named blob data, underneath a given parent path ('/' path prefixes are automatically pre-prepended)")
-	APIEndpointsGetDatatypeByName               = ffm("api.endpoints.getDatatypeByName", "Gets a datatype by its name and version")
-	APIEndpointsGetDatatypes                    = ffm("api.endpoints.getDatatypes", "Gets a list of datatypes that have been published")
-	APIEndpointsGetEventByID                    = ffm("api.endpoints.eventID", "Gets an event by its ID")
-	APIEndpointsGetEvents                       = ffm("api.endpoints.getEvents", "Gets a list of events")
-	APIEndpointsGetGroupByHash                  = ffm("api.endpoints.getGroupByHash", "Gets a group by its ID (hash)")
-	APIEndpointsGetGroups                       = ffm("api.endpoints.getGroups", "Gets a list of groups")
-	APIEndpointsGetIdentities                   = ffm("api.endpoints.getIdentities", "Gets a list of all identities that have been registered in the namespace")
-	APIEndpointsGetIdentityByID                 = ffm("api.endpoints.getIdentityByID", "Gets an identity by its ID")
-	APIEndpointsGetIdentityDID                  = ffm("api.endpoints.getIdentityDID", "Gets the DID for an identity based on its ID")
-	APIEndpointsGetIdentityVerifiers            = ffm("api.endpoints.getIdentityVerifiers", "Gets the verifiers for an identity")
-	APIEndpointsGetMsgByID                      = ffm("api.endpoints.getMsgByID", "Gets a message by its ID")
-	APIEndpointsGetMsgData                      = ffm("api.endpoints.getMsgData", "Gets the list of data items that are attached to a message")
-	APIEndpointsGetMsgEvents                    = ffm("api.endpoints.getMsgEvents", "Gets the list of events for a message")
-	APIEndpointsGetMsgTxn                       = ffm("api.endpoints.getMsgTxn", "Gets the transaction for a message")
-	APIEndpointsGetMsgs                         = ffm("api.endpoints.getMsgs", "Gets a list of messages")
-	APIEndpointsGetNamespace                    = ffm("api.endpoints.getNamespace", "Gets a namespace")
-	APIEndpointsGetNamespaces                   = ffm("api.endpoints.getNamespaces", "Gets a list of namespaces")
-	APIEndpointsGetNetworkIdentityByDID         = ffm("api.endpoints.getNetworkIdentityByDID", "Gets an identity by its DID (deprecated - use /identities/{did} instead of /network/identities/{did})")
-	APIEndpointsGetIdentityByDID                = ffm("api.endpoints.getIdentityByDID", "Gets an identity by its DID")
-	APIEndpointsGetDIDDocByDID                  = ffm("api.endpoints.getDIDDocByDID", "Gets a DID document by its DID")
-	APIEndpointsGetNetworkIdentities            = ffm("api.endpoints.getNetworkIdentities", "Gets the list of identities in the network (deprecated - use /identities instead of /network/identities")
-	APIEndpointsGetNetworkNode                  = ffm("api.endpoints.getNetworkNode", "Gets information about a specific node in the network")
-	APIEndpointsGetNetworkNodes                 = ffm("api.endpoints.getNetworkNodes", "Gets a list of nodes in the network")
-	APIEndpointsGetNetworkOrg                   = ffm("api.endpoints.getNetworkOrg", "Gets information about a specific org in the network")
-	APIEndpointsGetNetworkOrgs                  = ffm("api.endpoints.APIEndpointsGetNetworkOrgs", "Gets a list of orgs in the network")
-	APIEndpointsGetOpByID                       = ffm("api.endpoints.getOpByID", "Gets an operation by ID")
-	APIEndpointsGetOps                          = ffm("api.endpoints.getOps", "Gets a a list of operations")
-	APIEndpointsGetStatusBatchManager           = ffm("api.endpoints.getStatusBatchManager", "Gets the status of the batch manager")
-	APIEndpointsGetPins                         = ffm("api.endpoints.getPins", "Queries the list of pins received from the blockchain")
-	APIEndpointsGetNextPins                     = ffm("api.endpoints.getNextPins", "Queries the list of next-pins that determine the next masked message sequence for each member of a privacy group, on each context/topic")
-	APIEndpointsGetWebSockets                   = ffm("api.endpoints.getStatusWebSockets", "Gets a list of the current WebSocket connections to this node")
-	APIEndpointsGetStatus                       = ffm("api.endpoints.getStatus", "Gets the status of this namespace")
-	APIEndpointsGetMultipartyStatus             = ffm("api.endpoints.getMultipartyStatus", "Gets the registration status of this organization and node on the configured multiparty network")
-	APIEndpointsGetSubscriptionByID             = ffm("api.endpoints.getSubscriptionByID", "Gets a subscription by its ID")
-	APIEndpointsGetSubscriptionEventsFiltered   = ffm("api.endpoints.getSubscriptionEventsFiltered", "Gets a collection of events filtered by the subscription for further filtering")
-	APIEndpointsGetSubscriptions                = ffm("api.endpoints.getSubscriptions", "Gets a list of subscriptions")
-	APIEndpointsGetTokenAccountPools            = ffm("api.endpoints.getTokenAccountPools", "Gets a list of token pools that contain a given token account key")
-	APIEndpointsGetTokenAccounts                = ffm("api.endpoints.getTokenAccounts", "Gets a list of token accounts")
-	APIEndpointsGetTokenApprovals               = ffm("api.endpoints.getTokenApprovals", "Gets a list of token approvals")
-	APIEndpointsGetTokenBalances                = ffm("api.endpoints.getTokenBalances", "Gets a list of token balances")
-	APIEndpointsGetTokenConnectors              = ffm("api.endpoints.getTokenConnectors", "Gets the list of token connectors currently in use")
-	APIEndpointsGetTokenPoolByNameOrID          = ffm("api.endpoints.getTokenPoolByNameOrID", "Gets a token pool by its name or its ID")
-	APIEndpointsGetTokenPools                   = ffm("api.endpoints.getTokenPools", "Gets a list of token pools")
-	APIEndpointsGetTokenTransferByID            = ffm("api.endpoints.getTokenTransferByID", "Gets a token transfer by its ID")
-	APIEndpointsGetTokenTransfers               = ffm("api.endpoints.getTokenTransfers", "Gets a list of token transfers")
-	APIEndpointsGetTxnBlockchainEvents          = ffm("api.endpoints.getTxnBlockchainEvents", "Gets a list blockchain events for a specific transaction")
-	APIEndpointsGetTxnByID                      = ffm("api.endpoints.getTxnByID", "Gets a transaction by its ID")
-	APIEndpointsGetTxnOps                       = ffm("api.endpoints.getTxnOps", "Gets a list of operations in a specific transaction")
-	APIEndpointsGetTxnStatus                    = ffm("api.endpoints.getTxnStatus", "Gets the status of a transaction")
-	APIEndpointsGetTxns                         = ffm("api.endpoints.getTxns", "Gets a list of transactions")
-	APIEndpointsGetVerifierByHash               = ffm("api.endpoints.getVerifierByHash", "Gets a verifier by its hash")
-	APIEndpointsGetVerifiers                    = ffm("api.endpoints.getVerifiers", "Gets a list of verifiers")
-	APIEndpointsPatchUpdateIdentity             = ffm("api.endpoints.patchUpdateIdentity", "Updates an identity")
-	APIEndpointsPostBatchCancel                 = ffm("api.endpoints.postBatchCancel", "Cancel a batch that has failed to dispatch")
-	APIEndpointsPostContractDeploy              = ffm("api.endpoints.postContractDeploy", "Deploy a new smart contract")
-	APIEndpointsPostContractAPIInvoke           = ffm("api.endpoints.postContractAPIInvoke", "Invokes a method on a smart contract API. Performs a blockchain transaction.")
-	APIEndpointsPostContractAPIPublish          = ffm("api.endpoints.postContractAPIPublish", "Publish a contract API to all other members of the multiparty network")
-	APIEndpointsPostContractAPIQuery            = ffm("api.endpoints.postContractAPIQuery", "Queries a method on a smart contract API. Performs a read-only query.")
-	APIEndpointsPostContractInterfaceGenerate   = ffm("api.endpoints.postContractInterfaceGenerate", "A convenience method to convert a blockchain specific smart contract format into a FireFly Interface format. The specific blockchain plugin in use must support this functionality.")
-	APIEndpointsPostContractInterfaceInvoke     = ffm("api.endpoints.postContractInterfaceInvoke", "Invokes a method on a smart contract that matches a given contract interface. Performs a blockchain transaction.")
-	APIEndpointsPostContractInterfaceQuery      = ffm("api.endpoints.postContractInterfaceQuery", "Queries a method on a smart contract that matches a given contract interface. Performs a read-only query.")
-	APIEndpointsPostContractInterfacePublish    = ffm("api.endpoints.postContractInterfacePublish", "Publish a contract interface to all other members of the multiparty network")
-	APIEndpointsPostContractInvoke              = ffm("api.endpoints.postContractInvoke", "Invokes a method on a smart contract. Performs a blockchain transaction.")
-	APIEndpointsPostContractQuery               = ffm("api.endpoints.postContractQuery", "Queries a method on a smart contract. Performs a read-only query.")
-	APIEndpointsPostData                        = ffm("api.endpoints.postData", "Creates a new data item in this FireFly node")
-	APIEndpointsPostDataValuePublish            = ffm("api.endpoints.postDataValuePublish", "Publishes the JSON value from the specified data resource, to shared storage")
-	APIEndpointsPostDataBlobPublish             = ffm("api.endpoints.postDataBlobPublish", "Publishes the binary blob attachment stored in your local data exchange, to shared storage")
-	APIEndpointsPostNewContractAPI              = ffm("api.endpoints.postNewContractAPI", "Creates and broadcasts a new custom smart contract API")
-	APIEndpointsPostNewContractInterface        = ffm("api.endpoints.postNewContractInterface", "Creates and broadcasts a new custom smart contract interface")
-	APIEndpointsPostNewContractListener         = ffm("api.endpoints.postNewContractListener", "Creates a new blockchain listener for events emitted by custom smart contracts")
-	APIEndpointsPostContractListenerHash        = ffm("api.endpoints.postContractListenerHash", "Calculates the hash of a blockchain listener filters and events")
-	APIEndpointsPostNewDatatype                 = ffm("api.endpoints.postNewDatatype", "Creates and broadcasts a new datatype")
-	APIEndpointsPostNewIdentity                 = ffm("api.endpoints.postNewIdentity", "Registers a new identity in the network")
-	APIEndpointsPostNewMessageBroadcast         = ffm("api.endpoints.postNewMessageBroadcast", "Broadcasts a message to all members in the network")
-	APIEndpointsPostNewMessagePrivate           = ffm("api.endpoints.postNewMessagePrivate", "Privately sends a message to one or more members in the network")
-	APIEndpointsPostNewMessageRequestReply      = ffm("api.endpoints.postNewMessageRequestReply", "Sends a message with a blocking HTTP request, waits for a reply to that message, then sends the reply as the HTTP response.")
-	APIEndpointsPostNewNamespace                = ffm("api.endpoints.postNewNamespace", "Creates and broadcasts a new namespace")
-	APIEndpointsPostNodesSelf                   = ffm("api.endpoints.postNodesSelf", "Instructs this FireFly node to register itself on the network")
-	APIEndpointsPostNewOrganizationSelf         = ffm("api.endpoints.postNewOrganizationSelf", "Instructs this FireFly node to register its org on the network")
-	APIEndpointsPostNewOrganization             = ffm("api.endpoints.postNewOrganization", "Registers a new org in the network")
-	APIEndpointsPostNewSubscription             = ffm("api.endpoints.postNewSubscription", "Creates a new subscription for an application to receive events from FireFly")
-	APIEndpointsPostOpRetry                     = ffm("api.endpoints.postOpRetry", "Retries a failed operation")
-	APIEndpointsPostPinsRewind                  = ffm("api.endpoints.postPinsRewind", "Force a rewind of the event aggregator to a previous position, to re-evaluate (and possibly dispatch) that pin and others after it. Only accepts a sequence or batch ID for a currently undispatched pin")
-	APIEndpointsPostTokenApproval               = ffm("api.endpoints.postTokenApproval", "Creates a token approval")
-	APIEndpointsPostTokenBurn                   = ffm("api.endpoints.postTokenBurn", "Burns some tokens")
-	APIEndpointsPostTokenMint                   = ffm("api.endpoints.postTokenMint", "Mints some tokens")
-	APIEndpointsPostTokenPool                   = ffm("api.endpoints.postTokenPool", "Creates a new token pool")
-	APIEndpointsPostTokenPoolPublish            = ffm("api.endpoints.postTokenPoolPublish", "Publish a token pool to all other members of the multiparty network")
-	APIEndpointsPostTokenTransfer               = ffm("api.endpoints.postTokenTransfer", "Transfers some tokens")
-	APIEndpointsPutContractAPI                  = ffm("api.endpoints.putContractAPI", "Updates an existing contract API")
-	APIEndpointsPutSubscription                 = ffm("api.endpoints.putSubscription", "Update an existing subscription")
-	APIEndpointsGetContractAPIInterface         = ffm("api.endpoints.getContractAPIInterface", "Gets a contract interface for a contract API")
-	APIEndpointsPostNetworkAction               = ffm("api.endpoints.postNetworkAction", "Notify all nodes in the network of a new governance action")
-	APIEndpointsPostVerifiersResolve            = ffm("api.endpoints.postVerifiersResolve", "Resolves an input key to a signing key")
+	APIEndpointsDeleteAPIKey                     = ffm("api.endpoints.deleteAPIKey", "Deletes an API key")
+	APIEndpointsDeleteContractAPI                = ffm("api.endpoints.deleteContractAPI", "Delete a contract API")
+	APIEndpointsDeleteContractInterface          = ffm("api.endpoints.deleteContractInterface", "Delete a contract interface")
+	APIEndpointsDeleteContractListener           = ffm("api.endpoints.deleteContractListener", "Deletes a contract listener referenced by its name or its ID")
+	APIEndpointsDeleteSubscription               = ffm("api.endpoints.deleteSubscription", "Deletes a subscription")
+	APIEndpointsDeleteTokenPool                  = ffm("api.endpoints.deleteTokenPool", "Delete a token pool")
+	APIEndpointsGetAPIKeyByID                    = ffm("api.endpoints.getAPIKeyByID", "Gets an API key by ID")
+	APIEndpointsGetAPIKeys                       = ffm("api.endpoints.getAPIKeys", "Gets a list of API keys")
+	APIEndpointsGetBatchBbyID                    = ffm("api.endpoints.getBatchByID", "Gets a message batch")
+	APIEndpointsGetBatches                       = ffm("api.endpoints.getBatches", "Gets a list of message batches")
+	APIEndpointsGetBlockchainEventByID           = ffm("api.endpoints.getBlockchainEventByID", "Gets a blockchain event")
+	APIEndpointsListBlockchainEvents             = ffm("api.endpoints.getBlockchainEvents", "Gets a list of blockchain events")
+	APIEndpointsGetChartHistogram                = ffm("api.endpoints.getChartHistogram", "Gets a JSON object containing statistics data that can be used to build a graphical representation of recent activity in a given database collection")
+	APIEndpointsPostChartAggregate               = ffm("api.endpoints.postChartAggregate", "Gets a time series of aggregations over the records of a database collection, grouped by fields of the collection")
+	APIEndpointsGetContractAPIByName             = ffm("api.endpoints.getContractAPIByName", "Gets information about a contract API, including the URLs for the OpenAPI Spec and Swagger UI for the API")
+	APIEndpointsGetContractAPIs                  = ffm("api.endpoints.getContractAPIs", "Gets a list of contract APIs that have been published")
+	APIEndpointsGetContractInterfaceNameVersion  = ffm("api.endpoints.getContractInterfaceNameVersion", "Gets a contract interface by its name and version")
+	APIEndpointsGetContractInterface             = ffm("api.endpoints.getContractInterface", "Gets a contract interface by its ID")
+	APIEndpointsGetContractInterfaces            = ffm("api.endpoints.getContractInterfaces", "Gets a list of contract interfaces that have been published")
+	APIEndpointsGetContractListenerBackfills     = ffm("api.endpoints.getContractListenerBackfills", "Gets the backfills that have been requested for a contract listener, and their progress. Backfills are reported until an hour after they finish")
+	APIEndpointsGetContractListenerByNameOrID    = ffm("api.endpoints.getContractListenerByNameOrID", "Gets a contract listener by its name or ID")
+	APIEndpointsGetContractListeners             = ffm("api.endpoints.getContractListeners", "Gets a list of contract listeners")
+	APIEndpointsGetDataBlob                      = ffm("api.endpoints.getDataBlob", "Downloads the original file that was previously uploaded or received")
+	APIEndpointsGetDataValue                     = ffm("api.endpoints.getDataValue", "Downloads the JSON value of the data resource, without the associated metadata")
+	APIEndpointsGetDataByID                      = ffm("api.endpoints.getDataByID", "Gets a data item by its ID, including metadata about this item")
+	APIEndpointsDeleteData                       = ffm("api.endpoints.deleteData", "Deletes a data item by its ID, including metadata about this item")
+	APIEndpointsGetDataMsgs                      = ffm("api.endpoints.getDataMsgs", "Gets a list of the messages associated with a data item")
+	APIEndpointsGetData                          = ffm("api.endpoints.getData", "Gets a list of data items")
+	APIEndpointsGetDataSubPaths                  = ffm("api.endpoints.getDataSubPaths", "Gets a list of path names of named blob data, underneath a given parent path ('/' path prefixes are automatically pre-prepended)")
+	APIEndpointsGetDatatypeByName                = ffm("api.endpoints.getDatatypeByName", "Gets a datatype by its name and version")
+	APIEndpointsGetDatatypes                     = ffm("api.endpoints.getDatatypes", "Gets a list of datatypes that have been published")
+	APIEndpointsGetEventByID                     = ffm("api.endpoints.eventID", "Gets an event by its ID")
+	APIEndpointsGetEvents                        = ffm("api.endpoints.getEvents", "Gets a list of events")
+	APIEndpointsGetGroupByHash                   = ffm("api.endpoints.getGroupByHash", "Gets a group by its ID (hash)")
+	APIEndpointsGetGroups                        = ffm("api.endpoints.getGroups", "Gets a list of groups")
+	APIEndpointsGetIdentities                    = ffm("api.endpoints.getIdentities", "Gets a list of all identities that have been registered in the namespace")
+	APIEndpointsGetIdentityByID                  = ffm("api.endpoints.getIdentityByID", "Gets an identity by its ID")
+	APIEndpointsGetIdentityDID                   = ffm("api.endpoints.getIdentityDID", "Gets the DID for an identity based on its ID")
+	APIEndpointsGetIdentityVerifiers             = ffm("api.endpoints.getIdentityVerifiers", "Gets the verifiers for an identity")
+	APIEndpointsGetMsgByID                       = ffm("api.endpoints.getMsgByID", "Gets a message by its ID")
+	APIEndpointsGetMsgData                       = ffm("api.endpoints.getMsgData", "Gets the list of data items that are attached to a message")
+	APIEndpointsGetMsgEvents                     = ffm("api.endpoints.getMsgEvents", "Gets the list of events for a message")
+	APIEndpointsGetMsgTxn                        = ffm("api.endpoints.getMsgTxn", "Gets the transaction for a message")
+	APIEndpointsGetMsgs                          = ffm("api.endpoints.getMsgs", "Gets a list of messages")
+	APIEndpointsGetNamespace                     = ffm("api.endpoints.getNamespace", "Gets a namespace")
+	APIEndpointsGetNamespaces                    = ffm("api.endpoints.getNamespaces", "Gets a list of namespaces")
+	APIEndpointsGetNetworkIdentityByDID          = ffm("api.endpoints.getNetworkIdentityByDID", "Gets an identity by its DID (deprecated - use /identities/{did} instead of /network/identities/{did})")
+	APIEndpointsGetIdentityByDID                 = ffm("api.endpoints.getIdentityByDID", "Gets an identity by its DID")
+	APIEndpointsGetDIDDocByDID                   = ffm("api.endpoints.getDIDDocByDID", "Gets a DID document by its DID")
+	APIEndpointsGetNetworkIdentities             = ffm("api.endpoints.getNetworkIdentities", "Gets the list of identities in the network (deprecated - use /identities instead of /network/identities")
+	APIEndpointsGetNetworkNode                   = ffm("api.endpoints.getNetworkNode", "Gets information about a specific node in the network")
+	APIEndpointsGetNetworkNodes                  = ffm("api.endpoints.getNetworkNodes", "Gets a list of nodes in the network")
+	APIEndpointsGetNetworkOrg                    = ffm("api.endpoints.getNetworkOrg", "Gets information about a specific org in the network")
+	APIEndpointsGetNetworkOrgs                   = ffm("api.endpoints.APIEndpointsGetNetworkOrgs", "Gets a list of orgs in the network")
+	APIEndpointsGetOpByID                        = ffm("api.endpoints.getOpByID", "Gets an operation by ID")
+	APIEndpointsGetOps                           = ffm("api.endpoints.getOps", "Gets a a list of operations")
+	APIEndpointsGetStatusBatchManager            = ffm("api.endpoints.getStatusBatchManager", "Gets the status of the batch manager")
+	APIEndpointsGetPins                          = ffm("api.endpoints.getPins", "Queries the list of pins received from the blockchain")
+	APIEndpointsGetNextPins                      = ffm("api.endpoints.getNextPins", "Queries the list of next-pins that determine the next masked message sequence for each member of a privacy group, on each context/topic")
+	APIEndpointsGetWebSockets                    = ffm("api.endpoints.getStatusWebSockets", "Gets a list of the current WebSocket connections to this node")
+	APIEndpointsSPIGetStatusLeader               = ffm("api.endpoints.spiGetStatusLeader", "Gets the leader election status of this namespace, as seen by this replica")
+	APIEndpointsSPIGetNamespaceExport            = ffm("api.endpoints.spiGetNamespaceExport", "Exports the definitions in the namespace, such as datatypes, interfaces, listeners, subscriptions, identities and groups, as a gzip compressed archive")
+	APIEndpointsSPIPostNamespaceImport           = ffm("api.endpoints.spiPostNamespaceImport", "Imports a namespace archive into the namespace. Records that are already in the namespace are skipped")
+	APIEndpointsGetStatus                        = ffm("api.endpoints.getStatus", "Gets the status of this namespace")
+	APIEndpointsGetMultipartyStatus              = ffm("api.endpoints.getMultipartyStatus", "Gets the registration status of this organization and node on the configured multiparty network")
+	APIEndpointsGetSubscriptionByID              = ffm("api.endpoints.getSubscriptionByID", "Gets a subscription by its ID")
+	APIEndpointsGetSubscriptionEventsFiltered    = ffm("api.endpoints.getSubscriptionEventsFiltered", "Gets a collection of events filtered by the subscription for further filtering")
+	APIEndpointsGetSubscriptions                 = ffm("api.endpoints.getSubscriptions", "Gets a list of subscriptions")
+	APIEndpointsGetTokenAccountPools             = ffm("api.endpoints.getTokenAccountPools", "Gets a list of token pools that contain a given token account key")
+	APIEndpointsGetTokenAccounts                 = ffm("api.endpoints.getTokenAccounts", "Gets a list of token accounts")
+	APIEndpointsGetTokenApprovals                = ffm("api.endpoints.getTokenApprovals", "Gets a list of token approvals")
+	APIEndpointsGetTokenBalances                 = ffm("api.endpoints.getTokenBalances", "Gets a list of token balances")
+	APIEndpointsGetTokenConnectors               = ffm("api.endpoints.getTokenConnectors", "Gets the list of token connectors currently in use")
+	APIEndpointsGetTokenPoolByNameOrID           = ffm("api.endpoints.getTokenPoolByNameOrID", "Gets a token pool by its name or its ID")
+	APIEndpointsGetTokenPools                    = ffm("api.endpoints.getTokenPools", "Gets a list of token pools")
+	APIEndpointsGetTokenTransferByID             = ffm("api.endpoints.getTokenTransferByID", "Gets a token transfer by its ID")
+	APIEndpointsGetTokenTransfers                = ffm("api.endpoints.getTokenTransfers", "Gets a list of token transfers")
+	APIEndpointsGetTxnBlockchainEvents           = ffm("api.endpoints.getTxnBlockchainEvents", "Gets a list blockchain events for a specific transaction")
+	APIEndpointsGetTxnByID                       = ffm("api.endpoints.getTxnByID", "Gets a transaction by its ID")
+	APIEndpointsGetTxnOps                        = ffm("api.endpoints.getTxnOps", "Gets a list of operations in a specific transaction")
+	APIEndpointsGetTxnStatus                     = ffm("api.endpoints.getTxnStatus", "Gets the status of a transaction")
+	APIEndpointsGetTxns                          = ffm("api.endpoints.getTxns", "Gets a list of transactions")
+	APIEndpointsGetVerifierByHash                = ffm("api.endpoints.getVerifierByHash", "Gets a verifier by its hash")
+	APIEndpointsGetVerifiers                     = ffm("api.endpoints.getVerifiers", "Gets a list of verifiers")
+	APIEndpointsPatchUpdateIdentity              = ffm("api.endpoints.patchUpdateIdentity", "Updates an identity")
+	APIEndpointsPostAPIKey                       = ffm("api.endpoints.postAPIKey", "Creates an API key, returning the key itself just once in the response")
+	APIEndpointsPostBatchCancel                  = ffm("api.endpoints.postBatchCancel", "Cancel a batch that has failed to dispatch")
+	APIEndpointsPostContractDeploy               = ffm("api.endpoints.postContractDeploy", "Deploy a new smart contract")
+	APIEndpointsPostContractAPIInvoke            = ffm("api.endpoints.postContractAPIInvoke", "Invokes a method on a smart contract API. Performs a blockchain transaction.")
+	APIEndpointsPostContractAPIPublish           = ffm("api.endpoints.postContractAPIPublish", "Publish a contract API to all other members of the multiparty network")
+	APIEndpointsPostContractAPIQuery             = ffm("api.endpoints.postContractAPIQuery", "Queries a method on a smart contract API. Performs a read-only query.")
+	APIEndpointsPostContractListenerBackfill     = ffm("api.endpoints.postContractListenerBackfill", "Re-delivers the events matching a contract listener from a range of blocks. Events that have already been recorded for the listener are not delivered again. Requires a blockchain connector that reports the progress of listeners")
+	APIEndpointsPostContractInterfaceGenerate    = ffm("api.endpoints.postContractInterfaceGenerate", "A convenience method to convert a blockchain specific smart contract format into a FireFly Interface format. The specific blockchain plugin in use must support this functionality.")
+	APIEndpointsPostContractInterfaceInvoke      = ffm("api.endpoints.postContractInterfaceInvoke", "Invokes a method on a smart contract that matches a given contract interface. Performs a blockchain transaction.")
+	APIEndpointsPostContractInterfaceQuery       = ffm("api.endpoints.postContractInterfaceQuery", "Queries a method on a smart contract that matches a given contract interface. Performs a read-only query.")
+	APIEndpointsPostContractInterfacePublish     = ffm("api.endpoints.postContractInterfacePublish", "Publish a contract interface to all other members of the multiparty network")
+	APIEndpointsPostContractInvoke               = ffm("api.endpoints.postContractInvoke", "Invokes a method on a smart contract. Performs a blockchain transaction.")
+	APIEndpointsPostContractQuery                = ffm("api.endpoints.postContractQuery", "Queries a method on a smart contract. Performs a read-only query.")
+	APIEndpointsPostData                         = ffm("api.endpoints.postData", "Creates a new data item in this FireFly node")
+	APIEndpointsPostDataValuePublish             = ffm("api.endpoints.postDataValuePublish", "Publishes the JSON value from the specified data resource, to shared storage")
+	APIEndpointsPostDataBlobPublish              = ffm("api.endpoints.postDataBlobPublish", "Publishes the binary blob attachment stored in your local data exchange, to shared storage")
+	APIEndpointsPostNewContractAPI               = ffm("api.endpoints.postNewContractAPI", "Creates and broadcasts a new custom smart contract API")
+	APIEndpointsPostNewContractInterface         = ffm("api.endpoints.postNewContractInterface", "Creates and broadcasts a new custom smart contract interface")
+	APIEndpointsPostNewContractListener          = ffm("api.endpoints.postNewContractListener", "Creates a new blockchain listener for events emitted by custom smart contracts")
+	APIEndpointsPostContractListenerHash         = ffm("api.endpoints.postContractListenerHash", "Calculates the hash of a blockchain listener filters and events")
+	APIEndpointsPostNewDatatype                  = ffm("api.endpoints.postNewDatatype", "Creates and broadcasts a new datatype")
+	APIEndpointsPostNewIdentity                  = ffm("api.endpoints.postNewIdentity", "Registers a new identity in the network")
+	APIEndpointsPostNewMessageBroadcast          = ffm("api.endpoints.postNewMessageBroadcast", "Broadcasts a message to all members in the network")
+	APIEndpointsPostNewMessagePrivate            = ffm("api.endpoints.postNewMessagePrivate", "Privately sends a message to one or more members in the network")
+	APIEndpointsPostNewMessageRequestReply       = ffm("api.endpoints.postNewMessageRequestReply", "Sends a message with a blocking HTTP request, waits for a reply to that message, then sends the reply as the HTTP response.")
+	APIEndpointsPostNewNamespace                 = ffm("api.endpoints.postNewNamespace", "Creates and broadcasts a new namespace")
+	APIEndpointsPostNodesSelf                    = ffm("api.endpoints.postNodesSelf", "Instructs this FireFly node to register itself on the network")
+	APIEndpointsPostNewOrganizationSelf          = ffm("api.endpoints.postNewOrganizationSelf", "Instructs this FireFly node to register its org on the network")
+	APIEndpointsPostNewOrganization              = ffm("api.endpoints.postNewOrganization", "Registers a new org in the network")
+	APIEndpointsPostNewSubscription              = ffm("api.endpoints.postNewSubscription", "Creates a new subscription for an application to receive events from FireFly")
+	APIEndpointsPostOpRetry                      = ffm("api.endpoints.postOpRetry", "Retries a failed operation")
+	APIEndpointsPostRetentionPreview             = ffm("api.endpoints.postRetentionPreview", "Previews the number of records of each type a retention policy would archive and delete now. The configured retention policy of the namespace is used if the body is empty")
+	APIEndpointsPostPinsRewind                   = ffm("api.endpoints.postPinsRewind", "Force a rewind of the event aggregator to a previous position, to re-evaluate (and possibly dispatch) that pin and others after it. Only accepts a sequence or batch ID for a currently undispatched pin")
+	APIEndpointsGetSubscriptionDeadLetters       = ffm("api.endpoints.getSubscriptionDeadLetters", "Gets the events in the dead-letter queue of a subscription, which could not be delivered within the maximum number of attempts")
+	APIEndpointsGetSubscriptionDeadLetterByID    = ffm("api.endpoints.getSubscriptionDeadLetterByID", "Gets an entry in the dead-letter queue of a subscription")
+	APIEndpointsPostSubscriptionDeadLetterReplay = ffm("api.endpoints.postSubscriptionDeadLetterReplay", "Replays a dead-lettered event to the subscription. The entry is removed from the dead-letter queue when the event is acknowledged")
+	APIEndpointsDeleteSubscriptionDeadLetter     = ffm("api.endpoints.deleteSubscriptionDeadLetter", "Discards an entry from the dead-letter queue of a subscription")
+	APIEndpointsPostSSEAck                       = ffm("api.endpoints.postSSEAck", "Acknowledges an event or batch delivered on a Server-Sent Events stream, when autoack is not enabled")
+	APIEndpointsPostTokenApproval                = ffm("api.endpoints.postTokenApproval", "Creates a token approval")
+	APIEndpointsPostTokenBurn                    = ffm("api.endpoints.postTokenBurn", "Burns some tokens")
+	APIEndpointsPostTokenMint                    = ffm("api.endpoints.postTokenMint", "Mints some tokens")
+	APIEndpointsPostTokenPool                    = ffm("api.endpoints.postTokenPool", "Creates a new token pool")
+	APIEndpointsPostTokenPoolPublish             = ffm("api.endpoints.postTokenPoolPublish", "Publish a token pool to all other members of the multiparty network")
+	APIEndpointsPostTokenTransfer                = ffm("api.endpoints.postTokenTransfer", "Transfers some tokens")
+	APIEndpointsPutContractAPI                   = ffm("api.endpoints.putContractAPI", "Updates an existing contract API")
+	APIEndpointsPutSubscription                  = ffm("api.endpoints.putSubscription", "Update an existing subscription")
+	APIEndpointsGetContractAPIInterface          = ffm("api.endpoints.getContractAPIInterface", "Gets a contract interface for a contract API")
+	APIEndpointsPostNetworkAction                = ffm("api.endpoints.postNetworkAction", "Notify all nodes in the network of a new governance action")
+	APIEndpointsPostVerifiersResolve             = ffm("api.endpoints.postVerifiersResolve", "Resolves an input key to a signing key")
 
 	APIFilterParamDesc         = ffm("api.filterParam", "Data filter field. Prefixes supported: > >= < <= @ ^ ! !@ !^")
 	APIFilterSortDesc          = ffm("api.filterSort", "Sort field. For multi-field sort use comma separated values (or multiple query values) with '-' prefix for descending")
@@ -216,4 +237,5 @@ var (
 
 	APISubscriptionStartSequenceID = ffm("api.startsequenceid", "The sequence ID in the raw event stream to start indexing through events from. Leave blank to start indexing from the most recent events")
 	APISubscriptionEndSequenceID   = ffm("api.endsequenceid", "The sequence ID in the raw event stream to stop indexing through events at. Leave blank to start indexing from the most recent events")
+	APISubscriptionExpression      = ffm("api.subscriptionexpression", "A CEL expression to apply to the events, in addition to any filters (including any expression) on the subscription itself")
 )
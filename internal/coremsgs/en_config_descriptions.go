@@ -53,6 +53,13 @@ var (
 	ConfigAPIRequestMaxTimeout  = ffc("config.api.requestMaxTimeout", "The maximum amount of time that an HTTP client can specify in a `Request-Timeout` header to keep a specific request open", i18n.TimeDurationType)
 	ConfigAPIPassthroughHeaders = ffc("config.api.passthroughHeaders", "A list of HTTP request headers to pass through to dependency microservices", i18n.ArrayStringType)
 
+	ConfigAPIRateLimitNamespaceRequestsPerSecond = ffc("config.api.rateLimit.namespace.requestsPerSecond", "The sustained rate of API requests allowed to each namespace, shared by all callers. Zero means unlimited", i18n.FloatType)
+	ConfigAPIRateLimitNamespaceBurst             = ffc("config.api.rateLimit.namespace.burst", "The number of API requests that can be made to each namespace in a burst above the sustained rate. Defaults to the sustained rate per second", i18n.IntType)
+	ConfigAPIRateLimitNamespaceMaxConcurrent     = ffc("config.api.rateLimit.namespace.maxConcurrent", "The maximum number of API requests that can be in flight at once for each namespace. Zero means unlimited", i18n.IntType)
+	ConfigAPIRateLimitIdentityRequestsPerSecond  = ffc("config.api.rateLimit.identity.requestsPerSecond", "The sustained rate of API requests allowed for each calling identity within a namespace. Zero means unlimited", i18n.FloatType)
+	ConfigAPIRateLimitIdentityBurst              = ffc("config.api.rateLimit.identity.burst", "The number of API requests each calling identity can make in a burst above the sustained rate. Defaults to the sustained rate per second", i18n.IntType)
+	ConfigAPIRateLimitIdentityMaxConcurrent      = ffc("config.api.rateLimit.identity.maxConcurrent", "The maximum number of API requests that can be in flight at once for each calling identity within a namespace. Zero means unlimited", i18n.IntType)
+
 	ConfigAssetManagerKeyNormalization = ffc("config.asset.manager.keyNormalization", "Mechanism to normalize keys before using them. Valid options are `blockchain_plugin` - use blockchain plugin (default) or `none` - do not attempt normalization (deprecated - use namespaces.predefined[].asset.manager.keyNormalization)", i18n.StringType)
 
 	ConfigBatchManagerMinimumPollDelay = ffc("config.batch.manager.minimumPollDelay", "The minimum time the batch manager waits between polls on the DB - to prevent thrashing", i18n.TimeDurationType)
@@ -139,17 +146,51 @@ var (
 	ConfigPluginDatabaseName = ffc("config.plugins.database[].name", "The name of the Database plugin", i18n.StringType)
 	ConfigPluginDatabaseType = ffc("config.plugins.database[].type", "The type of the configured Database plugin", i18n.StringType)
 
-	ConfigPluginDatabasePostgresMaxConnIdleTime = ffc("config.plugins.database[].postgres.maxConnIdleTime", "The maximum amount of time a database connection can be idle", i18n.TimeDurationType)
-	ConfigPluginDatabasePostgresMaxConnLifetime = ffc("config.plugins.database[].postgres.maxConnLifetime", "The maximum amount of time to keep a database connection open", i18n.TimeDurationType)
-	ConfigPluginDatabasePostgresMaxConns        = ffc("config.plugins.database[].postgres.maxConns", "Maximum connections to the database", i18n.IntType)
-	ConfigPluginDatabasePostgresMaxIdleConns    = ffc("config.plugins.database[].postgres.maxIdleConns", "The maximum number of idle connections to the database", i18n.IntType)
-	ConfigPluginDatabasePostgresURL             = ffc("config.plugins.database[].postgres.url", "The PostgreSQL connection string for the database", i18n.StringType)
-
-	ConfigPluginDatabaseSqlite3MaxConnIdleTime = ffc("config.plugins.database[].sqlite3.maxConnIdleTime", "The maximum amount of time a database connection can be idle", i18n.TimeDurationType)
-	ConfigPluginDatabaseSqlite3MaxConnLifetime = ffc("config.plugins.database[].sqlite3.maxConnLifetime", "The maximum amount of time to keep a database connection open", i18n.TimeDurationType)
-	ConfigPluginDatabaseSqlite3MaxConns        = ffc("config.plugins.database[].sqlite3.maxConns", "Maximum connections to the database", i18n.IntType)
-	ConfigPluginDatabaseSqlite3MaxIdleConns    = ffc("config.plugins.database[].sqlite3.maxIdleConns", "The maximum number of idle connections to the database", i18n.IntType)
-	ConfigPluginDatabaseSqlite3URL             = ffc("config.plugins.database[].sqlite3.url", "The SQLite connection string for the database", i18n.StringType)
+	ConfigPluginDatabaseMySQLEncryptionFilePath         = ffc("config.plugins.database[].mysql.encryption.file.path", "The path of a YAML or JSON file with the key encryption keys of the file key provider, as a map of base64 encoded 32 byte keys by ID under keys, and the ID of the key that wraps new data keys under current", i18n.StringType)
+	ConfigPluginDatabaseMySQLEncryptionKeyProvider      = ffc("config.plugins.database[].mysql.encryption.keyProvider", "The type of key provider that holds the keys that wrap the data keys, when data values and operation inputs and outputs are encrypted at rest. Supported: file. Values are not encrypted when not set", i18n.StringType)
+	ConfigPluginDatabaseMySQLJSONPathMaxConditions      = ffc("config.plugins.database[].mysql.jsonPath.maxConditions", "The maximum number of JSON-path conditions on data values in one query", i18n.IntType)
+	ConfigPluginDatabaseMySQLJSONPathMaxDepth           = ffc("config.plugins.database[].mysql.jsonPath.maxDepth", "The maximum number of keys and array indexes in the path of a JSON-path condition", i18n.IntType)
+	ConfigPluginDatabaseMySQLMaxConnIdleTime            = ffc("config.plugins.database[].mysql.maxConnIdleTime", "The maximum amount of time a database connection can be idle", i18n.TimeDurationType)
+	ConfigPluginDatabaseMySQLMaxConnLifetime            = ffc("config.plugins.database[].mysql.maxConnLifetime", "The maximum amount of time to keep a database connection open", i18n.TimeDurationType)
+	ConfigPluginDatabaseMySQLMaxConns                   = ffc("config.plugins.database[].mysql.maxConns", "Maximum connections to the database", i18n.IntType)
+	ConfigPluginDatabaseMySQLMaxIdleConns               = ffc("config.plugins.database[].mysql.maxIdleConns", "The maximum number of idle connections to the database", i18n.IntType)
+	ConfigPluginDatabaseMySQLReadReplicaMaxConnIdleTime = ffc("config.plugins.database[].mysql.readReplica.maxConnIdleTime", "The maximum amount of time a connection to the read replica can be idle", i18n.TimeDurationType)
+	ConfigPluginDatabaseMySQLReadReplicaMaxConnLifetime = ffc("config.plugins.database[].mysql.readReplica.maxConnLifetime", "The maximum amount of time to keep a connection to the read replica open", i18n.TimeDurationType)
+	ConfigPluginDatabaseMySQLReadReplicaMaxConns        = ffc("config.plugins.database[].mysql.readReplica.maxConns", "Maximum connections to the read replica", i18n.IntType)
+	ConfigPluginDatabaseMySQLReadReplicaMaxIdleConns    = ffc("config.plugins.database[].mysql.readReplica.maxIdleConns", "The maximum number of idle connections to the read replica", i18n.IntType)
+	ConfigPluginDatabaseMySQLReadReplicaURL             = ffc("config.plugins.database[].mysql.readReplica.url", "The MySQL data source name for a read-only replica of the database, in the same format as the url. When set, API queries that tolerate results that are slightly behind are served by the replica", i18n.StringType)
+	ConfigPluginDatabaseMySQLURL                        = ffc("config.plugins.database[].mysql.url", "The MySQL data source name for the database, such as user:password@tcp(host:3306)/firefly. ANSI_QUOTES is added to the SQL mode of each connection", i18n.StringType)
+
+	ConfigPluginDatabasePostgresChangeFeedEnabled          = ffc("config.plugins.database[].postgres.changeFeed.enabled", "Uses PostgreSQL LISTEN/NOTIFY to tell other FireFly replicas sharing the database about new messages, events and pins as soon as they are written, rather than waiting for them to poll", i18n.BooleanType)
+	ConfigPluginDatabasePostgresEncryptionFilePath         = ffc("config.plugins.database[].postgres.encryption.file.path", "The path of a YAML or JSON file with the key encryption keys of the file key provider, as a map of base64 encoded 32 byte keys by ID under keys, and the ID of the key that wraps new data keys under current", i18n.StringType)
+	ConfigPluginDatabasePostgresEncryptionKeyProvider      = ffc("config.plugins.database[].postgres.encryption.keyProvider", "The type of key provider that holds the keys that wrap the data keys, when data values and operation inputs and outputs are encrypted at rest. Supported: file. Values are not encrypted when not set", i18n.StringType)
+	ConfigPluginDatabasePostgresJSONPathMaxConditions      = ffc("config.plugins.database[].postgres.jsonPath.maxConditions", "The maximum number of JSON-path conditions on data values in one query", i18n.IntType)
+	ConfigPluginDatabasePostgresJSONPathMaxDepth           = ffc("config.plugins.database[].postgres.jsonPath.maxDepth", "The maximum number of keys and array indexes in the path of a JSON-path condition", i18n.IntType)
+	ConfigPluginDatabasePostgresMaxConnIdleTime            = ffc("config.plugins.database[].postgres.maxConnIdleTime", "The maximum amount of time a database connection can be idle", i18n.TimeDurationType)
+	ConfigPluginDatabasePostgresMaxConnLifetime            = ffc("config.plugins.database[].postgres.maxConnLifetime", "The maximum amount of time to keep a database connection open", i18n.TimeDurationType)
+	ConfigPluginDatabasePostgresMaxConns                   = ffc("config.plugins.database[].postgres.maxConns", "Maximum connections to the database", i18n.IntType)
+	ConfigPluginDatabasePostgresMaxIdleConns               = ffc("config.plugins.database[].postgres.maxIdleConns", "The maximum number of idle connections to the database", i18n.IntType)
+	ConfigPluginDatabasePostgresURL                        = ffc("config.plugins.database[].postgres.url", "The PostgreSQL connection string for the database", i18n.StringType)
+	ConfigPluginDatabasePostgresReadReplicaMaxConnIdleTime = ffc("config.plugins.database[].postgres.readReplica.maxConnIdleTime", "The maximum amount of time a connection to the read replica can be idle", i18n.TimeDurationType)
+	ConfigPluginDatabasePostgresReadReplicaMaxConnLifetime = ffc("config.plugins.database[].postgres.readReplica.maxConnLifetime", "The maximum amount of time to keep a connection to the read replica open", i18n.TimeDurationType)
+	ConfigPluginDatabasePostgresReadReplicaMaxConns        = ffc("config.plugins.database[].postgres.readReplica.maxConns", "Maximum connections to the read replica", i18n.IntType)
+	ConfigPluginDatabasePostgresReadReplicaMaxIdleConns    = ffc("config.plugins.database[].postgres.readReplica.maxIdleConns", "The maximum number of idle connections to the read replica", i18n.IntType)
+	ConfigPluginDatabasePostgresReadReplicaURL             = ffc("config.plugins.database[].postgres.readReplica.url", "The PostgreSQL connection string for a read-only replica of the database. When set, API queries that tolerate results that are slightly behind are served by the replica", i18n.StringType)
+
+	ConfigPluginDatabaseSqlite3EncryptionFilePath         = ffc("config.plugins.database[].sqlite3.encryption.file.path", "The path of a YAML or JSON file with the key encryption keys of the file key provider, as a map of base64 encoded 32 byte keys by ID under keys, and the ID of the key that wraps new data keys under current", i18n.StringType)
+	ConfigPluginDatabaseSqlite3EncryptionKeyProvider      = ffc("config.plugins.database[].sqlite3.encryption.keyProvider", "The type of key provider that holds the keys that wrap the data keys, when data values and operation inputs and outputs are encrypted at rest. Supported: file. Values are not encrypted when not set", i18n.StringType)
+	ConfigPluginDatabaseSqlite3JSONPathMaxConditions      = ffc("config.plugins.database[].sqlite3.jsonPath.maxConditions", "The maximum number of JSON-path conditions on data values in one query", i18n.IntType)
+	ConfigPluginDatabaseSqlite3JSONPathMaxDepth           = ffc("config.plugins.database[].sqlite3.jsonPath.maxDepth", "The maximum number of keys and array indexes in the path of a JSON-path condition", i18n.IntType)
+	ConfigPluginDatabaseSqlite3MaxConnIdleTime            = ffc("config.plugins.database[].sqlite3.maxConnIdleTime", "The maximum amount of time a database connection can be idle", i18n.TimeDurationType)
+	ConfigPluginDatabaseSqlite3MaxConnLifetime            = ffc("config.plugins.database[].sqlite3.maxConnLifetime", "The maximum amount of time to keep a database connection open", i18n.TimeDurationType)
+	ConfigPluginDatabaseSqlite3MaxConns                   = ffc("config.plugins.database[].sqlite3.maxConns", "Maximum connections to the database", i18n.IntType)
+	ConfigPluginDatabaseSqlite3MaxIdleConns               = ffc("config.plugins.database[].sqlite3.maxIdleConns", "The maximum number of idle connections to the database", i18n.IntType)
+	ConfigPluginDatabaseSqlite3ReadReplicaMaxConnIdleTime = ffc("config.plugins.database[].sqlite3.readReplica.maxConnIdleTime", "The maximum amount of time a connection to the read replica can be idle", i18n.TimeDurationType)
+	ConfigPluginDatabaseSqlite3ReadReplicaMaxConnLifetime = ffc("config.plugins.database[].sqlite3.readReplica.maxConnLifetime", "The maximum amount of time to keep a connection to the read replica open", i18n.TimeDurationType)
+	ConfigPluginDatabaseSqlite3ReadReplicaMaxConns        = ffc("config.plugins.database[].sqlite3.readReplica.maxConns", "Maximum connections to the read replica", i18n.IntType)
+	ConfigPluginDatabaseSqlite3ReadReplicaMaxIdleConns    = ffc("config.plugins.database[].sqlite3.readReplica.maxIdleConns", "The maximum number of idle connections to the read replica", i18n.IntType)
+	ConfigPluginDatabaseSqlite3ReadReplicaURL             = ffc("config.plugins.database[].sqlite3.readReplica.url", "The SQLite connection string for a read-only replica of the database. When set, API queries that tolerate results that are slightly behind are served by the replica", i18n.StringType)
+	ConfigPluginDatabaseSqlite3URL                        = ffc("config.plugins.database[].sqlite3.url", "The SQLite connection string for the database", i18n.StringType)
 
 	ConfigPluginBlockchain     = ffc("config.plugins.blockchain", "The list of configured Blockchain plugins", i18n.StringType)
 	ConfigPluginBlockchainName = ffc("config.plugins.blockchain[].name", "The name of the configured Blockchain plugin", i18n.StringType)
@@ -188,6 +229,29 @@ var (
 	ConfigPluginBlockchainEthereumFFTMURL      = ffc("config.plugins.blockchain[].ethereum.fftm.url", "The URL of the FireFly Transaction Manager runtime, if enabled", i18n.StringType)
 	ConfigPluginBlockchainEthereumFFTMProxyURL = ffc("config.plugins.blockchain[].ethereum.fftm.proxy.url", "Optional HTTP proxy server to use when connecting to the Transaction Manager", i18n.StringType)
 
+	ConfigPluginBlockchainEthRPCChainID             = ffc("config.plugins.blockchain[].ethrpc.chainId", "The chain ID to use when signing transactions. When not set, it is queried from the node with eth_chainId", i18n.IntType)
+	ConfigPluginBlockchainEthRPCConfirmations       = ffc("config.plugins.blockchain[].ethrpc.confirmations", "The number of blocks that must be mined on top of a block before its events are delivered, unless a contract listener sets its own", i18n.IntType)
+	ConfigPluginBlockchainEthRPCGasEstimationFactor = ffc("config.plugins.blockchain[].ethrpc.gasEstimationFactor", "The factor applied to the result of eth_estimateGas to set the gas limit of a transaction, when no gas limit is supplied", i18n.FloatType)
+	ConfigPluginBlockchainEthRPCMaxBlockRange       = ffc("config.plugins.blockchain[].ethrpc.maxBlockRange", "The maximum number of blocks to query in a single eth_getLogs call", i18n.IntType)
+	ConfigPluginBlockchainEthRPCPollingInterval     = ffc("config.plugins.blockchain[].ethrpc.pollingInterval", "How often to poll the node for new blocks, events and transaction receipts", i18n.TimeDurationType)
+	ConfigPluginBlockchainEthRPCURL                 = ffc("config.plugins.blockchain[].ethrpc.rpc.url", "The URL of the JSON-RPC endpoint of the Ethereum node", urlStringType)
+	ConfigPluginBlockchainEthRPCProxyURL            = ffc("config.plugins.blockchain[].ethrpc.rpc.proxy.url", "Optional HTTP proxy server to use when connecting to the Ethereum node", urlStringType)
+
+	ConfigPluginBlockchainEthRPCKeystorePath                         = ffc("config.plugins.blockchain[].ethrpc.keystore.path", "The directory containing the keystore V3 files of the signing keys", i18n.StringType)
+	ConfigPluginBlockchainEthRPCKeystoreDefaultPasswordFile          = ffc("config.plugins.blockchain[].ethrpc.keystore.defaultPasswordFile", "The password file to use for keys that have no password file of their own", i18n.StringType)
+	ConfigPluginBlockchainEthRPCKeystoreDisableListener              = ffc("config.plugins.blockchain[].ethrpc.keystore.disableListener", "Disable watching the keystore directory for new keys", i18n.BooleanType)
+	ConfigPluginBlockchainEthRPCKeystoreSignerCacheSize              = ffc("config.plugins.blockchain[].ethrpc.keystore.signerCacheSize", "The maximum number of decrypted signing keys to hold in memory", i18n.StringType)
+	ConfigPluginBlockchainEthRPCKeystoreSignerCacheTTL               = ffc("config.plugins.blockchain[].ethrpc.keystore.signerCacheTTL", "How long to hold an unused decrypted signing key in memory", i18n.TimeDurationType)
+	ConfigPluginBlockchainEthRPCKeystoreFilenamesPrimaryExt          = ffc("config.plugins.blockchain[].ethrpc.keystore.filenames.primaryExt", "The extension appended to the address to find the keystore file of a key", i18n.StringType)
+	ConfigPluginBlockchainEthRPCKeystoreFilenamesPrimaryMatchRegex   = ffc("config.plugins.blockchain[].ethrpc.keystore.filenames.primaryMatchRegex", "A regular expression with a capture group that extracts the address from the name of a keystore file. Takes precedence over primaryExt", i18n.StringType)
+	ConfigPluginBlockchainEthRPCKeystoreFilenamesPasswordExt         = ffc("config.plugins.blockchain[].ethrpc.keystore.filenames.passwordExt", "The extension appended to the address to find the password file of a key", i18n.StringType)
+	ConfigPluginBlockchainEthRPCKeystoreFilenamesPasswordPath        = ffc("config.plugins.blockchain[].ethrpc.keystore.filenames.passwordPath", "The directory containing the password files, when it is not the keystore directory", i18n.StringType)
+	ConfigPluginBlockchainEthRPCKeystoreFilenamesPasswordTrimSpace   = ffc("config.plugins.blockchain[].ethrpc.keystore.filenames.passwordTrimSpace", "Whether to trim whitespace, such as a trailing newline, from passwords read from files", i18n.BooleanType)
+	ConfigPluginBlockchainEthRPCKeystoreFilenamesWith0xPrefix        = ffc("config.plugins.blockchain[].ethrpc.keystore.filenames.with0xPrefix", "Whether the address in the name of a password file has a 0x prefix", i18n.BooleanType)
+	ConfigPluginBlockchainEthRPCKeystoreMetadataFormat               = ffc("config.plugins.blockchain[].ethrpc.keystore.metadata.format", "The format of metadata files that point to the keystore and password files of a key - auto, filename, toml, yaml or json", i18n.StringType)
+	ConfigPluginBlockchainEthRPCKeystoreMetadataKeyFileProperty      = ffc("config.plugins.blockchain[].ethrpc.keystore.metadata.keyFileProperty", "A Go template that extracts the name of the keystore file from a metadata file", i18n.GoTemplateType)
+	ConfigPluginBlockchainEthRPCKeystoreMetadataPasswordFileProperty = ffc("config.plugins.blockchain[].ethrpc.keystore.metadata.passwordFileProperty", "A Go template that extracts the name of the password file from a metadata file", i18n.GoTemplateType)
+
 	ConfigPluginBlockchainTezosAddressResolverAlwaysResolve = ffc("config.plugins.blockchain[].tezos.addressResolver.alwaysResolve", "Causes the address resolver to be invoked on every API call that submits a signing key. Also disables any result caching", i18n.BooleanType)
 
 	ConfigPluginBlockchainTezosAddressResolverResponseField  = ffc("config.plugins.blockchain[].tezos.addressResolver.responseField", "The name of a JSON field that is provided in the response, that contains the tezos address (default `address`)", i18n.StringType)
@@ -231,17 +295,45 @@ var (
 
 	ConfigDatabaseType = ffc("config.database.type", "The type of the database interface plugin to use", i18n.IntType)
 
-	ConfigDatabasePostgresMaxConnIdleTime = ffc("config.database.postgres.maxConnIdleTime", "The maximum amount of time a database connection can be idle", i18n.TimeDurationType)
-	ConfigDatabasePostgresMaxConnLifetime = ffc("config.database.postgres.maxConnLifetime", "The maximum amount of time to keep a database connection open", i18n.TimeDurationType)
-	ConfigDatabasePostgresMaxConns        = ffc("config.database.postgres.maxConns", "Maximum connections to the database", i18n.IntType)
-	ConfigDatabasePostgresMaxIdleConns    = ffc("config.database.postgres.maxIdleConns", "The maximum number of idle connections to the database", i18n.IntType)
-	ConfigDatabasePostgresURL             = ffc("config.database.postgres.url", "The PostgreSQL connection string for the database", i18n.StringType)
-
-	ConfigDatabaseSqlite3MaxConnIdleTime = ffc("config.database.sqlite3.maxConnIdleTime", "The maximum amount of time a database connection can be idle", i18n.TimeDurationType)
-	ConfigDatabaseSqlite3MaxConnLifetime = ffc("config.database.sqlite3.maxConnLifetime", "The maximum amount of time to keep a database connection open", i18n.TimeDurationType)
-	ConfigDatabaseSqlite3MaxConns        = ffc("config.database.sqlite3.maxConns", "Maximum connections to the database", i18n.IntType)
-	ConfigDatabaseSqlite3MaxIdleConns    = ffc("config.database.sqlite3.maxIdleConns", "The maximum number of idle connections to the database", i18n.IntType)
-	ConfigDatabaseSqlite3URL             = ffc("config.database.sqlite3.url", "The SQLite connection string for the database", i18n.StringType)
+	ConfigDatabaseMySQLJSONPathMaxConditions      = ffc("config.database.mysql.jsonPath.maxConditions", "The maximum number of JSON-path conditions on data values in one query", i18n.IntType)
+	ConfigDatabaseMySQLJSONPathMaxDepth           = ffc("config.database.mysql.jsonPath.maxDepth", "The maximum number of keys and array indexes in the path of a JSON-path condition", i18n.IntType)
+	ConfigDatabaseMySQLMaxConnIdleTime            = ffc("config.database.mysql.maxConnIdleTime", "The maximum amount of time a database connection can be idle", i18n.TimeDurationType)
+	ConfigDatabaseMySQLMaxConnLifetime            = ffc("config.database.mysql.maxConnLifetime", "The maximum amount of time to keep a database connection open", i18n.TimeDurationType)
+	ConfigDatabaseMySQLMaxConns                   = ffc("config.database.mysql.maxConns", "Maximum connections to the database", i18n.IntType)
+	ConfigDatabaseMySQLMaxIdleConns               = ffc("config.database.mysql.maxIdleConns", "The maximum number of idle connections to the database", i18n.IntType)
+	ConfigDatabaseMySQLReadReplicaMaxConnIdleTime = ffc("config.database.mysql.readReplica.maxConnIdleTime", "The maximum amount of time a connection to the read replica can be idle", i18n.TimeDurationType)
+	ConfigDatabaseMySQLReadReplicaMaxConnLifetime = ffc("config.database.mysql.readReplica.maxConnLifetime", "The maximum amount of time to keep a connection to the read replica open", i18n.TimeDurationType)
+	ConfigDatabaseMySQLReadReplicaMaxConns        = ffc("config.database.mysql.readReplica.maxConns", "Maximum connections to the read replica", i18n.IntType)
+	ConfigDatabaseMySQLReadReplicaMaxIdleConns    = ffc("config.database.mysql.readReplica.maxIdleConns", "The maximum number of idle connections to the read replica", i18n.IntType)
+	ConfigDatabaseMySQLReadReplicaURL             = ffc("config.database.mysql.readReplica.url", "The MySQL data source name for a read-only replica of the database, in the same format as the url. When set, API queries that tolerate results that are slightly behind are served by the replica", i18n.StringType)
+	ConfigDatabaseMySQLURL                        = ffc("config.database.mysql.url", "The MySQL data source name for the database, such as user:password@tcp(host:3306)/firefly. ANSI_QUOTES is added to the SQL mode of each connection", i18n.StringType)
+
+	ConfigDatabasePostgresChangeFeedEnabled          = ffc("config.database.postgres.changeFeed.enabled", "Uses PostgreSQL LISTEN/NOTIFY to tell other FireFly replicas sharing the database about new messages, events and pins as soon as they are written, rather than waiting for them to poll", i18n.BooleanType)
+	ConfigDatabasePostgresJSONPathMaxConditions      = ffc("config.database.postgres.jsonPath.maxConditions", "The maximum number of JSON-path conditions on data values in one query", i18n.IntType)
+	ConfigDatabasePostgresJSONPathMaxDepth           = ffc("config.database.postgres.jsonPath.maxDepth", "The maximum number of keys and array indexes in the path of a JSON-path condition", i18n.IntType)
+	ConfigDatabasePostgresMaxConnIdleTime            = ffc("config.database.postgres.maxConnIdleTime", "The maximum amount of time a database connection can be idle", i18n.TimeDurationType)
+	ConfigDatabasePostgresMaxConnLifetime            = ffc("config.database.postgres.maxConnLifetime", "The maximum amount of time to keep a database connection open", i18n.TimeDurationType)
+	ConfigDatabasePostgresMaxConns                   = ffc("config.database.postgres.maxConns", "Maximum connections to the database", i18n.IntType)
+	ConfigDatabasePostgresMaxIdleConns               = ffc("config.database.postgres.maxIdleConns", "The maximum number of idle connections to the database", i18n.IntType)
+	ConfigDatabasePostgresURL                        = ffc("config.database.postgres.url", "The PostgreSQL connection string for the database", i18n.StringType)
+	ConfigDatabasePostgresReadReplicaMaxConnIdleTime = ffc("config.database.postgres.readReplica.maxConnIdleTime", "The maximum amount of time a connection to the read replica can be idle", i18n.TimeDurationType)
+	ConfigDatabasePostgresReadReplicaMaxConnLifetime = ffc("config.database.postgres.readReplica.maxConnLifetime", "The maximum amount of time to keep a connection to the read replica open", i18n.TimeDurationType)
+	ConfigDatabasePostgresReadReplicaMaxConns        = ffc("config.database.postgres.readReplica.maxConns", "Maximum connections to the read replica", i18n.IntType)
+	ConfigDatabasePostgresReadReplicaMaxIdleConns    = ffc("config.database.postgres.readReplica.maxIdleConns", "The maximum number of idle connections to the read replica", i18n.IntType)
+	ConfigDatabasePostgresReadReplicaURL             = ffc("config.database.postgres.readReplica.url", "The PostgreSQL connection string for a read-only replica of the database. When set, API queries that tolerate results that are slightly behind are served by the replica", i18n.StringType)
+
+	ConfigDatabaseSqlite3JSONPathMaxConditions      = ffc("config.database.sqlite3.jsonPath.maxConditions", "The maximum number of JSON-path conditions on data values in one query", i18n.IntType)
+	ConfigDatabaseSqlite3JSONPathMaxDepth           = ffc("config.database.sqlite3.jsonPath.maxDepth", "The maximum number of keys and array indexes in the path of a JSON-path condition", i18n.IntType)
+	ConfigDatabaseSqlite3MaxConnIdleTime            = ffc("config.database.sqlite3.maxConnIdleTime", "The maximum amount of time a database connection can be idle", i18n.TimeDurationType)
+	ConfigDatabaseSqlite3MaxConnLifetime            = ffc("config.database.sqlite3.maxConnLifetime", "The maximum amount of time to keep a database connection open", i18n.TimeDurationType)
+	ConfigDatabaseSqlite3MaxConns                   = ffc("config.database.sqlite3.maxConns", "Maximum connections to the database", i18n.IntType)
+	ConfigDatabaseSqlite3MaxIdleConns               = ffc("config.database.sqlite3.maxIdleConns", "The maximum number of idle connections to the database", i18n.IntType)
+	ConfigDatabaseSqlite3ReadReplicaMaxConnIdleTime = ffc("config.database.sqlite3.readReplica.maxConnIdleTime", "The maximum amount of time a connection to the read replica can be idle", i18n.TimeDurationType)
+	ConfigDatabaseSqlite3ReadReplicaMaxConnLifetime = ffc("config.database.sqlite3.readReplica.maxConnLifetime", "The maximum amount of time to keep a connection to the read replica open", i18n.TimeDurationType)
+	ConfigDatabaseSqlite3ReadReplicaMaxConns        = ffc("config.database.sqlite3.readReplica.maxConns", "Maximum connections to the read replica", i18n.IntType)
+	ConfigDatabaseSqlite3ReadReplicaMaxIdleConns    = ffc("config.database.sqlite3.readReplica.maxIdleConns", "The maximum number of idle connections to the read replica", i18n.IntType)
+	ConfigDatabaseSqlite3ReadReplicaURL             = ffc("config.database.sqlite3.readReplica.url", "The SQLite connection string for a read-only replica of the database. When set, API queries that tolerate results that are slightly behind are served by the replica", i18n.StringType)
+	ConfigDatabaseSqlite3URL                        = ffc("config.database.sqlite3.url", "The SQLite connection string for the database", i18n.StringType)
 
 	ConfigDataexchangeType = ffc("config.dataexchange.type", "The Data Exchange plugin to use", i18n.StringType)
 
@@ -277,6 +369,7 @@ var (
 	ConfigEventAggregatorPollTimeout       = ffc("config.event.aggregator.pollTimeout", "The time to wait without a notification of new events, before trying a select on the table", i18n.TimeDurationType)
 	ConfigEventAggregatorRewindQueueLength = ffc("config.event.aggregator.rewindQueueLength", "The size of the queue into the rewind dispatcher", i18n.IntType)
 	ConfigEventAggregatorRewindTimout      = ffc("config.event.aggregator.rewindTimeout", "The minimum time to wait for rewinds to accumulate before resolving them", i18n.TimeDurationType)
+	ConfigEventAggregatorRewindRetention   = ffc("config.event.aggregator.rewindRetention", "How long rewinds forwarded from other replicas to the leader are kept in the database, when leader election is enabled. A newly elected leader replays the rewinds from this period", i18n.TimeDurationType)
 	ConfigEventAggregatorRewindQueryLimit  = ffc("config.event.aggregator.rewindQueryLimit", "Safety limit on the maximum number of records to search when performing queries to search for rewinds", i18n.IntType)
 	ConfigEventDbeventsBufferSize          = ffc("config.event.dbevents.bufferSize", "The size of the buffer of change events", i18n.ByteSizeType)
 
@@ -316,6 +409,26 @@ var (
 	ConfigMessageWriterBatchTimeout    = ffc("config.message.writer.batchTimeout", "How long to wait for more messages to arrive before flushing the batch", i18n.TimeDurationType)
 	ConfigMessageWriterCount           = ffc("config.message.writer.count", "The number of message writer workers", i18n.IntType)
 
+	ConfigRetentionInterval           = ffc("config.retention.interval", "How often the retention policy of each namespace is applied", i18n.TimeDurationType)
+	ConfigRetentionBatchSize          = ffc("config.retention.batchSize", "The number of records that are archived and deleted together, in a single database transaction", i18n.IntType)
+	ConfigLeaderElectionEnabled       = ffc("config.leaderElection.enabled", "Elects a single replica, of those sharing the database, to run the background workers of each namespace. Must be enabled to run more than one replica", i18n.BooleanType)
+	ConfigLeaderElectionLeaseDuration = ffc("config.leaderElection.leaseDuration", "How long the leader holds its lease for. Another replica takes over this long after the leader stops renewing it", i18n.TimeDurationType)
+	ConfigLeaderElectionRenewInterval = ffc("config.leaderElection.renewInterval", "How often the leader renews its lease, and the other replicas try to acquire it. Must be less than the lease duration", i18n.TimeDurationType)
+	ConfigLeaderElectionReplicaName   = ffc("config.leaderElection.replicaName", "The name of this replica in the leader lease. Defaults to the hostname, with a unique suffix", i18n.StringType)
+	ConfigRetentionArchiveDirectory   = ffc("config.retention.archiveDirectory", "A directory to write gzip compressed NDJSON archives of records to, before they are deleted. Records are deleted without an archive if this is not set", i18n.StringType)
+
+	ConfigContractsListenerHealthInterval  = ffc("config.contracts.listenerHealth.interval", "How often the health of each contract listener is checked, to update metrics and to detect listeners that have fallen behind. Only checked when metrics are enabled, or a threshold is set", i18n.TimeDurationType)
+	ConfigContractsListenerHealthLagBlocks = ffc("config.contracts.listenerHealth.lagBlocks", "A contract_listener_behind event is emitted when a contract listener is more than this many confirmed blocks behind the head of the chain. Zero disables the check", i18n.IntType)
+	ConfigContractsListenerHealthLagTime   = ffc("config.contracts.listenerHealth.lagTime", "A contract_listener_behind event is emitted when a contract listener has been behind the newest confirmed block for more than this. Zero disables the check", i18n.TimeDurationType)
+
+	ConfigTracingEnabled      = ffc("config.tracing.enabled", "Enables the export of OpenTelemetry spans for API requests, batches, operations and events", i18n.BooleanType)
+	ConfigTracingServiceName  = ffc("config.tracing.serviceName", "The service name recorded on every span exported by this node", i18n.StringType)
+	ConfigTracingSampleRatio  = ffc("config.tracing.sampleRatio", "The fraction of new traces to sample, between 0 and 1. Traces continued from a caller follow the sampling decision of the caller", i18n.FloatType)
+	ConfigTracingOTLPProtocol = ffc("config.tracing.otlp.protocol", "The OTLP transport used to export spans - 'http' or 'grpc'", i18n.StringType)
+	ConfigTracingOTLPEndpoint = ffc("config.tracing.otlp.endpoint", "The URL of the OTLP collector. Use an 'http' scheme to connect without TLS. Defaults to the OTEL_EXPORTER_OTLP_ENDPOINT environment variable, or a collector on localhost", urlStringType)
+	ConfigTracingOTLPHeaders  = ffc("config.tracing.otlp.headers", "Additional headers to send to the OTLP collector, such as for authentication", i18n.MapStringStringType)
+	ConfigTracingOTLPTimeout  = ffc("config.tracing.otlp.timeout", "The maximum time to wait for the OTLP collector to accept a batch of spans", i18n.TimeDurationType)
+
 	ConfigTransactionWriterBatchMaxTransactions = ffc("config.transaction.writer.batchMaxTransactions", "The maximum number of transaction inserts to include in a batch", i18n.IntType)
 	ConfigTransactionWriterBatchTimeout         = ffc("config.transaction.writer.batchTimeout", "How long to wait for more transactions to arrive before flushing the batch", i18n.TimeDurationType)
 	ConfigTransactionWriterCount                = ffc("config.transaction.writer.count", "The number of message writer workers", i18n.IntType)
@@ -350,6 +463,20 @@ var (
 	ConfigNamespacesMultipartyContractLocation   = ffc("config.namespaces.predefined[].multiparty.contract[].location", "A blockchain-specific contract location. For example, an Ethereum contract address, or a Fabric chaincode name and channel", i18n.StringType)
 	ConfigNamespacesMultipartyContractOptions    = ffc("config.namespaces.predefined[].multiparty.contract[].options", "Blockchain-specific contract options", i18n.StringType)
 
+	ConfigNamespacesRateLimitNamespaceRequestsPerSecond = ffc("config.namespaces.predefined[].rateLimit.namespace.requestsPerSecond", "Overrides api.rateLimit.namespace.requestsPerSecond for this namespace", i18n.FloatType)
+	ConfigNamespacesRateLimitNamespaceBurst             = ffc("config.namespaces.predefined[].rateLimit.namespace.burst", "Overrides api.rateLimit.namespace.burst for this namespace", i18n.IntType)
+	ConfigNamespacesRateLimitNamespaceMaxConcurrent     = ffc("config.namespaces.predefined[].rateLimit.namespace.maxConcurrent", "Overrides api.rateLimit.namespace.maxConcurrent for this namespace", i18n.IntType)
+	ConfigNamespacesRateLimitIdentityRequestsPerSecond  = ffc("config.namespaces.predefined[].rateLimit.identity.requestsPerSecond", "Overrides api.rateLimit.identity.requestsPerSecond for this namespace", i18n.FloatType)
+	ConfigNamespacesRateLimitIdentityBurst              = ffc("config.namespaces.predefined[].rateLimit.identity.burst", "Overrides api.rateLimit.identity.burst for this namespace", i18n.IntType)
+	ConfigNamespacesRateLimitIdentityMaxConcurrent      = ffc("config.namespaces.predefined[].rateLimit.identity.maxConcurrent", "Overrides api.rateLimit.identity.maxConcurrent for this namespace", i18n.IntType)
+
+	ConfigNamespacesRetentionEvents           = ffc("config.namespaces.predefined[].retention.events", "The maximum age of events in this namespace. Events are only deleted once they have been delivered to every subscription", i18n.TimeDurationType)
+	ConfigNamespacesRetentionMessages         = ffc("config.namespaces.predefined[].retention.messages", "The maximum age of confirmed and rejected messages in this namespace", i18n.TimeDurationType)
+	ConfigNamespacesRetentionData             = ffc("config.namespaces.predefined[].retention.data", "The maximum age of data in this namespace", i18n.TimeDurationType)
+	ConfigNamespacesRetentionOperations       = ffc("config.namespaces.predefined[].retention.operations", "The maximum age of succeeded and failed operations in this namespace", i18n.TimeDurationType)
+	ConfigNamespacesRetentionBlockchainEvents = ffc("config.namespaces.predefined[].retention.blockchainEvents", "The maximum age of blockchain events in this namespace", i18n.TimeDurationType)
+	ConfigNamespacesRetentionTokenTransfers   = ffc("config.namespaces.predefined[].retention.tokenTransfers", "The maximum age of token transfers in this namespace", i18n.TimeDurationType)
+
 	ConfigNodeDescription = ffc("config.node.description", "The description of this FireFly node", i18n.StringType)
 	ConfigNodeName        = ffc("config.node.name", "The name of this FireFly node", i18n.StringType)
 
@@ -388,6 +515,10 @@ var (
 	ConfigSubscriptionDefaultsBatchTimeout         = ffc("config.subscription.defaults.batchTimeout", "Default batch timeout", i18n.IntType)
 	ConfigSubscriptionMaxHistoricalEventScanLength = ffc("config.subscription.events.maxScanLength", "The maximum number of events a search for historical events matching a subscription will index from the database", i18n.IntType)
 
+	ConfigSyncAsyncCoordinatorType         = ffc("config.syncasync.coordinator.type", "How requests waiting for confirmation are coordinated between FireFly core replicas. 'local' for a single replica, or 'database' to resolve requests confirmed on any replica sharing the same database", i18n.StringType)
+	ConfigSyncAsyncCoordinatorPollInterval = ffc("config.syncasync.coordinator.pollInterval", "How often to check the database for notifications from other replicas. Used as a fallback when the database cannot push notifications (such as SQLite)", i18n.TimeDurationType)
+	ConfigSyncAsyncCoordinatorRetention    = ffc("config.syncasync.coordinator.retention", "How long notifications between replicas are retained in the database before being deleted", i18n.TimeDurationType)
+
 	ConfigTokensName     = ffc("config.tokens[].name", "A name to identify this token plugin", i18n.StringType)
 	ConfigTokensPlugin   = ffc("config.tokens[].plugin", "The type of the token plugin to use", i18n.StringType)
 	ConfigTokensURL      = ffc("config.tokens[].url", "The URL of the token connector", urlStringType)
@@ -416,8 +547,32 @@ var (
 	ConfigPluginsAuthName = ffc("config.plugins.auth[].name", "The name of the auth plugin to use", i18n.StringType)
 	ConfigPluginsAuthType = ffc("config.plugins.auth[].type", "The type of the auth plugin to use", i18n.StringType)
 
-	ConfigPluginsEventSystemReadAhead           = ffc("config.events.system.readAhead", "", i18n.IgnoredType)
-	ConfigPluginsEventWebhooksURL               = ffc("config.events.webhooks.url", "", i18n.IgnoredType)
-	ConfigPluginsEventWebSocketsReadBufferSize  = ffc("config.events.websockets.readBufferSize", "WebSocket read buffer size", i18n.ByteSizeType)
-	ConfigPluginsEventWebSocketsWriteBufferSize = ffc("config.events.websockets.writeBufferSize", "WebSocket write buffer size", i18n.ByteSizeType)
+	ConfigGlobalRBACJWTJWKSFile       = ffc("config.global.rbac.jwt.jwksFile", "The path to a JSON Web Key Set (JWKS) file, containing the public or symmetric keys bearer tokens can be signed with", i18n.StringType)
+	ConfigGlobalRBACJWTPublicKeys     = ffc("config.global.rbac.jwt.publicKeys", "PEM encoded public keys bearer tokens can be signed with, in addition to the keys in the JWKS file", i18n.ArrayStringType)
+	ConfigGlobalRBACJWTIssuer         = ffc("config.global.rbac.jwt.issuer", "The issuer bearer tokens must have. Not checked if unset", i18n.StringType)
+	ConfigGlobalRBACJWTAudience       = ffc("config.global.rbac.jwt.audience", "An audience bearer tokens must have. Not checked if unset", i18n.StringType)
+	ConfigGlobalRBACJWTRolesClaim     = ffc("config.global.rbac.jwt.rolesClaim", "The claim of bearer tokens that holds the roles of the caller, such as 'realm_access.roles' for a nested claim. The claim can be an array, or a space or comma separated string", i18n.StringType)
+	ConfigGlobalRBACAPIKeysHeader     = ffc("config.global.rbac.apiKeys.header", "The request header API keys are passed in", i18n.StringType)
+	ConfigGlobalRBACRolesName         = ffc("config.global.rbac.roles[].name", "The name of the role, as it appears in the roles of bearer tokens and API keys", i18n.StringType)
+	ConfigGlobalRBACPermNamespaces    = ffc("config.global.rbac.roles[].permissions[].namespaces", "The namespaces the permission applies to. Empty, or '*', matches any namespace", i18n.ArrayStringType)
+	ConfigGlobalRBACPermRoutes        = ffc("config.global.rbac.roles[].permissions[].routes", "The names of the API routes the permission grants access to, such as 'getMessages'. Empty, or '*', matches any route", i18n.ArrayStringType)
+	ConfigGlobalRBACPermMethods       = ffc("config.global.rbac.roles[].permissions[].methods", "The HTTP methods the permission grants access to. Empty, or '*', matches any method", i18n.ArrayStringType)
+	ConfigGlobalRBACPermSubscriptions = ffc("config.global.rbac.roles[].permissions[].subscriptions", "The names of the subscriptions the permission allows event streams to be started for, over WebSockets or Server-Sent Events. Empty, or '*', matches any subscription", i18n.ArrayStringType)
+
+	ConfigPluginsEventKafkaBrokers               = ffc("config.events.kafka.brokers", "The bootstrap brokers of the Kafka cluster events are written to", i18n.ArrayStringType)
+	ConfigPluginsEventKafkaClientID              = ffc("config.events.kafka.clientId", "The client ID sent to the Kafka brokers", i18n.StringType)
+	ConfigPluginsEventKafkaTransactionalIDPrefix = ffc("config.events.kafka.transactionalIdPrefix", "The prefix of the transactional ID of the producer for each subscription, which is followed by the subscription ID. The same ID is used as the consumer group the subscription offset is committed to", i18n.StringType)
+	ConfigPluginsEventKafkaTopic                 = ffc("config.events.kafka.topic", "The broker topic events are written to, for subscriptions that do not set a 'topic' option", i18n.StringType)
+	ConfigPluginsEventKafkaPartitionKey          = ffc("config.events.kafka.partitionKey", "The event field used as the record key for partitioning, for subscriptions that do not set a 'partitionKey' option. One of: topic, group, none", i18n.StringType)
+	ConfigPluginsEventKafkaTransactionRetries    = ffc("config.events.kafka.transactionRetries", "The number of times a transaction that was aborted is retried, before the delivery fails and the event (or batch) is redelivered", i18n.IntType)
+	ConfigPluginsEventKafkaTransactionRetryDelay = ffc("config.events.kafka.transactionRetryDelay", "The delay before retrying a transaction that was aborted", i18n.TimeDurationType)
+	ConfigPluginsEventKafkaAuthUsername          = ffc("config.events.kafka.auth.username", "The username for SASL/PLAIN authentication with the Kafka brokers. SASL is not used if unset", i18n.StringType)
+	ConfigPluginsEventKafkaAuthPassword          = ffc("config.events.kafka.auth.password", "The password for SASL/PLAIN authentication with the Kafka brokers", i18n.StringType)
+	ConfigPluginsEventSSEHeartbeatInterval       = ffc("config.events.sse.heartbeatInterval", "How often a heartbeat comment is written to an idle Server-Sent Events stream, to stop proxies closing the connection", i18n.TimeDurationType)
+	ConfigPluginsEventSystemReadAhead            = ffc("config.events.system.readAhead", "", i18n.IgnoredType)
+	ConfigPluginsEventWebhooksURL                = ffc("config.events.webhooks.url", "", i18n.IgnoredType)
+	ConfigPluginsEventWebhooksSigningKey         = ffc("config.events.webhooks.signing.encryptionKey", "The key used to encrypt the HMAC signing secrets of webhook subscriptions, which are stored with the subscription. Required to use signed deliveries", i18n.StringType)
+	ConfigPluginsEventWebhooksReplyTolerance     = ffc("config.events.webhooks.signing.replyTolerance", "How far the timestamp of a signed webhook reply can be from the current time, before the reply is rejected", i18n.TimeDurationType)
+	ConfigPluginsEventWebSocketsReadBufferSize   = ffc("config.events.websockets.readBufferSize", "WebSocket read buffer size", i18n.ByteSizeType)
+	ConfigPluginsEventWebSocketsWriteBufferSize  = ffc("config.events.websockets.writeBufferSize", "WebSocket write buffer size", i18n.ByteSizeType)
 )
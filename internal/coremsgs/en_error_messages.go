@@ -316,4 +316,68 @@ var (
 	MsgFiltersEmpty                            = ffe("FF10475", "No filters specified in contract listener: %s.", 500)
 	MsgContractListenerBlockchainFilterLimit   = ffe("FF10476", "Blockchain plugin only supports one filter for contract listener: %s.", 500)
 	MsgDuplicateContractListenerFilterLocation = ffe("FF10477", "Duplicate filter provided for contract listener for location", 400)
+	MsgUnknownSyncAsyncCoordinator             = ffe("FF10478", "Unknown sync/async coordinator type '%s'")
+	MsgKafkaBrokerErr                          = ffe("FF10479", "Error from Kafka broker: %s")
+	MsgKafkaTopicEmpty                         = ffe("FF10480", "Kafka subscription option 'topic' cannot be empty", 400)
+	MsgKafkaInvalidPartitionKey                = ffe("FF10481", "Invalid Kafka subscription option partitionKey='%s' (must be one of: %s)", 400)
+	MsgKafkaTransactionFailed                  = ffe("FF10482", "Kafka transaction writing %d records to topic '%s' failed: %s")
+	MsgKafkaOffsetFetchFailed                  = ffe("FF10483", "Failed to read the committed event offset of Kafka group '%s' for topic '%s': %s")
+	MsgSSEInvalidStart                         = ffe("FF10484", "An event stream must set either a subscription name or ephemeral=true", 400)
+	MsgSSENoData                               = ffe("FF10485", "Server-Sent Events subscriptions do not support streaming the full data payload, just the references (withData must be false)", 400)
+	MsgSSEInvalidLastEventID                   = ffe("FF10486", "Invalid Last-Event-ID '%s' (must be an event sequence)", 400)
+	MsgSSEConnectionNotActive                  = ffe("FF10487", "Event stream connection '%s' is not active", 404)
+	MsgSSEAckNotMatched                        = ffe("FF10488", "Acknowledgment does not match an inflight event or batch on event stream connection '%s'", 400)
+	MsgSubscriptionExpressionInvalid           = ffe("FF10489", "Invalid subscription filter expression '%s': %s", 400)
+	MsgDeadLetterNoActiveDispatcher            = ffe("FF10490", "Subscription '%s' is not currently delivering events on this node, so dead-lettered events cannot be replayed", 409)
+	MsgWebhookTemplateInvalid                  = ffe("FF10491", "Webhook subscription option 'template' is not a valid template: %s", 400)
+	MsgWebhookTemplateFailed                   = ffe("FF10492", "Failed to render webhook template for event '%s': %s")
+	MsgWebhookSigningNoKey                     = ffe("FF10493", "Webhook signing secrets cannot be stored, as no 'signing.encryptionKey' is configured for the webhooks plugin", 400)
+	MsgWebhookSigningSecretInvalid             = ffe("FF10494", "Webhook signing secret '%s' cannot be decrypted with the configured encryption key", 400)
+	MsgWebhookReplySignatureInvalid            = ffe("FF10495", "Webhook reply signature in header '%s' is invalid: %s")
+	MsgAuthRoleNoName                          = ffe("FF10496", "Role at index %d in the rbac auth plugin configuration has no name")
+	MsgAuthJWKSInvalid                         = ffe("FF10497", "Invalid JSON Web Key Set '%s': %s")
+	MsgAuthPublicKeyInvalid                    = ffe("FF10498", "Invalid public key at index %d in the rbac auth plugin configuration: %s")
+	MsgRequestRateLimited                      = ffe("FF10499", "Request rate limit exceeded for %s '%s'", 429)
+	MsgRequestConcurrencyLimited               = ffe("FF10500", "Too many concurrent requests for %s '%s'", 429)
+	MsgInvalidTracingProtocol                  = ffe("FF10501", "Invalid OTLP protocol '%s' for tracing - must be 'http' or 'grpc'")
+	MsgRetentionArchiveFailed                  = ffe("FF10502", "Failed to archive records to '%s'")
+	MsgNamespaceArchiveInvalid                 = ffe("FF10503", "Invalid namespace archive at entry %d: %s", 400)
+	MsgNamespaceArchiveVersion                 = ffe("FF10504", "Namespace archive version %d is not supported - the latest supported version is %d", 400)
+	MsgNamespaceArchiveIncomplete              = ffe("FF10505", "Namespace archive is incomplete, as it does not end with a trailer", 400)
+	MsgNamespaceArchiveCountMismatch           = ffe("FF10506", "Namespace archive contains %d records of type '%s', but its trailer lists %d", 400)
+	MsgNamespaceArchiveMissingReference        = ffe("FF10507", "Namespace archive record '%s' of type '%s' refers to %s '%s', which is not in the archive or the namespace", 400)
+	MsgNamespaceArchiveGroupHash               = ffe("FF10508", "Namespace archive group '%s' does not match its hash", 400)
+	MsgNamespaceImportUploadRequired           = ffe("FF10509", "A namespace archive must be uploaded as multipart/form-data", 400)
+	MsgJSONPathInvalid                         = ffe("FF10510", "Invalid JSON-path condition '%s' - expected 'path:value', where path is a list of keys and array indexes such as 'invoice.lines[0].sku'", 400)
+	MsgJSONPathTooDeep                         = ffe("FF10511", "JSON path '%s' has more than the maximum of %d keys and indexes", 400)
+	MsgJSONPathTooManyConditions               = ffe("FF10512", "Query has more than the maximum of %d JSON-path conditions", 400)
+	MsgJSONPathMixedOr                         = ffe("FF10513", "JSON-path conditions on messages cannot be combined with conditions on other fields in an 'or'", 400)
+	MsgJSONPathInPaths                         = ffe("FF10514", "Every value of an 'in' JSON-path condition must have the same path", 400)
+	MsgInvalidCursor                           = ffe("FF10515", "Invalid cursor '%s'", 400)
+	MsgCursorSortUnsupported                   = ffe("FF10516", "A cursor can only be used when sorting by sequence", 400)
+	MsgCursorWithSkip                          = ffe("FF10517", "A cursor cannot be used with skip", 400)
+	MsgChartInvalidField                       = ffe("FF10518", "Field '%s' cannot be used in a chart of this collection", 400)
+	MsgChartFieldNotNumeric                    = ffe("FF10519", "Field '%s' is not numeric, so cannot be aggregated with '%s'", 400)
+	MsgChartInvalidAggregate                   = ffe("FF10520", "Invalid chart aggregation '%s'", 400)
+	MsgChartTooManyGroupBy                     = ffe("FF10521", "Charts can be grouped by at most %d fields", 400)
+	MsgUnknownKeyProviderPlugin                = ffe("FF10522", "Unknown key provider plugin '%s'")
+	MsgKeyFileReadFailed                       = ffe("FF10523", "Failed to read key file '%s'")
+	MsgKeyFileInvalid                          = ffe("FF10524", "Invalid key file '%s': %s")
+	MsgEncryptionKeyNotFound                   = ffe("FF10525", "Encryption key '%s' not found in the key provider")
+	MsgDataKeyUnwrapFailed                     = ffe("FF10526", "Failed to unwrap data key with encryption key '%s'")
+	MsgEncryptedValueNoKeyProvider             = ffe("FF10527", "Value in the database is encrypted, but no key provider is configured")
+	MsgDataKeyNotFound                         = ffe("FF10528", "Data key '%s' not found")
+	MsgDecryptFailed                           = ffe("FF10529", "Failed to decrypt value with data key '%s'")
+	MsgJSONPathEncrypted                       = ffe("FF10530", "JSON-path conditions cannot be used when data values are encrypted", 400)
+	MsgContractListenerConfirmationsInvalid    = ffe("FF10531", "Invalid confirmations '%d' for contract listener - must be zero or more", 400)
+	MsgEthRPCErr                               = ffe("FF10532", "Error from ethereum JSON-RPC endpoint: %s")
+	MsgEthRPCReverted                          = ffe("FF10533", "Ethereum transaction reverted: %s")
+	MsgEthRPCUnsupportedOption                 = ffe("FF10534", "Option '%s' is not supported by the ethrpc blockchain plugin", 400)
+	MsgEthRPCInvalidContract                   = ffe("FF10535", "Invalid contract definition: %s", 400)
+	MsgEthRPCListenerNotFound                  = ffe("FF10536", "Listener '%s' not found", 404)
+	MsgBackfillBlockRangeInvalid               = ffe("FF10537", "Invalid block range for backfill - fromBlock %d is after toBlock %d", 400)
+	MsgBackfillInProgress                      = ffe("FF10538", "Backfill '%s' is already running for this listener", 409)
+	MsgBackfillBeyondConfirmedBlock            = ffe("FF10539", "Backfill must end at or before the last confirmed block %d", 400)
+	MsgChartRangeTooShort                      = ffe("FF10541", "The time range is too short to divide into %d buckets", 400)
+	MsgNamespaceArchiveInvalidSubscription     = ffe("FF10542", "Namespace archive subscription '%s' is not valid", 400)
 )
@@ -72,6 +72,7 @@ var (
 	MessagePins           = ffm("Message.pins", "For private messages, a unique pin hash:nonce is assigned for each topic")
 	MessageTransactionID  = ffm("Message.txid", "The ID of the transaction used to order/deliver this message")
 	MessageIdempotencyKey = ffm("Message.idempotencyKey", "An optional unique identifier for a message. Cannot be duplicated within a namespace, thus allowing idempotent submission of messages to the API. Local only - not transferred when the message is sent to other members of the network")
+	MessageTraceContext   = ffm("Message.traceContext", "The W3C trace context of the request that created the message, used to continue the trace as the message is batched and confirmed. Local only")
 
 	// MessageInOut field descriptions
 	MessageInOutData  = ffm("MessageInOut.data", "For input allows you to specify data in-line in the message, that will be turned into data attachments. For output when fetchdata is used on API calls, includes the in-line data payloads of all data attachments")
@@ -168,11 +169,12 @@ var (
 	BatchManifestData     = ffm("BatchManifest.data", "Array of manifest entries, succinctly summarizing the data in the batch")
 
 	// BatchPersisted field descriptions
-	BatchPersistedHash       = ffm("Batch.hash", "The hash of the manifest of the batch")
-	BatchPersistedManifest   = ffm("Batch.manifest", "The manifest of the batch")
-	BatchPersistedTX         = ffm("Batch.tx", "The FireFly transaction associated with this batch")
-	BatchPersistedPayloadRef = ffm("Batch.payloadRef", "For broadcast batches, this is the reference to the binary batch in shared storage")
-	BatchPersistedConfirmed  = ffm("Batch.confirmed", "The time when the batch was confirmed")
+	BatchPersistedHash         = ffm("Batch.hash", "The hash of the manifest of the batch")
+	BatchPersistedManifest     = ffm("Batch.manifest", "The manifest of the batch")
+	BatchPersistedTX           = ffm("Batch.tx", "The FireFly transaction associated with this batch")
+	BatchPersistedPayloadRef   = ffm("Batch.payloadRef", "For broadcast batches, this is the reference to the binary batch in shared storage")
+	BatchPersistedConfirmed    = ffm("Batch.confirmed", "The time when the batch was confirmed")
+	BatchPersistedTraceContext = ffm("Batch.traceContext", "The W3C trace context of the span that sealed the batch, used to continue the trace when the batch is confirmed. Local only")
 
 	// Transaction field descriptions
 	TransactionID             = ffm("Transaction.id", "The UUID of the FireFly transaction")
@@ -184,18 +186,19 @@ var (
 	TransactionBlockchainIDs  = ffm("Transaction.blockchainIds", "The blockchain transaction ID, in the format specific to the blockchain involved in the transaction. Not all FireFly transactions include a blockchain. FireFly transactions are extensible to support multiple blockchain transactions")
 
 	// Operation field description
-	OperationID          = ffm("Operation.id", "The UUID of the operation")
-	OperationNamespace   = ffm("Operation.namespace", "The namespace of the operation")
-	OperationTransaction = ffm("Operation.tx", "The UUID of the FireFly transaction the operation is part of")
-	OperationType        = ffm("Operation.type", "The type of the operation")
-	OperationStatus      = ffm("Operation.status", "The current status of the operation")
-	OperationPlugin      = ffm("Operation.plugin", "The plugin responsible for performing the operation")
-	OperationInput       = ffm("Operation.input", "The input to this operation")
-	OperationOutput      = ffm("Operation.output", "Any output reported back from the plugin for this operation")
-	OperationError       = ffm("Operation.error", "Any error reported back from the plugin for this operation")
-	OperationCreated     = ffm("Operation.created", "The time the operation was created")
-	OperationUpdated     = ffm("Operation.updated", "The last update time of the operation")
-	OperationRetry       = ffm("Operation.retry", "If this operation was initiated as a retry to a previous operation, this field points to the UUID of the operation being retried")
+	OperationID           = ffm("Operation.id", "The UUID of the operation")
+	OperationNamespace    = ffm("Operation.namespace", "The namespace of the operation")
+	OperationTransaction  = ffm("Operation.tx", "The UUID of the FireFly transaction the operation is part of")
+	OperationType         = ffm("Operation.type", "The type of the operation")
+	OperationStatus       = ffm("Operation.status", "The current status of the operation")
+	OperationPlugin       = ffm("Operation.plugin", "The plugin responsible for performing the operation")
+	OperationInput        = ffm("Operation.input", "The input to this operation")
+	OperationOutput       = ffm("Operation.output", "Any output reported back from the plugin for this operation")
+	OperationError        = ffm("Operation.error", "Any error reported back from the plugin for this operation")
+	OperationCreated      = ffm("Operation.created", "The time the operation was created")
+	OperationUpdated      = ffm("Operation.updated", "The last update time of the operation")
+	OperationRetry        = ffm("Operation.retry", "If this operation was initiated as a retry to a previous operation, this field points to the UUID of the operation being retried")
+	OperationTraceContext = ffm("Operation.traceContext", "The W3C trace context of the request that created the operation, used to continue the trace when updates are received from the plugin")
 
 	// OperationWithDetail field description
 	OperationWithDetail = ffm("OperationWithDetail.detail", "Additional detailed information about an operation provided by the connector")
@@ -211,6 +214,7 @@ var (
 	BlockchainEventInfo       = ffm("BlockchainEvent.info", "Detailed blockchain specific information about the event, as generated by the blockchain connector")
 	BlockchainEventTimestamp  = ffm("BlockchainEvent.timestamp", "The time allocated to this event by the blockchain. This is the block timestamp for most blockchain connectors")
 	BlockchainEventTX         = ffm("BlockchainEvent.tx", "If this blockchain event is coorelated to FireFly transaction such as a FireFly submitted token transfer, this field is set to the UUID of the FireFly transaction")
+	BlockchainEventRemoved    = ffm("BlockchainEvent.removed", "True if the blockchain connector has reported that this event was removed from the chain by a reorganization")
 
 	// ChartHistogram field descriptions
 	ChartHistogramCount     = ffm("ChartHistogram.count", "Total count of entries in this time bucket within the histogram")
@@ -222,6 +226,26 @@ var (
 	ChartHistogramTypeCount = ffm("ChartHistogramType.count", "Count of entries of a given type within a bucket")
 	ChartHistogramTypeType  = ffm("ChartHistogramType.type", "Name of the type")
 
+	// ChartAggregateInput field descriptions
+	ChartAggregateInputStartTime = ffm("ChartAggregateInput.startTime", "Start time of the data to be fetched")
+	ChartAggregateInputEndTime   = ffm("ChartAggregateInput.endTime", "End time of the data to be fetched")
+	ChartAggregateInputBuckets   = ffm("ChartAggregateInput.buckets", "Number of buckets between start time and end time")
+	ChartAggregateInputGroupBy   = ffm("ChartAggregateInput.groupBy", "The fields of the collection to group the records of each bucket by")
+	ChartAggregateInputAggregate = ffm("ChartAggregateInput.aggregate", "The aggregation of the records in each group. Defaults to count")
+	ChartAggregateInputField     = ffm("ChartAggregateInput.field", "The numeric field of the collection to sum or average")
+	ChartAggregateInputFilter    = ffm("ChartAggregateInput.filter", "A filter on the fields of the collection, to select the records to aggregate")
+
+	// ChartAggregate field descriptions
+	ChartAggregateTimestamp = ffm("ChartAggregate.timestamp", "Starting timestamp for the bucket")
+	ChartAggregateCount     = ffm("ChartAggregate.count", "Total count of the records in this time bucket that match the filter")
+	ChartAggregateGroups    = ffm("ChartAggregate.groups", "The aggregation of each group of records within the bucket")
+	ChartAggregateIsCapped  = ffm("ChartAggregate.isCapped", "Always false, as every record in the bucket is included in the aggregation. Kept for compatibility with histograms")
+
+	// ChartAggregateGroup field descriptions
+	ChartAggregateGroupDimensions = ffm("ChartAggregateGroup.dimensions", "The value of each group-by field for the records in this group")
+	ChartAggregateGroupCount      = ffm("ChartAggregateGroup.count", "Count of the records in this group")
+	ChartAggregateGroupValue      = ffm("ChartAggregateGroup.value", "The result of the aggregation for this group, as a decimal string")
+
 	// ContractAPI field descriptions
 	ContractAPIID          = ffm("ContractAPI.id", "The UUID of the contract API")
 	ContractAPINamespace   = ffm("ContractAPI.namespace", "The namespace of the contract API")
@@ -317,7 +341,39 @@ var (
 	ContractListenerState     = ffm("ContractListener.state", "This field is provided for the event listener implementation of the blockchain provider to record state, such as checkpoint information")
 
 	// ContractListenerOptions field descriptions
-	ContractListenerOptionsFirstEvent = ffm("ContractListenerOptions.firstEvent", "A blockchain specific string, such as a block number, to start listening from. The special strings 'oldest' and 'newest' are supported by all blockchain connectors. Default is 'newest'")
+	ContractListenerOptionsConfirmations = ffm("ContractListenerOptions.confirmations", "The number of blocks that must be mined on top of the block containing an event, before the blockchain connector delivers the event. Only supported by blockchain connectors where blocks can be reorganized, and defaults to the confirmations configured on the connector")
+	ContractListenerOptionsFirstEvent    = ffm("ContractListenerOptions.firstEvent", "A blockchain specific string, such as a block number, to start listening from. The special strings 'oldest' and 'newest' are supported by all blockchain connectors. Default is 'newest'")
+
+	// ContractListenerWithStatus field descriptions
+	ContractListenerWithStatusStatus = ffm("ContractListenerWithStatus.status", "The status of the listener, as reported by the blockchain connector")
+	ContractListenerWithStatusHealth = ffm("ContractListenerWithStatus.health", "A blockchain independent summary of the progress of the listener through the chain")
+
+	// ContractListenerHealth field descriptions
+	ContractListenerHealthStatus          = ffm("ContractListenerHealth.status", "Whether the listener is synced with the head of the chain")
+	ContractListenerHealthCheckpointBlock = ffm("ContractListenerHealth.checkpointBlock", "The last block for which all events have been delivered, if reported by the blockchain connector")
+	ContractListenerHealthChainHead       = ffm("ContractListenerHealth.chainHead", "The latest block on the chain, if reported by the blockchain connector")
+	ContractListenerHealthLagBlocks       = ffm("ContractListenerHealth.lagBlocks", "The number of confirmed blocks the listener has yet to process")
+	ContractListenerHealthLagSeconds      = ffm("ContractListenerHealth.lagSeconds", "How many seconds the listener is behind the latest confirmed block. Measured between block timestamps if the blockchain connector reports them, otherwise from when the listener was first found to be behind")
+	ContractListenerHealthBehind          = ffm("ContractListenerHealth.behind", "True if the lag of the listener exceeds the thresholds configured under contracts.listenerHealth")
+	ContractListenerHealthLastError       = ffm("ContractListenerHealth.lastError", "The last error encountered by the listener, or by the query for its health")
+	ContractListenerHealthUpdated         = ffm("ContractListenerHealth.updated", "The time the health was calculated")
+
+	// ContractListenerBackfillRequest field descriptions
+	ContractListenerBackfillRequestFromBlock = ffm("ContractListenerBackfillRequest.fromBlock", "The first block of the range to backfill, inclusive")
+	ContractListenerBackfillRequestToBlock   = ffm("ContractListenerBackfillRequest.toBlock", "The last block of the range to backfill, inclusive. Must not be beyond the last confirmed block")
+
+	// ContractListenerBackfill field descriptions
+	ContractListenerBackfillID           = ffm("ContractListenerBackfill.id", "The UUID of the backfill")
+	ContractListenerBackfillNamespace    = ffm("ContractListenerBackfill.namespace", "The namespace of the listener being backfilled")
+	ContractListenerBackfillListener     = ffm("ContractListenerBackfill.listener", "The UUID of the listener being backfilled")
+	ContractListenerBackfillFromBlock    = ffm("ContractListenerBackfill.fromBlock", "The first block of the range being backfilled, inclusive")
+	ContractListenerBackfillToBlock      = ffm("ContractListenerBackfill.toBlock", "The last block of the range being backfilled, inclusive")
+	ContractListenerBackfillCurrentBlock = ffm("ContractListenerBackfill.currentBlock", "The last block for which all events have been delivered")
+	ContractListenerBackfillStatus       = ffm("ContractListenerBackfill.status", "The status of the backfill")
+	ContractListenerBackfillError        = ffm("ContractListenerBackfill.error", "The error that stopped the backfill, if it failed")
+	ContractListenerBackfillBackendID    = ffm("ContractListenerBackfill.backendId", "An ID assigned by the blockchain connector to any temporary subscription used for the backfill")
+	ContractListenerBackfillCreated      = ffm("ContractListenerBackfill.created", "The time the backfill was requested")
+	ContractListenerBackfillUpdated      = ffm("ContractListenerBackfill.updated", "The time the progress of the backfill was last updated")
 
 	ListenerFilterInterface = ffm("ListenerFilter.interface", "A reference to an existing FFI, containing pre-registered type information for the event")
 	ListenerFilterEvent     = ffm("ListenerFilter.event", "The definition of the event, either provided in-line when creating the listener, or extracted from the referenced FFI")
@@ -352,6 +408,7 @@ var (
 
 	// EnrichedEvent field descriptions
 	EnrichedEventBlockchainEvent   = ffm("EnrichedEvent.blockchainEvent", "A blockchain event if referenced by the FireFly event")
+	EnrichedEventContractListener  = ffm("EnrichedEvent.contractListener", "A Contract Listener if referenced by the FireFly event")
 	EnrichedEventContractAPI       = ffm("EnrichedEvent.contractAPI", "A Contract API if referenced by the FireFly event")
 	EnrichedEventContractInterface = ffm("EnrichedEvent.contractInterface", "A Contract Interface (FFI) if referenced by the FireFly event")
 	EnrichedEventDatatype          = ffm("EnrichedEvent.datatype", "A Datatype if referenced by the FireFly event")
@@ -362,6 +419,7 @@ var (
 	EnrichedEventTokenPool         = ffm("EnrichedEvent.tokenPool", "A Token Pool if referenced by the FireFly event")
 	EnrichedEventTokenTransfer     = ffm("EnrichedEvent.tokenTransfer", "A Token Transfer if referenced by the FireFly event")
 	EnrichedEventTransaction       = ffm("EnrichedEvent.transaction", "A Transaction if associated with the FireFly event")
+	EnrichedEventContractError     = ffm("EnrichedEvent.contractError", "The custom error raised by the smart contract, if a failed invocation could be decoded against the errors in its FFI")
 
 	// IdentityMessages field descriptions
 	IdentityMessagesClaim        = ffm("IdentityMessages.claim", "The UUID of claim message")
@@ -436,6 +494,15 @@ var (
 	NamespaceStatusOrg  = ffm("NamespaceStatus.org", "Details of the root organization identity registered for this namespace on the local node")
 	NamespacePlugins    = ffm("NamespaceStatus.plugins", "Information about plugins configured on this namespace")
 	NamespaceMultiparty = ffm("NamespaceStatus.multiparty", "Information about the multi-party system configured on this namespace")
+	NamespaceLeader     = ffm("NamespaceStatus.leader", "Information about the replica elected to run the background workers of this namespace, if leader election is enabled")
+
+	// LeaderStatus field descriptions
+	LeaderStatusEnabled = ffm("LeaderStatus.enabled", "Whether leader election is enabled, so only one replica runs the background workers of this namespace")
+	LeaderStatusReplica = ffm("LeaderStatus.replica", "The identity of this replica")
+	LeaderStatusLeader  = ffm("LeaderStatus.leader", "Whether this replica is the leader of the namespace")
+	LeaderStatusHolder  = ffm("LeaderStatus.holder", "The identity of the replica that holds the leader lease, as last seen by this replica")
+	LeaderStatusExpires = ffm("LeaderStatus.expires", "The time the leader lease expires, unless it is renewed")
+	LeaderStatusSince   = ffm("LeaderStatus.since", "The time this replica became the leader")
 
 	// NamespaceStatusNode field descriptions
 	NamespaceStatusNodeName                  = ffm("NamespaceStatusNode.name", "The name of this node, as specified in the local configuration")
@@ -510,6 +577,7 @@ var (
 	PinBatchHash      = ffm("Pin.batchHash", "The manifest hash batch of messages this pin is part of")
 	PinIndex          = ffm("Pin.index", "The index of this pin within the batch. One pin is created for each topic, of each message in the batch")
 	PinDispatched     = ffm("Pin.dispatched", "Once true, this pin has been processed and will not be processed again")
+	PinInvalidated    = ffm("Pin.invalidated", "True if the blockchain event that pinned this batch was removed by a chain reorganization. Invalidated pins are not processed, unless the batch is pinned again")
 	PinSigner         = ffm("Pin.signer", "The blockchain signing key that submitted this transaction, as passed through to FireFly by the smart contract that emitted the blockchain event")
 	PinCreated        = ffm("Pin.created", "The time the FireFly node created the pin")
 	PinRewindSequence = ffm("PinRewind.sequence", "The sequence of the pin to which the event aggregator should rewind. Either sequence or batch must be specified")
@@ -539,6 +607,7 @@ var (
 	SubscriptionFilterMessage          = ffm("SubscriptionFilter.message", "Filters specific to message events. If an event is not a message event, these filters are ignored")
 	SubscriptionFilterTransaction      = ffm("SubscriptionFilter.transaction", "Filters specific to events with a transaction. If an event is not associated with a transaction, this filter is ignored")
 	SubscriptionFilterBlockchainEvent  = ffm("SubscriptionFilter.blockchainevent", "Filters specific to blockchain events. If an event is not a blockchain event, these filters are ignored")
+	SubscriptionFilterExpression       = ffm("SubscriptionFilter.expression", "A CEL expression that must return true for the event to be dispatched. The enriched event, including the referred message, transaction, blockchain event etc., is available as the 'event' variable")
 	SubscriptionFilterDeprecatedTopics = ffm("SubscriptionFilter.topics", "Deprecated: Please use 'topic' instead")
 	SubscriptionFilterDeprecatedTag    = ffm("SubscriptionFilter.tag", "Deprecated: Please use 'message.tag' instead")
 	SubscriptionFilterDeprecatedGroup  = ffm("SubscriptionFilter.group", "Deprecated: Please use 'message.group' instead")
@@ -562,6 +631,8 @@ var (
 	SubscriptionCoreOptionsWithData     = ffm("SubscriptionCoreOptions.withData", "Whether message events delivered over the subscription, should be packaged with the full data of those messages in-line as part of the event JSON payload. Or if the application should make separate REST calls to download that data. May not be supported on some transports.")
 	SubscriptionCoreOptionsBatch        = ffm("SubscriptionCoreOptions.batch", "Events are delivered in batches in an ordered array. The batch size is capped to the readAhead limit. The event payload is always an array even if there is a single event in the batch, allowing client-side optimizations when processing the events in a group. Available for both Webhooks and WebSockets.")
 	SubscriptionCoreOptionsBatchTimeout = ffm("SubscriptionCoreOptions.batchTimeout", "When batching is enabled, the optional timeout to send events even when the batch hasn't filled.")
+	SubscriptionCoreOptionsMaxAttempts  = ffm("SubscriptionCoreOptions.maxAttempts", "The maximum number of times delivery of an event is attempted, before it is moved to the dead-letter queue for the subscription and delivery continues with the next event. Default is to retry indefinitely, blocking delivery of later events")
+	SubscriptionCoreOptionsRetryBackoff = ffm("SubscriptionCoreOptions.retryBackoff", "The delay before redelivering an event that was rejected, which doubles on each failed attempt up to the maximum retry delay of the event dispatcher. Default is to redeliver immediately")
 
 	// TokenApproval field descriptions
 	TokenApprovalLocalID         = ffm("TokenApproval.localId", "The UUID of this token approval, in the local FireFly node")
@@ -580,6 +651,7 @@ var (
 	TokenApprovalCreated         = ffm("TokenApproval.created", "The creation time of the token approval")
 	TokenApprovalTX              = ffm("TokenApproval.tx", "If submitted via FireFly, this will reference the UUID of the FireFly transaction (if the token connector in use supports attaching data)")
 	TokenApprovalBlockchainEvent = ffm("TokenApproval.blockchainEvent", "The UUID of the blockchain event")
+	TokenApprovalInvalidated     = ffm("TokenApproval.invalidated", "True if the blockchain event of this approval was removed by a chain reorganization")
 	TokenApprovalConfig          = ffm("TokenApproval.config", "Input only field, with token connector specific configuration of the approval.  See your chosen token connector documentation for details")
 
 	// TokenApprovalInput field descriptions
@@ -644,6 +716,7 @@ var (
 	TokenTransferCreated         = ffm("TokenTransfer.created", "The creation time of the transfer")
 	TokenTransferTX              = ffm("TokenTransfer.tx", "If submitted via FireFly, this will reference the UUID of the FireFly transaction (if the token connector in use supports attaching data)")
 	TokenTransferBlockchainEvent = ffm("TokenTransfer.blockchainEvent", "The UUID of the blockchain event")
+	TokenTransferInvalidated     = ffm("TokenTransfer.invalidated", "True if the blockchain event of this transfer was removed by a chain reorganization")
 	TokenTransferConfig          = ffm("TokenTransfer.config", "Input only field, with token connector specific configuration of the transfer. See your chosen token connector documentation for details")
 
 	// TokenTransferInput field descriptions
@@ -673,6 +746,11 @@ var (
 	ContractDeployRequestOptions        = ffm("ContractDeployRequest.options", "A map of named inputs that will be passed through to the blockchain connector")
 	ContractDeployRequestIdempotencyKey = ffm("ContractDeployRequest.idempotencyKey", "An optional identifier to allow idempotent submission of requests. Stored on the transaction uniquely within a namespace")
 
+	// ContractError field descriptions
+	ContractErrorName      = ffm("ContractError.name", "The name of the custom error, as defined in the errors of the FFI")
+	ContractErrorSignature = ffm("ContractError.signature", "The signature of the custom error, if the blockchain distinguishes errors by signature")
+	ContractErrorParams    = ffm("ContractError.params", "The parameters of the custom error, decoded against the FFI error definition")
+
 	// ContractCallRequest field descriptions
 	ContractCallRequestType       = ffm("ContractCallRequest.type", "Invocations cause transactions on the blockchain. Whereas queries simply execute logic in your local node to query data at a given current/historical block")
 	ContractCallRequestInterface  = ffm("ContractCallRequest.interface", "The UUID of a method within a pre-configured FireFly interface (FFI) definition for a smart contract. Required if the 'method' is omitted. Also see Contract APIs as a way to configure a dedicated API for your FFI, including all methods and an OpenAPI/Swagger interface")
@@ -704,11 +782,15 @@ var (
 	WSSubscriptionStatusFilter    = ffm("WSSubscriptionStatus.filter", "The subscription filter specification")
 	WSSubscriptionStatusStartTime = ffm("WSSubscriptionStatus.startTime", "The time the subscription started (reset on dynamic namespace reload)")
 
+	// SSEAck field descriptions
+	SSEAckID = ffm("SSEAck.id", "The ID of the event, or batch, to acknowledge. When omitted the oldest unacknowledged event on the stream is acknowledged")
+
 	WebhooksOptJSON                     = ffm("WebhookSubOptions.json", "Webhooks only: Whether to assume the response body is JSON, regardless of the returned Content-Type")
 	WebhooksOptReply                    = ffm("WebhookSubOptions.reply", "Webhooks only: Whether to automatically send a reply event, using the body returned by the webhook")
 	WebhooksOptHeaders                  = ffm("WebhookSubOptions.headers", "Webhooks only: Static headers to set on the webhook request")
 	WebhooksOptQuery                    = ffm("WebhookSubOptions.query", "Webhooks only: Static query params to set on the webhook request")
 	WebhooksOptInput                    = ffm("WebhookSubOptions.input", "Webhooks only: A set of options to extract data from the first JSON input data in the incoming message. Only applies if withData=true")
+	WebhooksOptTemplate                 = ffm("WebhookSubOptions.template", "Webhooks only: A Go template that renders the request body from the event and its data, in place of the default payload")
 	WebhooksOptFastAck                  = ffm("WebhookSubOptions.fastack", "Webhooks only: When true the event will be acknowledged before the webhook is invoked, allowing parallel invocations")
 	WebhooksOptURL                      = ffm("WebhookSubOptions.url", "Webhooks only: HTTP url to invoke. Can be relative if a base URL is set in the webhook plugin config")
 	WebhooksOptMethod                   = ffm("WebhookSubOptions.method", "Webhooks only: HTTP method to invoke. Default=POST")
@@ -717,6 +799,12 @@ var (
 	WebhooksOptTLSConfigName            = ffm("WebhookSubOptions.tlsConfigName", "The name of an existing TLS configuration associated to the namespace to use")
 	WebhooksOptHTTPOptions              = ffm("WebhookSubOptions.httpOptions", "Webhooks only: a set of options for HTTP")
 	WebhooksOptHTTPRetry                = ffm("WebhookSubOptions.retry", "Webhooks only: a set of options for retrying the webhook call")
+	WebhooksOptSigning                  = ffm("WebhookSubOptions.signing", "Webhooks only: a set of options for signing each request with an HMAC, so the receiver can verify it came from this node")
+	WebhooksOptSigningSecret            = ffm("WebhookSigningOptions.secret", "The secret used to sign requests. Encrypted before it is stored on the subscription")
+	WebhooksOptSigningPreviousSecret    = ffm("WebhookSigningOptions.previousSecret", "A previous secret that requests are also signed with until previousSecretExpiry, to allow receivers to move to a new secret")
+	WebhooksOptSigningPreviousExpiry    = ffm("WebhookSigningOptions.previousSecretExpiry", "The time after which requests are no longer signed with the previous secret")
+	WebhooksOptSigningHeader            = ffm("WebhookSigningOptions.header", "The header the signature is set in. Default=X-FireFly-Signature")
+	WebhooksOptSigningVerifyReply       = ffm("WebhookSigningOptions.verifyReply", "When reply=true, whether to require the webhook response to be signed in the same way, before a reply is sent")
 	WebhooksOptInputQuery               = ffm("WebhookInputOptions.query", "A top-level property of the first data input, to use for query parameters")
 	WebhooksOptInputHeaders             = ffm("WebhookInputOptions.headers", "A top-level property of the first data input, to use for headers")
 	WebhooksOptInputBody                = ffm("WebhookInputOptions.body", "A top-level property of the first data input, to use for the request body. Default is the whole first body")
@@ -734,9 +822,54 @@ var (
 	WebhookOptHTTPRequestTimeout        = ffm("WebhookHTTPOptions.requestTimeout", "The max duration to hold a TLS handshake alive")
 	WebhookOptHTTPProxyURL              = ffm("WebhookHTTPOptions.proxyURL", "HTTP proxy URL to use for outbound requests to the webhook")
 
+	// DeadLetter field descriptions
+	DeadLetterID            = ffm("DeadLetter.id", "The UUID of the dead-letter entry")
+	DeadLetterNamespace     = ffm("DeadLetter.namespace", "The namespace of the subscription")
+	DeadLetterSubscription  = ffm("DeadLetter.subscription", "The subscription that failed to deliver the event")
+	DeadLetterEvent         = ffm("DeadLetter.event", "The UUID of the event that could not be delivered")
+	DeadLetterEventSequence = ffm("DeadLetter.eventSequence", "The sequence of the event that could not be delivered")
+	DeadLetterEventType     = ffm("DeadLetter.eventType", "The type of the event that could not be delivered")
+	DeadLetterAttempts      = ffm("DeadLetter.attempts", "The number of failed attempts to deliver the event, including any replays")
+	DeadLetterLastError     = ffm("DeadLetter.lastError", "The error, or rejection information, from the last failed attempt to deliver the event")
+	DeadLetterCreated       = ffm("DeadLetter.created", "The time the event was moved to the dead-letter queue")
+	DeadLetterUpdated       = ffm("DeadLetter.updated", "The time of the last failed replay of the event")
+
 	// PublishInput field descriptions
 	PublishInputIdempotencyKey = ffm("PublishInput.idempotencyKey", "An optional identifier to allow idempotent submission of requests. Stored on the transaction uniquely within a namespace")
 
 	// DefinitionPublish field descriptions
 	DefinitionPublishNetworkName = ffm("DefinitionPublish.networkName", "An optional name to be used for publishing this definition to the multiparty network, which may differ from the local name")
+
+	// APIKey field descriptions
+	APIKeyID        = ffm("APIKey.id", "The UUID of the API key")
+	APIKeyNamespace = ffm("APIKey.namespace", "The namespace of the API key")
+	APIKeyName      = ffm("APIKey.name", "A name for the API key, to identify who or what it was issued to")
+	APIKeyRoles     = ffm("APIKey.roles", "The roles granted to callers that present the API key, as configured in the auth plugin")
+	APIKeyKey       = ffm("APIKey.key", "The API key itself. Only returned once, when the key is created, as just a hash of the key is stored")
+	APIKeyExpires   = ffm("APIKey.expires", "An optional time after which the API key is no longer accepted")
+	APIKeyCreated   = ffm("APIKey.created", "The time the API key was created")
+
+	// RetentionPolicy field descriptions
+	RetentionPolicyEvents           = ffm("RetentionPolicy.events", "The maximum age of events, after they have been delivered to every subscription in the namespace")
+	RetentionPolicyMessages         = ffm("RetentionPolicy.messages", "The maximum age of confirmed and rejected messages")
+	RetentionPolicyData             = ffm("RetentionPolicy.data", "The maximum age of data")
+	RetentionPolicyOperations       = ffm("RetentionPolicy.operations", "The maximum age of succeeded and failed operations")
+	RetentionPolicyBlockchainEvents = ffm("RetentionPolicy.blockchainEvents", "The maximum age of events received from the blockchain")
+	RetentionPolicyTokenTransfers   = ffm("RetentionPolicy.tokenTransfers", "The maximum age of token transfers, mints and burns")
+
+	// RetentionPreview field descriptions
+	RetentionPreviewType   = ffm("RetentionPreview.type", "The type of record")
+	RetentionPreviewMaxAge = ffm("RetentionPreview.maxAge", "The maximum age of records of this type in the policy")
+	RetentionPreviewBefore = ffm("RetentionPreview.before", "Records of this type created before this time would be archived and deleted")
+	RetentionPreviewCount  = ffm("RetentionPreview.count", "The number of records of this type that would be archived and deleted now")
+
+	// NamespaceImportResult field descriptions
+	NamespaceImportResultSource  = ffm("NamespaceImportResult.source", "The namespace the archive was exported from")
+	NamespaceImportResultDryRun  = ffm("NamespaceImportResult.dryRun", "Whether the archive was only verified, without writing to the database")
+	NamespaceImportResultRecords = ffm("NamespaceImportResult.records", "The number of records of each type in the archive")
+
+	// NamespaceImportCount field descriptions
+	NamespaceImportCountType     = ffm("NamespaceImportCount.type", "The type of record")
+	NamespaceImportCountImported = ffm("NamespaceImportCount.imported", "The number of records of this type that were imported")
+	NamespaceImportCountExisting = ffm("NamespaceImportCount.existing", "The number of records of this type that were already in the namespace, and were skipped")
 )
@@ -29,6 +29,7 @@ import (
 	"github.com/hyperledger/firefly/internal/cache"
 	"github.com/hyperledger/firefly/internal/coreconfig"
 	"github.com/hyperledger/firefly/internal/coremsgs"
+	"github.com/hyperledger/firefly/internal/tracing"
 	"github.com/hyperledger/firefly/pkg/core"
 	"github.com/hyperledger/firefly/pkg/database"
 	"github.com/hyperledger/firefly/pkg/dataexchange"
@@ -50,6 +51,7 @@ type Manager interface {
 	UploadJSON(ctx context.Context, inData *core.DataRefOrValue) (*core.Data, error)
 	UploadBlob(ctx context.Context, inData *core.DataRefOrValue, blob *ffapi.Multipart, autoMeta bool) (*core.Data, error)
 	DownloadBlob(ctx context.Context, dataID string) (*core.Blob, io.ReadCloser, error)
+	DeleteBlob(ctx context.Context, blob *core.Blob) error
 	DeleteData(ctx context.Context, dataID string) error
 	HydrateBatch(ctx context.Context, persistedBatch *core.BatchPersisted) (*core.Batch, error)
 	Start()
@@ -169,7 +171,7 @@ func (dm *dataManager) getValidatorForDatatype(ctx context.Context, validator co
 		return cachedValue.(Validator), nil
 	}
 
-	datatype, err := dm.database.GetDatatypeByName(ctx, dm.namespace.Name, datatypeRef.Name, datatypeRef.Version)
+	datatype, err := dm.database.GetDatatypeByName(database.WithPrimaryReads(ctx), dm.namespace.Name, datatypeRef.Name, datatypeRef.Version)
 	if err != nil {
 		return nil, err
 	}
@@ -193,7 +195,7 @@ func (dm *dataManager) GetMessageWithDataCached(ctx context.Context, msgID *ffty
 	if mce := dm.queryMessageCache(ctx, msgID, options...); mce != nil {
 		return mce.msg, mce.data, true, nil
 	}
-	msg, err = dm.database.GetMessageByID(ctx, dm.namespace.Name, msgID)
+	msg, err = dm.database.GetMessageByID(database.WithPrimaryReads(ctx), dm.namespace.Name, msgID)
 	if err != nil || msg == nil {
 		return nil, nil, false, err
 	}
@@ -211,9 +213,10 @@ func (dm *dataManager) GetMessageDataCached(ctx context.Context, msg *core.Messa
 	return dm.dataLookupAndCache(ctx, msg)
 }
 
-// cachedMessageAndDataLookup is the common function that can lookup and cache a message with its data
+// cachedMessageAndDataLookup is the common function that can lookup and cache a message with its data.
+// The data is read from the primary database, as a stale read from a replica would be cached.
 func (dm *dataManager) dataLookupAndCache(ctx context.Context, msg *core.Message) (data core.DataArray, foundAllData bool, err error) {
-	data, foundAllData, err = dm.getMessageData(ctx, msg)
+	data, foundAllData, err = dm.getMessageData(database.WithPrimaryReads(ctx), msg)
 	if err != nil {
 		return nil, false, err
 	}
@@ -527,6 +530,11 @@ func (dm *dataManager) WriteNewMessage(ctx context.Context, newMsg *NewMessage)
 		return i18n.NewError(ctx, i18n.MsgNilOrNullObject)
 	}
 
+	// Store the trace of the request, so it can be continued when the message is batched and confirmed
+	if newMsg.Message.TraceContext == nil {
+		newMsg.Message.TraceContext = tracing.TraceContext(ctx)
+	}
+
 	// We add the message to the cache before we write it, because the batch aggregator might
 	// pick up our message from the message-writer before we return. The batch processor
 	// writes a more authoritative cache entry, with pings/batchID etc.
@@ -1379,3 +1379,32 @@ func TestDeleteDataFailGetMessages(t *testing.T) {
 	assert.Regexp(t, "pop", err)
 	mdb.AssertExpectations(t)
 }
+
+func TestGetMessageWithDataCachedReadsPrimary(t *testing.T) {
+	dm, _, cancel := newTestDataManager(t)
+	defer cancel()
+	ctx := database.WithReplicaReads(context.Background())
+	mdi := dm.database.(*databasemocks.Plugin)
+	dataID := fftypes.NewUUID()
+	hash := fftypes.NewRandB32()
+	msg := &core.Message{
+		Header: core.MessageHeader{ID: fftypes.NewUUID()},
+		Data:   core.DataRefs{{ID: dataID, Hash: hash}},
+	}
+
+	// The results are cached, so they must not be stale reads from a replica
+	primaryReads := mock.MatchedBy(func(ctx context.Context) bool {
+		return !database.ReplicaReadsAllowed(ctx)
+	})
+	mdi.On("GetMessageByID", primaryReads, "ns1", msg.Header.ID).Return(msg, nil).Once()
+	mdi.On("GetDataByID", primaryReads, "ns1", dataID, true).Return(&core.Data{
+		ID:   dataID,
+		Hash: hash,
+	}, nil).Once()
+	_, data, foundAll, err := dm.GetMessageWithDataCached(ctx, msg.Header.ID)
+	assert.NoError(t, err)
+	assert.True(t, foundAll)
+	assert.Equal(t, *dataID, *data[0].ID)
+
+	mdi.AssertExpectations(t)
+}
@@ -0,0 +1,212 @@
+// Copyright © 2024 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mysql
+
+import (
+	"context"
+	"database/sql/driver"
+	"strconv"
+	"strings"
+)
+
+// textConnector wraps the connections of the MySQL driver, so the rows they return hold strings and integers
+// in the same way as the other databases. The driver returns the value of every character column as a []byte,
+// and in queries without arguments it also returns integers as a []byte - which types such as fftypes.FFTime
+// and fftypes.FFBigInt cannot scan.
+type textConnector struct {
+	driver.Connector
+}
+
+type textConn struct {
+	driver.Conn
+}
+
+type textStmt struct {
+	driver.Stmt
+}
+
+type textRows struct {
+	driver.Rows
+	kinds []columnKind
+}
+
+type columnKind int
+
+const (
+	columnRaw columnKind = iota
+	columnString
+	columnInteger
+)
+
+func (c *textConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	conn, err := c.Connector.Connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &textConn{Conn: conn}, nil
+}
+
+func (c *textConn) Prepare(query string) (driver.Stmt, error) {
+	return c.PrepareContext(context.Background(), query)
+}
+
+func (c *textConn) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+	var stmt driver.Stmt
+	var err error
+	if p, ok := c.Conn.(driver.ConnPrepareContext); ok {
+		stmt, err = p.PrepareContext(ctx, query)
+	} else {
+		stmt, err = c.Conn.Prepare(query)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &textStmt{Stmt: stmt}, nil
+}
+
+func (c *textConn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	if b, ok := c.Conn.(driver.ConnBeginTx); ok {
+		return b.BeginTx(ctx, opts)
+	}
+	return c.Conn.Begin() //nolint:staticcheck
+}
+
+// QueryContext returns driver.ErrSkip if the driver cannot run the query directly, and the query is prepared instead
+func (c *textConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	q, ok := c.Conn.(driver.QueryerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+	return newTextRows(q.QueryContext(ctx, query, args))
+}
+
+func (c *textConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	if e, ok := c.Conn.(driver.ExecerContext); ok {
+		return e.ExecContext(ctx, query, args)
+	}
+	return nil, driver.ErrSkip
+}
+
+func (c *textConn) Ping(ctx context.Context) error {
+	if p, ok := c.Conn.(driver.Pinger); ok {
+		return p.Ping(ctx)
+	}
+	return nil
+}
+
+func (c *textConn) ResetSession(ctx context.Context) error {
+	if r, ok := c.Conn.(driver.SessionResetter); ok {
+		return r.ResetSession(ctx)
+	}
+	return nil
+}
+
+func (c *textConn) IsValid() bool {
+	if v, ok := c.Conn.(driver.Validator); ok {
+		return v.IsValid()
+	}
+	return true
+}
+
+func (c *textConn) CheckNamedValue(nv *driver.NamedValue) error {
+	if n, ok := c.Conn.(driver.NamedValueChecker); ok {
+		return n.CheckNamedValue(nv)
+	}
+	return driver.ErrSkip
+}
+
+func (s *textStmt) Query(args []driver.Value) (driver.Rows, error) {
+	return newTextRows(s.Stmt.Query(args)) //nolint:staticcheck
+}
+
+func (s *textStmt) QueryContext(ctx context.Context, args []driver.NamedValue) (driver.Rows, error) {
+	if q, ok := s.Stmt.(driver.StmtQueryContext); ok {
+		return newTextRows(q.QueryContext(ctx, args))
+	}
+	values := make([]driver.Value, len(args))
+	for i, a := range args {
+		values[i] = a.Value
+	}
+	return s.Query(values)
+}
+
+func (s *textStmt) ExecContext(ctx context.Context, args []driver.NamedValue) (driver.Result, error) {
+	if e, ok := s.Stmt.(driver.StmtExecContext); ok {
+		return e.ExecContext(ctx, args)
+	}
+	values := make([]driver.Value, len(args))
+	for i, a := range args {
+		values[i] = a.Value
+	}
+	return s.Stmt.Exec(values) //nolint:staticcheck
+}
+
+func (s *textStmt) CheckNamedValue(nv *driver.NamedValue) error {
+	if n, ok := s.Stmt.(driver.NamedValueChecker); ok {
+		return n.CheckNamedValue(nv)
+	}
+	return driver.ErrSkip
+}
+
+func newTextRows(rows driver.Rows, err error) (driver.Rows, error) {
+	if err != nil {
+		return nil, err
+	}
+	r := &textRows{Rows: rows}
+	if t, ok := rows.(driver.RowsColumnTypeDatabaseTypeName); ok {
+		r.kinds = make([]columnKind, len(rows.Columns()))
+		for i := range r.kinds {
+			r.kinds[i] = kindOfColumn(t.ColumnTypeDatabaseTypeName(i))
+		}
+	}
+	return r, nil
+}
+
+// kindOfColumn keeps binary columns as raw bytes
+func kindOfColumn(typeName string) columnKind {
+	switch {
+	case strings.Contains(typeName, "BINARY"), strings.Contains(typeName, "BLOB"), typeName == "BIT", typeName == "GEOMETRY":
+		return columnRaw
+	case strings.HasSuffix(typeName, "INT"):
+		return columnInteger
+	default:
+		return columnString
+	}
+}
+
+func (r *textRows) Next(dest []driver.Value) error {
+	if err := r.Rows.Next(dest); err != nil {
+		return err
+	}
+	for i, kind := range r.kinds {
+		b, ok := dest[i].([]byte)
+		if !ok {
+			continue
+		}
+		switch kind {
+		case columnString:
+			dest[i] = string(b)
+		case columnInteger:
+			v, err := strconv.ParseInt(string(b), 10, 64)
+			if err != nil {
+				return err
+			}
+			dest[i] = v
+		}
+	}
+	return nil
+}
@@ -0,0 +1,270 @@
+// Copyright © 2024 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mysql
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+type mockConnector struct {
+	dsn    string
+	driver driver.Driver
+	err    error
+}
+
+func (mc *mockConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	if mc.err != nil {
+		return nil, mc.err
+	}
+	return mc.driver.Open(mc.dsn)
+}
+
+func (mc *mockConnector) Driver() driver.Driver {
+	return mc.driver
+}
+
+// bareConn only implements the required methods of a driver connection, as do the statements and rows it returns
+type bareConn struct {
+	valid bool
+}
+
+type bareStmt struct{}
+
+type bareRows struct{}
+
+type bareResult struct{}
+
+func (bc *bareConn) Prepare(query string) (driver.Stmt, error) {
+	if query == "bad" {
+		return nil, fmt.Errorf("pop")
+	}
+	return &bareStmt{}, nil
+}
+
+func (bc *bareConn) Close() error { return nil }
+
+func (bc *bareConn) Begin() (driver.Tx, error) { return nil, fmt.Errorf("pop") }
+
+func (bs *bareStmt) Close() error { return nil }
+
+func (bs *bareStmt) NumInput() int { return -1 }
+
+func (bs *bareStmt) Exec(args []driver.Value) (driver.Result, error) { return &bareResult{}, nil }
+
+func (bs *bareStmt) Query(args []driver.Value) (driver.Rows, error) { return &bareRows{}, nil }
+
+func (br *bareRows) Columns() []string { return []string{"col1"} }
+
+func (br *bareRows) Close() error { return nil }
+
+func (br *bareRows) Next(dest []driver.Value) error {
+	dest[0] = []byte("bytes")
+	return nil
+}
+
+func (br *bareResult) LastInsertId() (int64, error) { return 12345, nil }
+
+func (br *bareResult) RowsAffected() (int64, error) { return 1, nil }
+
+// validConn also checks the session before it is reused, and its statements check their arguments
+type validConn struct {
+	bareConn
+}
+
+type checkedStmt struct {
+	bareStmt
+}
+
+func (cs *checkedStmt) CheckNamedValue(nv *driver.NamedValue) error { return nil }
+
+func (vc *validConn) Prepare(query string) (driver.Stmt, error) {
+	return &checkedStmt{}, nil
+}
+
+func (vc *validConn) ResetSession(ctx context.Context) error { return fmt.Errorf("pop") }
+
+func (vc *validConn) IsValid() bool { return vc.valid }
+
+func newTextMockDB(t *testing.T) (*sql.DB, sqlmock.Sqlmock) {
+	dsn := t.Name()
+	mdb, mock, err := sqlmock.NewWithDSN(dsn)
+	assert.NoError(t, err)
+	db := sql.OpenDB(&textConnector{Connector: &mockConnector{dsn: dsn, driver: mdb.Driver()}})
+	t.Cleanup(func() {
+		db.Close()
+		mdb.Close()
+	})
+	return db, mock
+}
+
+func textRowDefinition() *sqlmock.Rows {
+	return sqlmock.NewRowsWithColumnDefinition(
+		sqlmock.NewColumn("id").OfType("CHAR", ""),
+		sqlmock.NewColumn("seq").OfType("BIGINT", int64(0)),
+		sqlmock.NewColumn("confirmed").OfType("TINYINT", int64(0)),
+		sqlmock.NewColumn("payload").OfType("BLOB", []byte{}),
+		sqlmock.NewColumn("value").OfType("LONGTEXT", ""),
+	)
+}
+
+func TestTextRowsQuery(t *testing.T) {
+	db, mock := newTextMockDB(t)
+	mock.ExpectQuery("SELECT .*").WillReturnRows(
+		textRowDefinition().AddRow([]byte("id1"), []byte("12"), int64(1), []byte{0x01}, nil),
+	)
+	var id, seq, confirmed, payload, value interface{}
+	err := db.QueryRow("SELECT id, seq, confirmed, payload, value FROM test").Scan(&id, &seq, &confirmed, &payload, &value)
+	assert.NoError(t, err)
+	assert.Equal(t, "id1", id)
+	assert.Equal(t, int64(12), seq)
+	assert.Equal(t, int64(1), confirmed)
+	assert.Equal(t, []byte{0x01}, payload)
+	assert.Nil(t, value)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestTextRowsBadInteger(t *testing.T) {
+	db, mock := newTextMockDB(t)
+	mock.ExpectQuery("SELECT .*").WillReturnRows(
+		textRowDefinition().AddRow([]byte("id1"), []byte("twelve"), int64(1), nil, nil),
+	)
+	var id, seq, confirmed, payload, value interface{}
+	err := db.QueryRow("SELECT id, seq, confirmed, payload, value FROM test").Scan(&id, &seq, &confirmed, &payload, &value)
+	assert.Regexp(t, "invalid syntax", err)
+}
+
+func TestTextRowsMultiple(t *testing.T) {
+	db, mock := newTextMockDB(t)
+	mock.ExpectQuery("SELECT .*").WillReturnRows(
+		sqlmock.NewRowsWithColumnDefinition(sqlmock.NewColumn("seq").OfType("BIGINT", int64(0))).
+			AddRow([]byte("1")).
+			AddRow([]byte("2")),
+	)
+	rows, err := db.Query("SELECT seq FROM test")
+	assert.NoError(t, err)
+	defer rows.Close()
+	seqs := []int64{}
+	for rows.Next() {
+		var seq int64
+		assert.NoError(t, rows.Scan(&seq))
+		seqs = append(seqs, seq)
+	}
+	assert.NoError(t, rows.Err())
+	assert.Equal(t, []int64{1, 2}, seqs)
+}
+
+func TestTextRowsNoColumnTypes(t *testing.T) {
+	db, mock := newTextMockDB(t)
+	mock.ExpectQuery("SELECT .*").WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow([]byte("id1")))
+	var id interface{}
+	err := db.QueryRow("SELECT id FROM test").Scan(&id)
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("id1"), id)
+}
+
+func TestTextRowsQueryFail(t *testing.T) {
+	db, mock := newTextMockDB(t)
+	mock.ExpectQuery("SELECT .*").WillReturnError(fmt.Errorf("pop"))
+	_, err := db.Query("SELECT id FROM test")
+	assert.Regexp(t, "pop", err)
+}
+
+func TestTextRowsPreparedStatement(t *testing.T) {
+	db, mock := newTextMockDB(t)
+	mock.ExpectBegin()
+	prepared := mock.ExpectPrepare("SELECT .*")
+	prepared.ExpectQuery().WithArgs("id1").WillReturnRows(
+		textRowDefinition().AddRow([]byte("id1"), int64(12), int64(0), nil, []byte(`{"a":1}`)),
+	)
+	prepared.ExpectExec().WithArgs("id1").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("INSERT .*").WithArgs("id2").WillReturnResult(sqlmock.NewResult(13, 1))
+	mock.ExpectCommit()
+
+	tx, err := db.BeginTx(context.Background(), &sql.TxOptions{Isolation: sql.LevelReadCommitted})
+	assert.NoError(t, err)
+	stmt, err := tx.Prepare("SELECT id, seq, confirmed, payload, value FROM test WHERE id = ?")
+	assert.NoError(t, err)
+	var id, seq, confirmed, payload, value interface{}
+	err = stmt.QueryRow("id1").Scan(&id, &seq, &confirmed, &payload, &value)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(12), seq)
+	assert.Equal(t, `{"a":1}`, value)
+	_, err = stmt.Exec("id1")
+	assert.NoError(t, err)
+	res, err := tx.Exec("INSERT INTO test (id) VALUES (?)", "id2")
+	assert.NoError(t, err)
+	seqInserted, _ := res.LastInsertId()
+	assert.Equal(t, int64(13), seqInserted)
+	err = tx.Commit()
+	assert.NoError(t, err)
+	assert.NoError(t, db.Ping())
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestTextConnectFail(t *testing.T) {
+	db := sql.OpenDB(&textConnector{Connector: &mockConnector{err: fmt.Errorf("pop")}})
+	defer db.Close()
+	assert.Regexp(t, "pop", db.Ping())
+}
+
+func TestTextConnBare(t *testing.T) {
+	ctx := context.Background()
+	conn := &textConn{Conn: &bareConn{}}
+
+	_, err := conn.QueryContext(ctx, "SELECT 1", nil)
+	assert.Equal(t, driver.ErrSkip, err)
+	_, err = conn.ExecContext(ctx, "DELETE FROM test", nil)
+	assert.Equal(t, driver.ErrSkip, err)
+	assert.Equal(t, driver.ErrSkip, conn.CheckNamedValue(&driver.NamedValue{}))
+	assert.NoError(t, conn.Ping(ctx))
+	assert.NoError(t, conn.ResetSession(ctx))
+	assert.True(t, conn.IsValid())
+	_, err = conn.BeginTx(ctx, driver.TxOptions{})
+	assert.Regexp(t, "pop", err)
+	_, err = conn.Prepare("bad")
+	assert.Regexp(t, "pop", err)
+
+	stmt, err := conn.Prepare("SELECT col1 FROM test")
+	assert.NoError(t, err)
+	assert.Equal(t, driver.ErrSkip, stmt.(driver.NamedValueChecker).CheckNamedValue(&driver.NamedValue{}))
+	rows, err := stmt.(driver.StmtQueryContext).QueryContext(ctx, []driver.NamedValue{{Ordinal: 1, Value: "val1"}})
+	assert.NoError(t, err)
+	dest := make([]driver.Value, 1)
+	assert.NoError(t, rows.Next(dest))
+	assert.Equal(t, []byte("bytes"), dest[0])
+	res, err := stmt.(driver.StmtExecContext).ExecContext(ctx, []driver.NamedValue{{Ordinal: 1, Value: "val1"}})
+	assert.NoError(t, err)
+	seq, _ := res.LastInsertId()
+	assert.Equal(t, int64(12345), seq)
+}
+
+func TestTextConnSessionChecks(t *testing.T) {
+	ctx := context.Background()
+	conn := &textConn{Conn: &validConn{}}
+	assert.Regexp(t, "pop", conn.ResetSession(ctx))
+	assert.False(t, conn.IsValid())
+	stmt, err := conn.Prepare("SELECT col1 FROM test")
+	assert.NoError(t, err)
+	assert.NoError(t, stmt.(driver.NamedValueChecker).CheckNamedValue(&driver.NamedValue{}))
+}
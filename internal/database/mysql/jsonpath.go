@@ -0,0 +1,47 @@
+// Copyright © 2024 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mysql
+
+import (
+	"fmt"
+	"strings"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/hyperledger/firefly/internal/database/sqlcommon"
+)
+
+// JSONPathText uses JSON_UNQUOTE, which returns the text of strings without their quotes.
+// A JSON null is returned as NULL, rather than the text "null".
+func (mysql *MySQL) JSONPathText(column string, path sqlcommon.JSONPath) string {
+	var mysqlPath strings.Builder
+	mysqlPath.WriteRune('$')
+	for _, p := range path {
+		switch p := p.(type) {
+		case int:
+			fmt.Fprintf(&mysqlPath, "[%d]", p)
+		default:
+			fmt.Fprintf(&mysqlPath, `."%s"`, p)
+		}
+	}
+	return fmt.Sprintf(`(CASE JSON_TYPE(JSON_EXTRACT(%[1]s, '%[2]s')) WHEN 'NULL' THEN NULL ELSE JSON_UNQUOTE(JSON_EXTRACT(%[1]s, '%[2]s')) END)`,
+		column, mysqlPath.String())
+}
+
+// JSONPathContains returns no condition, as the JSON is held in text columns that cannot be indexed
+func (mysql *MySQL) JSONPathContains(column string, path sqlcommon.JSONPath, match string) sq.Sqlizer {
+	return nil
+}
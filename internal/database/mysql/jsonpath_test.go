@@ -0,0 +1,35 @@
+// Copyright © 2024 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mysql
+
+import (
+	"testing"
+
+	"github.com/hyperledger/firefly/internal/database/sqlcommon"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestJSONPathText(t *testing.T) {
+	mysql := &MySQL{}
+	assert.Equal(t, `(CASE JSON_TYPE(JSON_EXTRACT(d.value, '$."invoice"."lines"[0]."sku"')) WHEN 'NULL' THEN NULL ELSE JSON_UNQUOTE(JSON_EXTRACT(d.value, '$."invoice"."lines"[0]."sku"')) END)`,
+		mysql.JSONPathText("d.value", sqlcommon.JSONPath{"invoice", "lines", 0, "sku"}))
+}
+
+func TestJSONPathContains(t *testing.T) {
+	mysql := &MySQL{}
+	assert.Nil(t, mysql.JSONPathContains("value", sqlcommon.JSONPath{"invoice", "number"}, "INV-001"))
+}
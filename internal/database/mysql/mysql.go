@@ -0,0 +1,116 @@
+// Copyright © 2024 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mysql
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"strings"
+
+	sq "github.com/Masterminds/squirrel"
+	mysqldriver "github.com/go-sql-driver/mysql"
+	migratedb "github.com/golang-migrate/migrate/v4/database"
+	migratemysql "github.com/golang-migrate/migrate/v4/database/mysql"
+	"github.com/hyperledger/firefly-common/pkg/config"
+	"github.com/hyperledger/firefly-common/pkg/dbsql"
+	"github.com/hyperledger/firefly/internal/database/sqlcommon"
+	"github.com/hyperledger/firefly/pkg/database"
+)
+
+// lockNameEscaper escapes a lock name as a string literal, in the default SQL mode where backslash is an escape character
+var lockNameEscaper = strings.NewReplacer(`\`, `\\`, `'`, `''`)
+
+type MySQL struct {
+	sqlcommon.SQLCommon
+}
+
+func (mysql *MySQL) Init(ctx context.Context, config config.Section) error {
+	capabilities := &database.Capabilities{}
+	return mysql.SQLCommon.Init(ctx, mysql, config, capabilities)
+}
+
+func (mysql *MySQL) SetHandler(namespace string, handler database.Callbacks) {
+	mysql.SQLCommon.SetHandler(namespace, handler)
+}
+
+func (mysql *MySQL) Name() string {
+	return "mysql"
+}
+
+func (mysql *MySQL) SequenceColumn() string {
+	return "seq"
+}
+
+func (mysql *MySQL) MigrationsDir() string {
+	return mysql.Name()
+}
+
+func (mysql *MySQL) Features() dbsql.SQLFeatures {
+	features := dbsql.DefaultSQLProviderFeatures()
+	features.PlaceholderFormat = sq.Question
+	features.UseILIKE = false // Not supported
+	// The insert takes an exclusive lock on the row for the name, which is held until the transaction ends
+	features.AcquireLock = func(lockName string) string {
+		return fmt.Sprintf(`INSERT INTO locks (name) VALUES ('%s') ON DUPLICATE KEY UPDATE name = name;`, lockNameEscaper.Replace(lockName))
+	}
+	// The auto-increment values of the rows in one insert are only consecutive in some lock modes,
+	// so each row is inserted on its own to read back its sequence
+	features.MultiRowInsert = false
+	return features
+}
+
+// ApplyInsertQueryCustomizations leaves the insert unchanged, and the sequence is read from the last insert ID.
+// On a conflict InnoDB only rolls back the failed statement, so callers can read the existing row in the same transaction.
+func (mysql *MySQL) ApplyInsertQueryCustomizations(insert sq.InsertBuilder, requestConflictEmptyResult bool) (sq.InsertBuilder, bool) {
+	return insert, false
+}
+
+func (mysql *MySQL) Open(url string) (*sql.DB, error) {
+	var connector driver.Connector
+	cfg, err := connectionConfig(url)
+	if err == nil {
+		connector, err = mysqldriver.NewConnector(cfg)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return sql.OpenDB(&textConnector{Connector: connector}), nil
+}
+
+// connectionConfig adds ANSI_QUOTES to the SQL mode of each connection, so names that are reserved words
+// in MySQL (such as "key" and "groups") can be quoted in the same way as in the other databases
+func connectionConfig(url string) (*mysqldriver.Config, error) {
+	cfg, err := mysqldriver.ParseDSN(url)
+	if err != nil {
+		return nil, err
+	}
+	sqlMode := "@@sql_mode"
+	if mode, ok := cfg.Params["sql_mode"]; ok {
+		sqlMode = mode
+	}
+	if cfg.Params == nil {
+		cfg.Params = map[string]string{}
+	}
+	cfg.Params["sql_mode"] = fmt.Sprintf("CONCAT(%s, ',ANSI_QUOTES')", sqlMode)
+	return cfg, nil
+}
+
+func (mysql *MySQL) GetMigrationDriver(db *sql.DB) (migratedb.Driver, error) {
+	return migratemysql.WithInstance(db, &migratemysql.Config{})
+}
@@ -0,0 +1,78 @@
+// Copyright © 2024 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mysql
+
+import (
+	"context"
+	"testing"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/hyperledger/firefly-common/pkg/config"
+	"github.com/hyperledger/firefly/internal/database/sqlcommon"
+	"github.com/hyperledger/firefly/mocks/databasemocks"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMySQLProvider(t *testing.T) {
+	mysql := &MySQL{}
+	mysql.SetHandler("ns", &databasemocks.Callbacks{})
+	config := config.RootSection("unittest")
+	mysql.InitConfig(config)
+	config.Set(sqlcommon.SQLConfDatasourceURL, "firefly:pass@tcp(127.0.0.1:1)/firefly")
+	err := mysql.Init(context.Background(), config)
+	assert.NoError(t, err)
+	_, err = mysql.GetMigrationDriver(mysql.DB())
+	assert.Error(t, err)
+
+	assert.Equal(t, "mysql", mysql.Name())
+	assert.Equal(t, "mysql", mysql.MigrationsDir())
+	assert.Equal(t, "seq", mysql.SequenceColumn())
+	assert.Equal(t, sq.Question, mysql.Features().PlaceholderFormat)
+	assert.False(t, mysql.Features().MultiRowInsert)
+	assert.Equal(t, `INSERT INTO locks (name) VALUES ('leases_ns1') ON DUPLICATE KEY UPDATE name = name;`, mysql.Features().AcquireLock("leases_ns1"))
+	assert.Equal(t, `INSERT INTO locks (name) VALUES ('a''b\\c') ON DUPLICATE KEY UPDATE name = name;`, mysql.Features().AcquireLock(`a'b\c`))
+
+	insert := sq.Insert("test").Columns("col1").Values("val1")
+	insert, query := mysql.ApplyInsertQueryCustomizations(insert, true)
+	sql, _, err := insert.ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "INSERT INTO test (col1) VALUES (?)", sql)
+	assert.False(t, query)
+}
+
+func TestMySQLInitBadURL(t *testing.T) {
+	mysql := &MySQL{}
+	config := config.RootSection("unittest")
+	mysql.InitConfig(config)
+	config.Set(sqlcommon.SQLConfDatasourceURL, "!bad connection")
+	err := mysql.Init(context.Background(), config)
+	assert.Regexp(t, "FF00173", err)
+}
+
+func TestConnectionConfig(t *testing.T) {
+	cfg, err := connectionConfig("firefly:pass@tcp(mysql:3306)/firefly")
+	assert.NoError(t, err)
+	assert.Equal(t, "CONCAT(@@sql_mode, ',ANSI_QUOTES')", cfg.Params["sql_mode"])
+	assert.Equal(t, "mysql:3306", cfg.Addr)
+}
+
+func TestConnectionConfigSQLMode(t *testing.T) {
+	cfg, err := connectionConfig("firefly:pass@tcp(mysql:3306)/firefly?sql_mode='TRADITIONAL'&charset=utf8mb4")
+	assert.NoError(t, err)
+	assert.Equal(t, "CONCAT('TRADITIONAL', ',ANSI_QUOTES')", cfg.Params["sql_mode"])
+	assert.Equal(t, "utf8mb4", cfg.Params["charset"])
+}
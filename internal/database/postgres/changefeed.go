@@ -0,0 +1,191 @@
+// Copyright © 2024 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"sync"
+
+	"github.com/hyperledger/firefly-common/pkg/fftypes"
+	"github.com/hyperledger/firefly-common/pkg/log"
+	"github.com/hyperledger/firefly/pkg/core"
+	"github.com/hyperledger/firefly/pkg/database"
+	"github.com/lib/pq"
+)
+
+const changeFeedChannel = "ff_changes"
+
+type execer interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+}
+
+// changeFeed shares the creation of messages, events and pins between FireFly replicas using the same database,
+// so the pollers on every replica can react immediately rather than waiting for their poll timeouts.
+// Changes are only a prompt to query, so all but the latest sequence of each collection are coalesced before
+// they are published.
+type changeFeed struct {
+	enabled  bool
+	origin   string
+	db       execer
+	mux      sync.Mutex
+	handlers map[string]database.Callbacks
+	pending  map[changeKey]*changeEvent
+	publish  chan struct{}
+}
+
+type changeKey struct {
+	namespace  string
+	collection string
+}
+
+type changeEvent struct {
+	Origin     string               `json:"origin"`
+	Namespace  string               `json:"namespace"`
+	Collection string               `json:"collection"`
+	Type       core.ChangeEventType `json:"type"`
+	ID         *fftypes.UUID        `json:"id,omitempty"`
+	Sequence   int64                `json:"sequence"`
+}
+
+// changeFeedCallbacks passes the changes made by this replica to the handler of the namespace, and publishes them
+type changeFeedCallbacks struct {
+	psql    *Postgres
+	handler database.Callbacks
+}
+
+func (cb *changeFeedCallbacks) OrderedUUIDCollectionNSEvent(resType database.OrderedUUIDCollectionNS, eventType core.ChangeEventType, ns string, id *fftypes.UUID, sequence int64) {
+	cb.handler.OrderedUUIDCollectionNSEvent(resType, eventType, ns, id, sequence)
+	if eventType == core.ChangeEventTypeCreated {
+		cb.psql.queueChange(&changeEvent{Namespace: ns, Collection: string(resType), Type: eventType, ID: id, Sequence: sequence})
+	}
+}
+
+func (cb *changeFeedCallbacks) OrderedCollectionNSEvent(resType database.OrderedCollectionNS, eventType core.ChangeEventType, ns string, sequence int64) {
+	cb.handler.OrderedCollectionNSEvent(resType, eventType, ns, sequence)
+	if eventType == core.ChangeEventTypeCreated {
+		cb.psql.queueChange(&changeEvent{Namespace: ns, Collection: string(resType), Type: eventType, Sequence: sequence})
+	}
+}
+
+func (cb *changeFeedCallbacks) UUIDCollectionNSEvent(resType database.UUIDCollectionNS, eventType core.ChangeEventType, ns string, id *fftypes.UUID) {
+	cb.handler.UUIDCollectionNSEvent(resType, eventType, ns, id)
+}
+
+func (cb *changeFeedCallbacks) HashCollectionNSEvent(resType database.HashCollectionNS, eventType core.ChangeEventType, ns string, hash *fftypes.Bytes32) {
+	cb.handler.HashCollectionNSEvent(resType, eventType, ns, hash)
+}
+
+func (psql *Postgres) startChangeFeed() {
+	cf := &psql.changeFeed
+	cf.origin = fftypes.NewUUID().String()
+	cf.db = psql.DB()
+	cf.pending = make(map[changeKey]*changeEvent)
+	cf.publish = make(chan struct{}, 1)
+	go psql.changePublishLoop()
+
+	n := &psql.notifier
+	n.mux.Lock()
+	defer n.mux.Unlock()
+	psql.startListener()
+}
+
+// setChangeHandler returns the handler to register for local changes, which publishes them to the other replicas.
+// The handler itself is called for the changes made by the other replicas.
+func (psql *Postgres) setChangeHandler(namespace string, handler database.Callbacks) database.Callbacks {
+	cf := &psql.changeFeed
+	if !cf.enabled || namespace == database.GlobalHandler {
+		return handler
+	}
+	cf.mux.Lock()
+	defer cf.mux.Unlock()
+	if cf.handlers == nil {
+		cf.handlers = make(map[string]database.Callbacks)
+	}
+	if handler == nil {
+		delete(cf.handlers, namespace)
+		return nil
+	}
+	cf.handlers[namespace] = handler
+	return &changeFeedCallbacks{psql: psql, handler: handler}
+}
+
+func (psql *Postgres) queueChange(change *changeEvent) {
+	cf := &psql.changeFeed
+	change.Origin = cf.origin
+	key := changeKey{namespace: change.Namespace, collection: change.Collection}
+	cf.mux.Lock()
+	if existing := cf.pending[key]; existing == nil || change.Sequence > existing.Sequence {
+		cf.pending[key] = change
+	}
+	cf.mux.Unlock()
+	select {
+	case cf.publish <- struct{}{}:
+	default:
+		// The publisher has not yet picked up the previous change
+	}
+}
+
+func (psql *Postgres) changePublishLoop() {
+	cf := &psql.changeFeed
+	for {
+		select {
+		case <-cf.publish:
+		case <-psql.ctx.Done():
+			log.L(psql.ctx).Debugf("Change feed publisher exiting")
+			return
+		}
+		cf.mux.Lock()
+		pending := cf.pending
+		cf.pending = make(map[changeKey]*changeEvent)
+		cf.mux.Unlock()
+		for _, change := range pending {
+			payload, _ := json.Marshal(change)
+			if _, err := cf.db.ExecContext(psql.ctx, `SELECT pg_notify($1, $2)`, changeFeedChannel, string(payload)); err != nil {
+				// The other replicas fall back to polling
+				log.L(psql.ctx).Errorf("Failed to publish change to %s: %s", change.Collection, err)
+			}
+		}
+	}
+}
+
+func (psql *Postgres) dispatchChange(pqn *pq.Notification) {
+	cf := &psql.changeFeed
+	var change changeEvent
+	if err := json.Unmarshal([]byte(pqn.Extra), &change); err != nil {
+		log.L(psql.ctx).Errorf("Invalid change feed payload '%s': %s", pqn.Extra, err)
+		return
+	}
+	if change.Origin == cf.origin {
+		// Our own changes have already been passed to the handler
+		return
+	}
+	cf.mux.Lock()
+	handler := cf.handlers[change.Namespace]
+	cf.mux.Unlock()
+	if handler == nil {
+		return
+	}
+	log.L(psql.ctx).Tracef("Change from replica %s: %s %s %d", change.Origin, change.Collection, change.Type, change.Sequence)
+	switch change.Collection {
+	case string(database.CollectionMessages), string(database.CollectionEvents):
+		handler.OrderedUUIDCollectionNSEvent(database.OrderedUUIDCollectionNS(change.Collection), change.Type, change.Namespace, change.ID, change.Sequence)
+	case string(database.CollectionPins):
+		handler.OrderedCollectionNSEvent(database.OrderedCollectionNS(change.Collection), change.Type, change.Namespace, change.Sequence)
+	}
+}
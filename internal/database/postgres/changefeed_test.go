@@ -0,0 +1,175 @@
+// Copyright © 2024 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/hyperledger/firefly-common/pkg/config"
+	"github.com/hyperledger/firefly-common/pkg/fftypes"
+	"github.com/hyperledger/firefly/internal/database/sqlcommon"
+	"github.com/hyperledger/firefly/mocks/databasemocks"
+	"github.com/hyperledger/firefly/pkg/core"
+	"github.com/hyperledger/firefly/pkg/database"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestChangeFeed(t *testing.T) (*Postgres, sqlmock.Sqlmock, func()) {
+	psql := &Postgres{}
+	config := config.RootSection("unittest_changefeed")
+	psql.InitConfig(config)
+	config.Set(sqlcommon.SQLConfDatasourceURL, "postgres://localhost:1/unreachable?sslmode=disable&connect_timeout=1")
+	config.Set(PostgresConfChangeFeedEnabled, true)
+	ctx, cancel := context.WithCancel(context.Background())
+	err := psql.Init(ctx, config)
+	assert.NoError(t, err)
+	db, mdb, err := sqlmock.New()
+	assert.NoError(t, err)
+	psql.changeFeed.mux.Lock()
+	psql.changeFeed.db = db
+	psql.changeFeed.mux.Unlock()
+	return psql, mdb, func() {
+		cancel()
+		psql.Close()
+	}
+}
+
+func TestChangeFeedSetHandler(t *testing.T) {
+	psql, _, done := newTestChangeFeed(t)
+	defer done()
+
+	mcb := &databasemocks.Callbacks{}
+	psql.SetHandler(database.GlobalHandler, mcb)
+	assert.Empty(t, psql.changeFeed.handlers)
+
+	psql.SetHandler("ns1", mcb)
+	assert.Equal(t, mcb, psql.changeFeed.handlers["ns1"])
+
+	psql.SetHandler("ns1", nil)
+	assert.Empty(t, psql.changeFeed.handlers)
+}
+
+func TestChangeFeedDisabled(t *testing.T) {
+	psql := newTestPostgres(t)
+	defer psql.Close()
+
+	psql.SetHandler("ns1", &databasemocks.Callbacks{})
+	assert.Nil(t, psql.changeFeed.handlers)
+}
+
+func TestChangeFeedPublish(t *testing.T) {
+	psql, mdb, done := newTestChangeFeed(t)
+	defer done()
+
+	mcb := &databasemocks.Callbacks{}
+	msgID := fftypes.NewUUID()
+	mcb.On("OrderedUUIDCollectionNSEvent", database.CollectionMessages, core.ChangeEventTypeCreated, "ns1", msgID, int64(10)).Return()
+	mcb.On("OrderedUUIDCollectionNSEvent", database.CollectionMessages, core.ChangeEventTypeUpdated, "ns1", msgID, int64(10)).Return()
+	mcb.On("OrderedCollectionNSEvent", database.CollectionPins, core.ChangeEventTypeCreated, "ns1", int64(20)).Return()
+	mcb.On("UUIDCollectionNSEvent", database.CollectionOperations, core.ChangeEventTypeCreated, "ns1", msgID).Return()
+	mcb.On("HashCollectionNSEvent", database.CollectionGroups, core.ChangeEventTypeCreated, "ns1", (*fftypes.Bytes32)(nil)).Return()
+
+	cb := psql.setChangeHandler("ns1", mcb)
+
+	mdb.ExpectExec("SELECT pg_notify").
+		WithArgs(changeFeedChannel, sqlmock.AnyArg()).
+		WillReturnError(fmt.Errorf("pop"))
+	mdb.ExpectExec("SELECT pg_notify").
+		WithArgs(changeFeedChannel, sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	cb.OrderedUUIDCollectionNSEvent(database.CollectionMessages, core.ChangeEventTypeCreated, "ns1", msgID, 10)
+	cb.OrderedCollectionNSEvent(database.CollectionPins, core.ChangeEventTypeCreated, "ns1", 20)
+
+	// Updates and other collections are not published
+	cb.OrderedUUIDCollectionNSEvent(database.CollectionMessages, core.ChangeEventTypeUpdated, "ns1", msgID, 10)
+	cb.UUIDCollectionNSEvent(database.CollectionOperations, core.ChangeEventTypeCreated, "ns1", msgID)
+	cb.HashCollectionNSEvent(database.CollectionGroups, core.ChangeEventTypeCreated, "ns1", nil)
+
+	for {
+		if err := mdb.ExpectationsWereMet(); err == nil {
+			break
+		}
+		time.Sleep(1 * time.Millisecond)
+	}
+	mcb.AssertExpectations(t)
+}
+
+func TestChangeFeedQueueCoalesce(t *testing.T) {
+	psql := &Postgres{ctx: context.Background()}
+	psql.changeFeed.pending = make(map[changeKey]*changeEvent)
+	psql.changeFeed.publish = make(chan struct{}, 1)
+
+	psql.queueChange(&changeEvent{Namespace: "ns1", Collection: "events", Sequence: 2})
+	psql.queueChange(&changeEvent{Namespace: "ns1", Collection: "events", Sequence: 1})
+	psql.queueChange(&changeEvent{Namespace: "ns1", Collection: "events", Sequence: 3})
+	psql.queueChange(&changeEvent{Namespace: "ns2", Collection: "events", Sequence: 1})
+
+	assert.Len(t, psql.changeFeed.pending, 2)
+	assert.Equal(t, int64(3), psql.changeFeed.pending[changeKey{namespace: "ns1", collection: "events"}].Sequence)
+	assert.Len(t, psql.changeFeed.publish, 1)
+}
+
+func TestChangeFeedDispatch(t *testing.T) {
+	psql, _, done := newTestChangeFeed(t)
+	defer done()
+
+	mcb := &databasemocks.Callbacks{}
+	psql.SetHandler("ns1", mcb)
+
+	eventID := fftypes.NewUUID()
+	mcb.On("OrderedUUIDCollectionNSEvent", database.CollectionEvents, core.ChangeEventTypeCreated, "ns1", eventID, int64(10)).Return()
+	mcb.On("OrderedCollectionNSEvent", database.CollectionPins, core.ChangeEventTypeCreated, "ns1", int64(20)).Return()
+
+	psql.dispatchChange(&pq.Notification{Channel: changeFeedChannel, Extra: fmt.Sprintf(
+		`{"origin":"other","namespace":"ns1","collection":"events","type":"created","id":"%s","sequence":10}`, eventID)})
+	psql.dispatchChange(&pq.Notification{Channel: changeFeedChannel, Extra: `{"origin":"other","namespace":"ns1","collection":"pins","type":"created","sequence":20}`})
+
+	// Ignored: our own changes, other namespaces, other collections and bad payloads
+	psql.dispatchChange(&pq.Notification{Channel: changeFeedChannel, Extra: fmt.Sprintf(
+		`{"origin":"%s","namespace":"ns1","collection":"pins","type":"created","sequence":30}`, psql.changeFeed.origin)})
+	psql.dispatchChange(&pq.Notification{Channel: changeFeedChannel, Extra: `{"origin":"other","namespace":"ns2","collection":"pins","type":"created","sequence":30}`})
+	psql.dispatchChange(&pq.Notification{Channel: changeFeedChannel, Extra: `{"origin":"other","namespace":"ns1","collection":"groups","type":"created","sequence":30}`})
+	psql.dispatchChange(&pq.Notification{Channel: changeFeedChannel, Extra: `!json`})
+
+	mcb.AssertExpectations(t)
+}
+
+func TestChangeFeedListenFail(t *testing.T) {
+	psql, _, done := newTestChangeFeed(t)
+	defer done()
+	listener := pq.NewListener(psql.url, notifyMinReconnectInterval, notifyMaxReconnectInterval, nil)
+	listener.Close()
+	psql.notificationLoop(listener)
+}
+
+func TestChangeFeedInitFail(t *testing.T) {
+	psql := &Postgres{}
+	config := config.RootSection("unittest_changefeed_fail")
+	psql.InitConfig(config)
+	config.Set(sqlcommon.SQLConfDatasourceURL, "!bad connection")
+	config.Set(sqlcommon.SQLConfMigrationsAuto, true)
+	config.Set(PostgresConfChangeFeedEnabled, true)
+	err := psql.Init(context.Background(), config)
+	assert.Error(t, err)
+	assert.Nil(t, psql.changeFeed.publish)
+}
@@ -23,9 +23,14 @@ import (
 
 const (
 	defaultConnectionLimitPostgreSQL = 50
+
+	// PostgresConfChangeFeedEnabled shares new messages, events and pins with other replicas using LISTEN/NOTIFY
+	PostgresConfChangeFeedEnabled = "changeFeed.enabled"
 )
 
 func (psql *Postgres) InitConfig(config config.Section) {
 	psql.SQLCommon.InitConfig(psql, config)
 	config.SetDefault(sqlcommon.SQLConfMaxConnections, defaultConnectionLimitPostgreSQL)
+	config.SubSection(sqlcommon.SQLConfReadReplica).SetDefault(sqlcommon.SQLConfMaxConnections, defaultConnectionLimitPostgreSQL)
+	config.AddKnownKey(PostgresConfChangeFeedEnabled, false)
 }
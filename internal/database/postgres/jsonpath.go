@@ -0,0 +1,76 @@
+// Copyright © 2024 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package postgres
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/hyperledger/firefly/internal/database/sqlcommon"
+)
+
+// JSONPathText uses the #>> operator, which returns the text of strings without their quotes
+func (psql *Postgres) JSONPathText(column string, path sqlcommon.JSONPath) string {
+	elements := make([]string, len(path))
+	for i, p := range path {
+		switch p := p.(type) {
+		case int:
+			elements[i] = fmt.Sprintf("%d", p)
+		default:
+			elements[i] = fmt.Sprintf(`"%s"`, p)
+		}
+	}
+	return fmt.Sprintf("(%s::jsonb #>> '{%s}')", column, strings.Join(elements, ","))
+}
+
+// JSONPathContains uses the @> containment operator, which can use a GIN index on the JSONB of the column.
+// A document is contained if any element of an array matches, whatever its index, so the caller must still
+// check the value at the path.
+func (psql *Postgres) JSONPathContains(column string, path sqlcommon.JSONPath, match string) sq.Sqlizer {
+	condition := fmt.Sprintf("%s::jsonb @> ?::jsonb", column)
+	contains := sq.Or{sq.Expr(condition, jsonPathDocument(path, match))}
+
+	// The text of a number or boolean is the same as the text of the match, but it is not contained as a string
+	if json.Valid([]byte(match)) {
+		var typed interface{}
+		dec := json.NewDecoder(bytes.NewReader([]byte(match)))
+		dec.UseNumber()
+		_ = dec.Decode(&typed)
+		switch typed.(type) {
+		case json.Number, bool:
+			contains = append(contains, sq.Expr(condition, jsonPathDocument(path, typed)))
+		}
+	}
+	return contains
+}
+
+func jsonPathDocument(path sqlcommon.JSONPath, leaf interface{}) string {
+	doc := leaf
+	for i := len(path) - 1; i >= 0; i-- {
+		switch p := path[i].(type) {
+		case int:
+			doc = []interface{}{doc}
+		default:
+			doc = map[string]interface{}{p.(string): doc}
+		}
+	}
+	b, _ := json.Marshal(doc)
+	return string(b)
+}
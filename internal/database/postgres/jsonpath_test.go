@@ -0,0 +1,60 @@
+// Copyright © 2024 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package postgres
+
+import (
+	"testing"
+
+	"github.com/hyperledger/firefly/internal/database/sqlcommon"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestJSONPathText(t *testing.T) {
+	psql := &Postgres{}
+	assert.Equal(t, `(d.value::jsonb #>> '{"invoice","lines",0,"sku"}')`,
+		psql.JSONPathText("d.value", sqlcommon.JSONPath{"invoice", "lines", 0, "sku"}))
+}
+
+func TestJSONPathContainsString(t *testing.T) {
+	psql := &Postgres{}
+	query, args, err := psql.JSONPathContains("value", sqlcommon.JSONPath{"invoice", "number"}, "INV-001").ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "(value::jsonb @> ?::jsonb)", query)
+	assert.Equal(t, []interface{}{`{"invoice":{"number":"INV-001"}}`}, args)
+}
+
+func TestJSONPathContainsNumber(t *testing.T) {
+	psql := &Postgres{}
+	query, args, err := psql.JSONPathContains("value", sqlcommon.JSONPath{"lines", 1, "qty"}, "12.50").ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "(value::jsonb @> ?::jsonb OR value::jsonb @> ?::jsonb)", query)
+	assert.Equal(t, []interface{}{`{"lines":[{"qty":"12.50"}]}`, `{"lines":[{"qty":12.50}]}`}, args)
+}
+
+func TestJSONPathContainsBool(t *testing.T) {
+	psql := &Postgres{}
+	_, args, err := psql.JSONPathContains("value", sqlcommon.JSONPath{"paid"}, "true").ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, []interface{}{`{"paid":"true"}`, `{"paid":true}`}, args)
+}
+
+func TestJSONPathContainsJSONString(t *testing.T) {
+	psql := &Postgres{}
+	_, args, err := psql.JSONPathContains("value", sqlcommon.JSONPath{"name"}, `"quoted"`).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, []interface{}{`{"name":"\"quoted\""}`}, args)
+}
@@ -0,0 +1,22 @@
+// Copyright © 2024 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package postgres
+
+// LeaseNow scales the seconds since the epoch of the start of the transaction, which have microsecond precision
+func (psql *Postgres) LeaseNow() string {
+	return "(EXTRACT(EPOCH FROM CURRENT_TIMESTAMP) * 1000000000)::bigint"
+}
@@ -0,0 +1,144 @@
+// Copyright © 2024 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/hyperledger/firefly-common/pkg/log"
+	"github.com/hyperledger/firefly/pkg/core"
+	"github.com/lib/pq"
+)
+
+const (
+	// The channel name is fixed in the migration that creates the trigger on the clusternotifications table
+	clusterNotificationsChannel = "ff_clusternotifications"
+	notifyMinReconnectInterval  = 1 * time.Second
+	notifyMaxReconnectInterval  = 1 * time.Minute
+)
+
+type clusterListener struct {
+	namespace string
+	topic     string
+	ch        chan *core.ClusterNotification
+}
+
+type notifier struct {
+	mux       sync.Mutex
+	listener  *pq.Listener
+	listeners map[*clusterListener]bool
+}
+
+// ListenClusterNotifications uses a single LISTEN connection to PostgreSQL for the whole process,
+// which is established on first use and fanned out to all the registered listeners.
+func (psql *Postgres) ListenClusterNotifications(ctx context.Context, namespace, topic string) (<-chan *core.ClusterNotification, error) {
+	n := &psql.notifier
+	n.mux.Lock()
+	defer n.mux.Unlock()
+
+	psql.startListener()
+
+	cl := &clusterListener{
+		namespace: namespace,
+		topic:     topic,
+		// Notifications are only a prompt to query, so we only need to buffer one
+		ch: make(chan *core.ClusterNotification, 1),
+	}
+	n.listeners[cl] = true
+	go func() {
+		<-ctx.Done()
+		n.mux.Lock()
+		defer n.mux.Unlock()
+		delete(n.listeners, cl)
+	}()
+	return cl.ch, nil
+}
+
+// startListener must be called with the notifier lock held
+func (psql *Postgres) startListener() {
+	n := &psql.notifier
+	if n.listener == nil {
+		n.listeners = make(map[*clusterListener]bool)
+		n.listener = pq.NewListener(psql.url, notifyMinReconnectInterval, notifyMaxReconnectInterval, func(event pq.ListenerEventType, err error) {
+			if err != nil {
+				log.L(psql.ctx).Errorf("Cluster notification listener error (event=%d): %s", event, err)
+			}
+		})
+		go psql.notificationLoop(n.listener)
+	}
+}
+
+func (psql *Postgres) notificationLoop(listener *pq.Listener) {
+	channels := []string{clusterNotificationsChannel}
+	if psql.changeFeed.enabled {
+		channels = append(channels, changeFeedChannel)
+	}
+	for _, channel := range channels {
+		// Listen blocks until the connection is established
+		if err := listener.Listen(channel); err != nil {
+			log.L(psql.ctx).Errorf("Failed to listen for notifications on channel '%s': %s", channel, err)
+			return
+		}
+		log.L(psql.ctx).Infof("Listening for notifications on channel '%s'", channel)
+	}
+	for pqn := range listener.NotificationChannel() {
+		if pqn != nil && pqn.Channel == changeFeedChannel {
+			psql.dispatchChange(pqn)
+		} else {
+			psql.dispatchNotification(pqn)
+		}
+	}
+}
+
+func (psql *Postgres) dispatchNotification(pqn *pq.Notification) {
+	var notification *core.ClusterNotification
+	if pqn != nil {
+		notification = &core.ClusterNotification{}
+		if err := json.Unmarshal([]byte(pqn.Extra), notification); err != nil {
+			log.L(psql.ctx).Errorf("Invalid cluster notification payload '%s': %s", pqn.Extra, err)
+			return
+		}
+	}
+
+	n := &psql.notifier
+	n.mux.Lock()
+	defer n.mux.Unlock()
+	for cl := range n.listeners {
+		// A nil notification means the connection was re-established, and notifications might have been missed
+		if notification == nil || (notification.Namespace == cl.namespace && notification.Topic == cl.topic) {
+			select {
+			case cl.ch <- notification:
+			default:
+				// There is already a notification pending for this listener
+			}
+		}
+	}
+}
+
+func (psql *Postgres) Close() {
+	n := &psql.notifier
+	n.mux.Lock()
+	if n.listener != nil {
+		_ = n.listener.Close()
+		n.listener = nil
+	}
+	n.mux.Unlock()
+	psql.SQLCommon.Close()
+}
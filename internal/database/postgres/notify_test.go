@@ -0,0 +1,90 @@
+// Copyright © 2024 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package postgres
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/firefly-common/pkg/config"
+	"github.com/hyperledger/firefly/internal/database/sqlcommon"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestPostgres(t *testing.T) *Postgres {
+	psql := &Postgres{}
+	config := config.RootSection("unittest")
+	psql.InitConfig(config)
+	config.Set(sqlcommon.SQLConfDatasourceURL, "postgres://localhost:1/unreachable?sslmode=disable&connect_timeout=1")
+	err := psql.Init(context.Background(), config)
+	assert.NoError(t, err)
+	return psql
+}
+
+func TestListenClusterNotificationsDispatch(t *testing.T) {
+	psql := newTestPostgres(t)
+	defer psql.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	ch1, err := psql.ListenClusterNotifications(ctx, "ns1", "topic1")
+	assert.NoError(t, err)
+	ch2, err := psql.ListenClusterNotifications(context.Background(), "ns2", "topic1")
+	assert.NoError(t, err)
+
+	psql.dispatchNotification(&pq.Notification{Extra: `{"namespace":"ns1","topic":"topic1","sequence":12345}`})
+	n := <-ch1
+	assert.Equal(t, int64(12345), n.Sequence)
+	assert.Empty(t, ch2)
+
+	// Only one notification is buffered
+	psql.dispatchNotification(&pq.Notification{Extra: `{"namespace":"ns1","topic":"topic1","sequence":12346}`})
+	psql.dispatchNotification(&pq.Notification{Extra: `{"namespace":"ns1","topic":"topic1","sequence":12347}`})
+	n = <-ch1
+	assert.Equal(t, int64(12346), n.Sequence)
+	assert.Empty(t, ch1)
+
+	// Bad payloads are ignored
+	psql.dispatchNotification(&pq.Notification{Extra: `!json`})
+	assert.Empty(t, ch1)
+	assert.Empty(t, ch2)
+
+	// Reconnects are delivered to everybody
+	psql.dispatchNotification(nil)
+	assert.Nil(t, <-ch1)
+	assert.Nil(t, <-ch2)
+
+	cancel()
+	for {
+		psql.notifier.mux.Lock()
+		remaining := len(psql.notifier.listeners)
+		psql.notifier.mux.Unlock()
+		if remaining == 1 {
+			break
+		}
+		time.Sleep(1 * time.Millisecond)
+	}
+}
+
+func TestNotificationLoopClosed(t *testing.T) {
+	psql := newTestPostgres(t)
+	listener := pq.NewListener(psql.url, notifyMinReconnectInterval, notifyMaxReconnectInterval, nil)
+	listener.Close()
+	psql.notificationLoop(listener)
+	psql.Close()
+}
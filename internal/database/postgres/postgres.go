@@ -37,18 +37,47 @@ import (
 
 type Postgres struct {
 	sqlcommon.SQLCommon
+
+	ctx        context.Context
+	url        string
+	notifier   notifier
+	changeFeed changeFeed
 }
 
 func (psql *Postgres) Init(ctx context.Context, config config.Section) error {
+	psql.ctx = ctx
+	psql.url = config.GetString(sqlcommon.SQLConfDatasourceURL)
 	capabilities := &database.Capabilities{}
 	if config.GetInt(dbsql.SQLConfMaxConnections) > 1 {
 		capabilities.Concurrency = true
 	}
-	return psql.SQLCommon.Init(ctx, psql, config, capabilities)
+	psql.changeFeed.enabled = config.GetBool(PostgresConfChangeFeedEnabled)
+	if err := psql.SQLCommon.Init(ctx, psql, config, capabilities); err != nil {
+		return err
+	}
+	if psql.changeFeed.enabled {
+		psql.startChangeFeed()
+	}
+	return nil
 }
 
 func (psql *Postgres) SetHandler(namespace string, handler database.Callbacks) {
-	psql.SQLCommon.SetHandler(namespace, handler)
+	psql.SQLCommon.SetHandler(namespace, psql.setChangeHandler(namespace, handler))
+}
+
+// RunAsReadSnapshot runs the reads in a REPEATABLE READ transaction. In the default READ COMMITTED
+// isolation level, each statement sees the records committed before that statement started.
+func (psql *Postgres) RunAsReadSnapshot(ctx context.Context, fn func(ctx context.Context) error) error {
+	if dbsql.GetTXFromContext(ctx) != nil {
+		// The isolation level cannot be changed once a transaction has started
+		return fn(ctx)
+	}
+	return psql.RunAsGroup(ctx, func(ctx context.Context) error {
+		if _, err := psql.ExecTx(ctx, "snapshot", dbsql.GetTXFromContext(ctx), "SET TRANSACTION ISOLATION LEVEL REPEATABLE READ READ ONLY", nil); err != nil {
+			return err
+		}
+		return fn(ctx)
+	})
 }
 
 func (psql *Postgres) Name() string {
@@ -18,12 +18,16 @@ package postgres
 
 import (
 	"context"
+	"database/sql"
+	"fmt"
 	"testing"
 
+	"github.com/DATA-DOG/go-sqlmock"
 	sq "github.com/Masterminds/squirrel"
 	"github.com/hyperledger/firefly-common/pkg/config"
 	"github.com/hyperledger/firefly/internal/database/sqlcommon"
 	"github.com/hyperledger/firefly/mocks/databasemocks"
+	"github.com/hyperledger/firefly/pkg/database"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -51,3 +55,54 @@ func TestPostgresProvider(t *testing.T) {
 	assert.Equal(t, "INSERT INTO test (col1) VALUES (?)  ON CONFLICT DO NOTHING RETURNING seq", sql)
 	assert.True(t, query)
 }
+
+type mockOpenPostgres struct {
+	*Postgres
+	db *sql.DB
+}
+
+func (mp *mockOpenPostgres) Open(url string) (*sql.DB, error) {
+	return mp.db, nil
+}
+
+func newMockPostgres(t *testing.T) (*Postgres, sqlmock.Sqlmock) {
+	psql := &Postgres{}
+	config := config.RootSection("unittest_snapshot")
+	psql.InitConfig(config)
+	config.Set(sqlcommon.SQLConfDatasourceURL, "mock")
+	db, mdb, err := sqlmock.New()
+	assert.NoError(t, err)
+	err = psql.SQLCommon.Init(context.Background(), &mockOpenPostgres{Postgres: psql, db: db}, config, &database.Capabilities{})
+	assert.NoError(t, err)
+	return psql, mdb
+}
+
+func TestRunAsReadSnapshot(t *testing.T) {
+	psql, mdb := newMockPostgres(t)
+	mdb.ExpectBegin()
+	mdb.ExpectExec("SET TRANSACTION ISOLATION LEVEL REPEATABLE READ READ ONLY").WillReturnResult(sqlmock.NewResult(0, 0))
+	mdb.ExpectCommit()
+	called := false
+	err := psql.RunAsReadSnapshot(context.Background(), func(ctx context.Context) error {
+		// Nested calls use the same transaction
+		return psql.RunAsReadSnapshot(ctx, func(ctx context.Context) error {
+			called = true
+			return nil
+		})
+	})
+	assert.NoError(t, err)
+	assert.True(t, called)
+	assert.NoError(t, mdb.ExpectationsWereMet())
+}
+
+func TestRunAsReadSnapshotFail(t *testing.T) {
+	psql, mdb := newMockPostgres(t)
+	mdb.ExpectBegin()
+	mdb.ExpectExec("SET TRANSACTION.*").WillReturnError(fmt.Errorf("pop"))
+	mdb.ExpectRollback()
+	err := psql.RunAsReadSnapshot(context.Background(), func(ctx context.Context) error {
+		return nil
+	})
+	assert.Regexp(t, "FF00245", err)
+	assert.NoError(t, mdb.ExpectationsWereMet())
+}
@@ -0,0 +1,164 @@
+// Copyright © 2024 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqlcommon
+
+import (
+	"context"
+	"database/sql"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/hyperledger/firefly-common/pkg/ffapi"
+	"github.com/hyperledger/firefly-common/pkg/fftypes"
+	"github.com/hyperledger/firefly-common/pkg/i18n"
+	"github.com/hyperledger/firefly-common/pkg/log"
+	"github.com/hyperledger/firefly/internal/coremsgs"
+	"github.com/hyperledger/firefly/pkg/core"
+	"github.com/hyperledger/firefly/pkg/database"
+)
+
+var (
+	apiKeyColumns = []string{
+		"id",
+		"namespace",
+		"name",
+		"roles",
+		"key_hash",
+		"expires",
+		"created",
+	}
+	apiKeyFilterFieldMap = map[string]string{}
+)
+
+const apiKeysTable = "apikeys"
+
+func (s *SQLCommon) InsertAPIKey(ctx context.Context, apiKey *core.APIKey) (err error) {
+	ctx, tx, autoCommit, err := s.BeginOrUseTx(ctx)
+	if err != nil {
+		return err
+	}
+	defer s.RollbackTx(ctx, tx, autoCommit)
+
+	if _, err = s.InsertTx(ctx, apiKeysTable, tx,
+		sq.Insert(apiKeysTable).
+			Columns(apiKeyColumns...).
+			Values(
+				apiKey.ID,
+				apiKey.Namespace,
+				apiKey.Name,
+				apiKey.Roles,
+				apiKey.Hash,
+				apiKey.Expires,
+				apiKey.Created,
+			),
+		func() {
+			s.callbacks.UUIDCollectionNSEvent(database.CollectionAPIKeys, core.ChangeEventTypeCreated, apiKey.Namespace, apiKey.ID)
+		},
+	); err != nil {
+		return err
+	}
+
+	return s.CommitTx(ctx, tx, autoCommit)
+}
+
+func (s *SQLCommon) apiKeyResult(ctx context.Context, row *sql.Rows) (*core.APIKey, error) {
+	var apiKey core.APIKey
+	err := row.Scan(
+		&apiKey.ID,
+		&apiKey.Namespace,
+		&apiKey.Name,
+		&apiKey.Roles,
+		&apiKey.Hash,
+		&apiKey.Expires,
+		&apiKey.Created,
+	)
+	if err != nil {
+		return nil, i18n.WrapError(ctx, err, coremsgs.MsgDBReadErr, apiKeysTable)
+	}
+	return &apiKey, nil
+}
+
+func (s *SQLCommon) getAPIKeyEq(ctx context.Context, eq sq.Eq, textName string) (*core.APIKey, error) {
+	rows, _, err := s.Query(ctx, apiKeysTable,
+		sq.Select(apiKeyColumns...).
+			From(apiKeysTable).
+			Where(eq),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	if !rows.Next() {
+		log.L(ctx).Debugf("API key '%s' not found", textName)
+		return nil, nil
+	}
+
+	return s.apiKeyResult(ctx, rows)
+}
+
+func (s *SQLCommon) GetAPIKeyByID(ctx context.Context, namespace string, id *fftypes.UUID) (*core.APIKey, error) {
+	return s.getAPIKeyEq(ctx, sq.Eq{"id": id, "namespace": namespace}, id.String())
+}
+
+func (s *SQLCommon) GetAPIKeyByHash(ctx context.Context, namespace, hash string) (*core.APIKey, error) {
+	return s.getAPIKeyEq(ctx, sq.Eq{"key_hash": hash, "namespace": namespace}, hash)
+}
+
+func (s *SQLCommon) GetAPIKeys(ctx context.Context, namespace string, filter ffapi.Filter) (apiKeys []*core.APIKey, res *ffapi.FilterResult, err error) {
+	query, fop, fi, err := s.FilterSelect(ctx, "", sq.Select(apiKeyColumns...).From(apiKeysTable),
+		filter, apiKeyFilterFieldMap, []interface{}{"sequence"}, sq.Eq{"namespace": namespace})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	rows, tx, err := s.Query(ctx, apiKeysTable, query)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close()
+
+	apiKeys = []*core.APIKey{}
+	for rows.Next() {
+		k, err := s.apiKeyResult(ctx, rows)
+		if err != nil {
+			return nil, nil, err
+		}
+		apiKeys = append(apiKeys, k)
+	}
+
+	return apiKeys, s.QueryRes(ctx, apiKeysTable, tx, fop, nil, fi), err
+}
+
+func (s *SQLCommon) DeleteAPIKey(ctx context.Context, namespace string, id *fftypes.UUID) (err error) {
+	ctx, tx, autoCommit, err := s.BeginOrUseTx(ctx)
+	if err != nil {
+		return err
+	}
+	defer s.RollbackTx(ctx, tx, autoCommit)
+
+	err = s.DeleteTx(ctx, apiKeysTable, tx, sq.Delete(apiKeysTable).Where(sq.Eq{
+		"id": id, "namespace": namespace,
+	}),
+		func() {
+			s.callbacks.UUIDCollectionNSEvent(database.CollectionAPIKeys, core.ChangeEventTypeDeleted, namespace, id)
+		})
+	if err != nil {
+		return err
+	}
+
+	return s.CommitTx(ctx, tx, autoCommit)
+}
@@ -0,0 +1,164 @@
+// Copyright © 2024 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqlcommon
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/hyperledger/firefly-common/pkg/fftypes"
+	"github.com/hyperledger/firefly/pkg/core"
+	"github.com/hyperledger/firefly/pkg/database"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAPIKeysE2EWithDB(t *testing.T) {
+	s, cleanup := newSQLiteTestProvider(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	// Create a new API key
+	apiKey := &core.APIKey{
+		ID:        fftypes.NewUUID(),
+		Namespace: "ns1",
+		Name:      "app1",
+		Roles:     fftypes.FFStringArray{"reader", "writer"},
+		Hash:      "7a38bf81f383f69433ad6e900d35b3e2385593f76a7b7ab5d4355b8ba41ee24b",
+		Expires:   fftypes.Now(),
+		Created:   fftypes.Now(),
+	}
+
+	s.callbacks.On("UUIDCollectionNSEvent", database.CollectionAPIKeys, core.ChangeEventTypeCreated, "ns1", apiKey.ID).Return()
+	s.callbacks.On("UUIDCollectionNSEvent", database.CollectionAPIKeys, core.ChangeEventTypeDeleted, "ns1", apiKey.ID).Return()
+
+	err := s.InsertAPIKey(ctx, apiKey)
+	assert.NoError(t, err)
+
+	// Check we get the exact same entry back, by ID and by hash
+	apiKeyJson, _ := json.Marshal(&apiKey)
+	apiKeyRead, err := s.GetAPIKeyByID(ctx, "ns1", apiKey.ID)
+	assert.NoError(t, err)
+	apiKeyReadJson, _ := json.Marshal(&apiKeyRead)
+	assert.Equal(t, string(apiKeyJson), string(apiKeyReadJson))
+	assert.Equal(t, apiKey.Hash, apiKeyRead.Hash)
+	apiKeyRead, err = s.GetAPIKeyByHash(ctx, "ns1", apiKey.Hash)
+	assert.NoError(t, err)
+	assert.Equal(t, apiKey.ID, apiKeyRead.ID)
+
+	// Keys are only found in their own namespace
+	apiKeyRead, err = s.GetAPIKeyByHash(ctx, "ns2", apiKey.Hash)
+	assert.NoError(t, err)
+	assert.Nil(t, apiKeyRead)
+
+	// Query back the entry
+	fb := database.APIKeyQueryFactory.NewFilter(ctx)
+	filter := fb.And(
+		fb.Eq("name", "app1"),
+		fb.Contains("roles", "writer"),
+	)
+	apiKeys, res, err := s.GetAPIKeys(ctx, "ns1", filter.Count(true))
+	assert.NoError(t, err)
+	assert.Len(t, apiKeys, 1)
+	assert.Equal(t, int64(1), *res.TotalCount)
+
+	// Delete
+	err = s.DeleteAPIKey(ctx, "ns1", apiKey.ID)
+	assert.NoError(t, err)
+	apiKeyRead, err = s.GetAPIKeyByID(ctx, "ns1", apiKey.ID)
+	assert.NoError(t, err)
+	assert.Nil(t, apiKeyRead)
+
+	s.callbacks.AssertExpectations(t)
+}
+
+func TestInsertAPIKeyFailBegin(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin().WillReturnError(fmt.Errorf("pop"))
+	err := s.InsertAPIKey(context.Background(), &core.APIKey{})
+	assert.Regexp(t, "FF00175", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestInsertAPIKeyFailInsert(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin()
+	mock.ExpectExec("INSERT .*").WillReturnError(fmt.Errorf("pop"))
+	mock.ExpectRollback()
+	err := s.InsertAPIKey(context.Background(), &core.APIKey{})
+	assert.Regexp(t, "FF00177", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetAPIKeyByIDSelectFail(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectQuery("SELECT .*").WillReturnError(fmt.Errorf("pop"))
+	_, err := s.GetAPIKeyByID(context.Background(), "ns1", fftypes.NewUUID())
+	assert.Regexp(t, "FF00176", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetAPIKeyByHashScanFail(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectQuery("SELECT .*").WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("only one"))
+	_, err := s.GetAPIKeyByHash(context.Background(), "ns1", "hash1")
+	assert.Regexp(t, "FF10121", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetAPIKeysBuildQueryFail(t *testing.T) {
+	s, _ := newMockProvider().init()
+	f := database.APIKeyQueryFactory.NewFilter(context.Background()).Eq("id", map[bool]bool{true: false})
+	_, _, err := s.GetAPIKeys(context.Background(), "ns1", f)
+	assert.Regexp(t, "FF00143.*id", err)
+}
+
+func TestGetAPIKeysQueryFail(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectQuery("SELECT .*").WillReturnError(fmt.Errorf("pop"))
+	f := database.APIKeyQueryFactory.NewFilter(context.Background()).Eq("name", "")
+	_, _, err := s.GetAPIKeys(context.Background(), "ns1", f)
+	assert.Regexp(t, "FF00176", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetAPIKeysReadFail(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectQuery("SELECT .*").WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("only one"))
+	f := database.APIKeyQueryFactory.NewFilter(context.Background()).Eq("name", "")
+	_, _, err := s.GetAPIKeys(context.Background(), "ns1", f)
+	assert.Regexp(t, "FF10121", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAPIKeyDeleteBeginFail(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin().WillReturnError(fmt.Errorf("pop"))
+	err := s.DeleteAPIKey(context.Background(), "ns1", fftypes.NewUUID())
+	assert.Regexp(t, "FF00175", err)
+}
+
+func TestAPIKeyDeleteFail(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin()
+	mock.ExpectExec("DELETE .*").WillReturnError(fmt.Errorf("pop"))
+	mock.ExpectRollback()
+	err := s.DeleteAPIKey(context.Background(), "ns1", fftypes.NewUUID())
+	assert.Regexp(t, "FF00179", err)
+}
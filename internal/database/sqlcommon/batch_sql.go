@@ -36,7 +36,7 @@ var (
 		"btype",
 		"namespace",
 		"author",
-		"key",
+		`"key"`,
 		"group_hash",
 		"created",
 		"hash",
@@ -45,12 +45,14 @@ var (
 		"tx_type",
 		"tx_id",
 		"node_id",
+		"trace_context",
 	}
 	batchFilterFieldMap = map[string]string{
 		"type":    "btype",
 		"tx.type": "tx_type",
 		"tx.id":   "tx_id",
 		"group":   "group_hash",
+		"key":     `"key"`,
 		"node":    "node_id",
 	}
 )
@@ -82,6 +84,7 @@ func (s *SQLCommon) InsertOrGetBatch(ctx context.Context, batch *core.BatchPersi
 				batch.TX.Type,
 				batch.TX.ID,
 				batch.Node,
+				batch.TraceContext,
 			),
 		func() {
 			s.callbacks.UUIDCollectionNSEvent(database.CollectionBatches, core.ChangeEventTypeCreated, batch.Namespace, batch.ID)
@@ -118,6 +121,7 @@ func (s *SQLCommon) batchResult(ctx context.Context, row *sql.Rows) (*core.Batch
 		&batch.TX.Type,
 		&batch.TX.ID,
 		&batch.Node,
+		&batch.TraceContext,
 	)
 	if err != nil {
 		return nil, i18n.WrapError(ctx, err, coremsgs.MsgDBReadErr, batchesTable)
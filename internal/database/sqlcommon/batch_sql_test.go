@@ -55,6 +55,7 @@ func TestBatch2EWithDB(t *testing.T) {
 		TX: core.TransactionRef{
 			Type: core.TransactionTypeUnpinned,
 		},
+		TraceContext: fftypes.JSONObject{"traceparent": "00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01"},
 		Manifest: fftypes.JSONAnyPtr((&core.BatchManifest{
 			Messages: []*core.MessageManifestEntry{
 				{MessageRef: core.MessageRef{ID: msgID1}},
@@ -45,6 +45,7 @@ var (
 		"tx_type",
 		"tx_id",
 		"tx_blockchain_id",
+		"removed",
 	}
 	blockchainEventFilterFieldMap = map[string]string{
 		"protocolid":      "protocol_id",
@@ -71,6 +72,7 @@ func (s *SQLCommon) setBlockchainEventInsertValues(query sq.InsertBuilder, event
 		event.TX.Type,
 		event.TX.ID,
 		event.TX.BlockchainID,
+		event.Removed,
 	)
 }
 
@@ -161,6 +163,9 @@ func (s *SQLCommon) blockchainEventResult(ctx context.Context, row *sql.Rows) (*
 		&event.TX.Type,
 		&event.TX.ID,
 		&event.TX.BlockchainID,
+		&event.Removed,
+		// Must be added to the list of columns in all selects
+		&event.Sequence,
 	)
 	if err != nil {
 		return nil, i18n.WrapError(ctx, err, coremsgs.MsgDBReadErr, blockchaineventsTable)
@@ -169,8 +174,10 @@ func (s *SQLCommon) blockchainEventResult(ctx context.Context, row *sql.Rows) (*
 }
 
 func (s *SQLCommon) getBlockchainEventPred(ctx context.Context, desc string, pred interface{}) (*core.BlockchainEvent, error) {
+	cols := append([]string{}, blockchainEventColumns...)
+	cols = append(cols, s.SequenceColumn())
 	rows, _, err := s.Query(ctx, blockchaineventsTable,
-		sq.Select(blockchainEventColumns...).
+		sq.Select(cols...).
 			From(blockchaineventsTable).
 			Where(pred),
 	)
@@ -204,10 +211,35 @@ func (s *SQLCommon) GetBlockchainEventByProtocolID(ctx context.Context, ns strin
 	})
 }
 
+func (s *SQLCommon) UpdateBlockchainEvent(ctx context.Context, namespace string, id *fftypes.UUID, update ffapi.Update) (err error) {
+	ctx, tx, autoCommit, err := s.BeginOrUseTx(ctx)
+	if err != nil {
+		return err
+	}
+	defer s.RollbackTx(ctx, tx, autoCommit)
+
+	query, err := s.BuildUpdate(sq.Update(blockchaineventsTable), update, blockchainEventFilterFieldMap)
+	if err != nil {
+		return err
+	}
+	query = query.Where(sq.Eq{"id": id, "namespace": namespace})
+
+	_, err = s.UpdateTx(ctx, blockchaineventsTable, tx, query, func() {
+		s.callbacks.UUIDCollectionNSEvent(database.CollectionBlockchainEvents, core.ChangeEventTypeUpdated, namespace, id)
+	})
+	if err != nil {
+		return err
+	}
+
+	return s.CommitTx(ctx, tx, autoCommit)
+}
+
 func (s *SQLCommon) GetBlockchainEvents(ctx context.Context, namespace string, filter ffapi.Filter) ([]*core.BlockchainEvent, *ffapi.FilterResult, error) {
 
+	cols := append([]string{}, blockchainEventColumns...)
+	cols = append(cols, s.SequenceColumn())
 	query, fop, fi, err := s.FilterSelect(ctx, "",
-		sq.Select(blockchainEventColumns...).From(blockchaineventsTable),
+		sq.Select(cols...).From(blockchaineventsTable),
 		filter, blockchainEventFilterFieldMap, []interface{}{"sequence"}, sq.Eq{"namespace": namespace})
 	if err != nil {
 		return nil, nil, err
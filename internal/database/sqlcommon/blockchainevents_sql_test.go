@@ -63,11 +63,13 @@ func TestBlockchainEventsE2EWithDB(t *testing.T) {
 	filter := fb.And(
 		fb.Eq("name", "Changed"),
 		fb.Eq("listener", event.Listener),
+		fb.Gt("sequence", 0),
 	)
 	events, res, err := s.GetBlockchainEvents(ctx, "ns", filter.Count(true))
 	assert.NoError(t, err)
 	assert.Equal(t, 1, len(events))
 	assert.Equal(t, int64(1), *res.TotalCount)
+	assert.Greater(t, events[0].Sequence, int64(0))
 	eventReadJson, _ := json.Marshal(events[0])
 	assert.Equal(t, string(eventJson), string(eventReadJson))
 
@@ -116,6 +118,16 @@ func TestBlockchainEventsE2EWithDB(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, event3.ID, existing.ID)
 
+	// Mark the event removed
+	s.callbacks.On("UUIDCollectionNSEvent", database.CollectionBlockchainEvents, core.ChangeEventTypeUpdated, "ns", event.ID).Return().Once()
+	err = s.UpdateBlockchainEvent(ctx, "ns", event.ID, database.BlockchainEventQueryFactory.NewUpdate(ctx).Set("removed", true))
+	assert.NoError(t, err)
+	events, _, err = s.GetBlockchainEvents(ctx, "ns", fb.And(fb.Eq("removed", true)))
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(events))
+	assert.True(t, events[0].Removed)
+	assert.Equal(t, event.ID, events[0].ID)
+
 }
 
 func TestInsertBlockchainEventFailBegin(t *testing.T) {
@@ -257,3 +269,33 @@ func TestGetBlockchainEventsScanFail(t *testing.T) {
 	assert.Regexp(t, "FF10121", err)
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
+
+func TestUpdateBlockchainEventBeginFail(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin().WillReturnError(fmt.Errorf("pop"))
+	ctx := context.Background()
+	err := s.UpdateBlockchainEvent(ctx, "ns1", fftypes.NewUUID(), database.BlockchainEventQueryFactory.NewUpdate(ctx).Set("removed", true))
+	assert.Regexp(t, "FF00175", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUpdateBlockchainEventBadUpdate(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin()
+	mock.ExpectRollback()
+	ctx := context.Background()
+	err := s.UpdateBlockchainEvent(ctx, "ns1", fftypes.NewUUID(), database.BlockchainEventQueryFactory.NewUpdate(ctx).Set("bad", true))
+	assert.Regexp(t, "FF00142", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUpdateBlockchainEventUpdateFail(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin()
+	mock.ExpectExec("UPDATE .*").WillReturnError(fmt.Errorf("pop"))
+	mock.ExpectRollback()
+	ctx := context.Background()
+	err := s.UpdateBlockchainEvent(ctx, "ns1", fftypes.NewUUID(), database.BlockchainEventQueryFactory.NewUpdate(ctx).Set("removed", true))
+	assert.Regexp(t, "FF00178", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
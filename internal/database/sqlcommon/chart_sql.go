@@ -19,10 +19,16 @@ package sqlcommon
 import (
 	"context"
 	"database/sql"
+	"fmt"
+	"math/big"
+	"sort"
 	"strconv"
+	"strings"
 
 	sq "github.com/Masterminds/squirrel"
 	"github.com/hyperledger/firefly-common/pkg/config"
+	"github.com/hyperledger/firefly-common/pkg/dbsql"
+	"github.com/hyperledger/firefly-common/pkg/fftypes"
 	"github.com/hyperledger/firefly-common/pkg/i18n"
 	"github.com/hyperledger/firefly/internal/coreconfig"
 	"github.com/hyperledger/firefly/internal/coremsgs"
@@ -164,3 +170,256 @@ func (s *SQLCommon) GetChartHistogram(ctx context.Context, ns string, intervals
 
 	return histogramList, nil
 }
+
+// chartBigIntColumns hold an FFBigInt, which is stored as zero-padded hex so that it sorts as a string
+var chartBigIntColumns = map[string]bool{
+	"tokentransfer.amount": true,
+}
+
+// None of the databases can convert the 64 hex digits of an FFBigInt to a number, so the value is split into
+// chunks of 8 digits that are summed separately, and the sums of the chunks are combined afterwards
+const (
+	chartHexDigits      = fftypes.MaxFFBigIntHexLength - 1
+	chartHexChunkDigits = 8
+	chartHexChunks      = (chartHexDigits + chartHexChunkDigits - 1) / chartHexChunkDigits
+)
+
+// chartProvider builds the SQL for chart aggregations that differs between databases.
+// SQLCommon provides the SQLite expressions, and other providers override them.
+type chartProvider interface {
+	// ChartHexValue returns an integer expression for the value of a text expression holding 8 lower case hex digits
+	ChartHexValue(hex string) string
+}
+
+func (s *SQLCommon) initChartProvider(provider dbsql.Provider) {
+	var ok bool
+	if s.chart, ok = provider.(chartProvider); !ok {
+		s.chart = s
+	}
+}
+
+// ChartHexValue looks up each digit in turn, as SQLite has no function to parse hex
+func (s *SQLCommon) ChartHexValue(hex string) string {
+	terms := make([]string, chartHexChunkDigits)
+	for i := range terms {
+		terms[i] = fmt.Sprintf("(instr('0123456789abcdef', substr(%s, %d, 1)) - 1) * %d", hex, i+1, 1<<(4*(chartHexChunkDigits-1-i)))
+	}
+	return "(" + strings.Join(terms, " + ") + ")"
+}
+
+// chartBigIntSums returns an expression for the sum of each chunk of an FFBigInt column, least significant first.
+// The sign of a negative value is stripped before the digits are split, and applied to each chunk. Offsets
+// are 1-based, as substr counts from 1 on all the databases.
+func (s *SQLCommon) chartBigIntSums(column string) []string {
+	sign := fmt.Sprintf("(CASE WHEN substr(%s, 1, 1) = '-' THEN -1 ELSE 1 END)", column)
+	digitsStart := fmt.Sprintf("(CASE WHEN substr(%s, 1, 1) = '-' THEN 2 ELSE 1 END)", column)
+	sums := make([]string, chartHexChunks)
+	for i := range sums {
+		offset := chartHexDigits - (i+1)*chartHexChunkDigits
+		chunk := fmt.Sprintf("substr(%s, %s + %d, %d)", column, digitsStart, offset, chartHexChunkDigits)
+		sums[i] = fmt.Sprintf("SUM(%s * %s)", sign, s.chart.ChartHexValue(chunk))
+	}
+	return sums
+}
+
+// chartSum combines the sums read for a group, which are NULL if no records in the group have a value
+func chartSum(sums []sql.NullString, bigInt bool) (*big.Rat, bool) {
+	if !bigInt {
+		if !sums[0].Valid {
+			return new(big.Rat), true
+		}
+		return new(big.Rat).SetString(sums[0].String)
+	}
+	total := new(big.Int)
+	for i, chunkSum := range sums {
+		if !chunkSum.Valid {
+			continue
+		}
+		v, ok := new(big.Int).SetString(chunkSum.String, 10)
+		if !ok {
+			return nil, false
+		}
+		total.Add(total, v.Lsh(v, uint(i*chartHexChunkDigits*4)))
+	}
+	return new(big.Rat).SetInt(total), true
+}
+
+type chartGroup struct {
+	dimensions map[string]string
+	count      int64
+	values     int64
+	sum        *big.Rat
+}
+
+func chartColumn(fieldMap map[string]string, field string) string {
+	if column, ok := fieldMap[field]; ok {
+		return column
+	}
+	return field
+}
+
+func chartValueString(r *big.Rat) string {
+	if r.IsInt() {
+		return r.Num().String()
+	}
+	return strings.TrimRight(r.FloatString(18), "0")
+}
+
+func (g *chartGroup) result(aggregate core.ChartAggregateType) *core.ChartAggregateGroup {
+	res := &core.ChartAggregateGroup{
+		Dimensions: g.dimensions,
+		Count:      strconv.FormatInt(g.count, 10),
+	}
+	switch aggregate {
+	case core.ChartAggregateTypeSum:
+		res.Value = chartValueString(g.sum)
+	case core.ChartAggregateTypeAvg:
+		avg := new(big.Rat)
+		if g.values > 0 {
+			avg.Quo(g.sum, new(big.Rat).SetInt64(g.values))
+		}
+		res.Value = chartValueString(avg)
+	default:
+		res.Value = res.Count
+	}
+	return res
+}
+
+// chartAggregateResult reads a row for each group in each bucket, and adds the groups to the aggregates of the buckets
+func (s *SQLCommon) chartAggregateResult(ctx context.Context, tableName string, rows *sql.Rows, query *database.ChartAggregateQuery, bigInt bool, aggregates []*core.ChartAggregate) error {
+	numSums := 1
+	if bigInt {
+		numSums = chartHexChunks
+	}
+	totals := make([]int64, len(aggregates))
+	for rows.Next() {
+		var bucket sql.NullInt64
+		var g chartGroup
+		dimensions := make([]sql.NullString, len(query.GroupBy))
+		sums := make([]sql.NullString, numSums)
+		dest := []interface{}{&bucket}
+		for i := range dimensions {
+			dest = append(dest, &dimensions[i])
+		}
+		dest = append(dest, &g.count)
+		if query.Field != "" {
+			dest = append(dest, &g.values)
+			for i := range sums {
+				dest = append(dest, &sums[i])
+			}
+		}
+		if err := rows.Scan(dest...); err != nil || bucket.Int64 < 0 || bucket.Int64 >= int64(len(aggregates)) {
+			return i18n.NewError(ctx, coremsgs.MsgDBReadErr, tableName)
+		}
+		if !bucket.Valid {
+			// The record falls in a gap between the intervals
+			continue
+		}
+
+		g.dimensions = map[string]string{}
+		for i, field := range query.GroupBy {
+			g.dimensions[field] = dimensions[i].String
+		}
+		var ok bool
+		if g.sum, ok = chartSum(sums, bigInt); !ok {
+			return i18n.NewError(ctx, coremsgs.MsgDBReadErr, tableName)
+		}
+		aggregates[bucket.Int64].Groups = append(aggregates[bucket.Int64].Groups, g.result(query.Aggregate))
+		totals[bucket.Int64] += g.count
+	}
+
+	for i, aggregate := range aggregates {
+		aggregate.Count = strconv.FormatInt(totals[i], 10)
+		sort.Slice(aggregate.Groups, func(a, b int) bool {
+			return chartGroupKey(query, aggregate.Groups[a]) < chartGroupKey(query, aggregate.Groups[b])
+		})
+	}
+	return nil
+}
+
+func chartGroupKey(query *database.ChartAggregateQuery, g *core.ChartAggregateGroup) string {
+	keys := make([]string, len(query.GroupBy))
+	for i, field := range query.GroupBy {
+		keys[i] = g.Dimensions[field]
+	}
+	return strings.Join(keys, "\x00")
+}
+
+// GetChartAggregates counts, sums or averages the records in every bucket with a single query. Each record is assigned
+// to its bucket with a CASE expression, and the records are grouped by their bucket and dimensions in the database.
+func (s *SQLCommon) GetChartAggregates(ctx context.Context, ns string, intervals []core.ChartHistogramInterval, collection database.CollectionName, query *database.ChartAggregateQuery) ([]*core.ChartAggregate, error) {
+	tableName, fieldMap, err := s.getTableNameFromCollection(ctx, collection)
+	if err != nil {
+		return nil, err
+	}
+
+	timestampKey := "created"
+	if tableName == "blockchainevents" {
+		timestampKey = "timestamp"
+	}
+
+	aggregates := make([]*core.ChartAggregate, len(intervals))
+	bucket := sq.Case()
+	for i, interval := range intervals {
+		aggregates[i] = &core.ChartAggregate{
+			Timestamp: interval.StartTime,
+			Groups:    []*core.ChartAggregateGroup{},
+		}
+		bucket = bucket.When(sq.And{
+			sq.GtOrEq{timestampKey: interval.StartTime},
+			sq.Lt{timestampKey: interval.EndTime},
+		}, strconv.Itoa(i))
+	}
+	if len(intervals) == 0 {
+		return aggregates, nil
+	}
+
+	sel := sq.Select().Column(sq.Alias(bucket, "bucket"))
+	groupBy := []string{"bucket"}
+	for _, field := range query.GroupBy {
+		if field == jsonPathField {
+			return nil, i18n.NewError(ctx, coremsgs.MsgChartInvalidField, field)
+		}
+		column := chartColumn(fieldMap, field)
+		sel = sel.Column(column)
+		groupBy = append(groupBy, column)
+	}
+	sel = sel.Column("COUNT(*)")
+	bigInt := false
+	if query.Field != "" {
+		column := chartColumn(fieldMap, query.Field)
+		sel = sel.Column(fmt.Sprintf("COUNT(%s)", column))
+		if bigInt = chartBigIntColumns[tableName+"."+column]; bigInt {
+			for _, sum := range s.chartBigIntSums(column) {
+				sel = sel.Column(sum)
+			}
+		} else {
+			sel = sel.Column(fmt.Sprintf("SUM(%s)", column))
+		}
+	}
+
+	filter := query.Filter
+	var conditions []sq.Sqlizer
+	if tableName == messagesTable {
+		filter, conditions = s.messageJSONPathFilter(ctx, ns, filter, "id")
+	}
+	preconditions := append([]sq.Sqlizer{
+		sq.Eq{"namespace": ns},
+		sq.GtOrEq{timestampKey: intervals[0].StartTime},
+		sq.Lt{timestampKey: intervals[len(intervals)-1].EndTime},
+	}, conditions...)
+	sel, _, _, err = s.FilterSelect(ctx, "", sel.From(tableName).GroupBy(groupBy...), filter, fieldMap, nil, preconditions...)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, _, err := s.Query(ctx, tableName, sel)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	if err := s.chartAggregateResult(ctx, tableName, rows, query, bigInt, aggregates); err != nil {
+		return nil, err
+	}
+	return aggregates, nil
+}
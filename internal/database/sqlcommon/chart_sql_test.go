@@ -18,7 +18,10 @@ package sqlcommon
 
 import (
 	"context"
+	"database/sql"
 	"fmt"
+	"math/big"
+	"strings"
 	"testing"
 
 	"github.com/DATA-DOG/go-sqlmock"
@@ -28,6 +31,7 @@ import (
 	"github.com/hyperledger/firefly/pkg/core"
 	"github.com/hyperledger/firefly/pkg/database"
 	"github.com/stretchr/testify/assert"
+	testmock "github.com/stretchr/testify/mock"
 )
 
 var (
@@ -252,3 +256,322 @@ func TestGetChartHistogramSuccessNoRowsOnlyTimestamp(t *testing.T) {
 	assert.Equal(t, emptyHistogramResult, histogram)
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
+
+func newTestChartTransfer(pool *fftypes.UUID, amount int64, created int64) *core.TokenTransfer {
+	t := &core.TokenTransfer{
+		Type:       core.TokenTransferTypeMint,
+		LocalID:    fftypes.NewUUID(),
+		Pool:       pool,
+		Namespace:  "ns1",
+		ProtocolID: fftypes.NewRandB32().String(),
+		Created:    fftypes.UnixTime(created),
+	}
+	t.Amount.Int().SetInt64(amount)
+	return t
+}
+
+func TestGetChartAggregatesTokenTransfersE2EWithDB(t *testing.T) {
+	coreconfig.Reset()
+	s, cleanup := newSQLiteTestProvider(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	s.callbacks.On("UUIDCollectionNSEvent", database.CollectionTokenTransfers, core.ChangeEventTypeCreated, "ns1", testmock.Anything).Return()
+	pool1 := fftypes.NewUUID()
+	pool2 := fftypes.NewUUID()
+	for _, transfer := range []*core.TokenTransfer{
+		newTestChartTransfer(pool1, 10, 1000000000),
+		newTestChartTransfer(pool1, 0x1000, 1000000000),
+		newTestChartTransfer(pool2, 5, 1000000000),
+		newTestChartTransfer(pool1, 7, 1000000001),
+	} {
+		_, err := s.InsertOrGetTokenTransfer(ctx, transfer)
+		assert.NoError(t, err)
+	}
+	intervals := []core.ChartHistogramInterval{
+		{StartTime: fftypes.UnixTime(1000000000), EndTime: fftypes.UnixTime(1000000001)},
+		{StartTime: fftypes.UnixTime(1000000001), EndTime: fftypes.UnixTime(1000000002)},
+	}
+
+	fb := database.TokenTransferQueryFactory.NewFilter(ctx)
+	aggregates, err := s.GetChartAggregates(ctx, "ns1", intervals, database.CollectionName(database.CollectionTokenTransfers), &database.ChartAggregateQuery{
+		Filter:    fb.And(fb.Eq("type", core.TokenTransferTypeMint)),
+		GroupBy:   []string{"pool"},
+		Aggregate: core.ChartAggregateTypeSum,
+		Field:     "amount",
+	})
+	assert.NoError(t, err)
+	assert.Len(t, aggregates, 2)
+	assert.Equal(t, "3", aggregates[0].Count)
+	assert.Len(t, aggregates[0].Groups, 2)
+	for _, g := range aggregates[0].Groups {
+		switch g.Dimensions["pool"] {
+		case pool1.String():
+			assert.Equal(t, "2", g.Count)
+			assert.Equal(t, "4106", g.Value)
+		default:
+			assert.Equal(t, pool2.String(), g.Dimensions["pool"])
+			assert.Equal(t, "5", g.Value)
+		}
+	}
+	assert.Equal(t, "1", aggregates[1].Count)
+	assert.Equal(t, "7", aggregates[1].Groups[0].Value)
+
+	aggregates, err = s.GetChartAggregates(ctx, "ns1", intervals[0:1], database.CollectionName(database.CollectionTokenTransfers), &database.ChartAggregateQuery{
+		Filter:    fb.And(),
+		Aggregate: core.ChartAggregateTypeAvg,
+		Field:     "amount",
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "3", aggregates[0].Groups[0].Count)
+	assert.Equal(t, "1370.333333333333333333", aggregates[0].Groups[0].Value)
+}
+
+func TestGetChartAggregatesTokenTransfersSignedAndWideE2EWithDB(t *testing.T) {
+	coreconfig.Reset()
+	s, cleanup := newSQLiteTestProvider(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	s.callbacks.On("UUIDCollectionNSEvent", database.CollectionTokenTransfers, core.ChangeEventTypeCreated, "ns1", testmock.Anything).Return()
+	pool1 := fftypes.NewUUID()
+	pool2 := fftypes.NewUUID()
+	wide := newTestChartTransfer(pool1, 0, 1000000000)
+	wide.Amount.Int().SetString(strings.Repeat("f", 63), 16)
+	wideNegative := newTestChartTransfer(pool1, 0, 1000000000)
+	wideNegative.Amount.Int().SetString("-1"+strings.Repeat("0", 62), 16)
+	for _, transfer := range []*core.TokenTransfer{
+		wide,
+		wideNegative,
+		newTestChartTransfer(pool1, -0x100000001, 1000000000),
+		newTestChartTransfer(pool2, -5, 1000000000),
+		newTestChartTransfer(pool2, 3, 1000000000),
+	} {
+		_, err := s.InsertOrGetTokenTransfer(ctx, transfer)
+		assert.NoError(t, err)
+	}
+	intervals := []core.ChartHistogramInterval{
+		{StartTime: fftypes.UnixTime(1000000000), EndTime: fftypes.UnixTime(1000000001)},
+	}
+
+	expected := new(big.Int).Add(wide.Amount.Int(), wideNegative.Amount.Int())
+	expected.Sub(expected, big.NewInt(0x100000001))
+	fb := database.TokenTransferQueryFactory.NewFilter(ctx)
+	aggregates, err := s.GetChartAggregates(ctx, "ns1", intervals, database.CollectionName(database.CollectionTokenTransfers), &database.ChartAggregateQuery{
+		Filter:    fb.And(),
+		GroupBy:   []string{"pool"},
+		Aggregate: core.ChartAggregateTypeSum,
+		Field:     "amount",
+	})
+	assert.NoError(t, err)
+	assert.Len(t, aggregates[0].Groups, 2)
+	for _, g := range aggregates[0].Groups {
+		switch g.Dimensions["pool"] {
+		case pool1.String():
+			assert.Equal(t, expected.String(), g.Value)
+		default:
+			assert.Equal(t, pool2.String(), g.Dimensions["pool"])
+			assert.Equal(t, "-2", g.Value)
+		}
+	}
+}
+
+func TestGetChartAggregatesOperationsE2EWithDB(t *testing.T) {
+	coreconfig.Reset()
+	s, cleanup := newSQLiteTestProvider(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	s.callbacks.On("UUIDCollectionNSEvent", database.CollectionOperations, core.ChangeEventTypeCreated, "ns1", testmock.Anything).Return()
+	for _, op := range []*core.Operation{
+		{ID: fftypes.NewUUID(), Transaction: fftypes.NewUUID(), Namespace: "ns1", Plugin: "ethereum", Status: core.OpStatusFailed, Type: core.OpTypeBlockchainInvoke},
+		{ID: fftypes.NewUUID(), Transaction: fftypes.NewUUID(), Namespace: "ns1", Plugin: "ethereum", Status: core.OpStatusSucceeded, Type: core.OpTypeBlockchainInvoke},
+		{ID: fftypes.NewUUID(), Transaction: fftypes.NewUUID(), Namespace: "ns1", Plugin: "erc1155", Status: core.OpStatusSucceeded, Type: core.OpTypeTokenTransfer},
+	} {
+		op.Created = fftypes.UnixTime(1000000000)
+		err := s.InsertOperation(ctx, op)
+		assert.NoError(t, err)
+	}
+
+	fb := database.OperationQueryFactory.NewFilter(ctx)
+	aggregates, err := s.GetChartAggregates(ctx, "ns1", mockHistogramInterval, database.CollectionName(database.CollectionOperations), &database.ChartAggregateQuery{
+		Filter:    fb.And(),
+		GroupBy:   []string{"plugin", "status"},
+		Aggregate: core.ChartAggregateTypeCount,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "3", aggregates[0].Count)
+	assert.Equal(t, []*core.ChartAggregateGroup{
+		{Dimensions: map[string]string{"plugin": "erc1155", "status": "Succeeded"}, Count: "1", Value: "1"},
+		{Dimensions: map[string]string{"plugin": "ethereum", "status": "Failed"}, Count: "1", Value: "1"},
+		{Dimensions: map[string]string{"plugin": "ethereum", "status": "Succeeded"}, Count: "1", Value: "1"},
+	}, aggregates[0].Groups)
+}
+
+func TestGetChartAggregatesMessagesJSONPathWithDB(t *testing.T) {
+	coreconfig.Reset()
+	s, cleanup := newSQLiteTestProvider(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	fb := database.MessageQueryFactory.NewFilter(ctx)
+	aggregates, err := s.GetChartAggregates(ctx, "ns1", mockHistogramInterval, database.CollectionName(database.CollectionMessages), &database.ChartAggregateQuery{
+		Filter:    fb.And(fb.Eq("jsonpath", "invoice.number:INV-001")),
+		Aggregate: core.ChartAggregateTypeCount,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "0", aggregates[0].Count)
+	assert.Empty(t, aggregates[0].Groups)
+
+	_, err = s.GetChartAggregates(ctx, "ns1", mockHistogramInterval, database.CollectionName(database.CollectionMessages), &database.ChartAggregateQuery{
+		Filter:  fb.And(),
+		GroupBy: []string{"jsonpath"},
+	})
+	assert.Regexp(t, "FF10518", err)
+}
+
+func TestGetChartAggregatesMoreRowsThanCapWithDB(t *testing.T) {
+	coreconfig.Reset()
+	config.Set(coreconfig.HistogramsMaxChartRows, 2)
+	defer coreconfig.Reset()
+	s, cleanup := newSQLiteTestProvider(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	s.callbacks.On("UUIDCollectionNSEvent", database.CollectionTokenTransfers, core.ChangeEventTypeCreated, "ns1", testmock.Anything).Return()
+	pool := fftypes.NewUUID()
+	large := newTestChartTransfer(pool, 0, 1000000000)
+	large.Amount.Int().Lsh(big.NewInt(1), 200)
+	negative := newTestChartTransfer(pool, -3, 1000000000)
+	for _, transfer := range []*core.TokenTransfer{
+		newTestChartTransfer(pool, 1, 1000000000),
+		newTestChartTransfer(pool, 0xffffffff, 1000000000),
+		newTestChartTransfer(pool, 0x100000000, 1000000000),
+		large,
+		negative,
+	} {
+		_, err := s.InsertOrGetTokenTransfer(ctx, transfer)
+		assert.NoError(t, err)
+	}
+
+	// Every record in the bucket is aggregated, regardless of the maximum rows of a histogram
+	fb := database.TokenTransferQueryFactory.NewFilter(ctx)
+	aggregates, err := s.GetChartAggregates(ctx, "ns1", mockHistogramInterval, database.CollectionName(database.CollectionTokenTransfers), &database.ChartAggregateQuery{
+		Filter:    fb.And(),
+		Aggregate: core.ChartAggregateTypeSum,
+		Field:     "amount",
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "5", aggregates[0].Count)
+	assert.False(t, aggregates[0].IsCapped)
+	expected := new(big.Int).Lsh(big.NewInt(1), 200)
+	expected.Add(expected, big.NewInt(1+0xffffffff+0x100000000-3))
+	assert.Equal(t, []*core.ChartAggregateGroup{
+		{Dimensions: map[string]string{}, Count: "5", Value: expected.String()},
+	}, aggregates[0].Groups)
+}
+
+func TestGetChartAggregatesBlockchainEvents(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectQuery("SELECT \\(CASE WHEN \\(timestamp >= \\$1 AND timestamp < \\$2\\) THEN 0 END\\) AS bucket, listener_id, COUNT\\(\\*\\) FROM blockchainevents WHERE .* GROUP BY bucket, listener_id").
+		WillReturnRows(sqlmock.NewRows([]string{"bucket", "listener_id", "count"}).
+			AddRow(0, "listener1", 3).
+			AddRow(0, nil, 2).
+			AddRow(nil, "listener1", 1))
+
+	fb := database.BlockchainEventQueryFactory.NewFilter(context.Background())
+	aggregates, err := s.GetChartAggregates(context.Background(), "ns1", mockHistogramInterval, database.CollectionName(database.CollectionBlockchainEvents), &database.ChartAggregateQuery{
+		Filter:    fb.And(),
+		GroupBy:   []string{"listener"},
+		Aggregate: core.ChartAggregateTypeCount,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "5", aggregates[0].Count)
+	assert.Equal(t, []*core.ChartAggregateGroup{
+		{Dimensions: map[string]string{"listener": ""}, Count: "2", Value: "2"},
+		{Dimensions: map[string]string{"listener": "listener1"}, Count: "3", Value: "3"},
+	}, aggregates[0].Groups)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetChartAggregatesNoIntervals(t *testing.T) {
+	s, _ := newMockProvider().init()
+	fb := database.EventQueryFactory.NewFilter(context.Background())
+	aggregates, err := s.GetChartAggregates(context.Background(), "ns1", nil, database.CollectionName(database.CollectionEvents), &database.ChartAggregateQuery{
+		Filter: fb.And(),
+	})
+	assert.NoError(t, err)
+	assert.Empty(t, aggregates)
+}
+
+func TestGetChartAggregatesErrors(t *testing.T) {
+	coreconfig.Reset()
+	ctx := context.Background()
+	events := database.CollectionName(database.CollectionEvents)
+	fb := database.EventQueryFactory.NewFilter(ctx)
+	query := &database.ChartAggregateQuery{Filter: fb.And(), Aggregate: core.ChartAggregateTypeSum, Field: "sequence"}
+
+	s, mock := newMockProvider().init()
+	_, err := s.GetChartAggregates(ctx, "ns1", mockHistogramInterval, database.CollectionName("abc"), query)
+	assert.Regexp(t, "FF10301", err)
+
+	_, err = s.GetChartAggregates(ctx, "ns1", mockHistogramInterval, events, &database.ChartAggregateQuery{
+		Filter: fb.And(fb.Eq("sequence", map[bool]bool{true: false})),
+	})
+	assert.Regexp(t, "FF00", err)
+
+	mock.ExpectQuery("SELECT .*").WillReturnError(fmt.Errorf("pop"))
+	_, err = s.GetChartAggregates(ctx, "ns1", mockHistogramInterval, events, query)
+	assert.Regexp(t, "FF00176", err)
+
+	mock.ExpectQuery("SELECT .*").WillReturnRows(sqlmock.NewRows([]string{"bucket"}).AddRow(0))
+	_, err = s.GetChartAggregates(ctx, "ns1", mockHistogramInterval, events, query)
+	assert.Regexp(t, "FF10121", err)
+
+	mock.ExpectQuery("SELECT .*").WillReturnRows(sqlmock.NewRows([]string{"bucket", "count", "values", "sum"}).AddRow(1, 1, 1, "1"))
+	_, err = s.GetChartAggregates(ctx, "ns1", mockHistogramInterval, events, query)
+	assert.Regexp(t, "FF10121", err)
+
+	mock.ExpectQuery("SELECT .*").WillReturnRows(sqlmock.NewRows([]string{"bucket", "count", "values", "sum"}).AddRow(0, 1, 1, "not a number"))
+	_, err = s.GetChartAggregates(ctx, "ns1", mockHistogramInterval, events, query)
+	assert.Regexp(t, "FF10121", err)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestChartSum(t *testing.T) {
+	sum, ok := chartSum([]sql.NullString{{}}, false)
+	assert.True(t, ok)
+	assert.Equal(t, "0", chartValueString(sum))
+
+	sum, ok = chartSum([]sql.NullString{{String: "5", Valid: true}, {String: "-1", Valid: true}, {}}, true)
+	assert.True(t, ok)
+	assert.Equal(t, "-4294967291", chartValueString(sum))
+
+	_, ok = chartSum([]sql.NullString{{String: "1.5", Valid: true}}, true)
+	assert.False(t, ok)
+}
+
+func TestChartHexValueWithDB(t *testing.T) {
+	s, cleanup := newSQLiteTestProvider(t)
+	defer cleanup()
+
+	var v int64
+	err := s.DB().QueryRow("SELECT " + s.chart.ChartHexValue("'fedcba98'")).Scan(&v)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0xfedcba98), v)
+}
+
+type testChartProvider struct {
+	*mockProvider
+}
+
+func (tp *testChartProvider) ChartHexValue(hex string) string {
+	return "hexvalue(" + hex + ")"
+}
+
+func TestChartProviderOverride(t *testing.T) {
+	s, _ := newMockProvider().init()
+	s.initChartProvider(&testChartProvider{})
+	assert.Contains(t, s.chartBigIntSums("amount")[0], "hexvalue(substr(amount, (CASE WHEN substr(amount, 1, 1) = '-' THEN 2 ELSE 1 END) + 56, 8))")
+}
@@ -0,0 +1,135 @@
+// Copyright © 2024 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqlcommon
+
+import (
+	"context"
+	"database/sql"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/hyperledger/firefly-common/pkg/ffapi"
+	"github.com/hyperledger/firefly-common/pkg/fftypes"
+	"github.com/hyperledger/firefly-common/pkg/i18n"
+	"github.com/hyperledger/firefly/internal/coremsgs"
+	"github.com/hyperledger/firefly/pkg/core"
+)
+
+var (
+	clusterNotificationColumns = []string{
+		"namespace",
+		"topic",
+		"payload",
+		"created",
+	}
+	clusterNotificationFilterFieldMap = map[string]string{}
+)
+
+const clusterNotificationsTable = "clusternotifications"
+
+func (s *SQLCommon) InsertClusterNotification(ctx context.Context, notification *core.ClusterNotification) (err error) {
+	ctx, tx, autoCommit, err := s.BeginOrUseTx(ctx)
+	if err != nil {
+		return err
+	}
+	defer s.RollbackTx(ctx, tx, autoCommit)
+
+	if notification.Created == nil {
+		notification.Created = fftypes.Now()
+	}
+	if notification.Sequence, err = s.InsertTx(ctx, clusterNotificationsTable, tx,
+		sq.Insert(clusterNotificationsTable).
+			Columns(clusterNotificationColumns...).
+			Values(
+				notification.Namespace,
+				notification.Topic,
+				notification.Payload,
+				notification.Created,
+			),
+		nil, // cluster notifications are delivered between replicas, not as change events
+	); err != nil {
+		return err
+	}
+
+	return s.CommitTx(ctx, tx, autoCommit)
+}
+
+func (s *SQLCommon) clusterNotificationResult(ctx context.Context, row *sql.Rows) (*core.ClusterNotification, error) {
+	var notification core.ClusterNotification
+	err := row.Scan(
+		&notification.Namespace,
+		&notification.Topic,
+		&notification.Payload,
+		&notification.Created,
+		&notification.Sequence, // must include s.SequenceColumn() in colum list
+	)
+	if err != nil {
+		return nil, i18n.WrapError(ctx, err, coremsgs.MsgDBReadErr, clusterNotificationsTable)
+	}
+	return &notification, nil
+}
+
+func (s *SQLCommon) GetClusterNotifications(ctx context.Context, namespace string, filter ffapi.Filter) (notifications []*core.ClusterNotification, res *ffapi.FilterResult, err error) {
+	cols := append([]string{}, clusterNotificationColumns...)
+	cols = append(cols, s.SequenceColumn())
+	query, fop, fi, err := s.FilterSelect(ctx, "", sq.Select(cols...).From(clusterNotificationsTable),
+		filter, clusterNotificationFilterFieldMap, []interface{}{"sequence"}, sq.Eq{"namespace": namespace})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	rows, tx, err := s.Query(ctx, clusterNotificationsTable, query)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close()
+
+	notifications = []*core.ClusterNotification{}
+	for rows.Next() {
+		n, err := s.clusterNotificationResult(ctx, rows)
+		if err != nil {
+			return nil, nil, err
+		}
+		notifications = append(notifications, n)
+	}
+
+	return notifications, s.QueryRes(ctx, clusterNotificationsTable, tx, fop, nil, fi), err
+}
+
+func (s *SQLCommon) DeleteClusterNotifications(ctx context.Context, namespace, topic string, before *fftypes.FFTime) (err error) {
+	ctx, tx, autoCommit, err := s.BeginOrUseTx(ctx)
+	if err != nil {
+		return err
+	}
+	defer s.RollbackTx(ctx, tx, autoCommit)
+
+	err = s.DeleteTx(ctx, clusterNotificationsTable, tx, sq.Delete(clusterNotificationsTable).Where(sq.And{
+		sq.Eq{"namespace": namespace},
+		sq.Eq{"topic": topic},
+		sq.Lt{"created": before},
+	}), nil)
+	if err != nil && err != fftypes.DeleteRecordNotFound {
+		return err
+	}
+
+	return s.CommitTx(ctx, tx, autoCommit)
+}
+
+// ListenClusterNotifications is a no-op for databases that cannot push notifications between
+// replicas, and callers must fall back to polling GetClusterNotifications
+func (s *SQLCommon) ListenClusterNotifications(ctx context.Context, namespace, topic string) (<-chan *core.ClusterNotification, error) {
+	return nil, nil
+}
@@ -0,0 +1,156 @@
+// Copyright © 2024 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqlcommon
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/hyperledger/firefly-common/pkg/fftypes"
+	"github.com/hyperledger/firefly/pkg/core"
+	"github.com/hyperledger/firefly/pkg/database"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClusterNotificationsE2EWithDB(t *testing.T) {
+	s, cleanup := newSQLiteTestProvider(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	old := fftypes.FFTime(time.Now().Add(-1 * time.Hour))
+	n1 := &core.ClusterNotification{
+		Namespace: "ns1",
+		Topic:     "topic1",
+		Payload:   fftypes.JSONAnyPtr(`{"some":"info"}`),
+		Created:   &old,
+	}
+	err := s.InsertClusterNotification(ctx, n1)
+	assert.NoError(t, err)
+
+	n2 := &core.ClusterNotification{
+		Namespace: "ns1",
+		Topic:     "topic1",
+		Payload:   fftypes.JSONAnyPtr(`{"more":"info"}`),
+	}
+	err = s.InsertClusterNotification(ctx, n2)
+	assert.NoError(t, err)
+	assert.NotNil(t, n2.Created)
+
+	// An old notification on another topic belongs to another producer
+	n3 := &core.ClusterNotification{
+		Namespace: "ns1",
+		Topic:     "topic2",
+		Payload:   fftypes.JSONAnyPtr(`{"other":"info"}`),
+		Created:   &old,
+	}
+	err = s.InsertClusterNotification(ctx, n3)
+	assert.NoError(t, err)
+	assert.Greater(t, n2.Sequence, n1.Sequence)
+
+	fb := database.ClusterNotificationQueryFactory.NewFilter(ctx)
+	notifications, res, err := s.GetClusterNotifications(ctx, "ns1", fb.And(
+		fb.Eq("topic", "topic1"),
+		fb.Gt("sequence", n1.Sequence),
+	).Count(true))
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), *res.TotalCount)
+	assert.Len(t, notifications, 1)
+	assert.Equal(t, n2.Sequence, notifications[0].Sequence)
+	assert.Equal(t, `{"more":"info"}`, notifications[0].Payload.String())
+
+	before := fftypes.FFTime(time.Now().Add(-1 * time.Minute))
+	err = s.DeleteClusterNotifications(ctx, "ns1", "topic1", &before)
+	assert.NoError(t, err)
+
+	// Nothing left to delete is not an error
+	err = s.DeleteClusterNotifications(ctx, "ns1", "topic1", &before)
+	assert.NoError(t, err)
+
+	notifications, _, err = s.GetClusterNotifications(ctx, "ns1", fb.And().Sort("sequence"))
+	assert.NoError(t, err)
+	assert.Len(t, notifications, 2)
+	assert.Equal(t, n2.Sequence, notifications[0].Sequence)
+	assert.Equal(t, n3.Sequence, notifications[1].Sequence)
+
+	notify, err := s.ListenClusterNotifications(ctx, "ns1", "topic1")
+	assert.NoError(t, err)
+	assert.Nil(t, notify)
+}
+
+func TestInsertClusterNotificationFailBegin(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin().WillReturnError(fmt.Errorf("pop"))
+	err := s.InsertClusterNotification(context.Background(), &core.ClusterNotification{})
+	assert.Regexp(t, "FF00175", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestInsertClusterNotificationFailInsert(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin()
+	mock.ExpectExec("INSERT .*").WillReturnError(fmt.Errorf("pop"))
+	mock.ExpectRollback()
+	err := s.InsertClusterNotification(context.Background(), &core.ClusterNotification{})
+	assert.Regexp(t, "FF00177", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetClusterNotificationsBuildQueryFail(t *testing.T) {
+	s, _ := newMockProvider().init()
+	f := database.ClusterNotificationQueryFactory.NewFilter(context.Background()).Eq("topic", map[bool]bool{true: false})
+	_, _, err := s.GetClusterNotifications(context.Background(), "ns1", f)
+	assert.Regexp(t, "FF00143.*topic", err)
+}
+
+func TestGetClusterNotificationsQueryFail(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectQuery("SELECT .*").WillReturnError(fmt.Errorf("pop"))
+	f := database.ClusterNotificationQueryFactory.NewFilter(context.Background()).Eq("topic", "topic1")
+	_, _, err := s.GetClusterNotifications(context.Background(), "ns1", f)
+	assert.Regexp(t, "FF00176", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetClusterNotificationsReadMessageFail(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectQuery("SELECT .*").WillReturnRows(sqlmock.NewRows([]string{"topic"}).AddRow("only one"))
+	f := database.ClusterNotificationQueryFactory.NewFilter(context.Background()).Eq("topic", "topic1")
+	_, _, err := s.GetClusterNotifications(context.Background(), "ns1", f)
+	assert.Regexp(t, "FF10121", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDeleteClusterNotificationsFailBegin(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin().WillReturnError(fmt.Errorf("pop"))
+	err := s.DeleteClusterNotifications(context.Background(), "ns1", "topic1", fftypes.Now())
+	assert.Regexp(t, "FF00175", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDeleteClusterNotificationsFailDelete(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin()
+	mock.ExpectExec("DELETE .*").WillReturnError(fmt.Errorf("pop"))
+	mock.ExpectRollback()
+	err := s.DeleteClusterNotifications(context.Background(), "ns1", "topic1", fftypes.Now())
+	assert.Regexp(t, "FF00179", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -21,6 +21,7 @@ import (
 
 	"github.com/hyperledger/firefly-common/pkg/config"
 	"github.com/hyperledger/firefly-common/pkg/dbsql"
+	"github.com/hyperledger/firefly/internal/keyprovider/kpfactory"
 )
 
 const (
@@ -38,10 +39,22 @@ const (
 	SQLConfMaxIdleConns = "maxIdleConns"
 	// SQLConfMaxConnLifetime maximum connections to the database
 	SQLConfMaxConnLifetime = "maxConnLifetime"
+	// SQLConfJSONPathMaxConditions maximum JSON-path conditions in one query
+	SQLConfJSONPathMaxConditions = "jsonPath.maxConditions"
+	// SQLConfJSONPathMaxDepth maximum keys and indexes in a JSON path
+	SQLConfJSONPathMaxDepth = "jsonPath.maxDepth"
+	// SQLConfReadReplica is the sub-section for an optional read-only connection, used for reads that tolerate stale results
+	SQLConfReadReplica = "readReplica"
+	// SQLConfEncryption is the sub-section for encrypting data values, and operation inputs and outputs, at rest
+	SQLConfEncryption = "encryption"
+	// SQLConfEncryptionKeyProvider is the type of the key provider plugin that holds the key encryption keys
+	SQLConfEncryptionKeyProvider = "keyProvider"
 )
 
 const (
 	defaultMigrationsDirectoryTemplate = "./db/migrations/%s"
+	defaultJSONPathMaxConditions       = 3
+	defaultJSONPathMaxDepth            = 8
 )
 
 func (s *SQLCommon) InitConfig(provider dbsql.Provider, config config.Section) {
@@ -52,4 +65,17 @@ func (s *SQLCommon) InitConfig(provider dbsql.Provider, config config.Section) {
 	config.AddKnownKey(SQLConfMaxConnIdleTime, "1m")
 	config.AddKnownKey(SQLConfMaxIdleConns) // defaults to the max connections
 	config.AddKnownKey(SQLConfMaxConnLifetime)
+	config.AddKnownKey(SQLConfJSONPathMaxConditions, defaultJSONPathMaxConditions)
+	config.AddKnownKey(SQLConfJSONPathMaxDepth, defaultJSONPathMaxDepth)
+
+	replicaConfig := config.SubSection(SQLConfReadReplica)
+	replicaConfig.AddKnownKey(SQLConfDatasourceURL)
+	replicaConfig.AddKnownKey(SQLConfMaxConnections) // some providers set a default
+	replicaConfig.AddKnownKey(SQLConfMaxConnIdleTime, "1m")
+	replicaConfig.AddKnownKey(SQLConfMaxIdleConns) // defaults to the max connections
+	replicaConfig.AddKnownKey(SQLConfMaxConnLifetime)
+
+	encryptionConfig := config.SubSection(SQLConfEncryption)
+	encryptionConfig.AddKnownKey(SQLConfEncryptionKeyProvider) // values are not encrypted if not set
+	kpfactory.InitConfig(encryptionConfig)
 }
@@ -0,0 +1,211 @@
+// Copyright © 2024 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqlcommon
+
+import (
+	"context"
+	"database/sql"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/hyperledger/firefly-common/pkg/ffapi"
+	"github.com/hyperledger/firefly-common/pkg/fftypes"
+	"github.com/hyperledger/firefly-common/pkg/i18n"
+	"github.com/hyperledger/firefly-common/pkg/log"
+	"github.com/hyperledger/firefly/internal/coremsgs"
+	"github.com/hyperledger/firefly/pkg/core"
+)
+
+var (
+	contractListenerBackfillColumns = []string{
+		"id",
+		"namespace",
+		"listener_id",
+		"from_block",
+		"to_block",
+		"current_block",
+		"status",
+		"error",
+		"backend_id",
+		"created",
+		"updated",
+	}
+	contractListenerBackfillFilterFieldMap = map[string]string{
+		"listener":     "listener_id",
+		"fromblock":    "from_block",
+		"toblock":      "to_block",
+		"currentblock": "current_block",
+		"backendid":    "backend_id",
+	}
+)
+
+const contractListenerBackfillsTable = "contractlistenerbackfills"
+
+func (s *SQLCommon) InsertContractListenerBackfill(ctx context.Context, backfill *core.ContractListenerBackfill) (err error) {
+	ctx, tx, autoCommit, err := s.BeginOrUseTx(ctx)
+	if err != nil {
+		return err
+	}
+	defer s.RollbackTx(ctx, tx, autoCommit)
+
+	if _, err = s.InsertTx(ctx, contractListenerBackfillsTable, tx,
+		sq.Insert(contractListenerBackfillsTable).
+			Columns(contractListenerBackfillColumns...).
+			Values(
+				backfill.ID,
+				backfill.Namespace,
+				backfill.Listener,
+				backfill.FromBlock,
+				backfill.ToBlock,
+				backfill.CurrentBlock,
+				backfill.Status,
+				backfill.Error,
+				backfill.BackendID,
+				backfill.Created,
+				backfill.Updated,
+			),
+		nil, // no change event
+	); err != nil {
+		return err
+	}
+
+	return s.CommitTx(ctx, tx, autoCommit)
+}
+
+func (s *SQLCommon) contractListenerBackfillResult(ctx context.Context, row *sql.Rows) (*core.ContractListenerBackfill, error) {
+	var backfill core.ContractListenerBackfill
+	var currentBlock sql.NullInt64
+	var errorMsg, backendID sql.NullString
+	err := row.Scan(
+		&backfill.ID,
+		&backfill.Namespace,
+		&backfill.Listener,
+		&backfill.FromBlock,
+		&backfill.ToBlock,
+		&currentBlock,
+		&backfill.Status,
+		&errorMsg,
+		&backendID,
+		&backfill.Created,
+		&backfill.Updated,
+	)
+	if err != nil {
+		return nil, i18n.WrapError(ctx, err, coremsgs.MsgDBReadErr, contractListenerBackfillsTable)
+	}
+	if currentBlock.Valid {
+		block := uint64(currentBlock.Int64)
+		backfill.CurrentBlock = &block
+	}
+	backfill.Error = errorMsg.String
+	backfill.BackendID = backendID.String
+	return &backfill, nil
+}
+
+func (s *SQLCommon) GetContractListenerBackfillByID(ctx context.Context, namespace string, id *fftypes.UUID) (*core.ContractListenerBackfill, error) {
+	rows, _, err := s.Query(ctx, contractListenerBackfillsTable,
+		sq.Select(contractListenerBackfillColumns...).
+			From(contractListenerBackfillsTable).
+			Where(sq.Eq{"id": id, "namespace": namespace}),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	if !rows.Next() {
+		log.L(ctx).Debugf("Contract listener backfill '%s' not found", id)
+		return nil, nil
+	}
+
+	return s.contractListenerBackfillResult(ctx, rows)
+}
+
+func (s *SQLCommon) GetContractListenerBackfills(ctx context.Context, namespace string, filter ffapi.Filter) (backfills []*core.ContractListenerBackfill, res *ffapi.FilterResult, err error) {
+	query, fop, fi, err := s.FilterSelect(ctx, "", sq.Select(contractListenerBackfillColumns...).From(contractListenerBackfillsTable),
+		filter, contractListenerBackfillFilterFieldMap, []interface{}{"sequence"}, sq.Eq{"namespace": namespace})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	rows, tx, err := s.Query(ctx, contractListenerBackfillsTable, query)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close()
+
+	backfills = []*core.ContractListenerBackfill{}
+	for rows.Next() {
+		b, err := s.contractListenerBackfillResult(ctx, rows)
+		if err != nil {
+			return nil, nil, err
+		}
+		backfills = append(backfills, b)
+	}
+
+	return backfills, s.QueryRes(ctx, contractListenerBackfillsTable, tx, fop, nil, fi), err
+}
+
+func (s *SQLCommon) UpdateContractListenerBackfill(ctx context.Context, namespace string, id *fftypes.UUID, update ffapi.Update) (err error) {
+	ctx, tx, autoCommit, err := s.BeginOrUseTx(ctx)
+	if err != nil {
+		return err
+	}
+	defer s.RollbackTx(ctx, tx, autoCommit)
+
+	query, err := s.BuildUpdate(sq.Update(contractListenerBackfillsTable), update, contractListenerBackfillFilterFieldMap)
+	if err != nil {
+		return err
+	}
+	query = query.Where(sq.Eq{"id": id, "namespace": namespace})
+
+	if _, err = s.UpdateTx(ctx, contractListenerBackfillsTable, tx, query, nil /* no change event */); err != nil {
+		return err
+	}
+
+	return s.CommitTx(ctx, tx, autoCommit)
+}
+
+func (s *SQLCommon) DeleteContractListenerBackfill(ctx context.Context, namespace string, id *fftypes.UUID) (err error) {
+	ctx, tx, autoCommit, err := s.BeginOrUseTx(ctx)
+	if err != nil {
+		return err
+	}
+	defer s.RollbackTx(ctx, tx, autoCommit)
+
+	if err = s.DeleteTx(ctx, contractListenerBackfillsTable, tx, sq.Delete(contractListenerBackfillsTable).Where(sq.Eq{
+		"id": id, "namespace": namespace,
+	}), nil /* no change event */); err != nil && err != fftypes.DeleteRecordNotFound {
+		return err
+	}
+
+	return s.CommitTx(ctx, tx, autoCommit)
+}
+
+func (s *SQLCommon) DeleteContractListenerBackfills(ctx context.Context, namespace string, listenerID *fftypes.UUID) (err error) {
+	ctx, tx, autoCommit, err := s.BeginOrUseTx(ctx)
+	if err != nil {
+		return err
+	}
+	defer s.RollbackTx(ctx, tx, autoCommit)
+
+	if err = s.DeleteTx(ctx, contractListenerBackfillsTable, tx, sq.Delete(contractListenerBackfillsTable).Where(sq.Eq{
+		"listener_id": listenerID, "namespace": namespace,
+	}), nil /* no change event */); err != nil && err != fftypes.DeleteRecordNotFound {
+		return err
+	}
+
+	return s.CommitTx(ctx, tx, autoCommit)
+}
@@ -0,0 +1,222 @@
+// Copyright © 2024 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqlcommon
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/hyperledger/firefly-common/pkg/fftypes"
+	"github.com/hyperledger/firefly/pkg/core"
+	"github.com/hyperledger/firefly/pkg/database"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestContractListenerBackfillsE2EWithDB(t *testing.T) {
+	s, cleanup := newSQLiteTestProvider(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	// Create a new backfill entry
+	listenerID := fftypes.NewUUID()
+	backfill := &core.ContractListenerBackfill{
+		ID:        fftypes.NewUUID(),
+		Namespace: "ns1",
+		Listener:  listenerID,
+		FromBlock: 100,
+		ToBlock:   200,
+		Status:    core.ContractListenerBackfillStatusRunning,
+		Created:   fftypes.Now(),
+		Updated:   fftypes.Now(),
+	}
+	err := s.InsertContractListenerBackfill(ctx, backfill)
+	assert.NoError(t, err)
+
+	// Check we get the exact same entry back
+	backfillRead, err := s.GetContractListenerBackfillByID(ctx, "ns1", backfill.ID)
+	assert.NoError(t, err)
+	backfillJson, _ := json.Marshal(&backfill)
+	backfillReadJson, _ := json.Marshal(&backfillRead)
+	assert.Equal(t, string(backfillJson), string(backfillReadJson))
+
+	// Update
+	updateTime := fftypes.Now()
+	up := database.ContractListenerBackfillQueryFactory.NewUpdate(ctx).
+		Set("currentblock", 150).
+		Set("backendid", "sub1").
+		Set("status", core.ContractListenerBackfillStatusFailed).
+		Set("error", "pop").
+		Set("updated", updateTime)
+	err = s.UpdateContractListenerBackfill(ctx, "ns1", backfill.ID, up)
+	assert.NoError(t, err)
+
+	// Query back the entry
+	fb := database.ContractListenerBackfillQueryFactory.NewFilter(ctx)
+	filter := fb.And(
+		fb.Eq("listener", listenerID),
+		fb.Eq("status", core.ContractListenerBackfillStatusFailed),
+	)
+	backfills, res, err := s.GetContractListenerBackfills(ctx, "ns1", filter.Count(true))
+	assert.NoError(t, err)
+	assert.Len(t, backfills, 1)
+	assert.Equal(t, int64(1), *res.TotalCount)
+	assert.Equal(t, uint64(150), *backfills[0].CurrentBlock)
+	assert.Equal(t, "sub1", backfills[0].BackendID)
+	assert.Equal(t, "pop", backfills[0].Error)
+	assert.Equal(t, updateTime.String(), backfills[0].Updated.String())
+
+	// Delete
+	err = s.DeleteContractListenerBackfill(ctx, "ns1", backfill.ID)
+	assert.NoError(t, err)
+	backfillRead, err = s.GetContractListenerBackfillByID(ctx, "ns1", backfill.ID)
+	assert.NoError(t, err)
+	assert.Nil(t, backfillRead)
+
+	// Deleting again is not an error
+	err = s.DeleteContractListenerBackfill(ctx, "ns1", backfill.ID)
+	assert.NoError(t, err)
+
+	// Delete all the entries of a listener
+	backfill.ID = fftypes.NewUUID()
+	err = s.InsertContractListenerBackfill(ctx, backfill)
+	assert.NoError(t, err)
+	err = s.DeleteContractListenerBackfills(ctx, "ns1", listenerID)
+	assert.NoError(t, err)
+	backfills, _, err = s.GetContractListenerBackfills(ctx, "ns1", fb.And(fb.Eq("listener", listenerID)))
+	assert.NoError(t, err)
+	assert.Empty(t, backfills)
+}
+
+func TestInsertContractListenerBackfillFailBegin(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin().WillReturnError(fmt.Errorf("pop"))
+	err := s.InsertContractListenerBackfill(context.Background(), &core.ContractListenerBackfill{})
+	assert.Regexp(t, "FF00175", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestInsertContractListenerBackfillFailInsert(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin()
+	mock.ExpectExec("INSERT .*").WillReturnError(fmt.Errorf("pop"))
+	mock.ExpectRollback()
+	err := s.InsertContractListenerBackfill(context.Background(), &core.ContractListenerBackfill{})
+	assert.Regexp(t, "FF00177", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetContractListenerBackfillByIDSelectFail(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectQuery("SELECT .*").WillReturnError(fmt.Errorf("pop"))
+	_, err := s.GetContractListenerBackfillByID(context.Background(), "ns1", fftypes.NewUUID())
+	assert.Regexp(t, "FF00176", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetContractListenerBackfillByIDScanFail(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectQuery("SELECT .*").WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("only one"))
+	_, err := s.GetContractListenerBackfillByID(context.Background(), "ns1", fftypes.NewUUID())
+	assert.Regexp(t, "FF10121", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetContractListenerBackfillsBuildQueryFail(t *testing.T) {
+	s, _ := newMockProvider().init()
+	f := database.ContractListenerBackfillQueryFactory.NewFilter(context.Background()).Eq("id", map[bool]bool{true: false})
+	_, _, err := s.GetContractListenerBackfills(context.Background(), "ns1", f)
+	assert.Regexp(t, "FF00143.*id", err)
+}
+
+func TestGetContractListenerBackfillsQueryFail(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectQuery("SELECT .*").WillReturnError(fmt.Errorf("pop"))
+	f := database.ContractListenerBackfillQueryFactory.NewFilter(context.Background()).Eq("status", "")
+	_, _, err := s.GetContractListenerBackfills(context.Background(), "ns1", f)
+	assert.Regexp(t, "FF00176", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetContractListenerBackfillsReadFail(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectQuery("SELECT .*").WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("only one"))
+	f := database.ContractListenerBackfillQueryFactory.NewFilter(context.Background()).Eq("status", "")
+	_, _, err := s.GetContractListenerBackfills(context.Background(), "ns1", f)
+	assert.Regexp(t, "FF10121", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestContractListenerBackfillUpdateBeginFail(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin().WillReturnError(fmt.Errorf("pop"))
+	u := database.ContractListenerBackfillQueryFactory.NewUpdate(context.Background()).Set("currentblock", 1)
+	err := s.UpdateContractListenerBackfill(context.Background(), "ns1", fftypes.NewUUID(), u)
+	assert.Regexp(t, "FF00175", err)
+}
+
+func TestContractListenerBackfillUpdateBuildQueryFail(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin()
+	u := database.ContractListenerBackfillQueryFactory.NewUpdate(context.Background()).Set("currentblock", map[bool]bool{true: false})
+	err := s.UpdateContractListenerBackfill(context.Background(), "ns1", fftypes.NewUUID(), u)
+	assert.Regexp(t, "FF00143.*currentblock", err)
+}
+
+func TestContractListenerBackfillUpdateFail(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin()
+	mock.ExpectExec("UPDATE .*").WillReturnError(fmt.Errorf("pop"))
+	mock.ExpectRollback()
+	u := database.ContractListenerBackfillQueryFactory.NewUpdate(context.Background()).Set("currentblock", 1)
+	err := s.UpdateContractListenerBackfill(context.Background(), "ns1", fftypes.NewUUID(), u)
+	assert.Regexp(t, "FF00178", err)
+}
+
+func TestContractListenerBackfillDeleteBeginFail(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin().WillReturnError(fmt.Errorf("pop"))
+	err := s.DeleteContractListenerBackfill(context.Background(), "ns1", fftypes.NewUUID())
+	assert.Regexp(t, "FF00175", err)
+}
+
+func TestContractListenerBackfillDeleteFail(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin()
+	mock.ExpectExec("DELETE .*").WillReturnError(fmt.Errorf("pop"))
+	mock.ExpectRollback()
+	err := s.DeleteContractListenerBackfill(context.Background(), "ns1", fftypes.NewUUID())
+	assert.Regexp(t, "FF00179", err)
+}
+
+func TestContractListenerBackfillsDeleteBeginFail(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin().WillReturnError(fmt.Errorf("pop"))
+	err := s.DeleteContractListenerBackfills(context.Background(), "ns1", fftypes.NewUUID())
+	assert.Regexp(t, "FF00175", err)
+}
+
+func TestContractListenerBackfillsDeleteFail(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin()
+	mock.ExpectExec("DELETE .*").WillReturnError(fmt.Errorf("pop"))
+	mock.ExpectRollback()
+	err := s.DeleteContractListenerBackfills(context.Background(), "ns1", fftypes.NewUUID())
+	assert.Regexp(t, "FF00179", err)
+}
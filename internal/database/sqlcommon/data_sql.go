@@ -88,7 +88,7 @@ func (s *SQLCommon) attemptDataUpdate(ctx context.Context, tx *dbsql.TXWrapper,
 			Set("blob_size", blob.Size).
 			Set("public", data.Public).
 			Set("value_size", data.ValueSize).
-			Set("value", data.Value).
+			Set("value", s.encrypted(data.Value)).
 			Where(sq.Eq{
 				"id":        data.ID,
 				"hash":      data.Hash,
@@ -124,7 +124,7 @@ func (s *SQLCommon) setDataInsertValues(query sq.InsertBuilder, data *core.Data)
 		blob.Size,
 		data.Public,
 		data.ValueSize,
-		data.Value,
+		s.encrypted(data.Value),
 	)
 }
 
@@ -230,7 +230,7 @@ func (s *SQLCommon) InsertDataArray(ctx context.Context, dataArray core.DataArra
 
 }
 
-func (s *SQLCommon) dataResult(ctx context.Context, row *sql.Rows, withValue bool) (*core.Data, error) {
+func (s *SQLCommon) dataResult(ctx context.Context, row *sql.Rows, withValue bool, d *decryptions) (*core.Data, error) {
 	data := core.Data{
 		Datatype: &core.DatatypeRef{},
 		Blob:     &core.BlobRef{},
@@ -252,7 +252,7 @@ func (s *SQLCommon) dataResult(ctx context.Context, row *sql.Rows, withValue boo
 		&data.ValueSize,
 	}
 	if withValue {
-		results = append(results, &data.Value)
+		results = append(results, d.jsonAny(&data.Value))
 	}
 	err := row.Scan(results...)
 	if data.Blob.Hash == nil && data.Blob.Public == "" {
@@ -290,19 +290,27 @@ func (s *SQLCommon) GetDataByID(ctx context.Context, namespace string, id *fftyp
 		return nil, nil
 	}
 
-	data, err := s.dataResult(ctx, rows, withValue)
+	d := s.newDecryptions(ctx)
+	data, err := s.dataResult(ctx, rows, withValue, d)
 	if err != nil {
 		return nil, err
 	}
+	rows.Close()
 
-	return data, nil
+	return data, d.complete()
 }
 
 func (s *SQLCommon) GetData(ctx context.Context, namespace string, filter ffapi.Filter) (message core.DataArray, res *ffapi.FilterResult, err error) {
+	return s.getData(ctx, namespace, filter)
+}
+
+func (s *SQLCommon) getData(ctx context.Context, namespace string, filter ffapi.Filter, extraConditions ...sq.Sqlizer) (message core.DataArray, res *ffapi.FilterResult, err error) {
 
+	filter, conditions := s.dataJSONPathFilter(ctx, filter, "data.value")
+	conditions = append(conditions, extraConditions...)
 	query, fop, fi, err := s.FilterSelect(
 		ctx, "", sq.Select(dataColumnsWithValue...).From(dataTable),
-		filter, dataFilterFieldMap, []interface{}{"sequence"}, sq.Eq{"namespace": namespace})
+		filter, dataFilterFieldMap, []interface{}{"sequence"}, append([]sq.Sqlizer{sq.Eq{"namespace": namespace}}, conditions...)...)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -314,12 +322,17 @@ func (s *SQLCommon) GetData(ctx context.Context, namespace string, filter ffapi.
 	defer rows.Close()
 
 	data := core.DataArray{}
+	d := s.newDecryptions(ctx)
 	for rows.Next() {
-		d, err := s.dataResult(ctx, rows, true)
+		dr, err := s.dataResult(ctx, rows, true, d)
 		if err != nil {
 			return nil, nil, err
 		}
-		data = append(data, d)
+		data = append(data, dr)
+	}
+	rows.Close()
+	if err := d.complete(); err != nil {
+		return nil, nil, err
 	}
 
 	return data, s.QueryRes(ctx, dataTable, tx, fop, nil, fi), err
@@ -328,9 +341,10 @@ func (s *SQLCommon) GetData(ctx context.Context, namespace string, filter ffapi.
 
 func (s *SQLCommon) GetDataRefs(ctx context.Context, namespace string, filter ffapi.Filter) (message core.DataRefs, res *ffapi.FilterResult, err error) {
 
+	filter, conditions := s.dataJSONPathFilter(ctx, filter, "data.value")
 	query, fop, fi, err := s.FilterSelect(
 		ctx, "", sq.Select("id", "hash").From(dataTable),
-		filter, dataFilterFieldMap, []interface{}{"sequence"}, sq.Eq{"namespace": namespace})
+		filter, dataFilterFieldMap, []interface{}{"sequence"}, append([]sq.Sqlizer{sq.Eq{"namespace": namespace}}, conditions...)...)
 	if err != nil {
 		return nil, nil, err
 	}
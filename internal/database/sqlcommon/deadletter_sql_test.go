@@ -0,0 +1,204 @@
+// Copyright © 2024 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqlcommon
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/hyperledger/firefly-common/pkg/fftypes"
+	"github.com/hyperledger/firefly/pkg/core"
+	"github.com/hyperledger/firefly/pkg/database"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDeadLettersE2EWithDB(t *testing.T) {
+	s, cleanup := newSQLiteTestProvider(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	// Create a new dead letter entry
+	deadLetter := &core.DeadLetter{
+		ID:        fftypes.NewUUID(),
+		Namespace: "ns1",
+		Subscription: core.SubscriptionRef{
+			ID:        fftypes.NewUUID(),
+			Namespace: "ns1",
+			Name:      "sub1",
+		},
+		Event:         fftypes.NewUUID(),
+		EventSequence: 12345,
+		EventType:     core.EventTypeMessageConfirmed,
+		Attempts:      5,
+		LastError:     "pop",
+		Created:       fftypes.Now(),
+	}
+
+	s.callbacks.On("UUIDCollectionNSEvent", database.CollectionDeadLetters, core.ChangeEventTypeCreated, "ns1", deadLetter.ID).Return()
+	s.callbacks.On("UUIDCollectionNSEvent", database.CollectionDeadLetters, core.ChangeEventTypeUpdated, "ns1", deadLetter.ID).Return()
+	s.callbacks.On("UUIDCollectionNSEvent", database.CollectionDeadLetters, core.ChangeEventTypeDeleted, "ns1", deadLetter.ID).Return()
+
+	err := s.InsertDeadLetter(ctx, deadLetter)
+	assert.NoError(t, err)
+
+	// Check we get the exact same entry back
+	deadLetterRead, err := s.GetDeadLetterByID(ctx, "ns1", deadLetter.ID)
+	assert.NoError(t, err)
+	deadLetterJson, _ := json.Marshal(&deadLetter)
+	deadLetterReadJson, _ := json.Marshal(&deadLetterRead)
+	assert.Equal(t, string(deadLetterJson), string(deadLetterReadJson))
+
+	// Update
+	updateTime := fftypes.Now()
+	up := database.DeadLetterQueryFactory.NewUpdate(ctx).
+		Set("attempts", 6).
+		Set("lasterror", "bang").
+		Set("updated", updateTime)
+	err = s.UpdateDeadLetter(ctx, "ns1", deadLetter.ID, up)
+	assert.NoError(t, err)
+
+	// Query back the entry
+	fb := database.DeadLetterQueryFactory.NewFilter(ctx)
+	filter := fb.And(
+		fb.Eq("subscription", deadLetter.Subscription.ID),
+		fb.Eq("eventsequence", 12345),
+		fb.Eq("lasterror", "bang"),
+	)
+	deadLetters, res, err := s.GetDeadLetters(ctx, "ns1", filter.Count(true))
+	assert.NoError(t, err)
+	assert.Len(t, deadLetters, 1)
+	assert.Equal(t, int64(1), *res.TotalCount)
+	assert.Equal(t, 6, deadLetters[0].Attempts)
+	assert.Equal(t, updateTime.String(), deadLetters[0].Updated.String())
+
+	// Delete
+	err = s.DeleteDeadLetter(ctx, "ns1", deadLetter.ID)
+	assert.NoError(t, err)
+	deadLetterRead, err = s.GetDeadLetterByID(ctx, "ns1", deadLetter.ID)
+	assert.NoError(t, err)
+	assert.Nil(t, deadLetterRead)
+
+	// Deleting again is not found
+	err = s.DeleteDeadLetter(ctx, "ns1", deadLetter.ID)
+	assert.Regexp(t, "FF00167", err)
+
+	s.callbacks.AssertExpectations(t)
+}
+
+func TestInsertDeadLetterFailBegin(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin().WillReturnError(fmt.Errorf("pop"))
+	err := s.InsertDeadLetter(context.Background(), &core.DeadLetter{})
+	assert.Regexp(t, "FF00175", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestInsertDeadLetterFailInsert(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin()
+	mock.ExpectExec("INSERT .*").WillReturnError(fmt.Errorf("pop"))
+	mock.ExpectRollback()
+	err := s.InsertDeadLetter(context.Background(), &core.DeadLetter{})
+	assert.Regexp(t, "FF00177", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetDeadLetterByIDSelectFail(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectQuery("SELECT .*").WillReturnError(fmt.Errorf("pop"))
+	_, err := s.GetDeadLetterByID(context.Background(), "ns1", fftypes.NewUUID())
+	assert.Regexp(t, "FF00176", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetDeadLetterByIDScanFail(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectQuery("SELECT .*").WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("only one"))
+	_, err := s.GetDeadLetterByID(context.Background(), "ns1", fftypes.NewUUID())
+	assert.Regexp(t, "FF10121", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetDeadLettersBuildQueryFail(t *testing.T) {
+	s, _ := newMockProvider().init()
+	f := database.DeadLetterQueryFactory.NewFilter(context.Background()).Eq("id", map[bool]bool{true: false})
+	_, _, err := s.GetDeadLetters(context.Background(), "ns1", f)
+	assert.Regexp(t, "FF00143.*id", err)
+}
+
+func TestGetDeadLettersQueryFail(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectQuery("SELECT .*").WillReturnError(fmt.Errorf("pop"))
+	f := database.DeadLetterQueryFactory.NewFilter(context.Background()).Eq("eventtype", "")
+	_, _, err := s.GetDeadLetters(context.Background(), "ns1", f)
+	assert.Regexp(t, "FF00176", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetDeadLettersReadMessageFail(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectQuery("SELECT .*").WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("only one"))
+	f := database.DeadLetterQueryFactory.NewFilter(context.Background()).Eq("eventtype", "")
+	_, _, err := s.GetDeadLetters(context.Background(), "ns1", f)
+	assert.Regexp(t, "FF10121", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDeadLetterUpdateBeginFail(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin().WillReturnError(fmt.Errorf("pop"))
+	u := database.DeadLetterQueryFactory.NewUpdate(context.Background()).Set("attempts", 1)
+	err := s.UpdateDeadLetter(context.Background(), "ns1", fftypes.NewUUID(), u)
+	assert.Regexp(t, "FF00175", err)
+}
+
+func TestDeadLetterUpdateBuildQueryFail(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin()
+	u := database.DeadLetterQueryFactory.NewUpdate(context.Background()).Set("attempts", map[bool]bool{true: false})
+	err := s.UpdateDeadLetter(context.Background(), "ns1", fftypes.NewUUID(), u)
+	assert.Regexp(t, "FF00143.*attempts", err)
+}
+
+func TestDeadLetterUpdateFail(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin()
+	mock.ExpectExec("UPDATE .*").WillReturnError(fmt.Errorf("pop"))
+	mock.ExpectRollback()
+	u := database.DeadLetterQueryFactory.NewUpdate(context.Background()).Set("attempts", 1)
+	err := s.UpdateDeadLetter(context.Background(), "ns1", fftypes.NewUUID(), u)
+	assert.Regexp(t, "FF00178", err)
+}
+
+func TestDeadLetterDeleteBeginFail(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin().WillReturnError(fmt.Errorf("pop"))
+	err := s.DeleteDeadLetter(context.Background(), "ns1", fftypes.NewUUID())
+	assert.Regexp(t, "FF00175", err)
+}
+
+func TestDeadLetterDeleteFail(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin()
+	mock.ExpectExec("DELETE .*").WillReturnError(fmt.Errorf("pop"))
+	mock.ExpectRollback()
+	err := s.DeleteDeadLetter(context.Background(), "ns1", fftypes.NewUUID())
+	assert.Regexp(t, "FF00179", err)
+}
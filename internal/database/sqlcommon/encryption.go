@@ -0,0 +1,438 @@
+// Copyright © 2024 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqlcommon
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/base64"
+	"fmt"
+	"strings"
+	"sync"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/hyperledger/firefly-common/pkg/config"
+	"github.com/hyperledger/firefly-common/pkg/ffapi"
+	"github.com/hyperledger/firefly-common/pkg/fftypes"
+	"github.com/hyperledger/firefly-common/pkg/i18n"
+	"github.com/hyperledger/firefly-common/pkg/log"
+	"github.com/hyperledger/firefly/internal/coremsgs"
+	"github.com/hyperledger/firefly/internal/keyprovider/kpfactory"
+	"github.com/hyperledger/firefly/pkg/keyprovider"
+)
+
+const (
+	datakeysTable = "datakeys"
+
+	// encryptedValuePrefix marks an encrypted value, which is followed by the ID of the data key and the
+	// base64 encoded nonce and ciphertext. JSON never starts with this prefix, so plaintext values written
+	// before encryption was enabled are still read.
+	encryptedValuePrefix = "ffenc1:"
+)
+
+var datakeyColumns = []string{
+	"id",
+	"key_id",
+	"wrapped",
+}
+
+// envelope encrypts values with a data key, which is stored in the datakeys table wrapped with a key
+// encryption key from the key provider. A new data key is created each time the plugin is initialized.
+type envelope struct {
+	keyProvider keyprovider.Plugin
+	current     *dataKey
+	keysMux     sync.Mutex
+	keys        map[string]*dataKey
+}
+
+type dataKey struct {
+	id   *fftypes.UUID
+	aead cipher.AEAD
+}
+
+func newDataKey(id *fftypes.UUID, key []byte) (*dataKey, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	aead, _ := cipher.NewGCM(block) // only fails for a non-standard nonce size
+	return &dataKey{id: id, aead: aead}, nil
+}
+
+func (s *SQLCommon) initEncryption(ctx context.Context, config config.Section) error {
+	pluginType := config.GetString(SQLConfEncryptionKeyProvider)
+	if pluginType == "" {
+		return nil
+	}
+	keyProvider, err := kpfactory.GetPlugin(ctx, pluginType)
+	if err == nil {
+		err = keyProvider.Init(ctx, config.SubSection(pluginType))
+	}
+	if err != nil {
+		return err
+	}
+	e := &envelope{
+		keyProvider: keyProvider,
+		keys:        make(map[string]*dataKey),
+	}
+	if err := s.loadDataKeys(ctx, e); err != nil {
+		return err
+	}
+	if e.current, err = s.createDataKey(ctx, e); err != nil {
+		return err
+	}
+	s.encryption = e
+	s.capabilities.EncryptionAtRest = true
+	log.L(ctx).Infof("Values will be encrypted with data key '%s', wrapped with key '%s' from the %s key provider", e.current.id, keyProvider.CurrentKeyID(), pluginType)
+	return nil
+}
+
+// loadDataKeys unwraps every data key, so that values can be decrypted without a query while the rows of
+// another query are being scanned. Any data key that is not wrapped with the current key encryption key is
+// re-wrapped, so the previous key encryption keys can be removed from the key provider once every
+// node has restarted with the new current key.
+func (s *SQLCommon) loadDataKeys(ctx context.Context, e *envelope) error {
+	rows, _, err := s.Query(ctx, datakeysTable, sq.Select(datakeyColumns...).From(datakeysTable))
+	if err != nil {
+		return err
+	}
+	type storedKey struct {
+		id      *fftypes.UUID
+		keyID   string
+		wrapped string
+	}
+	var stored []*storedKey
+	for rows.Next() {
+		var sk storedKey
+		if err := rows.Scan(&sk.id, &sk.keyID, &sk.wrapped); err != nil {
+			rows.Close()
+			return i18n.WrapError(ctx, err, coremsgs.MsgDBReadErr, datakeysTable)
+		}
+		stored = append(stored, &sk)
+	}
+	rows.Close()
+
+	currentKeyID := e.keyProvider.CurrentKeyID()
+	rewrapped := 0
+	for _, sk := range stored {
+		key, err := e.unwrapKey(ctx, sk.id, sk.keyID, sk.wrapped)
+		if err != nil {
+			return err
+		}
+		if sk.keyID != currentKeyID {
+			if err := s.rewrapDataKey(ctx, e, sk.id, sk.keyID, key); err != nil {
+				return err
+			}
+			rewrapped++
+		}
+	}
+	log.L(ctx).Infof("Loaded %d data keys (%d re-wrapped with key '%s')", len(stored), rewrapped, currentKeyID)
+	return nil
+}
+
+func (s *SQLCommon) rewrapDataKey(ctx context.Context, e *envelope, id *fftypes.UUID, previousKeyID string, key []byte) error {
+	currentKeyID := e.keyProvider.CurrentKeyID()
+	wrapped, err := e.keyProvider.WrapKey(ctx, currentKeyID, key)
+	if err != nil {
+		return err
+	}
+	ctx, tx, autoCommit, err := s.BeginOrUseTx(ctx)
+	if err != nil {
+		return err
+	}
+	defer s.RollbackTx(ctx, tx, autoCommit)
+
+	// Another node might have re-wrapped the key first, in which case nothing is updated
+	_, err = s.UpdateTx(ctx, datakeysTable, tx,
+		sq.Update(datakeysTable).
+			Set("key_id", currentKeyID).
+			Set("wrapped", base64.StdEncoding.EncodeToString(wrapped)).
+			Set("updated", fftypes.Now()).
+			Where(sq.Eq{"id": id, "key_id": previousKeyID}),
+		nil)
+	if err != nil {
+		return err
+	}
+	return s.CommitTx(ctx, tx, autoCommit)
+}
+
+func (s *SQLCommon) createDataKey(ctx context.Context, e *envelope) (*dataKey, error) {
+	key := make([]byte, 32)
+	_, _ = rand.Read(key)
+	currentKeyID := e.keyProvider.CurrentKeyID()
+	wrapped, err := e.keyProvider.WrapKey(ctx, currentKeyID, key)
+	if err != nil {
+		return nil, err
+	}
+	dk, _ := newDataKey(fftypes.NewUUID(), key) // a 32 byte key is always valid
+
+	ctx, tx, autoCommit, err := s.BeginOrUseTx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer s.RollbackTx(ctx, tx, autoCommit)
+
+	now := fftypes.Now()
+	_, err = s.InsertTx(ctx, datakeysTable, tx,
+		sq.Insert(datakeysTable).
+			Columns(append(append([]string{}, datakeyColumns...), "created", "updated")...).
+			Values(dk.id, currentKeyID, base64.StdEncoding.EncodeToString(wrapped), now, now),
+		nil)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.CommitTx(ctx, tx, autoCommit); err != nil {
+		return nil, err
+	}
+	e.keys[dk.id.String()] = dk
+	return dk, nil
+}
+
+func (e *envelope) unwrapKey(ctx context.Context, id *fftypes.UUID, keyID, wrapped string) ([]byte, error) {
+	b, err := base64.StdEncoding.DecodeString(wrapped)
+	if err != nil {
+		return nil, i18n.WrapError(ctx, err, coremsgs.MsgDataKeyUnwrapFailed, keyID)
+	}
+	key, err := e.keyProvider.UnwrapKey(ctx, keyID, b)
+	if err != nil {
+		return nil, err
+	}
+	dk, err := newDataKey(id, key)
+	if err != nil {
+		return nil, i18n.WrapError(ctx, err, coremsgs.MsgDataKeyUnwrapFailed, keyID)
+	}
+	e.keysMux.Lock()
+	e.keys[id.String()] = dk
+	e.keysMux.Unlock()
+	return key, nil
+}
+
+// getDataKey returns a data key from the cache, or loads a data key created by another node since this
+// one started
+func (s *SQLCommon) getDataKey(ctx context.Context, id string) (*dataKey, error) {
+	e := s.encryption
+	if dk := e.cachedKey(id); dk != nil {
+		return dk, nil
+	}
+
+	rows, _, err := s.Query(ctx, datakeysTable,
+		sq.Select(datakeyColumns...).
+			From(datakeysTable).
+			Where(sq.Eq{"id": id}))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	if !rows.Next() {
+		return nil, i18n.NewError(ctx, coremsgs.MsgDataKeyNotFound, id)
+	}
+	var dkID fftypes.UUID
+	var keyID, wrapped string
+	if err := rows.Scan(&dkID, &keyID, &wrapped); err != nil {
+		return nil, i18n.WrapError(ctx, err, coremsgs.MsgDBReadErr, datakeysTable)
+	}
+	if _, err := e.unwrapKey(ctx, &dkID, keyID, wrapped); err != nil {
+		return nil, err
+	}
+	return e.cachedKey(id), nil
+}
+
+func (e *envelope) cachedKey(id string) *dataKey {
+	e.keysMux.Lock()
+	defer e.keysMux.Unlock()
+	return e.keys[id]
+}
+
+func (e *envelope) encrypt(plaintext string) string {
+	dk := e.current
+	nonce := make([]byte, dk.aead.NonceSize())
+	_, _ = rand.Read(nonce)
+	sealed := dk.aead.Seal(nonce, nonce, []byte(plaintext), nil)
+	return encryptedValuePrefix + dk.id.String() + ":" + base64.StdEncoding.EncodeToString(sealed)
+}
+
+func (s *SQLCommon) decrypt(ctx context.Context, value string) (string, error) {
+	if !strings.HasPrefix(value, encryptedValuePrefix) {
+		return value, nil
+	}
+	if s.encryption == nil {
+		return "", i18n.NewError(ctx, coremsgs.MsgEncryptedValueNoKeyProvider)
+	}
+	id, encoded := splitEncryptedValue(value)
+	dk, err := s.getDataKey(ctx, id)
+	if err != nil {
+		return "", err
+	}
+	sealed, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil || len(sealed) < dk.aead.NonceSize() {
+		return "", i18n.NewError(ctx, coremsgs.MsgDecryptFailed, id)
+	}
+	plaintext, err := dk.aead.Open(nil, sealed[:dk.aead.NonceSize()], sealed[dk.aead.NonceSize():], nil)
+	if err != nil {
+		return "", i18n.WrapError(ctx, err, coremsgs.MsgDecryptFailed, id)
+	}
+	return string(plaintext), nil
+}
+
+// splitEncryptedValue returns the ID of the data key, and the encoded nonce and ciphertext
+func splitEncryptedValue(value string) (id, encoded string) {
+	id, encoded, _ = strings.Cut(strings.TrimPrefix(value, encryptedValuePrefix), ":")
+	return id, encoded
+}
+
+// encryptedValue encrypts a value when it is written, so errors from serializing the value are returned
+// from the statement as they would be without encryption
+type encryptedValue struct {
+	e     *envelope
+	value driver.Valuer
+}
+
+func (ev *encryptedValue) Value() (driver.Value, error) {
+	v, err := ev.value.Value()
+	if err != nil || v == nil {
+		return v, err
+	}
+	return ev.e.encrypt(fmt.Sprintf("%s", v)), nil
+}
+
+// encryptedField is an update of a field that is encrypted
+type encryptedField struct {
+	ffapi.FieldSerialization
+	e *envelope
+}
+
+func (ef *encryptedField) Value() (driver.Value, error) {
+	return (&encryptedValue{e: ef.e, value: ef.FieldSerialization}).Value()
+}
+
+// encryptedUpdate is an update where the values of the encrypted fields are encrypted when it is finalized
+type encryptedUpdate struct {
+	ffapi.Update
+	e      *envelope
+	fields []string
+}
+
+func (u *encryptedUpdate) Finalize() (*ffapi.UpdateInfo, error) {
+	ui, err := u.Update.Finalize()
+	if err != nil {
+		return nil, err
+	}
+	for _, so := range ui.SetOperations {
+		for _, f := range u.fields {
+			if so.Field == f {
+				so.Value = &encryptedField{FieldSerialization: so.Value, e: u.e}
+			}
+		}
+	}
+	return ui, nil
+}
+
+// encrypted returns the value to write to an encrypted column
+func (s *SQLCommon) encrypted(v driver.Valuer) interface{} {
+	if s.encryption == nil {
+		return v
+	}
+	return &encryptedValue{e: s.encryption, value: v}
+}
+
+// encryptedFields returns an update that encrypts the values set on the given fields
+func (s *SQLCommon) encryptedFields(update ffapi.Update, fields ...string) ffapi.Update {
+	if s.encryption == nil {
+		return update
+	}
+	return &encryptedUpdate{Update: update, e: s.encryption, fields: fields}
+}
+
+// decryptions scans the columns that might be encrypted in one set of rows. A value encrypted with a data key
+// that is not cached, because another node created it since this one started, is only decrypted by complete
+// once the rows are closed, as the query for the data key might need the connection the rows are holding.
+type decryptions struct {
+	ctx     context.Context
+	s       *SQLCommon
+	pending []func() error
+}
+
+func (s *SQLCommon) newDecryptions(ctx context.Context) *decryptions {
+	return &decryptions{ctx: ctx, s: s}
+}
+
+// complete decrypts the values that were waiting for a data key to be loaded
+func (d *decryptions) complete() error {
+	for _, decrypt := range d.pending {
+		if err := decrypt(); err != nil {
+			return err
+		}
+	}
+	d.pending = nil
+	return nil
+}
+
+// decryptingScanner decrypts a value that might be encrypted, before passing it on to be scanned
+type decryptingScanner struct {
+	d    *decryptions
+	scan func(src interface{}) error
+}
+
+func (ds *decryptingScanner) Scan(src interface{}) error {
+	var value string
+	switch v := src.(type) {
+	case string:
+		value = v
+	case []byte:
+		value = string(v)
+	default:
+		return ds.scan(src)
+	}
+	if !strings.HasPrefix(value, encryptedValuePrefix) {
+		return ds.scan(value)
+	}
+	decrypt := func() error {
+		plaintext, err := ds.d.s.decrypt(ds.d.ctx, value)
+		if err != nil {
+			return err
+		}
+		return ds.scan(plaintext)
+	}
+	if e := ds.d.s.encryption; e != nil {
+		if id, _ := splitEncryptedValue(value); e.cachedKey(id) == nil {
+			ds.d.pending = append(ds.d.pending, decrypt)
+			return nil
+		}
+	}
+	return decrypt()
+}
+
+// jsonAny scans a column that might be encrypted into a JSONAny, which is nil for a NULL value
+func (d *decryptions) jsonAny(target **fftypes.JSONAny) sql.Scanner {
+	return &decryptingScanner{d: d, scan: func(src interface{}) error {
+		if src == nil {
+			*target = nil
+			return nil
+		}
+		*target = new(fftypes.JSONAny)
+		return (*target).Scan(src)
+	}}
+}
+
+// jsonObject scans a column that might be encrypted into a JSONObject
+func (d *decryptions) jsonObject(target *fftypes.JSONObject) sql.Scanner {
+	return &decryptingScanner{d: d, scan: target.Scan}
+}
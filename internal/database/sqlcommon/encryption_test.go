@@ -0,0 +1,371 @@
+// Copyright © 2024 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqlcommon
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/hyperledger/firefly-common/pkg/config"
+	"github.com/hyperledger/firefly-common/pkg/fftypes"
+	"github.com/hyperledger/firefly/internal/keyprovider/filekeys"
+	"github.com/hyperledger/firefly/internal/keyprovider/kpfactory"
+	"github.com/hyperledger/firefly/mocks/keyprovidermocks"
+	"github.com/hyperledger/firefly/pkg/core"
+	"github.com/hyperledger/firefly/pkg/database"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func newEncryptionConfig(t *testing.T, keys map[string]string, current string) config.Section {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "current: %s\nkeys:\n", current)
+	for id, key := range keys {
+		fmt.Fprintf(&sb, "  %s: %s\n", id, key)
+	}
+	path := filepath.Join(t.TempDir(), "keys.yaml")
+	assert.NoError(t, os.WriteFile(path, []byte(sb.String()), 0600))
+
+	conf := config.RootSection("unittest.encryption")
+	conf.AddKnownKey(SQLConfEncryptionKeyProvider)
+	kpfactory.InitConfig(conf)
+	conf.Set(SQLConfEncryptionKeyProvider, "file")
+	conf.SubSection("file").Set(filekeys.FileKeysConfPath, path)
+	return conf
+}
+
+func newKey() string {
+	key := make([]byte, 32)
+	_, _ = rand.Read(key)
+	return base64.StdEncoding.EncodeToString(key)
+}
+
+func newMockEnvelope(s *SQLCommon) *keyprovidermocks.Plugin {
+	kp := &keyprovidermocks.Plugin{}
+	kp.On("CurrentKeyID").Return("key1").Maybe()
+	s.encryption = &envelope{keyProvider: kp, keys: map[string]*dataKey{}}
+	dk, _ := newDataKey(fftypes.NewUUID(), make([]byte, 32))
+	s.encryption.current = dk
+	s.encryption.keys[dk.id.String()] = dk
+	return kp
+}
+
+func TestEncryptionE2EWithDB(t *testing.T) {
+	s, cleanup := newSQLiteTestProvider(t)
+	defer cleanup()
+	ctx := context.Background()
+	s.callbacks.On("UUIDCollectionNSEvent", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return()
+
+	// A value written before encryption is enabled
+	plainData := &core.Data{ID: fftypes.NewUUID(), Namespace: "ns1", Hash: fftypes.NewRandB32(), Value: fftypes.JSONAnyPtr(`{"plain":true}`), Created: fftypes.Now()}
+	err := s.UpsertData(ctx, plainData, database.UpsertOptimizationNew)
+	assert.NoError(t, err)
+
+	keys := map[string]string{"key1": newKey()}
+	assert.False(t, s.Capabilities().EncryptionAtRest)
+	err = s.initEncryption(ctx, newEncryptionConfig(t, keys, "key1"))
+	assert.NoError(t, err)
+	assert.True(t, s.Capabilities().EncryptionAtRest)
+
+	data := &core.Data{ID: fftypes.NewUUID(), Namespace: "ns1", Hash: fftypes.NewRandB32(), Value: fftypes.JSONAnyPtr(`{"secret":"value"}`), Created: fftypes.Now()}
+	err = s.UpsertData(ctx, data, database.UpsertOptimizationNew)
+	assert.NoError(t, err)
+	op := &core.Operation{
+		ID:          fftypes.NewUUID(),
+		Namespace:   "ns1",
+		Transaction: fftypes.NewUUID(),
+		Type:        core.OpTypeBlockchainPinBatch,
+		Status:      core.OpStatusPending,
+		Input:       fftypes.JSONObject{"some": "input-info"},
+		Created:     fftypes.Now(),
+		Updated:     fftypes.Now(),
+	}
+	err = s.InsertOperation(ctx, op)
+	assert.NoError(t, err)
+	_, err = s.UpdateOperation(ctx, "ns1", op.ID, nil, database.OperationQueryFactory.NewUpdate(ctx).
+		Set("output", fftypes.JSONObject{"some": "output-info"}).
+		Set("status", core.OpStatusSucceeded))
+	assert.NoError(t, err)
+
+	// The values are encrypted in the database
+	var rawValue, rawInput, rawOutput string
+	err = s.DB().QueryRow("SELECT value FROM data WHERE id = ?", data.ID).Scan(&rawValue)
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(rawValue, encryptedValuePrefix+s.encryption.current.id.String()+":"))
+	assert.NotContains(t, rawValue, "secret")
+	err = s.DB().QueryRow("SELECT input, output FROM operations WHERE id = ?", op.ID).Scan(&rawInput, &rawOutput)
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(rawInput, encryptedValuePrefix))
+	assert.True(t, strings.HasPrefix(rawOutput, encryptedValuePrefix))
+
+	// ... and decrypted when they are read, along with the values written in plaintext
+	dataRead, err := s.GetDataByID(ctx, "ns1", data.ID, true)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"secret":"value"}`, dataRead.Value.String())
+	dataRead, err = s.GetDataByID(ctx, "ns1", plainData.ID, true)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"plain":true}`, dataRead.Value.String())
+	opRead, err := s.GetOperationByID(ctx, "ns1", op.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, "input-info", opRead.Input.GetString("some"))
+	assert.Equal(t, "output-info", opRead.Output.GetString("some"))
+	assert.Equal(t, core.OpStatusSucceeded, opRead.Status)
+
+	// JSON-path conditions cannot be evaluated on encrypted values
+	fb := database.DataQueryFactory.NewFilter(ctx)
+	_, _, err = s.GetData(ctx, "ns1", fb.Eq("jsonpath", "secret:value"))
+	assert.Regexp(t, "FF10530", err)
+
+	// Rotate the key encryption key, and check the existing data keys are re-wrapped
+	firstDataKey := s.encryption.current.id
+	keys["key2"] = newKey()
+	err = s.initEncryption(ctx, newEncryptionConfig(t, keys, "key2"))
+	assert.NoError(t, err)
+	var count int
+	err = s.DB().QueryRow("SELECT COUNT(*) FROM datakeys WHERE key_id = 'key2'").Scan(&count)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, count)
+
+	// The previous key encryption key can now be removed
+	delete(keys, "key1")
+	err = s.initEncryption(ctx, newEncryptionConfig(t, keys, "key2"))
+	assert.NoError(t, err)
+	assert.NotEqual(t, firstDataKey, s.encryption.current.id)
+	dataRead, err = s.GetDataByID(ctx, "ns1", data.ID, true)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"secret":"value"}`, dataRead.Value.String())
+
+	// A data key created by another node is loaded when it is first used
+	delete(s.encryption.keys, firstDataKey.String())
+	data.Value = nil
+	dataRead, err = s.GetDataByID(ctx, "ns1", data.ID, true)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"secret":"value"}`, dataRead.Value.String())
+
+	// A null value stays null
+	nullData := &core.Data{ID: fftypes.NewUUID(), Namespace: "ns1", Hash: fftypes.NewRandB32(), Created: fftypes.Now()}
+	err = s.UpsertData(ctx, nullData, database.UpsertOptimizationNew)
+	assert.NoError(t, err)
+	dataRead, err = s.GetDataByID(ctx, "ns1", nullData.ID, true)
+	assert.NoError(t, err)
+	assert.Nil(t, dataRead.Value)
+
+	// Encrypted values cannot be read without a key provider
+	s.encryption = nil
+	_, err = s.GetDataByID(ctx, "ns1", data.ID, true)
+	assert.Regexp(t, "FF10527", err)
+}
+
+func TestInitEncryptionUnknownPlugin(t *testing.T) {
+	mp := newMockProvider()
+	mp.config.SubSection(SQLConfEncryption).Set(SQLConfEncryptionKeyProvider, "wrong")
+	err := mp.Init(context.Background(), mp, mp.config, mp.capabilities)
+	assert.Regexp(t, "FF10522", err)
+}
+
+func TestInitEncryptionPluginInitFail(t *testing.T) {
+	mp := newMockProvider()
+	mp.config.SubSection(SQLConfEncryption).Set(SQLConfEncryptionKeyProvider, "file")
+	err := mp.Init(context.Background(), mp, mp.config, mp.capabilities)
+	assert.Regexp(t, "FF10138", err)
+}
+
+func TestInitEncryptionLoadFail(t *testing.T) {
+	s, mdb := newMockProvider().init()
+	mdb.ExpectQuery("SELECT .*").WillReturnError(fmt.Errorf("pop"))
+	err := s.initEncryption(context.Background(), newEncryptionConfig(t, map[string]string{"key1": newKey()}, "key1"))
+	assert.Regexp(t, "FF00176", err)
+	assert.NoError(t, mdb.ExpectationsWereMet())
+}
+
+func TestInitEncryptionCreateFail(t *testing.T) {
+	s, mdb := newMockProvider().init()
+	mdb.ExpectQuery("SELECT .*").WillReturnRows(sqlmock.NewRows(datakeyColumns))
+	mdb.ExpectBegin().WillReturnError(fmt.Errorf("pop"))
+	err := s.initEncryption(context.Background(), newEncryptionConfig(t, map[string]string{"key1": newKey()}, "key1"))
+	assert.Regexp(t, "FF00175", err)
+	assert.NoError(t, mdb.ExpectationsWereMet())
+}
+
+func TestLoadDataKeysScanFail(t *testing.T) {
+	s, mdb := newMockProvider().init()
+	kp := newMockEnvelope(&s.SQLCommon)
+	mdb.ExpectQuery("SELECT .*").WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("only one"))
+	err := s.loadDataKeys(context.Background(), s.encryption)
+	assert.Regexp(t, "FF10121", err)
+	kp.AssertExpectations(t)
+}
+
+func TestLoadDataKeysUnwrapFail(t *testing.T) {
+	s, mdb := newMockProvider().init()
+	kp := newMockEnvelope(&s.SQLCommon)
+	mdb.ExpectQuery("SELECT .*").WillReturnRows(sqlmock.NewRows(datakeyColumns).AddRow(fftypes.NewUUID().String(), "key0", "!base64"))
+	err := s.loadDataKeys(context.Background(), s.encryption)
+	assert.Regexp(t, "FF10526", err)
+	kp.AssertExpectations(t)
+}
+
+func TestLoadDataKeysRewrapFail(t *testing.T) {
+	s, mdb := newMockProvider().init()
+	kp := newMockEnvelope(&s.SQLCommon)
+	kp.On("UnwrapKey", mock.Anything, "key0", []byte("wrapped")).Return(make([]byte, 32), nil)
+	kp.On("WrapKey", mock.Anything, "key1", mock.Anything).Return(nil, fmt.Errorf("pop"))
+	mdb.ExpectQuery("SELECT .*").WillReturnRows(sqlmock.NewRows(datakeyColumns).AddRow(fftypes.NewUUID().String(), "key0", base64.StdEncoding.EncodeToString([]byte("wrapped"))))
+	err := s.loadDataKeys(context.Background(), s.encryption)
+	assert.Regexp(t, "pop", err)
+	kp.AssertExpectations(t)
+}
+
+func TestRewrapDataKeyFail(t *testing.T) {
+	s, mdb := newMockProvider().init()
+	kp := newMockEnvelope(&s.SQLCommon)
+	kp.On("WrapKey", mock.Anything, "key1", mock.Anything).Return([]byte("wrapped"), nil)
+	mdb.ExpectBegin().WillReturnError(fmt.Errorf("pop"))
+	err := s.rewrapDataKey(context.Background(), s.encryption, fftypes.NewUUID(), "key0", make([]byte, 32))
+	assert.Regexp(t, "FF00175", err)
+	mdb.ExpectBegin()
+	mdb.ExpectExec("UPDATE .*").WillReturnError(fmt.Errorf("pop"))
+	mdb.ExpectRollback()
+	err = s.rewrapDataKey(context.Background(), s.encryption, fftypes.NewUUID(), "key0", make([]byte, 32))
+	assert.Regexp(t, "FF00178", err)
+	assert.NoError(t, mdb.ExpectationsWereMet())
+	kp.AssertExpectations(t)
+}
+
+func TestCreateDataKeyFail(t *testing.T) {
+	s, mdb := newMockProvider().init()
+	kp := newMockEnvelope(&s.SQLCommon)
+	kp.On("WrapKey", mock.Anything, "key1", mock.Anything).Return(nil, fmt.Errorf("pop")).Once()
+	_, err := s.createDataKey(context.Background(), s.encryption)
+	assert.Regexp(t, "pop", err)
+
+	kp.On("WrapKey", mock.Anything, "key1", mock.Anything).Return([]byte("wrapped"), nil)
+	mdb.ExpectBegin()
+	mdb.ExpectExec("INSERT .*").WillReturnError(fmt.Errorf("pop"))
+	mdb.ExpectRollback()
+	_, err = s.createDataKey(context.Background(), s.encryption)
+	assert.Regexp(t, "FF00177", err)
+
+	mdb.ExpectBegin()
+	mdb.ExpectExec("INSERT .*").WillReturnResult(sqlmock.NewResult(1, 1))
+	mdb.ExpectCommit().WillReturnError(fmt.Errorf("pop"))
+	_, err = s.createDataKey(context.Background(), s.encryption)
+	assert.Regexp(t, "FF00180", err)
+	assert.NoError(t, mdb.ExpectationsWereMet())
+	kp.AssertExpectations(t)
+}
+
+func TestUnwrapKeyFail(t *testing.T) {
+	s, _ := newMockProvider().init()
+	kp := newMockEnvelope(&s.SQLCommon)
+	kp.On("UnwrapKey", mock.Anything, "key0", []byte("wrapped")).Return(nil, fmt.Errorf("pop")).Once()
+	_, err := s.encryption.unwrapKey(context.Background(), fftypes.NewUUID(), "key0", base64.StdEncoding.EncodeToString([]byte("wrapped")))
+	assert.Regexp(t, "pop", err)
+
+	kp.On("UnwrapKey", mock.Anything, "key0", []byte("wrapped")).Return([]byte("short"), nil)
+	_, err = s.encryption.unwrapKey(context.Background(), fftypes.NewUUID(), "key0", base64.StdEncoding.EncodeToString([]byte("wrapped")))
+	assert.Regexp(t, "FF10526", err)
+	kp.AssertExpectations(t)
+}
+
+func TestGetDataKeyFail(t *testing.T) {
+	s, mdb := newMockProvider().init()
+	kp := newMockEnvelope(&s.SQLCommon)
+	id := fftypes.NewUUID().String()
+
+	mdb.ExpectQuery("SELECT .*").WillReturnError(fmt.Errorf("pop"))
+	_, err := s.getDataKey(context.Background(), id)
+	assert.Regexp(t, "FF00176", err)
+
+	mdb.ExpectQuery("SELECT .*").WillReturnRows(sqlmock.NewRows(datakeyColumns))
+	_, err = s.getDataKey(context.Background(), id)
+	assert.Regexp(t, "FF10528", err)
+
+	mdb.ExpectQuery("SELECT .*").WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(id))
+	_, err = s.getDataKey(context.Background(), id)
+	assert.Regexp(t, "FF10121", err)
+
+	mdb.ExpectQuery("SELECT .*").WillReturnRows(sqlmock.NewRows(datakeyColumns).AddRow(id, "key0", "!base64"))
+	_, err = s.getDataKey(context.Background(), id)
+	assert.Regexp(t, "FF10526", err)
+
+	assert.NoError(t, mdb.ExpectationsWereMet())
+	kp.AssertExpectations(t)
+}
+
+func TestDecryptFail(t *testing.T) {
+	s, mdb := newMockProvider().init()
+	newMockEnvelope(&s.SQLCommon)
+	ctx := context.Background()
+	prefix := encryptedValuePrefix + s.encryption.current.id.String() + ":"
+
+	_, err := s.decrypt(ctx, prefix+"!base64")
+	assert.Regexp(t, "FF10529", err)
+	_, err = s.decrypt(ctx, prefix+base64.StdEncoding.EncodeToString([]byte("short")))
+	assert.Regexp(t, "FF10529", err)
+	_, err = s.decrypt(ctx, prefix+base64.StdEncoding.EncodeToString(make([]byte, 64)))
+	assert.Regexp(t, "FF10529", err)
+
+	mdb.ExpectQuery("SELECT .*").WillReturnRows(sqlmock.NewRows(datakeyColumns))
+	_, err = s.decrypt(ctx, encryptedValuePrefix+"unknown:"+base64.StdEncoding.EncodeToString(make([]byte, 64)))
+	assert.Regexp(t, "FF10528", err)
+
+	var obj fftypes.JSONObject
+	err = s.newDecryptions(ctx).jsonObject(&obj).Scan([]byte(prefix))
+	assert.Regexp(t, "FF10529", err)
+
+	// The data key is only queried once the rows are closed
+	d := s.newDecryptions(ctx)
+	err = d.jsonObject(&obj).Scan(encryptedValuePrefix + "unknown:" + base64.StdEncoding.EncodeToString(make([]byte, 64)))
+	assert.NoError(t, err)
+	mdb.ExpectQuery("SELECT .*").WillReturnRows(sqlmock.NewRows(datakeyColumns))
+	err = d.complete()
+	assert.Regexp(t, "FF10528", err)
+	assert.NoError(t, mdb.ExpectationsWereMet())
+}
+
+func TestDecryptRoundTrip(t *testing.T) {
+	s, _ := newMockProvider().init()
+	newMockEnvelope(&s.SQLCommon)
+	ctx := context.Background()
+
+	v, err := s.encrypted(fftypes.JSONObject{"some": "value"}).(*encryptedValue).Value()
+	assert.NoError(t, err)
+	var obj fftypes.JSONObject
+	err = s.newDecryptions(ctx).jsonObject(&obj).Scan([]byte(v.(string)))
+	assert.NoError(t, err)
+	assert.Equal(t, "value", obj.GetString("some"))
+
+	v, err = s.encrypted(fftypes.JSONObject{"bad": map[bool]bool{true: true}}).(*encryptedValue).Value()
+	assert.Error(t, err)
+	assert.Nil(t, v)
+}
+
+func TestEncryptedUpdateFinalizeFail(t *testing.T) {
+	s, _ := newMockProvider().init()
+	newMockEnvelope(&s.SQLCommon)
+	u := database.OperationQueryFactory.NewUpdate(context.Background()).Set("wrong", "value")
+	_, err := s.encryptedFields(u, "input").Finalize()
+	assert.Regexp(t, "FF00142", err)
+}
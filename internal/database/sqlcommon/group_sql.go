@@ -45,7 +45,7 @@ var (
 	}
 )
 
-const groupsTable = "groups"
+const groupsTable = `"groups"`
 
 func (s *SQLCommon) UpsertGroup(ctx context.Context, group *core.Group, optimization database.UpsertOptimization) (err error) {
 	ctx, tx, autoCommit, err := s.BeginOrUseTx(ctx)
@@ -0,0 +1,310 @@
+// Copyright © 2024 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqlcommon
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/hyperledger/firefly-common/pkg/config"
+	"github.com/hyperledger/firefly-common/pkg/dbsql"
+	"github.com/hyperledger/firefly-common/pkg/ffapi"
+	"github.com/hyperledger/firefly-common/pkg/i18n"
+	"github.com/hyperledger/firefly/internal/coremsgs"
+)
+
+// jsonPathField is the query field for conditions on the values inside JSON data, in the form "path:value"
+const jsonPathField = "jsonpath"
+
+// Keys are restricted so that paths can be written into SQL as literals, without any escaping
+var jsonPathSegmentRegex = regexp.MustCompile(`^([A-Za-z0-9_\-]+)((?:\[[0-9]+\])*)$`)
+var jsonPathIndexRegex = regexp.MustCompile(`\[([0-9]+)\]`)
+
+// JSONPath is a list of object keys (strings) and array indexes (ints), leading to a value inside a JSON document
+type JSONPath []interface{}
+
+func (p JSONPath) String() string {
+	var b strings.Builder
+	for i, s := range p {
+		switch s := s.(type) {
+		case int:
+			fmt.Fprintf(&b, "[%d]", s)
+		default:
+			if i > 0 {
+				b.WriteRune('.')
+			}
+			b.WriteString(s.(string))
+		}
+	}
+	return b.String()
+}
+
+func parseJSONPath(ctx context.Context, path string, maxDepth int) (JSONPath, error) {
+	var p JSONPath
+	// Every part starts with a key, as "[" at the start of a query value is a filter modifier
+	for _, part := range strings.Split(path, ".") {
+		m := jsonPathSegmentRegex.FindStringSubmatch(part)
+		if m == nil {
+			return nil, i18n.NewError(ctx, coremsgs.MsgJSONPathInvalid, path)
+		}
+		p = append(p, m[1])
+		for _, idx := range jsonPathIndexRegex.FindAllStringSubmatch(m[2], -1) {
+			n, err := strconv.Atoi(idx[1])
+			if err != nil {
+				return nil, i18n.NewError(ctx, coremsgs.MsgJSONPathInvalid, path)
+			}
+			p = append(p, n)
+		}
+	}
+	if len(p) > maxDepth {
+		return nil, i18n.NewError(ctx, coremsgs.MsgJSONPathTooDeep, path, maxDepth)
+	}
+	return p, nil
+}
+
+// parseJSONPathCondition splits the value of a JSON-path condition into the path, and the value to match
+func parseJSONPathCondition(ctx context.Context, v ffapi.FieldSerialization, maxDepth int) (JSONPath, string, error) {
+	value, _ := v.Value()
+	condition, _ := value.(string)
+	path, match, ok := strings.Cut(condition, ":")
+	if !ok {
+		return nil, "", i18n.NewError(ctx, coremsgs.MsgJSONPathInvalid, condition)
+	}
+	p, err := parseJSONPath(ctx, path, maxDepth)
+	return p, match, err
+}
+
+func jsonPathValue(match string) ffapi.FieldSerialization {
+	v := (&ffapi.StringField{}).GetSerialization()
+	_ = v.Scan(match)
+	return v
+}
+
+// jsonPathProvider builds the SQL for JSON-path conditions, as the JSON functions of each database differ.
+// SQLCommon provides the SQLite functions, and other providers override them.
+type jsonPathProvider interface {
+	// JSONPathText returns an expression for the text of the value at the path in the JSON column, or NULL if there is no value
+	JSONPathText(column string, path JSONPath) string
+	// JSONPathContains returns an optional condition that can use an index, which matches every row where the
+	// text of the value at the path equals the match - and possibly some others
+	JSONPathContains(column string, path JSONPath, match string) sq.Sqlizer
+}
+
+type jsonPathConfig struct {
+	provider      jsonPathProvider
+	maxConditions int
+	maxDepth      int
+}
+
+func (jc *jsonPathConfig) init(s *SQLCommon, provider dbsql.Provider, config config.Section) {
+	var ok bool
+	if jc.provider, ok = provider.(jsonPathProvider); !ok {
+		jc.provider = s
+	}
+	jc.maxConditions = config.GetInt(SQLConfJSONPathMaxConditions)
+	jc.maxDepth = config.GetInt(SQLConfJSONPathMaxDepth)
+}
+
+func (s *SQLCommon) JSONPathText(column string, path JSONPath) string {
+	var sqlitePath strings.Builder
+	sqlitePath.WriteRune('$')
+	for _, p := range path {
+		switch p := p.(type) {
+		case int:
+			fmt.Fprintf(&sqlitePath, "[%d]", p)
+		default:
+			fmt.Fprintf(&sqlitePath, `."%s"`, p)
+		}
+	}
+	// json_extract returns 1 and 0 for true and false, rather than their JSON text
+	return fmt.Sprintf(`(CASE json_type(%[1]s, '%[2]s') WHEN 'true' THEN 'true' WHEN 'false' THEN 'false' ELSE CAST(json_extract(%[1]s, '%[2]s') AS TEXT) END)`,
+		column, sqlitePath.String())
+}
+
+func (s *SQLCommon) JSONPathContains(column string, path JSONPath, match string) sq.Sqlizer {
+	return nil
+}
+
+// jsonPathFilter is a filter that has been finalized, and had its JSON-path conditions rewritten.
+// Any error is returned when the filter is finalized again.
+type jsonPathFilter struct {
+	ffapi.Filter
+	fi  *ffapi.FilterInfo
+	err error
+}
+
+func (f *jsonPathFilter) Finalize() (*ffapi.FilterInfo, error) {
+	return f.fi, f.err
+}
+
+// rewriteJSONPath changes each JSON-path condition into the same condition on the text of the value at the path
+func (s *SQLCommon) rewriteJSONPath(ctx context.Context, fi *ffapi.FilterInfo, column string, count *int) (err error) {
+	switch fi.Op {
+	case ffapi.FilterOpAnd, ffapi.FilterOpOr:
+		for _, c := range fi.Children {
+			if err := s.rewriteJSONPath(ctx, c, column, count); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if fi.Field != jsonPathField {
+		return nil
+	}
+	if s.encryption != nil {
+		return i18n.NewError(ctx, coremsgs.MsgJSONPathEncrypted)
+	}
+	if *count++; *count > s.jsonPath.maxConditions {
+		return i18n.NewError(ctx, coremsgs.MsgJSONPathTooManyConditions, s.jsonPath.maxConditions)
+	}
+	var path JSONPath
+	var match string
+	switch fi.Op {
+	case ffapi.FilterOpIn, ffapi.FilterOpNotIn:
+		for i, v := range fi.Values {
+			p, m, err := parseJSONPathCondition(ctx, v, s.jsonPath.maxDepth)
+			if err != nil {
+				return err
+			}
+			if path != nil && p.String() != path.String() {
+				return i18n.NewError(ctx, coremsgs.MsgJSONPathInPaths)
+			}
+			path = p
+			fi.Values[i] = jsonPathValue(m)
+		}
+	default:
+		if path, match, err = parseJSONPathCondition(ctx, fi.Value, s.jsonPath.maxDepth); err != nil {
+			return err
+		}
+		fi.Value = jsonPathValue(match)
+	}
+	fi.Field = s.jsonPath.provider.JSONPathText(column, path)
+	return nil
+}
+
+// jsonPathIndexConditions returns the conditions that let the database use an index, for the equality
+// JSON-path conditions that must all be true for the filter to match
+func (s *SQLCommon) jsonPathIndexConditions(ctx context.Context, fi *ffapi.FilterInfo, column string) (conditions []sq.Sqlizer, err error) {
+	required := []*ffapi.FilterInfo{fi}
+	if fi.Op == ffapi.FilterOpAnd {
+		required = fi.Children
+	}
+	for _, c := range required {
+		if c.Op != ffapi.FilterOpEq || c.Field != jsonPathField {
+			continue
+		}
+		path, match, err := parseJSONPathCondition(ctx, c.Value, s.jsonPath.maxDepth)
+		if err != nil {
+			return nil, err
+		}
+		if condition := s.jsonPath.provider.JSONPathContains(column, path, match); condition != nil {
+			conditions = append(conditions, condition)
+		}
+	}
+	return conditions, nil
+}
+
+// dataJSONPathFilter applies the JSON-path conditions in a filter to the value of each data item.
+// It returns the filter to query with, and any extra preconditions.
+func (s *SQLCommon) dataJSONPathFilter(ctx context.Context, filter ffapi.Filter, column string) (ffapi.Filter, []sq.Sqlizer) {
+	fi, err := filter.Finalize()
+	var conditions []sq.Sqlizer
+	if err == nil {
+		conditions, err = s.jsonPathIndexConditions(ctx, fi, column)
+	}
+	if err == nil {
+		count := 0
+		err = s.rewriteJSONPath(ctx, fi, column, &count)
+	}
+	return &jsonPathFilter{Filter: filter, fi: fi, err: err}, conditions
+}
+
+func isJSONPathOnly(fi *ffapi.FilterInfo) bool {
+	switch fi.Op {
+	case ffapi.FilterOpAnd, ffapi.FilterOpOr:
+		for _, c := range fi.Children {
+			if !isJSONPathOnly(c) {
+				return false
+			}
+		}
+		return len(fi.Children) > 0
+	default:
+		return fi.Field == jsonPathField
+	}
+}
+
+func hasJSONPath(fi *ffapi.FilterInfo) bool {
+	for _, c := range fi.Children {
+		if hasJSONPath(c) {
+			return true
+		}
+	}
+	return fi.Field == jsonPathField
+}
+
+// messageJSONPathFilter moves the JSON-path conditions in a message filter into one precondition, that the
+// message has a data item whose value matches all of them. It returns the filter to query the remaining
+// conditions with, and the preconditions.
+func (s *SQLCommon) messageJSONPathFilter(ctx context.Context, namespace string, filter ffapi.Filter, idColumn string) (ffapi.Filter, []sq.Sqlizer) {
+	fi, err := filter.Finalize()
+	if err != nil {
+		return &jsonPathFilter{Filter: filter, err: err}, nil
+	}
+	conditions := []*ffapi.FilterInfo{fi}
+	if fi.Op == ffapi.FilterOpAnd {
+		conditions = fi.Children
+	}
+	var jsonPathConditions, otherConditions []*ffapi.FilterInfo
+	for _, c := range conditions {
+		switch {
+		case isJSONPathOnly(c):
+			jsonPathConditions = append(jsonPathConditions, c)
+		case hasJSONPath(c):
+			return &jsonPathFilter{Filter: filter, err: i18n.NewError(ctx, coremsgs.MsgJSONPathMixedOr)}, nil
+		default:
+			otherConditions = append(otherConditions, c)
+		}
+	}
+	if len(jsonPathConditions) == 0 {
+		return &jsonPathFilter{Filter: filter, fi: fi}, nil
+	}
+
+	dataFilter, dataConditions := s.dataJSONPathFilter(ctx, &jsonPathFilter{Filter: filter, fi: &ffapi.FilterInfo{
+		Op:       ffapi.FilterOpAnd,
+		Children: jsonPathConditions,
+	}}, "d.value")
+	dataConditions = append(dataConditions, sq.Eq{"md.namespace": namespace})
+	matches, _, _, err := s.FilterSelect(ctx, "",
+		sq.Select("md.message_id").From("messages_data AS md").Join("data AS d ON d.id = md.data_id"),
+		dataFilter, nil, nil, dataConditions...)
+	if err != nil {
+		return &jsonPathFilter{Filter: filter, err: err}, nil
+	}
+
+	// The remaining conditions keep the sort, skip and limit of the filter
+	remaining := *fi
+	remaining.Op = ffapi.FilterOpAnd
+	remaining.Field = ""
+	remaining.Value = nil
+	remaining.Values = nil
+	remaining.Children = otherConditions
+	return &jsonPathFilter{Filter: filter, fi: &remaining}, []sq.Sqlizer{sq.Expr(fmt.Sprintf("%s IN (?)", idColumn), matches)}
+}
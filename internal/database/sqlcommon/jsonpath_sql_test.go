@@ -0,0 +1,285 @@
+// Copyright © 2024 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqlcommon
+
+import (
+	"context"
+	"database/sql/driver"
+	"fmt"
+	"testing"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/hyperledger/firefly-common/pkg/dbsql"
+	"github.com/hyperledger/firefly-common/pkg/ffapi"
+	"github.com/hyperledger/firefly-common/pkg/fftypes"
+	"github.com/hyperledger/firefly/pkg/core"
+	"github.com/hyperledger/firefly/pkg/database"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func insertJSONPathTestData(t *testing.T, s *sqliteGoTestProvider) []*core.Message {
+	ctx := context.Background()
+	s.callbacks.On("UUIDCollectionNSEvent", database.CollectionData, core.ChangeEventTypeCreated, "ns1", mock.Anything).Return()
+	s.callbacks.On("OrderedUUIDCollectionNSEvent", database.CollectionMessages, core.ChangeEventTypeCreated, "ns1", mock.Anything, mock.Anything).Return()
+	values := []string{
+		`{"invoice":{"number":"INV-001","total":150,"paid":true,"lines":[{"sku":"A1"},{"sku":"B2"}]}}`,
+		`{"invoice":{"number":"INV-002","total":99.5,"paid":false,"lines":[{"sku":"B2"}]}}`,
+		`{"order":{"number":"INV-001"}}`,
+		`["first","second"]`,
+	}
+	msgs := make([]*core.Message, len(values))
+	for i, v := range values {
+		data := &core.Data{
+			ID:        fftypes.NewUUID(),
+			Validator: core.ValidatorTypeJSON,
+			Namespace: "ns1",
+			Hash:      fftypes.NewRandB32(),
+			Created:   fftypes.Now(),
+			Value:     fftypes.JSONAnyPtr(v),
+		}
+		err := s.UpsertData(ctx, data, database.UpsertOptimizationNew)
+		assert.NoError(t, err)
+		msgs[i] = &core.Message{
+			Header: core.MessageHeader{
+				ID:        fftypes.NewUUID(),
+				Type:      core.MessageTypeBroadcast,
+				Namespace: "ns1",
+				Created:   fftypes.Now(),
+				DataHash:  fftypes.NewRandB32(),
+			},
+			Hash:           fftypes.NewRandB32(),
+			LocalNamespace: "ns1",
+			State:          core.MessageStateConfirmed,
+			Data:           core.DataRefs{{ID: data.ID, Hash: data.Hash}},
+		}
+		err = s.UpsertMessage(ctx, msgs[i], database.UpsertOptimizationNew)
+		assert.NoError(t, err)
+	}
+	return msgs
+}
+
+func queryDataJSONPath(t *testing.T, s *sqliteGoTestProvider, conditions ...string) (core.DataArray, error) {
+	ctx := context.Background()
+	fb := database.DataQueryFactory.NewFilter(ctx)
+	filter, err := ffapi.ParseFilterParam(ctx, fb, "jsonpath", conditions)
+	assert.NoError(t, err)
+	data, _, err := s.GetData(ctx, "ns1", fb.And(filter))
+	return data, err
+}
+
+func TestJSONPathDataE2EWithDB(t *testing.T) {
+	s, cleanup := newSQLiteTestProvider(t)
+	defer cleanup()
+	insertJSONPathTestData(t, s)
+
+	tests := []struct {
+		conditions []string
+		matches    int
+	}{
+		{[]string{"invoice.number:INV-001"}, 1},
+		{[]string{"invoice.number:INV-003"}, 0},
+		{[]string{"^invoice.number:INV-"}, 2},
+		{[]string{":invoice.number:inv-002"}, 1},
+		{[]string{"!invoice.number:INV-001"}, 1},
+		{[]string{"invoice.total:150"}, 1},
+		{[]string{"invoice.total:99.5"}, 1},
+		{[]string{"invoice.paid:true"}, 1},
+		{[]string{"invoice.lines[0].sku:B2"}, 1},
+		{[]string{"invoice.lines[1].sku:B2"}, 1},
+		{[]string{"@invoice.lines:A1"}, 1},
+		{[]string{"invoice.number:INV-001", "invoice.number:INV-002"}, 2},
+		{[]string{"[invoice.number:INV-001", "]invoice.paid:true"}, 1},
+	}
+	for _, test := range tests {
+		data, err := queryDataJSONPath(t, s, test.conditions...)
+		assert.NoError(t, err, test.conditions)
+		assert.Len(t, data, test.matches, test.conditions)
+	}
+
+	ctx := context.Background()
+	fb := database.DataQueryFactory.NewFilter(ctx)
+	data, _, err := s.GetData(ctx, "ns1", fb.And(fb.Eq("jsonpath", "invoice.number:INV-001"), fb.Eq("validator", core.ValidatorTypeJSON)))
+	assert.NoError(t, err)
+	assert.Len(t, data, 1)
+
+	fb = database.DataQueryFactory.NewFilter(ctx)
+	refs, _, err := s.GetDataRefs(ctx, "ns1", fb.In("jsonpath", []driver.Value{"invoice.number:INV-001", "invoice.number:INV-002"}))
+	assert.NoError(t, err)
+	assert.Len(t, refs, 2)
+}
+
+func TestJSONPathMessagesE2EWithDB(t *testing.T) {
+	s, cleanup := newSQLiteTestProvider(t)
+	defer cleanup()
+	msgs := insertJSONPathTestData(t, s)
+	ctx := context.Background()
+
+	fb := database.MessageQueryFactory.NewFilter(ctx)
+	found, res, err := s.GetMessages(ctx, "ns1", fb.And(
+		fb.Eq("jsonpath", "invoice.number:INV-001"),
+		fb.Eq("type", core.MessageTypeBroadcast),
+	).Count(true))
+	assert.NoError(t, err)
+	assert.Len(t, found, 1)
+	assert.Equal(t, msgs[0].Header.ID, found[0].Header.ID)
+	assert.Equal(t, int64(1), *res.TotalCount)
+
+	fb = database.MessageQueryFactory.NewFilter(ctx)
+	ids, err := s.GetMessageIDs(ctx, "ns1", fb.Or(
+		fb.Eq("jsonpath", "invoice.number:INV-001"),
+		fb.Eq("jsonpath", "order.number:INV-001"),
+	))
+	assert.NoError(t, err)
+	assert.Len(t, ids, 2)
+
+	fb = database.MessageQueryFactory.NewFilter(ctx)
+	found, _, err = s.GetMessagesForData(ctx, "ns1", msgs[1].Data[0].ID, fb.And(fb.Eq("jsonpath", "invoice.paid:false")))
+	assert.NoError(t, err)
+	assert.Len(t, found, 1)
+
+	fb = database.MessageQueryFactory.NewFilter(ctx)
+	found, _, err = s.GetMessagesForData(ctx, "ns1", msgs[1].Data[0].ID, fb.And(fb.Eq("jsonpath", "invoice.paid:true")))
+	assert.NoError(t, err)
+	assert.Empty(t, found)
+
+	fb = database.MessageQueryFactory.NewFilter(ctx)
+	found, _, err = s.GetMessages(ctx, "ns1", fb.And(fb.Eq("type", core.MessageTypeBroadcast)))
+	assert.NoError(t, err)
+	assert.Len(t, found, len(msgs))
+}
+
+func TestJSONPathErrors(t *testing.T) {
+	s, cleanup := newSQLiteTestProvider(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	tests := []struct {
+		condition string
+		err       string
+	}{
+		{"invoice.number", "FF10510"},
+		{":INV-001", "FF10510"},
+		{"invoice..number:INV-001", "FF10510"},
+		{"invoice.lines[0][:B2", "FF10510"},
+		{"invoice.'number':INV-001", "FF10510"},
+		{"lines.[0]:B2", "FF10510"},
+		{"invoice.lines[99999999999999999999].sku:B2", "FF10510"},
+		{"a.b.c.d.e.f.g.h.i:INV-001", "FF10511"},
+	}
+	for _, test := range tests {
+		fb := database.DataQueryFactory.NewFilter(ctx)
+		_, _, err := s.GetData(ctx, "ns1", fb.Eq("jsonpath", test.condition))
+		assert.Regexp(t, test.err, err, test.condition)
+	}
+
+	fb := database.DataQueryFactory.NewFilter(ctx)
+	_, _, err := s.GetData(ctx, "ns1", fb.And(
+		fb.Eq("jsonpath", "a:1"), fb.Eq("jsonpath", "b:2"), fb.Eq("jsonpath", "c:3"), fb.Eq("jsonpath", "d:4"),
+	))
+	assert.Regexp(t, "FF10512", err)
+
+	fb = database.DataQueryFactory.NewFilter(ctx)
+	_, _, err = s.GetData(ctx, "ns1", fb.In("jsonpath", []driver.Value{"a:1", "b:2"}))
+	assert.Regexp(t, "FF10514", err)
+
+	fb = database.DataQueryFactory.NewFilter(ctx)
+	_, _, err = s.GetData(ctx, "ns1", fb.In("jsonpath", []driver.Value{"a:1", "b"}))
+	assert.Regexp(t, "FF10510", err)
+
+	fb = database.DataQueryFactory.NewFilter(ctx)
+	_, _, err = s.GetData(ctx, "ns1", fb.Neq("jsonpath", "a"))
+	assert.Regexp(t, "FF10510", err)
+
+	fb = database.DataQueryFactory.NewFilter(ctx)
+	_, _, err = s.GetData(ctx, "ns1", fb.Eq("wrong", "a:1"))
+	assert.Regexp(t, "FF00142", err)
+
+	fb = database.MessageQueryFactory.NewFilter(ctx)
+	_, _, err = s.GetMessages(ctx, "ns1", fb.Or(fb.Eq("jsonpath", "a:1"), fb.Eq("type", "broadcast")))
+	assert.Regexp(t, "FF10513", err)
+
+	fb = database.MessageQueryFactory.NewFilter(ctx)
+	_, err = s.GetMessageIDs(ctx, "ns1", fb.Eq("jsonpath", "a"))
+	assert.Regexp(t, "FF10510", err)
+
+	fb = database.MessageQueryFactory.NewFilter(ctx)
+	_, _, err = s.GetMessagesForData(ctx, "ns1", fftypes.NewUUID(), fb.Eq("wrong", "a"))
+	assert.Regexp(t, "FF00142", err)
+
+	fb = database.MessageQueryFactory.NewFilter(ctx)
+	_, _, err = s.GetMessages(ctx, "ns1", fb.And(fb.Eq("jsonpath", "a:1"), fb.Eq("jsonpath", "b:2"), fb.Eq("jsonpath", "c:3"), fb.Eq("jsonpath", "d:4")))
+	assert.Regexp(t, "FF10512", err)
+}
+
+type testJSONPathProvider struct {
+	*sqliteGoTestProvider
+}
+
+func (tp *testJSONPathProvider) JSONPathContains(column string, path JSONPath, match string) sq.Sqlizer {
+	return sq.Expr(fmt.Sprintf("%s IS NOT NULL", column))
+}
+
+func TestJSONPathIndexConditions(t *testing.T) {
+	s, cleanup := newSQLiteTestProvider(t)
+	defer cleanup()
+	ctx := context.Background()
+	s.jsonPath.provider = &testJSONPathProvider{s}
+
+	fb := database.DataQueryFactory.NewFilter(ctx)
+	f := fb.And(fb.Eq("jsonpath", "invoice.number:INV-001"), fb.Neq("jsonpath", "invoice.number:INV-002"))
+	filter, conditions := s.dataJSONPathFilter(ctx, f, "data.value")
+	_, err := filter.Finalize()
+	assert.NoError(t, err)
+	assert.Len(t, conditions, 1)
+
+	filter, conditions = s.dataJSONPathFilter(ctx, fb.Eq("jsonpath", "invoice.number:INV-001"), "data.value")
+	_, err = filter.Finalize()
+	assert.NoError(t, err)
+	assert.Len(t, conditions, 1)
+
+	filter, _ = s.dataJSONPathFilter(ctx, fb.Eq("jsonpath", "invoice"), "data.value")
+	_, err = filter.Finalize()
+	assert.Regexp(t, "FF10510", err)
+
+	fb = database.MessageQueryFactory.NewFilter(ctx)
+	filter, _ = s.messageJSONPathFilter(ctx, "ns1", fb.And(fb.Eq("jsonpath", "invoice")), "id")
+	_, err = filter.Finalize()
+	assert.Regexp(t, "FF10510", err)
+
+	filter, _ = s.messageJSONPathFilter(ctx, "ns1", &jsonPathFilter{fi: &ffapi.FilterInfo{
+		Op:    "wrong",
+		Field: jsonPathField,
+		Value: jsonPathValue("invoice.number:INV-001"),
+	}}, "id")
+	_, err = filter.Finalize()
+	assert.Regexp(t, "FF00190", err)
+}
+
+func TestJSONPathString(t *testing.T) {
+	p, err := parseJSONPath(context.Background(), "invoice.lines[0][2].sku", 8)
+	assert.NoError(t, err)
+	assert.Equal(t, JSONPath{"invoice", "lines", 0, 2, "sku"}, p)
+	assert.Equal(t, "invoice.lines[0][2].sku", p.String())
+}
+
+func TestJSONPathDefaultProvider(t *testing.T) {
+	mp := newMockProvider()
+	s := &SQLCommon{}
+	s.jsonPath.init(s, dbsql.NewMockProvider(), mp.config)
+	assert.Equal(t, s, s.jsonPath.provider.(*SQLCommon))
+}
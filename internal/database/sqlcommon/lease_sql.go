@@ -0,0 +1,169 @@
+// Copyright © 2024 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqlcommon
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/hyperledger/firefly-common/pkg/dbsql"
+	"github.com/hyperledger/firefly-common/pkg/fftypes"
+	"github.com/hyperledger/firefly-common/pkg/i18n"
+	"github.com/hyperledger/firefly/internal/coremsgs"
+	"github.com/hyperledger/firefly/pkg/core"
+)
+
+var (
+	leaseColumns = []string{
+		"namespace",
+		"name",
+		"holder",
+		"expires",
+	}
+)
+
+const leasesTable = "leases"
+
+// leaseClock builds the SQL for the current time of the database. The expiry of every lease is set and checked
+// against this one clock, so the clocks of the replicas competing for a lease do not need to agree.
+// SQLCommon provides the SQLite expression, and other providers override it.
+type leaseClock interface {
+	// LeaseNow returns an integer expression for the current time of the database, in nanoseconds since the epoch
+	LeaseNow() string
+}
+
+func (s *SQLCommon) initLeaseClock(provider dbsql.Provider) {
+	var ok bool
+	if s.leaseClock, ok = provider.(leaseClock); !ok {
+		s.leaseClock = s
+	}
+}
+
+// LeaseNow converts the Julian day number of the current time, as SQLite has no function for the Unix time in nanoseconds
+func (s *SQLCommon) LeaseNow() string {
+	return "CAST((julianday('now') - 2440587.5) * 86400000000000 AS INTEGER)"
+}
+
+func (s *SQLCommon) AcquireLease(ctx context.Context, namespace, name, holder string, ttl time.Duration) (current *core.Lease, err error) {
+	ctx, tx, autoCommit, err := s.BeginOrUseTx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer s.RollbackTx(ctx, tx, autoCommit)
+
+	// Serialize all the replicas competing for the lease
+	if err = s.AcquireLockTx(ctx, leasesTable+"_"+namespace, tx); err != nil {
+		return nil, err
+	}
+
+	now := s.leaseClock.LeaseNow()
+	expires := sq.Expr(now+" + ?", ttl.Nanoseconds())
+
+	// Renew the lease if we hold it, or take it over if it has expired
+	updated, err := s.UpdateTx(ctx, leasesTable, tx,
+		sq.Update(leasesTable).
+			Set("holder", holder).
+			Set("expires", expires).
+			Where(sq.And{
+				sq.Eq{"namespace": namespace, "name": name},
+				sq.Or{sq.Eq{"holder": holder}, sq.Expr("expires < " + now)},
+			}),
+		nil,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if current, err = s.getLeaseTx(ctx, namespace, name, tx); err != nil {
+		return nil, err
+	}
+	switch {
+	case updated > 0:
+	case current == nil:
+		_, err = s.InsertTx(ctx, leasesTable, tx,
+			sq.Insert(leasesTable).
+				Columns(leaseColumns...).
+				Values(
+					namespace,
+					name,
+					holder,
+					expires,
+				),
+			nil,
+		)
+		if err == nil {
+			current, err = s.getLeaseTx(ctx, namespace, name, tx)
+		}
+		if err != nil {
+			return nil, err
+		}
+	default:
+		// Held by another replica
+		return current, nil
+	}
+
+	return current, s.CommitTx(ctx, tx, autoCommit)
+}
+
+func (s *SQLCommon) getLeaseTx(ctx context.Context, namespace, name string, tx *dbsql.TXWrapper) (lease *core.Lease, err error) {
+	rows, _, err := s.QueryTx(ctx, leasesTable, tx,
+		sq.Select(leaseColumns...).
+			From(leasesTable).
+			Where(sq.Eq{"namespace": namespace, "name": name}),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	if rows.Next() {
+		return s.leaseResult(ctx, rows)
+	}
+	return nil, nil
+}
+
+func (s *SQLCommon) leaseResult(ctx context.Context, row *sql.Rows) (*core.Lease, error) {
+	var lease core.Lease
+	err := row.Scan(
+		&lease.Namespace,
+		&lease.Name,
+		&lease.Holder,
+		&lease.Expires,
+	)
+	if err != nil {
+		return nil, i18n.WrapError(ctx, err, coremsgs.MsgDBReadErr, leasesTable)
+	}
+	return &lease, nil
+}
+
+func (s *SQLCommon) ReleaseLease(ctx context.Context, namespace, name, holder string) (err error) {
+	ctx, tx, autoCommit, err := s.BeginOrUseTx(ctx)
+	if err != nil {
+		return err
+	}
+	defer s.RollbackTx(ctx, tx, autoCommit)
+
+	err = s.DeleteTx(ctx, leasesTable, tx, sq.Delete(leasesTable).Where(sq.Eq{
+		"namespace": namespace, "name": name, "holder": holder,
+	}), nil)
+	if err != nil && err != fftypes.DeleteRecordNotFound {
+		return err
+	}
+
+	return s.CommitTx(ctx, tx, autoCommit)
+}
@@ -0,0 +1,180 @@
+// Copyright © 2024 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqlcommon
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLeasesE2EWithDB(t *testing.T) {
+	s, cleanup := newSQLiteTestProvider(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	// Acquire a new lease, which expires by the clock of the database
+	before := time.Now()
+	current, err := s.AcquireLease(ctx, "ns1", "leader", "replica1", 1*time.Minute)
+	assert.NoError(t, err)
+	assert.Equal(t, "replica1", current.Holder)
+	assert.WithinDuration(t, before.Add(1*time.Minute), *current.Expires.Time(), 5*time.Second)
+	expires := current.Expires
+
+	// Cannot be acquired by another holder before it expires
+	current, err = s.AcquireLease(ctx, "ns1", "leader", "replica2", 1*time.Minute)
+	assert.NoError(t, err)
+	assert.Equal(t, "replica1", current.Holder)
+	assert.Equal(t, expires.UnixNano(), current.Expires.UnixNano())
+
+	// Can be renewed by the holder
+	current, err = s.AcquireLease(ctx, "ns1", "leader", "replica1", -1*time.Second)
+	assert.NoError(t, err)
+	assert.Equal(t, "replica1", current.Holder)
+	assert.True(t, current.Expires.Time().Before(*expires.Time()))
+
+	// Can be acquired by another holder once it expires
+	current, err = s.AcquireLease(ctx, "ns1", "leader", "replica2", 1*time.Minute)
+	assert.NoError(t, err)
+	assert.Equal(t, "replica2", current.Holder)
+
+	// Releasing by a different holder does nothing
+	err = s.ReleaseLease(ctx, "ns1", "leader", "replica1")
+	assert.NoError(t, err)
+	current, err = s.AcquireLease(ctx, "ns1", "leader", "replica1", 1*time.Minute)
+	assert.NoError(t, err)
+	assert.Equal(t, "replica2", current.Holder)
+
+	// Can be acquired straight away once released
+	err = s.ReleaseLease(ctx, "ns1", "leader", "replica2")
+	assert.NoError(t, err)
+	current, err = s.AcquireLease(ctx, "ns1", "leader", "replica1", 1*time.Minute)
+	assert.NoError(t, err)
+	assert.Equal(t, "replica1", current.Holder)
+}
+
+func TestLeaseNowWithDB(t *testing.T) {
+	s, cleanup := newSQLiteTestProvider(t)
+	defer cleanup()
+
+	var now int64
+	err := s.DB().QueryRow("SELECT " + s.leaseClock.LeaseNow()).Scan(&now)
+	assert.NoError(t, err)
+	assert.WithinDuration(t, time.Now(), time.Unix(0, now), 5*time.Second)
+}
+
+func TestAcquireLeaseFailBegin(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin().WillReturnError(fmt.Errorf("pop"))
+	_, err := s.AcquireLease(context.Background(), "ns1", "leader", "replica1", time.Minute)
+	assert.Regexp(t, "FF00175", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAcquireLeaseFailLock(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin()
+	mock.ExpectExec("<acquire lock leases_ns1>").WillReturnError(fmt.Errorf("pop"))
+	mock.ExpectRollback()
+	_, err := s.AcquireLease(context.Background(), "ns1", "leader", "replica1", time.Minute)
+	assert.Regexp(t, "FF00187", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAcquireLeaseFailUpdate(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin()
+	mock.ExpectExec("<acquire lock leases_ns1>").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("UPDATE .*").WillReturnError(fmt.Errorf("pop"))
+	mock.ExpectRollback()
+	_, err := s.AcquireLease(context.Background(), "ns1", "leader", "replica1", time.Minute)
+	assert.Regexp(t, "FF00178", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAcquireLeaseFailSelect(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin()
+	mock.ExpectExec("<acquire lock leases_ns1>").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("UPDATE .*").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery("SELECT .*").WillReturnError(fmt.Errorf("pop"))
+	mock.ExpectRollback()
+	_, err := s.AcquireLease(context.Background(), "ns1", "leader", "replica1", time.Minute)
+	assert.Regexp(t, "FF00176", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAcquireLeaseFailScan(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin()
+	mock.ExpectExec("<acquire lock leases_ns1>").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("UPDATE .*").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery("SELECT .*").WillReturnRows(sqlmock.NewRows([]string{"namespace"}).AddRow("ns1"))
+	mock.ExpectRollback()
+	_, err := s.AcquireLease(context.Background(), "ns1", "leader", "replica1", time.Minute)
+	assert.Regexp(t, "FF10121", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAcquireLeaseFailInsert(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin()
+	mock.ExpectExec("<acquire lock leases_ns1>").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("UPDATE .*").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery("SELECT .*").WillReturnRows(sqlmock.NewRows(leaseColumns))
+	mock.ExpectExec("INSERT .*").WillReturnError(fmt.Errorf("pop"))
+	mock.ExpectRollback()
+	_, err := s.AcquireLease(context.Background(), "ns1", "leader", "replica1", time.Minute)
+	assert.Regexp(t, "FF00177", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAcquireLeaseFailReadInserted(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin()
+	mock.ExpectExec("<acquire lock leases_ns1>").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("UPDATE .*").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery("SELECT .*").WillReturnRows(sqlmock.NewRows(leaseColumns))
+	mock.ExpectExec("INSERT .*").WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectQuery("SELECT .*").WillReturnError(fmt.Errorf("pop"))
+	mock.ExpectRollback()
+	_, err := s.AcquireLease(context.Background(), "ns1", "leader", "replica1", time.Minute)
+	assert.Regexp(t, "FF00176", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestReleaseLeaseFailBegin(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin().WillReturnError(fmt.Errorf("pop"))
+	err := s.ReleaseLease(context.Background(), "ns1", "leader", "replica1")
+	assert.Regexp(t, "FF00175", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestReleaseLeaseFailDelete(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin()
+	mock.ExpectExec("DELETE .*").WillReturnError(fmt.Errorf("pop"))
+	mock.ExpectRollback()
+	err := s.ReleaseLease(context.Background(), "ns1", "leader", "replica1")
+	assert.Regexp(t, "FF00179", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -38,7 +38,7 @@ var (
 		"cid",
 		"mtype",
 		"author",
-		"key",
+		`"key"`,
 		"created",
 		"namespace",
 		"namespace_local",
@@ -57,6 +57,7 @@ var (
 		"tx_parent_id",
 		"batch_id",
 		"idempotency_key",
+		"trace_context",
 	}
 	msgFilterFieldMap = map[string]string{
 		"type":           "mtype",
@@ -66,6 +67,7 @@ var (
 		"txparent.id":    "tx_parent_id",
 		"batch":          "batch_id",
 		"group":          "group_hash",
+		"key":            `"key"`,
 		"idempotencykey": "idempotency_key",
 		"rejectreason":   "reject_reason",
 	}
@@ -87,7 +89,7 @@ func (s *SQLCommon) attemptMessageUpdate(ctx context.Context, tx *dbsql.TXWrappe
 			Set("cid", message.Header.CID).
 			Set("mtype", string(message.Header.Type)).
 			Set("author", message.Header.Author).
-			Set("key", message.Header.Key).
+			Set(`"key"`, message.Header.Key).
 			Set("created", message.Header.Created).
 			Set("topics", message.Header.Topics).
 			Set("tag", message.Header.Tag).
@@ -104,6 +106,7 @@ func (s *SQLCommon) attemptMessageUpdate(ctx context.Context, tx *dbsql.TXWrappe
 			Set("tx_parent_id", txParentID).
 			Set("batch_id", message.BatchID).
 			Set("idempotency_key", message.IdempotencyKey).
+			Set("trace_context", message.TraceContext).
 			Where(sq.Eq{
 				"id":              message.Header.ID,
 				"hash":            message.Hash,
@@ -147,6 +150,7 @@ func (s *SQLCommon) setMessageInsertValues(query sq.InsertBuilder, message *core
 		txParentID,
 		message.BatchID,
 		message.IdempotencyKey,
+		message.TraceContext,
 	)
 }
 
@@ -461,6 +465,7 @@ func (s *SQLCommon) msgResult(ctx context.Context, row *sql.Rows) (*core.Message
 		&txParent.ID,
 		&msg.BatchID,
 		&msg.IdempotencyKey,
+		&msg.TraceContext,
 		// Must be added to the list of columns in all selects
 		&msg.Sequence,
 	)
@@ -535,11 +540,12 @@ func (s *SQLCommon) getMessagesQuery(ctx context.Context, namespace string, quer
 }
 
 func (s *SQLCommon) GetMessageIDs(ctx context.Context, namespace string, filter ffapi.Filter) (ids []*core.IDAndSequence, err error) {
+	filter, conditions := s.messageJSONPathFilter(ctx, namespace, filter, "id")
 	query, _, _, err := s.FilterSelect(ctx, "", sq.Select("id", s.SequenceColumn()).From(messagesTable), filter, msgFilterFieldMap,
 		[]interface{}{
 			&ffapi.SortField{Field: "confirmed", Descending: true, Nulls: ffapi.NullsFirst},
 			"created",
-		}, sq.Eq{"namespace_local": namespace})
+		}, append([]sq.Sqlizer{sq.Eq{"namespace_local": namespace}}, conditions...)...)
 	if err != nil {
 		return nil, err
 	}
@@ -598,11 +604,12 @@ func (s *SQLCommon) queryBatchIDs(ctx context.Context, query sq.SelectBuilder) (
 func (s *SQLCommon) GetMessages(ctx context.Context, namespace string, filter ffapi.Filter) (message []*core.Message, fr *ffapi.FilterResult, err error) {
 	cols := append([]string{}, msgColumns...)
 	cols = append(cols, s.SequenceColumn())
+	filter, conditions := s.messageJSONPathFilter(ctx, namespace, filter, "id")
 	query, fop, fi, err := s.FilterSelect(ctx, "", sq.Select(cols...).From(messagesTable), filter, msgFilterFieldMap,
 		[]interface{}{
 			&ffapi.SortField{Field: "confirmed", Descending: true, Nulls: ffapi.NullsFirst},
 			&ffapi.SortField{Field: "created", Descending: true},
-		}, sq.Eq{"namespace_local": namespace})
+		}, append([]sq.Sqlizer{sq.Eq{"namespace_local": namespace}}, conditions...)...)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -615,10 +622,11 @@ func (s *SQLCommon) GetMessagesForData(ctx context.Context, namespace string, da
 		cols[i] = fmt.Sprintf("m.%s", col)
 	}
 	cols[len(msgColumns)] = "m.seq"
+	filter, conditions := s.messageJSONPathFilter(ctx, namespace, filter, "m.id")
 	query, fop, fi, err := s.FilterSelect(
 		ctx, "m", sq.Select(cols...).From("messages_data AS md"),
 		filter, msgFilterFieldMap, []interface{}{"sequence"},
-		sq.Eq{"md.data_id": dataID, "md.namespace": namespace})
+		append([]sq.Sqlizer{sq.Eq{"md.data_id": dataID, "md.namespace": namespace}}, conditions...)...)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -45,6 +45,7 @@ var (
 		"input",
 		"output",
 		"retry_id",
+		"trace_context",
 	}
 	opFilterFieldMap = map[string]string{
 		"tx":     "tx_id",
@@ -67,9 +68,10 @@ func (s *SQLCommon) setOperationInsertValues(query sq.InsertBuilder, operation *
 		operation.Created,
 		operation.Updated,
 		operation.Error,
-		operation.Input,
-		operation.Output,
+		s.encrypted(operation.Input),
+		s.encrypted(operation.Output),
 		operation.Retry,
+		operation.TraceContext,
 	)
 }
 
@@ -141,7 +143,7 @@ func (s *SQLCommon) InsertOperations(ctx context.Context, operations []*core.Ope
 
 }
 
-func (s *SQLCommon) opResult(ctx context.Context, row *sql.Rows) (*core.Operation, error) {
+func (s *SQLCommon) opResult(ctx context.Context, row *sql.Rows, d *decryptions) (*core.Operation, error) {
 	var op core.Operation
 	err := row.Scan(
 		&op.ID,
@@ -153,9 +155,10 @@ func (s *SQLCommon) opResult(ctx context.Context, row *sql.Rows) (*core.Operatio
 		&op.Created,
 		&op.Updated,
 		&op.Error,
-		&op.Input,
-		&op.Output,
+		d.jsonObject(&op.Input),
+		d.jsonObject(&op.Output),
 		&op.Retry,
+		&op.TraceContext,
 	)
 	if err != nil {
 		return nil, i18n.WrapError(ctx, err, coremsgs.MsgDBReadErr, operationsTable)
@@ -180,12 +183,14 @@ func (s *SQLCommon) GetOperationByID(ctx context.Context, namespace string, id *
 		return nil, nil
 	}
 
-	op, err := s.opResult(ctx, rows)
+	d := s.newDecryptions(ctx)
+	op, err := s.opResult(ctx, rows, d)
 	if err != nil {
 		return nil, err
 	}
+	rows.Close()
 
-	return op, nil
+	return op, d.complete()
 }
 
 func (s *SQLCommon) GetOperations(ctx context.Context, namespace string, filter ffapi.Filter) (operation []*core.Operation, fr *ffapi.FilterResult, err error) {
@@ -202,13 +207,18 @@ func (s *SQLCommon) GetOperations(ctx context.Context, namespace string, filter
 	defer rows.Close()
 
 	ops := []*core.Operation{}
+	d := s.newDecryptions(ctx)
 	for rows.Next() {
-		op, err := s.opResult(ctx, rows)
+		op, err := s.opResult(ctx, rows, d)
 		if err != nil {
 			return nil, nil, err
 		}
 		ops = append(ops, op)
 	}
+	rows.Close()
+	if err := d.complete(); err != nil {
+		return nil, nil, err
+	}
 
 	return ops, s.QueryRes(ctx, operationsTable, tx, fop, nil, fi), err
 }
@@ -221,7 +231,7 @@ func (s *SQLCommon) UpdateOperation(ctx context.Context, ns string, id *fftypes.
 	}
 	defer s.RollbackTx(ctx, tx, autoCommit)
 
-	query, err := s.BuildUpdate(sq.Update(operationsTable), update, opFilterFieldMap)
+	query, err := s.BuildUpdate(sq.Update(operationsTable), s.encryptedFields(update, "input", "output"), opFilterFieldMap)
 	if err != nil {
 		return false, err
 	}
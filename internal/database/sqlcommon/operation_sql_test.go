@@ -47,8 +47,11 @@ func TestOperationE2EWithDB(t *testing.T) {
 		Error:       "pop",
 		Input:       fftypes.JSONObject{"some": "input-info"},
 		Output:      fftypes.JSONObject{"some": "output-info"},
-		Created:     fftypes.Now(),
-		Updated:     fftypes.Now(),
+		TraceContext: fftypes.JSONObject{
+			"traceparent": "00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01",
+		},
+		Created: fftypes.Now(),
+		Updated: fftypes.Now(),
 	}
 	s.callbacks.On("UUIDCollectionNSEvent", database.CollectionOperations, core.ChangeEventTypeCreated, "ns1", operationID).Return()
 	s.callbacks.On("UUIDCollectionNSEvent", database.CollectionOperations, core.ChangeEventTypeUpdated, "ns1", operationID).Return()
@@ -41,6 +41,7 @@ var (
 		"signer",
 		"dispatched",
 		"created",
+		"invalidated",
 	}
 	pinFilterFieldMap = map[string]string{
 		"batch":     "batch_id",
@@ -60,7 +61,7 @@ func (s *SQLCommon) UpsertPin(ctx context.Context, pin *core.Pin) (err error) {
 
 	// Do a select within the transaction to detemine if the UUID already exists
 	pinRows, tx, err := s.QueryTx(ctx, pinsTable, tx,
-		sq.Select(s.SequenceColumn(), "masked", "dispatched").
+		sq.Select(s.SequenceColumn(), "masked", "dispatched", "invalidated").
 			From(pinsTable).
 			Where(sq.Eq{
 				"hash":      pin.Hash,
@@ -74,13 +75,24 @@ func (s *SQLCommon) UpsertPin(ctx context.Context, pin *core.Pin) (err error) {
 	existing := pinRows.Next()
 
 	if existing {
-		err := pinRows.Scan(&pin.Sequence, &pin.Masked, &pin.Dispatched)
+		err := pinRows.Scan(&pin.Sequence, &pin.Masked, &pin.Dispatched, &pin.Invalidated)
 		pinRows.Close()
 		if err != nil {
 			return i18n.WrapError(ctx, err, coremsgs.MsgDBReadErr, pinsTable)
 		}
 		// Pin's can only go from undispatched, to dispatched - so no update here.
+		// The exception is a pin invalidated by a chain reorganization, which is valid again when the batch is pinned again.
 		log.L(ctx).Debugf("Existing pin returned at sequence %d", pin.Sequence)
+		if pin.Invalidated {
+			if _, err = s.UpdateTx(ctx, pinsTable, tx,
+				sq.Update(pinsTable).
+					Set("invalidated", false).
+					Where(sq.Eq{s.SequenceColumn(): pin.Sequence}),
+				nil); err != nil {
+				return err
+			}
+			pin.Invalidated = false
+		}
 	} else {
 		pinRows.Close()
 		if err = s.attemptPinInsert(ctx, tx, pin); err != nil {
@@ -114,6 +126,7 @@ func (s *SQLCommon) setPinInsertValues(query sq.InsertBuilder, pin *core.Pin) sq
 		pin.Signer,
 		pin.Dispatched,
 		pin.Created,
+		pin.Invalidated,
 	)
 }
 
@@ -163,6 +176,7 @@ func (s *SQLCommon) pinResult(ctx context.Context, row *sql.Rows) (*core.Pin, er
 		&pin.Signer,
 		&pin.Dispatched,
 		&pin.Created,
+		&pin.Invalidated,
 		&pin.Sequence,
 	)
 	if err != nil {
@@ -83,6 +83,19 @@ func TestPinsE2EWithDB(t *testing.T) {
 	assert.Equal(t, existingSequence, pin.Sequence)
 	assert.True(t, pin.Dispatched)
 
+	// Invalidate it, and check a double insert makes it valid again
+	err = s.UpdatePins(ctx, "ns", database.PinQueryFactory.NewFilter(ctx).Eq("batch", pin.Batch), database.PinQueryFactory.NewUpdate(ctx).Set("invalidated", true))
+	assert.NoError(t, err)
+	pinRes, _, err = s.GetPins(ctx, "ns", filter)
+	assert.NoError(t, err)
+	assert.True(t, pinRes[0].Invalidated)
+	err = s.UpsertPin(ctx, pin)
+	assert.NoError(t, err)
+	assert.False(t, pin.Invalidated)
+	pinRes, _, err = s.GetPins(ctx, "ns", filter)
+	assert.NoError(t, err)
+	assert.False(t, pinRes[0].Invalidated)
+
 	s.callbacks.AssertExpectations(t)
 }
 
@@ -125,6 +138,17 @@ func TestUpsertPinFailExistingSequenceScan(t *testing.T) {
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
 
+func TestUpsertPinFailRevalidate(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT .*").WillReturnRows(sqlmock.NewRows([]string{"sequence", "masked", "dispatched", "invalidated"}).AddRow(1, false, false, true))
+	mock.ExpectExec("UPDATE .*").WillReturnError(fmt.Errorf("pop"))
+	mock.ExpectRollback()
+	err := s.UpsertPin(context.Background(), &core.Pin{Hash: fftypes.NewRandB32()})
+	assert.Regexp(t, "FF00178", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
 func TestUpsertPinFailCommit(t *testing.T) {
 	s, mock := newMockProvider().init()
 	mock.ExpectBegin()
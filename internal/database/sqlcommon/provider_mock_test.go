@@ -41,6 +41,9 @@ type mockProvider struct {
 	mockDB *sql.DB
 	mdb    sqlmock.Sqlmock
 
+	replicaDB        *sql.DB
+	replicaOpenError error
+
 	fakePSQLInsert          bool
 	openError               error
 	getMigrationDriverError error
@@ -101,6 +104,9 @@ func (mp *mockProvider) ApplyInsertQueryCustomizations(insert sq.InsertBuilder,
 }
 
 func (mp *mockProvider) Open(url string) (*sql.DB, error) {
+	if url == "replica" {
+		return mp.replicaDB, mp.replicaOpenError
+	}
 	return mp.mockDB, mp.openError
 }
 
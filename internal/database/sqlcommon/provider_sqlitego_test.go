@@ -19,6 +19,7 @@ package sqlcommon
 import (
 	"context"
 	"database/sql"
+	"fmt"
 	"io/ioutil"
 	"os"
 	"testing"
@@ -46,7 +47,13 @@ type sqliteGoTestProvider struct {
 	capabilities *database.Capabilities
 }
 
-// newSQLiteTestProvider creates a real in-memory database provider for e2e testing
+// TestDatabaseProviders are the database plugins that the e2e tests can run against in place of in-memory SQLite,
+// registered by provider_external_test.go to avoid an import cycle. Set FF_TEST_DATABASE_TYPE to the name of
+// the plugin, and FF_TEST_DATABASE_URL to the URL of an empty database that the tests can clear.
+var TestDatabaseProviders = map[string]func() dbsql.Provider{}
+
+// newSQLiteTestProvider creates a real in-memory database provider for e2e testing, or connects to the
+// database named by FF_TEST_DATABASE_TYPE
 func newSQLiteTestProvider(t *testing.T) (*sqliteGoTestProvider, func()) {
 	conf := config.RootSection("unittest.db")
 	conf.AddKnownKey("url", "test")
@@ -64,8 +71,23 @@ func newSQLiteTestProvider(t *testing.T) (*sqliteGoTestProvider, func()) {
 	tp.config.Set(SQLConfMigrationsDirectory, "../../../db/migrations/sqlite")
 	tp.config.Set(SQLConfMaxConnections, 1)
 
-	err = tp.Init(context.Background(), tp, tp.config, tp.capabilities)
+	var provider dbsql.Provider = tp
+	dbType := os.Getenv("FF_TEST_DATABASE_TYPE")
+	if dbType != "" {
+		newProvider, ok := TestDatabaseProviders[dbType]
+		if !ok {
+			t.Fatalf("Unknown FF_TEST_DATABASE_TYPE '%s'", dbType)
+		}
+		provider = newProvider()
+		tp.config.Set(SQLConfDatasourceURL, os.Getenv("FF_TEST_DATABASE_URL"))
+		tp.config.Set(SQLConfMigrationsDirectory, "../../../db/migrations/"+provider.MigrationsDir())
+	}
+
+	err = tp.Init(context.Background(), provider, tp.config, tp.capabilities)
 	assert.NoError(tp.t, err)
+	if err == nil && dbType != "" {
+		tp.truncateTables()
+	}
 	tp.SetHandler(database.GlobalHandler, tp.callbacks)
 
 	return tp, func() {
@@ -74,6 +96,25 @@ func newSQLiteTestProvider(t *testing.T) (*sqliteGoTestProvider, func()) {
 	}
 }
 
+// truncateTables clears the tables left by the previous test, as the external database is shared by every test
+func (tp *sqliteGoTestProvider) truncateTables() {
+	rows, err := tp.DB().Query(`SELECT table_name FROM information_schema.tables WHERE table_schema = DATABASE() AND table_name <> 'schema_migrations'`)
+	if !assert.NoError(tp.t, err) {
+		return
+	}
+	var tables []string
+	for rows.Next() {
+		var table string
+		assert.NoError(tp.t, rows.Scan(&table))
+		tables = append(tables, table)
+	}
+	rows.Close()
+	for _, table := range tables {
+		_, err := tp.DB().Exec(fmt.Sprintf(`TRUNCATE TABLE "%s"`, table))
+		assert.NoError(tp.t, err)
+	}
+}
+
 func (tp *sqliteGoTestProvider) Name() string {
 	return "sqlite3"
 }
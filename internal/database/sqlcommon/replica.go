@@ -0,0 +1,143 @@
+// Copyright © 2024 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqlcommon
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/hyperledger/firefly-common/pkg/config"
+	"github.com/hyperledger/firefly-common/pkg/dbsql"
+	"github.com/hyperledger/firefly-common/pkg/ffapi"
+	"github.com/hyperledger/firefly-common/pkg/i18n"
+	"github.com/hyperledger/firefly-common/pkg/log"
+	"github.com/hyperledger/firefly/pkg/database"
+)
+
+func (s *SQLCommon) initReadReplica(ctx context.Context, provider dbsql.Provider, config config.Section) (err error) {
+	url := config.GetString(SQLConfDatasourceURL)
+	if url == "" {
+		return nil
+	}
+	if s.replica, err = provider.Open(url); err != nil {
+		return i18n.WrapError(ctx, err, i18n.MsgDBInitFailed)
+	}
+	if connLimit := config.GetInt(SQLConfMaxConnections); connLimit > 0 {
+		s.replica.SetMaxOpenConns(connLimit)
+		s.replica.SetConnMaxIdleTime(config.GetDuration(SQLConfMaxConnIdleTime))
+		maxIdleConns := config.GetInt(SQLConfMaxIdleConns)
+		if maxIdleConns <= 0 {
+			maxIdleConns = connLimit
+		}
+		s.replica.SetMaxIdleConns(maxIdleConns)
+		s.replica.SetConnMaxLifetime(config.GetDuration(SQLConfMaxConnLifetime))
+	}
+	log.L(ctx).Infof("Reads that tolerate stale results will use the %s read replica", provider.Name())
+	return nil
+}
+
+// useReadReplica is true for reads outside of a transaction, where the caller has said a stale result is ok
+func (s *SQLCommon) useReadReplica(ctx context.Context) bool {
+	return s.replica != nil && database.ReplicaReadsAllowed(ctx) && dbsql.GetTXFromContext(ctx) == nil
+}
+
+func (s *SQLCommon) Query(ctx context.Context, table string, q sq.SelectBuilder) (*sql.Rows, *dbsql.TXWrapper, error) {
+	return s.QueryTx(ctx, table, nil, q)
+}
+
+func (s *SQLCommon) QueryTx(ctx context.Context, table string, tx *dbsql.TXWrapper, q sq.SelectBuilder) (*sql.Rows, *dbsql.TXWrapper, error) {
+	if tx != nil || !s.useReadReplica(ctx) {
+		return s.Database.QueryTx(ctx, table, tx, q)
+	}
+
+	l := log.L(ctx)
+	sqlQuery, args, err := q.PlaceholderFormat(s.Features().PlaceholderFormat).ToSql()
+	if err != nil {
+		return nil, nil, i18n.WrapError(ctx, err, i18n.MsgDBQueryBuildFailed)
+	}
+	before := time.Now()
+	l.Tracef(`SQL-> replica query: %s (args: %+v)`, sqlQuery, args)
+	rows, err := s.replica.QueryContext(ctx, sqlQuery, args...)
+	if err != nil {
+		l.Errorf(`SQL replica query failed: %s sql=[ %s ]`, err, sqlQuery)
+		return nil, nil, i18n.WrapError(ctx, err, i18n.MsgDBQueryFailed)
+	}
+	l.Debugf(`SQL<- replica query %s (%.2fms)`, table, floatMillisSince(before))
+	return rows, nil, nil
+}
+
+func (s *SQLCommon) QueryRes(ctx context.Context, table string, tx *dbsql.TXWrapper, fop sq.Sqlizer, qm dbsql.QueryModifier, fi *ffapi.FilterInfo) *ffapi.FilterResult {
+	if tx != nil || !fi.Count || !s.useReadReplica(ctx) {
+		return s.Database.QueryRes(ctx, table, tx, fop, qm, fi)
+	}
+
+	fr := &ffapi.FilterResult{}
+	count, err := s.replicaCount(ctx, table, fop, qm, fi.CountExpr)
+	if err != nil {
+		// Log, but continue
+		log.L(ctx).Warnf("Unable to return count for query: %s", err)
+	}
+	fr.TotalCount = &count
+	return fr
+}
+
+func (s *SQLCommon) replicaCount(ctx context.Context, table string, fop sq.Sqlizer, qm dbsql.QueryModifier, countExpr string) (count int64, err error) {
+	count = -1
+	l := log.L(ctx)
+	if countExpr == "" {
+		countExpr = "*"
+	}
+	q := sq.Select(fmt.Sprintf("COUNT(%s)", countExpr)).From(table).Where(fop)
+	if qm != nil {
+		if q, err = qm(q); err != nil {
+			return count, err
+		}
+	}
+	sqlQuery, args, err := q.PlaceholderFormat(s.Features().PlaceholderFormat).ToSql()
+	if err != nil {
+		return count, i18n.WrapError(ctx, err, i18n.MsgDBQueryBuildFailed)
+	}
+	before := time.Now()
+	l.Tracef(`SQL-> replica count query: %s (args: %+v)`, sqlQuery, args)
+	rows, err := s.replica.QueryContext(ctx, sqlQuery, args...)
+	if err != nil {
+		l.Errorf(`SQL replica count query failed: %s sql=[ %s ]`, err, sqlQuery)
+		return count, i18n.WrapError(ctx, err, i18n.MsgDBQueryFailed)
+	}
+	defer rows.Close()
+	if rows.Next() {
+		if err = rows.Scan(&count); err != nil {
+			return count, i18n.WrapError(ctx, err, i18n.MsgDBReadErr, table)
+		}
+	}
+	l.Debugf(`SQL<- replica count query %s: %d (%.2fms)`, table, count, floatMillisSince(before))
+	return count, nil
+}
+
+func (s *SQLCommon) Close() {
+	if s.replica != nil {
+		_ = s.replica.Close()
+	}
+	s.Database.Close()
+}
+
+func floatMillisSince(t time.Time) float64 {
+	return float64(time.Since(t)) / float64(time.Millisecond)
+}
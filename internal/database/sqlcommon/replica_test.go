@@ -0,0 +1,143 @@
+// Copyright © 2024 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqlcommon
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	sq "github.com/Masterminds/squirrel"
+	"github.com/hyperledger/firefly-common/pkg/ffapi"
+	"github.com/hyperledger/firefly/pkg/database"
+	"github.com/stretchr/testify/assert"
+)
+
+type errSqlizer struct{}
+
+func (errSqlizer) ToSql() (string, []interface{}, error) {
+	return "", nil, fmt.Errorf("pop")
+}
+
+func newMockReplicaProvider() (*mockProvider, sqlmock.Sqlmock, sqlmock.Sqlmock) {
+	mp := newMockProvider()
+	var rmdb sqlmock.Sqlmock
+	mp.replicaDB, rmdb, _ = sqlmock.New()
+	replicaConfig := mp.config.SubSection(SQLConfReadReplica)
+	replicaConfig.Set(SQLConfDatasourceURL, "replica")
+	replicaConfig.Set(SQLConfMaxConnections, 5)
+	s, mdb := mp.init()
+	return s, mdb, rmdb
+}
+
+func TestReplicaReads(t *testing.T) {
+	s, mdb, rmdb := newMockReplicaProvider()
+	defer s.Close()
+	ctx := database.WithReplicaReads(context.Background())
+
+	rmdb.ExpectQuery("SELECT .*").WillReturnRows(sqlmock.NewRows(datatypeColumns))
+	rmdb.ExpectQuery("SELECT COUNT.*").WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(10))
+
+	fb := database.DatatypeQueryFactory.NewFilter(ctx)
+	_, res, err := s.GetDatatypes(ctx, "ns1", fb.And().Count(true))
+	assert.NoError(t, err)
+	assert.Equal(t, int64(10), *res.TotalCount)
+
+	assert.NoError(t, mdb.ExpectationsWereMet())
+	assert.NoError(t, rmdb.ExpectationsWereMet())
+}
+
+func TestReplicaReadsPrimary(t *testing.T) {
+	s, mdb, rmdb := newMockReplicaProvider()
+	defer s.Close()
+
+	mdb.ExpectQuery("SELECT .*").WillReturnRows(sqlmock.NewRows(datatypeColumns))
+	mdb.ExpectBegin()
+	mdb.ExpectQuery("SELECT .*").WillReturnRows(sqlmock.NewRows(datatypeColumns))
+	mdb.ExpectQuery("SELECT COUNT.*").WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(10))
+	mdb.ExpectCommit()
+
+	// Not marked as tolerating stale reads
+	fb := database.DatatypeQueryFactory.NewFilter(context.Background())
+	_, _, err := s.GetDatatypes(context.Background(), "ns1", fb.And())
+	assert.NoError(t, err)
+
+	// In a transaction
+	err = s.RunAsGroup(database.WithReplicaReads(context.Background()), func(ctx context.Context) error {
+		_, _, err := s.GetDatatypes(ctx, "ns1", fb.And().Count(true))
+		return err
+	})
+	assert.NoError(t, err)
+
+	assert.NoError(t, mdb.ExpectationsWereMet())
+	assert.NoError(t, rmdb.ExpectationsWereMet())
+}
+
+func TestReplicaQueryFail(t *testing.T) {
+	s, _, rmdb := newMockReplicaProvider()
+	defer s.Close()
+	ctx := database.WithReplicaReads(context.Background())
+
+	rmdb.ExpectQuery("SELECT .*").WillReturnError(fmt.Errorf("pop"))
+	fb := database.DatatypeQueryFactory.NewFilter(ctx)
+	_, _, err := s.GetDatatypes(ctx, "ns1", fb.And())
+	assert.Regexp(t, "FF00176", err)
+	assert.NoError(t, rmdb.ExpectationsWereMet())
+}
+
+func TestReplicaQueryBuildFail(t *testing.T) {
+	s, _, _ := newMockReplicaProvider()
+	defer s.Close()
+	ctx := database.WithReplicaReads(context.Background())
+
+	_, _, err := s.Query(ctx, "table1", sq.Select().From("table1"))
+	assert.Regexp(t, "FF00174", err)
+}
+
+func TestReplicaCountFail(t *testing.T) {
+	s, _, rmdb := newMockReplicaProvider()
+	defer s.Close()
+	ctx := database.WithReplicaReads(context.Background())
+	fi := &ffapi.FilterInfo{Count: true, CountExpr: "id"}
+
+	rmdb.ExpectQuery("SELECT COUNT\\(id\\).*").WillReturnError(fmt.Errorf("pop"))
+	res := s.QueryRes(ctx, "table1", nil, sq.Eq{"a": 1}, nil, fi)
+	assert.Equal(t, int64(-1), *res.TotalCount)
+
+	rmdb.ExpectQuery("SELECT COUNT.*").WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow("not a number"))
+	res = s.QueryRes(ctx, "table1", nil, sq.Eq{"a": 1}, nil, fi)
+	assert.Equal(t, int64(-1), *res.TotalCount)
+
+	res = s.QueryRes(ctx, "table1", nil, errSqlizer{}, nil, fi)
+	assert.Equal(t, int64(-1), *res.TotalCount)
+
+	res = s.QueryRes(ctx, "table1", nil, sq.Eq{"a": 1}, func(q sq.SelectBuilder) (sq.SelectBuilder, error) {
+		return q, fmt.Errorf("pop")
+	}, fi)
+	assert.Equal(t, int64(-1), *res.TotalCount)
+
+	assert.NoError(t, rmdb.ExpectationsWereMet())
+}
+
+func TestReplicaOpenFail(t *testing.T) {
+	mp := newMockProvider()
+	mp.replicaOpenError = fmt.Errorf("pop")
+	mp.config.SubSection(SQLConfReadReplica).Set(SQLConfDatasourceURL, "replica")
+	err := mp.Init(context.Background(), mp, mp.config, mp.capabilities)
+	assert.Regexp(t, "FF00173.*pop", err)
+}
@@ -0,0 +1,116 @@
+// Copyright © 2024 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqlcommon
+
+import (
+	"context"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/hyperledger/firefly-common/pkg/ffapi"
+	"github.com/hyperledger/firefly-common/pkg/fftypes"
+	"github.com/hyperledger/firefly-common/pkg/i18n"
+	"github.com/hyperledger/firefly/internal/coremsgs"
+	"github.com/hyperledger/firefly/pkg/core"
+	"github.com/hyperledger/firefly/pkg/database"
+)
+
+type purgeTable struct {
+	table           string
+	idColumn        string
+	namespaceColumn string
+}
+
+var purgeTables = map[database.CollectionName]purgeTable{
+	database.CollectionName(database.CollectionMessages):         {messagesTable, "id", "namespace_local"},
+	database.CollectionName(database.CollectionData):             {dataTable, "id", "namespace"},
+	database.CollectionName(database.CollectionOperations):       {operationsTable, "id", "namespace"},
+	database.CollectionName(database.CollectionEvents):           {eventsTable, "id", "namespace"},
+	database.CollectionName(database.CollectionBlockchainEvents): {blockchaineventsTable, "id", "namespace"},
+	database.CollectionName(database.CollectionTokenTransfers):   {tokentransferTable, "local_id", "namespace"},
+}
+
+func (s *SQLCommon) PurgeRecords(ctx context.Context, namespace string, collection database.CollectionName, ids []*fftypes.UUID) (err error) {
+	pt, ok := purgeTables[collection]
+	if !ok {
+		return i18n.NewError(ctx, coremsgs.MsgUnsupportedCollection, collection)
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+
+	ctx, tx, autoCommit, err := s.BeginOrUseTx(ctx)
+	if err != nil {
+		return err
+	}
+	defer s.RollbackTx(ctx, tx, autoCommit)
+
+	if pt.table == messagesTable {
+		// The references from the messages to their data go with them
+		err = s.DeleteTx(ctx, messagesDataJoinTable, tx, sq.Delete(messagesDataJoinTable).Where(sq.Eq{
+			"message_id": ids,
+			"namespace":  namespace,
+		}), nil)
+		if err != nil && err != fftypes.DeleteRecordNotFound {
+			return err
+		}
+	}
+
+	if pt.table == dataTable {
+		// Any blob records left behind by the caller go with the data
+		err = s.DeleteTx(ctx, blobsTable, tx, sq.Delete(blobsTable).Where(sq.Eq{
+			"data_id":   ids,
+			"namespace": namespace,
+		}), nil)
+		if err != nil && err != fftypes.DeleteRecordNotFound {
+			return err
+		}
+	}
+
+	err = s.DeleteTx(ctx, pt.table, tx, sq.Delete(pt.table).Where(sq.Eq{
+		pt.idColumn:        ids,
+		pt.namespaceColumn: namespace,
+	}), nil)
+	if err != nil && err != fftypes.DeleteRecordNotFound {
+		return err
+	}
+
+	return s.CommitTx(ctx, tx, autoCommit)
+}
+
+// GetPurgeableData excludes the data that is still referenced by a message that is being kept. A message is kept
+// unless it is confirmed or rejected, and was created before messagesBefore.
+func (s *SQLCommon) GetPurgeableData(ctx context.Context, namespace string, filter ffapi.Filter, messagesBefore *fftypes.FFTime) (data core.DataArray, res *ffapi.FilterResult, err error) {
+	kept := sq.And{
+		sq.Expr("md.data_id = data.id"),
+		sq.Eq{"md.namespace": namespace},
+	}
+	if messagesBefore != nil {
+		kept = append(kept, sq.Or{
+			sq.NotEq{"m.state": []string{string(core.MessageStateConfirmed), string(core.MessageStateRejected)}},
+			sq.GtOrEq{"m.created": messagesBefore},
+		})
+	}
+	keptQuery, args, err := sq.Select("1").
+		From(messagesDataJoinTable + " AS md").
+		Join(messagesTable + " AS m ON m.id = md.message_id AND m.namespace_local = md.namespace").
+		Where(kept).
+		ToSql()
+	if err != nil {
+		return nil, nil, i18n.WrapError(ctx, err, i18n.MsgDBQueryBuildFailed)
+	}
+	return s.getData(ctx, namespace, filter, sq.Expr("NOT EXISTS ("+keptQuery+")", args...))
+}
@@ -0,0 +1,215 @@
+// Copyright © 2024 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqlcommon
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/hyperledger/firefly-common/pkg/fftypes"
+	"github.com/hyperledger/firefly/pkg/core"
+	"github.com/hyperledger/firefly/pkg/database"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestPurgeRecordsE2EWithDB(t *testing.T) {
+	s, cleanup := newSQLiteTestProvider(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	s.callbacks.On("OrderedUUIDCollectionNSEvent", database.CollectionMessages, core.ChangeEventTypeCreated, "ns1", mock.Anything, mock.Anything).Return()
+	s.callbacks.On("UUIDCollectionNSEvent", database.CollectionOperations, core.ChangeEventTypeCreated, "ns1", mock.Anything).Return()
+
+	dataID := fftypes.NewUUID()
+	msg1 := &core.Message{Header: core.MessageHeader{ID: fftypes.NewUUID(), Namespace: "ns1", Created: fftypes.Now(), DataHash: fftypes.NewRandB32()}, Hash: fftypes.NewRandB32(), LocalNamespace: "ns1", Data: core.DataRefs{{ID: dataID, Hash: fftypes.NewRandB32()}}}
+	msg2 := &core.Message{Header: core.MessageHeader{ID: fftypes.NewUUID(), Namespace: "ns1", Created: fftypes.Now(), DataHash: fftypes.NewRandB32()}, Hash: fftypes.NewRandB32(), LocalNamespace: "ns1"}
+	err := s.InsertMessages(ctx, []*core.Message{msg1, msg2})
+	assert.NoError(t, err)
+
+	op := &core.Operation{ID: fftypes.NewUUID(), Namespace: "ns1", Transaction: fftypes.NewUUID(), Created: fftypes.Now(), Updated: fftypes.Now(), Plugin: "ethereum", Type: core.OpTypeBlockchainPinBatch, Status: core.OpStatusSucceeded}
+	err = s.InsertOperation(ctx, op)
+	assert.NoError(t, err)
+
+	err = s.PurgeRecords(ctx, "ns1", database.CollectionName(database.CollectionMessages), []*fftypes.UUID{msg1.Header.ID})
+	assert.NoError(t, err)
+	err = s.PurgeRecords(ctx, "ns1", database.CollectionName(database.CollectionOperations), []*fftypes.UUID{op.ID})
+	assert.NoError(t, err)
+
+	// Nothing left to delete is not an error
+	err = s.PurgeRecords(ctx, "ns1", database.CollectionName(database.CollectionMessages), []*fftypes.UUID{msg1.Header.ID})
+	assert.NoError(t, err)
+
+	msgRead, err := s.GetMessageByID(ctx, "ns1", msg1.Header.ID)
+	assert.NoError(t, err)
+	assert.Nil(t, msgRead)
+	msgRead, err = s.GetMessageByID(ctx, "ns1", msg2.Header.ID)
+	assert.NoError(t, err)
+	assert.NotNil(t, msgRead)
+	msgs, _, err := s.GetMessagesForData(ctx, "ns1", dataID, database.MessageQueryFactory.NewFilter(ctx).And())
+	assert.NoError(t, err)
+	assert.Empty(t, msgs)
+	opRead, err := s.GetOperationByID(ctx, "ns1", op.ID)
+	assert.NoError(t, err)
+	assert.Nil(t, opRead)
+}
+
+func TestPurgeRecordsDataWithBlobE2EWithDB(t *testing.T) {
+	s, cleanup := newSQLiteTestProvider(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	s.callbacks.On("UUIDCollectionNSEvent", database.CollectionData, core.ChangeEventTypeCreated, "ns1", mock.Anything).Return()
+
+	data := &core.Data{ID: fftypes.NewUUID(), Namespace: "ns1", Created: fftypes.Now(), Hash: fftypes.NewRandB32(), Blob: &core.BlobRef{Hash: fftypes.NewRandB32()}}
+	err := s.InsertDataArray(ctx, core.DataArray{data})
+	assert.NoError(t, err)
+	err = s.InsertBlob(ctx, &core.Blob{Namespace: "ns1", Hash: data.Blob.Hash, PayloadRef: "ref1", Created: fftypes.Now(), DataID: data.ID})
+	assert.NoError(t, err)
+
+	err = s.PurgeRecords(ctx, "ns1", database.CollectionName(database.CollectionData), []*fftypes.UUID{data.ID})
+	assert.NoError(t, err)
+
+	dataRead, err := s.GetDataByID(ctx, "ns1", data.ID, false)
+	assert.NoError(t, err)
+	assert.Nil(t, dataRead)
+	fb := database.BlobQueryFactory.NewFilter(ctx)
+	blobs, _, err := s.GetBlobs(ctx, "ns1", fb.Eq("data_id", data.ID))
+	assert.NoError(t, err)
+	assert.Empty(t, blobs)
+}
+
+func TestPurgeRecordsUnsupportedCollection(t *testing.T) {
+	s, _ := newMockProvider().init()
+	err := s.PurgeRecords(context.Background(), "ns1", database.CollectionName(database.CollectionBatches), []*fftypes.UUID{fftypes.NewUUID()})
+	assert.Regexp(t, "FF10301", err)
+}
+
+func TestPurgeRecordsNoIDs(t *testing.T) {
+	s, mock := newMockProvider().init()
+	err := s.PurgeRecords(context.Background(), "ns1", database.CollectionName(database.CollectionEvents), []*fftypes.UUID{})
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPurgeRecordsFailBegin(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin().WillReturnError(fmt.Errorf("pop"))
+	err := s.PurgeRecords(context.Background(), "ns1", database.CollectionName(database.CollectionEvents), []*fftypes.UUID{fftypes.NewUUID()})
+	assert.Regexp(t, "FF00175", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPurgeRecordsFailDeleteMessageData(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin()
+	mock.ExpectExec("DELETE .*messages_data").WillReturnError(fmt.Errorf("pop"))
+	mock.ExpectRollback()
+	err := s.PurgeRecords(context.Background(), "ns1", database.CollectionName(database.CollectionMessages), []*fftypes.UUID{fftypes.NewUUID()})
+	assert.Regexp(t, "FF00179", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPurgeRecordsFailDeleteBlobs(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin()
+	mock.ExpectExec("DELETE .*blobs").WillReturnError(fmt.Errorf("pop"))
+	mock.ExpectRollback()
+	err := s.PurgeRecords(context.Background(), "ns1", database.CollectionName(database.CollectionData), []*fftypes.UUID{fftypes.NewUUID()})
+	assert.Regexp(t, "FF00179", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPurgeRecordsFailDelete(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin()
+	mock.ExpectExec("DELETE .*tokentransfer").WillReturnError(fmt.Errorf("pop"))
+	mock.ExpectRollback()
+	err := s.PurgeRecords(context.Background(), "ns1", database.CollectionName(database.CollectionTokenTransfers), []*fftypes.UUID{fftypes.NewUUID()})
+	assert.Regexp(t, "FF00179", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPurgeRecordsOK(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin()
+	mock.ExpectExec("DELETE .*blockchainevents").WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectCommit()
+	err := s.PurgeRecords(context.Background(), "ns1", database.CollectionName(database.CollectionBlockchainEvents), []*fftypes.UUID{fftypes.NewUUID(), fftypes.NewUUID()})
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetPurgeableDataE2EWithDB(t *testing.T) {
+	s, cleanup := newSQLiteTestProvider(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	s.callbacks.On("OrderedUUIDCollectionNSEvent", database.CollectionMessages, core.ChangeEventTypeCreated, "ns1", mock.Anything, mock.Anything).Return()
+	s.callbacks.On("UUIDCollectionNSEvent", database.CollectionData, core.ChangeEventTypeCreated, "ns1", mock.Anything).Return()
+
+	newData := func() *core.Data {
+		return &core.Data{ID: fftypes.NewUUID(), Namespace: "ns1", Hash: fftypes.NewRandB32(), Created: fftypes.Now()}
+	}
+	unreferenced := newData()
+	pending := newData()
+	oldConfirmed := newData()
+	newConfirmed := newData()
+	err := s.InsertDataArray(ctx, core.DataArray{unreferenced, pending, oldConfirmed, newConfirmed})
+	assert.NoError(t, err)
+
+	old := fftypes.FFTime(time.Now().Add(-2 * time.Hour))
+	newMessage := func(state core.MessageState, created *fftypes.FFTime, data *core.Data) *core.Message {
+		return &core.Message{
+			Header:         core.MessageHeader{ID: fftypes.NewUUID(), Namespace: "ns1", Created: created, DataHash: fftypes.NewRandB32()},
+			Hash:           fftypes.NewRandB32(),
+			LocalNamespace: "ns1",
+			State:          state,
+			Data:           core.DataRefs{{ID: data.ID, Hash: data.Hash}},
+		}
+	}
+	err = s.InsertMessages(ctx, []*core.Message{
+		newMessage(core.MessageStatePending, &old, pending),
+		newMessage(core.MessageStateConfirmed, &old, oldConfirmed),
+		newMessage(core.MessageStateConfirmed, fftypes.Now(), newConfirmed),
+	})
+	assert.NoError(t, err)
+
+	dataIDs := func(data core.DataArray) []*fftypes.UUID {
+		ids := make([]*fftypes.UUID, len(data))
+		for i, d := range data {
+			ids[i] = d.ID
+		}
+		return ids
+	}
+	fb := database.DataQueryFactory.NewFilter(ctx)
+
+	// Only data from confirmed or rejected messages that are being deleted can go
+	messagesBefore := fftypes.FFTime(time.Now().Add(-1 * time.Hour))
+	data, res, err := s.GetPurgeableData(ctx, "ns1", fb.And().Sort("created").Count(true), &messagesBefore)
+	assert.NoError(t, err)
+	assert.Equal(t, []*fftypes.UUID{unreferenced.ID, oldConfirmed.ID}, dataIDs(data))
+	assert.Equal(t, int64(2), *res.TotalCount)
+
+	// All messages are kept if there is no retention for messages
+	data, _, err = s.GetPurgeableData(ctx, "ns1", fb.And().Sort("created"), nil)
+	assert.NoError(t, err)
+	assert.Equal(t, []*fftypes.UUID{unreferenced.ID}, dataIDs(data))
+}
@@ -18,6 +18,7 @@ package sqlcommon
 
 import (
 	"context"
+	"database/sql"
 	"sync"
 
 	"github.com/hyperledger/firefly-common/pkg/config"
@@ -34,6 +35,11 @@ type SQLCommon struct {
 	dbsql.Database
 	capabilities *database.Capabilities
 	callbacks    callbacks
+	jsonPath     jsonPathConfig
+	chart        chartProvider
+	leaseClock   leaseClock
+	replica      *sql.DB
+	encryption   *envelope
 }
 
 type callbacks struct {
@@ -79,7 +85,16 @@ func (cb *callbacks) HashCollectionNSEvent(resType database.HashCollectionNS, ev
 
 func (s *SQLCommon) Init(ctx context.Context, provider dbsql.Provider, config config.Section, capabilities *database.Capabilities) (err error) {
 	s.capabilities = capabilities
-	return s.Database.Init(ctx, provider, config)
+	s.jsonPath.init(s, provider, config)
+	s.initChartProvider(provider)
+	s.initLeaseClock(provider)
+	if err = s.Database.Init(ctx, provider, config); err != nil {
+		return err
+	}
+	if err = s.initReadReplica(ctx, provider, config.SubSection(SQLConfReadReplica)); err != nil {
+		return err
+	}
+	return s.initEncryption(ctx, config.SubSection(SQLConfEncryption))
 }
 
 func (s *SQLCommon) SetHandler(namespace string, handler database.Callbacks) {
@@ -96,3 +111,9 @@ func (s *SQLCommon) SetHandler(namespace string, handler database.Callbacks) {
 }
 
 func (s *SQLCommon) Capabilities() *database.Capabilities { return s.capabilities }
+
+// RunAsReadSnapshot runs all the reads in the function in one transaction. Databases where every statement
+// in a transaction does not see the same snapshot must override this.
+func (s *SQLCommon) RunAsReadSnapshot(ctx context.Context, fn func(ctx context.Context) error) error {
+	return s.RunAsGroup(ctx, fn)
+}
@@ -21,6 +21,7 @@ import (
 	"fmt"
 	"testing"
 
+	"github.com/DATA-DOG/go-sqlmock"
 	sq "github.com/Masterminds/squirrel"
 	"github.com/golang-migrate/migrate/v4"
 	"github.com/hyperledger/firefly-common/pkg/fftypes"
@@ -114,3 +115,16 @@ func TestNamespaceCallbacks(t *testing.T) {
 	s.SetHandler("ns1", nil)
 	assert.Empty(t, s.callbacks.handlers)
 }
+
+func TestRunAsReadSnapshot(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT .*").WillReturnRows(sqlmock.NewRows(datatypeColumns))
+	mock.ExpectCommit()
+	err := s.RunAsReadSnapshot(context.Background(), func(ctx context.Context) error {
+		_, err := s.GetDatatypeByID(ctx, "ns1", fftypes.NewUUID())
+		return err
+	})
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -36,7 +36,7 @@ var (
 		"protocol_id",
 		"subject",
 		"active",
-		"key",
+		`"key"`,
 		"operator_key",
 		"pool_id",
 		"connector",
@@ -49,13 +49,14 @@ var (
 		"created",
 		"message_id",
 		"message_hash",
+		"invalidated",
 	}
 	tokenApprovalFilterFieldMap = map[string]string{
 		"localid":         "local_id",
 		"protocolid":      "protocol_id",
 		"pool":            "pool_id",
 		"approved":        "approved",
-		"key":             "key",
+		"key":             `"key"`,
 		"operator":        "operator_key",
 		"tx.type":         "tx_type",
 		"tx.id":           "tx_id",
@@ -95,7 +96,7 @@ func (s *SQLCommon) UpsertTokenApproval(ctx context.Context, approval *core.Toke
 				Set("local_id", approval.LocalID).
 				Set("subject", approval.Subject).
 				Set("active", approval.Active).
-				Set("key", approval.Key).
+				Set(`"key"`, approval.Key).
 				Set("operator_key", approval.Operator).
 				Set("pool_id", approval.Pool).
 				Set("connector", approval.Connector).
@@ -106,6 +107,7 @@ func (s *SQLCommon) UpsertTokenApproval(ctx context.Context, approval *core.Toke
 				Set("blockchain_event", approval.BlockchainEvent).
 				Set("message_id", approval.Message).
 				Set("message_hash", approval.MessageHash).
+				Set("invalidated", approval.Invalidated).
 				Where(sq.Eq{"protocol_id": approval.ProtocolID}),
 			func() {
 				s.callbacks.UUIDCollectionNSEvent(database.CollectionTokenApprovals, core.ChangeEventTypeUpdated, approval.Namespace, approval.LocalID)
@@ -136,6 +138,7 @@ func (s *SQLCommon) UpsertTokenApproval(ctx context.Context, approval *core.Toke
 					approval.Created,
 					approval.Message,
 					approval.MessageHash,
+					approval.Invalidated,
 				),
 			func() {
 				s.callbacks.UUIDCollectionNSEvent(database.CollectionTokenApprovals, core.ChangeEventTypeCreated, approval.Namespace, approval.LocalID)
@@ -167,6 +170,7 @@ func (s *SQLCommon) tokenApprovalResult(ctx context.Context, row *sql.Rows) (*co
 		&approval.Created,
 		&approval.Message,
 		&approval.MessageHash,
+		&approval.Invalidated,
 	)
 	if err != nil {
 		return nil, i18n.WrapError(ctx, err, coremsgs.MsgDBReadErr, tokenapprovalTable)
@@ -39,13 +39,14 @@ var (
 		"uri",
 		"connector",
 		"namespace",
-		"key",
+		`"key"`,
 		"balance",
 		"updated",
 	}
 	tokenBalanceFilterFieldMap = map[string]string{
 		"pool":       "pool_id",
 		"tokenindex": "token_index",
+		"key":        `"key"`,
 	}
 )
 
@@ -77,7 +78,7 @@ func (s *SQLCommon) addTokenBalance(ctx context.Context, tx *dbsql.TXWrapper, tr
 					"namespace":   balance.Namespace,
 					"pool_id":     balance.Pool,
 					"token_index": balance.TokenIndex,
-					"key":         balance.Key,
+					`"key"`:       balance.Key,
 				}),
 			nil,
 		); err != nil {
@@ -175,7 +176,7 @@ func (s *SQLCommon) GetTokenBalance(ctx context.Context, namespace string, poolI
 		sq.Eq{"namespace": namespace},
 		sq.Eq{"pool_id": poolID},
 		sq.Eq{"token_index": tokenIndex},
-		sq.Eq{"key": key},
+		sq.Eq{`"key"`: key},
 	})
 }
 
@@ -206,7 +207,7 @@ func (s *SQLCommon) GetTokenBalances(ctx context.Context, namespace string, filt
 
 func (s *SQLCommon) GetTokenAccounts(ctx context.Context, namespace string, filter ffapi.Filter) ([]*core.TokenAccount, *ffapi.FilterResult, error) {
 	query, fop, fi, err := s.FilterSelect(ctx, "",
-		sq.Select("key", "MAX(updated) AS updated", "MAX(seq) AS seq").From(tokenbalanceTable).GroupBy("key"),
+		sq.Select(`"key"`, "MAX(updated) AS updated", "MAX(seq) AS seq").From(tokenbalanceTable).GroupBy(`"key"`),
 		filter, tokenBalanceFilterFieldMap, []interface{}{"seq"}, sq.Eq{"namespace": namespace})
 	if err != nil {
 		return nil, nil, err
@@ -235,7 +236,7 @@ func (s *SQLCommon) GetTokenAccounts(ctx context.Context, namespace string, filt
 func (s *SQLCommon) GetTokenAccountPools(ctx context.Context, namespace, key string, filter ffapi.Filter) ([]*core.TokenAccountPool, *ffapi.FilterResult, error) {
 	query, fop, fi, err := s.FilterSelect(ctx, "",
 		sq.Select("pool_id", "MAX(updated) AS updated", "MAX(seq) AS seq").From(tokenbalanceTable).GroupBy("pool_id"),
-		filter, tokenBalanceFilterFieldMap, []interface{}{"seq"}, sq.Eq{"key": key, "namespace": namespace})
+		filter, tokenBalanceFilterFieldMap, []interface{}{"seq"}, sq.Eq{`"key"`: key, "namespace": namespace})
 	if err != nil {
 		return nil, nil, err
 	}
@@ -78,6 +78,7 @@ func TestTokenTransferE2EWithDB(t *testing.T) {
 	transferRead, err := s.GetTokenTransferByID(ctx, "ns1", transfer.LocalID)
 	assert.NoError(t, err)
 	assert.NotNil(t, transferRead)
+	assert.Greater(t, transferRead.Sequence, int64(0))
 	transferReadJson, _ := json.Marshal(&transferRead)
 	assert.Equal(t, string(transferJson), string(transferReadJson))
 
@@ -105,6 +106,14 @@ func TestTokenTransferE2EWithDB(t *testing.T) {
 	transferReadJson, _ = json.Marshal(transfers[0])
 	assert.Equal(t, string(transferJson), string(transferReadJson))
 
+	// Invalidate the token transfer
+	err = s.UpdateTokenTransfers(ctx, "ns1", fb.Eq("blockchainevent", transfer.BlockchainEvent), database.TokenTransferQueryFactory.NewUpdate(ctx).Set("invalidated", true))
+	assert.NoError(t, err)
+	transfers, _, err = s.GetTokenTransfers(ctx, "ns1", fb.And(fb.Eq("invalidated", true)))
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(transfers))
+	assert.True(t, transfers[0].Invalidated)
+
 	// Delete the token transfer
 	err = s.DeleteTokenTransfers(ctx, "ns1", transfer.Pool)
 	assert.NoError(t, err)
@@ -220,3 +229,43 @@ func TestDeleteTokenTransfersFailDelete(t *testing.T) {
 	assert.Regexp(t, "FF00179", err)
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
+
+func TestUpdateTokenTransfersBeginFail(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin().WillReturnError(fmt.Errorf("pop"))
+	ctx := context.Background()
+	err := s.UpdateTokenTransfers(ctx, "ns1", database.TokenTransferQueryFactory.NewFilter(ctx).Eq("protocolid", "1"), database.TokenTransferQueryFactory.NewUpdate(ctx).Set("invalidated", true))
+	assert.Regexp(t, "FF00175", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUpdateTokenTransfersBadUpdate(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin()
+	mock.ExpectRollback()
+	ctx := context.Background()
+	err := s.UpdateTokenTransfers(ctx, "ns1", database.TokenTransferQueryFactory.NewFilter(ctx).Eq("protocolid", "1"), database.TokenTransferQueryFactory.NewUpdate(ctx).Set("bad", true))
+	assert.Regexp(t, "FF00142", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUpdateTokenTransfersBadFilter(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin()
+	mock.ExpectRollback()
+	ctx := context.Background()
+	err := s.UpdateTokenTransfers(ctx, "ns1", database.TokenTransferQueryFactory.NewFilter(ctx).Eq("bad", "1"), database.TokenTransferQueryFactory.NewUpdate(ctx).Set("invalidated", true))
+	assert.Regexp(t, "FF00142", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUpdateTokenTransfersUpdateFail(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin()
+	mock.ExpectExec("UPDATE .*").WillReturnError(fmt.Errorf("pop"))
+	mock.ExpectRollback()
+	ctx := context.Background()
+	err := s.UpdateTokenTransfers(ctx, "ns1", database.TokenTransferQueryFactory.NewFilter(ctx).Eq("protocolid", "1"), database.TokenTransferQueryFactory.NewUpdate(ctx).Set("invalidated", true))
+	assert.Regexp(t, "FF00178", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
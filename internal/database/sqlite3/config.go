@@ -31,4 +31,5 @@ const (
 func (sqlite *SQLite3) InitConfig(config config.Section) {
 	sqlite.SQLCommon.InitConfig(sqlite, config)
 	config.SetDefault(sqlcommon.SQLConfMaxConnections, defaultConnectionLimitSQLite)
+	config.SubSection(sqlcommon.SQLConfReadReplica).SetDefault(sqlcommon.SQLConfMaxConnections, defaultConnectionLimitSQLite)
 }
@@ -33,6 +33,7 @@ import (
 	"github.com/hyperledger/firefly-common/pkg/retry"
 	"github.com/hyperledger/firefly-common/pkg/wsclient"
 	"github.com/hyperledger/firefly/internal/coremsgs"
+	"github.com/hyperledger/firefly/internal/tracing"
 	"github.com/hyperledger/firefly/pkg/core"
 	"github.com/hyperledger/firefly/pkg/dataexchange"
 )
@@ -192,6 +193,7 @@ func (h *FFDX) Init(ctx context.Context, cancelCtx context.CancelFunc, config co
 	if err != nil {
 		return err
 	}
+	tracing.InstrumentClient(h.client)
 
 	h.capabilities = &dataexchange.Capabilities{
 		Manifest: config.GetBool(DataExchangeManifestEnabled),
@@ -37,6 +37,7 @@ import (
 	"github.com/hyperledger/firefly/internal/identity"
 	"github.com/hyperledger/firefly/internal/metrics"
 	"github.com/hyperledger/firefly/internal/privatemessaging"
+	"github.com/hyperledger/firefly/internal/tracing"
 	"github.com/hyperledger/firefly/pkg/blockchain"
 	"github.com/hyperledger/firefly/pkg/core"
 	"github.com/hyperledger/firefly/pkg/database"
@@ -60,6 +61,8 @@ type aggregator struct {
 	metrics      metrics.Manager
 	batchCache   cache.CInterface
 	rewinder     *rewinder
+	forwarder    *rewindForwarder
+	started      bool
 }
 
 type batchCacheEntry struct {
@@ -137,16 +140,24 @@ func newAggregator(ctx context.Context, ns string, di database.Plugin, bi blockc
 		queryFactory:     database.PinQueryFactory,
 		addCriteria: func(af ffapi.AndFilter) ffapi.AndFilter {
 			fb := af.Builder()
-			return af.Condition(fb.Eq("dispatched", false))
+			return af.Condition(fb.Eq("dispatched", false), fb.Eq("invalidated", false))
 		},
 		maybeRewind: ag.rewindOffchainBatches,
 	})
 	ag.retry = &ag.eventPoller.conf.retry
 	ag.rewinder = newRewinder(ag)
+	if config.GetBool(coreconfig.LeaderElectionEnabled) {
+		ag.forwarder = newRewindForwarder(ag)
+	}
 	return ag, nil
 }
 
+// start is only called on the leader replica, when leader election is enabled
 func (ag *aggregator) start() {
+	ag.started = true
+	if ag.forwarder != nil {
+		ag.forwarder.startListening()
+	}
 	ag.rewinder.start()
 	ag.eventPoller.start()
 }
@@ -198,6 +209,7 @@ func (ag *aggregator) rewindOffchainBatches() (bool, int64) {
 		pinFilter := pfb.And(
 			pfb.In("batch", batchIDs),
 			pfb.Eq("dispatched", false),
+			pfb.Eq("invalidated", false),
 		).Sort("sequence").Limit(1) // only need the one oldest sequence
 		sequences, _, err := ag.database.GetPins(ag.ctx, ag.namespace, pinFilter)
 		if err != nil {
@@ -328,7 +340,7 @@ func (ag *aggregator) GetBatchForPin(ctx context.Context, pin *core.Pin) (*core.
 		log.L(ag.ctx).Debugf("Batch cache hit %s", cacheKey)
 		return bce.batch, bce.manifest, nil
 	}
-	batch, err := ag.database.GetBatchByID(ctx, ag.namespace, pin.Batch)
+	batch, err := ag.database.GetBatchByID(database.WithPrimaryReads(ctx), ag.namespace, pin.Batch)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -456,6 +468,12 @@ func (ag *aggregator) checkOnchainConsistency(ctx context.Context, msg *core.Mes
 }
 
 func (ag *aggregator) processMessage(ctx context.Context, manifest *core.BatchManifest, pin *core.Pin, msgBaseIndex int64, msgEntry *core.MessageManifestEntry, batch *core.BatchPersisted, state *batchState) (err error) {
+	_, span := tracing.StartSpanFrom(ctx, "message.process", []fftypes.JSONObject{batch.TraceContext},
+		tracing.NamespaceKey.String(ag.namespace),
+		tracing.BatchIDKey.String(batch.ID.String()),
+		tracing.MessageIDKey.String(msgEntry.ID.String()),
+	)
+	defer func() { tracing.EndSpan(span, err) }()
 	l := log.L(ctx)
 
 	unmaskedContexts := make([]*fftypes.Bytes32, 0)
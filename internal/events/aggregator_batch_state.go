@@ -134,6 +134,7 @@ func (bs *batchState) checkUnmaskedContextReady(ctx context.Context, contextUnma
 		filter := fb.And(
 			fb.Eq("hash", contextUnmasked),
 			fb.Eq("dispatched", false),
+			fb.Eq("invalidated", false),
 			fb.Lt("sequence", firstMsgPinSequence),
 		)
 		earlier, _, err := bs.database.GetPins(ctx, bs.namespace, filter)
@@ -420,6 +421,7 @@ func (bs *batchState) attemptContextInit(ctx context.Context, msg *core.Message,
 	filter := fb.And(
 		fb.In("hash", zeroHashes),
 		fb.Eq("dispatched", false),
+		fb.Eq("invalidated", false),
 		fb.Lt("sequence", pinnedSequence),
 	)
 	earlier, _, err := bs.database.GetPins(ctx, bs.namespace, filter)
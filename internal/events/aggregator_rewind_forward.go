@@ -0,0 +1,187 @@
+// Copyright © 2024 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/hyperledger/firefly-common/pkg/config"
+	"github.com/hyperledger/firefly-common/pkg/fftypes"
+	"github.com/hyperledger/firefly-common/pkg/log"
+	"github.com/hyperledger/firefly-common/pkg/retry"
+	"github.com/hyperledger/firefly/internal/coreconfig"
+	"github.com/hyperledger/firefly/pkg/core"
+	"github.com/hyperledger/firefly/pkg/database"
+)
+
+const (
+	rewindForwardTopic     = "aggregator_rewind"
+	rewindForwardReadLimit = 100
+)
+
+// forwardedRewind is a rewind queued on a follower replica, for the aggregator on the leader
+type forwardedRewind struct {
+	Type rewindType       `json:"type"`
+	UUID *fftypes.UUID    `json:"uuid,omitempty"`
+	Hash *fftypes.Bytes32 `json:"hash,omitempty"`
+	DID  string           `json:"did,omitempty"`
+}
+
+// rewindForwarder passes the rewinds queued on a follower replica to the aggregator on the leader, as cluster
+// notifications. Rewinds are idempotent, so a newly elected leader replays all the rewinds that have not yet
+// expired, in case the previous leader did not process them.
+type rewindForwarder struct {
+	ctx          context.Context
+	namespace    string
+	database     database.Plugin
+	rewinder     *rewinder
+	retry        *retry.Retry
+	pollInterval time.Duration
+	retention    time.Duration
+	leading      chan struct{}
+	forwardDone  chan struct{}
+	listenDone   chan struct{}
+	lastSequence int64
+	lastPrune    time.Time
+}
+
+func newRewindForwarder(ag *aggregator) *rewindForwarder {
+	return &rewindForwarder{
+		ctx:          log.WithLogField(ag.ctx, "role", "aggregator-rewind-forward"),
+		namespace:    ag.namespace,
+		database:     ag.database,
+		rewinder:     ag.rewinder,
+		retry:        ag.retry,
+		pollInterval: config.GetDuration(coreconfig.EventAggregatorPollTimeout),
+		retention:    config.GetDuration(coreconfig.EventAggregatorRewindRetention),
+		leading:      make(chan struct{}),
+		forwardDone:  make(chan struct{}),
+		listenDone:   make(chan struct{}),
+		lastPrune:    time.Now(),
+	}
+}
+
+// startForwarding forwards rewinds to the leader, until this replica becomes the leader
+func (fw *rewindForwarder) startForwarding() {
+	go fw.forwardLoop()
+}
+
+// startListening stops forwarding rewinds, and starts receiving them from the other replicas
+func (fw *rewindForwarder) startListening() {
+	close(fw.leading)
+	go fw.listenLoop()
+}
+
+func (fw *rewindForwarder) forwardLoop() {
+	defer close(fw.forwardDone)
+	for {
+		select {
+		case <-fw.leading:
+			log.L(fw.ctx).Debugf("Rewind forwarder stopping as this replica is the leader")
+			return
+		case rw := <-fw.rewinder.rewindRequests:
+			fw.forward(&rw)
+		case <-fw.ctx.Done():
+			log.L(fw.ctx).Debugf("Rewind forwarder stopping")
+			return
+		}
+	}
+}
+
+func (fw *rewindForwarder) forward(rw *rewind) {
+	fr := &forwardedRewind{Type: rw.rewindType}
+	switch rw.rewindType {
+	case rewindBatch, rewindMessage:
+		fr.UUID = &rw.uuid
+	case rewindBlob:
+		fr.Hash = &rw.hash
+	default:
+		fr.DID = rw.did
+	}
+	payload, _ := json.Marshal(fr)
+	// Retry until we are stopped, as the leader would otherwise miss the rewind
+	_ = fw.retry.Do(fw.ctx, "forward rewind", func(attempt int) (retry bool, err error) {
+		return true, fw.database.InsertClusterNotification(fw.ctx, &core.ClusterNotification{
+			Namespace: fw.namespace,
+			Topic:     rewindForwardTopic,
+			Payload:   fftypes.JSONAnyPtrBytes(payload),
+		})
+	})
+}
+
+func (fw *rewindForwarder) listenLoop() {
+	defer close(fw.listenDone)
+	l := log.L(fw.ctx)
+	notify, err := fw.database.ListenClusterNotifications(fw.ctx, fw.namespace, rewindForwardTopic)
+	if err != nil {
+		l.Errorf("Failed to listen for forwarded rewinds. Polling every %s: %s", fw.pollInterval, err)
+	}
+	for {
+		if err := fw.readRewinds(); err != nil {
+			l.Errorf("Failed to read rewinds forwarded from other replicas: %s", err)
+		}
+		if time.Since(fw.lastPrune) > fw.retention {
+			fw.lastPrune = time.Now()
+			expiry := fftypes.FFTime(fw.lastPrune.Add(-fw.retention))
+			if err := fw.database.DeleteClusterNotifications(fw.ctx, fw.namespace, rewindForwardTopic, &expiry); err != nil {
+				l.Errorf("Failed to delete expired rewinds: %s", err)
+			}
+		}
+		select {
+		case <-notify:
+		case <-time.After(fw.pollInterval):
+		case <-fw.ctx.Done():
+			l.Debugf("Rewind listener stopping")
+			return
+		}
+	}
+}
+
+func (fw *rewindForwarder) readRewinds() error {
+	for {
+		fb := database.ClusterNotificationQueryFactory.NewFilterLimit(fw.ctx, rewindForwardReadLimit)
+		notifications, _, err := fw.database.GetClusterNotifications(fw.ctx, fw.namespace, fb.And(
+			fb.Eq("topic", rewindForwardTopic),
+			fb.Gt("sequence", fw.lastSequence),
+		).Sort("sequence"))
+		if err != nil || len(notifications) == 0 {
+			return err
+		}
+		for _, n := range notifications {
+			fw.lastSequence = n.Sequence
+			var fr forwardedRewind
+			if err := json.Unmarshal(n.Payload.Bytes(), &fr); err != nil {
+				log.L(fw.ctx).Errorf("Invalid forwarded rewind %d: %s", n.Sequence, err)
+				continue
+			}
+			rw := rewind{rewindType: fr.Type, did: fr.DID}
+			if fr.UUID != nil {
+				rw.uuid = *fr.UUID
+			}
+			if fr.Hash != nil {
+				rw.hash = *fr.Hash
+			}
+			select {
+			case fw.rewinder.rewindRequests <- rw:
+			case <-fw.ctx.Done():
+				return nil
+			}
+		}
+	}
+}
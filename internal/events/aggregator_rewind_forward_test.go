@@ -0,0 +1,215 @@
+// Copyright © 2024 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package events
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/firefly-common/pkg/cache"
+	"github.com/hyperledger/firefly-common/pkg/config"
+	"github.com/hyperledger/firefly-common/pkg/fftypes"
+	"github.com/hyperledger/firefly-common/pkg/retry"
+	"github.com/hyperledger/firefly/internal/coreconfig"
+	"github.com/hyperledger/firefly/mocks/blockchainmocks"
+	"github.com/hyperledger/firefly/mocks/cachemocks"
+	"github.com/hyperledger/firefly/mocks/databasemocks"
+	"github.com/hyperledger/firefly/pkg/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func newTestRewindForwarder(ag *testAggregator) *rewindForwarder {
+	fw := newRewindForwarder(&ag.aggregator)
+	fw.retry = &retry.Retry{InitialDelay: time.Microsecond, MaximumDelay: time.Microsecond}
+	ag.forwarder = fw
+	return fw
+}
+
+func TestNewAggregatorLeaderElection(t *testing.T) {
+	coreconfig.Reset()
+	config.Set(coreconfig.LeaderElectionEnabled, true)
+	mbi := &blockchainmocks.Plugin{}
+	mbi.On("VerifierType").Return(core.VerifierTypeEthAddress)
+	cmi := &cachemocks.Manager{}
+	cmi.On("GetCache", mock.Anything).Return(cache.NewUmanagedCache(context.Background(), 100, 5*time.Minute), nil)
+	ag, err := newAggregator(context.Background(), "ns1", &databasemocks.Plugin{}, mbi, nil, nil, nil, nil, newEventNotifier(context.Background(), "ut"), nil, cmi)
+	assert.NoError(t, err)
+	assert.NotNil(t, ag.forwarder)
+}
+
+func TestRewindForwardRoundTrip(t *testing.T) {
+	ag := newTestAggregator()
+	defer ag.cleanup(t)
+	fw := newTestRewindForwarder(ag)
+
+	rewinds := []rewind{
+		{rewindType: rewindBatch, uuid: *fftypes.NewUUID()},
+		{rewindType: rewindMessage, uuid: *fftypes.NewUUID()},
+		{rewindType: rewindBlob, hash: *fftypes.NewRandB32()},
+		{rewindType: rewindDIDConfirmed, did: "did:firefly:org/org1"},
+	}
+
+	var forwarded []*core.ClusterNotification
+	inserted := make(chan struct{})
+	ag.mdi.On("InsertClusterNotification", mock.Anything, mock.Anything).Return(fmt.Errorf("pop")).Once()
+	ag.mdi.On("InsertClusterNotification", mock.Anything, mock.MatchedBy(func(n *core.ClusterNotification) bool {
+		return n.Namespace == "ns1" && n.Topic == rewindForwardTopic
+	})).Return(nil).Run(func(args mock.Arguments) {
+		n := args[1].(*core.ClusterNotification)
+		n.Sequence = int64(len(forwarded) + 1)
+		forwarded = append(forwarded, n)
+		inserted <- struct{}{}
+	})
+
+	fw.startForwarding()
+	for _, rw := range rewinds {
+		ag.rewinder.rewindRequests <- rw
+		<-inserted
+	}
+
+	// Become the leader, and receive the rewinds back
+	ag.mdi.On("ListenClusterNotifications", mock.Anything, "ns1", rewindForwardTopic).Return(nil, fmt.Errorf("pop"))
+	ag.mdi.On("GetClusterNotifications", mock.Anything, "ns1", mock.Anything).Return(forwarded, nil, nil).Once()
+	ag.mdi.On("GetClusterNotifications", mock.Anything, "ns1", mock.Anything).Return([]*core.ClusterNotification{}, nil, nil)
+	fw.startListening()
+	<-fw.forwardDone
+
+	for _, rw := range rewinds {
+		assert.Equal(t, rw, <-ag.rewinder.rewindRequests)
+	}
+	assert.Equal(t, int64(len(rewinds)), fw.lastSequence)
+
+	ag.cancel()
+	<-fw.listenDone
+}
+
+func TestRewindForwardStopOnCancel(t *testing.T) {
+	ag := newTestAggregator()
+	defer ag.cleanup(t)
+	fw := newTestRewindForwarder(ag)
+
+	ag.cancel()
+	fw.startForwarding()
+	<-fw.forwardDone
+}
+
+func TestRewindListenNotifyAndPrune(t *testing.T) {
+	ag := newTestAggregator()
+	defer ag.cleanup(t)
+	fw := newTestRewindForwarder(ag)
+	fw.retention = 0
+	fw.lastPrune = time.Now().Add(-time.Second)
+
+	notify := make(chan *core.ClusterNotification)
+	ag.mdi.On("ListenClusterNotifications", mock.Anything, "ns1", rewindForwardTopic).Return((<-chan *core.ClusterNotification)(notify), nil)
+	ag.mdi.On("GetClusterNotifications", mock.Anything, "ns1", mock.Anything).Return(nil, nil, fmt.Errorf("pop"))
+	ag.mdi.On("DeleteClusterNotifications", mock.Anything, "ns1", rewindForwardTopic, mock.Anything).Return(fmt.Errorf("pop")).Once()
+	ag.mdi.On("DeleteClusterNotifications", mock.Anything, "ns1", rewindForwardTopic, mock.Anything).Return(nil).Run(func(args mock.Arguments) {
+		ag.cancel()
+	})
+
+	go fw.listenLoop()
+	notify <- &core.ClusterNotification{}
+	<-fw.listenDone
+}
+
+func TestRewindListenPoll(t *testing.T) {
+	ag := newTestAggregator()
+	defer ag.cleanup(t)
+	fw := newTestRewindForwarder(ag)
+	fw.pollInterval = time.Microsecond
+
+	ag.mdi.On("ListenClusterNotifications", mock.Anything, "ns1", rewindForwardTopic).Return(nil, fmt.Errorf("pop"))
+	ag.mdi.On("GetClusterNotifications", mock.Anything, "ns1", mock.Anything).Return([]*core.ClusterNotification{}, nil, nil).Once()
+	ag.mdi.On("GetClusterNotifications", mock.Anything, "ns1", mock.Anything).Return([]*core.ClusterNotification{}, nil, nil).Run(func(args mock.Arguments) {
+		ag.cancel()
+	})
+
+	go fw.listenLoop()
+	<-fw.listenDone
+}
+
+func TestReadRewindsBadPayload(t *testing.T) {
+	ag := newTestAggregator()
+	defer ag.cleanup(t)
+	fw := newTestRewindForwarder(ag)
+
+	ag.mdi.On("GetClusterNotifications", mock.Anything, "ns1", mock.Anything).Return([]*core.ClusterNotification{
+		{Sequence: 12345, Payload: fftypes.JSONAnyPtr("!json")},
+	}, nil, nil).Once()
+	ag.mdi.On("GetClusterNotifications", mock.Anything, "ns1", mock.Anything).Return([]*core.ClusterNotification{}, nil, nil)
+
+	err := fw.readRewinds()
+	assert.NoError(t, err)
+	assert.Equal(t, int64(12345), fw.lastSequence)
+	assert.Empty(t, ag.rewinder.rewindRequests)
+}
+
+func TestReadRewindsCancelled(t *testing.T) {
+	ag := newTestAggregator()
+	defer ag.cleanup(t)
+	fw := newTestRewindForwarder(ag)
+	fw.rewinder.rewindRequests = make(chan rewind)
+
+	ag.mdi.On("GetClusterNotifications", mock.Anything, "ns1", mock.Anything).Return([]*core.ClusterNotification{
+		{Sequence: 1, Payload: fftypes.JSONAnyPtr(`{"type":0}`)},
+	}, nil, nil)
+
+	ag.cancel()
+	err := fw.readRewinds()
+	assert.NoError(t, err)
+}
+
+func TestStartAggregatorListensForRewinds(t *testing.T) {
+	ag := newTestAggregator()
+	defer ag.cleanup(t)
+	fw := newTestRewindForwarder(ag)
+
+	ag.mdi.On("GetOffset", mock.Anything, core.OffsetTypeAggregator, aggregatorOffsetName).Return(&core.Offset{
+		Type:    core.OffsetTypeAggregator,
+		Name:    aggregatorOffsetName,
+		Current: 12345,
+		RowID:   333333,
+	}, nil)
+	ag.mdi.On("GetPins", mock.Anything, "ns1", mock.Anything).Return([]*core.Pin{}, nil, nil)
+	ag.mdi.On("ListenClusterNotifications", mock.Anything, "ns1", rewindForwardTopic).Return(nil, fmt.Errorf("pop"))
+	ag.mdi.On("GetClusterNotifications", mock.Anything, "ns1", mock.Anything).Return([]*core.ClusterNotification{}, nil, nil)
+
+	ag.start()
+	assert.True(t, ag.started)
+	ag.cancel()
+	<-fw.listenDone
+	<-ag.eventPoller.closed
+	<-ag.rewinder.loop1Done
+	<-ag.rewinder.loop2Done
+}
+
+func TestEventManagerStartForwardsRewinds(t *testing.T) {
+	em := newTestEventManager(t)
+	defer em.cleanup(t)
+	em.mdi.On("GetSubscriptions", mock.Anything, mock.Anything, mock.Anything).Return([]*core.Subscription{}, nil, nil)
+	fw := newRewindForwarder(em.aggregator)
+	em.aggregator.forwarder = fw
+
+	assert.NoError(t, em.Start())
+	em.cancel()
+	<-fw.forwardDone
+	em.WaitStop()
+}
@@ -21,6 +21,7 @@ import (
 
 	"github.com/hyperledger/firefly-common/pkg/fftypes"
 	"github.com/hyperledger/firefly-common/pkg/log"
+	"github.com/hyperledger/firefly/internal/tracing"
 	"github.com/hyperledger/firefly/pkg/blockchain"
 	"github.com/hyperledger/firefly/pkg/core"
 )
@@ -31,8 +32,14 @@ import (
 //
 // We must block here long enough to get the payload from the sharedstorage, persist the messages in the correct
 // sequence, and also persist all the data.
-func (em *eventManager) handleBlockchainBatchPinEvent(ctx context.Context, event *blockchain.BatchPinCompleteEvent, bc *eventBatchContext) error {
+func (em *eventManager) handleBlockchainBatchPinEvent(ctx context.Context, event *blockchain.BatchPinCompleteEvent, bc *eventBatchContext) (err error) {
 	batchPin := event.Batch
+	ctx, span := tracing.StartSpan(ctx, "blockchain.batchPinComplete",
+		tracing.NamespaceKey.String(event.Namespace),
+		tracing.BatchIDKey.String(batchPin.BatchID.String()),
+		tracing.TransactionIDKey.String(batchPin.TransactionID.String()),
+	)
+	defer func() { tracing.EndSpan(span, err) }()
 
 	if em.multiparty == nil {
 		log.L(ctx).Errorf("Ignoring batch pin from non-multiparty network!")
@@ -51,6 +58,14 @@ func (em *eventManager) handleBlockchainBatchPinEvent(ctx context.Context, event
 		batchPin.TransactionType = core.TransactionTypeBatchPin
 	}
 
+	if batchPin.Event.Removed {
+		log.L(ctx).Infof("BatchPinComplete removed batch=%s txn=%s", batchPin.BatchID, batchPin.Event.ProtocolID)
+		bc.postInsert = append(bc.postInsert, func() error {
+			return em.removeBlockchainEvent(ctx, nil, &batchPin.Event, em.getTopicForChainListener(nil), batchPin.BatchID)
+		})
+		return nil
+	}
+
 	log.L(ctx).Infof("-> BatchPinComplete batch=%s txn=%s signingIdentity=%s", batchPin.BatchID, batchPin.Event.ProtocolID, event.SigningKey.Value)
 	defer func() {
 		log.L(ctx).Infof("<- BatchPinComplete batch=%s txn=%s signingIdentity=%s", batchPin.BatchID, batchPin.Event.ProtocolID, event.SigningKey.Value)
@@ -69,15 +84,15 @@ func (em *eventManager) handleBlockchainBatchPinEvent(ctx context.Context, event
 	bc.addEventToInsert(chainEvent, em.getTopicForChainListener(nil))
 	bc.postInsert = append(bc.postInsert, func() error {
 		em.emitBlockchainEventMetric(&batchPin.Event)
-		return em.postBlockchainBatchPinEventInsert(ctx, event)
+		return em.postBlockchainBatchPinEventInsert(ctx, event, bc)
 	})
 	return nil
 }
 
-func (em *eventManager) postBlockchainBatchPinEventInsert(ctx context.Context, event *blockchain.BatchPinCompleteEvent) error {
+func (em *eventManager) postBlockchainBatchPinEventInsert(ctx context.Context, event *blockchain.BatchPinCompleteEvent, bc *eventBatchContext) error {
 	batchPin := event.Batch
 	private := batchPin.BatchPayloadRef == ""
-	if err := em.persistContexts(ctx, batchPin, event.SigningKey, private); err != nil {
+	if err := em.persistContexts(ctx, batchPin, event.SigningKey, private, bc); err != nil {
 		return err
 	}
 
@@ -102,7 +117,7 @@ func (em *eventManager) persistBatchTransaction(ctx context.Context, batchPin *b
 	return err
 }
 
-func (em *eventManager) persistContexts(ctx context.Context, batchPin *blockchain.BatchPin, signingKey *core.VerifierRef, private bool) error {
+func (em *eventManager) persistContexts(ctx context.Context, batchPin *blockchain.BatchPin, signingKey *core.VerifierRef, private bool, bc *eventBatchContext) error {
 	pins := make([]*core.Pin, len(batchPin.Contexts))
 	for idx, hash := range batchPin.Contexts {
 		pins[idx] = &core.Pin{
@@ -124,11 +139,12 @@ func (em *eventManager) persistContexts(ctx context.Context, batchPin *blockchai
 	}
 	log.L(ctx).Warnf("Batch insert of pins failed - assuming replay and performing upserts: %s", err)
 
-	// Fall back to an upsert
+	// Fall back to an upsert, which revalidates any pins invalidated by a chain reorganization
 	for _, pin := range pins {
 		if err := em.database.UpsertPin(ctx, pin); err != nil {
 			return err
 		}
 	}
+	bc.batchesToRewind = append(bc.batchesToRewind, batchPin.BatchID)
 	return nil
 }
@@ -252,6 +252,10 @@ func TestBatchPinCompleteOkPrivate(t *testing.T) {
 	})
 	assert.NoError(t, err)
 
+	// The upsert fallback may have revalidated pins, so the batch is rewound
+	rw := <-em.aggregator.rewinder.rewindRequests
+	assert.Equal(t, *batchPin.BatchID, rw.uuid)
+
 	// Call through to persistBatch - the hash of our batch will be invalid,
 	// which is swallowed without error as we cannot retry (it is logged of course)
 	fn := em.mdi.Calls[1].Arguments[1].(func(ctx context.Context) error)
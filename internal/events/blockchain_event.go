@@ -22,20 +22,29 @@ import (
 
 	"github.com/hyperledger/firefly-common/pkg/fftypes"
 	"github.com/hyperledger/firefly-common/pkg/log"
+	"github.com/hyperledger/firefly/internal/tracing"
 	"github.com/hyperledger/firefly/pkg/blockchain"
 	"github.com/hyperledger/firefly/pkg/core"
+	"github.com/hyperledger/firefly/pkg/database"
 )
 
 type eventBatchContext struct {
 	contractListenerResults map[string]*core.ContractListener
-	topicsByEventID         map[string]string
+	topicsByEventKey        map[string]string
 	chainEventsToInsert     []*core.BlockchainEvent
 	postInsert              []func() error
+	batchesToRewind         []*fftypes.UUID
+}
+
+// eventKey identifies a blockchain event by its listener and protocol ID, as an event that is delivered
+// again after a reorganization is reinstated with the ID it was first stored with
+func eventKey(event *core.BlockchainEvent) string {
+	return fmt.Sprintf("%v:%s", event.Listener, event.ProtocolID)
 }
 
 func (bc *eventBatchContext) addEventToInsert(event *core.BlockchainEvent, topic string) {
 	bc.chainEventsToInsert = append(bc.chainEventsToInsert, event)
-	bc.topicsByEventID[event.ID.String()] = topic
+	bc.topicsByEventKey[eventKey(event)] = topic
 }
 
 func buildBlockchainEvent(ns string, subID *fftypes.UUID, event *blockchain.Event, tx *core.BlockchainTransactionRef) *core.BlockchainEvent {
@@ -62,7 +71,7 @@ func (em *eventManager) getChainListenerByProtocolIDCached(ctx context.Context,
 		return l, nil
 	}
 	l, err := em.getChainListenerCached(fmt.Sprintf("pid:%s", protocolID), func() (*core.ContractListener, error) {
-		return em.database.GetContractListenerByBackendID(ctx, em.namespace.Name, protocolID)
+		return em.database.GetContractListenerByBackendID(database.WithPrimaryReads(ctx), em.namespace.Name, protocolID)
 	})
 	if err != nil {
 		return nil, err
@@ -122,7 +131,7 @@ func (em *eventManager) maybePersistBlockchainEvents(ctx context.Context, bc *ev
 	}
 	// Only the ones newly inserted need events emitting
 	for _, chainEvent := range inserted {
-		topic := bc.topicsByEventID[chainEvent.ID.String()] // bc.addEvent() ensures this is there
+		topic := bc.topicsByEventKey[eventKey(chainEvent)] // bc.addEvent() ensures this is there
 		ffEvent := core.NewEvent(core.EventTypeBlockchainEventReceived, chainEvent.Namespace, chainEvent.ID, chainEvent.TX.ID, topic)
 		if err := em.database.InsertEvent(ctx, ffEvent); err != nil {
 			return err
@@ -137,13 +146,19 @@ func (em *eventManager) emitBlockchainEventMetric(event *blockchain.Event) {
 	}
 }
 
-func (em *eventManager) BlockchainEventBatch(batch []*blockchain.EventToDispatch) error {
-	return em.retry.Do(em.ctx, "persist blockchain event", func(attempt int) (bool, error) {
+func (em *eventManager) BlockchainEventBatch(batch []*blockchain.EventToDispatch) (err error) {
+	ctx, span := tracing.StartSpan(em.ctx, "blockchain.eventBatch",
+		tracing.NamespaceKey.String(em.namespace.Name),
+		tracing.EventCountKey.Int(len(batch)),
+	)
+	defer func() { tracing.EndSpan(span, err) }()
+
+	return em.retry.Do(ctx, "persist blockchain event", func(attempt int) (bool, error) {
 		bc := &eventBatchContext{
 			contractListenerResults: make(map[string]*core.ContractListener),
-			topicsByEventID:         make(map[string]string),
+			topicsByEventKey:        make(map[string]string),
 		}
-		return true, em.database.RunAsGroup(em.ctx, func(ctx context.Context) error {
+		err := em.database.RunAsGroup(ctx, func(ctx context.Context) error {
 			// Process the events, generating the optimized list of event inserts
 			for _, event := range batch {
 				switch event.Type {
@@ -175,6 +190,14 @@ func (em *eventManager) BlockchainEventBatch(batch []*blockchain.EventToDispatch
 			}
 			return nil
 		})
+		if err != nil {
+			return true, err
+		}
+		// Pins that were revalidated are behind the aggregator, so it must be rewound once they are committed
+		for _, batchID := range bc.batchesToRewind {
+			em.aggregator.queueBatchRewind(batchID)
+		}
+		return false, nil
 	})
 }
 
@@ -193,6 +216,13 @@ func (em *eventManager) handleBlockchainEventForListener(ctx context.Context, ev
 	}
 	listener.Namespace = em.namespace.Name
 
+	if event.Removed {
+		bc.postInsert = append(bc.postInsert, func() error {
+			return em.removeBlockchainEvent(ctx, listener.ID, event.Event, em.getTopicForChainListener(listener), nil)
+		})
+		return nil
+	}
+
 	chainEvent := buildBlockchainEvent(listener.Namespace, listener.ID, event.Event, &core.BlockchainTransactionRef{
 		BlockchainID: event.BlockchainTXID,
 	})
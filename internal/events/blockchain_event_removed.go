@@ -0,0 +1,63 @@
+// Copyright © 2024 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package events
+
+import (
+	"context"
+
+	"github.com/hyperledger/firefly-common/pkg/fftypes"
+	"github.com/hyperledger/firefly-common/pkg/log"
+	"github.com/hyperledger/firefly/pkg/blockchain"
+	"github.com/hyperledger/firefly/pkg/core"
+	"github.com/hyperledger/firefly/pkg/database"
+)
+
+// removeBlockchainEvent is called when the connector reports that an event it delivered previously has been
+// removed from the chain by a reorganization. The stored event is marked removed, the records that depend on
+// it are invalidated, and a blockchain_event_removed event is emitted.
+func (em *eventManager) removeBlockchainEvent(ctx context.Context, listenerID *fftypes.UUID, event *blockchain.Event, topic string, batchID *fftypes.UUID) error {
+	chainEvent, err := em.database.GetBlockchainEventByProtocolID(ctx, em.namespace.Name, listenerID, event.ProtocolID)
+	if err != nil {
+		return err
+	}
+	if chainEvent == nil {
+		log.L(ctx).Warnf("Ignoring removal of unknown blockchain event %s", event.ProtocolID)
+		return nil
+	}
+	if chainEvent.Removed {
+		log.L(ctx).Debugf("Ignoring duplicate removal of blockchain event %s", event.ProtocolID)
+		return nil
+	}
+
+	log.L(ctx).Infof("Blockchain event %s (%s) removed by a chain reorganization", chainEvent.ID, chainEvent.ProtocolID)
+	if err := em.txHelper.SetBlockchainEventRemoved(ctx, chainEvent, true); err != nil {
+		return err
+	}
+	if batchID != nil {
+		if err := em.invalidateBatchPins(ctx, batchID); err != nil {
+			return err
+		}
+	}
+	ffEvent := core.NewEvent(core.EventTypeBlockchainEventRemoved, chainEvent.Namespace, chainEvent.ID, chainEvent.TX.ID, topic)
+	return em.database.InsertEvent(ctx, ffEvent)
+}
+
+func (em *eventManager) invalidateBatchPins(ctx context.Context, batchID *fftypes.UUID) error {
+	fb := database.PinQueryFactory.NewFilter(ctx)
+	update := database.PinQueryFactory.NewUpdate(ctx).Set("invalidated", true)
+	return em.database.UpdatePins(ctx, em.namespace.Name, fb.Eq("batch", batchID), update)
+}
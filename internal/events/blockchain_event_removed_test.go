@@ -0,0 +1,195 @@
+// Copyright © 2024 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package events
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/hyperledger/firefly-common/pkg/fftypes"
+	"github.com/hyperledger/firefly/pkg/blockchain"
+	"github.com/hyperledger/firefly/pkg/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestContractEventRemoved(t *testing.T) {
+	em := newTestEventManager(t)
+	defer em.cleanup(t)
+
+	sub := &core.ContractListener{
+		Namespace: "ns1",
+		ID:        fftypes.NewUUID(),
+		Topic:     "topic1",
+	}
+	chainEvent := &core.BlockchainEvent{
+		ID:         fftypes.NewUUID(),
+		Namespace:  "ns1",
+		Listener:   sub.ID,
+		ProtocolID: "10/20/30",
+	}
+
+	em.mdi.On("GetContractListenerByBackendID", mock.Anything, "ns1", "sb-1").Return(sub, nil)
+	em.mdi.On("GetBlockchainEventByProtocolID", mock.Anything, "ns1", sub.ID, "10/20/30").Return(chainEvent, nil)
+	em.mth.On("SetBlockchainEventRemoved", mock.Anything, chainEvent, true).Return(nil)
+	em.mdi.On("InsertEvent", mock.Anything, mock.MatchedBy(func(e *core.Event) bool {
+		return e.Type == core.EventTypeBlockchainEventRemoved && e.Reference.Equals(chainEvent.ID) && e.Topic == "topic1"
+	})).Return(nil)
+
+	err := em.BlockchainEventBatch([]*blockchain.EventToDispatch{
+		{
+			Type: blockchain.EventTypeForListener,
+			ForListener: &blockchain.EventForListener{
+				ListenerID: "sb-1",
+				Event: &blockchain.Event{
+					BlockchainTXID: "0xabcd1234",
+					ProtocolID:     "10/20/30",
+					Name:           "Changed",
+					Removed:        true,
+				},
+			},
+		},
+	})
+	assert.NoError(t, err)
+}
+
+func TestBatchPinRemoved(t *testing.T) {
+	em := newTestEventManager(t)
+	defer em.cleanup(t)
+
+	batchPin := &blockchain.BatchPin{
+		TransactionID: fftypes.NewUUID(),
+		BatchID:       fftypes.NewUUID(),
+		Contexts:      []*fftypes.Bytes32{fftypes.NewRandB32()},
+		Event: blockchain.Event{
+			BlockchainTXID: "0x12345",
+			ProtocolID:     "10/20/30",
+			Removed:        true,
+		},
+	}
+	chainEvent := &core.BlockchainEvent{
+		ID:         fftypes.NewUUID(),
+		Namespace:  "ns1",
+		ProtocolID: "10/20/30",
+		TX: core.BlockchainTransactionRef{
+			ID: batchPin.TransactionID,
+		},
+	}
+
+	em.mdi.On("GetBlockchainEventByProtocolID", mock.Anything, "ns1", (*fftypes.UUID)(nil), "10/20/30").Return(chainEvent, nil)
+	em.mth.On("SetBlockchainEventRemoved", mock.Anything, chainEvent, true).Return(nil)
+	em.mdi.On("UpdatePins", mock.Anything, "ns1", mock.Anything, mock.Anything).Return(nil)
+	em.mdi.On("InsertEvent", mock.Anything, mock.MatchedBy(func(e *core.Event) bool {
+		return e.Type == core.EventTypeBlockchainEventRemoved && e.Reference.Equals(chainEvent.ID) &&
+			e.Transaction.Equals(batchPin.TransactionID) && e.Topic == core.SystemBatchPinTopic
+	})).Return(nil)
+
+	err := em.BlockchainEventBatch([]*blockchain.EventToDispatch{
+		{
+			Type: blockchain.EventTypeBatchPinComplete,
+			BatchPinComplete: &blockchain.BatchPinCompleteEvent{
+				Namespace: "ns1",
+				Batch:     batchPin,
+				SigningKey: &core.VerifierRef{
+					Type:  core.VerifierTypeEthAddress,
+					Value: "0xffffeeee",
+				},
+			},
+		},
+	})
+	assert.NoError(t, err)
+}
+
+func TestNetworkActionRemoved(t *testing.T) {
+	em := newTestEventManager(t)
+	defer em.cleanup(t)
+
+	err := em.BlockchainEventBatch([]*blockchain.EventToDispatch{
+		{
+			Type: blockchain.EventTypeNetworkAction,
+			NetworkAction: &blockchain.NetworkActionEvent{
+				Action:   "terminate",
+				Location: fftypes.JSONAnyPtr("{}"),
+				Event:    &blockchain.Event{ProtocolID: "0001", Removed: true},
+				SigningKey: &core.VerifierRef{
+					Type:  core.VerifierTypeEthAddress,
+					Value: "0x1234",
+				},
+			},
+		},
+	})
+	assert.NoError(t, err)
+}
+
+func TestRemoveBlockchainEventUnknown(t *testing.T) {
+	em := newTestEventManager(t)
+	defer em.cleanup(t)
+
+	em.mdi.On("GetBlockchainEventByProtocolID", mock.Anything, "ns1", (*fftypes.UUID)(nil), "10/20/30").Return(nil, nil)
+
+	err := em.removeBlockchainEvent(context.Background(), nil, &blockchain.Event{ProtocolID: "10/20/30"}, "topic1", nil)
+	assert.NoError(t, err)
+}
+
+func TestRemoveBlockchainEventAlreadyRemoved(t *testing.T) {
+	em := newTestEventManager(t)
+	defer em.cleanup(t)
+
+	em.mdi.On("GetBlockchainEventByProtocolID", mock.Anything, "ns1", (*fftypes.UUID)(nil), "10/20/30").Return(&core.BlockchainEvent{
+		ID:      fftypes.NewUUID(),
+		Removed: true,
+	}, nil)
+
+	err := em.removeBlockchainEvent(context.Background(), nil, &blockchain.Event{ProtocolID: "10/20/30"}, "topic1", nil)
+	assert.NoError(t, err)
+}
+
+func TestRemoveBlockchainEventGetFail(t *testing.T) {
+	em := newTestEventManager(t)
+	defer em.cleanup(t)
+
+	em.mdi.On("GetBlockchainEventByProtocolID", mock.Anything, "ns1", (*fftypes.UUID)(nil), "10/20/30").Return(nil, fmt.Errorf("pop"))
+
+	err := em.removeBlockchainEvent(context.Background(), nil, &blockchain.Event{ProtocolID: "10/20/30"}, "topic1", nil)
+	assert.EqualError(t, err, "pop")
+}
+
+func TestRemoveBlockchainEventUpdateFail(t *testing.T) {
+	em := newTestEventManager(t)
+	defer em.cleanup(t)
+
+	chainEvent := &core.BlockchainEvent{ID: fftypes.NewUUID()}
+	em.mdi.On("GetBlockchainEventByProtocolID", mock.Anything, "ns1", (*fftypes.UUID)(nil), "10/20/30").Return(chainEvent, nil)
+	em.mth.On("SetBlockchainEventRemoved", mock.Anything, chainEvent, true).Return(fmt.Errorf("pop"))
+
+	err := em.removeBlockchainEvent(context.Background(), nil, &blockchain.Event{ProtocolID: "10/20/30"}, "topic1", nil)
+	assert.EqualError(t, err, "pop")
+}
+
+func TestRemoveBlockchainEventInvalidateFail(t *testing.T) {
+	em := newTestEventManager(t)
+	defer em.cleanup(t)
+
+	chainEvent := &core.BlockchainEvent{ID: fftypes.NewUUID()}
+	em.mdi.On("GetBlockchainEventByProtocolID", mock.Anything, "ns1", (*fftypes.UUID)(nil), "10/20/30").Return(chainEvent, nil)
+	em.mth.On("SetBlockchainEventRemoved", mock.Anything, chainEvent, true).Return(nil)
+	em.mdi.On("UpdatePins", mock.Anything, "ns1", mock.Anything, mock.Anything).Return(fmt.Errorf("pop"))
+
+	err := em.removeBlockchainEvent(context.Background(), nil, &blockchain.Event{ProtocolID: "10/20/30"}, "topic1", fftypes.NewUUID())
+	assert.EqualError(t, err, "pop")
+}
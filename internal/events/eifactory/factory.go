@@ -22,6 +22,8 @@ import (
 	"github.com/hyperledger/firefly-common/pkg/config"
 	"github.com/hyperledger/firefly-common/pkg/i18n"
 	"github.com/hyperledger/firefly/internal/coremsgs"
+	"github.com/hyperledger/firefly/internal/events/kafka"
+	"github.com/hyperledger/firefly/internal/events/sse"
 	"github.com/hyperledger/firefly/internal/events/system"
 	"github.com/hyperledger/firefly/internal/events/webhooks"
 	"github.com/hyperledger/firefly/internal/events/websockets"
@@ -32,6 +34,8 @@ var plugins = []events.Plugin{
 	&websockets.WebSockets{},
 	&webhooks.WebHooks{},
 	&system.Events{},
+	&kafka.Kafka{},
+	&sse.SSE{},
 }
 
 var pluginsByName = make(map[string]events.Plugin)
@@ -52,6 +52,20 @@ func TestGetPluginEvents(t *testing.T) {
 	assert.NotNil(t, plugin)
 }
 
+func TestGetPluginKafka(t *testing.T) {
+	ctx := context.Background()
+	plugin, err := GetPlugin(ctx, "kafka")
+	assert.NoError(t, err)
+	assert.NotNil(t, plugin)
+}
+
+func TestGetPluginSSE(t *testing.T) {
+	ctx := context.Background()
+	plugin, err := GetPlugin(ctx, "sse")
+	assert.NoError(t, err)
+	assert.NotNil(t, plugin)
+}
+
 var root = config.RootSection("di")
 
 func TestInitConfig(t *testing.T) {
@@ -33,6 +33,7 @@ import (
 	"github.com/hyperledger/firefly/internal/coremsgs"
 	"github.com/hyperledger/firefly/internal/data"
 	"github.com/hyperledger/firefly/internal/privatemessaging"
+	"github.com/hyperledger/firefly/internal/tracing"
 	"github.com/hyperledger/firefly/internal/txcommon"
 	"github.com/hyperledger/firefly/pkg/core"
 	"github.com/hyperledger/firefly/pkg/database"
@@ -48,6 +49,13 @@ type ackNack struct {
 	id     fftypes.UUID
 	isNack bool
 	offset int64
+	event  *core.Event
+	info   string
+}
+
+type deadLetterReplay struct {
+	deadLetter *core.DeadLetter
+	event      *core.Event
 }
 
 type eventDispatcher struct {
@@ -72,6 +80,12 @@ type eventDispatcher struct {
 	batch         bool
 	subscription  *subscription
 	txHelper      txcommon.Helper
+	attempts      map[fftypes.UUID]int
+	maxAttempts   int
+	retryBackoff  time.Duration
+	retryMaxDelay time.Duration
+	replays       map[fftypes.UUID]*deadLetterReplay
+	replayMux     sync.Mutex // serializes dead-letter replays with closing the eventDelivery channel
 }
 
 func newEventDispatcher(ctx context.Context, enricher *eventEnricher, ei events.Plugin, di database.Plugin, dm data.Manager, bm broadcast.Manager, pm privatemessaging.Manager, connID string, sub *subscription, en *eventNotifier, txHelper txcommon.Helper) *eventDispatcher {
@@ -89,6 +103,14 @@ func newEventDispatcher(ctx context.Context, enricher *eventEnricher, ei events.
 	if sub.definition.Options.Batch != nil {
 		batch = *sub.definition.Options.Batch
 	}
+	maxAttempts := 0
+	if sub.definition.Options.MaxAttempts != nil {
+		maxAttempts = int(*sub.definition.Options.MaxAttempts)
+	}
+	var retryBackoff time.Duration
+	if sub.definition.Options.RetryBackoff != nil && *sub.definition.Options.RetryBackoff != "" {
+		retryBackoff = fftypes.ParseToDuration(*sub.definition.Options.RetryBackoff)
+	}
 	ed := &eventDispatcher{
 		ctx: log.WithLogField(log.WithLogField(ctx,
 			"role", fmt.Sprintf("ed[%s]", connID)),
@@ -110,6 +132,11 @@ func newEventDispatcher(ctx context.Context, enricher *eventEnricher, ei events.
 		closed:        make(chan struct{}),
 		txHelper:      txHelper,
 		batch:         batch,
+		attempts:      make(map[fftypes.UUID]int),
+		maxAttempts:   maxAttempts,
+		retryBackoff:  retryBackoff,
+		retryMaxDelay: config.GetDuration(coreconfig.EventDispatcherRetryMaxDelay),
+		replays:       make(map[fftypes.UUID]*deadLetterReplay),
 	}
 
 	pollerConf := &eventPollerConf{
@@ -171,7 +198,9 @@ func (ed *eventDispatcher) electAndStart() {
 		return
 	}
 	// We're ready to go
+	ed.replayMux.Lock()
 	ed.elected = true
+	ed.replayMux.Unlock()
 	ed.eventPoller.start()
 
 	go ed.deliverEvents()
@@ -206,14 +235,21 @@ func (ed *eventDispatcher) enrichEvents(events []core.LocallySequenced) ([]*core
 	return enriched, nil
 }
 
-func (ed *eventDispatcher) filterEvents(candidates []*core.EventDelivery) []*core.EventDelivery {
+func (ed *eventDispatcher) filterEvents(candidates []*core.EventDelivery) ([]*core.EventDelivery, error) {
 	matchingEvents := make([]*core.EventDelivery, 0, len(candidates))
 	for _, event := range candidates {
-		if ed.subscription.MatchesEvent(&event.EnrichedEvent) {
+		if !ed.subscription.MatchesEvent(&event.EnrichedEvent) {
+			continue
+		}
+		matches, err := ed.subscription.MatchesExpression(ed.ctx, newExpressionActivation(ed.data, event))
+		if err != nil {
+			return nil, err
+		}
+		if matches {
 			matchingEvents = append(matchingEvents, event)
 		}
 	}
-	return matchingEvents
+	return matchingEvents, nil
 }
 
 func (ed *eventDispatcher) bufferedDelivery(events []core.LocallySequenced) (bool, error) {
@@ -227,6 +263,7 @@ func (ed *eventDispatcher) bufferedDelivery(events []core.LocallySequenced) (boo
 	highestOffset := events[len(events)-1].LocalSequence()
 	var lastAck int64
 	var nacks int
+	var nackAttempts int
 
 	l := log.L(ed.ctx)
 	candidates, err := ed.enrichEvents(events)
@@ -234,7 +271,10 @@ func (ed *eventDispatcher) bufferedDelivery(events []core.LocallySequenced) (boo
 		return false, err
 	}
 
-	matching := ed.filterEvents(candidates)
+	matching, err := ed.filterEvents(candidates)
+	if err != nil {
+		return false, err
+	}
 	matchCount := len(matching)
 	dispatched := 0
 
@@ -285,6 +325,15 @@ func (ed *eventDispatcher) bufferedDelivery(events []core.LocallySequenced) (boo
 		case <-ed.ctx.Done():
 			return false, i18n.NewError(ed.ctx, coremsgs.MsgDispatcherClosing)
 		case an := <-ed.acksNacks:
+			if an.isNack {
+				attempts := ed.recordFailedAttempt(an)
+				if ed.maxAttempts > 0 && attempts >= ed.maxAttempts && ed.deadLetter(an, attempts) {
+					// Continue past the event now it is in the dead-letter queue, as if it had been acked
+					an.isNack = false
+				} else if attempts > nackAttempts {
+					nackAttempts = attempts
+				}
+			}
 			if an.isNack {
 				nacks++
 				ed.handleNackOffsetUpdate(an)
@@ -297,9 +346,69 @@ func (ed *eventDispatcher) bufferedDelivery(events []core.LocallySequenced) (boo
 	if nacks == 0 && lastAck != highestOffset {
 		ed.eventPoller.commitOffset(highestOffset)
 	}
+	if nacks > 0 && ed.retryBackoff > 0 {
+		if err := ed.nackBackoff(nackAttempts); err != nil {
+			return false, err
+		}
+	}
 	return true, nil // poll again straight away for more messages
 }
 
+// recordFailedAttempt counts the failed deliveries of an event, when the subscription
+// has options that depend on the count
+func (ed *eventDispatcher) recordFailedAttempt(nack ackNack) int {
+	if ed.maxAttempts == 0 && ed.retryBackoff == 0 {
+		return 0
+	}
+	ed.mux.Lock()
+	defer ed.mux.Unlock()
+	ed.attempts[nack.id]++
+	return ed.attempts[nack.id]
+}
+
+// nackBackoff waits before redelivering rejected events, doubling the delay for each failed attempt
+func (ed *eventDispatcher) nackBackoff(attempts int) error {
+	delay := ed.retryBackoff
+	for i := 1; i < attempts && delay < ed.retryMaxDelay; i++ {
+		delay *= 2
+	}
+	if delay > ed.retryMaxDelay {
+		delay = ed.retryMaxDelay
+	}
+	log.L(ed.ctx).Debugf("Waiting %s before redelivery after %d failed attempts", delay, attempts)
+	select {
+	case <-time.After(delay):
+		return nil
+	case <-ed.ctx.Done():
+		return i18n.NewError(ed.ctx, coremsgs.MsgDispatcherClosing)
+	}
+}
+
+// deadLetter moves an event that has exhausted its delivery attempts into the dead-letter queue of the subscription.
+// If that fails, the event is treated as a normal nack and the move is attempted again on the next failure.
+func (ed *eventDispatcher) deadLetter(nack ackNack, attempts int) bool {
+	deadLetter := &core.DeadLetter{
+		ID:            fftypes.NewUUID(),
+		Namespace:     ed.namespace,
+		Subscription:  ed.subscription.definition.SubscriptionRef,
+		Event:         nack.event.ID,
+		EventSequence: nack.event.Sequence,
+		EventType:     nack.event.Type,
+		Attempts:      attempts,
+		LastError:     nack.info,
+		Created:       fftypes.Now(),
+	}
+	if err := ed.database.InsertDeadLetter(ed.ctx, deadLetter); err != nil {
+		log.L(ed.ctx).Errorf("Failed to move event %.10d/%s to the dead-letter queue after %d attempts: %s", nack.event.Sequence, nack.event.ID, attempts, err)
+		return false
+	}
+	log.L(ed.ctx).Warnf("Moved event %.10d/%s [%s] to the dead-letter queue as %s after %d failed attempts: %s", nack.event.Sequence, nack.event.ID, nack.event.Type, deadLetter.ID, attempts, nack.info)
+	ed.mux.Lock()
+	delete(ed.attempts, nack.id)
+	ed.mux.Unlock()
+	return true
+}
+
 func (ed *eventDispatcher) handleNackOffsetUpdate(nack ackNack) {
 	ed.mux.Lock()
 	defer ed.mux.Unlock()
@@ -317,6 +426,7 @@ func (ed *eventDispatcher) handleAckOffsetUpdate(ack ackNack) {
 	oldOffset := ed.eventPoller.getPollingOffset()
 	ed.mux.Lock()
 	delete(ed.inflight, ack.id)
+	delete(ed.attempts, ack.id)
 	lowestInflight := int64(-1)
 	for _, inflight := range ed.inflight {
 		if lowestInflight < 0 || inflight.Sequence < lowestInflight {
@@ -360,11 +470,17 @@ func (ed *eventDispatcher) deliverEvents() {
 				if !ed.batch {
 					// .. only attempt to deliver if we've not triggered into an error scenario for one of the events already
 					if err == nil {
-						err = ed.transport.DeliveryRequest(ed.ctx, ed.connID, ed.subscription.definition, e.Event, e.Data)
+						ctx, span := tracing.StartSpanFrom(ed.ctx, "event.deliver", eventTraceContexts(e),
+							tracing.NamespaceKey.String(ed.namespace),
+							tracing.SubscriptionKey.String(ed.subscription.definition.Name),
+							tracing.EventIDKey.String(e.Event.ID.String()),
+						)
+						err = ed.transport.DeliveryRequest(ctx, ed.connID, ed.subscription.definition, e.Event, e.Data)
+						tracing.EndSpan(span, err)
 					}
 					// ... if we've triggered into an error scenario, we need to nack immediately for this and all the rest of the events
 					if err != nil {
-						ed.deliveryResponse(&core.EventDeliveryResponse{ID: e.Event.ID, Rejected: true})
+						ed.deliveryResponse(&core.EventDeliveryResponse{ID: e.Event.ID, Rejected: true, Info: err.Error()})
 					}
 				}
 			}
@@ -373,12 +489,18 @@ func (ed *eventDispatcher) deliverEvents() {
 			if ed.batch {
 				// Only attempt to deliver if we're in a non error case (enrich might have failed above)
 				if err == nil {
-					err = ed.transport.BatchDeliveryRequest(ed.ctx, ed.connID, ed.subscription.definition, eventsWithData)
+					ctx, span := tracing.StartSpanFrom(ed.ctx, "event.deliverBatch", eventTraceContexts(eventsWithData...),
+						tracing.NamespaceKey.String(ed.namespace),
+						tracing.SubscriptionKey.String(ed.subscription.definition.Name),
+						tracing.EventCountKey.Int(len(eventsWithData)),
+					)
+					err = ed.transport.BatchDeliveryRequest(ctx, ed.connID, ed.subscription.definition, eventsWithData)
+					tracing.EndSpan(span, err)
 				}
 				// If we're in an error case we have to nack everything immediately
 				if err != nil {
 					for _, e := range events {
-						ed.deliveryResponse(&core.EventDeliveryResponse{ID: e.Event.ID, Rejected: true})
+						ed.deliveryResponse(&core.EventDeliveryResponse{ID: e.Event.ID, Rejected: true, Info: err.Error()})
 					}
 				}
 			}
@@ -389,16 +511,36 @@ func (ed *eventDispatcher) deliverEvents() {
 	}
 }
 
+// eventTraceContexts returns the trace contexts of the messages of the events, so their delivery
+// can be traced back to the requests that sent them
+func eventTraceContexts(events ...*core.CombinedEventDataDelivery) []fftypes.JSONObject {
+	traceContexts := make([]fftypes.JSONObject, 0, len(events))
+	for _, e := range events {
+		if e.Event.Message != nil {
+			traceContexts = append(traceContexts, e.Event.Message.TraceContext)
+		}
+	}
+	return traceContexts
+}
+
 func (ed *eventDispatcher) deliveryResponse(response *core.EventDeliveryResponse) {
 	l := log.L(ed.ctx)
 
 	ed.mux.Lock()
+	if replay, isReplay := ed.replays[*response.ID]; isReplay {
+		delete(ed.replays, *response.ID)
+		ed.mux.Unlock()
+		ed.replayResponse(replay, response)
+		return
+	}
 	var an ackNack
 	event, found := ed.inflight[*response.ID]
 	if found {
 		an.id = *response.ID
 		an.offset = event.Sequence
 		an.isNack = response.Rejected
+		an.event = event
+		an.info = response.Info
 	}
 	ed.mux.Unlock()
 
@@ -425,10 +567,80 @@ func (ed *eventDispatcher) deliveryResponse(response *core.EventDeliveryResponse
 	}
 }
 
+// replayDeadLetter delivers a dead-lettered event again, outside of the offset based flow of events
+// through the dispatcher. Returns false if this dispatcher is not delivering events for the subscription.
+func (ed *eventDispatcher) replayDeadLetter(ctx context.Context, deadLetter *core.DeadLetter) (bool, error) {
+	ed.replayMux.Lock()
+	defer ed.replayMux.Unlock()
+	if !ed.elected || ed.ctx.Err() != nil {
+		return false, nil
+	}
+
+	event, err := ed.database.GetEventByID(ctx, ed.namespace, deadLetter.Event)
+	if err != nil {
+		return false, err
+	}
+	if event == nil {
+		return false, i18n.NewError(ctx, coremsgs.Msg404NoResult)
+	}
+	enrichedEvent, err := ed.enricher.enrichEvent(ctx, event)
+	if err != nil {
+		return false, err
+	}
+
+	ed.mux.Lock()
+	ed.replays[*event.ID] = &deadLetterReplay{
+		deadLetter: deadLetter,
+		event:      event,
+	}
+	ed.mux.Unlock()
+
+	log.L(ed.ctx).Infof("Replaying dead-lettered event %.10d/%s [%s] from %s", event.Sequence, event.ID, event.Type, deadLetter.ID)
+	select {
+	case ed.eventDelivery <- []*core.EventDelivery{{
+		EnrichedEvent: *enrichedEvent,
+		Subscription:  ed.subscription.definition.SubscriptionRef,
+		Replay:        true,
+	}}:
+		return true, nil
+	case <-ed.ctx.Done():
+		return false, i18n.NewError(ctx, coremsgs.MsgDispatcherClosing)
+	}
+}
+
+// replayResponse removes a successfully replayed event from the dead-letter queue, or records the failed attempt
+func (ed *eventDispatcher) replayResponse(replay *deadLetterReplay, response *core.EventDeliveryResponse) {
+	l := log.L(ed.ctx)
+	deadLetter := replay.deadLetter
+
+	if response.Reply != nil {
+		ed.sendReply(ed.ctx, replay.event, response.Reply)
+	}
+
+	if !response.Rejected {
+		l.Infof("Replay of dead-lettered event %s succeeded - removing %s", deadLetter.Event, deadLetter.ID)
+		if err := ed.database.DeleteDeadLetter(ed.ctx, ed.namespace, deadLetter.ID); err != nil {
+			l.Errorf("Failed to remove dead-letter %s after successful replay: %s", deadLetter.ID, err)
+		}
+		return
+	}
+
+	l.Warnf("Replay of dead-lettered event %s failed: %s", deadLetter.Event, response.Info)
+	update := database.DeadLetterQueryFactory.NewUpdate(ed.ctx).
+		Set("attempts", deadLetter.Attempts+1).
+		Set("lasterror", response.Info).
+		Set("updated", fftypes.Now())
+	if err := ed.database.UpdateDeadLetter(ed.ctx, ed.namespace, deadLetter.ID, update); err != nil {
+		l.Errorf("Failed to update dead-letter %s after failed replay: %s", deadLetter.ID, err)
+	}
+}
+
 func (ed *eventDispatcher) close() {
 	log.L(ed.ctx).Infof("Dispatcher closing for conn=%s subscription=%s", ed.connID, ed.subscription.definition.ID)
 	ed.cancelCtx()
 	<-ed.closed
+	ed.replayMux.Lock()
+	defer ed.replayMux.Unlock()
 	if ed.elected {
 		close(ed.eventDelivery)
 		ed.elected = false
@@ -196,7 +196,7 @@ func TestEventDispatcherReadAheadOutOfOrderAcks(t *testing.T) {
 	mdm := ed.data.(*datamocks.Manager)
 
 	eventDeliveries := make(chan *core.EventDelivery)
-	deliveryRequestMock := mei.On("DeliveryRequest", ed.ctx, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	deliveryRequestMock := mei.On("DeliveryRequest", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
 	deliveryRequestMock.RunFn = func(a mock.Arguments) {
 		eventDeliveries <- a.Get(3).(*core.EventDelivery)
 	}
@@ -392,7 +392,7 @@ func TestEventDispatcherBatchBased(t *testing.T) {
 	mdm := ed.data.(*datamocks.Manager)
 
 	eventDeliveries := make(chan []*core.CombinedEventDataDelivery)
-	deliveryRequestMock := mei.On("BatchDeliveryRequest", ed.ctx, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	deliveryRequestMock := mei.On("BatchDeliveryRequest", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
 	deliveryRequestMock.RunFn = func(a mock.Arguments) {
 		eventDeliveries <- a.Get(3).([]*core.CombinedEventDataDelivery)
 	}
@@ -494,7 +494,7 @@ func TestEventDispatcherBatchDispatchFail(t *testing.T) {
 	mdm := ed.data.(*datamocks.Manager)
 
 	eventDeliveries := make(chan []*core.CombinedEventDataDelivery)
-	deliveryRequestMock := mei.On("BatchDeliveryRequest", ed.ctx, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(fmt.Errorf("pop"))
+	deliveryRequestMock := mei.On("BatchDeliveryRequest", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(fmt.Errorf("pop"))
 	deliveryRequestMock.RunFn = func(a mock.Arguments) {
 		eventDeliveries <- a.Get(3).([]*core.CombinedEventDataDelivery)
 	}
@@ -578,6 +578,74 @@ func TestEnrichEventsFailGetBlockchainEvents(t *testing.T) {
 	assert.EqualError(t, err, "pop")
 }
 
+func TestFilterEventsExpression(t *testing.T) {
+
+	ef, err := newExpressionFilter(context.Background(), "event.message.header.tag == 'tag1' && event.subscription.name == 'sub1'")
+	assert.NoError(t, err)
+	sub := &subscription{
+		definition:       &core.Subscription{},
+		expressionFilter: ef,
+	}
+	ed, cancel := newTestEventDispatcher(sub)
+	defer cancel()
+
+	id1 := fftypes.NewUUID()
+	id2 := fftypes.NewUUID()
+	id3 := fftypes.NewUUID()
+	subRef := core.SubscriptionRef{Name: "sub1"}
+	matched, err := ed.filterEvents([]*core.EventDelivery{
+		{
+			EnrichedEvent: core.EnrichedEvent{
+				Event:   core.Event{ID: id1, Type: core.EventTypeMessageConfirmed},
+				Message: &core.Message{Header: core.MessageHeader{Tag: "tag1"}},
+			},
+			Subscription: subRef,
+		},
+		{
+			EnrichedEvent: core.EnrichedEvent{
+				Event:   core.Event{ID: id2, Type: core.EventTypeMessageConfirmed},
+				Message: &core.Message{Header: core.MessageHeader{Tag: "tag2"}},
+			},
+			Subscription: subRef,
+		},
+		{
+			EnrichedEvent: core.EnrichedEvent{
+				Event: core.Event{ID: id3, Type: core.EventTypeBlockchainEventReceived},
+			},
+			Subscription: subRef,
+		},
+	})
+	assert.NoError(t, err)
+	assert.Len(t, matched, 1)
+	assert.Equal(t, *id1, *matched[0].ID)
+}
+
+func TestFilterEventsExpressionDataFail(t *testing.T) {
+
+	ef, err := newExpressionFilter(context.Background(), "data.exists(d, d.value.amount > 10)")
+	assert.NoError(t, err)
+	sub := &subscription{
+		definition:       &core.Subscription{},
+		expressionFilter: ef,
+	}
+	ed, cancel := newTestEventDispatcher(sub)
+	defer cancel()
+
+	msg := &core.Message{Header: core.MessageHeader{ID: fftypes.NewUUID()}}
+	mdm := ed.data.(*datamocks.Manager)
+	mdm.On("GetMessageDataCached", mock.Anything, msg).Return(nil, false, fmt.Errorf("pop"))
+
+	_, err = ed.filterEvents([]*core.EventDelivery{
+		{
+			EnrichedEvent: core.EnrichedEvent{
+				Event:   core.Event{ID: fftypes.NewUUID(), Type: core.EventTypeMessageConfirmed},
+				Message: msg,
+			},
+		},
+	})
+	assert.EqualError(t, err, "pop")
+}
+
 func TestFilterEventsMatch(t *testing.T) {
 
 	sub := &subscription{
@@ -597,7 +665,7 @@ func TestFilterEventsMatch(t *testing.T) {
 	id5 := fftypes.NewUUID()
 	id6 := fftypes.NewUUID()
 	lid := fftypes.NewUUID()
-	events := ed.filterEvents([]*core.EventDelivery{
+	events, _ := ed.filterEvents([]*core.EventDelivery{
 		{
 			EnrichedEvent: core.EnrichedEvent{
 				Event: core.Event{
@@ -697,7 +765,8 @@ func TestFilterEventsMatch(t *testing.T) {
 	ed.subscription.topicFilter = regexp.MustCompile(".*")
 	ed.subscription.messageFilter.tagFilter = regexp.MustCompile(".*")
 	ed.subscription.messageFilter.groupFilter = regexp.MustCompile(".*")
-	matched := ed.filterEvents(events)
+	matched, err := ed.filterEvents(events)
+	assert.NoError(t, err)
 	assert.Equal(t, 2, len(matched))
 	assert.Equal(t, *id1, *matched[0].ID)
 	assert.Equal(t, *id2, *matched[1].ID)
@@ -707,7 +776,7 @@ func TestFilterEventsMatch(t *testing.T) {
 	ed.subscription.topicFilter = nil
 	ed.subscription.messageFilter.tagFilter = nil
 	ed.subscription.messageFilter.groupFilter = nil
-	matched = ed.filterEvents(events)
+	matched, _ = ed.filterEvents(events)
 	assert.Equal(t, 6, len(matched))
 	assert.Equal(t, *id1, *matched[0].ID)
 	assert.Equal(t, *id2, *matched[1].ID)
@@ -716,46 +785,46 @@ func TestFilterEventsMatch(t *testing.T) {
 	assert.Equal(t, *id5, *matched[4].ID)
 
 	ed.subscription.topicFilter = regexp.MustCompile("topic1")
-	matched = ed.filterEvents(events)
+	matched, _ = ed.filterEvents(events)
 	assert.Equal(t, 2, len(matched))
 	assert.Equal(t, *id1, *matched[0].ID)
 	assert.Equal(t, *id2, *matched[1].ID)
 
 	ed.subscription.topicFilter = nil
 	ed.subscription.messageFilter.tagFilter = regexp.MustCompile("tag2")
-	matched = ed.filterEvents(events)
+	matched, _ = ed.filterEvents(events)
 	assert.Equal(t, 1, len(matched))
 	assert.Equal(t, *id2, *matched[0].ID)
 
 	ed.subscription.topicFilter = nil
 	ed.subscription.messageFilter.authorFilter = nil
 	ed.subscription.messageFilter.groupFilter = regexp.MustCompile(gid1.String())
-	matched = ed.filterEvents(events)
+	matched, _ = ed.filterEvents(events)
 	assert.Equal(t, 1, len(matched))
 	assert.Equal(t, *id2, *matched[0].ID)
 
 	ed.subscription.messageFilter.groupFilter = regexp.MustCompile("^$")
-	matched = ed.filterEvents(events)
+	matched, _ = ed.filterEvents(events)
 	assert.Equal(t, 0, len(matched))
 
 	ed.subscription.messageFilter.groupFilter = nil
 	ed.subscription.topicFilter = nil
 	ed.subscription.messageFilter.tagFilter = nil
 	ed.subscription.messageFilter.authorFilter = regexp.MustCompile("org2")
-	matched = ed.filterEvents(events)
+	matched, _ = ed.filterEvents(events)
 	assert.Equal(t, 1, len(matched))
 	assert.Equal(t, *id2, *matched[0].ID)
 
 	ed.subscription.messageFilter = nil
 	ed.subscription.transactionFilter.typeFilter = regexp.MustCompile(fmt.Sprintf("^%s$", core.TransactionTypeBatchPin))
-	matched = ed.filterEvents(events)
+	matched, _ = ed.filterEvents(events)
 	assert.Equal(t, 1, len(matched))
 	assert.Equal(t, *id5, *matched[0].ID)
 
 	ed.subscription.messageFilter = nil
 	ed.subscription.transactionFilter = nil
 	ed.subscription.blockchainFilter.nameFilter = regexp.MustCompile("flapflip")
-	matched = ed.filterEvents(events)
+	matched, _ = ed.filterEvents(events)
 	assert.Equal(t, 1, len(matched))
 	assert.Equal(t, *id4, *matched[0].ID)
 
@@ -763,7 +832,7 @@ func TestFilterEventsMatch(t *testing.T) {
 	ed.subscription.transactionFilter = nil
 	ed.subscription.blockchainFilter.nameFilter = nil
 	ed.subscription.blockchainFilter.listenerFilter = regexp.MustCompile(lid.String())
-	matched = ed.filterEvents(events)
+	matched, _ = ed.filterEvents(events)
 	assert.Equal(t, 1, len(matched))
 	assert.Equal(t, *id6, *matched[0].ID)
 }
@@ -983,6 +1052,28 @@ func TestBufferedDeliveryEnrichFail(t *testing.T) {
 
 }
 
+func TestBufferedDeliveryFilterFail(t *testing.T) {
+
+	ef, err := newExpressionFilter(context.Background(), "size(data) > 0")
+	assert.NoError(t, err)
+	sub := &subscription{
+		definition:       &core.Subscription{},
+		expressionFilter: ef,
+	}
+	ed, cancel := newTestEventDispatcher(sub)
+	defer cancel()
+
+	msg := &core.Message{Header: core.MessageHeader{ID: fftypes.NewUUID()}}
+	mdm := ed.data.(*datamocks.Manager)
+	mdm.On("GetMessageWithDataCached", mock.Anything, msg.Header.ID).Return(msg, nil, true, nil)
+	mdm.On("GetMessageDataCached", mock.Anything, msg).Return(nil, false, fmt.Errorf("pop"))
+
+	repoll, err := ed.bufferedDelivery([]core.LocallySequenced{&core.Event{ID: fftypes.NewUUID(), Type: core.EventTypeMessageConfirmed, Reference: msg.Header.ID}})
+	assert.False(t, repoll)
+	assert.EqualError(t, err, "pop")
+
+}
+
 func TestBufferedDeliveryClosedContext(t *testing.T) {
 
 	sub := &subscription{
@@ -1303,3 +1394,307 @@ func TestEventDeliveryBatch(t *testing.T) {
 	mbm.AssertExpectations(t)
 	mms.AssertExpectations(t)
 }
+
+func TestBufferedDeliveryDeadLetterAfterMaxAttempts(t *testing.T) {
+
+	two := uint16(2)
+	sub := &subscription{
+		definition: &core.Subscription{
+			SubscriptionRef: core.SubscriptionRef{ID: fftypes.NewUUID(), Namespace: "ns1", Name: "sub1"},
+			Options: core.SubscriptionOptions{
+				SubscriptionCoreOptions: core.SubscriptionCoreOptions{
+					MaxAttempts: &two,
+				},
+			},
+		},
+	}
+	ed, cancel := newTestEventDispatcher(sub)
+	defer cancel()
+	go ed.deliverEvents()
+
+	mdi := ed.database.(*databasemocks.Plugin)
+	mei := ed.transport.(*eventsmocks.Plugin)
+	mdi.On("UpdateOffset", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil).Maybe()
+	mei.On("DeliveryRequest", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(fmt.Errorf("pop"))
+
+	ev1 := fftypes.NewUUID()
+	mdi.On("InsertDeadLetter", mock.Anything, mock.MatchedBy(func(dl *core.DeadLetter) bool {
+		return dl.Event.Equals(ev1) &&
+			dl.EventSequence == 100001 &&
+			dl.Attempts == 2 &&
+			dl.LastError == "pop" &&
+			dl.Subscription.Name == "sub1"
+	})).Return(nil)
+
+	// First attempt fails, and rewinds
+	ed.eventPoller.pollingOffset = 100000
+	repoll, err := ed.bufferedDelivery([]core.LocallySequenced{&core.Event{ID: ev1, Sequence: 100001}})
+	assert.NoError(t, err)
+	assert.True(t, repoll)
+	assert.Equal(t, int64(100000), ed.eventPoller.pollingOffset)
+	assert.Equal(t, 1, ed.attempts[*ev1])
+
+	// Second attempt fails, and the event is moved to the dead-letter queue so we move on
+	repoll, err = ed.bufferedDelivery([]core.LocallySequenced{&core.Event{ID: ev1, Sequence: 100001}})
+	assert.NoError(t, err)
+	assert.True(t, repoll)
+	assert.Equal(t, int64(100001), ed.eventPoller.pollingOffset)
+	assert.Empty(t, ed.attempts)
+
+	mdi.AssertExpectations(t)
+}
+
+func TestBufferedDeliveryDeadLetterInsertFail(t *testing.T) {
+
+	one := uint16(1)
+	sub := &subscription{
+		definition: &core.Subscription{
+			Options: core.SubscriptionOptions{
+				SubscriptionCoreOptions: core.SubscriptionCoreOptions{
+					MaxAttempts: &one,
+				},
+			},
+		},
+	}
+	ed, cancel := newTestEventDispatcher(sub)
+	defer cancel()
+	go ed.deliverEvents()
+
+	mdi := ed.database.(*databasemocks.Plugin)
+	mei := ed.transport.(*eventsmocks.Plugin)
+	mei.On("DeliveryRequest", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(fmt.Errorf("pop"))
+	mdi.On("InsertDeadLetter", mock.Anything, mock.Anything).Return(fmt.Errorf("bang"))
+
+	ev1 := fftypes.NewUUID()
+	ed.eventPoller.pollingOffset = 100000
+	repoll, err := ed.bufferedDelivery([]core.LocallySequenced{&core.Event{ID: ev1, Sequence: 100001}})
+	assert.NoError(t, err)
+	assert.True(t, repoll)
+	assert.Equal(t, int64(100000), ed.eventPoller.pollingOffset)
+	assert.Equal(t, 1, ed.attempts[*ev1])
+
+	mdi.AssertExpectations(t)
+}
+
+func TestBufferedDeliveryNackBackoff(t *testing.T) {
+
+	backoff := "1ms"
+	sub := &subscription{
+		definition: &core.Subscription{
+			Options: core.SubscriptionOptions{
+				SubscriptionCoreOptions: core.SubscriptionCoreOptions{
+					RetryBackoff: &backoff,
+				},
+			},
+		},
+	}
+	ed, cancel := newTestEventDispatcher(sub)
+	defer cancel()
+	go ed.deliverEvents()
+	assert.Equal(t, time.Millisecond, ed.retryBackoff)
+
+	mei := ed.transport.(*eventsmocks.Plugin)
+	mei.On("DeliveryRequest", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(fmt.Errorf("pop"))
+
+	ev1 := fftypes.NewUUID()
+	ed.eventPoller.pollingOffset = 100000
+	repoll, err := ed.bufferedDelivery([]core.LocallySequenced{&core.Event{ID: ev1, Sequence: 100001}})
+	assert.NoError(t, err)
+	assert.True(t, repoll)
+	repoll, err = ed.bufferedDelivery([]core.LocallySequenced{&core.Event{ID: ev1, Sequence: 100001}})
+	assert.NoError(t, err)
+	assert.True(t, repoll)
+	assert.Equal(t, 2, ed.attempts[*ev1])
+}
+
+func TestNackBackoffMaxDelay(t *testing.T) {
+
+	sub := &subscription{
+		definition: &core.Subscription{},
+	}
+	ed, cancel := newTestEventDispatcher(sub)
+	defer cancel()
+
+	ed.retryBackoff = 1 * time.Millisecond
+	ed.retryMaxDelay = 5 * time.Millisecond
+	err := ed.nackBackoff(100)
+	assert.NoError(t, err)
+}
+
+func TestNackBackoffClosed(t *testing.T) {
+
+	sub := &subscription{
+		definition: &core.Subscription{},
+	}
+	ed, cancel := newTestEventDispatcher(sub)
+	cancel()
+
+	ed.retryBackoff = 1 * time.Hour
+	ed.retryMaxDelay = 1 * time.Hour
+	err := ed.nackBackoff(1)
+	assert.Regexp(t, "FF10182", err)
+}
+
+func newTestDeadLetterReplay(t *testing.T) (*eventDispatcher, *core.DeadLetter, func()) {
+	sub := &subscription{
+		definition: &core.Subscription{
+			SubscriptionRef: core.SubscriptionRef{ID: fftypes.NewUUID(), Namespace: "ns1", Name: "sub1"},
+		},
+	}
+	ed, cancel := newTestEventDispatcher(sub)
+	ed.elected = true
+	return ed, &core.DeadLetter{
+		ID:           fftypes.NewUUID(),
+		Namespace:    "ns1",
+		Subscription: sub.definition.SubscriptionRef,
+		Event:        fftypes.NewUUID(),
+		Attempts:     3,
+	}, cancel
+}
+
+func TestReplayDeadLetterAck(t *testing.T) {
+	ed, dl, cancel := newTestDeadLetterReplay(t)
+	defer cancel()
+	go ed.deliverEvents()
+
+	mdi := ed.database.(*databasemocks.Plugin)
+	mei := ed.transport.(*eventsmocks.Plugin)
+	mdi.On("GetEventByID", mock.Anything, "ns1", dl.Event).Return(&core.Event{ID: dl.Event, Sequence: 12345}, nil)
+	mdi.On("DeleteDeadLetter", mock.Anything, "ns1", dl.ID).Return(fmt.Errorf("pop"))
+	delivered := make(chan struct{})
+	deliver := mei.On("DeliveryRequest", mock.Anything, mock.Anything, mock.Anything, mock.MatchedBy(func(e *core.EventDelivery) bool {
+		return e.ID.Equals(dl.Event) && e.Subscription.Name == "sub1" && e.Replay
+	}), mock.Anything).Return(nil)
+	deliver.RunFn = func(a mock.Arguments) {
+		close(delivered)
+	}
+
+	replayed, err := ed.replayDeadLetter(context.Background(), dl)
+	assert.NoError(t, err)
+	assert.True(t, replayed)
+
+	<-delivered
+	ed.deliveryResponse(&core.EventDeliveryResponse{ID: dl.Event})
+	assert.Empty(t, ed.replays)
+
+	mdi.AssertExpectations(t)
+}
+
+func TestReplayDeadLetterNackWithReply(t *testing.T) {
+	ed, dl, cancel := newTestDeadLetterReplay(t)
+	defer cancel()
+
+	mdi := ed.database.(*databasemocks.Plugin)
+	mdi.On("UpdateDeadLetter", mock.Anything, "ns1", dl.ID, mock.Anything).Return(fmt.Errorf("pop"))
+	mms := &syncasyncmocks.Sender{}
+	mbm := ed.broadcast.(*broadcastmocks.Manager)
+	mbm.On("NewBroadcast", mock.Anything).Return(mms)
+	mms.On("Send", mock.Anything).Return(nil)
+
+	// Simulate the transport having failed
+	ed.mux.Lock()
+	ed.replays[*dl.Event] = &deadLetterReplay{deadLetter: dl, event: &core.Event{ID: dl.Event, Namespace: "ns1"}}
+	ed.mux.Unlock()
+	ed.deliveryResponse(&core.EventDeliveryResponse{
+		ID:       dl.Event,
+		Rejected: true,
+		Info:     "bang",
+		Reply: &core.MessageInOut{
+			Message: core.Message{
+				Header: core.MessageHeader{
+					Type: core.MessageTypeBroadcast,
+				},
+			},
+		},
+	})
+
+	mdi.AssertExpectations(t)
+	mbm.AssertExpectations(t)
+}
+
+func TestReplayDeadLetterNotElected(t *testing.T) {
+	ed, dl, cancel := newTestDeadLetterReplay(t)
+	defer cancel()
+	ed.elected = false
+
+	replayed, err := ed.replayDeadLetter(context.Background(), dl)
+	assert.NoError(t, err)
+	assert.False(t, replayed)
+}
+
+func TestReplayDeadLetterGetEventFail(t *testing.T) {
+	ed, dl, cancel := newTestDeadLetterReplay(t)
+	defer cancel()
+
+	mdi := ed.database.(*databasemocks.Plugin)
+	mdi.On("GetEventByID", mock.Anything, "ns1", dl.Event).Return(nil, fmt.Errorf("pop"))
+
+	_, err := ed.replayDeadLetter(context.Background(), dl)
+	assert.EqualError(t, err, "pop")
+}
+
+func TestReplayDeadLetterEventNotFound(t *testing.T) {
+	ed, dl, cancel := newTestDeadLetterReplay(t)
+	defer cancel()
+
+	mdi := ed.database.(*databasemocks.Plugin)
+	mdi.On("GetEventByID", mock.Anything, "ns1", dl.Event).Return(nil, nil)
+
+	_, err := ed.replayDeadLetter(context.Background(), dl)
+	assert.Regexp(t, "FF10143", err)
+}
+
+func TestReplayDeadLetterEnrichFail(t *testing.T) {
+	ed, dl, cancel := newTestDeadLetterReplay(t)
+	defer cancel()
+
+	mdi := ed.database.(*databasemocks.Plugin)
+	mdm := ed.data.(*datamocks.Manager)
+	mdi.On("GetEventByID", mock.Anything, "ns1", dl.Event).Return(&core.Event{ID: dl.Event, Type: core.EventTypeMessageConfirmed}, nil)
+	mdm.On("GetMessageWithDataCached", mock.Anything, mock.Anything).Return(nil, nil, false, fmt.Errorf("pop"))
+
+	_, err := ed.replayDeadLetter(context.Background(), dl)
+	assert.EqualError(t, err, "pop")
+}
+
+func TestReplayDeadLetterClosing(t *testing.T) {
+	ed, dl, cancel := newTestDeadLetterReplay(t)
+	defer cancel()
+	ed.eventDelivery = make(chan []*core.EventDelivery) // nothing is reading
+
+	mdi := ed.database.(*databasemocks.Plugin)
+	mdi.On("GetEventByID", mock.Anything, "ns1", dl.Event).Return(&core.Event{ID: dl.Event}, nil).Run(func(args mock.Arguments) {
+		cancel()
+	})
+
+	_, err := ed.replayDeadLetter(context.Background(), dl)
+	assert.Regexp(t, "FF10182", err)
+}
+
+func TestBufferedDeliveryNackBackoffClosed(t *testing.T) {
+
+	backoff := "1h"
+	sub := &subscription{
+		definition: &core.Subscription{
+			Options: core.SubscriptionOptions{
+				SubscriptionCoreOptions: core.SubscriptionCoreOptions{
+					RetryBackoff: &backoff,
+				},
+			},
+		},
+	}
+	ed, cancel := newTestEventDispatcher(sub)
+	defer cancel()
+	ed.retryMaxDelay = 1 * time.Hour
+
+	// Nack the event, then close the dispatcher while we are backing off
+	go func() {
+		events := <-ed.eventDelivery
+		ed.acksNacks <- ackNack{id: *events[0].ID, isNack: true, offset: events[0].Sequence}
+		cancel()
+	}()
+
+	ev1 := fftypes.NewUUID()
+	_, err := ed.bufferedDelivery([]core.LocallySequenced{&core.Event{ID: ev1, Sequence: 100001}})
+	assert.Regexp(t, "FF10182", err)
+}
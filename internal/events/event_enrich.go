@@ -18,7 +18,9 @@ package events
 
 import (
 	"context"
+	"encoding/json"
 
+	"github.com/hyperledger/firefly-common/pkg/fftypes"
 	"github.com/hyperledger/firefly/internal/data"
 	"github.com/hyperledger/firefly/internal/operations"
 	"github.com/hyperledger/firefly/internal/txcommon"
@@ -74,7 +76,7 @@ func (em *eventEnricher) enrichEvent(ctx context.Context, event *core.Event) (*c
 			return nil, err
 		}
 		e.Message = msg
-	case core.EventTypeBlockchainEventReceived:
+	case core.EventTypeBlockchainEventReceived, core.EventTypeBlockchainEventRemoved:
 		be, err := em.txHelper.GetBlockchainEventByIDCached(ctx, event.Reference)
 		if err != nil {
 			return nil, err
@@ -122,6 +124,12 @@ func (em *eventEnricher) enrichEvent(ctx context.Context, event *core.Event) (*c
 			return nil, err
 		}
 		e.TokenTransfer = transfer
+	case core.EventTypeContractListenerBehind:
+		listener, err := em.database.GetContractListenerByID(ctx, em.namespace, event.Reference)
+		if err != nil {
+			return nil, err
+		}
+		e.ContractListener = listener
 	case core.EventTypeApprovalOpFailed,
 		core.EventTypeTransferOpFailed,
 		core.EventTypePoolOpFailed,
@@ -134,6 +142,22 @@ func (em *eventEnricher) enrichEvent(ctx context.Context, event *core.Event) (*c
 			return nil, err
 		}
 		e.Operation = operation
+		if event.Type == core.EventTypeBlockchainInvokeOpFailed && operation != nil {
+			e.ContractError = contractErrorFromOutput(operation.Output)
+		}
 	}
 	return e, nil
 }
+
+// contractErrorFromOutput returns the custom error decoded from a failed invocation, if there was one
+func contractErrorFromOutput(output fftypes.JSONObject) *core.ContractError {
+	errorJSON := output.GetObject("contractError")
+	if len(errorJSON) == 0 {
+		return nil
+	}
+	var contractError core.ContractError
+	if err := json.Unmarshal([]byte(errorJSON.String()), &contractError); err != nil {
+		return nil
+	}
+	return &contractError
+}
@@ -619,6 +619,125 @@ func TestEnrichTokenTransferConfirmedFail(t *testing.T) {
 	assert.EqualError(t, err, "pop")
 }
 
+func TestEnrichContractListenerBehind(t *testing.T) {
+	em := newTestEventEnricher()
+	ctx := context.Background()
+
+	// Setup the IDs
+	ref1 := fftypes.NewUUID()
+	ev1 := fftypes.NewUUID()
+
+	// Setup enrichment
+	mdi := em.database.(*databasemocks.Plugin)
+	mdi.On("GetContractListenerByID", mock.Anything, "ns1", ref1).Return(&core.ContractListener{
+		ID: ref1,
+	}, nil)
+
+	event := &core.Event{
+		ID:        ev1,
+		Type:      core.EventTypeContractListenerBehind,
+		Reference: ref1,
+	}
+
+	enriched, err := em.enrichEvent(ctx, event)
+	assert.NoError(t, err)
+	assert.Equal(t, ref1, enriched.ContractListener.ID)
+}
+
+func TestEnrichContractListenerBehindFail(t *testing.T) {
+	em := newTestEventEnricher()
+	ctx := context.Background()
+
+	// Setup the IDs
+	ref1 := fftypes.NewUUID()
+	ev1 := fftypes.NewUUID()
+
+	// Setup enrichment
+	mdi := em.database.(*databasemocks.Plugin)
+	mdi.On("GetContractListenerByID", mock.Anything, "ns1", ref1).Return(nil, fmt.Errorf("pop"))
+
+	event := &core.Event{
+		ID:        ev1,
+		Type:      core.EventTypeContractListenerBehind,
+		Reference: ref1,
+	}
+
+	_, err := em.enrichEvent(ctx, event)
+	assert.EqualError(t, err, "pop")
+}
+
+func TestEnrichBlockchainInvokeOpFailedContractError(t *testing.T) {
+	em := newTestEventEnricher()
+	ctx := context.Background()
+
+	// Setup the IDs
+	ref1 := fftypes.NewUUID()
+	ev1 := fftypes.NewUUID()
+
+	// Setup enrichment
+	mom := em.operations.(*operationmocks.Manager)
+	mom.On("GetOperationByIDCached", mock.Anything, ref1).Return(&core.Operation{
+		ID: ref1,
+		Output: fftypes.JSONObject{
+			"contractError": fftypes.JSONObject{
+				"name":   "InsufficientBalance",
+				"params": fftypes.JSONObject{"available": "10"},
+			},
+		},
+	}, nil)
+
+	event := &core.Event{
+		ID:        ev1,
+		Type:      core.EventTypeBlockchainInvokeOpFailed,
+		Reference: ref1,
+	}
+
+	enriched, err := em.enrichEvent(ctx, event)
+	assert.NoError(t, err)
+	assert.Equal(t, ref1, enriched.Operation.ID)
+	assert.Equal(t, "InsufficientBalance", enriched.ContractError.Name)
+	assert.Equal(t, "10", enriched.ContractError.Params.GetString("available"))
+}
+
+func TestEnrichBlockchainInvokeOpFailedNoContractError(t *testing.T) {
+	em := newTestEventEnricher()
+	ctx := context.Background()
+
+	// Setup the IDs
+	ref1 := fftypes.NewUUID()
+	ref2 := fftypes.NewUUID()
+
+	// Setup enrichment
+	mom := em.operations.(*operationmocks.Manager)
+	mom.On("GetOperationByIDCached", mock.Anything, ref1).Return(&core.Operation{
+		ID: ref1,
+	}, nil)
+	mom.On("GetOperationByIDCached", mock.Anything, ref2).Return(&core.Operation{
+		ID: ref2,
+		Output: fftypes.JSONObject{
+			"contractError": fftypes.JSONObject{
+				"name": 12345,
+			},
+		},
+	}, nil)
+
+	enriched, err := em.enrichEvent(ctx, &core.Event{
+		ID:        fftypes.NewUUID(),
+		Type:      core.EventTypeBlockchainInvokeOpFailed,
+		Reference: ref1,
+	})
+	assert.NoError(t, err)
+	assert.Nil(t, enriched.ContractError)
+
+	enriched, err = em.enrichEvent(ctx, &core.Event{
+		ID:        fftypes.NewUUID(),
+		Type:      core.EventTypeBlockchainInvokeOpFailed,
+		Reference: ref2,
+	})
+	assert.NoError(t, err)
+	assert.Nil(t, enriched.ContractError)
+}
+
 func TestEnrichOperationFail(t *testing.T) {
 	em := newTestEventEnricher()
 	ctx := context.Background()
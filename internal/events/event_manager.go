@@ -64,8 +64,12 @@ type EventManager interface {
 	EnrichEvents(ctx context.Context, events []*core.Event) ([]*core.EnrichedEvent, error)
 	FilterHistoricalEventsOnSubscription(ctx context.Context, events []*core.EnrichedEvent, sub *core.Subscription) ([]*core.EnrichedEvent, error)
 	QueueBatchRewind(batchID *fftypes.UUID)
+	ReplayDeadLetter(ctx context.Context, deadLetter *core.DeadLetter) error
 	ResolveTransportAndCapabilities(ctx context.Context, transportName string) (string, *events.Capabilities, error)
 	Start() error
+	StartAggregator()
+	StartConnectionlessDispatchers()
+	StopConnectionlessDispatchers()
 	WaitStop()
 
 	// Bound blockchain callbacks
@@ -184,13 +188,34 @@ func (em *eventManager) Start() (err error) {
 	err = em.subManager.start()
 	if err == nil {
 		if em.aggregator != nil {
-			em.aggregator.start()
 			em.blobReceiver.start()
+			if em.aggregator.forwarder != nil {
+				em.aggregator.forwarder.startForwarding()
+			}
 		}
 	}
 	return err
 }
 
+// StartAggregator starts processing pins. Only one replica sharing the database can run the aggregator
+func (em *eventManager) StartAggregator() {
+	if em.aggregator != nil {
+		em.aggregator.start()
+	}
+}
+
+// StartConnectionlessDispatchers starts delivering durable subscriptions on transports such as webhooks and Kafka,
+// where the destination is the same from every replica. Only one replica sharing the database can run these
+func (em *eventManager) StartConnectionlessDispatchers() {
+	em.subManager.startConnectionlessDispatchers()
+}
+
+// StopConnectionlessDispatchers stops delivering durable subscriptions on connectionless transports,
+// so that another replica can take over
+func (em *eventManager) StopConnectionlessDispatchers() {
+	em.subManager.stopConnectionlessDispatchers()
+}
+
 func (em *eventManager) NewEvents() chan<- int64 {
 	return em.newEventNotifier.newEvents
 }
@@ -228,7 +253,7 @@ func (em *eventManager) WaitStop() {
 		em.blobReceiver.stop()
 		em.blobReceiver = nil
 	}
-	if em.aggregator != nil {
+	if em.aggregator != nil && em.aggregator.started {
 		<-em.aggregator.eventPoller.closed
 	}
 }
@@ -281,6 +306,10 @@ func (em *eventManager) DeleteDurableSubscription(ctx context.Context, subDef *c
 	return em.database.DeleteSubscriptionByID(ctx, em.namespace.Name, subDef.ID)
 }
 
+func (em *eventManager) ReplayDeadLetter(ctx context.Context, deadLetter *core.DeadLetter) error {
+	return em.subManager.replayDeadLetter(ctx, deadLetter)
+}
+
 func (em *eventManager) AddSystemEventListener(ns string, el system.EventListener) error {
 	return em.internalEvents.AddListener(ns, el)
 }
@@ -320,7 +349,17 @@ func (em *eventManager) FilterHistoricalEventsOnSubscription(ctx context.Context
 
 	matchingEvents := []*core.EnrichedEvent{}
 	for _, event := range events {
-		if subscriptionDef.MatchesEvent(event) {
+		if !subscriptionDef.MatchesEvent(event) {
+			continue
+		}
+		matches, err := subscriptionDef.MatchesExpression(ctx, newExpressionActivation(em.data, &core.EventDelivery{
+			EnrichedEvent: *event,
+			Subscription:  sub.SubscriptionRef,
+		}))
+		if err != nil {
+			return nil, err
+		}
+		if matches {
 			matchingEvents = append(matchingEvents, event)
 		}
 	}
@@ -200,6 +200,7 @@ func TestStartStop(t *testing.T) {
 	em.mdi.On("GetPins", mock.Anything, "ns1", mock.Anything).Return([]*core.Pin{}, nil, nil)
 	em.mdi.On("GetSubscriptions", mock.Anything, mock.Anything, mock.Anything).Return([]*core.Subscription{}, nil, nil)
 	assert.NoError(t, em.Start())
+	em.StartAggregator()
 	em.NewEvents() <- 12345
 	em.NewPins() <- 12345
 	em.cancel()
@@ -373,6 +374,7 @@ func TestEmitSubscriptionEventsNoops(t *testing.T) {
 	}
 
 	assert.NoError(t, em.Start())
+	em.StartAggregator()
 
 	// Wait until the gets occur for these events, which will return nil
 	getSubCallReady <- true
@@ -751,6 +753,69 @@ func TestEventFilterOnSubscriptionMatchesEventType(t *testing.T) {
 	assert.Equal(t, 1, len(filteredEvents))
 }
 
+func TestEventFilterOnSubscriptionExpression(t *testing.T) {
+	em := newTestEventManager(t)
+	defer em.cleanup(t)
+
+	events := []*core.EnrichedEvent{
+		{
+			Event: core.Event{Type: core.EventTypeBlockchainEventReceived},
+			BlockchainEvent: &core.BlockchainEvent{
+				Output: fftypes.JSONObject{"value": "1000"},
+			},
+		},
+		{
+			Event: core.Event{Type: core.EventTypeBlockchainEventReceived},
+			BlockchainEvent: &core.BlockchainEvent{
+				Output: fftypes.JSONObject{"value": "10"},
+			},
+		},
+	}
+
+	subscription := &core.Subscription{
+		SubscriptionRef: core.SubscriptionRef{Name: "sub1"},
+		Filter: core.SubscriptionFilter{
+			Expression: "bigint(event.blockchainEvent.output.value) > 100 && event.subscription.name == 'sub1'",
+		},
+	}
+
+	filteredEvents, err := em.FilterHistoricalEventsOnSubscription(context.Background(), events, subscription)
+	assert.NoError(t, err)
+	assert.Len(t, filteredEvents, 1)
+	assert.Equal(t, "1000", filteredEvents[0].BlockchainEvent.Output.GetString("value"))
+
+	subscription.Filter.Expression = "event.blockchainEvent.output.value =="
+	_, err = em.FilterHistoricalEventsOnSubscription(context.Background(), events, subscription)
+	assert.Regexp(t, "FF10489", err)
+}
+
+func TestEventFilterOnSubscriptionExpressionDataFail(t *testing.T) {
+	em := newTestEventManager(t)
+	defer em.cleanup(t)
+
+	msg := &core.Message{Header: core.MessageHeader{ID: fftypes.NewUUID()}}
+	em.mdm.On("GetMessageDataCached", mock.Anything, msg).Return(nil, false, fmt.Errorf("pop"))
+
+	events := []*core.EnrichedEvent{
+		{
+			Event: core.Event{Type: core.EventTypeBlockchainEventReceived},
+		},
+		{
+			Event:   core.Event{Type: core.EventTypeMessageConfirmed},
+			Message: msg,
+		},
+	}
+	subscription := &core.Subscription{
+		Filter: core.SubscriptionFilter{
+			Events:     string(core.EventTypeMessageConfirmed),
+			Expression: "size(data) > 0",
+		},
+	}
+
+	_, err := em.FilterHistoricalEventsOnSubscription(context.Background(), events, subscription)
+	assert.EqualError(t, err, "pop")
+}
+
 func TestEventFilterOnSubscriptionFailsWithBadRegex(t *testing.T) {
 	em := newTestEventManager(t)
 	defer em.cleanup(t)
@@ -844,3 +909,15 @@ func TestEventFilterOnSubscriptionFailsWithBadRegex(t *testing.T) {
 	_, err = em.FilterHistoricalEventsOnSubscription(context.Background(), events, subscription)
 	assert.NotNil(t, err)
 }
+
+func TestEventManagerReplayDeadLetterNoDispatcher(t *testing.T) {
+	em := newTestEventManager(t)
+	defer em.cleanup(t)
+
+	err := em.ReplayDeadLetter(context.Background(), &core.DeadLetter{
+		ID:           fftypes.NewUUID(),
+		Subscription: core.SubscriptionRef{ID: fftypes.NewUUID(), Name: "sub1"},
+		Event:        fftypes.NewUUID(),
+	})
+	assert.Regexp(t, "FF10490", err)
+}
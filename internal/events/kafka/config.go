@@ -0,0 +1,68 @@
+// Copyright © 2024 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kafka
+
+import (
+	"github.com/hyperledger/firefly-common/pkg/config"
+	"github.com/hyperledger/firefly-common/pkg/fftls"
+)
+
+const (
+	defaultClientID              = "firefly"
+	defaultTransactionalIDPrefix = "firefly"
+	defaultTopic                 = "firefly_events"
+	defaultPartitionKey          = PartitionKeyTopic
+	defaultTransactionRetries    = 3
+	defaultTransactionRetryDelay = "250ms"
+)
+
+const (
+	// KafkaConfBrokers is the list of bootstrap brokers of the cluster
+	KafkaConfBrokers = "brokers"
+	// KafkaConfClientID is the client ID sent to the brokers
+	KafkaConfClientID = "clientId"
+	// KafkaConfTransactionalIDPrefix is the prefix of the transactional ID (and consumer group) used for each subscription
+	KafkaConfTransactionalIDPrefix = "transactionalIdPrefix"
+	// KafkaConfTopic is the broker topic events are written to, when a subscription does not specify one
+	KafkaConfTopic = "topic"
+	// KafkaConfPartitionKey is the event field used as the record key, when a subscription does not specify one
+	KafkaConfPartitionKey = "partitionKey"
+	// KafkaConfTransactionRetries is the number of times a transaction that failed is retried, before the delivery fails
+	KafkaConfTransactionRetries = "transactionRetries"
+	// KafkaConfTransactionRetryDelay is the delay before retrying a transaction that failed
+	KafkaConfTransactionRetryDelay = "transactionRetryDelay"
+	// KafkaConfAuthUsername is the username for SASL/PLAIN authentication with the brokers
+	KafkaConfAuthUsername = "username"
+	// KafkaConfAuthPassword is the password for SASL/PLAIN authentication with the brokers
+	KafkaConfAuthPassword = "password"
+)
+
+func (k *Kafka) InitConfig(config config.Section) {
+	config.AddKnownKey(KafkaConfBrokers)
+	config.AddKnownKey(KafkaConfClientID, defaultClientID)
+	config.AddKnownKey(KafkaConfTransactionalIDPrefix, defaultTransactionalIDPrefix)
+	config.AddKnownKey(KafkaConfTopic, defaultTopic)
+	config.AddKnownKey(KafkaConfPartitionKey, defaultPartitionKey)
+	config.AddKnownKey(KafkaConfTransactionRetries, defaultTransactionRetries)
+	config.AddKnownKey(KafkaConfTransactionRetryDelay, defaultTransactionRetryDelay)
+
+	authConfig := config.SubSection("auth")
+	authConfig.AddKnownKey(KafkaConfAuthUsername)
+	authConfig.AddKnownKey(KafkaConfAuthPassword)
+
+	fftls.InitTLSConfig(config.SubSection("tls"))
+}
@@ -0,0 +1,434 @@
+// Copyright © 2024 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kafka
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/IBM/sarama"
+	"github.com/hyperledger/firefly-common/pkg/config"
+	"github.com/hyperledger/firefly-common/pkg/fftls"
+	"github.com/hyperledger/firefly-common/pkg/fftypes"
+	"github.com/hyperledger/firefly-common/pkg/i18n"
+	"github.com/hyperledger/firefly-common/pkg/log"
+	"github.com/hyperledger/firefly/internal/coremsgs"
+	"github.com/hyperledger/firefly/pkg/core"
+	"github.com/hyperledger/firefly/pkg/events"
+)
+
+const (
+	// PartitionKeyTopic keys each record by the event topic, so all events on a topic land on one partition in order
+	PartitionKeyTopic = "topic"
+	// PartitionKeyGroup keys each record by the privacy group of the message, falling back to the topic
+	PartitionKeyGroup = "group"
+	// PartitionKeyNone writes records without a key, leaving the broker to spread them across partitions
+	PartitionKeyNone = "none"
+)
+
+// offsetPartition is the partition of the broker topic that the event offset of a subscription is committed
+// against, in the consumer group of the subscription. The records themselves can be on any partition.
+const offsetPartition = 0
+
+// Kafka is an event transport that writes events as records onto a broker topic, using a
+// transactional producer for each subscription.
+//
+// The records for an event (or batch) are written in a single transaction, which also commits
+// the sequence of the next event as the offset of a consumer group owned by the subscription.
+// Events are only acknowledged back to the subscription once the transaction has committed, and
+// events below the committed offset are acknowledged without being written again. So each event
+// is written exactly once, as seen by consumers that read with isolation.level=read_committed.
+type Kafka struct {
+	ctx                   context.Context
+	capabilities          *events.Capabilities
+	callbacks             callbacks
+	connID                string
+	brokers               []string
+	config                *sarama.Config
+	transactionalIDPrefix string
+	topic                 string
+	partitionKey          string
+	txnRetries            int
+	txnRetryDelay         time.Duration
+	producerLock          sync.Mutex
+	producers             map[string]*subscriptionProducer
+}
+
+type callbacks struct {
+	writeLock sync.Mutex
+	handlers  map[string]events.Callbacks
+}
+
+// subscriptionProducer writes the events of one subscription. The transactional ID fences any producer
+// for the same subscription on another replica, and is also the consumer group the event offset is committed to.
+type subscriptionProducer struct {
+	lock      sync.Mutex
+	id        string
+	producer  sarama.SyncProducer
+	admin     sarama.ClusterAdmin
+	committed map[string]int64
+	closed    bool
+}
+
+type eventPayload struct {
+	*core.EventDelivery
+	Data core.DataArray `json:"data,omitempty"`
+}
+
+type eventRecord struct {
+	key   *string
+	value []byte
+}
+
+func (k *Kafka) Name() string { return "kafka" }
+
+func (k *Kafka) Init(ctx context.Context, config config.Section) (err error) {
+	brokers := config.GetStringSlice(KafkaConfBrokers)
+	if len(brokers) == 0 {
+		return i18n.NewError(ctx, coremsgs.MsgMissingPluginConfig, config.Resolve(KafkaConfBrokers), "kafka")
+	}
+
+	sc := sarama.NewConfig()
+	sc.ClientID = config.GetString(KafkaConfClientID)
+	sc.Producer.Idempotent = true
+	sc.Producer.RequiredAcks = sarama.WaitForAll
+	sc.Producer.Return.Successes = true
+	sc.Producer.Return.Errors = true
+	sc.Net.MaxOpenRequests = 1
+	if sc.Net.TLS.Config, err = fftls.ConstructTLSConfig(ctx, config.SubSection("tls"), fftls.ClientType); err != nil {
+		return err
+	}
+	sc.Net.TLS.Enable = sc.Net.TLS.Config != nil
+	authConfig := config.SubSection("auth")
+	if username := authConfig.GetString(KafkaConfAuthUsername); username != "" {
+		sc.Net.SASL.Enable = true
+		sc.Net.SASL.Mechanism = sarama.SASLTypePlaintext
+		sc.Net.SASL.User = username
+		sc.Net.SASL.Password = authConfig.GetString(KafkaConfAuthPassword)
+	}
+
+	connID := fftypes.ShortID()
+	*k = Kafka{
+		ctx: log.WithLogField(ctx, "kafka", connID),
+		capabilities: &events.Capabilities{
+			BatchDelivery:  true,
+			Connectionless: true,
+		},
+		callbacks: callbacks{
+			handlers: make(map[string]events.Callbacks),
+		},
+		connID:                connID,
+		brokers:               brokers,
+		config:                sc,
+		transactionalIDPrefix: config.GetString(KafkaConfTransactionalIDPrefix),
+		topic:                 config.GetString(KafkaConfTopic),
+		partitionKey:          config.GetString(KafkaConfPartitionKey),
+		txnRetries:            config.GetInt(KafkaConfTransactionRetries),
+		txnRetryDelay:         config.GetDuration(KafkaConfTransactionRetryDelay),
+		producers:             make(map[string]*subscriptionProducer),
+	}
+	return nil
+}
+
+func (k *Kafka) SetHandler(namespace string, handler events.Callbacks) error {
+	k.callbacks.writeLock.Lock()
+	defer k.callbacks.writeLock.Unlock()
+	if handler == nil {
+		delete(k.callbacks.handlers, namespace)
+		return nil
+	}
+	k.callbacks.handlers[namespace] = handler
+	// We have a single logical connection to the broker, that matches all subscriptions
+	return handler.RegisterConnection(k.connID, func(sr core.SubscriptionRef) bool { return true })
+}
+
+func (k *Kafka) Capabilities() *events.Capabilities {
+	return k.capabilities
+}
+
+// target resolves the topic and partitioning for a subscription, from its transport options
+// with a fallback to the plugin configuration
+func (k *Kafka) target(ctx context.Context, options fftypes.JSONObject) (topic, partitionKey string, err error) {
+	topic = options.GetString("topic")
+	if topic == "" {
+		topic = k.topic
+	}
+	if topic == "" {
+		return "", "", i18n.NewError(ctx, coremsgs.MsgKafkaTopicEmpty)
+	}
+	partitionKey = options.GetString("partitionKey")
+	if partitionKey == "" {
+		partitionKey = k.partitionKey
+	}
+	switch partitionKey {
+	case PartitionKeyTopic, PartitionKeyGroup, PartitionKeyNone:
+	default:
+		return "", "", i18n.NewError(ctx, coremsgs.MsgKafkaInvalidPartitionKey, partitionKey,
+			strings.Join([]string{PartitionKeyTopic, PartitionKeyGroup, PartitionKeyNone}, ","))
+	}
+	return topic, partitionKey, nil
+}
+
+func (k *Kafka) ValidateOptions(ctx context.Context, options *core.SubscriptionOptions) error {
+	_, _, err := k.target(ctx, options.TransportOptions())
+	return err
+}
+
+func recordKey(partitionKey string, event *core.EventDelivery) *string {
+	var key string
+	switch partitionKey {
+	case PartitionKeyNone:
+		return nil
+	case PartitionKeyGroup:
+		if event.Message != nil && event.Message.Header.Group != nil {
+			key = event.Message.Header.Group.String()
+		}
+	}
+	if key == "" {
+		key = event.Topic
+	}
+	if key == "" {
+		return nil
+	}
+	return &key
+}
+
+// getProducer returns the producer for a subscription, connecting it to the brokers if there is not one already
+func (k *Kafka) getProducer(ctx context.Context, sub *core.Subscription) (*subscriptionProducer, error) {
+	k.producerLock.Lock()
+	defer k.producerLock.Unlock()
+	if sp, ok := k.producers[sub.ID.String()]; ok {
+		return sp, nil
+	}
+
+	id := k.transactionalIDPrefix + "-" + sub.ID.String()
+	sc := *k.config
+	sc.Producer.Transaction.ID = id
+	client, err := sarama.NewClient(k.brokers, &sc)
+	if err != nil {
+		return nil, i18n.NewError(ctx, coremsgs.MsgKafkaBrokerErr, err)
+	}
+	producer, err := sarama.NewSyncProducerFromClient(client)
+	if err != nil {
+		_ = client.Close()
+		return nil, i18n.NewError(ctx, coremsgs.MsgKafkaBrokerErr, err)
+	}
+	admin, err := sarama.NewClusterAdminFromClient(client)
+	if err != nil {
+		_ = producer.Close()
+		_ = client.Close()
+		return nil, i18n.NewError(ctx, coremsgs.MsgKafkaBrokerErr, err)
+	}
+	log.L(ctx).Infof("Kafka producer %s connected for subscription %s", id, sub.ID)
+	sp := &subscriptionProducer{
+		id:        id,
+		producer:  producer,
+		admin:     admin,
+		committed: make(map[string]int64),
+	}
+	k.producers[sub.ID.String()] = sp
+	return sp, nil
+}
+
+// closeProducer disconnects a producer that has failed, so a new one is connected (and initialized with
+// the brokers) on the next delivery
+func (k *Kafka) closeProducer(ctx context.Context, sp *subscriptionProducer) {
+	k.producerLock.Lock()
+	defer k.producerLock.Unlock()
+	for subID, p := range k.producers {
+		if p == sp {
+			delete(k.producers, subID)
+		}
+	}
+	sp.closed = true
+	if err := sp.producer.Close(); err != nil {
+		log.L(ctx).Warnf("Failed to close Kafka producer %s: %s", sp.id, err)
+	}
+	_ = sp.admin.Close() // also closes the client
+}
+
+// nextSequence returns the sequence of the first event that has not been written to a topic by the subscription,
+// from the offset committed to its consumer group by the last transaction - or -1 if nothing has been committed
+func (sp *subscriptionProducer) nextSequence(ctx context.Context, topic string) (int64, error) {
+	if next, ok := sp.committed[topic]; ok {
+		return next, nil
+	}
+	res, err := sp.admin.ListConsumerGroupOffsets(sp.id, map[string][]int32{topic: {offsetPartition}})
+	if err == nil && res.Err != sarama.ErrNoError {
+		err = res.Err
+	}
+	next := int64(-1)
+	if err == nil {
+		if block := res.GetBlock(topic, offsetPartition); block != nil {
+			if block.Err != sarama.ErrNoError {
+				err = block.Err
+			}
+			next = block.Offset
+		}
+	}
+	if err != nil {
+		return -1, i18n.NewError(ctx, coremsgs.MsgKafkaOffsetFetchFailed, sp.id, topic, err)
+	}
+	sp.committed[topic] = next
+	return next, nil
+}
+
+func (k *Kafka) produce(ctx context.Context, sub *core.Subscription, events []*core.CombinedEventDataDelivery) error {
+	topic, partitionKey, err := k.target(ctx, sub.Options.TransportOptions())
+	if err != nil {
+		return err
+	}
+	sp, err := k.getProducer(ctx, sub)
+	if err != nil {
+		return err
+	}
+	sp.lock.Lock()
+	defer sp.lock.Unlock()
+
+	next, err := sp.nextSequence(ctx, topic)
+	if err != nil {
+		return err
+	}
+	records := make([]*eventRecord, 0, len(events))
+	// A replayed dead-letter is always written, and does not move the committed offset
+	commitSequence := next
+	for _, e := range events {
+		if !e.Event.Replay && e.Event.Sequence < next {
+			// A transaction committed this event, but the replica that wrote it stopped before acknowledging it
+			log.L(ctx).Debugf("Kafka event %s (sequence=%d) already written to topic %s", e.Event.ID, e.Event.Sequence, topic)
+			continue
+		}
+		value, err := json.Marshal(&eventPayload{
+			EventDelivery: e.Event,
+			Data:          e.Data,
+		})
+		if err != nil {
+			return err
+		}
+		records = append(records, &eventRecord{key: recordKey(partitionKey, e.Event), value: value})
+		if !e.Event.Replay && e.Event.Sequence >= commitSequence {
+			commitSequence = e.Event.Sequence + 1
+		}
+	}
+	if len(records) == 0 {
+		return nil
+	}
+
+	// A failed transaction is aborted, so none of its records are visible to consumers, and the whole
+	// transaction is retried
+	for attempt := 0; ; attempt++ {
+		err := k.writeTransaction(ctx, sp, topic, records, commitSequence)
+		if err == nil {
+			sp.committed[topic] = commitSequence
+			return nil
+		}
+		if attempt >= k.txnRetries || sp.closed {
+			// A closed producer might have committed the transaction, so the events are redelivered to a
+			// new producer, which reads the committed offset again
+			return err
+		}
+		log.L(ctx).Warnf("Kafka transaction on subscription %s failed (attempt=%d): %s", sub.ID, attempt, err)
+		select {
+		case <-time.After(k.txnRetryDelay):
+		case <-ctx.Done():
+			return i18n.NewError(ctx, coremsgs.MsgContextCanceled)
+		}
+	}
+}
+
+// writeTransaction writes the records, and commits the sequence of the next event as the offset of the
+// consumer group of the subscription, in a single transaction. No offset is committed if it is negative,
+// which is only the case when the records are replays and nothing has been committed before.
+func (k *Kafka) writeTransaction(ctx context.Context, sp *subscriptionProducer, topic string, records []*eventRecord, nextSequence int64) error {
+	log.L(ctx).Debugf("Kafka-> %s %d records with producer %s", topic, len(records), sp.id)
+	msgs := make([]*sarama.ProducerMessage, len(records))
+	for i, r := range records {
+		msgs[i] = &sarama.ProducerMessage{
+			Topic: topic,
+			Value: sarama.ByteEncoder(r.value),
+		}
+		if r.key != nil {
+			msgs[i].Key = sarama.StringEncoder(*r.key)
+		}
+	}
+
+	err := sp.producer.BeginTxn()
+	if err == nil {
+		err = sp.producer.SendMessages(msgs)
+	}
+	if err == nil && nextSequence >= 0 {
+		err = sp.producer.AddOffsetsToTxn(map[string][]*sarama.PartitionOffsetMetadata{
+			topic: {{Partition: offsetPartition, Offset: nextSequence}},
+		}, sp.id)
+	}
+	if err == nil {
+		err = sp.producer.CommitTxn()
+	}
+	if err != nil {
+		status := sp.producer.TxnStatus()
+		if status&sarama.ProducerTxnFlagFatalError == 0 && status&sarama.ProducerTxnFlagReady == 0 {
+			if abortErr := sp.producer.AbortTxn(); abortErr != nil {
+				log.L(ctx).Errorf("Failed to abort Kafka transaction with producer %s: %s", sp.id, abortErr)
+				status |= sarama.ProducerTxnFlagFatalError
+			}
+		}
+		if status&sarama.ProducerTxnFlagFatalError != 0 {
+			k.closeProducer(ctx, sp)
+		}
+		return i18n.NewError(ctx, coremsgs.MsgKafkaTransactionFailed, len(records), topic, err)
+	}
+	log.L(ctx).Debugf("Kafka<- %s %d records committed with producer %s", topic, len(records), sp.id)
+	return nil
+}
+
+func (k *Kafka) ack(connID string, sub *core.Subscription, events []*core.CombinedEventDataDelivery) {
+	if cb, ok := k.callbacks.handlers[sub.Namespace]; ok {
+		for _, e := range events {
+			cb.DeliveryResponse(connID, &core.EventDeliveryResponse{
+				ID:           e.Event.ID,
+				Rejected:     false,
+				Subscription: e.Event.Subscription,
+			})
+		}
+	}
+}
+
+func (k *Kafka) DeliveryRequest(ctx context.Context, connID string, sub *core.Subscription, event *core.EventDelivery, data core.DataArray) error {
+	events := []*core.CombinedEventDataDelivery{{Event: event, Data: data}}
+	if err := k.produce(ctx, sub, events); err != nil {
+		return err
+	}
+	k.ack(connID, sub, events)
+	return nil
+}
+
+func (k *Kafka) BatchDeliveryRequest(ctx context.Context, connID string, sub *core.Subscription, events []*core.CombinedEventDataDelivery) error {
+	// The whole batch is written in a single transaction, and is only acknowledged once the
+	// transaction has committed - otherwise the whole batch is redelivered
+	if err := k.produce(ctx, sub, events); err != nil {
+		return err
+	}
+	k.ack(connID, sub, events)
+	return nil
+}
+
+func (k *Kafka) NamespaceRestarted(ns string, startTime time.Time) {
+	// no-op
+}
@@ -0,0 +1,658 @@
+// Copyright © 2024 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kafka
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/IBM/sarama"
+	"github.com/hyperledger/firefly-common/pkg/config"
+	"github.com/hyperledger/firefly-common/pkg/fftls"
+	"github.com/hyperledger/firefly-common/pkg/fftypes"
+	"github.com/hyperledger/firefly/internal/coreconfig"
+	"github.com/hyperledger/firefly/mocks/eventsmocks"
+	"github.com/hyperledger/firefly/pkg/core"
+	"github.com/hyperledger/firefly/pkg/events"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+const mockPartitions = 3
+
+var mockTopics = []string{defaultTopic, "app1_events"}
+
+type mockRecord struct {
+	Key       *string
+	Value     *eventPayload
+	Partition int32
+}
+
+// mockBroker is an in-process Kafka broker, that acts as the partition leader and the transaction and
+// group coordinator. The records sent by the producers are captured with an interceptor.
+type mockBroker struct {
+	*sarama.MockBroker
+	t        *testing.T
+	lock     sync.Mutex
+	sent     []*sarama.ProducerMessage
+	handlers map[string]sarama.MockResponse
+}
+
+func newMockBroker(t *testing.T) *mockBroker {
+	mb := &mockBroker{
+		MockBroker: sarama.NewMockBroker(t, 1),
+		t:          t,
+	}
+	metadata := sarama.NewMockMetadataResponse(t).
+		SetBroker(mb.Addr(), mb.BrokerID()).
+		SetController(mb.BrokerID())
+	addPartitions := &sarama.AddPartitionsToTxnResponse{Errors: map[string][]*sarama.PartitionError{}}
+	for _, topic := range mockTopics {
+		for p := int32(0); p < mockPartitions; p++ {
+			metadata.SetLeader(topic, p, mb.BrokerID())
+			addPartitions.Errors[topic] = append(addPartitions.Errors[topic], &sarama.PartitionError{Partition: p, Err: sarama.ErrNoError})
+		}
+	}
+	mb.handlers = map[string]sarama.MockResponse{
+		"MetadataRequest":           metadata,
+		"FindCoordinatorRequest":    sarama.NewMockFindCoordinatorResponse(t),
+		"InitProducerIDRequest":     sarama.NewMockInitProducerIDResponse(t).SetProducerID(1000),
+		"AddPartitionsToTxnRequest": sarama.NewMockWrapper(addPartitions),
+		"ProduceRequest":            sarama.NewMockProduceResponse(t),
+		"AddOffsetsToTxnRequest":    sarama.NewMockWrapper(&sarama.AddOffsetsToTxnResponse{Err: sarama.ErrNoError}),
+		"TxnOffsetCommitRequest":    sarama.NewMockWrapper(&sarama.TxnOffsetCommitResponse{Topics: map[string][]*sarama.PartitionError{}}),
+		"EndTxnRequest":             sarama.NewMockWrapper(&sarama.EndTxnResponse{Err: sarama.ErrNoError}),
+		"OffsetFetchRequest":        sarama.NewMockOffsetFetchResponse(t),
+	}
+	mb.SetHandlerByMap(mb.handlers)
+	return mb
+}
+
+func (mb *mockBroker) setHandler(request string, res sarama.MockResponse) {
+	mb.handlers[request] = res
+	mb.SetHandlerByMap(mb.handlers)
+}
+
+// coordinate makes the broker the transaction and group coordinator for a subscription, with the given
+// event offset committed to the group of the subscription
+func (mb *mockBroker) coordinate(sub *core.Subscription, committed int64) string {
+	id := defaultTransactionalIDPrefix + "-" + sub.ID.String()
+	mb.handlers["FindCoordinatorRequest"].(*sarama.MockFindCoordinatorResponse).
+		SetCoordinator(sarama.CoordinatorTransaction, id, mb.MockBroker).
+		SetCoordinator(sarama.CoordinatorGroup, id, mb.MockBroker)
+	offsets := sarama.NewMockOffsetFetchResponse(mb.t)
+	for _, topic := range mockTopics {
+		offsets.SetOffset(id, topic, offsetPartition, committed, "", sarama.ErrNoError)
+	}
+	mb.setHandler("OffsetFetchRequest", offsets)
+	return id
+}
+
+func (mb *mockBroker) OnSend(msg *sarama.ProducerMessage) {
+	mb.lock.Lock()
+	defer mb.lock.Unlock()
+	mb.sent = append(mb.sent, msg)
+}
+
+func (mb *mockBroker) records(topic string) []*mockRecord {
+	mb.lock.Lock()
+	defer mb.lock.Unlock()
+	records := []*mockRecord{}
+	for _, msg := range mb.sent {
+		if msg.Topic != topic {
+			continue
+		}
+		r := &mockRecord{Partition: msg.Partition}
+		if msg.Key != nil {
+			b, _ := msg.Key.Encode()
+			key := string(b)
+			r.Key = &key
+		}
+		b, _ := msg.Value.Encode()
+		err := json.Unmarshal(b, &r.Value)
+		assert.NoError(mb.t, err)
+		records = append(records, r)
+	}
+	return records
+}
+
+// transactions returns the result of each transaction that was ended, with true for a commit
+func (mb *mockBroker) transactions() []bool {
+	results := []bool{}
+	for _, rr := range mb.History() {
+		if req, ok := rr.Request.(*sarama.EndTxnRequest); ok {
+			results = append(results, req.TransactionResult)
+		}
+	}
+	return results
+}
+
+// committedOffsets returns the event offsets committed to the group of a subscription by transactions
+func (mb *mockBroker) committedOffsets(id, topic string) []int64 {
+	offsets := []int64{}
+	for _, rr := range mb.History() {
+		if req, ok := rr.Request.(*sarama.TxnOffsetCommitRequest); ok && req.GroupID == id {
+			for _, o := range req.Topics[topic] {
+				offsets = append(offsets, o.Offset)
+			}
+		}
+	}
+	return offsets
+}
+
+func newTestKafka(t *testing.T) (k *Kafka, mb *mockBroker, cbs *eventsmocks.Callbacks, done func()) {
+	coreconfig.Reset()
+	mb = newMockBroker(t)
+
+	cbs = &eventsmocks.Callbacks{}
+	rc := cbs.On("RegisterConnection", mock.Anything, mock.Anything).Return(nil)
+	rc.RunFn = func(a mock.Arguments) {
+		assert.Equal(t, true, a[1].(events.SubscriptionMatcher)(core.SubscriptionRef{}))
+	}
+	k = &Kafka{}
+	ctx, cancelCtx := context.WithCancel(context.Background())
+	conf := config.RootSection("ut.kafka")
+	k.InitConfig(conf)
+	conf.Set(KafkaConfBrokers, []string{mb.Addr()})
+	conf.Set(KafkaConfTransactionRetryDelay, "1ms")
+	err := k.Init(ctx, conf)
+	assert.NoError(t, err)
+	k.config.Producer.Interceptors = []sarama.ProducerInterceptor{mb}
+	k.config.Producer.Retry.Backoff = time.Millisecond
+	k.config.Producer.Transaction.Retry.Backoff = time.Millisecond
+	k.config.Metadata.Retry.Max = 0
+	err = k.SetHandler("ns1", cbs)
+	assert.NoError(t, err)
+	assert.Equal(t, "kafka", k.Name())
+	assert.True(t, k.Capabilities().BatchDelivery)
+	return k, mb, cbs, func() {
+		cancelCtx()
+		for _, sp := range k.producers {
+			k.closeProducer(k.ctx, sp)
+		}
+		mb.Close()
+		cbs.AssertExpectations(t)
+	}
+}
+
+func testSubscription(options map[string]interface{}) *core.Subscription {
+	sub := &core.Subscription{
+		SubscriptionRef: core.SubscriptionRef{
+			ID:        fftypes.NewUUID(),
+			Namespace: "ns1",
+			Name:      "sub1",
+		},
+	}
+	for k, v := range options {
+		sub.Options.TransportOptions()[k] = v
+	}
+	return sub
+}
+
+func testEvent(sub *core.Subscription, sequence int64, topic string, group *fftypes.Bytes32) *core.EventDelivery {
+	event := &core.EventDelivery{
+		EnrichedEvent: core.EnrichedEvent{
+			Event: core.Event{
+				ID:        fftypes.NewUUID(),
+				Sequence:  sequence,
+				Type:      core.EventTypeMessageConfirmed,
+				Namespace: "ns1",
+				Topic:     topic,
+			},
+		},
+		Subscription: sub.SubscriptionRef,
+	}
+	if group != nil {
+		event.Message = &core.Message{
+			Header: core.MessageHeader{
+				ID:    fftypes.NewUUID(),
+				Group: group,
+			},
+		}
+	}
+	return event
+}
+
+func expectAcks(cbs *eventsmocks.Callbacks, k *Kafka, evs ...*core.EventDelivery) {
+	for _, e := range evs {
+		eventID := e.ID
+		cbs.On("DeliveryResponse", k.connID, mock.MatchedBy(func(r *core.EventDeliveryResponse) bool {
+			return r.ID.Equals(eventID) && !r.Rejected
+		})).Return().Once()
+	}
+}
+
+func TestInitMissingBrokers(t *testing.T) {
+	coreconfig.Reset()
+	k := &Kafka{}
+	conf := config.RootSection("ut.kafka")
+	k.InitConfig(conf)
+	err := k.Init(context.Background(), conf)
+	assert.Regexp(t, "FF10138.*brokers", err)
+}
+
+func TestInitBadTLS(t *testing.T) {
+	coreconfig.Reset()
+	k := &Kafka{}
+	conf := config.RootSection("ut.kafka")
+	k.InitConfig(conf)
+	conf.Set(KafkaConfBrokers, []string{"localhost:9092"})
+	tlsConfig := conf.SubSection("tls")
+	tlsConfig.Set(fftls.HTTPConfTLSEnabled, true)
+	tlsConfig.Set(fftls.HTTPConfTLSCAFile, "BADCA")
+	err := k.Init(context.Background(), conf)
+	assert.Regexp(t, "FF00153", err)
+}
+
+func TestInitSASL(t *testing.T) {
+	coreconfig.Reset()
+	k := &Kafka{}
+	conf := config.RootSection("ut.kafka")
+	k.InitConfig(conf)
+	conf.Set(KafkaConfBrokers, []string{"localhost:9092"})
+	authConfig := conf.SubSection("auth")
+	authConfig.Set(KafkaConfAuthUsername, "user1")
+	authConfig.Set(KafkaConfAuthPassword, "pass1")
+	err := k.Init(context.Background(), conf)
+	assert.NoError(t, err)
+	assert.True(t, k.config.Net.SASL.Enable)
+	assert.Equal(t, "user1", k.config.Net.SASL.User)
+	assert.False(t, k.config.Net.TLS.Enable)
+	assert.NoError(t, k.config.Validate())
+}
+
+func TestSetHandlerRemove(t *testing.T) {
+	k, _, _, done := newTestKafka(t)
+	defer done()
+
+	err := k.SetHandler("ns1", nil)
+	assert.NoError(t, err)
+	assert.Empty(t, k.callbacks.handlers)
+	k.NamespaceRestarted("ns1", time.Now())
+}
+
+func TestValidateOptions(t *testing.T) {
+	k, _, _, done := newTestKafka(t)
+	defer done()
+
+	err := k.ValidateOptions(k.ctx, &core.SubscriptionOptions{})
+	assert.NoError(t, err)
+
+	err = k.ValidateOptions(k.ctx, &testSubscription(map[string]interface{}{
+		"topic":        "app1_events",
+		"partitionKey": PartitionKeyGroup,
+	}).Options)
+	assert.NoError(t, err)
+
+	err = k.ValidateOptions(k.ctx, &testSubscription(map[string]interface{}{
+		"partitionKey": "wrong",
+	}).Options)
+	assert.Regexp(t, "FF10481.*wrong", err)
+
+	k.topic = ""
+	err = k.ValidateOptions(k.ctx, &core.SubscriptionOptions{})
+	assert.Regexp(t, "FF10480", err)
+}
+
+func TestDeliveryRequestDefaultTopic(t *testing.T) {
+	k, mb, cbs, done := newTestKafka(t)
+	defer done()
+
+	sub := testSubscription(nil)
+	id := mb.coordinate(sub, -1)
+	event := testEvent(sub, 10, "topic1", nil)
+	expectAcks(cbs, k, event)
+
+	err := k.DeliveryRequest(k.ctx, k.connID, sub, event, core.DataArray{
+		{ID: fftypes.NewUUID(), Value: fftypes.JSONAnyPtr(`{"some":"data"}`)},
+	})
+	assert.NoError(t, err)
+
+	written := mb.records(defaultTopic)
+	assert.Len(t, written, 1)
+	assert.Equal(t, "topic1", *written[0].Key)
+	assert.Equal(t, event.ID, written[0].Value.ID)
+	assert.Equal(t, `{"some":"data"}`, written[0].Value.Data[0].Value.String())
+
+	// The record and the offset of the next event are committed in one transaction
+	assert.Equal(t, []bool{true}, mb.transactions())
+	assert.Equal(t, []int64{11}, mb.committedOffsets(id, defaultTopic))
+}
+
+func TestBatchDeliveryRequestPartitionByTopic(t *testing.T) {
+	k, mb, cbs, done := newTestKafka(t)
+	defer done()
+
+	sub := testSubscription(map[string]interface{}{
+		"topic": "app1_events",
+	})
+	id := mb.coordinate(sub, -1)
+	evs := []*core.CombinedEventDataDelivery{}
+	for i := 0; i < 10; i++ {
+		topic := "topic1"
+		if i%2 == 1 {
+			topic = "topic2"
+		}
+		e := testEvent(sub, int64(100+i), topic, nil)
+		expectAcks(cbs, k, e)
+		evs = append(evs, &core.CombinedEventDataDelivery{Event: e})
+	}
+
+	err := k.BatchDeliveryRequest(k.ctx, k.connID, sub, evs)
+	assert.NoError(t, err)
+
+	// Each topic lands on a single partition, in the order it was delivered
+	byTopic := map[string][]*fftypes.UUID{}
+	partitions := map[string]int32{}
+	for _, r := range mb.records("app1_events") {
+		if p, ok := partitions[*r.Key]; ok {
+			assert.Equal(t, p, r.Partition)
+		}
+		partitions[*r.Key] = r.Partition
+		byTopic[*r.Key] = append(byTopic[*r.Key], r.Value.ID)
+	}
+	assert.Len(t, byTopic["topic1"], 5)
+	assert.Len(t, byTopic["topic2"], 5)
+	for i, e := range evs {
+		assert.Equal(t, e.Event.ID, byTopic[e.Event.Topic][i/2])
+	}
+
+	// The whole batch is one transaction
+	assert.Equal(t, []bool{true}, mb.transactions())
+	assert.Equal(t, []int64{110}, mb.committedOffsets(id, "app1_events"))
+}
+
+func TestBatchDeliveryRequestPartitionByGroup(t *testing.T) {
+	k, mb, cbs, done := newTestKafka(t)
+	defer done()
+
+	sub := testSubscription(map[string]interface{}{
+		"topic":        "app1_events",
+		"partitionKey": PartitionKeyGroup,
+	})
+	mb.coordinate(sub, -1)
+	group := fftypes.NewRandB32()
+	e1 := testEvent(sub, 1, "topic1", group)
+	e2 := testEvent(sub, 2, "topic2", nil)
+	expectAcks(cbs, k, e1, e2)
+
+	err := k.BatchDeliveryRequest(k.ctx, k.connID, sub, []*core.CombinedEventDataDelivery{
+		{Event: e1}, {Event: e2},
+	})
+	assert.NoError(t, err)
+
+	keys := map[string]*fftypes.UUID{}
+	for _, r := range mb.records("app1_events") {
+		keys[*r.Key] = r.Value.ID
+	}
+	assert.Equal(t, e1.ID, keys[group.String()])
+	assert.Equal(t, e2.ID, keys["topic2"])
+}
+
+func TestBatchDeliveryRequestPartitionNone(t *testing.T) {
+	k, mb, cbs, done := newTestKafka(t)
+	defer done()
+
+	sub := testSubscription(map[string]interface{}{
+		"partitionKey": PartitionKeyNone,
+	})
+	mb.coordinate(sub, -1)
+	e1 := testEvent(sub, 1, "topic1", nil)
+	e2 := testEvent(sub, 2, "", nil)
+	expectAcks(cbs, k, e1, e2)
+
+	err := k.BatchDeliveryRequest(k.ctx, k.connID, sub, []*core.CombinedEventDataDelivery{
+		{Event: e1}, {Event: e2},
+	})
+	assert.NoError(t, err)
+
+	written := mb.records(defaultTopic)
+	assert.Len(t, written, 2)
+	for _, r := range written {
+		assert.Nil(t, r.Key)
+	}
+	assert.Nil(t, recordKey(PartitionKeyTopic, e2))
+}
+
+func TestBatchDeliveryRequestSkipsCommittedEvents(t *testing.T) {
+	k, mb, cbs, done := newTestKafka(t)
+	defer done()
+
+	// A previous transaction committed events up to sequence 11, but they were not acknowledged
+	sub := testSubscription(nil)
+	id := mb.coordinate(sub, 12)
+	e1 := testEvent(sub, 10, "topic1", nil)
+	e2 := testEvent(sub, 11, "topic1", nil)
+	e3 := testEvent(sub, 12, "topic1", nil)
+	expectAcks(cbs, k, e1, e2, e3)
+
+	err := k.BatchDeliveryRequest(k.ctx, k.connID, sub, []*core.CombinedEventDataDelivery{
+		{Event: e1}, {Event: e2}, {Event: e3},
+	})
+	assert.NoError(t, err)
+
+	written := mb.records(defaultTopic)
+	assert.Len(t, written, 1)
+	assert.Equal(t, e3.ID, written[0].Value.ID)
+	assert.Equal(t, []int64{13}, mb.committedOffsets(id, defaultTopic))
+
+	// Redelivering the same events does not write them again, or read the offset again
+	expectAcks(cbs, k, e1, e2, e3)
+	err = k.BatchDeliveryRequest(k.ctx, k.connID, sub, []*core.CombinedEventDataDelivery{
+		{Event: e1}, {Event: e2}, {Event: e3},
+	})
+	assert.NoError(t, err)
+	assert.Len(t, mb.records(defaultTopic), 1)
+	assert.Equal(t, []bool{true}, mb.transactions())
+}
+
+func TestDeliveryRequestReplaysDeadLetter(t *testing.T) {
+	k, mb, cbs, done := newTestKafka(t)
+	defer done()
+
+	// The subscription has moved past the dead-lettered event, and committed events up to sequence 11
+	sub := testSubscription(nil)
+	id := mb.coordinate(sub, 12)
+	replay := testEvent(sub, 5, "topic1", nil)
+	replay.Replay = true
+	redelivered := testEvent(sub, 11, "topic1", nil)
+	expectAcks(cbs, k, replay, redelivered)
+
+	err := k.BatchDeliveryRequest(k.ctx, k.connID, sub, []*core.CombinedEventDataDelivery{
+		{Event: redelivered}, {Event: replay},
+	})
+	assert.NoError(t, err)
+
+	// Only the replay is written, and the committed offset does not move backwards
+	written := mb.records(defaultTopic)
+	assert.Len(t, written, 1)
+	assert.Equal(t, replay.ID, written[0].Value.ID)
+	assert.Equal(t, []int64{12}, mb.committedOffsets(id, defaultTopic))
+}
+
+func TestDeliveryRequestReplayNothingCommitted(t *testing.T) {
+	k, mb, cbs, done := newTestKafka(t)
+	defer done()
+
+	sub := testSubscription(nil)
+	id := mb.coordinate(sub, -1)
+	replay := testEvent(sub, 5, "topic1", nil)
+	replay.Replay = true
+	expectAcks(cbs, k, replay)
+
+	err := k.DeliveryRequest(k.ctx, k.connID, sub, replay, nil)
+	assert.NoError(t, err)
+
+	written := mb.records(defaultTopic)
+	assert.Len(t, written, 1)
+	assert.Equal(t, replay.ID, written[0].Value.ID)
+	assert.Equal(t, []bool{true}, mb.transactions())
+	assert.Empty(t, mb.committedOffsets(id, defaultTopic))
+}
+
+func TestBatchDeliveryRequestAbortAndRetry(t *testing.T) {
+	k, mb, cbs, done := newTestKafka(t)
+	defer done()
+
+	sub := testSubscription(nil)
+	id := mb.coordinate(sub, -1)
+	failed := sarama.NewMockProduceResponse(t)
+	for p := int32(0); p < mockPartitions; p++ {
+		failed.SetError(defaultTopic, p, sarama.ErrTopicAuthorizationFailed)
+	}
+	mb.setHandler("ProduceRequest", sarama.NewMockSequence(failed, sarama.NewMockProduceResponse(t)))
+
+	e1 := testEvent(sub, 1, "topic1", nil)
+	e2 := testEvent(sub, 2, "topic1", nil)
+	expectAcks(cbs, k, e1, e2)
+	err := k.BatchDeliveryRequest(k.ctx, k.connID, sub, []*core.CombinedEventDataDelivery{
+		{Event: e1}, {Event: e2},
+	})
+	assert.NoError(t, err)
+
+	// The first transaction is aborted, so its records are not visible to consumers, and the whole batch
+	// is written again in a second transaction
+	assert.Equal(t, []bool{false, true}, mb.transactions())
+	assert.Equal(t, []int64{3}, mb.committedOffsets(id, defaultTopic))
+}
+
+func TestBatchDeliveryRequestRetriesExhausted(t *testing.T) {
+	k, mb, _, done := newTestKafka(t)
+	defer done()
+	k.txnRetries = 1
+
+	sub := testSubscription(nil)
+	id := mb.coordinate(sub, -1)
+	failed := sarama.NewMockProduceResponse(t)
+	for p := int32(0); p < mockPartitions; p++ {
+		failed.SetError(defaultTopic, p, sarama.ErrTopicAuthorizationFailed)
+	}
+	mb.setHandler("ProduceRequest", failed)
+
+	err := k.BatchDeliveryRequest(k.ctx, k.connID, sub, []*core.CombinedEventDataDelivery{
+		{Event: testEvent(sub, 1, "topic1", nil)},
+	})
+	assert.Regexp(t, "FF10482.*firefly_events", err)
+	assert.Equal(t, []bool{false, false}, mb.transactions())
+	assert.Empty(t, mb.committedOffsets(id, defaultTopic))
+}
+
+func TestBatchDeliveryRequestRetryCancelled(t *testing.T) {
+	k, mb, _, done := newTestKafka(t)
+	defer done()
+	k.txnRetryDelay = time.Minute
+
+	sub := testSubscription(nil)
+	mb.coordinate(sub, -1)
+	failed := sarama.NewMockProduceResponse(t)
+	for p := int32(0); p < mockPartitions; p++ {
+		failed.SetError(defaultTopic, p, sarama.ErrTopicAuthorizationFailed)
+	}
+	mb.setHandler("ProduceRequest", failed)
+
+	ctx, cancel := context.WithCancel(k.ctx)
+	cancel()
+	err := k.BatchDeliveryRequest(ctx, k.connID, sub, []*core.CombinedEventDataDelivery{
+		{Event: testEvent(sub, 1, "topic1", nil)},
+	})
+	assert.Regexp(t, "FF00154", err)
+}
+
+func TestBatchDeliveryRequestFatalErrorClosesProducer(t *testing.T) {
+	k, mb, _, done := newTestKafka(t)
+	defer done()
+
+	sub := testSubscription(nil)
+	id := mb.coordinate(sub, -1)
+	mb.setHandler("TxnOffsetCommitRequest", sarama.NewMockWrapper(&sarama.TxnOffsetCommitResponse{
+		Topics: map[string][]*sarama.PartitionError{
+			defaultTopic: {{Partition: offsetPartition, Err: sarama.ErrInvalidProducerEpoch}},
+		},
+	}))
+
+	err := k.BatchDeliveryRequest(k.ctx, k.connID, sub, []*core.CombinedEventDataDelivery{
+		{Event: testEvent(sub, 1, "topic1", nil)},
+	})
+	assert.Regexp(t, "FF10482", err)
+
+	// The transaction is not retried by a producer in a fatal state, and a new producer is used next time
+	assert.Equal(t, []int64{2}, mb.committedOffsets(id, defaultTopic))
+	assert.Empty(t, k.producers)
+}
+
+func TestDeliveryRequestOffsetFetchFail(t *testing.T) {
+	k, mb, _, done := newTestKafka(t)
+	defer done()
+
+	sub := testSubscription(nil)
+	id := mb.coordinate(sub, -1)
+	mb.setHandler("OffsetFetchRequest", sarama.NewMockOffsetFetchResponse(t).
+		SetOffset(id, defaultTopic, offsetPartition, -1, "", sarama.ErrGroupAuthorizationFailed))
+
+	err := k.DeliveryRequest(k.ctx, k.connID, sub, testEvent(sub, 1, "topic1", nil), nil)
+	assert.Regexp(t, "FF10483.*"+id, err)
+}
+
+func TestDeliveryRequestOffsetFetchGroupFail(t *testing.T) {
+	k, mb, _, done := newTestKafka(t)
+	defer done()
+
+	sub := testSubscription(nil)
+	id := mb.coordinate(sub, -1)
+	mb.setHandler("OffsetFetchRequest", sarama.NewMockOffsetFetchResponse(t).
+		SetError(sarama.ErrGroupAuthorizationFailed))
+
+	err := k.DeliveryRequest(k.ctx, k.connID, sub, testEvent(sub, 1, "topic1", nil), nil)
+	assert.Regexp(t, "FF10483.*"+id, err)
+}
+
+func TestDeliveryRequestBrokerUnavailable(t *testing.T) {
+	k, _, _, done := newTestKafka(t)
+	defer done()
+	k.brokers = []string{"127.0.0.1:1"}
+
+	sub := testSubscription(nil)
+	err := k.DeliveryRequest(k.ctx, k.connID, sub, testEvent(sub, 1, "topic1", nil), nil)
+	assert.Regexp(t, "FF10479", err)
+}
+
+func TestDeliveryRequestProducerInitFail(t *testing.T) {
+	k, mb, _, done := newTestKafka(t)
+	defer done()
+	k.config.Producer.Transaction.Retry.Max = 0
+
+	sub := testSubscription(nil)
+	mb.coordinate(sub, -1)
+	mb.setHandler("InitProducerIDRequest", sarama.NewMockInitProducerIDResponse(t).SetError(sarama.ErrTransactionalIDAuthorizationFailed))
+
+	err := k.DeliveryRequest(k.ctx, k.connID, sub, testEvent(sub, 1, "topic1", nil), nil)
+	assert.Regexp(t, "FF10479", err)
+}
+
+func TestDeliveryRequestBadOptions(t *testing.T) {
+	k, _, _, done := newTestKafka(t)
+	defer done()
+
+	sub := testSubscription(map[string]interface{}{
+		"partitionKey": "wrong",
+	})
+	err := k.DeliveryRequest(k.ctx, k.connID, sub, testEvent(sub, 1, "topic1", nil), nil)
+	assert.Regexp(t, "FF10481", err)
+}
@@ -34,6 +34,10 @@ func (em *eventManager) handleBlockchainNetworkAction(ctx context.Context, event
 		log.L(ctx).Errorf("Ignoring network action from non-multiparty network!")
 		return nil
 	}
+	if event.Event.Removed {
+		log.L(ctx).Warnf("Ignoring removal of network action %s, which cannot be reversed", event.Action)
+		return nil
+	}
 
 	// Verify that the action came from a registered root org
 	resolvedAuthor, err := em.identity.FindIdentityForVerifier(ctx, []core.IdentityType{core.IdentityTypeOrg}, event.SigningKey)
@@ -38,12 +38,12 @@ func TestNetworkAction(t *testing.T) {
 		Value: "0x1234",
 	}
 
-	em.mim.On("FindIdentityForVerifier", em.ctx, []core.IdentityType{core.IdentityTypeOrg}, verifier).Return(&core.Identity{}, nil)
-	em.mth.On("InsertNewBlockchainEvents", em.ctx, mock.MatchedBy(func(be []*core.BlockchainEvent) bool {
+	em.mim.On("FindIdentityForVerifier", mock.Anything, []core.IdentityType{core.IdentityTypeOrg}, verifier).Return(&core.Identity{}, nil)
+	em.mth.On("InsertNewBlockchainEvents", mock.Anything, mock.MatchedBy(func(be []*core.BlockchainEvent) bool {
 		return len(be) == 1 && be[0].ProtocolID == "0001"
 	})).Return([]*core.BlockchainEvent{{ID: fftypes.NewUUID()}}, nil)
-	em.mdi.On("InsertEvent", em.ctx, mock.Anything).Return(nil)
-	em.mmp.On("TerminateContract", em.ctx, location, mock.AnythingOfType("*blockchain.Event")).Return(nil)
+	em.mdi.On("InsertEvent", mock.Anything, mock.Anything).Return(nil)
+	em.mmp.On("TerminateContract", mock.Anything, location, mock.AnythingOfType("*blockchain.Event")).Return(nil)
 
 	err := em.BlockchainEventBatch([]*blockchain.EventToDispatch{
 		{
@@ -69,8 +69,8 @@ func TestNetworkActionUnknownIdentity(t *testing.T) {
 		Value: "0x1234",
 	}
 
-	em.mim.On("FindIdentityForVerifier", em.ctx, []core.IdentityType{core.IdentityTypeOrg}, verifier).Return(nil, fmt.Errorf("pop")).Once()
-	em.mim.On("FindIdentityForVerifier", em.ctx, []core.IdentityType{core.IdentityTypeOrg}, verifier).Return(nil, nil).Once()
+	em.mim.On("FindIdentityForVerifier", mock.Anything, []core.IdentityType{core.IdentityTypeOrg}, verifier).Return(nil, fmt.Errorf("pop")).Once()
+	em.mim.On("FindIdentityForVerifier", mock.Anything, []core.IdentityType{core.IdentityTypeOrg}, verifier).Return(nil, nil).Once()
 
 	err := em.BlockchainEventBatch([]*blockchain.EventToDispatch{
 		{
@@ -96,7 +96,7 @@ func TestNetworkActionNonRootIdentity(t *testing.T) {
 		Value: "0x1234",
 	}
 
-	em.mim.On("FindIdentityForVerifier", em.ctx, []core.IdentityType{core.IdentityTypeOrg}, verifier).Return(&core.Identity{
+	em.mim.On("FindIdentityForVerifier", mock.Anything, []core.IdentityType{core.IdentityTypeOrg}, verifier).Return(&core.Identity{
 		IdentityBase: core.IdentityBase{
 			Parent: fftypes.NewUUID(),
 		},
@@ -151,7 +151,7 @@ func TestNetworkActionUnknown(t *testing.T) {
 		Value: "0x1234",
 	}
 
-	em.mim.On("FindIdentityForVerifier", em.ctx, []core.IdentityType{core.IdentityTypeOrg}, verifier).Return(&core.Identity{}, nil)
+	em.mim.On("FindIdentityForVerifier", mock.Anything, []core.IdentityType{core.IdentityTypeOrg}, verifier).Return(&core.Identity{}, nil)
 
 	err := em.BlockchainEventBatch([]*blockchain.EventToDispatch{
 		{
@@ -0,0 +1,222 @@
+// Copyright © 2024 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sse
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/hyperledger/firefly-common/pkg/config"
+	"github.com/hyperledger/firefly-common/pkg/fftypes"
+	"github.com/hyperledger/firefly-common/pkg/i18n"
+	"github.com/hyperledger/firefly/internal/coremsgs"
+	"github.com/hyperledger/firefly/pkg/core"
+	"github.com/hyperledger/firefly/pkg/events"
+)
+
+type SSENamespaced interface {
+	ServeHTTPNamespaced(namespace string, res http.ResponseWriter, req *http.Request)
+}
+
+// SSE delivers events to clients that hold open a one-way text/event-stream HTTP response,
+// with acknowledgements sent back as separate HTTP requests
+type SSE struct {
+	ctx               context.Context
+	capabilities      *events.Capabilities
+	callbacks         callbacks
+	connections       map[string]*sseConnection
+	connMux           sync.Mutex
+	heartbeatInterval time.Duration
+	auth              core.Authorizer
+}
+
+type callbacks struct {
+	writeLock sync.Mutex
+	handlers  map[string]events.Callbacks
+}
+
+func (s *SSE) Name() string { return "sse" }
+
+func (s *SSE) Init(ctx context.Context, config config.Section) error {
+	*s = SSE{
+		ctx:         ctx,
+		connections: make(map[string]*sseConnection),
+		capabilities: &events.Capabilities{
+			BatchDelivery: true,
+		},
+		callbacks: callbacks{
+			handlers: make(map[string]events.Callbacks),
+		},
+		heartbeatInterval: config.GetDuration(HeartbeatInterval),
+	}
+	return nil
+}
+
+func (s *SSE) SetAuthorizer(auth core.Authorizer) {
+	s.auth = auth
+}
+
+func (s *SSE) SetHandler(namespace string, handler events.Callbacks) error {
+	s.callbacks.writeLock.Lock()
+	defer s.callbacks.writeLock.Unlock()
+	if handler == nil {
+		delete(s.callbacks.handlers, namespace)
+		return nil
+	}
+	s.callbacks.handlers[namespace] = handler
+	return nil
+}
+
+func (s *SSE) Capabilities() *events.Capabilities {
+	return s.capabilities
+}
+
+func (s *SSE) ValidateOptions(ctx context.Context, options *core.SubscriptionOptions) error {
+	// We don't support streaming the full data over SSE
+	if options.WithData != nil && *options.WithData {
+		return i18n.NewError(ctx, coremsgs.MsgSSENoData)
+	}
+	forceFalse := false
+	options.WithData = &forceFalse
+	return nil
+}
+
+func (s *SSE) getConnection(ctx context.Context, connID string) (*sseConnection, error) {
+	s.connMux.Lock()
+	conn, ok := s.connections[connID]
+	s.connMux.Unlock()
+	if !ok {
+		return nil, i18n.NewError(ctx, coremsgs.MsgSSEConnectionNotActive, connID)
+	}
+	return conn, nil
+}
+
+func (s *SSE) DeliveryRequest(ctx context.Context, connID string, sub *core.Subscription, event *core.EventDelivery, data core.DataArray) error {
+	conn, err := s.getConnection(ctx, connID)
+	if err != nil {
+		return err
+	}
+	return conn.dispatch(event)
+}
+
+func (s *SSE) BatchDeliveryRequest(ctx context.Context, connID string, sub *core.Subscription, events []*core.CombinedEventDataDelivery) error {
+	conn, err := s.getConnection(ctx, connID)
+	if err != nil {
+		return err
+	}
+	return conn.dispatchBatch(sub, events)
+}
+
+// ServeHTTPNamespaced holds open a text/event-stream response for the lifetime of the subscription
+// started from the query parameters of the request
+func (s *SSE) ServeHTTPNamespaced(namespace string, res http.ResponseWriter, req *http.Request) {
+	if s.auth != nil {
+		authReq := &fftypes.AuthReq{
+			Method:    req.Method,
+			URL:       req.URL,
+			Header:    req.Header,
+			Namespace: namespace,
+		}
+		authCtx := core.WithAuthContext(req.Context(), &core.AuthContext{EventStream: true, Subscription: req.URL.Query().Get("name")})
+		if err := s.auth.Authorize(authCtx, authReq); err != nil {
+			writeError(res, http.StatusUnauthorized, err)
+			return
+		}
+	}
+
+	start, err := parseStart(req.Context(), namespace, req)
+	if err != nil {
+		writeError(res, http.StatusBadRequest, err)
+		return
+	}
+
+	sc := newConnection(s.ctx, s, res, req, start)
+	s.connMux.Lock()
+	s.connections[sc.connID] = sc
+	s.connMux.Unlock()
+
+	sc.run()
+}
+
+// Ack processes an acknowledgement sent over HTTP, for an event or batch delivered on the stream
+// of a connection in the given namespace
+func (s *SSE) Ack(ctx context.Context, namespace, connID string, ack *core.SSEAck) error {
+	conn, err := s.getConnection(ctx, connID)
+	if err != nil {
+		return err
+	}
+	if conn.start.Namespace != namespace {
+		// Do not leak the existence of connections in other namespaces
+		return i18n.NewError(ctx, coremsgs.MsgSSEConnectionNotActive, connID)
+	}
+	return conn.handleAck(ctx, ack)
+}
+
+func (s *SSE) ack(connID string, inflight *core.EventDeliveryResponse) {
+	if cb, ok := s.callbacks.handlers[inflight.Subscription.Namespace]; ok {
+		cb.DeliveryResponse(connID, inflight)
+	}
+}
+
+func (s *SSE) start(sc *sseConnection) error {
+	start := sc.start
+	if cb, ok := s.callbacks.handlers[start.Namespace]; ok {
+		if start.Ephemeral {
+			return cb.EphemeralSubscription(sc.connID, start.Namespace, &start.Filter, &start.Options)
+		}
+		return cb.RegisterConnection(sc.connID, func(sr core.SubscriptionRef) bool {
+			return sr.Namespace == start.Namespace && sr.Name == start.Name
+		})
+	}
+	return i18n.NewError(s.ctx, coremsgs.MsgNamespaceDoesNotExist)
+}
+
+func (s *SSE) connClosed(connID string) {
+	s.connMux.Lock()
+	delete(s.connections, connID)
+	s.connMux.Unlock()
+	// Drop lock before calling back
+	for _, cb := range s.callbacks.handlers {
+		cb.ConnectionClosed(connID)
+	}
+}
+
+func (s *SSE) NamespaceRestarted(ns string, startTime time.Time) {
+	s.connMux.Lock()
+	connections := make([]*sseConnection, 0, len(s.connections))
+	for _, c := range s.connections {
+		connections = append(connections, c)
+	}
+	s.connMux.Unlock()
+
+	for _, sc := range connections {
+		sc.restartForNamespace(ns, startTime)
+	}
+}
+
+func writeError(res http.ResponseWriter, defaultStatus int, err error) {
+	status := defaultStatus
+	if ffe, ok := err.(i18n.FFError); ok && ffe.HTTPStatus() != http.StatusInternalServerError {
+		status = ffe.HTTPStatus()
+	}
+	res.Header().Set("Content-Type", "application/json")
+	res.WriteHeader(status)
+	_ = json.NewEncoder(res).Encode(&fftypes.RESTError{Error: err.Error()})
+}
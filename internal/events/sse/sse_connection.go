@@ -0,0 +1,384 @@
+// Copyright © 2024 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sse
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/hyperledger/firefly-common/pkg/fftypes"
+	"github.com/hyperledger/firefly-common/pkg/i18n"
+	"github.com/hyperledger/firefly-common/pkg/log"
+	"github.com/hyperledger/firefly/internal/coremsgs"
+	"github.com/hyperledger/firefly/pkg/core"
+)
+
+const (
+	// SSEConnectedEventType is the SSE event type of the first frame on every stream, carrying the connection ID for acks
+	SSEConnectedEventType = "connected"
+	// SSEBatchEventType is the SSE event type for a batch of events, when batching is enabled
+	SSEBatchEventType = "batch"
+	// SSEErrorEventType is the SSE event type sent before the stream is closed due to an error
+	SSEErrorEventType = "error"
+)
+
+// SSEConnected is the payload of the first frame on every stream
+type SSEConnected struct {
+	Connection string `json:"connection"`
+}
+
+type sseFrame struct {
+	id    string
+	event string
+	data  interface{}
+}
+
+type sseConnection struct {
+	ctx             context.Context
+	cancelCtx       func()
+	reqCtx          context.Context
+	s               *SSE
+	res             http.ResponseWriter
+	rc              *http.ResponseController
+	connID          string
+	start           *core.WSStart
+	startTime       time.Time
+	sendMessages    chan *sseFrame
+	inflight        []*core.EventDeliveryResponse
+	inflightBatches []*core.WSEventBatch
+	mux             sync.Mutex
+	closed          bool
+}
+
+func isBoolQuerySet(query url.Values, boolOption string) bool {
+	optionValues, hasOptionValues := query[boolOption]
+	return hasOptionValues && (len(optionValues) == 0 || optionValues[0] != "false")
+}
+
+// parseStart builds the subscription to start from the query parameters, using the same
+// parameters as the auto-start support of the websocket transport
+func parseStart(ctx context.Context, namespace string, req *http.Request) (*core.WSStart, error) {
+	query := req.URL.Query()
+	isEphemeral := isBoolQuerySet(query, "ephemeral")
+	name := query.Get("name")
+	if !isEphemeral && name == "" {
+		return nil, i18n.NewError(ctx, coremsgs.MsgSSEInvalidStart)
+	}
+	isAutoack := isBoolQuerySet(query, "autoack")
+	isBatch := isBoolQuerySet(query, "batch")
+	start := &core.WSStart{
+		AutoAck:   &isAutoack,
+		Ephemeral: isEphemeral,
+		Namespace: namespace,
+		Name:      name,
+		Filter:    core.NewSubscriptionFilterFromQuery(query),
+		Options: core.SubscriptionOptions{
+			SubscriptionCoreOptions: core.SubscriptionCoreOptions{
+				Batch: &isBatch,
+			},
+		},
+	}
+	if batchTimeout := query.Get("batchtimeout"); batchTimeout != "" {
+		start.Options.BatchTimeout = &batchTimeout
+	}
+	if readAheadStr := query.Get("readahead"); readAheadStr != "" {
+		if readAheadInt, err := strconv.ParseUint(readAheadStr, 10, 16); err == nil {
+			readAhead := uint16(readAheadInt)
+			start.Options.ReadAhead = &readAhead
+		}
+	}
+	// The ID of each event frame is the event sequence, so a reconnecting client resumes
+	// an ephemeral subscription from the event after the last one it received
+	if lastEventID := req.Header.Get("Last-Event-ID"); lastEventID != "" && isEphemeral {
+		if _, err := strconv.ParseInt(lastEventID, 10, 64); err != nil {
+			return nil, i18n.NewError(ctx, coremsgs.MsgSSEInvalidLastEventID, lastEventID)
+		}
+		firstEvent := core.SubOptsFirstEvent(lastEventID)
+		start.Options.FirstEvent = &firstEvent
+	}
+	return start, nil
+}
+
+func newConnection(pCtx context.Context, s *SSE, res http.ResponseWriter, req *http.Request, start *core.WSStart) *sseConnection {
+	connID := fftypes.NewUUID().String()
+	ctx := log.WithLogField(pCtx, "sse", connID)
+	ctx, cancelCtx := context.WithCancel(ctx)
+	return &sseConnection{
+		ctx:          ctx,
+		cancelCtx:    cancelCtx,
+		reqCtx:       req.Context(),
+		s:            s,
+		res:          res,
+		rc:           http.NewResponseController(res),
+		connID:       connID,
+		start:        start,
+		startTime:    time.Now(),
+		sendMessages: make(chan *sseFrame),
+	}
+}
+
+// run streams to the client until either side closes the connection
+func (sc *sseConnection) run() {
+	defer sc.close()
+	l := log.L(sc.ctx)
+
+	header := sc.res.Header()
+	header.Set("Content-Type", "text/event-stream")
+	header.Set("Cache-Control", "no-cache")
+	header.Set("Connection", "keep-alive")
+	header.Set("X-Accel-Buffering", "no")
+	sc.res.WriteHeader(http.StatusOK)
+	// The stream lives far longer than the write timeout of the HTTP server
+	_ = sc.rc.SetWriteDeadline(time.Time{})
+
+	if err := sc.write(&sseFrame{event: SSEConnectedEventType, data: &SSEConnected{Connection: sc.connID}}); err != nil {
+		l.Errorf("Write failed on stream: %s", err)
+		return
+	}
+	if err := sc.s.start(sc); err != nil {
+		sc.protocolError(err)
+		return
+	}
+
+	heartbeat := time.NewTicker(sc.s.heartbeatInterval)
+	defer heartbeat.Stop()
+	for {
+		var err error
+		select {
+		case frame := <-sc.sendMessages:
+			l.Tracef("Sending: %+v", frame.data)
+			err = sc.write(frame)
+		case <-heartbeat.C:
+			err = sc.writeRaw(": heartbeat\n\n")
+		case <-sc.reqCtx.Done():
+			l.Debugf("Stream closing - client disconnected")
+			return
+		case <-sc.ctx.Done():
+			l.Debugf("Stream closing - context cancelled")
+			return
+		}
+		if err != nil {
+			l.Errorf("Write failed on stream: %s", err)
+			return
+		}
+	}
+}
+
+func (sc *sseConnection) write(frame *sseFrame) error {
+	b, err := json.Marshal(frame.data)
+	if err != nil {
+		return err
+	}
+	var msg string
+	if frame.id != "" {
+		msg += fmt.Sprintf("id: %s\n", frame.id)
+	}
+	if frame.event != "" {
+		msg += fmt.Sprintf("event: %s\n", frame.event)
+	}
+	return sc.writeRaw(fmt.Sprintf("%sdata: %s\n\n", msg, b))
+}
+
+func (sc *sseConnection) writeRaw(msg string) error {
+	if _, err := sc.res.Write([]byte(msg)); err != nil {
+		return err
+	}
+	return sc.rc.Flush()
+}
+
+func (sc *sseConnection) protocolError(err error) {
+	log.L(sc.ctx).Errorf("Sending protocol error to client: %s", err)
+	writeErr := sc.write(&sseFrame{
+		event: SSEErrorEventType,
+		data: &core.WSError{
+			Type:  core.WSProtocolErrorEventType,
+			Error: err.Error(),
+		},
+	})
+	if writeErr != nil {
+		log.L(sc.ctx).Errorf("Failed to send protocol error: %s", writeErr)
+	}
+}
+
+func (sc *sseConnection) send(frame *sseFrame) error {
+	select {
+	case sc.sendMessages <- frame:
+		return nil
+	case <-sc.ctx.Done():
+		return i18n.NewError(sc.ctx, coremsgs.MsgSSEConnectionNotActive, sc.connID)
+	}
+}
+
+func (sc *sseConnection) dispatch(event *core.EventDelivery) error {
+	inflight := &core.EventDeliveryResponse{
+		ID:           event.ID,
+		Subscription: event.Subscription,
+	}
+
+	autoAck := *sc.start.AutoAck
+	if !autoAck {
+		sc.mux.Lock()
+		sc.inflight = append(sc.inflight, inflight)
+		sc.mux.Unlock()
+	}
+
+	err := sc.send(&sseFrame{
+		id:   strconv.FormatInt(event.Sequence, 10),
+		data: event,
+	})
+	if err != nil {
+		return err
+	}
+
+	if autoAck {
+		sc.s.ack(sc.connID, inflight)
+	}
+	return nil
+}
+
+func (sc *sseConnection) dispatchBatch(sub *core.Subscription, events []*core.CombinedEventDataDelivery) error {
+	inflightBatch := &core.WSEventBatch{
+		Type:   core.WSEventBatchType,
+		ID:     fftypes.NewUUID(),
+		Events: make([]*core.EventDelivery, len(events)),
+	}
+	if sub != nil {
+		inflightBatch.Subscription = sub.SubscriptionRef
+	}
+	var lastSequence int64
+	for i, e := range events {
+		// For ephemeral there's no sub, so we pick up from first event
+		if inflightBatch.Subscription.Namespace == "" {
+			inflightBatch.Subscription = e.Event.Subscription
+		}
+		inflightBatch.Events[i] = e.Event
+		lastSequence = e.Event.Sequence
+	}
+
+	autoAck := *sc.start.AutoAck
+	if !autoAck {
+		sc.mux.Lock()
+		sc.inflightBatches = append(sc.inflightBatches, inflightBatch)
+		sc.mux.Unlock()
+	}
+
+	err := sc.send(&sseFrame{
+		id:    strconv.FormatInt(lastSequence, 10),
+		event: SSEBatchEventType,
+		data:  inflightBatch,
+	})
+	if err != nil {
+		return err
+	}
+
+	if autoAck {
+		sc.ackBatch(inflightBatch)
+	}
+	return nil
+}
+
+func (sc *sseConnection) ackBatch(batch *core.WSEventBatch) {
+	for _, e := range batch.Events {
+		sc.s.ack(sc.connID, &core.EventDeliveryResponse{
+			ID:           e.ID,
+			Subscription: batch.Subscription,
+		})
+	}
+}
+
+// checkAck removes the matching batch or event from the inflight lists, or the oldest
+// event if the ack does not specify an ID
+func (sc *sseConnection) checkAck(ctx context.Context, ack *core.SSEAck) (batch *core.WSEventBatch, inflight *core.EventDeliveryResponse, err error) {
+	sc.mux.Lock()
+	defer sc.mux.Unlock()
+
+	if *sc.start.AutoAck {
+		return nil, nil, i18n.NewError(ctx, coremsgs.MsgWSAutoAckEnabled)
+	}
+
+	if ack.ID == nil {
+		if len(sc.inflight) > 0 {
+			inflight, sc.inflight = sc.inflight[0], sc.inflight[1:]
+			return nil, inflight, nil
+		}
+		return nil, nil, i18n.NewError(ctx, coremsgs.MsgSSEAckNotMatched, sc.connID)
+	}
+	for i, candidate := range sc.inflightBatches {
+		if candidate.ID.Equals(ack.ID) {
+			sc.inflightBatches = append(sc.inflightBatches[0:i], sc.inflightBatches[i+1:]...)
+			return candidate, nil, nil
+		}
+	}
+	for i, candidate := range sc.inflight {
+		if candidate.ID.Equals(ack.ID) {
+			sc.inflight = append(sc.inflight[0:i], sc.inflight[i+1:]...)
+			return nil, candidate, nil
+		}
+	}
+	return nil, nil, i18n.NewError(ctx, coremsgs.MsgSSEAckNotMatched, sc.connID)
+}
+
+func (sc *sseConnection) handleAck(ctx context.Context, ack *core.SSEAck) error {
+	batch, inflight, err := sc.checkAck(ctx, ack)
+	if err != nil {
+		return err
+	}
+	// Deliver the ack to the core, now we're unlocked
+	if batch != nil {
+		sc.ackBatch(batch)
+	} else {
+		sc.s.ack(sc.connID, inflight)
+	}
+	return nil
+}
+
+func (sc *sseConnection) restartForNamespace(ns string, startTime time.Time) {
+	sc.mux.Lock()
+	restart := sc.start.Namespace == ns && sc.startTime.Before(startTime)
+	if restart {
+		sc.startTime = time.Now()
+	}
+	sc.mux.Unlock()
+	if restart {
+		log.L(sc.ctx).Infof("Restarting subscription '%s:%s' (ephemeral=%t)", sc.start.Namespace, sc.start.Name, sc.start.Ephemeral)
+		if err := sc.s.start(sc); err != nil {
+			log.L(sc.ctx).Errorf("Failed restart subscription '%s:%s' (closing): %s", sc.start.Namespace, sc.start.Name, err)
+			sc.close()
+		}
+	}
+}
+
+func (sc *sseConnection) close() {
+	var didClose bool
+	sc.mux.Lock()
+	if !sc.closed {
+		didClose = true
+		sc.closed = true
+		sc.cancelCtx()
+	}
+	sc.mux.Unlock()
+	// Drop lock before callback
+	if didClose {
+		sc.s.connClosed(sc.connID)
+	}
+}
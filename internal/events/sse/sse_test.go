@@ -0,0 +1,550 @@
+// Copyright © 2024 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sse
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/firefly-common/pkg/config"
+	"github.com/hyperledger/firefly-common/pkg/fftypes"
+	"github.com/hyperledger/firefly-common/pkg/i18n"
+	"github.com/hyperledger/firefly/internal/coreconfig"
+	"github.com/hyperledger/firefly/mocks/eventsmocks"
+	"github.com/hyperledger/firefly/pkg/core"
+	"github.com/hyperledger/firefly/pkg/events"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type testAuthorizer struct{}
+
+func (t *testAuthorizer) Authorize(ctx context.Context, authReq *fftypes.AuthReq) error {
+	if ac := core.GetAuthContext(ctx); !ac.EventStream || ac.Subscription == "forbidden" {
+		return i18n.NewError(ctx, i18n.MsgForbidden)
+	}
+	if authReq.Namespace == "ns1" {
+		return nil
+	}
+	return i18n.NewError(ctx, i18n.MsgUnauthorized)
+}
+
+type testFrame struct {
+	id    string
+	event string
+	data  string
+}
+
+type testClient struct {
+	res    *http.Response
+	reader *bufio.Reader
+	cancel func()
+}
+
+func newTestSSE(t *testing.T, cbs *eventsmocks.Callbacks) (s *SSE, svr *httptest.Server, done func()) {
+	coreconfig.Reset()
+
+	s = &SSE{}
+	ctx, cancelCtx := context.WithCancel(context.Background())
+	conf := config.RootSection("ut.sse")
+	s.InitConfig(conf)
+	err := s.Init(ctx, conf)
+	assert.NoError(t, err)
+	err = s.SetHandler("ns1", cbs)
+	assert.NoError(t, err)
+	s.SetAuthorizer(&testAuthorizer{})
+	assert.Equal(t, "sse", s.Name())
+	assert.True(t, s.Capabilities().BatchDelivery)
+
+	svr = httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		ns := strings.Split(strings.TrimPrefix(req.URL.Path, "/"), "/")[0]
+		s.ServeHTTPNamespaced(ns, res, req)
+	}))
+	return s, svr, func() {
+		cancelCtx()
+		svr.Close()
+		cbs.AssertExpectations(t)
+	}
+}
+
+func connect(t *testing.T, svr *httptest.Server, path string, headers map[string]string) *testClient {
+	ctx, cancel := context.WithCancel(context.Background())
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, svr.URL+path, nil)
+	assert.NoError(t, err)
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	res, err := http.DefaultClient.Do(req)
+	assert.NoError(t, err)
+	return &testClient{
+		res:    res,
+		reader: bufio.NewReader(res.Body),
+		cancel: cancel,
+	}
+}
+
+func (tc *testClient) readFrame(t *testing.T) *testFrame {
+	frame := &testFrame{}
+	for {
+		line, err := tc.reader.ReadString('\n')
+		assert.NoError(t, err)
+		line = strings.TrimSuffix(line, "\n")
+		switch {
+		case line == "":
+			return frame
+		case strings.HasPrefix(line, "id: "):
+			frame.id = strings.TrimPrefix(line, "id: ")
+		case strings.HasPrefix(line, "event: "):
+			frame.event = strings.TrimPrefix(line, "event: ")
+		case strings.HasPrefix(line, "data: "):
+			frame.data = strings.TrimPrefix(line, "data: ")
+		case strings.HasPrefix(line, ":"):
+			frame.event = "comment"
+		}
+	}
+}
+
+func (tc *testClient) readConnected(t *testing.T) string {
+	frame := tc.readFrame(t)
+	assert.Equal(t, SSEConnectedEventType, frame.event)
+	var connected SSEConnected
+	err := json.Unmarshal([]byte(frame.data), &connected)
+	assert.NoError(t, err)
+	return connected.Connection
+}
+
+func (tc *testClient) close() {
+	tc.cancel()
+	_ = tc.res.Body.Close()
+}
+
+func testEvent(seq int64) *core.EventDelivery {
+	return &core.EventDelivery{
+		EnrichedEvent: core.EnrichedEvent{
+			Event: core.Event{
+				ID:        fftypes.NewUUID(),
+				Sequence:  seq,
+				Namespace: "ns1",
+			},
+		},
+		Subscription: core.SubscriptionRef{
+			ID:        fftypes.NewUUID(),
+			Namespace: "ns1",
+			Name:      "sub1",
+		},
+	}
+}
+
+func waitClosed(cbs *eventsmocks.Callbacks) chan struct{} {
+	closed := make(chan struct{})
+	cbs.On("ConnectionClosed", mock.Anything).Run(func(args mock.Arguments) {
+		close(closed)
+	}).Return(nil).Once()
+	return closed
+}
+
+func TestEphemeralAutoAckResume(t *testing.T) {
+	cbs := &eventsmocks.Callbacks{}
+	s, svr, done := newTestSSE(t, cbs)
+	defer done()
+
+	started := make(chan string)
+	cbs.On("EphemeralSubscription", mock.Anything, "ns1", mock.Anything, mock.MatchedBy(func(o *core.SubscriptionOptions) bool {
+		return *o.FirstEvent == "10" && !*o.Batch && *o.ReadAhead == 50 && *o.BatchTimeout == "1s"
+	})).Run(func(args mock.Arguments) {
+		started <- args[0].(string)
+	}).Return(nil)
+	event := testEvent(11)
+	cbs.On("DeliveryResponse", mock.Anything, mock.MatchedBy(func(r *core.EventDeliveryResponse) bool {
+		return r.ID.Equals(event.ID)
+	})).Return()
+	closed := waitClosed(cbs)
+
+	tc := connect(t, svr, "/ns1?ephemeral&autoack&readahead=50&batchtimeout=1s&topic=topic1", map[string]string{
+		"Last-Event-ID": "10",
+	})
+	assert.Equal(t, http.StatusOK, tc.res.StatusCode)
+	assert.Equal(t, "text/event-stream", tc.res.Header.Get("Content-Type"))
+	connID := tc.readConnected(t)
+	assert.Equal(t, connID, <-started)
+
+	go func() {
+		err := s.DeliveryRequest(s.ctx, connID, nil, event, nil)
+		assert.NoError(t, err)
+	}()
+	frame := tc.readFrame(t)
+	assert.Equal(t, "11", frame.id)
+	assert.Empty(t, frame.event)
+	var received core.EventDelivery
+	err := json.Unmarshal([]byte(frame.data), &received)
+	assert.NoError(t, err)
+	assert.Equal(t, event.ID, received.ID)
+
+	err = s.Ack(s.ctx, "ns1", connID, &core.SSEAck{})
+	assert.Regexp(t, "FF10180", err)
+
+	tc.close()
+	<-closed
+	err = s.DeliveryRequest(s.ctx, connID, nil, event, nil)
+	assert.Regexp(t, "FF10487", err)
+}
+
+func TestDurableBatchManualAck(t *testing.T) {
+	cbs := &eventsmocks.Callbacks{}
+	s, svr, done := newTestSSE(t, cbs)
+	defer done()
+
+	started := make(chan string)
+	cbs.On("RegisterConnection", mock.Anything, mock.MatchedBy(func(matcher events.SubscriptionMatcher) bool {
+		return matcher(core.SubscriptionRef{Namespace: "ns1", Name: "sub1"}) &&
+			!matcher(core.SubscriptionRef{Namespace: "ns1", Name: "sub2"})
+	})).Run(func(args mock.Arguments) {
+		started <- args[0].(string)
+	}).Return(nil)
+	e1, e2 := testEvent(1), testEvent(2)
+	for _, e := range []*core.EventDelivery{e1, e2} {
+		eventID := e.ID
+		cbs.On("DeliveryResponse", mock.Anything, mock.MatchedBy(func(r *core.EventDeliveryResponse) bool {
+			return r.ID.Equals(eventID)
+		})).Return().Once()
+	}
+	closed := waitClosed(cbs)
+
+	tc := connect(t, svr, "/ns1?name=sub1&batch", map[string]string{
+		"Last-Event-ID": "ignored for durable subscriptions",
+	})
+	connID := tc.readConnected(t)
+	assert.Equal(t, connID, <-started)
+
+	sub := &core.Subscription{SubscriptionRef: e1.Subscription}
+	go func() {
+		err := s.BatchDeliveryRequest(s.ctx, connID, sub, []*core.CombinedEventDataDelivery{
+			{Event: e1}, {Event: e2},
+		})
+		assert.NoError(t, err)
+	}()
+	frame := tc.readFrame(t)
+	assert.Equal(t, "2", frame.id)
+	assert.Equal(t, SSEBatchEventType, frame.event)
+	var batch core.WSEventBatch
+	err := json.Unmarshal([]byte(frame.data), &batch)
+	assert.NoError(t, err)
+	assert.Len(t, batch.Events, 2)
+
+	err = s.Ack(s.ctx, "ns2", connID, &core.SSEAck{ID: batch.ID})
+	assert.Regexp(t, "FF10487", err)
+	err = s.Ack(s.ctx, "ns1", connID, &core.SSEAck{ID: fftypes.NewUUID()})
+	assert.Regexp(t, "FF10488", err)
+	err = s.Ack(s.ctx, "ns1", connID, &core.SSEAck{ID: batch.ID})
+	assert.NoError(t, err)
+
+	tc.close()
+	<-closed
+	err = s.BatchDeliveryRequest(s.ctx, connID, sub, nil)
+	assert.Regexp(t, "FF10487", err)
+}
+
+func TestEphemeralBatchAutoAck(t *testing.T) {
+	cbs := &eventsmocks.Callbacks{}
+	s, svr, done := newTestSSE(t, cbs)
+	defer done()
+
+	started := make(chan string)
+	cbs.On("EphemeralSubscription", mock.Anything, "ns1", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		started <- args[0].(string)
+	}).Return(nil)
+	event := testEvent(1)
+	cbs.On("DeliveryResponse", mock.Anything, mock.Anything).Return().Once()
+	closed := waitClosed(cbs)
+
+	tc := connect(t, svr, "/ns1?ephemeral&batch&autoack", nil)
+	connID := tc.readConnected(t)
+	<-started
+
+	go func() {
+		// No subscription is passed for ephemeral subscriptions
+		err := s.BatchDeliveryRequest(s.ctx, connID, nil, []*core.CombinedEventDataDelivery{{Event: event}})
+		assert.NoError(t, err)
+	}()
+	frame := tc.readFrame(t)
+	var batch core.WSEventBatch
+	err := json.Unmarshal([]byte(frame.data), &batch)
+	assert.NoError(t, err)
+	assert.Equal(t, "sub1", batch.Subscription.Name)
+
+	tc.close()
+	<-closed
+}
+
+func TestManualAckEvents(t *testing.T) {
+	cbs := &eventsmocks.Callbacks{}
+	s, svr, done := newTestSSE(t, cbs)
+	defer done()
+
+	started := make(chan string)
+	cbs.On("EphemeralSubscription", mock.Anything, "ns1", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		started <- args[0].(string)
+	}).Return(nil)
+	e1, e2, e3 := testEvent(1), testEvent(2), testEvent(3)
+	acked := make(chan *fftypes.UUID, 3)
+	cbs.On("DeliveryResponse", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		acked <- args[1].(*core.EventDeliveryResponse).ID
+	}).Return()
+	closed := waitClosed(cbs)
+
+	tc := connect(t, svr, "/ns1?ephemeral", nil)
+	connID := tc.readConnected(t)
+	<-started
+
+	for _, e := range []*core.EventDelivery{e1, e2, e3} {
+		go func(e *core.EventDelivery) {
+			err := s.DeliveryRequest(s.ctx, connID, nil, e, nil)
+			assert.NoError(t, err)
+		}(e)
+		tc.readFrame(t)
+	}
+
+	// Ack out of order by ID, then the remaining events from the front of the queue
+	err := s.Ack(s.ctx, "ns1", connID, &core.SSEAck{ID: e2.ID})
+	assert.NoError(t, err)
+	assert.Equal(t, e2.ID, <-acked)
+	err = s.Ack(s.ctx, "ns1", connID, &core.SSEAck{})
+	assert.NoError(t, err)
+	assert.Equal(t, e1.ID, <-acked)
+	err = s.Ack(s.ctx, "ns1", connID, &core.SSEAck{})
+	assert.NoError(t, err)
+	assert.Equal(t, e3.ID, <-acked)
+	err = s.Ack(s.ctx, "ns1", connID, &core.SSEAck{})
+	assert.Regexp(t, "FF10488", err)
+	err = s.Ack(s.ctx, "ns1", "unknown", &core.SSEAck{})
+	assert.Regexp(t, "FF10487", err)
+
+	tc.close()
+	<-closed
+}
+
+func TestHeartbeat(t *testing.T) {
+	cbs := &eventsmocks.Callbacks{}
+	s, svr, done := newTestSSE(t, cbs)
+	defer done()
+	s.heartbeatInterval = 1 * time.Millisecond
+
+	cbs.On("EphemeralSubscription", mock.Anything, "ns1", mock.Anything, mock.Anything).Return(nil)
+	closed := waitClosed(cbs)
+
+	tc := connect(t, svr, "/ns1?ephemeral", nil)
+	tc.readConnected(t)
+	frame := tc.readFrame(t)
+	assert.Equal(t, "comment", frame.event)
+
+	tc.close()
+	<-closed
+}
+
+func TestStartFailNamespaceUnknown(t *testing.T) {
+	cbs := &eventsmocks.Callbacks{}
+	s, svr, done := newTestSSE(t, cbs)
+	defer done()
+	s.SetAuthorizer(nil)
+	closed := waitClosed(cbs)
+
+	tc := connect(t, svr, "/ns2?ephemeral", nil)
+	tc.readConnected(t)
+	frame := tc.readFrame(t)
+	assert.Equal(t, SSEErrorEventType, frame.event)
+	assert.Regexp(t, "FF10187", frame.data)
+	<-closed
+	tc.close()
+}
+
+func TestServeUnauthorized(t *testing.T) {
+	cbs := &eventsmocks.Callbacks{}
+	_, svr, done := newTestSSE(t, cbs)
+	defer done()
+
+	tc := connect(t, svr, "/ns2?ephemeral", nil)
+	defer tc.close()
+	assert.Equal(t, http.StatusUnauthorized, tc.res.StatusCode)
+}
+
+func TestServeForbiddenSubscription(t *testing.T) {
+	cbs := &eventsmocks.Callbacks{}
+	_, svr, done := newTestSSE(t, cbs)
+	defer done()
+
+	tc := connect(t, svr, "/ns1?name=forbidden", nil)
+	defer tc.close()
+	assert.Equal(t, http.StatusForbidden, tc.res.StatusCode)
+}
+
+func TestServeBadRequest(t *testing.T) {
+	cbs := &eventsmocks.Callbacks{}
+	_, svr, done := newTestSSE(t, cbs)
+	defer done()
+
+	tc := connect(t, svr, "/ns1", nil)
+	defer tc.close()
+	assert.Equal(t, http.StatusBadRequest, tc.res.StatusCode)
+	var restErr fftypes.RESTError
+	err := json.NewDecoder(tc.res.Body).Decode(&restErr)
+	assert.NoError(t, err)
+	assert.Regexp(t, "FF10484", restErr.Error)
+
+	tc2 := connect(t, svr, "/ns1?ephemeral", map[string]string{"Last-Event-ID": "not a number"})
+	defer tc2.close()
+	assert.Equal(t, http.StatusBadRequest, tc2.res.StatusCode)
+}
+
+func TestNamespaceRestarted(t *testing.T) {
+	cbs := &eventsmocks.Callbacks{}
+	s, svr, done := newTestSSE(t, cbs)
+	defer done()
+
+	started := make(chan string)
+	cbs.On("EphemeralSubscription", mock.Anything, "ns1", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		started <- args[0].(string)
+	}).Return(nil).Once()
+	cbs.On("EphemeralSubscription", mock.Anything, "ns1", mock.Anything, mock.Anything).Return(nil).Once()
+	cbs.On("EphemeralSubscription", mock.Anything, "ns1", mock.Anything, mock.Anything).Return(fmt.Errorf("pop")).Once()
+	closed := waitClosed(cbs)
+
+	tc := connect(t, svr, "/ns1?ephemeral", nil)
+	defer tc.close()
+	tc.readConnected(t)
+	<-started
+
+	// Other namespaces, and restarts before we started, are ignored
+	s.NamespaceRestarted("ns2", time.Now())
+	s.NamespaceRestarted("ns1", time.Now().Add(-1*time.Hour))
+	// The first restart succeeds, and the second fails and closes the stream
+	s.NamespaceRestarted("ns1", time.Now().Add(1*time.Hour))
+	s.NamespaceRestarted("ns1", time.Now().Add(2*time.Hour))
+	<-closed
+}
+
+func TestValidateOptions(t *testing.T) {
+	s := &SSE{}
+	opts := &core.SubscriptionOptions{}
+	err := s.ValidateOptions(context.Background(), opts)
+	assert.NoError(t, err)
+	assert.False(t, *opts.WithData)
+
+	yes := true
+	opts.WithData = &yes
+	err = s.ValidateOptions(context.Background(), opts)
+	assert.Regexp(t, "FF10485", err)
+}
+
+func TestSetHandlerRemove(t *testing.T) {
+	cbs := &eventsmocks.Callbacks{}
+	s, _, done := newTestSSE(t, cbs)
+	defer done()
+
+	err := s.SetHandler("ns1", nil)
+	assert.NoError(t, err)
+	assert.Empty(t, s.callbacks.handlers)
+}
+
+type failingWriter struct {
+	httptest.ResponseRecorder
+}
+
+func (fw *failingWriter) Write(b []byte) (int, error) {
+	return 0, fmt.Errorf("pop")
+}
+
+func TestWriteFailures(t *testing.T) {
+	cbs := &eventsmocks.Callbacks{}
+	s, _, done := newTestSSE(t, cbs)
+	defer done()
+	cbs.On("ConnectionClosed", mock.Anything).Return(nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/ns1?ephemeral", nil)
+	fw := &failingWriter{ResponseRecorder: *httptest.NewRecorder()}
+	s.ServeHTTPNamespaced("ns1", fw, req)
+
+	start, err := parseStart(context.Background(), "ns1", req)
+	assert.NoError(t, err)
+	sc := newConnection(s.ctx, s, fw, req, start)
+	err = sc.write(&sseFrame{data: map[bool]bool{true: false}})
+	assert.Error(t, err)
+	sc.protocolError(fmt.Errorf("pop"))
+	sc.close()
+	err = sc.send(&sseFrame{})
+	assert.Regexp(t, "FF10487", err)
+	err = sc.dispatch(testEvent(1))
+	assert.Regexp(t, "FF10487", err)
+	err = sc.dispatchBatch(nil, []*core.CombinedEventDataDelivery{{Event: testEvent(1)}})
+	assert.Regexp(t, "FF10487", err)
+}
+
+func TestStreamWriteFailAfterStart(t *testing.T) {
+	cbs := &eventsmocks.Callbacks{}
+	s, _, done := newTestSSE(t, cbs)
+	defer done()
+	cbs.On("ConnectionClosed", mock.Anything).Return(nil)
+	cbs.On("EphemeralSubscription", mock.Anything, "ns1", mock.Anything, mock.Anything).Return(nil)
+	s.heartbeatInterval = 1 * time.Millisecond
+
+	req := httptest.NewRequest(http.MethodGet, "/ns1?ephemeral", nil)
+	start, err := parseStart(context.Background(), "ns1", req)
+	assert.NoError(t, err)
+	// Connected frame written OK, then the heartbeat write fails
+	sc := newConnection(s.ctx, s, &writeOnce{ResponseWriter: httptest.NewRecorder()}, req, start)
+	sc.run()
+	assert.True(t, sc.closed)
+}
+
+type writeOnce struct {
+	http.ResponseWriter
+	written bool
+}
+
+func (wo *writeOnce) Write(b []byte) (int, error) {
+	if wo.written {
+		return 0, fmt.Errorf("pop")
+	}
+	wo.written = true
+	return wo.ResponseWriter.Write(b)
+}
+
+func (wo *writeOnce) Unwrap() http.ResponseWriter {
+	return wo.ResponseWriter
+}
+
+func TestStreamCancelled(t *testing.T) {
+	cbs := &eventsmocks.Callbacks{}
+	s, _, done := newTestSSE(t, cbs)
+	defer done()
+	cbs.On("ConnectionClosed", mock.Anything).Return(nil)
+	cbs.On("EphemeralSubscription", mock.Anything, "ns1", mock.Anything, mock.Anything).Return(nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/ns1?ephemeral", nil)
+	start, err := parseStart(context.Background(), "ns1", req)
+	assert.NoError(t, err)
+	sc := newConnection(s.ctx, s, httptest.NewRecorder(), req, start)
+	sc.cancelCtx()
+	sc.run()
+	assert.True(t, sc.closed)
+}
@@ -0,0 +1,215 @@
+// Copyright © 2024 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package events
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"math/big"
+
+	"github.com/google/cel-go/cel"
+	"github.com/hyperledger/firefly-common/pkg/i18n"
+	"github.com/hyperledger/firefly-common/pkg/log"
+	"github.com/hyperledger/firefly/internal/coremsgs"
+	"github.com/hyperledger/firefly/internal/data"
+	"github.com/hyperledger/firefly/pkg/core"
+)
+
+// expressionEventVariable is the name under which the JSON serialization of the EventDelivery
+// is available to a subscription filter expression
+const expressionEventVariable = "event"
+
+// expressionDataVariable is the name under which the data of a message event is available to a
+// subscription filter expression, in the same JSON format as it is delivered with withData.
+// It is an empty list for events that do not refer to a message.
+const expressionDataVariable = "data"
+
+const (
+	// expressionCostLimit bounds the work a single evaluation can do, so an expensive expression
+	// (such as a comprehension over a large array) cannot stall delivery for the namespace
+	expressionCostLimit = 1000000
+	// expressionInterruptCheckFrequency is how many comprehension iterations run between checks
+	// for cancellation of the context
+	expressionInterruptCheckFrequency = 100
+)
+
+type expressionFilter struct {
+	expression string
+	program    cel.Program
+	usesData   bool
+}
+
+// expressionActivation holds the variables an expression is evaluated against. The event is
+// only serialized the first time it is needed, and then reused for every evaluation against it.
+// The message data is only loaded the first time an expression refers to it.
+type expressionActivation struct {
+	data      data.Manager
+	event     *core.EventDelivery
+	vars      map[string]interface{}
+	err       error
+	dataValue []interface{}
+}
+
+func newExpressionActivation(dm data.Manager, event *core.EventDelivery) *expressionActivation {
+	return &expressionActivation{data: dm, event: event}
+}
+
+// toExpressionJSON serializes a value to generic JSON for evaluation. Integers that fit in an int64
+// become CEL ints, and larger integers (such as a uint256) become a bigint, rather than losing
+// precision as a double.
+func toExpressionJSON(v interface{}) (interface{}, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	decoder := json.NewDecoder(bytes.NewReader(b))
+	decoder.UseNumber()
+	var generic interface{}
+	if err := decoder.Decode(&generic); err != nil {
+		return nil, err
+	}
+	return convertExpressionNumbers(generic), nil
+}
+
+func convertExpressionNumbers(v interface{}) interface{} {
+	switch vt := v.(type) {
+	case map[string]interface{}:
+		for k, mv := range vt {
+			vt[k] = convertExpressionNumbers(mv)
+		}
+	case []interface{}:
+		for i, lv := range vt {
+			vt[i] = convertExpressionNumbers(lv)
+		}
+	case json.Number:
+		if i, err := vt.Int64(); err == nil {
+			return i
+		}
+		if i, ok := new(big.Int).SetString(vt.String(), 10); ok {
+			return newBigIntValue(i)
+		}
+		f, _ := vt.Float64()
+		return f
+	}
+	return v
+}
+
+func (ea *expressionActivation) variables(ctx context.Context, withData bool) (map[string]interface{}, error) {
+	if ea.vars == nil && ea.err == nil {
+		eventJSON, err := toExpressionJSON(ea.event)
+		if err != nil {
+			ea.err = err
+		} else {
+			ea.vars = map[string]interface{}{
+				expressionEventVariable: eventJSON,
+				expressionDataVariable:  []interface{}{},
+			}
+		}
+	}
+	if ea.err != nil {
+		return nil, ea.err
+	}
+	if withData && ea.dataValue == nil {
+		ea.dataValue = []interface{}{}
+		if ea.event.Message != nil {
+			msgData, _, err := ea.data.GetMessageDataCached(ctx, ea.event.Message)
+			if err != nil {
+				// Not cached, as loading the data can be retried
+				ea.dataValue = nil
+				return nil, err
+			}
+			dataJSON, err := toExpressionJSON(msgData)
+			if err != nil {
+				ea.err = err
+				return nil, err
+			}
+			if dataList, ok := dataJSON.([]interface{}); ok {
+				ea.dataValue = dataList
+			}
+		}
+		ea.vars[expressionDataVariable] = ea.dataValue
+	}
+	return ea.vars, nil
+}
+
+func newExpressionFilter(ctx context.Context, expression string) (*expressionFilter, error) {
+	env, err := cel.NewEnv(append(expressionBigIntOptions(),
+		cel.Variable(expressionEventVariable, cel.MapType(cel.StringType, cel.DynType)),
+		cel.Variable(expressionDataVariable, cel.ListType(cel.MapType(cel.StringType, cel.DynType))),
+	)...)
+	if err != nil {
+		return nil, i18n.NewError(ctx, coremsgs.MsgSubscriptionExpressionInvalid, expression, err)
+	}
+
+	ast, issues := env.Compile(expression)
+	if issues.Err() != nil {
+		return nil, i18n.NewError(ctx, coremsgs.MsgSubscriptionExpressionInvalid, expression, issues.Err())
+	}
+	// Dynamic results can only be checked at evaluation time, but anything we know is not a bool is rejected now
+	outputType := ast.OutputType()
+	if !outputType.IsExactType(cel.BoolType) && !outputType.IsExactType(cel.DynType) {
+		return nil, i18n.NewError(ctx, coremsgs.MsgSubscriptionExpressionInvalid, expression, "expression must return a bool")
+	}
+
+	program, err := env.Program(ast,
+		cel.CostLimit(expressionCostLimit),
+		cel.InterruptCheckFrequency(expressionInterruptCheckFrequency),
+	)
+	if err != nil {
+		return nil, i18n.NewError(ctx, coremsgs.MsgSubscriptionExpressionInvalid, expression, err)
+	}
+	// The message data is only loaded for expressions that refer to it
+	usesData := false
+	for _, ref := range ast.NativeRep().ReferenceMap() {
+		if ref.Name == expressionDataVariable && len(ref.OverloadIDs) == 0 {
+			usesData = true
+		}
+	}
+	return &expressionFilter{
+		expression: expression,
+		program:    program,
+		usesData:   usesData,
+	}, nil
+}
+
+// matches evaluates the expression against the event. Any failure to evaluate the expression
+// (such as referring to a field that is not set on this event, or exceeding the cost limit) is
+// treated as a non-match. An error is only returned if the message data for the event could not be
+// loaded, so that delivery can be retried.
+func (ef *expressionFilter) matches(ctx context.Context, activation *expressionActivation) (bool, error) {
+	event := activation.event
+	vars, err := activation.variables(ctx, ef.usesData)
+	if err != nil {
+		if activation.err == nil {
+			return false, err
+		}
+		log.L(ctx).Errorf("Failed to serialize event %s for expression filter: %s", event.ID, err)
+		return false, nil
+	}
+	out, _, err := ef.program.ContextEval(ctx, vars)
+	if err != nil {
+		log.L(ctx).Debugf("Expression '%s' did not match event %s: %s", ef.expression, event.ID, err)
+		return false, nil
+	}
+	result, ok := out.Value().(bool)
+	if !ok {
+		log.L(ctx).Debugf("Expression '%s' returned non-bool result for event %s: %v", ef.expression, event.ID, out.Value())
+		return false, nil
+	}
+	return result, nil
+}
@@ -0,0 +1,220 @@
+// Copyright © 2024 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package events
+
+import (
+	"math"
+	"math/big"
+	"reflect"
+
+	"github.com/google/cel-go/cel"
+	"github.com/google/cel-go/common/operators"
+	"github.com/google/cel-go/common/types"
+	"github.com/google/cel-go/common/types/ref"
+	"github.com/google/cel-go/common/types/traits"
+)
+
+// expressionBigIntFunction converts a string or number to a bigint, so that values such as
+// a uint256 blockchain event output or a token amount can be compared without overflowing an int
+const expressionBigIntFunction = "bigint"
+
+// bigIntType is the CEL type of an arbitrary precision integer
+var bigIntType = cel.ObjectType(expressionBigIntFunction,
+	traits.ComparerType,
+	traits.AdderType,
+	traits.SubtractorType,
+	traits.MultiplierType,
+)
+
+// bigIntValue is the CEL value of an arbitrary precision integer. The standard operators dispatch
+// to the traits of their left hand operand, so a bigint must be on the left of a comparison
+// with an int or uint.
+type bigIntValue struct {
+	i *big.Int
+}
+
+func newBigIntValue(i *big.Int) ref.Val {
+	return bigIntValue{i: i}
+}
+
+// toBigInt returns the integer value of a bigint, int or uint CEL value
+func toBigInt(val ref.Val) (*big.Int, bool) {
+	switch v := val.(type) {
+	case bigIntValue:
+		return v.i, true
+	case types.Int:
+		return big.NewInt(int64(v)), true
+	case types.Uint:
+		return new(big.Int).SetUint64(uint64(v)), true
+	default:
+		return nil, false
+	}
+}
+
+func (b bigIntValue) ConvertToNative(typeDesc reflect.Type) (interface{}, error) {
+	switch typeDesc {
+	case reflect.TypeOf(&big.Int{}):
+		return b.i, nil
+	case reflect.TypeOf(""):
+		return b.i.String(), nil
+	}
+	return nil, types.NewErr("type conversion error from bigint to '%v'", typeDesc).(*types.Err)
+}
+
+func (b bigIntValue) ConvertToType(typeVal ref.Type) ref.Val {
+	switch typeVal {
+	case types.StringType:
+		return types.String(b.i.String())
+	case types.IntType:
+		if b.i.IsInt64() {
+			return types.Int(b.i.Int64())
+		}
+		return types.NewErr("int overflow converting bigint %s", b.i)
+	case types.DoubleType:
+		f, _ := new(big.Float).SetInt(b.i).Float64()
+		return types.Double(f)
+	case types.TypeType:
+		return bigIntType
+	case bigIntType:
+		return b
+	}
+	return types.NewErr("type conversion error from bigint to '%s'", typeVal)
+}
+
+func (b bigIntValue) Equal(other ref.Val) ref.Val {
+	o, ok := toBigInt(other)
+	return types.Bool(ok && b.i.Cmp(o) == 0)
+}
+
+func (b bigIntValue) Type() ref.Type {
+	return bigIntType
+}
+
+func (b bigIntValue) Value() interface{} {
+	return b.i
+}
+
+func (b bigIntValue) Compare(other ref.Val) ref.Val {
+	o, ok := toBigInt(other)
+	if !ok {
+		return types.MaybeNoSuchOverloadErr(other)
+	}
+	return types.Int(b.i.Cmp(o))
+}
+
+func (b bigIntValue) Add(other ref.Val) ref.Val {
+	o, ok := toBigInt(other)
+	if !ok {
+		return types.MaybeNoSuchOverloadErr(other)
+	}
+	return newBigIntValue(new(big.Int).Add(b.i, o))
+}
+
+func (b bigIntValue) Subtract(other ref.Val) ref.Val {
+	o, ok := toBigInt(other)
+	if !ok {
+		return types.MaybeNoSuchOverloadErr(other)
+	}
+	return newBigIntValue(new(big.Int).Sub(b.i, o))
+}
+
+func (b bigIntValue) Multiply(other ref.Val) ref.Val {
+	o, ok := toBigInt(other)
+	if !ok {
+		return types.MaybeNoSuchOverloadErr(other)
+	}
+	return newBigIntValue(new(big.Int).Mul(b.i, o))
+}
+
+// toBigIntValue is the binding of the bigint() conversion function
+func toBigIntValue(val ref.Val) ref.Val {
+	switch v := val.(type) {
+	case bigIntValue:
+		return v
+	case types.Int, types.Uint:
+		i, _ := toBigInt(v)
+		return newBigIntValue(i)
+	case types.Double:
+		f := float64(v)
+		if math.IsInf(f, 0) || math.IsNaN(f) || f != math.Trunc(f) {
+			return types.NewErr("cannot convert %v to bigint", f)
+		}
+		i, _ := big.NewFloat(f).Int(nil)
+		return newBigIntValue(i)
+	case types.String:
+		// Accepts decimal, or hex with a 0x prefix
+		i, ok := new(big.Int).SetString(string(v), 0)
+		if !ok {
+			return types.NewErr("cannot convert '%s' to bigint", v)
+		}
+		return newBigIntValue(i)
+	}
+	return types.MaybeNoSuchOverloadErr(val)
+}
+
+// convertBigIntValue is the binding of the conversion of a bigint to another type
+func convertBigIntValue(t ref.Type) func(val ref.Val) ref.Val {
+	return func(val ref.Val) ref.Val {
+		return val.ConvertToType(t)
+	}
+}
+
+// expressionBigIntOptions declares the bigint type, the conversions to and from it, and the overloads
+// of the standard operators it supports. Equality is only declared between two bigints. The operator
+// overloads have no bindings of their own, as the standard operators call the traits of bigIntValue
+// at runtime.
+func expressionBigIntOptions() []cel.EnvOption {
+	opts := []cel.EnvOption{
+		cel.Types(bigIntType),
+		cel.Function(expressionBigIntFunction,
+			cel.Overload("bigint_to_bigint", []*cel.Type{bigIntType}, bigIntType, cel.UnaryBinding(toBigIntValue)),
+			cel.Overload("string_to_bigint", []*cel.Type{cel.StringType}, bigIntType, cel.UnaryBinding(toBigIntValue)),
+			cel.Overload("int_to_bigint", []*cel.Type{cel.IntType}, bigIntType, cel.UnaryBinding(toBigIntValue)),
+			cel.Overload("uint_to_bigint", []*cel.Type{cel.UintType}, bigIntType, cel.UnaryBinding(toBigIntValue)),
+			cel.Overload("double_to_bigint", []*cel.Type{cel.DoubleType}, bigIntType, cel.UnaryBinding(toBigIntValue)),
+		),
+		cel.Function("string",
+			cel.Overload("bigint_to_string", []*cel.Type{bigIntType}, cel.StringType, cel.UnaryBinding(convertBigIntValue(types.StringType))),
+		),
+		cel.Function("int",
+			cel.Overload("bigint_to_int", []*cel.Type{bigIntType}, cel.IntType, cel.UnaryBinding(convertBigIntValue(types.IntType))),
+		),
+		cel.Function("double",
+			cel.Overload("bigint_to_double", []*cel.Type{bigIntType}, cel.DoubleType, cel.UnaryBinding(convertBigIntValue(types.DoubleType))),
+		),
+	}
+	for _, op := range []struct {
+		name     string
+		function string
+		result   *cel.Type
+	}{
+		{name: "less", function: operators.Less, result: cel.BoolType},
+		{name: "less_equals", function: operators.LessEquals, result: cel.BoolType},
+		{name: "greater", function: operators.Greater, result: cel.BoolType},
+		{name: "greater_equals", function: operators.GreaterEquals, result: cel.BoolType},
+		{name: "add", function: operators.Add, result: bigIntType},
+		{name: "subtract", function: operators.Subtract, result: bigIntType},
+		{name: "multiply", function: operators.Multiply, result: bigIntType},
+	} {
+		opts = append(opts, cel.Function(op.function,
+			cel.Overload(op.name+"_bigint", []*cel.Type{bigIntType, bigIntType}, op.result),
+			cel.Overload(op.name+"_bigint_int", []*cel.Type{bigIntType, cel.IntType}, op.result),
+			cel.Overload(op.name+"_bigint_uint", []*cel.Type{bigIntType, cel.UintType}, op.result),
+		))
+	}
+	return opts
+}
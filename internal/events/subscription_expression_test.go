@@ -0,0 +1,312 @@
+// Copyright © 2024 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package events
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"reflect"
+	"testing"
+
+	"github.com/google/cel-go/common/types"
+	"github.com/hyperledger/firefly-common/pkg/fftypes"
+	"github.com/hyperledger/firefly/mocks/datamocks"
+	"github.com/hyperledger/firefly/pkg/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func evalMatches(t *testing.T, ef *expressionFilter, activation *expressionActivation) bool {
+	matched, err := ef.matches(context.Background(), activation)
+	assert.NoError(t, err)
+	return matched
+}
+
+func TestExpressionFilterMatches(t *testing.T) {
+	ef, err := newExpressionFilter(context.Background(), "event.type == 'message_confirmed' && event.topic.startsWith('orders') && event.sequence > 10")
+	assert.NoError(t, err)
+
+	assert.True(t, evalMatches(t, ef, newExpressionActivation(nil, &core.EventDelivery{
+		EnrichedEvent: core.EnrichedEvent{
+			Event: core.Event{Type: core.EventTypeMessageConfirmed, Topic: "orders-eu", Sequence: 11},
+		},
+	})))
+	assert.False(t, evalMatches(t, ef, newExpressionActivation(nil, &core.EventDelivery{
+		EnrichedEvent: core.EnrichedEvent{
+			Event: core.Event{Type: core.EventTypeMessageConfirmed, Topic: "orders-eu", Sequence: 10},
+		},
+	})))
+	assert.False(t, evalMatches(t, ef, newExpressionActivation(nil, &core.EventDelivery{
+		EnrichedEvent: core.EnrichedEvent{
+			Event: core.Event{Type: core.EventTypeMessageRejected, Topic: "orders-eu", Sequence: 11},
+		},
+	})))
+}
+
+func TestExpressionFilterMissingFields(t *testing.T) {
+	ef, err := newExpressionFilter(context.Background(), "event.tokenTransfer.amount == '100'")
+	assert.NoError(t, err)
+
+	// Fields that are not set on the event fail evaluation, which is a non-match
+	assert.False(t, evalMatches(t, ef, newExpressionActivation(nil, &core.EventDelivery{
+		EnrichedEvent: core.EnrichedEvent{
+			Event: core.Event{Type: core.EventTypeMessageConfirmed},
+		},
+	})))
+
+	ef, err = newExpressionFilter(context.Background(), "!has(event.tokenTransfer)")
+	assert.NoError(t, err)
+	assert.True(t, evalMatches(t, ef, newExpressionActivation(nil, &core.EventDelivery{
+		EnrichedEvent: core.EnrichedEvent{
+			Event: core.Event{Type: core.EventTypeMessageConfirmed},
+		},
+	})))
+}
+
+func TestExpressionFilterNonBoolResult(t *testing.T) {
+	ef, err := newExpressionFilter(context.Background(), "event.topic")
+	assert.NoError(t, err)
+
+	assert.False(t, evalMatches(t, ef, newExpressionActivation(nil, &core.EventDelivery{
+		EnrichedEvent: core.EnrichedEvent{
+			Event: core.Event{Topic: "topic1"},
+		},
+	})))
+}
+
+func TestExpressionFilterSerializeFail(t *testing.T) {
+	ef, err := newExpressionFilter(context.Background(), "true")
+	assert.NoError(t, err)
+
+	assert.False(t, evalMatches(t, ef, newExpressionActivation(nil, &core.EventDelivery{
+		EnrichedEvent: core.EnrichedEvent{
+			ContractInterface: &fftypes.FFI{
+				Events: []*fftypes.FFIEvent{{
+					FFIEventDefinition: fftypes.FFIEventDefinition{
+						Params: fftypes.FFIParams{{Schema: fftypes.JSONAnyPtr("!json")}},
+					},
+				}},
+			},
+		},
+	})))
+}
+
+func TestExpressionFilterCostLimit(t *testing.T) {
+	list := "[0,1,2,3,4,5,6,7,8,9]"
+	ef, err := newExpressionFilter(context.Background(),
+		fmt.Sprintf("%[1]s.all(a, %[1]s.all(b, %[1]s.all(c, %[1]s.all(d, %[1]s.all(e, %[1]s.all(f, true))))))", list))
+	assert.NoError(t, err)
+
+	// Exceeding the cost limit is a non-match
+	assert.False(t, evalMatches(t, ef, newExpressionActivation(nil, &core.EventDelivery{})))
+}
+
+func TestExpressionActivationReused(t *testing.T) {
+	activation := newExpressionActivation(nil, &core.EventDelivery{
+		EnrichedEvent: core.EnrichedEvent{
+			Event: core.Event{Type: core.EventTypeMessageConfirmed, Topic: "orders-eu"},
+		},
+	})
+	vars1, err := activation.variables(context.Background(), false)
+	assert.NoError(t, err)
+	vars2, err := activation.variables(context.Background(), false)
+	assert.NoError(t, err)
+	assert.Equal(t, fmt.Sprintf("%p", vars1), fmt.Sprintf("%p", vars2))
+
+	for _, expression := range []string{"event.topic == 'orders-eu'", "event.type == 'message_confirmed'"} {
+		ef, err := newExpressionFilter(context.Background(), expression)
+		assert.NoError(t, err)
+		assert.True(t, evalMatches(t, ef, activation))
+	}
+}
+
+func TestExpressionFilterMessageData(t *testing.T) {
+	ef, err := newExpressionFilter(context.Background(), "data.exists(d, d.value.amount > 1000 && d.value.currency == 'GBP')")
+	assert.NoError(t, err)
+	assert.True(t, ef.usesData)
+
+	msg := &core.Message{Header: core.MessageHeader{ID: fftypes.NewUUID()}}
+	mdm := &datamocks.Manager{}
+	mdm.On("GetMessageDataCached", mock.Anything, msg).Return(core.DataArray{
+		{ID: fftypes.NewUUID(), Value: fftypes.JSONAnyPtr(`{"amount": 5000, "currency": "GBP"}`)},
+	}, true, nil).Once()
+
+	activation := newExpressionActivation(mdm, &core.EventDelivery{
+		EnrichedEvent: core.EnrichedEvent{
+			Event:   core.Event{Type: core.EventTypeMessageConfirmed},
+			Message: msg,
+		},
+	})
+	assert.True(t, evalMatches(t, ef, activation))
+	// The data is only loaded once for the activation
+	assert.True(t, evalMatches(t, ef, activation))
+
+	// Events without a message have no data
+	assert.False(t, evalMatches(t, ef, newExpressionActivation(mdm, &core.EventDelivery{
+		EnrichedEvent: core.EnrichedEvent{
+			Event: core.Event{Type: core.EventTypeBlockchainEventReceived},
+		},
+	})))
+
+	mdm.AssertExpectations(t)
+}
+
+func TestExpressionFilterMessageDataNotLoadedWhenUnused(t *testing.T) {
+	ef, err := newExpressionFilter(context.Background(), "event.message.header.tag == 'tag1'")
+	assert.NoError(t, err)
+	assert.False(t, ef.usesData)
+
+	mdm := &datamocks.Manager{}
+	assert.True(t, evalMatches(t, ef, newExpressionActivation(mdm, &core.EventDelivery{
+		EnrichedEvent: core.EnrichedEvent{
+			Event:   core.Event{Type: core.EventTypeMessageConfirmed},
+			Message: &core.Message{Header: core.MessageHeader{Tag: "tag1"}},
+		},
+	})))
+	mdm.AssertExpectations(t)
+}
+
+func TestExpressionFilterMessageDataFail(t *testing.T) {
+	ef, err := newExpressionFilter(context.Background(), "size(data) > 0")
+	assert.NoError(t, err)
+
+	msg := &core.Message{Header: core.MessageHeader{ID: fftypes.NewUUID()}}
+	mdm := &datamocks.Manager{}
+	mdm.On("GetMessageDataCached", mock.Anything, msg).Return(nil, false, fmt.Errorf("pop")).Once()
+	mdm.On("GetMessageDataCached", mock.Anything, msg).Return(core.DataArray{{ID: fftypes.NewUUID()}}, true, nil).Once()
+
+	activation := newExpressionActivation(mdm, &core.EventDelivery{
+		EnrichedEvent: core.EnrichedEvent{
+			Event:   core.Event{Type: core.EventTypeMessageConfirmed},
+			Message: msg,
+		},
+	})
+	_, err = ef.matches(context.Background(), activation)
+	assert.EqualError(t, err, "pop")
+
+	// Loading the data is retried on the next evaluation
+	assert.True(t, evalMatches(t, ef, activation))
+
+	mdm.AssertExpectations(t)
+}
+
+func TestExpressionFilterMessageDataSerializeFail(t *testing.T) {
+	ef, err := newExpressionFilter(context.Background(), "size(data) > 0")
+	assert.NoError(t, err)
+
+	msg := &core.Message{Header: core.MessageHeader{ID: fftypes.NewUUID()}}
+	mdm := &datamocks.Manager{}
+	mdm.On("GetMessageDataCached", mock.Anything, msg).Return(core.DataArray{
+		{Value: fftypes.JSONAnyPtr("!json")},
+	}, true, nil).Once()
+
+	activation := newExpressionActivation(mdm, &core.EventDelivery{
+		EnrichedEvent: core.EnrichedEvent{
+			Event:   core.Event{Type: core.EventTypeMessageConfirmed},
+			Message: msg,
+		},
+	})
+	// Data that cannot be serialized is a non-match, and is not loaded again
+	assert.False(t, evalMatches(t, ef, activation))
+	assert.False(t, evalMatches(t, ef, activation))
+
+	mdm.AssertExpectations(t)
+}
+
+func TestExpressionFilterBigInt(t *testing.T) {
+	uint256Max := "115792089237316195423570985008687907853269984665640564039457584007913129639935"
+	event := &core.EventDelivery{
+		EnrichedEvent: core.EnrichedEvent{
+			Event: core.Event{Type: core.EventTypeBlockchainEventReceived},
+			BlockchainEvent: &core.BlockchainEvent{
+				Output: fftypes.JSONObject{
+					"value":  uint256Max,
+					"hex":    "0xffffffffffffffffffff",
+					"number": fftypes.NewFFBigInt(1000),
+				},
+			},
+		},
+	}
+	for expression, expected := range map[string]bool{
+		"bigint(event.blockchainEvent.output.value) > 1000":                                           true,
+		"bigint(event.blockchainEvent.output.value) > 1000u":                                          true,
+		"bigint(event.blockchainEvent.output.value) == bigint('" + uint256Max + "')":                  true,
+		"bigint(event.blockchainEvent.output.value) - 1 < bigint(event.blockchainEvent.output.value)": true,
+		"bigint(event.blockchainEvent.output.value) + 1 > bigint(event.blockchainEvent.output.value)": true,
+		"bigint(event.blockchainEvent.output.hex) >= bigint(2) * bigint('0x7fffffffffffffffffff')":    true,
+		"bigint(event.blockchainEvent.output.number) <= 1000":                                         true,
+		"bigint(event.blockchainEvent.output.number) == bigint(1000)":                                 true,
+		"bigint(event.blockchainEvent.output.number) != bigint(1000)":                                 false,
+		"string(bigint(event.blockchainEvent.output.number)) == '1000'":                               true,
+		"int(bigint(event.blockchainEvent.output.number)) == 1000":                                    true,
+		"double(bigint(event.blockchainEvent.output.number)) == 1000.0":                               true,
+		"bigint(1000.0) == bigint(1000)":                                                              true,
+		"bigint(bigint(1)) == bigint(1u)":                                                             true,
+		// Evaluation errors are a non-match
+		"int(bigint(event.blockchainEvent.output.value)) > 1000": false,
+		"bigint('not a number') > 1000":                          false,
+		"bigint(1.5) > 1":                                        false,
+	} {
+		ef, err := newExpressionFilter(context.Background(), expression)
+		assert.NoError(t, err, expression)
+		assert.Equal(t, expected, evalMatches(t, ef, newExpressionActivation(nil, event)), expression)
+	}
+}
+
+func TestExpressionFilterLargeJSONNumbers(t *testing.T) {
+	ef, err := newExpressionFilter(context.Background(),
+		"data[0].value.big > 1000 && data[0].value.big == bigint('100000000000000000000') && data[0].value.small == 5 && data[0].value.fraction > 1.0")
+	assert.NoError(t, err)
+
+	msg := &core.Message{Header: core.MessageHeader{ID: fftypes.NewUUID()}}
+	mdm := &datamocks.Manager{}
+	mdm.On("GetMessageDataCached", mock.Anything, msg).Return(core.DataArray{
+		{Value: fftypes.JSONAnyPtr(`{"big": 100000000000000000000, "small": 5, "fraction": 1.5}`)},
+	}, true, nil)
+
+	assert.True(t, evalMatches(t, ef, newExpressionActivation(mdm, &core.EventDelivery{
+		EnrichedEvent: core.EnrichedEvent{
+			Event:   core.Event{Type: core.EventTypeMessageConfirmed},
+			Message: msg,
+		},
+	})))
+}
+
+func TestBigIntValueConversions(t *testing.T) {
+	v := toBigIntValue(types.Int(42)).(bigIntValue)
+	native, err := v.ConvertToNative(reflect.TypeOf(""))
+	assert.NoError(t, err)
+	assert.Equal(t, "42", native)
+	native, err = v.ConvertToNative(reflect.TypeOf(&big.Int{}))
+	assert.NoError(t, err)
+	assert.Equal(t, int64(42), native.(*big.Int).Int64())
+	_, err = v.ConvertToNative(reflect.TypeOf(0))
+	assert.Error(t, err)
+
+	assert.Equal(t, bigIntType, v.ConvertToType(types.TypeType))
+	assert.Equal(t, v, v.ConvertToType(bigIntType))
+	assert.True(t, types.IsError(v.ConvertToType(types.BoolType)))
+	assert.Equal(t, big.NewInt(42), v.Value())
+
+	assert.True(t, types.IsError(v.Compare(types.String("42"))))
+	assert.True(t, types.IsError(v.Add(types.String("42"))))
+	assert.True(t, types.IsError(v.Subtract(types.String("42"))))
+	assert.True(t, types.IsError(v.Multiply(types.String("42"))))
+	assert.Equal(t, types.False, v.Equal(types.String("42")))
+	assert.True(t, types.IsError(toBigIntValue(types.Bool(true))))
+}
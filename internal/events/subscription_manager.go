@@ -47,6 +47,7 @@ type subscription struct {
 	blockchainFilter   *blockchainFilter
 	transactionFilter  *transactionFilter
 	topicFilter        *regexp.Regexp
+	expressionFilter   *expressionFilter
 }
 
 type messageFilter struct {
@@ -91,6 +92,7 @@ type subscriptionManager struct {
 	newOrUpdatedSubscriptions chan *fftypes.UUID
 	deletedSubscriptions      chan *fftypes.UUID
 	retry                     retry.Retry
+	connectionlessStarted     bool
 
 	defaultBatchSize    uint16
 	defaultBatchTimeout time.Duration
@@ -255,6 +257,26 @@ func (sm *subscriptionManager) deletedDurableSubscription(id *fftypes.UUID) {
 	}
 }
 
+func (sm *subscriptionManager) replayDeadLetter(ctx context.Context, deadLetter *core.DeadLetter) error {
+	sm.mux.Lock()
+	dispatchers := make([]*eventDispatcher, 0)
+	for _, conn := range sm.connections {
+		if dispatcher, ok := conn.dispatchers[*deadLetter.Subscription.ID]; ok {
+			dispatchers = append(dispatchers, dispatcher)
+		}
+	}
+	sm.mux.Unlock()
+
+	// Only the dispatcher that is currently elected to deliver events for the subscription can replay
+	for _, dispatcher := range dispatchers {
+		replayed, err := dispatcher.replayDeadLetter(ctx, deadLetter)
+		if err != nil || replayed {
+			return err
+		}
+	}
+	return i18n.NewError(ctx, coremsgs.MsgDeadLetterNoActiveDispatcher, deadLetter.Subscription.Name)
+}
+
 func (sm *subscriptionManager) getTransport(ctx context.Context, transportName string) (events.Plugin, error) {
 	transport, ok := sm.transports[transportName]
 	if !ok {
@@ -403,6 +425,13 @@ func (sm *subscriptionManager) parseSubscriptionDef(ctx context.Context, subDef
 		sub.blockchainFilter = bf
 	}
 
+	if filter.Expression != "" {
+		sub.expressionFilter, err = newExpressionFilter(ctx, filter.Expression)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	if (filter.Transaction != core.TransactionFilter{}) {
 		var typeFilter *regexp.Regexp
 		if filter.Transaction.Type != "" {
@@ -482,6 +511,10 @@ func (sm *subscriptionManager) matchSubToConnLocked(conn *connection, sub *subsc
 		return
 	}
 	if conn.transport == sub.definition.Transport && conn.matcher(sub.definition.SubscriptionRef) {
+		if !sm.connectionlessStarted && conn.ei.Capabilities().Connectionless {
+			log.L(sm.ctx).Debugf("Deferring dispatcher for subscription %s on %s until this node is the leader", sub.definition.ID, conn.transport)
+			return
+		}
 		if _, ok := conn.dispatchers[*sub.definition.ID]; !ok {
 			dispatcher := newEventDispatcher(sm.ctx, sm.enricher, conn.ei, sm.database, sm.data, sm.broadcast, sm.messaging, conn.id, sub, sm.eventNotifier, sm.txHelper)
 			conn.dispatchers[*sub.definition.ID] = dispatcher
@@ -490,6 +523,44 @@ func (sm *subscriptionManager) matchSubToConnLocked(conn *connection, sub *subsc
 	}
 }
 
+// startConnectionlessDispatchers starts the dispatchers for durable subscriptions on connectionless transports,
+// which must only run on one node sharing the database
+func (sm *subscriptionManager) startConnectionlessDispatchers() {
+	sm.mux.Lock()
+	defer sm.mux.Unlock()
+	sm.connectionlessStarted = true
+	for _, conn := range sm.connections {
+		for _, sub := range sm.durableSubs {
+			sm.matchSubToConnLocked(conn, sub)
+		}
+	}
+}
+
+// stopConnectionlessDispatchers closes the dispatchers started by startConnectionlessDispatchers,
+// so another node can take over delivery
+func (sm *subscriptionManager) stopConnectionlessDispatchers() {
+	sm.mux.Lock()
+	sm.connectionlessStarted = false
+	dispatchers := make([]*eventDispatcher, 0)
+	for _, conn := range sm.connections {
+		if !conn.ei.Capabilities().Connectionless {
+			continue
+		}
+		for subID, d := range conn.dispatchers {
+			if !d.subscription.definition.Ephemeral {
+				dispatchers = append(dispatchers, d)
+				delete(conn.dispatchers, subID)
+			}
+		}
+	}
+	sm.mux.Unlock()
+
+	log.L(sm.ctx).Infof("Closing %d dispatcher(s) for connectionless transports", len(dispatchers))
+	for _, d := range dispatchers {
+		d.close()
+	}
+}
+
 func (sm *subscriptionManager) ephemeralSubscription(ei events.Plugin, connID, namespace string, filter *core.SubscriptionFilter, options *core.SubscriptionOptions) error {
 	sm.mux.Lock()
 	defer sm.mux.Unlock()
@@ -574,6 +645,15 @@ func (sm *subscriptionManager) deliveryResponse(ei events.Plugin, connID string,
 	dispatcher.deliveryResponse(inflight)
 }
 
+// MatchesExpression evaluates any filter expression on the subscription against the enriched event,
+// as it would be delivered. It is evaluated separately to MatchesEvent, as it is more expensive
+func (sub *subscription) MatchesExpression(ctx context.Context, activation *expressionActivation) (bool, error) {
+	if sub.expressionFilter == nil {
+		return true, nil
+	}
+	return sub.expressionFilter.matches(ctx, activation)
+}
+
 func (sub *subscription) MatchesEvent(event *core.EnrichedEvent) bool {
 	if sub.eventMatcher != nil && !sub.eventMatcher.MatchString(string(event.Type)) {
 		return false
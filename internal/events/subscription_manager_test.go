@@ -20,6 +20,7 @@ import (
 	"context"
 	"crypto/tls"
 	"fmt"
+	"sync"
 	"testing"
 	"time"
 
@@ -28,6 +29,7 @@ import (
 	"github.com/hyperledger/firefly-common/pkg/fftypes"
 	"github.com/hyperledger/firefly/internal/cache"
 	"github.com/hyperledger/firefly/internal/coreconfig"
+	"github.com/hyperledger/firefly/internal/leader"
 	"github.com/hyperledger/firefly/internal/txcommon"
 	"github.com/hyperledger/firefly/mocks/broadcastmocks"
 	"github.com/hyperledger/firefly/mocks/cachemocks"
@@ -477,6 +479,49 @@ func TestCreateSubscriptionBadBlockchainEventListenerFilter(t *testing.T) {
 	assert.Regexp(t, "FF10171.*listener", err)
 }
 
+func TestCreateSubscriptionBadExpressionFilter(t *testing.T) {
+	mei := &eventsmocks.Plugin{}
+	sm, cancel := newTestSubManager(t, mei)
+	defer cancel()
+	mei.On("ValidateOptions", mock.Anything, mock.Anything).Return(nil)
+	_, err := sm.parseSubscriptionDef(sm.ctx, &core.Subscription{
+		Filter: core.SubscriptionFilter{
+			Expression: "event.message.header.tag ==",
+		},
+		Transport: "ut",
+	})
+	assert.Regexp(t, "FF10489", err)
+}
+
+func TestCreateSubscriptionNonBoolExpressionFilter(t *testing.T) {
+	mei := &eventsmocks.Plugin{}
+	sm, cancel := newTestSubManager(t, mei)
+	defer cancel()
+	mei.On("ValidateOptions", mock.Anything, mock.Anything).Return(nil)
+	_, err := sm.parseSubscriptionDef(sm.ctx, &core.Subscription{
+		Filter: core.SubscriptionFilter{
+			Expression: "'flapflip'",
+		},
+		Transport: "ut",
+	})
+	assert.Regexp(t, "FF10489.*bool", err)
+}
+
+func TestCreateSubscriptionSuccessExpressionFilter(t *testing.T) {
+	mei := &eventsmocks.Plugin{}
+	sm, cancel := newTestSubManager(t, mei)
+	defer cancel()
+	mei.On("ValidateOptions", mock.Anything, mock.Anything).Return(nil)
+	sub, err := sm.parseSubscriptionDef(sm.ctx, &core.Subscription{
+		Filter: core.SubscriptionFilter{
+			Expression: "event.message.header.tag == 'flapflip'",
+		},
+		Transport: "ut",
+	})
+	assert.NoError(t, err)
+	assert.NotNil(t, sub.expressionFilter)
+}
+
 func TestCreateSubscriptionSuccessMessageFilter(t *testing.T) {
 	mei := &eventsmocks.Plugin{}
 	sm, cancel := newTestSubManager(t, mei)
@@ -907,3 +952,257 @@ func TestDeleteDurableSubscriptionOk(t *testing.T) {
 	assert.Empty(t, sm.durableSubs)
 	<-ed.closed
 }
+
+func TestReplayDeadLetterNoDispatcher(t *testing.T) {
+	subID := fftypes.NewUUID()
+	ed, cancel1 := newTestEventDispatcher(&subscription{definition: &core.Subscription{SubscriptionRef: core.SubscriptionRef{ID: subID}}})
+	defer cancel1()
+
+	mei := ed.transport.(*eventsmocks.Plugin)
+	sm, cancel := newTestSubManager(t, mei)
+	defer cancel()
+
+	// Not elected, so cannot replay
+	sm.connections["conn1"] = &connection{
+		ei:        mei,
+		id:        "conn1",
+		transport: "ut",
+		dispatchers: map[fftypes.UUID]*eventDispatcher{
+			*subID: ed,
+		},
+	}
+
+	err := sm.replayDeadLetter(context.Background(), &core.DeadLetter{
+		ID:           fftypes.NewUUID(),
+		Subscription: core.SubscriptionRef{ID: subID, Name: "sub1"},
+		Event:        fftypes.NewUUID(),
+	})
+	assert.Regexp(t, "FF10490.*sub1", err)
+}
+
+func TestReplayDeadLetterDispatcherFail(t *testing.T) {
+	subID := fftypes.NewUUID()
+	ed, cancel1 := newTestEventDispatcher(&subscription{definition: &core.Subscription{SubscriptionRef: core.SubscriptionRef{ID: subID}}})
+	defer cancel1()
+	ed.elected = true
+
+	mei := ed.transport.(*eventsmocks.Plugin)
+	sm, cancel := newTestSubManager(t, mei)
+	defer cancel()
+
+	sm.connections["conn1"] = &connection{
+		ei:        mei,
+		id:        "conn1",
+		transport: "ut",
+		dispatchers: map[fftypes.UUID]*eventDispatcher{
+			*subID: ed,
+		},
+	}
+
+	mdi := ed.database.(*databasemocks.Plugin)
+	mdi.On("GetEventByID", mock.Anything, mock.Anything, mock.Anything).Return(nil, fmt.Errorf("pop"))
+
+	err := sm.replayDeadLetter(context.Background(), &core.DeadLetter{
+		ID:           fftypes.NewUUID(),
+		Subscription: core.SubscriptionRef{ID: subID, Name: "sub1"},
+		Event:        fftypes.NewUUID(),
+	})
+	assert.EqualError(t, err, "pop")
+}
+
+func TestConnectionlessDispatchersStartStop(t *testing.T) {
+	sub1 := fftypes.NewUUID()
+
+	mei := &eventsmocks.Plugin{}
+	mei.On("Capabilities").Return(&events.Capabilities{Connectionless: true})
+	sm, cancel := newTestSubManager(t, mei)
+	defer cancel()
+
+	mdi := sm.database.(*databasemocks.Plugin)
+	mdi.On("GetSubscriptions", mock.Anything, "ns1", mock.Anything).Return([]*core.Subscription{
+		{SubscriptionRef: core.SubscriptionRef{
+			ID: sub1,
+		}, Transport: "ut"},
+	}, nil, nil)
+	mei.On("ValidateOptions", mock.Anything, mock.Anything).Return(nil)
+	err := sm.start()
+	assert.NoError(t, err)
+
+	be := &boundCallbacks{sm: sm, ei: mei}
+	err = be.RegisterConnection("conn1", func(sr core.SubscriptionRef) bool { return true })
+	assert.NoError(t, err)
+	err = be.EphemeralSubscription("conn1", "ns1", &core.SubscriptionFilter{}, &core.SubscriptionOptions{})
+	assert.NoError(t, err)
+
+	// Only the ephemeral subscription is dispatched until we are the leader
+	assert.Equal(t, 1, len(sm.connections["conn1"].dispatchers))
+	assert.Nil(t, sm.connections["conn1"].dispatchers[*sub1])
+
+	sm.startConnectionlessDispatchers()
+	assert.Equal(t, 2, len(sm.connections["conn1"].dispatchers))
+	assert.NotNil(t, sm.connections["conn1"].dispatchers[*sub1])
+
+	sm.stopConnectionlessDispatchers()
+	assert.Equal(t, 1, len(sm.connections["conn1"].dispatchers))
+	assert.Nil(t, sm.connections["conn1"].dispatchers[*sub1])
+
+	sm.close()
+}
+
+func TestConnectionlessDispatchersIgnoreConnectionTransports(t *testing.T) {
+	sub1 := fftypes.NewUUID()
+
+	mei := &eventsmocks.Plugin{}
+	sm, cancel := newTestSubManager(t, mei)
+	defer cancel()
+
+	mdi := sm.database.(*databasemocks.Plugin)
+	mdi.On("GetSubscriptions", mock.Anything, "ns1", mock.Anything).Return([]*core.Subscription{
+		{SubscriptionRef: core.SubscriptionRef{
+			ID: sub1,
+		}, Transport: "ut"},
+	}, nil, nil)
+	mei.On("ValidateOptions", mock.Anything, mock.Anything).Return(nil)
+	err := sm.start()
+	assert.NoError(t, err)
+
+	be := &boundCallbacks{sm: sm, ei: mei}
+	err = be.RegisterConnection("conn1", func(sr core.SubscriptionRef) bool { return true })
+	assert.NoError(t, err)
+	assert.NotNil(t, sm.connections["conn1"].dispatchers[*sub1])
+
+	sm.stopConnectionlessDispatchers()
+	assert.NotNil(t, sm.connections["conn1"].dispatchers[*sub1])
+
+	sm.close()
+}
+
+type testLeaderCallbacks struct {
+	sm *subscriptionManager
+}
+
+func (tlc *testLeaderCallbacks) LeaderElected() {
+	tlc.sm.startConnectionlessDispatchers()
+}
+
+func (tlc *testLeaderCallbacks) LeaderDeposed() {
+	tlc.sm.stopConnectionlessDispatchers()
+}
+
+type testSharedLease struct {
+	mux   sync.Mutex
+	lease *core.Lease
+}
+
+func (sl *testSharedLease) acquire(ctx context.Context, namespace, name, holder string, ttl time.Duration) (*core.Lease, error) {
+	sl.mux.Lock()
+	defer sl.mux.Unlock()
+	if sl.lease == nil || sl.lease.Holder == holder || time.Now().After(*sl.lease.Expires.Time()) {
+		expires := fftypes.FFTime(time.Now().Add(ttl))
+		sl.lease = &core.Lease{Namespace: namespace, Name: name, Holder: holder, Expires: &expires}
+	}
+	return sl.lease, nil
+}
+
+func (sl *testSharedLease) release(ctx context.Context, namespace, name, holder string) error {
+	sl.mux.Lock()
+	defer sl.mux.Unlock()
+	if sl.lease != nil && sl.lease.Holder == holder {
+		sl.lease = nil
+	}
+	return nil
+}
+
+// newTestLeaderNode creates a node with a webhook-like transport, sharing a database containing
+// one durable subscription and one event with the other nodes
+func newTestLeaderNode(t *testing.T, replica string, lease *testSharedLease, subID, msgID *fftypes.UUID, deliveries chan<- string) (*subscriptionManager, leader.Elector, func()) {
+	mdi := &databasemocks.Plugin{}
+	mdm := &datamocks.Manager{}
+	mom := &operationmocks.Manager{}
+	mei := &eventsmocks.Plugin{}
+	ctx, cancel := context.WithCancel(context.Background())
+	cmi := &cachemocks.Manager{}
+	cmi.On("GetCache", mock.Anything).Return(cache.NewUmanagedCache(ctx, 100, 5*time.Minute), nil)
+	txHelper, _ := txcommon.NewTransactionHelper(ctx, "ns1", mdi, mdm, cmi)
+	enricher := newEventEnricher("ns1", mdi, mdm, mom, txHelper)
+	batchTimeout := "1ms"
+
+	mei.On("Name").Return("ut")
+	mei.On("Capabilities").Return(&events.Capabilities{Connectionless: true})
+	mei.On("ValidateOptions", mock.Anything, mock.Anything).Return(nil)
+	mei.On("SetHandler", "ns1", mock.Anything).Return(nil)
+	mei.On("DeliveryRequest", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(func(ctx context.Context, connID string, sub *core.Subscription, event *core.EventDelivery, data core.DataArray) error {
+		deliveries <- replica
+		return nil
+	})
+	mdi.On("GetSubscriptions", mock.Anything, "ns1", mock.Anything).Return([]*core.Subscription{
+		{SubscriptionRef: core.SubscriptionRef{
+			ID:        subID,
+			Namespace: "ns1",
+			Name:      "sub1",
+		}, Transport: "ut", Options: core.SubscriptionOptions{
+			SubscriptionCoreOptions: core.SubscriptionCoreOptions{BatchTimeout: &batchTimeout},
+		}},
+	}, nil, nil)
+	mdi.On("GetOffset", mock.Anything, core.OffsetTypeSubscription, subID.String()).Return(&core.Offset{RowID: 1, Current: 0}, nil)
+	mdi.On("GetEvents", mock.Anything, "ns1", mock.Anything).Return([]*core.Event{
+		{ID: fftypes.NewUUID(), Namespace: "ns1", Sequence: 1, Type: core.EventTypeMessageConfirmed, Reference: msgID},
+	}, nil, nil)
+	mdi.On("AcquireLease", mock.Anything, "ns1", "leader", replica, mock.Anything).Return(lease.acquire)
+	mdi.On("ReleaseLease", mock.Anything, "ns1", "leader", replica).Return(lease.release)
+	mdm.On("GetMessageWithDataCached", mock.Anything, msgID).Return(&core.Message{
+		Header: core.MessageHeader{ID: msgID},
+	}, nil, true, nil)
+
+	sm, err := newSubscriptionManager(ctx, &core.Namespace{Name: "ns1"}, enricher, mdi, mdm, newEventNotifier(ctx, "ut"), nil, nil, txHelper, map[string]events.Plugin{"ut": mei})
+	assert.NoError(t, err)
+	err = sm.start()
+	assert.NoError(t, err)
+	err = (&boundCallbacks{sm: sm, ei: mei}).RegisterConnection(replica, func(sr core.SubscriptionRef) bool { return true })
+	assert.NoError(t, err)
+
+	config.Set(coreconfig.LeaderElectionReplicaName, replica)
+	le := leader.NewElector(ctx, "ns1", mdi, &testLeaderCallbacks{sm: sm})
+	return sm, le, func() {
+		// Stopping the namespace stops the elector, then closes the dispatchers
+		cancel()
+		le.WaitStop()
+		sm.close()
+	}
+}
+
+func TestConnectionlessDispatchersDeliverOnlyFromLeader(t *testing.T) {
+	coreconfig.Reset()
+	config.Set(coreconfig.LeaderElectionRenewInterval, "5ms")
+
+	subID := fftypes.NewUUID()
+	msgID := fftypes.NewUUID()
+	lease := &testSharedLease{}
+	deliveries := make(chan string, 10)
+
+	sm1, le1, stop1 := newTestLeaderNode(t, "replica1", lease, subID, msgID, deliveries)
+	sm2, le2, stop2 := newTestLeaderNode(t, "replica2", lease, subID, msgID, deliveries)
+	defer stop2()
+
+	// Both nodes have the connection, but neither dispatches the subscription until elected
+	assert.Empty(t, sm1.connections["replica1"].dispatchers)
+	assert.Empty(t, sm2.connections["replica2"].dispatchers)
+
+	le1.Start()
+	assert.Equal(t, "replica1", <-deliveries)
+	le2.Start()
+	for !le2.Status().Enabled || le2.Status().Holder != "replica1" {
+		time.Sleep(time.Millisecond)
+	}
+
+	// The follower does not deliver the event that the leader delivered
+	time.Sleep(50 * time.Millisecond)
+	assert.Empty(t, deliveries)
+	assert.Empty(t, sm2.connections["replica2"].dispatchers)
+
+	// The other node takes over delivery when the leader stops
+	stop1()
+	assert.Equal(t, "replica2", <-deliveries)
+	assert.True(t, le2.Status().Leader)
+	assert.Empty(t, deliveries)
+}
@@ -60,8 +60,10 @@ func (se *Events) Name() string { return SystemEventsTransport }
 
 func (se *Events) Init(ctx context.Context, config config.Section) (err error) {
 	*se = Events{
-		ctx:          ctx,
-		capabilities: &events.Capabilities{},
+		ctx: ctx,
+		capabilities: &events.Capabilities{
+			Connectionless: true,
+		},
 		callbacks: callbacks{
 			handlers: make(map[string]events.Callbacks),
 		},
@@ -1,4 +1,4 @@
-// Copyright © 2022 Kaleido, Inc.
+// Copyright © 2024 Kaleido, Inc.
 //
 // SPDX-License-Identifier: Apache-2.0
 //
@@ -21,6 +21,22 @@ import (
 	"github.com/hyperledger/firefly-common/pkg/ffresty"
 )
 
+const (
+	defaultReplyTolerance = "5m"
+)
+
+const (
+	// WebhooksConfSigning is the sub-section of options for signed deliveries
+	WebhooksConfSigning = "signing"
+	// WebhooksConfSigningEncryptionKey is the key used to encrypt the signing secrets stored on subscriptions
+	WebhooksConfSigningEncryptionKey = "encryptionKey"
+	// WebhooksConfSigningReplyTolerance is how far the timestamp of a signed reply can be from the current time
+	WebhooksConfSigningReplyTolerance = "replyTolerance"
+)
+
 func (wh *WebHooks) InitConfig(config config.Section) {
 	ffresty.InitConfig(config)
+	signingConfig := config.SubSection(WebhooksConfSigning)
+	signingConfig.AddKnownKey(WebhooksConfSigningEncryptionKey)
+	signingConfig.AddKnownKey(WebhooksConfSigningReplyTolerance, defaultReplyTolerance)
 }
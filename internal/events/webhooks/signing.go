@@ -0,0 +1,197 @@
+// Copyright © 2024 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package webhooks
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hyperledger/firefly-common/pkg/fftypes"
+	"github.com/hyperledger/firefly-common/pkg/i18n"
+	"github.com/hyperledger/firefly/internal/coremsgs"
+	"github.com/hyperledger/firefly/pkg/core"
+)
+
+const (
+	defaultSignatureHeader = "X-FireFly-Signature"
+	// encryptedSecretPrefix marks secrets that have already been encrypted for storage
+	encryptedSecretPrefix = "enc:"
+	signatureVersion      = "v1"
+)
+
+func newSigningCipher(encryptionKey string) cipher.AEAD {
+	// A SHA-256 digest is always a valid AES-256 key, so neither of these can fail
+	key := sha256.Sum256([]byte(encryptionKey))
+	block, _ := aes.NewCipher(key[:])
+	gcm, _ := cipher.NewGCM(block)
+	return gcm
+}
+
+func (wh *WebHooks) encryptSecret(secret string) string {
+	nonce := make([]byte, wh.signingCipher.NonceSize())
+	_, _ = rand.Read(nonce)
+	sealed := wh.signingCipher.Seal(nonce, nonce, []byte(secret), nil)
+	return encryptedSecretPrefix + base64.StdEncoding.EncodeToString(sealed)
+}
+
+func (wh *WebHooks) decryptSecret(ctx context.Context, name, secret string) ([]byte, error) {
+	sealed, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(secret, encryptedSecretPrefix))
+	nonceSize := wh.signingCipher.NonceSize()
+	if err != nil || len(sealed) < nonceSize {
+		return nil, i18n.NewError(ctx, coremsgs.MsgWebhookSigningSecretInvalid, name)
+	}
+	plaintext, err := wh.signingCipher.Open(nil, sealed[:nonceSize], sealed[nonceSize:], nil)
+	if err != nil {
+		return nil, i18n.NewError(ctx, coremsgs.MsgWebhookSigningSecretInvalid, name)
+	}
+	return plaintext, nil
+}
+
+// resolveSigningKeys encrypts any new plaintext secrets in the signing options, so only the encrypted
+// form is stored on the subscription, and decrypts them for use when signing deliveries
+func (wh *WebHooks) resolveSigningKeys(ctx context.Context, options *core.SubscriptionOptions) error {
+	options.SigningKeys = nil
+	signing, ok := options.TransportOptions().GetObjectOk("signing")
+	if !ok || signing.GetString("secret") == "" {
+		return nil
+	}
+	if wh.signingCipher == nil {
+		return i18n.NewError(ctx, coremsgs.MsgWebhookSigningNoKey)
+	}
+
+	keys := &core.WebhookSigningKeys{}
+	for _, name := range []string{"secret", "previousSecret"} {
+		secret := signing.GetString(name)
+		if secret == "" {
+			continue
+		}
+		if !strings.HasPrefix(secret, encryptedSecretPrefix) {
+			secret = wh.encryptSecret(secret)
+			signing[name] = secret
+		}
+		key, err := wh.decryptSecret(ctx, name, secret)
+		if err != nil {
+			return err
+		}
+		if name == "secret" {
+			keys.Current = key
+			options.Signing.Secret = secret
+		} else {
+			keys.Previous = key
+			options.Signing.PreviousSecret = secret
+		}
+	}
+	if expiry := signing.GetString("previousSecretExpiry"); expiry != "" {
+		t, err := fftypes.ParseTimeString(expiry)
+		if err != nil {
+			return err
+		}
+		keys.PreviousExpiry = t
+	}
+	options.TransportOptions()["signing"] = signing
+	options.SigningKeys = keys
+	return nil
+}
+
+func signatureHeader(options fftypes.JSONObject) string {
+	if header := options.GetObject("signing").GetString("header"); header != "" {
+		return header
+	}
+	return defaultSignatureHeader
+}
+
+// requestBodyBytes serializes the body in the same way it would be sent if it were not signed
+func requestBodyBytes(body interface{}) []byte {
+	switch b := body.(type) {
+	case string:
+		return []byte(b)
+	case json.RawMessage:
+		return b
+	default:
+		bytes, _ := json.Marshal(b)
+		return bytes
+	}
+}
+
+func computeSignature(key []byte, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// activeKeys returns the current key, and the previous key while it is within its grace period
+func activeKeys(keys *core.WebhookSigningKeys, now time.Time) [][]byte {
+	active := [][]byte{keys.Current}
+	if keys.Previous != nil && (keys.PreviousExpiry == nil || now.Before(*keys.PreviousExpiry.Time())) {
+		active = append(active, keys.Previous)
+	}
+	return active
+}
+
+// signBody builds a signature header value of the form "t=<unix seconds>,v1=<hex hmac>", with a
+// v1 signature for each active key so receivers can verify with either secret during a rotation
+func signBody(keys *core.WebhookSigningKeys, now time.Time, body []byte) string {
+	timestamp := now.Unix()
+	signature := fmt.Sprintf("t=%d", timestamp)
+	for _, key := range activeKeys(keys, now) {
+		signature += fmt.Sprintf(",%s=%s", signatureVersion, computeSignature(key, timestamp, body))
+	}
+	return signature
+}
+
+// verifyBody checks a signature header value built in the same way as signBody
+func (wh *WebHooks) verifyBody(ctx context.Context, keys *core.WebhookSigningKeys, header, signature string, now time.Time, body []byte) error {
+	var timestamp int64
+	var signatures []string
+	for _, part := range strings.Split(signature, ",") {
+		k, v, _ := strings.Cut(strings.TrimSpace(part), "=")
+		switch k {
+		case "t":
+			timestamp, _ = strconv.ParseInt(v, 10, 64)
+		case signatureVersion:
+			signatures = append(signatures, v)
+		}
+	}
+	if timestamp == 0 || len(signatures) == 0 {
+		return i18n.NewError(ctx, coremsgs.MsgWebhookReplySignatureInvalid, header, "missing timestamp or signature")
+	}
+	if age := now.Sub(time.Unix(timestamp, 0)); age > wh.replyTolerance || age < -wh.replyTolerance {
+		return i18n.NewError(ctx, coremsgs.MsgWebhookReplySignatureInvalid, header, "timestamp outside of tolerance")
+	}
+	for _, key := range activeKeys(keys, now) {
+		expected := computeSignature(key, timestamp, body)
+		for _, s := range signatures {
+			if hmac.Equal([]byte(s), []byte(expected)) {
+				return nil
+			}
+		}
+	}
+	return i18n.NewError(ctx, coremsgs.MsgWebhookReplySignatureInvalid, header, "signature does not match")
+}
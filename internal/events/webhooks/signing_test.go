@@ -0,0 +1,370 @@
+// Copyright © 2024 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package webhooks
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/hyperledger/firefly-common/pkg/config"
+	"github.com/hyperledger/firefly-common/pkg/fftypes"
+	"github.com/hyperledger/firefly/internal/coreconfig"
+	"github.com/hyperledger/firefly/mocks/eventsmocks"
+	"github.com/hyperledger/firefly/pkg/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func newTestSigningWebHooks(t *testing.T) (wh *WebHooks, cancel func()) {
+	wh, cancel = newTestWebHooks(t)
+	wh.signingCipher = newSigningCipher("testkey")
+	wh.replyTolerance = 1 * time.Minute
+	return wh, cancel
+}
+
+func verifyTestSignature(t *testing.T, secret string, signature string, body []byte) int {
+	parts := strings.Split(signature, ",")
+	timestamp := strings.TrimPrefix(parts[0], "t=")
+	matched := 0
+	for _, p := range parts[1:] {
+		if p == "v1="+computeSignature([]byte(secret), mustParseInt(t, timestamp), body) {
+			matched++
+		}
+	}
+	return matched
+}
+
+func mustParseInt(t *testing.T, s string) int64 {
+	var i int64
+	_, err := fmt.Sscanf(s, "%d", &i)
+	assert.NoError(t, err)
+	return i
+}
+
+func TestInitSigningConfig(t *testing.T) {
+	coreconfig.Reset()
+
+	wh := &WebHooks{}
+	svrConfig := config.RootSection("ut.webhooks")
+	wh.InitConfig(svrConfig)
+	svrConfig.SubSection(WebhooksConfSigning).Set(WebhooksConfSigningEncryptionKey, "testkey")
+	err := wh.Init(context.Background(), svrConfig)
+	assert.NoError(t, err)
+	assert.NotNil(t, wh.signingCipher)
+	assert.Equal(t, 5*time.Minute, wh.replyTolerance)
+}
+
+func TestValidateOptionsSigningNoKey(t *testing.T) {
+	wh, cancel := newTestWebHooks(t)
+	defer cancel()
+
+	opts := &core.SubscriptionOptions{}
+	opts.TransportOptions()["url"] = "/anything"
+	opts.TransportOptions()["signing"] = map[string]interface{}{
+		"secret": "secret1",
+	}
+	err := wh.ValidateOptions(wh.ctx, opts)
+	assert.Regexp(t, "FF10493", err)
+}
+
+func TestValidateOptionsSigningEncryptsSecrets(t *testing.T) {
+	wh, cancel := newTestSigningWebHooks(t)
+	defer cancel()
+
+	opts := &core.SubscriptionOptions{}
+	opts.TransportOptions()["url"] = "/anything"
+	opts.TransportOptions()["signing"] = map[string]interface{}{
+		"secret":               "secret2",
+		"previousSecret":       "secret1",
+		"previousSecretExpiry": "2024-01-01T00:00:00Z",
+	}
+	err := wh.ValidateOptions(wh.ctx, opts)
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("secret2"), opts.SigningKeys.Current)
+	assert.Equal(t, []byte("secret1"), opts.SigningKeys.Previous)
+	assert.Equal(t, int64(1704067200), opts.SigningKeys.PreviousExpiry.Time().Unix())
+
+	// Only the encrypted form is stored
+	b, err := opts.Value()
+	assert.NoError(t, err)
+	assert.NotContains(t, string(b.([]byte)), "secret1")
+	assert.NotContains(t, string(b.([]byte)), "secret2")
+	signing := opts.TransportOptions().GetObject("signing")
+	assert.Regexp(t, "^enc:", signing.GetString("secret"))
+	assert.Equal(t, signing.GetString("secret"), opts.Signing.Secret)
+	assert.Equal(t, signing.GetString("previousSecret"), opts.Signing.PreviousSecret)
+
+	// Reloading the stored options gives the same keys
+	opts2 := &core.SubscriptionOptions{}
+	err = opts2.Scan(b)
+	assert.NoError(t, err)
+	err = wh.ValidateOptions(wh.ctx, opts2)
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("secret2"), opts2.SigningKeys.Current)
+	assert.Equal(t, []byte("secret1"), opts2.SigningKeys.Previous)
+	assert.Equal(t, signing.GetString("secret"), opts2.TransportOptions().GetObject("signing").GetString("secret"))
+}
+
+func TestValidateOptionsSigningBadSecrets(t *testing.T) {
+	wh, cancel := newTestSigningWebHooks(t)
+	defer cancel()
+
+	for _, secret := range []string{"enc:!!!", "enc:AAAA", "enc:" + strings.Repeat("A", 64)} {
+		opts := &core.SubscriptionOptions{}
+		opts.TransportOptions()["url"] = "/anything"
+		opts.TransportOptions()["signing"] = map[string]interface{}{
+			"secret": secret,
+		}
+		err := wh.ValidateOptions(wh.ctx, opts)
+		assert.Regexp(t, "FF10494.*secret", err)
+	}
+
+	// Encrypted with a different key
+	other := &WebHooks{signingCipher: newSigningCipher("otherkey")}
+	opts := &core.SubscriptionOptions{}
+	opts.TransportOptions()["url"] = "/anything"
+	opts.TransportOptions()["signing"] = map[string]interface{}{
+		"secret":         "secret2",
+		"previousSecret": other.encryptSecret("secret1"),
+	}
+	err := wh.ValidateOptions(wh.ctx, opts)
+	assert.Regexp(t, "FF10494.*previousSecret", err)
+}
+
+func TestValidateOptionsSigningBadExpiry(t *testing.T) {
+	wh, cancel := newTestSigningWebHooks(t)
+	defer cancel()
+
+	opts := &core.SubscriptionOptions{}
+	opts.TransportOptions()["url"] = "/anything"
+	opts.TransportOptions()["signing"] = map[string]interface{}{
+		"secret":               "secret2",
+		"previousSecretExpiry": "not a time",
+	}
+	err := wh.ValidateOptions(wh.ctx, opts)
+	assert.Regexp(t, "FF00136", err)
+}
+
+func TestSignBodyRotation(t *testing.T) {
+	now := time.Now()
+	expiry := fftypes.FFTime(now.Add(1 * time.Hour))
+	keys := &core.WebhookSigningKeys{
+		Current:        []byte("secret2"),
+		Previous:       []byte("secret1"),
+		PreviousExpiry: &expiry,
+	}
+
+	// Both secrets are used in the grace period
+	signature := signBody(keys, now, []byte("body"))
+	assert.Equal(t, 1, verifyTestSignature(t, "secret2", signature, []byte("body")))
+	assert.Equal(t, 1, verifyTestSignature(t, "secret1", signature, []byte("body")))
+
+	// Only the current secret is used after it
+	signature = signBody(keys, now.Add(2*time.Hour), []byte("body"))
+	assert.Equal(t, 1, verifyTestSignature(t, "secret2", signature, []byte("body")))
+	assert.Equal(t, 0, verifyTestSignature(t, "secret1", signature, []byte("body")))
+}
+
+func TestVerifyBody(t *testing.T) {
+	wh, cancel := newTestSigningWebHooks(t)
+	defer cancel()
+
+	now := time.Now()
+	keys := &core.WebhookSigningKeys{
+		Current:  []byte("secret2"),
+		Previous: []byte("secret1"),
+	}
+	ctx := context.Background()
+
+	// Signed with the previous secret
+	signature := signBody(&core.WebhookSigningKeys{Current: []byte("secret1")}, now, []byte("body"))
+	err := wh.verifyBody(ctx, keys, "X-Sig", signature, now, []byte("body"))
+	assert.NoError(t, err)
+
+	err = wh.verifyBody(ctx, keys, "X-Sig", "", now, []byte("body"))
+	assert.Regexp(t, "FF10495.*X-Sig.*missing", err)
+
+	err = wh.verifyBody(ctx, keys, "X-Sig", signature, now.Add(2*time.Minute), []byte("body"))
+	assert.Regexp(t, "FF10495.*tolerance", err)
+
+	err = wh.verifyBody(ctx, keys, "X-Sig", signature, now.Add(-2*time.Minute), []byte("body"))
+	assert.Regexp(t, "FF10495.*tolerance", err)
+
+	err = wh.verifyBody(ctx, keys, "X-Sig", signature, now, []byte("tampered"))
+	assert.Regexp(t, "FF10495.*match", err)
+}
+
+func TestRequestSignedWithVerifiedReply(t *testing.T) {
+	wh, cancel := newTestSigningWebHooks(t)
+	defer cancel()
+
+	r := mux.NewRouter()
+	r.HandleFunc("/myapi", func(res http.ResponseWriter, req *http.Request) {
+		body, err := io.ReadAll(req.Body)
+		assert.NoError(t, err)
+		assert.Equal(t, 1, verifyTestSignature(t, "secret1", req.Header.Get("X-My-Signature"), body))
+		var parsed fftypes.JSONObject
+		err = json.Unmarshal(body, &parsed)
+		assert.NoError(t, err)
+		assert.Equal(t, "inputvalue", parsed.GetString("inputfield"))
+
+		reply := []byte(`{"replyfield":"replyvalue"}`)
+		res.Header().Set("Content-Type", "application/json")
+		res.Header().Set("X-My-Signature", signBody(&core.WebhookSigningKeys{Current: []byte("secret1")}, time.Now(), reply))
+		res.WriteHeader(200)
+		res.Write(reply)
+	}).Methods(http.MethodPost)
+	server := httptest.NewServer(r)
+	defer server.Close()
+
+	sub, event, data := newTestSignedSub(server, true)
+	err := wh.ValidateOptions(wh.ctx, &sub.Options)
+	assert.NoError(t, err)
+
+	mcb := wh.callbacks.handlers["ns1"].(*eventsmocks.Callbacks)
+	mcb.On("DeliveryResponse", mock.Anything, mock.MatchedBy(func(response *core.EventDeliveryResponse) bool {
+		assert.Equal(t, float64(200), response.Reply.InlineData[0].Value.JSONObject()["status"])
+		assert.Equal(t, "replyvalue", response.Reply.InlineData[0].Value.JSONObject().GetObject("body").GetString("replyfield"))
+		return true
+	})).Return(nil)
+
+	err = wh.DeliveryRequest(wh.ctx, mock.Anything, sub, event, core.DataArray{data})
+	assert.NoError(t, err)
+
+	mcb.AssertExpectations(t)
+}
+
+func TestRequestSignedUnverifiedReply(t *testing.T) {
+	wh, cancel := newTestSigningWebHooks(t)
+	defer cancel()
+
+	r := mux.NewRouter()
+	r.HandleFunc("/myapi", func(res http.ResponseWriter, req *http.Request) {
+		res.Header().Set("Content-Type", "application/json")
+		res.WriteHeader(200)
+		res.Write([]byte(`{"replyfield":"replyvalue"}`))
+	}).Methods(http.MethodPost)
+	server := httptest.NewServer(r)
+	defer server.Close()
+
+	sub, event, data := newTestSignedSub(server, true)
+	err := wh.ValidateOptions(wh.ctx, &sub.Options)
+	assert.NoError(t, err)
+
+	mcb := wh.callbacks.handlers["ns1"].(*eventsmocks.Callbacks)
+	mcb.On("DeliveryResponse", mock.Anything, mock.MatchedBy(func(response *core.EventDeliveryResponse) bool {
+		assert.Equal(t, float64(502), response.Reply.InlineData[0].Value.JSONObject()["status"])
+		assert.Regexp(t, "FF10495", response.Reply.InlineData[0].Value.JSONObject().GetObject("body").GetString("error"))
+		return true
+	})).Return(nil)
+
+	err = wh.DeliveryRequest(wh.ctx, mock.Anything, sub, event, core.DataArray{data})
+	assert.NoError(t, err)
+
+	mcb.AssertExpectations(t)
+}
+
+func TestRequestSignedNoBody(t *testing.T) {
+	wh, cancel := newTestSigningWebHooks(t)
+	defer cancel()
+
+	called := false
+	r := mux.NewRouter()
+	r.HandleFunc("/myapi", func(res http.ResponseWriter, req *http.Request) {
+		assert.Equal(t, 1, verifyTestSignature(t, "secret1", req.Header.Get("X-My-Signature"), []byte{}))
+		res.WriteHeader(204)
+		called = true
+	}).Methods(http.MethodGet)
+	server := httptest.NewServer(r)
+	defer server.Close()
+
+	sub, event, data := newTestSignedSub(server, false)
+	sub.Options.TransportOptions()["method"] = http.MethodGet
+	err := wh.ValidateOptions(wh.ctx, &sub.Options)
+	assert.NoError(t, err)
+
+	mcb := wh.callbacks.handlers["ns1"].(*eventsmocks.Callbacks)
+	mcb.On("DeliveryResponse", mock.Anything, mock.Anything).Return(nil)
+
+	err = wh.DeliveryRequest(wh.ctx, mock.Anything, sub, event, core.DataArray{data})
+	assert.NoError(t, err)
+	assert.True(t, called)
+
+	mcb.AssertExpectations(t)
+}
+
+func TestSignatureHeaderDefault(t *testing.T) {
+	assert.Equal(t, "X-FireFly-Signature", signatureHeader(fftypes.JSONObject{}))
+}
+
+func TestRequestBodyBytes(t *testing.T) {
+	assert.Equal(t, []byte("text"), requestBodyBytes("text"))
+	assert.Equal(t, []byte(`{"a":1}`), requestBodyBytes(json.RawMessage(`{"a":1}`)))
+	assert.Equal(t, []byte(`{"a":1}`), requestBodyBytes(fftypes.JSONObject{"a": 1}))
+}
+
+func newTestSignedSub(server *httptest.Server, reply bool) (*core.Subscription, *core.EventDelivery, *core.Data) {
+	yes := true
+	dataID := fftypes.NewUUID()
+	sub := &core.Subscription{
+		SubscriptionRef: core.SubscriptionRef{
+			Namespace: "ns1",
+		},
+		Options: core.SubscriptionOptions{
+			SubscriptionCoreOptions: core.SubscriptionCoreOptions{
+				WithData: &yes,
+			},
+		},
+	}
+	to := sub.Options.TransportOptions()
+	to["reply"] = reply
+	to["url"] = fmt.Sprintf("http://%s/myapi", server.Listener.Addr())
+	to["signing"] = map[string]interface{}{
+		"secret":      "secret1",
+		"header":      "X-My-Signature",
+		"verifyReply": true,
+	}
+	event := &core.EventDelivery{
+		EnrichedEvent: core.EnrichedEvent{
+			Event: core.Event{
+				ID: fftypes.NewUUID(),
+			},
+			Message: &core.Message{
+				Header: core.MessageHeader{
+					ID:   fftypes.NewUUID(),
+					Type: core.MessageTypeBroadcast,
+				},
+				Data: core.DataRefs{
+					{ID: dataID},
+				},
+			},
+		},
+	}
+	data := &core.Data{
+		ID:    dataID,
+		Value: fftypes.JSONAnyPtr(`{"inputfield": "inputvalue"}`),
+	}
+	return sub, event, data
+}
@@ -0,0 +1,91 @@
+// Copyright © 2024 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"text/template"
+
+	"github.com/hyperledger/firefly-common/pkg/i18n"
+	"github.com/hyperledger/firefly/internal/coremsgs"
+	"github.com/hyperledger/firefly/pkg/core"
+)
+
+// templateFuncs are available in webhook templates, in addition to the Go template builtins
+var templateFuncs = template.FuncMap{
+	"json": func(v interface{}) (string, error) {
+		b, err := json.Marshal(v)
+		return string(b), err
+	},
+}
+
+func parseTemplate(ctx context.Context, text string) (*template.Template, error) {
+	tmpl, err := template.New("webhook").Funcs(templateFuncs).Parse(text)
+	if err != nil {
+		return nil, i18n.NewError(ctx, coremsgs.MsgWebhookTemplateInvalid, err)
+	}
+	return tmpl, nil
+}
+
+// subscriptionTemplate returns the template parsed when the subscription options were validated,
+// or parses it now if the options did not go through validation
+func subscriptionTemplate(ctx context.Context, options *core.SubscriptionOptions) (*template.Template, error) {
+	if options.BodyTemplate != nil {
+		return options.BodyTemplate, nil
+	}
+	text := options.TransportOptions().GetString("template")
+	if text == "" {
+		return nil, nil
+	}
+	return parseTemplate(ctx, text)
+}
+
+// renderTemplate executes the template against the JSON form of the event and its data, so fields are
+// referred to by the names they are delivered with - such as {{.event.message.header.tag}}.
+// Output that is valid JSON is sent as JSON, and anything else is sent as a string.
+func renderTemplate(ctx context.Context, tmpl *template.Template, event *core.CombinedEventDataDelivery, withData bool) (interface{}, error) {
+	var input map[string]interface{}
+	b, _ := json.Marshal(map[string]interface{}{
+		"event": event.Event,
+		"data":  templateData(event, withData),
+	})
+	_ = json.Unmarshal(b, &input)
+
+	buf := &bytes.Buffer{}
+	if err := tmpl.Execute(buf, input); err != nil {
+		return nil, i18n.NewError(ctx, coremsgs.MsgWebhookTemplateFailed, event.Event.ID, err)
+	}
+	output := bytes.TrimSpace(buf.Bytes())
+	if json.Valid(output) {
+		return json.RawMessage(output), nil
+	}
+	return buf.String(), nil
+}
+
+func templateData(event *core.CombinedEventDataDelivery, withData bool) []interface{} {
+	data := make([]interface{}, 0, len(event.Data))
+	if withData {
+		for _, d := range event.Data {
+			if d.Value != nil {
+				data = append(data, d.Value)
+			}
+		}
+	}
+	return data
+}
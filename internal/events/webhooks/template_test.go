@@ -0,0 +1,254 @@
+// Copyright © 2024 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package webhooks
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/hyperledger/firefly-common/pkg/fftypes"
+	"github.com/hyperledger/firefly/mocks/eventsmocks"
+	"github.com/hyperledger/firefly/pkg/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func newTestTemplateEvent(sub *core.Subscription) (*core.EventDelivery, *core.Data) {
+	dataID := fftypes.NewUUID()
+	event := &core.EventDelivery{
+		EnrichedEvent: core.EnrichedEvent{
+			Event: core.Event{
+				ID:   fftypes.NewUUID(),
+				Type: core.EventTypeMessageConfirmed,
+			},
+			Message: &core.Message{
+				Header: core.MessageHeader{
+					ID:  fftypes.NewUUID(),
+					Tag: "invoice",
+				},
+				Data: core.DataRefs{
+					{ID: dataID},
+				},
+			},
+		},
+		Subscription: sub.SubscriptionRef,
+	}
+	data := &core.Data{
+		ID:    dataID,
+		Value: fftypes.JSONAnyPtr(`{"customer": "acme", "amount": 100}`),
+	}
+	return event, data
+}
+
+func TestValidateOptionsTemplate(t *testing.T) {
+	wh, cancel := newTestWebHooks(t)
+	defer cancel()
+
+	opts := &core.SubscriptionOptions{}
+	opts.TransportOptions()["url"] = "/anything"
+	opts.TransportOptions()["template"] = `{"text": {{json .event.type}}}`
+	err := wh.ValidateOptions(wh.ctx, opts)
+	assert.NoError(t, err)
+	assert.NotNil(t, opts.BodyTemplate)
+}
+
+func TestValidateOptionsBadTemplate(t *testing.T) {
+	wh, cancel := newTestWebHooks(t)
+	defer cancel()
+
+	opts := &core.SubscriptionOptions{}
+	opts.TransportOptions()["url"] = "/anything"
+	opts.TransportOptions()["template"] = `{{.event`
+	err := wh.ValidateOptions(wh.ctx, opts)
+	assert.Regexp(t, "FF10491", err)
+}
+
+func TestRequestTemplateJSON(t *testing.T) {
+	wh, cancel := newTestWebHooks(t)
+	defer cancel()
+
+	sub := &core.Subscription{
+		SubscriptionRef: core.SubscriptionRef{
+			Namespace: "ns1",
+		},
+	}
+	event, data := newTestTemplateEvent(sub)
+
+	called := false
+	r := mux.NewRouter()
+	r.HandleFunc("/myapi", func(res http.ResponseWriter, req *http.Request) {
+		var body fftypes.JSONObject
+		err := json.NewDecoder(req.Body).Decode(&body)
+		assert.NoError(t, err)
+		assert.Equal(t, "invoice for acme: 100", body.GetString("text"))
+		assert.Equal(t, event.ID.String(), body.GetString("ref"))
+		res.WriteHeader(200)
+		called = true
+	}).Methods(http.MethodPost)
+	server := httptest.NewServer(r)
+	defer server.Close()
+
+	yes := true
+	sub.Options.WithData = &yes
+	to := sub.Options.TransportOptions()
+	to["url"] = fmt.Sprintf("http://%s/myapi", server.Listener.Addr())
+	to["template"] = `{
+		"text": "{{.event.message.header.tag}} for {{(index .data 0).customer}}: {{(index .data 0).amount}}",
+		"ref": {{json .event.id}}
+	}`
+	err := wh.ValidateOptions(wh.ctx, &sub.Options)
+	assert.NoError(t, err)
+
+	mcb := wh.callbacks.handlers["ns1"].(*eventsmocks.Callbacks)
+	mcb.On("DeliveryResponse", mock.Anything, mock.MatchedBy(func(response *core.EventDeliveryResponse) bool {
+		return !response.Rejected
+	})).Return(nil)
+
+	err = wh.DeliveryRequest(wh.ctx, mock.Anything, sub, event, core.DataArray{data})
+	assert.NoError(t, err)
+	assert.True(t, called)
+
+	mcb.AssertExpectations(t)
+}
+
+func TestRequestTemplateTextBatch(t *testing.T) {
+	wh, cancel := newTestWebHooks(t)
+	defer cancel()
+
+	sub := &core.Subscription{
+		SubscriptionRef: core.SubscriptionRef{
+			Namespace: "ns1",
+		},
+	}
+	event1, data1 := newTestTemplateEvent(sub)
+	event2, data2 := newTestTemplateEvent(sub)
+
+	called := false
+	r := mux.NewRouter()
+	r.HandleFunc("/myapi", func(res http.ResponseWriter, req *http.Request) {
+		var body []string
+		err := json.NewDecoder(req.Body).Decode(&body)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"invoice for acme", "invoice for acme"}, body)
+		res.WriteHeader(200)
+		called = true
+	}).Methods(http.MethodPost)
+	server := httptest.NewServer(r)
+	defer server.Close()
+
+	// Templates are parsed on demand if the options have not been validated
+	yes := true
+	sub.Options.WithData = &yes
+	to := sub.Options.TransportOptions()
+	to["url"] = fmt.Sprintf("http://%s/myapi", server.Listener.Addr())
+	to["template"] = `{{.event.message.header.tag}} for {{(index .data 0).customer}}`
+
+	mcb := wh.callbacks.handlers["ns1"].(*eventsmocks.Callbacks)
+	mcb.On("DeliveryResponse", mock.Anything, mock.MatchedBy(func(response *core.EventDeliveryResponse) bool {
+		return !response.Rejected
+	})).Return(nil)
+
+	err := wh.BatchDeliveryRequest(wh.ctx, mock.Anything, sub, []*core.CombinedEventDataDelivery{
+		{Event: event1, Data: core.DataArray{data1}},
+		{Event: event2, Data: core.DataArray{data2}},
+	})
+	assert.NoError(t, err)
+	assert.True(t, called)
+
+	mcb.AssertExpectations(t)
+}
+
+func TestRequestTemplateExecuteFail(t *testing.T) {
+	wh, cancel := newTestWebHooks(t)
+	defer cancel()
+
+	sub := &core.Subscription{
+		SubscriptionRef: core.SubscriptionRef{
+			Namespace: "ns1",
+		},
+	}
+	event, data := newTestTemplateEvent(sub)
+	to := sub.Options.TransportOptions()
+	to["url"] = "http://localhost:12345/myapi"
+	to["template"] = `{{index .data 0}}`
+
+	// No data is available to the template, as withData is not set
+	_, _, err := wh.attemptRequest(context.Background(), sub, []*core.CombinedEventDataDelivery{
+		{Event: event, Data: core.DataArray{data}},
+	}, false)
+	assert.Regexp(t, "FF10492", err)
+
+	_, _, err = wh.attemptRequest(context.Background(), sub, []*core.CombinedEventDataDelivery{
+		{Event: event, Data: core.DataArray{data}},
+	}, true)
+	assert.Regexp(t, "FF10492", err)
+}
+
+func TestRequestTemplateParseFail(t *testing.T) {
+	wh, cancel := newTestWebHooks(t)
+	defer cancel()
+
+	sub := &core.Subscription{}
+	to := sub.Options.TransportOptions()
+	to["url"] = "http://localhost:12345/myapi"
+	to["template"] = `{{.event`
+
+	_, _, err := wh.attemptRequest(context.Background(), sub, []*core.CombinedEventDataDelivery{}, true)
+	assert.Regexp(t, "FF10491", err)
+}
+
+func TestRequestTemplatePlainTextBody(t *testing.T) {
+	wh, cancel := newTestWebHooks(t)
+	defer cancel()
+
+	sub := &core.Subscription{
+		SubscriptionRef: core.SubscriptionRef{
+			Namespace: "ns1",
+		},
+	}
+	event, _ := newTestTemplateEvent(sub)
+
+	called := false
+	r := mux.NewRouter()
+	r.HandleFunc("/myapi", func(res http.ResponseWriter, req *http.Request) {
+		b, err := io.ReadAll(req.Body)
+		assert.NoError(t, err)
+		assert.Equal(t, "message_confirmed: invoice", string(b))
+		assert.Equal(t, "text/plain", req.Header.Get("Content-Type"))
+		res.WriteHeader(200)
+		called = true
+	}).Methods(http.MethodPost)
+	server := httptest.NewServer(r)
+	defer server.Close()
+
+	to := sub.Options.TransportOptions()
+	to["url"] = fmt.Sprintf("http://%s/myapi", server.Listener.Addr())
+	to["headers"] = map[string]interface{}{"Content-Type": "text/plain"}
+	to["template"] = `{{.event.type}}: {{.event.message.header.tag}}`
+
+	_, _, err := wh.attemptRequest(context.Background(), sub, []*core.CombinedEventDataDelivery{
+		{Event: event},
+	}, false)
+	assert.NoError(t, err)
+	assert.True(t, called)
+}
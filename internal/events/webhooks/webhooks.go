@@ -19,6 +19,7 @@ package webhooks
 import (
 	"bytes"
 	"context"
+	"crypto/cipher"
 	"encoding/base64"
 	"encoding/json"
 	"io"
@@ -26,6 +27,7 @@ import (
 	"net/url"
 	"strings"
 	"sync"
+	"text/template"
 	"time"
 
 	"github.com/go-resty/resty/v2"
@@ -35,17 +37,20 @@ import (
 	"github.com/hyperledger/firefly-common/pkg/i18n"
 	"github.com/hyperledger/firefly-common/pkg/log"
 	"github.com/hyperledger/firefly/internal/coremsgs"
+	"github.com/hyperledger/firefly/internal/tracing"
 	"github.com/hyperledger/firefly/pkg/core"
 	"github.com/hyperledger/firefly/pkg/events"
 )
 
 type WebHooks struct {
-	ctx           context.Context
-	capabilities  *events.Capabilities
-	callbacks     callbacks
-	client        *resty.Client
-	connID        string
-	ffrestyConfig *ffresty.Config
+	ctx            context.Context
+	capabilities   *events.Capabilities
+	callbacks      callbacks
+	client         *resty.Client
+	connID         string
+	ffrestyConfig  *ffresty.Config
+	signingCipher  cipher.AEAD
+	replyTolerance time.Duration
 }
 
 type callbacks struct {
@@ -86,17 +91,26 @@ func (wh *WebHooks) Init(ctx context.Context, config config.Section) (err error)
 
 	client := ffresty.NewWithConfig(ctx, *ffrestyConfig)
 
+	signingConfig := config.SubSection(WebhooksConfSigning)
+	var signingCipher cipher.AEAD
+	if encryptionKey := signingConfig.GetString(WebhooksConfSigningEncryptionKey); encryptionKey != "" {
+		signingCipher = newSigningCipher(encryptionKey)
+	}
+
 	*wh = WebHooks{
 		ctx: log.WithLogField(ctx, "webhook", wh.connID),
 		capabilities: &events.Capabilities{
-			BatchDelivery: true,
+			BatchDelivery:  true,
+			Connectionless: true,
 		},
 		callbacks: callbacks{
 			handlers: make(map[string]events.Callbacks),
 		},
-		client:        client,
-		connID:        connID,
-		ffrestyConfig: ffrestyConfig,
+		client:         client,
+		connID:         connID,
+		ffrestyConfig:  ffrestyConfig,
+		signingCipher:  signingCipher,
+		replyTolerance: signingConfig.GetDuration(WebhooksConfSigningReplyTolerance),
 	}
 	return nil
 }
@@ -138,7 +152,7 @@ func (p *whPayload) firstData() fftypes.JSONObject {
 	return p.parsedData0
 }
 
-func (wh *WebHooks) buildPayload(ctx context.Context, sub *core.Subscription, event *core.CombinedEventDataDelivery) *whPayload {
+func (wh *WebHooks) buildPayload(ctx context.Context, sub *core.Subscription, tmpl *template.Template, event *core.CombinedEventDataDelivery) (*whPayload, error) {
 	log.L(wh.ctx).Debugf("Webhook-> %s event %s on subscription %s", sub.Options.URL, event.Event.ID, sub.ID)
 	withData := sub.Options.WithData != nil && *sub.Options.WithData
 	options := sub.Options.TransportOptions()
@@ -167,6 +181,13 @@ func (wh *WebHooks) buildPayload(ctx context.Context, sub *core.Subscription, ev
 	}
 
 	switch {
+	case tmpl != nil:
+		// The template reshapes the event and its data into the body
+		body, err := renderTemplate(ctx, tmpl, event, withData)
+		if err != nil {
+			return nil, err
+		}
+		p.body = body
 	case bodyFromFirstData != nil:
 		// We might have been told to extract a body from the first data record
 		p.body = bodyFromFirstData.String()
@@ -180,7 +201,7 @@ func (wh *WebHooks) buildPayload(ctx context.Context, sub *core.Subscription, ev
 		// Just send the event itself
 		p.body = event.Event
 	}
-	return p
+	return p, nil
 }
 
 func (wh *WebHooks) buildRequest(ctx context.Context, restyClient *resty.Client, options fftypes.JSONObject, p *whPayload) (req *whRequest, err error) {
@@ -349,6 +370,20 @@ func (wh *WebHooks) ValidateOptions(ctx context.Context, options *core.Subscript
 		newFFRestyConfig.TLSClientConfig = options.TLSConfig
 	}
 
+	if err := wh.resolveSigningKeys(ctx, options); err != nil {
+		return err
+	}
+
+	// Parse the template once, so it can be used for every delivery on the subscription
+	options.BodyTemplate = nil
+	if tmplText := options.TransportOptions().GetString("template"); tmplText != "" {
+		tmpl, err := parseTemplate(ctx, tmplText)
+		if err != nil {
+			return err
+		}
+		options.BodyTemplate = tmpl
+	}
+
 	// NOTE: this is the plugin context, as the context passed through can be terminated as part of a
 	// API call or anything else and we want to use this client later on!!
 	// So these clients should live as long as the plugin exists
@@ -360,17 +395,28 @@ func (wh *WebHooks) ValidateOptions(ctx context.Context, options *core.Subscript
 
 func (wh *WebHooks) attemptRequest(ctx context.Context, sub *core.Subscription, events []*core.CombinedEventDataDelivery, batch bool) (req *whRequest, res *whResponse, err error) {
 
+	tmpl, err := subscriptionTemplate(ctx, &sub.Options)
+	if err != nil {
+		return nil, nil, err
+	}
+
 	var payloadForBuildingRequest *whPayload // only set for a single event delivery
 	var requestBody interface{}
 	if len(events) == 1 && !batch {
-		payloadForBuildingRequest = wh.buildPayload(ctx, sub, events[0])
+		payloadForBuildingRequest, err = wh.buildPayload(ctx, sub, tmpl, events[0])
+		if err != nil {
+			return nil, nil, err
+		}
 		// Payload for POST/PATCH/PUT is what is calculated for a single event in buildPayload
 		requestBody = payloadForBuildingRequest.body
 	} else {
 		batchBody := make([]interface{}, len(events))
 		for i, event := range events {
 			// We only use the body itself from the whPayload - then discard it.
-			p := wh.buildPayload(ctx, sub, event)
+			p, err := wh.buildPayload(ctx, sub, tmpl, event)
+			if err != nil {
+				return nil, nil, err
+			}
 			batchBody[i] = p.body
 		}
 		// Payload for POST/PATCH/PUT is the array of outputs calculated for a each event in buildPayload
@@ -387,17 +433,43 @@ func (wh *WebHooks) attemptRequest(ctx context.Context, sub *core.Subscription,
 		return nil, nil, err
 	}
 
-	if req.method == http.MethodPost || req.method == http.MethodPatch || req.method == http.MethodPut {
+	options := sub.Options.TransportOptions()
+	hasBody := req.method == http.MethodPost || req.method == http.MethodPatch || req.method == http.MethodPut
+	signingKeys := sub.Options.SigningKeys
+	if signingKeys != nil {
+		// Sign the exact bytes that are sent
+		var bodyBytes []byte
+		if hasBody {
+			bodyBytes = requestBodyBytes(requestBody)
+			req.r.SetBody(bodyBytes)
+		}
+		req.r.SetHeader(signatureHeader(options), signBody(signingKeys, time.Now(), bodyBytes))
+	} else if hasBody {
 		req.r.SetBody(requestBody)
 	}
 
+	_, span := tracing.StartClientSpan(ctx, "webhook.deliver", req.method, req.url, req.r.Header)
 	resp, err := req.r.Execute(req.method, req.url)
 	if err != nil {
+		tracing.EndClientSpan(span, 0, err)
 		log.L(ctx).Errorf("Webhook<- %s %s on subscription %s failed: %s", req.method, req.url, sub.ID, err)
 		return nil, nil, err
 	}
+	tracing.EndClientSpan(span, resp.StatusCode(), nil)
 	defer func() { _ = resp.RawBody().Close() }()
 
+	var resBody io.Reader = resp.RawBody()
+	if signingKeys != nil && options.GetBool("reply") && options.GetObject("signing").GetBool("verifyReply") {
+		// The reply must be signed with the same secret, before we act on it
+		b, _ := io.ReadAll(resBody)
+		header := signatureHeader(options)
+		if err := wh.verifyBody(ctx, signingKeys, header, resp.Header().Get(header), time.Now(), b); err != nil {
+			log.L(ctx).Errorf("Webhook<- %s %s on subscription %s returned an unverified reply: %s", req.method, req.url, sub.ID, err)
+			return nil, nil, err
+		}
+		resBody = bytes.NewReader(b)
+	}
+
 	res = &whResponse{
 		Status:  resp.StatusCode(),
 		Headers: fftypes.JSONObject{},
@@ -415,7 +487,7 @@ func (wh *WebHooks) attemptRequest(ctx context.Context, sub *core.Subscription,
 	res.Headers["Content-Type"] = contentType
 	if req.forceJSON || strings.HasPrefix(contentType, "application/json") {
 		var resData interface{}
-		err = json.NewDecoder(resp.RawBody()).Decode(&resData)
+		err = json.NewDecoder(resBody).Decode(&resData)
 		if err != nil {
 			return nil, nil, i18n.WrapError(ctx, err, coremsgs.MsgWebhooksReplyBadJSON)
 		}
@@ -426,7 +498,7 @@ func (wh *WebHooks) attemptRequest(ctx context.Context, sub *core.Subscription,
 		buf := &bytes.Buffer{}
 		buf.WriteByte('"')
 		b64Encoder := base64.NewEncoder(base64.StdEncoding, buf)
-		_, _ = io.Copy(b64Encoder, resp.RawBody())
+		_, _ = io.Copy(b64Encoder, resBody)
 		_ = b64Encoder.Close()
 		buf.WriteByte('"')
 		res.Body = fftypes.JSONAnyPtrBytes(buf.Bytes())
@@ -213,7 +213,7 @@ func (wc *websocketConnection) receiveLoop() {
 			if err == nil {
 				msg.Namespace, err = wc.assertNamespace(msg.Namespace)
 				if err == nil {
-					err = wc.authorizeMessage(msg.Namespace)
+					err = wc.authorizeMessage(msg.Namespace, msg.Name)
 				}
 				if err == nil {
 					err = wc.handleStart(&msg)
@@ -499,7 +499,7 @@ func (wc *websocketConnection) waitClose() {
 	<-wc.receiverDone
 }
 
-func (wc *websocketConnection) authorizeMessage(ns string) error {
+func (wc *websocketConnection) authorizeMessage(ns, subscription string) error {
 	wc.mux.Lock()
 	defer wc.mux.Unlock()
 	authReq := &fftypes.AuthReq{
@@ -507,7 +507,8 @@ func (wc *websocketConnection) authorizeMessage(ns string) error {
 		Header:    wc.header,
 	}
 	if wc.auth != nil {
-		if err := wc.auth.Authorize(wc.ctx, authReq); err != nil {
+		ctx := core.WithAuthContext(wc.ctx, &core.AuthContext{EventStream: true, Subscription: subscription})
+		if err := wc.auth.Authorize(ctx, authReq); err != nil {
 			return err
 		}
 	}
@@ -44,6 +44,9 @@ import (
 type testAuthorizer struct{}
 
 func (t *testAuthorizer) Authorize(ctx context.Context, authReq *fftypes.AuthReq) error {
+	if ac := core.GetAuthContext(ctx); !ac.EventStream || ac.Subscription == "forbidden" {
+		return i18n.NewError(ctx, i18n.MsgForbidden)
+	}
 	if authReq.Namespace == "ns1" {
 		return nil
 	}
@@ -1219,4 +1222,14 @@ func TestHandleStartWrongNamespace(t *testing.T) {
 	err := wc.handleStart(startMessage)
 	assert.Error(t, err)
 	assert.Regexp(t, "FF10462", err)
-}
\ No newline at end of file
+}
+func TestAuthorizeMessageSubscription(t *testing.T) {
+	wsc := &websocketConnection{
+		ctx:  context.Background(),
+		auth: &testAuthorizer{},
+	}
+	err := wsc.authorizeMessage("ns1", "sub1")
+	assert.NoError(t, err)
+	err = wsc.authorizeMessage("ns1", "forbidden")
+	assert.Regexp(t, "FF00170", err)
+}
@@ -437,6 +437,7 @@ func (im *identityManager) cachedIdentityLookupByVerifierRef(ctx context.Context
 	if cachedValue := im.identityCache.Get(cacheKey); cachedValue != nil {
 		return cachedValue.(*core.Identity), nil
 	}
+	ctx = database.WithPrimaryReads(ctx)
 	verifier, err := im.database.GetVerifierByValue(ctx, verifierRef.Type, namespace, verifierRef.Value)
 	if err != nil {
 		return nil, err
@@ -475,6 +476,7 @@ func (im *identityManager) cachedIdentityLookup(ctx context.Context, namespace,
 	if cachedValue := im.identityCache.Get(cacheKey); cachedValue != nil {
 		identity = cachedValue.(*core.Identity)
 	} else {
+		ctx = database.WithPrimaryReads(ctx)
 		if strings.HasPrefix(didLookupStr, core.DIDPrefix) {
 			if !strings.HasPrefix(didLookupStr, core.FireFlyDIDPrefix) {
 				return nil, false, i18n.NewError(ctx, coremsgs.MsgDIDResolverUnknown, didLookupStr)
@@ -532,7 +534,7 @@ func (im *identityManager) cachedIdentityLookupByID(ctx context.Context, namespa
 	if cachedValue := im.identityCache.Get(cacheKey); cachedValue != nil {
 		identity = cachedValue.(*core.Identity)
 	} else {
-		identity, err = im.database.GetIdentityByID(ctx, namespace, id)
+		identity, err = im.database.GetIdentityByID(database.WithPrimaryReads(ctx), namespace, id)
 		if err != nil {
 			return nil, err
 		}
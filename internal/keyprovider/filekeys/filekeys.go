@@ -0,0 +1,118 @@
+// Copyright © 2024 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package filekeys
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"os"
+
+	"github.com/ghodss/yaml"
+	"github.com/hyperledger/firefly-common/pkg/config"
+	"github.com/hyperledger/firefly-common/pkg/i18n"
+	"github.com/hyperledger/firefly-common/pkg/log"
+	"github.com/hyperledger/firefly/internal/coremsgs"
+)
+
+// FileKeys is a key provider for local use, that reads 256-bit AES key encryption keys from a file.
+// Data keys are wrapped with AES-GCM, using the ID of the key encryption key as additional data.
+type FileKeys struct {
+	currentKeyID string
+	keys         map[string]cipher.AEAD
+}
+
+// keyFile is the format of the file, which lists every key that might have wrapped a data key.
+// Keys that are no longer current are kept until the data keys have been re-wrapped.
+type keyFile struct {
+	Current string            `json:"current"`
+	Keys    map[string]string `json:"keys"` // base64 encoded 32 byte keys, by ID
+}
+
+func (fk *FileKeys) Name() string {
+	return "file"
+}
+
+func (fk *FileKeys) Init(ctx context.Context, config config.Section) error {
+	path := config.GetString(FileKeysConfPath)
+	if path == "" {
+		return i18n.NewError(ctx, coremsgs.MsgMissingPluginConfig, config.Resolve(FileKeysConfPath), "file")
+	}
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return i18n.WrapError(ctx, err, coremsgs.MsgKeyFileReadFailed, path)
+	}
+	var kf keyFile
+	if err := yaml.Unmarshal(b, &kf); err != nil {
+		return i18n.NewError(ctx, coremsgs.MsgKeyFileInvalid, path, err)
+	}
+	if _, ok := kf.Keys[kf.Current]; !ok {
+		return i18n.NewError(ctx, coremsgs.MsgKeyFileInvalid, path, "the current key is not in the keys")
+	}
+	fk.currentKeyID = kf.Current
+	fk.keys = make(map[string]cipher.AEAD, len(kf.Keys))
+	for id, encoded := range kf.Keys {
+		key, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil || len(key) != 32 {
+			return i18n.NewError(ctx, coremsgs.MsgKeyFileInvalid, path, "key '"+id+"' is not a base64 encoded 32 byte key")
+		}
+		block, _ := aes.NewCipher(key) // only fails for a bad key length
+		fk.keys[id], _ = cipher.NewGCM(block)
+	}
+	log.L(ctx).Infof("Loaded %d key encryption keys from '%s' (current=%s)", len(fk.keys), path, fk.currentKeyID)
+	return nil
+}
+
+func (fk *FileKeys) CurrentKeyID() string {
+	return fk.currentKeyID
+}
+
+func (fk *FileKeys) getKey(ctx context.Context, keyID string) (cipher.AEAD, error) {
+	aead, ok := fk.keys[keyID]
+	if !ok {
+		return nil, i18n.NewError(ctx, coremsgs.MsgEncryptionKeyNotFound, keyID)
+	}
+	return aead, nil
+}
+
+// WrapKey returns the nonce, followed by the sealed data key
+func (fk *FileKeys) WrapKey(ctx context.Context, keyID string, dataKey []byte) ([]byte, error) {
+	aead, err := fk.getKey(ctx, keyID)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	_, _ = rand.Read(nonce)
+	return aead.Seal(nonce, nonce, dataKey, []byte(keyID)), nil
+}
+
+func (fk *FileKeys) UnwrapKey(ctx context.Context, keyID string, wrapped []byte) ([]byte, error) {
+	aead, err := fk.getKey(ctx, keyID)
+	if err != nil {
+		return nil, err
+	}
+	if len(wrapped) < aead.NonceSize() {
+		return nil, i18n.NewError(ctx, coremsgs.MsgDataKeyUnwrapFailed, keyID)
+	}
+	dataKey, err := aead.Open(nil, wrapped[:aead.NonceSize()], wrapped[aead.NonceSize():], []byte(keyID))
+	if err != nil {
+		return nil, i18n.WrapError(ctx, err, coremsgs.MsgDataKeyUnwrapFailed, keyID)
+	}
+	return dataKey, nil
+}
@@ -0,0 +1,128 @@
+// Copyright © 2024 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package filekeys
+
+import (
+	"context"
+	"encoding/base64"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hyperledger/firefly-common/pkg/config"
+	"github.com/hyperledger/firefly/internal/coreconfig"
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	key1 = base64.StdEncoding.EncodeToString([]byte("0123456789abcdef0123456789abcdef"))
+	key2 = base64.StdEncoding.EncodeToString([]byte("fedcba9876543210fedcba9876543210"))
+)
+
+func newTestFileKeys(t *testing.T, content string) (*FileKeys, error) {
+	path := filepath.Join(t.TempDir(), "keys.yaml")
+	err := os.WriteFile(path, []byte(content), 0600)
+	assert.NoError(t, err)
+	coreconfig.Reset()
+	fk := &FileKeys{}
+	conf := config.RootSection("filekeys")
+	fk.InitConfig(conf)
+	conf.Set(FileKeysConfPath, path)
+	return fk, fk.Init(context.Background(), conf)
+}
+
+func TestWrapUnwrapKey(t *testing.T) {
+	fk, err := newTestFileKeys(t, "current: key2\nkeys:\n  key1: "+key1+"\n  key2: "+key2+"\n")
+	assert.NoError(t, err)
+	assert.Equal(t, "file", fk.Name())
+	assert.Equal(t, "key2", fk.CurrentKeyID())
+
+	ctx := context.Background()
+	dataKey := []byte("data key of any length")
+	wrapped, err := fk.WrapKey(ctx, "key1", dataKey)
+	assert.NoError(t, err)
+	assert.NotContains(t, string(wrapped), string(dataKey))
+
+	unwrapped, err := fk.UnwrapKey(ctx, "key1", wrapped)
+	assert.NoError(t, err)
+	assert.Equal(t, dataKey, unwrapped)
+
+	// The key ID is authenticated, as well as the key
+	_, err = fk.UnwrapKey(ctx, "key2", wrapped)
+	assert.Regexp(t, "FF10526.*key2", err)
+}
+
+func TestInitJSON(t *testing.T) {
+	fk, err := newTestFileKeys(t, `{"current":"key1","keys":{"key1":"`+key1+`"}}`)
+	assert.NoError(t, err)
+	assert.Equal(t, "key1", fk.CurrentKeyID())
+}
+
+func TestInitMissingPath(t *testing.T) {
+	coreconfig.Reset()
+	fk := &FileKeys{}
+	conf := config.RootSection("filekeys")
+	fk.InitConfig(conf)
+	err := fk.Init(context.Background(), conf)
+	assert.Regexp(t, "FF10138.*path", err)
+}
+
+func TestInitFileNotFound(t *testing.T) {
+	coreconfig.Reset()
+	fk := &FileKeys{}
+	conf := config.RootSection("filekeys")
+	fk.InitConfig(conf)
+	conf.Set(FileKeysConfPath, filepath.Join(t.TempDir(), "missing.yaml"))
+	err := fk.Init(context.Background(), conf)
+	assert.Regexp(t, "FF10523", err)
+}
+
+func TestInitBadYAML(t *testing.T) {
+	_, err := newTestFileKeys(t, "!! not yaml")
+	assert.Regexp(t, "FF10524", err)
+}
+
+func TestInitCurrentKeyMissing(t *testing.T) {
+	_, err := newTestFileKeys(t, "current: key2\nkeys:\n  key1: "+key1+"\n")
+	assert.Regexp(t, "FF10524.*current key", err)
+}
+
+func TestInitBadKey(t *testing.T) {
+	_, err := newTestFileKeys(t, "current: key1\nkeys:\n  key1: "+base64.StdEncoding.EncodeToString([]byte("short"))+"\n")
+	assert.Regexp(t, "FF10524.*key1", err)
+}
+
+func TestWrapKeyNotFound(t *testing.T) {
+	fk, err := newTestFileKeys(t, "current: key1\nkeys:\n  key1: "+key1+"\n")
+	assert.NoError(t, err)
+	_, err = fk.WrapKey(context.Background(), "key2", []byte("data key"))
+	assert.Regexp(t, "FF10525.*key2", err)
+}
+
+func TestUnwrapKeyNotFound(t *testing.T) {
+	fk, err := newTestFileKeys(t, "current: key1\nkeys:\n  key1: "+key1+"\n")
+	assert.NoError(t, err)
+	_, err = fk.UnwrapKey(context.Background(), "key2", []byte("wrapped"))
+	assert.Regexp(t, "FF10525.*key2", err)
+}
+
+func TestUnwrapKeyTooShort(t *testing.T) {
+	fk, err := newTestFileKeys(t, "current: key1\nkeys:\n  key1: "+key1+"\n")
+	assert.NoError(t, err)
+	_, err = fk.UnwrapKey(context.Background(), "key1", []byte("short"))
+	assert.Regexp(t, "FF10526.*key1", err)
+}
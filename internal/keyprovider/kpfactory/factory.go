@@ -0,0 +1,45 @@
+// Copyright © 2024 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kpfactory
+
+import (
+	"context"
+
+	"github.com/hyperledger/firefly-common/pkg/config"
+	"github.com/hyperledger/firefly-common/pkg/i18n"
+	"github.com/hyperledger/firefly/internal/coremsgs"
+	"github.com/hyperledger/firefly/internal/keyprovider/filekeys"
+	"github.com/hyperledger/firefly/pkg/keyprovider"
+)
+
+var pluginsByName = map[string]func() keyprovider.Plugin{
+	(*filekeys.FileKeys)(nil).Name(): func() keyprovider.Plugin { return &filekeys.FileKeys{} },
+}
+
+func InitConfig(config config.Section) {
+	for name, plugin := range pluginsByName {
+		plugin().InitConfig(config.SubSection(name))
+	}
+}
+
+func GetPlugin(ctx context.Context, pluginType string) (keyprovider.Plugin, error) {
+	plugin, ok := pluginsByName[pluginType]
+	if !ok {
+		return nil, i18n.NewError(ctx, coremsgs.MsgUnknownKeyProviderPlugin, pluginType)
+	}
+	return plugin(), nil
+}
@@ -0,0 +1,188 @@
+// Copyright © 2024 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package leader
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/hyperledger/firefly-common/pkg/config"
+	"github.com/hyperledger/firefly-common/pkg/fftypes"
+	"github.com/hyperledger/firefly-common/pkg/log"
+	"github.com/hyperledger/firefly/internal/coreconfig"
+	"github.com/hyperledger/firefly/pkg/core"
+	"github.com/hyperledger/firefly/pkg/database"
+)
+
+const leaseName = "leader"
+
+// replicaSuffix makes the default replica name unique to this process, across all namespaces
+var replicaSuffix = fftypes.NewUUID().String()[0:8]
+
+type Elector interface {
+	Start()
+	WaitStop()
+
+	// Status returns the leader election status of the namespace, as seen by this replica
+	Status() *core.LeaderStatus
+}
+
+// Callbacks are made from the election loop, so must not wait for the elector to stop
+type Callbacks interface {
+	LeaderElected()
+	LeaderDeposed()
+}
+
+// elector competes with the other replicas sharing the database for the leader lease of a namespace.
+// The leader renews the lease on every interval. The other replicas try to acquire it on every interval,
+// and succeed once it has expired. A leader that cannot renew its lease before it expires is deposed.
+type elector struct {
+	ctx           context.Context
+	namespace     string
+	database      database.Plugin
+	callbacks     Callbacks
+	replica       string
+	leaseDuration time.Duration
+	renewInterval time.Duration
+	mux           sync.Mutex
+	leader        bool
+	holder        string
+	expires       *fftypes.FFTime
+	renewedAt     time.Time
+	since         *fftypes.FFTime
+	done          chan struct{}
+}
+
+func NewElector(ctx context.Context, ns string, di database.Plugin, callbacks Callbacks) Elector {
+	le := &elector{
+		ctx:           log.WithLogField(ctx, "role", "leader-election"),
+		namespace:     ns,
+		database:      di,
+		callbacks:     callbacks,
+		replica:       config.GetString(coreconfig.LeaderElectionReplicaName),
+		leaseDuration: config.GetDuration(coreconfig.LeaderElectionLeaseDuration),
+		renewInterval: config.GetDuration(coreconfig.LeaderElectionRenewInterval),
+	}
+	if le.replica == "" {
+		hostname, _ := os.Hostname()
+		le.replica = fmt.Sprintf("%s-%s", hostname, replicaSuffix)
+	}
+	if le.renewInterval <= 0 || le.renewInterval >= le.leaseDuration {
+		le.renewInterval = le.leaseDuration / 3
+		log.L(le.ctx).Warnf("Leader election renew interval must be less than the lease duration. Using %s", le.renewInterval)
+	}
+	return le
+}
+
+func (le *elector) Start() {
+	log.L(le.ctx).Infof("Replica '%s' joining leader election", le.replica)
+	le.done = make(chan struct{})
+	go le.electionLoop()
+}
+
+func (le *elector) WaitStop() {
+	if le.done != nil {
+		<-le.done
+	}
+}
+
+func (le *elector) Status() *core.LeaderStatus {
+	le.mux.Lock()
+	defer le.mux.Unlock()
+	return &core.LeaderStatus{
+		Enabled: true,
+		Replica: le.replica,
+		Leader:  le.leader,
+		Holder:  le.holder,
+		Expires: le.expires,
+		Since:   le.since,
+	}
+}
+
+func (le *elector) electionLoop() {
+	defer close(le.done)
+	ticker := time.NewTicker(le.renewInterval)
+	defer ticker.Stop()
+	for {
+		le.acquire()
+		select {
+		case <-ticker.C:
+		case <-le.ctx.Done():
+			le.release()
+			log.L(le.ctx).Debugf("Leader election exiting")
+			return
+		}
+	}
+}
+
+func (le *elector) acquire() {
+	// The expiry of the lease is set by the clock of the database. Our own lease lasts at least the lease duration
+	// from before the request was sent, so that is how long we can carry on as the leader by our own clock.
+	now := time.Now()
+	current, err := le.database.AcquireLease(le.ctx, le.namespace, leaseName, le.replica, le.leaseDuration)
+
+	le.mux.Lock()
+	wasLeader := le.leader
+	switch {
+	case err != nil:
+		log.L(le.ctx).Errorf("Failed to acquire leader lease: %s", err)
+		// We can only carry on as the leader if our lease is still valid until the next attempt
+		if le.leader && now.Add(le.renewInterval).After(le.renewedAt.Add(le.leaseDuration)) {
+			le.leader = false
+		}
+	case current.Holder == le.replica:
+		if !le.leader {
+			le.leader = true
+			le.since = fftypes.Now()
+		}
+		le.holder = current.Holder
+		le.expires = current.Expires
+		le.renewedAt = now
+	default:
+		le.leader = false
+		le.holder = current.Holder
+		le.expires = current.Expires
+	}
+	isLeader := le.leader
+	le.mux.Unlock()
+
+	switch {
+	case isLeader && !wasLeader:
+		log.L(le.ctx).Infof("Replica '%s' elected leader", le.replica)
+		le.callbacks.LeaderElected()
+	case wasLeader && !isLeader:
+		log.L(le.ctx).Errorf("Replica '%s' is no longer the leader (holder='%s')", le.replica, le.holder)
+		le.callbacks.LeaderDeposed()
+	}
+}
+
+// release allows another replica to take over straight away, rather than when the lease expires
+func (le *elector) release() {
+	le.mux.Lock()
+	leader := le.leader
+	le.leader = false
+	le.mux.Unlock()
+	if leader {
+		// Our context is already cancelled
+		if err := le.database.ReleaseLease(context.Background(), le.namespace, leaseName, le.replica); err != nil {
+			log.L(le.ctx).Warnf("Failed to release leader lease: %s", err)
+		}
+	}
+}
@@ -0,0 +1,159 @@
+// Copyright © 2024 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package leader
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/firefly-common/pkg/config"
+	"github.com/hyperledger/firefly-common/pkg/fftypes"
+	"github.com/hyperledger/firefly/internal/coreconfig"
+	"github.com/hyperledger/firefly/mocks/databasemocks"
+	"github.com/hyperledger/firefly/pkg/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type testCallbacks struct {
+	elected chan struct{}
+	deposed chan struct{}
+}
+
+func (tc *testCallbacks) LeaderElected() {
+	tc.elected <- struct{}{}
+}
+
+func (tc *testCallbacks) LeaderDeposed() {
+	tc.deposed <- struct{}{}
+}
+
+func newTestElector(t *testing.T) (*elector, *databasemocks.Plugin, *testCallbacks, func()) {
+	coreconfig.Reset()
+	config.Set(coreconfig.LeaderElectionReplicaName, "replica1")
+	config.Set(coreconfig.LeaderElectionRenewInterval, "1ms")
+	ctx, cancel := context.WithCancel(context.Background())
+	mdi := &databasemocks.Plugin{}
+	tc := &testCallbacks{
+		elected: make(chan struct{}, 1),
+		deposed: make(chan struct{}, 1),
+	}
+	le := NewElector(ctx, "ns1", mdi, tc).(*elector)
+	return le, mdi, tc, func() {
+		cancel()
+		le.WaitStop()
+		mdi.AssertExpectations(t)
+	}
+}
+
+func leaseHeldBy(holder string, expires time.Time) *core.Lease {
+	ft := fftypes.FFTime(expires)
+	return &core.Lease{Namespace: "ns1", Name: leaseName, Holder: holder, Expires: &ft}
+}
+
+func TestNewElectorDefaults(t *testing.T) {
+	coreconfig.Reset()
+	config.Set(coreconfig.LeaderElectionLeaseDuration, "15s")
+	config.Set(coreconfig.LeaderElectionRenewInterval, "20s")
+	le := NewElector(context.Background(), "ns1", &databasemocks.Plugin{}, &testCallbacks{}).(*elector)
+	assert.True(t, strings.HasSuffix(le.replica, "-"+replicaSuffix))
+	assert.Equal(t, 5*time.Second, le.renewInterval)
+
+	le.WaitStop() // not started
+}
+
+func TestElectedThenRelease(t *testing.T) {
+	le, mdi, tc, done := newTestElector(t)
+
+	mdi.On("AcquireLease", mock.Anything, "ns1", leaseName, "replica1", le.leaseDuration).Return(leaseHeldBy("replica1", time.Now().Add(time.Minute)), nil)
+	mdi.On("ReleaseLease", mock.Anything, "ns1", leaseName, "replica1").Return(fmt.Errorf("pop"))
+
+	le.Start()
+	<-tc.elected
+
+	status := le.Status()
+	assert.True(t, status.Enabled)
+	assert.True(t, status.Leader)
+	assert.Equal(t, "replica1", status.Replica)
+	assert.Equal(t, "replica1", status.Holder)
+	assert.NotNil(t, status.Since)
+
+	done()
+	assert.False(t, le.Status().Leader)
+}
+
+func TestFollower(t *testing.T) {
+	le, mdi, tc, done := newTestElector(t)
+	defer done()
+
+	mdi.On("AcquireLease", mock.Anything, "ns1", leaseName, "replica1", le.leaseDuration).Return(leaseHeldBy("replica2", time.Now().Add(time.Minute)), nil)
+
+	le.acquire()
+
+	status := le.Status()
+	assert.False(t, status.Leader)
+	assert.Equal(t, "replica2", status.Holder)
+	assert.Nil(t, status.Since)
+	assert.Empty(t, tc.elected)
+}
+
+func TestDeposedByOtherHolder(t *testing.T) {
+	le, mdi, tc, done := newTestElector(t)
+	defer done()
+
+	mdi.On("AcquireLease", mock.Anything, "ns1", leaseName, "replica1", le.leaseDuration).Return(leaseHeldBy("replica1", time.Now().Add(time.Minute)), nil).Once()
+	mdi.On("AcquireLease", mock.Anything, "ns1", leaseName, "replica1", le.leaseDuration).Return(leaseHeldBy("replica2", time.Now().Add(time.Minute)), nil).Once()
+
+	le.acquire()
+	<-tc.elected
+	le.acquire()
+	<-tc.deposed
+	assert.False(t, le.Status().Leader)
+}
+
+func TestAcquireFailKeepsValidLease(t *testing.T) {
+	le, mdi, tc, done := newTestElector(t)
+	defer done()
+
+	mdi.On("AcquireLease", mock.Anything, "ns1", leaseName, "replica1", le.leaseDuration).Return(leaseHeldBy("replica1", time.Now().Add(time.Minute)), nil).Once()
+	mdi.On("AcquireLease", mock.Anything, "ns1", leaseName, "replica1", le.leaseDuration).Return(nil, fmt.Errorf("pop")).Once()
+
+	le.acquire()
+	<-tc.elected
+	le.acquire()
+	assert.True(t, le.Status().Leader)
+	assert.Empty(t, tc.deposed)
+}
+
+func TestAcquireFailDeposedOnExpiry(t *testing.T) {
+	le, mdi, tc, done := newTestElector(t)
+	defer done()
+
+	// The expiry reported by the database is not compared with our own clock
+	mdi.On("AcquireLease", mock.Anything, "ns1", leaseName, "replica1", le.leaseDuration).Return(leaseHeldBy("replica1", time.Now().Add(time.Hour)), nil).Once()
+	mdi.On("AcquireLease", mock.Anything, "ns1", leaseName, "replica1", le.leaseDuration).Return(nil, fmt.Errorf("pop")).Once()
+
+	le.acquire()
+	<-tc.elected
+	le.renewedAt = time.Now().Add(-le.leaseDuration)
+	le.acquire()
+	<-tc.deposed
+	assert.False(t, le.Status().Leader)
+}
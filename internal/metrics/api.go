@@ -0,0 +1,41 @@
+// Copyright © 2024 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var APIRequestsRejectedCounter *prometheus.CounterVec
+
+// APIRequestsRejectedCounterName is the prometheus metric for tracking the total number of API requests rejected by rate limits and quotas
+var APIRequestsRejectedCounterName = "ff_api_requests_rejected_total"
+
+var NamespaceLabelName = "namespace"
+var ScopeLabelName = "scope"
+var ReasonLabelName = "reason"
+
+func InitAPIMetrics() {
+	APIRequestsRejectedCounter = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: APIRequestsRejectedCounterName,
+		Help: "Number of API requests rejected by rate limits and concurrency quotas",
+	}, []string{NamespaceLabelName, ScopeLabelName, ReasonLabelName})
+}
+
+func RegisterAPIMetrics() {
+	registry.MustRegister(APIRequestsRejectedCounter)
+}
@@ -0,0 +1,75 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var ContractListenerCheckpointGauge *prometheus.GaugeVec
+var ContractListenerChainHeadGauge *prometheus.GaugeVec
+var ContractListenerLagBlocksGauge *prometheus.GaugeVec
+var ContractListenerLagSecondsGauge *prometheus.GaugeVec
+var ContractListenerBehindGauge *prometheus.GaugeVec
+
+// ContractListenerCheckpointGaugeName is the prometheus metric for tracking the last block processed by each contract listener
+var ContractListenerCheckpointGaugeName = "ff_contract_listener_checkpoint_block"
+
+// ContractListenerChainHeadGaugeName is the prometheus metric for tracking the head of the chain, as seen by each contract listener
+var ContractListenerChainHeadGaugeName = "ff_contract_listener_chain_head_block"
+
+// ContractListenerLagBlocksGaugeName is the prometheus metric for tracking how many blocks each contract listener is behind
+var ContractListenerLagBlocksGaugeName = "ff_contract_listener_lag_blocks"
+
+// ContractListenerLagSecondsGaugeName is the prometheus metric for tracking how many seconds each contract listener is behind
+var ContractListenerLagSecondsGaugeName = "ff_contract_listener_lag_seconds"
+
+// ContractListenerBehindGaugeName is the prometheus metric that is 1 for each contract listener that is behind its configured thresholds
+var ContractListenerBehindGaugeName = "ff_contract_listener_behind"
+
+var ListenerLabelName = "listener"
+
+func InitContractListenerMetrics() {
+	ContractListenerCheckpointGauge = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: ContractListenerCheckpointGaugeName,
+		Help: "Last block processed by the contract listener",
+	}, []string{NamespaceLabelName, ListenerLabelName})
+	ContractListenerChainHeadGauge = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: ContractListenerChainHeadGaugeName,
+		Help: "Head of the chain when the contract listener was checked",
+	}, []string{NamespaceLabelName, ListenerLabelName})
+	ContractListenerLagBlocksGauge = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: ContractListenerLagBlocksGaugeName,
+		Help: "Number of confirmed blocks not yet processed by the contract listener",
+	}, []string{NamespaceLabelName, ListenerLabelName})
+	ContractListenerLagSecondsGauge = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: ContractListenerLagSecondsGaugeName,
+		Help: "Age of the oldest confirmed block not yet processed by the contract listener, relative to the newest",
+	}, []string{NamespaceLabelName, ListenerLabelName})
+	ContractListenerBehindGauge = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: ContractListenerBehindGaugeName,
+		Help: "Whether the contract listener is further behind than the configured thresholds",
+	}, []string{NamespaceLabelName, ListenerLabelName})
+}
+
+func RegisterContractListenerMetrics() {
+	registry.MustRegister(ContractListenerCheckpointGauge)
+	registry.MustRegister(ContractListenerChainHeadGauge)
+	registry.MustRegister(ContractListenerLagBlocksGauge)
+	registry.MustRegister(ContractListenerLagSecondsGauge)
+	registry.MustRegister(ContractListenerBehindGauge)
+}
@@ -25,6 +25,7 @@ import (
 	"github.com/hyperledger/firefly-common/pkg/fftypes"
 	"github.com/hyperledger/firefly/internal/coreconfig"
 	"github.com/hyperledger/firefly/pkg/core"
+	"github.com/prometheus/client_golang/prometheus"
 )
 
 var mutex = &sync.Mutex{}
@@ -39,6 +40,9 @@ type Manager interface {
 	BlockchainTransaction(location, methodName string)
 	BlockchainQuery(location, methodName string)
 	BlockchainEvent(location, signature string)
+	APIRequestRejected(namespace, scope, reason string)
+	ContractListenerHealth(namespace, listenerID string, health *core.ContractListenerHealth)
+	ContractListenerRemoved(namespace, listenerID string)
 	AddTime(id string)
 	GetTime(id string) time.Time
 	DeleteTime(id string)
@@ -162,6 +166,50 @@ func (mm *metricsManager) BlockchainEvent(location, signature string) {
 	BlockchainEventsCounter.WithLabelValues(location, signature).Inc()
 }
 
+func (mm *metricsManager) APIRequestRejected(namespace, scope, reason string) {
+	APIRequestsRejectedCounter.WithLabelValues(namespace, scope, reason).Inc()
+}
+
+func (mm *metricsManager) ContractListenerHealth(namespace, listenerID string, health *core.ContractListenerHealth) {
+	setGauge := func(gauge *prometheus.GaugeVec, value *float64) {
+		if value == nil {
+			gauge.DeleteLabelValues(namespace, listenerID)
+		} else {
+			gauge.WithLabelValues(namespace, listenerID).Set(*value)
+		}
+	}
+	setGauge(ContractListenerCheckpointGauge, uint64Gauge(health.CheckpointBlock))
+	setGauge(ContractListenerChainHeadGauge, uint64Gauge(health.ChainHead))
+	setGauge(ContractListenerLagBlocksGauge, uint64Gauge(health.LagBlocks))
+	var lagSeconds *float64
+	if health.LagSeconds != nil {
+		v := float64(*health.LagSeconds)
+		lagSeconds = &v
+	}
+	setGauge(ContractListenerLagSecondsGauge, lagSeconds)
+	behind := float64(0)
+	if health.Behind {
+		behind = 1
+	}
+	setGauge(ContractListenerBehindGauge, &behind)
+}
+
+func (mm *metricsManager) ContractListenerRemoved(namespace, listenerID string) {
+	ContractListenerCheckpointGauge.DeleteLabelValues(namespace, listenerID)
+	ContractListenerChainHeadGauge.DeleteLabelValues(namespace, listenerID)
+	ContractListenerLagBlocksGauge.DeleteLabelValues(namespace, listenerID)
+	ContractListenerLagSecondsGauge.DeleteLabelValues(namespace, listenerID)
+	ContractListenerBehindGauge.DeleteLabelValues(namespace, listenerID)
+}
+
+func uint64Gauge(v *uint64) *float64 {
+	if v == nil {
+		return nil
+	}
+	f := float64(*v)
+	return &f
+}
+
 func (mm *metricsManager) AddTime(id string) {
 	mutex.Lock()
 	mm.timeMap[id] = time.Now()
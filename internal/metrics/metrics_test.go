@@ -1,4 +1,4 @@
-// Copyright © 2022 Kaleido, Inc.
+// Copyright © 2024 Kaleido, Inc.
 //
 // SPDX-License-Identifier: Apache-2.0
 //
@@ -215,6 +215,46 @@ func TestBlockchainEvents(t *testing.T) {
 	assert.Equal(t, float64(1), v)
 }
 
+func TestAPIRequestRejected(t *testing.T) {
+	mm, cancel := newTestMetricsManager(t)
+	defer cancel()
+	mm.APIRequestRejected("ns1", "identity", "rate")
+	m, err := APIRequestsRejectedCounter.GetMetricWith(prometheus.Labels{NamespaceLabelName: "ns1", ScopeLabelName: "identity", ReasonLabelName: "rate"})
+	assert.NoError(t, err)
+	v := testutil.ToFloat64(m)
+	assert.Equal(t, float64(1), v)
+}
+
+func TestContractListenerHealth(t *testing.T) {
+	mm, cancel := newTestMetricsManager(t)
+	defer cancel()
+	labels := prometheus.Labels{NamespaceLabelName: "ns1", ListenerLabelName: "listener1"}
+	checkpoint := uint64(90)
+	head := uint64(100)
+	lagBlocks := uint64(10)
+	lagSeconds := int64(20)
+	mm.ContractListenerHealth("ns1", "listener1", &core.ContractListenerHealth{
+		CheckpointBlock: &checkpoint,
+		ChainHead:       &head,
+		LagBlocks:       &lagBlocks,
+		LagSeconds:      &lagSeconds,
+		Behind:          true,
+	})
+	assert.Equal(t, float64(90), testutil.ToFloat64(ContractListenerCheckpointGauge.With(labels)))
+	assert.Equal(t, float64(100), testutil.ToFloat64(ContractListenerChainHeadGauge.With(labels)))
+	assert.Equal(t, float64(10), testutil.ToFloat64(ContractListenerLagBlocksGauge.With(labels)))
+	assert.Equal(t, float64(20), testutil.ToFloat64(ContractListenerLagSecondsGauge.With(labels)))
+	assert.Equal(t, float64(1), testutil.ToFloat64(ContractListenerBehindGauge.With(labels)))
+
+	// Values that are no longer reported are removed
+	mm.ContractListenerHealth("ns1", "listener1", &core.ContractListenerHealth{})
+	assert.Equal(t, 0, testutil.CollectAndCount(ContractListenerLagBlocksGauge))
+	assert.Equal(t, float64(0), testutil.ToFloat64(ContractListenerBehindGauge.With(labels)))
+
+	mm.ContractListenerRemoved("ns1", "listener1")
+	assert.Equal(t, 0, testutil.CollectAndCount(ContractListenerBehindGauge))
+}
+
 func TestIsMetricsEnabledTrue(t *testing.T) {
 	mm, cancel := newTestMetricsManager(t)
 	defer cancel()
@@ -1,4 +1,4 @@
-// Copyright © 2022 Kaleido, Inc.
+// Copyright © 2024 Kaleido, Inc.
 //
 // SPDX-License-Identifier: Apache-2.0
 //
@@ -88,6 +88,8 @@ func initMetricsCollectors() {
 	InitTokenBurnMetrics()
 	InitBatchPinMetrics()
 	InitBlockchainMetrics()
+	InitAPIMetrics()
+	InitContractListenerMetrics()
 }
 
 func registerMetricsCollectors() {
@@ -101,4 +103,6 @@ func registerMetricsCollectors() {
 	RegisterTokenTransferMetrics()
 	RegisterTokenBurnMetrics()
 	RegisterBlockchainMetrics()
+	RegisterAPIMetrics()
+	RegisterContractListenerMetrics()
 }
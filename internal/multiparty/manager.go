@@ -26,6 +26,7 @@ import (
 	"github.com/hyperledger/firefly/internal/coremsgs"
 	"github.com/hyperledger/firefly/internal/metrics"
 	"github.com/hyperledger/firefly/internal/operations"
+	"github.com/hyperledger/firefly/internal/tracing"
 	"github.com/hyperledger/firefly/internal/txcommon"
 	"github.com/hyperledger/firefly/pkg/blockchain"
 	"github.com/hyperledger/firefly/pkg/core"
@@ -260,7 +261,14 @@ func (mm *multipartyManager) prepareInvokeOperation(ctx context.Context, batch *
 	}), nil
 }
 
-func (mm *multipartyManager) SubmitBatchPin(ctx context.Context, batch *core.BatchPersisted, contexts []*fftypes.Bytes32, payloadRef string, idempotentSubmit bool) error {
+func (mm *multipartyManager) SubmitBatchPin(ctx context.Context, batch *core.BatchPersisted, contexts []*fftypes.Bytes32, payloadRef string, idempotentSubmit bool) (err error) {
+	ctx, span := tracing.StartSpan(ctx, "multiparty.submitBatchPin",
+		tracing.NamespaceKey.String(mm.namespace.Name),
+		tracing.BatchIDKey.String(batch.ID.String()),
+		tracing.TransactionIDKey.String(batch.TX.ID.String()),
+	)
+	defer func() { tracing.EndSpan(span, err) }()
+
 	if batch.TX.Type == core.TransactionTypeContractInvokePin {
 		preparedOp, err := mm.prepareInvokeOperation(ctx, batch, contexts, payloadRef)
 		if err != nil {
@@ -285,6 +293,6 @@ func (mm *multipartyManager) SubmitBatchPin(ctx context.Context, batch *core.Bat
 	if mm.metrics.IsMetricsEnabled() {
 		mm.metrics.CountBatchPin()
 	}
-	_, err := mm.operations.RunOperation(ctx, opBatchPin(op, batch, contexts, payloadRef), idempotentSubmit)
+	_, err = mm.operations.RunOperation(ctx, opBatchPin(op, batch, contexts, payloadRef), idempotentSubmit)
 	return err
 }
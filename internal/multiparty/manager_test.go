@@ -440,7 +440,7 @@ func TestSubmitBatchPinOk(t *testing.T) {
 	contexts := []*fftypes.Bytes32{}
 
 	mp.mbi.On("Name").Return("ut")
-	mp.mom.On("AddOrReuseOperation", ctx, mock.MatchedBy(func(op *core.Operation) bool {
+	mp.mom.On("AddOrReuseOperation", mock.Anything, mock.MatchedBy(func(op *core.Operation) bool {
 		assert.Equal(t, core.OpTypeBlockchainPinBatch, op.Type)
 		assert.Equal(t, "ut", op.Plugin)
 		assert.Equal(t, *batch.TX.ID, *op.Transaction)
@@ -477,7 +477,7 @@ func TestSubmitPinnedBatchWithMetricsOk(t *testing.T) {
 	contexts := []*fftypes.Bytes32{}
 
 	mp.mbi.On("Name").Return("ut")
-	mp.mom.On("AddOrReuseOperation", ctx, mock.MatchedBy(func(op *core.Operation) bool {
+	mp.mom.On("AddOrReuseOperation", mock.Anything, mock.MatchedBy(func(op *core.Operation) bool {
 		assert.Equal(t, core.OpTypeBlockchainPinBatch, op.Type)
 		assert.Equal(t, "ut", op.Plugin)
 		assert.Equal(t, *batch.TX.ID, *op.Transaction)
@@ -518,7 +518,7 @@ func TestSubmitBatchPinWithBatchOk(t *testing.T) {
 		Type: core.OpTypeBlockchainInvoke,
 	}
 
-	mp.mth.On("FindOperationInTransaction", ctx, batch.TX.ID, core.OpTypeBlockchainInvoke).Return(invokeOp, nil)
+	mp.mth.On("FindOperationInTransaction", mock.Anything, batch.TX.ID, core.OpTypeBlockchainInvoke).Return(invokeOp, nil)
 	mp.mom.On("RunOperation", mock.Anything, mock.MatchedBy(func(op *core.PreparedOperation) bool {
 		data := op.Data.(txcommon.BlockchainInvokeData)
 		assert.Equal(t, contexts, data.BatchPin.Contexts)
@@ -550,7 +550,7 @@ func TestSubmitBatchPinWithBatchOpFailure(t *testing.T) {
 	}
 	contexts := []*fftypes.Bytes32{fftypes.NewRandB32()}
 
-	mp.mth.On("FindOperationInTransaction", ctx, batch.TX.ID, core.OpTypeBlockchainInvoke).Return(nil, fmt.Errorf("pop"))
+	mp.mth.On("FindOperationInTransaction", mock.Anything, batch.TX.ID, core.OpTypeBlockchainInvoke).Return(nil, fmt.Errorf("pop"))
 
 	err := mp.SubmitBatchPin(ctx, batch, contexts, "payload1", false)
 	assert.EqualError(t, err, "pop")
@@ -582,7 +582,7 @@ func TestSubmitBatchPinWithBatchOpMalformed(t *testing.T) {
 		},
 	}
 
-	mp.mth.On("FindOperationInTransaction", ctx, batch.TX.ID, core.OpTypeBlockchainInvoke).Return(invokeOp, nil)
+	mp.mth.On("FindOperationInTransaction", mock.Anything, batch.TX.ID, core.OpTypeBlockchainInvoke).Return(invokeOp, nil)
 
 	err := mp.SubmitBatchPin(ctx, batch, contexts, "payload1", false)
 	assert.Regexp(t, "FF00127", err)
@@ -608,9 +608,9 @@ func TestSubmitBatchPinWithBatchOpNotFound(t *testing.T) {
 	}
 	contexts := []*fftypes.Bytes32{fftypes.NewRandB32()}
 
-	mp.mth.On("FindOperationInTransaction", ctx, batch.TX.ID, core.OpTypeBlockchainInvoke).Return(nil, nil)
+	mp.mth.On("FindOperationInTransaction", mock.Anything, batch.TX.ID, core.OpTypeBlockchainInvoke).Return(nil, nil)
 	mp.mbi.On("Name").Return("ut")
-	mp.mom.On("AddOrReuseOperation", ctx, mock.MatchedBy(func(op *core.Operation) bool {
+	mp.mom.On("AddOrReuseOperation", mock.Anything, mock.MatchedBy(func(op *core.Operation) bool {
 		assert.Equal(t, core.OpTypeBlockchainPinBatch, op.Type)
 		assert.Equal(t, "ut", op.Plugin)
 		assert.Equal(t, *batch.TX.ID, *op.Transaction)
@@ -648,7 +648,7 @@ func TestSubmitPinnedBatchOpFail(t *testing.T) {
 	contexts := []*fftypes.Bytes32{}
 
 	mp.mbi.On("Name").Return("ut")
-	mp.mom.On("AddOrReuseOperation", ctx, mock.Anything).Return(fmt.Errorf("pop"))
+	mp.mom.On("AddOrReuseOperation", mock.Anything, mock.Anything).Return(fmt.Errorf("pop"))
 	err := mp.SubmitBatchPin(ctx, batch, contexts, "payload1", false)
 	assert.Regexp(t, "pop", err)
 }
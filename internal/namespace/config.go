@@ -17,9 +17,11 @@
 package namespace
 
 import (
+	"github.com/hyperledger/firefly-common/pkg/auth"
 	"github.com/hyperledger/firefly-common/pkg/auth/authfactory"
 	"github.com/hyperledger/firefly-common/pkg/config"
 	"github.com/hyperledger/firefly-common/pkg/fftls"
+	"github.com/hyperledger/firefly/internal/auth/rbac"
 	"github.com/hyperledger/firefly/internal/blockchain/bifactory"
 	"github.com/hyperledger/firefly/internal/coreconfig"
 	"github.com/hyperledger/firefly/internal/database/difactory"
@@ -77,12 +79,31 @@ func InitConfig() {
 	tlsConf := tlsConfigs.SubSection(coreconfig.NamespaceTLSConfigTLSSection)
 	fftls.InitTLSConfig(tlsConf)
 
+	rateLimitConf := namespacePredefined.SubSection(coreconfig.NamespaceRateLimit)
+	for _, scope := range []string{coreconfig.RateLimitNamespace, coreconfig.RateLimitIdentity} {
+		scopeConf := rateLimitConf.SubSection(scope)
+		scopeConf.AddKnownKey(coreconfig.RateLimitRequestsPerSecond)
+		scopeConf.AddKnownKey(coreconfig.RateLimitBurst)
+		scopeConf.AddKnownKey(coreconfig.RateLimitMaxConcurrent)
+	}
+
+	retentionConf := namespacePredefined.SubSection(coreconfig.NamespaceRetention)
+	retentionConf.AddKnownKey(coreconfig.RetentionEvents)
+	retentionConf.AddKnownKey(coreconfig.RetentionMessages)
+	retentionConf.AddKnownKey(coreconfig.RetentionData)
+	retentionConf.AddKnownKey(coreconfig.RetentionOperations)
+	retentionConf.AddKnownKey(coreconfig.RetentionBlockchainEvents)
+	retentionConf.AddKnownKey(coreconfig.RetentionTokenTransfers)
+
 	bifactory.InitConfig(blockchainConfig)
 	difactory.InitConfig(databaseConfig)
 	ssfactory.InitConfig(sharedstorageConfig)
 	dxfactory.InitConfig(dataexchangeConfig)
 	iifactory.InitConfig(identityConfig)
 	tifactory.InitConfig(tokensConfig)
+	authfactory.RegisterPlugins(map[string]func() auth.Plugin{
+		rbac.Name(): func() auth.Plugin { return &rbac.Auth{} },
+	}, authConfig.SubSection(rbac.Name()))
 	authfactory.InitConfigArray(authConfig)
 	eifactory.InitConfig(eventsConfig)
 }
@@ -372,10 +372,29 @@ func (nm *namespaceManager) preInitNamespace(ns *namespace) error {
 	ns.orchestrator = nm.orchestratorFactory(&ns.Namespace, ns.config, ns.plugins, nm.metrics, nm.cacheManager)
 	ns.ctx, ns.cancelCtx = context.WithCancel(bgCtx)
 
-	ns.orchestrator.PreInit(ns.ctx, ns.cancelCtx)
+	ns.orchestrator.PreInit(ns.ctx, func() { nm.restartNamespace(ns) })
 	return nil
 }
 
+// restartNamespace stops the orchestrator of a running namespace, and starts a new one in its place
+func (nm *namespaceManager) restartNamespace(ns *namespace) {
+	nm.nsMux.Lock()
+	defer nm.nsMux.Unlock()
+	if nm.namespaces[ns.Name] != ns {
+		// The namespace has been replaced by a config reload
+		return
+	}
+	nm.stopNamespace(nm.ctx, ns)
+	ns.started = false
+	log.L(nm.ctx).Infof("Restarting namespace '%s'", ns.Name)
+	if err := nm.preInitNamespace(ns); err != nil {
+		log.L(nm.ctx).Errorf("Failed to restart namespace '%s': %s", ns.Name, err)
+		nm.cancelCtx() // stop the world
+		return
+	}
+	go nm.namespaceStarter(ns)
+}
+
 func (nm *namespaceManager) initNamespace(ns *namespace) error {
 	return ns.orchestrator.Init()
 }
@@ -790,6 +809,46 @@ func (nm *namespaceManager) loadTLSConfig(ctx context.Context, tlsConfigs map[st
 }
 
 // nolint: gocyclo
+// loadRateLimit reads a rate limit for the namespace, falling back to the global API defaults for any value it does not set
+func loadRateLimit(conf config.Section, scope string, rpsKey, burstKey, maxConcurrentKey config.RootKey) orchestrator.RateLimit {
+	limit := orchestrator.RateLimit{
+		RequestsPerSecond: config.GetFloat64(rpsKey),
+		Burst:             config.GetInt(burstKey),
+		MaxConcurrent:     config.GetInt(maxConcurrentKey),
+	}
+	scopeConf := conf.SubSection(coreconfig.NamespaceRateLimit).SubSection(scope)
+	if scopeConf.Get(coreconfig.RateLimitRequestsPerSecond) != nil {
+		limit.RequestsPerSecond = scopeConf.GetFloat64(coreconfig.RateLimitRequestsPerSecond)
+	}
+	if scopeConf.Get(coreconfig.RateLimitBurst) != nil {
+		limit.Burst = scopeConf.GetInt(coreconfig.RateLimitBurst)
+	}
+	if scopeConf.Get(coreconfig.RateLimitMaxConcurrent) != nil {
+		limit.MaxConcurrent = scopeConf.GetInt(coreconfig.RateLimitMaxConcurrent)
+	}
+	return limit
+}
+
+// loadRetention reads the retention policy of the namespace, where any type without a maximum age is kept forever
+func loadRetention(conf config.Section) core.RetentionPolicy {
+	retentionConf := conf.SubSection(coreconfig.NamespaceRetention)
+	maxAge := func(key string) *fftypes.FFDuration {
+		if retentionConf.Get(key) == nil {
+			return nil
+		}
+		d := fftypes.FFDuration(retentionConf.GetDuration(key))
+		return &d
+	}
+	return core.RetentionPolicy{
+		Events:           maxAge(coreconfig.RetentionEvents),
+		Messages:         maxAge(coreconfig.RetentionMessages),
+		Data:             maxAge(coreconfig.RetentionData),
+		Operations:       maxAge(coreconfig.RetentionOperations),
+		BlockchainEvents: maxAge(coreconfig.RetentionBlockchainEvents),
+		TokenTransfers:   maxAge(coreconfig.RetentionTokenTransfers),
+	}
+}
+
 func (nm *namespaceManager) loadNamespace(ctx context.Context, name string, index int, conf config.Section, rawNSConfig fftypes.JSONObject, availablePlugins map[string]*plugin) (ns *namespace, err error) {
 	if err := fftypes.ValidateFFNameField(ctx, name, fmt.Sprintf("namespaces.predefined[%d].name", index)); err != nil {
 		return nil, err
@@ -886,6 +945,13 @@ func (nm *namespaceManager) loadNamespace(ctx context.Context, name string, inde
 		TokenBroadcastNames:         nm.tokenBroadcastNames,
 		KeyNormalization:            keyNormalization,
 		MaxHistoricalEventScanLimit: config.GetInt(coreconfig.SubscriptionMaxHistoricalEventScanLength),
+		RateLimits: orchestrator.RateLimits{
+			Namespace: loadRateLimit(conf, coreconfig.RateLimitNamespace,
+				coreconfig.APIRateLimitNamespaceRequestsPerSecond, coreconfig.APIRateLimitNamespaceBurst, coreconfig.APIRateLimitNamespaceMaxConcurrent),
+			Identity: loadRateLimit(conf, coreconfig.RateLimitIdentity,
+				coreconfig.APIRateLimitIdentityRequestsPerSecond, coreconfig.APIRateLimitIdentityBurst, coreconfig.APIRateLimitIdentityMaxConcurrent),
+		},
+		Retention: loadRetention(conf),
 	}
 	if multipartyEnabled.(bool) {
 		contractsConf := multipartyConf.SubArray(coreconfig.NamespaceMultipartyContract)
@@ -1075,6 +1075,20 @@ func TestAuthPlugin(t *testing.T) {
 	assert.NoError(t, err)
 }
 
+func TestAuthPluginRBAC(t *testing.T) {
+	nm, _, cleanup := newTestNamespaceManager(t, false)
+	defer cleanup()
+	InitConfig()
+	config.Set("plugins.auth", []fftypes.JSONObject{{}})
+	authConfig.AddKnownKey(coreconfig.PluginConfigName, "rbacauth")
+	authConfig.AddKnownKey(coreconfig.PluginConfigType, "rbac")
+	nm.authFactory = authfactory.GetPlugin
+	plugins := make(map[string]*plugin)
+	err := nm.getAuthPlugin(context.Background(), plugins, nm.dumpRootConfig())
+	assert.NoError(t, err)
+	assert.Equal(t, "rbac", plugins["rbacauth"].auth.Name())
+}
+
 func TestAuthPluginBadType(t *testing.T) {
 	nm, _, cleanup := newTestNamespaceManager(t, false)
 	defer cleanup()
@@ -1726,6 +1740,70 @@ func TestLoadNamespacesNonMultipartyWithAuth(t *testing.T) {
 	assert.NoError(t, err)
 }
 
+func TestLoadNamespacesRateLimits(t *testing.T) {
+	nm, _, cleanup := newTestNamespaceManager(t, true)
+	defer cleanup()
+
+	coreconfig.Reset()
+	config.Set(coreconfig.APIRateLimitNamespaceRequestsPerSecond, 100)
+	config.Set(coreconfig.APIRateLimitNamespaceBurst, 200)
+	config.Set(coreconfig.APIRateLimitIdentityMaxConcurrent, 5)
+	viper.SetConfigType("yaml")
+	err := viper.ReadConfig(strings.NewReader(`
+  namespaces:
+    default: ns1
+    predefined:
+    - name: ns1
+      plugins: [ethereum, postgres]
+      multiparty:
+        enabled: false
+      rateLimit:
+        namespace:
+          requestsPerSecond: 2.5
+        identity:
+          requestsPerSecond: 1
+          burst: 3
+          maxConcurrent: 0
+  `))
+	assert.NoError(t, err)
+
+	nm.namespaces, err = nm.loadNamespaces(context.Background(), nm.dumpRootConfig(), nm.plugins)
+	assert.NoError(t, err)
+	assert.Equal(t, orchestrator.RateLimits{
+		Namespace: orchestrator.RateLimit{RequestsPerSecond: 2.5, Burst: 200},
+		Identity:  orchestrator.RateLimit{RequestsPerSecond: 1, Burst: 3},
+	}, nm.namespaces["ns1"].config.RateLimits)
+}
+
+func TestLoadNamespacesRetention(t *testing.T) {
+	nm, _, cleanup := newTestNamespaceManager(t, true)
+	defer cleanup()
+
+	coreconfig.Reset()
+	viper.SetConfigType("yaml")
+	err := viper.ReadConfig(strings.NewReader(`
+  namespaces:
+    default: ns1
+    predefined:
+    - name: ns1
+      plugins: [ethereum, postgres]
+      multiparty:
+        enabled: false
+      retention:
+        events: 24h
+        operations: 168h
+  `))
+	assert.NoError(t, err)
+
+	nm.namespaces, err = nm.loadNamespaces(context.Background(), nm.dumpRootConfig(), nm.plugins)
+	assert.NoError(t, err)
+	retention := nm.namespaces["ns1"].config.Retention
+	assert.Equal(t, fftypes.FFDuration(24*time.Hour), *retention.Events)
+	assert.Equal(t, fftypes.FFDuration(168*time.Hour), *retention.Operations)
+	assert.Nil(t, retention.Messages)
+	assert.Nil(t, retention.TokenTransfers)
+}
+
 func TestLoadNamespacesMultipartyContract(t *testing.T) {
 	nm, _, cleanup := newTestNamespaceManager(t, true)
 	defer cleanup()
@@ -2135,3 +2213,47 @@ func TestOrchestratorWhileInitializing(t *testing.T) {
 	_, err := nm.Orchestrator(nm.ctx, "default", false)
 	assert.Regexp(t, "FF10441", err)
 }
+
+func TestRestartNamespace(t *testing.T) {
+	nm, nmm, cleanup := newTestNamespaceManager(t, true)
+	defer cleanup()
+
+	waitInit := namespaceInitWaiter(t, nmm, []string{"default"})
+
+	var restart func()
+	nmm.mdi.On("GetNamespace", mock.Anything, "default").Return(nil, nil)
+	nmm.mdi.On("UpsertNamespace", mock.Anything, mock.AnythingOfType("*core.Namespace"), true).Return(nil)
+	nmm.mo.On("PreInit", mock.Anything, mock.Anything).Return().Run(func(args mock.Arguments) {
+		restart = args[1].(func())
+	})
+	nmm.mo.On("WaitStop").Return()
+	nmm.mo.On("Init").Return(nil)
+	nmm.mo.On("Start", mock.Anything).Return(nil)
+
+	ns := nm.namespaces["default"]
+	err := nm.preInitNamespace(ns)
+	assert.NoError(t, err)
+
+	restart()
+	waitInit.Wait()
+
+	nmm.mo.AssertExpectations(t)
+	assert.True(t, ns.started)
+}
+
+func TestRestartNamespaceReplaced(t *testing.T) {
+	nm, _, cleanup := newTestNamespaceManager(t, true)
+	defer cleanup()
+
+	nm.restartNamespace(&namespace{Namespace: core.Namespace{Name: "default"}})
+}
+
+func TestRestartNamespaceFail(t *testing.T) {
+	nm, nmm, cleanup := newTestNamespaceManager(t, true)
+	defer cleanup()
+
+	nmm.mdi.On("GetNamespace", mock.Anything, "default").Return(nil, fmt.Errorf("pop"))
+
+	nm.restartNamespace(nm.namespaces["default"])
+	<-nm.ctx.Done()
+}
@@ -0,0 +1,557 @@
+// Copyright © 2024 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nsarchive
+
+import (
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"io"
+
+	"github.com/hyperledger/firefly-common/pkg/fftypes"
+	"github.com/hyperledger/firefly-common/pkg/i18n"
+	"github.com/hyperledger/firefly-common/pkg/log"
+	"github.com/hyperledger/firefly/internal/coremsgs"
+	"github.com/hyperledger/firefly/pkg/core"
+	"github.com/hyperledger/firefly/pkg/database"
+)
+
+// archive is the content of a namespace archive, read into memory so it can be verified before it is imported
+type archive struct {
+	header            *core.NamespaceArchiveHeader
+	datatypes         []*core.Datatype
+	ffis              []*fftypes.FFI
+	ffiMethods        []*fftypes.FFIMethod
+	ffiEvents         []*fftypes.FFIEvent
+	ffiErrors         []*fftypes.FFIError
+	contractAPIs      []*core.ContractAPI
+	contractListeners []*core.ContractListener
+	subscriptions     []*core.Subscription
+	identities        []*core.Identity
+	verifiers         []*core.Verifier
+	groups            []*core.Group
+}
+
+func (a *archive) add(ctx context.Context, archiveType core.NamespaceArchiveType, record []byte) (err error) {
+	switch archiveType {
+	case core.NamespaceArchiveTypeDatatypes:
+		var r core.Datatype
+		if err = json.Unmarshal(record, &r); err == nil {
+			a.datatypes = append(a.datatypes, &r)
+		}
+	case core.NamespaceArchiveTypeFFIs:
+		var r fftypes.FFI
+		if err = json.Unmarshal(record, &r); err == nil {
+			a.ffis = append(a.ffis, &r)
+		}
+	case core.NamespaceArchiveTypeFFIMethods:
+		var r fftypes.FFIMethod
+		if err = json.Unmarshal(record, &r); err == nil {
+			a.ffiMethods = append(a.ffiMethods, &r)
+		}
+	case core.NamespaceArchiveTypeFFIEvents:
+		var r fftypes.FFIEvent
+		if err = json.Unmarshal(record, &r); err == nil {
+			a.ffiEvents = append(a.ffiEvents, &r)
+		}
+	case core.NamespaceArchiveTypeFFIErrors:
+		var r fftypes.FFIError
+		if err = json.Unmarshal(record, &r); err == nil {
+			a.ffiErrors = append(a.ffiErrors, &r)
+		}
+	case core.NamespaceArchiveTypeContractAPIs:
+		var r core.ContractAPI
+		if err = json.Unmarshal(record, &r); err == nil {
+			a.contractAPIs = append(a.contractAPIs, &r)
+		}
+	case core.NamespaceArchiveTypeContractListeners:
+		var r core.ContractListener
+		if err = json.Unmarshal(record, &r); err == nil {
+			a.contractListeners = append(a.contractListeners, &r)
+		}
+	case core.NamespaceArchiveTypeSubscriptions:
+		var r core.Subscription
+		if err = json.Unmarshal(record, &r); err == nil {
+			a.subscriptions = append(a.subscriptions, &r)
+		}
+	case core.NamespaceArchiveTypeIdentities:
+		var r core.Identity
+		if err = json.Unmarshal(record, &r); err == nil {
+			a.identities = append(a.identities, &r)
+		}
+	case core.NamespaceArchiveTypeVerifiers:
+		var r core.Verifier
+		if err = json.Unmarshal(record, &r); err == nil {
+			a.verifiers = append(a.verifiers, &r)
+		}
+	case core.NamespaceArchiveTypeGroups:
+		var r core.Group
+		if err = json.Unmarshal(record, &r); err == nil {
+			a.groups = append(a.groups, &r)
+		}
+	default:
+		return i18n.NewError(ctx, i18n.MsgInvalidEnumValue, archiveType, "namespacearchivetype", core.NamespaceArchiveTypes)
+	}
+	return err
+}
+
+func (a *archive) count(archiveType core.NamespaceArchiveType) int64 {
+	switch archiveType {
+	case core.NamespaceArchiveTypeDatatypes:
+		return int64(len(a.datatypes))
+	case core.NamespaceArchiveTypeFFIs:
+		return int64(len(a.ffis))
+	case core.NamespaceArchiveTypeFFIMethods:
+		return int64(len(a.ffiMethods))
+	case core.NamespaceArchiveTypeFFIEvents:
+		return int64(len(a.ffiEvents))
+	case core.NamespaceArchiveTypeFFIErrors:
+		return int64(len(a.ffiErrors))
+	case core.NamespaceArchiveTypeContractAPIs:
+		return int64(len(a.contractAPIs))
+	case core.NamespaceArchiveTypeContractListeners:
+		return int64(len(a.contractListeners))
+	case core.NamespaceArchiveTypeSubscriptions:
+		return int64(len(a.subscriptions))
+	case core.NamespaceArchiveTypeIdentities:
+		return int64(len(a.identities))
+	case core.NamespaceArchiveTypeVerifiers:
+		return int64(len(a.verifiers))
+	default:
+		return int64(len(a.groups))
+	}
+}
+
+// readArchive reads and checks the whole of an archive
+func readArchive(ctx context.Context, r io.Reader) (*archive, error) {
+	gr, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, i18n.NewError(ctx, coremsgs.MsgNamespaceArchiveInvalid, 0, err)
+	}
+	dec := json.NewDecoder(gr)
+	a := &archive{}
+	for i := 0; ; i++ {
+		var entry core.NamespaceArchiveEntry
+		if err := dec.Decode(&entry); err != nil {
+			if err == io.EOF {
+				return nil, i18n.NewError(ctx, coremsgs.MsgNamespaceArchiveIncomplete)
+			}
+			return nil, i18n.NewError(ctx, coremsgs.MsgNamespaceArchiveInvalid, i, err)
+		}
+		switch {
+		case i == 0:
+			if entry.Header == nil {
+				return nil, i18n.NewError(ctx, coremsgs.MsgNamespaceArchiveInvalid, i, "missing header")
+			}
+			if entry.Header.Version > core.NamespaceArchiveVersion {
+				return nil, i18n.NewError(ctx, coremsgs.MsgNamespaceArchiveVersion, entry.Header.Version, core.NamespaceArchiveVersion)
+			}
+			a.header = entry.Header
+		case entry.Trailer != nil:
+			for _, archiveType := range core.NamespaceArchiveTypes {
+				if a.count(archiveType) != entry.Trailer.Counts[archiveType] {
+					return nil, i18n.NewError(ctx, coremsgs.MsgNamespaceArchiveCountMismatch, a.count(archiveType), archiveType, entry.Trailer.Counts[archiveType])
+				}
+			}
+			return a, nil
+		case entry.Record == nil:
+			return nil, i18n.NewError(ctx, coremsgs.MsgNamespaceArchiveInvalid, i, "missing record")
+		default:
+			if err := a.add(ctx, entry.Type, entry.Record.Bytes()); err != nil {
+				return nil, i18n.NewError(ctx, coremsgs.MsgNamespaceArchiveInvalid, i, err)
+			}
+		}
+	}
+}
+
+// retarget moves every record into the namespace it is being imported into
+func (a *archive) retarget(ns string) {
+	for _, r := range a.datatypes {
+		r.Namespace = ns
+	}
+	for _, r := range a.ffis {
+		r.Namespace = ns
+	}
+	for _, r := range a.ffiMethods {
+		r.Namespace = ns
+	}
+	for _, r := range a.ffiEvents {
+		r.Namespace = ns
+	}
+	for _, r := range a.ffiErrors {
+		r.Namespace = ns
+	}
+	for _, r := range a.contractAPIs {
+		r.Namespace = ns
+	}
+	for _, r := range a.contractListeners {
+		r.Namespace = ns
+	}
+	for _, r := range a.subscriptions {
+		r.Namespace = ns
+	}
+	for _, r := range a.identities {
+		r.Namespace = ns
+	}
+	for _, r := range a.verifiers {
+		// The hash of a verifier includes its namespace
+		r.Namespace = ns
+		r.Seal()
+	}
+	for _, r := range a.groups {
+		// The namespace of the group identity is the network name, which is part of the group hash agreed by its members
+		r.LocalNamespace = ns
+	}
+}
+
+// references checks records refer to each other by ID, within the archive or against the namespace
+type references struct {
+	ctx          context.Context
+	am           *archiveManager
+	ffis         map[fftypes.UUID]bool
+	identities   map[fftypes.UUID]bool
+	identityDIDs map[string]bool
+}
+
+func (am *archiveManager) newReferences(ctx context.Context, a *archive) *references {
+	refs := &references{
+		ctx:          ctx,
+		am:           am,
+		ffis:         make(map[fftypes.UUID]bool),
+		identities:   make(map[fftypes.UUID]bool),
+		identityDIDs: make(map[string]bool),
+	}
+	for _, r := range a.ffis {
+		if r.ID != nil {
+			refs.ffis[*r.ID] = true
+		}
+	}
+	for _, r := range a.identities {
+		if r.ID != nil {
+			refs.identities[*r.ID] = true
+		}
+		refs.identityDIDs[r.DID] = true
+	}
+	return refs
+}
+
+func (refs *references) checkFFI(from core.NamespaceArchiveType, fromID string, id *fftypes.UUID) error {
+	if id == nil || refs.ffis[*id] {
+		return nil
+	}
+	ffi, err := refs.am.database.GetFFIByID(refs.ctx, refs.am.namespace.Name, id)
+	if err == nil && ffi == nil {
+		err = i18n.NewError(refs.ctx, coremsgs.MsgNamespaceArchiveMissingReference, fromID, from, core.NamespaceArchiveTypeFFIs, id)
+	}
+	return err
+}
+
+func (refs *references) checkFFIRef(from core.NamespaceArchiveType, fromID string, ref *fftypes.FFIReference) error {
+	if ref == nil {
+		return nil
+	}
+	return refs.checkFFI(from, fromID, ref.ID)
+}
+
+func (refs *references) checkIdentity(from core.NamespaceArchiveType, fromID string, id *fftypes.UUID) error {
+	if id == nil || refs.identities[*id] {
+		return nil
+	}
+	identity, err := refs.am.database.GetIdentityByID(refs.ctx, refs.am.namespace.Name, id)
+	if err == nil && identity == nil {
+		err = i18n.NewError(refs.ctx, coremsgs.MsgNamespaceArchiveMissingReference, fromID, from, core.NamespaceArchiveTypeIdentities, id)
+	}
+	return err
+}
+
+func (refs *references) checkIdentityDID(from core.NamespaceArchiveType, fromID string, did string) error {
+	if did == "" || refs.identityDIDs[did] {
+		return nil
+	}
+	identity, err := refs.am.database.GetIdentityByDID(refs.ctx, refs.am.namespace.Name, did)
+	if err == nil && identity == nil {
+		err = i18n.NewError(refs.ctx, coremsgs.MsgNamespaceArchiveMissingReference, fromID, from, core.NamespaceArchiveTypeIdentities, did)
+	}
+	return err
+}
+
+// verify checks the referential integrity of the archive, before anything is imported
+func (am *archiveManager) verify(ctx context.Context, a *archive) error {
+	refs := am.newReferences(ctx, a)
+	for _, r := range a.ffiMethods {
+		if err := refs.checkFFI(core.NamespaceArchiveTypeFFIMethods, r.ID.String(), r.Interface); err != nil {
+			return err
+		}
+	}
+	for _, r := range a.ffiEvents {
+		if err := refs.checkFFI(core.NamespaceArchiveTypeFFIEvents, r.ID.String(), r.Interface); err != nil {
+			return err
+		}
+	}
+	for _, r := range a.ffiErrors {
+		if err := refs.checkFFI(core.NamespaceArchiveTypeFFIErrors, r.ID.String(), r.Interface); err != nil {
+			return err
+		}
+	}
+	for _, r := range a.contractAPIs {
+		if err := refs.checkFFIRef(core.NamespaceArchiveTypeContractAPIs, r.ID.String(), r.Interface); err != nil {
+			return err
+		}
+	}
+	for _, r := range a.contractListeners {
+		if err := refs.checkFFIRef(core.NamespaceArchiveTypeContractListeners, r.ID.String(), r.Interface); err != nil {
+			return err
+		}
+		for _, f := range r.Filters {
+			if err := refs.checkFFIRef(core.NamespaceArchiveTypeContractListeners, r.ID.String(), f.Interface); err != nil {
+				return err
+			}
+		}
+	}
+	for _, r := range a.subscriptions {
+		if err := am.validateSubscription(ctx, r); err != nil {
+			return err
+		}
+	}
+	for _, r := range a.identities {
+		if err := refs.checkIdentity(core.NamespaceArchiveTypeIdentities, r.ID.String(), r.Parent); err != nil {
+			return err
+		}
+	}
+	for _, r := range a.verifiers {
+		if err := refs.checkIdentity(core.NamespaceArchiveTypeVerifiers, r.Hash.String(), r.Identity); err != nil {
+			return err
+		}
+	}
+	for _, r := range a.groups {
+		if r.Hash == nil || !r.GroupIdentity.Hash().Equals(r.Hash) {
+			return i18n.NewError(ctx, coremsgs.MsgNamespaceArchiveGroupHash, r.Hash)
+		}
+		for _, m := range r.Members {
+			if err := refs.checkIdentityDID(core.NamespaceArchiveTypeGroups, r.Hash.String(), m.Identity); err != nil {
+				return err
+			}
+			if err := refs.checkIdentity(core.NamespaceArchiveTypeGroups, r.Hash.String(), m.Node); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// validateSubscription validates the options of a subscription with its transport, in the same way as when
+// the subscription is created. Any plaintext webhook signing secret is encrypted with the key of this node.
+func (am *archiveManager) validateSubscription(ctx context.Context, sub *core.Subscription) error {
+	transport, ok := am.transports[sub.Transport]
+	if !ok {
+		return i18n.NewError(ctx, coremsgs.MsgUnknownEventTransportPlugin, sub.Transport)
+	}
+	if sub.Options.TLSConfigName != "" && am.namespace.TLSConfigs[sub.Options.TLSConfigName] != nil {
+		sub.Options.TLSConfig = am.namespace.TLSConfigs[sub.Options.TLSConfigName]
+	}
+	if err := transport.ValidateOptions(ctx, &sub.Options); err != nil {
+		return i18n.WrapError(ctx, err, coremsgs.MsgNamespaceArchiveInvalidSubscription, sub.Name)
+	}
+	return nil
+}
+
+// importCollection imports the records of one type, one at a time
+type importCollection struct {
+	archiveType core.NamespaceArchiveType
+	count       int
+	exists      func(ctx context.Context, i int) (bool, error)
+	insert      func(ctx context.Context, i int) error
+}
+
+func (am *archiveManager) importCollections(a *archive) []*importCollection {
+	ns := am.namespace.Name
+	return []*importCollection{
+		{
+			archiveType: core.NamespaceArchiveTypeDatatypes,
+			count:       len(a.datatypes),
+			exists: func(ctx context.Context, i int) (bool, error) {
+				existing, err := am.database.GetDatatypeByID(ctx, ns, a.datatypes[i].ID)
+				return existing != nil, err
+			},
+			insert: func(ctx context.Context, i int) error {
+				return am.database.UpsertDatatype(ctx, a.datatypes[i], false)
+			},
+		},
+		{
+			archiveType: core.NamespaceArchiveTypeFFIs,
+			count:       len(a.ffis),
+			exists: func(ctx context.Context, i int) (bool, error) {
+				existing, err := am.database.GetFFIByID(ctx, ns, a.ffis[i].ID)
+				return existing != nil, err
+			},
+			insert: func(ctx context.Context, i int) error {
+				return am.database.UpsertFFI(ctx, a.ffis[i], database.UpsertOptimizationNew)
+			},
+		},
+		{
+			archiveType: core.NamespaceArchiveTypeFFIMethods,
+			count:       len(a.ffiMethods),
+			exists: func(ctx context.Context, i int) (bool, error) {
+				existing, err := am.database.GetFFIMethod(ctx, ns, a.ffiMethods[i].Interface, a.ffiMethods[i].Pathname)
+				return existing != nil, err
+			},
+			insert: func(ctx context.Context, i int) error {
+				return am.database.UpsertFFIMethod(ctx, a.ffiMethods[i])
+			},
+		},
+		{
+			archiveType: core.NamespaceArchiveTypeFFIEvents,
+			count:       len(a.ffiEvents),
+			exists: func(ctx context.Context, i int) (bool, error) {
+				existing, err := am.database.GetFFIEvent(ctx, ns, a.ffiEvents[i].Interface, a.ffiEvents[i].Pathname)
+				return existing != nil, err
+			},
+			insert: func(ctx context.Context, i int) error {
+				return am.database.UpsertFFIEvent(ctx, a.ffiEvents[i])
+			},
+		},
+		{
+			archiveType: core.NamespaceArchiveTypeFFIErrors,
+			count:       len(a.ffiErrors),
+			exists: func(ctx context.Context, i int) (bool, error) {
+				fb := database.FFIErrorQueryFactory.NewFilterLimit(ctx, 1)
+				existing, _, err := am.database.GetFFIErrors(ctx, ns, fb.And(
+					fb.Eq("interface", a.ffiErrors[i].Interface),
+					fb.Eq("pathname", a.ffiErrors[i].Pathname),
+				))
+				return len(existing) > 0, err
+			},
+			insert: func(ctx context.Context, i int) error {
+				return am.database.UpsertFFIError(ctx, a.ffiErrors[i])
+			},
+		},
+		{
+			archiveType: core.NamespaceArchiveTypeContractAPIs,
+			count:       len(a.contractAPIs),
+			exists: func(ctx context.Context, i int) (bool, error) {
+				existing, err := am.database.GetContractAPIByID(ctx, ns, a.contractAPIs[i].ID)
+				return existing != nil, err
+			},
+			insert: func(ctx context.Context, i int) error {
+				return am.database.UpsertContractAPI(ctx, a.contractAPIs[i], database.UpsertOptimizationNew)
+			},
+		},
+		{
+			archiveType: core.NamespaceArchiveTypeContractListeners,
+			count:       len(a.contractListeners),
+			exists: func(ctx context.Context, i int) (bool, error) {
+				existing, err := am.database.GetContractListenerByID(ctx, ns, a.contractListeners[i].ID)
+				return existing != nil, err
+			},
+			insert: func(ctx context.Context, i int) error {
+				return am.database.InsertContractListener(ctx, a.contractListeners[i])
+			},
+		},
+		{
+			archiveType: core.NamespaceArchiveTypeSubscriptions,
+			count:       len(a.subscriptions),
+			exists: func(ctx context.Context, i int) (bool, error) {
+				existing, err := am.database.GetSubscriptionByID(ctx, ns, a.subscriptions[i].ID)
+				return existing != nil, err
+			},
+			insert: func(ctx context.Context, i int) error {
+				return am.database.UpsertSubscription(ctx, a.subscriptions[i], false)
+			},
+		},
+		{
+			archiveType: core.NamespaceArchiveTypeIdentities,
+			count:       len(a.identities),
+			exists: func(ctx context.Context, i int) (bool, error) {
+				existing, err := am.database.GetIdentityByID(ctx, ns, a.identities[i].ID)
+				return existing != nil, err
+			},
+			insert: func(ctx context.Context, i int) error {
+				return am.database.UpsertIdentity(ctx, a.identities[i], database.UpsertOptimizationNew)
+			},
+		},
+		{
+			archiveType: core.NamespaceArchiveTypeVerifiers,
+			count:       len(a.verifiers),
+			exists: func(ctx context.Context, i int) (bool, error) {
+				existing, err := am.database.GetVerifierByHash(ctx, ns, a.verifiers[i].Hash)
+				return existing != nil, err
+			},
+			insert: func(ctx context.Context, i int) error {
+				return am.database.UpsertVerifier(ctx, a.verifiers[i], database.UpsertOptimizationNew)
+			},
+		},
+		{
+			archiveType: core.NamespaceArchiveTypeGroups,
+			count:       len(a.groups),
+			exists: func(ctx context.Context, i int) (bool, error) {
+				existing, err := am.database.GetGroupByHash(ctx, ns, a.groups[i].Hash)
+				return existing != nil, err
+			},
+			insert: func(ctx context.Context, i int) error {
+				return am.database.UpsertGroup(ctx, a.groups[i], database.UpsertOptimizationNew)
+			},
+		},
+	}
+}
+
+func (am *archiveManager) Import(ctx context.Context, r io.Reader, dryRun bool) (*core.NamespaceImportResult, error) {
+	a, err := readArchive(ctx, r)
+	if err != nil {
+		return nil, err
+	}
+	a.retarget(am.namespace.Name)
+
+	result := &core.NamespaceImportResult{
+		Source: a.header.Namespace,
+		DryRun: dryRun,
+	}
+	run := am.database.RunAsGroup
+	if dryRun {
+		run = am.database.RunAsReadSnapshot
+	}
+	err = run(ctx, func(ctx context.Context) error {
+		if err := am.verify(ctx, a); err != nil {
+			return err
+		}
+		for _, c := range am.importCollections(a) {
+			count := &core.NamespaceImportCount{Type: c.archiveType}
+			for i := 0; i < c.count; i++ {
+				exists, err := c.exists(ctx, i)
+				if err != nil {
+					return err
+				}
+				if exists {
+					count.Existing++
+					continue
+				}
+				if !dryRun {
+					if err := c.insert(ctx, i); err != nil {
+						return err
+					}
+				}
+				count.Imported++
+			}
+			log.L(ctx).Infof("Imported %d %s into namespace '%s' (existing=%d dryRun=%t)", count.Imported, c.archiveType, am.namespace.Name, count.Existing, dryRun)
+			result.Records = append(result.Records, count)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
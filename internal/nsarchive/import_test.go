@@ -0,0 +1,414 @@
+// Copyright © 2024 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nsarchive
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/hyperledger/firefly-common/pkg/fftypes"
+	"github.com/hyperledger/firefly/mocks/databasemocks"
+	"github.com/hyperledger/firefly/pkg/core"
+	"github.com/hyperledger/firefly/pkg/database"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func newTestImportManager(t *testing.T) (*archiveManager, *databasemocks.Plugin) {
+	mdi := &databasemocks.Plugin{}
+	t.Cleanup(func() { mdi.AssertExpectations(t) })
+	am := NewArchiveManager(&core.Namespace{Name: "ns2", NetworkName: "network1"}, mdi, newTestWebHooks(t, "target", "targetkey")).(*archiveManager)
+	return am, mdi
+}
+
+func writeTestArchive(t *testing.T, entries ...string) *bytes.Buffer {
+	var b bytes.Buffer
+	gw := gzip.NewWriter(&b)
+	for _, e := range entries {
+		_, err := gw.Write([]byte(e + "\n"))
+		assert.NoError(t, err)
+	}
+	assert.NoError(t, gw.Close())
+	return &b
+}
+
+func mustGunzip(t *testing.T, b []byte) []byte {
+	gr, err := gzip.NewReader(bytes.NewReader(b))
+	assert.NoError(t, err)
+	plain, err := io.ReadAll(gr)
+	assert.NoError(t, err)
+	return plain
+}
+
+func mockImportLookups(mdi *databasemocks.Plugin, found bool) {
+	var (
+		datatype *core.Datatype
+		ffi      *fftypes.FFI
+		method   *fftypes.FFIMethod
+		event    *fftypes.FFIEvent
+		errors   []*fftypes.FFIError
+		api      *core.ContractAPI
+		listener *core.ContractListener
+		sub      *core.Subscription
+		identity *core.Identity
+		verifier *core.Verifier
+		group    *core.Group
+	)
+	if found {
+		datatype, ffi, method, event, errors = &core.Datatype{}, &fftypes.FFI{}, &fftypes.FFIMethod{}, &fftypes.FFIEvent{}, []*fftypes.FFIError{{}}
+		api, listener, sub = &core.ContractAPI{}, &core.ContractListener{}, &core.Subscription{}
+		identity, verifier, group = &core.Identity{}, &core.Verifier{}, &core.Group{}
+	}
+	mdi.On("GetDatatypeByID", mock.Anything, "ns2", mock.Anything).Return(datatype, nil)
+	mdi.On("GetFFIByID", mock.Anything, "ns2", mock.Anything).Return(ffi, nil)
+	mdi.On("GetFFIMethod", mock.Anything, "ns2", mock.Anything, "set").Return(method, nil)
+	mdi.On("GetFFIEvent", mock.Anything, "ns2", mock.Anything, "changed").Return(event, nil)
+	mdi.On("GetFFIErrors", mock.Anything, "ns2", mock.Anything).Return(errors, nil, nil)
+	mdi.On("GetContractAPIByID", mock.Anything, "ns2", mock.Anything).Return(api, nil)
+	mdi.On("GetContractListenerByID", mock.Anything, "ns2", mock.Anything).Return(listener, nil)
+	mdi.On("GetSubscriptionByID", mock.Anything, "ns2", mock.Anything).Return(sub, nil)
+	mdi.On("GetIdentityByID", mock.Anything, "ns2", mock.Anything).Return(identity, nil)
+	mdi.On("GetVerifierByHash", mock.Anything, "ns2", mock.Anything).Return(verifier, nil)
+	mdi.On("GetGroupByHash", mock.Anything, "ns2", mock.Anything).Return(group, nil)
+}
+
+func importCounts(result *core.NamespaceImportResult) map[core.NamespaceArchiveType][2]int64 {
+	counts := make(map[core.NamespaceArchiveType][2]int64)
+	for _, c := range result.Records {
+		counts[c.Type] = [2]int64{c.Imported, c.Existing}
+	}
+	return counts
+}
+
+func TestImportRoundTrip(t *testing.T) {
+	tr := newTestRecords("ns1")
+	b := exportTestArchive(t, tr)
+
+	am, mdi := newTestImportManager(t)
+	mockRunAs(mdi, "RunAsGroup")
+	mockImportLookups(mdi, false)
+	mdi.On("UpsertDatatype", mock.Anything, mock.MatchedBy(func(r *core.Datatype) bool {
+		return r.Namespace == "ns2" && r.ID.Equals(tr.datatype.ID)
+	}), false).Return(nil)
+	mdi.On("UpsertFFI", mock.Anything, mock.MatchedBy(func(r *fftypes.FFI) bool {
+		return r.Namespace == "ns2"
+	}), database.UpsertOptimizationNew).Return(nil)
+	mdi.On("UpsertFFIMethod", mock.Anything, mock.MatchedBy(func(r *fftypes.FFIMethod) bool {
+		return r.Namespace == "ns2"
+	})).Return(nil)
+	mdi.On("UpsertFFIEvent", mock.Anything, mock.MatchedBy(func(r *fftypes.FFIEvent) bool {
+		return r.Namespace == "ns2"
+	})).Return(nil)
+	mdi.On("UpsertFFIError", mock.Anything, mock.MatchedBy(func(r *fftypes.FFIError) bool {
+		return r.Namespace == "ns2"
+	})).Return(nil)
+	mdi.On("UpsertContractAPI", mock.Anything, mock.MatchedBy(func(r *core.ContractAPI) bool {
+		return r.Namespace == "ns2"
+	}), database.UpsertOptimizationNew).Return(nil)
+	mdi.On("InsertContractListener", mock.Anything, mock.MatchedBy(func(r *core.ContractListener) bool {
+		return r.Namespace == "ns2" && r.BackendID == "sb-1"
+	})).Return(nil)
+	mdi.On("UpsertSubscription", mock.Anything, mock.MatchedBy(func(r *core.Subscription) bool {
+		return r.Namespace == "ns2"
+	}), false).Return(nil)
+	mdi.On("UpsertIdentity", mock.Anything, mock.MatchedBy(func(r *core.Identity) bool {
+		return r.Namespace == "ns2"
+	}), database.UpsertOptimizationNew).Return(nil).Twice()
+	mdi.On("UpsertVerifier", mock.Anything, mock.MatchedBy(func(r *core.Verifier) bool {
+		return r.Namespace == "ns2" && !r.Hash.Equals(tr.verifier.Hash)
+	}), database.UpsertOptimizationNew).Return(nil)
+	mdi.On("UpsertGroup", mock.Anything, mock.MatchedBy(func(r *core.Group) bool {
+		return r.LocalNamespace == "ns2" && r.Namespace == "network1" && r.Hash.Equals(tr.group.Hash)
+	}), database.UpsertOptimizationNew).Return(nil)
+
+	result, err := am.Import(context.Background(), bytes.NewReader(b), false)
+	assert.NoError(t, err)
+	assert.Equal(t, "ns1", result.Source)
+	assert.False(t, result.DryRun)
+	counts := importCounts(result)
+	assert.Len(t, counts, len(core.NamespaceArchiveTypes))
+	assert.Equal(t, [2]int64{1, 0}, counts[core.NamespaceArchiveTypeDatatypes])
+	assert.Equal(t, [2]int64{2, 0}, counts[core.NamespaceArchiveTypeIdentities])
+	assert.Equal(t, [2]int64{1, 0}, counts[core.NamespaceArchiveTypeGroups])
+}
+
+func TestImportDryRun(t *testing.T) {
+	tr := newTestRecords("ns1")
+	b := exportTestArchive(t, tr)
+
+	am, mdi := newTestImportManager(t)
+	mockRunAs(mdi, "RunAsReadSnapshot")
+	mockImportLookups(mdi, false)
+
+	result, err := am.Import(context.Background(), bytes.NewReader(b), true)
+	assert.NoError(t, err)
+	assert.True(t, result.DryRun)
+	counts := importCounts(result)
+	assert.Equal(t, [2]int64{1, 0}, counts[core.NamespaceArchiveTypeContractListeners])
+	assert.Equal(t, [2]int64{2, 0}, counts[core.NamespaceArchiveTypeIdentities])
+}
+
+func TestImportExisting(t *testing.T) {
+	tr := newTestRecords("ns1")
+	b := exportTestArchive(t, tr)
+
+	am, mdi := newTestImportManager(t)
+	mockRunAs(mdi, "RunAsGroup")
+	mockImportLookups(mdi, true)
+
+	result, err := am.Import(context.Background(), bytes.NewReader(b), false)
+	assert.NoError(t, err)
+	counts := importCounts(result)
+	for _, archiveType := range core.NamespaceArchiveTypes {
+		assert.Zero(t, counts[archiveType][0])
+		assert.NotZero(t, counts[archiveType][1])
+	}
+}
+
+func TestImportReadFail(t *testing.T) {
+	am, _ := newTestImportManager(t)
+	_, err := am.Import(context.Background(), bytes.NewReader([]byte("not gzip")), false)
+	assert.Regexp(t, "FF10503", err)
+}
+
+func TestImportVerifyFail(t *testing.T) {
+	tr := newTestRecords("ns1")
+	tr.group.Name = "renamed"
+	b := exportTestArchive(t, tr)
+
+	am, mdi := newTestImportManager(t)
+	mockRunAs(mdi, "RunAsGroup")
+	_, err := am.Import(context.Background(), bytes.NewReader(b), false)
+	assert.Regexp(t, "FF10508", err)
+}
+
+func TestImportSubscriptionSigningSecrets(t *testing.T) {
+	tr := newTestRecords("ns1")
+	tr.sub = newTestSubscription("ns1", `{"url":"http://localhost:12345","signing":{"secret":"secret1","header":"X-Sig"}}`)
+	am, _ := newTestArchiveManager(t)
+	err := am.transports["webhooks"].ValidateOptions(context.Background(), &tr.sub.Options)
+	assert.NoError(t, err)
+	b := exportTestArchive(t, tr)
+
+	// The secret encrypted by the source node is not in the archive, so the subscription is imported without it
+	am, mdi := newTestImportManager(t)
+	mockRunAs(mdi, "RunAsGroup")
+	mockImportLookups(mdi, true)
+	mdi.On("GetSubscriptionByID", mock.Anything, "ns2", mock.Anything).Unset()
+	mdi.On("GetSubscriptionByID", mock.Anything, "ns2", mock.Anything).Return(nil, nil)
+	mdi.On("UpsertSubscription", mock.Anything, mock.MatchedBy(func(r *core.Subscription) bool {
+		signing := r.Options.TransportOptions().GetObject("signing")
+		return signing.GetString("secret") == "" && signing.GetString("header") == "X-Sig" && r.Options.SigningKeys == nil
+	}), false).Return(nil)
+	result, err := am.Import(context.Background(), bytes.NewReader(b), false)
+	assert.NoError(t, err)
+	assert.Equal(t, [2]int64{1, 0}, importCounts(result)[core.NamespaceArchiveTypeSubscriptions])
+}
+
+func TestImportSubscriptionSecretEncryptedForTarget(t *testing.T) {
+	tr := newTestRecords("ns1")
+	b := exportTestArchive(t, tr)
+	// A plaintext secret added to the archive is encrypted with the key of the target node
+	b = bytes.Replace(mustGunzip(t, b), []byte(`"url":"http://localhost:12345"`), []byte(`"url":"http://localhost:12345","signing":{"secret":"secret1"}`), 1)
+
+	am, mdi := newTestImportManager(t)
+	mockRunAs(mdi, "RunAsGroup")
+	mockImportLookups(mdi, true)
+	mdi.On("GetSubscriptionByID", mock.Anything, "ns2", mock.Anything).Unset()
+	mdi.On("GetSubscriptionByID", mock.Anything, "ns2", mock.Anything).Return(nil, nil)
+	mdi.On("UpsertSubscription", mock.Anything, mock.MatchedBy(func(r *core.Subscription) bool {
+		secret := r.Options.TransportOptions().GetObject("signing").GetString("secret")
+		return strings.HasPrefix(secret, "enc:") && string(r.Options.SigningKeys.Current) == "secret1"
+	}), false).Return(nil)
+	_, err := am.Import(context.Background(), writeTestArchive(t, strings.Split(strings.TrimSpace(string(b)), "\n")...), false)
+	assert.NoError(t, err)
+}
+
+func TestImportSubscriptionSecretFromOtherNode(t *testing.T) {
+	tr := newTestRecords("ns1")
+	b := exportTestArchive(t, tr)
+	source, _ := newTestArchiveManager(t)
+	options := &core.SubscriptionOptions{}
+	_ = json.Unmarshal([]byte(`{"url":"http://localhost:12345","signing":{"secret":"secret1"}}`), options)
+	err := source.transports["webhooks"].ValidateOptions(context.Background(), options)
+	assert.NoError(t, err)
+	encrypted := options.TransportOptions().GetObject("signing").GetString("secret")
+	b = bytes.Replace(mustGunzip(t, b), []byte(`"url":"http://localhost:12345"`), []byte(fmt.Sprintf(`"url":"http://localhost:12345","signing":{"secret":"%s"}`, encrypted)), 1)
+
+	am, mdi := newTestImportManager(t)
+	mockRunAs(mdi, "RunAsReadSnapshot")
+	_, err = am.Import(context.Background(), writeTestArchive(t, strings.Split(strings.TrimSpace(string(b)), "\n")...), true)
+	assert.Regexp(t, "FF10542.*sub1.*FF10494", err)
+}
+
+func TestImportSubscriptionUnknownTransport(t *testing.T) {
+	tr := newTestRecords("ns1")
+	tr.sub.Transport = "wrong"
+	b := exportTestArchive(t, tr)
+
+	am, mdi := newTestImportManager(t)
+	mockRunAs(mdi, "RunAsReadSnapshot")
+	_, err := am.Import(context.Background(), bytes.NewReader(b), true)
+	assert.Regexp(t, "FF10172.*wrong", err)
+}
+
+func TestValidateSubscriptionTLSConfig(t *testing.T) {
+	am, _ := newTestImportManager(t)
+	tlsConfig := &tls.Config{}
+	am.namespace.TLSConfigs = map[string]*tls.Config{"tls1": tlsConfig}
+	sub := newTestSubscription("ns2", `{"url":"https://localhost:12345","tlsConfigName":"tls1"}`)
+	err := am.validateSubscription(context.Background(), sub)
+	assert.NoError(t, err)
+	assert.Same(t, tlsConfig, sub.Options.TLSConfig)
+}
+
+func TestImportExistsFail(t *testing.T) {
+	tr := newTestRecords("ns1")
+	b := exportTestArchive(t, tr)
+
+	am, mdi := newTestImportManager(t)
+	mockRunAs(mdi, "RunAsGroup")
+	mdi.On("GetDatatypeByID", mock.Anything, "ns2", mock.Anything).Return(nil, fmt.Errorf("pop"))
+	_, err := am.Import(context.Background(), bytes.NewReader(b), false)
+	assert.Regexp(t, "pop", err)
+}
+
+func TestImportInsertFail(t *testing.T) {
+	tr := newTestRecords("ns1")
+	b := exportTestArchive(t, tr)
+
+	am, mdi := newTestImportManager(t)
+	mockRunAs(mdi, "RunAsGroup")
+	mdi.On("GetDatatypeByID", mock.Anything, "ns2", mock.Anything).Return(nil, nil)
+	mdi.On("UpsertDatatype", mock.Anything, mock.Anything, false).Return(fmt.Errorf("pop"))
+	_, err := am.Import(context.Background(), bytes.NewReader(b), false)
+	assert.Regexp(t, "pop", err)
+}
+
+func TestReadArchiveErrors(t *testing.T) {
+	header := `{"header":{"version":1,"namespace":"ns1"}}`
+	datatype := fmt.Sprintf(`{"type":"datatypes","record":{"id":"%s","name":"dt1"}}`, fftypes.NewUUID())
+	trailer := `{"trailer":{"counts":{"datatypes":1}}}`
+	ctx := context.Background()
+
+	a, err := readArchive(ctx, writeTestArchive(t, header, datatype, trailer))
+	assert.NoError(t, err)
+	assert.Len(t, a.datatypes, 1)
+
+	tests := []struct {
+		name    string
+		entries []string
+		err     string
+	}{
+		{"badjson", []string{header, "!json"}, "FF10503.*entry 1"},
+		{"noheader", []string{datatype, trailer}, "FF10503.*entry 0"},
+		{"version", []string{`{"header":{"version":2}}`}, "FF10504"},
+		{"incomplete", []string{header, datatype}, "FF10505"},
+		{"counts", []string{header, datatype, `{"trailer":{"counts":{}}}`}, "FF10506"},
+		{"norecord", []string{header, `{"type":"datatypes"}`}, "FF10503.*entry 1"},
+		{"badtype", []string{header, `{"type":"wrong","record":{}}`}, "FF10503.*FF00172"},
+		{"badrecord", []string{header, `{"type":"datatypes","record":{"id":false}}`}, "FF10503.*entry 1"},
+	}
+	for _, test := range tests {
+		_, err := readArchive(ctx, writeTestArchive(t, test.entries...))
+		assert.Regexp(t, test.err, err, test.name)
+	}
+}
+
+func TestArchiveAddAllTypes(t *testing.T) {
+	a := &archive{}
+	for _, archiveType := range core.NamespaceArchiveTypes {
+		err := a.add(context.Background(), archiveType, []byte(`{}`))
+		assert.NoError(t, err)
+		assert.Equal(t, int64(1), a.count(archiveType))
+	}
+}
+
+func TestVerifyMissingReferences(t *testing.T) {
+	tr := newTestRecords("ns2")
+	missingFFI := &fftypes.FFIReference{ID: fftypes.NewUUID()}
+	missingIdentity := fftypes.NewUUID()
+	badHash := *tr.group
+	badHash.Hash = fftypes.NewRandB32()
+
+	tests := []struct {
+		name string
+		a    *archive
+		err  string
+	}{
+		{"method", &archive{ffiMethods: []*fftypes.FFIMethod{{Interface: missingFFI.ID}}}, "FF10507.*ffimethods.*ffis"},
+		{"event", &archive{ffiEvents: []*fftypes.FFIEvent{{Interface: missingFFI.ID}}}, "FF10507.*ffievents.*ffis"},
+		{"error", &archive{ffiErrors: []*fftypes.FFIError{{Interface: missingFFI.ID}}}, "FF10507.*ffierrors.*ffis"},
+		{"api", &archive{contractAPIs: []*core.ContractAPI{{Interface: missingFFI}}}, "FF10507.*contractapis.*ffis"},
+		{"listener", &archive{contractListeners: []*core.ContractListener{{Interface: missingFFI}}}, "FF10507.*contractlisteners.*ffis"},
+		{"filter", &archive{contractListeners: []*core.ContractListener{{Filters: core.ListenerFilters{{Interface: missingFFI}}}}}, "FF10507.*contractlisteners.*ffis"},
+		{"parent", &archive{identities: []*core.Identity{{IdentityBase: core.IdentityBase{Parent: missingIdentity}}}}, "FF10507.*identities.*identities"},
+		{"verifier", &archive{verifiers: []*core.Verifier{{Identity: missingIdentity}}}, "FF10507.*verifiers.*identities"},
+		{"grouphash", &archive{groups: []*core.Group{&badHash}}, "FF10508"},
+		{"member", &archive{groups: []*core.Group{tr.group}}, "FF10507.*groups.*did:firefly:org/org1"},
+		{"node", &archive{groups: []*core.Group{tr.group}, identities: []*core.Identity{tr.org}}, "FF10507.*groups.*identities"},
+	}
+	for _, test := range tests {
+		am, mdi := newTestImportManager(t)
+		mdi.On("GetFFIByID", mock.Anything, "ns2", missingFFI.ID).Return(nil, nil).Maybe()
+		mdi.On("GetIdentityByID", mock.Anything, "ns2", mock.Anything).Return(nil, nil).Maybe()
+		mdi.On("GetIdentityByDID", mock.Anything, "ns2", mock.Anything).Return(nil, nil).Maybe()
+		err := am.verify(context.Background(), test.a)
+		assert.Regexp(t, test.err, err, test.name)
+	}
+}
+
+func TestVerifyReferencesInNamespace(t *testing.T) {
+	tr := newTestRecords("ns2")
+	am, mdi := newTestImportManager(t)
+	mdi.On("GetFFIByID", mock.Anything, "ns2", tr.ffi.ID).Return(tr.ffi, nil)
+	mdi.On("GetIdentityByID", mock.Anything, "ns2", tr.org.ID).Return(tr.org, nil)
+	mdi.On("GetIdentityByDID", mock.Anything, "ns2", tr.org.DID).Return(tr.org, nil)
+	err := am.verify(context.Background(), &archive{
+		ffiMethods:        []*fftypes.FFIMethod{tr.ffiMethod},
+		ffiEvents:         []*fftypes.FFIEvent{tr.ffiEvent},
+		ffiErrors:         []*fftypes.FFIError{tr.ffiError},
+		contractAPIs:      []*core.ContractAPI{tr.contractAPI},
+		contractListeners: []*core.ContractListener{tr.listener},
+		identities:        []*core.Identity{tr.node},
+		verifiers:         []*core.Verifier{tr.verifier},
+		groups:            []*core.Group{tr.group},
+	})
+	assert.NoError(t, err)
+}
+
+func TestVerifyLookupFail(t *testing.T) {
+	tr := newTestRecords("ns2")
+	am, mdi := newTestImportManager(t)
+	mdi.On("GetIdentityByDID", mock.Anything, "ns2", tr.org.DID).Return(nil, fmt.Errorf("pop"))
+	err := am.verify(context.Background(), &archive{groups: []*core.Group{tr.group}})
+	assert.Regexp(t, "pop", err)
+}
+
+func TestImportEntryJSON(t *testing.T) {
+	// The archive format is part of the compatibility of FireFly releases
+	b, err := json.Marshal(&core.NamespaceArchiveEntry{Type: core.NamespaceArchiveTypeFFIMethods, Record: fftypes.JSONAnyPtr(`{}`)})
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"type":"ffimethods","record":{}}`, string(b))
+}
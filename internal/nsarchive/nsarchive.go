@@ -0,0 +1,220 @@
+// Copyright © 2024 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nsarchive
+
+import (
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"io"
+
+	"github.com/hyperledger/firefly-common/pkg/ffapi"
+	"github.com/hyperledger/firefly-common/pkg/fftypes"
+	"github.com/hyperledger/firefly-common/pkg/log"
+	"github.com/hyperledger/firefly/pkg/core"
+	"github.com/hyperledger/firefly/pkg/database"
+	"github.com/hyperledger/firefly/pkg/events"
+)
+
+const exportPageSize = 100
+
+type Manager interface {
+	// Export writes a gzip compressed archive of the definitions in the namespace, read from a consistent snapshot
+	Export(ctx context.Context, w io.Writer) error
+
+	// Import reads an archive into the namespace. Records that are already in the namespace are skipped,
+	// so an archive can be imported more than once. Nothing is written if dryRun is set.
+	Import(ctx context.Context, r io.Reader, dryRun bool) (*core.NamespaceImportResult, error)
+}
+
+// An archive is a gzip compressed stream of newline delimited JSON entries, with a header entry,
+// an entry for each record, and a trailer entry that lists the number of records of each type.
+type archiveManager struct {
+	namespace  *core.Namespace
+	database   database.Plugin
+	transports map[string]events.Plugin
+}
+
+func NewArchiveManager(ns *core.Namespace, di database.Plugin, transports map[string]events.Plugin) Manager {
+	return &archiveManager{
+		namespace:  ns,
+		database:   di,
+		transports: transports,
+	}
+}
+
+// exportCollection reads a page of records of one type from the database
+type exportCollection struct {
+	archiveType core.NamespaceArchiveType
+	page        func(ctx context.Context, skip uint64) ([]interface{}, error)
+}
+
+func pageFilter(ctx context.Context, qf *ffapi.QueryFields, skip uint64) ffapi.AndFilter {
+	f := qf.NewFilterLimit(ctx, exportPageSize).And()
+	f.Skip(skip)
+	return f
+}
+
+func (am *archiveManager) exportCollections() []*exportCollection {
+	ns := am.namespace.Name
+	return []*exportCollection{
+		{archiveType: core.NamespaceArchiveTypeDatatypes, page: func(ctx context.Context, skip uint64) (records []interface{}, err error) {
+			datatypes, _, err := am.database.GetDatatypes(ctx, ns, pageFilter(ctx, database.DatatypeQueryFactory, skip))
+			for _, r := range datatypes {
+				records = append(records, r)
+			}
+			return records, err
+		}},
+		{archiveType: core.NamespaceArchiveTypeFFIs, page: func(ctx context.Context, skip uint64) (records []interface{}, err error) {
+			ffis, _, err := am.database.GetFFIs(ctx, ns, pageFilter(ctx, database.FFIQueryFactory, skip))
+			for _, r := range ffis {
+				records = append(records, r)
+			}
+			return records, err
+		}},
+		{archiveType: core.NamespaceArchiveTypeFFIMethods, page: func(ctx context.Context, skip uint64) (records []interface{}, err error) {
+			methods, _, err := am.database.GetFFIMethods(ctx, ns, pageFilter(ctx, database.FFIMethodQueryFactory, skip))
+			for _, r := range methods {
+				records = append(records, r)
+			}
+			return records, err
+		}},
+		{archiveType: core.NamespaceArchiveTypeFFIEvents, page: func(ctx context.Context, skip uint64) (records []interface{}, err error) {
+			events, _, err := am.database.GetFFIEvents(ctx, ns, pageFilter(ctx, database.FFIEventQueryFactory, skip))
+			for _, r := range events {
+				records = append(records, r)
+			}
+			return records, err
+		}},
+		{archiveType: core.NamespaceArchiveTypeFFIErrors, page: func(ctx context.Context, skip uint64) (records []interface{}, err error) {
+			errors, _, err := am.database.GetFFIErrors(ctx, ns, pageFilter(ctx, database.FFIErrorQueryFactory, skip))
+			for _, r := range errors {
+				records = append(records, r)
+			}
+			return records, err
+		}},
+		{archiveType: core.NamespaceArchiveTypeContractAPIs, page: func(ctx context.Context, skip uint64) (records []interface{}, err error) {
+			apis, _, err := am.database.GetContractAPIs(ctx, ns, pageFilter(ctx, database.ContractAPIQueryFactory, skip))
+			for _, r := range apis {
+				records = append(records, r)
+			}
+			return records, err
+		}},
+		{archiveType: core.NamespaceArchiveTypeContractListeners, page: func(ctx context.Context, skip uint64) (records []interface{}, err error) {
+			listeners, _, err := am.database.GetContractListeners(ctx, ns, pageFilter(ctx, database.ContractListenerQueryFactory, skip))
+			for _, r := range listeners {
+				records = append(records, r)
+			}
+			return records, err
+		}},
+		{archiveType: core.NamespaceArchiveTypeSubscriptions, page: func(ctx context.Context, skip uint64) (records []interface{}, err error) {
+			subs, _, err := am.database.GetSubscriptions(ctx, ns, pageFilter(ctx, database.SubscriptionQueryFactory, skip))
+			for _, r := range subs {
+				records = append(records, withoutSigningSecrets(ctx, r))
+			}
+			return records, err
+		}},
+		{archiveType: core.NamespaceArchiveTypeIdentities, page: func(ctx context.Context, skip uint64) (records []interface{}, err error) {
+			identities, _, err := am.database.GetIdentities(ctx, ns, pageFilter(ctx, database.IdentityQueryFactory, skip))
+			for _, r := range identities {
+				records = append(records, r)
+			}
+			return records, err
+		}},
+		{archiveType: core.NamespaceArchiveTypeVerifiers, page: func(ctx context.Context, skip uint64) (records []interface{}, err error) {
+			verifiers, _, err := am.database.GetVerifiers(ctx, ns, pageFilter(ctx, database.VerifierQueryFactory, skip))
+			for _, r := range verifiers {
+				records = append(records, r)
+			}
+			return records, err
+		}},
+		{archiveType: core.NamespaceArchiveTypeGroups, page: func(ctx context.Context, skip uint64) (records []interface{}, err error) {
+			groups, _, err := am.database.GetGroups(ctx, ns, pageFilter(ctx, database.GroupQueryFactory, skip))
+			for _, r := range groups {
+				records = append(records, r)
+			}
+			return records, err
+		}},
+	}
+}
+
+func (am *archiveManager) Export(ctx context.Context, w io.Writer) error {
+	gw := gzip.NewWriter(w)
+	enc := json.NewEncoder(gw)
+	err := am.database.RunAsReadSnapshot(ctx, func(ctx context.Context) error {
+		err := enc.Encode(&core.NamespaceArchiveEntry{
+			Header: &core.NamespaceArchiveHeader{
+				Version:     core.NamespaceArchiveVersion,
+				Namespace:   am.namespace.Name,
+				NetworkName: am.namespace.NetworkName,
+				Created:     fftypes.Now(),
+			},
+		})
+		if err != nil {
+			return err
+		}
+		counts := make(map[core.NamespaceArchiveType]int64)
+		for _, c := range am.exportCollections() {
+			for skip := uint64(0); ; skip += exportPageSize {
+				records, err := c.page(ctx, skip)
+				if err != nil {
+					return err
+				}
+				for _, r := range records {
+					b, _ := json.Marshal(r)
+					if err := enc.Encode(&core.NamespaceArchiveEntry{Type: c.archiveType, Record: fftypes.JSONAnyPtrBytes(b)}); err != nil {
+						return err
+					}
+				}
+				counts[c.archiveType] += int64(len(records))
+				if len(records) < exportPageSize {
+					break
+				}
+			}
+			log.L(ctx).Debugf("Exported %d %s from namespace '%s'", counts[c.archiveType], c.archiveType, am.namespace.Name)
+		}
+		return enc.Encode(&core.NamespaceArchiveEntry{
+			Trailer: &core.NamespaceArchiveTrailer{Counts: counts},
+		})
+	})
+	if err != nil {
+		return err
+	}
+	return gw.Close()
+}
+
+// withoutSigningSecrets removes the webhook signing secrets from a subscription. They are encrypted with the
+// signing encryption key of this node, so could not be used by the node the archive is imported into.
+func withoutSigningSecrets(ctx context.Context, sub *core.Subscription) *core.Subscription {
+	signing, ok := sub.Options.TransportOptions().GetObjectOk("signing")
+	if !ok || (signing.GetString("secret") == "" && signing.GetString("previousSecret") == "") {
+		return sub
+	}
+	stripped := fftypes.JSONObject{}
+	for k, v := range signing {
+		if k != "secret" && k != "previousSecret" && k != "previousSecretExpiry" {
+			stripped[k] = v
+		}
+	}
+	sub.Options.TransportOptions()["signing"] = stripped
+	sub.Options.Signing = core.WebhookSigningOptions{
+		Header:      sub.Options.Signing.Header,
+		VerifyReply: sub.Options.Signing.VerifyReply,
+	}
+	log.L(ctx).Infof("Removed the signing secrets of subscription '%s' from the archive. A new secret must be set after it is imported", sub.Name)
+	return sub
+}
@@ -0,0 +1,274 @@
+// Copyright © 2024 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nsarchive
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/hyperledger/firefly-common/pkg/config"
+	"github.com/hyperledger/firefly-common/pkg/fftypes"
+	"github.com/hyperledger/firefly/internal/events/webhooks"
+	"github.com/hyperledger/firefly/mocks/databasemocks"
+	"github.com/hyperledger/firefly/pkg/core"
+	"github.com/hyperledger/firefly/pkg/events"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type testRecords struct {
+	datatype    *core.Datatype
+	ffi         *fftypes.FFI
+	ffiMethod   *fftypes.FFIMethod
+	ffiEvent    *fftypes.FFIEvent
+	ffiError    *fftypes.FFIError
+	contractAPI *core.ContractAPI
+	listener    *core.ContractListener
+	sub         *core.Subscription
+	org         *core.Identity
+	node        *core.Identity
+	verifier    *core.Verifier
+	group       *core.Group
+}
+
+func newTestRecords(ns string) *testRecords {
+	ffiID := fftypes.NewUUID()
+	ffiRef := &fftypes.FFIReference{ID: ffiID, Name: "ffi1", Version: "1.0"}
+	org := &core.Identity{
+		IdentityBase: core.IdentityBase{
+			ID:        fftypes.NewUUID(),
+			DID:       "did:firefly:org/org1",
+			Namespace: ns,
+			Name:      "org1",
+			Type:      core.IdentityTypeOrg,
+		},
+	}
+	node := &core.Identity{
+		IdentityBase: core.IdentityBase{
+			ID:        fftypes.NewUUID(),
+			DID:       "did:firefly:node/node1",
+			Parent:    org.ID,
+			Namespace: ns,
+			Name:      "node1",
+			Type:      core.IdentityTypeNode,
+		},
+	}
+	group := &core.Group{
+		GroupIdentity: core.GroupIdentity{
+			Namespace: "network1",
+			Name:      "group1",
+			Members: core.Members{
+				{Identity: org.DID, Node: node.ID},
+			},
+		},
+		LocalNamespace: ns,
+	}
+	group.Seal()
+	return &testRecords{
+		datatype:    &core.Datatype{ID: fftypes.NewUUID(), Namespace: ns, Name: "dt1", Version: "1.0"},
+		ffi:         &fftypes.FFI{ID: ffiID, Namespace: ns, Name: "ffi1", Version: "1.0"},
+		ffiMethod:   &fftypes.FFIMethod{ID: fftypes.NewUUID(), Interface: ffiID, Namespace: ns, Name: "set", Pathname: "set"},
+		ffiEvent:    &fftypes.FFIEvent{ID: fftypes.NewUUID(), Interface: ffiID, Namespace: ns, Pathname: "changed"},
+		ffiError:    &fftypes.FFIError{ID: fftypes.NewUUID(), Interface: ffiID, Namespace: ns, Pathname: "failed"},
+		contractAPI: &core.ContractAPI{ID: fftypes.NewUUID(), Namespace: ns, Name: "api1", Interface: ffiRef},
+		listener: &core.ContractListener{
+			ID:        fftypes.NewUUID(),
+			Namespace: ns,
+			Name:      "listener1",
+			BackendID: "sb-1",
+			Interface: ffiRef,
+			Filters:   core.ListenerFilters{{Interface: ffiRef}},
+		},
+		sub:      newTestSubscription(ns, `{"url":"http://localhost:12345"}`),
+		org:      org,
+		node:     node,
+		verifier: (&core.Verifier{Identity: org.ID, Namespace: ns, VerifierRef: core.VerifierRef{Type: core.VerifierTypeEthAddress, Value: "0x12345"}}).Seal(),
+		group:    group,
+	}
+}
+
+func newTestSubscription(ns, options string) *core.Subscription {
+	sub := &core.Subscription{
+		SubscriptionRef: core.SubscriptionRef{ID: fftypes.NewUUID(), Namespace: ns, Name: "sub1"},
+		Transport:       "webhooks",
+	}
+	_ = json.Unmarshal([]byte(options), &sub.Options)
+	return sub
+}
+
+func newTestWebHooks(t *testing.T, name, encryptionKey string) map[string]events.Plugin {
+	wh := &webhooks.WebHooks{}
+	conf := config.RootSection("ut.nsarchive." + name)
+	wh.InitConfig(conf)
+	conf.SubSection(webhooks.WebhooksConfSigning).Set(webhooks.WebhooksConfSigningEncryptionKey, encryptionKey)
+	err := wh.Init(context.Background(), conf)
+	assert.NoError(t, err)
+	return map[string]events.Plugin{"webhooks": wh}
+}
+
+func newTestArchiveManager(t *testing.T) (*archiveManager, *databasemocks.Plugin) {
+	mdi := &databasemocks.Plugin{}
+	t.Cleanup(func() { mdi.AssertExpectations(t) })
+	am := NewArchiveManager(&core.Namespace{Name: "ns1", NetworkName: "network1"}, mdi, newTestWebHooks(t, "source", "sourcekey")).(*archiveManager)
+	return am, mdi
+}
+
+func mockRunAs(mdi *databasemocks.Plugin, method string) {
+	run := mdi.On(method, mock.Anything, mock.Anything)
+	run.RunFn = func(a mock.Arguments) {
+		run.ReturnArguments = mock.Arguments{a[1].(func(context.Context) error)(a[0].(context.Context))}
+	}
+}
+
+func mockExport(mdi *databasemocks.Plugin, tr *testRecords) {
+	mdi.On("GetDatatypes", mock.Anything, "ns1", mock.Anything).Return([]*core.Datatype{tr.datatype}, nil, nil)
+	mockExportExceptDatatypes(mdi, tr)
+}
+
+func mockExportExceptDatatypes(mdi *databasemocks.Plugin, tr *testRecords) {
+	mockRunAs(mdi, "RunAsReadSnapshot")
+	mdi.On("GetFFIs", mock.Anything, "ns1", mock.Anything).Return([]*fftypes.FFI{tr.ffi}, nil, nil)
+	mdi.On("GetFFIMethods", mock.Anything, "ns1", mock.Anything).Return([]*fftypes.FFIMethod{tr.ffiMethod}, nil, nil)
+	mdi.On("GetFFIEvents", mock.Anything, "ns1", mock.Anything).Return([]*fftypes.FFIEvent{tr.ffiEvent}, nil, nil)
+	mdi.On("GetFFIErrors", mock.Anything, "ns1", mock.Anything).Return([]*fftypes.FFIError{tr.ffiError}, nil, nil)
+	mdi.On("GetContractAPIs", mock.Anything, "ns1", mock.Anything).Return([]*core.ContractAPI{tr.contractAPI}, nil, nil)
+	mdi.On("GetContractListeners", mock.Anything, "ns1", mock.Anything).Return([]*core.ContractListener{tr.listener}, nil, nil)
+	mdi.On("GetSubscriptions", mock.Anything, "ns1", mock.Anything).Return([]*core.Subscription{tr.sub}, nil, nil)
+	mdi.On("GetIdentities", mock.Anything, "ns1", mock.Anything).Return([]*core.Identity{tr.org, tr.node}, nil, nil)
+	mdi.On("GetVerifiers", mock.Anything, "ns1", mock.Anything).Return([]*core.Verifier{tr.verifier}, nil, nil)
+	mdi.On("GetGroups", mock.Anything, "ns1", mock.Anything).Return([]*core.Group{tr.group}, nil, nil)
+}
+
+func exportTestArchive(t *testing.T, tr *testRecords) []byte {
+	am, mdi := newTestArchiveManager(t)
+	mockExport(mdi, tr)
+	var b bytes.Buffer
+	err := am.Export(context.Background(), &b)
+	assert.NoError(t, err)
+	return b.Bytes()
+}
+
+type failingWriter struct {
+	writes int
+}
+
+func (fw *failingWriter) Write(p []byte) (int, error) {
+	if fw.writes <= 0 {
+		return 0, fmt.Errorf("pop")
+	}
+	fw.writes--
+	return len(p), nil
+}
+
+func TestExport(t *testing.T) {
+	tr := newTestRecords("ns1")
+	b := exportTestArchive(t, tr)
+
+	a, err := readArchive(context.Background(), bytes.NewReader(b))
+	assert.NoError(t, err)
+	assert.Equal(t, core.NamespaceArchiveVersion, a.header.Version)
+	assert.Equal(t, "ns1", a.header.Namespace)
+	assert.Equal(t, "network1", a.header.NetworkName)
+	assert.Equal(t, tr.datatype.ID, a.datatypes[0].ID)
+	assert.Equal(t, tr.ffi.ID, a.ffis[0].ID)
+	assert.Equal(t, tr.ffiMethod.ID, a.ffiMethods[0].ID)
+	assert.Equal(t, tr.ffiEvent.ID, a.ffiEvents[0].ID)
+	assert.Equal(t, tr.ffiError.ID, a.ffiErrors[0].ID)
+	assert.Equal(t, tr.contractAPI.ID, a.contractAPIs[0].ID)
+	assert.Equal(t, "sb-1", a.contractListeners[0].BackendID)
+	assert.Equal(t, tr.sub.ID, a.subscriptions[0].ID)
+	assert.Len(t, a.identities, 2)
+	assert.Equal(t, tr.verifier.Hash, a.verifiers[0].Hash)
+	assert.Equal(t, tr.group.Hash, a.groups[0].Hash)
+	for _, archiveType := range core.NamespaceArchiveTypes {
+		assert.NotZero(t, a.count(archiveType))
+	}
+}
+
+func TestExportRemovesSigningSecrets(t *testing.T) {
+	tr := newTestRecords("ns1")
+	tr.sub = newTestSubscription("ns1", `{"url":"http://localhost:12345","signing":{"secret":"secret1","previousSecret":"secret0","previousSecretExpiry":"2024-01-01T00:00:00Z","header":"X-Sig"}}`)
+	am, mdi := newTestArchiveManager(t)
+	// The secrets are encrypted with the key of the source node when the subscription is created
+	err := am.transports["webhooks"].ValidateOptions(context.Background(), &tr.sub.Options)
+	assert.NoError(t, err)
+	assert.Regexp(t, "^enc:", tr.sub.Options.TransportOptions().GetObject("signing").GetString("secret"))
+
+	mockExport(mdi, tr)
+	var b bytes.Buffer
+	err = am.Export(context.Background(), &b)
+	assert.NoError(t, err)
+	assert.NotContains(t, b.String(), "enc:")
+
+	a, err := readArchive(context.Background(), &b)
+	assert.NoError(t, err)
+	options := a.subscriptions[0].Options
+	assert.Equal(t, fftypes.JSONObject{"header": "X-Sig"}, options.TransportOptions().GetObject("signing"))
+	assert.Equal(t, core.WebhookSigningOptions{Header: "X-Sig"}, options.Signing)
+}
+
+func TestExportPages(t *testing.T) {
+	am, mdi := newTestArchiveManager(t)
+	tr := newTestRecords("ns1")
+	mockExportExceptDatatypes(mdi, tr)
+	page := make([]*core.Datatype, exportPageSize)
+	for i := range page {
+		page[i] = &core.Datatype{ID: fftypes.NewUUID(), Namespace: "ns1", Name: fmt.Sprintf("dt%d", i)}
+	}
+	mdi.On("GetDatatypes", mock.Anything, "ns1", mock.Anything).Return(page, nil, nil).Once()
+	mdi.On("GetDatatypes", mock.Anything, "ns1", mock.Anything).Return([]*core.Datatype{tr.datatype}, nil, nil).Once()
+	var b bytes.Buffer
+	err := am.Export(context.Background(), &b)
+	assert.NoError(t, err)
+
+	a, err := readArchive(context.Background(), &b)
+	assert.NoError(t, err)
+	assert.Len(t, a.datatypes, exportPageSize+1)
+}
+
+func TestExportQueryFail(t *testing.T) {
+	am, mdi := newTestArchiveManager(t)
+	mockRunAs(mdi, "RunAsReadSnapshot")
+	mdi.On("GetDatatypes", mock.Anything, "ns1", mock.Anything).Return(nil, nil, fmt.Errorf("pop"))
+	var b bytes.Buffer
+	err := am.Export(context.Background(), &b)
+	assert.Regexp(t, "pop", err)
+}
+
+func TestExportHeaderWriteFail(t *testing.T) {
+	am, mdi := newTestArchiveManager(t)
+	mockRunAs(mdi, "RunAsReadSnapshot")
+	err := am.Export(context.Background(), &failingWriter{})
+	assert.Regexp(t, "pop", err)
+}
+
+func TestExportRecordWriteFail(t *testing.T) {
+	am, mdi := newTestArchiveManager(t)
+	mockRunAs(mdi, "RunAsReadSnapshot")
+	page := make([]*core.Datatype, exportPageSize)
+	for i := range page {
+		// Random values do not compress, so the gzip writer flushes to the underlying writer
+		page[i] = &core.Datatype{ID: fftypes.NewUUID(), Namespace: "ns1", Name: fftypes.NewRandB32().String()}
+		page[i].Value = fftypes.JSONAnyPtr(fmt.Sprintf(`{"hash":"%s"}`, fftypes.NewRandB32()))
+	}
+	mdi.On("GetDatatypes", mock.Anything, "ns1", mock.Anything).Return(page, nil, nil)
+	err := am.Export(context.Background(), &failingWriter{writes: 1})
+	assert.Regexp(t, "pop", err)
+}
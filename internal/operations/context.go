@@ -22,6 +22,7 @@ import (
 
 	"github.com/hyperledger/firefly-common/pkg/fftypes"
 	"github.com/hyperledger/firefly-common/pkg/log"
+	"github.com/hyperledger/firefly/internal/tracing"
 	"github.com/hyperledger/firefly/pkg/core"
 	"github.com/hyperledger/firefly/pkg/database"
 )
@@ -66,6 +67,9 @@ func RunWithOperationContext(ctx context.Context, fn func(ctx context.Context) e
 }
 
 func (om *operationsManager) AddOrReuseOperation(ctx context.Context, op *core.Operation, hooks ...database.PostCompletionHook) error {
+	if op.TraceContext == nil {
+		op.TraceContext = tracing.TraceContext(ctx)
+	}
 	// If a ops has been created via RunWithOperationCache, detect duplicate operation inserts
 	ops := getOperationContext(ctx)
 	if ops != nil {
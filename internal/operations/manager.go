@@ -27,6 +27,7 @@ import (
 	"github.com/hyperledger/firefly/internal/cache"
 	"github.com/hyperledger/firefly/internal/coreconfig"
 	"github.com/hyperledger/firefly/internal/coremsgs"
+	"github.com/hyperledger/firefly/internal/tracing"
 	"github.com/hyperledger/firefly/internal/txcommon"
 	"github.com/hyperledger/firefly/pkg/core"
 	"github.com/hyperledger/firefly/pkg/database"
@@ -167,6 +168,11 @@ func (om *operationsManager) RunOperation(ctx context.Context, op *core.Prepared
 	if !ok {
 		return nil, i18n.NewError(ctx, coremsgs.MsgOperationNotSupported, op.Type)
 	}
+	ctx, span := tracing.StartSpan(ctx, "operation.run",
+		tracing.NamespaceKey.String(op.Namespace),
+		tracing.OperationIDKey.String(op.ID.String()),
+		tracing.OperationTypeKey.String(string(op.Type)),
+	)
 	log.L(ctx).Infof("Executing %s operation %s via handler %s", op.Type, op.ID, handler.Name())
 	log.L(ctx).Tracef("Operation detail: %+v", op)
 	outputs, phase, err := handler.RunOperation(ctx, op)
@@ -216,6 +222,7 @@ func (om *operationsManager) RunOperation(ctx context.Context, op *core.Prepared
 			Output:         outputs,
 		})
 	}
+	tracing.EndSpan(span, err)
 	return outputs, err
 }
 
@@ -256,6 +263,10 @@ func (om *operationsManager) RetryOperation(ctx context.Context, opID *fftypes.U
 		op.Output = nil
 		op.Created = fftypes.Now()
 		op.Updated = op.Created
+		if tc := tracing.TraceContext(ctx); tc != nil {
+			// Continue the trace of the retry request, rather than the original submission
+			op.TraceContext = tc
+		}
 		if err = om.database.InsertOperation(ctx, op); err != nil {
 			return err
 		}
@@ -306,7 +317,7 @@ func (om *operationsManager) GetOperationByIDCached(ctx context.Context, opID *f
 	if cached := om.getCachedOperation(opID); cached != nil {
 		return cached, nil
 	}
-	op, err := om.database.GetOperationByID(ctx, om.namespace, opID)
+	op, err := om.database.GetOperationByID(database.WithPrimaryReads(ctx), om.namespace, opID)
 	if err == nil && op != nil {
 		om.cacheOperation(op)
 	}
@@ -326,7 +337,7 @@ func (om *operationsManager) getOperationsCached(ctx context.Context, opIDs []*f
 
 	if len(cacheMisses) > 0 {
 		opFilter := database.OperationQueryFactory.NewFilter(ctx).In("id", cacheMisses)
-		dbOps, _, err := om.database.GetOperations(ctx, om.namespace, opFilter)
+		dbOps, _, err := om.database.GetOperations(database.WithPrimaryReads(ctx), om.namespace, opFilter)
 		if err != nil {
 			return nil, err
 		}
@@ -35,6 +35,7 @@ import (
 	"github.com/hyperledger/firefly/pkg/database"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"go.opentelemetry.io/otel/trace"
 )
 
 type mockHandler struct {
@@ -189,6 +190,7 @@ func TestRunOperationSyncSuccess(t *testing.T) {
 	om.updater.workQueues = []chan *core.OperationUpdate{
 		make(chan *core.OperationUpdate),
 	}
+	om.updater.queuesReady.Store(true)
 	om.updater.cancelFunc()
 
 	ctx := context.Background()
@@ -211,6 +213,7 @@ func TestRunOperationFailIdempotentInit(t *testing.T) {
 	om.updater.workQueues = []chan *core.OperationUpdate{
 		make(chan *core.OperationUpdate, 1),
 	}
+	om.updater.queuesReady.Store(true)
 
 	ctx := context.Background()
 	op := &core.PreparedOperation{
@@ -239,6 +242,7 @@ func TestRunOperationFailNonIdempotentInit(t *testing.T) {
 	om.updater.workQueues = []chan *core.OperationUpdate{
 		make(chan *core.OperationUpdate, 1),
 	}
+	om.updater.queuesReady.Store(true)
 
 	ctx := context.Background()
 	op := &core.PreparedOperation{
@@ -267,6 +271,7 @@ func TestRunOperationFailConflict(t *testing.T) {
 	om.updater.workQueues = []chan *core.OperationUpdate{
 		make(chan *core.OperationUpdate, 1),
 	}
+	om.updater.queuesReady.Store(true)
 
 	ctx := context.Background()
 	op := &core.PreparedOperation{
@@ -292,6 +297,7 @@ func TestRunOperationFailRemainPending(t *testing.T) {
 	om.updater.workQueues = []chan *core.OperationUpdate{
 		make(chan *core.OperationUpdate),
 	}
+	om.updater.queuesReady.Store(true)
 	om.updater.cancelFunc()
 
 	ctx := context.Background()
@@ -314,7 +320,14 @@ func TestRetryOperationSuccess(t *testing.T) {
 	om, cancel := newTestOperations(t)
 	defer cancel()
 
-	ctx := context.Background()
+	// The retry request is traced
+	traceID, _ := trace.TraceIDFromHex("0af7651916cd43dd8448eb211c80319c")
+	spanID, _ := trace.SpanIDFromHex("b7ad6b7169203331")
+	ctx := trace.ContextWithSpanContext(context.Background(), trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    traceID,
+		SpanID:     spanID,
+		TraceFlags: trace.FlagsSampled,
+	}))
 	opID := fftypes.NewUUID()
 	txID := fftypes.NewUUID()
 	op := &core.Operation{
@@ -365,6 +378,7 @@ func TestRetryOperationSuccess(t *testing.T) {
 
 	assert.NoError(t, err)
 	assert.NotNil(t, newOp)
+	assert.Equal(t, "00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01", newOp.TraceContext.GetString("traceparent"))
 
 	mdi.AssertExpectations(t)
 }
@@ -742,3 +756,27 @@ func TestErrTernaryHelper(t *testing.T) {
 	assert.Equal(t, core.OpPhasePending, ErrTernary(nil, core.OpPhaseInitializing, core.OpPhasePending))
 	assert.Equal(t, core.OpPhaseInitializing, ErrTernary(fmt.Errorf("pop"), core.OpPhaseInitializing, core.OpPhasePending))
 }
+
+func TestGetOperationByIDCachedReadsPrimary(t *testing.T) {
+	om, cancel := newTestOperations(t)
+	defer cancel()
+
+	ctx := database.WithReplicaReads(context.Background())
+	op := &core.Operation{ID: fftypes.NewUUID()}
+	primaryReads := mock.MatchedBy(func(ctx context.Context) bool {
+		return !database.ReplicaReadsAllowed(ctx)
+	})
+	mdi := om.database.(*databasemocks.Plugin)
+	mdi.On("GetOperationByID", primaryReads, "ns1", op.ID).Return(op, nil).Once()
+	mdi.On("GetOperations", primaryReads, "ns1", mock.Anything).Return([]*core.Operation{{ID: fftypes.NewUUID()}}, nil, nil).Once()
+
+	result, err := om.GetOperationByIDCached(ctx, op.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, op, result)
+
+	ops, err := om.getOperationsCached(ctx, []*fftypes.UUID{op.ID, fftypes.NewUUID()})
+	assert.NoError(t, err)
+	assert.Len(t, ops, 2)
+
+	mdi.AssertExpectations(t)
+}
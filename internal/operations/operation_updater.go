@@ -19,6 +19,7 @@ package operations
 import (
 	"context"
 	"fmt"
+	"sync/atomic"
 	"time"
 
 	"github.com/hyperledger/firefly-common/pkg/config"
@@ -29,6 +30,7 @@ import (
 	"github.com/hyperledger/firefly-common/pkg/retry"
 	"github.com/hyperledger/firefly/internal/coreconfig"
 	"github.com/hyperledger/firefly/internal/coremsgs"
+	"github.com/hyperledger/firefly/internal/tracing"
 	"github.com/hyperledger/firefly/internal/txcommon"
 	"github.com/hyperledger/firefly/pkg/core"
 	"github.com/hyperledger/firefly/pkg/database"
@@ -49,6 +51,7 @@ type operationUpdater struct {
 	txHelper    txcommon.Helper
 	workQueues  []chan *core.OperationUpdate
 	workersDone []chan struct{}
+	queuesReady atomic.Bool
 	conf        operationUpdaterConf
 	closed      bool
 	retry       *retry.Retry
@@ -104,7 +107,9 @@ func (ou *operationUpdater) SubmitOperationUpdate(ctx context.Context, update *c
 		return
 	}
 
-	if ou.conf.workerCount > 0 {
+	// Until the workers are started, which only happens on the leader when leader election is enabled, updates are
+	// processed in-line
+	if ou.conf.workerCount > 0 && ou.queuesReady.Load() {
 		if update.Status == core.OpStatusFailed {
 			// We do a cache update pre-emptively, as for idempotency checking on an error status we want to
 			// see the update immediately - even though it's being asynchronously flushed to the storage
@@ -132,6 +137,7 @@ func (ou *operationUpdater) initQueues() {
 		ou.workQueues[i] = make(chan *core.OperationUpdate, ou.conf.queueLength)
 		ou.workersDone[i] = make(chan struct{})
 	}
+	ou.queuesReady.Store(true)
 }
 
 func (ou *operationUpdater) start() {
@@ -240,7 +246,7 @@ func (ou *operationUpdater) doBatchUpdate(ctx context.Context, updates []*core.O
 	return nil
 }
 
-func (ou *operationUpdater) doUpdate(ctx context.Context, update *core.OperationUpdate, ops []*core.Operation, transactions []*core.Transaction) error {
+func (ou *operationUpdater) doUpdate(ctx context.Context, update *core.OperationUpdate, ops []*core.Operation, transactions []*core.Transaction) (err error) {
 
 	_, updateID, err := core.ParseNamespacedOpID(ctx, update.NamespacedOpID)
 	if err != nil {
@@ -265,6 +271,15 @@ func (ou *operationUpdater) doUpdate(ctx context.Context, update *core.Operation
 		return nil
 	}
 
+	// Continue the trace of the request that submitted the operation
+	ctx, span := tracing.StartSpanFrom(ctx, "operation.update", []fftypes.JSONObject{op.TraceContext},
+		tracing.NamespaceKey.String(op.Namespace),
+		tracing.OperationIDKey.String(op.ID.String()),
+		tracing.OperationTypeKey.String(string(op.Type)),
+		tracing.OperationStatusKey.String(string(update.Status)),
+	)
+	defer func() { tracing.EndSpan(span, err) }()
+
 	// Match a TX we already retrieved, if found add a specified Blockchain Transaction ID to it
 	var tx *core.Transaction
 	if op.Transaction != nil && update.BlockchainTXID != "" {
@@ -120,6 +120,7 @@ func TestSubmitUpdateClosed(t *testing.T) {
 	ou.workQueues = []chan *core.OperationUpdate{
 		make(chan *core.OperationUpdate),
 	}
+	ou.queuesReady.Store(true)
 	ou.cancelFunc()
 	ou.SubmitOperationUpdate(ou.ctx, &core.OperationUpdate{
 		NamespacedOpID: "ns1:" + fftypes.NewUUID().String(),
@@ -148,6 +149,29 @@ func TestSubmitUpdateSyncFallbackOpNotFound(t *testing.T) {
 	mdi.AssertExpectations(t)
 }
 
+func TestSubmitUpdateInlineBeforeStart(t *testing.T) {
+	ou := newTestOperationUpdater(t)
+	defer ou.close()
+	assert.Greater(t, ou.conf.workerCount, 0)
+	customCtx := context.WithValue(context.Background(), "dbtx", "on this context")
+
+	mdi := ou.database.(*databasemocks.Plugin)
+	mdi.On("RunAsGroup", customCtx, mock.Anything).Run(func(args mock.Arguments) {
+		err := args[1].(func(context.Context) error)(customCtx)
+		assert.NoError(t, err)
+	}).Return(nil)
+	mdi.On("GetOperations", customCtx, mock.Anything, mock.Anything).Return(nil, nil, nil)
+
+	complete := false
+	ou.SubmitOperationUpdate(customCtx, &core.OperationUpdate{
+		NamespacedOpID: "ns1:" + fftypes.NewUUID().String(),
+		OnComplete:     func() { complete = true },
+	})
+	assert.True(t, complete)
+
+	mdi.AssertExpectations(t)
+}
+
 func TestSubmitUpdateDatabaseError(t *testing.T) {
 	ou := newTestOperationUpdaterNoConcurrency(t)
 	defer ou.close()
@@ -0,0 +1,78 @@
+// Copyright © 2024 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package orchestrator
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+
+	"github.com/hyperledger/firefly-common/pkg/ffapi"
+	"github.com/hyperledger/firefly-common/pkg/fftypes"
+	"github.com/hyperledger/firefly-common/pkg/i18n"
+	"github.com/hyperledger/firefly/internal/coremsgs"
+	"github.com/hyperledger/firefly/pkg/core"
+)
+
+const apiKeyLength = 32
+
+func (or *orchestrator) CreateAPIKey(ctx context.Context, apiKey *core.APIKey) (*core.APIKey, error) {
+	if err := fftypes.ValidateFFNameFieldNoUUID(ctx, apiKey.Name, "name"); err != nil {
+		return nil, err
+	}
+	keyBytes := make([]byte, apiKeyLength)
+	_, _ = rand.Read(keyBytes)
+	key := base64.RawURLEncoding.EncodeToString(keyBytes)
+
+	apiKey.ID = fftypes.NewUUID()
+	apiKey.Namespace = or.namespace.Name
+	apiKey.Hash = core.HashAPIKey(key)
+	apiKey.Created = fftypes.Now()
+	if err := or.database().InsertAPIKey(ctx, apiKey); err != nil {
+		return nil, err
+	}
+	// The key is only ever returned here, as just the hash is stored
+	apiKey.Key = key
+	return apiKey, nil
+}
+
+func (or *orchestrator) GetAPIKeys(ctx context.Context, filter ffapi.AndFilter) ([]*core.APIKey, *ffapi.FilterResult, error) {
+	return or.database().GetAPIKeys(ctx, or.namespace.Name, filter)
+}
+
+func (or *orchestrator) GetAPIKeyByID(ctx context.Context, id string) (*core.APIKey, error) {
+	u, err := fftypes.ParseUUID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	apiKey, err := or.database().GetAPIKeyByID(ctx, or.namespace.Name, u)
+	if err != nil {
+		return nil, err
+	}
+	if apiKey == nil {
+		return nil, i18n.NewError(ctx, coremsgs.Msg404NotFound)
+	}
+	return apiKey, nil
+}
+
+func (or *orchestrator) DeleteAPIKey(ctx context.Context, id string) error {
+	apiKey, err := or.GetAPIKeyByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	return or.database().DeleteAPIKey(ctx, or.namespace.Name, apiKey.ID)
+}
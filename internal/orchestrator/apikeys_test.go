@@ -0,0 +1,134 @@
+// Copyright © 2024 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package orchestrator
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/hyperledger/firefly-common/pkg/fftypes"
+	"github.com/hyperledger/firefly/pkg/core"
+	"github.com/hyperledger/firefly/pkg/database"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestCreateAPIKey(t *testing.T) {
+	or := newTestOrchestrator()
+	defer or.cleanup(t)
+
+	var hash string
+	or.mdi.On("InsertAPIKey", mock.Anything, mock.MatchedBy(func(apiKey *core.APIKey) bool {
+		hash = apiKey.Hash
+		return apiKey.ID != nil && apiKey.Namespace == "ns" && apiKey.Key == "" && apiKey.Created != nil
+	})).Return(nil)
+	apiKey, err := or.CreateAPIKey(context.Background(), &core.APIKey{
+		Name:  "app1",
+		Roles: fftypes.FFStringArray{"reader"},
+	})
+	assert.NoError(t, err)
+	assert.NotEmpty(t, apiKey.Key)
+	assert.Equal(t, core.HashAPIKey(apiKey.Key), hash)
+}
+
+func TestCreateAPIKeyBadName(t *testing.T) {
+	or := newTestOrchestrator()
+	defer or.cleanup(t)
+
+	_, err := or.CreateAPIKey(context.Background(), &core.APIKey{Name: "!bad"})
+	assert.Regexp(t, "FF00140", err)
+}
+
+func TestCreateAPIKeyInsertFail(t *testing.T) {
+	or := newTestOrchestrator()
+	defer or.cleanup(t)
+
+	or.mdi.On("InsertAPIKey", mock.Anything, mock.Anything).Return(fmt.Errorf("pop"))
+	_, err := or.CreateAPIKey(context.Background(), &core.APIKey{Name: "app1"})
+	assert.EqualError(t, err, "pop")
+}
+
+func TestGetAPIKeys(t *testing.T) {
+	or := newTestOrchestrator()
+	defer or.cleanup(t)
+
+	or.mdi.On("GetAPIKeys", mock.Anything, "ns", mock.Anything).Return([]*core.APIKey{}, nil, nil)
+	fb := database.APIKeyQueryFactory.NewFilter(context.Background())
+	_, _, err := or.GetAPIKeys(context.Background(), fb.And(fb.Eq("name", "app1")))
+	assert.NoError(t, err)
+}
+
+func TestGetAPIKeyByID(t *testing.T) {
+	or := newTestOrchestrator()
+	defer or.cleanup(t)
+
+	apiKey := &core.APIKey{ID: fftypes.NewUUID()}
+	or.mdi.On("GetAPIKeyByID", mock.Anything, "ns", apiKey.ID).Return(apiKey, nil)
+	res, err := or.GetAPIKeyByID(context.Background(), apiKey.ID.String())
+	assert.NoError(t, err)
+	assert.Equal(t, apiKey, res)
+}
+
+func TestGetAPIKeyByIDBadUUID(t *testing.T) {
+	or := newTestOrchestrator()
+	defer or.cleanup(t)
+
+	_, err := or.GetAPIKeyByID(context.Background(), "! a UUID")
+	assert.Regexp(t, "FF00138", err)
+}
+
+func TestGetAPIKeyByIDNotFound(t *testing.T) {
+	or := newTestOrchestrator()
+	defer or.cleanup(t)
+
+	id := fftypes.NewUUID()
+	or.mdi.On("GetAPIKeyByID", mock.Anything, "ns", id).Return(nil, nil)
+	_, err := or.GetAPIKeyByID(context.Background(), id.String())
+	assert.Regexp(t, "FF10109", err)
+}
+
+func TestGetAPIKeyByIDFail(t *testing.T) {
+	or := newTestOrchestrator()
+	defer or.cleanup(t)
+
+	id := fftypes.NewUUID()
+	or.mdi.On("GetAPIKeyByID", mock.Anything, "ns", id).Return(nil, fmt.Errorf("pop"))
+	_, err := or.GetAPIKeyByID(context.Background(), id.String())
+	assert.EqualError(t, err, "pop")
+}
+
+func TestDeleteAPIKey(t *testing.T) {
+	or := newTestOrchestrator()
+	defer or.cleanup(t)
+
+	apiKey := &core.APIKey{ID: fftypes.NewUUID()}
+	or.mdi.On("GetAPIKeyByID", mock.Anything, "ns", apiKey.ID).Return(apiKey, nil)
+	or.mdi.On("DeleteAPIKey", mock.Anything, "ns", apiKey.ID).Return(nil)
+	err := or.DeleteAPIKey(context.Background(), apiKey.ID.String())
+	assert.NoError(t, err)
+}
+
+func TestDeleteAPIKeyNotFound(t *testing.T) {
+	or := newTestOrchestrator()
+	defer or.cleanup(t)
+
+	_, err := or.GetAPIKeyByID(context.Background(), "! a UUID")
+	assert.Regexp(t, "FF00138", err)
+	err = or.DeleteAPIKey(context.Background(), "! a UUID")
+	assert.Regexp(t, "FF00138", err)
+}
@@ -18,6 +18,7 @@ package orchestrator
 
 import (
 	"context"
+	"database/sql/driver"
 	"sync"
 
 	"github.com/hyperledger/firefly-common/pkg/fftypes"
@@ -25,6 +26,7 @@ import (
 	"github.com/hyperledger/firefly/internal/coremsgs"
 	"github.com/hyperledger/firefly/pkg/blockchain"
 	"github.com/hyperledger/firefly/pkg/core"
+	"github.com/hyperledger/firefly/pkg/database"
 	"github.com/hyperledger/firefly/pkg/dataexchange"
 	"github.com/hyperledger/firefly/pkg/tokens"
 )
@@ -45,6 +47,36 @@ func (bc *boundCallbacks) OperationUpdate(update *core.OperationUpdate) {
 	bc.o.operations.SubmitOperationUpdate(update)
 }
 
+func (bc *boundCallbacks) GetPendingOperations(ctx context.Context, plugin string, opTypes []core.OpType, skip, limit uint64) ([]*core.Operation, error) {
+	types := make([]driver.Value, len(opTypes))
+	for i, opType := range opTypes {
+		types[i] = opType
+	}
+	fb := database.OperationQueryFactory.NewFilter(ctx)
+	filter := fb.And(
+		fb.Eq("plugin", plugin),
+		fb.In("type", types),
+		fb.Eq("status", core.OpStatusPending),
+	).
+		Sort("created").
+		Skip(skip).
+		Limit(limit)
+	ops, _, err := bc.o.database().GetOperations(ctx, bc.o.namespace.Name, filter)
+	return ops, err
+}
+
+func (bc *boundCallbacks) GetContractListenerBackfill(ctx context.Context, id *fftypes.UUID) (*core.ContractListenerBackfill, *core.ContractListener, error) {
+	backfill, err := bc.o.database().GetContractListenerBackfillByID(ctx, bc.o.namespace.Name, id)
+	if err != nil || backfill == nil {
+		return nil, nil, err
+	}
+	listener, err := bc.o.database().GetContractListenerByID(ctx, bc.o.namespace.Name, backfill.Listener)
+	if err != nil || listener == nil {
+		return nil, nil, err
+	}
+	return backfill, listener, nil
+}
+
 func (bc *boundCallbacks) SharedStorageBatchDownloaded(payloadRef string, data []byte) (*fftypes.UUID, error) {
 	if err := bc.checkStopped(); err != nil {
 		return nil, err
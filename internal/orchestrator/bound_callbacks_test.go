@@ -21,7 +21,9 @@ import (
 	"fmt"
 	"testing"
 
+	"github.com/hyperledger/firefly-common/pkg/ffapi"
 	"github.com/hyperledger/firefly-common/pkg/fftypes"
+	"github.com/hyperledger/firefly/mocks/databasemocks"
 	"github.com/hyperledger/firefly/mocks/dataexchangemocks"
 	"github.com/hyperledger/firefly/mocks/eventmocks"
 	"github.com/hyperledger/firefly/mocks/operationmocks"
@@ -137,3 +139,51 @@ func TestBoundCallbacksStopped(t *testing.T) {
 	err = bc.TokensApproved(nil, &tokens.TokenApproval{})
 	assert.Regexp(t, "FF10446", err)
 }
+
+func TestBoundCallbacksGetPendingOperations(t *testing.T) {
+	_, _, _, bc := newTestBoundCallbacks(t)
+	mdi := &databasemocks.Plugin{}
+	bc.o.plugins.Database.Plugin = mdi
+
+	ops := []*core.Operation{{ID: fftypes.NewUUID()}}
+	mdi.On("GetOperations", mock.Anything, "ns1", mock.MatchedBy(func(filter ffapi.Filter) bool {
+		info, _ := filter.Finalize()
+		return info.String() == "( plugin == 'ethrpc' ) && ( type IN ['blockchain_invoke','blockchain_deploy'] ) && ( status == 'Pending' ) sort=created skip=50 limit=25" &&
+			info.Skip == 50 && info.Limit == 25
+	})).Return(ops, nil, nil)
+
+	result, err := bc.GetPendingOperations(context.Background(), "ethrpc", []core.OpType{core.OpTypeBlockchainInvoke, core.OpTypeBlockchainContractDeploy}, 50, 25)
+	assert.NoError(t, err)
+	assert.Equal(t, ops, result)
+
+	mdi.AssertExpectations(t)
+}
+
+func TestBoundCallbacksGetContractListenerBackfill(t *testing.T) {
+	_, _, _, bc := newTestBoundCallbacks(t)
+	mdi := &databasemocks.Plugin{}
+	bc.o.plugins.Database.Plugin = mdi
+
+	backfill := &core.ContractListenerBackfill{ID: fftypes.NewUUID(), Listener: fftypes.NewUUID()}
+	listener := &core.ContractListener{ID: backfill.Listener}
+	mdi.On("GetContractListenerBackfillByID", mock.Anything, "ns1", backfill.ID).Return(backfill, nil).Once()
+	mdi.On("GetContractListenerByID", mock.Anything, "ns1", listener.ID).Return(listener, nil).Once()
+	bf, l, err := bc.GetContractListenerBackfill(context.Background(), backfill.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, backfill, bf)
+	assert.Equal(t, listener, l)
+
+	// The listener has been deleted
+	mdi.On("GetContractListenerBackfillByID", mock.Anything, "ns1", backfill.ID).Return(backfill, nil).Once()
+	mdi.On("GetContractListenerByID", mock.Anything, "ns1", listener.ID).Return(nil, nil).Once()
+	bf, l, err = bc.GetContractListenerBackfill(context.Background(), backfill.ID)
+	assert.NoError(t, err)
+	assert.Nil(t, bf)
+	assert.Nil(t, l)
+
+	mdi.On("GetContractListenerBackfillByID", mock.Anything, "ns1", backfill.ID).Return(nil, fmt.Errorf("pop")).Once()
+	_, _, err = bc.GetContractListenerBackfill(context.Background(), backfill.ID)
+	assert.EqualError(t, err, "pop")
+
+	mdi.AssertExpectations(t)
+}
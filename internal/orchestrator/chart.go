@@ -19,6 +19,7 @@ package orchestrator
 import (
 	"context"
 
+	"github.com/hyperledger/firefly-common/pkg/ffapi"
 	"github.com/hyperledger/firefly-common/pkg/fftypes"
 	"github.com/hyperledger/firefly-common/pkg/i18n"
 	"github.com/hyperledger/firefly/internal/coremsgs"
@@ -26,6 +27,30 @@ import (
 	"github.com/hyperledger/firefly/pkg/database"
 )
 
+// chartQueryFactories are the fields that the records of each collection can be filtered, grouped and aggregated by
+var chartQueryFactories = map[database.CollectionName]*ffapi.QueryFields{
+	database.CollectionName(database.CollectionMessages):         database.MessageQueryFactory,
+	database.CollectionName(database.CollectionTransactions):     database.TransactionQueryFactory,
+	database.CollectionName(database.CollectionOperations):       database.OperationQueryFactory,
+	database.CollectionName(database.CollectionEvents):           database.EventQueryFactory,
+	database.CollectionName(database.CollectionTokenTransfers):   database.TokenTransferQueryFactory,
+	database.CollectionName(database.CollectionBlockchainEvents): database.BlockchainEventQueryFactory,
+}
+
+// validateChartRange checks the time range can be divided into the buckets, with every bucket at least one unit long
+func validateChartRange(ctx context.Context, startTime, endTime, buckets int64) error {
+	if buckets > core.ChartHistogramMaxBuckets || buckets < core.ChartHistogramMinBuckets {
+		return i18n.NewError(ctx, coremsgs.MsgInvalidNumberOfIntervals, core.ChartHistogramMinBuckets, core.ChartHistogramMaxBuckets)
+	}
+	if startTime >= endTime {
+		return i18n.NewError(ctx, coremsgs.MsgHistogramInvalidTimes)
+	}
+	if endTime-startTime < buckets {
+		return i18n.NewError(ctx, coremsgs.MsgChartRangeTooShort, buckets)
+	}
+	return nil
+}
+
 func (or *orchestrator) getHistogramIntervals(startTime int64, endTime int64, numBuckets int64) (intervals []core.ChartHistogramInterval) {
 	timeIntervalLength := (endTime - startTime) / numBuckets
 
@@ -40,11 +65,8 @@ func (or *orchestrator) getHistogramIntervals(startTime int64, endTime int64, nu
 }
 
 func (or *orchestrator) GetChartHistogram(ctx context.Context, startTime int64, endTime int64, buckets int64, collection database.CollectionName) ([]*core.ChartHistogram, error) {
-	if buckets > core.ChartHistogramMaxBuckets || buckets < core.ChartHistogramMinBuckets {
-		return nil, i18n.NewError(ctx, coremsgs.MsgInvalidNumberOfIntervals, core.ChartHistogramMinBuckets, core.ChartHistogramMaxBuckets)
-	}
-	if startTime > endTime {
-		return nil, i18n.NewError(ctx, coremsgs.MsgHistogramInvalidTimes)
+	if err := validateChartRange(ctx, startTime, endTime, buckets); err != nil {
+		return nil, err
 	}
 
 	intervals := or.getHistogramIntervals(startTime, endTime, buckets)
@@ -56,3 +78,55 @@ func (or *orchestrator) GetChartHistogram(ctx context.Context, startTime int64,
 
 	return histogram, nil
 }
+
+func (or *orchestrator) GetChartAggregates(ctx context.Context, collection database.CollectionName, input *core.ChartAggregateInput) ([]*core.ChartAggregate, error) {
+	if input.StartTime == nil || input.EndTime == nil {
+		return nil, i18n.NewError(ctx, coremsgs.MsgHistogramInvalidTimes)
+	}
+	if err := validateChartRange(ctx, input.StartTime.UnixNano(), input.EndTime.UnixNano(), input.Buckets); err != nil {
+		return nil, err
+	}
+	qf, ok := chartQueryFactories[collection]
+	if !ok {
+		return nil, i18n.NewError(ctx, coremsgs.MsgUnsupportedCollection, collection)
+	}
+
+	if len(input.GroupBy) > core.ChartAggregateMaxGroupBy {
+		return nil, i18n.NewError(ctx, coremsgs.MsgChartTooManyGroupBy, core.ChartAggregateMaxGroupBy)
+	}
+	for _, field := range input.GroupBy {
+		if _, ok := (*qf)[field]; !ok {
+			return nil, i18n.NewError(ctx, coremsgs.MsgChartInvalidField, field)
+		}
+	}
+
+	query := &database.ChartAggregateQuery{
+		GroupBy:   input.GroupBy,
+		Aggregate: input.Aggregate,
+	}
+	switch input.Aggregate {
+	case "":
+		query.Aggregate = core.ChartAggregateTypeCount
+	case core.ChartAggregateTypeCount:
+	case core.ChartAggregateTypeSum, core.ChartAggregateTypeAvg:
+		if _, ok := (*qf)[input.Field].(*ffapi.Int64Field); !ok {
+			return nil, i18n.NewError(ctx, coremsgs.MsgChartFieldNotNumeric, input.Field, input.Aggregate)
+		}
+		query.Field = input.Field
+	default:
+		return nil, i18n.NewError(ctx, coremsgs.MsgChartInvalidAggregate, input.Aggregate)
+	}
+
+	fb := qf.NewFilter(ctx)
+	query.Filter = fb.And()
+	if input.Filter != nil {
+		filter, err := input.Filter.BuildAndFilter(ctx, fb)
+		if err != nil {
+			return nil, err
+		}
+		query.Filter = filter
+	}
+
+	intervals := or.getHistogramIntervals(input.StartTime.UnixNano(), input.EndTime.UnixNano(), input.Buckets)
+	return or.database().GetChartAggregates(ctx, or.namespace.Name, intervals, collection, query)
+}
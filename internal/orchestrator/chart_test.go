@@ -20,7 +20,9 @@ import (
 	"context"
 	"fmt"
 	"testing"
+	"time"
 
+	"github.com/hyperledger/firefly-common/pkg/ffapi"
 	"github.com/hyperledger/firefly-common/pkg/fftypes"
 	"github.com/hyperledger/firefly/pkg/core"
 	"github.com/hyperledger/firefly/pkg/database"
@@ -56,6 +58,18 @@ func TestGetHistogramBadStartEndTimes(t *testing.T) {
 	assert.Regexp(t, "FF10300", err)
 }
 
+func TestGetHistogramEqualStartEndTimes(t *testing.T) {
+	or := newTestOrchestrator()
+	_, err := or.GetChartHistogram(context.Background(), 1234567890, 1234567890, 10, database.CollectionName("test"))
+	assert.Regexp(t, "FF10300", err)
+}
+
+func TestGetHistogramRangeTooShort(t *testing.T) {
+	or := newTestOrchestrator()
+	_, err := or.GetChartHistogram(context.Background(), 1234567890, 1234567899, 10, database.CollectionName("test"))
+	assert.Regexp(t, "FF10541", err)
+}
+
 func TestGetHistogramFailDB(t *testing.T) {
 	or := newTestOrchestrator()
 	intervals := makeTestIntervals(1000000000, 10)
@@ -73,3 +87,121 @@ func TestGetHistogramSuccess(t *testing.T) {
 	_, err := or.GetChartHistogram(context.Background(), 1000000000, 1000000010, 10, database.CollectionName("test"))
 	assert.NoError(t, err)
 }
+
+func newTestChartAggregateInput() *core.ChartAggregateInput {
+	return &core.ChartAggregateInput{
+		StartTime: fftypes.UnixTime(1000000000),
+		EndTime:   fftypes.UnixTime(1000000010),
+		Buckets:   10,
+	}
+}
+
+func TestGetChartAggregatesBadInput(t *testing.T) {
+	or := newTestOrchestrator()
+	ctx := context.Background()
+	transfers := database.CollectionName(database.CollectionTokenTransfers)
+
+	input := newTestChartAggregateInput()
+	input.Buckets = core.ChartHistogramMaxBuckets + 1
+	_, err := or.GetChartAggregates(ctx, transfers, input)
+	assert.Regexp(t, "FF10298", err)
+
+	input = newTestChartAggregateInput()
+	input.Buckets = 0
+	_, err = or.GetChartAggregates(ctx, transfers, input)
+	assert.Regexp(t, "FF10298", err)
+
+	input = newTestChartAggregateInput()
+	input.Buckets = -1
+	_, err = or.GetChartAggregates(ctx, transfers, input)
+	assert.Regexp(t, "FF10298", err)
+
+	input = newTestChartAggregateInput()
+	input.EndTime = nil
+	_, err = or.GetChartAggregates(ctx, transfers, input)
+	assert.Regexp(t, "FF10300", err)
+
+	input = newTestChartAggregateInput()
+	input.EndTime = input.StartTime
+	_, err = or.GetChartAggregates(ctx, transfers, input)
+	assert.Regexp(t, "FF10300", err)
+
+	input = newTestChartAggregateInput()
+	endTime := fftypes.FFTime(input.StartTime.Time().Add(9 * time.Nanosecond))
+	input.EndTime = &endTime
+	_, err = or.GetChartAggregates(ctx, transfers, input)
+	assert.Regexp(t, "FF10541", err)
+
+	input = newTestChartAggregateInput()
+	input.StartTime = fftypes.UnixTime(2000000000)
+	_, err = or.GetChartAggregates(ctx, transfers, input)
+	assert.Regexp(t, "FF10300", err)
+
+	_, err = or.GetChartAggregates(ctx, database.CollectionName("test"), newTestChartAggregateInput())
+	assert.Regexp(t, "FF10301", err)
+
+	input = newTestChartAggregateInput()
+	input.GroupBy = []string{"pool", "from", "to", "type"}
+	_, err = or.GetChartAggregates(ctx, transfers, input)
+	assert.Regexp(t, "FF10521", err)
+
+	input = newTestChartAggregateInput()
+	input.GroupBy = []string{"wrong"}
+	_, err = or.GetChartAggregates(ctx, transfers, input)
+	assert.Regexp(t, "FF10518", err)
+
+	input = newTestChartAggregateInput()
+	input.Aggregate = core.ChartAggregateTypeSum
+	input.Field = "from"
+	_, err = or.GetChartAggregates(ctx, transfers, input)
+	assert.Regexp(t, "FF10519", err)
+
+	input = newTestChartAggregateInput()
+	input.Aggregate = "max"
+	_, err = or.GetChartAggregates(ctx, transfers, input)
+	assert.Regexp(t, "FF10520", err)
+
+	input = newTestChartAggregateInput()
+	input.Filter = &ffapi.FilterJSON{FilterJSONOps: ffapi.FilterJSONOps{
+		Eq: []*ffapi.FilterJSONKeyValue{{FilterJSONBase: ffapi.FilterJSONBase{Field: "wrong"}}},
+	}}
+	_, err = or.GetChartAggregates(ctx, transfers, input)
+	assert.Regexp(t, "FF00142", err)
+}
+
+func TestGetChartAggregatesSum(t *testing.T) {
+	or := newTestOrchestrator()
+	intervals := makeTestIntervals(1000000000, 10)
+	input := newTestChartAggregateInput()
+	input.GroupBy = []string{"pool"}
+	input.Aggregate = core.ChartAggregateTypeSum
+	input.Field = "amount"
+	input.Filter = &ffapi.FilterJSON{FilterJSONOps: ffapi.FilterJSONOps{
+		Eq: []*ffapi.FilterJSONKeyValue{{FilterJSONBase: ffapi.FilterJSONBase{Field: "type"}, Value: "mint"}},
+	}}
+
+	or.mdi.On("GetChartAggregates", mock.Anything, "ns", intervals, database.CollectionName(database.CollectionTokenTransfers), mock.MatchedBy(func(query *database.ChartAggregateQuery) bool {
+		fi, _ := query.Filter.Finalize()
+		return fi.String() == "( type == 'mint' )" && query.Field == "amount" && query.GroupBy[0] == "pool"
+	})).Return([]*core.ChartAggregate{}, nil)
+	_, err := or.GetChartAggregates(context.Background(), database.CollectionName(database.CollectionTokenTransfers), input)
+	assert.NoError(t, err)
+}
+
+func TestGetChartAggregatesCount(t *testing.T) {
+	or := newTestOrchestrator()
+	intervals := makeTestIntervals(1000000000, 10)
+	input := newTestChartAggregateInput()
+	input.Aggregate = core.ChartAggregateTypeCount
+	input.Field = "ignored"
+
+	or.mdi.On("GetChartAggregates", mock.Anything, "ns", intervals, database.CollectionName(database.CollectionOperations), mock.MatchedBy(func(query *database.ChartAggregateQuery) bool {
+		return query.Aggregate == core.ChartAggregateTypeCount && query.Field == ""
+	})).Return(nil, fmt.Errorf("pop"))
+	_, err := or.GetChartAggregates(context.Background(), database.CollectionName(database.CollectionOperations), input)
+	assert.EqualError(t, err, "pop")
+
+	input.Aggregate = ""
+	_, err = or.GetChartAggregates(context.Background(), database.CollectionName(database.CollectionOperations), input)
+	assert.EqualError(t, err, "pop")
+}
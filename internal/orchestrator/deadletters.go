@@ -0,0 +1,71 @@
+// Copyright © 2024 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package orchestrator
+
+import (
+	"context"
+
+	"github.com/hyperledger/firefly-common/pkg/ffapi"
+	"github.com/hyperledger/firefly-common/pkg/fftypes"
+	"github.com/hyperledger/firefly-common/pkg/i18n"
+	"github.com/hyperledger/firefly/internal/coremsgs"
+	"github.com/hyperledger/firefly/pkg/core"
+)
+
+func (or *orchestrator) GetDeadLetters(ctx context.Context, subID string, filter ffapi.AndFilter) ([]*core.DeadLetter, *ffapi.FilterResult, error) {
+	u, err := fftypes.ParseUUID(ctx, subID)
+	if err != nil {
+		return nil, nil, err
+	}
+	filter = filter.Condition(filter.Builder().Eq("subscription", u))
+	return or.database().GetDeadLetters(ctx, or.namespace.Name, filter)
+}
+
+func (or *orchestrator) GetDeadLetterByID(ctx context.Context, subID, id string) (*core.DeadLetter, error) {
+	subUUID, err := fftypes.ParseUUID(ctx, subID)
+	if err != nil {
+		return nil, err
+	}
+	u, err := fftypes.ParseUUID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	deadLetter, err := or.database().GetDeadLetterByID(ctx, or.namespace.Name, u)
+	if err != nil {
+		return nil, err
+	}
+	if deadLetter == nil || !deadLetter.Subscription.ID.Equals(subUUID) {
+		return nil, i18n.NewError(ctx, coremsgs.Msg404NotFound)
+	}
+	return deadLetter, nil
+}
+
+func (or *orchestrator) ReplayDeadLetter(ctx context.Context, subID, id string) (*core.DeadLetter, error) {
+	deadLetter, err := or.GetDeadLetterByID(ctx, subID, id)
+	if err != nil {
+		return nil, err
+	}
+	return deadLetter, or.events.ReplayDeadLetter(ctx, deadLetter)
+}
+
+func (or *orchestrator) DeleteDeadLetter(ctx context.Context, subID, id string) error {
+	deadLetter, err := or.GetDeadLetterByID(ctx, subID, id)
+	if err != nil {
+		return err
+	}
+	return or.database().DeleteDeadLetter(ctx, or.namespace.Name, deadLetter.ID)
+}
@@ -0,0 +1,153 @@
+// Copyright © 2024 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package orchestrator
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/hyperledger/firefly-common/pkg/fftypes"
+	"github.com/hyperledger/firefly/pkg/core"
+	"github.com/hyperledger/firefly/pkg/database"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func newTestDeadLetter() *core.DeadLetter {
+	return &core.DeadLetter{
+		ID:        fftypes.NewUUID(),
+		Namespace: "ns",
+		Subscription: core.SubscriptionRef{
+			ID:        fftypes.NewUUID(),
+			Namespace: "ns",
+			Name:      "sub1",
+		},
+		Event: fftypes.NewUUID(),
+	}
+}
+
+func TestGetDeadLetters(t *testing.T) {
+	or := newTestOrchestrator()
+	defer or.cleanup(t)
+
+	subID := fftypes.NewUUID()
+	or.mdi.On("GetDeadLetters", mock.Anything, "ns", mock.Anything).Return([]*core.DeadLetter{}, nil, nil)
+	fb := database.DeadLetterQueryFactory.NewFilter(context.Background())
+	f := fb.And(fb.Eq("eventtype", core.EventTypeMessageConfirmed))
+	_, _, err := or.GetDeadLetters(context.Background(), subID.String(), f)
+	assert.NoError(t, err)
+	fi, err := f.Finalize()
+	assert.NoError(t, err)
+	assert.Contains(t, fi.String(), subID.String())
+}
+
+func TestGetDeadLettersBadUUID(t *testing.T) {
+	or := newTestOrchestrator()
+	defer or.cleanup(t)
+
+	fb := database.DeadLetterQueryFactory.NewFilter(context.Background())
+	_, _, err := or.GetDeadLetters(context.Background(), "! a UUID", fb.And())
+	assert.Regexp(t, "FF00138", err)
+}
+
+func TestGetDeadLetterByID(t *testing.T) {
+	or := newTestOrchestrator()
+	defer or.cleanup(t)
+
+	dl := newTestDeadLetter()
+	or.mdi.On("GetDeadLetterByID", mock.Anything, "ns", dl.ID).Return(dl, nil)
+	res, err := or.GetDeadLetterByID(context.Background(), dl.Subscription.ID.String(), dl.ID.String())
+	assert.NoError(t, err)
+	assert.Equal(t, dl, res)
+}
+
+func TestGetDeadLetterByIDBadSubUUID(t *testing.T) {
+	or := newTestOrchestrator()
+	defer or.cleanup(t)
+
+	_, err := or.GetDeadLetterByID(context.Background(), "! a UUID", fftypes.NewUUID().String())
+	assert.Regexp(t, "FF00138", err)
+}
+
+func TestGetDeadLetterByIDBadUUID(t *testing.T) {
+	or := newTestOrchestrator()
+	defer or.cleanup(t)
+
+	_, err := or.GetDeadLetterByID(context.Background(), fftypes.NewUUID().String(), "! a UUID")
+	assert.Regexp(t, "FF00138", err)
+}
+
+func TestGetDeadLetterByIDLookupError(t *testing.T) {
+	or := newTestOrchestrator()
+	defer or.cleanup(t)
+
+	or.mdi.On("GetDeadLetterByID", mock.Anything, "ns", mock.Anything).Return(nil, fmt.Errorf("pop"))
+	_, err := or.GetDeadLetterByID(context.Background(), fftypes.NewUUID().String(), fftypes.NewUUID().String())
+	assert.EqualError(t, err, "pop")
+}
+
+func TestGetDeadLetterByIDWrongSubscription(t *testing.T) {
+	or := newTestOrchestrator()
+	defer or.cleanup(t)
+
+	dl := newTestDeadLetter()
+	or.mdi.On("GetDeadLetterByID", mock.Anything, "ns", dl.ID).Return(dl, nil)
+	_, err := or.GetDeadLetterByID(context.Background(), fftypes.NewUUID().String(), dl.ID.String())
+	assert.Regexp(t, "FF10109", err)
+}
+
+func TestReplayDeadLetter(t *testing.T) {
+	or := newTestOrchestrator()
+	defer or.cleanup(t)
+
+	dl := newTestDeadLetter()
+	or.mdi.On("GetDeadLetterByID", mock.Anything, "ns", dl.ID).Return(dl, nil)
+	or.mem.On("ReplayDeadLetter", mock.Anything, dl).Return(nil)
+	res, err := or.ReplayDeadLetter(context.Background(), dl.Subscription.ID.String(), dl.ID.String())
+	assert.NoError(t, err)
+	assert.Equal(t, dl, res)
+}
+
+func TestReplayDeadLetterNotFound(t *testing.T) {
+	or := newTestOrchestrator()
+	defer or.cleanup(t)
+
+	or.mdi.On("GetDeadLetterByID", mock.Anything, "ns", mock.Anything).Return(nil, nil)
+	_, err := or.ReplayDeadLetter(context.Background(), fftypes.NewUUID().String(), fftypes.NewUUID().String())
+	assert.Regexp(t, "FF10109", err)
+}
+
+func TestDeleteDeadLetter(t *testing.T) {
+	or := newTestOrchestrator()
+	defer or.cleanup(t)
+
+	dl := newTestDeadLetter()
+	or.mdi.On("GetDeadLetterByID", mock.Anything, "ns", dl.ID).Return(dl, nil)
+	or.mdi.On("DeleteDeadLetter", mock.Anything, "ns", dl.ID).Return(nil)
+	err := or.DeleteDeadLetter(context.Background(), dl.Subscription.ID.String(), dl.ID.String())
+	assert.NoError(t, err)
+}
+
+func TestDeleteDeadLetterNotFound(t *testing.T) {
+	or := newTestOrchestrator()
+	defer or.cleanup(t)
+
+	or.mdi.On("GetDeadLetterByID", mock.Anything, "ns", mock.Anything).Return(nil, nil)
+	err := or.DeleteDeadLetter(context.Background(), fftypes.NewUUID().String(), fftypes.NewUUID().String())
+	assert.Regexp(t, "FF10109", err)
+}
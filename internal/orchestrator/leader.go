@@ -0,0 +1,45 @@
+// Copyright © 2024 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package orchestrator
+
+import (
+	"context"
+
+	"github.com/hyperledger/firefly-common/pkg/log"
+	"github.com/hyperledger/firefly/pkg/core"
+)
+
+func (or *orchestrator) LeaderElected() {
+	log.L(or.ctx).Infof("Starting background workers as the leader of namespace '%s'", or.namespace.Name)
+	or.startSingletons()
+}
+
+func (or *orchestrator) LeaderDeposed() {
+	// The background workers cannot be stopped on their own, so restart the whole namespace as a follower.
+	// This must happen asynchronously, as stopping the namespace waits for the elector that is calling us.
+	log.L(or.ctx).Errorf("Restarting namespace '%s' after losing leadership", or.namespace.Name)
+	// Stop delivering webhook and Kafka subscriptions straight away, as the new leader will start delivering them
+	or.events.StopConnectionlessDispatchers()
+	go or.restart()
+}
+
+func (or *orchestrator) GetLeaderStatus(ctx context.Context) *core.LeaderStatus {
+	if or.leader == nil {
+		return &core.LeaderStatus{Enabled: false}
+	}
+	return or.leader.Status()
+}
@@ -0,0 +1,107 @@
+// Copyright © 2024 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package orchestrator
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hyperledger/firefly-common/pkg/config"
+	"github.com/hyperledger/firefly/internal/coreconfig"
+	"github.com/hyperledger/firefly/mocks/leadermocks"
+	"github.com/hyperledger/firefly/pkg/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestInitLeaderElector(t *testing.T) {
+	or := newTestOrchestrator()
+	defer or.cleanup(t)
+	config.Set(coreconfig.LeaderElectionEnabled, true)
+	or.config.Multiparty.Enabled = false
+	err := or.initManagers(context.Background())
+	assert.NoError(t, err)
+	assert.NotNil(t, or.leader)
+}
+
+func TestStartStopWithLeaderElection(t *testing.T) {
+	or := newTestOrchestrator()
+	defer or.cleanup(t)
+	mle := &leadermocks.Elector{}
+	or.leader = mle
+	or.mdm.On("Start").Return(nil)
+	or.mba.On("Start").Return(nil)
+	or.mbm.On("Start").Return(nil)
+	or.msd.On("Start").Return(nil)
+	or.mem.On("Start").Return(nil)
+	or.mtw.On("Start").Return()
+	or.mam.On("Start").Return(nil)
+	mle.On("Start").Return()
+	mle.On("WaitStop").Return()
+	or.mba.On("WaitStop").Return(nil)
+	or.mbm.On("WaitStop").Return(nil)
+	or.mdm.On("WaitStop").Return(nil)
+	or.msd.On("WaitStop").Return(nil)
+	or.mom.On("WaitStop").Return(nil)
+	or.mem.On("WaitStop").Return(nil)
+	or.mtw.On("Close").Return(nil)
+	or.mrm.On("WaitStop").Return()
+	or.mcm.On("WaitStop").Return()
+	or.mbi.On("StopNamespace", mock.Anything, "ns").Return(nil)
+	or.mti.On("StopNamespace", mock.Anything, "ns").Return(nil)
+	err := or.Start()
+	assert.NoError(t, err)
+	or.WaitStop()
+	assert.Nil(t, or.leader)
+	mle.AssertExpectations(t)
+}
+
+func TestLeaderElected(t *testing.T) {
+	or := newTestOrchestrator()
+	defer or.cleanup(t)
+	or.mba.On("StartSequencer").Return()
+	or.msd.On("StartRecovery").Return()
+	or.mem.On("StartAggregator").Return()
+	or.mem.On("StartConnectionlessDispatchers").Return()
+	or.mom.On("Start").Return(nil)
+	or.mrm.On("Start").Return()
+	or.mcm.On("Start").Return()
+	or.LeaderElected()
+}
+
+func TestLeaderDeposed(t *testing.T) {
+	or := newTestOrchestrator()
+	defer or.cleanup(t)
+	restarted := make(chan struct{})
+	or.restart = func() { close(restarted) }
+	or.mem.On("StopConnectionlessDispatchers").Return()
+	or.LeaderDeposed()
+	<-restarted
+}
+
+func TestGetLeaderStatus(t *testing.T) {
+	or := newTestOrchestrator()
+	defer or.cleanup(t)
+	assert.Equal(t, &core.LeaderStatus{Enabled: false}, or.GetLeaderStatus(or.ctx))
+
+	mle := &leadermocks.Elector{}
+	or.leader = mle
+	status := &core.LeaderStatus{Enabled: true, Replica: "replica1", Leader: true, Holder: "replica1"}
+	mle.On("Status").Return(status)
+	assert.Equal(t, status, or.GetLeaderStatus(or.ctx))
+	mle.AssertExpectations(t)
+}
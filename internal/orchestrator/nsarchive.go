@@ -0,0 +1,42 @@
+// Copyright © 2024 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package orchestrator
+
+import (
+	"context"
+	"io"
+
+	"github.com/hyperledger/firefly-common/pkg/log"
+	"github.com/hyperledger/firefly/pkg/core"
+)
+
+// ExportNamespace streams the archive as it is written. The archive has no trailer if the export fails part way through.
+func (or *orchestrator) ExportNamespace(ctx context.Context) io.ReadCloser {
+	r, w := io.Pipe()
+	go func() {
+		err := or.archive.Export(ctx, w)
+		if err != nil {
+			log.L(ctx).Errorf("Export of namespace '%s' failed: %s", or.namespace.Name, err)
+		}
+		_ = w.CloseWithError(err)
+	}()
+	return r
+}
+
+func (or *orchestrator) ImportNamespace(ctx context.Context, r io.Reader, dryRun bool) (*core.NamespaceImportResult, error) {
+	return or.archive.Import(ctx, r, dryRun)
+}
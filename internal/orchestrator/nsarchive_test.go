@@ -0,0 +1,66 @@
+// Copyright © 2024 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package orchestrator
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/hyperledger/firefly/pkg/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestExportNamespace(t *testing.T) {
+	or := newTestOrchestrator()
+	defer or.cleanup(t)
+
+	or.mna.On("Export", or.ctx, mock.Anything).Return(nil).Run(func(args mock.Arguments) {
+		_, _ = args[1].(io.Writer).Write([]byte("archive"))
+	})
+
+	r := or.ExportNamespace(or.ctx)
+	b, err := io.ReadAll(r)
+	assert.NoError(t, err)
+	assert.Equal(t, "archive", string(b))
+}
+
+func TestExportNamespaceFail(t *testing.T) {
+	or := newTestOrchestrator()
+	defer or.cleanup(t)
+
+	or.mna.On("Export", or.ctx, mock.Anything).Return(fmt.Errorf("pop"))
+
+	r := or.ExportNamespace(or.ctx)
+	_, err := io.ReadAll(r)
+	assert.EqualError(t, err, "pop")
+}
+
+func TestImportNamespace(t *testing.T) {
+	or := newTestOrchestrator()
+	defer or.cleanup(t)
+
+	archive := strings.NewReader("archive")
+	result := &core.NamespaceImportResult{DryRun: true}
+	or.mna.On("Import", or.ctx, archive, true).Return(result, nil)
+
+	res, err := or.ImportNamespace(or.ctx, archive, true)
+	assert.NoError(t, err)
+	assert.Equal(t, result, res)
+}
@@ -18,9 +18,12 @@ package orchestrator
 
 import (
 	"context"
+	"io"
 	"sync"
+	"time"
 
 	"github.com/hyperledger/firefly-common/pkg/auth"
+	"github.com/hyperledger/firefly-common/pkg/config"
 	"github.com/hyperledger/firefly-common/pkg/ffapi"
 	"github.com/hyperledger/firefly-common/pkg/fftypes"
 	"github.com/hyperledger/firefly-common/pkg/i18n"
@@ -30,16 +33,20 @@ import (
 	"github.com/hyperledger/firefly/internal/broadcast"
 	"github.com/hyperledger/firefly/internal/cache"
 	"github.com/hyperledger/firefly/internal/contracts"
+	"github.com/hyperledger/firefly/internal/coreconfig"
 	"github.com/hyperledger/firefly/internal/coremsgs"
 	"github.com/hyperledger/firefly/internal/data"
 	"github.com/hyperledger/firefly/internal/definitions"
 	"github.com/hyperledger/firefly/internal/events"
 	"github.com/hyperledger/firefly/internal/identity"
+	"github.com/hyperledger/firefly/internal/leader"
 	"github.com/hyperledger/firefly/internal/metrics"
 	"github.com/hyperledger/firefly/internal/multiparty"
 	"github.com/hyperledger/firefly/internal/networkmap"
+	"github.com/hyperledger/firefly/internal/nsarchive"
 	"github.com/hyperledger/firefly/internal/operations"
 	"github.com/hyperledger/firefly/internal/privatemessaging"
+	"github.com/hyperledger/firefly/internal/retention"
 	"github.com/hyperledger/firefly/internal/shareddownload"
 	"github.com/hyperledger/firefly/internal/syncasync"
 	"github.com/hyperledger/firefly/internal/txcommon"
@@ -56,7 +63,7 @@ import (
 
 // Orchestrator is the main interface behind the API, implementing the actions
 type Orchestrator interface {
-	PreInit(ctx context.Context, cancelCtx context.CancelFunc)
+	PreInit(ctx context.Context, restart func()) // restart is called if the namespace must be restarted, for example on losing leadership
 	Init() error
 	Start() error
 	WaitStop() // The close itself is performed by canceling the context
@@ -77,6 +84,7 @@ type Orchestrator interface {
 	// Status
 	GetStatus(ctx context.Context) (*core.NamespaceStatus, error)
 	GetMultipartyStatus(ctx context.Context) (*core.NamespaceMultipartyStatus, error)
+	GetLeaderStatus(ctx context.Context) *core.LeaderStatus
 
 	// Subscription management
 	GetSubscriptions(ctx context.Context, filter ffapi.AndFilter) ([]*core.Subscription, *ffapi.FilterResult, error)
@@ -86,6 +94,10 @@ type Orchestrator interface {
 	CreateSubscription(ctx context.Context, subDef *core.Subscription) (*core.Subscription, error)
 	CreateUpdateSubscription(ctx context.Context, subDef *core.Subscription) (*core.Subscription, error)
 	DeleteSubscription(ctx context.Context, id string) error
+	GetDeadLetters(ctx context.Context, subID string, filter ffapi.AndFilter) ([]*core.DeadLetter, *ffapi.FilterResult, error)
+	GetDeadLetterByID(ctx context.Context, subID, id string) (*core.DeadLetter, error)
+	ReplayDeadLetter(ctx context.Context, subID, id string) (*core.DeadLetter, error)
+	DeleteDeadLetter(ctx context.Context, subID, id string) error
 
 	// Data Query
 	GetNamespace(ctx context.Context) *core.Namespace
@@ -123,8 +135,16 @@ type Orchestrator interface {
 	GetNextPins(ctx context.Context, filter ffapi.AndFilter) ([]*core.NextPin, *ffapi.FilterResult, error)
 	RewindPins(ctx context.Context, rewind *core.PinRewind) (*core.PinRewind, error)
 
+	// Data retention
+	PreviewRetention(ctx context.Context, policy *core.RetentionPolicy) ([]*core.RetentionPreview, error)
+
+	// Namespace export/import
+	ExportNamespace(ctx context.Context) io.ReadCloser
+	ImportNamespace(ctx context.Context, r io.Reader, dryRun bool) (*core.NamespaceImportResult, error)
+
 	// Charts
 	GetChartHistogram(ctx context.Context, startTime int64, endTime int64, buckets int64, tableName database.CollectionName) ([]*core.ChartHistogram, error)
+	GetChartAggregates(ctx context.Context, collection database.CollectionName, input *core.ChartAggregateInput) ([]*core.ChartAggregate, error)
 
 	// Message Routing
 	RequestReply(ctx context.Context, msg *core.MessageInOut) (reply *core.MessageInOut, err error)
@@ -134,6 +154,15 @@ type Orchestrator interface {
 
 	// Authorizer
 	Authorize(ctx context.Context, authReq *fftypes.AuthReq) error
+
+	// Rate limiting - the release function must be called when the request completes
+	AcquireRequestQuota(ctx context.Context, identity string) (release func(), retryAfter time.Duration, err error)
+
+	// API keys
+	CreateAPIKey(ctx context.Context, apiKey *core.APIKey) (*core.APIKey, error)
+	GetAPIKeys(ctx context.Context, filter ffapi.AndFilter) ([]*core.APIKey, *ffapi.FilterResult, error)
+	GetAPIKeyByID(ctx context.Context, id string) (*core.APIKey, error)
+	DeleteAPIKey(ctx context.Context, id string) error
 }
 
 type BlockchainPlugin struct {
@@ -188,11 +217,14 @@ type Config struct {
 	Multiparty                  multiparty.Config
 	TokenBroadcastNames         map[string]string
 	MaxHistoricalEventScanLimit int
+	RateLimits                  RateLimits
+	Retention                   core.RetentionPolicy
 }
 
 type orchestrator struct {
 	ctx                     context.Context
 	cancelCtx               context.CancelFunc
+	restart                 func()
 	started                 bool
 	startedBlockchainPlugin bool
 	startedLock             sync.Mutex
@@ -219,6 +251,10 @@ type orchestrator struct {
 	operations              operations.Manager
 	txHelper                txcommon.Helper
 	txWriter                txwriter.Writer
+	retention               retention.Manager
+	archive                 nsarchive.Manager
+	leader                  leader.Elector
+	rateLimiter             *rateLimiter
 }
 
 func NewOrchestrator(ns *core.Namespace, config Config, plugins *Plugins, metrics metrics.Manager, cacheManager cache.Manager) Orchestrator {
@@ -228,12 +264,14 @@ func NewOrchestrator(ns *core.Namespace, config Config, plugins *Plugins, metric
 		plugins:      plugins,
 		metrics:      metrics,
 		cacheManager: cacheManager,
+		rateLimiter:  newRateLimiter(ns.Name, config.RateLimits),
 	}
 	or.bc.o = or
 	return or
 }
 
-func (or *orchestrator) PreInit(ctx context.Context, cancelCtx context.CancelFunc) {
+func (or *orchestrator) PreInit(ctx context.Context, restart func()) {
+	or.restart = restart
 	namespaceLog := or.namespace.Name
 	if or.namespace.NetworkName != "" && or.namespace.NetworkName != or.namespace.Name {
 		namespaceLog += "->" + or.namespace.NetworkName
@@ -293,24 +331,48 @@ func (or *orchestrator) Start() (err error) {
 	if err == nil {
 		err = or.events.Start()
 	}
-	if err == nil {
-		err = or.operations.Start()
-	}
 	if err == nil {
 		or.txWriter.Start()
 	}
 	if err == nil {
 		err = or.assets.Start()
 	}
+	if err == nil {
+		if or.leader != nil {
+			// The singleton workers are started if we are elected leader
+			or.leader.Start()
+		} else {
+			or.startSingletons()
+		}
+	}
 
 	or.started = true
 	return err
 }
 
+// startSingletons starts the background workers that assume they are the only instance processing the namespace.
+// When leader election is enabled, these only run on the leader replica.
+func (or *orchestrator) startSingletons() {
+	if or.config.Multiparty.Enabled {
+		or.batch.StartSequencer()
+		or.sharedDownload.StartRecovery()
+	}
+	or.events.StartAggregator()
+	or.events.StartConnectionlessDispatchers()
+	_ = or.operations.Start() // cannot fail
+	or.retention.Start()
+	or.contracts.Start()
+}
+
 func (or *orchestrator) WaitStop() {
 	if !or.started {
 		return
 	}
+	if or.leader != nil {
+		// Must stop first, so the singleton workers cannot be started while we are stopping
+		or.leader.WaitStop()
+		or.leader = nil
+	}
 	err := or.plugins.Blockchain.Plugin.StopNamespace(or.ctx, or.namespace.Name)
 	if err != nil {
 		log.L(or.ctx).Errorf("Error purging namespace '%s' from blockchain plugin '%s': %s", or.namespace.Name, or.plugins.Blockchain.Name, err.Error())
@@ -348,6 +410,14 @@ func (or *orchestrator) WaitStop() {
 	if or.txWriter != nil {
 		or.txWriter.Close()
 	}
+	if or.retention != nil {
+		or.retention.WaitStop()
+		or.retention = nil
+	}
+	if or.contracts != nil {
+		or.contracts.WaitStop()
+		or.contracts = nil
+	}
 	or.startedLock.Lock()
 	defer or.startedLock.Unlock()
 	or.started = false
@@ -440,6 +510,14 @@ func setHandlers(ctx context.Context,
 		token.Plugin.SetOperationHandler(namespace.Name, bc)
 	}
 
+	if apiKeyAuth, ok := plugins.Auth.Plugin.(core.APIKeyAuthorizer); ok {
+		// API keys are looked up in the database of the namespace, until it is purged
+		var store core.APIKeyStore
+		if dbc != nil {
+			store = plugins.Database.Plugin
+		}
+		apiKeyAuth.SetAPIKeyStore(namespace.Name, store)
+	}
 }
 
 func (or *orchestrator) initMultiParty(ctx context.Context) error {
@@ -493,6 +571,18 @@ func (or *orchestrator) initManagers(ctx context.Context) (err error) {
 		or.txWriter = txwriter.NewTransactionWriter(ctx, or.namespace.Name, or.database(), or.txHelper, or.operations)
 	}
 
+	if or.retention == nil {
+		or.retention = retention.NewRetentionManager(ctx, or.namespace.Name, &or.config.Retention, or.database(), or.data)
+	}
+
+	if or.archive == nil {
+		or.archive = nsarchive.NewArchiveManager(or.namespace, or.database(), or.plugins.Events)
+	}
+
+	if or.leader == nil && config.GetBool(coreconfig.LeaderElectionEnabled) {
+		or.leader = leader.NewElector(ctx, or.namespace.Name, or.database(), or)
+	}
+
 	if or.config.Multiparty.Enabled {
 		if or.multiparty == nil {
 			or.multiparty, err = multiparty.NewMultipartyManager(or.ctx, or.namespace, or.config.Multiparty, or.database(), or.blockchain(), or.operations, or.metrics, or.txHelper)
@@ -512,7 +602,9 @@ func (or *orchestrator) initManagers(ctx context.Context) (err error) {
 		}
 	}
 
-	or.syncasync = syncasync.NewSyncAsyncBridge(ctx, or.namespace.Name, or.database(), or.data, or.operations)
+	if or.syncasync, err = syncasync.NewSyncAsyncBridge(ctx, or.namespace.Name, or.database(), or.data, or.operations); err != nil {
+		return err
+	}
 
 	if or.config.Multiparty.Enabled {
 		if err = or.initMultiPartyComponents(ctx); err != nil {
@@ -537,7 +629,7 @@ func (or *orchestrator) initManagers(ctx context.Context) (err error) {
 
 	if or.blockchain() != nil {
 		if or.contracts == nil {
-			or.contracts, err = contracts.NewContractManager(ctx, or.namespace.Name, or.database(), or.blockchain(), or.data, or.broadcast, or.messaging, or.batch, or.identity, or.operations, or.txHelper, or.txWriter, or.syncasync, or.cacheManager)
+			or.contracts, err = contracts.NewContractManager(ctx, or.namespace.Name, or.database(), or.blockchain(), or.data, or.broadcast, or.messaging, or.batch, or.identity, or.operations, or.txHelper, or.txWriter, or.syncasync, or.metrics, or.cacheManager)
 			if err != nil {
 				return err
 			}
@@ -599,9 +691,7 @@ func (or *orchestrator) initComponents(ctx context.Context) (err error) {
 		}
 	}
 
-	or.syncasync.Init(or.events)
-
-	return nil
+	return or.syncasync.Init(or.events)
 }
 
 func (or *orchestrator) SubmitNetworkAction(ctx context.Context, action *core.NetworkAction) error {
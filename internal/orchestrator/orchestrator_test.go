@@ -24,6 +24,7 @@ import (
 	"time"
 
 	"github.com/hyperledger/firefly-common/mocks/authmocks"
+	"github.com/hyperledger/firefly-common/pkg/config"
 	"github.com/hyperledger/firefly-common/pkg/fftypes"
 	"github.com/hyperledger/firefly/internal/cache"
 	"github.com/hyperledger/firefly/internal/coreconfig"
@@ -44,8 +45,10 @@ import (
 	"github.com/hyperledger/firefly/mocks/metricsmocks"
 	"github.com/hyperledger/firefly/mocks/multipartymocks"
 	"github.com/hyperledger/firefly/mocks/networkmapmocks"
+	"github.com/hyperledger/firefly/mocks/nsarchivemocks"
 	"github.com/hyperledger/firefly/mocks/operationmocks"
 	"github.com/hyperledger/firefly/mocks/privatemessagingmocks"
+	"github.com/hyperledger/firefly/mocks/retentionmocks"
 	"github.com/hyperledger/firefly/mocks/shareddownloadmocks"
 	"github.com/hyperledger/firefly/mocks/sharedstoragemocks"
 	"github.com/hyperledger/firefly/mocks/spieventsmocks"
@@ -89,6 +92,8 @@ type testOrchestrator struct {
 	mmp *multipartymocks.Manager
 	mds *definitionsmocks.Sender
 	mtw *txwritermocks.Writer
+	mrm *retentionmocks.Manager
+	mna *nsarchivemocks.Manager
 }
 
 func (tor *testOrchestrator) cleanup(t *testing.T) {
@@ -115,6 +120,8 @@ func (tor *testOrchestrator) cleanup(t *testing.T) {
 	tor.mae.AssertExpectations(t)
 	tor.mdh.AssertExpectations(t)
 	tor.mmp.AssertExpectations(t)
+	tor.mrm.AssertExpectations(t)
+	tor.mna.AssertExpectations(t)
 }
 
 func newTestOrchestrator() *testOrchestrator {
@@ -122,9 +129,10 @@ func newTestOrchestrator() *testOrchestrator {
 	ctx, cancel := context.WithCancel(context.Background())
 	tor := &testOrchestrator{
 		orchestrator: orchestrator{
-			ctx:       ctx,
-			cancelCtx: cancel,
-			namespace: &core.Namespace{Name: "ns", NetworkName: "ns"},
+			ctx:         ctx,
+			cancelCtx:   cancel,
+			namespace:   &core.Namespace{Name: "ns", NetworkName: "ns"},
+			rateLimiter: newRateLimiter("ns", RateLimits{}),
 		},
 		mdi: &databasemocks.Plugin{},
 		mdm: &datamocks.Manager{},
@@ -151,6 +159,8 @@ func newTestOrchestrator() *testOrchestrator {
 		mmp: &multipartymocks.Manager{},
 		mds: &definitionsmocks.Sender{},
 		mtw: &txwritermocks.Writer{},
+		mrm: &retentionmocks.Manager{},
+		mna: &nsarchivemocks.Manager{},
 	}
 	tor.orchestrator.multiparty = tor.mmp
 	tor.orchestrator.data = tor.mdm
@@ -168,6 +178,8 @@ func newTestOrchestrator() *testOrchestrator {
 	tor.orchestrator.sharedDownload = tor.msd
 	tor.orchestrator.txHelper = tor.mth
 	tor.orchestrator.txWriter = tor.mtw
+	tor.orchestrator.retention = tor.mrm
+	tor.orchestrator.archive = tor.mna
 	tor.orchestrator.defhandler = tor.mdh
 	tor.orchestrator.defsender = tor.mds
 	tor.orchestrator.config.Multiparty.Enabled = true
@@ -420,6 +432,16 @@ func TestInitIdentityComponentFail(t *testing.T) {
 	assert.Regexp(t, "FF10128", err)
 }
 
+func TestInitSyncAsyncComponentFail(t *testing.T) {
+	or := newTestOrchestrator()
+	defer or.cleanup(t)
+	config.Set(coreconfig.SyncAsyncCoordinatorType, "wrong")
+	or.mbi.On("StartNamespace", mock.Anything, "ns").Return(nil)
+	or.mmp.On("ConfigureContract", mock.Anything, mock.Anything).Return(nil)
+	err := or.initComponents(context.Background())
+	assert.Regexp(t, "FF10478", err)
+}
+
 func TestInitAssetsComponentFail(t *testing.T) {
 	or := newTestOrchestrator()
 	defer or.cleanup(t)
@@ -473,6 +495,26 @@ func TestInitTXWriter(t *testing.T) {
 	assert.NoError(t, err)
 }
 
+func TestInitRetention(t *testing.T) {
+	or := newTestOrchestrator()
+	defer or.cleanup(t)
+	or.retention = nil
+	or.config.Multiparty.Enabled = false
+	err := or.initManagers(context.Background())
+	assert.NoError(t, err)
+	assert.NotNil(t, or.retention)
+}
+
+func TestInitNamespaceArchive(t *testing.T) {
+	or := newTestOrchestrator()
+	defer or.cleanup(t)
+	or.archive = nil
+	or.config.Multiparty.Enabled = false
+	err := or.initManagers(context.Background())
+	assert.NoError(t, err)
+	assert.NotNil(t, or.archive)
+}
+
 func TestStartStopOk(t *testing.T) {
 	coreconfig.Reset()
 	or := newTestOrchestrator()
@@ -485,6 +527,12 @@ func TestStartStopOk(t *testing.T) {
 	or.mom.On("Start").Return(nil)
 	or.mtw.On("Start").Return()
 	or.mam.On("Start").Return(nil)
+	or.mrm.On("Start").Return()
+	or.mcm.On("Start").Return()
+	or.mba.On("StartSequencer").Return()
+	or.msd.On("StartRecovery").Return()
+	or.mem.On("StartAggregator").Return()
+	or.mem.On("StartConnectionlessDispatchers").Return()
 	or.mba.On("WaitStop").Return(nil)
 	or.mbm.On("WaitStop").Return(nil)
 	or.mdm.On("WaitStop").Return(nil)
@@ -492,6 +540,8 @@ func TestStartStopOk(t *testing.T) {
 	or.mom.On("WaitStop").Return(nil)
 	or.mem.On("WaitStop").Return(nil)
 	or.mtw.On("Close").Return(nil)
+	or.mrm.On("WaitStop").Return()
+	or.mcm.On("WaitStop").Return()
 	or.mbi.On("StopNamespace", mock.Anything, "ns").Return(nil)
 	or.mti.On("StopNamespace", mock.Anything, "ns").Return(nil)
 	err := or.Start()
@@ -508,6 +558,12 @@ func TestStartStopOk(t *testing.T) {
 	or.mom.On("Start").Return(nil)
 	or.mtw.On("Start").Return()
 	or.mam.On("Start").Return(nil)
+	or.mrm.On("Start").Return()
+	or.mcm.On("Start").Return()
+	or.mba.On("StartSequencer").Return()
+	or.msd.On("StartRecovery").Return()
+	or.mem.On("StartAggregator").Return()
+	or.mem.On("StartConnectionlessDispatchers").Return()
 	or.mba.On("WaitStop").Return(nil)
 	or.mbm.On("WaitStop").Return(nil)
 	or.mdm.On("WaitStop").Return(nil)
@@ -515,6 +571,8 @@ func TestStartStopOk(t *testing.T) {
 	or.mom.On("WaitStop").Return(nil)
 	or.mem.On("WaitStop").Return(nil)
 	or.mtw.On("Close").Return(nil)
+	or.mrm.On("WaitStop").Return()
+	or.mcm.On("WaitStop").Return()
 	or.mbi.On("StopNamespace", mock.Anything, "ns").Return(fmt.Errorf("pop"))
 	or.mti.On("StopNamespace", mock.Anything, "ns").Return(fmt.Errorf("pop"))
 	err = or.Start()
@@ -573,6 +631,37 @@ func TestPurgeTokenError(t *testing.T) {
 	Purge(context.Background(), or.namespace, or.plugins, "Test1")
 }
 
+type testAPIKeyAuth struct {
+	authmocks.Plugin
+	stores map[string]core.APIKeyStore
+}
+
+func (a *testAPIKeyAuth) SetAPIKeyStore(namespace string, store core.APIKeyStore) {
+	a.stores[namespace] = store
+}
+
+func TestSetHandlersAPIKeyStore(t *testing.T) {
+	coreconfig.Reset()
+	or := newTestOrchestrator()
+	defer or.cleanup(t)
+	auth := &testAPIKeyAuth{stores: map[string]core.APIKeyStore{}}
+	or.plugins.Auth.Plugin = auth
+	or.mdi.On("SetHandler", mock.Anything, mock.Anything).Return(nil)
+	or.mbi.On("SetHandler", mock.Anything, mock.Anything).Return(nil)
+	or.mbi.On("SetOperationHandler", mock.Anything, mock.Anything).Return(nil)
+	or.mps.On("SetHandler", mock.Anything, mock.Anything).Return(nil)
+	or.mdx.On("SetHandler", mock.Anything, "Test1", mock.Anything).Return(nil)
+	or.mdx.On("SetOperationHandler", mock.Anything, mock.Anything).Return(nil)
+	or.mti.On("SetHandler", mock.Anything, mock.Anything).Return(nil)
+	or.mti.On("SetOperationHandler", mock.Anything, mock.Anything).Return(nil)
+
+	setHandlers(context.Background(), or.plugins, or.namespace, "Test1", or, &or.bc)
+	assert.Equal(t, or.mdi, auth.stores["ns"])
+
+	Purge(context.Background(), or.namespace, or.plugins, "Test1")
+	assert.Nil(t, auth.stores["ns"])
+}
+
 func TestNetworkAction(t *testing.T) {
 	or := newTestOrchestrator()
 	or.namespace.Name = core.LegacySystemNamespace
@@ -0,0 +1,195 @@
+// Copyright © 2024 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package orchestrator
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/hyperledger/firefly-common/pkg/i18n"
+	"github.com/hyperledger/firefly-common/pkg/log"
+	"github.com/hyperledger/firefly/internal/coremsgs"
+	"golang.org/x/time/rate"
+)
+
+const (
+	rateLimitScopeNamespace  = "namespace"
+	rateLimitScopeIdentity   = "identity"
+	rateLimitReasonRate      = "rate"
+	rateLimitReasonInflight  = "concurrency"
+	concurrencyRetryAfter    = 1 * time.Second
+	identityQuotaIdleTimeout = 5 * time.Minute
+)
+
+// RateLimit is a token bucket rate limit, combined with a cap on the number of requests in flight.
+// Zero values are unlimited.
+type RateLimit struct {
+	RequestsPerSecond float64
+	Burst             int
+	MaxConcurrent     int
+}
+
+// RateLimits are the limits applied to API requests for a namespace, both in total and for each calling identity
+type RateLimits struct {
+	Namespace RateLimit
+	Identity  RateLimit
+}
+
+func (rl *RateLimit) enabled() bool {
+	return rl.RequestsPerSecond > 0 || rl.MaxConcurrent > 0
+}
+
+// quota tracks the usage of a single rate limit
+type quota struct {
+	limit    *RateLimit
+	limiter  *rate.Limiter
+	inflight int
+	lastUsed time.Time
+}
+
+func newQuota(limit *RateLimit) *quota {
+	q := &quota{limit: limit}
+	if limit.RequestsPerSecond > 0 {
+		burst := limit.Burst
+		if burst <= 0 {
+			burst = int(math.Max(1, math.Ceil(limit.RequestsPerSecond)))
+		}
+		q.limiter = rate.NewLimiter(rate.Limit(limit.RequestsPerSecond), burst)
+	}
+	return q
+}
+
+func (q *quota) full() bool {
+	return q.limit.MaxConcurrent > 0 && q.inflight >= q.limit.MaxConcurrent
+}
+
+// reserve takes a token for the request, returning how long to wait if none is available
+func (q *quota) reserve(now time.Time) (*rate.Reservation, time.Duration) {
+	if q.limiter == nil {
+		return nil, 0
+	}
+	r := q.limiter.ReserveN(now, 1)
+	if delay := r.DelayFrom(now); delay > 0 {
+		r.CancelAt(now)
+		return nil, delay
+	}
+	return r, 0
+}
+
+// rateLimiter enforces the API rate limits and concurrency quotas of a namespace
+type rateLimiter struct {
+	namespace  string
+	limits     RateLimits
+	mux        sync.Mutex
+	total      *quota
+	identities map[string]*quota
+	lastSweep  time.Time
+}
+
+func newRateLimiter(namespace string, limits RateLimits) *rateLimiter {
+	return &rateLimiter{
+		namespace:  namespace,
+		limits:     limits,
+		total:      newQuota(&limits.Namespace),
+		identities: make(map[string]*quota),
+		lastSweep:  time.Now(),
+	}
+}
+
+// identityQuota returns the quota for an identity, discarding any that have been idle for a while
+// so the set of identities does not grow without bound
+func (rl *rateLimiter) identityQuota(identity string, now time.Time) *quota {
+	if now.Sub(rl.lastSweep) > identityQuotaIdleTimeout {
+		for id, q := range rl.identities {
+			if q.inflight == 0 && now.Sub(q.lastUsed) > identityQuotaIdleTimeout {
+				delete(rl.identities, id)
+			}
+		}
+		rl.lastSweep = now
+	}
+	q := rl.identities[identity]
+	if q == nil {
+		q = newQuota(&rl.limits.Identity)
+		rl.identities[identity] = q
+	}
+	q.lastUsed = now
+	return q
+}
+
+func (rl *rateLimiter) acquire(ctx context.Context, identity string, now time.Time) (release func(), scope, reason string, retryAfter time.Duration, err error) {
+	rl.mux.Lock()
+	defer rl.mux.Unlock()
+
+	quotas := []*quota{rl.total}
+	scopes := []string{rateLimitScopeNamespace}
+	names := []string{rl.namespace}
+	if rl.limits.Identity.enabled() {
+		quotas = append(quotas, rl.identityQuota(identity, now))
+		scopes = append(scopes, rateLimitScopeIdentity)
+		names = append(names, identity)
+	}
+
+	// Check concurrency first, as it does not consume anything
+	for i, q := range quotas {
+		if q.full() {
+			return nil, scopes[i], rateLimitReasonInflight, concurrencyRetryAfter, i18n.NewError(ctx, coremsgs.MsgRequestConcurrencyLimited, scopes[i], names[i])
+		}
+	}
+
+	reservations := make([]*rate.Reservation, 0, len(quotas))
+	for i, q := range quotas {
+		r, delay := q.reserve(now)
+		if delay > 0 {
+			// Return the tokens taken from any quota checked before this one
+			for _, r := range reservations {
+				if r != nil {
+					r.CancelAt(now)
+				}
+			}
+			return nil, scopes[i], rateLimitReasonRate, delay, i18n.NewError(ctx, coremsgs.MsgRequestRateLimited, scopes[i], names[i])
+		}
+		reservations = append(reservations, r)
+	}
+
+	for _, q := range quotas {
+		q.inflight++
+	}
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			rl.mux.Lock()
+			defer rl.mux.Unlock()
+			for _, q := range quotas {
+				q.inflight--
+			}
+		})
+	}, "", "", 0, nil
+}
+
+func (or *orchestrator) AcquireRequestQuota(ctx context.Context, identity string) (release func(), retryAfter time.Duration, err error) {
+	release, scope, reason, retryAfter, err := or.rateLimiter.acquire(ctx, identity, time.Now())
+	if err != nil {
+		log.L(ctx).Warnf("API request from '%s' rejected: %s", identity, err)
+		if or.metrics.IsMetricsEnabled() {
+			or.metrics.APIRequestRejected(or.namespace.Name, scope, reason)
+		}
+		return nil, retryAfter, err
+	}
+	return release, 0, nil
+}
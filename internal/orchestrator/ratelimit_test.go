@@ -0,0 +1,147 @@
+// Copyright © 2024 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package orchestrator
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAcquireRequestQuotaUnlimited(t *testing.T) {
+	or := newTestOrchestrator()
+	defer or.cleanup(t)
+
+	for i := 0; i < 100; i++ {
+		release, retryAfter, err := or.AcquireRequestQuota(context.Background(), "user1")
+		assert.NoError(t, err)
+		assert.Zero(t, retryAfter)
+		release()
+	}
+	assert.Empty(t, or.rateLimiter.identities)
+}
+
+func TestAcquireRequestQuotaNamespaceRate(t *testing.T) {
+	or := newTestOrchestrator()
+	defer or.cleanup(t)
+	or.rateLimiter = newRateLimiter("ns", RateLimits{
+		Namespace: RateLimit{RequestsPerSecond: 0.5},
+	})
+	or.mmi.On("IsMetricsEnabled").Return(true)
+	or.mmi.On("APIRequestRejected", "ns", "namespace", "rate").Return()
+
+	release, _, err := or.AcquireRequestQuota(context.Background(), "user1")
+	assert.NoError(t, err)
+	release()
+
+	_, retryAfter, err := or.AcquireRequestQuota(context.Background(), "user2")
+	assert.Regexp(t, "FF10499.*namespace 'ns'", err)
+	assert.Greater(t, retryAfter, time.Second)
+}
+
+func TestAcquireRequestQuotaIdentityRate(t *testing.T) {
+	or := newTestOrchestrator()
+	defer or.cleanup(t)
+	or.rateLimiter = newRateLimiter("ns", RateLimits{
+		Namespace: RateLimit{RequestsPerSecond: 1, Burst: 2},
+		Identity:  RateLimit{RequestsPerSecond: 1},
+	})
+	or.mmi.On("IsMetricsEnabled").Return(false)
+
+	release, _, err := or.AcquireRequestQuota(context.Background(), "user1")
+	assert.NoError(t, err)
+	release()
+
+	_, retryAfter, err := or.AcquireRequestQuota(context.Background(), "user1")
+	assert.Regexp(t, "FF10499.*identity 'user1'", err)
+	assert.Greater(t, retryAfter, time.Duration(0))
+
+	// The token taken from the namespace quota is returned, so another identity can still make a request
+	release, _, err = or.AcquireRequestQuota(context.Background(), "user2")
+	assert.NoError(t, err)
+	release()
+}
+
+func TestAcquireRequestQuotaConcurrency(t *testing.T) {
+	or := newTestOrchestrator()
+	defer or.cleanup(t)
+	or.rateLimiter = newRateLimiter("ns", RateLimits{
+		Namespace: RateLimit{MaxConcurrent: 2},
+		Identity:  RateLimit{MaxConcurrent: 1},
+	})
+	or.mmi.On("IsMetricsEnabled").Return(true)
+	or.mmi.On("APIRequestRejected", "ns", "identity", "concurrency").Return().Once()
+	or.mmi.On("APIRequestRejected", "ns", "namespace", "concurrency").Return().Once()
+
+	release1, _, err := or.AcquireRequestQuota(context.Background(), "user1")
+	assert.NoError(t, err)
+
+	_, retryAfter, err := or.AcquireRequestQuota(context.Background(), "user1")
+	assert.Regexp(t, "FF10500.*identity 'user1'", err)
+	assert.Equal(t, concurrencyRetryAfter, retryAfter)
+
+	release2, _, err := or.AcquireRequestQuota(context.Background(), "user2")
+	assert.NoError(t, err)
+
+	_, _, err = or.AcquireRequestQuota(context.Background(), "user3")
+	assert.Regexp(t, "FF10500.*namespace 'ns'", err)
+
+	// Releasing more than once has no further effect
+	release1()
+	release1()
+	release2()
+	assert.Zero(t, or.rateLimiter.total.inflight)
+
+	release, _, err := or.AcquireRequestQuota(context.Background(), "user1")
+	assert.NoError(t, err)
+	release()
+}
+
+func TestRateLimiterIdleIdentities(t *testing.T) {
+	rl := newRateLimiter("ns", RateLimits{
+		Identity: RateLimit{MaxConcurrent: 1},
+	})
+	now := time.Now()
+
+	release, _, _, _, err := rl.acquire(context.Background(), "user1", now)
+	assert.NoError(t, err)
+	release()
+	release, _, _, _, err = rl.acquire(context.Background(), "user2", now)
+	assert.NoError(t, err)
+	assert.Len(t, rl.identities, 2)
+
+	// Only idle identities without requests in flight are removed
+	now = now.Add(identityQuotaIdleTimeout * 2)
+	release3, _, _, _, err := rl.acquire(context.Background(), "user3", now)
+	assert.NoError(t, err)
+	assert.Len(t, rl.identities, 2)
+	assert.NotNil(t, rl.identities["user2"])
+	assert.NotNil(t, rl.identities["user3"])
+	release()
+	release3()
+}
+
+func TestRateLimitDefaultBurst(t *testing.T) {
+	q := newQuota(&RateLimit{RequestsPerSecond: 2.5})
+	assert.Equal(t, 3, q.limiter.Burst())
+	q = newQuota(&RateLimit{RequestsPerSecond: 0.1})
+	assert.Equal(t, 1, q.limiter.Burst())
+	q = newQuota(&RateLimit{RequestsPerSecond: 1, Burst: 10})
+	assert.Equal(t, 10, q.limiter.Burst())
+}
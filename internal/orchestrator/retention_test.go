@@ -0,0 +1,42 @@
+// Copyright © 2024 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package orchestrator
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/firefly-common/pkg/fftypes"
+	"github.com/hyperledger/firefly/pkg/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestPreviewRetention(t *testing.T) {
+	or := newTestOrchestrator()
+	defer or.cleanup(t)
+
+	maxAge := fftypes.FFDuration(time.Hour)
+	policy := &core.RetentionPolicy{Events: &maxAge}
+	previews := []*core.RetentionPreview{{Type: core.RetentionTypeEvents, MaxAge: &maxAge, Count: 5}}
+	or.mrm.On("Preview", mock.Anything, policy).Return(previews, nil)
+
+	res, err := or.PreviewRetention(context.Background(), policy)
+	assert.NoError(t, err)
+	assert.Equal(t, previews, res)
+}
@@ -89,6 +89,7 @@ func (or *orchestrator) GetStatus(ctx context.Context) (status *core.NamespaceSt
 		Multiparty: core.NamespaceStatusMultiparty{
 			Enabled: or.config.Multiparty.Enabled,
 		},
+		Leader: or.GetLeaderStatus(ctx),
 	}
 
 	if or.config.Multiparty.Enabled {
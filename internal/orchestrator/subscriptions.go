@@ -72,6 +72,13 @@ func (or *orchestrator) createUpdateSubscription(ctx context.Context, subDef *co
 		}
 	}
 
+	if subDef.Options.RetryBackoff != nil && *subDef.Options.RetryBackoff != "" {
+		_, err := fftypes.ParseDurationString(*subDef.Options.RetryBackoff, time.Millisecond)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	if subDef.Options.Batch != nil && *subDef.Options.Batch {
 		if subDef.Options.WithData != nil && *subDef.Options.WithData {
 			return nil, i18n.NewError(ctx, coremsgs.MsgBatchWithDataNotSupported, subDef.Name)
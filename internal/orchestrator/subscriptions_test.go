@@ -80,6 +80,28 @@ func TestCreateSubscriptionBadBatchTimeout(t *testing.T) {
 	assert.Regexp(t, "FF00137", err)
 }
 
+func TestCreateSubscriptionBadRetryBackoff(t *testing.T) {
+	or := newTestOrchestrator()
+	defer or.cleanup(t)
+
+	badBackoff := "-abc"
+	_, err := or.CreateSubscription(or.ctx, &core.Subscription{
+		SubscriptionRef: core.SubscriptionRef{
+			Name: "sub1",
+		},
+		Options: core.SubscriptionOptions{
+			SubscriptionCoreOptions: core.SubscriptionCoreOptions{
+				RetryBackoff: &badBackoff,
+			},
+			WebhookSubOptions: core.WebhookSubOptions{
+				URL: "http://example.com",
+			},
+		},
+		Transport: "webhooks",
+	})
+	assert.Regexp(t, "FF00137", err)
+}
+
 func TestCreateSubscriptionBatchNotSupported(t *testing.T) {
 	or := newTestOrchestrator()
 	defer or.cleanup(t)
@@ -177,7 +177,7 @@ func (gm *groupManager) getGroupNodes(ctx context.Context, groupHash *fftypes.By
 		return ghe.group, ghe.nodes, nil
 	}
 
-	group, err := gm.database.GetGroupByHash(ctx, gm.namespace.Name, groupHash)
+	group, err := gm.database.GetGroupByHash(database.WithPrimaryReads(ctx), gm.namespace.Name, groupHash)
 	if err != nil || (allowNil && group == nil) {
 		return nil, nil, err
 	}
@@ -0,0 +1,504 @@
+// Copyright © 2024 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package retention
+
+import (
+	"compress/gzip"
+	"context"
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/hyperledger/firefly-common/pkg/config"
+	"github.com/hyperledger/firefly-common/pkg/ffapi"
+	"github.com/hyperledger/firefly-common/pkg/fftypes"
+	"github.com/hyperledger/firefly-common/pkg/i18n"
+	"github.com/hyperledger/firefly-common/pkg/log"
+	"github.com/hyperledger/firefly/internal/coreconfig"
+	"github.com/hyperledger/firefly/internal/coremsgs"
+	"github.com/hyperledger/firefly/internal/data"
+	"github.com/hyperledger/firefly/pkg/core"
+	"github.com/hyperledger/firefly/pkg/database"
+)
+
+type Manager interface {
+	Start()
+	WaitStop()
+
+	// Preview returns how many records of each type a retention policy would archive and delete now.
+	// The configured policy of the namespace is used if the supplied policy is empty
+	Preview(ctx context.Context, policy *core.RetentionPolicy) ([]*core.RetentionPreview, error)
+}
+
+// leaseName is the database lease held by the replica that applies the retention policy of a namespace
+const leaseName = "retention"
+
+// retentionManager is the background reaper that applies the retention policy of a namespace.
+// Records older than the maximum age of their type are written to a compressed archive, and then
+// deleted from the database, in batches. Events are only deleted once every subscription has
+// moved past them. The blobs of deleted data are deleted from data exchange, but are not archived.
+//
+// Only one replica sharing the database applies the policy at a time, by holding a database lease.
+// This is in addition to leader election, so replicas never archive and delete the same records
+// even when leader election is disabled.
+type retentionManager struct {
+	ctx              context.Context
+	namespace        string
+	policy           *core.RetentionPolicy
+	database         database.Plugin
+	data             data.Manager
+	interval         time.Duration
+	batchSize        int
+	archiveDirectory string
+	holder           string
+	leaseHeld        bool
+	done             chan struct{}
+}
+
+func NewRetentionManager(ctx context.Context, ns string, policy *core.RetentionPolicy, di database.Plugin, dm data.Manager) Manager {
+	rm := &retentionManager{
+		ctx:              log.WithLogField(ctx, "role", "retention"),
+		namespace:        ns,
+		policy:           policy,
+		database:         di,
+		data:             dm,
+		interval:         config.GetDuration(coreconfig.RetentionInterval),
+		batchSize:        config.GetInt(coreconfig.RetentionBatchSize),
+		archiveDirectory: config.GetString(coreconfig.RetentionArchiveDirectory),
+		holder:           fmt.Sprintf("%s-%s", hostname(), fftypes.ShortID()),
+	}
+	if rm.batchSize <= 0 {
+		rm.batchSize = 1
+	}
+	return rm
+}
+
+func hostname() string {
+	name, _ := os.Hostname()
+	return name
+}
+
+func (rm *retentionManager) Start() {
+	if rm.policy.IsEmpty() {
+		return
+	}
+	if rm.archiveDirectory == "" {
+		log.L(rm.ctx).Warnf("No retention archive directory is configured. Records will be deleted without an archive")
+	} else if rm.database.Capabilities().EncryptionAtRest {
+		log.L(rm.ctx).Warnf("Values are encrypted in the database, but are written to the retention archives in '%s' as plaintext", rm.archiveDirectory)
+	}
+	rm.done = make(chan struct{})
+	go rm.reaperLoop()
+}
+
+func (rm *retentionManager) WaitStop() {
+	if rm.done != nil {
+		<-rm.done
+	}
+}
+
+func (rm *retentionManager) reaperLoop() {
+	defer close(rm.done)
+	ticker := time.NewTicker(rm.interval)
+	defer ticker.Stop()
+	for {
+		rm.applyPolicy(rm.ctx)
+		select {
+		case <-ticker.C:
+		case <-rm.ctx.Done():
+			rm.releaseLease()
+			log.L(rm.ctx).Debugf("Retention reaper exiting")
+			return
+		}
+	}
+}
+
+// applyPolicy archives and deletes each type of record in turn. Any error is logged, and the
+// remaining records of that type are retried on the next interval. Data is not deleted in a pass
+// where deleting messages failed, as it might still be referenced by the messages that are left.
+func (rm *retentionManager) applyPolicy(ctx context.Context) {
+	now := time.Now()
+	messagesFailed := false
+	for _, rt := range core.RetentionTypes {
+		before := purgeBefore(rm.policy, rt, now)
+		if before == nil {
+			continue
+		}
+		if rt == core.RetentionTypeData && messagesFailed {
+			log.L(ctx).Warnf("Retention policy not applied to %s, as deleting messages failed", rt)
+			continue
+		}
+		// The lease is renewed before each type, so it cannot expire during a long pass
+		if !rm.holdLease(ctx) {
+			return
+		}
+		count, err := rm.purgeType(ctx, rt, before)
+		if err != nil {
+			log.L(ctx).Errorf("Failed to apply retention policy to %s after %d deleted: %s", rt, count, err)
+			messagesFailed = messagesFailed || rt == core.RetentionTypeMessages
+			continue
+		}
+		if count > 0 {
+			log.L(ctx).Infof("Retention policy deleted %d %s created before %s", count, rt, before.String())
+		}
+	}
+}
+
+// holdLease acquires or renews the retention lease of the namespace, returning false if another replica holds it.
+// The lease lasts beyond the next interval, so the replica that holds it keeps applying the policy until it stops.
+func (rm *retentionManager) holdLease(ctx context.Context) bool {
+	current, err := rm.database.AcquireLease(ctx, rm.namespace, leaseName, rm.holder, 2*rm.interval)
+	if err != nil {
+		log.L(ctx).Errorf("Failed to acquire retention lease: %s", err)
+		return false
+	}
+	rm.leaseHeld = current.Holder == rm.holder
+	if !rm.leaseHeld {
+		log.L(ctx).Debugf("Retention policy is being applied by replica '%s' until %s", current.Holder, current.Expires)
+	}
+	return rm.leaseHeld
+}
+
+// releaseLease allows another replica to take over straight away, rather than when the lease expires
+func (rm *retentionManager) releaseLease() {
+	if rm.leaseHeld {
+		// Our context is already cancelled
+		if err := rm.database.ReleaseLease(context.Background(), rm.namespace, leaseName, rm.holder); err != nil {
+			log.L(rm.ctx).Warnf("Failed to release retention lease: %s", err)
+		}
+		rm.leaseHeld = false
+	}
+}
+
+// purgeBefore returns the time before which records of a type are deleted by a policy, or nil if they are kept forever
+func purgeBefore(policy *core.RetentionPolicy, rt core.RetentionType, now time.Time) *fftypes.FFTime {
+	maxAge := policy.MaxAge(rt)
+	if maxAge <= 0 {
+		return nil
+	}
+	before := fftypes.FFTime(now.Add(-time.Duration(maxAge)))
+	return &before
+}
+
+// purgeType deletes the records of a type created before the given time. The messages that are eligible for deletion
+// have already been deleted when data is purged, so only data that is not referenced by any remaining message is deleted.
+func (rm *retentionManager) purgeType(ctx context.Context, rt core.RetentionType, before *fftypes.FFTime) (count int64, err error) {
+	filter, ok, err := rm.buildFilter(ctx, rt, before)
+	if err != nil || !ok {
+		return 0, err
+	}
+
+	var archive *archiveWriter
+	defer func() {
+		if archive != nil {
+			archive.close()
+		}
+	}()
+
+	for {
+		records, ids, _, err := rm.getRecords(ctx, rt, filter.Limit(uint64(rm.batchSize)), nil)
+		if err != nil || len(ids) == 0 {
+			return count, err
+		}
+		if rm.archiveDirectory != "" {
+			if archive == nil {
+				if archive, err = rm.newArchiveWriter(ctx, rt); err != nil {
+					return count, err
+				}
+			}
+			// The archive must be durable before the records are deleted
+			if err := archive.write(records); err != nil {
+				return count, err
+			}
+		}
+		if rt == core.RetentionTypeData {
+			if err := rm.deleteBlobs(ctx, records); err != nil {
+				return count, err
+			}
+		}
+		if err := rm.database.PurgeRecords(ctx, rm.namespace, collectionForType(rt), ids); err != nil {
+			return count, err
+		}
+		count += int64(len(ids))
+		if len(ids) < rm.batchSize {
+			return count, nil
+		}
+	}
+}
+
+// deleteBlobs deletes the blobs of data that is about to be deleted from data exchange, before the data is deleted.
+// Without data exchange, only the blob records are deleted, along with the data.
+func (rm *retentionManager) deleteBlobs(ctx context.Context, records []interface{}) error {
+	var dataIDs []driver.Value
+	for _, r := range records {
+		if d := r.(*core.Data); d.Blob != nil && d.Blob.Hash != nil {
+			dataIDs = append(dataIDs, d.ID)
+		}
+	}
+	if len(dataIDs) == 0 || !rm.data.BlobsEnabled() {
+		return nil
+	}
+	fb := database.BlobQueryFactory.NewFilter(ctx)
+	blobs, _, err := rm.database.GetBlobs(ctx, rm.namespace, fb.In("data_id", dataIDs))
+	if err != nil {
+		return err
+	}
+	for _, blob := range blobs {
+		if err := rm.data.DeleteBlob(ctx, blob); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (rm *retentionManager) Preview(ctx context.Context, policy *core.RetentionPolicy) ([]*core.RetentionPreview, error) {
+	if policy == nil || policy.IsEmpty() {
+		policy = rm.policy
+	}
+	now := time.Now()
+	messagesBefore := purgeBefore(policy, core.RetentionTypeMessages, now)
+	previews := make([]*core.RetentionPreview, 0, len(core.RetentionTypes))
+	for _, rt := range core.RetentionTypes {
+		maxAge := policy.MaxAge(rt)
+		if maxAge <= 0 {
+			continue
+		}
+		before := fftypes.FFTime(now.Add(-time.Duration(maxAge)))
+		preview := &core.RetentionPreview{
+			Type:   rt,
+			MaxAge: &maxAge,
+			Before: &before,
+		}
+		filter, ok, err := rm.buildFilter(ctx, rt, &before)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			_, _, res, err := rm.getRecords(ctx, rt, filter.Count(true).Limit(1), messagesBefore)
+			if err != nil {
+				return nil, err
+			}
+			if res != nil && res.TotalCount != nil {
+				preview.Count = *res.TotalCount
+			}
+		}
+		previews = append(previews, preview)
+	}
+	return previews, nil
+}
+
+// buildFilter returns the filter for the records of a type that are eligible for deletion,
+// or false if none of them can be deleted at the moment
+func (rm *retentionManager) buildFilter(ctx context.Context, rt core.RetentionType, before *fftypes.FFTime) (ffapi.Filter, bool, error) {
+	switch rt {
+	case core.RetentionTypeEvents:
+		maxSequence, ok, err := rm.deliveredEventSequence(ctx)
+		if err != nil || !ok {
+			return nil, false, err
+		}
+		fb := database.EventQueryFactory.NewFilter(ctx)
+		conditions := []ffapi.Filter{fb.Lt("created", before)}
+		if maxSequence >= 0 {
+			conditions = append(conditions, fb.Lte("sequence", maxSequence))
+		}
+		return fb.And(conditions...).Sort("sequence"), true, nil
+	case core.RetentionTypeMessages:
+		fb := database.MessageQueryFactory.NewFilter(ctx)
+		return fb.And(
+			fb.Lt("created", before),
+			fb.In("state", []driver.Value{core.MessageStateConfirmed, core.MessageStateRejected}),
+		).Sort("created"), true, nil
+	case core.RetentionTypeData:
+		fb := database.DataQueryFactory.NewFilter(ctx)
+		return fb.And(fb.Lt("created", before)).Sort("created"), true, nil
+	case core.RetentionTypeOperations:
+		fb := database.OperationQueryFactory.NewFilter(ctx)
+		return fb.And(
+			fb.Lt("created", before),
+			fb.In("status", []driver.Value{core.OpStatusSucceeded, core.OpStatusFailed}),
+		).Sort("created"), true, nil
+	case core.RetentionTypeBlockchainEvents:
+		fb := database.BlockchainEventQueryFactory.NewFilter(ctx)
+		return fb.And(fb.Lt("timestamp", before)).Sort("timestamp"), true, nil
+	default: // core.RetentionTypeTokenTransfers
+		fb := database.TokenTransferQueryFactory.NewFilter(ctx)
+		return fb.And(fb.Lt("created", before)).Sort("created"), true, nil
+	}
+}
+
+// deliveredEventSequence returns the highest event sequence that has been delivered to every
+// subscription in the namespace, and is not held in a dead-letter queue for replay.
+// Returns -1 if there is nothing to hold events back, or false if no events can be deleted yet.
+func (rm *retentionManager) deliveredEventSequence(ctx context.Context) (int64, bool, error) {
+	maxSequence := int64(-1)
+	subs, _, err := rm.database.GetSubscriptions(ctx, rm.namespace, database.SubscriptionQueryFactory.NewFilter(ctx).And())
+	if err != nil {
+		return -1, false, err
+	}
+	for _, sub := range subs {
+		offset, err := rm.database.GetOffset(ctx, core.OffsetTypeSubscription, sub.ID.String())
+		if err != nil {
+			return -1, false, err
+		}
+		if offset == nil {
+			// The subscription has not started delivering events yet
+			log.L(ctx).Debugf("Subscription '%s' has no offset - events will not be deleted", sub.Name)
+			return -1, false, nil
+		}
+		if maxSequence < 0 || offset.Current < maxSequence {
+			maxSequence = offset.Current
+		}
+	}
+
+	fb := database.DeadLetterQueryFactory.NewFilter(ctx)
+	deadLetters, _, err := rm.database.GetDeadLetters(ctx, rm.namespace, fb.And().Sort("eventsequence").Limit(1))
+	if err != nil {
+		return -1, false, err
+	}
+	if len(deadLetters) > 0 {
+		held := deadLetters[0].EventSequence - 1
+		if held < 0 {
+			return -1, false, nil
+		}
+		if maxSequence < 0 || held < maxSequence {
+			maxSequence = held
+		}
+	}
+	return maxSequence, true, nil
+}
+
+// getRecords returns a page of the records selected by the filter. Data that is still referenced by a message
+// being kept is excluded, and messages created before messagesBefore are only kept if they are not yet confirmed
+// or rejected. All messages are kept if messagesBefore is nil.
+func (rm *retentionManager) getRecords(ctx context.Context, rt core.RetentionType, filter ffapi.Filter, messagesBefore *fftypes.FFTime) (records []interface{}, ids []*fftypes.UUID, res *ffapi.FilterResult, err error) {
+	switch rt {
+	case core.RetentionTypeEvents:
+		var events []*core.Event
+		events, res, err = rm.database.GetEvents(ctx, rm.namespace, filter)
+		for _, e := range events {
+			records = append(records, e)
+			ids = append(ids, e.ID)
+		}
+	case core.RetentionTypeMessages:
+		var msgs []*core.Message
+		msgs, res, err = rm.database.GetMessages(ctx, rm.namespace, filter)
+		for _, m := range msgs {
+			records = append(records, m)
+			ids = append(ids, m.Header.ID)
+		}
+	case core.RetentionTypeData:
+		var data core.DataArray
+		data, res, err = rm.database.GetPurgeableData(ctx, rm.namespace, filter, messagesBefore)
+		for _, d := range data {
+			records = append(records, d)
+			ids = append(ids, d.ID)
+		}
+	case core.RetentionTypeOperations:
+		var ops []*core.Operation
+		ops, res, err = rm.database.GetOperations(ctx, rm.namespace, filter)
+		for _, op := range ops {
+			records = append(records, op)
+			ids = append(ids, op.ID)
+		}
+	case core.RetentionTypeBlockchainEvents:
+		var events []*core.BlockchainEvent
+		events, res, err = rm.database.GetBlockchainEvents(ctx, rm.namespace, filter)
+		for _, e := range events {
+			records = append(records, e)
+			ids = append(ids, e.ID)
+		}
+	default: // core.RetentionTypeTokenTransfers
+		var transfers []*core.TokenTransfer
+		transfers, res, err = rm.database.GetTokenTransfers(ctx, rm.namespace, filter)
+		for _, t := range transfers {
+			records = append(records, t)
+			ids = append(ids, t.LocalID)
+		}
+	}
+	return records, ids, res, err
+}
+
+func collectionForType(rt core.RetentionType) database.CollectionName {
+	switch rt {
+	case core.RetentionTypeEvents:
+		return database.CollectionName(database.CollectionEvents)
+	case core.RetentionTypeMessages:
+		return database.CollectionName(database.CollectionMessages)
+	case core.RetentionTypeData:
+		return database.CollectionName(database.CollectionData)
+	case core.RetentionTypeOperations:
+		return database.CollectionName(database.CollectionOperations)
+	case core.RetentionTypeBlockchainEvents:
+		return database.CollectionName(database.CollectionBlockchainEvents)
+	default: // core.RetentionTypeTokenTransfers
+		return database.CollectionName(database.CollectionTokenTransfers)
+	}
+}
+
+// archiveWriter writes records as newline delimited JSON to a gzip compressed file
+type archiveWriter struct {
+	ctx  context.Context
+	path string
+	file *os.File
+	gzip *gzip.Writer
+}
+
+func (rm *retentionManager) newArchiveWriter(ctx context.Context, rt core.RetentionType) (*archiveWriter, error) {
+	name := fmt.Sprintf("%s-%s-%s.ndjson.gz", rm.namespace, rt, time.Now().UTC().Format("20060102T150405Z"))
+	path := filepath.Join(rm.archiveDirectory, name)
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return nil, i18n.WrapError(ctx, err, coremsgs.MsgRetentionArchiveFailed, path)
+	}
+	return &archiveWriter{
+		ctx:  ctx,
+		path: path,
+		file: file,
+		gzip: gzip.NewWriter(file),
+	}, nil
+}
+
+func (aw *archiveWriter) write(records []interface{}) error {
+	encoder := json.NewEncoder(aw.gzip)
+	for _, r := range records {
+		if err := encoder.Encode(r); err != nil {
+			return i18n.WrapError(aw.ctx, err, coremsgs.MsgRetentionArchiveFailed, aw.path)
+		}
+	}
+	if err := aw.gzip.Flush(); err != nil {
+		return i18n.WrapError(aw.ctx, err, coremsgs.MsgRetentionArchiveFailed, aw.path)
+	}
+	if err := aw.file.Sync(); err != nil {
+		return i18n.WrapError(aw.ctx, err, coremsgs.MsgRetentionArchiveFailed, aw.path)
+	}
+	return nil
+}
+
+// close completes the archive. Each batch was already synced before it was deleted, so a failure
+// here only loses the gzip trailer, and the records can still be recovered from the archive.
+func (aw *archiveWriter) close() {
+	err := aw.gzip.Close()
+	if closeErr := aw.file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		log.L(aw.ctx).Warnf("Failed to close retention archive '%s': %s", aw.path, err)
+	}
+}
@@ -0,0 +1,579 @@
+// Copyright © 2024 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package retention
+
+import (
+	"bufio"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/firefly-common/pkg/config"
+	"github.com/hyperledger/firefly-common/pkg/ffapi"
+	"github.com/hyperledger/firefly-common/pkg/fftypes"
+	"github.com/hyperledger/firefly/internal/coreconfig"
+	"github.com/hyperledger/firefly/mocks/databasemocks"
+	"github.com/hyperledger/firefly/mocks/datamocks"
+	"github.com/hyperledger/firefly/pkg/core"
+	"github.com/hyperledger/firefly/pkg/database"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func maxAge(d time.Duration) *fftypes.FFDuration {
+	ffd := fftypes.FFDuration(d)
+	return &ffd
+}
+
+func newTestRetentionManager(t *testing.T, policy *core.RetentionPolicy, mods ...func()) (*retentionManager, *databasemocks.Plugin, func()) {
+	coreconfig.Reset()
+	for _, mod := range mods {
+		mod()
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	mdi := &databasemocks.Plugin{}
+	mdi.On("AcquireLease", mock.Anything, "ns1", leaseName, mock.Anything, mock.Anything).Return(func(ctx context.Context, namespace, name, holder string, ttl time.Duration) (*core.Lease, error) {
+		expires := fftypes.FFTime(time.Now().Add(ttl))
+		return &core.Lease{Namespace: namespace, Name: name, Holder: holder, Expires: &expires}, nil
+	}).Maybe()
+	mdi.On("ReleaseLease", mock.Anything, "ns1", leaseName, mock.Anything).Return(nil).Maybe()
+	mdm := &datamocks.Manager{}
+	rm := NewRetentionManager(ctx, "ns1", policy, mdi, mdm).(*retentionManager)
+	return rm, mdi, func() {
+		cancel()
+		mdi.AssertExpectations(t)
+		mdm.AssertExpectations(t)
+	}
+}
+
+func readArchive(t *testing.T, dir string) (lines []string) {
+	files, err := filepath.Glob(filepath.Join(dir, "ns1-operations-*.ndjson.gz"))
+	assert.NoError(t, err)
+	assert.Len(t, files, 1)
+	f, err := os.Open(files[0])
+	assert.NoError(t, err)
+	defer f.Close()
+	gr, err := gzip.NewReader(f)
+	assert.NoError(t, err)
+	scanner := bufio.NewScanner(gr)
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+	return lines
+}
+
+func TestStartEmptyPolicy(t *testing.T) {
+	rm, _, done := newTestRetentionManager(t, &core.RetentionPolicy{}, func() {
+		config.Set(coreconfig.RetentionBatchSize, 0)
+	})
+	defer done()
+	assert.Equal(t, 1, rm.batchSize)
+	rm.Start()
+	rm.WaitStop()
+}
+
+func TestReaperLoopArchivesAndDeletes(t *testing.T) {
+	dir := t.TempDir()
+	rm, mdi, done := newTestRetentionManager(t, &core.RetentionPolicy{
+		Operations: maxAge(24 * time.Hour),
+	}, func() {
+		config.Set(coreconfig.RetentionBatchSize, 2)
+		config.Set(coreconfig.RetentionArchiveDirectory, dir)
+	})
+	defer done()
+
+	// A warning is logged that the archive holds plaintext values
+	mdi.On("Capabilities").Return(&database.Capabilities{EncryptionAtRest: true})
+	op1 := &core.Operation{ID: fftypes.NewUUID(), Status: core.OpStatusSucceeded}
+	op2 := &core.Operation{ID: fftypes.NewUUID(), Status: core.OpStatusFailed}
+	op3 := &core.Operation{ID: fftypes.NewUUID(), Status: core.OpStatusSucceeded}
+	mdi.On("GetOperations", mock.Anything, "ns1", mock.MatchedBy(func(f ffapi.Filter) bool {
+		fi, _ := f.Finalize()
+		return fi.Limit == 2 && fi.Sort[0].Field == "created"
+	})).Return([]*core.Operation{op1, op2}, nil, nil).Once()
+	mdi.On("GetOperations", mock.Anything, "ns1", mock.Anything).Return([]*core.Operation{op3}, nil, nil).Once()
+	mdi.On("PurgeRecords", mock.Anything, "ns1", database.CollectionName(database.CollectionOperations), []*fftypes.UUID{op1.ID, op2.ID}).Return(nil).Once()
+	mdi.On("PurgeRecords", mock.Anything, "ns1", database.CollectionName(database.CollectionOperations), []*fftypes.UUID{op3.ID}).Return(nil).Once().
+		Run(func(args mock.Arguments) {
+			done()
+		})
+
+	rm.Start()
+	rm.WaitStop()
+
+	lines := readArchive(t, dir)
+	assert.Len(t, lines, 3)
+	assert.Contains(t, lines[0], op1.ID.String())
+	assert.Contains(t, lines[2], op3.ID.String())
+}
+
+func TestReaperLoopNoArchive(t *testing.T) {
+	rm, mdi, done := newTestRetentionManager(t, &core.RetentionPolicy{
+		Data: maxAge(time.Hour),
+	}, func() {
+		config.Set(coreconfig.RetentionInterval, "1ms")
+	})
+	defer done()
+
+	mdi.On("GetPurgeableData", mock.Anything, "ns1", mock.Anything, (*fftypes.FFTime)(nil)).Return(core.DataArray{}, nil, nil).Once()
+	mdi.On("GetPurgeableData", mock.Anything, "ns1", mock.Anything, (*fftypes.FFTime)(nil)).Return(core.DataArray{}, nil, nil).Once().
+		Run(func(args mock.Arguments) {
+			done()
+		})
+
+	rm.Start()
+	rm.WaitStop()
+}
+
+func TestApplyPolicyAllTypesNoArchive(t *testing.T) {
+	rm, mdi, done := newTestRetentionManager(t, &core.RetentionPolicy{
+		Events:           maxAge(time.Hour),
+		Messages:         maxAge(time.Hour),
+		Data:             maxAge(time.Hour),
+		Operations:       maxAge(time.Hour),
+		BlockchainEvents: maxAge(time.Hour),
+		TokenTransfers:   maxAge(time.Hour),
+	})
+	defer done()
+
+	sub := &core.Subscription{SubscriptionRef: core.SubscriptionRef{ID: fftypes.NewUUID(), Name: "sub1"}}
+	mdi.On("GetSubscriptions", mock.Anything, "ns1", mock.Anything).Return([]*core.Subscription{sub, sub}, nil, nil)
+	mdi.On("GetOffset", mock.Anything, core.OffsetTypeSubscription, sub.ID.String()).Return(&core.Offset{Current: 100}, nil).Once()
+	mdi.On("GetOffset", mock.Anything, core.OffsetTypeSubscription, sub.ID.String()).Return(&core.Offset{Current: 90}, nil).Once()
+	mdi.On("GetDeadLetters", mock.Anything, "ns1", mock.Anything).Return([]*core.DeadLetter{{EventSequence: 50}}, nil, nil)
+
+	event := &core.Event{ID: fftypes.NewUUID()}
+	mdi.On("GetEvents", mock.Anything, "ns1", mock.MatchedBy(func(f ffapi.Filter) bool {
+		fi, _ := f.Finalize()
+		return strings.HasSuffix(fi.String(), "&& ( sequence <= 49 ) sort=sequence limit=1000")
+	})).Return([]*core.Event{event}, nil, nil)
+	msg := &core.Message{Header: core.MessageHeader{ID: fftypes.NewUUID()}}
+	mdi.On("GetMessages", mock.Anything, "ns1", mock.Anything).Return([]*core.Message{msg}, nil, nil)
+	data := &core.Data{ID: fftypes.NewUUID()}
+	// The messages have been deleted by the time data is read, so data referenced by any remaining message is kept
+	mdi.On("GetPurgeableData", mock.Anything, "ns1", mock.Anything, (*fftypes.FFTime)(nil)).Return(core.DataArray{data}, nil, nil)
+	op := &core.Operation{ID: fftypes.NewUUID()}
+	mdi.On("GetOperations", mock.Anything, "ns1", mock.Anything).Return([]*core.Operation{op}, nil, nil)
+	bcEvent := &core.BlockchainEvent{ID: fftypes.NewUUID()}
+	mdi.On("GetBlockchainEvents", mock.Anything, "ns1", mock.Anything).Return([]*core.BlockchainEvent{bcEvent}, nil, nil)
+	transfer := &core.TokenTransfer{LocalID: fftypes.NewUUID()}
+	mdi.On("GetTokenTransfers", mock.Anything, "ns1", mock.Anything).Return([]*core.TokenTransfer{transfer}, nil, nil)
+
+	mdi.On("PurgeRecords", mock.Anything, "ns1", database.CollectionName(database.CollectionEvents), []*fftypes.UUID{event.ID}).Return(nil)
+	mdi.On("PurgeRecords", mock.Anything, "ns1", database.CollectionName(database.CollectionMessages), []*fftypes.UUID{msg.Header.ID}).Return(nil)
+	mdi.On("PurgeRecords", mock.Anything, "ns1", database.CollectionName(database.CollectionData), []*fftypes.UUID{data.ID}).Return(nil)
+	mdi.On("PurgeRecords", mock.Anything, "ns1", database.CollectionName(database.CollectionOperations), []*fftypes.UUID{op.ID}).Return(nil)
+	mdi.On("PurgeRecords", mock.Anything, "ns1", database.CollectionName(database.CollectionBlockchainEvents), []*fftypes.UUID{bcEvent.ID}).Return(nil)
+	mdi.On("PurgeRecords", mock.Anything, "ns1", database.CollectionName(database.CollectionTokenTransfers), []*fftypes.UUID{transfer.LocalID}).Return(nil)
+
+	rm.applyPolicy(rm.ctx)
+}
+
+func TestApplyPolicyLogsErrors(t *testing.T) {
+	rm, mdi, done := newTestRetentionManager(t, &core.RetentionPolicy{
+		Data: maxAge(time.Hour),
+	})
+	defer done()
+
+	mdi.On("GetPurgeableData", mock.Anything, "ns1", mock.Anything, (*fftypes.FFTime)(nil)).Return(nil, nil, fmt.Errorf("pop"))
+
+	rm.applyPolicy(rm.ctx)
+}
+
+func TestApplyPolicyMessagesFailKeepsData(t *testing.T) {
+	rm, mdi, done := newTestRetentionManager(t, &core.RetentionPolicy{
+		Messages:   maxAge(time.Hour),
+		Data:       maxAge(time.Hour),
+		Operations: maxAge(time.Hour),
+	})
+	defer done()
+
+	msg := &core.Message{Header: core.MessageHeader{ID: fftypes.NewUUID()}}
+	mdi.On("GetMessages", mock.Anything, "ns1", mock.Anything).Return([]*core.Message{msg}, nil, nil)
+	mdi.On("PurgeRecords", mock.Anything, "ns1", database.CollectionName(database.CollectionMessages), []*fftypes.UUID{msg.Header.ID}).Return(fmt.Errorf("pop"))
+	op := &core.Operation{ID: fftypes.NewUUID()}
+	mdi.On("GetOperations", mock.Anything, "ns1", mock.Anything).Return([]*core.Operation{op}, nil, nil)
+	mdi.On("PurgeRecords", mock.Anything, "ns1", database.CollectionName(database.CollectionOperations), []*fftypes.UUID{op.ID}).Return(nil)
+
+	rm.applyPolicy(rm.ctx)
+
+	// The other types are still deleted, but no data is read or deleted
+	mdi.AssertNotCalled(t, "GetPurgeableData", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	mdi.AssertNotCalled(t, "PurgeRecords", mock.Anything, "ns1", database.CollectionName(database.CollectionData), mock.Anything)
+}
+
+func TestApplyPolicyLeaseHeldByOtherReplica(t *testing.T) {
+	rm, mdi, done := newTestRetentionManager(t, &core.RetentionPolicy{
+		Data: maxAge(time.Hour),
+	})
+	defer done()
+
+	mdi.ExpectedCalls = nil
+	mdi.On("AcquireLease", mock.Anything, "ns1", leaseName, rm.holder, 2*rm.interval).Return(&core.Lease{Holder: "replica2", Expires: fftypes.Now()}, nil).Once()
+
+	rm.applyPolicy(rm.ctx)
+	assert.False(t, rm.leaseHeld)
+	rm.releaseLease()
+}
+
+func TestApplyPolicyLeaseFail(t *testing.T) {
+	rm, mdi, done := newTestRetentionManager(t, &core.RetentionPolicy{
+		Data: maxAge(time.Hour),
+	})
+	defer done()
+
+	mdi.ExpectedCalls = nil
+	mdi.On("AcquireLease", mock.Anything, "ns1", leaseName, rm.holder, 2*rm.interval).Return(nil, fmt.Errorf("pop")).Once()
+
+	rm.applyPolicy(rm.ctx)
+	assert.False(t, rm.leaseHeld)
+}
+
+func TestReleaseLeaseFail(t *testing.T) {
+	rm, mdi, done := newTestRetentionManager(t, &core.RetentionPolicy{})
+	defer done()
+
+	mdi.ExpectedCalls = nil
+	mdi.On("ReleaseLease", mock.Anything, "ns1", leaseName, rm.holder).Return(fmt.Errorf("pop")).Once()
+
+	rm.leaseHeld = true
+	rm.releaseLease()
+	assert.False(t, rm.leaseHeld)
+}
+
+func TestPurgeTypeNoRecords(t *testing.T) {
+	rm, mdi, done := newTestRetentionManager(t, &core.RetentionPolicy{})
+	defer done()
+
+	mdi.On("GetTokenTransfers", mock.Anything, "ns1", mock.Anything).Return([]*core.TokenTransfer{}, nil, nil)
+
+	count, err := rm.purgeType(rm.ctx, core.RetentionTypeTokenTransfers, fftypes.Now())
+	assert.NoError(t, err)
+	assert.Zero(t, count)
+}
+
+func TestPurgeTypeDataDeletesBlobs(t *testing.T) {
+	rm, mdi, done := newTestRetentionManager(t, &core.RetentionPolicy{})
+	defer done()
+	mdm := rm.data.(*datamocks.Manager)
+
+	data := core.DataArray{
+		{ID: fftypes.NewUUID(), Blob: &core.BlobRef{Hash: fftypes.NewRandB32()}},
+		{ID: fftypes.NewUUID()},
+	}
+	blob := &core.Blob{Sequence: 1, PayloadRef: "ref1", DataID: data[0].ID}
+	mdi.On("GetPurgeableData", mock.Anything, "ns1", mock.Anything, (*fftypes.FFTime)(nil)).Return(data, nil, nil)
+	mdm.On("BlobsEnabled").Return(true)
+	mdi.On("GetBlobs", mock.Anything, "ns1", mock.MatchedBy(func(f ffapi.Filter) bool {
+		fi, _ := f.Finalize()
+		return fi.String() == fmt.Sprintf("data_id IN ['%s']", data[0].ID)
+	})).Return([]*core.Blob{blob}, nil, nil)
+	mdm.On("DeleteBlob", mock.Anything, blob).Return(nil)
+	mdi.On("PurgeRecords", mock.Anything, "ns1", database.CollectionName(database.CollectionData), []*fftypes.UUID{data[0].ID, data[1].ID}).Return(nil)
+
+	count, err := rm.purgeType(rm.ctx, core.RetentionTypeData, fftypes.Now())
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), count)
+}
+
+func TestPurgeTypeDataBlobsDisabled(t *testing.T) {
+	rm, mdi, done := newTestRetentionManager(t, &core.RetentionPolicy{})
+	defer done()
+	mdm := rm.data.(*datamocks.Manager)
+
+	data := &core.Data{ID: fftypes.NewUUID(), Blob: &core.BlobRef{Hash: fftypes.NewRandB32()}}
+	mdi.On("GetPurgeableData", mock.Anything, "ns1", mock.Anything, (*fftypes.FFTime)(nil)).Return(core.DataArray{data}, nil, nil)
+	mdm.On("BlobsEnabled").Return(false)
+	mdi.On("PurgeRecords", mock.Anything, "ns1", database.CollectionName(database.CollectionData), []*fftypes.UUID{data.ID}).Return(nil)
+
+	count, err := rm.purgeType(rm.ctx, core.RetentionTypeData, fftypes.Now())
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), count)
+}
+
+func TestPurgeTypeDataGetBlobsFail(t *testing.T) {
+	rm, mdi, done := newTestRetentionManager(t, &core.RetentionPolicy{})
+	defer done()
+	mdm := rm.data.(*datamocks.Manager)
+
+	data := &core.Data{ID: fftypes.NewUUID(), Blob: &core.BlobRef{Hash: fftypes.NewRandB32()}}
+	mdi.On("GetPurgeableData", mock.Anything, "ns1", mock.Anything, (*fftypes.FFTime)(nil)).Return(core.DataArray{data}, nil, nil)
+	mdm.On("BlobsEnabled").Return(true)
+	mdi.On("GetBlobs", mock.Anything, "ns1", mock.Anything).Return(nil, nil, fmt.Errorf("pop"))
+
+	_, err := rm.purgeType(rm.ctx, core.RetentionTypeData, fftypes.Now())
+	assert.Regexp(t, "pop", err)
+}
+
+func TestPurgeTypeDataDeleteBlobFail(t *testing.T) {
+	rm, mdi, done := newTestRetentionManager(t, &core.RetentionPolicy{})
+	defer done()
+	mdm := rm.data.(*datamocks.Manager)
+
+	data := &core.Data{ID: fftypes.NewUUID(), Blob: &core.BlobRef{Hash: fftypes.NewRandB32()}}
+	blob := &core.Blob{Sequence: 1, PayloadRef: "ref1", DataID: data.ID}
+	mdi.On("GetPurgeableData", mock.Anything, "ns1", mock.Anything, (*fftypes.FFTime)(nil)).Return(core.DataArray{data}, nil, nil)
+	mdm.On("BlobsEnabled").Return(true)
+	mdi.On("GetBlobs", mock.Anything, "ns1", mock.Anything).Return([]*core.Blob{blob}, nil, nil)
+	mdm.On("DeleteBlob", mock.Anything, blob).Return(fmt.Errorf("pop"))
+
+	// The data is not deleted, so its blob is retried on the next interval
+	_, err := rm.purgeType(rm.ctx, core.RetentionTypeData, fftypes.Now())
+	assert.Regexp(t, "pop", err)
+}
+
+func TestPurgeTypeEventsFail(t *testing.T) {
+	rm, mdi, done := newTestRetentionManager(t, &core.RetentionPolicy{})
+	defer done()
+
+	mdi.On("GetSubscriptions", mock.Anything, "ns1", mock.Anything).Return(nil, nil, fmt.Errorf("pop"))
+
+	_, err := rm.purgeType(rm.ctx, core.RetentionTypeEvents, fftypes.Now())
+	assert.Regexp(t, "pop", err)
+}
+
+func TestPurgeTypeArchiveOpenFail(t *testing.T) {
+	rm, mdi, done := newTestRetentionManager(t, &core.RetentionPolicy{}, func() {
+		config.Set(coreconfig.RetentionArchiveDirectory, filepath.Join(t.TempDir(), "missing"))
+	})
+	defer done()
+
+	mdi.On("GetOperations", mock.Anything, "ns1", mock.Anything).Return([]*core.Operation{{ID: fftypes.NewUUID()}}, nil, nil)
+
+	_, err := rm.purgeType(rm.ctx, core.RetentionTypeOperations, fftypes.Now())
+	assert.Regexp(t, "FF10502", err)
+}
+
+func TestPurgeTypeArchiveWriteFail(t *testing.T) {
+	rm, mdi, done := newTestRetentionManager(t, &core.RetentionPolicy{}, func() {
+		config.Set(coreconfig.RetentionArchiveDirectory, t.TempDir())
+	})
+	defer done()
+
+	mdi.On("GetOperations", mock.Anything, "ns1", mock.Anything).Return([]*core.Operation{
+		{ID: fftypes.NewUUID(), Input: fftypes.JSONObject{"bad": map[bool]bool{true: false}}},
+	}, nil, nil)
+
+	_, err := rm.purgeType(rm.ctx, core.RetentionTypeOperations, fftypes.Now())
+	assert.Regexp(t, "FF10502", err)
+}
+
+func TestArchiveWriteFail(t *testing.T) {
+	rm, _, done := newTestRetentionManager(t, &core.RetentionPolicy{}, func() {
+		config.Set(coreconfig.RetentionArchiveDirectory, t.TempDir())
+	})
+	defer done()
+
+	archive, err := rm.newArchiveWriter(rm.ctx, core.RetentionTypeOperations)
+	assert.NoError(t, err)
+	archive.file.Close()
+	err = archive.write([]interface{}{"record"})
+	assert.Regexp(t, "FF10502", err)
+	archive.close()
+}
+
+func TestArchiveFlushFail(t *testing.T) {
+	rm, _, done := newTestRetentionManager(t, &core.RetentionPolicy{}, func() {
+		config.Set(coreconfig.RetentionArchiveDirectory, t.TempDir())
+	})
+	defer done()
+
+	archive, err := rm.newArchiveWriter(rm.ctx, core.RetentionTypeOperations)
+	assert.NoError(t, err)
+	archive.file.Close()
+	err = archive.write([]interface{}{})
+	assert.Regexp(t, "FF10502", err)
+}
+
+func TestArchiveSyncFail(t *testing.T) {
+	rm, _, done := newTestRetentionManager(t, &core.RetentionPolicy{})
+	defer done()
+
+	devNull, err := os.OpenFile(os.DevNull, os.O_WRONLY, 0)
+	assert.NoError(t, err)
+	archive := &archiveWriter{ctx: rm.ctx, path: os.DevNull, file: devNull, gzip: gzip.NewWriter(devNull)}
+	err = archive.write([]interface{}{})
+	assert.Regexp(t, "FF10502", err)
+	archive.close()
+}
+
+func TestPurgeTypePurgeFail(t *testing.T) {
+	dir := t.TempDir()
+	rm, mdi, done := newTestRetentionManager(t, &core.RetentionPolicy{}, func() {
+		config.Set(coreconfig.RetentionArchiveDirectory, dir)
+	})
+	defer done()
+
+	op := &core.Operation{ID: fftypes.NewUUID()}
+	mdi.On("GetOperations", mock.Anything, "ns1", mock.Anything).Return([]*core.Operation{op}, nil, nil)
+	mdi.On("PurgeRecords", mock.Anything, "ns1", database.CollectionName(database.CollectionOperations), []*fftypes.UUID{op.ID}).Return(fmt.Errorf("pop"))
+
+	_, err := rm.purgeType(rm.ctx, core.RetentionTypeOperations, fftypes.Now())
+	assert.Regexp(t, "pop", err)
+
+	// The records are archived again on the next attempt
+	assert.Len(t, readArchive(t, dir), 1)
+}
+
+func TestDeliveredEventSequenceNoSubscriptions(t *testing.T) {
+	rm, mdi, done := newTestRetentionManager(t, &core.RetentionPolicy{})
+	defer done()
+
+	mdi.On("GetSubscriptions", mock.Anything, "ns1", mock.Anything).Return([]*core.Subscription{}, nil, nil)
+	mdi.On("GetDeadLetters", mock.Anything, "ns1", mock.Anything).Return([]*core.DeadLetter{}, nil, nil)
+
+	filter, ok, err := rm.buildFilter(rm.ctx, core.RetentionTypeEvents, fftypes.Now())
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	fi, err := filter.Finalize()
+	assert.NoError(t, err)
+	assert.NotContains(t, fi.String(), "sequence <=")
+}
+
+func TestDeliveredEventSequenceDeadLetterOnly(t *testing.T) {
+	rm, mdi, done := newTestRetentionManager(t, &core.RetentionPolicy{})
+	defer done()
+
+	mdi.On("GetSubscriptions", mock.Anything, "ns1", mock.Anything).Return([]*core.Subscription{}, nil, nil)
+	mdi.On("GetDeadLetters", mock.Anything, "ns1", mock.Anything).Return([]*core.DeadLetter{{EventSequence: 10}}, nil, nil)
+
+	seq, ok, err := rm.deliveredEventSequence(rm.ctx)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, int64(9), seq)
+}
+
+func TestDeliveredEventSequenceFirstEventDeadLettered(t *testing.T) {
+	rm, mdi, done := newTestRetentionManager(t, &core.RetentionPolicy{})
+	defer done()
+
+	mdi.On("GetSubscriptions", mock.Anything, "ns1", mock.Anything).Return([]*core.Subscription{}, nil, nil)
+	mdi.On("GetDeadLetters", mock.Anything, "ns1", mock.Anything).Return([]*core.DeadLetter{{EventSequence: 0}}, nil, nil)
+
+	_, ok, err := rm.deliveredEventSequence(rm.ctx)
+	assert.NoError(t, err)
+	assert.False(t, ok)
+}
+
+func TestDeliveredEventSequenceNoOffset(t *testing.T) {
+	rm, mdi, done := newTestRetentionManager(t, &core.RetentionPolicy{})
+	defer done()
+
+	sub := &core.Subscription{SubscriptionRef: core.SubscriptionRef{ID: fftypes.NewUUID(), Name: "sub1"}}
+	mdi.On("GetSubscriptions", mock.Anything, "ns1", mock.Anything).Return([]*core.Subscription{sub}, nil, nil)
+	mdi.On("GetOffset", mock.Anything, core.OffsetTypeSubscription, sub.ID.String()).Return(nil, nil)
+
+	count, err := rm.purgeType(rm.ctx, core.RetentionTypeEvents, fftypes.Now())
+	assert.NoError(t, err)
+	assert.Zero(t, count)
+}
+
+func TestDeliveredEventSequenceOffsetFail(t *testing.T) {
+	rm, mdi, done := newTestRetentionManager(t, &core.RetentionPolicy{})
+	defer done()
+
+	sub := &core.Subscription{SubscriptionRef: core.SubscriptionRef{ID: fftypes.NewUUID(), Name: "sub1"}}
+	mdi.On("GetSubscriptions", mock.Anything, "ns1", mock.Anything).Return([]*core.Subscription{sub}, nil, nil)
+	mdi.On("GetOffset", mock.Anything, core.OffsetTypeSubscription, sub.ID.String()).Return(nil, fmt.Errorf("pop"))
+
+	_, _, err := rm.deliveredEventSequence(rm.ctx)
+	assert.Regexp(t, "pop", err)
+}
+
+func TestDeliveredEventSequenceDeadLettersFail(t *testing.T) {
+	rm, mdi, done := newTestRetentionManager(t, &core.RetentionPolicy{})
+	defer done()
+
+	mdi.On("GetSubscriptions", mock.Anything, "ns1", mock.Anything).Return([]*core.Subscription{}, nil, nil)
+	mdi.On("GetDeadLetters", mock.Anything, "ns1", mock.Anything).Return(nil, nil, fmt.Errorf("pop"))
+
+	_, _, err := rm.deliveredEventSequence(rm.ctx)
+	assert.Regexp(t, "pop", err)
+}
+
+func TestPreviewConfiguredPolicy(t *testing.T) {
+	rm, mdi, done := newTestRetentionManager(t, &core.RetentionPolicy{
+		Events:     maxAge(time.Hour),
+		Operations: maxAge(24 * time.Hour),
+	})
+	defer done()
+
+	sub := &core.Subscription{SubscriptionRef: core.SubscriptionRef{ID: fftypes.NewUUID(), Name: "sub1"}}
+	mdi.On("GetSubscriptions", mock.Anything, "ns1", mock.Anything).Return([]*core.Subscription{sub}, nil, nil)
+	mdi.On("GetOffset", mock.Anything, core.OffsetTypeSubscription, sub.ID.String()).Return(nil, nil)
+	total := int64(12)
+	mdi.On("GetOperations", mock.Anything, "ns1", mock.MatchedBy(func(f ffapi.Filter) bool {
+		fi, _ := f.Finalize()
+		return fi.Count && fi.Limit == 1
+	})).Return([]*core.Operation{}, &ffapi.FilterResult{TotalCount: &total}, nil)
+
+	previews, err := rm.Preview(context.Background(), &core.RetentionPolicy{})
+	assert.NoError(t, err)
+	assert.Len(t, previews, 2)
+	assert.Equal(t, core.RetentionTypeEvents, previews[0].Type)
+	assert.Zero(t, previews[0].Count)
+	assert.Equal(t, core.RetentionTypeOperations, previews[1].Type)
+	assert.Equal(t, fftypes.FFDuration(24*time.Hour), *previews[1].MaxAge)
+	assert.Equal(t, int64(12), previews[1].Count)
+	assert.True(t, previews[1].Before.Time().Before(time.Now().Add(-23*time.Hour)))
+}
+
+func TestPreviewSuppliedPolicy(t *testing.T) {
+	rm, mdi, done := newTestRetentionManager(t, &core.RetentionPolicy{
+		Operations: maxAge(24 * time.Hour),
+	})
+	defer done()
+
+	mdi.On("GetBlockchainEvents", mock.Anything, "ns1", mock.Anything).Return([]*core.BlockchainEvent{}, nil, nil)
+
+	previews, err := rm.Preview(context.Background(), &core.RetentionPolicy{
+		BlockchainEvents: maxAge(time.Hour),
+	})
+	assert.NoError(t, err)
+	assert.Len(t, previews, 1)
+	assert.Equal(t, core.RetentionTypeBlockchainEvents, previews[0].Type)
+	assert.Zero(t, previews[0].Count)
+}
+
+func TestPreviewFilterFail(t *testing.T) {
+	rm, mdi, done := newTestRetentionManager(t, &core.RetentionPolicy{})
+	defer done()
+
+	mdi.On("GetSubscriptions", mock.Anything, "ns1", mock.Anything).Return(nil, nil, fmt.Errorf("pop"))
+
+	_, err := rm.Preview(context.Background(), &core.RetentionPolicy{
+		Events: maxAge(time.Hour),
+	})
+	assert.Regexp(t, "pop", err)
+}
+
+func TestPreviewQueryFail(t *testing.T) {
+	rm, mdi, done := newTestRetentionManager(t, &core.RetentionPolicy{})
+	defer done()
+
+	mdi.On("GetMessages", mock.Anything, "ns1", mock.Anything).Return(nil, nil, fmt.Errorf("pop"))
+
+	_, err := rm.Preview(context.Background(), &core.RetentionPolicy{
+		Messages: maxAge(time.Hour),
+	})
+	assert.Regexp(t, "pop", err)
+}
@@ -37,6 +37,7 @@ import (
 
 type Manager interface {
 	Start() error
+	StartRecovery()
 	WaitStop()
 
 	InitiateDownloadBatch(ctx context.Context, tx *fftypes.UUID, payloadRef string, idempotentSubmit bool) error
@@ -125,9 +126,14 @@ func (dm *downloadManager) Start() error {
 	for i := 0; i < dm.workerCount; i++ {
 		dm.workers[i] = newDownloadWorker(dm, i)
 	}
+	return nil
+}
+
+// StartRecovery restarts the downloads that were in-flight when the previous run stopped. Only one replica
+// sharing the database can run the recovery
+func (dm *downloadManager) StartRecovery() {
 	dm.recoveryComplete = make(chan struct{})
 	go dm.recoverDownloads(fftypes.Now())
-	return nil
 }
 
 func (dm *downloadManager) Name() string {
@@ -303,6 +303,7 @@ func TestDownloadManagerStartupRecoveryCombinations(t *testing.T) {
 
 	err := dm.Start()
 	assert.NoError(t, err)
+	dm.StartRecovery()
 
 	<-called
 	<-called
@@ -29,6 +29,7 @@ import (
 	"github.com/hyperledger/firefly-common/pkg/i18n"
 	"github.com/hyperledger/firefly-common/pkg/log"
 	"github.com/hyperledger/firefly/internal/coremsgs"
+	"github.com/hyperledger/firefly/internal/tracing"
 	"github.com/hyperledger/firefly/pkg/sharedstorage"
 )
 
@@ -61,6 +62,7 @@ func (i *IPFS) Init(ctx context.Context, config config.Section) (err error) {
 	if err != nil {
 		return err
 	}
+	tracing.InstrumentClient(i.apiClient)
 	gwConfig := config.SubSection(IPFSConfGatewaySubconf)
 	if gwConfig.GetString(ffresty.HTTPConfigURL) == "" {
 		return i18n.NewError(ctx, coremsgs.MsgMissingPluginConfig, gwConfig.Resolve(ffresty.HTTPConfigURL), "ipfs")
@@ -69,6 +71,7 @@ func (i *IPFS) Init(ctx context.Context, config config.Section) (err error) {
 	if err != nil {
 		return err
 	}
+	tracing.InstrumentClient(i.gwClient)
 	i.capabilities = &sharedstorage.Capabilities{}
 	return nil
 }
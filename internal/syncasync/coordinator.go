@@ -0,0 +1,209 @@
+// Copyright © 2024 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package syncasync
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/hyperledger/firefly-common/pkg/config"
+	"github.com/hyperledger/firefly-common/pkg/fftypes"
+	"github.com/hyperledger/firefly-common/pkg/i18n"
+	"github.com/hyperledger/firefly-common/pkg/log"
+	"github.com/hyperledger/firefly/internal/coreconfig"
+	"github.com/hyperledger/firefly/internal/coremsgs"
+	"github.com/hyperledger/firefly/pkg/core"
+	"github.com/hyperledger/firefly/pkg/database"
+)
+
+const (
+	// CoordinatorTypeLocal only resolves requests from events processed by this replica
+	CoordinatorTypeLocal = "local"
+	// CoordinatorTypeDatabase shares requests between all replicas connected to the same database
+	CoordinatorTypeDatabase = "database"
+)
+
+// Coordinator shares the in-flight requests of the sync/async bridge between multiple FireFly core
+// replicas, so that a request can be resolved regardless of which replica processes the confirming event.
+type Coordinator interface {
+	// Start begins delivering messages published by other replicas to the callback, until the context is cancelled
+	Start(callback CoordinatorCallback) error
+
+	// Publish sends a message to all the other replicas, with this replica set as the origin
+	Publish(ctx context.Context, msg *CoordinatorMessage) error
+
+	// Sync delivers any messages already persisted by other replicas that have not yet been delivered to
+	// the callback, before returning
+	Sync() error
+}
+
+type CoordinatorCallback func(msg *CoordinatorMessage)
+
+type CoordinatorMessageType string
+
+const (
+	// CoordinatorRequestAdded is published when a replica starts waiting for a request
+	CoordinatorRequestAdded CoordinatorMessageType = "request_added"
+	// CoordinatorRequestRemoved is published when a replica stops waiting for a request
+	CoordinatorRequestRemoved CoordinatorMessageType = "request_removed"
+	// CoordinatorEventForwarded is published when a replica processes an event for a request added by another replica
+	CoordinatorEventForwarded CoordinatorMessageType = "event_forwarded"
+)
+
+// CoordinatorMessage is the payload exchanged between replicas
+type CoordinatorMessage struct {
+	Type    CoordinatorMessageType `json:"type"`
+	Origin  *fftypes.UUID          `json:"origin"`
+	Target  *fftypes.UUID          `json:"target,omitempty"`
+	Request *fftypes.UUID          `json:"request,omitempty"`
+	Event   *core.Event            `json:"event,omitempty"`
+}
+
+const (
+	coordinatorTopic     = "syncasync"
+	coordinatorReadLimit = 100
+)
+
+type dbCoordinator struct {
+	ctx          context.Context
+	namespace    string
+	replicaID    *fftypes.UUID
+	database     database.Plugin
+	pollInterval time.Duration
+	retention    time.Duration
+	lastSequence atomic.Int64
+	lastPrune    time.Time
+	callback     CoordinatorCallback
+	readMux      sync.Mutex
+}
+
+func newCoordinator(ctx context.Context, ns string, di database.Plugin) (Coordinator, error) {
+	coordinatorType := config.GetString(coreconfig.SyncAsyncCoordinatorType)
+	switch coordinatorType {
+	case CoordinatorTypeLocal:
+		return nil, nil
+	case CoordinatorTypeDatabase:
+		return &dbCoordinator{
+			ctx:          ctx,
+			namespace:    ns,
+			replicaID:    fftypes.NewUUID(),
+			database:     di,
+			pollInterval: config.GetDuration(coreconfig.SyncAsyncCoordinatorPollInterval),
+			retention:    config.GetDuration(coreconfig.SyncAsyncCoordinatorRetention),
+			lastPrune:    time.Now(),
+		}, nil
+	default:
+		return nil, i18n.NewError(ctx, coremsgs.MsgUnknownSyncAsyncCoordinator, coordinatorType)
+	}
+}
+
+func (dc *dbCoordinator) Publish(ctx context.Context, msg *CoordinatorMessage) error {
+	msg.Origin = dc.replicaID
+	payload, _ := json.Marshal(msg)
+	return dc.database.InsertClusterNotification(ctx, &core.ClusterNotification{
+		Namespace: dc.namespace,
+		Topic:     coordinatorTopic,
+		Payload:   fftypes.JSONAnyPtrBytes(payload),
+	})
+}
+
+func (dc *dbCoordinator) Start(callback CoordinatorCallback) error {
+	// We only need to process notifications published after we started
+	fb := database.ClusterNotificationQueryFactory.NewFilterLimit(dc.ctx, 1)
+	latest, _, err := dc.database.GetClusterNotifications(dc.ctx, dc.namespace, fb.And(
+		fb.Eq("topic", coordinatorTopic),
+	).Sort("sequence").Descending())
+	if err != nil {
+		return err
+	}
+	if len(latest) > 0 {
+		dc.lastSequence.Store(latest[0].Sequence)
+	}
+
+	notify, err := dc.database.ListenClusterNotifications(dc.ctx, dc.namespace, coordinatorTopic)
+	if err != nil {
+		return err
+	}
+	dc.callback = callback
+	go dc.notificationLoop(notify, callback)
+	return nil
+}
+
+func (dc *dbCoordinator) Sync() error {
+	if dc.callback == nil {
+		return nil
+	}
+	return dc.readNotifications(dc.callback)
+}
+
+func (dc *dbCoordinator) notificationLoop(notify <-chan *core.ClusterNotification, callback CoordinatorCallback) {
+	l := log.L(dc.ctx)
+	for {
+		if err := dc.readNotifications(callback); err != nil {
+			l.Errorf("Failed to read sync/async notifications from other replicas: %s", err)
+		}
+		if time.Since(dc.lastPrune) > dc.retention {
+			dc.lastPrune = time.Now()
+			expiry := fftypes.FFTime(dc.lastPrune.Add(-dc.retention))
+			if err := dc.database.DeleteClusterNotifications(dc.ctx, dc.namespace, coordinatorTopic, &expiry); err != nil {
+				l.Errorf("Failed to delete expired sync/async notifications: %s", err)
+			}
+		}
+		select {
+		case <-notify:
+		case <-time.After(dc.pollInterval):
+		case <-dc.ctx.Done():
+			l.Debugf("Sync/async coordinator exiting")
+			return
+		}
+	}
+}
+
+func (dc *dbCoordinator) readNotifications(callback CoordinatorCallback) error {
+	// Notifications are read both by the loop and on demand, and must be delivered exactly once and in order
+	dc.readMux.Lock()
+	defer dc.readMux.Unlock()
+
+	for {
+		fb := database.ClusterNotificationQueryFactory.NewFilterLimit(dc.ctx, coordinatorReadLimit)
+		notifications, _, err := dc.database.GetClusterNotifications(dc.ctx, dc.namespace, fb.And(
+			fb.Eq("topic", coordinatorTopic),
+			fb.Gt("sequence", dc.lastSequence.Load()),
+		).Sort("sequence"))
+		if err != nil || len(notifications) == 0 {
+			return err
+		}
+		for _, n := range notifications {
+			dc.lastSequence.Store(n.Sequence)
+			var msg CoordinatorMessage
+			if err := json.Unmarshal(n.Payload.Bytes(), &msg); err != nil {
+				log.L(dc.ctx).Errorf("Invalid sync/async notification %d: %s", n.Sequence, err)
+				continue
+			}
+			if msg.Origin.Equals(dc.replicaID) || (msg.Target != nil && !msg.Target.Equals(dc.replicaID)) {
+				continue
+			}
+			callback(&msg)
+		}
+		if len(notifications) < coordinatorReadLimit {
+			return nil
+		}
+	}
+}
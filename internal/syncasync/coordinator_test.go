@@ -0,0 +1,230 @@
+// Copyright © 2024 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package syncasync
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/firefly-common/pkg/config"
+	"github.com/hyperledger/firefly-common/pkg/fftypes"
+	"github.com/hyperledger/firefly/internal/coreconfig"
+	"github.com/hyperledger/firefly/mocks/databasemocks"
+	"github.com/hyperledger/firefly/pkg/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func newTestDBCoordinator(t *testing.T) (*dbCoordinator, *databasemocks.Plugin, func()) {
+	coreconfig.Reset()
+	config.Set(coreconfig.SyncAsyncCoordinatorType, CoordinatorTypeDatabase)
+	ctx, cancel := context.WithCancel(context.Background())
+	mdi := &databasemocks.Plugin{}
+	c, err := newCoordinator(ctx, "ns1", mdi)
+	assert.NoError(t, err)
+	return c.(*dbCoordinator), mdi, func() {
+		cancel()
+		mdi.AssertExpectations(t)
+	}
+}
+
+func testNotification(seq int64, msg *CoordinatorMessage) *core.ClusterNotification {
+	b, _ := json.Marshal(msg)
+	return &core.ClusterNotification{
+		Sequence:  seq,
+		Namespace: "ns1",
+		Topic:     coordinatorTopic,
+		Payload:   fftypes.JSONAnyPtrBytes(b),
+	}
+}
+
+func TestNewCoordinatorLocal(t *testing.T) {
+	coreconfig.Reset()
+	c, err := newCoordinator(context.Background(), "ns1", &databasemocks.Plugin{})
+	assert.NoError(t, err)
+	assert.Nil(t, c)
+}
+
+func TestNewCoordinatorUnknown(t *testing.T) {
+	coreconfig.Reset()
+	config.Set(coreconfig.SyncAsyncCoordinatorType, "wrong")
+	_, err := newCoordinator(context.Background(), "ns1", &databasemocks.Plugin{})
+	assert.Regexp(t, "FF10478.*wrong", err)
+}
+
+func TestDBCoordinatorPublish(t *testing.T) {
+	dc, mdi, done := newTestDBCoordinator(t)
+	defer done()
+
+	reqID := fftypes.NewUUID()
+	mdi.On("InsertClusterNotification", mock.Anything, mock.MatchedBy(func(n *core.ClusterNotification) bool {
+		var msg CoordinatorMessage
+		err := json.Unmarshal(n.Payload.Bytes(), &msg)
+		assert.NoError(t, err)
+		return n.Namespace == "ns1" && n.Topic == coordinatorTopic &&
+			msg.Type == CoordinatorRequestAdded && msg.Request.Equals(reqID) && msg.Origin.Equals(dc.replicaID)
+	})).Return(nil)
+
+	err := dc.Publish(context.Background(), &CoordinatorMessage{Type: CoordinatorRequestAdded, Request: reqID})
+	assert.NoError(t, err)
+}
+
+func TestDBCoordinatorStartQueryFail(t *testing.T) {
+	dc, mdi, done := newTestDBCoordinator(t)
+	defer done()
+
+	mdi.On("GetClusterNotifications", dc.ctx, "ns1", mock.Anything).Return(nil, nil, fmt.Errorf("pop"))
+
+	err := dc.Start(func(msg *CoordinatorMessage) {})
+	assert.Regexp(t, "pop", err)
+}
+
+func TestDBCoordinatorStartListenFail(t *testing.T) {
+	dc, mdi, done := newTestDBCoordinator(t)
+	defer done()
+
+	mdi.On("GetClusterNotifications", dc.ctx, "ns1", mock.Anything).Return([]*core.ClusterNotification{}, nil, nil)
+	mdi.On("ListenClusterNotifications", dc.ctx, "ns1", coordinatorTopic).Return(nil, fmt.Errorf("pop"))
+
+	err := dc.Start(func(msg *CoordinatorMessage) {})
+	assert.Regexp(t, "pop", err)
+}
+
+func TestDBCoordinatorDeliverNotifications(t *testing.T) {
+	dc, mdi, done := newTestDBCoordinator(t)
+	defer done()
+	dc.retention = 0 // prune every loop
+	dc.pollInterval = 1 * time.Hour
+
+	otherReplica := fftypes.NewUUID()
+	reqID := fftypes.NewUUID()
+	notify := make(chan *core.ClusterNotification, 1)
+
+	mdi.On("GetClusterNotifications", dc.ctx, "ns1", mock.Anything).Return([]*core.ClusterNotification{
+		{Sequence: 1000},
+	}, nil, nil).Once()
+	mdi.On("ListenClusterNotifications", dc.ctx, "ns1", coordinatorTopic).Return((<-chan *core.ClusterNotification)(notify), nil)
+	mdi.On("GetClusterNotifications", dc.ctx, "ns1", mock.Anything).Return([]*core.ClusterNotification{}, nil, nil).Once()
+	mdi.On("GetClusterNotifications", dc.ctx, "ns1", mock.Anything).Return([]*core.ClusterNotification{
+		testNotification(1001, &CoordinatorMessage{Type: CoordinatorRequestAdded, Origin: dc.replicaID, Request: reqID}),
+		testNotification(1002, &CoordinatorMessage{Type: CoordinatorEventForwarded, Origin: otherReplica, Target: fftypes.NewUUID()}),
+		{Sequence: 1003, Payload: fftypes.JSONAnyPtr("!json")},
+		testNotification(1004, &CoordinatorMessage{Type: CoordinatorRequestAdded, Origin: otherReplica, Request: reqID}),
+	}, nil, nil).Once()
+	mdi.On("DeleteClusterNotifications", dc.ctx, "ns1", coordinatorTopic, mock.Anything).Return(fmt.Errorf("pop"))
+
+	received := make(chan *CoordinatorMessage)
+	err := dc.Start(func(msg *CoordinatorMessage) {
+		received <- msg
+	})
+	assert.NoError(t, err)
+
+	notify <- nil
+	msg := <-received
+	assert.Equal(t, CoordinatorRequestAdded, msg.Type)
+	assert.Equal(t, otherReplica, msg.Origin)
+	assert.Equal(t, reqID, msg.Request)
+	assert.Equal(t, int64(1004), dc.lastSequence.Load())
+}
+
+func TestDBCoordinatorReadMultiplePages(t *testing.T) {
+	dc, mdi, done := newTestDBCoordinator(t)
+	defer done()
+
+	page := make([]*core.ClusterNotification, coordinatorReadLimit)
+	for i := range page {
+		page[i] = testNotification(int64(i+1), &CoordinatorMessage{Type: CoordinatorRequestRemoved, Origin: dc.replicaID})
+	}
+	mdi.On("GetClusterNotifications", dc.ctx, "ns1", mock.Anything).Return(page, nil, nil).Once()
+	mdi.On("GetClusterNotifications", dc.ctx, "ns1", mock.Anything).Return([]*core.ClusterNotification{}, nil, nil).Once()
+
+	err := dc.readNotifications(func(msg *CoordinatorMessage) {
+		assert.Fail(t, "should not be called for our own messages")
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(coordinatorReadLimit), dc.lastSequence.Load())
+}
+
+func TestDBCoordinatorLoopExit(t *testing.T) {
+	dc, mdi, done := newTestDBCoordinator(t)
+	dc.lastPrune = time.Now()
+	done()
+
+	mdi.On("GetClusterNotifications", dc.ctx, "ns1", mock.Anything).Return(nil, nil, fmt.Errorf("pop"))
+	dc.notificationLoop(nil, func(msg *CoordinatorMessage) {})
+}
+
+func TestDBCoordinatorSyncBeforeStart(t *testing.T) {
+	dc, _, done := newTestDBCoordinator(t)
+	defer done()
+
+	err := dc.Sync()
+	assert.NoError(t, err)
+}
+
+func TestDBCoordinatorSync(t *testing.T) {
+	dc, mdi, done := newTestDBCoordinator(t)
+	defer done()
+
+	otherReplica := fftypes.NewUUID()
+	reqID := fftypes.NewUUID()
+	dc.lastSequence.Store(1000)
+
+	var received []*CoordinatorMessage
+	dc.callback = func(msg *CoordinatorMessage) {
+		received = append(received, msg)
+	}
+	mdi.On("GetClusterNotifications", dc.ctx, "ns1", mock.Anything).Return([]*core.ClusterNotification{
+		testNotification(1001, &CoordinatorMessage{Type: CoordinatorRequestAdded, Origin: otherReplica, Request: reqID}),
+	}, nil, nil).Once()
+
+	err := dc.Sync()
+	assert.NoError(t, err)
+	assert.Len(t, received, 1)
+	assert.Equal(t, reqID, received[0].Request)
+	assert.Equal(t, int64(1001), dc.lastSequence.Load())
+}
+
+func TestDBCoordinatorSyncNotificationAlreadyConsumed(t *testing.T) {
+	dc, mdi, done := newTestDBCoordinator(t)
+	defer done()
+
+	otherReplica := fftypes.NewUUID()
+	reqID := fftypes.NewUUID()
+	dc.lastSequence.Store(1000)
+
+	var received []*CoordinatorMessage
+	dc.callback = func(msg *CoordinatorMessage) {
+		received = append(received, msg)
+	}
+
+	// The loop has taken the notification from the channel, but has not yet read the database
+	notify := make(chan *core.ClusterNotification, 1)
+	notify <- nil
+	<-notify
+	mdi.On("GetClusterNotifications", dc.ctx, "ns1", mock.Anything).Return([]*core.ClusterNotification{
+		testNotification(1001, &CoordinatorMessage{Type: CoordinatorRequestAdded, Origin: otherReplica, Request: reqID}),
+	}, nil, nil).Once()
+
+	err := dc.Sync()
+	assert.NoError(t, err)
+	assert.Len(t, received, 1)
+	assert.Equal(t, reqID, received[0].Request)
+}
@@ -23,9 +23,11 @@ import (
 	"sync"
 	"time"
 
+	"github.com/hyperledger/firefly-common/pkg/config"
 	"github.com/hyperledger/firefly-common/pkg/fftypes"
 	"github.com/hyperledger/firefly-common/pkg/i18n"
 	"github.com/hyperledger/firefly-common/pkg/log"
+	"github.com/hyperledger/firefly/internal/coreconfig"
 	"github.com/hyperledger/firefly/internal/coremsgs"
 	"github.com/hyperledger/firefly/internal/data"
 	"github.com/hyperledger/firefly/internal/events/system"
@@ -38,7 +40,7 @@ import (
 // message and blocking until a correlating response is received, or we hit a timeout.
 type Bridge interface {
 	// Init is required as there's a bi-directional relationship between event manager and syncasync bridge
-	Init(sysevents system.EventInterface)
+	Init(sysevents system.EventInterface) error
 
 	// The following "WaitFor*" methods all wait for a particular type of event callback, and block until it is received.
 	// To use them, invoke the appropriate method, and pass a "send" callback that is expected to trigger the relevant event.
@@ -98,38 +100,96 @@ type inflightResponse struct {
 
 type inflightRequestMap map[string]map[fftypes.UUID]*inflightRequest
 
-type syncAsyncBridge struct {
-	ctx         context.Context
-	namespace   string
-	database    database.Plugin
-	data        data.Manager
-	operations  operations.Manager
-	sysevents   system.EventInterface
-	inflightMux sync.Mutex
-	inflight    inflightRequestMap
+// remoteRequest is a request that is in-flight on another replica, which we might be able to resolve
+type remoteRequest struct {
+	origin    *fftypes.UUID
+	startTime time.Time
+}
+
+type remoteRequestMap map[string]map[fftypes.UUID]*remoteRequest
+
+// resolvingEventTypes are the event types that can resolve in-flight requests
+var resolvingEventTypes = map[core.EventType]bool{
+	core.EventTypeMessageConfirmed:                    true,
+	core.EventTypeMessageRejected:                     true,
+	core.EventTypeIdentityConfirmed:                   true,
+	core.EventTypePoolConfirmed:                       true,
+	core.EventTypePoolOpFailed:                        true,
+	core.EventTypeTransferConfirmed:                   true,
+	core.EventTypeTransferOpFailed:                    true,
+	core.EventTypeApprovalConfirmed:                   true,
+	core.EventTypeApprovalOpFailed:                    true,
+	core.EventTypeBlockchainInvokeOpSucceeded:         true,
+	core.EventTypeBlockchainInvokeOpFailed:            true,
+	core.EventTypeBlockchainContractDeployOpSucceeded: true,
+	core.EventTypeBlockchainContractDeployOpFailed:    true,
 }
 
-func NewSyncAsyncBridge(ctx context.Context, ns string, di database.Plugin, dm data.Manager, om operations.Manager) Bridge {
+type syncAsyncBridge struct {
+	ctx          context.Context
+	namespace    string
+	database     database.Plugin
+	data         data.Manager
+	operations   operations.Manager
+	sysevents    system.EventInterface
+	coordinator  Coordinator
+	remoteExpiry time.Duration
+	inflightMux  sync.Mutex
+	inflight     inflightRequestMap
+	remote       remoteRequestMap
+}
+
+func NewSyncAsyncBridge(ctx context.Context, ns string, di database.Plugin, dm data.Manager, om operations.Manager) (Bridge, error) {
 	sa := &syncAsyncBridge{
-		ctx:        log.WithLogField(ctx, "role", "sync-async-bridge"),
-		namespace:  ns,
-		database:   di,
-		data:       dm,
-		operations: om,
-		inflight:   make(inflightRequestMap),
+		// Events are resolved as soon as they are written, so the reads must not go to a read replica that is behind
+		ctx:          database.WithPrimaryReads(log.WithLogField(ctx, "role", "sync-async-bridge")),
+		namespace:    ns,
+		database:     di,
+		data:         dm,
+		operations:   om,
+		remoteExpiry: config.GetDuration(coreconfig.SyncAsyncCoordinatorRetention),
+		inflight:     make(inflightRequestMap),
+		remote:       make(remoteRequestMap),
+	}
+	var err error
+	if sa.coordinator, err = newCoordinator(sa.ctx, ns, di); err != nil {
+		return nil, err
 	}
-	return sa
+	return sa, nil
 }
 
-func (sa *syncAsyncBridge) Init(sysevents system.EventInterface) {
+func (sa *syncAsyncBridge) Init(sysevents system.EventInterface) error {
 	sa.sysevents = sysevents
+	if sa.coordinator != nil {
+		// Listen for events from the start, as any of them might resolve a request added by another replica
+		sa.inflightMux.Lock()
+		err := sa.initNamespace(sa.namespace)
+		sa.inflightMux.Unlock()
+		if err != nil {
+			return err
+		}
+		return sa.coordinator.Start(sa.coordinatorCallback)
+	}
+	return nil
+}
+
+// initNamespace must be called with the inflightMux held
+func (sa *syncAsyncBridge) initNamespace(ns string) error {
+	if sa.inflight[ns] == nil {
+		err := sa.sysevents.AddSystemEventListener(ns, sa.eventCallback)
+		if err != nil {
+			return err
+		}
+		sa.inflight[ns] = make(map[fftypes.UUID]*inflightRequest)
+	}
+	return nil
 }
 
 func (sa *syncAsyncBridge) addInFlight(ns string, id *fftypes.UUID, reqType requestType) (*inflightRequest, error) {
 	inflight := &inflightRequest{
 		id:        id,
 		startTime: time.Now(),
-		response:  make(chan inflightResponse),
+		response:  make(chan inflightResponse, 1),
 		reqType:   reqType,
 	}
 	sa.inflightMux.Lock()
@@ -137,19 +197,27 @@ func (sa *syncAsyncBridge) addInFlight(ns string, id *fftypes.UUID, reqType requ
 		sa.inflightMux.Unlock()
 	}()
 
-	inflightNS := sa.inflight[ns]
-	if inflightNS == nil {
-		err := sa.sysevents.AddSystemEventListener(ns, sa.eventCallback)
-		if err != nil {
-			return nil, err
-		}
-		inflightNS = make(map[fftypes.UUID]*inflightRequest)
-		sa.inflight[ns] = inflightNS
+	if err := sa.initNamespace(ns); err != nil {
+		return nil, err
 	}
-	inflightNS[*inflight.id] = inflight
+	sa.inflight[ns][*inflight.id] = inflight
 	return inflight, nil
 }
 
+// hasInFlight checks whether an event references a request added by this replica
+func (sa *syncAsyncBridge) hasInFlight(event *core.EventDelivery) bool {
+	sa.inflightMux.Lock()
+	defer sa.inflightMux.Unlock()
+
+	inflightNS := sa.inflight[event.Namespace]
+	for _, id := range []*fftypes.UUID{event.Reference, event.Correlator} {
+		if id != nil && inflightNS[*id] != nil {
+			return true
+		}
+	}
+	return false
+}
+
 func (sa *syncAsyncBridge) getInFlight(ns string, reqType requestType, id *fftypes.UUID) *inflightRequest {
 	if id == nil {
 		return nil
@@ -180,6 +248,130 @@ func (inflight *inflightRequest) msInflight() float64 {
 	return float64(dur) / float64(time.Millisecond)
 }
 
+// resolve delivers the first response for a request - any subsequent responses are discarded, as the
+// same event might be processed both locally and by another replica
+func (inflight *inflightRequest) resolve(response inflightResponse) {
+	select {
+	case inflight.response <- response:
+	default:
+	}
+}
+
+func (sa *syncAsyncBridge) publish(ctx context.Context, msgType CoordinatorMessageType, id *fftypes.UUID) error {
+	if sa.coordinator == nil {
+		return nil
+	}
+	return sa.coordinator.Publish(ctx, &CoordinatorMessage{
+		Type:    msgType,
+		Request: id,
+	})
+}
+
+func (sa *syncAsyncBridge) coordinatorCallback(msg *CoordinatorMessage) {
+	switch msg.Type {
+	case CoordinatorRequestAdded:
+		sa.addRemote(msg.Origin, msg.Request)
+	case CoordinatorRequestRemoved:
+		sa.removeRemote(msg.Request)
+	case CoordinatorEventForwarded:
+		if msg.Event != nil {
+			log.L(sa.ctx).Debugf("Processing %s event '%s' forwarded by replica '%s'", msg.Event.Type, msg.Event.ID, msg.Origin)
+			// Forwarded events are only resolved locally - they were already forwarded by the replica that processed them
+			if err := sa.resolveInFlight(&core.EventDelivery{EnrichedEvent: core.EnrichedEvent{Event: *msg.Event}}); err != nil {
+				log.L(sa.ctx).Errorf("Failed to process forwarded event '%s': %s", msg.Event.ID, err)
+			}
+		}
+	}
+}
+
+func (sa *syncAsyncBridge) addRemote(origin, id *fftypes.UUID) {
+	if id == nil {
+		return
+	}
+	sa.inflightMux.Lock()
+	defer sa.inflightMux.Unlock()
+
+	// We need to listen for events in the namespace, to resolve requests on behalf of other replicas
+	if err := sa.initNamespace(sa.namespace); err != nil {
+		log.L(sa.ctx).Errorf("Failed to track request '%s' from replica '%s': %s", id, origin, err)
+		return
+	}
+
+	remoteNS := sa.remote[sa.namespace]
+	if remoteNS == nil {
+		remoteNS = make(map[fftypes.UUID]*remoteRequest)
+		sa.remote[sa.namespace] = remoteNS
+	}
+	// Clean up anything left behind by a replica that did not remove its requests (such as after a crash)
+	for remoteID, remote := range remoteNS {
+		if time.Since(remote.startTime) > sa.remoteExpiry {
+			delete(remoteNS, remoteID)
+		}
+	}
+	remoteNS[*id] = &remoteRequest{origin: origin, startTime: time.Now()}
+}
+
+func (sa *syncAsyncBridge) removeRemote(id *fftypes.UUID) {
+	if id == nil {
+		return
+	}
+	sa.inflightMux.Lock()
+	defer sa.inflightMux.Unlock()
+	if remoteNS := sa.remote[sa.namespace]; remoteNS != nil {
+		delete(remoteNS, *id)
+	}
+}
+
+// remoteForwards returns the messages required to forward an event to the replicas that are waiting for it.
+// They are published after the inflightMux is released, so sync requests are not held up by the database.
+func (sa *syncAsyncBridge) remoteForwards(event *core.EventDelivery) []*CoordinatorMessage {
+	sa.inflightMux.Lock()
+	defer sa.inflightMux.Unlock()
+
+	remoteNS := sa.remote[event.Namespace]
+	if len(remoteNS) == 0 || !resolvingEventTypes[event.Type] {
+		return nil
+	}
+	var forwards []*CoordinatorMessage
+	for _, id := range []*fftypes.UUID{event.Reference, event.Correlator} {
+		if id == nil {
+			continue
+		}
+		if remote := remoteNS[*id]; remote != nil {
+			log.L(sa.ctx).Debugf("Forwarding %s event '%s' to replica '%s' for request '%s'", event.Type, event.ID, remote.origin, id)
+			forwards = append(forwards, &CoordinatorMessage{
+				Type:    CoordinatorEventForwarded,
+				Target:  remote.origin,
+				Request: id,
+				Event:   &event.Event,
+			})
+		}
+	}
+	return forwards
+}
+
+func (sa *syncAsyncBridge) forwardRemote(event *core.EventDelivery) error {
+	if sa.coordinator == nil || !resolvingEventTypes[event.Type] || (event.Reference == nil && event.Correlator == nil) {
+		return nil
+	}
+	forwards := sa.remoteForwards(event)
+	if len(forwards) == 0 && !sa.hasInFlight(event) {
+		// Another replica persists each request before sending it, but we might not have read the notification yet,
+		// so we catch up with the persisted requests before deciding there is nothing to forward. This is a single
+		// indexed query for the notifications after the last one we read.
+		if err := sa.coordinator.Sync(); err != nil {
+			return err
+		}
+		forwards = sa.remoteForwards(event)
+	}
+	for _, msg := range forwards {
+		if err := sa.coordinator.Publish(sa.ctx, msg); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func (sa *syncAsyncBridge) getMessageFromEvent(event *core.EventDelivery) (msg *core.Message, err error) {
 	if msg, err = sa.database.GetMessageByID(sa.ctx, sa.namespace, event.Reference); err != nil {
 		return nil, err
@@ -505,6 +697,13 @@ func (sa *syncAsyncBridge) handleOperationFailedEvent(event *core.EventDelivery)
 }
 
 func (sa *syncAsyncBridge) eventCallback(event *core.EventDelivery) error {
+	if err := sa.forwardRemote(event); err != nil {
+		return err
+	}
+	return sa.resolveInFlight(event)
+}
+
+func (sa *syncAsyncBridge) resolveInFlight(event *core.EventDelivery) error {
 	sa.inflightMux.Lock()
 	defer sa.inflightMux.Unlock()
 
@@ -568,54 +767,54 @@ func (sa *syncAsyncBridge) resolveReply(inflight *inflightRequest, msg *core.Mes
 		return
 	}
 	response.SetInlineData(data)
-	inflight.response <- inflightResponse{id: msg.Header.ID, data: response}
+	inflight.resolve(inflightResponse{id: msg.Header.ID, data: response})
 }
 
 func (sa *syncAsyncBridge) resolveConfirmed(inflight *inflightRequest, msg *core.Message) {
 	log.L(sa.ctx).Debugf("Resolving message confirmation request '%s' with ID '%s'", inflight.id, msg.Header.ID)
-	inflight.response <- inflightResponse{id: msg.Header.ID, data: msg}
+	inflight.resolve(inflightResponse{id: msg.Header.ID, data: msg})
 }
 
 func (sa *syncAsyncBridge) resolveRejected(inflight *inflightRequest, msgID *fftypes.UUID) {
 	err := i18n.NewError(sa.ctx, coremsgs.MsgRejected, msgID)
 	log.L(sa.ctx).Errorf("Resolving message confirmation request '%s' with error: %s", inflight.id, err)
-	inflight.response <- inflightResponse{err: err}
+	inflight.resolve(inflightResponse{err: err})
 }
 
 func (sa *syncAsyncBridge) resolveIdentity(inflight *inflightRequest, identity *core.Identity) {
 	log.L(sa.ctx).Debugf("Resolving identity creation '%s' with ID '%s'", inflight.id, identity.ID)
-	inflight.response <- inflightResponse{id: identity.ID, data: identity}
+	inflight.resolve(inflightResponse{id: identity.ID, data: identity})
 }
 
 func (sa *syncAsyncBridge) resolveConfirmedTokenPool(inflight *inflightRequest, pool *core.TokenPool) {
 	log.L(sa.ctx).Debugf("Resolving token pool confirmation request '%s' with ID '%s'", inflight.id, pool.ID)
-	inflight.response <- inflightResponse{id: pool.ID, data: pool}
+	inflight.resolve(inflightResponse{id: pool.ID, data: pool})
 }
 
 func (sa *syncAsyncBridge) resolveRejectedTokenPool(inflight *inflightRequest, poolID *fftypes.UUID) {
 	err := i18n.NewError(sa.ctx, coremsgs.MsgTokenPoolRejected, poolID)
 	log.L(sa.ctx).Errorf("Resolving token pool confirmation request '%s' with error '%s'", inflight.id, err)
-	inflight.response <- inflightResponse{err: err}
+	inflight.resolve(inflightResponse{err: err})
 }
 
 func (sa *syncAsyncBridge) resolveConfirmedTokenTransfer(inflight *inflightRequest, transfer *core.TokenTransfer) {
 	log.L(sa.ctx).Debugf("Resolving token transfer confirmation request '%s' with ID '%s'", inflight.id, transfer.LocalID)
-	inflight.response <- inflightResponse{id: transfer.LocalID, data: transfer}
+	inflight.resolve(inflightResponse{id: transfer.LocalID, data: transfer})
 }
 
 func (sa *syncAsyncBridge) resolveConfirmedTokenApproval(inflight *inflightRequest, approval *core.TokenApproval) {
 	log.L(sa.ctx).Debugf("Resolving token approval confirmation request '%s' with ID '%s'", inflight.id, approval.LocalID)
-	inflight.response <- inflightResponse{id: approval.LocalID, data: approval}
+	inflight.resolve(inflightResponse{id: approval.LocalID, data: approval})
 }
 
 func (sa *syncAsyncBridge) resolveSuccessfulOperation(inflight *inflightRequest, typeName string, op *core.Operation) {
 	log.L(sa.ctx).Debugf("Resolving %s request '%s' with ID '%s'", typeName, inflight.id, op.ID)
-	inflight.response <- inflightResponse{id: op.ID, data: op}
+	inflight.resolve(inflightResponse{id: op.ID, data: op})
 }
 
 func (sa *syncAsyncBridge) resolveFailedOperation(inflight *inflightRequest, typeName string, op *core.Operation) {
 	log.L(sa.ctx).Debugf("Resolving %s request '%s' with error '%s'", typeName, inflight.id, op.Error)
-	inflight.response <- inflightResponse{err: fmt.Errorf(op.Error)}
+	inflight.resolve(inflightResponse{err: fmt.Errorf(op.Error)})
 }
 
 func (sa *syncAsyncBridge) sendAndWait(ctx context.Context, ns string, id *fftypes.UUID, reqType requestType, send SendFunction) (interface{}, error) {
@@ -627,6 +826,9 @@ func (sa *syncAsyncBridge) sendAndWait(ctx context.Context, ns string, id *fftyp
 	var replyID *fftypes.UUID
 	defer func() {
 		sa.removeInFlight(ns, inflight.id)
+		if err := sa.publish(sa.ctx, CoordinatorRequestRemoved, inflight.id); err != nil {
+			log.L(sa.ctx).Warnf("Failed to notify other replicas that request '%s' was removed: %s", inflight.id, err)
+		}
 		if replyID != nil {
 			log.L(sa.ctx).Infof("Inflight request '%s' resolved with reply '%s' after %.2fms", inflight.id, replyID, inflight.msInflight())
 		} else {
@@ -634,6 +836,12 @@ func (sa *syncAsyncBridge) sendAndWait(ctx context.Context, ns string, id *fftyp
 		}
 	}()
 
+	// The request is persisted for other replicas before we send, so any replica that processes the
+	// confirmation can find it - even if it has not yet been notified
+	if err = sa.publish(ctx, CoordinatorRequestAdded, inflight.id); err != nil {
+		return nil, err
+	}
+
 	err = send(ctx)
 	if err != nil {
 		return nil, err
@@ -21,25 +21,32 @@ import (
 	"encoding/json"
 	"fmt"
 	"testing"
+	"time"
 
+	"github.com/hyperledger/firefly-common/pkg/config"
 	"github.com/hyperledger/firefly-common/pkg/fftypes"
+	"github.com/hyperledger/firefly/internal/coreconfig"
 	"github.com/hyperledger/firefly/mocks/databasemocks"
 	"github.com/hyperledger/firefly/mocks/datamocks"
 	"github.com/hyperledger/firefly/mocks/operationmocks"
 	"github.com/hyperledger/firefly/mocks/systemeventmocks"
 	"github.com/hyperledger/firefly/pkg/core"
+	"github.com/hyperledger/firefly/pkg/database"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 )
 
 func newTestSyncAsyncBridge(t *testing.T) (*syncAsyncBridge, func()) {
+	coreconfig.Reset()
 	ctx, cancel := context.WithCancel(context.Background())
 	mdi := &databasemocks.Plugin{}
 	mdm := &datamocks.Manager{}
 	mom := &operationmocks.Manager{}
 	mse := &systemeventmocks.EventInterface{}
-	sa := NewSyncAsyncBridge(ctx, "ns1", mdi, mdm, mom)
-	sa.Init(mse)
+	sa, err := NewSyncAsyncBridge(ctx, "ns1", mdi, mdm, mom)
+	assert.NoError(t, err)
+	err = sa.Init(mse)
+	assert.NoError(t, err)
 	return sa.(*syncAsyncBridge), cancel
 }
 
@@ -1700,3 +1707,376 @@ func TestAwaitInvokeOpFailedLookupFail(t *testing.T) {
 	})
 	assert.EqualError(t, err, "pop")
 }
+
+// mockCoordinator is declared here, as a generated mock in syncasyncmocks would be an import cycle
+type mockCoordinator struct {
+	mock.Mock
+}
+
+func (mc *mockCoordinator) Start(callback CoordinatorCallback) error {
+	return mc.Called(callback).Error(0)
+}
+
+func (mc *mockCoordinator) Publish(ctx context.Context, msg *CoordinatorMessage) error {
+	return mc.Called(ctx, msg).Error(0)
+}
+
+func (mc *mockCoordinator) Sync() error {
+	return mc.Called().Error(0)
+}
+
+func TestNewSyncAsyncBridgeBadCoordinator(t *testing.T) {
+	coreconfig.Reset()
+	config.Set(coreconfig.SyncAsyncCoordinatorType, "wrong")
+	_, err := NewSyncAsyncBridge(context.Background(), "ns1", &databasemocks.Plugin{}, &datamocks.Manager{}, &operationmocks.Manager{})
+	assert.Regexp(t, "FF10478", err)
+}
+
+func TestNewSyncAsyncBridgePrimaryReads(t *testing.T) {
+	coreconfig.Reset()
+	ctx := database.WithReplicaReads(context.Background())
+	sa, err := NewSyncAsyncBridge(ctx, "ns1", &databasemocks.Plugin{}, &datamocks.Manager{}, &operationmocks.Manager{})
+	assert.NoError(t, err)
+	assert.False(t, database.ReplicaReadsAllowed(sa.(*syncAsyncBridge).ctx))
+}
+
+func TestInitStartsCoordinator(t *testing.T) {
+	sa, cancel := newTestSyncAsyncBridge(t)
+	defer cancel()
+
+	mse := sa.sysevents.(*systemeventmocks.EventInterface)
+	mse.On("AddSystemEventListener", "ns1", mock.Anything).Return(nil)
+
+	mcd := &mockCoordinator{}
+	mcd.On("Start", mock.Anything).Return(fmt.Errorf("pop"))
+	sa.coordinator = mcd
+
+	err := sa.Init(sa.sysevents)
+	assert.Regexp(t, "pop", err)
+	assert.NotNil(t, sa.inflight["ns1"])
+	mse.AssertExpectations(t)
+	mcd.AssertExpectations(t)
+}
+
+func TestInitWithCoordinatorListenerFail(t *testing.T) {
+	sa, cancel := newTestSyncAsyncBridge(t)
+	defer cancel()
+
+	mse := sa.sysevents.(*systemeventmocks.EventInterface)
+	mse.On("AddSystemEventListener", "ns1", mock.Anything).Return(fmt.Errorf("pop"))
+
+	mcd := &mockCoordinator{}
+	sa.coordinator = mcd
+
+	err := sa.Init(sa.sysevents)
+	assert.Regexp(t, "pop", err)
+	mse.AssertExpectations(t)
+	mcd.AssertExpectations(t)
+}
+
+func TestForwardRequestAddedBeforeNotificationRead(t *testing.T) {
+	sa, cancel := newTestSyncAsyncBridge(t)
+	defer cancel()
+
+	requestID := fftypes.NewUUID()
+	otherReplica := fftypes.NewUUID()
+
+	mse := sa.sysevents.(*systemeventmocks.EventInterface)
+	mse.On("AddSystemEventListener", "ns1", mock.Anything).Return(nil).Once()
+
+	event := &core.EventDelivery{
+		EnrichedEvent: core.EnrichedEvent{
+			Event: core.Event{
+				ID:        fftypes.NewUUID(),
+				Type:      core.EventTypeMessageConfirmed,
+				Reference: requestID,
+				Namespace: "ns1",
+			},
+		},
+	}
+
+	mcd := &mockCoordinator{}
+	mcd.On("Sync").Run(func(args mock.Arguments) {
+		// The request was persisted by the other replica, but the notification had not been read yet
+		sa.coordinatorCallback(&CoordinatorMessage{Type: CoordinatorRequestAdded, Origin: otherReplica, Request: requestID})
+	}).Return(nil).Once()
+	mcd.On("Publish", sa.ctx, mock.MatchedBy(func(msg *CoordinatorMessage) bool {
+		return msg.Type == CoordinatorEventForwarded &&
+			msg.Target.Equals(otherReplica) &&
+			msg.Request.Equals(requestID) &&
+			msg.Event.ID.Equals(event.ID)
+	})).Return(nil).Once()
+	sa.coordinator = mcd
+
+	err := sa.eventCallback(event)
+	assert.NoError(t, err)
+
+	mse.AssertExpectations(t)
+	mcd.AssertExpectations(t)
+}
+
+func TestForwardSyncFail(t *testing.T) {
+	sa, cancel := newTestSyncAsyncBridge(t)
+	defer cancel()
+
+	mcd := &mockCoordinator{}
+	mcd.On("Sync").Return(fmt.Errorf("pop"))
+	sa.coordinator = mcd
+
+	err := sa.eventCallback(&core.EventDelivery{
+		EnrichedEvent: core.EnrichedEvent{
+			Event: core.Event{
+				ID:        fftypes.NewUUID(),
+				Type:      core.EventTypeMessageConfirmed,
+				Reference: fftypes.NewUUID(),
+				Namespace: "ns1",
+			},
+		},
+	})
+	assert.Regexp(t, "pop", err)
+
+	mcd.AssertExpectations(t)
+}
+
+func TestForwardSkipsSyncForLocalRequest(t *testing.T) {
+	sa, cancel := newTestSyncAsyncBridge(t)
+	defer cancel()
+
+	requestID := fftypes.NewUUID()
+	sa.inflight = map[string]map[fftypes.UUID]*inflightRequest{
+		"ns1": {
+			*requestID: &inflightRequest{
+				reqType:  messageConfirm,
+				response: make(chan inflightResponse, 1),
+			},
+		},
+	}
+
+	mdi := sa.database.(*databasemocks.Plugin)
+	mdi.On("GetMessageByID", sa.ctx, "ns1", requestID).Return(&core.Message{
+		Header: core.MessageHeader{ID: requestID},
+	}, nil)
+
+	mcd := &mockCoordinator{}
+	sa.coordinator = mcd
+
+	err := sa.eventCallback(&core.EventDelivery{
+		EnrichedEvent: core.EnrichedEvent{
+			Event: core.Event{
+				ID:        fftypes.NewUUID(),
+				Type:      core.EventTypeMessageConfirmed,
+				Reference: requestID,
+				Namespace: "ns1",
+			},
+		},
+	})
+	assert.NoError(t, err)
+	response := <-sa.inflight["ns1"][*requestID].response
+	assert.Equal(t, requestID, response.id)
+
+	mdi.AssertExpectations(t)
+	mcd.AssertExpectations(t)
+}
+
+func TestAwaitConfirmationForwardedByOtherReplica(t *testing.T) {
+	sa, cancel := newTestSyncAsyncBridge(t)
+	defer cancel()
+
+	requestID := fftypes.NewUUID()
+	otherReplica := fftypes.NewUUID()
+
+	mse := sa.sysevents.(*systemeventmocks.EventInterface)
+	mse.On("AddSystemEventListener", "ns1", mock.Anything).Return(nil)
+
+	mdi := sa.database.(*databasemocks.Plugin)
+	mdi.On("GetMessageByID", sa.ctx, "ns1", requestID).Return(&core.Message{
+		Header: core.MessageHeader{ID: requestID},
+	}, nil)
+
+	mcd := &mockCoordinator{}
+	mcd.On("Publish", mock.Anything, mock.MatchedBy(func(msg *CoordinatorMessage) bool {
+		return msg.Type == CoordinatorRequestAdded && msg.Request.Equals(requestID)
+	})).Return(nil)
+	mcd.On("Publish", sa.ctx, mock.MatchedBy(func(msg *CoordinatorMessage) bool {
+		return msg.Type == CoordinatorRequestRemoved && msg.Request.Equals(requestID)
+	})).Return(fmt.Errorf("pop"))
+	sa.coordinator = mcd
+
+	reply, err := sa.WaitForMessage(sa.ctx, requestID, func(ctx context.Context) error {
+		go func() {
+			sa.coordinatorCallback(&CoordinatorMessage{
+				Type:    CoordinatorEventForwarded,
+				Origin:  otherReplica,
+				Request: requestID,
+				Event: &core.Event{
+					ID:        fftypes.NewUUID(),
+					Type:      core.EventTypeMessageConfirmed,
+					Reference: requestID,
+					Namespace: "ns1",
+				},
+			})
+		}()
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, requestID, reply.Header.ID)
+
+	mcd.AssertExpectations(t)
+}
+
+func TestAwaitConfirmationPublishFail(t *testing.T) {
+	sa, cancel := newTestSyncAsyncBridge(t)
+	defer cancel()
+
+	requestID := fftypes.NewUUID()
+
+	mse := sa.sysevents.(*systemeventmocks.EventInterface)
+	mse.On("AddSystemEventListener", "ns1", mock.Anything).Return(nil)
+
+	mcd := &mockCoordinator{}
+	mcd.On("Publish", mock.Anything, mock.Anything).Return(fmt.Errorf("pop"))
+	sa.coordinator = mcd
+
+	_, err := sa.WaitForMessage(sa.ctx, requestID, func(ctx context.Context) error {
+		assert.Fail(t, "should not be sent")
+		return nil
+	})
+	assert.Regexp(t, "pop", err)
+
+	mcd.AssertExpectations(t)
+}
+
+func TestForwardedEventLookupFail(t *testing.T) {
+	sa, cancel := newTestSyncAsyncBridge(t)
+	defer cancel()
+
+	requestID := fftypes.NewUUID()
+	sa.inflight = map[string]map[fftypes.UUID]*inflightRequest{
+		"ns1": {
+			*requestID: &inflightRequest{
+				reqType: messageConfirm,
+			},
+		},
+	}
+
+	mdi := sa.database.(*databasemocks.Plugin)
+	mdi.On("GetMessageByID", sa.ctx, "ns1", requestID).Return(nil, fmt.Errorf("pop"))
+
+	sa.coordinatorCallback(&CoordinatorMessage{
+		Type: CoordinatorEventForwarded,
+		Event: &core.Event{
+			ID:        fftypes.NewUUID(),
+			Type:      core.EventTypeMessageConfirmed,
+			Reference: requestID,
+			Namespace: "ns1",
+		},
+	})
+
+	mdi.AssertExpectations(t)
+}
+
+func TestResolveRequestForOtherReplica(t *testing.T) {
+	sa, cancel := newTestSyncAsyncBridge(t)
+	defer cancel()
+
+	requestID := fftypes.NewUUID()
+	staleID := fftypes.NewUUID()
+	otherReplica := fftypes.NewUUID()
+
+	mse := sa.sysevents.(*systemeventmocks.EventInterface)
+	mse.On("AddSystemEventListener", "ns1", mock.Anything).Return(nil).Once()
+
+	event := &core.EventDelivery{
+		EnrichedEvent: core.EnrichedEvent{
+			Event: core.Event{
+				ID:         fftypes.NewUUID(),
+				Type:       core.EventTypePoolOpFailed,
+				Reference:  fftypes.NewUUID(),
+				Correlator: requestID,
+				Namespace:  "ns1",
+			},
+		},
+	}
+
+	mcd := &mockCoordinator{}
+	mcd.On("Publish", sa.ctx, mock.MatchedBy(func(msg *CoordinatorMessage) bool {
+		return msg.Type == CoordinatorEventForwarded &&
+			msg.Target.Equals(otherReplica) &&
+			msg.Request.Equals(requestID) &&
+			msg.Event.ID.Equals(event.ID)
+	})).Return(nil).Once()
+	mcd.On("Publish", sa.ctx, mock.Anything).Return(fmt.Errorf("pop")).Once()
+	mcd.On("Sync").Return(nil).Once()
+	sa.coordinator = mcd
+
+	// Requests without IDs are ignored
+	sa.coordinatorCallback(&CoordinatorMessage{Type: CoordinatorRequestAdded, Origin: otherReplica})
+	sa.coordinatorCallback(&CoordinatorMessage{Type: CoordinatorRequestRemoved, Origin: otherReplica})
+	assert.Empty(t, sa.remote)
+
+	// Stale requests are cleaned up when new ones arrive
+	sa.coordinatorCallback(&CoordinatorMessage{Type: CoordinatorRequestAdded, Origin: otherReplica, Request: staleID})
+	sa.remote["ns1"][*staleID].startTime = time.Now().Add(-1 * time.Hour)
+	sa.coordinatorCallback(&CoordinatorMessage{Type: CoordinatorRequestAdded, Origin: otherReplica, Request: requestID})
+	assert.Len(t, sa.remote["ns1"], 1)
+
+	// Events that cannot resolve requests are not forwarded
+	err := sa.eventCallback(&core.EventDelivery{
+		EnrichedEvent: core.EnrichedEvent{
+			Event: core.Event{
+				ID:        fftypes.NewUUID(),
+				Type:      core.EventTypeTransactionSubmitted,
+				Reference: requestID,
+				Namespace: "ns1",
+			},
+		},
+	})
+	assert.NoError(t, err)
+	err = sa.eventCallback(&core.EventDelivery{
+		EnrichedEvent: core.EnrichedEvent{
+			Event: core.Event{
+				ID:        fftypes.NewUUID(),
+				Type:      core.EventTypeMessageConfirmed,
+				Namespace: "ns1",
+			},
+		},
+	})
+	assert.NoError(t, err)
+
+	err = sa.eventCallback(event)
+	assert.NoError(t, err)
+
+	err = sa.eventCallback(event)
+	assert.Regexp(t, "pop", err)
+
+	sa.coordinatorCallback(&CoordinatorMessage{Type: CoordinatorRequestRemoved, Origin: otherReplica, Request: requestID})
+	assert.Empty(t, sa.remote["ns1"])
+
+	err = sa.eventCallback(event)
+	assert.NoError(t, err)
+
+	mse.AssertExpectations(t)
+	mcd.AssertExpectations(t)
+}
+
+func TestTrackRequestForOtherReplicaListenerFail(t *testing.T) {
+	sa, cancel := newTestSyncAsyncBridge(t)
+	defer cancel()
+
+	mse := sa.sysevents.(*systemeventmocks.EventInterface)
+	mse.On("AddSystemEventListener", "ns1", mock.Anything).Return(fmt.Errorf("pop"))
+
+	sa.coordinatorCallback(&CoordinatorMessage{Type: CoordinatorRequestAdded, Origin: fftypes.NewUUID(), Request: fftypes.NewUUID()})
+	assert.Empty(t, sa.remote)
+
+	mse.AssertExpectations(t)
+}
+
+func TestResolveDiscardsDuplicates(t *testing.T) {
+	inflight := &inflightRequest{
+		response: make(chan inflightResponse, 1),
+	}
+	inflight.resolve(inflightResponse{id: fftypes.NewUUID()})
+	inflight.resolve(inflightResponse{id: fftypes.NewUUID()})
+	assert.Len(t, inflight.response, 1)
+}
@@ -34,6 +34,7 @@ import (
 	"github.com/hyperledger/firefly-signer/pkg/abi"
 	"github.com/hyperledger/firefly-signer/pkg/ffi2abi"
 	"github.com/hyperledger/firefly/internal/coremsgs"
+	"github.com/hyperledger/firefly/internal/tracing"
 	"github.com/hyperledger/firefly/pkg/blockchain"
 	"github.com/hyperledger/firefly/pkg/core"
 	"github.com/hyperledger/firefly/pkg/tokens"
@@ -311,6 +312,7 @@ func (ft *FFTokens) Init(ctx context.Context, cancelCtx context.CancelFunc, name
 	if err != nil {
 		return err
 	}
+	tracing.InstrumentClient(ft.client)
 
 	if ft.wsConfig.WSKeyPath == "" {
 		ft.wsConfig.WSKeyPath = "/api/ws"
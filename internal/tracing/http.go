@@ -0,0 +1,123 @@
+// Copyright © 2024 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tracing
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/go-resty/resty/v2"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type clientParentKey struct{}
+
+// StartServerSpan starts a span for an inbound HTTP request, continuing any trace propagated by the caller
+func StartServerSpan(req *http.Request, route string) (context.Context, trace.Span) {
+	ctx := propagator.Extract(req.Context(), propagation.HeaderCarrier(req.Header))
+	return tracer().Start(ctx, route,
+		trace.WithSpanKind(trace.SpanKindServer),
+		trace.WithAttributes(
+			semconv.HTTPRequestMethodKey.String(req.Method),
+			semconv.HTTPRoute(route),
+			semconv.URLPath(req.URL.Path),
+		),
+	)
+}
+
+// ServerHandler wraps the handler of an API route, to record a span for each request
+func ServerHandler(route string, handler http.HandlerFunc) http.HandlerFunc {
+	return func(res http.ResponseWriter, req *http.Request) {
+		ctx, span := StartServerSpan(req, route)
+		sr := &statusRecorder{ResponseWriter: res, status: http.StatusOK}
+		handler(sr, req.WithContext(ctx))
+		span.SetAttributes(semconv.HTTPResponseStatusCode(sr.status))
+		if sr.status >= 500 {
+			span.SetStatus(codes.Error, http.StatusText(sr.status))
+		}
+		span.End()
+	}
+}
+
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (sr *statusRecorder) WriteHeader(status int) {
+	sr.status = status
+	sr.ResponseWriter.WriteHeader(status)
+}
+
+// StartClientSpan starts a span for an outbound HTTP request, and adds the trace context to its headers
+func StartClientSpan(ctx context.Context, name, method, url string, header http.Header) (context.Context, trace.Span) {
+	ctx, span := tracer().Start(ctx, name,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			semconv.HTTPRequestMethodKey.String(method),
+			semconv.URLFull(url),
+		),
+	)
+	propagator.Inject(ctx, propagation.HeaderCarrier(header))
+	return ctx, span
+}
+
+// EndClientSpan ends a span for an outbound HTTP request, recording the response status or error
+func EndClientSpan(span trace.Span, statusCode int, err error) {
+	if statusCode > 0 {
+		span.SetAttributes(semconv.HTTPResponseStatusCode(statusCode))
+		if statusCode >= 400 && err == nil {
+			span.SetStatus(codes.Error, http.StatusText(statusCode))
+		}
+	}
+	EndSpan(span, err)
+}
+
+// InstrumentClient records a span for each request made by a resty client to a connector, and propagates
+// the trace to the connector in the headers of the request
+func InstrumentClient(client *resty.Client) *resty.Client {
+	return client.
+		OnBeforeRequest(func(_ *resty.Client, req *resty.Request) error {
+			parent := req.Context()
+			if p, ok := parent.Value(clientParentKey{}).(context.Context); ok {
+				// This is a retry, so end the span of the previous attempt and start a sibling
+				span := trace.SpanFromContext(parent)
+				span.SetStatus(codes.Error, "retried")
+				span.End()
+				parent = p
+			}
+			ctx, _ := StartClientSpan(parent, "HTTP "+req.Method, req.Method, req.URL, req.Header)
+			req.SetContext(context.WithValue(ctx, clientParentKey{}, parent))
+			return nil
+		}).
+		OnSuccess(func(_ *resty.Client, res *resty.Response) {
+			EndClientSpan(trace.SpanFromContext(res.Request.Context()), res.StatusCode(), nil)
+		}).
+		OnError(func(req *resty.Request, err error) {
+			if _, ok := req.Context().Value(clientParentKey{}).(context.Context); !ok {
+				return // failed before the span was started
+			}
+			statusCode := 0
+			if re, ok := err.(*resty.ResponseError); ok && re.Response != nil {
+				statusCode = re.Response.StatusCode()
+			}
+			EndClientSpan(trace.SpanFromContext(req.Context()), statusCode, err)
+		})
+}
@@ -0,0 +1,165 @@
+// Copyright © 2024 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/jarcoal/httpmock"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestStartServerSpan(t *testing.T) {
+	sr := newTestRecorder(t)
+
+	ctx, caller := StartSpan(context.Background(), "caller")
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/namespaces/ns1/messages/broadcast", nil)
+	_, span := StartClientSpan(ctx, "HTTP POST", req.Method, req.URL.String(), req.Header)
+	EndClientSpan(span, 500, nil)
+	caller.End()
+
+	_, server := StartServerSpan(req, "postNewMessageBroadcast")
+	server.End()
+
+	spans := sr.Ended()
+	assert.Len(t, spans, 3)
+	assert.Equal(t, codes.Error, spans[0].Status().Code)
+	assert.Equal(t, trace.SpanKindServer, spans[2].SpanKind())
+	assert.Equal(t, spans[0].SpanContext().SpanID(), spans[2].Parent().SpanID())
+	assert.Equal(t, "postNewMessageBroadcast", spans[2].Name())
+}
+
+func TestServerHandler(t *testing.T) {
+	sr := newTestRecorder(t)
+
+	handler := ServerHandler("getStatus", func(res http.ResponseWriter, req *http.Request) {
+		assert.True(t, trace.SpanContextFromContext(req.Context()).IsValid())
+		res.WriteHeader(http.StatusServiceUnavailable)
+	})
+	res := httptest.NewRecorder()
+	handler(res, httptest.NewRequest(http.MethodGet, "/api/v1/status", nil))
+	assert.Equal(t, http.StatusServiceUnavailable, res.Code)
+
+	handler = ServerHandler("getStatus", func(res http.ResponseWriter, req *http.Request) {})
+	handler(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/api/v1/status", nil))
+
+	spans := sr.Ended()
+	assert.Len(t, spans, 2)
+	assert.Equal(t, codes.Error, spans[0].Status().Code)
+	assert.Equal(t, codes.Unset, spans[1].Status().Code)
+}
+
+func TestInstrumentClient(t *testing.T) {
+	sr := newTestRecorder(t)
+
+	client := resty.New()
+	httpmock.ActivateNonDefault(client.GetClient())
+	defer httpmock.DeactivateAndReset()
+	InstrumentClient(client)
+
+	attempts := 0
+	var traceparents []string
+	httpmock.RegisterResponder("POST", "http://connector/api",
+		func(req *http.Request) (*http.Response, error) {
+			attempts++
+			traceparents = append(traceparents, req.Header.Get("traceparent"))
+			if attempts == 1 {
+				return httpmock.NewStringResponse(500, "retry me"), nil
+			}
+			return httpmock.NewStringResponse(200, "{}"), nil
+		})
+	client.SetRetryCount(1).AddRetryCondition(func(r *resty.Response, err error) bool {
+		return r.StatusCode() == 500
+	})
+
+	ctx, parent := StartSpan(context.Background(), "parent")
+	res, err := client.R().SetContext(ctx).Post("http://connector/api")
+	assert.NoError(t, err)
+	assert.Equal(t, 200, res.StatusCode())
+	parent.End()
+
+	spans := sr.Ended()
+	assert.Len(t, spans, 3)
+	for i, span := range spans[0:2] {
+		assert.Equal(t, "HTTP POST", span.Name())
+		assert.Equal(t, trace.SpanKindClient, span.SpanKind())
+		assert.Equal(t, parent.SpanContext().SpanID(), span.Parent().SpanID())
+		assert.Contains(t, traceparents[i], span.SpanContext().SpanID().String())
+	}
+	assert.Equal(t, codes.Error, spans[0].Status().Code)
+	assert.Equal(t, codes.Unset, spans[1].Status().Code)
+}
+
+func TestInstrumentClientError(t *testing.T) {
+	sr := newTestRecorder(t)
+
+	client := resty.New()
+	httpmock.ActivateNonDefault(client.GetClient())
+	defer httpmock.DeactivateAndReset()
+	InstrumentClient(client)
+	httpmock.RegisterResponder("GET", "http://connector/api", httpmock.NewErrorResponder(fmt.Errorf("pop")))
+
+	_, err := client.R().Get("http://connector/api")
+	assert.Regexp(t, "pop", err)
+
+	spans := sr.Ended()
+	assert.Len(t, spans, 1)
+	assert.Equal(t, codes.Error, spans[0].Status().Code)
+}
+
+func TestInstrumentClientResponseError(t *testing.T) {
+	sr := newTestRecorder(t)
+
+	client := resty.New()
+	httpmock.ActivateNonDefault(client.GetClient())
+	defer httpmock.DeactivateAndReset()
+	client.OnBeforeRequest(func(c *resty.Client, r *resty.Request) error {
+		if r.Method == http.MethodPut {
+			return fmt.Errorf("refused before span")
+		}
+		return nil
+	})
+	InstrumentClient(client)
+	client.OnAfterResponse(func(c *resty.Client, r *resty.Response) error {
+		return fmt.Errorf("bad response")
+	})
+	client.OnBeforeRequest(func(c *resty.Client, r *resty.Request) error {
+		if r.Method == http.MethodDelete {
+			return fmt.Errorf("refused")
+		}
+		return nil
+	})
+	httpmock.RegisterResponder("GET", "http://connector/api", httpmock.NewStringResponder(409, "{}"))
+
+	_, err := client.R().Get("http://connector/api")
+	assert.Regexp(t, "bad response", err)
+	_, err = client.R().Delete("http://connector/api")
+	assert.Regexp(t, "refused", err)
+	_, err = client.R().Put("http://connector/api")
+	assert.Regexp(t, "refused before span", err)
+
+	spans := sr.Ended()
+	assert.Len(t, spans, 2)
+	assert.Equal(t, codes.Error, spans[0].Status().Code)
+}
@@ -0,0 +1,182 @@
+// Copyright © 2024 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tracing
+
+import (
+	"context"
+	"strings"
+
+	"github.com/hyperledger/firefly-common/pkg/config"
+	"github.com/hyperledger/firefly-common/pkg/fftypes"
+	"github.com/hyperledger/firefly-common/pkg/i18n"
+	"github.com/hyperledger/firefly-common/pkg/log"
+	"github.com/hyperledger/firefly/internal/coreconfig"
+	"github.com/hyperledger/firefly/internal/coremsgs"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const instrumentationName = "github.com/hyperledger/firefly"
+
+// Attribute keys for the FireFly resources that a span relates to
+var (
+	NamespaceKey       = attribute.Key("firefly.namespace")
+	MessageIDKey       = attribute.Key("firefly.message.id")
+	BatchIDKey         = attribute.Key("firefly.batch.id")
+	TransactionIDKey   = attribute.Key("firefly.tx.id")
+	OperationIDKey     = attribute.Key("firefly.operation.id")
+	OperationTypeKey   = attribute.Key("firefly.operation.type")
+	OperationStatusKey = attribute.Key("firefly.operation.status")
+	EventIDKey         = attribute.Key("firefly.event.id")
+	EventCountKey      = attribute.Key("firefly.event.count")
+	SubscriptionKey    = attribute.Key("firefly.subscription")
+)
+
+// W3C trace context and baggage are used to propagate traces, both over HTTP and on records stored in the database
+var propagator = propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{})
+
+// Init configures OpenTelemetry to export spans to an OTLP collector, if tracing is enabled.
+// When it is not, spans are still propagated but nothing is recorded.
+// The returned function flushes any buffered spans, and must be called on shutdown.
+func Init(ctx context.Context) (shutdown func(), err error) {
+	if !config.GetBool(coreconfig.TracingEnabled) {
+		return func() {}, nil
+	}
+
+	exporter, err := newExporter(ctx)
+	if err != nil {
+		return nil, err
+	}
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(resource.NewSchemaless(semconv.ServiceName(config.GetString(coreconfig.TracingServiceName)))),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(config.GetFloat64(coreconfig.TracingSampleRatio)))),
+	)
+	otel.SetTracerProvider(provider)
+	otel.SetErrorHandler(otel.ErrorHandlerFunc(func(err error) {
+		log.L(ctx).Warnf("OpenTelemetry error: %s", err)
+	}))
+	log.L(ctx).Infof("OpenTelemetry tracing enabled (protocol=%s)", config.GetString(coreconfig.TracingOTLPProtocol))
+
+	return func() {
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), config.GetDuration(coreconfig.TracingOTLPTimeout))
+		defer cancel()
+		if err := provider.Shutdown(shutdownCtx); err != nil {
+			log.L(ctx).Warnf("Failed to flush OpenTelemetry spans: %s", err)
+		}
+	}, nil
+}
+
+func newExporter(ctx context.Context) (sdktrace.SpanExporter, error) {
+	endpoint := config.GetString(coreconfig.TracingOTLPEndpoint)
+	headers := map[string]string{}
+	headersConf := config.GetObject(coreconfig.TracingOTLPHeaders)
+	for k := range headersConf {
+		headers[k] = headersConf.GetString(k)
+	}
+	timeout := config.GetDuration(coreconfig.TracingOTLPTimeout)
+
+	protocol := strings.ToLower(config.GetString(coreconfig.TracingOTLPProtocol))
+	switch protocol {
+	case "http":
+		opts := []otlptracehttp.Option{otlptracehttp.WithHeaders(headers), otlptracehttp.WithTimeout(timeout)}
+		if endpoint != "" {
+			opts = append(opts, otlptracehttp.WithEndpointURL(endpoint))
+		}
+		return otlptracehttp.New(ctx, opts...)
+	case "grpc":
+		opts := []otlptracegrpc.Option{otlptracegrpc.WithHeaders(headers), otlptracegrpc.WithTimeout(timeout)}
+		if endpoint != "" {
+			opts = append(opts, otlptracegrpc.WithEndpointURL(endpoint))
+		}
+		return otlptracegrpc.New(ctx, opts...)
+	default:
+		return nil, i18n.NewError(ctx, coremsgs.MsgInvalidTracingProtocol, protocol)
+	}
+}
+
+func tracer() trace.Tracer {
+	return otel.GetTracerProvider().Tracer(instrumentationName)
+}
+
+// StartSpan starts a span as a child of any span in the context
+func StartSpan(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return tracer().Start(ctx, name, trace.WithAttributes(attrs...))
+}
+
+// StartSpanFrom starts a span that continues traces stored on records by TraceContext, so a trace
+// can be followed across asynchronous processing. The span is a child of the first valid trace context,
+// and is linked to the others. If there are none, the span is a child of any span in the context.
+func StartSpanFrom(ctx context.Context, name string, traceContexts []fftypes.JSONObject, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	var parent trace.SpanContext
+	var links []trace.Link
+	for _, tc := range traceContexts {
+		sc := trace.SpanContextFromContext(propagator.Extract(context.Background(), toCarrier(tc)))
+		switch {
+		case !sc.IsValid():
+			continue
+		case !parent.IsValid():
+			parent = sc
+		case !sc.Equal(parent):
+			links = append(links, trace.Link{SpanContext: sc})
+		}
+	}
+	if parent.IsValid() {
+		ctx = trace.ContextWithRemoteSpanContext(ctx, parent)
+	}
+	return tracer().Start(ctx, name, trace.WithAttributes(attrs...), trace.WithLinks(links...))
+}
+
+// EndSpan ends a span, recording the error if there is one
+func EndSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// TraceContext returns the trace context of the current span, to be stored on a record so that
+// later asynchronous processing of the record can continue the trace. It is nil if there is no span.
+func TraceContext(ctx context.Context) fftypes.JSONObject {
+	if !trace.SpanContextFromContext(ctx).IsValid() {
+		return nil
+	}
+	carrier := propagation.MapCarrier{}
+	propagator.Inject(ctx, carrier)
+	tc := make(fftypes.JSONObject, len(carrier))
+	for k, v := range carrier {
+		tc[k] = v
+	}
+	return tc
+}
+
+func toCarrier(tc fftypes.JSONObject) propagation.MapCarrier {
+	carrier := make(propagation.MapCarrier, len(tc))
+	for k := range tc {
+		carrier[k] = tc.GetString(k)
+	}
+	return carrier
+}
@@ -0,0 +1,145 @@
+// Copyright © 2024 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/hyperledger/firefly-common/pkg/config"
+	"github.com/hyperledger/firefly-common/pkg/fftypes"
+	"github.com/hyperledger/firefly/internal/coreconfig"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func newTestRecorder(t *testing.T) *tracetest.SpanRecorder {
+	prev := otel.GetTracerProvider()
+	sr := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(sr)))
+	t.Cleanup(func() { otel.SetTracerProvider(prev) })
+	return sr
+}
+
+func TestInitDisabled(t *testing.T) {
+	coreconfig.Reset()
+	shutdown, err := Init(context.Background())
+	assert.NoError(t, err)
+	shutdown()
+}
+
+func TestInitHTTP(t *testing.T) {
+	prev := otel.GetTracerProvider()
+	defer otel.SetTracerProvider(prev)
+	coreconfig.Reset()
+	config.Set(coreconfig.TracingEnabled, true)
+	config.Set(coreconfig.TracingOTLPEndpoint, "http://localhost:4318")
+	config.Set(coreconfig.TracingOTLPHeaders, map[string]interface{}{"Authorization": "Bearer token"})
+	config.Set(coreconfig.TracingOTLPTimeout, "0s")
+
+	shutdown, err := Init(context.Background())
+	assert.NoError(t, err)
+	_, ok := otel.GetTracerProvider().(*sdktrace.TracerProvider)
+	assert.True(t, ok)
+
+	// The span cannot be flushed to the collector before the timeout
+	_, span := StartSpan(context.Background(), "test")
+	span.End()
+	shutdown()
+}
+
+func TestInitGRPC(t *testing.T) {
+	prev := otel.GetTracerProvider()
+	defer otel.SetTracerProvider(prev)
+	coreconfig.Reset()
+	config.Set(coreconfig.TracingEnabled, true)
+	config.Set(coreconfig.TracingOTLPProtocol, "gRPC")
+	config.Set(coreconfig.TracingOTLPEndpoint, "http://localhost:4317")
+
+	shutdown, err := Init(context.Background())
+	assert.NoError(t, err)
+	otel.Handle(fmt.Errorf("pop")) // logged
+	shutdown()
+}
+
+func TestInitBadProtocol(t *testing.T) {
+	coreconfig.Reset()
+	config.Set(coreconfig.TracingEnabled, true)
+	config.Set(coreconfig.TracingOTLPProtocol, "zipkin")
+
+	_, err := Init(context.Background())
+	assert.Regexp(t, "FF10501.*zipkin", err)
+}
+
+func TestTraceContextRoundTrip(t *testing.T) {
+	sr := newTestRecorder(t)
+
+	assert.Nil(t, TraceContext(context.Background()))
+
+	ctx, span := StartSpan(context.Background(), "api", MessageIDKey.String("msg1"))
+	tc := TraceContext(ctx)
+	assert.Regexp(t, "^00-[0-9a-f]{32}-[0-9a-f]{16}-01$", tc.GetString("traceparent"))
+	EndSpan(span, nil)
+
+	// Continue the trace later, from the stored trace context
+	_, child := StartSpanFrom(context.Background(), "batch", []fftypes.JSONObject{nil, {"traceparent": "bad"}, tc})
+	EndSpan(child, fmt.Errorf("pop"))
+
+	spans := sr.Ended()
+	assert.Len(t, spans, 2)
+	assert.Equal(t, spans[0].SpanContext().TraceID(), spans[1].SpanContext().TraceID())
+	assert.Equal(t, spans[0].SpanContext().SpanID(), spans[1].Parent().SpanID())
+	assert.Equal(t, codes.Error, spans[1].Status().Code)
+	assert.Len(t, spans[1].Events(), 1)
+}
+
+func TestStartSpanFromLinks(t *testing.T) {
+	sr := newTestRecorder(t)
+
+	ctx1, span1 := StartSpan(context.Background(), "msg1")
+	ctx2, span2 := StartSpan(context.Background(), "msg2")
+	span1.End()
+	span2.End()
+	tc1, tc2 := TraceContext(ctx1), TraceContext(ctx2)
+
+	_, batch := StartSpanFrom(context.Background(), "batch", []fftypes.JSONObject{tc1, tc1, tc2})
+	batch.End()
+
+	spans := sr.Ended()
+	assert.Len(t, spans, 3)
+	assert.Equal(t, span1.SpanContext().SpanID(), spans[2].Parent().SpanID())
+	assert.Len(t, spans[2].Links(), 1)
+	assert.Equal(t, span2.SpanContext().SpanID(), spans[2].Links()[0].SpanContext.SpanID())
+}
+
+func TestStartSpanFromNoTraceContext(t *testing.T) {
+	sr := newTestRecorder(t)
+
+	ctx, parent := StartSpan(context.Background(), "parent")
+	_, child := StartSpanFrom(ctx, "child", nil)
+	child.End()
+	parent.End()
+
+	spans := sr.Ended()
+	assert.Equal(t, parent.SpanContext().SpanID(), spans[0].Parent().SpanID())
+	assert.Equal(t, trace.SpanKindInternal, spans[0].SpanKind())
+}
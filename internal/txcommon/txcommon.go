@@ -40,6 +40,7 @@ type Helper interface {
 	AddBlockchainTX(ctx context.Context, tx *core.Transaction, blockchainTXID string) error
 	InsertOrGetBlockchainEvent(ctx context.Context, event *core.BlockchainEvent) (existing *core.BlockchainEvent, err error)
 	InsertNewBlockchainEvents(ctx context.Context, events []*core.BlockchainEvent) (inserted []*core.BlockchainEvent, err error)
+	SetBlockchainEventRemoved(ctx context.Context, event *core.BlockchainEvent, removed bool) error
 	GetTransactionByIDCached(ctx context.Context, id *fftypes.UUID) (*core.Transaction, error)
 	GetBlockchainEventByIDCached(ctx context.Context, id *fftypes.UUID) (*core.BlockchainEvent, error)
 	FindOperationInTransaction(ctx context.Context, tx *fftypes.UUID, opType core.OpType) (*core.Operation, error)
@@ -113,7 +114,8 @@ func (t *transactionHelper) GetTransactionByIDCached(ctx context.Context, id *ff
 	if cachedValue := t.transactionCache.Get(id.String()); cachedValue != nil {
 		return cachedValue.(*core.Transaction), nil
 	}
-	tx, err := t.database.GetTransactionByID(ctx, t.namespace, id)
+	// The cache is shared with event processing, so must not be filled from a read replica that is behind
+	tx, err := t.database.GetTransactionByID(database.WithPrimaryReads(ctx), t.namespace, id)
 	if err != nil || tx == nil {
 		return tx, err
 	}
@@ -305,7 +307,7 @@ func (t *transactionHelper) GetBlockchainEventByIDCached(ctx context.Context, id
 	if cachedValue := t.blockchainEventCache.Get(id.String()); cachedValue != nil {
 		return cachedValue.(*core.BlockchainEvent), nil
 	}
-	chainEvent, err := t.database.GetBlockchainEventByID(ctx, t.namespace, id)
+	chainEvent, err := t.database.GetBlockchainEventByID(database.WithPrimaryReads(ctx), t.namespace, id)
 	if err != nil || chainEvent == nil {
 		return chainEvent, err
 	}
@@ -347,11 +349,18 @@ func (t *transactionHelper) InsertNewBlockchainEvents(ctx context.Context, event
 			return nil, err
 		}
 
-		if existing != nil {
+		if existing != nil && existing.Removed {
+			// The event was removed by a chain reorganization, and the connector has delivered it again
+			log.L(ctx).Infof("Reinstating removed blockchain event %s", existing.ProtocolID)
+			if err := t.SetBlockchainEventRemoved(ctx, existing, false); err != nil {
+				return nil, err
+			}
+			inserted = append(inserted, existing) // notify caller so the event is emitted again
+		} else if existing != nil {
 			// It's possible the batch insert was partially successful, and this is actually a "new" row.
 			// Look to see if the corresponding entry also exists in the "events" table.
 			fb := database.EventQueryFactory.NewFilter(ctx)
-			notifications, _, err := t.database.GetEvents(ctx, t.namespace, fb.And(
+			notifications, _, err := t.database.GetEvents(database.WithPrimaryReads(ctx), t.namespace, fb.And(
 				fb.Eq("type", core.EventTypeBlockchainEventReceived),
 				fb.Eq("reference", existing.ID),
 			))
@@ -374,13 +383,87 @@ func (t *transactionHelper) InsertNewBlockchainEvents(ctx context.Context, event
 	return inserted, nil
 }
 
+// SetBlockchainEventRemoved marks a blockchain event as removed by a chain reorganization (or reinstated), and
+// invalidates (or revalidates) the token transfers and approvals that were recorded for it. Callers must run this
+// inside a database group, so the event and its dependents are updated together.
+func (t *transactionHelper) SetBlockchainEventRemoved(ctx context.Context, event *core.BlockchainEvent, removed bool) error {
+	update := database.BlockchainEventQueryFactory.NewUpdate(ctx).Set("removed", removed)
+	if err := t.database.UpdateBlockchainEvent(ctx, t.namespace, event.ID, update); err != nil {
+		return err
+	}
+	if err := t.setBlockchainEventDependentsInvalidated(ctx, event, removed); err != nil {
+		return err
+	}
+	event.Removed = removed
+	t.addBlockchainEventToCache(event)
+	return nil
+}
+
+func (t *transactionHelper) setBlockchainEventDependentsInvalidated(ctx context.Context, event *core.BlockchainEvent, invalidated bool) error {
+	eventIDs := []*fftypes.UUID{event.ID}
+	if event.Listener != nil {
+		// Token connectors do not report removals, so token events recorded for the same log are included here
+		tokenEvent, err := t.database.GetBlockchainEventByProtocolID(ctx, t.namespace, nil, event.ProtocolID)
+		if err != nil {
+			return err
+		}
+		if tokenEvent != nil {
+			eventIDs = append(eventIDs, tokenEvent.ID)
+		}
+	}
+
+	for _, eventID := range eventIDs {
+		tfb := database.TokenTransferQueryFactory.NewFilter(ctx)
+		transfers, _, err := t.database.GetTokenTransfers(ctx, t.namespace, tfb.And(
+			tfb.Eq("blockchainevent", eventID),
+			tfb.Eq("invalidated", !invalidated),
+		))
+		if err != nil {
+			return err
+		}
+		for _, transfer := range transfers {
+			if err := t.setTokenTransferInvalidated(ctx, transfer, invalidated); err != nil {
+				return err
+			}
+		}
+
+		afb := database.TokenApprovalQueryFactory.NewFilter(ctx)
+		aUpdate := database.TokenApprovalQueryFactory.NewUpdate(ctx).Set("invalidated", invalidated)
+		if err := t.database.UpdateTokenApprovals(ctx, afb.Eq("blockchainevent", eventID), aUpdate); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (t *transactionHelper) setTokenTransferInvalidated(ctx context.Context, transfer *core.TokenTransfer, invalidated bool) error {
+	balanceUpdate := transfer
+	if invalidated {
+		// Reverse the effect of the transfer on balances, by moving the amount back from the recipient to the sender
+		reversed := *transfer
+		reversed.From, reversed.To = transfer.To, transfer.From
+		balanceUpdate = &reversed
+	}
+	if err := t.database.UpdateTokenBalances(ctx, balanceUpdate); err != nil {
+		return err
+	}
+
+	fb := database.TokenTransferQueryFactory.NewFilter(ctx)
+	update := database.TokenTransferQueryFactory.NewUpdate(ctx).Set("invalidated", invalidated)
+	if err := t.database.UpdateTokenTransfers(ctx, t.namespace, fb.Eq("localid", transfer.LocalID), update); err != nil {
+		return err
+	}
+	transfer.Invalidated = invalidated
+	return nil
+}
+
 func (t *transactionHelper) FindOperationInTransaction(ctx context.Context, tx *fftypes.UUID, opType core.OpType) (*core.Operation, error) {
 	fb := database.OperationQueryFactory.NewFilter(ctx)
 	filter := fb.And(
 		fb.Eq("tx", tx),
 		fb.Eq("type", opType),
 	)
-	ops, _, err := t.database.GetOperations(ctx, t.namespace, filter)
+	ops, _, err := t.database.GetOperations(database.WithPrimaryReads(ctx), t.namespace, filter)
 	if err != nil || len(ops) == 0 {
 		return nil, err
 	}
@@ -37,6 +37,10 @@ import (
 	"github.com/stretchr/testify/mock"
 )
 
+var primaryReads = mock.MatchedBy(func(ctx context.Context) bool {
+	return !database.ReplicaReadsAllowed(ctx)
+})
+
 type testTransactionHelper struct {
 	transactionHelper
 
@@ -178,7 +182,7 @@ func TestPersistTransactionNew(t *testing.T) {
 	txHelper, _ := NewTransactionHelper(ctx, "ns1", mdi, mdm, cmi)
 
 	txid := fftypes.NewUUID()
-	mdi.On("GetTransactionByID", ctx, "ns1", txid).Return(nil, nil)
+	mdi.On("GetTransactionByID", primaryReads, "ns1", txid).Return(nil, nil)
 	mdi.On("InsertTransaction", ctx, mock.MatchedBy(func(transaction *core.Transaction) bool {
 		assert.Equal(t, txid, transaction.ID)
 		assert.Equal(t, "ns1", transaction.Namespace)
@@ -205,7 +209,7 @@ func TestPersistTransactionNewInsertFail(t *testing.T) {
 	txHelper, _ := NewTransactionHelper(ctx, "ns1", mdi, mdm, cmi)
 
 	txid := fftypes.NewUUID()
-	mdi.On("GetTransactionByID", ctx, "ns1", txid).Return(nil, nil)
+	mdi.On("GetTransactionByID", primaryReads, "ns1", txid).Return(nil, nil)
 	mdi.On("InsertTransaction", ctx, mock.Anything).Return(fmt.Errorf("pop"))
 
 	valid, err := txHelper.PersistTransaction(ctx, txid, core.TransactionTypeBatchPin, "0x222222")
@@ -227,7 +231,7 @@ func TestPersistTransactionExistingAddBlockchainID(t *testing.T) {
 	txHelper, _ := NewTransactionHelper(ctx, "ns1", mdi, mdm, cmi)
 
 	txid := fftypes.NewUUID()
-	mdi.On("GetTransactionByID", ctx, "ns1", txid).Return(&core.Transaction{
+	mdi.On("GetTransactionByID", primaryReads, "ns1", txid).Return(&core.Transaction{
 		ID:            txid,
 		Namespace:     "ns1",
 		Type:          core.TransactionTypeBatchPin,
@@ -257,7 +261,7 @@ func TestPersistTransactionExistingUpdateFail(t *testing.T) {
 	txHelper, _ := NewTransactionHelper(ctx, "ns1", mdi, mdm, cmi)
 
 	txid := fftypes.NewUUID()
-	mdi.On("GetTransactionByID", ctx, "ns1", txid).Return(&core.Transaction{
+	mdi.On("GetTransactionByID", primaryReads, "ns1", txid).Return(&core.Transaction{
 		ID:            txid,
 		Namespace:     "ns1",
 		Type:          core.TransactionTypeBatchPin,
@@ -297,7 +301,7 @@ func TestPersistTransactionExistingNoChange(t *testing.T) {
 	))
 
 	txid := fftypes.NewUUID()
-	mdi.On("GetTransactionByID", ctx, "ns1", txid).Return(&core.Transaction{
+	mdi.On("GetTransactionByID", primaryReads, "ns1", txid).Return(&core.Transaction{
 		ID:            txid,
 		Namespace:     "ns1",
 		Type:          core.TransactionTypeBatchPin,
@@ -323,7 +327,7 @@ func TestPersistTransactionExistingNoBlockchainID(t *testing.T) {
 	txHelper, _ := NewTransactionHelper(ctx, "ns1", mdi, mdm, cmi)
 
 	txid := fftypes.NewUUID()
-	mdi.On("GetTransactionByID", ctx, "ns1", txid).Return(&core.Transaction{
+	mdi.On("GetTransactionByID", primaryReads, "ns1", txid).Return(&core.Transaction{
 		ID:            txid,
 		Namespace:     "ns1",
 		Type:          core.TransactionTypeBatchPin,
@@ -349,7 +353,7 @@ func TestPersistTransactionExistingLookupFail(t *testing.T) {
 	txHelper, _ := NewTransactionHelper(ctx, "ns1", mdi, mdm, cmi)
 
 	txid := fftypes.NewUUID()
-	mdi.On("GetTransactionByID", ctx, "ns1", txid).Return(nil, fmt.Errorf("pop"))
+	mdi.On("GetTransactionByID", primaryReads, "ns1", txid).Return(nil, fmt.Errorf("pop"))
 
 	valid, err := txHelper.PersistTransaction(ctx, txid, core.TransactionTypeBatchPin, "")
 	assert.Regexp(t, "pop", err)
@@ -369,7 +373,7 @@ func TestPersistTransactionExistingMismatchType(t *testing.T) {
 	txHelper, _ := NewTransactionHelper(ctx, "ns1", mdi, mdm, cmi)
 
 	txid := fftypes.NewUUID()
-	mdi.On("GetTransactionByID", ctx, "ns1", txid).Return(&core.Transaction{
+	mdi.On("GetTransactionByID", primaryReads, "ns1", txid).Return(&core.Transaction{
 		ID:            txid,
 		Namespace:     "ns1",
 		Type:          core.TransactionTypeContractInvoke,
@@ -471,7 +475,7 @@ func TestGetTransactionByIDCached(t *testing.T) {
 	ctx := context.Background()
 
 	txid := fftypes.NewUUID()
-	txHelper.mdi.On("GetTransactionByID", ctx, "ns1", txid).Return(&core.Transaction{
+	txHelper.mdi.On("GetTransactionByID", primaryReads, "ns1", txid).Return(&core.Transaction{
 		ID:            txid,
 		Namespace:     "ns1",
 		Type:          core.TransactionTypeContractInvoke,
@@ -490,6 +494,23 @@ func TestGetTransactionByIDCached(t *testing.T) {
 
 }
 
+func TestGetTransactionByIDCachedReplicaReads(t *testing.T) {
+
+	txHelper, _, _ := NewTestTransactionHelper()
+	defer txHelper.cleanup(t)
+	ctx := database.WithReplicaReads(context.Background())
+
+	txid := fftypes.NewUUID()
+	txHelper.mdi.On("GetTransactionByID", primaryReads, "ns1", txid).Return(&core.Transaction{
+		ID: txid,
+	}, nil).Once()
+
+	tx, err := txHelper.GetTransactionByIDCached(ctx, txid)
+	assert.NoError(t, err)
+	assert.Equal(t, txid, tx.ID)
+
+}
+
 func TestGetTransactionByIDCachedFail(t *testing.T) {
 
 	txHelper, _, _ := NewTestTransactionHelper()
@@ -497,7 +518,7 @@ func TestGetTransactionByIDCachedFail(t *testing.T) {
 	ctx := context.Background()
 
 	txid := fftypes.NewUUID()
-	txHelper.mdi.On("GetTransactionByID", ctx, "ns1", txid).Return(nil, fmt.Errorf("pop"))
+	txHelper.mdi.On("GetTransactionByID", primaryReads, "ns1", txid).Return(nil, fmt.Errorf("pop"))
 
 	_, err := txHelper.GetTransactionByIDCached(ctx, txid)
 	assert.EqualError(t, err, "pop")
@@ -514,7 +535,7 @@ func TestGetBlockchainEventByIDCached(t *testing.T) {
 	txHelper, _ := NewTransactionHelper(ctx, "ns1", mdi, mdm, cmi)
 
 	evID := fftypes.NewUUID()
-	mdi.On("GetBlockchainEventByID", ctx, "ns1", evID).Return(&core.BlockchainEvent{
+	mdi.On("GetBlockchainEventByID", primaryReads, "ns1", evID).Return(&core.BlockchainEvent{
 		ID:        evID,
 		Namespace: "ns1",
 	}, nil).Once()
@@ -541,7 +562,7 @@ func TestGetBlockchainEventByIDNil(t *testing.T) {
 	txHelper, _ := NewTransactionHelper(ctx, "ns1", mdi, mdm, cmi)
 
 	evID := fftypes.NewUUID()
-	mdi.On("GetBlockchainEventByID", ctx, "ns1", evID).Return(nil, nil)
+	mdi.On("GetBlockchainEventByID", primaryReads, "ns1", evID).Return(nil, nil)
 
 	chainEvent, err := txHelper.GetBlockchainEventByIDCached(ctx, evID)
 	assert.NoError(t, err)
@@ -561,7 +582,7 @@ func TestGetBlockchainEventByIDErr(t *testing.T) {
 	txHelper, _ := NewTransactionHelper(ctx, "ns1", mdi, mdm, cmi)
 
 	evID := fftypes.NewUUID()
-	mdi.On("GetBlockchainEventByID", ctx, "ns1", evID).Return(nil, fmt.Errorf("pop"))
+	mdi.On("GetBlockchainEventByID", primaryReads, "ns1", evID).Return(nil, fmt.Errorf("pop"))
 
 	_, err := txHelper.GetBlockchainEventByIDCached(ctx, evID)
 	assert.Regexp(t, "pop", err)
@@ -715,7 +736,7 @@ func TestInsertBlockchainEventDuplicate(t *testing.T) {
 	existingEvent := &core.BlockchainEvent{}
 	txHelper.mdi.On("InsertBlockchainEvents", ctx, []*core.BlockchainEvent{chainEvent}, mock.Anything).Return(fmt.Errorf("optimization bypass"))
 	txHelper.mdi.On("InsertOrGetBlockchainEvent", ctx, chainEvent).Return(existingEvent, nil)
-	txHelper.mdi.On("GetEvents", ctx, "ns1", mock.Anything).Return([]*core.Event{{}}, nil, nil)
+	txHelper.mdi.On("GetEvents", primaryReads, "ns1", mock.Anything).Return([]*core.Event{{}}, nil, nil)
 
 	result, err := txHelper.InsertNewBlockchainEvents(ctx, []*core.BlockchainEvent{chainEvent})
 	assert.NoError(t, err)
@@ -736,7 +757,7 @@ func TestInsertBlockchainEventFailEventQuery(t *testing.T) {
 	existingEvent := &core.BlockchainEvent{}
 	txHelper.mdi.On("InsertBlockchainEvents", ctx, []*core.BlockchainEvent{chainEvent}, mock.Anything).Return(fmt.Errorf("optimization bypass"))
 	txHelper.mdi.On("InsertOrGetBlockchainEvent", ctx, chainEvent).Return(existingEvent, nil)
-	txHelper.mdi.On("GetEvents", ctx, "ns1", mock.Anything).Return(nil, nil, fmt.Errorf("pop"))
+	txHelper.mdi.On("GetEvents", primaryReads, "ns1", mock.Anything).Return(nil, nil, fmt.Errorf("pop"))
 
 	_, err := txHelper.InsertNewBlockchainEvents(ctx, []*core.BlockchainEvent{chainEvent})
 	assert.EqualError(t, err, "pop")
@@ -756,12 +777,217 @@ func TestInsertBlockchainEventPartialBatch(t *testing.T) {
 	existingEvent := &core.BlockchainEvent{}
 	txHelper.mdi.On("InsertBlockchainEvents", ctx, []*core.BlockchainEvent{chainEvent}, mock.Anything).Return(fmt.Errorf("optimization bypass"))
 	txHelper.mdi.On("InsertOrGetBlockchainEvent", ctx, chainEvent).Return(existingEvent, nil)
-	txHelper.mdi.On("GetEvents", ctx, "ns1", mock.Anything).Return([]*core.Event{}, nil, nil)
+	txHelper.mdi.On("GetEvents", primaryReads, "ns1", mock.Anything).Return([]*core.Event{}, nil, nil)
+
+	result, err := txHelper.InsertNewBlockchainEvents(ctx, []*core.BlockchainEvent{chainEvent})
+	assert.NoError(t, err)
+	assert.Len(t, result, 1)
+	assert.Equal(t, existingEvent, result[0])
+
+}
+
+func TestInsertBlockchainEventReinstateRemoved(t *testing.T) {
+
+	txHelper, _, _ := NewTestTransactionHelper()
+	defer txHelper.cleanup(t)
+	ctx := context.Background()
+
+	chainEvent := &core.BlockchainEvent{
+		ID:        fftypes.NewUUID(),
+		Namespace: "ns1",
+	}
+	existingEvent := &core.BlockchainEvent{
+		ID:      fftypes.NewUUID(),
+		Removed: true,
+	}
+	txHelper.mdi.On("InsertBlockchainEvents", ctx, []*core.BlockchainEvent{chainEvent}, mock.Anything).Return(fmt.Errorf("optimization bypass"))
+	txHelper.mdi.On("InsertOrGetBlockchainEvent", ctx, chainEvent).Return(existingEvent, nil)
+	txHelper.mdi.On("UpdateBlockchainEvent", ctx, "ns1", existingEvent.ID, mock.Anything).Return(nil)
+	txHelper.mdi.On("GetTokenTransfers", ctx, "ns1", mock.Anything).Return([]*core.TokenTransfer{}, nil, nil)
+	txHelper.mdi.On("UpdateTokenApprovals", ctx, mock.Anything, mock.Anything).Return(nil)
 
 	result, err := txHelper.InsertNewBlockchainEvents(ctx, []*core.BlockchainEvent{chainEvent})
 	assert.NoError(t, err)
 	assert.Len(t, result, 1)
 	assert.Equal(t, existingEvent, result[0])
+	assert.False(t, existingEvent.Removed)
+
+	cached, err := txHelper.GetBlockchainEventByIDCached(ctx, existingEvent.ID)
+	assert.NoError(t, err)
+	assert.False(t, cached.Removed)
+
+}
+
+func TestInsertBlockchainEventReinstateFail(t *testing.T) {
+
+	txHelper, _, _ := NewTestTransactionHelper()
+	defer txHelper.cleanup(t)
+	ctx := context.Background()
+
+	chainEvent := &core.BlockchainEvent{
+		ID:        fftypes.NewUUID(),
+		Namespace: "ns1",
+	}
+	existingEvent := &core.BlockchainEvent{
+		ID:      fftypes.NewUUID(),
+		Removed: true,
+	}
+	txHelper.mdi.On("InsertBlockchainEvents", ctx, []*core.BlockchainEvent{chainEvent}, mock.Anything).Return(fmt.Errorf("optimization bypass"))
+	txHelper.mdi.On("InsertOrGetBlockchainEvent", ctx, chainEvent).Return(existingEvent, nil)
+	txHelper.mdi.On("UpdateBlockchainEvent", ctx, "ns1", existingEvent.ID, mock.Anything).Return(fmt.Errorf("pop"))
+
+	_, err := txHelper.InsertNewBlockchainEvents(ctx, []*core.BlockchainEvent{chainEvent})
+	assert.EqualError(t, err, "pop")
+	assert.True(t, existingEvent.Removed)
+
+}
+
+func TestBlockchainEventRemoveAndReinstate(t *testing.T) {
+
+	txHelper, _, _ := NewTestTransactionHelper()
+	defer txHelper.cleanup(t)
+	ctx := context.Background()
+
+	chainEvent := &core.BlockchainEvent{
+		ID:         fftypes.NewUUID(),
+		Namespace:  "ns1",
+		Listener:   fftypes.NewUUID(),
+		ProtocolID: "000000000010/000020/000030",
+	}
+	tokenEvent := &core.BlockchainEvent{
+		ID:         fftypes.NewUUID(),
+		Namespace:  "ns1",
+		ProtocolID: "000000000010/000020/000030",
+	}
+	transfer := &core.TokenTransfer{
+		LocalID:         fftypes.NewUUID(),
+		From:            "0x1111",
+		To:              "0x2222",
+		Amount:          *fftypes.NewFFBigInt(10),
+		BlockchainEvent: tokenEvent.ID,
+	}
+	forEvent := func(eventID *fftypes.UUID, invalidated bool) interface{} {
+		return mock.MatchedBy(func(filter ffapi.Filter) bool {
+			info, _ := filter.Finalize()
+			return info.String() == fmt.Sprintf("( blockchainevent == '%s' ) && ( invalidated == %t )", eventID, invalidated)
+		})
+	}
+
+	txHelper.mdi.On("UpdateBlockchainEvent", ctx, "ns1", chainEvent.ID, mock.Anything).Return(nil)
+	txHelper.mdi.On("GetBlockchainEventByProtocolID", ctx, "ns1", (*fftypes.UUID)(nil), chainEvent.ProtocolID).Return(tokenEvent, nil)
+	txHelper.mdi.On("GetTokenTransfers", ctx, "ns1", forEvent(chainEvent.ID, false)).Return([]*core.TokenTransfer{}, nil, nil)
+	txHelper.mdi.On("GetTokenTransfers", ctx, "ns1", forEvent(tokenEvent.ID, false)).Return([]*core.TokenTransfer{transfer}, nil, nil)
+	txHelper.mdi.On("GetTokenTransfers", ctx, "ns1", forEvent(chainEvent.ID, true)).Return([]*core.TokenTransfer{}, nil, nil)
+	txHelper.mdi.On("GetTokenTransfers", ctx, "ns1", forEvent(tokenEvent.ID, true)).Return([]*core.TokenTransfer{transfer}, nil, nil)
+	txHelper.mdi.On("UpdateTokenBalances", ctx, mock.MatchedBy(func(balanceUpdate *core.TokenTransfer) bool {
+		return balanceUpdate.From == "0x2222" && balanceUpdate.To == "0x1111" && balanceUpdate.Amount.Int().Int64() == 10
+	})).Return(nil).Once()
+	txHelper.mdi.On("UpdateTokenBalances", ctx, mock.MatchedBy(func(balanceUpdate *core.TokenTransfer) bool {
+		return balanceUpdate.From == "0x1111" && balanceUpdate.To == "0x2222" && balanceUpdate.Amount.Int().Int64() == 10
+	})).Return(nil).Once()
+	txHelper.mdi.On("UpdateTokenTransfers", ctx, "ns1", mock.Anything, mock.MatchedBy(func(update ffapi.Update) bool {
+		info, _ := update.Finalize()
+		return info.String() == "invalidated=true"
+	})).Return(nil).Once()
+	txHelper.mdi.On("UpdateTokenTransfers", ctx, "ns1", mock.Anything, mock.MatchedBy(func(update ffapi.Update) bool {
+		info, _ := update.Finalize()
+		return info.String() == "invalidated=false"
+	})).Return(nil).Once()
+	txHelper.mdi.On("UpdateTokenApprovals", ctx, mock.Anything, mock.Anything).Return(nil).Times(4)
+
+	err := txHelper.SetBlockchainEventRemoved(ctx, chainEvent, true)
+	assert.NoError(t, err)
+	assert.True(t, chainEvent.Removed)
+	assert.True(t, transfer.Invalidated)
+
+	err = txHelper.SetBlockchainEventRemoved(ctx, chainEvent, false)
+	assert.NoError(t, err)
+	assert.False(t, chainEvent.Removed)
+	assert.False(t, transfer.Invalidated)
+
+}
+
+func TestBlockchainEventRemovedTokenEventFail(t *testing.T) {
+
+	txHelper, _, _ := NewTestTransactionHelper()
+	defer txHelper.cleanup(t)
+	ctx := context.Background()
+
+	chainEvent := &core.BlockchainEvent{
+		ID:         fftypes.NewUUID(),
+		Listener:   fftypes.NewUUID(),
+		ProtocolID: "10/20/30",
+	}
+	txHelper.mdi.On("UpdateBlockchainEvent", ctx, "ns1", chainEvent.ID, mock.Anything).Return(nil)
+	txHelper.mdi.On("GetBlockchainEventByProtocolID", ctx, "ns1", (*fftypes.UUID)(nil), "10/20/30").Return(nil, fmt.Errorf("pop"))
+
+	err := txHelper.SetBlockchainEventRemoved(ctx, chainEvent, true)
+	assert.EqualError(t, err, "pop")
+	assert.False(t, chainEvent.Removed)
+
+}
+
+func TestBlockchainEventRemovedGetTransfersFail(t *testing.T) {
+
+	txHelper, _, _ := NewTestTransactionHelper()
+	defer txHelper.cleanup(t)
+	ctx := context.Background()
+
+	chainEvent := &core.BlockchainEvent{ID: fftypes.NewUUID()}
+	txHelper.mdi.On("UpdateBlockchainEvent", ctx, "ns1", chainEvent.ID, mock.Anything).Return(nil)
+	txHelper.mdi.On("GetTokenTransfers", ctx, "ns1", mock.Anything).Return(nil, nil, fmt.Errorf("pop"))
+
+	err := txHelper.SetBlockchainEventRemoved(ctx, chainEvent, true)
+	assert.EqualError(t, err, "pop")
+
+}
+
+func TestBlockchainEventRemovedBalancesFail(t *testing.T) {
+
+	txHelper, _, _ := NewTestTransactionHelper()
+	defer txHelper.cleanup(t)
+	ctx := context.Background()
+
+	chainEvent := &core.BlockchainEvent{ID: fftypes.NewUUID()}
+	txHelper.mdi.On("UpdateBlockchainEvent", ctx, "ns1", chainEvent.ID, mock.Anything).Return(nil)
+	txHelper.mdi.On("GetTokenTransfers", ctx, "ns1", mock.Anything).Return([]*core.TokenTransfer{{LocalID: fftypes.NewUUID()}}, nil, nil)
+	txHelper.mdi.On("UpdateTokenBalances", ctx, mock.Anything).Return(fmt.Errorf("pop"))
+
+	err := txHelper.SetBlockchainEventRemoved(ctx, chainEvent, true)
+	assert.EqualError(t, err, "pop")
+
+}
+
+func TestBlockchainEventRemovedTransfersFail(t *testing.T) {
+
+	txHelper, _, _ := NewTestTransactionHelper()
+	defer txHelper.cleanup(t)
+	ctx := context.Background()
+
+	chainEvent := &core.BlockchainEvent{ID: fftypes.NewUUID()}
+	txHelper.mdi.On("UpdateBlockchainEvent", ctx, "ns1", chainEvent.ID, mock.Anything).Return(nil)
+	txHelper.mdi.On("GetTokenTransfers", ctx, "ns1", mock.Anything).Return([]*core.TokenTransfer{{LocalID: fftypes.NewUUID()}}, nil, nil)
+	txHelper.mdi.On("UpdateTokenBalances", ctx, mock.Anything).Return(nil)
+	txHelper.mdi.On("UpdateTokenTransfers", ctx, "ns1", mock.Anything, mock.Anything).Return(fmt.Errorf("pop"))
+
+	err := txHelper.SetBlockchainEventRemoved(ctx, chainEvent, true)
+	assert.EqualError(t, err, "pop")
+
+}
+
+func TestBlockchainEventRemovedApprovalsFail(t *testing.T) {
+
+	txHelper, _, _ := NewTestTransactionHelper()
+	defer txHelper.cleanup(t)
+	ctx := context.Background()
+
+	chainEvent := &core.BlockchainEvent{ID: fftypes.NewUUID()}
+	txHelper.mdi.On("UpdateBlockchainEvent", ctx, "ns1", chainEvent.ID, mock.Anything).Return(nil)
+	txHelper.mdi.On("GetTokenTransfers", ctx, "ns1", mock.Anything).Return([]*core.TokenTransfer{}, nil, nil)
+	txHelper.mdi.On("UpdateTokenApprovals", ctx, mock.Anything, mock.Anything).Return(fmt.Errorf("pop"))
+
+	err := txHelper.SetBlockchainEventRemoved(ctx, chainEvent, true)
+	assert.EqualError(t, err, "pop")
 
 }
 
@@ -801,7 +1027,7 @@ func TestFindOperationInTransaction(t *testing.T) {
 	ops := []*core.Operation{{
 		ID: fftypes.NewUUID(),
 	}}
-	mdi.On("GetOperations", ctx, "ns1", mock.Anything).Return(ops, nil, nil)
+	mdi.On("GetOperations", primaryReads, "ns1", mock.Anything).Return(ops, nil, nil)
 
 	result, err := txHelper.FindOperationInTransaction(ctx, txID, core.OpTypeTokenTransfer)
 
@@ -820,7 +1046,7 @@ func TestFindOperationInTransactionFail(t *testing.T) {
 	txHelper, _ := NewTransactionHelper(ctx, "ns1", mdi, mdm, cmi)
 
 	txID := fftypes.NewUUID()
-	mdi.On("GetOperations", ctx, "ns1", mock.Anything).Return(nil, nil, fmt.Errorf("pop"))
+	mdi.On("GetOperations", primaryReads, "ns1", mock.Anything).Return(nil, nil, fmt.Errorf("pop"))
 
 	_, err := txHelper.FindOperationInTransaction(ctx, txID, core.OpTypeTokenTransfer)
 
@@ -27,6 +27,7 @@ import (
 	"github.com/hyperledger/firefly/internal/coreconfig"
 	"github.com/hyperledger/firefly/internal/coremsgs"
 	"github.com/hyperledger/firefly/internal/operations"
+	"github.com/hyperledger/firefly/internal/tracing"
 	"github.com/hyperledger/firefly/internal/txcommon"
 	"github.com/hyperledger/firefly/pkg/core"
 	"github.com/hyperledger/firefly/pkg/database"
@@ -102,6 +103,13 @@ func NewTransactionWriter(ctx context.Context, ns string, di database.Plugin, tx
 }
 
 func (tw *txWriter) WriteTransactionAndOps(ctx context.Context, txType core.TransactionType, idempotencyKey core.IdempotencyKey, operations ...*core.Operation) (*core.Transaction, error) {
+	// The operations are written on a background worker, so store the trace of the caller on them now
+	traceContext := tracing.TraceContext(ctx)
+	for _, op := range operations {
+		if op.TraceContext == nil {
+			op.TraceContext = traceContext
+		}
+	}
 	req := &request{
 		txType:         txType,
 		idempotencyKey: idempotencyKey,
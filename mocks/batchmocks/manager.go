@@ -101,6 +101,11 @@ func (_m *Manager) Start() error {
 	return r0
 }
 
+// StartSequencer provides a mock function with given fields:
+func (_m *Manager) StartSequencer() {
+	_m.Called()
+}
+
 // Status provides a mock function with given fields:
 func (_m *Manager) Status() *batch.ManagerStatus {
 	ret := _m.Called()
@@ -70,6 +70,24 @@ func (_m *Plugin) AddFireflySubscription(ctx context.Context, namespace *core.Na
 	return r0, r1
 }
 
+// BackfillContractListener provides a mock function with given fields: ctx, listener, backfill, started, progress
+func (_m *Plugin) BackfillContractListener(ctx context.Context, listener *core.ContractListener, backfill *core.ContractListenerBackfill, started func(string) error, progress func(uint64)) error {
+	ret := _m.Called(ctx, listener, backfill, started, progress)
+
+	if len(ret) == 0 {
+		panic("no return value specified for BackfillContractListener")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *core.ContractListener, *core.ContractListenerBackfill, func(string) error, func(uint64)) error); ok {
+		r0 = rf(ctx, listener, backfill, started, progress)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // Capabilities provides a mock function with given fields:
 func (_m *Plugin) Capabilities() *blockchain.Capabilities {
 	ret := _m.Called()
@@ -118,6 +136,26 @@ func (_m *Plugin) CheckOverlappingLocations(ctx context.Context, left *fftypes.J
 	return r0, r1
 }
 
+// DecodeContractError provides a mock function with given fields: ctx, errors, errorMessage
+func (_m *Plugin) DecodeContractError(ctx context.Context, errors []*fftypes.FFIError, errorMessage string) *core.ContractError {
+	ret := _m.Called(ctx, errors, errorMessage)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DecodeContractError")
+	}
+
+	var r0 *core.ContractError
+	if rf, ok := ret.Get(0).(func(context.Context, []*fftypes.FFIError, string) *core.ContractError); ok {
+		r0 = rf(ctx, errors, errorMessage)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*core.ContractError)
+		}
+	}
+
+	return r0
+}
+
 // DeleteContractListener provides a mock function with given fields: ctx, subscription, okNotFound
 func (_m *Plugin) DeleteContractListener(ctx context.Context, subscription *core.ContractListener, okNotFound bool) error {
 	ret := _m.Called(ctx, subscription, okNotFound)
@@ -305,6 +343,36 @@ func (_m *Plugin) GetAndConvertDeprecatedContractConfig(ctx context.Context) (*f
 	return r0, r1, r2
 }
 
+// GetContractListenerHealth provides a mock function with given fields: ctx, namespace, subID
+func (_m *Plugin) GetContractListenerHealth(ctx context.Context, namespace string, subID string) (*core.ContractListenerHealth, error) {
+	ret := _m.Called(ctx, namespace, subID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetContractListenerHealth")
+	}
+
+	var r0 *core.ContractListenerHealth
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) (*core.ContractListenerHealth, error)); ok {
+		return rf(ctx, namespace, subID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) *core.ContractListenerHealth); ok {
+		r0 = rf(ctx, namespace, subID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*core.ContractListenerHealth)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = rf(ctx, namespace, subID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetContractListenerStatus provides a mock function with given fields: ctx, namespace, subID, okNotFound
 func (_m *Plugin) GetContractListenerStatus(ctx context.Context, namespace string, subID string, okNotFound bool) (bool, interface{}, fftypes.FFEnum, error) {
 	ret := _m.Called(ctx, namespace, subID, okNotFound)
@@ -79,6 +79,36 @@ func (_m *Manager) AddContractListener(ctx context.Context, listener *core.Contr
 	return r0, r1
 }
 
+// BackfillContractListener provides a mock function with given fields: ctx, nameOrID, req
+func (_m *Manager) BackfillContractListener(ctx context.Context, nameOrID string, req *core.ContractListenerBackfillRequest) (*core.ContractListenerBackfill, error) {
+	ret := _m.Called(ctx, nameOrID, req)
+
+	if len(ret) == 0 {
+		panic("no return value specified for BackfillContractListener")
+	}
+
+	var r0 *core.ContractListenerBackfill
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, *core.ContractListenerBackfillRequest) (*core.ContractListenerBackfill, error)); ok {
+		return rf(ctx, nameOrID, req)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, *core.ContractListenerBackfillRequest) *core.ContractListenerBackfill); ok {
+		r0 = rf(ctx, nameOrID, req)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*core.ContractListenerBackfill)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, *core.ContractListenerBackfillRequest) error); ok {
+		r1 = rf(ctx, nameOrID, req)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // ConstructContractListenerSignature provides a mock function with given fields: ctx, listener
 func (_m *Manager) ConstructContractListenerSignature(ctx context.Context, listener *core.ContractListenerInput) (*core.ContractListenerSignatureOutput, error) {
 	ret := _m.Called(ctx, listener)
@@ -361,6 +391,36 @@ func (_m *Manager) GetContractAPIs(ctx context.Context, httpServerURL string, fi
 	return r0, r1, r2
 }
 
+// GetContractListenerBackfills provides a mock function with given fields: ctx, nameOrID
+func (_m *Manager) GetContractListenerBackfills(ctx context.Context, nameOrID string) ([]*core.ContractListenerBackfill, error) {
+	ret := _m.Called(ctx, nameOrID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetContractListenerBackfills")
+	}
+
+	var r0 []*core.ContractListenerBackfill
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) ([]*core.ContractListenerBackfill, error)); ok {
+		return rf(ctx, nameOrID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) []*core.ContractListenerBackfill); ok {
+		r0 = rf(ctx, nameOrID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*core.ContractListenerBackfill)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, nameOrID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetContractListenerByNameOrID provides a mock function with given fields: ctx, nameOrID
 func (_m *Manager) GetContractListenerByNameOrID(ctx context.Context, nameOrID string) (*core.ContractListener, error) {
 	ret := _m.Called(ctx, nameOrID)
@@ -878,6 +938,16 @@ func (_m *Manager) RunOperation(ctx context.Context, op *core.PreparedOperation)
 	return r0, r1, r2
 }
 
+// Start provides a mock function with given fields:
+func (_m *Manager) Start() {
+	_m.Called()
+}
+
+// WaitStop provides a mock function with given fields:
+func (_m *Manager) WaitStop() {
+	_m.Called()
+}
+
 // NewManager creates a new instance of Manager. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
 // The first argument is typically a *testing.T value.
 func NewManager(t interface {
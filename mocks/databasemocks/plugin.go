@@ -16,6 +16,8 @@ import (
 	fftypes "github.com/hyperledger/firefly-common/pkg/fftypes"
 
 	mock "github.com/stretchr/testify/mock"
+
+	time "time"
 )
 
 // Plugin is an autogenerated mock type for the Plugin type
@@ -23,6 +25,36 @@ type Plugin struct {
 	mock.Mock
 }
 
+// AcquireLease provides a mock function with given fields: ctx, namespace, name, holder, ttl
+func (_m *Plugin) AcquireLease(ctx context.Context, namespace string, name string, holder string, ttl time.Duration) (*core.Lease, error) {
+	ret := _m.Called(ctx, namespace, name, holder, ttl)
+
+	if len(ret) == 0 {
+		panic("no return value specified for AcquireLease")
+	}
+
+	var r0 *core.Lease
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, string, time.Duration) (*core.Lease, error)); ok {
+		return rf(ctx, namespace, name, holder, ttl)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, string, time.Duration) *core.Lease); ok {
+		r0 = rf(ctx, namespace, name, holder, ttl)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*core.Lease)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string, string, time.Duration) error); ok {
+		r1 = rf(ctx, namespace, name, holder, ttl)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // Capabilities provides a mock function with given fields:
 func (_m *Plugin) Capabilities() *database.Capabilities {
 	ret := _m.Called()
@@ -43,6 +75,24 @@ func (_m *Plugin) Capabilities() *database.Capabilities {
 	return r0
 }
 
+// DeleteAPIKey provides a mock function with given fields: ctx, namespace, id
+func (_m *Plugin) DeleteAPIKey(ctx context.Context, namespace string, id *fftypes.UUID) error {
+	ret := _m.Called(ctx, namespace, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteAPIKey")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, *fftypes.UUID) error); ok {
+		r0 = rf(ctx, namespace, id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // DeleteBlob provides a mock function with given fields: ctx, sequence
 func (_m *Plugin) DeleteBlob(ctx context.Context, sequence int64) error {
 	ret := _m.Called(ctx, sequence)
@@ -61,6 +111,24 @@ func (_m *Plugin) DeleteBlob(ctx context.Context, sequence int64) error {
 	return r0
 }
 
+// DeleteClusterNotifications provides a mock function with given fields: ctx, namespace, topic, before
+func (_m *Plugin) DeleteClusterNotifications(ctx context.Context, namespace string, topic string, before *fftypes.FFTime) error {
+	ret := _m.Called(ctx, namespace, topic, before)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteClusterNotifications")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, *fftypes.FFTime) error); ok {
+		r0 = rf(ctx, namespace, topic, before)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // DeleteContractAPI provides a mock function with given fields: ctx, namespace, id
 func (_m *Plugin) DeleteContractAPI(ctx context.Context, namespace string, id *fftypes.UUID) error {
 	ret := _m.Called(ctx, namespace, id)
@@ -79,6 +147,42 @@ func (_m *Plugin) DeleteContractAPI(ctx context.Context, namespace string, id *f
 	return r0
 }
 
+// DeleteContractListenerBackfill provides a mock function with given fields: ctx, namespace, id
+func (_m *Plugin) DeleteContractListenerBackfill(ctx context.Context, namespace string, id *fftypes.UUID) error {
+	ret := _m.Called(ctx, namespace, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteDeadLetter")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, *fftypes.UUID) error); ok {
+		r0 = rf(ctx, namespace, id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// DeleteContractListenerBackfills provides a mock function with given fields: ctx, namespace, listenerID
+func (_m *Plugin) DeleteContractListenerBackfills(ctx context.Context, namespace string, listenerID *fftypes.UUID) error {
+	ret := _m.Called(ctx, namespace, listenerID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteDeadLetter")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, *fftypes.UUID) error); ok {
+		r0 = rf(ctx, namespace, listenerID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // DeleteContractListenerByID provides a mock function with given fields: ctx, namespace, id
 func (_m *Plugin) DeleteContractListenerByID(ctx context.Context, namespace string, id *fftypes.UUID) error {
 	ret := _m.Called(ctx, namespace, id)
@@ -115,6 +219,24 @@ func (_m *Plugin) DeleteData(ctx context.Context, namespace string, id *fftypes.
 	return r0
 }
 
+// DeleteDeadLetter provides a mock function with given fields: ctx, namespace, id
+func (_m *Plugin) DeleteDeadLetter(ctx context.Context, namespace string, id *fftypes.UUID) error {
+	ret := _m.Called(ctx, namespace, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteDeadLetter")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, *fftypes.UUID) error); ok {
+		r0 = rf(ctx, namespace, id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // DeleteFFI provides a mock function with given fields: ctx, namespace, id
 func (_m *Plugin) DeleteFFI(ctx context.Context, namespace string, id *fftypes.UUID) error {
 	ret := _m.Called(ctx, namespace, id)
@@ -259,6 +381,105 @@ func (_m *Plugin) DeleteTokenTransfers(ctx context.Context, namespace string, po
 	return r0
 }
 
+// GetAPIKeyByHash provides a mock function with given fields: ctx, namespace, hash
+func (_m *Plugin) GetAPIKeyByHash(ctx context.Context, namespace string, hash string) (*core.APIKey, error) {
+	ret := _m.Called(ctx, namespace, hash)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetAPIKeyByHash")
+	}
+
+	var r0 *core.APIKey
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) (*core.APIKey, error)); ok {
+		return rf(ctx, namespace, hash)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) *core.APIKey); ok {
+		r0 = rf(ctx, namespace, hash)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*core.APIKey)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = rf(ctx, namespace, hash)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetAPIKeyByID provides a mock function with given fields: ctx, namespace, id
+func (_m *Plugin) GetAPIKeyByID(ctx context.Context, namespace string, id *fftypes.UUID) (*core.APIKey, error) {
+	ret := _m.Called(ctx, namespace, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetAPIKeyByID")
+	}
+
+	var r0 *core.APIKey
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, *fftypes.UUID) (*core.APIKey, error)); ok {
+		return rf(ctx, namespace, id)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, *fftypes.UUID) *core.APIKey); ok {
+		r0 = rf(ctx, namespace, id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*core.APIKey)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, *fftypes.UUID) error); ok {
+		r1 = rf(ctx, namespace, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetAPIKeys provides a mock function with given fields: ctx, namespace, filter
+func (_m *Plugin) GetAPIKeys(ctx context.Context, namespace string, filter ffapi.Filter) ([]*core.APIKey, *ffapi.FilterResult, error) {
+	ret := _m.Called(ctx, namespace, filter)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetAPIKeys")
+	}
+
+	var r0 []*core.APIKey
+	var r1 *ffapi.FilterResult
+	var r2 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, ffapi.Filter) ([]*core.APIKey, *ffapi.FilterResult, error)); ok {
+		return rf(ctx, namespace, filter)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, ffapi.Filter) []*core.APIKey); ok {
+		r0 = rf(ctx, namespace, filter)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*core.APIKey)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, ffapi.Filter) *ffapi.FilterResult); ok {
+		r1 = rf(ctx, namespace, filter)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*ffapi.FilterResult)
+		}
+	}
+
+	if rf, ok := ret.Get(2).(func(context.Context, string, ffapi.Filter) error); ok {
+		r2 = rf(ctx, namespace, filter)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
 // GetBatchByID provides a mock function with given fields: ctx, namespace, id
 func (_m *Plugin) GetBatchByID(ctx context.Context, namespace string, id *fftypes.UUID) (*core.BatchPersisted, error) {
 	ret := _m.Called(ctx, namespace, id)
@@ -526,6 +747,36 @@ func (_m *Plugin) GetBlockchainEvents(ctx context.Context, namespace string, fil
 	return r0, r1, r2
 }
 
+// GetChartAggregates provides a mock function with given fields: ctx, namespace, intervals, collection, query
+func (_m *Plugin) GetChartAggregates(ctx context.Context, namespace string, intervals []core.ChartHistogramInterval, collection database.CollectionName, query *database.ChartAggregateQuery) ([]*core.ChartAggregate, error) {
+	ret := _m.Called(ctx, namespace, intervals, collection, query)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetChartAggregates")
+	}
+
+	var r0 []*core.ChartAggregate
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, []core.ChartHistogramInterval, database.CollectionName, *database.ChartAggregateQuery) ([]*core.ChartAggregate, error)); ok {
+		return rf(ctx, namespace, intervals, collection, query)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, []core.ChartHistogramInterval, database.CollectionName, *database.ChartAggregateQuery) []*core.ChartAggregate); ok {
+		r0 = rf(ctx, namespace, intervals, collection, query)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*core.ChartAggregate)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, []core.ChartHistogramInterval, database.CollectionName, *database.ChartAggregateQuery) error); ok {
+		r1 = rf(ctx, namespace, intervals, collection, query)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetChartHistogram provides a mock function with given fields: ctx, namespace, intervals, collection
 func (_m *Plugin) GetChartHistogram(ctx context.Context, namespace string, intervals []core.ChartHistogramInterval, collection database.CollectionName) ([]*core.ChartHistogram, error) {
 	ret := _m.Called(ctx, namespace, intervals, collection)
@@ -556,6 +807,45 @@ func (_m *Plugin) GetChartHistogram(ctx context.Context, namespace string, inter
 	return r0, r1
 }
 
+// GetClusterNotifications provides a mock function with given fields: ctx, namespace, filter
+func (_m *Plugin) GetClusterNotifications(ctx context.Context, namespace string, filter ffapi.Filter) ([]*core.ClusterNotification, *ffapi.FilterResult, error) {
+	ret := _m.Called(ctx, namespace, filter)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetClusterNotifications")
+	}
+
+	var r0 []*core.ClusterNotification
+	var r1 *ffapi.FilterResult
+	var r2 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, ffapi.Filter) ([]*core.ClusterNotification, *ffapi.FilterResult, error)); ok {
+		return rf(ctx, namespace, filter)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, ffapi.Filter) []*core.ClusterNotification); ok {
+		r0 = rf(ctx, namespace, filter)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*core.ClusterNotification)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, ffapi.Filter) *ffapi.FilterResult); ok {
+		r1 = rf(ctx, namespace, filter)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*ffapi.FilterResult)
+		}
+	}
+
+	if rf, ok := ret.Get(2).(func(context.Context, string, ffapi.Filter) error); ok {
+		r2 = rf(ctx, namespace, filter)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
 // GetContractAPIByID provides a mock function with given fields: ctx, namespace, id
 func (_m *Plugin) GetContractAPIByID(ctx context.Context, namespace string, id *fftypes.UUID) (*core.ContractAPI, error) {
 	ret := _m.Called(ctx, namespace, id)
@@ -706,13 +996,82 @@ func (_m *Plugin) GetContractListener(ctx context.Context, namespace string, nam
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
-		r1 = rf(ctx, namespace, name)
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = rf(ctx, namespace, name)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetContractListenerBackfillByID provides a mock function with given fields: ctx, namespace, id
+func (_m *Plugin) GetContractListenerBackfillByID(ctx context.Context, namespace string, id *fftypes.UUID) (*core.ContractListenerBackfill, error) {
+	ret := _m.Called(ctx, namespace, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetDeadLetterByID")
+	}
+
+	var r0 *core.ContractListenerBackfill
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, *fftypes.UUID) (*core.ContractListenerBackfill, error)); ok {
+		return rf(ctx, namespace, id)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, *fftypes.UUID) *core.ContractListenerBackfill); ok {
+		r0 = rf(ctx, namespace, id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*core.ContractListenerBackfill)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, *fftypes.UUID) error); ok {
+		r1 = rf(ctx, namespace, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetContractListenerBackfills provides a mock function with given fields: ctx, namespace, filter
+func (_m *Plugin) GetContractListenerBackfills(ctx context.Context, namespace string, filter ffapi.Filter) ([]*core.ContractListenerBackfill, *ffapi.FilterResult, error) {
+	ret := _m.Called(ctx, namespace, filter)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetDeadLetters")
+	}
+
+	var r0 []*core.ContractListenerBackfill
+	var r1 *ffapi.FilterResult
+	var r2 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, ffapi.Filter) ([]*core.ContractListenerBackfill, *ffapi.FilterResult, error)); ok {
+		return rf(ctx, namespace, filter)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, ffapi.Filter) []*core.ContractListenerBackfill); ok {
+		r0 = rf(ctx, namespace, filter)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*core.ContractListenerBackfill)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, ffapi.Filter) *ffapi.FilterResult); ok {
+		r1 = rf(ctx, namespace, filter)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*ffapi.FilterResult)
+		}
+	}
+
+	if rf, ok := ret.Get(2).(func(context.Context, string, ffapi.Filter) error); ok {
+		r2 = rf(ctx, namespace, filter)
 	} else {
-		r1 = ret.Error(1)
+		r2 = ret.Error(2)
 	}
 
-	return r0, r1
+	return r0, r1, r2
 }
 
 // GetContractListenerByBackendID provides a mock function with given fields: ctx, namespace, id
@@ -1051,6 +1410,75 @@ func (_m *Plugin) GetDatatypes(ctx context.Context, namespace string, filter ffa
 	return r0, r1, r2
 }
 
+// GetDeadLetterByID provides a mock function with given fields: ctx, namespace, id
+func (_m *Plugin) GetDeadLetterByID(ctx context.Context, namespace string, id *fftypes.UUID) (*core.DeadLetter, error) {
+	ret := _m.Called(ctx, namespace, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetDeadLetterByID")
+	}
+
+	var r0 *core.DeadLetter
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, *fftypes.UUID) (*core.DeadLetter, error)); ok {
+		return rf(ctx, namespace, id)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, *fftypes.UUID) *core.DeadLetter); ok {
+		r0 = rf(ctx, namespace, id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*core.DeadLetter)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, *fftypes.UUID) error); ok {
+		r1 = rf(ctx, namespace, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetDeadLetters provides a mock function with given fields: ctx, namespace, filter
+func (_m *Plugin) GetDeadLetters(ctx context.Context, namespace string, filter ffapi.Filter) ([]*core.DeadLetter, *ffapi.FilterResult, error) {
+	ret := _m.Called(ctx, namespace, filter)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetDeadLetters")
+	}
+
+	var r0 []*core.DeadLetter
+	var r1 *ffapi.FilterResult
+	var r2 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, ffapi.Filter) ([]*core.DeadLetter, *ffapi.FilterResult, error)); ok {
+		return rf(ctx, namespace, filter)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, ffapi.Filter) []*core.DeadLetter); ok {
+		r0 = rf(ctx, namespace, filter)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*core.DeadLetter)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, ffapi.Filter) *ffapi.FilterResult); ok {
+		r1 = rf(ctx, namespace, filter)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*ffapi.FilterResult)
+		}
+	}
+
+	if rf, ok := ret.Get(2).(func(context.Context, string, ffapi.Filter) error); ok {
+		r2 = rf(ctx, namespace, filter)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
 // GetEventByID provides a mock function with given fields: ctx, namespace, id
 func (_m *Plugin) GetEventByID(ctx context.Context, namespace string, id *fftypes.UUID) (*core.Event, error) {
 	ret := _m.Called(ctx, namespace, id)
@@ -2146,6 +2574,45 @@ func (_m *Plugin) GetPins(ctx context.Context, namespace string, filter ffapi.Fi
 	return r0, r1, r2
 }
 
+// GetPurgeableData provides a mock function with given fields: ctx, namespace, filter, messagesBefore
+func (_m *Plugin) GetPurgeableData(ctx context.Context, namespace string, filter ffapi.Filter, messagesBefore *fftypes.FFTime) (core.DataArray, *ffapi.FilterResult, error) {
+	ret := _m.Called(ctx, namespace, filter, messagesBefore)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetPurgeableData")
+	}
+
+	var r0 core.DataArray
+	var r1 *ffapi.FilterResult
+	var r2 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, ffapi.Filter, *fftypes.FFTime) (core.DataArray, *ffapi.FilterResult, error)); ok {
+		return rf(ctx, namespace, filter, messagesBefore)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, ffapi.Filter, *fftypes.FFTime) core.DataArray); ok {
+		r0 = rf(ctx, namespace, filter, messagesBefore)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(core.DataArray)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, ffapi.Filter, *fftypes.FFTime) *ffapi.FilterResult); ok {
+		r1 = rf(ctx, namespace, filter, messagesBefore)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*ffapi.FilterResult)
+		}
+	}
+
+	if rf, ok := ret.Get(2).(func(context.Context, string, ffapi.Filter, *fftypes.FFTime) error); ok {
+		r2 = rf(ctx, namespace, filter, messagesBefore)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
 // GetSubscriptionByID provides a mock function with given fields: ctx, namespace, id
 func (_m *Plugin) GetSubscriptionByID(ctx context.Context, namespace string, id *fftypes.UUID) (*core.Subscription, error) {
 	ret := _m.Called(ctx, namespace, id)
@@ -2910,6 +3377,24 @@ func (_m *Plugin) InitConfig(_a0 config.Section) {
 	_m.Called(_a0)
 }
 
+// InsertAPIKey provides a mock function with given fields: ctx, apiKey
+func (_m *Plugin) InsertAPIKey(ctx context.Context, apiKey *core.APIKey) error {
+	ret := _m.Called(ctx, apiKey)
+
+	if len(ret) == 0 {
+		panic("no return value specified for InsertAPIKey")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *core.APIKey) error); ok {
+		r0 = rf(ctx, apiKey)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // InsertBlob provides a mock function with given fields: ctx, blob
 func (_m *Plugin) InsertBlob(ctx context.Context, blob *core.Blob) error {
 	ret := _m.Called(ctx, blob)
@@ -2971,6 +3456,24 @@ func (_m *Plugin) InsertBlockchainEvents(ctx context.Context, messages []*core.B
 	return r0
 }
 
+// InsertClusterNotification provides a mock function with given fields: ctx, notification
+func (_m *Plugin) InsertClusterNotification(ctx context.Context, notification *core.ClusterNotification) error {
+	ret := _m.Called(ctx, notification)
+
+	if len(ret) == 0 {
+		panic("no return value specified for InsertClusterNotification")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *core.ClusterNotification) error); ok {
+		r0 = rf(ctx, notification)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // InsertContractListener provides a mock function with given fields: ctx, sub
 func (_m *Plugin) InsertContractListener(ctx context.Context, sub *core.ContractListener) error {
 	ret := _m.Called(ctx, sub)
@@ -2989,6 +3492,24 @@ func (_m *Plugin) InsertContractListener(ctx context.Context, sub *core.Contract
 	return r0
 }
 
+// InsertContractListenerBackfill provides a mock function with given fields: ctx, backfill
+func (_m *Plugin) InsertContractListenerBackfill(ctx context.Context, backfill *core.ContractListenerBackfill) error {
+	ret := _m.Called(ctx, backfill)
+
+	if len(ret) == 0 {
+		panic("no return value specified for InsertDeadLetter")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *core.ContractListenerBackfill) error); ok {
+		r0 = rf(ctx, backfill)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // InsertDataArray provides a mock function with given fields: ctx, data
 func (_m *Plugin) InsertDataArray(ctx context.Context, data core.DataArray) error {
 	ret := _m.Called(ctx, data)
@@ -3007,6 +3528,24 @@ func (_m *Plugin) InsertDataArray(ctx context.Context, data core.DataArray) erro
 	return r0
 }
 
+// InsertDeadLetter provides a mock function with given fields: ctx, deadLetter
+func (_m *Plugin) InsertDeadLetter(ctx context.Context, deadLetter *core.DeadLetter) error {
+	ret := _m.Called(ctx, deadLetter)
+
+	if len(ret) == 0 {
+		panic("no return value specified for InsertDeadLetter")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *core.DeadLetter) error); ok {
+		r0 = rf(ctx, deadLetter)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // InsertEvent provides a mock function with given fields: ctx, data
 func (_m *Plugin) InsertEvent(ctx context.Context, data *core.Event) error {
 	ret := _m.Called(ctx, data)
@@ -3370,6 +3909,36 @@ func (_m *Plugin) InsertTransactions(ctx context.Context, txns []*core.Transacti
 	return r0
 }
 
+// ListenClusterNotifications provides a mock function with given fields: ctx, namespace, topic
+func (_m *Plugin) ListenClusterNotifications(ctx context.Context, namespace string, topic string) (<-chan *core.ClusterNotification, error) {
+	ret := _m.Called(ctx, namespace, topic)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListenClusterNotifications")
+	}
+
+	var r0 <-chan *core.ClusterNotification
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) (<-chan *core.ClusterNotification, error)); ok {
+		return rf(ctx, namespace, topic)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) <-chan *core.ClusterNotification); ok {
+		r0 = rf(ctx, namespace, topic)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(<-chan *core.ClusterNotification)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = rf(ctx, namespace, topic)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // Name provides a mock function with given fields:
 func (_m *Plugin) Name() string {
 	ret := _m.Called()
@@ -3388,6 +3957,42 @@ func (_m *Plugin) Name() string {
 	return r0
 }
 
+// PurgeRecords provides a mock function with given fields: ctx, namespace, collection, ids
+func (_m *Plugin) PurgeRecords(ctx context.Context, namespace string, collection database.CollectionName, ids []*fftypes.UUID) error {
+	ret := _m.Called(ctx, namespace, collection, ids)
+
+	if len(ret) == 0 {
+		panic("no return value specified for PurgeRecords")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, database.CollectionName, []*fftypes.UUID) error); ok {
+		r0 = rf(ctx, namespace, collection, ids)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// ReleaseLease provides a mock function with given fields: ctx, namespace, name, holder
+func (_m *Plugin) ReleaseLease(ctx context.Context, namespace string, name string, holder string) error {
+	ret := _m.Called(ctx, namespace, name, holder)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ReleaseLease")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, string) error); ok {
+		r0 = rf(ctx, namespace, name, holder)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // ReplaceMessage provides a mock function with given fields: ctx, message
 func (_m *Plugin) ReplaceMessage(ctx context.Context, message *core.Message) error {
 	ret := _m.Called(ctx, message)
@@ -3424,6 +4029,24 @@ func (_m *Plugin) RunAsGroup(ctx context.Context, fn func(context.Context) error
 	return r0
 }
 
+// RunAsReadSnapshot provides a mock function with given fields: ctx, fn
+func (_m *Plugin) RunAsReadSnapshot(ctx context.Context, fn func(context.Context) error) error {
+	ret := _m.Called(ctx, fn)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RunAsReadSnapshot")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, func(context.Context) error) error); ok {
+		r0 = rf(ctx, fn)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // SetHandler provides a mock function with given fields: namespace, handler
 func (_m *Plugin) SetHandler(namespace string, handler database.Callbacks) {
 	_m.Called(namespace, handler)
@@ -3447,6 +4070,24 @@ func (_m *Plugin) UpdateBatch(ctx context.Context, namespace string, id *fftypes
 	return r0
 }
 
+// UpdateBlockchainEvent provides a mock function with given fields: ctx, namespace, id, update
+func (_m *Plugin) UpdateBlockchainEvent(ctx context.Context, namespace string, id *fftypes.UUID, update ffapi.Update) error {
+	ret := _m.Called(ctx, namespace, id, update)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdateBlockchainEvent")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, *fftypes.UUID, ffapi.Update) error); ok {
+		r0 = rf(ctx, namespace, id, update)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // UpdateContractListener provides a mock function with given fields: ctx, namespace, id, update
 func (_m *Plugin) UpdateContractListener(ctx context.Context, namespace string, id *fftypes.UUID, update ffapi.Update) error {
 	ret := _m.Called(ctx, namespace, id, update)
@@ -3465,6 +4106,24 @@ func (_m *Plugin) UpdateContractListener(ctx context.Context, namespace string,
 	return r0
 }
 
+// UpdateContractListenerBackfill provides a mock function with given fields: ctx, namespace, id, update
+func (_m *Plugin) UpdateContractListenerBackfill(ctx context.Context, namespace string, id *fftypes.UUID, update ffapi.Update) error {
+	ret := _m.Called(ctx, namespace, id, update)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdateDeadLetter")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, *fftypes.UUID, ffapi.Update) error); ok {
+		r0 = rf(ctx, namespace, id, update)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // UpdateData provides a mock function with given fields: ctx, namespace, id, update
 func (_m *Plugin) UpdateData(ctx context.Context, namespace string, id *fftypes.UUID, update ffapi.Update) error {
 	ret := _m.Called(ctx, namespace, id, update)
@@ -3483,6 +4142,24 @@ func (_m *Plugin) UpdateData(ctx context.Context, namespace string, id *fftypes.
 	return r0
 }
 
+// UpdateDeadLetter provides a mock function with given fields: ctx, namespace, id, update
+func (_m *Plugin) UpdateDeadLetter(ctx context.Context, namespace string, id *fftypes.UUID, update ffapi.Update) error {
+	ret := _m.Called(ctx, namespace, id, update)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdateDeadLetter")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, *fftypes.UUID, ffapi.Update) error); ok {
+		r0 = rf(ctx, namespace, id, update)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // UpdateMessage provides a mock function with given fields: ctx, namespace, id, update
 func (_m *Plugin) UpdateMessage(ctx context.Context, namespace string, id *fftypes.UUID, update ffapi.Update) error {
 	ret := _m.Called(ctx, namespace, id, update)
@@ -3673,6 +4350,24 @@ func (_m *Plugin) UpdateTokenBalances(ctx context.Context, transfer *core.TokenT
 	return r0
 }
 
+// UpdateTokenTransfers provides a mock function with given fields: ctx, namespace, filter, update
+func (_m *Plugin) UpdateTokenTransfers(ctx context.Context, namespace string, filter ffapi.Filter, update ffapi.Update) error {
+	ret := _m.Called(ctx, namespace, filter, update)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdateTokenTransfers")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, ffapi.Filter, ffapi.Update) error); ok {
+		r0 = rf(ctx, namespace, filter, update)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // UpdateTransaction provides a mock function with given fields: ctx, namespace, id, update
 func (_m *Plugin) UpdateTransaction(ctx context.Context, namespace string, id *fftypes.UUID, update ffapi.Update) error {
 	ret := _m.Called(ctx, namespace, id, update)
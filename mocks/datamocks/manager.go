@@ -58,6 +58,24 @@ func (_m *Manager) CheckDatatype(ctx context.Context, datatype *core.Datatype) e
 	return r0
 }
 
+// DeleteBlob provides a mock function with given fields: ctx, blob
+func (_m *Manager) DeleteBlob(ctx context.Context, blob *core.Blob) error {
+	ret := _m.Called(ctx, blob)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteBlob")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *core.Blob) error); ok {
+		r0 = rf(ctx, blob)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // DeleteData provides a mock function with given fields: ctx, dataID
 func (_m *Manager) DeleteData(ctx context.Context, dataID string) error {
 	ret := _m.Called(ctx, dataID)
@@ -314,6 +314,24 @@ func (_m *EventManager) QueueBatchRewind(batchID *fftypes.UUID) {
 	_m.Called(batchID)
 }
 
+// ReplayDeadLetter provides a mock function with given fields: ctx, deadLetter
+func (_m *EventManager) ReplayDeadLetter(ctx context.Context, deadLetter *core.DeadLetter) error {
+	ret := _m.Called(ctx, deadLetter)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ReplayDeadLetter")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *core.DeadLetter) error); ok {
+		r0 = rf(ctx, deadLetter)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // ResolveTransportAndCapabilities provides a mock function with given fields: ctx, transportName
 func (_m *EventManager) ResolveTransportAndCapabilities(ctx context.Context, transportName string) (string, *pkgevents.Capabilities, error) {
 	ret := _m.Called(ctx, transportName)
@@ -417,6 +435,21 @@ func (_m *EventManager) Start() error {
 	return r0
 }
 
+// StartAggregator provides a mock function with given fields:
+func (_m *EventManager) StartAggregator() {
+	_m.Called()
+}
+
+// StartConnectionlessDispatchers provides a mock function with given fields:
+func (_m *EventManager) StartConnectionlessDispatchers() {
+	_m.Called()
+}
+
+// StopConnectionlessDispatchers provides a mock function with given fields:
+func (_m *EventManager) StopConnectionlessDispatchers() {
+	_m.Called()
+}
+
 // SubscriptionUpdates provides a mock function with given fields:
 func (_m *EventManager) SubscriptionUpdates() chan<- *fftypes.UUID {
 	ret := _m.Called()
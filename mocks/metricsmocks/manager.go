@@ -16,6 +16,11 @@ type Manager struct {
 	mock.Mock
 }
 
+// APIRequestRejected provides a mock function with given fields: namespace, scope, reason
+func (_m *Manager) APIRequestRejected(namespace string, scope string, reason string) {
+	_m.Called(namespace, scope, reason)
+}
+
 // AddTime provides a mock function with given fields: id
 func (_m *Manager) AddTime(id string) {
 	_m.Called(id)
@@ -41,6 +46,16 @@ func (_m *Manager) BlockchainTransaction(location string, methodName string) {
 	_m.Called(location, methodName)
 }
 
+// ContractListenerHealth provides a mock function with given fields: namespace, listenerID, health
+func (_m *Manager) ContractListenerHealth(namespace string, listenerID string, health *core.ContractListenerHealth) {
+	_m.Called(namespace, listenerID, health)
+}
+
+// ContractListenerRemoved provides a mock function with given fields: namespace, listenerID
+func (_m *Manager) ContractListenerRemoved(namespace string, listenerID string) {
+	_m.Called(namespace, listenerID)
+}
+
 // CountBatchPin provides a mock function with given fields:
 func (_m *Manager) CountBatchPin() {
 	_m.Called()
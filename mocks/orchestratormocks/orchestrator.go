@@ -28,6 +28,8 @@ import (
 
 	identity "github.com/hyperledger/firefly/internal/identity"
 
+	io "io"
+
 	mock "github.com/stretchr/testify/mock"
 
 	multiparty "github.com/hyperledger/firefly/internal/multiparty"
@@ -37,6 +39,8 @@ import (
 	operations "github.com/hyperledger/firefly/internal/operations"
 
 	privatemessaging "github.com/hyperledger/firefly/internal/privatemessaging"
+
+	time "time"
 )
 
 // Orchestrator is an autogenerated mock type for the Orchestrator type
@@ -44,6 +48,43 @@ type Orchestrator struct {
 	mock.Mock
 }
 
+// AcquireRequestQuota provides a mock function with given fields: ctx, _a1
+func (_m *Orchestrator) AcquireRequestQuota(ctx context.Context, _a1 string) (func(), time.Duration, error) {
+	ret := _m.Called(ctx, _a1)
+
+	if len(ret) == 0 {
+		panic("no return value specified for AcquireRequestQuota")
+	}
+
+	var r0 func()
+	var r1 time.Duration
+	var r2 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (func(), time.Duration, error)); ok {
+		return rf(ctx, _a1)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) func()); ok {
+		r0 = rf(ctx, _a1)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(func())
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) time.Duration); ok {
+		r1 = rf(ctx, _a1)
+	} else {
+		r1 = ret.Get(1).(time.Duration)
+	}
+
+	if rf, ok := ret.Get(2).(func(context.Context, string) error); ok {
+		r2 = rf(ctx, _a1)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
 // Assets provides a mock function with given fields:
 func (_m *Orchestrator) Assets() assets.Manager {
 	ret := _m.Called()
@@ -142,6 +183,36 @@ func (_m *Orchestrator) Contracts() contracts.Manager {
 	return r0
 }
 
+// CreateAPIKey provides a mock function with given fields: ctx, apiKey
+func (_m *Orchestrator) CreateAPIKey(ctx context.Context, apiKey *core.APIKey) (*core.APIKey, error) {
+	ret := _m.Called(ctx, apiKey)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CreateAPIKey")
+	}
+
+	var r0 *core.APIKey
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *core.APIKey) (*core.APIKey, error)); ok {
+		return rf(ctx, apiKey)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *core.APIKey) *core.APIKey); ok {
+		r0 = rf(ctx, apiKey)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*core.APIKey)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *core.APIKey) error); ok {
+		r1 = rf(ctx, apiKey)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // CreateSubscription provides a mock function with given fields: ctx, subDef
 func (_m *Orchestrator) CreateSubscription(ctx context.Context, subDef *core.Subscription) (*core.Subscription, error) {
 	ret := _m.Called(ctx, subDef)
@@ -242,6 +313,42 @@ func (_m *Orchestrator) DefinitionSender() definitions.Sender {
 	return r0
 }
 
+// DeleteAPIKey provides a mock function with given fields: ctx, id
+func (_m *Orchestrator) DeleteAPIKey(ctx context.Context, id string) error {
+	ret := _m.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteAPIKey")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) error); ok {
+		r0 = rf(ctx, id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// DeleteDeadLetter provides a mock function with given fields: ctx, subID, id
+func (_m *Orchestrator) DeleteDeadLetter(ctx context.Context, subID string, id string) error {
+	ret := _m.Called(ctx, subID, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteDeadLetter")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) error); ok {
+		r0 = rf(ctx, subID, id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // DeleteSubscription provides a mock function with given fields: ctx, id
 func (_m *Orchestrator) DeleteSubscription(ctx context.Context, id string) error {
 	ret := _m.Called(ctx, id)
@@ -280,6 +387,95 @@ func (_m *Orchestrator) Events() events.EventManager {
 	return r0
 }
 
+// ExportNamespace provides a mock function with given fields: ctx
+func (_m *Orchestrator) ExportNamespace(ctx context.Context) io.ReadCloser {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ExportNamespace")
+	}
+
+	var r0 io.ReadCloser
+	if rf, ok := ret.Get(0).(func(context.Context) io.ReadCloser); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(io.ReadCloser)
+		}
+	}
+
+	return r0
+}
+
+// GetAPIKeyByID provides a mock function with given fields: ctx, id
+func (_m *Orchestrator) GetAPIKeyByID(ctx context.Context, id string) (*core.APIKey, error) {
+	ret := _m.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetAPIKeyByID")
+	}
+
+	var r0 *core.APIKey
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (*core.APIKey, error)); ok {
+		return rf(ctx, id)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) *core.APIKey); ok {
+		r0 = rf(ctx, id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*core.APIKey)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetAPIKeys provides a mock function with given fields: ctx, filter
+func (_m *Orchestrator) GetAPIKeys(ctx context.Context, filter ffapi.AndFilter) ([]*core.APIKey, *ffapi.FilterResult, error) {
+	ret := _m.Called(ctx, filter)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetAPIKeys")
+	}
+
+	var r0 []*core.APIKey
+	var r1 *ffapi.FilterResult
+	var r2 error
+	if rf, ok := ret.Get(0).(func(context.Context, ffapi.AndFilter) ([]*core.APIKey, *ffapi.FilterResult, error)); ok {
+		return rf(ctx, filter)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, ffapi.AndFilter) []*core.APIKey); ok {
+		r0 = rf(ctx, filter)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*core.APIKey)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, ffapi.AndFilter) *ffapi.FilterResult); ok {
+		r1 = rf(ctx, filter)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*ffapi.FilterResult)
+		}
+	}
+
+	if rf, ok := ret.Get(2).(func(context.Context, ffapi.AndFilter) error); ok {
+		r2 = rf(ctx, filter)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
 // GetBatchByID provides a mock function with given fields: ctx, id
 func (_m *Orchestrator) GetBatchByID(ctx context.Context, id string) (*core.BatchPersisted, error) {
 	ret := _m.Called(ctx, id)
@@ -418,6 +614,36 @@ func (_m *Orchestrator) GetBlockchainEvents(ctx context.Context, filter ffapi.An
 	return r0, r1, r2
 }
 
+// GetChartAggregates provides a mock function with given fields: ctx, collection, input
+func (_m *Orchestrator) GetChartAggregates(ctx context.Context, collection database.CollectionName, input *core.ChartAggregateInput) ([]*core.ChartAggregate, error) {
+	ret := _m.Called(ctx, collection, input)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetChartAggregates")
+	}
+
+	var r0 []*core.ChartAggregate
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, database.CollectionName, *core.ChartAggregateInput) ([]*core.ChartAggregate, error)); ok {
+		return rf(ctx, collection, input)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, database.CollectionName, *core.ChartAggregateInput) []*core.ChartAggregate); ok {
+		r0 = rf(ctx, collection, input)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*core.ChartAggregate)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, database.CollectionName, *core.ChartAggregateInput) error); ok {
+		r1 = rf(ctx, collection, input)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetChartHistogram provides a mock function with given fields: ctx, startTime, endTime, buckets, tableName
 func (_m *Orchestrator) GetChartHistogram(ctx context.Context, startTime int64, endTime int64, buckets int64, tableName database.CollectionName) ([]*core.ChartHistogram, error) {
 	ret := _m.Called(ctx, startTime, endTime, buckets, tableName)
@@ -646,6 +872,75 @@ func (_m *Orchestrator) GetDatatypes(ctx context.Context, filter ffapi.AndFilter
 	return r0, r1, r2
 }
 
+// GetDeadLetterByID provides a mock function with given fields: ctx, subID, id
+func (_m *Orchestrator) GetDeadLetterByID(ctx context.Context, subID string, id string) (*core.DeadLetter, error) {
+	ret := _m.Called(ctx, subID, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetDeadLetterByID")
+	}
+
+	var r0 *core.DeadLetter
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) (*core.DeadLetter, error)); ok {
+		return rf(ctx, subID, id)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) *core.DeadLetter); ok {
+		r0 = rf(ctx, subID, id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*core.DeadLetter)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = rf(ctx, subID, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetDeadLetters provides a mock function with given fields: ctx, subID, filter
+func (_m *Orchestrator) GetDeadLetters(ctx context.Context, subID string, filter ffapi.AndFilter) ([]*core.DeadLetter, *ffapi.FilterResult, error) {
+	ret := _m.Called(ctx, subID, filter)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetDeadLetters")
+	}
+
+	var r0 []*core.DeadLetter
+	var r1 *ffapi.FilterResult
+	var r2 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, ffapi.AndFilter) ([]*core.DeadLetter, *ffapi.FilterResult, error)); ok {
+		return rf(ctx, subID, filter)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, ffapi.AndFilter) []*core.DeadLetter); ok {
+		r0 = rf(ctx, subID, filter)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*core.DeadLetter)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, ffapi.AndFilter) *ffapi.FilterResult); ok {
+		r1 = rf(ctx, subID, filter)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*ffapi.FilterResult)
+		}
+	}
+
+	if rf, ok := ret.Get(2).(func(context.Context, string, ffapi.AndFilter) error); ok {
+		r2 = rf(ctx, subID, filter)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
 // GetEventByID provides a mock function with given fields: ctx, id
 func (_m *Orchestrator) GetEventByID(ctx context.Context, id string) (*core.Event, error) {
 	ret := _m.Called(ctx, id)
@@ -784,6 +1079,26 @@ func (_m *Orchestrator) GetEventsWithReferences(ctx context.Context, filter ffap
 	return r0, r1, r2
 }
 
+// GetLeaderStatus provides a mock function with given fields: ctx
+func (_m *Orchestrator) GetLeaderStatus(ctx context.Context) *core.LeaderStatus {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetLeaderStatus")
+	}
+
+	var r0 *core.LeaderStatus
+	if rf, ok := ret.Get(0).(func(context.Context) *core.LeaderStatus); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*core.LeaderStatus)
+		}
+	}
+
+	return r0
+}
+
 // GetMessageByID provides a mock function with given fields: ctx, id
 func (_m *Orchestrator) GetMessageByID(ctx context.Context, id string) (*core.Message, error) {
 	ret := _m.Called(ctx, id)
@@ -1652,6 +1967,36 @@ func (_m *Orchestrator) Identity() identity.Manager {
 	return r0
 }
 
+// ImportNamespace provides a mock function with given fields: ctx, r, dryRun
+func (_m *Orchestrator) ImportNamespace(ctx context.Context, r io.Reader, dryRun bool) (*core.NamespaceImportResult, error) {
+	ret := _m.Called(ctx, r, dryRun)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ImportNamespace")
+	}
+
+	var r0 *core.NamespaceImportResult
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, io.Reader, bool) (*core.NamespaceImportResult, error)); ok {
+		return rf(ctx, r, dryRun)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, io.Reader, bool) *core.NamespaceImportResult); ok {
+		r0 = rf(ctx, r, dryRun)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*core.NamespaceImportResult)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, io.Reader, bool) error); ok {
+		r1 = rf(ctx, r, dryRun)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // Init provides a mock function with given fields:
 func (_m *Orchestrator) Init() error {
 	ret := _m.Called()
@@ -1730,9 +2075,39 @@ func (_m *Orchestrator) Operations() operations.Manager {
 	return r0
 }
 
-// PreInit provides a mock function with given fields: ctx, cancelCtx
-func (_m *Orchestrator) PreInit(ctx context.Context, cancelCtx context.CancelFunc) {
-	_m.Called(ctx, cancelCtx)
+// PreInit provides a mock function with given fields: ctx, restart
+func (_m *Orchestrator) PreInit(ctx context.Context, restart func()) {
+	_m.Called(ctx, restart)
+}
+
+// PreviewRetention provides a mock function with given fields: ctx, policy
+func (_m *Orchestrator) PreviewRetention(ctx context.Context, policy *core.RetentionPolicy) ([]*core.RetentionPreview, error) {
+	ret := _m.Called(ctx, policy)
+
+	if len(ret) == 0 {
+		panic("no return value specified for PreviewRetention")
+	}
+
+	var r0 []*core.RetentionPreview
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *core.RetentionPolicy) ([]*core.RetentionPreview, error)); ok {
+		return rf(ctx, policy)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *core.RetentionPolicy) []*core.RetentionPreview); ok {
+		r0 = rf(ctx, policy)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*core.RetentionPreview)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *core.RetentionPolicy) error); ok {
+		r1 = rf(ctx, policy)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
 }
 
 // PrivateMessaging provides a mock function with given fields:
@@ -1755,6 +2130,36 @@ func (_m *Orchestrator) PrivateMessaging() privatemessaging.Manager {
 	return r0
 }
 
+// ReplayDeadLetter provides a mock function with given fields: ctx, subID, id
+func (_m *Orchestrator) ReplayDeadLetter(ctx context.Context, subID string, id string) (*core.DeadLetter, error) {
+	ret := _m.Called(ctx, subID, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ReplayDeadLetter")
+	}
+
+	var r0 *core.DeadLetter
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) (*core.DeadLetter, error)); ok {
+		return rf(ctx, subID, id)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) *core.DeadLetter); ok {
+		r0 = rf(ctx, subID, id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*core.DeadLetter)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = rf(ctx, subID, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // RequestReply provides a mock function with given fields: ctx, msg
 func (_m *Orchestrator) RequestReply(ctx context.Context, msg *core.MessageInOut) (*core.MessageInOut, error) {
 	ret := _m.Called(ctx, msg)
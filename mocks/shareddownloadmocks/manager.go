@@ -68,6 +68,11 @@ func (_m *Manager) Start() error {
 	return r0
 }
 
+// StartRecovery provides a mock function with given fields:
+func (_m *Manager) StartRecovery() {
+	_m.Called()
+}
+
 // WaitStop provides a mock function with given fields:
 func (_m *Manager) WaitStop() {
 	_m.Called()
@@ -21,8 +21,21 @@ type Bridge struct {
 }
 
 // Init provides a mock function with given fields: sysevents
-func (_m *Bridge) Init(sysevents system.EventInterface) {
-	_m.Called(sysevents)
+func (_m *Bridge) Init(sysevents system.EventInterface) error {
+	ret := _m.Called(sysevents)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Init")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(system.EventInterface) error); ok {
+		r0 = rf(sysevents)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
 }
 
 // WaitForDeployOperation provides a mock function with given fields: ctx, id, send
@@ -214,6 +214,24 @@ func (_m *Helper) PersistTransaction(ctx context.Context, id *fftypes.UUID, txTy
 	return r0, r1
 }
 
+// SetBlockchainEventRemoved provides a mock function with given fields: ctx, event, removed
+func (_m *Helper) SetBlockchainEventRemoved(ctx context.Context, event *core.BlockchainEvent, removed bool) error {
+	ret := _m.Called(ctx, event, removed)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SetBlockchainEventRemoved")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *core.BlockchainEvent, bool) error); ok {
+		r0 = rf(ctx, event, removed)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // SubmitNewTransaction provides a mock function with given fields: ctx, txType, idempotencyKey
 func (_m *Helper) SubmitNewTransaction(ctx context.Context, txType fftypes.FFEnum, idempotencyKey core.IdempotencyKey) (*fftypes.UUID, error) {
 	ret := _m.Called(ctx, txType, idempotencyKey)
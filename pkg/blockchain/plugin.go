@@ -105,6 +105,18 @@ type Plugin interface {
 	// GetContractListenerStatus gets the status of a contract listener from the backend connector. Returns false if not found
 	GetContractListenerStatus(ctx context.Context, namespace, subID string, okNotFound bool) (bool, interface{}, core.ContractListenerStatus, error)
 
+	// GetContractListenerHealth gets the checkpoint of a contract listener, and how far it is behind the head of the chain,
+	// in a form that is common to all blockchain connectors. Fields the connector cannot report are left nil.
+	GetContractListenerHealth(ctx context.Context, namespace, subID string) (*core.ContractListenerHealth, error)
+
+	// BackfillContractListener re-delivers the events matching an existing listener from the inclusive range of blocks
+	// of the backfill, through the same callbacks as the listener, and with the same listener ID. Blocks until the whole
+	// range has been delivered, calling progress with the last block that has been fully delivered. Calls started with
+	// the ID of any temporary subscription created for the backfill, which must be recorded against the backfill so that
+	// its events can be attributed to the listener. A backfill that was interrupted has its BackendID and CurrentBlock
+	// set, and resumes from there.
+	BackfillContractListener(ctx context.Context, listener *core.ContractListener, backfill *core.ContractListenerBackfill, started func(backendID string) error, progress func(block uint64)) error
+
 	// GetFFIParamValidator returns a blockchain-plugin-specific validator for FFIParams and their JSON Schema
 	GetFFIParamValidator(ctx context.Context) (fftypes.FFIParamValidator, error)
 
@@ -126,6 +138,10 @@ type Plugin interface {
 	// GenerateErrorSignature generates a strigified signature for the custom error, incorporating any fields significant to identifying the error as unique
 	GenerateErrorSignature(ctx context.Context, errorDef *fftypes.FFIErrorDefinition) string
 
+	// DecodeContractError matches the error from a failed contract invocation against the custom errors of the method,
+	// returning nil if it is not one of them
+	DecodeContractError(ctx context.Context, errors []*fftypes.FFIError, errorMessage string) *core.ContractError
+
 	// GetNetworkVersion queries the provided contract to get the network version
 	GetNetworkVersion(ctx context.Context, location *fftypes.JSONAny) (int, error)
 
@@ -206,6 +222,15 @@ type Callbacks interface {
 	BlockchainEventBatch(batch []*EventToDispatch) error
 }
 
+// BackfillCallbacks is implemented by callbacks that can also look up the backfill jobs of contract listeners,
+// so that the events of a temporary backfill subscription can be attributed to their listener after a restart
+type BackfillCallbacks interface {
+	Callbacks
+
+	// GetContractListenerBackfill returns a backfill job, and the listener it is backfilling. Returns nil if either is not found
+	GetContractListenerBackfill(ctx context.Context, id *fftypes.UUID) (*core.ContractListenerBackfill, *core.ContractListener, error)
+}
+
 // Capabilities the supported featureset of the blockchain
 // interface implemented by the plugin, with the specified config
 type Capabilities struct {
@@ -287,4 +312,8 @@ type Event struct {
 
 	// Signature is the event signature, including the event name and output types
 	Signature string
+
+	// Removed is set when the connector reports that an event it delivered previously has been removed from the
+	// chain by a reorganization. The event is identified by its ProtocolID, and is otherwise as it was first delivered
+	Removed bool
 }
@@ -0,0 +1,43 @@
+// Copyright © 2024 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package core
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+
+	"github.com/hyperledger/firefly-common/pkg/fftypes"
+)
+
+// APIKey is a credential that can be presented to an auth plugin, to be granted the roles on the key.
+// Only a hash of the key is stored - the key itself is returned just once, when it is created.
+type APIKey struct {
+	ID        *fftypes.UUID         `ffstruct:"APIKey" json:"id" ffexcludeinput:"true"`
+	Namespace string                `ffstruct:"APIKey" json:"namespace" ffexcludeinput:"true"`
+	Name      string                `ffstruct:"APIKey" json:"name"`
+	Roles     fftypes.FFStringArray `ffstruct:"APIKey" json:"roles"`
+	Key       string                `ffstruct:"APIKey" json:"key,omitempty" ffexcludeinput:"true"`
+	Hash      string                `json:"-"`
+	Expires   *fftypes.FFTime       `ffstruct:"APIKey" json:"expires,omitempty"`
+	Created   *fftypes.FFTime       `ffstruct:"APIKey" json:"created" ffexcludeinput:"true"`
+}
+
+// HashAPIKey returns the hash that is stored for an API key, and used to look it up
+func HashAPIKey(key string) string {
+	hash := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(hash[:])
+}
@@ -1,4 +1,4 @@
-// Copyright © 2022 Kaleido, Inc.
+// Copyright © 2024 Kaleido, Inc.
 //
 // SPDX-License-Identifier: Apache-2.0
 //
@@ -25,3 +25,40 @@ import (
 type Authorizer interface {
 	Authorize(ctx context.Context, authReq *fftypes.AuthReq) error
 }
+
+type authContextKey struct{}
+
+// AuthContext holds details of a request being authorized, that are not part of fftypes.AuthReq
+type AuthContext struct {
+	// Route is the name of the API route being invoked
+	Route string
+	// EventStream is set when an event stream is being started, rather than an API route invoked
+	EventStream bool
+	// Subscription is the name of the subscription an event stream is being started for, if it is not ephemeral
+	Subscription string
+	// Identity is set by the Authorizer to the authenticated caller, so quotas can be applied to each caller
+	Identity string
+}
+
+// WithAuthContext returns a context carrying the details of a request, for Authorizer implementations
+func WithAuthContext(ctx context.Context, ac *AuthContext) context.Context {
+	return context.WithValue(ctx, authContextKey{}, ac)
+}
+
+// GetAuthContext returns the details of the request being authorized, or an empty AuthContext if there are none
+func GetAuthContext(ctx context.Context) *AuthContext {
+	if ac, ok := ctx.Value(authContextKey{}).(*AuthContext); ok {
+		return ac
+	}
+	return &AuthContext{}
+}
+
+// APIKeyStore looks up the API keys of a namespace, by the hash of the key
+type APIKeyStore interface {
+	GetAPIKeyByHash(ctx context.Context, namespace, hash string) (*APIKey, error)
+}
+
+// APIKeyAuthorizer is implemented by auth plugins that authorize requests using the API keys of each namespace
+type APIKeyAuthorizer interface {
+	SetAPIKeyStore(namespace string, store APIKeyStore)
+}
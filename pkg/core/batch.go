@@ -78,10 +78,11 @@ type Batch struct {
 // BatchPersisted is the structure written to the database
 type BatchPersisted struct {
 	BatchHeader
-	Hash      *fftypes.Bytes32 `ffstruct:"Batch" json:"hash"`
-	Manifest  *fftypes.JSONAny `ffstruct:"Batch" json:"manifest"`
-	TX        TransactionRef   `ffstruct:"Batch" json:"tx"`
-	Confirmed *fftypes.FFTime  `ffstruct:"Batch" json:"confirmed"`
+	Hash         *fftypes.Bytes32   `ffstruct:"Batch" json:"hash"`
+	Manifest     *fftypes.JSONAny   `ffstruct:"Batch" json:"manifest"`
+	TX           TransactionRef     `ffstruct:"Batch" json:"tx"`
+	Confirmed    *fftypes.FFTime    `ffstruct:"Batch" json:"confirmed"`
+	TraceContext fftypes.JSONObject `ffstruct:"Batch" json:"traceContext,omitempty"`
 }
 
 // BatchPayload contains the full JSON of the messages and data, but
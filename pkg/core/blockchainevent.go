@@ -1,4 +1,4 @@
-// Copyright © 2022 Kaleido, Inc.
+// Copyright © 2024 Kaleido, Inc.
 //
 // SPDX-License-Identifier: Apache-2.0
 //
@@ -29,4 +29,10 @@ type BlockchainEvent struct {
 	Info       fftypes.JSONObject       `ffstruct:"BlockchainEvent" json:"info,omitempty"`
 	Timestamp  *fftypes.FFTime          `ffstruct:"BlockchainEvent" json:"timestamp,omitempty"`
 	TX         BlockchainTransactionRef `ffstruct:"BlockchainEvent" json:"tx"`
+	Removed    bool                     `ffstruct:"BlockchainEvent" json:"removed,omitempty"`
+	Sequence   int64                    `json:"-"` // Local database sequence used for cursor pagination
+}
+
+func (e *BlockchainEvent) LocalSequence() int64 {
+	return e.Sequence
 }
@@ -0,0 +1,65 @@
+// Copyright © 2024 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package core
+
+import (
+	"github.com/hyperledger/firefly-common/pkg/ffapi"
+	"github.com/hyperledger/firefly-common/pkg/fftypes"
+)
+
+// ChartAggregateType is the aggregation applied to the records of each group
+type ChartAggregateType = fftypes.FFEnum
+
+var (
+	// ChartAggregateTypeCount counts the records in each group
+	ChartAggregateTypeCount = fftypes.FFEnumValue("chartaggregatetype", "count")
+	// ChartAggregateTypeSum sums a numeric field over the records in each group
+	ChartAggregateTypeSum = fftypes.FFEnumValue("chartaggregatetype", "sum")
+	// ChartAggregateTypeAvg averages a numeric field over the records in each group
+	ChartAggregateTypeAvg = fftypes.FFEnumValue("chartaggregatetype", "avg")
+)
+
+const (
+	// ChartAggregateMaxGroupBy max fields that records can be grouped by
+	ChartAggregateMaxGroupBy = 3
+)
+
+// ChartAggregateInput is a request for a time series of aggregations over a collection
+type ChartAggregateInput struct {
+	StartTime *fftypes.FFTime    `ffstruct:"ChartAggregateInput" json:"startTime"`
+	EndTime   *fftypes.FFTime    `ffstruct:"ChartAggregateInput" json:"endTime"`
+	Buckets   int64              `ffstruct:"ChartAggregateInput" json:"buckets"`
+	GroupBy   []string           `ffstruct:"ChartAggregateInput" json:"groupBy,omitempty"`
+	Aggregate ChartAggregateType `ffstruct:"ChartAggregateInput" json:"aggregate,omitempty" ffenum:"chartaggregatetype"`
+	Field     string             `ffstruct:"ChartAggregateInput" json:"field,omitempty"`
+	Filter    *ffapi.FilterJSON  `ffstruct:"ChartAggregateInput" json:"filter,omitempty"`
+}
+
+// ChartAggregate is a time bucket, with the aggregation of each group of records in the bucket
+type ChartAggregate struct {
+	Timestamp *fftypes.FFTime        `ffstruct:"ChartAggregate" json:"timestamp"`
+	Count     string                 `ffstruct:"ChartAggregate" json:"count"`
+	Groups    []*ChartAggregateGroup `ffstruct:"ChartAggregate" json:"groups"`
+	IsCapped  bool                   `ffstruct:"ChartAggregate" json:"isCapped"`
+}
+
+// ChartAggregateGroup is the aggregation of the records in a bucket that have the same value for each dimension
+type ChartAggregateGroup struct {
+	Dimensions map[string]string `ffstruct:"ChartAggregateGroup" json:"dimensions"`
+	Count      string            `ffstruct:"ChartAggregateGroup" json:"count"`
+	Value      string            `ffstruct:"ChartAggregateGroup" json:"value"`
+}
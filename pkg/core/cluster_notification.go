@@ -0,0 +1,29 @@
+// Copyright © 2024 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package core
+
+import "github.com/hyperledger/firefly-common/pkg/fftypes"
+
+// ClusterNotification is a short-lived record written by one FireFly core replica, to be read
+// by all the other replicas that share the same database
+type ClusterNotification struct {
+	Sequence  int64            `json:"sequence"`
+	Namespace string           `json:"namespace"`
+	Topic     string           `json:"topic"`
+	Payload   *fftypes.JSONAny `json:"payload,omitempty"`
+	Created   *fftypes.FFTime  `json:"created,omitempty"`
+}
@@ -42,10 +42,55 @@ type ContractListener struct {
 
 type ContractListenerWithStatus struct {
 	ContractListener
-	Status interface{} `ffstruct:"ContractListenerWithStatus" json:"status,omitempty" ffexcludeinput:"true"`
+	Status interface{}             `ffstruct:"ContractListenerWithStatus" json:"status,omitempty" ffexcludeinput:"true"`
+	Health *ContractListenerHealth `ffstruct:"ContractListenerWithStatus" json:"health,omitempty" ffexcludeinput:"true"`
+}
+
+// ContractListenerHealth is a blockchain independent view of how far a listener has progressed through the chain.
+// Fields that cannot be reported by the blockchain connector are omitted.
+type ContractListenerHealth struct {
+	Status          ContractListenerStatus `ffstruct:"ContractListenerHealth" json:"status"`
+	CheckpointBlock *uint64                `ffstruct:"ContractListenerHealth" json:"checkpointBlock,omitempty"`
+	ChainHead       *uint64                `ffstruct:"ContractListenerHealth" json:"chainHead,omitempty"`
+	LagBlocks       *uint64                `ffstruct:"ContractListenerHealth" json:"lagBlocks,omitempty"`
+	LagSeconds      *int64                 `ffstruct:"ContractListenerHealth" json:"lagSeconds,omitempty"`
+	Behind          bool                   `ffstruct:"ContractListenerHealth" json:"behind"`
+	LastError       string                 `ffstruct:"ContractListenerHealth" json:"lastError,omitempty"`
+	Updated         *fftypes.FFTime        `ffstruct:"ContractListenerHealth" json:"updated,omitempty"`
 }
 type ContractListenerOptions struct {
-	FirstEvent string `ffstruct:"ContractListenerOptions" json:"firstEvent,omitempty"`
+	FirstEvent    string `ffstruct:"ContractListenerOptions" json:"firstEvent,omitempty"`
+	Confirmations int    `ffstruct:"ContractListenerOptions" json:"confirmations,omitempty"`
+}
+
+type ContractListenerBackfillStatus = fftypes.FFEnum
+
+var (
+	// events in the block range are being re-delivered
+	ContractListenerBackfillStatusRunning = fftypes.FFEnumValue("backfillstatus", "running")
+	// all events in the block range have been delivered
+	ContractListenerBackfillStatusSucceeded = fftypes.FFEnumValue("backfillstatus", "succeeded")
+	// the backfill stopped before the end of the block range
+	ContractListenerBackfillStatusFailed = fftypes.FFEnumValue("backfillstatus", "failed")
+)
+
+type ContractListenerBackfillRequest struct {
+	FromBlock uint64 `ffstruct:"ContractListenerBackfillRequest" json:"fromBlock"`
+	ToBlock   uint64 `ffstruct:"ContractListenerBackfillRequest" json:"toBlock"`
+}
+
+type ContractListenerBackfill struct {
+	ID           *fftypes.UUID                  `ffstruct:"ContractListenerBackfill" json:"id"`
+	Namespace    string                         `ffstruct:"ContractListenerBackfill" json:"namespace"`
+	Listener     *fftypes.UUID                  `ffstruct:"ContractListenerBackfill" json:"listener"`
+	FromBlock    uint64                         `ffstruct:"ContractListenerBackfill" json:"fromBlock"`
+	ToBlock      uint64                         `ffstruct:"ContractListenerBackfill" json:"toBlock"`
+	CurrentBlock *uint64                        `ffstruct:"ContractListenerBackfill" json:"currentBlock,omitempty"`
+	Status       ContractListenerBackfillStatus `ffstruct:"ContractListenerBackfill" json:"status"`
+	Error        string                         `ffstruct:"ContractListenerBackfill" json:"error,omitempty"`
+	BackendID    string                         `ffstruct:"ContractListenerBackfill" json:"backendId,omitempty"`
+	Created      *fftypes.FFTime                `ffstruct:"ContractListenerBackfill" json:"created"`
+	Updated      *fftypes.FFTime                `ffstruct:"ContractListenerBackfill" json:"updated"`
 }
 
 type ListenerStatusError struct {
@@ -54,6 +54,13 @@ type ContractDeployRequest struct {
 	IdempotencyKey IdempotencyKey         `ffstruct:"ContractDeployRequest" json:"idempotencyKey,omitempty" ffexcludeoutput:"true"`
 }
 
+// ContractError is a custom error raised by a smart contract, decoded against the errors defined in its interface
+type ContractError struct {
+	Name      string             `ffstruct:"ContractError" json:"name"`
+	Signature string             `ffstruct:"ContractError" json:"signature,omitempty"`
+	Params    fftypes.JSONObject `ffstruct:"ContractError" json:"params,omitempty"`
+}
+
 type ContractURLs struct {
 	API     string `ffstruct:"ContractURLs" json:"api"`
 	OpenAPI string `ffstruct:"ContractURLs" json:"openapi"`
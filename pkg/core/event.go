@@ -53,6 +53,10 @@ var (
 	EventTypeContractAPIConfirmed = fftypes.FFEnumValue("eventtype", "contract_api_confirmed")
 	// EventTypeBlockchainEventReceived occurs when a new event has been received from the blockchain
 	EventTypeBlockchainEventReceived = fftypes.FFEnumValue("eventtype", "blockchain_event_received")
+	// EventTypeBlockchainEventRemoved occurs when the blockchain connector reports that a blockchain event was removed by a chain reorganization
+	EventTypeBlockchainEventRemoved = fftypes.FFEnumValue("eventtype", "blockchain_event_removed")
+	// EventTypeContractListenerBehind occurs when a contract listener falls further behind the head of the chain than the configured threshold
+	EventTypeContractListenerBehind = fftypes.FFEnumValue("eventtype", "contract_listener_behind")
 	// EventTypeBlockchainInvokeOpSucceeded occurs when a blockchain "invoke" request has succeeded
 	EventTypeBlockchainInvokeOpSucceeded = fftypes.FFEnumValue("eventtype", "blockchain_invoke_op_succeeded")
 	// EventTypeBlockchainInvokeOpFailed occurs when a blockchain "invoke" request has failed
@@ -79,17 +83,19 @@ type Event struct {
 // EnrichedEvent adds the referred object to an event
 type EnrichedEvent struct {
 	Event
-	BlockchainEvent   *BlockchainEvent `ffstruct:"EnrichedEvent" json:"blockchainEvent,omitempty"`
-	ContractAPI       *ContractAPI     `ffstruct:"EnrichedEvent" json:"contractAPI,omitempty"`
-	ContractInterface *fftypes.FFI     `ffstruct:"EnrichedEvent" json:"contractInterface,omitempty"`
-	Datatype          *Datatype        `ffstruct:"EnrichedEvent" json:"datatype,omitempty"`
-	Identity          *Identity        `ffstruct:"EnrichedEvent" json:"identity,omitempty"`
-	Message           *Message         `ffstruct:"EnrichedEvent" json:"message,omitempty"`
-	TokenApproval     *TokenApproval   `ffstruct:"EnrichedEvent" json:"tokenApproval,omitempty"`
-	TokenPool         *TokenPool       `ffstruct:"EnrichedEvent" json:"tokenPool,omitempty"`
-	TokenTransfer     *TokenTransfer   `ffstruct:"EnrichedEvent" json:"tokenTransfer,omitempty"`
-	Transaction       *Transaction     `ffstruct:"EnrichedEvent" json:"transaction,omitempty"`
-	Operation         *Operation       `ffstruct:"EnrichedEvent" json:"operation,omitempty"`
+	BlockchainEvent   *BlockchainEvent  `ffstruct:"EnrichedEvent" json:"blockchainEvent,omitempty"`
+	ContractAPI       *ContractAPI      `ffstruct:"EnrichedEvent" json:"contractAPI,omitempty"`
+	ContractInterface *fftypes.FFI      `ffstruct:"EnrichedEvent" json:"contractInterface,omitempty"`
+	ContractListener  *ContractListener `ffstruct:"EnrichedEvent" json:"contractListener,omitempty"`
+	Datatype          *Datatype         `ffstruct:"EnrichedEvent" json:"datatype,omitempty"`
+	Identity          *Identity         `ffstruct:"EnrichedEvent" json:"identity,omitempty"`
+	Message           *Message          `ffstruct:"EnrichedEvent" json:"message,omitempty"`
+	TokenApproval     *TokenApproval    `ffstruct:"EnrichedEvent" json:"tokenApproval,omitempty"`
+	TokenPool         *TokenPool        `ffstruct:"EnrichedEvent" json:"tokenPool,omitempty"`
+	TokenTransfer     *TokenTransfer    `ffstruct:"EnrichedEvent" json:"tokenTransfer,omitempty"`
+	Transaction       *Transaction      `ffstruct:"EnrichedEvent" json:"transaction,omitempty"`
+	Operation         *Operation        `ffstruct:"EnrichedEvent" json:"operation,omitempty"`
+	ContractError     *ContractError    `ffstruct:"EnrichedEvent" json:"contractError,omitempty"`
 }
 
 // EventDelivery adds the referred object to an event, as well as details of the subscription that caused the event to
@@ -97,6 +103,7 @@ type EnrichedEvent struct {
 type EventDelivery struct {
 	EnrichedEvent
 	Subscription SubscriptionRef `json:"subscription"`
+	Replay       bool            `json:"-"` // Set when a dead-lettered event is delivered again, outside the offset based flow of the subscription
 }
 
 type CombinedEventDataDelivery struct {
@@ -0,0 +1,38 @@
+// Copyright © 2024 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package core
+
+import "github.com/hyperledger/firefly-common/pkg/fftypes"
+
+// Lease is held by a single FireFly core replica at a time, and expires unless it is renewed by its holder.
+// Leases are used to elect the replica that runs the singleton background workers of a namespace.
+type Lease struct {
+	Namespace string          `json:"namespace"`
+	Name      string          `json:"name"`
+	Holder    string          `json:"holder"`
+	Expires   *fftypes.FFTime `json:"expires"`
+}
+
+// LeaderStatus is the leader election status of a namespace, as seen by the local replica
+type LeaderStatus struct {
+	Enabled bool            `ffstruct:"LeaderStatus" json:"enabled"`
+	Replica string          `ffstruct:"LeaderStatus" json:"replica,omitempty"`
+	Leader  bool            `ffstruct:"LeaderStatus" json:"leader"`
+	Holder  string          `ffstruct:"LeaderStatus" json:"holder,omitempty"`
+	Expires *fftypes.FFTime `ffstruct:"LeaderStatus" json:"expires,omitempty"`
+	Since   *fftypes.FFTime `ffstruct:"LeaderStatus" json:"since,omitempty"`
+}
@@ -104,6 +104,7 @@ type Message struct {
 	Data           DataRefs              `ffstruct:"Message" json:"data" ffexcludeinput:"true"`
 	Pins           fftypes.FFStringArray `ffstruct:"Message" json:"pins,omitempty" ffexcludeinput:"true"`
 	IdempotencyKey IdempotencyKey        `ffstruct:"Message" json:"idempotencyKey,omitempty"`
+	TraceContext   fftypes.JSONObject    `ffstruct:"Message" json:"traceContext,omitempty" ffexcludeinput:"true"`
 	Sequence       int64                 `ffstruct:"Message" json:"-"` // Local database sequence used internally for batch assembly
 }
 
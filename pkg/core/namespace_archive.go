@@ -0,0 +1,102 @@
+// Copyright © 2024 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package core
+
+import "github.com/hyperledger/firefly-common/pkg/fftypes"
+
+// NamespaceArchiveVersion is the version of the namespace archive format written by this release
+const NamespaceArchiveVersion = 1
+
+// NamespaceArchiveType is a type of record in a namespace archive
+type NamespaceArchiveType = fftypes.FFEnum
+
+var (
+	// NamespaceArchiveTypeDatatypes is the datatypes of the namespace
+	NamespaceArchiveTypeDatatypes = fftypes.FFEnumValue("namespacearchivetype", "datatypes")
+	// NamespaceArchiveTypeFFIs is the FireFly Interfaces of the namespace
+	NamespaceArchiveTypeFFIs = fftypes.FFEnumValue("namespacearchivetype", "ffis")
+	// NamespaceArchiveTypeFFIMethods is the methods of the FireFly Interfaces
+	NamespaceArchiveTypeFFIMethods = fftypes.FFEnumValue("namespacearchivetype", "ffimethods")
+	// NamespaceArchiveTypeFFIEvents is the events of the FireFly Interfaces
+	NamespaceArchiveTypeFFIEvents = fftypes.FFEnumValue("namespacearchivetype", "ffievents")
+	// NamespaceArchiveTypeFFIErrors is the errors of the FireFly Interfaces
+	NamespaceArchiveTypeFFIErrors = fftypes.FFEnumValue("namespacearchivetype", "ffierrors")
+	// NamespaceArchiveTypeContractAPIs is the contract APIs of the namespace
+	NamespaceArchiveTypeContractAPIs = fftypes.FFEnumValue("namespacearchivetype", "contractapis")
+	// NamespaceArchiveTypeContractListeners is the contract listeners of the namespace
+	NamespaceArchiveTypeContractListeners = fftypes.FFEnumValue("namespacearchivetype", "contractlisteners")
+	// NamespaceArchiveTypeSubscriptions is the durable subscriptions of the namespace
+	NamespaceArchiveTypeSubscriptions = fftypes.FFEnumValue("namespacearchivetype", "subscriptions")
+	// NamespaceArchiveTypeIdentities is the identities of the namespace
+	NamespaceArchiveTypeIdentities = fftypes.FFEnumValue("namespacearchivetype", "identities")
+	// NamespaceArchiveTypeVerifiers is the verifiers of the identities
+	NamespaceArchiveTypeVerifiers = fftypes.FFEnumValue("namespacearchivetype", "verifiers")
+	// NamespaceArchiveTypeGroups is the private messaging groups of the namespace
+	NamespaceArchiveTypeGroups = fftypes.FFEnumValue("namespacearchivetype", "groups")
+)
+
+// NamespaceArchiveTypes is every type of record, in the order they are imported.
+// Records are always imported after the records they refer to.
+var NamespaceArchiveTypes = []NamespaceArchiveType{
+	NamespaceArchiveTypeDatatypes,
+	NamespaceArchiveTypeFFIs,
+	NamespaceArchiveTypeFFIMethods,
+	NamespaceArchiveTypeFFIEvents,
+	NamespaceArchiveTypeFFIErrors,
+	NamespaceArchiveTypeContractAPIs,
+	NamespaceArchiveTypeContractListeners,
+	NamespaceArchiveTypeSubscriptions,
+	NamespaceArchiveTypeIdentities,
+	NamespaceArchiveTypeVerifiers,
+	NamespaceArchiveTypeGroups,
+}
+
+// NamespaceArchiveEntry is one line of a namespace archive. The first entry is the header, and the last
+// entry is the trailer. Every entry in between is a record.
+type NamespaceArchiveEntry struct {
+	Header  *NamespaceArchiveHeader  `json:"header,omitempty"`
+	Type    NamespaceArchiveType     `json:"type,omitempty"`
+	Record  *fftypes.JSONAny         `json:"record,omitempty"`
+	Trailer *NamespaceArchiveTrailer `json:"trailer,omitempty"`
+}
+
+// NamespaceArchiveHeader describes the namespace an archive was exported from
+type NamespaceArchiveHeader struct {
+	Version     int             `json:"version"`
+	Namespace   string          `json:"namespace"`
+	NetworkName string          `json:"networkName,omitempty"`
+	Created     *fftypes.FFTime `json:"created"`
+}
+
+// NamespaceArchiveTrailer marks the end of a complete archive, with the number of records of each type
+type NamespaceArchiveTrailer struct {
+	Counts map[NamespaceArchiveType]int64 `json:"counts"`
+}
+
+// NamespaceImportResult is the outcome of importing a namespace archive
+type NamespaceImportResult struct {
+	Source  string                  `ffstruct:"NamespaceImportResult" json:"source"`
+	DryRun  bool                    `ffstruct:"NamespaceImportResult" json:"dryRun"`
+	Records []*NamespaceImportCount `ffstruct:"NamespaceImportResult" json:"records"`
+}
+
+// NamespaceImportCount is the number of records of a type that were imported, or were already in the namespace
+type NamespaceImportCount struct {
+	Type     NamespaceArchiveType `ffstruct:"NamespaceImportCount" json:"type" ffenum:"namespacearchivetype"`
+	Imported int64                `ffstruct:"NamespaceImportCount" json:"imported"`
+	Existing int64                `ffstruct:"NamespaceImportCount" json:"existing"`
+}
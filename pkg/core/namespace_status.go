@@ -25,6 +25,7 @@ type NamespaceStatus struct {
 	Org        *NamespaceStatusOrg       `ffstruct:"NamespaceStatus" json:"org,omitempty"`
 	Plugins    NamespaceStatusPlugins    `ffstruct:"NamespaceStatus" json:"plugins"`
 	Multiparty NamespaceStatusMultiparty `ffstruct:"NamespaceStatus" json:"multiparty"`
+	Leader     *LeaderStatus             `ffstruct:"NamespaceStatus" json:"leader,omitempty"`
 }
 
 type NamespaceRegistrationStatus = fftypes.FFEnum
@@ -108,6 +108,9 @@ func (op *Operation) DeepCopy() *Operation {
 	if op.Output != nil {
 		cop.Output = deepCopyMap(op.Output)
 	}
+	if op.TraceContext != nil {
+		cop.TraceContext = deepCopyMap(op.TraceContext)
+	}
 	return cop
 }
 
@@ -184,18 +187,19 @@ func NewOperation(plugin Named, namespace string, tx *fftypes.UUID, opType OpTyp
 
 // Operation is a description of an action performed as part of a transaction submitted by this node
 type Operation struct {
-	ID          *fftypes.UUID      `ffstruct:"Operation" json:"id" ffexcludeinput:"true"`
-	Namespace   string             `ffstruct:"Operation" json:"namespace" ffexcludeinput:"true"`
-	Transaction *fftypes.UUID      `ffstruct:"Operation" json:"tx" ffexcludeinput:"true"`
-	Type        OpType             `ffstruct:"Operation" json:"type" ffenum:"optype" ffexcludeinput:"true"`
-	Status      OpStatus           `ffstruct:"Operation" json:"status"`
-	Plugin      string             `ffstruct:"Operation" json:"plugin" ffexcludeinput:"true"`
-	Input       fftypes.JSONObject `ffstruct:"Operation" json:"input,omitempty" ffexcludeinput:"true"`
-	Output      fftypes.JSONObject `ffstruct:"Operation" json:"output,omitempty"`
-	Error       string             `ffstruct:"Operation" json:"error,omitempty"`
-	Created     *fftypes.FFTime    `ffstruct:"Operation" json:"created,omitempty" ffexcludeinput:"true"`
-	Updated     *fftypes.FFTime    `ffstruct:"Operation" json:"updated,omitempty" ffexcludeinput:"true"`
-	Retry       *fftypes.UUID      `ffstruct:"Operation" json:"retry,omitempty" ffexcludeinput:"true"`
+	ID           *fftypes.UUID      `ffstruct:"Operation" json:"id" ffexcludeinput:"true"`
+	Namespace    string             `ffstruct:"Operation" json:"namespace" ffexcludeinput:"true"`
+	Transaction  *fftypes.UUID      `ffstruct:"Operation" json:"tx" ffexcludeinput:"true"`
+	Type         OpType             `ffstruct:"Operation" json:"type" ffenum:"optype" ffexcludeinput:"true"`
+	Status       OpStatus           `ffstruct:"Operation" json:"status"`
+	Plugin       string             `ffstruct:"Operation" json:"plugin" ffexcludeinput:"true"`
+	Input        fftypes.JSONObject `ffstruct:"Operation" json:"input,omitempty" ffexcludeinput:"true"`
+	Output       fftypes.JSONObject `ffstruct:"Operation" json:"output,omitempty"`
+	Error        string             `ffstruct:"Operation" json:"error,omitempty"`
+	Created      *fftypes.FFTime    `ffstruct:"Operation" json:"created,omitempty" ffexcludeinput:"true"`
+	Updated      *fftypes.FFTime    `ffstruct:"Operation" json:"updated,omitempty" ffexcludeinput:"true"`
+	Retry        *fftypes.UUID      `ffstruct:"Operation" json:"retry,omitempty" ffexcludeinput:"true"`
+	TraceContext fftypes.JSONObject `ffstruct:"Operation" json:"traceContext,omitempty" ffexcludeinput:"true"`
 }
 
 // OperationUpdateDTO is the subset of fields on an operation that are mutable, via the SPI
@@ -247,6 +251,14 @@ type OperationCallbacks interface {
 	OperationUpdate(update *OperationUpdate)
 }
 
+// OperationQueryCallbacks is implemented by operation handlers that can also look up the operations of a plugin,
+// so that a plugin which tracks the transactions it submits can resume tracking them after a restart
+type OperationQueryCallbacks interface {
+	OperationCallbacks
+	// GetPendingOperations returns a page of the pending operations of the given types that were submitted through a plugin, oldest first
+	GetPendingOperations(ctx context.Context, plugin string, opTypes []OpType, skip, limit uint64) ([]*Operation, error)
+}
+
 // OperationUpdate notifies FireFly of an update to an operation.
 // Only success/failure and errorMessage (for errors) are modeled.
 // Output can be used to add opaque protocol-specific JSON from the plugin (protocol transaction ID etc.)
@@ -112,6 +112,9 @@ func TestOperationDeepCopy(t *testing.T) {
 		Created:     fftypes.Now(),
 		Updated:     fftypes.Now(),
 		Retry:       fftypes.NewUUID(),
+		TraceContext: fftypes.JSONObject{
+			"traceparent": "00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01",
+		},
 	}
 
 	copyOp := op.DeepCopy()
@@ -130,6 +133,7 @@ func TestOperationDeepCopy(t *testing.T) {
 	assert.Equal(t, op.Created, copyOp.Created)
 	assert.Equal(t, op.Updated, copyOp.Updated)
 	assert.Equal(t, op.Retry, copyOp.Retry)
+	assert.Equal(t, op.TraceContext, copyOp.TraceContext)
 
 	// Modify the original and ensure the copy is not modified
 	*op.ID = *fftypes.NewUUID()
@@ -147,13 +151,17 @@ func TestOperationDeepCopy(t *testing.T) {
 	assert.NotSame(t, copyOp.Input, op.Input)
 	assert.NotSame(t, copyOp.Output, op.Output)
 
+	// Modify the trace context of the copy and ensure the original is not modified
+	copyOp.TraceContext["traceparent"] = "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+	assert.Equal(t, "00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01", op.TraceContext["traceparent"])
+
 	// showcasing that the shallow copy is a shallow copy and the copied object value changed as well the pointer has the same address as the original
 	assert.Equal(t, shallowCopy.ID, op.ID)
 	assert.Same(t, shallowCopy.ID, op.ID)
 
 	// Ensure no new fields are added to the Operation struct
 	// If a new field is added, this test will fail and the DeepCopy function should be updated
-	assert.Equal(t, 12, reflect.TypeOf(Operation{}).NumField())
+	assert.Equal(t, 13, reflect.TypeOf(Operation{}).NumField())
 }
 func TestParseNamespacedOpID(t *testing.T) {
 
@@ -38,16 +38,17 @@ import "github.com/hyperledger/firefly-common/pkg/fftypes"
 // before receiving the blob data - we have to upgrade a batch-park, to a pin-park.
 // This is because the sequence must be in the order the pins arrive.
 type Pin struct {
-	Sequence   int64            `ffstruct:"Pin" json:"sequence"`
-	Namespace  string           `ffstruct:"Pin" json:"namespace"`
-	Masked     bool             `ffstruct:"Pin" json:"masked,omitempty"`
-	Hash       *fftypes.Bytes32 `ffstruct:"Pin" json:"hash,omitempty"`
-	Batch      *fftypes.UUID    `ffstruct:"Pin" json:"batch,omitempty"`
-	BatchHash  *fftypes.Bytes32 `ffstruct:"Pin" json:"batchHash,omitempty"`
-	Index      int64            `ffstruct:"Pin" json:"index"`
-	Dispatched bool             `ffstruct:"Pin" json:"dispatched,omitempty"`
-	Signer     string           `ffstruct:"Pin" json:"signer,omitempty"`
-	Created    *fftypes.FFTime  `ffstruct:"Pin" json:"created,omitempty"`
+	Sequence    int64            `ffstruct:"Pin" json:"sequence"`
+	Namespace   string           `ffstruct:"Pin" json:"namespace"`
+	Masked      bool             `ffstruct:"Pin" json:"masked,omitempty"`
+	Hash        *fftypes.Bytes32 `ffstruct:"Pin" json:"hash,omitempty"`
+	Batch       *fftypes.UUID    `ffstruct:"Pin" json:"batch,omitempty"`
+	BatchHash   *fftypes.Bytes32 `ffstruct:"Pin" json:"batchHash,omitempty"`
+	Index       int64            `ffstruct:"Pin" json:"index"`
+	Dispatched  bool             `ffstruct:"Pin" json:"dispatched,omitempty"`
+	Invalidated bool             `ffstruct:"Pin" json:"invalidated,omitempty"`
+	Signer      string           `ffstruct:"Pin" json:"signer,omitempty"`
+	Created     *fftypes.FFTime  `ffstruct:"Pin" json:"created,omitempty"`
 }
 
 func (p *Pin) LocalSequence() int64 {
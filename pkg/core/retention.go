@@ -0,0 +1,99 @@
+// Copyright © 2024 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package core
+
+import "github.com/hyperledger/firefly-common/pkg/fftypes"
+
+// RetentionType is a type of record that can be archived and deleted by a retention policy
+type RetentionType = fftypes.FFEnum
+
+var (
+	// RetentionTypeEvents is the events emitted in a namespace
+	RetentionTypeEvents = fftypes.FFEnumValue("retentiontype", "events")
+	// RetentionTypeMessages is confirmed and rejected messages
+	RetentionTypeMessages = fftypes.FFEnumValue("retentiontype", "messages")
+	// RetentionTypeData is data, along with the value stored on it
+	RetentionTypeData = fftypes.FFEnumValue("retentiontype", "data")
+	// RetentionTypeOperations is succeeded and failed operations
+	RetentionTypeOperations = fftypes.FFEnumValue("retentiontype", "operations")
+	// RetentionTypeBlockchainEvents is the events received from the blockchain
+	RetentionTypeBlockchainEvents = fftypes.FFEnumValue("retentiontype", "blockchainevents")
+	// RetentionTypeTokenTransfers is token transfers, mints and burns
+	RetentionTypeTokenTransfers = fftypes.FFEnumValue("retentiontype", "tokentransfers")
+)
+
+// RetentionTypes is every type of record, in the order a retention policy is applied
+var RetentionTypes = []RetentionType{
+	RetentionTypeEvents,
+	RetentionTypeMessages,
+	RetentionTypeData,
+	RetentionTypeOperations,
+	RetentionTypeBlockchainEvents,
+	RetentionTypeTokenTransfers,
+}
+
+// RetentionPolicy sets how long each type of record is kept in a namespace before it is archived and deleted.
+// Types without a maximum age are kept forever.
+type RetentionPolicy struct {
+	Events           *fftypes.FFDuration `ffstruct:"RetentionPolicy" json:"events,omitempty"`
+	Messages         *fftypes.FFDuration `ffstruct:"RetentionPolicy" json:"messages,omitempty"`
+	Data             *fftypes.FFDuration `ffstruct:"RetentionPolicy" json:"data,omitempty"`
+	Operations       *fftypes.FFDuration `ffstruct:"RetentionPolicy" json:"operations,omitempty"`
+	BlockchainEvents *fftypes.FFDuration `ffstruct:"RetentionPolicy" json:"blockchainEvents,omitempty"`
+	TokenTransfers   *fftypes.FFDuration `ffstruct:"RetentionPolicy" json:"tokenTransfers,omitempty"`
+}
+
+// MaxAge returns the maximum age of a type of record, or zero if it is kept forever
+func (rp *RetentionPolicy) MaxAge(rt RetentionType) fftypes.FFDuration {
+	var maxAge *fftypes.FFDuration
+	switch rt {
+	case RetentionTypeEvents:
+		maxAge = rp.Events
+	case RetentionTypeMessages:
+		maxAge = rp.Messages
+	case RetentionTypeData:
+		maxAge = rp.Data
+	case RetentionTypeOperations:
+		maxAge = rp.Operations
+	case RetentionTypeBlockchainEvents:
+		maxAge = rp.BlockchainEvents
+	case RetentionTypeTokenTransfers:
+		maxAge = rp.TokenTransfers
+	}
+	if maxAge == nil || *maxAge < 0 {
+		return 0
+	}
+	return *maxAge
+}
+
+// IsEmpty is true if the policy keeps every type of record forever
+func (rp *RetentionPolicy) IsEmpty() bool {
+	for _, rt := range RetentionTypes {
+		if rp.MaxAge(rt) > 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// RetentionPreview is the number of records of a type that a retention policy would archive and delete
+type RetentionPreview struct {
+	Type   RetentionType       `ffstruct:"RetentionPreview" json:"type" ffenum:"retentiontype"`
+	MaxAge *fftypes.FFDuration `ffstruct:"RetentionPreview" json:"maxAge"`
+	Before *fftypes.FFTime     `ffstruct:"RetentionPreview" json:"before"`
+	Count  int64               `ffstruct:"RetentionPreview" json:"count"`
+}
@@ -0,0 +1,50 @@
+// Copyright © 2024 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package core
+
+import (
+	"testing"
+	"time"
+
+	"github.com/hyperledger/firefly-common/pkg/fftypes"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRetentionPolicyMaxAge(t *testing.T) {
+	d := func(dur time.Duration) *fftypes.FFDuration {
+		ffd := fftypes.FFDuration(dur)
+		return &ffd
+	}
+	rp := &RetentionPolicy{
+		Events:           d(1 * time.Hour),
+		Messages:         d(2 * time.Hour),
+		Data:             d(3 * time.Hour),
+		Operations:       d(4 * time.Hour),
+		BlockchainEvents: d(5 * time.Hour),
+		TokenTransfers:   d(-1 * time.Hour),
+	}
+	assert.Equal(t, fftypes.FFDuration(1*time.Hour), rp.MaxAge(RetentionTypeEvents))
+	assert.Equal(t, fftypes.FFDuration(2*time.Hour), rp.MaxAge(RetentionTypeMessages))
+	assert.Equal(t, fftypes.FFDuration(3*time.Hour), rp.MaxAge(RetentionTypeData))
+	assert.Equal(t, fftypes.FFDuration(4*time.Hour), rp.MaxAge(RetentionTypeOperations))
+	assert.Equal(t, fftypes.FFDuration(5*time.Hour), rp.MaxAge(RetentionTypeBlockchainEvents))
+	assert.Equal(t, fftypes.FFDuration(0), rp.MaxAge(RetentionTypeTokenTransfers))
+	assert.False(t, rp.IsEmpty())
+
+	rp = &RetentionPolicy{TokenTransfers: d(-1 * time.Hour)}
+	assert.True(t, rp.IsEmpty())
+}
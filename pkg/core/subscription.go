@@ -33,6 +33,7 @@ type SubscriptionFilter struct {
 	Transaction      TransactionFilter     `ffstruct:"SubscriptionFilter" json:"transaction,omitempty"`
 	BlockchainEvent  BlockchainEventFilter `ffstruct:"SubscriptionFilter" json:"blockchainevent,omitempty"`
 	Topic            string                `ffstruct:"SubscriptionFilter" json:"topic,omitempty"`
+	Expression       string                `ffstruct:"SubscriptionFilter" json:"expression,omitempty"`
 	DeprecatedTopics string                `ffstruct:"SubscriptionFilter" json:"topics,omitempty"`
 	DeprecatedTag    string                `ffstruct:"SubscriptionFilter" json:"tag,omitempty"`
 	DeprecatedGroup  string                `ffstruct:"SubscriptionFilter" json:"group,omitempty"`
@@ -55,6 +56,7 @@ func NewSubscriptionFilterFromQuery(query url.Values) SubscriptionFilter {
 			Type: query.Get("filter.transaction.type"),
 		},
 		Topic:            query.Get("filter.topic"),
+		Expression:       query.Get("filter.expression"),
 		DeprecatedTag:    query.Get("filter.tag"),
 		DeprecatedTopics: query.Get("filter.topics"),
 		DeprecatedGroup:  query.Get("filter.group"),
@@ -95,6 +97,8 @@ type SubscriptionCoreOptions struct {
 	WithData     *bool              `ffstruct:"SubscriptionCoreOptions" json:"withData,omitempty"`
 	Batch        *bool              `ffstruct:"SubscriptionCoreOptions" json:"batch,omitempty"`
 	BatchTimeout *string            `ffstruct:"SubscriptionCoreOptions" json:"batchTimeout,omitempty"`
+	MaxAttempts  *uint16            `ffstruct:"SubscriptionCoreOptions" json:"maxAttempts,omitempty"`
+	RetryBackoff *string            `ffstruct:"SubscriptionCoreOptions" json:"retryBackoff,omitempty"`
 }
 
 // SubscriptionOptions customize the behavior of subscriptions
@@ -149,6 +153,8 @@ func (so *SubscriptionOptions) UnmarshalJSON(b []byte) error {
 	delete(so.additionalOptions, "firstEvent")
 	delete(so.additionalOptions, "readAhead")
 	delete(so.additionalOptions, "withData")
+	delete(so.additionalOptions, "maxAttempts")
+	delete(so.additionalOptions, "retryBackoff")
 	return nil
 }
 
@@ -174,6 +180,12 @@ func (so SubscriptionOptions) MarshalJSON() ([]byte, error) {
 	if so.BatchTimeout != nil {
 		so.additionalOptions["batchTimeout"] = so.BatchTimeout
 	}
+	if so.MaxAttempts != nil {
+		so.additionalOptions["maxAttempts"] = float64(*so.MaxAttempts)
+	}
+	if so.RetryBackoff != nil {
+		so.additionalOptions["retryBackoff"] = so.RetryBackoff
+	}
 
 	return json.Marshal(&so.additionalOptions)
 }
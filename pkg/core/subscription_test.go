@@ -29,6 +29,7 @@ func TestSubscriptionOptionsDatabaseSerialization(t *testing.T) {
 	readAhead := uint16(50)
 	yes := true
 	oneSec := "1s"
+	maxAttempts := uint16(5)
 	sub1 := &Subscription{
 		Options: SubscriptionOptions{
 			SubscriptionCoreOptions: SubscriptionCoreOptions{
@@ -37,6 +38,8 @@ func TestSubscriptionOptionsDatabaseSerialization(t *testing.T) {
 				WithData:     &yes,
 				Batch:        &yes,
 				BatchTimeout: &oneSec,
+				MaxAttempts:  &maxAttempts,
+				RetryBackoff: &oneSec,
 			},
 			WebhookSubOptions: WebhookSubOptions{
 				TLSConfigName: "myconfig",
@@ -62,7 +65,9 @@ func TestSubscriptionOptionsDatabaseSerialization(t *testing.T) {
 		"tlsConfigName":"myconfig",
 		"withData":true,
 		"batch":true,
-		"batchTimeout":"1s"
+		"batchTimeout":"1s",
+		"maxAttempts":5,
+		"retryBackoff":"1s"
 	}`, string(b1.([]byte)))
 
 	f1, err := sub1.Filter.Value()
@@ -78,6 +83,8 @@ func TestSubscriptionOptionsDatabaseSerialization(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, SubOptsFirstEventNewest, *sub2.Options.FirstEvent)
 	assert.Equal(t, uint16(50), *sub2.Options.ReadAhead)
+	assert.Equal(t, uint16(5), *sub2.Options.MaxAttempts)
+	assert.Equal(t, "1s", *sub2.Options.RetryBackoff)
 	assert.Equal(t, "myconfig", sub2.Options.TLSConfigName)
 	assert.Equal(t, string(b1.([]byte)), string(b2.([]byte)))
 
@@ -85,6 +92,8 @@ func TestSubscriptionOptionsDatabaseSerialization(t *testing.T) {
 	assert.Nil(t, sub2.Options.TransportOptions()["withData"])
 	assert.Nil(t, sub2.Options.TransportOptions()["firstEvent"])
 	assert.Nil(t, sub2.Options.TransportOptions()["readAhead"])
+	assert.Nil(t, sub2.Options.TransportOptions()["maxAttempts"])
+	assert.Nil(t, sub2.Options.TransportOptions()["retryBackoff"])
 
 	// Confirm we get back the transport options
 	assert.Equal(t, float64(12345), sub2.Options.TransportOptions().GetObject("my-nested-opts")["myopt1"])
@@ -1,4 +1,4 @@
-// Copyright © 2022 Kaleido, Inc.
+// Copyright © 2024 Kaleido, Inc.
 //
 // SPDX-License-Identifier: Apache-2.0
 //
@@ -44,7 +44,9 @@ type TokenTransfer struct {
 	Created         *fftypes.FFTime    `ffstruct:"TokenTransfer" json:"created,omitempty" ffexcludeinput:"true"`
 	TX              TransactionRef     `ffstruct:"TokenTransfer" json:"tx" ffexcludeinput:"true"`
 	BlockchainEvent *fftypes.UUID      `ffstruct:"TokenTransfer" json:"blockchainEvent,omitempty" ffexcludeinput:"true"`
+	Invalidated     bool               `ffstruct:"TokenTransfer" json:"invalidated,omitempty" ffexcludeinput:"true"`
 	Config          fftypes.JSONObject `ffstruct:"TokenTransfer" json:"config,omitempty" ffexcludeoutput:"true"` // for REST calls only (not stored)
+	Sequence        int64              `json:"-"`                                                                // Local database sequence used for cursor pagination
 }
 
 type TokenTransferInput struct {
@@ -53,3 +55,7 @@ type TokenTransferInput struct {
 	Pool           string         `ffstruct:"TokenTransferInput" json:"pool,omitempty"`
 	IdempotencyKey IdempotencyKey `ffstruct:"TokenTransferInput" json:"idempotencyKey,omitempty" ffexcludeoutput:"true"`
 }
+
+func (t *TokenTransfer) LocalSequence() int64 {
+	return t.Sequence
+}
@@ -18,26 +18,47 @@ package core
 
 import (
 	"crypto/tls"
+	"text/template"
 
 	"github.com/go-resty/resty/v2"
+	"github.com/hyperledger/firefly-common/pkg/fftypes"
 )
 
 type WebhookSubOptions struct {
-	Fastack       bool                `ffstruct:"WebhookSubOptions" json:"fastack,omitempty"`
-	URL           string              `ffstruct:"WebhookSubOptions" json:"url,omitempty"`
-	Method        string              `ffstruct:"WebhookSubOptions" json:"method,omitempty"`
-	JSON          bool                `ffstruct:"WebhookSubOptions" json:"json,omitempty"`
-	Reply         bool                `ffstruct:"WebhookSubOptions" json:"reply,omitempty"`
-	ReplyTag      string              `ffstruct:"WebhookSubOptions" json:"replytag,omitempty"`
-	ReplyTX       string              `ffstruct:"WebhookSubOptions" json:"replytx,omitempty"`
-	Headers       map[string]string   `ffstruct:"WebhookSubOptions" json:"headers,omitempty"`
-	Query         map[string]string   `ffstruct:"WebhookSubOptions" json:"query,omitempty"`
-	TLSConfigName string              `ffstruct:"WebhookSubOptions" json:"tlsConfigName,omitempty"`
-	TLSConfig     *tls.Config         `ffstruct:"WebhookSubOptions" json:"-" ffexcludeinput:"true"`
-	Input         WebhookInputOptions `ffstruct:"WebhookSubOptions" json:"input,omitempty"`
-	Retry         WebhookRetryOptions `ffstruct:"WebhookSubOptions" json:"retry,omitempty"`
-	HTTPOptions   WebhookHTTPOptions  `ffstruct:"WebhookSubOptions" json:"httpOptions,omitempty"`
-	RestyClient   *resty.Client       `ffstruct:"WebhookSubOptions" json:"-" ffexcludeinput:"true"`
+	Fastack       bool                  `ffstruct:"WebhookSubOptions" json:"fastack,omitempty"`
+	URL           string                `ffstruct:"WebhookSubOptions" json:"url,omitempty"`
+	Method        string                `ffstruct:"WebhookSubOptions" json:"method,omitempty"`
+	JSON          bool                  `ffstruct:"WebhookSubOptions" json:"json,omitempty"`
+	Reply         bool                  `ffstruct:"WebhookSubOptions" json:"reply,omitempty"`
+	ReplyTag      string                `ffstruct:"WebhookSubOptions" json:"replytag,omitempty"`
+	ReplyTX       string                `ffstruct:"WebhookSubOptions" json:"replytx,omitempty"`
+	Headers       map[string]string     `ffstruct:"WebhookSubOptions" json:"headers,omitempty"`
+	Query         map[string]string     `ffstruct:"WebhookSubOptions" json:"query,omitempty"`
+	TLSConfigName string                `ffstruct:"WebhookSubOptions" json:"tlsConfigName,omitempty"`
+	TLSConfig     *tls.Config           `ffstruct:"WebhookSubOptions" json:"-" ffexcludeinput:"true"`
+	Input         WebhookInputOptions   `ffstruct:"WebhookSubOptions" json:"input,omitempty"`
+	Template      string                `ffstruct:"WebhookSubOptions" json:"template,omitempty"`
+	Signing       WebhookSigningOptions `ffstruct:"WebhookSubOptions" json:"signing,omitempty"`
+	SigningKeys   *WebhookSigningKeys   `ffstruct:"WebhookSubOptions" json:"-" ffexcludeinput:"true"`
+	BodyTemplate  *template.Template    `ffstruct:"WebhookSubOptions" json:"-" ffexcludeinput:"true"`
+	Retry         WebhookRetryOptions   `ffstruct:"WebhookSubOptions" json:"retry,omitempty"`
+	HTTPOptions   WebhookHTTPOptions    `ffstruct:"WebhookSubOptions" json:"httpOptions,omitempty"`
+	RestyClient   *resty.Client         `ffstruct:"WebhookSubOptions" json:"-" ffexcludeinput:"true"`
+}
+
+type WebhookSigningOptions struct {
+	Secret               string          `ffstruct:"WebhookSigningOptions" json:"secret,omitempty"`
+	PreviousSecret       string          `ffstruct:"WebhookSigningOptions" json:"previousSecret,omitempty"`
+	PreviousSecretExpiry *fftypes.FFTime `ffstruct:"WebhookSigningOptions" json:"previousSecretExpiry,omitempty"`
+	Header               string          `ffstruct:"WebhookSigningOptions" json:"header,omitempty"`
+	VerifyReply          bool            `ffstruct:"WebhookSigningOptions" json:"verifyReply,omitempty"`
+}
+
+// WebhookSigningKeys are the decrypted signing secrets of a subscription, resolved when its options are validated
+type WebhookSigningKeys struct {
+	Current        []byte
+	Previous       []byte
+	PreviousExpiry *fftypes.FFTime
 }
 
 type WebhookRetryOptions struct {
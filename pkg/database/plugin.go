@@ -18,6 +18,7 @@ package database
 
 import (
 	"context"
+	"time"
 
 	"github.com/hyperledger/firefly-common/pkg/config"
 	"github.com/hyperledger/firefly-common/pkg/ffapi"
@@ -418,6 +419,9 @@ type iTokenTransferCollection interface {
 	// GetTokenTransfers - Get token transfers
 	GetTokenTransfers(ctx context.Context, namespace string, filter ffapi.Filter) ([]*core.TokenTransfer, *ffapi.FilterResult, error)
 
+	// UpdateTokenTransfers - Update multiple token transfers
+	UpdateTokenTransfers(ctx context.Context, namespace string, filter ffapi.Filter, update ffapi.Update) (err error)
+
 	// DeleteTokenTransfers - Delete token transfers from a particular pool
 	DeleteTokenTransfers(ctx context.Context, namespace string, poolID *fftypes.UUID) error
 }
@@ -561,14 +565,121 @@ type iBlockchainEventCollection interface {
 	// GetBlockchainEventByID - get blockchain event by protocol ID
 	GetBlockchainEventByProtocolID(ctx context.Context, namespace string, listener *fftypes.UUID, protocolID string) (*core.BlockchainEvent, error)
 
+	// UpdateBlockchainEvent - update a blockchain event
+	UpdateBlockchainEvent(ctx context.Context, namespace string, id *fftypes.UUID, update ffapi.Update) (err error)
+
 	// GetBlockchainEvents - get blockchain events
 	GetBlockchainEvents(ctx context.Context, namespace string, filter ffapi.Filter) ([]*core.BlockchainEvent, *ffapi.FilterResult, error)
 }
 
+type iClusterNotificationCollection interface {
+	// InsertClusterNotification - Insert a notification to be read by all FireFly core replicas sharing the database
+	InsertClusterNotification(ctx context.Context, notification *core.ClusterNotification) (err error)
+
+	// GetClusterNotifications - Get cluster notifications
+	GetClusterNotifications(ctx context.Context, namespace string, filter ffapi.Filter) ([]*core.ClusterNotification, *ffapi.FilterResult, error)
+
+	// DeleteClusterNotifications - Delete the cluster notifications for a topic created before the supplied time
+	DeleteClusterNotifications(ctx context.Context, namespace, topic string, before *fftypes.FFTime) (err error)
+
+	// ListenClusterNotifications - Register for immediate delivery of notifications inserted by any replica, for the
+	//                              given namespace and topic, until the context is cancelled. The delivered notifications
+	//                              only contain the namespace, topic and sequence, and are a prompt to query for new entries.
+	//                              Returns a nil channel if the database cannot push notifications, and the caller must poll.
+	ListenClusterNotifications(ctx context.Context, namespace, topic string) (<-chan *core.ClusterNotification, error)
+}
+
+type iLeaseCollection interface {
+	// AcquireLease - Acquire or renew the named lease for the holder, unless it is held by another holder and has not
+	//                expired. The lease expires after the TTL, measured by the clock of the database.
+	//                Returns the lease as it is now stored, which has a different holder if it was not acquired.
+	AcquireLease(ctx context.Context, namespace, name, holder string, ttl time.Duration) (current *core.Lease, err error)
+
+	// ReleaseLease - Release the named lease, if it is held by the holder
+	ReleaseLease(ctx context.Context, namespace, name, holder string) (err error)
+}
+
+type iDeadLetterCollection interface {
+	// InsertDeadLetter - Insert an entry into the dead-letter queue of a subscription
+	InsertDeadLetter(ctx context.Context, deadLetter *core.DeadLetter) (err error)
+
+	// UpdateDeadLetter - Update a dead-letter entry
+	UpdateDeadLetter(ctx context.Context, namespace string, id *fftypes.UUID, update ffapi.Update) (err error)
+
+	// GetDeadLetterByID - Get a dead-letter entry by ID
+	GetDeadLetterByID(ctx context.Context, namespace string, id *fftypes.UUID) (*core.DeadLetter, error)
+
+	// GetDeadLetters - Get dead-letter entries
+	GetDeadLetters(ctx context.Context, namespace string, filter ffapi.Filter) ([]*core.DeadLetter, *ffapi.FilterResult, error)
+
+	// DeleteDeadLetter - Delete a dead-letter entry
+	DeleteDeadLetter(ctx context.Context, namespace string, id *fftypes.UUID) (err error)
+}
+
+type iContractListenerBackfillCollection interface {
+	// InsertContractListenerBackfill - Insert a backfill job of a contract listener
+	InsertContractListenerBackfill(ctx context.Context, backfill *core.ContractListenerBackfill) (err error)
+
+	// UpdateContractListenerBackfill - Update the progress or status of a backfill job
+	UpdateContractListenerBackfill(ctx context.Context, namespace string, id *fftypes.UUID, update ffapi.Update) (err error)
+
+	// GetContractListenerBackfillByID - Get a backfill job by ID
+	GetContractListenerBackfillByID(ctx context.Context, namespace string, id *fftypes.UUID) (*core.ContractListenerBackfill, error)
+
+	// GetContractListenerBackfills - Get backfill jobs
+	GetContractListenerBackfills(ctx context.Context, namespace string, filter ffapi.Filter) ([]*core.ContractListenerBackfill, *ffapi.FilterResult, error)
+
+	// DeleteContractListenerBackfill - Delete a backfill job
+	DeleteContractListenerBackfill(ctx context.Context, namespace string, id *fftypes.UUID) (err error)
+
+	// DeleteContractListenerBackfills - Delete all the backfill jobs of a contract listener
+	DeleteContractListenerBackfills(ctx context.Context, namespace string, listenerID *fftypes.UUID) (err error)
+}
+
+type iAPIKeyCollection interface {
+	// InsertAPIKey - Insert an API key, which must have its hash set
+	InsertAPIKey(ctx context.Context, apiKey *core.APIKey) (err error)
+
+	// GetAPIKeyByID - Get an API key by ID
+	GetAPIKeyByID(ctx context.Context, namespace string, id *fftypes.UUID) (*core.APIKey, error)
+
+	// GetAPIKeyByHash - Get an API key by the hash of the key
+	GetAPIKeyByHash(ctx context.Context, namespace, hash string) (*core.APIKey, error)
+
+	// GetAPIKeys - Get API keys
+	GetAPIKeys(ctx context.Context, namespace string, filter ffapi.Filter) ([]*core.APIKey, *ffapi.FilterResult, error)
+
+	// DeleteAPIKey - Delete an API key
+	DeleteAPIKey(ctx context.Context, namespace string, id *fftypes.UUID) (err error)
+}
+
+type iRetentionCollection interface {
+	// PurgeRecords - Permanently delete records from a collection, which have been selected for deletion by a retention policy.
+	//                Supported for messages, data, operations, events, blockchainevents and tokentransfers.
+	//                The blob records of deleted data are deleted with it
+	PurgeRecords(ctx context.Context, namespace string, collection CollectionName, ids []*fftypes.UUID) (err error)
+
+	// GetPurgeableData - Get the data matching the filter that is not referenced by a message being kept. Messages are kept
+	//                    unless they are confirmed or rejected, and were created before messagesBefore. All messages are
+	//                    kept if messagesBefore is nil
+	GetPurgeableData(ctx context.Context, namespace string, filter ffapi.Filter, messagesBefore *fftypes.FFTime) (data core.DataArray, res *ffapi.FilterResult, err error)
+}
+
 // PersistenceInterface are the operations that must be implemented by a database interface plugin.
 type iChartCollection interface {
 	// GetChartHistogram - Get charting data for a histogram
 	GetChartHistogram(ctx context.Context, namespace string, intervals []core.ChartHistogramInterval, collection CollectionName) ([]*core.ChartHistogram, error)
+
+	// GetChartAggregates - Get charting data for an aggregation of the records in each interval, grouped by fields of the collection
+	GetChartAggregates(ctx context.Context, namespace string, intervals []core.ChartHistogramInterval, collection CollectionName, query *ChartAggregateQuery) ([]*core.ChartAggregate, error)
+}
+
+// ChartAggregateQuery selects the records of a chart, and how they are grouped and aggregated
+type ChartAggregateQuery struct {
+	Filter    ffapi.Filter
+	GroupBy   []string
+	Aggregate core.ChartAggregateType
+	Field     string
 }
 
 // PeristenceInterface are the operations that must be implemented by a database interface plugin.
@@ -605,6 +716,11 @@ type PersistenceInterface interface {
 	// - The caller is responsible for passing the supplied context to all database operations within the callback function
 	RunAsGroup(ctx context.Context, fn func(ctx context.Context) error) error
 
+	// RunAsReadSnapshot runs all the reads performed within the context function against a single consistent
+	// snapshot of the database, so that records written while they run are not seen by some reads and not others.
+	// The caller must not perform any writes within the callback function.
+	RunAsReadSnapshot(ctx context.Context, fn func(ctx context.Context) error) error
+
 	iNamespaceCollection
 	iMessageCollection
 	iDataCollection
@@ -634,6 +750,12 @@ type PersistenceInterface interface {
 	iContractListenerCollection
 	iBlockchainEventCollection
 	iChartCollection
+	iClusterNotificationCollection
+	iLeaseCollection
+	iDeadLetterCollection
+	iContractListenerBackfillCollection
+	iAPIKeyCollection
+	iRetentionCollection
 }
 
 // CollectionName represents all collections
@@ -680,6 +802,8 @@ const (
 	CollectionContractAPIs      UUIDCollectionNS = "contractapis"
 	CollectionContractListeners UUIDCollectionNS = "contractlisteners"
 	CollectionIdentities        UUIDCollectionNS = "identities"
+	CollectionDeadLetters       UUIDCollectionNS = "deadletters"
+	CollectionAPIKeys           UUIDCollectionNS = "apikeys"
 )
 
 // HashCollectionNS is a collection where the primary key is a hash, such that it can
@@ -699,11 +823,12 @@ const (
 type OtherCollection CollectionName
 
 const (
-	CollectionBlobs         OtherCollection = "blobs"
-	CollectionNextpins      OtherCollection = "nextpins"
-	CollectionNonces        OtherCollection = "nonces"
-	CollectionOffsets       OtherCollection = "offsets"
-	CollectionTokenBalances OtherCollection = "tokenbalances"
+	CollectionBlobs                OtherCollection = "blobs"
+	CollectionNextpins             OtherCollection = "nextpins"
+	CollectionNonces               OtherCollection = "nonces"
+	CollectionOffsets              OtherCollection = "offsets"
+	CollectionTokenBalances        OtherCollection = "tokenbalances"
+	CollectionClusterNotifications OtherCollection = "clusternotifications"
 )
 
 // PostCompletionHook is a closure/function that will be called after a successful insertion.
@@ -739,6 +864,8 @@ type Callbacks interface {
 // Capabilities defines the capabilities a plugin can report as implementing or not
 type Capabilities struct {
 	Concurrency bool
+	// EncryptionAtRest is set when data values, and operation inputs and outputs, are encrypted in the database
+	EncryptionAtRest bool
 }
 
 // MessageQueryFactory filter fields for messages
@@ -765,6 +892,7 @@ var MessageQueryFactory = &ffapi.QueryFields{
 	"txid":           &ffapi.UUIDField{},
 	"txparent.type":  &ffapi.StringField{},
 	"txparent.id":    &ffapi.UUIDField{},
+	"jsonpath":       &ffapi.StringField{},
 }
 
 // BatchQueryFactory filter fields for batches
@@ -807,6 +935,7 @@ var DataQueryFactory = &ffapi.QueryFields{
 	"created":          &ffapi.TimeField{},
 	"value":            &ffapi.JSONField{},
 	"public":           &ffapi.StringField{},
+	"jsonpath":         &ffapi.StringField{},
 }
 
 // DatatypeQueryFactory filter fields for data definitions
@@ -826,6 +955,50 @@ var OffsetQueryFactory = &ffapi.QueryFields{
 	"current": &ffapi.Int64Field{},
 }
 
+// ClusterNotificationQueryFactory filter fields for cluster notifications
+var ClusterNotificationQueryFactory = &ffapi.QueryFields{
+	"sequence": &ffapi.Int64Field{},
+	"topic":    &ffapi.StringField{},
+	"created":  &ffapi.TimeField{},
+}
+
+// DeadLetterQueryFactory filter fields for dead-letter entries
+var DeadLetterQueryFactory = &ffapi.QueryFields{
+	"id":               &ffapi.UUIDField{},
+	"subscription":     &ffapi.UUIDField{},
+	"subscriptionname": &ffapi.StringField{},
+	"event":            &ffapi.UUIDField{},
+	"eventsequence":    &ffapi.Int64Field{},
+	"eventtype":        &ffapi.StringField{},
+	"attempts":         &ffapi.Int64Field{},
+	"lasterror":        &ffapi.StringField{},
+	"created":          &ffapi.TimeField{},
+	"updated":          &ffapi.TimeField{},
+}
+
+// ContractListenerBackfillQueryFactory filter fields for contract listener backfill jobs
+var ContractListenerBackfillQueryFactory = &ffapi.QueryFields{
+	"id":           &ffapi.UUIDField{},
+	"listener":     &ffapi.UUIDField{},
+	"fromblock":    &ffapi.Int64Field{},
+	"toblock":      &ffapi.Int64Field{},
+	"currentblock": &ffapi.Int64Field{},
+	"status":       &ffapi.StringField{},
+	"error":        &ffapi.StringField{},
+	"backendid":    &ffapi.StringField{},
+	"created":      &ffapi.TimeField{},
+	"updated":      &ffapi.TimeField{},
+}
+
+// APIKeyQueryFactory filter fields for API keys
+var APIKeyQueryFactory = &ffapi.QueryFields{
+	"id":      &ffapi.UUIDField{},
+	"name":    &ffapi.StringField{},
+	"roles":   &ffapi.FFStringArrayField{},
+	"expires": &ffapi.TimeField{},
+	"created": &ffapi.TimeField{},
+}
+
 // OperationQueryFactory filter fields for data operations
 var OperationQueryFactory = &ffapi.QueryFields{
 	"id":      &ffapi.UUIDField{},
@@ -866,13 +1039,14 @@ var EventQueryFactory = &ffapi.QueryFields{
 
 // PinQueryFactory filter fields for parked contexts
 var PinQueryFactory = &ffapi.QueryFields{
-	"sequence":   &ffapi.Int64Field{},
-	"masked":     &ffapi.BoolField{},
-	"hash":       &ffapi.Bytes32Field{},
-	"batch":      &ffapi.UUIDField{},
-	"index":      &ffapi.Int64Field{},
-	"dispatched": &ffapi.BoolField{},
-	"created":    &ffapi.TimeField{},
+	"sequence":    &ffapi.Int64Field{},
+	"masked":      &ffapi.BoolField{},
+	"hash":        &ffapi.Bytes32Field{},
+	"batch":       &ffapi.UUIDField{},
+	"index":       &ffapi.Int64Field{},
+	"dispatched":  &ffapi.BoolField{},
+	"invalidated": &ffapi.BoolField{},
+	"created":     &ffapi.TimeField{},
 }
 
 // IdentityQueryFactory filter fields for identities
@@ -994,7 +1168,9 @@ var TokenTransferQueryFactory = &ffapi.QueryFields{
 	"tx.type":         &ffapi.StringField{},
 	"tx.id":           &ffapi.UUIDField{},
 	"blockchainevent": &ffapi.UUIDField{},
+	"invalidated":     &ffapi.BoolField{},
 	"type":            &ffapi.StringField{},
+	"sequence":        &ffapi.Int64Field{},
 }
 
 var TokenApprovalQueryFactory = &ffapi.QueryFields{
@@ -1011,6 +1187,7 @@ var TokenApprovalQueryFactory = &ffapi.QueryFields{
 	"tx.type":         &ffapi.StringField{},
 	"tx.id":           &ffapi.UUIDField{},
 	"blockchainevent": &ffapi.UUIDField{},
+	"invalidated":     &ffapi.BoolField{},
 	"message":         &ffapi.UUIDField{},
 	"messagehash":     &ffapi.Bytes32Field{},
 }
@@ -1077,6 +1254,8 @@ var BlockchainEventQueryFactory = &ffapi.QueryFields{
 	"tx.id":           &ffapi.UUIDField{},
 	"tx.blockchainid": &ffapi.StringField{},
 	"timestamp":       &ffapi.TimeField{},
+	"removed":         &ffapi.BoolField{},
+	"sequence":        &ffapi.Int64Field{},
 }
 
 // ContractAPIQueryFactory filter fields for Contract APIs
@@ -0,0 +1,51 @@
+// Copyright © 2024 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package database
+
+import "context"
+
+type readPreferenceKey struct{}
+
+type readPreference int
+
+const (
+	readPreferencePrimary readPreference = iota
+	readPreferenceReplica
+)
+
+// WithReplicaReads marks the reads made with the returned context as tolerating stale results, so that a plugin
+// with a read replica can serve them from the replica rather than the primary database. Reads that are part of
+// a database transaction are always served by the primary.
+func WithReplicaReads(ctx context.Context) context.Context {
+	return context.WithValue(ctx, readPreferenceKey{}, readPreferenceReplica)
+}
+
+// WithPrimaryReads marks the reads made with the returned context as needing to see all committed writes,
+// overriding WithReplicaReads on a parent context. Use it for reads whose results are cached, so that a stale
+// result from a replica does not outlive the request. A context that already reads from the primary is returned as is.
+func WithPrimaryReads(ctx context.Context) context.Context {
+	if !ReplicaReadsAllowed(ctx) {
+		return ctx
+	}
+	return context.WithValue(ctx, readPreferenceKey{}, readPreferencePrimary)
+}
+
+// ReplicaReadsAllowed returns true if the reads made with the context tolerate stale results
+func ReplicaReadsAllowed(ctx context.Context) bool {
+	pref, _ := ctx.Value(readPreferenceKey{}).(readPreference)
+	return pref == readPreferenceReplica
+}
@@ -89,4 +89,7 @@ type Callbacks interface {
 
 type Capabilities struct {
 	BatchDelivery bool
+	// Connectionless transports deliver durable subscriptions from every node to the same destination,
+	// rather than to a connection made by an application, so only the leader may dispatch them
+	Connectionless bool
 }
@@ -0,0 +1,45 @@
+// Copyright © 2024 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package keyprovider
+
+import (
+	"context"
+
+	"github.com/hyperledger/firefly-common/pkg/config"
+	"github.com/hyperledger/firefly/pkg/core"
+)
+
+// Plugin is the interface implemented by each key provider plugin. A key provider holds the key encryption keys
+// used to wrap the data keys that encrypt values at rest, and never returns the key encryption keys themselves.
+type Plugin interface {
+	core.Named
+
+	// InitConfig initializes the set of configuration options that are valid, with defaults. Called on all plugins.
+	InitConfig(config config.Section)
+
+	// Init initializes the plugin, with configuration
+	Init(ctx context.Context, config config.Section) error
+
+	// CurrentKeyID returns the ID of the key encryption key that new data keys are wrapped with
+	CurrentKeyID() string
+
+	// WrapKey encrypts a data key with the identified key encryption key
+	WrapKey(ctx context.Context, keyID string, dataKey []byte) (wrapped []byte, err error)
+
+	// UnwrapKey decrypts a data key that was wrapped with the identified key encryption key
+	UnwrapKey(ctx context.Context, keyID string, wrapped []byte) (dataKey []byte, err error)
+}